@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/cachemissexplainer"
+	"github.com/buildbarn/bb-remote-execution/pkg/cas"
+	cachemissexplainer_pb "github.com/buildbarn/bb-remote-execution/pkg/proto/cachemissexplainer"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/completedactionlogger"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/bb_cache_miss_explainer"
+	blobstore_configuration "github.com/buildbarn/bb-storage/pkg/blobstore/configuration"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/eviction"
+	"github.com/buildbarn/bb-storage/pkg/global"
+	bb_grpc "github.com/buildbarn/bb-storage/pkg/grpc"
+	"github.com/buildbarn/bb-storage/pkg/program"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"golang.org/x/sync/semaphore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// This service receives completed actions from workers through the
+// CompletedActionLogger service and records fingerprints of them. These
+// fingerprints can be compared through the CacheMissExplainer service,
+// allowing users to determine why an action was not served from the
+// Action Cache. Actions are compared against the most recently
+// executed action having the same output paths, unless an explicit
+// action to compare against is provided.
+
+func main() {
+	program.RunMain(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+		if len(os.Args) != 2 {
+			return status.Error(codes.InvalidArgument, "Usage: bb_cache_miss_explainer bb_cache_miss_explainer.jsonnet")
+		}
+		var configuration bb_cache_miss_explainer.ApplicationConfiguration
+		if err := util.UnmarshalConfigurationFromFile(os.Args[1], &configuration); err != nil {
+			return util.StatusWrapf(err, "Failed to read configuration from %s", os.Args[1])
+		}
+		lifecycleState, grpcClientFactory, err := global.ApplyConfiguration(configuration.Global)
+		if err != nil {
+			return util.StatusWrap(err, "Failed to apply global configuration options")
+		}
+
+		info, err := blobstore_configuration.NewBlobAccessFromConfiguration(
+			dependenciesGroup,
+			configuration.ContentAddressableStorage,
+			blobstore_configuration.NewCASBlobAccessCreator(
+				grpcClientFactory,
+				int(configuration.MaximumMessageSizeBytes)))
+		if err != nil {
+			return util.StatusWrap(err, "Failed to create Content Addressable Storage")
+		}
+		directoryFetcher := cas.NewBlobAccessDirectoryFetcher(
+			info.BlobAccess,
+			/* maximumDirectorySizeBytes = */ int(configuration.MaximumMessageSizeBytes),
+			/* maximumTreeSizeBytes = */ 0)
+
+		evictionSet, err := eviction.NewSetFromConfiguration[digest.Digest](configuration.FingerprintReplacementPolicy)
+		if err != nil {
+			return util.StatusWrap(err, "Failed to create fingerprint eviction set")
+		}
+		server := cachemissexplainer.NewServer(
+			cachemissexplainer.NewBlobAccessFingerprinter(
+				info.BlobAccess,
+				int(configuration.MaximumMessageSizeBytes)),
+			cachemissexplainer.NewFingerprintStore(
+				int(configuration.MaximumFingerprints),
+				eviction.NewMetricsSet(evictionSet, "FingerprintStore")),
+			semaphore.NewWeighted(configuration.FingerprintingConcurrency),
+			directoryFetcher,
+			int(configuration.MaximumInputFileDifferences))
+
+		if err := bb_grpc.NewServersFromConfigurationAndServe(
+			configuration.GrpcServers,
+			func(s grpc.ServiceRegistrar) {
+				completedactionlogger.RegisterCompletedActionLoggerServer(s, server)
+				cachemissexplainer_pb.RegisterCacheMissExplainerServer(s, server)
+			},
+			siblingsGroup,
+		); err != nil {
+			return util.StatusWrap(err, "gRPC server failure")
+		}
+
+		lifecycleState.MarkReadyAndWait(siblingsGroup)
+		return nil
+	})
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/bb_conformance"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	blobstore_configuration "github.com/buildbarn/bb-storage/pkg/blobstore/configuration"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/global"
+	"github.com/buildbarn/bb-storage/pkg/program"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// This is a conformance test suite for deployments of Buildbarn. It
+// executes a series of actions that exercise edge cases of the Remote
+// Execution API against a scheduler and its workers, validates the
+// results, and prints a compliance report.
+
+// testEnvironment provides the facilities that test cases use to
+// upload inputs, execute actions and inspect their outputs.
+type testEnvironment struct {
+	contentAddressableStorage blobstore.BlobAccess
+	executionClient           remoteexecution.ExecutionClient
+	maximumMessageSizeBytes   int
+	digestFunction            digest.Function
+	platform                  *remoteexecution.Platform
+}
+
+// putBlob uploads a blob into the Content Addressable Storage.
+func (e *testEnvironment) putBlob(ctx context.Context, data []byte) (*remoteexecution.Digest, error) {
+	generator := e.digestFunction.NewGenerator(int64(len(data)))
+	generator.Write(data)
+	blobDigest := generator.Sum()
+	if err := e.contentAddressableStorage.Put(ctx, blobDigest, buffer.NewValidatedBufferFromByteSlice(data)); err != nil {
+		return nil, util.StatusWrap(err, "Failed to upload blob")
+	}
+	return blobDigest.GetProto(), nil
+}
+
+// putProto uploads a Protobuf message into the Content Addressable
+// Storage.
+func (e *testEnvironment) putProto(ctx context.Context, message proto.Message) (*remoteexecution.Digest, error) {
+	blobDigest, err := blobstore.CASPutProto(ctx, e.contentAddressableStorage, message, e.digestFunction)
+	if err != nil {
+		return nil, util.StatusWrap(err, "Failed to upload message")
+	}
+	return blobDigest.GetProto(), nil
+}
+
+// getBlob downloads a blob from the Content Addressable Storage.
+func (e *testEnvironment) getBlob(ctx context.Context, blobDigest *remoteexecution.Digest) ([]byte, error) {
+	d, err := e.digestFunction.NewDigestFromProto(blobDigest)
+	if err != nil {
+		return nil, err
+	}
+	return e.contentAddressableStorage.Get(ctx, d).ToByteSlice(e.maximumMessageSizeBytes)
+}
+
+// getProto downloads a Protobuf message from the Content Addressable
+// Storage.
+func (e *testEnvironment) getProto(ctx context.Context, blobDigest *remoteexecution.Digest, message proto.Message) (proto.Message, error) {
+	d, err := e.digestFunction.NewDigestFromProto(blobDigest)
+	if err != nil {
+		return nil, err
+	}
+	return e.contentAddressableStorage.Get(ctx, d).ToProto(message, e.maximumMessageSizeBytes)
+}
+
+// execute an action having a given command and input root, returning
+// the ActionResult. Execution failures are returned as errors.
+func (e *testEnvironment) execute(ctx context.Context, command *remoteexecution.Command, inputRootDigest *remoteexecution.Digest) (*remoteexecution.ActionResult, error) {
+	command.Platform = e.platform
+	commandDigest, err := e.putProto(ctx, command)
+	if err != nil {
+		return nil, util.StatusWrap(err, "Command")
+	}
+	actionDigest, err := e.putProto(ctx, &remoteexecution.Action{
+		CommandDigest:   commandDigest,
+		InputRootDigest: inputRootDigest,
+		DoNotCache:      true,
+		Platform:        e.platform,
+	})
+	if err != nil {
+		return nil, util.StatusWrap(err, "Action")
+	}
+
+	client, err := e.executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
+		InstanceName:    e.digestFunction.GetInstanceName().String(),
+		ActionDigest:    actionDigest,
+		SkipCacheLookup: true,
+		DigestFunction:  e.digestFunction.GetEnumValue(),
+	})
+	if err != nil {
+		return nil, util.StatusWrap(err, "Failed to start execution")
+	}
+	for {
+		operation, err := client.Recv()
+		if err != nil {
+			return nil, util.StatusWrap(err, "Failed to receive operation")
+		}
+		if operation.Done {
+			if err := status.ErrorProto(operation.GetError()); err != nil {
+				return nil, util.StatusWrap(err, "Operation failed")
+			}
+			var response remoteexecution.ExecuteResponse
+			if err := operation.GetResponse().UnmarshalTo(&response); err != nil {
+				return nil, util.StatusWrap(err, "Failed to unmarshal execute response")
+			}
+			if err := status.ErrorProto(response.Status); err != nil {
+				return nil, util.StatusWrap(err, "Execution failed")
+			}
+			if response.Result == nil {
+				return nil, status.Error(codes.Internal, "Execute response does not contain an action result")
+			}
+			return response.Result, nil
+		}
+	}
+}
+
+// executeExpectingSuccess is identical to execute(), except that it
+// also requires that the command terminated with exit code zero.
+func (e *testEnvironment) executeExpectingSuccess(ctx context.Context, command *remoteexecution.Command, inputRootDigest *remoteexecution.Digest) (*remoteexecution.ActionResult, error) {
+	actionResult, err := e.execute(ctx, command, inputRootDigest)
+	if err != nil {
+		return nil, err
+	}
+	if actionResult.ExitCode != 0 {
+		stderr, _ := e.getStandardError(ctx, actionResult)
+		return nil, status.Errorf(codes.FailedPrecondition, "Command terminated with exit code %d: %#v", actionResult.ExitCode, string(stderr))
+	}
+	return actionResult, nil
+}
+
+// getStandardError returns the data written to stderr by an action,
+// regardless of whether it was inlined or not.
+func (e *testEnvironment) getStandardError(ctx context.Context, actionResult *remoteexecution.ActionResult) ([]byte, error) {
+	if actionResult.StderrDigest != nil {
+		return e.getBlob(ctx, actionResult.StderrDigest)
+	}
+	return actionResult.StderrRaw, nil
+}
+
+// getStandardOutput returns the data written to stdout by an action,
+// regardless of whether it was inlined or not.
+func (e *testEnvironment) getStandardOutput(ctx context.Context, actionResult *remoteexecution.ActionResult) ([]byte, error) {
+	if actionResult.StdoutDigest != nil {
+		return e.getBlob(ctx, actionResult.StdoutDigest)
+	}
+	return actionResult.StdoutRaw, nil
+}
+
+// getOutputFile returns the contents of an output file of an action.
+func (e *testEnvironment) getOutputFile(ctx context.Context, actionResult *remoteexecution.ActionResult, path string) ([]byte, error) {
+	for _, outputFile := range actionResult.OutputFiles {
+		if outputFile.Path == path {
+			return e.getBlob(ctx, outputFile.Digest)
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "Action result does not contain output file %#v", path)
+}
+
+func main() {
+	program.RunMain(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+		if len(os.Args) != 2 {
+			return status.Error(codes.InvalidArgument, "Usage: bb_conformance bb_conformance.jsonnet")
+		}
+		var configuration bb_conformance.ApplicationConfiguration
+		if err := util.UnmarshalConfigurationFromFile(os.Args[1], &configuration); err != nil {
+			return util.StatusWrapf(err, "Failed to read configuration from %s", os.Args[1])
+		}
+		_, grpcClientFactory, err := global.ApplyConfiguration(configuration.Global)
+		if err != nil {
+			return util.StatusWrap(err, "Failed to apply global configuration options")
+		}
+
+		info, err := blobstore_configuration.NewBlobAccessFromConfiguration(
+			dependenciesGroup,
+			configuration.ContentAddressableStorage,
+			blobstore_configuration.NewCASBlobAccessCreator(
+				grpcClientFactory,
+				int(configuration.MaximumMessageSizeBytes)))
+		if err != nil {
+			return util.StatusWrap(err, "Failed to create Content Adddressable Storage")
+		}
+
+		schedulerConnection, err := grpcClientFactory.NewClientFromConfiguration(configuration.Scheduler)
+		if err != nil {
+			return util.StatusWrap(err, "Failed to create scheduler RPC client")
+		}
+
+		instanceName, err := digest.NewInstanceName(configuration.InstanceName)
+		if err != nil {
+			return util.StatusWrapf(err, "Invalid instance name %#v", configuration.InstanceName)
+		}
+		digestFunction, err := instanceName.GetDigestFunction(configuration.DigestFunction, 0)
+		if err != nil {
+			return util.StatusWrap(err, "Invalid digest function")
+		}
+
+		environment := &testEnvironment{
+			contentAddressableStorage: info.BlobAccess,
+			executionClient:           remoteexecution.NewExecutionClient(schedulerConnection),
+			maximumMessageSizeBytes:   int(configuration.MaximumMessageSizeBytes),
+			digestFunction:            digestFunction,
+			platform:                  configuration.Platform,
+		}
+
+		selectedTestCases := map[string]bool{}
+		for _, name := range configuration.TestCases {
+			selectedTestCases[name] = true
+		}
+
+		// Run all test cases sequentially, so that failures are
+		// easy to attribute, and print a compliance report.
+		passed, failed := 0, 0
+		for _, testCase := range testCases {
+			if len(selectedTestCases) > 0 && !selectedTestCases[testCase.name] {
+				continue
+			}
+			start := time.Now()
+			err := testCase.run(ctx, environment)
+			duration := time.Since(start).Round(time.Millisecond)
+			if err == nil {
+				fmt.Printf("PASS  %-32s %10s\n", testCase.name, duration)
+				passed++
+			} else {
+				fmt.Printf("FAIL  %-32s %10s  %s\n", testCase.name, duration, err)
+				failed++
+			}
+		}
+		fmt.Printf("\n%d passed, %d failed\n", passed, failed)
+		if failed > 0 {
+			return status.Errorf(codes.FailedPrecondition, "%d out of %d test cases failed", failed, passed+failed)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testCase is a single conformance test that is run against the
+// scheduler and its workers.
+type testCase struct {
+	name string
+	run  func(ctx context.Context, e *testEnvironment) error
+}
+
+// testCases contains the list of all conformance tests, in the order
+// in which they are run.
+var testCases = []testCase{
+	{name: "EmptyInputRoot", run: testEmptyInputRoot},
+	{name: "ExitCode", run: testExitCode},
+	{name: "StandardOutputAndError", run: testStandardOutputAndError},
+	{name: "EnvironmentVariables", run: testEnvironmentVariables},
+	{name: "WorkingDirectory", run: testWorkingDirectory},
+	{name: "UnicodeFilenames", run: testUnicodeFilenames},
+	{name: "HugeArgv", run: testHugeArgv},
+	{name: "DuplicateBlobs", run: testDuplicateBlobs},
+	{name: "OutputDirectory", run: testOutputDirectory},
+	{name: "SymlinkOutput", run: testSymlinkOutput},
+}
+
+// putDirectoryWithFiles uploads a Directory message containing files
+// with the provided contents.
+func (e *testEnvironment) putDirectoryWithFiles(ctx context.Context, files map[string][]byte) (*remoteexecution.Digest, error) {
+	directory := &remoteexecution.Directory{}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	// Directory entries must be sorted by name.
+	sort.Strings(names)
+	for _, name := range names {
+		fileDigest, err := e.putBlob(ctx, files[name])
+		if err != nil {
+			return nil, util.StatusWrapf(err, "File %#v", name)
+		}
+		directory.Files = append(directory.Files, &remoteexecution.FileNode{
+			Name:   name,
+			Digest: fileDigest,
+		})
+	}
+	return e.putProto(ctx, directory)
+}
+
+func testEmptyInputRoot(ctx context.Context, e *testEnvironment) error {
+	inputRootDigest, err := e.putProto(ctx, &remoteexecution.Directory{})
+	if err != nil {
+		return err
+	}
+	_, err = e.executeExpectingSuccess(ctx, &remoteexecution.Command{
+		Arguments: []string{"true"},
+	}, inputRootDigest)
+	return err
+}
+
+func testExitCode(ctx context.Context, e *testEnvironment) error {
+	inputRootDigest, err := e.putProto(ctx, &remoteexecution.Directory{})
+	if err != nil {
+		return err
+	}
+	actionResult, err := e.execute(ctx, &remoteexecution.Command{
+		Arguments: []string{"sh", "-c", "exit 3"},
+	}, inputRootDigest)
+	if err != nil {
+		return err
+	}
+	if actionResult.ExitCode != 3 {
+		return status.Errorf(codes.FailedPrecondition, "Expected exit code 3, got %d", actionResult.ExitCode)
+	}
+	return nil
+}
+
+func testStandardOutputAndError(ctx context.Context, e *testEnvironment) error {
+	inputRootDigest, err := e.putProto(ctx, &remoteexecution.Directory{})
+	if err != nil {
+		return err
+	}
+	actionResult, err := e.executeExpectingSuccess(ctx, &remoteexecution.Command{
+		Arguments: []string{"sh", "-c", "echo stdout; echo stderr >&2"},
+	}, inputRootDigest)
+	if err != nil {
+		return err
+	}
+	stdout, err := e.getStandardOutput(ctx, actionResult)
+	if err != nil {
+		return util.StatusWrap(err, "Failed to obtain stdout")
+	}
+	if string(stdout) != "stdout\n" {
+		return status.Errorf(codes.FailedPrecondition, "Unexpected stdout: %#v", string(stdout))
+	}
+	stderr, err := e.getStandardError(ctx, actionResult)
+	if err != nil {
+		return util.StatusWrap(err, "Failed to obtain stderr")
+	}
+	if string(stderr) != "stderr\n" {
+		return status.Errorf(codes.FailedPrecondition, "Unexpected stderr: %#v", string(stderr))
+	}
+	return nil
+}
+
+func testEnvironmentVariables(ctx context.Context, e *testEnvironment) error {
+	inputRootDigest, err := e.putProto(ctx, &remoteexecution.Directory{})
+	if err != nil {
+		return err
+	}
+	actionResult, err := e.executeExpectingSuccess(ctx, &remoteexecution.Command{
+		Arguments: []string{"sh", "-c", "printf '%s' \"$CONFORMANCE\""},
+		EnvironmentVariables: []*remoteexecution.Command_EnvironmentVariable{
+			{Name: "CONFORMANCE", Value: "a b=c"},
+		},
+	}, inputRootDigest)
+	if err != nil {
+		return err
+	}
+	stdout, err := e.getStandardOutput(ctx, actionResult)
+	if err != nil {
+		return util.StatusWrap(err, "Failed to obtain stdout")
+	}
+	if string(stdout) != "a b=c" {
+		return status.Errorf(codes.FailedPrecondition, "Unexpected value of environment variable: %#v", string(stdout))
+	}
+	return nil
+}
+
+func testWorkingDirectory(ctx context.Context, e *testEnvironment) error {
+	subdirectoryDigest, err := e.putDirectoryWithFiles(ctx, map[string][]byte{
+		"input": []byte("Hello"),
+	})
+	if err != nil {
+		return err
+	}
+	inputRootDigest, err := e.putProto(ctx, &remoteexecution.Directory{
+		Directories: []*remoteexecution.DirectoryNode{
+			{Name: "subdirectory", Digest: subdirectoryDigest},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	actionResult, err := e.executeExpectingSuccess(ctx, &remoteexecution.Command{
+		Arguments:        []string{"cp", "input", "output"},
+		WorkingDirectory: "subdirectory",
+		OutputPaths:      []string{"output"},
+	}, inputRootDigest)
+	if err != nil {
+		return err
+	}
+	// Output paths are relative to the working directory.
+	output, err := e.getOutputFile(ctx, actionResult, "output")
+	if err != nil {
+		return err
+	}
+	if string(output) != "Hello" {
+		return status.Errorf(codes.FailedPrecondition, "Unexpected output file contents: %#v", string(output))
+	}
+	return nil
+}
+
+func testUnicodeFilenames(ctx context.Context, e *testEnvironment) error {
+	const inputName = "été 日本 \U0001f600"
+	const outputName = "результат"
+	inputRootDigest, err := e.putDirectoryWithFiles(ctx, map[string][]byte{
+		inputName: []byte("Unicode"),
+	})
+	if err != nil {
+		return err
+	}
+	actionResult, err := e.executeExpectingSuccess(ctx, &remoteexecution.Command{
+		Arguments:   []string{"cp", inputName, outputName},
+		OutputPaths: []string{outputName},
+	}, inputRootDigest)
+	if err != nil {
+		return err
+	}
+	output, err := e.getOutputFile(ctx, actionResult, outputName)
+	if err != nil {
+		return err
+	}
+	if string(output) != "Unicode" {
+		return status.Errorf(codes.FailedPrecondition, "Unexpected output file contents: %#v", string(output))
+	}
+	return nil
+}
+
+func testHugeArgv(ctx context.Context, e *testEnvironment) error {
+	inputRootDigest, err := e.putProto(ctx, &remoteexecution.Directory{})
+	if err != nil {
+		return err
+	}
+	// Provide approximately 1 MiB of arguments, which exceeds the
+	// limits of naive implementations that pass arguments through
+	// a single buffer.
+	const argumentsCount = 16384
+	arguments := []string{"sh", "-c", "echo $#", "sh"}
+	argument := strings.Repeat("x", 63)
+	for i := 0; i < argumentsCount; i++ {
+		arguments = append(arguments, argument)
+	}
+	actionResult, err := e.executeExpectingSuccess(ctx, &remoteexecution.Command{
+		Arguments: arguments,
+	}, inputRootDigest)
+	if err != nil {
+		return err
+	}
+	stdout, err := e.getStandardOutput(ctx, actionResult)
+	if err != nil {
+		return util.StatusWrap(err, "Failed to obtain stdout")
+	}
+	if got := strings.TrimSpace(string(stdout)); got != strconv.Itoa(argumentsCount) {
+		return status.Errorf(codes.FailedPrecondition, "Expected %d arguments, got %#v", argumentsCount, got)
+	}
+	return nil
+}
+
+func testDuplicateBlobs(ctx context.Context, e *testEnvironment) error {
+	// Reference the same blob from multiple files and directories,
+	// and the same directory from multiple places in the input root.
+	contents := []byte("Duplicate")
+	subdirectoryDigest, err := e.putDirectoryWithFiles(ctx, map[string][]byte{
+		"a": contents,
+		"b": contents,
+	})
+	if err != nil {
+		return err
+	}
+	inputRootDigest, err := e.putProto(ctx, &remoteexecution.Directory{
+		Directories: []*remoteexecution.DirectoryNode{
+			{Name: "x", Digest: subdirectoryDigest},
+			{Name: "y", Digest: subdirectoryDigest},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	actionResult, err := e.executeExpectingSuccess(ctx, &remoteexecution.Command{
+		Arguments:   []string{"sh", "-c", "cat x/a x/b y/a y/b > output"},
+		OutputPaths: []string{"output"},
+	}, inputRootDigest)
+	if err != nil {
+		return err
+	}
+	output, err := e.getOutputFile(ctx, actionResult, "output")
+	if err != nil {
+		return err
+	}
+	if expected := bytes.Repeat(contents, 4); !bytes.Equal(output, expected) {
+		return status.Errorf(codes.FailedPrecondition, "Unexpected output file contents: %#v", string(output))
+	}
+	return nil
+}
+
+func testOutputDirectory(ctx context.Context, e *testEnvironment) error {
+	inputRootDigest, err := e.putProto(ctx, &remoteexecution.Directory{})
+	if err != nil {
+		return err
+	}
+	actionResult, err := e.executeExpectingSuccess(ctx, &remoteexecution.Command{
+		Arguments:   []string{"sh", "-c", "mkdir -p output/empty output/nested && echo Hello > output/nested/file"},
+		OutputPaths: []string{"output"},
+	}, inputRootDigest)
+	if err != nil {
+		return err
+	}
+	for _, outputDirectory := range actionResult.OutputDirectories {
+		if outputDirectory.Path != "output" {
+			continue
+		}
+		var tree remoteexecution.Tree
+		if _, err := e.getProto(ctx, outputDirectory.TreeDigest, &tree); err != nil {
+			return util.StatusWrap(err, "Failed to obtain tree")
+		}
+		if names := fmt.Sprint(directoryNodeNames(tree.Root)); names != "[empty nested]" {
+			return status.Errorf(codes.FailedPrecondition, "Unexpected directories in output directory: %s", names)
+		}
+		if len(tree.Children) < 2 {
+			return status.Errorf(codes.FailedPrecondition, "Expected tree to contain at least 2 children, got %d", len(tree.Children))
+		}
+		return nil
+	}
+	return status.Error(codes.NotFound, "Action result does not contain output directory \"output\"")
+}
+
+func testSymlinkOutput(ctx context.Context, e *testEnvironment) error {
+	inputRootDigest, err := e.putProto(ctx, &remoteexecution.Directory{})
+	if err != nil {
+		return err
+	}
+	actionResult, err := e.executeExpectingSuccess(ctx, &remoteexecution.Command{
+		Arguments:   []string{"ln", "-s", "../target", "link"},
+		OutputPaths: []string{"link"},
+	}, inputRootDigest)
+	if err != nil {
+		return err
+	}
+	// Workers implementing older versions of the protocol may
+	// report the symlink through output_file_symlinks.
+	for _, outputSymlinks := range [][]*remoteexecution.OutputSymlink{
+		actionResult.OutputSymlinks,
+		actionResult.OutputFileSymlinks,
+	} {
+		for _, outputSymlink := range outputSymlinks {
+			if outputSymlink.Path == "link" {
+				if outputSymlink.Target != "../target" {
+					return status.Errorf(codes.FailedPrecondition, "Unexpected symlink target: %#v", outputSymlink.Target)
+				}
+				return nil
+			}
+		}
+	}
+	return status.Error(codes.NotFound, "Action result does not contain output symlink \"link\"")
+}
+
+func directoryNodeNames(directory *remoteexecution.Directory) []string {
+	var names []string
+	for _, directoryNode := range directory.GetDirectories() {
+		names = append(names, directoryNode.Name)
+	}
+	return names
+}
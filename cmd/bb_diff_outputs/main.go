@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/cas"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/bb_diff_outputs"
+	blobstore_configuration "github.com/buildbarn/bb-storage/pkg/blobstore/configuration"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/global"
+	"github.com/buildbarn/bb-storage/pkg/program"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// This is a tool for comparing the results of two actions stored in
+// the Action Cache. It prints the exit codes and output streams of
+// both actions, followed by a list of output paths whose contents
+// differ. This is useful for debugging nondeterministic build actions,
+// which cause unexpected cache misses for actions depending on them.
+
+// parseActionDigest parses an action digest that is provided on the
+// command line in the form "${hash}-${size}".
+func parseActionDigest(digestFunction digest.Function, s string) (digest.Digest, error) {
+	separator := strings.LastIndexByte(s, '-')
+	if separator < 0 {
+		return digest.BadDigest, status.Errorf(codes.InvalidArgument, "Action digest %#v is not of the form ${hash}-${size}", s)
+	}
+	sizeBytes, err := strconv.ParseInt(s[separator+1:], 10, 64)
+	if err != nil {
+		return digest.BadDigest, status.Errorf(codes.InvalidArgument, "Action digest %#v has an invalid size", s)
+	}
+	return digestFunction.NewDigest(s[:separator], sizeBytes)
+}
+
+// formatOutputEntry returns a textual summary of an output.
+func formatOutputEntry(entry *cas.OutputEntry) string {
+	switch entry.Kind {
+	case cas.OutputEntryKindFile:
+		if entry.IsExecutable {
+			return fmt.Sprintf("executable file %s-%d", entry.Digest.GetHashString(), entry.Digest.GetSizeBytes())
+		}
+		return fmt.Sprintf("file %s-%d", entry.Digest.GetHashString(), entry.Digest.GetSizeBytes())
+	case cas.OutputEntryKindDirectory:
+		return "directory"
+	case cas.OutputEntryKindSymlink:
+		return fmt.Sprintf("symbolic link to %#v", entry.SymlinkTarget)
+	default:
+		panic("Unknown output entry kind")
+	}
+}
+
+// formatOutputStream returns a textual summary of an output stream of
+// an action, which may either be stored in the CAS or inline.
+func formatOutputStream(streamDigest *remoteexecution.Digest, streamRaw []byte) string {
+	if streamDigest != nil {
+		return fmt.Sprintf("%s-%d", streamDigest.Hash, streamDigest.SizeBytes)
+	}
+	return fmt.Sprintf("%d bytes inline", len(streamRaw))
+}
+
+func main() {
+	program.RunMain(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+		if len(os.Args) != 4 {
+			return status.Error(codes.InvalidArgument, "Usage: bb_diff_outputs bb_diff_outputs.jsonnet ${old_action_hash}-${size} ${new_action_hash}-${size}")
+		}
+		var configuration bb_diff_outputs.ApplicationConfiguration
+		if err := util.UnmarshalConfigurationFromFile(os.Args[1], &configuration); err != nil {
+			return util.StatusWrapf(err, "Failed to read configuration from %s", os.Args[1])
+		}
+		_, grpcClientFactory, err := global.ApplyConfiguration(configuration.Global)
+		if err != nil {
+			return util.StatusWrap(err, "Failed to apply global configuration options")
+		}
+
+		contentAddressableStorage, actionCache, err := blobstore_configuration.NewCASAndACBlobAccessFromConfiguration(
+			dependenciesGroup,
+			configuration.Blobstore,
+			grpcClientFactory,
+			int(configuration.MaximumMessageSizeBytes))
+		if err != nil {
+			return err
+		}
+		directoryFetcher := cas.NewBlobAccessDirectoryFetcher(
+			contentAddressableStorage,
+			/* maximumDirectorySizeBytes = */ int(configuration.MaximumMessageSizeBytes),
+			/* maximumTreeSizeBytes = */ configuration.MaximumMessageSizeBytes)
+
+		instanceName, err := digest.NewInstanceName(configuration.InstanceName)
+		if err != nil {
+			return util.StatusWrapf(err, "Invalid instance name %#v", configuration.InstanceName)
+		}
+		digestFunction, err := instanceName.GetDigestFunction(configuration.DigestFunction, 0)
+		if err != nil {
+			return util.StatusWrap(err, "Invalid digest function")
+		}
+
+		// Load the action results of both actions.
+		var actionResults [2]*remoteexecution.ActionResult
+		for i, arg := range os.Args[2:] {
+			actionDigest, err := parseActionDigest(digestFunction, arg)
+			if err != nil {
+				return err
+			}
+			m, err := actionCache.Get(ctx, actionDigest).ToProto(&remoteexecution.ActionResult{}, int(configuration.MaximumMessageSizeBytes))
+			if err != nil {
+				return util.StatusWrapf(err, "Failed to obtain action result for action %#v", arg)
+			}
+			actionResults[i] = m.(*remoteexecution.ActionResult)
+		}
+		oldActionResult, newActionResult := actionResults[0], actionResults[1]
+
+		if oldActionResult.ExitCode != newActionResult.ExitCode {
+			fmt.Printf("Exit code: %d -> %d\n", oldActionResult.ExitCode, newActionResult.ExitCode)
+		}
+		if oldStdout, newStdout := formatOutputStream(oldActionResult.StdoutDigest, oldActionResult.StdoutRaw), formatOutputStream(newActionResult.StdoutDigest, newActionResult.StdoutRaw); oldStdout != newStdout || !bytes.Equal(oldActionResult.StdoutRaw, newActionResult.StdoutRaw) {
+			fmt.Printf("Standard output: %s -> %s\n", oldStdout, newStdout)
+		}
+		if oldStderr, newStderr := formatOutputStream(oldActionResult.StderrDigest, oldActionResult.StderrRaw), formatOutputStream(newActionResult.StderrDigest, newActionResult.StderrRaw); oldStderr != newStderr || !bytes.Equal(oldActionResult.StderrRaw, newActionResult.StderrRaw) {
+			fmt.Printf("Standard error: %s -> %s\n", oldStderr, newStderr)
+		}
+
+		differences, err := cas.DiffActionResultOutputs(ctx, directoryFetcher, digestFunction, oldActionResult, newActionResult)
+		if err != nil {
+			return err
+		}
+		for _, difference := range differences {
+			if difference.Old == nil {
+				fmt.Printf("+ %s: %s\n", difference.Path, formatOutputEntry(difference.New))
+			} else if difference.New == nil {
+				fmt.Printf("- %s: %s\n", difference.Path, formatOutputEntry(difference.Old))
+			} else {
+				fmt.Printf("~ %s: %s -> %s\n", difference.Path, formatOutputEntry(difference.Old), formatOutputEntry(difference.New))
+			}
+		}
+		fmt.Printf("%d output paths differ\n", len(differences))
+		return nil
+	})
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/bb_execution_bench"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	blobstore_configuration "github.com/buildbarn/bb-storage/pkg/blobstore/configuration"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/global"
+	"github.com/buildbarn/bb-storage/pkg/program"
+	"github.com/buildbarn/bb-storage/pkg/random"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// This is a load generation tool for Buildbarn deployments. It
+// synthesizes actions according to a configurable mix of input sizes,
+// durations and output sizes, executes them against a scheduler, and
+// reports latency percentiles for each of the stages of execution.
+// This permits capacity planning without requiring a Bazel workspace.
+
+// stage names, in the order in which they are reported.
+var stages = []string{
+	"UploadInputs",
+	"Queued",
+	"FetchingInputs",
+	"Executing",
+	"UploadingOutputs",
+	"Total",
+}
+
+// benchmark holds the state of a single run of bb_execution_bench.
+type benchmark struct {
+	contentAddressableStorage blobstore.BlobAccess
+	executionClient           remoteexecution.ExecutionClient
+	digestFunction            digest.Function
+	platform                  *remoteexecution.Platform
+	actionKinds               []*bb_execution_bench.ActionKindConfiguration
+	totalWeight               uint64
+
+	lock       sync.Mutex
+	latencies  map[string][]time.Duration
+	failures   map[codes.Code]int
+	executions int
+}
+
+// pickActionKind randomly picks a kind of action to synthesize,
+// respecting the configured weights.
+func (b *benchmark) pickActionKind(generator random.SingleThreadedGenerator) *bb_execution_bench.ActionKindConfiguration {
+	n := uint64(generator.Int63n(int64(b.totalWeight)))
+	for _, actionKind := range b.actionKinds {
+		if n < uint64(actionKind.Weight) {
+			return actionKind
+		}
+		n -= uint64(actionKind.Weight)
+	}
+	panic("Weights of action kinds are inconsistent")
+}
+
+// uploadAction synthesizes an action of a given kind, and uploads all
+// of its inputs into the Content Addressable Storage.
+func (b *benchmark) uploadAction(ctx context.Context, generator random.SingleThreadedGenerator, actionKind *bb_execution_bench.ActionKindConfiguration) (digest.Digest, error) {
+	// Generate input files having random contents. This ensures
+	// that every action has a unique input root, and that actions
+	// aren't deduplicated by the scheduler.
+	var inputRoot remoteexecution.Directory
+	for i := uint32(0); i < actionKind.InputFilesCount; i++ {
+		data := make([]byte, actionKind.InputFileSizeBytes)
+		generator.Read(data)
+		fileDigest := b.digestFunction.NewGenerator(int64(len(data)))
+		fileDigest.Write(data)
+		d := fileDigest.Sum()
+		if err := b.contentAddressableStorage.Put(ctx, d, buffer.NewValidatedBufferFromByteSlice(data)); err != nil {
+			return digest.BadDigest, util.StatusWrapf(err, "Failed to upload input file %d", i)
+		}
+		inputRoot.Files = append(inputRoot.Files, &remoteexecution.FileNode{
+			Name:   fmt.Sprintf("input%08d", i),
+			Digest: d.GetProto(),
+		})
+	}
+	inputRootDigest, err := blobstore.CASPutProto(ctx, b.contentAddressableStorage, &inputRoot, b.digestFunction)
+	if err != nil {
+		return digest.BadDigest, util.StatusWrap(err, "Failed to upload input root")
+	}
+
+	commandDigest, err := blobstore.CASPutProto(ctx, b.contentAddressableStorage, &remoteexecution.Command{
+		Arguments: []string{
+			"sh",
+			"-c",
+			fmt.Sprintf(
+				"sleep %f && head -c %d /dev/urandom > output",
+				actionKind.Duration.AsDuration().Seconds(),
+				actionKind.OutputSizeBytes),
+		},
+		OutputPaths: []string{"output"},
+		Platform:    b.platform,
+	}, b.digestFunction)
+	if err != nil {
+		return digest.BadDigest, util.StatusWrap(err, "Failed to upload command")
+	}
+
+	actionDigest, err := blobstore.CASPutProto(ctx, b.contentAddressableStorage, &remoteexecution.Action{
+		CommandDigest:   commandDigest.GetProto(),
+		InputRootDigest: inputRootDigest.GetProto(),
+		DoNotCache:      true,
+		Platform:        b.platform,
+	}, b.digestFunction)
+	if err != nil {
+		return digest.BadDigest, util.StatusWrap(err, "Failed to upload action")
+	}
+	return actionDigest, nil
+}
+
+// executeAction executes a previously uploaded action, waiting for it
+// to complete.
+func (b *benchmark) executeAction(ctx context.Context, actionDigest digest.Digest) (*remoteexecution.ExecuteResponse, error) {
+	client, err := b.executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
+		InstanceName:   b.digestFunction.GetInstanceName().String(),
+		ActionDigest:   actionDigest.GetProto(),
+		DigestFunction: b.digestFunction.GetEnumValue(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for {
+		operation, err := client.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if operation.Done {
+			if err := status.ErrorProto(operation.GetError()); err != nil {
+				return nil, err
+			}
+			var response remoteexecution.ExecuteResponse
+			if err := operation.GetResponse().UnmarshalTo(&response); err != nil {
+				return nil, util.StatusWrap(err, "Failed to unmarshal execute response")
+			}
+			return &response, nil
+		}
+	}
+}
+
+// recordLatencyLocked adds a latency for a given stage.
+func (b *benchmark) recordLatencyLocked(stage string, d time.Duration) {
+	b.latencies[stage] = append(b.latencies[stage], d)
+}
+
+// recordWorkerLatencyLocked adds the latency of a stage as reported
+// by the worker, if the timestamps are provided.
+func (b *benchmark) recordWorkerLatencyLocked(stage string, start, completed *timestamppb.Timestamp) {
+	if start != nil && completed != nil {
+		b.recordLatencyLocked(stage, completed.AsTime().Sub(start.AsTime()))
+	}
+}
+
+// runAction synthesizes, uploads and executes a single action.
+func (b *benchmark) runAction(ctx context.Context, generator random.SingleThreadedGenerator) {
+	actionKind := b.pickActionKind(generator)
+
+	uploadStart := time.Now()
+	actionDigest, err := b.uploadAction(ctx, generator, actionKind)
+	executionStart := time.Now()
+	var response *remoteexecution.ExecuteResponse
+	if err == nil {
+		response, err = b.executeAction(ctx, actionDigest)
+		if err == nil {
+			err = status.ErrorProto(response.Status)
+		}
+	}
+	completed := time.Now()
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.executions++
+	if err != nil {
+		b.failures[status.Code(err)]++
+		log.Printf("Failed to execute action of kind %#v: %s", actionKind.Name, err)
+		return
+	}
+	b.recordLatencyLocked("UploadInputs", executionStart.Sub(uploadStart))
+	if metadata := response.Result.GetExecutionMetadata(); metadata != nil {
+		b.recordWorkerLatencyLocked("Queued", metadata.QueuedTimestamp, metadata.WorkerStartTimestamp)
+		b.recordWorkerLatencyLocked("FetchingInputs", metadata.InputFetchStartTimestamp, metadata.InputFetchCompletedTimestamp)
+		b.recordWorkerLatencyLocked("Executing", metadata.ExecutionStartTimestamp, metadata.ExecutionCompletedTimestamp)
+		b.recordWorkerLatencyLocked("UploadingOutputs", metadata.OutputUploadStartTimestamp, metadata.OutputUploadCompletedTimestamp)
+	}
+	b.recordLatencyLocked("Total", completed.Sub(uploadStart))
+}
+
+// getPercentile returns a percentile of a sorted list of durations.
+func getPercentile(latencies []time.Duration, percentile int) time.Duration {
+	return latencies[(len(latencies)-1)*percentile/100]
+}
+
+// printReport writes the latency percentiles of every stage to
+// standard output.
+func (b *benchmark) printReport(wallTime time.Duration) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	fmt.Printf("Executed %d actions in %s (%.2f actions/s)\n", b.executions, wallTime, float64(b.executions)/wallTime.Seconds())
+	failureCodes := make([]codes.Code, 0, len(b.failures))
+	for code := range b.failures {
+		failureCodes = append(failureCodes, code)
+	}
+	sort.Slice(failureCodes, func(i, j int) bool { return failureCodes[i] < failureCodes[j] })
+	for _, code := range failureCodes {
+		fmt.Printf("Failed with %s: %d\n", code, b.failures[code])
+	}
+
+	fmt.Printf("\n%-20s %8s %14s %14s %14s %14s\n", "Stage", "Count", "p50", "p90", "p99", "Max")
+	for _, stage := range stages {
+		latencies := b.latencies[stage]
+		if len(latencies) == 0 {
+			continue
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		fmt.Printf(
+			"%-20s %8d %14s %14s %14s %14s\n",
+			stage,
+			len(latencies),
+			getPercentile(latencies, 50),
+			getPercentile(latencies, 90),
+			getPercentile(latencies, 99),
+			latencies[len(latencies)-1])
+	}
+}
+
+func main() {
+	program.RunMain(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+		if len(os.Args) != 2 {
+			return status.Error(codes.InvalidArgument, "Usage: bb_execution_bench bb_execution_bench.jsonnet")
+		}
+		var configuration bb_execution_bench.ApplicationConfiguration
+		if err := util.UnmarshalConfigurationFromFile(os.Args[1], &configuration); err != nil {
+			return util.StatusWrapf(err, "Failed to read configuration from %s", os.Args[1])
+		}
+		_, grpcClientFactory, err := global.ApplyConfiguration(configuration.Global)
+		if err != nil {
+			return util.StatusWrap(err, "Failed to apply global configuration options")
+		}
+
+		info, err := blobstore_configuration.NewBlobAccessFromConfiguration(
+			dependenciesGroup,
+			configuration.ContentAddressableStorage,
+			blobstore_configuration.NewCASBlobAccessCreator(
+				grpcClientFactory,
+				int(configuration.MaximumMessageSizeBytes)))
+		if err != nil {
+			return util.StatusWrap(err, "Failed to create Content Adddressable Storage")
+		}
+
+		schedulerConnection, err := grpcClientFactory.NewClientFromConfiguration(configuration.Scheduler)
+		if err != nil {
+			return util.StatusWrap(err, "Failed to create scheduler RPC client")
+		}
+
+		instanceName, err := digest.NewInstanceName(configuration.InstanceName)
+		if err != nil {
+			return util.StatusWrapf(err, "Invalid instance name %#v", configuration.InstanceName)
+		}
+		digestFunction, err := instanceName.GetDigestFunction(configuration.DigestFunction, 0)
+		if err != nil {
+			return util.StatusWrap(err, "Invalid digest function")
+		}
+
+		if configuration.Concurrency == 0 {
+			return status.Error(codes.InvalidArgument, "Concurrency must be positive")
+		}
+		var totalWeight uint64
+		for i, actionKind := range configuration.ActionKinds {
+			if err := actionKind.Duration.CheckValid(); err != nil {
+				return util.StatusWrapf(err, "Invalid duration for action kind at index %d", i)
+			}
+			totalWeight += uint64(actionKind.Weight)
+		}
+		if totalWeight == 0 {
+			return status.Error(codes.InvalidArgument, "At least one action kind with a positive weight must be provided")
+		}
+
+		b := &benchmark{
+			contentAddressableStorage: info.BlobAccess,
+			executionClient:           remoteexecution.NewExecutionClient(schedulerConnection),
+			digestFunction:            digestFunction,
+			platform:                  configuration.Platform,
+			actionKinds:               configuration.ActionKinds,
+			totalWeight:               totalWeight,
+			latencies:                 map[string][]time.Duration{},
+			failures:                  map[codes.Code]int{},
+		}
+
+		// Launch a fixed number of goroutines that each execute
+		// actions sequentially, until the desired number of
+		// actions has been executed.
+		var actionsStarted atomic.Uint64
+		var wg sync.WaitGroup
+		start := time.Now()
+		for i := uint32(0); i < configuration.Concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				generator := random.NewFastSingleThreadedGenerator()
+				for ctx.Err() == nil && actionsStarted.Add(1) <= configuration.ActionsCount {
+					b.runAction(ctx, generator)
+				}
+			}()
+		}
+		wg.Wait()
+		b.printReport(time.Since(start))
+		return nil
+	})
+}
@@ -82,7 +82,7 @@ func main() {
 			instanceNamePrefix,
 			configuration.Platform,
 			0)
-		builder.LaunchWorkerThread(siblingsGroup, buildClient, "noop")
+		builder.LaunchWorkerThread(siblingsGroup, buildClient, "noop", nil)
 
 		lifecycleState.MarkReadyAndWait(siblingsGroup)
 		return nil
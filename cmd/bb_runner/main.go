@@ -3,6 +3,9 @@ package main
 import (
 	"context"
 	"os"
+	"regexp"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/buildbarn/bb-remote-execution/pkg/cleaner"
@@ -12,6 +15,7 @@ import (
 	runner_pb "github.com/buildbarn/bb-remote-execution/pkg/proto/runner"
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/tmp_installer"
 	"github.com/buildbarn/bb-remote-execution/pkg/runner"
+	"github.com/buildbarn/bb-storage/pkg/clock"
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
 	"github.com/buildbarn/bb-storage/pkg/global"
@@ -63,11 +67,94 @@ func main() {
 			commandCreator = runner.NewPlainCommandCreator(sysProcAttr)
 		}
 
+		var cpuTimeLimit *runner.CPUTimeLimitConfiguration
+		if cpuTimeLimitConfiguration := configuration.CpuTimeLimit; cpuTimeLimitConfiguration != nil {
+			if defaultLimit := cpuTimeLimitConfiguration.DefaultLimit; defaultLimit != nil {
+				if err := defaultLimit.CheckValid(); err != nil {
+					return util.StatusWrap(err, "Invalid default CPU time limit")
+				}
+			}
+			defaultLimit := cpuTimeLimitConfiguration.DefaultLimit.AsDuration()
+			var maximumLimit time.Duration
+			if cpuTimeLimitConfiguration.PlatformProperty != "" {
+				if err := cpuTimeLimitConfiguration.MaximumLimit.CheckValid(); err != nil {
+					return util.StatusWrap(err, "Invalid maximum CPU time limit")
+				}
+				maximumLimit = cpuTimeLimitConfiguration.MaximumLimit.AsDuration()
+				if maximumLimit < defaultLimit {
+					return status.Error(codes.InvalidArgument, "Maximum CPU time limit must not be smaller than the default CPU time limit")
+				}
+			}
+			maximumCPUs := int(cpuTimeLimitConfiguration.MaximumCpus)
+			if maximumCPUs == 0 {
+				maximumCPUs = runtime.NumCPU()
+			}
+			cpuTimeLimit = &runner.CPUTimeLimitConfiguration{
+				CgroupParentPath: cpuTimeLimitConfiguration.CgroupParentPath,
+				PlatformProperty: cpuTimeLimitConfiguration.PlatformProperty,
+				DefaultLimit:     defaultLimit,
+				MaximumLimit:     maximumLimit,
+				MaximumCPUs:      maximumCPUs,
+				Clock:            clock.SystemClock,
+			}
+		}
+
 		r := runner.NewLocalRunner(
 			buildDirectory,
 			buildDirectoryPath,
 			commandCreator,
-			configuration.SetTmpdirEnvironmentVariable)
+			configuration.SetTmpdirEnvironmentVariable,
+			cpuTimeLimit)
+
+		// Optionally run actions inside WSL distributions or
+		// containers, based on their platform properties.
+		if guestEnvironment := configuration.GuestEnvironment; guestEnvironment != nil {
+			wslPath := guestEnvironment.WslPath
+			if wslPath == "" {
+				wslPath = "wsl.exe"
+			}
+			containerRuntimePath := guestEnvironment.ContainerRuntimePath
+			if containerRuntimePath == "" {
+				containerRuntimePath = "docker.exe"
+			}
+			hostEnvironmentVariables := map[string]string{}
+			for _, environmentVariable := range os.Environ() {
+				if name, value, ok := strings.Cut(environmentVariable, "="); ok && name != "" {
+					hostEnvironmentVariables[name] = value
+				}
+			}
+			var allowedWSLDistributions *regexp.Regexp
+			if pattern := guestEnvironment.AllowedWslDistributionsPattern; pattern != "" {
+				allowedWSLDistributions, err = regexp.Compile("^(?:" + pattern + ")$")
+				if err != nil {
+					return util.StatusWrapWithCode(err, codes.InvalidArgument, "Invalid allowed WSL distributions pattern")
+				}
+			}
+			var allowedContainerImages *regexp.Regexp
+			if pattern := guestEnvironment.AllowedContainerImagesPattern; pattern != "" {
+				allowedContainerImages, err = regexp.Compile("^(?:" + pattern + ")$")
+				if err != nil {
+					return util.StatusWrapWithCode(err, codes.InvalidArgument, "Invalid allowed container images pattern")
+				}
+			}
+			r, err = runner.NewGuestEnvironmentRunner(r, runner.GuestEnvironmentConfiguration{
+				BuildDirectoryPath:              configuration.BuildDirectoryPath,
+				HostEnvironmentVariables:        hostEnvironmentVariables,
+				WSLDistributionPlatformProperty: guestEnvironment.WslDistributionPlatformProperty,
+				WSLPath:                         wslPath,
+				AllowedWSLDistributions:         allowedWSLDistributions,
+				ContainerImagePlatformProperty:  guestEnvironment.ContainerImagePlatformProperty,
+				ContainerRuntimePath:            containerRuntimePath,
+				ContainerBuildDirectoryPath:     guestEnvironment.ContainerBuildDirectoryPath,
+				WindowsContainers:               guestEnvironment.WindowsContainers,
+				AllowedContainerImages:          allowedContainerImages,
+				CPUTimeLimit:                    cpuTimeLimit,
+				ContainerCgroupParent:           guestEnvironment.ContainerCgroupParent,
+			})
+			if err != nil {
+				return util.StatusWrap(err, "Failed to create guest environment runner")
+			}
+		}
 
 		// Let bb_runner replace temporary directories with symbolic
 		// links pointing to the temporary directory set up by
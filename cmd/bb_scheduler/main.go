@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"log"
 	"net/url"
 	"os"
 	"path"
@@ -9,12 +10,15 @@ import (
 	"time"
 
 	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	remotelogstream "github.com/bazelbuild/remote-apis/build/bazel/remote/logstream/v1"
 	re_blobstore "github.com/buildbarn/bb-remote-execution/pkg/blobstore"
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/buildqueuestate"
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/bb_scheduler"
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
 	"github.com/buildbarn/bb-remote-execution/pkg/scheduler"
 	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/initialsizeclass"
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/logstream"
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/platform"
 	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/routing"
 	"github.com/buildbarn/bb-storage/pkg/auth"
 	blobstore_configuration "github.com/buildbarn/bb-storage/pkg/blobstore/configuration"
@@ -30,7 +34,10 @@ import (
 	"github.com/buildbarn/bb-storage/pkg/util"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/redis/go-redis/v9"
 
+	"go.etcd.io/bbolt"
+	"google.golang.org/genproto/googleapis/bytestream"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -74,7 +81,11 @@ func main() {
 		// are configured to use feedback driven initial size class
 		// analysis.
 		var previousExecutionStatsStore initialsizeclass.PreviousExecutionStatsStore
+		var previousExecutionStatsStoreBackend re_blobstore.MutableProtoStoreBackend
 		if isccConfiguration := configuration.InitialSizeClassCache; isccConfiguration != nil {
+			if configuration.PreviousExecutionStatsStore != nil {
+				return status.Error(codes.InvalidArgument, "The Initial Size Class Cache and the previous execution stats store cannot be configured at the same time")
+			}
 			info, err := blobstore_configuration.NewBlobAccessFromConfiguration(
 				dependenciesGroup,
 				isccConfiguration,
@@ -84,9 +95,71 @@ func main() {
 			if err != nil {
 				return util.StatusWrap(err, "Failed to create Initial Size Class Cache")
 			}
-			previousExecutionStatsStore = re_blobstore.NewBlobAccessMutableProtoStore[iscc.PreviousExecutionStats](
-				info.BlobAccess,
+			previousExecutionStatsStoreBackend = info.BlobAccess
+		} else if storeConfiguration := configuration.PreviousExecutionStatsStore; storeConfiguration != nil {
+			previousExecutionStatsStoreBackend, err = newPreviousExecutionStatsStoreBackend(storeConfiguration, int(configuration.MaximumMessageSizeBytes))
+			if err != nil {
+				return util.StatusWrap(err, "Failed to create previous execution stats store")
+			}
+		}
+		if previousExecutionStatsStoreBackend != nil {
+			store := re_blobstore.NewBlobAccessMutableProtoStore[iscc.PreviousExecutionStats](
+				previousExecutionStatsStoreBackend,
 				int(configuration.MaximumMessageSizeBytes))
+			previousExecutionStatsStore = store
+
+			// Write execution times back to storage periodically
+			// if configured, and write any execution times that
+			// are still pending when shutting down.
+			flushInterval := time.Duration(0)
+			shutdownFlushTimeout := time.Minute
+			maximumWritesPerFlush := 100
+			if writeBehindConfiguration := configuration.InitialSizeClassCacheWriteBehind; writeBehindConfiguration != nil {
+				if err := writeBehindConfiguration.FlushInterval.CheckValid(); err != nil {
+					return util.StatusWrap(err, "Invalid Initial Size Class Cache flush interval")
+				}
+				flushInterval = writeBehindConfiguration.FlushInterval.AsDuration()
+				if flushInterval <= 0 {
+					return status.Error(codes.InvalidArgument, "Initial Size Class Cache flush interval must be positive")
+				}
+				shutdownFlushTimeout = flushInterval
+				if writeBehindConfiguration.MaximumWritesPerFlush == 0 {
+					return status.Error(codes.InvalidArgument, "Maximum number of Initial Size Class Cache writes per flush must be positive")
+				}
+				maximumWritesPerFlush = int(writeBehindConfiguration.MaximumWritesPerFlush)
+			}
+			siblingsGroup.Go(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+				if flushInterval > 0 {
+					for ctx.Err() == nil {
+						timer, timerChannel := clock.SystemClock.NewTimer(flushInterval)
+						select {
+						case <-ctx.Done():
+							timer.Stop()
+						case <-timerChannel:
+							if _, err := store.Flush(ctx, maximumWritesPerFlush); err != nil {
+								log.Print("Failed to flush Initial Size Class Cache: ", err)
+							}
+						}
+					}
+				}
+				<-ctx.Done()
+
+				// The context provided to this function has
+				// been canceled. Use a separate context for
+				// writing the remaining execution times.
+				ctxFlush, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownFlushTimeout)
+				defer cancel()
+				for {
+					n, err := store.Flush(ctxFlush, maximumWritesPerFlush)
+					if err != nil {
+						log.Print("Failed to flush Initial Size Class Cache during shutdown: ", err)
+						return nil
+					}
+					if n == 0 {
+						return nil
+					}
+				}
+			})
 		}
 
 		// Create an action router that is responsible for analyzing
@@ -110,14 +183,76 @@ func main() {
 		if err != nil {
 			return util.StatusWrap(err, "Failed to create kill operaitons authorizer")
 		}
+		actionCacheWriteAuthorizer := auth.NewStaticAuthorizer(func(in digest.InstanceName) bool { return true })
+		if configuration.ActionCacheWriteAuthorizer != nil {
+			actionCacheWriteAuthorizer, err = authorizerFactory.NewAuthorizerFromConfiguration(configuration.ActionCacheWriteAuthorizer)
+			if err != nil {
+				return util.StatusWrap(err, "Failed to create Action Cache write authorizer")
+			}
+		}
+		nestedExecutionAuthorizer := auth.NewStaticAuthorizer(func(in digest.InstanceName) bool { return false })
+		if configuration.NestedExecutionAuthorizer != nil {
+			nestedExecutionAuthorizer, err = authorizerFactory.NewAuthorizerFromConfiguration(configuration.NestedExecutionAuthorizer)
+			if err != nil {
+				return util.StatusWrap(err, "Failed to create nested execution authorizer")
+			}
+		}
 
 		platformQueueWithNoWorkersTimeout := configuration.PlatformQueueWithNoWorkersTimeout
 		if err := platformQueueWithNoWorkersTimeout.CheckValid(); err != nil {
 			return util.StatusWrap(err, "Invalid platform queue with no workers timeout")
 		}
 
+		operationWithNoWaitersTimeout := time.Minute
+		if d := configuration.OperationWithNoWaitersTimeout; d != nil {
+			if err := d.CheckValid(); err != nil {
+				return util.StatusWrap(err, "Invalid operation with no waiters timeout")
+			}
+			operationWithNoWaitersTimeout = d.AsDuration()
+		}
+		busyWorkerSynchronizationInterval := 10 * time.Second
+		if d := configuration.BusyWorkerSynchronizationInterval; d != nil {
+			if err := d.CheckValid(); err != nil {
+				return util.StatusWrap(err, "Invalid busy worker synchronization interval")
+			}
+			busyWorkerSynchronizationInterval = d.AsDuration()
+		}
+
+		streamingIdleWorkerSynchronizationInterval := 30 * time.Minute
+		if d := configuration.StreamingIdleWorkerSynchronizationInterval; d != nil {
+			if err := d.CheckValid(); err != nil {
+				return util.StatusWrap(err, "Invalid streaming idle worker synchronization interval")
+			}
+			streamingIdleWorkerSynchronizationInterval = d.AsDuration()
+		}
+
+		// Optionally let the scheduler host LogStreams, so that
+		// clients can read the output of running actions.
+		var logStreamServer logstream.Server
+		if logStreamConfiguration := configuration.LogStream; logStreamConfiguration != nil {
+			if err := logStreamConfiguration.Retention.CheckValid(); err != nil {
+				return util.StatusWrap(err, "Invalid log stream retention")
+			}
+			logStreamServer = logstream.NewInMemoryServer(
+				clock.SystemClock,
+				uuid.NewRandom,
+				int(logStreamConfiguration.MaximumSizeBytesPerStream),
+				int(logStreamConfiguration.MaximumTotalSizeBytes),
+				logStreamConfiguration.Retention.AsDuration(),
+				int(logStreamConfiguration.MaximumStreams))
+		}
+
+		skipCacheLookupIgnoredInstanceNamePrefixes := make([]digest.InstanceName, 0, len(configuration.SkipCacheLookupIgnoredInstanceNamePrefixes))
+		for _, instanceNamePrefixStr := range configuration.SkipCacheLookupIgnoredInstanceNamePrefixes {
+			instanceNamePrefix, err := digest.NewInstanceName(instanceNamePrefixStr)
+			if err != nil {
+				return util.StatusWrapf(err, "Invalid instance name prefix %#v for which to ignore skip_cache_lookup", instanceNamePrefixStr)
+			}
+			skipCacheLookupIgnoredInstanceNamePrefixes = append(skipCacheLookupIgnoredInstanceNamePrefixes, instanceNamePrefix)
+		}
+
 		// Create in-memory build queue.
-		// TODO: Make timeouts configurable.
+		// TODO: Make the remaining timeouts configurable.
 		generator := random.NewFastSingleThreadedGenerator()
 		buildQueue := scheduler.NewInMemoryBuildQueue(
 			contentAddressableStorage,
@@ -125,23 +260,31 @@ func main() {
 			uuid.NewRandom,
 			&scheduler.InMemoryBuildQueueConfiguration{
 				ExecutionUpdateInterval:           time.Minute,
-				OperationWithNoWaitersTimeout:     time.Minute,
+				OperationWithNoWaitersTimeout:     operationWithNoWaitersTimeout,
 				PlatformQueueWithNoWorkersTimeout: platformQueueWithNoWorkersTimeout.AsDuration(),
-				BusyWorkerSynchronizationInterval: 10 * time.Second,
+				BusyWorkerSynchronizationInterval: busyWorkerSynchronizationInterval,
 				GetIdleWorkerSynchronizationInterval: func() time.Duration {
 					// Let synchronization calls block somewhere
 					// between 0 and 2 minutes. Add jitter to
 					// prevent recurring traffic spikes.
 					return random.Duration(generator, 2*time.Minute)
 				},
-				WorkerTaskRetryCount:                9,
-				WorkerWithNoSynchronizationsTimeout: time.Minute,
+				StreamingIdleWorkerSynchronizationInterval: streamingIdleWorkerSynchronizationInterval,
+				WorkerTaskRetryCount:                       9,
+				WorkerWithNoSynchronizationsTimeout:        time.Minute,
+				ReportExpectedDurationToClients:            configuration.ReportExpectedDurationToClients,
+				MigrateQueuedOperations:                    configuration.QueuedOperationMigration != nil,
+				MigrationIgnoredPlatformPropertyNames:      configuration.QueuedOperationMigration.GetIgnoredPlatformPropertyNames(),
+				SkipCacheLookupIgnoredInstanceNamePrefixes: skipCacheLookupIgnoredInstanceNamePrefixes,
+				CacheKeyExcludedPlatformPropertyNames:      configuration.CacheKeyExcludedPlatformProperties,
 			},
 			int(configuration.MaximumMessageSizeBytes),
 			actionRouter,
 			executeAuthorizer,
 			modifyDrainsAuthorizer,
-			killOperationsAuthorizer)
+			killOperationsAuthorizer,
+			actionCacheWriteAuthorizer,
+			nestedExecutionAuthorizer)
 
 		// Create predeclared platform queues.
 		for _, platformQueue := range configuration.PredeclaredPlatformQueues {
@@ -157,12 +300,21 @@ func main() {
 				workerInvocationStickinessLimits = append(workerInvocationStickinessLimits, d.AsDuration())
 			}
 
+			var platformPropertyMerger *platform.PropertyMerger
+			if len(platformQueue.DefaultExecutionProperties) > 0 || len(platformQueue.EnforcedExecutionProperties) > 0 {
+				platformPropertyMerger = platform.NewPropertyMerger(
+					platformQueue.DefaultExecutionProperties,
+					platformQueue.EnforcedExecutionProperties)
+			}
+
 			if err := buildQueue.RegisterPredeclaredPlatformQueue(
 				instanceName,
 				platformQueue.Platform,
 				workerInvocationStickinessLimits,
 				int(platformQueue.MaximumQueuedBackgroundLearningOperations),
 				platformQueue.BackgroundLearningOperationPriority,
+				platformQueue.BackgroundLearningOnIdleWorkersOnly,
+				platformPropertyMerger,
 				platformQueue.MaximumSizeClass,
 			); err != nil {
 				return util.StatusWrap(err, "Failed to register predeclared platform queue")
@@ -177,6 +329,11 @@ func main() {
 					s,
 					capabilities.NewServer(buildQueue))
 				remoteexecution.RegisterExecutionServer(s, buildQueue)
+				buildqueuestate.RegisterQueuedOperationControlServer(s, buildQueue)
+				if logStreamServer != nil {
+					remotelogstream.RegisterLogStreamServiceServer(s, logStreamServer)
+					bytestream.RegisterByteStreamServer(s, logStreamServer)
+				}
 			},
 			siblingsGroup,
 		); err != nil {
@@ -186,6 +343,10 @@ func main() {
 			configuration.WorkerGrpcServers,
 			func(s grpc.ServiceRegistrar) {
 				remoteworker.RegisterOperationQueueServer(s, buildQueue)
+				if logStreamServer != nil {
+					remotelogstream.RegisterLogStreamServiceServer(s, logStreamServer)
+					bytestream.RegisterByteStreamServer(s, logStreamServer)
+				}
 			},
 			siblingsGroup,
 		); err != nil {
@@ -195,6 +356,7 @@ func main() {
 			configuration.BuildQueueStateGrpcServers,
 			func(s grpc.ServiceRegistrar) {
 				buildqueuestate.RegisterBuildQueueStateServer(s, buildQueue)
+				buildqueuestate.RegisterQueuedOperationControlServer(s, buildQueue)
 			},
 			siblingsGroup,
 		); err != nil {
@@ -219,3 +381,65 @@ func main() {
 		return nil
 	})
 }
+
+func newPreviousExecutionStatsStoreBackend(configuration *bb_scheduler.PreviousExecutionStatsStoreConfiguration, maximumMessageSizeBytes int) (re_blobstore.MutableProtoStoreBackend, error) {
+	switch backend := configuration.Backend.(type) {
+	case *bb_scheduler.PreviousExecutionStatsStoreConfiguration_BoltDb:
+		if backend.BoltDb.Path == "" {
+			return nil, status.Error(codes.InvalidArgument, "No BoltDB database path provided")
+		}
+		// Don't block indefinitely if another scheduler is
+		// already using the same database.
+		db, err := bbolt.Open(backend.BoltDb.Path, 0o600, &bbolt.Options{Timeout: 10 * time.Second})
+		if err != nil {
+			return nil, util.StatusWrapfWithCode(err, codes.Internal, "Failed to open BoltDB database %#v", backend.BoltDb.Path)
+		}
+		return re_blobstore.NewBoltDBMutableProtoStoreBackend(db, maximumMessageSizeBytes)
+	case *bb_scheduler.PreviousExecutionStatsStoreConfiguration_Redis:
+		redisConfiguration := backend.Redis
+		if redisConfiguration.Address == "" {
+			return nil, status.Error(codes.InvalidArgument, "No Redis server address provided")
+		}
+		var expiration time.Duration
+		if redisConfiguration.Expiration != nil {
+			if err := redisConfiguration.Expiration.CheckValid(); err != nil {
+				return nil, util.StatusWrap(err, "Invalid Redis expiration")
+			}
+			expiration = redisConfiguration.Expiration.AsDuration()
+		}
+		var dialTimeout time.Duration
+		if redisConfiguration.DialTimeout != nil {
+			if err := redisConfiguration.DialTimeout.CheckValid(); err != nil {
+				return nil, util.StatusWrap(err, "Invalid Redis dial timeout")
+			}
+			dialTimeout = redisConfiguration.DialTimeout.AsDuration()
+		}
+		var password string
+		if passwordPath := redisConfiguration.PasswordPath; passwordPath != "" {
+			passwordData, err := os.ReadFile(passwordPath)
+			if err != nil {
+				return nil, util.StatusWrapf(err, "Failed to read Redis password from %#v", passwordPath)
+			}
+			password = strings.TrimRight(string(passwordData), "\r\n")
+		}
+		tlsConfig, err := util.NewTLSConfigFromClientConfiguration(redisConfiguration.Tls)
+		if err != nil {
+			return nil, util.StatusWrap(err, "Failed to create Redis TLS configuration")
+		}
+		return re_blobstore.NewRedisMutableProtoStoreBackend(
+			redis.NewClient(&redis.Options{
+				Addr:         redisConfiguration.Address,
+				Username:     redisConfiguration.Username,
+				Password:     password,
+				DB:           int(redisConfiguration.Database),
+				DialTimeout:  dialTimeout,
+				MaxIdleConns: int(redisConfiguration.MaximumIdleConnections),
+				TLSConfig:    tlsConfig,
+			}),
+			redisConfiguration.KeyPrefix,
+			expiration,
+			maximumMessageSizeBytes), nil
+	default:
+		return nil, status.Error(codes.InvalidArgument, "No backend provided")
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/bb_scheduler_router"
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/sharding"
+	"github.com/buildbarn/bb-storage/pkg/capabilities"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/global"
+	bb_grpc "github.com/buildbarn/bb-storage/pkg/grpc"
+	"github.com/buildbarn/bb-storage/pkg/program"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// This is a front-end for the Execution service that shards requests
+// across multiple instances of bb_scheduler by action digest. It can be
+// used by deployments whose workload exceeds what can be tracked in
+// the memory of a single scheduler.
+
+func getDurationOrDefault(d *durationpb.Duration, defaultValue time.Duration) (time.Duration, error) {
+	if d == nil {
+		return defaultValue, nil
+	}
+	if err := d.CheckValid(); err != nil {
+		return 0, err
+	}
+	return d.AsDuration(), nil
+}
+
+func main() {
+	program.RunMain(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+		if len(os.Args) != 2 {
+			return status.Error(codes.InvalidArgument, "Usage: bb_scheduler_router bb_scheduler_router.jsonnet")
+		}
+		var configuration bb_scheduler_router.ApplicationConfiguration
+		if err := util.UnmarshalConfigurationFromFile(os.Args[1], &configuration); err != nil {
+			return util.StatusWrapf(err, "Failed to read configuration from %s", os.Args[1])
+		}
+		lifecycleState, grpcClientFactory, err := global.ApplyConfiguration(configuration.Global)
+		if err != nil {
+			return util.StatusWrap(err, "Failed to apply global configuration options")
+		}
+
+		healthCheckInterval, err := getDurationOrDefault(configuration.HealthCheckInterval, 10*time.Second)
+		if err != nil {
+			return util.StatusWrap(err, "Invalid health check interval")
+		}
+		healthCheckTimeout, err := getDurationOrDefault(configuration.HealthCheckTimeout, 5*time.Second)
+		if err != nil {
+			return util.StatusWrap(err, "Invalid health check timeout")
+		}
+		virtualNodesPerShard := int(configuration.VirtualNodesPerShard)
+		if virtualNodesPerShard <= 0 {
+			virtualNodesPerShard = 100
+		}
+
+		// Create connections to all of the schedulers.
+		shards := map[string]sharding.Shard{}
+		healthClients := map[string]grpc_health_v1.HealthClient{}
+		for _, shardConfiguration := range configuration.Shards {
+			shardName := shardConfiguration.Name
+			if shardName == "" || strings.Contains(shardName, "/") {
+				return status.Errorf(codes.InvalidArgument, "Invalid shard name %#v", shardName)
+			}
+			if _, ok := shards[shardName]; ok {
+				return status.Errorf(codes.InvalidArgument, "Multiple shards with name %#v", shardName)
+			}
+			connection, err := grpcClientFactory.NewClientFromConfiguration(shardConfiguration.Client)
+			if err != nil {
+				return util.StatusWrapf(err, "Failed to create RPC client for shard %#v", shardName)
+			}
+			shards[shardName] = sharding.Shard{
+				ExecutionClient:    remoteexecution.NewExecutionClient(connection),
+				CapabilitiesClient: remoteexecution.NewCapabilitiesClient(connection),
+			}
+			healthClients[shardName] = grpc_health_v1.NewHealthClient(connection)
+		}
+		if len(shards) == 0 {
+			return status.Error(codes.InvalidArgument, "No shards configured")
+		}
+
+		// Keep the membership of the hash ring up to date, so that
+		// actions are resharded when schedulers come and go.
+		ring := sharding.NewConsistentHashRing(virtualNodesPerShard)
+		siblingsGroup.Go(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+			return sharding.UpdateMembershipFromHealthChecks(ctx, ring, healthClients, clock.SystemClock, healthCheckInterval, healthCheckTimeout)
+		})
+
+		server := sharding.NewShardingExecutionServer(shards, ring)
+		if err := bb_grpc.NewServersFromConfigurationAndServe(
+			configuration.GrpcServers,
+			func(s grpc.ServiceRegistrar) {
+				remoteexecution.RegisterCapabilitiesServer(
+					s,
+					capabilities.NewServer(server))
+				remoteexecution.RegisterExecutionServer(s, server)
+			},
+			siblingsGroup,
+		); err != nil {
+			return util.StatusWrap(err, "gRPC server failure")
+		}
+
+		lifecycleState.MarkReadyAndWait(siblingsGroup)
+		return nil
+	})
+}
@@ -69,7 +69,8 @@ func main() {
 				virtual.NewStaticDirectory(map[path.Component]virtual.DirectoryChild{
 					path.MustNewComponent("tmp"): virtual.DirectoryChild{}.
 						FromLeaf(handleAllocator.New().AsNativeLeaf(userSettableSymlink)),
-				}))); err != nil {
+				})),
+			/* statFSProvider = */ nil); err != nil {
 			return util.StatusWrap(err, "Failed to expose virtual file system mount")
 		}
 
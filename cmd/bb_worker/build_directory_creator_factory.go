@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-remote-execution/pkg/cas"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/bb_worker"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// subdirectoryBuildDirectoryCreatorFactory creates BuildDirectoryCreators
+// for all worker threads that share a single build directory. It
+// ensures that the names of subdirectories in which build actions are
+// executed don't collide, regardless of the reuse policy that is used
+// by each runner.
+type subdirectoryBuildDirectoryCreatorFactory struct {
+	nextParallelActionID atomic.Uint64
+	nextThreadID         uint64
+	toolchainPools       *builder.ToolchainBuildDirectoryNamePools
+}
+
+func newSubdirectoryBuildDirectoryCreatorFactory() *subdirectoryBuildDirectoryCreatorFactory {
+	return &subdirectoryBuildDirectoryCreatorFactory{
+		toolchainPools: builder.NewToolchainBuildDirectoryNamePools(),
+	}
+}
+
+// newBuildDirectoryCreator creates a BuildDirectoryCreator for a single
+// worker thread, which causes build actions to be executed inside a
+// subdirectory of the build directory, named according to the
+// configured reuse policy.
+func (f *subdirectoryBuildDirectoryCreatorFactory) newBuildDirectoryCreator(base builder.BuildDirectoryCreator, runnerConfiguration *bb_worker.RunnerConfiguration, directoryFetcher cas.DirectoryFetcher, contentAddressableStorage blobstore.BlobAccess) (builder.BuildDirectoryCreator, error) {
+	switch policy := runnerConfiguration.BuildDirectoryReusePolicy; policy {
+	case bb_worker.BuildDirectoryReusePolicy_FRESH_PER_ACTION:
+		return builder.NewSharedBuildDirectoryCreator(base, &f.nextParallelActionID), nil
+	case bb_worker.BuildDirectoryReusePolicy_REUSE_PER_THREAD:
+		// Give every worker thread its own pool, so that it
+		// always uses the same subdirectory.
+		name := path.MustNewComponent(fmt.Sprintf("thread-%d", f.nextThreadID))
+		f.nextThreadID++
+		return builder.NewReusingBuildDirectoryCreator(base, builder.NewBuildDirectoryNamePool(name)), nil
+	case bb_worker.BuildDirectoryReusePolicy_REUSE_PER_TOOLCHAIN:
+		// Share pools between all worker threads, so that
+		// actions using the same toolchain may run in any
+		// subdirectory that contains it.
+		if runnerConfiguration.ToolchainDirectoryPath == "" {
+			return nil, status.Error(codes.InvalidArgument, "A toolchain directory path must be provided when reusing build directories per toolchain")
+		}
+		return builder.NewToolchainReusingBuildDirectoryCreator(
+			base,
+			f.toolchainPools,
+			runnerConfiguration.ToolchainDirectoryPath,
+			directoryFetcher,
+			contentAddressableStorage)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "Unknown build directory reuse policy %d", policy)
+	}
+}
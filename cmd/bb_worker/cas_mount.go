@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/cas"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/util"
+)
+
+// newCASDirectory creates the root directory of the CAS mount. It
+// contains one subdirectory for every supported digest function (e.g.,
+// "sha256"), in which blobs can be accessed by looking up files named
+// "${hash}-${size}". It also contains a subdirectory named "trees",
+// which has the same layout, but gives access to the contents of Tree
+// objects instead. Similarly, a subdirectory named "actions" gives
+// access to the results of actions stored in the Action Cache, keyed by
+// action digest.
+func newCASDirectory(ctx context.Context, instanceName digest.InstanceName, casFileFactory virtual.CASFileFactory, directoryFetcher cas.DirectoryFetcher, actionCache blobstore.BlobAccess, maximumMessageSizeBytes int, symlinkFactory virtual.SymlinkFactory, errorLogger util.ErrorLogger, handleAllocator virtual.StatefulHandleAllocator, caseInsensitive bool) (virtual.Directory, error) {
+	blobDirectories := map[path.Component]virtual.DirectoryChild{}
+	treeDirectories := map[path.Component]virtual.DirectoryChild{}
+	actionDirectories := map[path.Component]virtual.DirectoryChild{}
+	for _, digestFunctionValue := range digest.SupportedDigestFunctions {
+		digestFunction, err := instanceName.GetDigestFunction(digestFunctionValue, 0)
+		if err != nil {
+			return nil, util.StatusWrapf(err, "Failed to obtain digest function %s", digestFunctionValue)
+		}
+		name := path.MustNewComponent(strings.ToLower(digestFunctionValue.String()))
+		blobDirectories[name] = virtual.DirectoryChild{}.FromDirectory(
+			handleAllocator.New().AsStatelessDirectory(
+				virtual.NewCASBlobDirectory(casFileFactory, digestFunction)))
+		treeDirectories[name] = virtual.DirectoryChild{}.FromDirectory(
+			handleAllocator.New().AsStatelessDirectory(
+				virtual.NewCASTreeDirectory(
+					directoryFetcher,
+					casFileFactory,
+					symlinkFactory,
+					errorLogger,
+					digestFunction,
+					handleAllocator.New(),
+					caseInsensitive)))
+		actionDirectories[name] = virtual.DirectoryChild{}.FromDirectory(
+			handleAllocator.New().AsStatelessDirectory(
+				virtual.NewActionResultDirectory(
+					ctx,
+					actionCache,
+					maximumMessageSizeBytes,
+					directoryFetcher,
+					casFileFactory,
+					symlinkFactory,
+					errorLogger,
+					digestFunction,
+					handleAllocator.New())))
+	}
+	blobDirectories[path.MustNewComponent("actions")] = virtual.DirectoryChild{}.FromDirectory(
+		handleAllocator.New().AsStatelessDirectory(
+			virtual.NewStaticDirectory(actionDirectories)))
+	blobDirectories[path.MustNewComponent("trees")] = virtual.DirectoryChild{}.FromDirectory(
+		handleAllocator.New().AsStatelessDirectory(
+			virtual.NewStaticDirectory(treeDirectories)))
+	return handleAllocator.New().AsStatelessDirectory(
+		virtual.NewStaticDirectory(blobDirectories)), nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/bb_worker"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// runnerLocation identifies a runner within the worker's configuration.
+type runnerLocation struct {
+	buildDirectoryIndex int
+	runnerIndex         int
+}
+
+// validateConfiguration checks the build directories, runners and
+// platform queues declared in the worker's configuration for
+// consistency. Doing this before any of them are instantiated ensures
+// that mistakes are reported with the location at which they occur,
+// instead of causing the worker to fail halfway through startup.
+func validateConfiguration(configuration *bb_worker.ApplicationConfiguration) error {
+	if len(configuration.BuildDirectories) == 0 {
+		return status.Error(codes.InvalidArgument, "Cannot start worker without any build directories")
+	}
+
+	// Worker IDs are used by the scheduler to distinguish workers.
+	// Runners sharing the same worker ID would cause their worker
+	// threads to be mixed up.
+	workerIDs := map[string]runnerLocation{}
+	for buildDirectoryIndex, buildDirectoryConfiguration := range configuration.BuildDirectories {
+		if err := validateBuildDirectoryConfiguration(buildDirectoryIndex, buildDirectoryConfiguration, workerIDs); err != nil {
+			return util.StatusWrapf(err, "Invalid build directory at index %d", buildDirectoryIndex)
+		}
+	}
+	return nil
+}
+
+func validateBuildDirectoryConfiguration(buildDirectoryIndex int, buildDirectoryConfiguration *bb_worker.BuildDirectoryConfiguration, workerIDs map[string]runnerLocation) error {
+	if buildDirectoryConfiguration.Backend == nil {
+		return status.Error(codes.InvalidArgument, "No build directory backend specified")
+	}
+	if len(buildDirectoryConfiguration.Runners) == 0 {
+		return status.Error(codes.InvalidArgument, "Cannot start worker without any runners")
+	}
+	for runnerIndex, runnerConfiguration := range buildDirectoryConfiguration.Runners {
+		if err := validateRunnerConfiguration(runnerConfiguration); err != nil {
+			return util.StatusWrapf(err, "Invalid runner at index %d", runnerIndex)
+		}
+
+		workerID := getWorkerIDKey(runnerConfiguration.WorkerId)
+		if other, ok := workerIDs[workerID]; ok {
+			return status.Errorf(codes.InvalidArgument, "Runner at index %d has the same worker ID as the runner at index %d of the build directory at index %d", runnerIndex, other.runnerIndex, other.buildDirectoryIndex)
+		}
+		workerIDs[workerID] = runnerLocation{
+			buildDirectoryIndex: buildDirectoryIndex,
+			runnerIndex:         runnerIndex,
+		}
+	}
+	return nil
+}
+
+func validateRunnerConfiguration(runnerConfiguration *bb_worker.RunnerConfiguration) error {
+	if runnerConfiguration.Endpoint == nil {
+		return status.Error(codes.InvalidArgument, "No runner endpoint specified")
+	}
+	if runnerConfiguration.Concurrency < 1 {
+		return status.Error(codes.InvalidArgument, "Runner concurrency must be positive")
+	}
+
+	// Index 0 refers to the platform queue that is declared
+	// inline, while additional platform queues follow.
+	platformQueues := append([]*bb_worker.PlatformQueueConfiguration{{
+		InstanceNamePrefix: runnerConfiguration.InstanceNamePrefix,
+		Platform:           runnerConfiguration.Platform,
+		SizeClass:          runnerConfiguration.SizeClass,
+	}}, runnerConfiguration.AdditionalPlatformQueues...)
+	seenPlatformQueues := map[string]int{}
+	for queueIndex, platformQueue := range platformQueues {
+		if _, err := digest.NewInstanceName(platformQueue.InstanceNamePrefix); err != nil {
+			return util.StatusWrapf(err, "Invalid instance name prefix %#v of platform queue at index %d", platformQueue.InstanceNamePrefix, queueIndex)
+		}
+		key := fmt.Sprintf("%s\x00%s\x00%d", platformQueue.InstanceNamePrefix, getPlatformKey(platformQueue.Platform), platformQueue.SizeClass)
+		if otherIndex, ok := seenPlatformQueues[key]; ok {
+			return status.Errorf(codes.InvalidArgument, "Platform queue at index %d is identical to the platform queue at index %d", queueIndex, otherIndex)
+		}
+		seenPlatformQueues[key] = queueIndex
+	}
+
+	if nestedExecution := runnerConfiguration.NestedExecution; nestedExecution != nil {
+		if nestedExecution.Scheduler == nil {
+			return status.Error(codes.InvalidArgument, "No scheduler endpoint specified for nested execution")
+		}
+		if nestedExecution.SocketDirectory == "" || nestedExecution.EnvironmentVariableName == "" || nestedExecution.TokenEnvironmentVariableName == "" {
+			return status.Error(codes.InvalidArgument, "Nested execution requires a socket directory and environment variable names for the endpoint and token")
+		}
+		if nestedExecution.PlatformPropertyName == "" {
+			return status.Error(codes.InvalidArgument, "Nested execution requires a platform property name for the nesting depth")
+		}
+		if nestedExecution.MaximumConcurrentSubActions < 1 {
+			return status.Error(codes.InvalidArgument, "Maximum number of concurrent sub-actions for nested execution must be positive")
+		}
+		if nestedExecution.MaximumSubActionsPerAction < 1 {
+			return status.Error(codes.InvalidArgument, "Maximum number of sub-actions per action for nested execution must be positive")
+		}
+	}
+	return nil
+}
+
+// getPlatformKey converts a platform to a string that can be used as
+// a map key, regardless of the order in which properties are listed.
+func getPlatformKey(platform *remoteexecution.Platform) string {
+	properties := make([]string, 0, len(platform.GetProperties()))
+	for _, property := range platform.GetProperties() {
+		properties = append(properties, fmt.Sprintf("%q=%q", property.Name, property.Value))
+	}
+	sort.Strings(properties)
+	return strings.Join(properties, ",")
+}
+
+// getWorkerIDKey converts a worker ID to a string that can be used as a
+// map key.
+func getWorkerIDKey(workerID map[string]string) string {
+	keyValues := make([]string, 0, len(workerID))
+	for k, v := range workerID {
+		keyValues = append(keyValues, fmt.Sprintf("%q=%q", k, v))
+	}
+	sort.Strings(keyValues)
+	return strings.Join(keyValues, ",")
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/buildbarn/bb-storage/pkg/auth"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/gorilla/mux"
+)
+
+// inputFileCacheFlusher removes all files from an input file cache,
+// returning the number of files removed.
+type inputFileCacheFlusher func() (int, error)
+
+type inputFileCacheFlushService struct {
+	flushers   []inputFileCacheFlusher
+	authorizer auth.Authorizer
+}
+
+// newInputFileCacheFlushService registers an HTTP endpoint that can be
+// used to remove all files from the input file caches of the worker.
+// This can be used to reclaim disk space without restarting the
+// worker.
+//
+// As flushing causes subsequent build actions to download their inputs
+// once again, requests are only processed if they are permitted by the
+// authorizer. The authorizer is invoked with the empty instance name.
+func newInputFileCacheFlushService(flushers []inputFileCacheFlusher, authorizer auth.Authorizer, router *mux.Router) {
+	s := &inputFileCacheFlushService{
+		flushers:   flushers,
+		authorizer: authorizer,
+	}
+	router.HandleFunc("/-/flush_input_file_cache", s.handleFlush).Methods(http.MethodPost)
+}
+
+func (s *inputFileCacheFlushService) handleFlush(w http.ResponseWriter, req *http.Request) {
+	if err := auth.AuthorizeSingleInstanceName(req.Context(), s.authorizer, digest.EmptyInstanceName); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	log.Print("Flushing input file caches")
+	totalCount := 0
+	for _, flusher := range s.flushers {
+		count, err := flusher()
+		totalCount += count
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	log.Printf("Flushed %d files from input file caches", totalCount)
+	fmt.Fprintf(w, "Flushed %d files\n", totalCount)
+}
@@ -5,15 +5,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/url"
 	"os"
+	"path"
 	"regexp"
 	"sort"
 	"strconv"
-	"sync/atomic"
+	"strings"
 	"time"
 
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	remotelogstream "github.com/bazelbuild/remote-apis/build/bazel/remote/logstream/v1"
 	re_blobstore "github.com/buildbarn/bb-remote-execution/pkg/blobstore"
+	"github.com/buildbarn/bb-remote-execution/pkg/blobstore/objectstorage"
 	"github.com/buildbarn/bb-remote-execution/pkg/builder"
 	"github.com/buildbarn/bb-remote-execution/pkg/cas"
 	"github.com/buildbarn/bb-remote-execution/pkg/cleaner"
@@ -25,20 +30,32 @@ import (
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/bb_worker"
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
 	runner_pb "github.com/buildbarn/bb-remote-execution/pkg/proto/runner"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/virtualfilesystemdebug"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/workerdebug"
+	re_util "github.com/buildbarn/bb-remote-execution/pkg/util"
+	"github.com/buildbarn/bb-storage/pkg/auth"
 	"github.com/buildbarn/bb-storage/pkg/blobstore"
 	blobstore_configuration "github.com/buildbarn/bb-storage/pkg/blobstore/configuration"
+	"github.com/buildbarn/bb-storage/pkg/blockdevice"
 	"github.com/buildbarn/bb-storage/pkg/clock"
 	"github.com/buildbarn/bb-storage/pkg/digest"
 	"github.com/buildbarn/bb-storage/pkg/eviction"
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/global"
+	bb_grpc "github.com/buildbarn/bb-storage/pkg/grpc"
+	"github.com/buildbarn/bb-storage/pkg/http"
 	"github.com/buildbarn/bb-storage/pkg/program"
+	eviction_pb "github.com/buildbarn/bb-storage/pkg/proto/configuration/eviction"
 	"github.com/buildbarn/bb-storage/pkg/random"
 	"github.com/buildbarn/bb-storage/pkg/util"
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 
 	"golang.org/x/sync/semaphore"
+	"google.golang.org/genproto/googleapis/bytestream"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/status"
 
 	"go.opentelemetry.io/otel"
@@ -53,6 +70,9 @@ func main() {
 		if err := util.UnmarshalConfigurationFromFile(os.Args[1], &configuration); err != nil {
 			return util.StatusWrapf(err, "Failed to read configuration from %s", os.Args[1])
 		}
+		if err := validateConfiguration(&configuration); err != nil {
+			return util.StatusWrapf(err, "Invalid configuration in %s", os.Args[1])
+		}
 		lifecycleState, grpcClientFactory, err := global.ApplyConfiguration(configuration.Global)
 		if err != nil {
 			return util.StatusWrap(err, "Failed to apply global configuration options")
@@ -69,7 +89,16 @@ func main() {
 		if err != nil {
 			return util.StatusWrap(err, "Failed to create scheduler RPC client")
 		}
-		schedulerClient := remoteworker.NewOperationQueueClient(schedulerConnection)
+		schedulerClients := []remoteworker.OperationQueueClient{
+			remoteworker.NewOperationQueueClient(schedulerConnection),
+		}
+		for i, schedulerConfiguration := range configuration.AdditionalSchedulers {
+			schedulerConnection, err := grpcClientFactory.NewClientFromConfiguration(schedulerConfiguration)
+			if err != nil {
+				return util.StatusWrapf(err, "Failed to create RPC client for additional scheduler %d", i)
+			}
+			schedulerClients = append(schedulerClients, remoteworker.NewOperationQueueClient(schedulerConnection))
+		}
 
 		// Location for storing temporary file objects. This is
 		// currently only used by the virtual file system to store
@@ -89,6 +118,78 @@ func main() {
 		if err != nil {
 			return err
 		}
+
+		// Optionally write large output files into an object
+		// store, as opposed to writing them into the CAS.
+		// References to these objects are written into the
+		// Indirect Content Addressable Storage (ICAS). Storage
+		// frontends and workers need to combine their CAS with
+		// 'read_fallback' and 'reference_expanding' to be able to
+		// read these files.
+		var newObjectStorageOffloadingBlobAccess func(base blobstore.BlobAccess) blobstore.BlobAccess
+		if offloadingConfiguration := configuration.ObjectStorageOffloading; offloadingConfiguration != nil {
+			if offloadingConfiguration.MinimumSizeBytes <= 0 {
+				return status.Error(codes.InvalidArgument, "Object storage offloading minimum size must be positive")
+			}
+			objectStore, err := objectstorage.NewObjectStoreFromConfiguration(ctx, offloadingConfiguration.ObjectStore)
+			if err != nil {
+				return util.StatusWrap(err, "Failed to create object store")
+			}
+			indirectContentAddressableStorage, err := blobstore_configuration.NewBlobAccessFromConfiguration(
+				dependenciesGroup,
+				offloadingConfiguration.IndirectContentAddressableStorage,
+				blobstore_configuration.NewICASBlobAccessCreator(
+					grpcClientFactory,
+					int(configuration.MaximumMessageSizeBytes)))
+			if err != nil {
+				return util.StatusWrap(err, "Failed to create Indirect Content Addressable Storage for object storage offloading")
+			}
+			instanceNamePrefixes := make([]digest.InstanceName, 0, len(offloadingConfiguration.InstanceNamePrefixes))
+			for _, instanceNamePrefix := range offloadingConfiguration.InstanceNamePrefixes {
+				instanceName, err := digest.NewInstanceName(instanceNamePrefix)
+				if err != nil {
+					return util.StatusWrapf(err, "Invalid object storage offloading instance name prefix %#v", instanceNamePrefix)
+				}
+				instanceNamePrefixes = append(instanceNamePrefixes, instanceName)
+			}
+			newObjectStorageOffloadingBlobAccess = func(base blobstore.BlobAccess) blobstore.BlobAccess {
+				return re_blobstore.NewObjectStorageOffloadingBlobAccess(
+					base,
+					indirectContentAddressableStorage.BlobAccess,
+					objectStore,
+					offloadingConfiguration.KeyPrefix,
+					offloadingConfiguration.MinimumSizeBytes,
+					instanceNamePrefixes)
+			}
+		}
+
+		// Limit the number of concurrent transfers and the
+		// bandwidth used to access storage. Limits that apply to
+		// all storage backends are shared.
+		var globalTransferLimiter *re_blobstore.TransferLimiter
+		if globalTransferLimitConfiguration := configuration.TransferLimits.GetGlobal(); globalTransferLimitConfiguration != nil {
+			globalTransferLimiter = newTransferLimiterFromConfiguration(globalTransferLimitConfiguration)
+		}
+		globalContentAddressableStorage = newTransferLimitingBlobAccess(
+			globalContentAddressableStorage,
+			configuration.TransferLimits.GetContentAddressableStorage(),
+			globalTransferLimiter)
+		actionCache = newTransferLimitingBlobAccess(
+			actionCache,
+			configuration.TransferLimits.GetActionCache(),
+			globalTransferLimiter)
+
+		if existenceCacheConfiguration := configuration.BlobExistenceCache; existenceCacheConfiguration != nil {
+			// Blobs present in one instance's Content
+			// Addressable Storage may be absent in
+			// another's, so include the instance name in
+			// the key.
+			existenceCache, err := digest.NewExistenceCacheFromConfiguration(existenceCacheConfiguration, digest.KeyWithInstance, "BlobExistenceCache")
+			if err != nil {
+				return util.StatusWrap(err, "Failed to create blob existence cache")
+			}
+			globalContentAddressableStorage = blobstore.NewExistenceCachingBlobAccess(globalContentAddressableStorage, existenceCache)
+		}
 		globalContentAddressableStorage = re_blobstore.NewExistencePreconditionBlobAccess(globalContentAddressableStorage)
 
 		var prefetchingDownloadConcurrency *semaphore.Weighted
@@ -104,7 +205,10 @@ func main() {
 			if err != nil {
 				return util.StatusWrap(err, "Failed to create File System Access Cache")
 			}
-			fileSystemAccessCache = info.BlobAccess
+			fileSystemAccessCache = newTransferLimitingBlobAccess(
+				info.BlobAccess,
+				configuration.TransferLimits.GetFileSystemAccessCache(),
+				globalTransferLimiter)
 			prefetchingDownloadConcurrency = semaphore.NewWeighted(prefetchingConfiguration.DownloadConcurrency)
 		}
 
@@ -112,18 +216,67 @@ func main() {
 		// Content Addressable Storage. All workers make use of the same
 		// cache, to increase the hit rate. This process does not read
 		// Tree objects.
-		directoryFetcher, err := cas.NewCachingDirectoryFetcherFromConfiguration(
+		directoryFetcher := cas.NewBlobAccessDirectoryFetcher(
+			globalContentAddressableStorage,
+			/* maximumDirectorySizeBytes = */ int(configuration.MaximumMessageSizeBytes),
+			/* maximumTreeSizeBytes = */ 0)
+		if getTreeConfiguration := configuration.GetTree; getTreeConfiguration != nil {
+			getTreeConnection, err := grpcClientFactory.NewClientFromConfiguration(getTreeConfiguration.Client)
+			if err != nil {
+				return util.StatusWrap(err, "Failed to create GetTree RPC client")
+			}
+			var getTreeCallOptions []grpc.CallOption
+			if getTreeConfiguration.UseGzipCompression {
+				getTreeCallOptions = append(getTreeCallOptions, grpc.UseCompressor(gzip.Name))
+			}
+			maximumPrefetchedDirectories := 10000
+			if n := getTreeConfiguration.MaximumPrefetchedDirectories; n > 0 {
+				maximumPrefetchedDirectories = int(n)
+			}
+			directoryFetcher = cas.NewGetTreePrefetchingDirectoryFetcher(
+				directoryFetcher,
+				remoteexecution.NewContentAddressableStorageClient(getTreeConnection),
+				digest.KeyWithoutInstance,
+				maximumPrefetchedDirectories,
+				getTreeCallOptions)
+		}
+		directoryFetcher, err = cas.NewCachingDirectoryFetcherFromConfiguration(
 			configuration.DirectoryCache,
-			cas.NewBlobAccessDirectoryFetcher(
-				globalContentAddressableStorage,
-				/* maximumDirectorySizeBytes = */ int(configuration.MaximumMessageSizeBytes),
-				/* maximumTreeSizeBytes = */ 0))
+			directoryFetcher)
 		if err != nil {
 			return util.StatusWrap(err, "Failed to create caching directory fetcher")
 		}
 
-		if len(configuration.BuildDirectories) == 0 {
-			return status.Error(codes.InvalidArgument, "Cannot start worker without any build directories")
+		// When running on Kubernetes, annotate completed actions
+		// with the identity of the pod, and permit draining the
+		// worker prior to the pod being terminated.
+		var workerAnnotations map[string]string
+		var workerDrainer *builder.WorkerDrainer
+		if kubernetesConfiguration := configuration.Kubernetes; kubernetesConfiguration != nil {
+			workerAnnotations = map[string]string{}
+			for key, value := range map[string]string{
+				"kubernetes_pod_name":      kubernetesConfiguration.PodName,
+				"kubernetes_pod_namespace": kubernetesConfiguration.PodNamespace,
+				"kubernetes_node_name":     kubernetesConfiguration.NodeName,
+			} {
+				if value != "" {
+					workerAnnotations[key] = value
+				}
+			}
+			workerDrainer = builder.NewWorkerDrainer()
+		}
+
+		// Permit adjusting the amount of diagnostic information
+		// that is logged at runtime.
+		debugFlags := builder.NewDebugFlags()
+		if err := bb_grpc.NewServersFromConfigurationAndServe(
+			configuration.DebugGrpcServers,
+			func(s grpc.ServiceRegistrar) {
+				workerdebug.RegisterWorkerDebugServer(s, debugFlags)
+			},
+			siblingsGroup,
+		); err != nil {
+			return util.StatusWrap(err, "Worker debug gRPC server failure")
 		}
 
 		// Setup the RemoteCompletedActionLogger for the
@@ -160,21 +313,270 @@ func main() {
 			}()
 		}
 
+		// Optionally stream the output of actions to LogStreams
+		// while they are running.
+		var outputLogStreamer *builder.OutputLogStreamer
+		if outputLogStreamingConfiguration := configuration.OutputLogStreaming; outputLogStreamingConfiguration != nil {
+			outputLogStreamingConnection, err := grpcClientFactory.NewClientFromConfiguration(outputLogStreamingConfiguration.Endpoint)
+			if err != nil {
+				return util.StatusWrap(err, "Failed to create output log streaming RPC client")
+			}
+			if err := outputLogStreamingConfiguration.PollInterval.CheckValid(); err != nil {
+				return util.StatusWrap(err, "Invalid output log streaming poll interval")
+			}
+			outputLogStreamer = builder.NewOutputLogStreamer(
+				remotelogstream.NewLogStreamServiceClient(outputLogStreamingConnection),
+				bytestream.NewByteStreamClient(outputLogStreamingConnection),
+				clock.SystemClock,
+				outputLogStreamingConfiguration.PollInterval.AsDuration())
+		}
+
 		outputUploadConcurrency := configuration.OutputUploadConcurrency
 		if outputUploadConcurrency <= 0 {
 			return status.Errorf(codes.InvalidArgument, "Nonpositive output upload concurrency: ", outputUploadConcurrency)
 		}
 		outputUploadConcurrencySemaphore := semaphore.NewWeighted(outputUploadConcurrency)
 
+		// Errors reported asynchronously by the virtual file
+		// system may optionally be deduplicated and rate limited,
+		// so that logs remain readable when storage misbehaves.
+		virtualFileSystemErrorLogger := util.DefaultErrorLogger
+		if errorLoggingConfiguration := configuration.ErrorLogging; errorLoggingConfiguration != nil {
+			if err := errorLoggingConfiguration.Interval.CheckValid(); err != nil {
+				return util.StatusWrap(err, "Invalid error logging interval")
+			}
+			virtualFileSystemErrorLogger = re_util.NewDeduplicatingErrorLogger(
+				util.DefaultErrorLogger,
+				clock.SystemClock,
+				"virtual_file_system",
+				errorLoggingConfiguration.Interval.AsDuration(),
+				int(errorLoggingConfiguration.MaximumErrorsPerInterval))
+		}
+
+		// Functions that can be called through the admin HTTP
+		// server to discard the contents of input file caches.
+		var inputFileCacheFlushers []inputFileCacheFlusher
+
+		// Optionally expose a virtual file system through which
+		// arbitrary blobs in the Content Addressable Storage can
+		// be accessed by digest.
+		if casMountConfiguration := configuration.CasMount; casMountConfiguration != nil {
+			instanceName, err := digest.NewInstanceName(casMountConfiguration.InstanceName)
+			if err != nil {
+				return util.StatusWrapf(err, "Invalid instance name for CAS mount %#v", casMountConfiguration.InstanceName)
+			}
+			casMount, casHandleAllocator, err := virtual_configuration.NewMountFromConfiguration(
+				casMountConfiguration.Mount,
+				"bb_worker_cas",
+				/* rootDirectory = */ virtual_configuration.LongAttributeCaching,
+				/* childDirectories = */ virtual_configuration.LongAttributeCaching,
+				/* leaves = */ virtual_configuration.LongAttributeCaching)
+			if err != nil {
+				return util.StatusWrap(err, "Failed to create CAS mount")
+			}
+			casFileReadaheadOptions, err := newCASFileReadaheadOptionsFromConfiguration(casMountConfiguration.CasFileReadahead)
+			if err != nil {
+				return util.StatusWrap(err, "Invalid CAS file readahead configuration for CAS mount")
+			}
+			casReadaheadFileFactory := virtual.NewReadaheadBlobAccessCASFileFactory(
+				ctx,
+				globalContentAddressableStorage,
+				virtualFileSystemErrorLogger,
+				casFileReadaheadOptions)
+			inputFileCacheFlushers = append(inputFileCacheFlushers, casReadaheadFileFactory.FlushCachedFiles)
+			if err := startReadaheadIdleEviction(dependenciesGroup, casMountConfiguration.CasFileReadahead, casReadaheadFileFactory, "CAS mount"); err != nil {
+				return util.StatusWrap(err, "Invalid CAS file readahead configuration for CAS mount")
+			}
+			casFileFactory := virtual.NewResolvableHandleAllocatingCASFileFactory(
+				casReadaheadFileFactory,
+				casHandleAllocator.New())
+			// Tree objects are not read by the rest of this
+			// process, so use a separate directory fetcher
+			// that permits loading them.
+			casTreeDirectoryFetcher, err := cas.NewCachingDirectoryFetcherFromConfiguration(
+				configuration.DirectoryCache,
+				cas.NewBlobAccessDirectoryFetcher(
+					globalContentAddressableStorage,
+					/* maximumDirectorySizeBytes = */ int(configuration.MaximumMessageSizeBytes),
+					casMountConfiguration.MaximumTreeSizeBytes))
+			if err != nil {
+				return util.StatusWrap(err, "Failed to create caching directory fetcher for CAS mount")
+			}
+			casDirectory, err := newCASDirectory(
+				ctx,
+				instanceName,
+				casFileFactory,
+				casTreeDirectoryFetcher,
+				actionCache,
+				int(configuration.MaximumMessageSizeBytes),
+				virtual.NewHandleAllocatingSymlinkFactory(
+					virtual.BaseSymlinkFactory,
+					casHandleAllocator.New()),
+				virtualFileSystemErrorLogger,
+				casHandleAllocator,
+				casMountConfiguration.CaseInsensitiveLookups)
+			if err != nil {
+				return err
+			}
+			if err := casMount.Expose(dependenciesGroup, casDirectory, nil); err != nil {
+				return util.StatusWrap(err, "Failed to expose CAS mount")
+			}
+		}
+
+		// Per-instance name policies for overriding flags
+		// controlling caching behavior.
+		cacheFlagOverrides := make([]builder.CacheFlagOverride, 0, len(configuration.CacheFlagOverrides))
+		for _, cacheFlagOverrideConfiguration := range configuration.CacheFlagOverrides {
+			instanceNamePrefix, err := digest.NewInstanceName(cacheFlagOverrideConfiguration.InstanceNamePrefix)
+			if err != nil {
+				return util.StatusWrapf(err, "Invalid instance name prefix %#v", cacheFlagOverrideConfiguration.InstanceNamePrefix)
+			}
+			doNotCache, err := newCacheFlagPolicyFromConfiguration(cacheFlagOverrideConfiguration.DoNotCache)
+			if err != nil {
+				return util.StatusWrapf(err, "Invalid do_not_cache policy for instance name prefix %#v", cacheFlagOverrideConfiguration.InstanceNamePrefix)
+			}
+			cacheFlagOverrides = append(cacheFlagOverrides, builder.CacheFlagOverride{
+				InstanceNamePrefix: instanceNamePrefix,
+				DoNotCache:         doNotCache,
+			})
+		}
+
+		// Helper binaries, such as runner shims and tool wrappers,
+		// that are downloaded from the Content Addressable Storage.
+		// These are installed at startup, and repaired in between
+		// actions if needed.
+		var helperBinaryInstaller *builder.HelperBinaryInstaller
+		if len(configuration.HelperBinaries) > 0 {
+			helperBinaries := make([]builder.HelperBinary, 0, len(configuration.HelperBinaries))
+			for _, helperBinaryConfiguration := range configuration.HelperBinaries {
+				instanceName, err := digest.NewInstanceName(helperBinaryConfiguration.InstanceName)
+				if err != nil {
+					return util.StatusWrapf(err, "Invalid instance name for helper binary %#v", helperBinaryConfiguration.Path)
+				}
+				digestFunction, err := instanceName.GetDigestFunction(helperBinaryConfiguration.DigestFunction, 0)
+				if err != nil {
+					return util.StatusWrapf(err, "Invalid digest function for helper binary %#v", helperBinaryConfiguration.Path)
+				}
+				helperBinaryDigest, err := digestFunction.NewDigestFromProto(helperBinaryConfiguration.Digest)
+				if err != nil {
+					return util.StatusWrapf(err, "Invalid digest for helper binary %#v", helperBinaryConfiguration.Path)
+				}
+				helperBinaries = append(helperBinaries, builder.HelperBinary{
+					Path:         helperBinaryConfiguration.Path,
+					Digest:       helperBinaryDigest,
+					IsExecutable: helperBinaryConfiguration.IsExecutable,
+				})
+			}
+			helperBinaryInstaller = builder.NewHelperBinaryInstaller(globalContentAddressableStorage, helperBinaries)
+			if err := helperBinaryInstaller.EnsureInstalled(ctx); err != nil {
+				return err
+			}
+		}
+
+		// Facts about the worker host, used to extend the platform
+		// properties advertised by all runners. This reduces
+		// configuration drift across heterogeneous fleets.
+		var platformPropertyTemplates []builder.PlatformPropertyTemplate
+		var hostFacts map[string]string
+		if platformDiscoveryConfiguration := configuration.PlatformDiscovery; platformDiscoveryConfiguration != nil {
+			facts := make([]builder.HostFact, 0, len(platformDiscoveryConfiguration.Facts))
+			for _, factConfiguration := range platformDiscoveryConfiguration.Facts {
+				var source builder.HostFactSource
+				switch {
+				case factConfiguration.FilePath != "" && len(factConfiguration.Command) == 0:
+					source = builder.NewFileHostFactSource(factConfiguration.FilePath)
+				case factConfiguration.FilePath == "" && len(factConfiguration.Command) > 0:
+					source = builder.NewCommandHostFactSource(factConfiguration.Command)
+				default:
+					return status.Errorf(codes.InvalidArgument, "Fact %#v must have either a file path or a command", factConfiguration.Name)
+				}
+				facts = append(facts, builder.HostFact{
+					Name:         factConfiguration.Name,
+					Source:       source,
+					IgnoreErrors: factConfiguration.IgnoreErrors,
+				})
+			}
+			hostFacts, err = builder.DiscoverHostFacts(ctx, facts)
+			if err != nil {
+				return util.StatusWrap(err, "Failed to discover facts about the worker host")
+			}
+			if nodeLabelsPath := configuration.Kubernetes.GetNodeLabelsPath(); nodeLabelsPath != "" {
+				data, err := os.ReadFile(nodeLabelsPath)
+				if err != nil {
+					return util.StatusWrapf(err, "Failed to read Kubernetes node labels from %#v", nodeLabelsPath)
+				}
+				nodeLabels, err := builder.ParseKubernetesLabels(data)
+				if err != nil {
+					return util.StatusWrapf(err, "Failed to parse Kubernetes node labels from %#v", nodeLabelsPath)
+				}
+				for key, value := range nodeLabels {
+					hostFacts["node_label:"+key] = value
+				}
+			}
+			for _, propertyConfiguration := range platformDiscoveryConfiguration.Properties {
+				platformPropertyTemplate, err := builder.NewPlatformPropertyTemplate(propertyConfiguration.Name, propertyConfiguration.Value)
+				if err != nil {
+					return err
+				}
+				platformPropertyTemplates = append(platformPropertyTemplates, platformPropertyTemplate)
+			}
+		}
+
+		// Keep track of the actions executed by all threads, so
+		// that they can be displayed in the web UI.
+		workerStatus := builder.NewWorkerStatus(clock.SystemClock, 100)
+
 		testInfrastructureFailureShutdownState := builder.NewTestInfrastructureFailureShutdownState()
-		for _, buildDirectoryConfiguration := range configuration.BuildDirectories {
+
+		// Merge uploads of identical output files that are
+		// produced by actions running concurrently, so that
+		// they are only transferred to storage once. Uploads are
+		// only merged if they target the same instance name, as
+		// storage may be partitioned by instance name.
+		//
+		// As all output files are uploaded through this
+		// BlobAccess, this is also where large output files are
+		// offloaded into the object store, if enabled.
+		outputContentAddressableStorage := globalContentAddressableStorage
+		if newObjectStorageOffloadingBlobAccess != nil {
+			outputContentAddressableStorage = newObjectStorageOffloadingBlobAccess(outputContentAddressableStorage)
+		}
+		deduplicatedContentAddressableStorage := re_blobstore.NewInFlightDeduplicatingBlobAccess(
+			outputContentAddressableStorage,
+			digest.KeyWithInstance)
+
+		// Sockets for nested execution are numbered sequentially
+		// across all worker threads.
+		nestedExecutionSocketCount := 0
+		for buildDirectoryIndex, buildDirectoryConfiguration := range configuration.BuildDirectories {
+			// Build directories may use a dedicated file pool,
+			// so that output files of one build directory
+			// cannot exhaust the space of another.
+			buildDirectoryFilePool := filePool
+			if filePoolConfiguration := buildDirectoryConfiguration.FilePool; filePoolConfiguration != nil {
+				buildDirectoryFilePool, err = re_filesystem.NewFilePoolFromConfiguration(filePoolConfiguration)
+				if err != nil {
+					return util.StatusWrapf(err, "Failed to create file pool for build directory at index %d", buildDirectoryIndex)
+				}
+			}
+
 			var virtualBuildDirectory virtual.PrepopulatedDirectory
 			var handleAllocator virtual.StatefulHandleAllocator
 			var symlinkFactory virtual.SymlinkFactory
+			var symlinkTargetRewriter virtual.SymlinkTargetRewriter
 			var characterDeviceFactory virtual.CharacterDeviceFactory
+			var backgroundUploadContentAddressableStorage blobstore.BlobAccess
+			var quiescentFileConversionContentAddressableStorage blobstore.BlobAccess
+			var quiescentFileConversionPeriod time.Duration
+			var fileDeduplicationOptions *virtual.FileDeduplicationOptions
+			var casFileReadaheadOptions *virtual.CASFileReadaheadOptions
 			var naiveBuildDirectory filesystem.DirectoryCloser
 			var fileFetcher cas.FileFetcher
 			var buildDirectoryCleaner cleaner.Cleaner
+			var cacheScrubber cleaner.Cleaner
+			var cacheScrubbingInterval time.Duration
+			var cacheIdleEvicter func() (int, error)
+			var cacheIdleEvictionInterval time.Duration
 			uploadBatchSize := blobstore.RecommendedFindMissingDigestsCount
 			var maximumExecutionTimeoutCompensation time.Duration
 			switch backend := buildDirectoryConfiguration.Backend.(type) {
@@ -209,23 +611,147 @@ func main() {
 				characterDeviceFactory = virtual.NewHandleAllocatingCharacterDeviceFactory(
 					virtual.BaseCharacterDeviceFactory,
 					handleAllocator.New())
+				fileAllocator := virtual.NewPoolBackedFileAllocator(
+					re_filesystem.EmptyFilePool,
+					virtualFileSystemErrorLogger,
+					clock.SystemClock)
+				if leakDetection := backend.Virtual.ReferenceCountLeakDetection; leakDetection != nil {
+					// Periodically report files that have
+					// been unlinked, but are still opened.
+					if err := leakDetection.MinimumLeakAge.CheckValid(); err != nil {
+						return util.StatusWrap(err, "Invalid reference count leak detection minimum leak age")
+					}
+					if err := leakDetection.ReportInterval.CheckValid(); err != nil {
+						return util.StatusWrap(err, "Invalid reference count leak detection report interval")
+					}
+					leakDetectingFileAllocator := virtual.NewReferenceCountLeakDetectingFileAllocator(
+						fileAllocator,
+						clock.SystemClock,
+						virtualFileSystemErrorLogger,
+						leakDetection.MinimumLeakAge.AsDuration())
+					reportInterval := leakDetection.ReportInterval.AsDuration()
+					dependenciesGroup.Go(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+						for {
+							timer, t := clock.SystemClock.NewTimer(reportInterval)
+							select {
+							case <-t:
+								leakDetectingFileAllocator.ReportLeaks()
+							case <-ctx.Done():
+								timer.Stop()
+								return nil
+							}
+						}
+					})
+					fileAllocator = leakDetectingFileAllocator
+				}
 				virtualBuildDirectory = virtual.NewInMemoryPrepopulatedDirectory(
 					virtual.NewHandleAllocatingFileAllocator(
-						virtual.NewPoolBackedFileAllocator(
-							re_filesystem.EmptyFilePool,
-							util.DefaultErrorLogger),
+						fileAllocator,
 						handleAllocator),
 					symlinkFactory,
-					util.DefaultErrorLogger,
+					virtualFileSystemErrorLogger,
 					handleAllocator,
 					initialContentsSorter,
 					hiddenFilesPattern,
-					clock.SystemClock)
+					clock.SystemClock,
+					backend.Virtual.CaseInsensitiveLookups)
 
-				if err := mount.Expose(dependenciesGroup, virtualBuildDirectory); err != nil {
+				// Let statfs() report the capacity of the
+				// file pool, if it is capable of providing
+				// it. Build directories of individual
+				// actions report the capacity of their
+				// quota enforcing file pools instead.
+				var statFSProvider virtual.StatFSProvider
+				if _, err := re_filesystem.GetFilePoolUsage(buildDirectoryFilePool); err == nil {
+					statFSProvider = virtual.NewFilePoolStatFSProvider(buildDirectoryFilePool, virtualFileSystemErrorLogger)
+				}
+				if err := mount.Expose(dependenciesGroup, virtualBuildDirectory, statFSProvider); err != nil {
 					return util.StatusWrap(err, "Failed to expose build directory mount")
 				}
 
+				// Optionally upload output files while
+				// build actions are still running. These
+				// uploads bypass batching, as files cannot
+				// be modified while being uploaded.
+				if backend.Virtual.UploadOutputsInBackground {
+					backgroundUploadContentAddressableStorage = blobstore.NewMetricsBlobAccess(
+						deduplicatedContentAddressableStorage,
+						clock.SystemClock,
+						"cas",
+						"background_upload")
+				}
+				// Optionally upload output files that are
+				// no longer modified while build actions
+				// are still running, so that they no longer
+				// occupy space in the file pool.
+				if period := backend.Virtual.QuiescentFileConversionPeriod; period != nil {
+					if err := period.CheckValid(); err != nil {
+						return util.StatusWrap(err, "Invalid quiescent file conversion period")
+					}
+					quiescentFileConversionPeriod = period.AsDuration()
+					if quiescentFileConversionPeriod <= 0 {
+						return status.Error(codes.InvalidArgument, "Quiescent file conversion period must be positive")
+					}
+					quiescentFileConversionContentAddressableStorage = blobstore.NewMetricsBlobAccess(
+						deduplicatedContentAddressableStorage,
+						clock.SystemClock,
+						"cas",
+						"quiescent_file_conversion")
+				}
+				fileDeduplicationOptions, err = newFileDeduplicationOptionsFromConfiguration(backend.Virtual.FileDeduplication)
+				if err != nil {
+					return util.StatusWrap(err, "Invalid file deduplication configuration for build directory")
+				}
+				casFileReadaheadOptions, err = newCASFileReadaheadOptionsFromConfiguration(backend.Virtual.CasFileReadahead)
+				if err != nil {
+					return util.StatusWrap(err, "Invalid CAS file readahead configuration for build directory")
+				}
+				symlinkTargetRewriter, err = newSymlinkTargetRewriterFromConfiguration(backend.Virtual.SymlinkTargetPolicy)
+				if err != nil {
+					return util.StatusWrap(err, "Invalid symlink target policy for build directory")
+				}
+
+				// Optionally allow inspecting the state of the
+				// virtual file system through gRPC.
+				var migrationFilePool re_filesystem.FilePool
+				if migrationConfiguration := backend.Virtual.DebugMigrationFilePool; migrationConfiguration != nil {
+					migrationFilePool, err = re_filesystem.NewFilePoolFromConfiguration(migrationConfiguration)
+					if err != nil {
+						return util.StatusWrap(err, "Failed to create debug migration file pool")
+					}
+				}
+				// Unlike the files created for individual
+				// build actions, the readahead state of files
+				// created by the debug server is retained for
+				// the lifetime of the worker.
+				debugReadaheadFileFactory := virtual.NewReadaheadBlobAccessCASFileFactory(
+					ctx,
+					globalContentAddressableStorage,
+					virtualFileSystemErrorLogger,
+					casFileReadaheadOptions)
+				inputFileCacheFlushers = append(inputFileCacheFlushers, debugReadaheadFileFactory.FlushCachedFiles)
+				if err := startReadaheadIdleEviction(dependenciesGroup, backend.Virtual.CasFileReadahead, debugReadaheadFileFactory, "debug server"); err != nil {
+					return util.StatusWrap(err, "Invalid CAS file readahead configuration for build directory")
+				}
+				debugServer := virtual.NewDebugServer(
+					virtualBuildDirectory,
+					migrationFilePool,
+					&virtual.DebugServerFlushOptions{
+						ContentAddressableStorage: deduplicatedContentAddressableStorage,
+						CASFileFactory: virtual.NewStatelessHandleAllocatingCASFileFactory(
+							debugReadaheadFileFactory,
+							handleAllocator.New()),
+					})
+				if err := bb_grpc.NewServersFromConfigurationAndServe(
+					backend.Virtual.DebugGrpcServers,
+					func(s grpc.ServiceRegistrar) {
+						virtualfilesystemdebug.RegisterVirtualFileSystemDebugServer(s, debugServer)
+					},
+					siblingsGroup,
+				); err != nil {
+					return util.StatusWrap(err, "Virtual file system debug gRPC server failure")
+				}
+
 				buildDirectoryCleaner = func(ctx context.Context) error {
 					if err := virtualBuildDirectory.RemoveAllChildren(false); err != nil {
 						return util.StatusWrapWithCode(err, codes.Internal, "Failed to clean virtual build directory")
@@ -245,30 +771,153 @@ func main() {
 				}
 				buildDirectoryCleaner = cleaner.NewDirectoryCleaner(naiveBuildDirectory, nativeConfiguration.BuildDirectoryPath)
 
-				// Create a cache directory that holds input
-				// files that can be hardlinked into build
-				// directory.
-				//
-				// TODO: Have a single process-wide hardlinking
-				// cache even if multiple build directories are
-				// used. This increases cache hit rate.
-				cacheDirectory, err := filesystem.NewLocalDirectory(nativeConfiguration.CacheDirectoryPath)
-				if err != nil {
-					return util.StatusWrap(err, "Failed to open cache directory")
-				}
-				if err := cacheDirectory.RemoveAllChildren(); err != nil {
-					return util.StatusWrap(err, "Failed to clear cache directory")
-				}
-				evictionSet, err := eviction.NewSetFromConfiguration[string](nativeConfiguration.CacheReplacementPolicy)
-				if err != nil {
-					return util.StatusWrap(err, "Failed to create eviction set for cache directory")
+				if packedCacheConfiguration := nativeConfiguration.PackedCache; packedCacheConfiguration != nil {
+					// Store input files in a single pack
+					// file, copying them into the build
+					// directory.
+					if nativeConfiguration.CacheDirectoryFanOutLevels != 0 || nativeConfiguration.CacheScrubbing != nil || nativeConfiguration.CacheIdleEviction != nil || nativeConfiguration.MigrateFromPackedCache != nil {
+						return status.Error(codes.InvalidArgument, "The packed input file cache cannot be combined with options that only apply to the cache directory")
+					}
+					packedFileFetcher, err := newPackedFileFetcherFromConfiguration(
+						packedCacheConfiguration,
+						cas.NewBlobAccessFileFetcher(globalContentAddressableStorage),
+						int(nativeConfiguration.MaximumCacheFileCount))
+					if err != nil {
+						return err
+					}
+					fileFetcher = packedFileFetcher
+					inputFileCacheFlushers = append(inputFileCacheFlushers, packedFileFetcher.FlushCachedFiles)
+					if packedCacheConfiguration.IndexDirectoryPath != "" {
+						if err := packedCacheConfiguration.IndexWriteInterval.CheckValid(); err != nil {
+							return util.StatusWrap(err, "Invalid packed input file cache index write interval")
+						}
+						indexWriteInterval := packedCacheConfiguration.IndexWriteInterval.AsDuration()
+						if indexWriteInterval <= 0 {
+							return status.Error(codes.InvalidArgument, "Packed input file cache index write interval must be positive")
+						}
+						runPackedCacheIndexWriter(dependenciesGroup, indexWriteInterval, packedFileFetcher)
+					}
+					if nativeConfiguration.PreserveCacheDirectory {
+						// Move files left behind in the
+						// cache directory by a previous
+						// invocation into the pack file in
+						// the background, so that build
+						// actions can start immediately.
+						cacheDirectory, err := filesystem.NewLocalDirectory(nativeConfiguration.CacheDirectoryPath)
+						if err != nil {
+							return util.StatusWrap(err, "Failed to open cache directory")
+						}
+						dependenciesGroup.Go(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+							defer cacheDirectory.Close()
+							if importedCount, err := packedFileFetcher.ImportCachedFiles(ctx, cacheDirectory); err != nil {
+								log.Print("Failed to import files in cache directory into pack file: ", err)
+							} else {
+								log.Printf("Imported %d files in cache directory into pack file", importedCount)
+							}
+							return nil
+						})
+					}
+				} else {
+					// Create a cache directory that holds input
+					// files that can be hardlinked into build
+					// directory.
+					//
+					// TODO: Have a single process-wide hardlinking
+					// cache even if multiple build directories are
+					// used. This increases cache hit rate.
+					cacheDirectory, err := filesystem.NewLocalDirectory(nativeConfiguration.CacheDirectoryPath)
+					if err != nil {
+						return util.StatusWrap(err, "Failed to open cache directory")
+					}
+					if !nativeConfiguration.PreserveCacheDirectory {
+						if err := cacheDirectory.RemoveAllChildren(); err != nil {
+							return util.StatusWrap(err, "Failed to clear cache directory")
+						}
+					}
+					evictionSet, err := eviction.NewSetFromConfiguration[string](nativeConfiguration.CacheReplacementPolicy)
+					if err != nil {
+						return util.StatusWrap(err, "Failed to create eviction set for cache directory")
+					}
+					cacheLayout := cas.FlatHardlinkingCacheLayout
+					if levels := nativeConfiguration.CacheDirectoryFanOutLevels; levels > 0 {
+						if levels > 4 {
+							return status.Errorf(codes.InvalidArgument, "Cache directory fan-out levels must be at most 4, while %d levels were provided", levels)
+						}
+						cacheLayout = cas.NewFanOutHardlinkingCacheLayout(int(levels))
+					}
+					hardlinkingFileFetcher := cas.NewHardlinkingFileFetcher(
+						cas.NewBlobAccessFileFetcher(globalContentAddressableStorage),
+						cacheDirectory,
+						cacheLayout,
+						int(nativeConfiguration.MaximumCacheFileCount),
+						nativeConfiguration.MaximumCacheSizeBytes,
+						eviction.NewMetricsSet(evictionSet, "HardlinkingFileFetcher"),
+						nativeConfiguration.CacheVerificationProbability,
+						random.FastThreadSafeGenerator,
+						clock.SystemClock,
+						nativeConfiguration.CacheDirectoryPath)
+					fileFetcher = hardlinkingFileFetcher
+					inputFileCacheFlushers = append(inputFileCacheFlushers, hardlinkingFileFetcher.FlushCachedFiles)
+					var previousPackedFileFetcher cas.PackedFileFetcher
+					if migrateConfiguration := nativeConfiguration.MigrateFromPackedCache; migrateConfiguration != nil {
+						if !nativeConfiguration.PreserveCacheDirectory {
+							return status.Error(codes.InvalidArgument, "Migrating files from a packed input file cache requires that the cache directory is preserved")
+						}
+						previousPackedFileFetcher, err = newPackedFileFetcherFromConfiguration(
+							migrateConfiguration,
+							cas.NewBlobAccessFileFetcher(globalContentAddressableStorage),
+							int(nativeConfiguration.MaximumCacheFileCount))
+						if err != nil {
+							return err
+						}
+					}
+					if nativeConfiguration.PreserveCacheDirectory {
+						// Adopt files left behind by a previous
+						// invocation in the background, so that
+						// build actions can start immediately.
+						dependenciesGroup.Go(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+							if previousPackedFileFetcher != nil {
+								if exportedCount, err := previousPackedFileFetcher.ExportCachedFiles(ctx, cacheDirectory); err != nil {
+									log.Print("Failed to export files in pack file into cache directory: ", err)
+								} else {
+									log.Printf("Exported %d files in pack file into cache directory", exportedCount)
+								}
+							}
+							if migratedCount, err := hardlinkingFileFetcher.MigrateCachedFiles(ctx); err != nil {
+								log.Print("Failed to migrate files in input file cache: ", err)
+							} else {
+								log.Printf("Migrated %d files in input file cache", migratedCount)
+							}
+							return nil
+						})
+					}
+					if scrubbingConfiguration := nativeConfiguration.CacheScrubbing; scrubbingConfiguration != nil {
+						if err := scrubbingConfiguration.Interval.CheckValid(); err != nil {
+							return util.StatusWrap(err, "Invalid cache scrubbing interval")
+						}
+						cacheScrubbingInterval = scrubbingConfiguration.Interval.AsDuration()
+						maximumFilesPerInterval := int(scrubbingConfiguration.MaximumFilesPerInterval)
+						cacheScrubber = func(ctx context.Context) error {
+							return hardlinkingFileFetcher.ScrubCachedFiles(ctx, maximumFilesPerInterval)
+						}
+					}
+					if idleEvictionConfiguration := nativeConfiguration.CacheIdleEviction; idleEvictionConfiguration != nil {
+						// Idle files can only be found
+						// efficiently if the eviction set is
+						// ordered by access time.
+						if nativeConfiguration.CacheReplacementPolicy != eviction_pb.CacheReplacementPolicy_LEAST_RECENTLY_USED {
+							return status.Error(codes.InvalidArgument, "Evicting idle files from the cache directory requires the LEAST_RECENTLY_USED cache replacement policy")
+						}
+						var maximumIdleTime time.Duration
+						cacheIdleEvictionInterval, maximumIdleTime, err = newCacheIdleEvictionParametersFromConfiguration(idleEvictionConfiguration)
+						if err != nil {
+							return err
+						}
+						cacheIdleEvicter = func() (int, error) {
+							return hardlinkingFileFetcher.EvictIdleFiles(maximumIdleTime)
+						}
+					}
 				}
-				fileFetcher = cas.NewHardlinkingFileFetcher(
-					cas.NewBlobAccessFileFetcher(globalContentAddressableStorage),
-					cacheDirectory,
-					int(nativeConfiguration.MaximumCacheFileCount),
-					nativeConfiguration.MaximumCacheSizeBytes,
-					eviction.NewMetricsSet(evictionSet, "HardlinkingFileFetcher"))
 
 				// Using a native file system requires us to
 				// hold on to file descriptors while uploading
@@ -280,14 +929,35 @@ func main() {
 			}
 
 			buildDirectoryIdleInvoker := cleaner.NewIdleInvoker(buildDirectoryCleaner)
-			var sharedBuildDirectoryNextParallelActionID atomic.Uint64
-			if len(buildDirectoryConfiguration.Runners) == 0 {
-				return util.StatusWrap(err, "Cannot start worker without any runners")
+			if cacheScrubber != nil {
+				// Validate the contents of the input file
+				// cache while no build actions are running.
+				dependenciesGroup.Go(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+					for {
+						timer, t := clock.SystemClock.NewTimer(cacheScrubbingInterval)
+						select {
+						case <-t:
+							if _, err := buildDirectoryIdleInvoker.InvokeIfIdle(ctx, cacheScrubber); err != nil {
+								log.Print("Failed to scrub input file cache: ", err)
+							}
+						case <-ctx.Done():
+							timer.Stop()
+							return nil
+						}
+					}
+				})
 			}
+			if cacheIdleEvicter != nil {
+				// Remove files from the input file cache
+				// that are no longer used by any build
+				// actions. Files that are hardlinked into
+				// build directories remain accessible, so
+				// there is no need to wait for the build
+				// directory to become idle.
+				runCacheIdleEviction(dependenciesGroup, cacheIdleEvictionInterval, cacheIdleEvicter, "input file cache")
+			}
+			buildDirectoryCreatorFactory := newSubdirectoryBuildDirectoryCreatorFactory()
 			for _, runnerConfiguration := range buildDirectoryConfiguration.Runners {
-				if runnerConfiguration.Concurrency < 1 {
-					return util.StatusWrap(err, "Runner concurrency must be positive")
-				}
 				concurrencyLength := len(strconv.FormatUint(runnerConfiguration.Concurrency-1, 10))
 
 				// Obtain raw device numbers of character
@@ -311,164 +981,672 @@ func main() {
 				}
 				runnerClient := runner_pb.NewRunnerClient(runnerConnection)
 
-				for threadID := uint64(0); threadID < runnerConfiguration.Concurrency; threadID++ {
-					// Per-worker separate writer of the Content
-					// Addressable Storage that batches writes after
-					// completing the build action.
-					contentAddressableStorageWriter, contentAddressableStorageFlusher := re_blobstore.NewBatchedStoreBlobAccess(
-						globalContentAddressableStorage,
-						digest.KeyWithoutInstance,
-						uploadBatchSize,
-						outputUploadConcurrencySemaphore)
-					contentAddressableStorageWriter = blobstore.NewMetricsBlobAccess(
-						contentAddressableStorageWriter,
+				// Optionally quarantine all worker threads of
+				// this runner if too many actions fail due to
+				// infrastructure errors.
+				var infrastructureErrorBudget *builder.InfrastructureErrorBudget
+				if errorBudgetConfiguration := runnerConfiguration.InfrastructureErrorBudget; errorBudgetConfiguration != nil {
+					if errorBudgetConfiguration.WindowSize < 1 {
+						return status.Error(codes.InvalidArgument, "Infrastructure error budget window size must be positive")
+					}
+					if err := errorBudgetConfiguration.QuarantineDuration.CheckValid(); err != nil {
+						return util.StatusWrap(err, "Invalid infrastructure error budget quarantine duration")
+					}
+					infrastructureErrorBudget = builder.NewInfrastructureErrorBudget(
 						clock.SystemClock,
-						"cas",
-						"batched_store")
-
-					// When the virtual file system is
-					// enabled, we can lazily load the input
-					// root, as opposed to explicitly
-					// instantiating it before every build.
-					var executionTimeoutClock clock.Clock
-					var buildDirectory builder.BuildDirectory
-					if virtualBuildDirectory != nil {
-						suspendableClock := re_clock.NewSuspendableClock(
-							clock.SystemClock,
-							maximumExecutionTimeoutCompensation,
-							time.Second/10)
-						executionTimeoutClock = suspendableClock
-						buildDirectory = builder.NewVirtualBuildDirectory(
-							virtualBuildDirectory,
-							cas.NewSuspendingDirectoryFetcher(
-								directoryFetcher,
-								suspendableClock),
-							re_blobstore.NewSuspendingBlobAccess(
-								contentAddressableStorageWriter,
-								suspendableClock),
-							symlinkFactory,
-							characterDeviceFactory,
-							handleAllocator)
-					} else {
-						executionTimeoutClock = clock.SystemClock
-						buildDirectory = builder.NewNaiveBuildDirectory(
-							naiveBuildDirectory,
-							directoryFetcher,
-							fileFetcher,
-							contentAddressableStorageWriter)
+						int(errorBudgetConfiguration.WindowSize),
+						int(errorBudgetConfiguration.MaximumFailures),
+						errorBudgetConfiguration.QuarantineDuration.AsDuration())
+				}
+
+				pathMappings := make([]builder.PathMapping, 0, len(runnerConfiguration.PathMappings))
+				for i, pathMappingConfiguration := range runnerConfiguration.PathMappings {
+					if pathMappingConfiguration.Directory == "" || pathMappingConfiguration.CanonicalName == "" || strings.Contains(pathMappingConfiguration.CanonicalName, "/") {
+						return status.Errorf(codes.InvalidArgument, "Path mapping at index %d must have a directory and a canonical name that is a single pathname component", i)
 					}
+					pathMappings = append(pathMappings, builder.PathMapping{
+						Directory:     strings.Trim(pathMappingConfiguration.Directory, "/"),
+						CanonicalName: pathMappingConfiguration.CanonicalName,
+					})
+				}
 
-					// Create a per-action subdirectory in
-					// the build directory named after the
-					// action digest, so that multiple
-					// actions may be run concurrently.
-					//
-					// Also clean the build directory every
-					// time when going from fully idle to
-					// executing one action.
-					buildDirectoryCreator := builder.NewSharedBuildDirectoryCreator(
-						builder.NewCleanBuildDirectoryCreator(
-							builder.NewRootBuildDirectoryCreator(buildDirectory),
-							buildDirectoryIdleInvoker),
-						&sharedBuildDirectoryNextParallelActionID)
-
-					workerID := map[string]string{}
-					if runnerConfiguration.Concurrency > 1 {
-						workerID["thread"] = fmt.Sprintf("%0*d", concurrencyLength, threadID)
+				// Actions may submit sub-actions to the
+				// scheduler through a socket that is created
+				// for every worker thread.
+				var nestedExecutionClient remoteexecution.ExecutionClient
+				if nestedExecutionConfiguration := runnerConfiguration.NestedExecution; nestedExecutionConfiguration != nil {
+					nestedExecutionConnection, err := grpcClientFactory.NewClientFromConfiguration(nestedExecutionConfiguration.Scheduler)
+					if err != nil {
+						return util.StatusWrap(err, "Failed to create nested execution scheduler RPC client")
+					}
+					nestedExecutionClient = remoteexecution.NewExecutionClient(nestedExecutionConnection)
+				}
+
+				// Shared caches are shared by all worker
+				// threads of the runner.
+				sharedCaches := make([]*builder.SharedCache, 0, len(runnerConfiguration.SharedCaches))
+				for i, sharedCacheConfiguration := range runnerConfiguration.SharedCaches {
+					sharedCache, err := builder.NewSharedCache(
+						sharedCacheConfiguration.Path,
+						globalContentAddressableStorage,
+						directoryFetcher,
+						sharedCacheConfiguration.MaximumSizeBytes)
+					if err != nil {
+						return util.StatusWrapf(err, "Invalid shared cache at index %d", i)
 					}
-					for k, v := range runnerConfiguration.WorkerId {
-						workerID[k] = v
+					sharedCaches = append(sharedCaches, sharedCache)
+				}
+
+				// Worker threads may be registered in multiple
+				// platform queues or against multiple
+				// schedulers, sharing the same build directory
+				// and runner. In that case the number of
+				// actions executed concurrently across all
+				// platform queues and schedulers is limited.
+				platformQueues := append([]*bb_worker.PlatformQueueConfiguration{{
+					InstanceNamePrefix: runnerConfiguration.InstanceNamePrefix,
+					Platform:           runnerConfiguration.Platform,
+					SizeClass:          runnerConfiguration.SizeClass,
+				}}, runnerConfiguration.AdditionalPlatformQueues...)
+				var concurrencyLimit *semaphore.Weighted
+				if len(platformQueues) > 1 || len(schedulerClients) > 1 {
+					concurrencyLimit = semaphore.NewWeighted(int64(runnerConfiguration.Concurrency))
+				}
+
+				for queueID, platformQueue := range platformQueues {
+					queueConcurrency := platformQueue.MaximumConcurrency
+					if queueConcurrency == 0 || queueConcurrency > runnerConfiguration.Concurrency {
+						queueConcurrency = runnerConfiguration.Concurrency
 					}
-					workerName, err := json.Marshal(workerID)
+					instanceNamePrefix, err := digest.NewInstanceName(platformQueue.InstanceNamePrefix)
 					if err != nil {
-						return util.StatusWrap(err, "Failed to marshal worker ID")
+						return util.StatusWrapf(err, "Invalid instance name prefix %#v", platformQueue.InstanceNamePrefix)
+					}
+					// Memory backing temporary
+					// directories is capped for each
+					// platform queue individually.
+					var temporaryDirectoryPolicy *builder.TemporaryDirectoryPolicy
+					if temporaryDirectoryConfiguration := runnerConfiguration.InMemoryTemporaryDirectory; temporaryDirectoryConfiguration != nil {
+						temporaryDirectoryPolicy = builder.NewTemporaryDirectoryPolicy(
+							temporaryDirectoryConfiguration.PlatformPropertyName,
+							temporaryDirectoryConfiguration.MaximumTotalSizeBytes,
+							temporaryDirectoryConfiguration.MaximumSizeBytesPerAction)
 					}
 
-					buildExecutor := builder.NewLocalBuildExecutor(
-						contentAddressableStorageWriter,
-						buildDirectoryCreator,
-						runnerClient,
-						executionTimeoutClock,
-						inputRootCharacterDevices,
-						int(configuration.MaximumMessageSizeBytes),
-						runnerConfiguration.EnvironmentVariables,
-						configuration.ForceUploadTreesAndDirectories)
-
-					if prefetchingConfiguration != nil {
-						buildExecutor = builder.NewPrefetchingBuildExecutor(
-							buildExecutor,
-							globalContentAddressableStorage,
-							directoryFetcher,
-							prefetchingDownloadConcurrency,
-							fileSystemAccessCache,
-							int(configuration.MaximumMessageSizeBytes),
-							int(prefetchingConfiguration.BloomFilterBitsPerPath),
-							int(prefetchingConfiguration.BloomFilterMaximumSizeBytes))
+					platform := platformQueue.Platform
+					if len(platformPropertyTemplates) > 0 {
+						platform, err = builder.ApplyPlatformPropertyTemplates(platform, platformPropertyTemplates, hostFacts)
+						if err != nil {
+							return err
+						}
 					}
 
-					buildExecutor = builder.NewMetricsBuildExecutor(
-						builder.NewFilePoolStatsBuildExecutor(
-							builder.NewTimestampedBuildExecutor(
-								builder.NewStorageFlushingBuildExecutor(
-									buildExecutor,
-									contentAddressableStorageFlusher),
+					for schedulerID, schedulerClient := range schedulerClients {
+						for threadID := uint64(0); threadID < queueConcurrency; threadID++ {
+							// Per-worker separate writer of the Content
+							// Addressable Storage that batches writes after
+							// completing the build action.
+							contentAddressableStorageWriter, contentAddressableStorageFlusher := re_blobstore.NewBatchedStoreBlobAccess(
+								deduplicatedContentAddressableStorage,
+								digest.KeyWithoutInstance,
+								uploadBatchSize,
+								outputUploadConcurrencySemaphore)
+							contentAddressableStorageWriter = blobstore.NewMetricsBlobAccess(
+								contentAddressableStorageWriter,
 								clock.SystemClock,
-								string(workerName))))
+								"cas",
+								"batched_store")
 
-					if len(runnerConfiguration.CostsPerSecond) > 0 {
-						buildExecutor = builder.NewCostComputingBuildExecutor(buildExecutor, runnerConfiguration.CostsPerSecond)
-					}
+							// When the virtual file system is
+							// enabled, we can lazily load the input
+							// root, as opposed to explicitly
+							// instantiating it before every build.
+							var executionTimeoutClock clock.Clock
+							var buildDirectory builder.BuildDirectory
+							if virtualBuildDirectory != nil {
+								suspendableClock := re_clock.NewSuspendableClock(
+									clock.SystemClock,
+									maximumExecutionTimeoutCompensation,
+									time.Second/10)
+								executionTimeoutClock = suspendableClock
+								buildDirectory = builder.NewVirtualBuildDirectory(
+									virtualBuildDirectory,
+									cas.NewSuspendingDirectoryFetcher(
+										directoryFetcher,
+										suspendableClock),
+									re_blobstore.NewSuspendingBlobAccess(
+										contentAddressableStorageWriter,
+										suspendableClock),
+									symlinkFactory,
+									symlinkTargetRewriter,
+									characterDeviceFactory,
+									handleAllocator,
+									backgroundUploadContentAddressableStorage,
+									outputUploadConcurrencySemaphore,
+									fileDeduplicationOptions,
+									casFileReadaheadOptions,
+									quiescentFileConversionContentAddressableStorage,
+									quiescentFileConversionPeriod)
+							} else {
+								executionTimeoutClock = clock.SystemClock
+								buildDirectory = builder.NewNaiveBuildDirectory(
+									naiveBuildDirectory,
+									directoryFetcher,
+									fileFetcher,
+									contentAddressableStorageWriter)
+							}
 
-					if maximumConsecutiveFailures := runnerConfiguration.MaximumConsecutiveTestInfrastructureFailures; maximumConsecutiveFailures > 0 {
-						buildExecutor = builder.NewTestInfrastructureFailureDetectingBuildExecutor(
-							buildExecutor,
-							testInfrastructureFailureShutdownState,
-							maximumConsecutiveFailures)
-					}
+							// Create a subdirectory in the build
+							// directory for every action, so that
+							// multiple actions may be run
+							// concurrently. Its name and whether it
+							// is reused depends on the configured
+							// policy.
+							//
+							// Also clean the build directory every
+							// time when going from fully idle to
+							// executing one action.
+							buildDirectoryCreator, err := buildDirectoryCreatorFactory.newBuildDirectoryCreator(
+								builder.NewCleanBuildDirectoryCreator(
+									builder.NewRootBuildDirectoryCreator(buildDirectory),
+									buildDirectoryIdleInvoker),
+								runnerConfiguration,
+								directoryFetcher,
+								globalContentAddressableStorage)
+							if err != nil {
+								return err
+							}
 
-					buildExecutor = builder.NewCachingBuildExecutor(
-						buildExecutor,
-						globalContentAddressableStorage,
-						actionCache,
-						browserURL)
-
-					for _, remoteCompletedActionLogger := range remoteCompletedActionLoggers {
-						buildExecutor = builder.NewCompletedActionLoggingBuildExecutor(
-							buildExecutor,
-							uuid.NewRandom,
-							remoteCompletedActionLogger.logger,
-							remoteCompletedActionLogger.instanceNamePatcher)
-					}
+							workerID := map[string]string{}
+							if runnerConfiguration.Concurrency > 1 {
+								workerID["thread"] = fmt.Sprintf("%0*d", concurrencyLength, threadID)
+							}
+							if len(platformQueues) > 1 {
+								workerID["queue"] = strconv.Itoa(queueID)
+							}
+							if len(schedulerClients) > 1 {
+								workerID["scheduler"] = strconv.Itoa(schedulerID)
+							}
+							for k, v := range runnerConfiguration.WorkerId {
+								workerID[k] = v
+							}
+							workerName, err := json.Marshal(workerID)
+							if err != nil {
+								return util.StatusWrap(err, "Failed to marshal worker ID")
+							}
 
-					buildExecutor = builder.NewTracingBuildExecutor(
-						builder.NewLoggingBuildExecutor(
-							buildExecutor,
-							browserURL),
-						tracerProvider)
+							var workerMetadataFile *builder.WorkerMetadataFile
+							if workerMetadataFileConfiguration := runnerConfiguration.WorkerMetadataFile; workerMetadataFileConfiguration != nil {
+								workerMetadataFile, err = builder.NewWorkerMetadataFile(
+									workerMetadataFileConfiguration.Path,
+									globalContentAddressableStorage,
+									platformQueue.SizeClass,
+									workerID,
+									workerMetadataFileConfiguration.WorkerIdKeys)
+								if err != nil {
+									return err
+								}
+							}
 
-					instanceNamePrefix, err := digest.NewInstanceName(runnerConfiguration.InstanceNamePrefix)
-					if err != nil {
-						return util.StatusWrapf(err, "Invalid instance name prefix %#v", runnerConfiguration.InstanceNamePrefix)
-					}
+							environmentVariables := runnerConfiguration.EnvironmentVariables
+							var nestedExecutionProxy *builder.NestedExecutionProxy
+							if nestedExecutionConfiguration := runnerConfiguration.NestedExecution; nestedExecutionConfiguration != nil {
+								socketPath := path.Join(
+									nestedExecutionConfiguration.SocketDirectory,
+									fmt.Sprintf("%d.sock", nestedExecutionSocketCount))
+								nestedExecutionSocketCount++
+								nestedExecutionProxy = builder.NewNestedExecutionProxy(
+									nestedExecutionClient,
+									globalContentAddressableStorage,
+									int(configuration.MaximumMessageSizeBytes),
+									nestedExecutionConfiguration.PlatformPropertyName,
+									int(nestedExecutionConfiguration.MaximumConcurrentSubActions),
+									int(nestedExecutionConfiguration.MaximumSubActionsPerAction))
+								if err := serveNestedExecutionProxy(siblingsGroup, nestedExecutionProxy, socketPath); err != nil {
+									return err
+								}
 
-					buildClient := builder.NewBuildClient(
-						schedulerClient,
-						buildExecutor,
-						re_filesystem.NewQuotaEnforcingFilePool(
-							filePool,
-							runnerConfiguration.MaximumFilePoolFileCount,
-							runnerConfiguration.MaximumFilePoolSizeBytes),
-						clock.SystemClock,
-						workerID,
-						instanceNamePrefix,
-						runnerConfiguration.Platform,
-						runnerConfiguration.SizeClass)
-					builder.LaunchWorkerThread(siblingsGroup, buildClient, string(workerName))
+								environmentVariables = make(map[string]string, len(runnerConfiguration.EnvironmentVariables)+1)
+								for name, value := range runnerConfiguration.EnvironmentVariables {
+									environmentVariables[name] = value
+								}
+								environmentVariables[nestedExecutionConfiguration.EnvironmentVariableName] = "unix:" + socketPath
+							}
+
+							var previousActionOutputGrafter *builder.PreviousActionOutputGrafter
+							if platformPropertyName := runnerConfiguration.PreviousActionOutputPlatformPropertyName; platformPropertyName != "" {
+								previousActionOutputGrafter = builder.NewPreviousActionOutputGrafter(
+									platformPropertyName,
+									globalContentAddressableStorage,
+									actionCache,
+									int(configuration.MaximumMessageSizeBytes))
+							}
+
+							var stdinFile *builder.StdinFile
+							if platformPropertyName := runnerConfiguration.StdinPlatformPropertyName; platformPropertyName != "" {
+								stdinFile = builder.NewStdinFile(
+									platformPropertyName,
+									globalContentAddressableStorage)
+							}
+
+							buildExecutor := builder.NewLocalBuildExecutor(
+								contentAddressableStorageWriter,
+								buildDirectoryCreator,
+								runnerClient,
+								executionTimeoutClock,
+								inputRootCharacterDevices,
+								int(configuration.MaximumMessageSizeBytes),
+								environmentVariables,
+								configuration.ForceUploadTreesAndDirectories,
+								sharedCaches,
+								temporaryDirectoryPolicy,
+								stdinFile,
+								workerMetadataFile,
+								previousActionOutputGrafter,
+								outputLogStreamer)
+
+							if nestedExecutionProxy != nil {
+								buildExecutor = builder.NewNestedExecutionBuildExecutor(
+									buildExecutor,
+									nestedExecutionProxy,
+									uuid.NewRandom,
+									runnerConfiguration.NestedExecution.TokenEnvironmentVariableName)
+							}
+
+							if progressWatchdogConfiguration := runnerConfiguration.ProgressWatchdog; progressWatchdogConfiguration != nil {
+								if err := progressWatchdogConfiguration.StallTimeout.CheckValid(); err != nil {
+									return util.StatusWrap(err, "Invalid progress watchdog stall timeout")
+								}
+								var cpuUsageReader builder.CPUUsageReader
+								if cgroupPath := progressWatchdogConfiguration.CpuUsageCgroupPath; cgroupPath != "" {
+									cpuUsageReader = builder.NewCgroupCPUUsageReader(cgroupPath)
+								}
+								buildExecutor = builder.NewProgressWatchdogBuildExecutor(
+									buildExecutor,
+									clock.SystemClock,
+									progressWatchdogConfiguration.StallTimeout.AsDuration(),
+									progressWatchdogConfiguration.FailStalledActions,
+									util.DefaultErrorLogger,
+									cpuUsageReader)
+							}
+
+							if prefetchingConfiguration != nil {
+								buildExecutor = builder.NewPrefetchingBuildExecutor(
+									buildExecutor,
+									globalContentAddressableStorage,
+									directoryFetcher,
+									prefetchingDownloadConcurrency,
+									fileSystemAccessCache,
+									int(configuration.MaximumMessageSizeBytes),
+									int(prefetchingConfiguration.BloomFilterBitsPerPath),
+									int(prefetchingConfiguration.BloomFilterMaximumSizeBytes))
+							}
+
+							buildExecutor = builder.NewMetricsBuildExecutor(
+								builder.NewFilePoolStatsBuildExecutor(
+									builder.NewTimestampedBuildExecutor(
+										builder.NewStorageFlushingBuildExecutor(
+											buildExecutor,
+											contentAddressableStorageFlusher),
+										clock.SystemClock,
+										string(workerName))))
+
+							if readinessCheckingFilePool, ok := buildDirectoryFilePool.(re_filesystem.ReadinessCheckingFilePool); ok {
+								buildExecutor = builder.NewFilePoolReadinessCheckingBuildExecutor(
+									buildExecutor,
+									readinessCheckingFilePool)
+							}
+
+							if helperBinaryInstaller != nil {
+								buildExecutor = builder.NewHelperBinaryInstallingBuildExecutor(
+									buildExecutor,
+									helperBinaryInstaller)
+							}
+
+							if len(runnerConfiguration.CostsPerSecond) > 0 {
+								buildExecutor = builder.NewCostComputingBuildExecutor(buildExecutor, runnerConfiguration.CostsPerSecond)
+							}
+
+							if maximumConsecutiveFailures := runnerConfiguration.MaximumConsecutiveTestInfrastructureFailures; maximumConsecutiveFailures > 0 {
+								buildExecutor = builder.NewTestInfrastructureFailureDetectingBuildExecutor(
+									buildExecutor,
+									testInfrastructureFailureShutdownState,
+									maximumConsecutiveFailures)
+							}
+
+							if infrastructureErrorBudget != nil {
+								buildExecutor = builder.NewErrorBudgetQuarantiningBuildExecutor(
+									buildExecutor,
+									infrastructureErrorBudget)
+							}
+
+							if len(pathMappings) > 0 {
+								buildExecutor = builder.NewPathMappingBuildExecutor(
+									buildExecutor,
+									globalContentAddressableStorage,
+									actionCache,
+									int(configuration.MaximumMessageSizeBytes),
+									pathMappings)
+							}
+
+							if excludedProperties := runnerConfiguration.CacheKeyExcludedPlatformProperties; len(excludedProperties) > 0 {
+								buildExecutor = builder.NewPlatformPropertyExcludingBuildExecutor(
+									buildExecutor,
+									globalContentAddressableStorage,
+									actionCache,
+									int(configuration.MaximumMessageSizeBytes),
+									excludedProperties)
+							}
+
+							buildExecutor = builder.NewCachingBuildExecutor(
+								buildExecutor,
+								globalContentAddressableStorage,
+								actionCache,
+								browserURL,
+								cacheFlagOverrides)
+
+							for _, remoteCompletedActionLogger := range remoteCompletedActionLoggers {
+								buildExecutor = builder.NewCompletedActionLoggingBuildExecutor(
+									buildExecutor,
+									uuid.NewRandom,
+									remoteCompletedActionLogger.logger,
+									remoteCompletedActionLogger.instanceNamePatcher,
+									workerAnnotations)
+							}
+
+							if concurrencyLimit != nil {
+								buildExecutor = builder.NewConcurrencyLimitingBuildExecutor(
+									buildExecutor,
+									concurrencyLimit)
+							}
+
+							buildExecutor = builder.NewTracingBuildExecutor(
+								builder.NewLoggingBuildExecutor(
+									builder.NewDebugLoggingBuildExecutor(
+										buildExecutor,
+										debugFlags,
+										clock.SystemClock),
+									browserURL),
+								tracerProvider)
+							buildExecutor = workerStatus.NewBuildExecutor(buildExecutor, string(workerName))
+
+							threadSchedulerClient := schedulerClient
+							if configuration.UseSynchronizeStream {
+								threadSchedulerClient = builder.NewStreamingOperationQueueClient(ctx, schedulerClient)
+							}
+							buildClient := builder.NewBuildClient(
+								threadSchedulerClient,
+								buildExecutor,
+								re_filesystem.NewQuotaEnforcingFilePool(
+									buildDirectoryFilePool,
+									runnerConfiguration.MaximumFilePoolFileCount,
+									runnerConfiguration.MaximumFilePoolSizeBytes),
+								clock.SystemClock,
+								workerID,
+								instanceNamePrefix,
+								platform,
+								platformQueue.SizeClass)
+							builder.LaunchWorkerThread(siblingsGroup, buildClient, string(workerName), workerDrainer)
+						}
+					}
 				}
 			}
 		}
 
+		// Web server for metrics, profiling and displaying the
+		// state of the worker.
+		router := mux.NewRouter()
+		routePrefix := path.Join("/", configuration.AdminRoutePrefix)
+		if !strings.HasSuffix(routePrefix, "/") {
+			routePrefix += "/"
+		}
+		if workerDrainer != nil {
+			newWorkerDrainService(workerDrainer, router)
+		}
+		if authorizerConfiguration := configuration.InputFileCacheFlushAuthorizer; authorizerConfiguration != nil {
+			inputFileCacheFlushAuthorizer, err := auth.DefaultAuthorizerFactory.NewAuthorizerFromConfiguration(authorizerConfiguration)
+			if err != nil {
+				return util.StatusWrap(err, "Failed to create input file cache flush authorizer")
+			}
+			newInputFileCacheFlushService(inputFileCacheFlushers, inputFileCacheFlushAuthorizer, router)
+		}
+		subrouter := router.PathPrefix(routePrefix).Subrouter()
+		newWorkerStatusService(workerStatus, clock.SystemClock, browserURL, subrouter)
+		http.NewServersFromConfigurationAndServe(
+			configuration.AdminHttpServers,
+			http.NewMetricsHandler(router, "WorkerUI"),
+			siblingsGroup,
+		)
+
 		lifecycleState.MarkReadyAndWait(siblingsGroup)
 		return nil
 	})
 }
+
+func newCacheFlagPolicyFromConfiguration(policy bb_worker.CacheFlagOverrideConfiguration_Policy) (builder.CacheFlagPolicy, error) {
+	switch policy {
+	case bb_worker.CacheFlagOverrideConfiguration_RESPECT:
+		return builder.CacheFlagPolicyRespect, nil
+	case bb_worker.CacheFlagOverrideConfiguration_IGNORE:
+		return builder.CacheFlagPolicyIgnore, nil
+	default:
+		return 0, status.Errorf(codes.InvalidArgument, "Unknown policy %d", policy)
+	}
+}
+
+// serveNestedExecutionProxy exposes a NestedExecutionProxy on a UNIX
+// socket, so that actions running on a worker thread can submit
+// sub-actions. The socket is only accessible by the user running the
+// worker.
+func serveNestedExecutionProxy(group program.Group, proxy *builder.NestedExecutionProxy, socketPath string) error {
+	if err := os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
+		return util.StatusWrapf(err, "Could not remove stale socket for nested execution %#v", socketPath)
+	}
+
+	// Create the socket inside a private directory, so that it
+	// cannot be connected to before its permissions are restricted.
+	temporaryDirectory, err := os.MkdirTemp(path.Dir(socketPath), ".nested-execution-")
+	if err != nil {
+		return util.StatusWrapf(err, "Failed to create temporary directory for nested execution socket %#v", socketPath)
+	}
+	defer os.Remove(temporaryDirectory)
+	temporarySocketPath := path.Join(temporaryDirectory, "socket")
+	listener, err := net.Listen("unix", temporarySocketPath)
+	if err != nil {
+		return util.StatusWrapf(err, "Failed to create listening socket for nested execution %#v", socketPath)
+	}
+	if err := os.Chmod(temporarySocketPath, 0o600); err != nil {
+		listener.Close()
+		return util.StatusWrapf(err, "Failed to set permissions of socket for nested execution %#v", socketPath)
+	}
+	if err := os.Rename(temporarySocketPath, socketPath); err != nil {
+		listener.Close()
+		return util.StatusWrapf(err, "Failed to move socket for nested execution to %#v", socketPath)
+	}
+
+	server := grpc.NewServer()
+	remoteexecution.RegisterExecutionServer(server, proxy)
+	group.Go(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+		// Stop accepting connections upon shutdown.
+		go func() {
+			<-ctx.Done()
+			server.Stop()
+		}()
+		if err := server.Serve(listener); err != nil && ctx.Err() == nil {
+			return util.StatusWrapf(err, "Failed to serve nested execution requests on %#v", socketPath)
+		}
+		return nil
+	})
+	return nil
+}
+
+func newTransferLimiterFromConfiguration(configuration *bb_worker.TransferLimitConfiguration) *re_blobstore.TransferLimiter {
+	return re_blobstore.NewTransferLimiter(
+		clock.SystemClock,
+		configuration.MaximumConcurrentTransfers,
+		configuration.MaximumBytesPerSecond,
+		configuration.BurstSizeBytes)
+}
+
+// newTransferLimitingBlobAccess decorates a BlobAccess, so that
+// transfers are subject to the limits of the storage backend, followed
+// by the limits that apply to all storage backends.
+func newTransferLimitingBlobAccess(base blobstore.BlobAccess, configuration *bb_worker.TransferLimitConfiguration, globalTransferLimiter *re_blobstore.TransferLimiter) blobstore.BlobAccess {
+	var limiters []*re_blobstore.TransferLimiter
+	if configuration != nil {
+		limiters = append(limiters, newTransferLimiterFromConfiguration(configuration))
+	}
+	if globalTransferLimiter != nil {
+		limiters = append(limiters, globalTransferLimiter)
+	}
+	if len(limiters) == 0 {
+		return base
+	}
+	return re_blobstore.NewTransferLimitingBlobAccess(base, limiters)
+}
+
+func newSymlinkTargetRewriterFromConfiguration(configuration *bb_worker.SymlinkTargetPolicyConfiguration) (virtual.SymlinkTargetRewriter, error) {
+	if configuration == nil {
+		return virtual.IdentitySymlinkTargetRewriter, nil
+	}
+	rules := make([]virtual.SymlinkTargetRewriteRule, 0, len(configuration.RewriteRules))
+	for _, rule := range configuration.RewriteRules {
+		rules = append(rules, virtual.SymlinkTargetRewriteRule{
+			AbsolutePrefix: rule.AbsolutePrefix,
+			Replacement:    rule.Replacement,
+		})
+	}
+	return virtual.NewPrefixSymlinkTargetRewriter(rules, configuration.RefuseUnmatchedAbsoluteTargets)
+}
+
+// newCacheIdleEvictionParametersFromConfiguration validates the
+// configuration of idle eviction of a cache, returning the interval at
+// which eviction needs to be performed and the maximum amount of time
+// entries may remain unused.
+func newCacheIdleEvictionParametersFromConfiguration(configuration *bb_worker.CacheIdleEvictionConfiguration) (time.Duration, time.Duration, error) {
+	if err := configuration.Interval.CheckValid(); err != nil {
+		return 0, 0, util.StatusWrap(err, "Invalid cache idle eviction interval")
+	}
+	interval := configuration.Interval.AsDuration()
+	if interval <= 0 {
+		return 0, 0, status.Error(codes.InvalidArgument, "Cache idle eviction interval must be positive")
+	}
+	if err := configuration.MaximumIdleTime.CheckValid(); err != nil {
+		return 0, 0, util.StatusWrap(err, "Invalid cache maximum idle time")
+	}
+	return interval, configuration.MaximumIdleTime.AsDuration(), nil
+}
+
+// newPackedFileFetcherFromConfiguration creates a PackedFileFetcher,
+// storing its index in the configured directory, if any.
+func newPackedFileFetcherFromConfiguration(configuration *bb_worker.PackedInputFileCacheConfiguration, base cas.FileFetcher, maximumFileCount int) (cas.PackedFileFetcher, error) {
+	packFile, sectorSizeBytes, sectorCount, err := blockdevice.NewBlockDeviceFromConfiguration(configuration.PackFile, true)
+	if err != nil {
+		return nil, util.StatusWrap(err, "Failed to open pack file of input file cache")
+	}
+	var indexDirectory filesystem.Directory
+	if configuration.IndexDirectoryPath != "" {
+		if indexDirectory, err = filesystem.NewLocalDirectory(configuration.IndexDirectoryPath); err != nil {
+			return nil, util.StatusWrap(err, "Failed to open index directory of input file cache")
+		}
+	}
+	packedFileFetcher, err := cas.NewPackedFileFetcher(base, packFile, int64(sectorSizeBytes)*sectorCount, maximumFileCount, indexDirectory)
+	if err != nil {
+		return nil, util.StatusWrap(err, "Failed to create packed input file cache")
+	}
+	return packedFileFetcher, nil
+}
+
+// runPackedCacheIndexWriter launches a goroutine that periodically
+// writes the index of a packed input file cache, and writes it one
+// final time upon shutdown.
+func runPackedCacheIndexWriter(dependenciesGroup program.Group, interval time.Duration, packedFileFetcher cas.PackedFileFetcher) {
+	dependenciesGroup.Go(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+		for {
+			timer, t := clock.SystemClock.NewTimer(interval)
+			select {
+			case <-t:
+				if err := packedFileFetcher.WriteIndex(); err != nil {
+					log.Print("Failed to write index of packed input file cache: ", err)
+				}
+			case <-ctx.Done():
+				timer.Stop()
+				if err := packedFileFetcher.WriteIndex(); err != nil {
+					return util.StatusWrap(err, "Failed to write index of packed input file cache")
+				}
+				return nil
+			}
+		}
+	})
+}
+
+// runCacheIdleEviction launches a goroutine that periodically removes
+// entries from a cache that have not been used for some time.
+func runCacheIdleEviction(dependenciesGroup program.Group, interval time.Duration, evicter func() (int, error), cacheDescription string) {
+	dependenciesGroup.Go(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+		for {
+			timer, t := clock.SystemClock.NewTimer(interval)
+			select {
+			case <-t:
+				if evictedCount, err := evicter(); err != nil {
+					log.Printf("Failed to evict idle files from %s: %s", cacheDescription, err)
+				} else if evictedCount > 0 {
+					log.Printf("Evicted %d idle files from %s", evictedCount, cacheDescription)
+				}
+			case <-ctx.Done():
+				timer.Stop()
+				return nil
+			}
+		}
+	})
+}
+
+// startReadaheadIdleEviction periodically discards the readahead state
+// of files created by a long-lived CASFileFactory, if configured.
+func startReadaheadIdleEviction(dependenciesGroup program.Group, configuration *bb_worker.CASFileReadaheadConfiguration, casFileFactory virtual.ReadaheadCASFileFactory, factoryDescription string) error {
+	idleEvictionConfiguration := configuration.GetIdleEviction()
+	if idleEvictionConfiguration == nil {
+		return nil
+	}
+	interval, maximumIdleTime, err := newCacheIdleEvictionParametersFromConfiguration(idleEvictionConfiguration)
+	if err != nil {
+		return err
+	}
+	runCacheIdleEviction(dependenciesGroup, interval, func() (int, error) {
+		return casFileFactory.EvictIdleFiles(maximumIdleTime)
+	}, factoryDescription+" readahead state")
+	return nil
+}
+
+func newFileDeduplicationOptionsFromConfiguration(configuration *bb_worker.FileDeduplicationConfiguration) (*virtual.FileDeduplicationOptions, error) {
+	if configuration == nil {
+		return nil, nil
+	}
+	if configuration.HashingConcurrency == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Hashing concurrency must be positive")
+	}
+	if configuration.MaximumInputFiles == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Maximum number of input files must be positive")
+	}
+	return &virtual.FileDeduplicationOptions{
+		HashingSemaphore:  semaphore.NewWeighted(int64(configuration.HashingConcurrency)),
+		MaximumInputFiles: int(configuration.MaximumInputFiles),
+	}, nil
+}
+
+func newCASFileReadaheadOptionsFromConfiguration(configuration *bb_worker.CASFileReadaheadConfiguration) (*virtual.CASFileReadaheadOptions, error) {
+	if configuration == nil {
+		return nil, nil
+	}
+	if configuration.ChunkSizeBytes <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "Chunk size must be positive")
+	}
+	if configuration.MaximumFiles == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Maximum number of files must be positive")
+	}
+	return &virtual.CASFileReadaheadOptions{
+		ChunkSizeBytes: int(configuration.ChunkSizeBytes),
+		MaximumFiles:   int(configuration.MaximumFiles),
+		Clock:          clock.SystemClock,
+	}, nil
+}
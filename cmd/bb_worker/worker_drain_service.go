@@ -0,0 +1,34 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/gorilla/mux"
+)
+
+type workerDrainService struct {
+	workerDrainer *builder.WorkerDrainer
+}
+
+// newWorkerDrainService registers an HTTP endpoint that can be invoked
+// from a preStop lifecycle hook on Kubernetes. As draining is
+// irreversible, it only accepts POST requests. This prevents the
+// worker from being drained by crawlers, browsers prefetching links,
+// etc.
+func newWorkerDrainService(workerDrainer *builder.WorkerDrainer, router *mux.Router) {
+	s := &workerDrainService{
+		workerDrainer: workerDrainer,
+	}
+	router.HandleFunc("/-/drain", s.handleDrain).Methods(http.MethodPost)
+}
+
+func (s *workerDrainService) handleDrain(w http.ResponseWriter, req *http.Request) {
+	log.Print("Draining worker")
+	if err := s.workerDrainer.Drain(req.Context()); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	log.Print("Worker drained")
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"embed"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	re_util "github.com/buildbarn/bb-remote-execution/pkg/util"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+var (
+	//go:embed templates
+	templatesFS embed.FS
+	//go:embed stylesheet.css
+	stylesheet template.CSS
+
+	templates = template.Must(template.New("templates").Funcs(template.FuncMap{
+		"stylesheet": func() template.CSS { return stylesheet },
+		"time_past": func(t, now time.Time) string {
+			return now.Sub(t).Truncate(time.Second).String()
+		},
+	}).ParseFS(templatesFS, "templates/*.html"))
+)
+
+// cacheStatistic is a single sample of a Prometheus metric related to
+// caching, as displayed by the web UI.
+type cacheStatistic struct {
+	Name   string
+	Labels string
+	Value  float64
+}
+
+// getCacheStatistics extracts the values of all counters and gauges
+// registered with Prometheus whose name refers to caching (e.g.,
+// hit/miss counters of the directory and file caches).
+func getCacheStatistics() []cacheStatistic {
+	metricFamilies, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		log.Print("Failed to gather Prometheus metrics: ", err)
+	}
+	var statistics []cacheStatistic
+	for _, metricFamily := range metricFamilies {
+		name := metricFamily.GetName()
+		if !strings.Contains(name, "cache") {
+			continue
+		}
+		for _, metric := range metricFamily.Metric {
+			var value float64
+			switch metricFamily.GetType() {
+			case dto.MetricType_COUNTER:
+				value = metric.GetCounter().GetValue()
+			case dto.MetricType_GAUGE:
+				value = metric.GetGauge().GetValue()
+			default:
+				continue
+			}
+			labels := make([]string, 0, len(metric.Label))
+			for _, label := range metric.Label {
+				labels = append(labels, label.GetName()+"="+label.GetValue())
+			}
+			statistics = append(statistics, cacheStatistic{
+				Name:   name,
+				Labels: strings.Join(labels, ", "),
+				Value:  value,
+			})
+		}
+	}
+	sort.SliceStable(statistics, func(i, j int) bool {
+		return statistics[i].Name < statistics[j].Name
+	})
+	return statistics
+}
+
+type workerStatusService struct {
+	workerStatus *builder.WorkerStatus
+	clock        clock.Clock
+	browserURL   *url.URL
+}
+
+// newWorkerStatusService registers the handlers of bb_worker's web UI,
+// which displays the state of the worker for debugging purposes.
+func newWorkerStatusService(workerStatus *builder.WorkerStatus, clock clock.Clock, browserURL *url.URL, router *mux.Router) {
+	s := &workerStatusService{
+		workerStatus: workerStatus,
+		clock:        clock,
+		browserURL:   browserURL,
+	}
+	router.HandleFunc("/", s.handleGetWorkerState)
+}
+
+// executingAction contains the state of an action that is currently
+// being executed, converted to a form that can be displayed easily.
+type executingAction struct {
+	builder.ExecutingActionState
+	ActionURL string
+}
+
+// recentError contains an error that was encountered recently,
+// converted to a form that can be displayed easily.
+type recentError struct {
+	builder.RecentError
+	ActionURL string
+}
+
+func (s *workerStatusService) handleGetWorkerState(w http.ResponseWriter, req *http.Request) {
+	var executingActions []executingAction
+	var filePoolFilesCount, filePoolFilesSizeBytes uint64
+	for _, state := range s.workerStatus.GetExecutingActions() {
+		a := executingAction{ExecutingActionState: state}
+		if actionDigest, err := state.DigestFunction.NewDigestFromProto(state.ActionDigest); err == nil {
+			a.ActionURL = re_util.GetBrowserURL(s.browserURL, "action", actionDigest)
+		}
+		executingActions = append(executingActions, a)
+		filePoolFilesCount += state.FilePoolFilesCount
+		filePoolFilesSizeBytes += state.FilePoolFilesSizeBytes
+	}
+
+	var recentErrors []recentError
+	for _, e := range s.workerStatus.GetRecentErrors() {
+		r := recentError{RecentError: e}
+		if actionDigest, err := e.DigestFunction.NewDigestFromProto(e.ActionDigest); err == nil {
+			r.ActionURL = re_util.GetBrowserURL(s.browserURL, "action", actionDigest)
+		}
+		recentErrors = append(recentErrors, r)
+	}
+
+	if err := templates.ExecuteTemplate(w, "get_worker_state.html", struct {
+		Now                    time.Time
+		ExecutingActions       []executingAction
+		FilePoolFilesCount     uint64
+		FilePoolFilesSizeBytes uint64
+		CacheStatistics        []cacheStatistic
+		RecentErrors           []recentError
+	}{
+		Now:                    s.clock.Now(),
+		ExecutingActions:       executingActions,
+		FilePoolFilesCount:     filePoolFilesCount,
+		FilePoolFilesSizeBytes: filePoolFilesSizeBytes,
+		CacheStatistics:        getCacheStatistics(),
+		RecentErrors:           recentErrors,
+	}); err != nil {
+		log.Print(err)
+	}
+}
@@ -167,6 +167,7 @@ def go_dependencies():
     go_repository(
         name = "com_github_buildbarn_go_xdr",
         importpath = "github.com/buildbarn/go-xdr",
+        patches = ["//:patches/com_github_buildbarn_go_xdr/nfsv4-xattr.diff"],
         sum = "h1:/sKWC0Fs5fXNo/t72BRZRLERg4v2gFoEeg2Mk+a8xak=",
         version = "v0.0.0-20231115101217-a9e2aa4cf64b",
     )
@@ -201,6 +202,12 @@ def go_dependencies():
         sum = "h1:vj9j/u1bqnvCEfJOwUhtlOARqs3+rkHYY13jYWTU97c=",
         version = "v1.1.1",
     )
+    go_repository(
+        name = "com_github_dgryski_go_rendezvous",
+        importpath = "github.com/dgryski/go-rendezvous",
+        sum = "h1:lO4WD4F/rVNCu3HqELle0jiPLLBs70cWOduZpkS1E78=",
+        version = "v0.0.0-20200823014737-9f7001d12a5f",
+    )
     go_repository(
         name = "com_github_envoyproxy_go_control_plane",
         importpath = "github.com/envoyproxy/go-control-plane",
@@ -385,7 +392,9 @@ def go_dependencies():
         importpath = "github.com/hanwen/go-fuse/v2",
         patches = [
             "//:patches/com_github_hanwen_go_fuse_v2/direntrylist-offsets-and-testability.diff",
+            "//:patches/com_github_hanwen_go_fuse_v2/ioctl.diff",
             "//:patches/com_github_hanwen_go_fuse_v2/notify-testability.diff",
+            "//:patches/com_github_hanwen_go_fuse_v2/tmpfile.diff",
             "//:patches/com_github_hanwen_go_fuse_v2/writeback-cache.diff",
         ],
         sum = "h1:12OhD7CkXXQdvxG2osIdBQLdXh+nmLXY9unkUIe/xaU=",
@@ -535,6 +544,12 @@ def go_dependencies():
         sum = "h1:xRC8Iq1yyca5ypa9n1EZnWZkt7dwcoRPQwX/5gwaUuI=",
         version = "v0.11.1",
     )
+    go_repository(
+        name = "com_github_redis_go_redis_v9",
+        importpath = "github.com/redis/go-redis/v9",
+        sum = "h1:RiVDjmig62jIWp7Kk4XVLs0hzV6pI3PyTnnL0cnn0u0=",
+        version = "v9.3.0",
+    )
     go_repository(
         name = "com_github_rogpeppe_fastuuid",
         importpath = "github.com/rogpeppe/fastuuid",
@@ -1309,6 +1324,12 @@ def go_dependencies():
         sum = "h1:fxVm/GzAzEWqLHuvctI91KS9hhNmmWOoWu0XTYJS7CA=",
         version = "v3.0.1",
     )
+    go_repository(
+        name = "io_etcd_go_bbolt",
+        importpath = "go.etcd.io/bbolt",
+        sum = "h1:xs88BrvEv273UsB79e0hcVrlUWmS0a8upikMFhSyAtA=",
+        version = "v1.3.8",
+    )
     go_repository(
         name = "io_k8s_sigs_yaml",
         importpath = "sigs.k8s.io/yaml",
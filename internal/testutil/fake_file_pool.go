@@ -0,0 +1,67 @@
+package testutil
+
+import (
+	"sync"
+
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+)
+
+// FakeFilePool is an implementation of FilePool that stores all data in
+// memory. In addition to that, it keeps track of the number of files
+// that are currently opened, so that tests can validate that
+// decorators of FilePool don't leak any files.
+type FakeFilePool struct {
+	lock          sync.Mutex
+	openFileCount int
+}
+
+var _ re_filesystem.FilePool = (*FakeFilePool)(nil)
+
+// NewFakeFilePool creates a FakeFilePool that contains no files.
+func NewFakeFilePool() *FakeFilePool {
+	return &FakeFilePool{}
+}
+
+// NewFile creates a new empty file that is stored in memory.
+func (fp *FakeFilePool) NewFile() (filesystem.FileReadWriter, error) {
+	f, err := re_filesystem.InMemoryFilePool.NewFile()
+	if err != nil {
+		return nil, err
+	}
+
+	fp.lock.Lock()
+	fp.openFileCount++
+	fp.lock.Unlock()
+	return &fakeFile{
+		FileReadWriter: f,
+		pool:           fp,
+	}, nil
+}
+
+// GetOpenFileCount returns the number of files that have been created,
+// but have not been closed yet.
+func (fp *FakeFilePool) GetOpenFileCount() int {
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+	return fp.openFileCount
+}
+
+type fakeFile struct {
+	filesystem.FileReadWriter
+	pool *FakeFilePool
+}
+
+func (f *fakeFile) Close() error {
+	fp := f.pool
+	if fp == nil {
+		panic("Attempted to close a file that was already closed")
+	}
+	err := f.FileReadWriter.Close()
+
+	fp.lock.Lock()
+	fp.openFileCount--
+	fp.lock.Unlock()
+	f.pool = nil
+	return err
+}
@@ -0,0 +1,37 @@
+package testutil_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeFilePool(t *testing.T) {
+	filePool := testutil.NewFakeFilePool()
+	require.Equal(t, 0, filePool.GetOpenFileCount())
+
+	// Files should be backed by memory.
+	f1, err := filePool.NewFile()
+	require.NoError(t, err)
+	n, err := f1.WriteAt([]byte("Hello"), 0)
+	require.Equal(t, 5, n)
+	require.NoError(t, err)
+
+	var p [10]byte
+	n, err = f1.ReadAt(p[:], 0)
+	require.Equal(t, 5, n)
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, []byte("Hello"), p[:n])
+
+	// The number of open files should be tracked.
+	f2, err := filePool.NewFile()
+	require.NoError(t, err)
+	require.Equal(t, 2, filePool.GetOpenFileCount())
+
+	require.NoError(t, f1.Close())
+	require.Equal(t, 1, filePool.GetOpenFileCount())
+	require.NoError(t, f2.Close())
+	require.Equal(t, 0, filePool.GetOpenFileCount())
+}
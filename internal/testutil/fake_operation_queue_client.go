@@ -0,0 +1,163 @@
+package testutil
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakeSynchronizeResult struct {
+	response *remoteworker.SynchronizeResponse
+	err      error
+}
+
+// FakeOperationQueueClient is an implementation of
+// OperationQueueClient that can be used to test workers without
+// connecting to a scheduler. Responses to calls to Synchronize() and
+// SynchronizeStream() are provided by the test ahead of time, while
+// requests are recorded, so that they can be inspected afterwards.
+type FakeOperationQueueClient struct {
+	lock      sync.Mutex
+	requests  []*remoteworker.SynchronizeRequest
+	responses []fakeSynchronizeResult
+}
+
+var _ remoteworker.OperationQueueClient = (*FakeOperationQueueClient)(nil)
+
+// NewFakeOperationQueueClient creates a FakeOperationQueueClient that
+// has no responses enqueued.
+func NewFakeOperationQueueClient() *FakeOperationQueueClient {
+	return &FakeOperationQueueClient{}
+}
+
+// EnqueueResponse schedules a response to be returned by a future call
+// to Synchronize(). Responses are returned in the order in which they
+// are enqueued.
+func (c *FakeOperationQueueClient) EnqueueResponse(response *remoteworker.SynchronizeResponse, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.responses = append(c.responses, fakeSynchronizeResult{
+		response: response,
+		err:      err,
+	})
+}
+
+// GetRequests returns all requests that have been received through
+// Synchronize() and SynchronizeStream() up to this point.
+func (c *FakeOperationQueueClient) GetRequests() []*remoteworker.SynchronizeRequest {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]*remoteworker.SynchronizeRequest(nil), c.requests...)
+}
+
+// Synchronize records the request and returns the oldest response that
+// was enqueued. If no responses are enqueued, an error is returned.
+func (c *FakeOperationQueueClient) Synchronize(ctx context.Context, in *remoteworker.SynchronizeRequest, opts ...grpc.CallOption) (*remoteworker.SynchronizeResponse, error) {
+	if err := util.StatusFromContext(ctx); err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.requests = append(c.requests, proto.Clone(in).(*remoteworker.SynchronizeRequest))
+	if len(c.responses) == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "No response enqueued for synchronization request")
+	}
+	result := c.responses[0]
+	c.responses = c.responses[1:]
+	return result.response, result.err
+}
+
+// SynchronizeStream returns a stream on which every request sent by
+// the worker is answered with the oldest response that was enqueued,
+// in the same way as Synchronize().
+func (c *FakeOperationQueueClient) SynchronizeStream(ctx context.Context, opts ...grpc.CallOption) (remoteworker.OperationQueue_SynchronizeStreamClient, error) {
+	if err := util.StatusFromContext(ctx); err != nil {
+		return nil, err
+	}
+	return &fakeSynchronizeStreamClient{
+		ctx:    ctx,
+		client: c,
+	}, nil
+}
+
+// fakeSynchronizeStreamClient is the stream that is returned by
+// FakeOperationQueueClient.SynchronizeStream(). Responses are computed
+// when requests are sent, and are buffered until received.
+type fakeSynchronizeStreamClient struct {
+	ctx    context.Context
+	client *FakeOperationQueueClient
+
+	lock    sync.Mutex
+	pending []fakeSynchronizeResult
+	closed  bool
+}
+
+func (s *fakeSynchronizeStreamClient) Send(request *remoteworker.SynchronizeRequest) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return status.Error(codes.FailedPrecondition, "Stream has already been closed for sending")
+	}
+	response, err := s.client.Synchronize(s.ctx, request)
+	s.pending = append(s.pending, fakeSynchronizeResult{
+		response: response,
+		err:      err,
+	})
+	return nil
+}
+
+func (s *fakeSynchronizeStreamClient) Recv() (*remoteworker.SynchronizeResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.pending) == 0 {
+		if s.closed {
+			return nil, io.EOF
+		}
+		return nil, status.Error(codes.FailedPrecondition, "No request was sent for which a response can be received")
+	}
+	result := s.pending[0]
+	s.pending = s.pending[1:]
+	return result.response, result.err
+}
+
+func (s *fakeSynchronizeStreamClient) Header() (metadata.MD, error) {
+	return nil, nil
+}
+
+func (s *fakeSynchronizeStreamClient) Trailer() metadata.MD {
+	return nil
+}
+
+func (s *fakeSynchronizeStreamClient) CloseSend() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *fakeSynchronizeStreamClient) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeSynchronizeStreamClient) SendMsg(m interface{}) error {
+	return s.Send(m.(*remoteworker.SynchronizeRequest))
+}
+
+func (s *fakeSynchronizeStreamClient) RecvMsg(m interface{}) error {
+	response, err := s.Recv()
+	if err != nil {
+		return err
+	}
+	proto.Merge(m.(*remoteworker.SynchronizeResponse), response)
+	return nil
+}
@@ -0,0 +1,77 @@
+package testutil_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/internal/testutil"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	bb_testutil "github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFakeOperationQueueClient(t *testing.T) {
+	ctx := context.Background()
+	client := testutil.NewFakeOperationQueueClient()
+
+	request1 := &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{"thread": "0"},
+	}
+	request2 := &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{"thread": "1"},
+	}
+
+	t.Run("NoResponseEnqueued", func(t *testing.T) {
+		_, err := client.Synchronize(ctx, request1)
+		bb_testutil.RequireEqualStatus(t, status.Error(codes.FailedPrecondition, "No response enqueued for synchronization request"), err)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		// Responses should be returned in the order in which
+		// they were enqueued.
+		response := &remoteworker.SynchronizeResponse{}
+		client.EnqueueResponse(response, nil)
+		client.EnqueueResponse(nil, status.Error(codes.Unavailable, "Scheduler shutting down"))
+
+		actualResponse, err := client.Synchronize(ctx, request1)
+		require.NoError(t, err)
+		require.Same(t, response, actualResponse)
+
+		_, err = client.Synchronize(ctx, request2)
+		bb_testutil.RequireEqualStatus(t, status.Error(codes.Unavailable, "Scheduler shutting down"), err)
+	})
+
+	t.Run("Requests", func(t *testing.T) {
+		requests := client.GetRequests()
+		require.Len(t, requests, 3)
+		bb_testutil.RequireEqualProto(t, request1, requests[0])
+		bb_testutil.RequireEqualProto(t, request1, requests[1])
+		bb_testutil.RequireEqualProto(t, request2, requests[2])
+	})
+	t.Run("Stream", func(t *testing.T) {
+		stream, err := client.SynchronizeStream(ctx)
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		bb_testutil.RequireEqualStatus(t, status.Error(codes.FailedPrecondition, "No request was sent for which a response can be received"), err)
+
+		response := &remoteworker.SynchronizeResponse{}
+		client.EnqueueResponse(response, nil)
+		require.NoError(t, stream.Send(request2))
+		actualResponse, err := stream.Recv()
+		require.NoError(t, err)
+		require.Same(t, response, actualResponse)
+
+		require.NoError(t, stream.CloseSend())
+		_, err = stream.Recv()
+		require.Equal(t, io.EOF, err)
+
+		requests := client.GetRequests()
+		require.Len(t, requests, 4)
+		bb_testutil.RequireEqualProto(t, request2, requests[3])
+	})
+}
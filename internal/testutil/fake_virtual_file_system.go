@@ -0,0 +1,50 @@
+package testutil
+
+import (
+	"sort"
+	"testing"
+
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/random"
+)
+
+// testErrorLogger is an implementation of ErrorLogger that causes the
+// test to fail when errors are reported.
+type testErrorLogger struct {
+	t testing.TB
+}
+
+func (el testErrorLogger) Log(err error) {
+	el.t.Errorf("Error logged by virtual file system: %s", err)
+}
+
+// NewFakeFileAllocator creates a FileAllocator that stores the contents
+// of files in the provided FilePool. Any errors reported by the files
+// that are created, such as I/O errors on the FilePool, cause the test
+// to fail.
+func NewFakeFileAllocator(t testing.TB, filePool re_filesystem.FilePool) virtual.FileAllocator {
+	return virtual.NewPoolBackedFileAllocator(filePool, testErrorLogger{t: t}, clock.SystemClock)
+}
+
+// NewFakePrepopulatedDirectory creates an empty PrepopulatedDirectory
+// that is fully backed by memory. It can be used to test code that
+// interacts with the virtual file system, without requiring FUSE or NFS
+// to be set up. Handles are allocated using the same strategy as
+// FUSE, and directory listings are sorted to ensure tests behave
+// deterministically.
+func NewFakePrepopulatedDirectory(t testing.TB, fileAllocator virtual.FileAllocator) virtual.PrepopulatedDirectory {
+	handleAllocator := virtual.NewFUSEHandleAllocator(random.FastThreadSafeGenerator)
+	return virtual.NewInMemoryPrepopulatedDirectory(
+		fileAllocator,
+		virtual.NewHandleAllocatingSymlinkFactory(
+			virtual.BaseSymlinkFactory,
+			handleAllocator.New()),
+		testErrorLogger{t: t},
+		handleAllocator,
+		sort.Sort,
+		/* hiddenFilesMatcher = */ func(s string) bool { return false },
+		clock.SystemClock,
+		/* caseInsensitive = */ false)
+}
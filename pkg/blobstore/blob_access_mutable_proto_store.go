@@ -4,7 +4,6 @@ import (
 	"context"
 	"sync"
 
-	"github.com/buildbarn/bb-storage/pkg/blobstore"
 	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
 	"github.com/buildbarn/bb-storage/pkg/digest"
 	"github.com/buildbarn/bb-storage/pkg/util"
@@ -54,7 +53,7 @@ type blobAccessMutableProtoStore[T any, TProto interface {
 	*T
 	proto.Message
 }] struct {
-	initialSizeClassCache   blobstore.BlobAccess
+	initialSizeClassCache   MutableProtoStoreBackend
 	maximumMessageSizeBytes int
 
 	lock           sync.Mutex
@@ -63,7 +62,8 @@ type blobAccessMutableProtoStore[T any, TProto interface {
 }
 
 // NewBlobAccessMutableProtoStore creates an instance of
-// MutableProtoStore that is backed by BlobAccess.
+// MutableProtoStore that is backed by BlobAccess, or any other
+// implementation of MutableProtoStoreBackend.
 //
 // What makes this interface harder to implement is that releasing
 // MutableProtoHandle is performed while holding locks. We can't block,
@@ -75,7 +75,7 @@ type blobAccessMutableProtoStore[T any, TProto interface {
 func NewBlobAccessMutableProtoStore[T any, TProto interface {
 	*T
 	proto.Message
-}](initialSizeClassCache blobstore.BlobAccess, maximumMessageSizeBytes int) MutableProtoStore[TProto] {
+}](initialSizeClassCache MutableProtoStoreBackend, maximumMessageSizeBytes int) MutableProtoStore[TProto] {
 	blobAccessMutableProtoHandleMetrics.Do(func() {
 		prometheus.MustRegister(blobAccessMutableProtoHandlesCreated)
 		prometheus.MustRegister(blobAccessMutableProtoHandlesDestroyed)
@@ -99,24 +99,17 @@ type handleToWrite[T any, TProto interface {
 	writingVersion int
 }
 
-func (ss *blobAccessMutableProtoStore[T, TProto]) Get(ctx context.Context, reducedActionDigest digest.Digest) (MutableProtoHandle[TProto], error) {
-	const writesPerRead = 3
-	handlesToWrite := make([]handleToWrite[T, TProto], 0, writesPerRead)
-
-	// See if a handle for the current reduced action digest already
-	// exists that we can use. Remove it from the write queue to
-	// prevent unnecessary writes to the ISCC.
-	ss.lock.Lock()
-	handleToReturn, hasExistingHandle := ss.handles[reducedActionDigest]
-	if hasExistingHandle {
-		handleToReturn.increaseUseCount()
+// dequeueHandlesToWriteLocked extracts up to maximumWrites handles from
+// the write queue, so that they may be written to storage. It is safe
+// to access handle.message here, as handle.useCount is guaranteed to
+// be zero for handles that are queued for writing.
+func (ss *blobAccessMutableProtoStore[T, TProto]) dequeueHandlesToWriteLocked(maximumWrites int) []handleToWrite[T, TProto] {
+	count := len(ss.handlesToWrite)
+	if count > maximumWrites {
+		count = maximumWrites
 	}
-
-	// Extract a couple of handles from previous actions that we can
-	// write to storage at this point. It is safe to access
-	// handle.message here, as handle.useCount is guaranteed to be
-	// zero for handles that are queued for writing.
-	for i := 0; i < writesPerRead && len(ss.handlesToWrite) > 0; i++ {
+	handlesToWrite := make([]handleToWrite[T, TProto], 0, count)
+	for i := 0; i < count; i++ {
 		newLength := len(ss.handlesToWrite) - 1
 		handle := ss.handlesToWrite[newLength]
 		ss.handlesToWrite[newLength] = nil
@@ -131,6 +124,45 @@ func (ss *blobAccessMutableProtoStore[T, TProto]) Get(ctx context.Context, reduc
 			writingVersion: handle.currentVersion,
 		})
 	}
+	return handlesToWrite
+}
+
+// writeHandles writes messages of handles extracted from the write
+// queue to storage in parallel.
+func (ss *blobAccessMutableProtoStore[T, TProto]) writeHandles(ctx context.Context, group *errgroup.Group, handlesToWrite []handleToWrite[T, TProto]) {
+	for _, handleToWriteIter := range handlesToWrite {
+		handleToWrite := handleToWriteIter
+		group.Go(func() error {
+			if err := ss.initialSizeClassCache.Put(ctx, handleToWrite.handle.digest, buffer.NewProtoBufferFromProto(handleToWrite.message, buffer.UserProvided)); err != nil {
+				ss.lock.Lock()
+				handleToWrite.handle.removeOrQueueForWriteLocked()
+				ss.lock.Unlock()
+				return util.StatusWrapf(err, "Failed to write mutable Protobuf message with digest %#v", handleToWrite.handle.digest.String())
+			}
+			ss.lock.Lock()
+			handleToWrite.handle.writtenVersion = handleToWrite.writingVersion
+			handleToWrite.handle.removeOrQueueForWriteLocked()
+			ss.lock.Unlock()
+			return nil
+		})
+	}
+}
+
+func (ss *blobAccessMutableProtoStore[T, TProto]) Get(ctx context.Context, reducedActionDigest digest.Digest) (MutableProtoHandle[TProto], error) {
+	const writesPerRead = 3
+
+	// See if a handle for the current reduced action digest already
+	// exists that we can use. Remove it from the write queue to
+	// prevent unnecessary writes to the ISCC.
+	ss.lock.Lock()
+	handleToReturn, hasExistingHandle := ss.handles[reducedActionDigest]
+	if hasExistingHandle {
+		handleToReturn.increaseUseCount()
+	}
+
+	// Extract a couple of handles from previous actions that we can
+	// write to storage at this point.
+	handlesToWrite := ss.dequeueHandlesToWriteLocked(writesPerRead)
 	ss.lock.Unlock()
 	blobAccessMutableProtoHandlesDequeued.Add(float64(len(handlesToWrite)))
 
@@ -156,22 +188,7 @@ func (ss *blobAccessMutableProtoStore[T, TProto]) Get(ctx context.Context, reduc
 	}
 
 	// Write statistics for the actions that completed previously.
-	for _, handleToWriteIter := range handlesToWrite {
-		handleToWrite := handleToWriteIter
-		group.Go(func() error {
-			if err := ss.initialSizeClassCache.Put(ctxWithCancel, handleToWrite.handle.digest, buffer.NewProtoBufferFromProto(handleToWrite.message, buffer.UserProvided)); err != nil {
-				ss.lock.Lock()
-				handleToWrite.handle.removeOrQueueForWriteLocked()
-				ss.lock.Unlock()
-				return util.StatusWrapf(err, "Failed to write mutable Protobuf message with digest %#v", handleToWrite.handle.digest.String())
-			}
-			ss.lock.Lock()
-			handleToWrite.handle.writtenVersion = handleToWrite.writingVersion
-			handleToWrite.handle.removeOrQueueForWriteLocked()
-			ss.lock.Unlock()
-			return nil
-		})
-	}
+	ss.writeHandles(ctxWithCancel, group, handlesToWrite)
 
 	// Wait for the read and both writes to complete.
 	if err := group.Wait(); err != nil {
@@ -200,6 +217,20 @@ func (ss *blobAccessMutableProtoStore[T, TProto]) Get(ctx context.Context, reduc
 	return handleToReturn, nil
 }
 
+func (ss *blobAccessMutableProtoStore[T, TProto]) Flush(ctx context.Context, maximumWrites int) (int, error) {
+	ss.lock.Lock()
+	handlesToWrite := ss.dequeueHandlesToWriteLocked(maximumWrites)
+	ss.lock.Unlock()
+	blobAccessMutableProtoHandlesDequeued.Add(float64(len(handlesToWrite)))
+
+	group, ctxWithCancel := errgroup.WithContext(ctx)
+	ss.writeHandles(ctxWithCancel, group, handlesToWrite)
+	if err := group.Wait(); err != nil {
+		return 0, err
+	}
+	return len(handlesToWrite), nil
+}
+
 type blobAccessMutableProtoHandle[T any, TProto interface {
 	*T
 	proto.Message
@@ -171,4 +171,41 @@ func TestBlobAccessMutableProtoStore(t *testing.T) {
 
 		handle5.Release(false)
 	})
+
+	t.Run("Flush", func(t *testing.T) {
+		// Flushing a store that has no handles queued for
+		// writing should not cause any writes.
+		n, err := store.Flush(ctx, 10)
+		require.NoError(t, err)
+		require.Equal(t, 0, n)
+
+		// Create a handle and modify it.
+		blobAccess.EXPECT().Get(gomock.Any(), digest.MustNewDigest("hello", remoteexecution.DigestFunction_MD5, "0ce9b2b1e7bf6e2c4b2e9a5e85b8e8c1", 123)).
+			Return(buffer.NewBufferFromError(status.Error(codes.NotFound, "Blob does not exist")))
+
+		handle, err := store.Get(ctx, digest.MustNewDigest("hello", remoteexecution.DigestFunction_MD5, "0ce9b2b1e7bf6e2c4b2e9a5e85b8e8c1", 123))
+		require.NoError(t, err)
+		handle.GetMutableProto().LastSeenFailure = &timestamppb.Timestamp{Seconds: 1620819007}
+		handle.Release(true)
+
+		// Flush() should write the handle, without needing to
+		// wait for the next call to Get().
+		blobAccess.EXPECT().Put(gomock.Any(), digest.MustNewDigest("hello", remoteexecution.DigestFunction_MD5, "0ce9b2b1e7bf6e2c4b2e9a5e85b8e8c1", 123), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				m, err := b.ToProto(&iscc.PreviousExecutionStats{}, 10000)
+				require.NoError(t, err)
+				testutil.RequireEqualProto(t, &iscc.PreviousExecutionStats{
+					LastSeenFailure: &timestamppb.Timestamp{Seconds: 1620819007},
+				}, m)
+				return nil
+			})
+		n, err = store.Flush(ctx, 10)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+
+		// Successive calls should not write it again.
+		n, err = store.Flush(ctx, 10)
+		require.NoError(t, err)
+		require.Equal(t, 0, n)
+	})
 }
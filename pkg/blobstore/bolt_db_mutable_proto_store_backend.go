@@ -0,0 +1,81 @@
+package blobstore
+
+import (
+	"context"
+
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// boltDBMutableProtoStoreBucket is the name of the BoltDB bucket in
+// which messages are stored.
+var boltDBMutableProtoStoreBucket = []byte("messages")
+
+type boltDBMutableProtoStoreBackend struct {
+	db                      *bbolt.DB
+	maximumMessageSizeBytes int
+}
+
+// NewBoltDBMutableProtoStoreBackend creates a MutableProtoStoreBackend
+// that stores messages in a BoltDB database on local disk. This
+// permits schedulers to persist execution statistics of actions across
+// restarts, without requiring a shared Initial Size Class Cache.
+//
+// All messages are stored in a single bucket, keyed by the key of the
+// reduced action digest. Concurrent calls to Put() are coalesced into
+// a single transaction, to reduce the number of times the database
+// needs to be synchronized to disk.
+func NewBoltDBMutableProtoStoreBackend(db *bbolt.DB, maximumMessageSizeBytes int) (MutableProtoStoreBackend, error) {
+	if err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltDBMutableProtoStoreBucket)
+		return err
+	}); err != nil {
+		return nil, util.StatusWrapWithCode(err, codes.Internal, "Failed to create bucket")
+	}
+	return &boltDBMutableProtoStoreBackend{
+		db:                      db,
+		maximumMessageSizeBytes: maximumMessageSizeBytes,
+	}, nil
+}
+
+func (b *boltDBMutableProtoStoreBackend) Get(ctx context.Context, key digest.Digest) buffer.Buffer {
+	var data []byte
+	if err := b.db.View(func(tx *bbolt.Tx) error {
+		// Values returned by BoltDB are only valid for the
+		// duration of the transaction, so they need to be copied.
+		if value := tx.Bucket(boltDBMutableProtoStoreBucket).Get([]byte(key.GetKey(digest.KeyWithInstance))); value != nil {
+			if len(value) > b.maximumMessageSizeBytes {
+				return status.Errorf(codes.InvalidArgument, "Message is %d bytes in size, while a maximum of %d bytes is permitted", len(value), b.maximumMessageSizeBytes)
+			}
+			data = append([]byte{}, value...)
+		}
+		return nil
+	}); err != nil {
+		if _, ok := status.FromError(err); ok {
+			return buffer.NewBufferFromError(err)
+		}
+		return buffer.NewBufferFromError(util.StatusWrapWithCode(err, codes.Internal, "Failed to read message"))
+	}
+	if data == nil {
+		return buffer.NewBufferFromError(status.Error(codes.NotFound, "Message does not exist"))
+	}
+	return buffer.NewValidatedBufferFromByteSlice(data)
+}
+
+func (b *boltDBMutableProtoStoreBackend) Put(ctx context.Context, key digest.Digest, buf buffer.Buffer) error {
+	data, err := buf.ToByteSlice(b.maximumMessageSizeBytes)
+	if err != nil {
+		return err
+	}
+	if err := b.db.Batch(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltDBMutableProtoStoreBucket).Put([]byte(key.GetKey(digest.KeyWithInstance)), data)
+	}); err != nil {
+		return util.StatusWrapWithCode(err, codes.Internal, "Failed to write message")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package blobstore_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/stretchr/testify/require"
+
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBoltDBMutableProtoStoreBackend(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "previous_execution_stats.db")
+	db, err := bbolt.Open(path, 0o600, nil)
+	require.NoError(t, err)
+	backend, err := blobstore.NewBoltDBMutableProtoStoreBackend(db, 10)
+	require.NoError(t, err)
+	key1 := digest.MustNewDigest("hello", remoteexecution.DigestFunction_MD5, "b3edf9adbbd9cbfc2673c84cd03e5598", 567)
+	key2 := digest.MustNewDigest("world", remoteexecution.DigestFunction_MD5, "b3edf9adbbd9cbfc2673c84cd03e5598", 567)
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := backend.Get(ctx, key1).ToByteSlice(10)
+		testutil.RequireEqualStatus(t, status.Error(codes.NotFound, "Message does not exist"), err)
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		err := backend.Put(ctx, key1, buffer.NewValidatedBufferFromByteSlice([]byte("Hello world")))
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		require.NoError(t, backend.Put(ctx, key1, buffer.NewValidatedBufferFromByteSlice([]byte("Hello"))))
+		require.NoError(t, backend.Put(ctx, key2, buffer.NewValidatedBufferFromByteSlice([]byte("World"))))
+
+		// Keys with different instance names should be stored
+		// separately.
+		data, err := backend.Get(ctx, key1).ToByteSlice(10)
+		require.NoError(t, err)
+		require.Equal(t, []byte("Hello"), data)
+		data, err = backend.Get(ctx, key2).ToByteSlice(10)
+		require.NoError(t, err)
+		require.Equal(t, []byte("World"), data)
+
+		// Overwriting existing messages should be permitted.
+		require.NoError(t, backend.Put(ctx, key1, buffer.NewValidatedBufferFromByteSlice([]byte("Bye"))))
+		data, err = backend.Get(ctx, key1).ToByteSlice(10)
+		require.NoError(t, err)
+		require.Equal(t, []byte("Bye"), data)
+	})
+
+	t.Run("Reopen", func(t *testing.T) {
+		// Messages should be retained across restarts.
+		require.NoError(t, db.Close())
+		db, err := bbolt.Open(path, 0o600, nil)
+		require.NoError(t, err)
+		defer db.Close()
+		backend, err := blobstore.NewBoltDBMutableProtoStoreBackend(db, 10)
+		require.NoError(t, err)
+
+		data, err := backend.Get(ctx, key1).ToByteSlice(10)
+		require.NoError(t, err)
+		require.Equal(t, []byte("Bye"), data)
+	})
+}
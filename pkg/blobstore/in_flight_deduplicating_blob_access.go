@@ -0,0 +1,81 @@
+package blobstore
+
+import (
+	"context"
+	"sync"
+
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+)
+
+type inFlightPutOperation struct {
+	done chan struct{}
+	err  error
+}
+
+type inFlightDeduplicatingBlobAccess struct {
+	blobstore.BlobAccess
+	blobKeyFormat digest.KeyFormat
+
+	lock                  sync.Mutex
+	inFlightPutOperations map[string]*inFlightPutOperation
+}
+
+// NewInFlightDeduplicatingBlobAccess is an adapter for BlobAccess that
+// merges concurrent Put() operations for the same blob. Only the first
+// caller uploads the blob. Other callers wait for that upload to
+// complete and discard their own copy of the blob.
+//
+// This adapter may be shared by all worker threads, so that identical
+// output files produced by actions running in parallel (e.g., empty
+// files, stamped manifests) are only uploaded once. If the upload
+// fails, callers that were waiting for it retry the upload using
+// their own copy of the blob, so that the failure of one action (e.g.,
+// due to cancelation) does not cause others to fail.
+func NewInFlightDeduplicatingBlobAccess(base blobstore.BlobAccess, blobKeyFormat digest.KeyFormat) blobstore.BlobAccess {
+	return &inFlightDeduplicatingBlobAccess{
+		BlobAccess:            base,
+		blobKeyFormat:         blobKeyFormat,
+		inFlightPutOperations: map[string]*inFlightPutOperation{},
+	}
+}
+
+func (ba *inFlightDeduplicatingBlobAccess) Put(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+	key := digest.GetKey(ba.blobKeyFormat)
+	for {
+		ba.lock.Lock()
+		if o, ok := ba.inFlightPutOperations[key]; ok {
+			// Another caller is already uploading the
+			// same blob. Wait for it to complete.
+			ba.lock.Unlock()
+			select {
+			case <-o.done:
+				if o.err == nil {
+					b.Discard()
+					return nil
+				}
+			case <-ctx.Done():
+				b.Discard()
+				return util.StatusFromContext(ctx)
+			}
+			continue
+		}
+
+		// Upload the blob ourselves.
+		o := &inFlightPutOperation{
+			done: make(chan struct{}),
+		}
+		ba.inFlightPutOperations[key] = o
+		ba.lock.Unlock()
+
+		o.err = ba.BlobAccess.Put(ctx, digest, b)
+
+		ba.lock.Lock()
+		delete(ba.inFlightPutOperations, key)
+		ba.lock.Unlock()
+		close(o.done)
+		return o.err
+	}
+}
@@ -0,0 +1,123 @@
+package blobstore_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestInFlightDeduplicatingBlobAccess(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseBlobAccess := mock.NewMockBlobAccess(ctrl)
+	blobAccess := blobstore.NewInFlightDeduplicatingBlobAccess(baseBlobAccess, digest.KeyWithoutInstance)
+
+	digestHello := digest.MustNewDigest(
+		"default",
+		remoteexecution.DigestFunction_MD5,
+		"8b1a9953c4611296a827abf8c47804d7",
+		5)
+
+	t.Run("Sequential", func(t *testing.T) {
+		// Uploads that don't overlap in time should not be
+		// merged.
+		baseBlobAccess.EXPECT().Put(ctx, digestHello, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				b.Discard()
+				return nil
+			}).Times(2)
+
+		require.NoError(t, blobAccess.Put(ctx, digestHello, buffer.NewValidatedBufferFromByteSlice([]byte("Hello"))))
+		require.NoError(t, blobAccess.Put(ctx, digestHello, buffer.NewValidatedBufferFromByteSlice([]byte("Hello"))))
+	})
+
+	t.Run("ConcurrentCanceled", func(t *testing.T) {
+		// While the first upload is in progress, other uploads
+		// of the same blob should wait for it to complete
+		// instead of calling into the backend. It should be
+		// possible to abandon waiting.
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+		baseBlobAccess.EXPECT().Put(ctx, digestHello, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				close(started)
+				<-unblock
+				b.Discard()
+				return nil
+			})
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			require.NoError(t, blobAccess.Put(ctx, digestHello, buffer.NewValidatedBufferFromByteSlice([]byte("Hello"))))
+			wg.Done()
+		}()
+		<-started
+
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.Canceled, "context canceled"),
+			blobAccess.Put(canceledCtx, digestHello, buffer.NewValidatedBufferFromByteSlice([]byte("Hello"))))
+
+		close(unblock)
+		wg.Wait()
+	})
+
+	t.Run("ConcurrentFailure", func(t *testing.T) {
+		// If the first upload fails, the error should be
+		// returned to its caller. Callers that were waiting
+		// should retry the upload using their own copy.
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+		baseBlobAccess.EXPECT().Put(ctx, digestHello, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				close(started)
+				<-unblock
+				b.Discard()
+				return status.Error(codes.Internal, "Server on fire")
+			})
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			testutil.RequireEqualStatus(
+				t,
+				status.Error(codes.Internal, "Server on fire"),
+				blobAccess.Put(ctx, digestHello, buffer.NewValidatedBufferFromByteSlice([]byte("Hello"))))
+			wg.Done()
+		}()
+		<-started
+
+		retried := make(chan struct{})
+		baseBlobAccess.EXPECT().Put(ctx, digestHello, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				data, err := b.ToByteSlice(100)
+				require.NoError(t, err)
+				require.Equal(t, []byte("Hello"), data)
+				close(retried)
+				return nil
+			})
+		wg.Add(1)
+		go func() {
+			require.NoError(t, blobAccess.Put(ctx, digestHello, buffer.NewValidatedBufferFromByteSlice([]byte("Hello"))))
+			wg.Done()
+		}()
+		close(unblock)
+		<-retried
+		wg.Wait()
+	})
+}
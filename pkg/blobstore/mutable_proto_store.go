@@ -3,6 +3,7 @@ package blobstore
 import (
 	"context"
 
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
 	"github.com/buildbarn/bb-storage/pkg/digest"
 
 	"google.golang.org/protobuf/proto"
@@ -20,6 +21,24 @@ import (
 // invalidated after locks are dropped.
 type MutableProtoStore[T proto.Message] interface {
 	Get(ctx context.Context, reducedActionDigest digest.Digest) (MutableProtoHandle[T], error)
+
+	// Flush() writes up to maximumWrites messages belonging to
+	// handles that have been released back to storage. This can
+	// be called periodically to ensure that modified messages are
+	// written in a timely fashion, even if Get() is called
+	// infrequently. It returns the number of messages that were
+	// written, which callers may use to determine whether all
+	// pending messages have been written prior to shutting down.
+	Flush(ctx context.Context, maximumWrites int) (int, error)
+}
+
+// MutableProtoStoreBackend is the storage in which MutableProtoStore
+// persists its Protobuf messages. It is a subset of BlobAccess, so
+// that the Initial Size Class Cache can be used directly, while also
+// permitting simpler key-value stores to be used.
+type MutableProtoStoreBackend interface {
+	Get(ctx context.Context, key digest.Digest) buffer.Buffer
+	Put(ctx context.Context, key digest.Digest, b buffer.Buffer) error
 }
 
 // MutableProtoHandle is a handle that is returned by MutableProtoStore.
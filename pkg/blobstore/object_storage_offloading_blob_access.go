@@ -0,0 +1,80 @@
+package blobstore
+
+import (
+	"context"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/blobstore/objectstorage"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+)
+
+type objectStorageOffloadingBlobAccess struct {
+	blobstore.BlobAccess
+	indirectContentAddressableStorage blobstore.BlobAccess
+	objectStore                       objectstorage.ObjectStore
+	keyPrefix                         string
+	minimumSizeBytes                  int64
+	instanceNamePrefixes              *digest.InstanceNameTrie
+}
+
+// NewObjectStorageOffloadingBlobAccess creates a decorator for a
+// Content Addressable Storage (CAS) that writes the contents of large
+// blobs into an object store (e.g., Amazon S3 or Google Cloud Storage)
+// instead. This may be used by deployments whose CAS is not capable of
+// storing blobs that are multiple gigabytes in size.
+//
+// For every blob that is written into the object store, a reference
+// is written into an Indirect Content Addressable Storage (ICAS) under
+// the digest of the blob. Storage frontends and workers that combine
+// their CAS with bb-storage's ReferenceExpandingBlobAccess (e.g., by
+// using 'read_fallback' and 'reference_expanding') are thus capable of
+// reading these blobs, and report them as being present. This means
+// that clients and completeness checking of the Action Cache are not
+// affected by offloading.
+//
+// Only blobs whose size is at least minimumSizeBytes and that belong
+// to an instance name starting with one of the provided prefixes are
+// written into the object store. This decorator only alters the
+// behavior of writes. It should only be applied to the path through
+// which output files of build actions are uploaded.
+func NewObjectStorageOffloadingBlobAccess(base, indirectContentAddressableStorage blobstore.BlobAccess, objectStore objectstorage.ObjectStore, keyPrefix string, minimumSizeBytes int64, instanceNamePrefixes []digest.InstanceName) blobstore.BlobAccess {
+	instanceNamePrefixesTrie := digest.NewInstanceNameTrie()
+	for i, instanceNamePrefix := range instanceNamePrefixes {
+		instanceNamePrefixesTrie.Set(instanceNamePrefix, i)
+	}
+	return &objectStorageOffloadingBlobAccess{
+		BlobAccess:                        base,
+		indirectContentAddressableStorage: indirectContentAddressableStorage,
+		objectStore:                       objectStore,
+		keyPrefix:                         keyPrefix,
+		minimumSizeBytes:                  minimumSizeBytes,
+		instanceNamePrefixes:              instanceNamePrefixesTrie,
+	}
+}
+
+func (ba *objectStorageOffloadingBlobAccess) Put(ctx context.Context, blobDigest digest.Digest, b buffer.Buffer) error {
+	sizeBytes := blobDigest.GetSizeBytes()
+	if sizeBytes < ba.minimumSizeBytes || ba.instanceNamePrefixes.GetLongestPrefix(blobDigest.GetInstanceName()) < 0 {
+		return ba.BlobAccess.Put(ctx, blobDigest, b)
+	}
+
+	key := ba.keyPrefix + blobDigest.GetKey(digest.KeyWithInstance)
+	r := b.ToReader()
+	err := ba.objectStore.Put(ctx, key, sizeBytes, r)
+	r.Close()
+	if err != nil {
+		return util.StatusWrap(err, "Failed to write blob to object store")
+	}
+
+	// Only store the reference after the object has been written,
+	// so that the blob is not reported as being present
+	// prematurely.
+	reference := ba.objectStore.NewReference(key)
+	reference.SizeBytes = sizeBytes
+	if err := ba.indirectContentAddressableStorage.Put(ctx, blobDigest, buffer.NewProtoBufferFromProto(reference, buffer.UserProvided)); err != nil {
+		return util.StatusWrap(err, "Failed to write reference to object into the Indirect Content Addressable Storage")
+	}
+	return nil
+}
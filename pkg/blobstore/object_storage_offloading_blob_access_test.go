@@ -0,0 +1,149 @@
+package blobstore_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/proto/icas"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestObjectStorageOffloadingBlobAccess(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseBlobAccess := mock.NewMockBlobAccess(ctrl)
+	indirectContentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	objectStore := mock.NewMockObjectStore(ctrl)
+	blobAccess := blobstore.NewObjectStorageOffloadingBlobAccess(
+		baseBlobAccess,
+		indirectContentAddressableStorage,
+		objectStore,
+		"outputs/",
+		5,
+		[]digest.InstanceName{digest.MustNewInstanceName("hello")})
+
+	smallDigest := digest.MustNewDigest("hello", remoteexecution.DigestFunction_MD5, "d3b07384d113edec49eaa6238ad5ff00", 4)
+	largeDigest := digest.MustNewDigest("hello", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	otherInstanceDigest := digest.MustNewDigest("other", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	objectKey := "outputs/" + largeDigest.GetKey(digest.KeyWithInstance)
+
+	t.Run("PutSmall", func(t *testing.T) {
+		// Blobs below the size threshold should be written into
+		// the CAS directly.
+		baseBlobAccess.EXPECT().Put(ctx, smallDigest, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				b.Discard()
+				return nil
+			})
+
+		require.NoError(t, blobAccess.Put(ctx, smallDigest, buffer.NewValidatedBufferFromByteSlice([]byte("Foo\n"))))
+	})
+
+	t.Run("PutOtherInstanceName", func(t *testing.T) {
+		// Blobs belonging to instance names for which
+		// offloading is disabled should also be written into the
+		// CAS directly.
+		baseBlobAccess.EXPECT().Put(ctx, otherInstanceDigest, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				b.Discard()
+				return nil
+			})
+
+		require.NoError(t, blobAccess.Put(ctx, otherInstanceDigest, buffer.NewValidatedBufferFromByteSlice([]byte("Hello"))))
+	})
+
+	t.Run("PutObjectStoreFailure", func(t *testing.T) {
+		// If writing into the object store fails, no reference
+		// should be written into the ICAS.
+		objectStore.EXPECT().Put(ctx, objectKey, int64(5), gomock.Any()).
+			Return(status.Error(codes.Unavailable, "Server offline"))
+
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.Unavailable, "Failed to write blob to object store: Server offline"),
+			blobAccess.Put(ctx, largeDigest, buffer.NewValidatedBufferFromByteSlice([]byte("Hello"))))
+	})
+
+	t.Run("PutReferenceFailure", func(t *testing.T) {
+		objectStore.EXPECT().Put(ctx, objectKey, int64(5), gomock.Any()).Return(nil)
+		objectStore.EXPECT().NewReference(objectKey).Return(&icas.Reference{
+			Medium: &icas.Reference_S3_{
+				S3: &icas.Reference_S3{
+					Bucket: "my-bucket",
+					Key:    objectKey,
+				},
+			},
+		})
+		indirectContentAddressableStorage.EXPECT().Put(ctx, largeDigest, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				b.Discard()
+				return status.Error(codes.Unavailable, "Server offline")
+			})
+
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.Unavailable, "Failed to write reference to object into the Indirect Content Addressable Storage: Server offline"),
+			blobAccess.Put(ctx, largeDigest, buffer.NewValidatedBufferFromByteSlice([]byte("Hello"))))
+	})
+
+	t.Run("PutLarge", func(t *testing.T) {
+		// Large blobs should be written into the object store,
+		// followed by writing a reference to it into the ICAS
+		// under the digest of the blob. This permits readers to
+		// access the blob through the CAS.
+		objectStore.EXPECT().Put(ctx, objectKey, int64(5), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, key string, sizeBytes int64, r io.Reader) error {
+				data, err := io.ReadAll(r)
+				require.NoError(t, err)
+				require.Equal(t, []byte("Hello"), data)
+				return nil
+			})
+		objectStore.EXPECT().NewReference(objectKey).Return(&icas.Reference{
+			Medium: &icas.Reference_S3_{
+				S3: &icas.Reference_S3{
+					Bucket: "my-bucket",
+					Key:    objectKey,
+				},
+			},
+		})
+		indirectContentAddressableStorage.EXPECT().Put(ctx, largeDigest, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				reference, err := b.ToProto(&icas.Reference{}, 1000)
+				require.NoError(t, err)
+				testutil.RequireEqualProto(t, &icas.Reference{
+					Medium: &icas.Reference_S3_{
+						S3: &icas.Reference_S3{
+							Bucket: "my-bucket",
+							Key:    objectKey,
+						},
+					},
+					SizeBytes: 5,
+				}, reference)
+				return nil
+			})
+
+		require.NoError(t, blobAccess.Put(ctx, largeDigest, buffer.NewValidatedBufferFromByteSlice([]byte("Hello"))))
+	})
+
+	t.Run("GetLarge", func(t *testing.T) {
+		// Reads should not be affected, even if they are for
+		// blobs that meet the criteria for offloading.
+		baseBlobAccess.EXPECT().Get(ctx, largeDigest).
+			Return(buffer.NewValidatedBufferFromByteSlice([]byte("Hello")))
+
+		data, err := blobAccess.Get(ctx, largeDigest).ToByteSlice(1000)
+		require.NoError(t, err)
+		require.Equal(t, []byte("Hello"), data)
+	})
+}
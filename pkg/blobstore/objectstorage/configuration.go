@@ -0,0 +1,44 @@
+package objectstorage
+
+import (
+	"context"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	pb "github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/objectstorage"
+	"github.com/buildbarn/bb-storage/pkg/cloud/aws"
+	"github.com/buildbarn/bb-storage/pkg/cloud/gcp"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewObjectStoreFromConfiguration creates an ObjectStore based on
+// parameters provided in a configuration file.
+func NewObjectStoreFromConfiguration(ctx context.Context, configuration *pb.ObjectStoreConfiguration) (ObjectStore, error) {
+	if configuration == nil {
+		return nil, status.Error(codes.InvalidArgument, "No object store configuration provided")
+	}
+
+	switch backend := configuration.Backend.(type) {
+	case *pb.ObjectStoreConfiguration_S3:
+		cfg, err := aws.NewConfigFromConfiguration(backend.S3.AwsSession, "S3ObjectStore")
+		if err != nil {
+			return nil, util.StatusWrap(err, "Failed to create AWS config")
+		}
+		return NewS3ObjectStore(s3.NewFromConfig(cfg), backend.S3.Bucket), nil
+	case *pb.ObjectStoreConfiguration_Gcs:
+		clientOptions, err := gcp.NewClientOptionsFromConfiguration(backend.Gcs.ClientOptions, "GCSObjectStore")
+		if err != nil {
+			return nil, util.StatusWrap(err, "Failed to create Google Cloud client options")
+		}
+		client, err := storage.NewClient(ctx, clientOptions...)
+		if err != nil {
+			return nil, util.StatusWrap(err, "Failed to create Google Cloud Storage client")
+		}
+		return NewGCSObjectStore(client, backend.Gcs.Bucket), nil
+	default:
+		return nil, status.Error(codes.InvalidArgument, "Configuration did not contain a supported object store backend")
+	}
+}
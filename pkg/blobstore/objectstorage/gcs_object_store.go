@@ -0,0 +1,56 @@
+package objectstorage
+
+import (
+	"context"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"github.com/buildbarn/bb-storage/pkg/proto/icas"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+)
+
+type gcsObjectStore struct {
+	bucket     *storage.BucketHandle
+	bucketName string
+}
+
+// NewGCSObjectStore creates an ObjectStore that stores objects in a
+// Google Cloud Storage bucket.
+func NewGCSObjectStore(client *storage.Client, bucketName string) ObjectStore {
+	return &gcsObjectStore{
+		bucket:     client.Bucket(bucketName),
+		bucketName: bucketName,
+	}
+}
+
+func (s *gcsObjectStore) NewReference(key string) *icas.Reference {
+	return &icas.Reference{
+		Medium: &icas.Reference_Gcs{
+			Gcs: &icas.Reference_GCS{
+				Bucket: s.bucketName,
+				Object: key,
+			},
+		},
+	}
+}
+
+func (s *gcsObjectStore) Put(ctx context.Context, key string, sizeBytes int64, r io.Reader) error {
+	// Canceling the context prior to closing the writer causes
+	// the upload to be abandoned, meaning the object does not
+	// become visible.
+	ctxWithCancel, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := s.bucket.Object(key).NewWriter(ctxWithCancel)
+	if _, err := io.Copy(w, io.LimitReader(r, sizeBytes)); err != nil {
+		cancel()
+		w.Close()
+		return util.StatusWrapWithCode(err, codes.Unavailable, "Failed to write object")
+	}
+	if err := w.Close(); err != nil {
+		return util.StatusWrapWithCode(err, codes.Unavailable, "Failed to close object")
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package objectstorage
+
+import (
+	"context"
+	"io"
+
+	"github.com/buildbarn/bb-storage/pkg/proto/icas"
+)
+
+// ObjectStore is a simple key-value store for objects that may be
+// multiple gigabytes in size, such as Amazon S3 or Google Cloud
+// Storage. It is used to store blobs that are too large to be stored
+// in the Content Addressable Storage efficiently.
+type ObjectStore interface {
+	// Put an object into storage, reading its contents from a
+	// reader that yields exactly sizeBytes bytes of data. The
+	// object must not become visible if reading fails.
+	Put(ctx context.Context, key string, sizeBytes int64, r io.Reader) error
+	// NewReference returns a reference to an object, which may be
+	// stored in an Indirect Content Addressable Storage (ICAS). The
+	// caller is responsible for filling in the size of the object.
+	NewReference(key string) *icas.Reference
+}
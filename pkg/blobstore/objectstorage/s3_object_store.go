@@ -0,0 +1,117 @@
+package objectstorage
+
+import (
+	"context"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/buildbarn/bb-storage/pkg/proto/icas"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+)
+
+// s3PartSizeBytes is the size of the parts in which objects are
+// uploaded using S3's multipart upload API. As S3 permits up to 10,000
+// parts per object, this permits storing objects up to 2.5 TB in size.
+const s3PartSizeBytes = 256 * 1024 * 1024
+
+type s3ObjectStore struct {
+	client *s3.Client
+	bucket *string
+}
+
+// NewS3ObjectStore creates an ObjectStore that stores objects in an
+// Amazon S3 bucket. Objects that are larger than a single part are
+// uploaded using the multipart upload API, as S3 does not permit
+// uploading objects larger than 5 GB in a single request.
+func NewS3ObjectStore(client *s3.Client, bucket string) ObjectStore {
+	return &s3ObjectStore{
+		client: client,
+		bucket: aws.String(bucket),
+	}
+}
+
+func (s *s3ObjectStore) NewReference(key string) *icas.Reference {
+	return &icas.Reference{
+		Medium: &icas.Reference_S3_{
+			S3: &icas.Reference_S3{
+				Bucket: *s.bucket,
+				Key:    key,
+			},
+		},
+	}
+}
+
+func (s *s3ObjectStore) Put(ctx context.Context, key string, sizeBytes int64, r io.Reader) error {
+	if sizeBytes <= s3PartSizeBytes {
+		if _, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:        s.bucket,
+			Key:           aws.String(key),
+			Body:          r,
+			ContentLength: aws.Int64(sizeBytes),
+		}); err != nil {
+			return util.StatusWrapWithCode(err, codes.Unavailable, "Failed to put object")
+		}
+		return nil
+	}
+
+	upload, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: s.bucket,
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return util.StatusWrapWithCode(err, codes.Unavailable, "Failed to create multipart upload")
+	}
+	if err := s.uploadParts(ctx, key, upload.UploadId, sizeBytes, r); err != nil {
+		// Release storage consumed by parts that have already
+		// been uploaded.
+		s.client.AbortMultipartUpload(context.Background(), &s3.AbortMultipartUploadInput{
+			Bucket:   s.bucket,
+			Key:      aws.String(key),
+			UploadId: upload.UploadId,
+		})
+		return err
+	}
+	return nil
+}
+
+func (s *s3ObjectStore) uploadParts(ctx context.Context, key string, uploadID *string, sizeBytes int64, r io.Reader) error {
+	var parts []types.CompletedPart
+	for partNumber, offset := int32(1), int64(0); offset < sizeBytes; partNumber++ {
+		partSizeBytes := sizeBytes - offset
+		if partSizeBytes > s3PartSizeBytes {
+			partSizeBytes = s3PartSizeBytes
+		}
+		output, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:        s.bucket,
+			Key:           aws.String(key),
+			UploadId:      uploadID,
+			PartNumber:    aws.Int32(partNumber),
+			Body:          io.LimitReader(r, partSizeBytes),
+			ContentLength: aws.Int64(partSizeBytes),
+		})
+		if err != nil {
+			return util.StatusWrapfWithCode(err, codes.Unavailable, "Failed to upload part %d", partNumber)
+		}
+		parts = append(parts, types.CompletedPart{
+			ETag:       output.ETag,
+			PartNumber: aws.Int32(partNumber),
+		})
+		offset += partSizeBytes
+	}
+
+	if _, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:   s.bucket,
+		Key:      aws.String(key),
+		UploadId: uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: parts,
+		},
+	}); err != nil {
+		return util.StatusWrapWithCode(err, codes.Unavailable, "Failed to complete multipart upload")
+	}
+	return nil
+}
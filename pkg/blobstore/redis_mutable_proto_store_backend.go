@@ -0,0 +1,78 @@
+package blobstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/redis/go-redis/v9"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RedisClient is the subset of the operations provided by the go-redis
+// client that is used by the Redis MutableProtoStoreBackend. It is
+// declared explicitly, so that it can be mocked.
+type RedisClient interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+}
+
+var _ RedisClient = redis.UniversalClient(nil)
+
+type redisMutableProtoStoreBackend struct {
+	client                  RedisClient
+	keyPrefix               string
+	expiration              time.Duration
+	maximumMessageSizeBytes int
+}
+
+// NewRedisMutableProtoStoreBackend creates a MutableProtoStoreBackend
+// that stores messages in Redis, using the GET and SET commands. This
+// permits multiple schedulers to share execution statistics of actions,
+// without requiring a full Initial Size Class Cache.
+//
+// Messages are stored under keys consisting of the provided prefix,
+// followed by the key of the reduced action digest. If expiration is
+// non-zero, messages are stored with a time to live, so that
+// statistics of actions that are no longer executed are discarded.
+func NewRedisMutableProtoStoreBackend(client RedisClient, keyPrefix string, expiration time.Duration, maximumMessageSizeBytes int) MutableProtoStoreBackend {
+	return &redisMutableProtoStoreBackend{
+		client:                  client,
+		keyPrefix:               keyPrefix,
+		expiration:              expiration,
+		maximumMessageSizeBytes: maximumMessageSizeBytes,
+	}
+}
+
+func (b *redisMutableProtoStoreBackend) getKey(key digest.Digest) string {
+	return b.keyPrefix + key.GetKey(digest.KeyWithInstance)
+}
+
+func (b *redisMutableProtoStoreBackend) Get(ctx context.Context, key digest.Digest) buffer.Buffer {
+	data, err := b.client.Get(ctx, b.getKey(key)).Bytes()
+	if err == redis.Nil {
+		return buffer.NewBufferFromError(status.Error(codes.NotFound, "Message does not exist"))
+	}
+	if err != nil {
+		return buffer.NewBufferFromError(util.StatusWrapWithCode(err, codes.Unavailable, "Failed to read message from Redis"))
+	}
+	if len(data) > b.maximumMessageSizeBytes {
+		return buffer.NewBufferFromError(status.Errorf(codes.InvalidArgument, "Message is %d bytes in size, while a maximum of %d bytes is permitted", len(data), b.maximumMessageSizeBytes))
+	}
+	return buffer.NewValidatedBufferFromByteSlice(data)
+}
+
+func (b *redisMutableProtoStoreBackend) Put(ctx context.Context, key digest.Digest, buf buffer.Buffer) error {
+	data, err := buf.ToByteSlice(b.maximumMessageSizeBytes)
+	if err != nil {
+		return err
+	}
+	if err := b.client.Set(ctx, b.getKey(key), data, b.expiration).Err(); err != nil {
+		return util.StatusWrapWithCode(err, codes.Unavailable, "Failed to write message to Redis")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package blobstore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRedisMutableProtoStoreBackend(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	client := mock.NewMockRedisClient(ctrl)
+	backend := blobstore.NewRedisMutableProtoStoreBackend(client, "iscc:", time.Hour, 10)
+	key := digest.MustNewDigest("hello", remoteexecution.DigestFunction_MD5, "b3edf9adbbd9cbfc2673c84cd03e5598", 567)
+	redisKey := "iscc:" + key.GetKey(digest.KeyWithInstance)
+
+	t.Run("GetNotFound", func(t *testing.T) {
+		client.EXPECT().Get(ctx, redisKey).Return(redis.NewStringResult("", redis.Nil))
+
+		_, err := backend.Get(ctx, key).ToByteSlice(10)
+		testutil.RequireEqualStatus(t, status.Error(codes.NotFound, "Message does not exist"), err)
+	})
+
+	t.Run("GetFailure", func(t *testing.T) {
+		client.EXPECT().Get(ctx, redisKey).Return(redis.NewStringResult("", status.Error(codes.Internal, "Connection reset by peer")))
+
+		_, err := backend.Get(ctx, key).ToByteSlice(10)
+		testutil.RequireEqualStatus(t, status.Error(codes.Unavailable, "Failed to read message from Redis: Connection reset by peer"), err)
+	})
+
+	t.Run("GetTooLarge", func(t *testing.T) {
+		client.EXPECT().Get(ctx, redisKey).Return(redis.NewStringResult("Hello world", nil))
+
+		_, err := backend.Get(ctx, key).ToByteSlice(10)
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Message is 11 bytes in size, while a maximum of 10 bytes is permitted"), err)
+	})
+
+	t.Run("GetSuccess", func(t *testing.T) {
+		client.EXPECT().Get(ctx, redisKey).Return(redis.NewStringResult("Hello", nil))
+
+		data, err := backend.Get(ctx, key).ToByteSlice(10)
+		require.NoError(t, err)
+		require.Equal(t, []byte("Hello"), data)
+	})
+
+	t.Run("PutFailure", func(t *testing.T) {
+		client.EXPECT().Set(ctx, redisKey, []byte("Hello"), time.Hour).
+			Return(redis.NewStatusResult("", status.Error(codes.Internal, "NOAUTH Authentication required")))
+
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.Unavailable, "Failed to write message to Redis: NOAUTH Authentication required"),
+			backend.Put(ctx, key, buffer.NewValidatedBufferFromByteSlice([]byte("Hello"))))
+	})
+
+	t.Run("PutSuccess", func(t *testing.T) {
+		client.EXPECT().Set(ctx, redisKey, []byte("Hello"), time.Hour).Return(redis.NewStatusResult("OK", nil))
+
+		require.NoError(t, backend.Put(ctx, key, buffer.NewValidatedBufferFromByteSlice([]byte("Hello"))))
+	})
+}
@@ -0,0 +1,239 @@
+package blobstore
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/util"
+)
+
+// TransferPriority of a transfer that is admitted by a
+// TransferLimiter. Transfers having a higher priority are always
+// admitted before transfers having a lower priority.
+type TransferPriority int
+
+const (
+	// TransferPriorityHigh is used by transfers that block the
+	// execution of actions, such as fetching input files.
+	TransferPriorityHigh TransferPriority = iota
+	// TransferPriorityLow is used by transfers that only need to
+	// complete eventually, such as uploading output files.
+	TransferPriorityLow
+
+	transferPriorityCount
+)
+
+// TransferLimiter limits the number of transfers against storage that
+// may run concurrently, and the rate at which data is transferred.
+// Instances may be shared by multiple BlobAccess decorators, so that
+// limits can be applied to all transfers performed by a process.
+//
+// Bandwidth is limited using a token bucket. Tokens are deducted while
+// data is being transferred, by calling WaitForBandwidth() for every
+// chunk of data. As a chunk may be transferred as soon as the bucket is
+// non-empty, the bucket may temporarily hold a negative number of
+// tokens, though never more than the size of a single chunk per
+// transfer. This means that a large low priority transfer cannot
+// starve high priority transfers of bandwidth for a prolonged amount
+// of time.
+type TransferLimiter struct {
+	clock                      clock.Clock
+	maximumConcurrentTransfers int64
+	bytesPerSecond             float64
+	burstBytes                 float64
+
+	lock sync.Mutex
+
+	activeTransfers int64
+	slotWaiters     [transferPriorityCount][]chan struct{}
+
+	tokens           float64
+	lastRefill       time.Time
+	bandwidthWaiters [transferPriorityCount]int
+	bandwidthWakeup  chan struct{}
+}
+
+// NewTransferLimiter creates a TransferLimiter that permits a given
+// number of concurrent transfers, transferring data at a given rate.
+// Limits that are set to zero are not enforced. If no burst size is
+// provided, it is set to the amount of data that may be transferred in
+// one second.
+func NewTransferLimiter(clock clock.Clock, maximumConcurrentTransfers, bytesPerSecond, burstBytes int64) *TransferLimiter {
+	if burstBytes <= 0 {
+		burstBytes = bytesPerSecond
+	}
+	return &TransferLimiter{
+		clock:                      clock,
+		maximumConcurrentTransfers: maximumConcurrentTransfers,
+		bytesPerSecond:             float64(bytesPerSecond),
+		burstBytes:                 float64(burstBytes),
+
+		tokens:          float64(burstBytes),
+		lastRefill:      clock.Now(),
+		bandwidthWakeup: make(chan struct{}),
+	}
+}
+
+// Acquire blocks until a transfer having a given priority may start.
+// Upon success, Release() must be called when the transfer completes.
+func (l *TransferLimiter) Acquire(ctx context.Context, priority TransferPriority) error {
+	if l.maximumConcurrentTransfers == 0 {
+		return nil
+	}
+
+	l.lock.Lock()
+	if l.activeTransfers < l.maximumConcurrentTransfers && !l.hasSlotWaitersLocked(priority) {
+		l.activeTransfers++
+		l.lock.Unlock()
+		return nil
+	}
+	wakeup := make(chan struct{})
+	l.slotWaiters[priority] = append(l.slotWaiters[priority], wakeup)
+	l.lock.Unlock()
+
+	select {
+	case <-wakeup:
+		return nil
+	case <-ctx.Done():
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		select {
+		case <-wakeup:
+			// A slot was handed over to us while the
+			// context was being canceled. Pass it on.
+			l.releaseSlotLocked()
+		default:
+			waiters := l.slotWaiters[priority]
+			for i, w := range waiters {
+				if w == wakeup {
+					l.slotWaiters[priority] = append(waiters[:i:i], waiters[i+1:]...)
+					break
+				}
+			}
+		}
+		return util.StatusFromContext(ctx)
+	}
+}
+
+// Release a slot for running a transfer that was obtained through
+// Acquire(). If transfers are waiting for a slot, the slot is handed
+// over to the one with the highest priority.
+func (l *TransferLimiter) Release() {
+	if l.maximumConcurrentTransfers == 0 {
+		return
+	}
+	l.lock.Lock()
+	l.releaseSlotLocked()
+	l.lock.Unlock()
+}
+
+func (l *TransferLimiter) releaseSlotLocked() {
+	for priority := range l.slotWaiters {
+		if waiters := l.slotWaiters[priority]; len(waiters) > 0 {
+			close(waiters[0])
+			l.slotWaiters[priority] = waiters[1:]
+			return
+		}
+	}
+	l.activeTransfers--
+}
+
+// hasSlotWaitersLocked returns whether transfers having a priority
+// that is higher than or equal to the one provided are waiting for a
+// slot.
+func (l *TransferLimiter) hasSlotWaitersLocked(priority TransferPriority) bool {
+	for _, waiters := range l.slotWaiters[:priority+1] {
+		if len(waiters) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// refillLocked adds tokens to the bucket, based on the amount of time
+// that has passed since the last refill.
+func (l *TransferLimiter) refillLocked() {
+	now := l.clock.Now()
+	l.tokens = math.Min(l.burstBytes, l.tokens+now.Sub(l.lastRefill).Seconds()*l.bytesPerSecond)
+	l.lastRefill = now
+}
+
+// mustWaitForBandwidthLocked returns whether a transfer must wait for
+// other transfers to be granted bandwidth first. Transfers only need
+// to wait for ones that have a higher priority, or ones having the same
+// priority that started waiting earlier.
+func (l *TransferLimiter) mustWaitForBandwidthLocked(priority TransferPriority, waiting bool) bool {
+	for _, count := range l.bandwidthWaiters[:priority] {
+		if count > 0 {
+			return true
+		}
+	}
+	return !waiting && l.bandwidthWaiters[priority] > 0
+}
+
+// stopWaitingForBandwidthLocked unregisters a transfer that was
+// waiting for bandwidth, and wakes up all other waiting transfers, as
+// they may now be permitted to proceed.
+func (l *TransferLimiter) stopWaitingForBandwidthLocked(priority TransferPriority) {
+	l.bandwidthWaiters[priority]--
+	close(l.bandwidthWakeup)
+	l.bandwidthWakeup = make(chan struct{})
+}
+
+// WaitForBandwidth blocks until a chunk of data of a given size having
+// a given priority may be transferred, and deducts its size from the
+// token bucket. Callers should transfer data in small chunks, so that
+// transfers having a higher priority are not delayed excessively.
+func (l *TransferLimiter) WaitForBandwidth(ctx context.Context, priority TransferPriority, sizeBytes int64) error {
+	if l.bytesPerSecond == 0 {
+		return nil
+	}
+
+	l.lock.Lock()
+	waiting := false
+	for {
+		l.refillLocked()
+		if l.tokens >= 0 && !l.mustWaitForBandwidthLocked(priority, waiting) {
+			l.tokens -= float64(sizeBytes)
+			if waiting {
+				l.stopWaitingForBandwidthLocked(priority)
+			}
+			l.lock.Unlock()
+			return nil
+		}
+		if !waiting {
+			l.bandwidthWaiters[priority]++
+			waiting = true
+		}
+
+		// Wait until the bucket is non-empty, or until other
+		// transfers have been granted bandwidth.
+		wakeup := l.bandwidthWakeup
+		var timer clock.Timer
+		var timerChannel <-chan time.Time
+		if l.tokens < 0 {
+			timer, timerChannel = l.clock.NewTimer(time.Duration(math.Ceil(-l.tokens / l.bytesPerSecond * float64(time.Second))))
+		}
+		l.lock.Unlock()
+
+		select {
+		case <-timerChannel:
+		case <-wakeup:
+			if timer != nil {
+				timer.Stop()
+			}
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			l.lock.Lock()
+			l.stopWaitingForBandwidthLocked(priority)
+			l.lock.Unlock()
+			return util.StatusFromContext(ctx)
+		}
+		l.lock.Lock()
+	}
+}
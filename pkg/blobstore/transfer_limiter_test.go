@@ -0,0 +1,126 @@
+package blobstore_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTransferLimiterConcurrency(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(1000, 0))
+	limiter := blobstore.NewTransferLimiter(clock, 2, 0, 0)
+
+	// The first two transfers should be admitted immediately.
+	require.NoError(t, limiter.Acquire(ctx, blobstore.TransferPriorityLow))
+	require.NoError(t, limiter.Acquire(ctx, blobstore.TransferPriorityHigh))
+
+	// A third transfer should block. Canceling it should cause
+	// the call to fail.
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	testutil.RequireEqualStatus(
+		t,
+		status.Error(codes.Canceled, "context canceled"),
+		limiter.Acquire(canceledCtx, blobstore.TransferPriorityHigh))
+
+	// Releasing a slot should hand it over to a transfer that is
+	// waiting.
+	acquired := make(chan error, 1)
+	go func() {
+		acquired <- limiter.Acquire(ctx, blobstore.TransferPriorityLow)
+	}()
+	limiter.Release()
+	require.NoError(t, <-acquired)
+
+	limiter.Release()
+	limiter.Release()
+	require.NoError(t, limiter.Acquire(ctx, blobstore.TransferPriorityLow))
+	require.NoError(t, limiter.Acquire(ctx, blobstore.TransferPriorityLow))
+}
+
+func TestTransferLimiterBandwidth(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	var nowMilliseconds atomic.Int64
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().DoAndReturn(func() time.Time {
+		return time.UnixMilli(nowMilliseconds.Load())
+	}).AnyTimes()
+	limiter := blobstore.NewTransferLimiter(clock, 0, 100, 0)
+
+	// The bucket initially contains a second worth of tokens. As
+	// the bucket is non-empty, a large chunk may be transferred
+	// immediately, causing the bucket to become negative.
+	require.NoError(t, limiter.WaitForBandwidth(ctx, blobstore.TransferPriorityLow, 300))
+
+	// Subsequent chunks should wait for the bucket to be
+	// refilled. Let a low priority transfer start waiting before
+	// a high priority transfer.
+	timer1 := mock.NewMockTimer(ctrl)
+	timerChannel1 := make(chan time.Time, 1)
+	timerCreated1 := make(chan struct{})
+	clock.EXPECT().NewTimer(2 * time.Second).DoAndReturn(func(d time.Duration) (*mock.MockTimer, <-chan time.Time) {
+		close(timerCreated1)
+		return timer1, timerChannel1
+	})
+	lowAcquired := make(chan error, 1)
+	go func() {
+		lowAcquired <- limiter.WaitForBandwidth(ctx, blobstore.TransferPriorityLow, 10)
+	}()
+	<-timerCreated1
+
+	timer2 := mock.NewMockTimer(ctrl)
+	timerChannel2 := make(chan time.Time, 1)
+	timerCreated2 := make(chan struct{})
+	clock.EXPECT().NewTimer(2 * time.Second).DoAndReturn(func(d time.Duration) (*mock.MockTimer, <-chan time.Time) {
+		close(timerCreated2)
+		return timer2, timerChannel2
+	})
+	highAcquired := make(chan error, 1)
+	go func() {
+		highAcquired <- limiter.WaitForBandwidth(ctx, blobstore.TransferPriorityHigh, 10)
+	}()
+	<-timerCreated2
+
+	// Once the bucket is refilled, the low priority transfer
+	// should not be admitted, as a high priority transfer is
+	// waiting.
+	nowMilliseconds.Store(2000)
+	timerChannel1 <- time.UnixMilli(2000)
+
+	// Once the high priority transfer is admitted, the low
+	// priority transfer should wait for the bucket to be refilled
+	// once more.
+	timer3 := mock.NewMockTimer(ctrl)
+	timerChannel3 := make(chan time.Time, 1)
+	timerCreated3 := make(chan struct{})
+	clock.EXPECT().NewTimer(100 * time.Millisecond).DoAndReturn(func(d time.Duration) (*mock.MockTimer, <-chan time.Time) {
+		close(timerCreated3)
+		return timer3, timerChannel3
+	})
+	timerChannel2 <- time.UnixMilli(2000)
+	require.NoError(t, <-highAcquired)
+	<-timerCreated3
+	select {
+	case <-lowAcquired:
+		t.Fatal("Low priority transfer should not have been admitted yet")
+	default:
+	}
+
+	nowMilliseconds.Store(2100)
+	timerChannel3 <- time.UnixMilli(2100)
+	require.NoError(t, <-lowAcquired)
+}
@@ -0,0 +1,154 @@
+package blobstore
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/slicing"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+)
+
+// transferLimitingChunkSizeBytes is the maximum amount of data that is
+// read at once by transferLimitingReader. It bounds the amount of
+// tokens by which a single transfer may overdraw a token bucket.
+const transferLimitingChunkSizeBytes = 64 * 1024
+
+type transferLimitingBlobAccess struct {
+	base     blobstore.BlobAccess
+	limiters []*TransferLimiter
+}
+
+// NewTransferLimitingBlobAccess is a decorator for BlobAccess that
+// obtains permission from one or more TransferLimiters before
+// transferring blobs. Blobs are downloaded with a high priority, while
+// blobs are uploaded with a low priority. This prevents the uploading
+// of output files of one action from starving the fetching of input
+// files of other actions.
+//
+// Bandwidth is accounted for while the contents of blobs are being
+// transferred, as opposed to at the start of every transfer. Calls to
+// FindMissing() only count towards the limit on the number of
+// concurrent transfers, as they are used to determine which outputs
+// need to be uploaded.
+//
+// Limiters are acquired in the order in which they are provided. To
+// prevent deadlocks, limiters that are shared by multiple instances of
+// this decorator should be provided last.
+func NewTransferLimitingBlobAccess(base blobstore.BlobAccess, limiters []*TransferLimiter) blobstore.BlobAccess {
+	return &transferLimitingBlobAccess{
+		base:     base,
+		limiters: limiters,
+	}
+}
+
+func (ba *transferLimitingBlobAccess) acquire(ctx context.Context, priority TransferPriority) error {
+	for i, limiter := range ba.limiters {
+		if err := limiter.Acquire(ctx, priority); err != nil {
+			releaseTransferLimiters(ba.limiters[:i])
+			return err
+		}
+	}
+	return nil
+}
+
+func releaseTransferLimiters(limiters []*TransferLimiter) {
+	for _, limiter := range limiters {
+		limiter.Release()
+	}
+}
+
+// newLimitedBuffer wraps a buffer, so that bandwidth is deducted from
+// the TransferLimiters as its contents are read. If releaseOnClose is
+// set, the transfer slots are released once the buffer is consumed.
+func (ba *transferLimitingBlobAccess) newLimitedBuffer(ctx context.Context, blobDigest digest.Digest, b buffer.Buffer, priority TransferPriority, releaseOnClose bool, source buffer.Source) buffer.Buffer {
+	return buffer.NewCASBufferFromReader(
+		blobDigest,
+		&transferLimitingReader{
+			ReadCloser:     b.ToReader(),
+			ctx:            ctx,
+			limiters:       ba.limiters,
+			priority:       priority,
+			releaseOnClose: releaseOnClose,
+		},
+		source)
+}
+
+func (ba *transferLimitingBlobAccess) Get(ctx context.Context, digest digest.Digest) buffer.Buffer {
+	if err := ba.acquire(ctx, TransferPriorityHigh); err != nil {
+		return buffer.NewBufferFromError(err)
+	}
+	return ba.newLimitedBuffer(ctx, digest, ba.base.Get(ctx, digest), TransferPriorityHigh, true, buffer.BackendProvided(buffer.Irreparable(digest)))
+}
+
+func (ba *transferLimitingBlobAccess) GetFromComposite(ctx context.Context, parentDigest, childDigest digest.Digest, slicer slicing.BlobSlicer) buffer.Buffer {
+	if err := ba.acquire(ctx, TransferPriorityHigh); err != nil {
+		return buffer.NewBufferFromError(err)
+	}
+	return ba.newLimitedBuffer(ctx, childDigest, ba.base.GetFromComposite(ctx, parentDigest, childDigest, slicer), TransferPriorityHigh, true, buffer.BackendProvided(buffer.Irreparable(childDigest)))
+}
+
+func (ba *transferLimitingBlobAccess) Put(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+	if err := ba.acquire(ctx, TransferPriorityLow); err != nil {
+		b.Discard()
+		return err
+	}
+	defer releaseTransferLimiters(ba.limiters)
+
+	return ba.base.Put(ctx, digest, ba.newLimitedBuffer(ctx, digest, b, TransferPriorityLow, false, buffer.UserProvided))
+}
+
+func (ba *transferLimitingBlobAccess) FindMissing(ctx context.Context, digests digest.Set) (digest.Set, error) {
+	if err := ba.acquire(ctx, TransferPriorityLow); err != nil {
+		return digest.EmptySet, err
+	}
+	defer releaseTransferLimiters(ba.limiters)
+
+	return ba.base.FindMissing(ctx, digests)
+}
+
+func (ba *transferLimitingBlobAccess) GetCapabilities(ctx context.Context, instanceName digest.InstanceName) (*remoteexecution.ServerCapabilities, error) {
+	return ba.base.GetCapabilities(ctx, instanceName)
+}
+
+// transferLimitingReader deducts bandwidth from TransferLimiters as
+// data is read. Data is read in small chunks, so that a large transfer
+// having a low priority cannot overdraw the token bucket by the full
+// size of the blob.
+type transferLimitingReader struct {
+	io.ReadCloser
+	ctx            context.Context
+	limiters       []*TransferLimiter
+	priority       TransferPriority
+	releaseOnClose bool
+
+	closeOnce sync.Once
+}
+
+func (r *transferLimitingReader) Read(p []byte) (int, error) {
+	if len(p) > transferLimitingChunkSizeBytes {
+		p = p[:transferLimitingChunkSizeBytes]
+	}
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		for _, limiter := range r.limiters {
+			if errWait := limiter.WaitForBandwidth(r.ctx, r.priority, int64(n)); errWait != nil {
+				return n, errWait
+			}
+		}
+	}
+	return n, err
+}
+
+func (r *transferLimitingReader) Close() error {
+	err := r.ReadCloser.Close()
+	if r.releaseOnClose {
+		r.closeOnce.Do(func() {
+			releaseTransferLimiters(r.limiters)
+		})
+	}
+	return err
+}
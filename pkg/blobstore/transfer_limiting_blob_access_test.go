@@ -0,0 +1,120 @@
+package blobstore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTransferLimitingBlobAccess(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(1000, 0))
+	baseBlobAccess := mock.NewMockBlobAccess(ctrl)
+	blobAccess := blobstore.NewTransferLimitingBlobAccess(
+		baseBlobAccess,
+		[]*blobstore.TransferLimiter{
+			blobstore.NewTransferLimiter(clock, 1, 0, 0),
+		})
+
+	exampleDigest := digest.MustNewDigest("hello", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	// While a blob is being downloaded, no other transfers may
+	// start, as only a single transfer may run concurrently.
+	baseBlobAccess.EXPECT().Get(ctx, exampleDigest).
+		Return(buffer.NewValidatedBufferFromByteSlice([]byte("Hello")))
+	b := blobAccess.Get(ctx, exampleDigest)
+
+	testutil.RequireEqualStatus(
+		t,
+		status.Error(codes.Canceled, "context canceled"),
+		blobAccess.Put(canceledCtx, exampleDigest, buffer.NewValidatedBufferFromByteSlice([]byte("Hello"))))
+
+	// Once the downloaded blob has been consumed, uploads may
+	// start.
+	data, err := b.ToByteSlice(1000)
+	require.NoError(t, err)
+	require.Equal(t, []byte("Hello"), data)
+
+	baseBlobAccess.EXPECT().Put(ctx, exampleDigest, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+			b.Discard()
+			return nil
+		})
+	require.NoError(t, blobAccess.Put(ctx, exampleDigest, buffer.NewValidatedBufferFromByteSlice([]byte("Hello"))))
+
+	// Downloads should fail if the context is canceled while
+	// waiting for a transfer slot.
+	baseBlobAccess.EXPECT().Get(ctx, exampleDigest).
+		Return(buffer.NewValidatedBufferFromByteSlice([]byte("Hello")))
+	b = blobAccess.Get(ctx, exampleDigest)
+	_, err = blobAccess.Get(canceledCtx, exampleDigest).ToByteSlice(1000)
+	testutil.RequireEqualStatus(t, status.Error(codes.Canceled, "context canceled"), err)
+
+	// Calls to FindMissing() should also count towards the number
+	// of concurrent transfers.
+	_, err = blobAccess.FindMissing(canceledCtx, exampleDigest.ToSingletonSet())
+	testutil.RequireEqualStatus(t, status.Error(codes.Canceled, "context canceled"), err)
+	b.Discard()
+
+	baseBlobAccess.EXPECT().FindMissing(ctx, exampleDigest.ToSingletonSet()).Return(digest.EmptySet, nil)
+	missing, err := blobAccess.FindMissing(ctx, exampleDigest.ToSingletonSet())
+	require.NoError(t, err)
+	require.Equal(t, digest.EmptySet, missing)
+}
+
+func TestTransferLimitingBlobAccessBandwidth(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(1000, 0)).AnyTimes()
+	baseBlobAccess := mock.NewMockBlobAccess(ctrl)
+	blobAccess := blobstore.NewTransferLimitingBlobAccess(
+		baseBlobAccess,
+		[]*blobstore.TransferLimiter{
+			blobstore.NewTransferLimiter(clock, 0, 1<<20, 0),
+		})
+
+	// Upload a blob that is a lot larger than the size of the
+	// token bucket. As bandwidth is deducted as data is read, a
+	// download that starts while the upload is in progress should
+	// not need to wait for the upload's full size worth of tokens.
+	largeData := make([]byte, 16<<20)
+	generator := digest.MustNewFunction("hello", remoteexecution.DigestFunction_MD5).NewGenerator(int64(len(largeData)))
+	generator.Write(largeData)
+	largeDigest := generator.Sum()
+	exampleDigest := digest.MustNewDigest("hello", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+
+	baseBlobAccess.EXPECT().Put(ctx, largeDigest, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+			r := b.ToReader()
+			defer r.Close()
+			var chunk [1 << 20]byte
+			n, err := r.Read(chunk[:])
+			require.NoError(t, err)
+			require.Less(t, n, 1<<20)
+
+			baseBlobAccess.EXPECT().Get(ctx, exampleDigest).
+				Return(buffer.NewValidatedBufferFromByteSlice([]byte("Hello")))
+			data, err := blobAccess.Get(ctx, exampleDigest).ToByteSlice(1000)
+			require.NoError(t, err)
+			require.Equal(t, []byte("Hello"), data)
+			return nil
+		})
+	require.NoError(t, blobAccess.Put(ctx, largeDigest, buffer.NewValidatedBufferFromByteSlice(largeData)))
+}
@@ -308,8 +308,17 @@ func (bc *BuildClient) Run(ctx context.Context) (bool, error) {
 // LaunchWorkerThread launches a single routine that uses a build client
 // to repeatedly synchronizes against the scheduler, requesting a task
 // to execute.
-func LaunchWorkerThread(group program.Group, buildClient *BuildClient, workerName string) {
+//
+// If a WorkerDrainer is provided, the routine terminates gracefully
+// once draining starts, as if termination of the program was requested.
+func LaunchWorkerThread(group program.Group, buildClient *BuildClient, workerName string, drainer *WorkerDrainer) {
 	group.Go(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+		if drainer != nil {
+			var leaveThread func()
+			ctx, leaveThread = drainer.enterThread(ctx)
+			defer leaveThread()
+		}
+
 		generator := random.NewFastSingleThreadedGenerator()
 		for {
 			terminationStartedBeforeRun := ctx.Err() != nil
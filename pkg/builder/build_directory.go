@@ -23,6 +23,7 @@ type BuildDirectory interface {
 
 	// Methods inherited from filesystem.Directory.
 	Mknod(name path.Component, perm os.FileMode, deviceNumber filesystem.DeviceNumber) error
+	OpenRead(name path.Component) (filesystem.FileReader, error)
 	Remove(name path.Component) error
 	RemoveAll(name path.Component) error
 
@@ -36,12 +37,31 @@ type BuildDirectory interface {
 	// errors. Implementations of BuildDirectory are free to let
 	// this be a no-op, with the disadvantage that they cannot apply
 	// resource limits or provide rich I/O error messages.
-	InstallHooks(filePool re_filesystem.FilePool, errorLogger util.ErrorLogger)
+	//
+	// The context and digest function may be used to upload output
+	// files into the Content Addressable Storage while the build
+	// action is still running.
+	InstallHooks(ctx context.Context, filePool re_filesystem.FilePool, errorLogger util.ErrorLogger, digestFunction digest.Function)
+
+	// Starts uploading files stored at the provided output paths
+	// into the Content Addressable Storage as soon as they are
+	// closed by the build action, until the returned function is
+	// called. Paths are relative to this directory. This may only
+	// be called after InstallHooks(). Implementations of
+	// BuildDirectory are free to let this be a no-op, in which case
+	// all output files are uploaded after the build action
+	// completes.
+	UploadOutputsInBackground(ctx context.Context, outputPaths [][]path.Component) (stop func())
 
 	// Recursively merges the contents of a Directory stored in the
 	// Content Addressable Storage into a local directory. If this
 	// process is synchronous, this function can return a
 	// synchronous error. If this process is lazy/asynchronous, the
 	// provided ErrorLogger may be used to return an error.
-	MergeDirectoryContents(ctx context.Context, errorLogger util.ErrorLogger, digest digest.Digest, monitor access.UnreadDirectoryMonitor) error
+	//
+	// The depth is the number of directories between the input
+	// root and this directory. Implementations may use it to
+	// rewrite targets of symbolic links contained in the Directory
+	// relative to the input root.
+	MergeDirectoryContents(ctx context.Context, errorLogger util.ErrorLogger, digest digest.Digest, monitor access.UnreadDirectoryMonitor, depth int) error
 }
@@ -12,3 +12,21 @@ import (
 type BuildDirectoryCreator interface {
 	GetBuildDirectory(ctx context.Context, actionDigestIfNotRunInParallel *digest.Digest) (BuildDirectory, *path.Trace, error)
 }
+
+type inputRootDigestKey struct{}
+
+// NewContextWithInputRootDigest attaches the digest of the input root
+// of an action to a Context. LocalBuildExecutor calls this before
+// obtaining a build directory. Implementations of BuildDirectoryCreator
+// may use it to select the build directory based on the contents of
+// the input root.
+func NewContextWithInputRootDigest(ctx context.Context, inputRootDigest digest.Digest) context.Context {
+	return context.WithValue(ctx, inputRootDigestKey{}, inputRootDigest)
+}
+
+// getInputRootDigest returns the digest of the input root that was
+// attached to a Context using NewContextWithInputRootDigest().
+func getInputRootDigest(ctx context.Context) (digest.Digest, bool) {
+	inputRootDigest, ok := ctx.Value(inputRootDigestKey{}).(digest.Digest)
+	return inputRootDigest, ok
+}
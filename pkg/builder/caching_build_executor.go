@@ -3,6 +3,7 @@ package builder
 import (
 	"context"
 	"net/url"
+	"sync"
 
 	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
 	"github.com/buildbarn/bb-remote-execution/pkg/filesystem"
@@ -14,16 +15,60 @@ import (
 	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
 	"github.com/buildbarn/bb-storage/pkg/digest"
 	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
+var (
+	cachingBuildExecutorPrometheusMetrics sync.Once
+
+	cachingBuildExecutorCacheFlagsOverridden = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "buildbarn",
+		Subsystem: "builder",
+		Name:      "caching_build_executor_cache_flags_overridden_total",
+		Help:      "Number of actions for which the value of a flag controlling caching was changed, due to a per-instance name policy.",
+	},
+		[]string{"flag"},
+	)
+	cachingBuildExecutorCacheFlagsOverriddenDoNotCache = cachingBuildExecutorCacheFlagsOverridden.WithLabelValues("DoNotCache")
+)
+
+// CacheFlagPolicy determines how CachingBuildExecutor interprets a flag
+// provided by the client that controls caching behavior, such as
+// Action.do_not_cache.
+type CacheFlagPolicy int
+
+const (
+	// CacheFlagPolicyRespect causes the flag to be used as provided
+	// by the client.
+	CacheFlagPolicyRespect CacheFlagPolicy = iota
+	// CacheFlagPolicyIgnore causes the flag to be treated as if it
+	// were not set by the client.
+	CacheFlagPolicyIgnore
+)
+
+func (p CacheFlagPolicy) apply(value bool) bool {
+	return value && p != CacheFlagPolicyIgnore
+}
+
+// CacheFlagOverride describes how CachingBuildExecutor interprets the
+// flags controlling caching behavior for all actions whose instance
+// name starts with a given prefix.
+type CacheFlagOverride struct {
+	InstanceNamePrefix digest.InstanceName
+	DoNotCache         CacheFlagPolicy
+}
+
 type cachingBuildExecutor struct {
 	BuildExecutor
 	contentAddressableStorage blobstore.BlobAccess
 	actionCache               blobstore.BlobAccess
 	browserURL                *url.URL
+	cacheFlagOverridesTrie    *digest.InstanceNameTrie
+	cacheFlagOverrides        []CacheFlagOverride
 }
 
 // NewCachingBuildExecutor creates an adapter for BuildExecutor that
@@ -33,16 +78,62 @@ type cachingBuildExecutor struct {
 //
 // In both cases, a link to bb_browser is added to the ExecuteResponse,
 // so that the user may inspect the Action and ActionResult in detail.
-func NewCachingBuildExecutor(base BuildExecutor, contentAddressableStorage, actionCache blobstore.BlobAccess, browserURL *url.URL) BuildExecutor {
+//
+// The do_not_cache flag provided by the client may be overridden on a
+// per-instance name basis. This can, for example, be used to ensure
+// that builds performed by CI always populate the Action Cache. The
+// effective value of this flag is propagated to the underlying
+// BuildExecutor. Overriding skip_cache_lookup is performed by the
+// scheduler instead, as it also affects in-flight deduplication.
+func NewCachingBuildExecutor(base BuildExecutor, contentAddressableStorage, actionCache blobstore.BlobAccess, browserURL *url.URL, cacheFlagOverrides []CacheFlagOverride) BuildExecutor {
+	cachingBuildExecutorPrometheusMetrics.Do(func() {
+		prometheus.MustRegister(cachingBuildExecutorCacheFlagsOverridden)
+	})
+
+	cacheFlagOverridesTrie := digest.NewInstanceNameTrie()
+	for i, cacheFlagOverride := range cacheFlagOverrides {
+		cacheFlagOverridesTrie.Set(cacheFlagOverride.InstanceNamePrefix, i)
+	}
 	return &cachingBuildExecutor{
 		BuildExecutor:             base,
 		contentAddressableStorage: contentAddressableStorage,
 		actionCache:               actionCache,
 		browserURL:                browserURL,
+		cacheFlagOverridesTrie:    cacheFlagOverridesTrie,
+		cacheFlagOverrides:        cacheFlagOverrides,
 	}
 }
 
+// applyCacheFlagOverrides returns a copy of the request in which the
+// flags controlling caching behavior are adjusted according to the
+// policy for the action's instance name.
+func (be *cachingBuildExecutor) applyCacheFlagOverrides(digestFunction digest.Function, request *remoteworker.DesiredState_Executing) *remoteworker.DesiredState_Executing {
+	idx := be.cacheFlagOverridesTrie.GetLongestPrefix(digestFunction.GetInstanceName())
+	if idx < 0 || request.Action == nil {
+		return request
+	}
+	cacheFlagOverride := &be.cacheFlagOverrides[idx]
+	doNotCache := cacheFlagOverride.DoNotCache.apply(request.Action.DoNotCache)
+	if doNotCache == request.Action.DoNotCache {
+		return request
+	}
+
+	newRequest := proto.Clone(request).(*remoteworker.DesiredState_Executing)
+	newRequest.Action.DoNotCache = doNotCache
+	cachingBuildExecutorCacheFlagsOverriddenDoNotCache.Inc()
+	return newRequest
+}
+
 func (be *cachingBuildExecutor) Execute(ctx context.Context, filePool filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+	request = be.applyCacheFlagOverrides(digestFunction, request)
+	if request.ProhibitActionCacheWrites && request.Action != nil && !request.Action.DoNotCache {
+		// The scheduler prohibits this worker from writing into
+		// the Action Cache. This takes precedence over any
+		// cache flag overrides.
+		newRequest := proto.Clone(request).(*remoteworker.DesiredState_Executing)
+		newRequest.Action.DoNotCache = true
+		request = newRequest
+	}
 	response := be.BuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates)
 	if actionDigest, err := digestFunction.NewDigestFromProto(request.ActionDigest); err != nil {
 		attachErrorToExecuteResponse(response, util.StatusWrap(err, "Failed to extract digest for action"))
@@ -8,6 +8,8 @@ import (
 	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
 	"github.com/buildbarn/bb-remote-execution/internal/mock"
 	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
 	cas_proto "github.com/buildbarn/bb-remote-execution/pkg/proto/cas"
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
 	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
@@ -62,7 +64,7 @@ func TestCachingBuildExecutorCachedSuccess(t *testing.T) {
 		Scheme: "https",
 		Host:   "example.com",
 		Path:   "/some/sub/directory",
-	})
+	}, nil)
 
 	executeResponse := cachingBuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, metadata)
 	testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
@@ -115,7 +117,7 @@ func TestCachingBuildExecutorCachedSuccessExplicitOK(t *testing.T) {
 		Scheme: "https",
 		Host:   "example.com",
 		Path:   "/some/sub/directory",
-	})
+	}, nil)
 
 	executeResponse := cachingBuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, metadata)
 	testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
@@ -183,7 +185,7 @@ func TestCachingBuildExecutorCachedSuccessNonZeroExitCode(t *testing.T) {
 		Scheme: "https",
 		Host:   "example.com",
 		Path:   "/some/sub/directory",
-	})
+	}, nil)
 
 	executeResponse := cachingBuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, metadata)
 	testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
@@ -236,7 +238,7 @@ func TestCachingBuildExecutorCachedStorageFailure(t *testing.T) {
 		Scheme: "https",
 		Host:   "example.com",
 		Path:   "/",
-	})
+	}, nil)
 
 	executeResponse := cachingBuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, metadata)
 	testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
@@ -296,7 +298,7 @@ func TestCachingBuildExecutorUncachedDoNotCache(t *testing.T) {
 		Scheme: "http",
 		Host:   "example.com",
 		Path:   "/some/sub/directory/",
-	})
+	}, nil)
 
 	executeResponse := cachingBuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, metadata)
 	testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
@@ -358,7 +360,7 @@ func TestCachingBuildExecutorUncachedError(t *testing.T) {
 		Scheme: "http",
 		Host:   "example.com",
 		Path:   "/some/sub/directory/",
-	})
+	}, nil)
 
 	executeResponse := cachingBuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, metadata)
 	testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
@@ -421,7 +423,7 @@ func TestCachingBuildExecutorUncachedStorageFailure(t *testing.T) {
 		Scheme: "http",
 		Host:   "example.com",
 		Path:   "/some/sub/directory/",
-	})
+	}, nil)
 
 	executeResponse := cachingBuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, metadata)
 	testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
@@ -431,3 +433,119 @@ func TestCachingBuildExecutorUncachedStorageFailure(t *testing.T) {
 		Status: status.New(codes.DeadlineExceeded, "Build took more than ten seconds").Proto(),
 	}, executeResponse)
 }
+
+// Per-instance name policies may cause the flags provided by the client
+// to be overridden. The effective values should be propagated to the
+// underlying BuildExecutor.
+func TestCachingBuildExecutorCacheFlagOverrides(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+	baseBuildExecutor := mock.NewMockBuildExecutor(ctrl)
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	actionCache := mock.NewMockBlobAccess(ctrl)
+	cachingBuildExecutor := builder.NewCachingBuildExecutor(baseBuildExecutor, contentAddressableStorage, actionCache, &url.URL{
+		Scheme: "https",
+		Host:   "example.com",
+		Path:   "/some/sub/directory",
+	}, []builder.CacheFlagOverride{
+		{
+			InstanceNamePrefix: digest.MustNewInstanceName("ci"),
+			DoNotCache:         builder.CacheFlagPolicyIgnore,
+		},
+		{
+			InstanceNamePrefix: digest.MustNewInstanceName("ci/trusted"),
+		},
+	})
+	filePool := mock.NewMockFilePool(ctrl)
+	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
+	var metadata chan<- *remoteworker.CurrentState_Executing = make(chan *remoteworker.CurrentState_Executing, 10)
+	actionDigest := &remoteexecution.Digest{
+		Hash:      "64ec88ca00b268e5ba1a35678a1b5316d212f4f366b2477232534a8aeca37f3c",
+		SizeBytes: 11,
+	}
+
+	t.Run("Overridden", func(t *testing.T) {
+		// Results should be stored in the Action Cache, even
+		// though the client requested otherwise.
+		request := &remoteworker.DesiredState_Executing{
+			ActionDigest: actionDigest,
+			Action:       &remoteexecution.Action{DoNotCache: true},
+		}
+		digestFunction := digest.MustNewFunction("ci/main", remoteexecution.DigestFunction_SHA256)
+		baseBuildExecutor.EXPECT().Execute(ctx, filePool, monitor, digestFunction, gomock.Any(), metadata).DoAndReturn(
+			func(ctx context.Context, filePool filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+				testutil.RequireEqualProto(t, &remoteworker.DesiredState_Executing{
+					ActionDigest: actionDigest,
+					Action:       &remoteexecution.Action{},
+				}, request)
+				return &remoteexecution.ExecuteResponse{
+					Result: &remoteexecution.ActionResult{
+						StdoutRaw: []byte("Hello, world!"),
+					},
+				}
+			})
+		actionCache.EXPECT().Put(
+			ctx,
+			digest.MustNewDigest("ci/main", remoteexecution.DigestFunction_SHA256, "64ec88ca00b268e5ba1a35678a1b5316d212f4f366b2477232534a8aeca37f3c", 11),
+			gomock.Any())
+
+		executeResponse := cachingBuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, metadata)
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{
+				StdoutRaw: []byte("Hello, world!"),
+			},
+			Message: "Action details (cached result): https://example.com/some/sub/directory/ci/main/blobs/sha256/action/64ec88ca00b268e5ba1a35678a1b5316d212f4f366b2477232534a8aeca37f3c-11/",
+		}, executeResponse)
+
+		// The original request should be left untouched.
+		require.True(t, request.Action.DoNotCache)
+	})
+
+	t.Run("LongestPrefixRespected", func(t *testing.T) {
+		// The policy with the longest matching instance name
+		// prefix should be used, causing the flags to be
+		// respected.
+		request := &remoteworker.DesiredState_Executing{
+			ActionDigest: actionDigest,
+			Action:       &remoteexecution.Action{DoNotCache: true},
+		}
+		digestFunction := digest.MustNewFunction("ci/trusted", remoteexecution.DigestFunction_SHA256)
+		baseBuildExecutor.EXPECT().Execute(ctx, filePool, monitor, digestFunction, request, metadata).Return(&remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{
+				StdoutRaw: []byte("Hello, world!"),
+			},
+		})
+		contentAddressableStorage.EXPECT().Put(ctx, gomock.Any(), gomock.Any())
+
+		executeResponse := cachingBuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, metadata)
+		require.Contains(t, executeResponse.Message, "Action details (uncached result): ")
+	})
+
+	t.Run("ProhibitActionCacheWrites", func(t *testing.T) {
+		// If the scheduler prohibits the worker from writing
+		// into the Action Cache, results should only be stored
+		// in the Content Addressable Storage. Cache flag
+		// overrides should not be able to undo this.
+		request := &remoteworker.DesiredState_Executing{
+			ActionDigest:              actionDigest,
+			Action:                    &remoteexecution.Action{},
+			ProhibitActionCacheWrites: true,
+		}
+		digestFunction := digest.MustNewFunction("ci/main", remoteexecution.DigestFunction_SHA256)
+		baseBuildExecutor.EXPECT().Execute(ctx, filePool, monitor, digestFunction, testutil.EqProto(t, &remoteworker.DesiredState_Executing{
+			ActionDigest:              actionDigest,
+			Action:                    &remoteexecution.Action{DoNotCache: true},
+			ProhibitActionCacheWrites: true,
+		}), metadata).Return(&remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{
+				StdoutRaw: []byte("Hello, world!"),
+			},
+		})
+		contentAddressableStorage.EXPECT().Put(ctx, gomock.Any(), gomock.Any())
+
+		executeResponse := cachingBuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, metadata)
+		require.Contains(t, executeResponse.Message, "Action details (uncached result): ")
+
+		// The original request should be left untouched.
+		require.False(t, request.Action.DoNotCache)
+	})
+}
@@ -0,0 +1,46 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type cgroupCPUUsageReader struct {
+	cpuStatPath string
+}
+
+// NewCgroupCPUUsageReader creates a CPUUsageReader that reports the
+// CPU time consumed by all processes in a cgroup (cgroups v2),
+// including processes that have already terminated. It reads the
+// "usage_usec" field of the cgroup's "cpu.stat" file, which is
+// available regardless of whether the cpu controller is enabled.
+func NewCgroupCPUUsageReader(cgroupPath string) CPUUsageReader {
+	return &cgroupCPUUsageReader{
+		cpuStatPath: filepath.Join(cgroupPath, "cpu.stat"),
+	}
+}
+
+func (r *cgroupCPUUsageReader) GetCPUUsage() (time.Duration, error) {
+	data, err := os.ReadFile(r.cpuStatPath)
+	if err != nil {
+		return 0, util.StatusWrapfWithCode(err, codes.Internal, "Failed to read %#v", r.cpuStatPath)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, "usage_usec "); ok {
+			usageMicroseconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return 0, util.StatusWrapfWithCode(err, codes.Internal, "Invalid CPU usage %#v in %#v", value, r.cpuStatPath)
+			}
+			return time.Duration(usageMicroseconds) * time.Microsecond, nil
+		}
+	}
+	return 0, status.Errorf(codes.Internal, "File %#v does not report CPU usage", r.cpuStatPath)
+}
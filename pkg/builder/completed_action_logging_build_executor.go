@@ -19,18 +19,21 @@ type completedActionLoggingBuildExecutor struct {
 	uuidGenerator       util.UUIDGenerator
 	logger              CompletedActionLogger
 	instanceNamePatcher digest.InstanceNamePatcher
+	workerAnnotations   map[string]string
 }
 
 // NewCompletedActionLoggingBuildExecutor returns a new
 // completedActionLoggingBuildExecutor that will transmit CompletedActions
 // to an external server for real-time analysis of REv2 Action metadata
-// using a CompletedActionLogger.
-func NewCompletedActionLoggingBuildExecutor(base BuildExecutor, uuidGenerator util.UUIDGenerator, logger CompletedActionLogger, instanceNamePatcher digest.InstanceNamePatcher) BuildExecutor {
+// using a CompletedActionLogger. All CompletedActions are annotated
+// with a fixed set of key-value pairs describing the worker.
+func NewCompletedActionLoggingBuildExecutor(base BuildExecutor, uuidGenerator util.UUIDGenerator, logger CompletedActionLogger, instanceNamePatcher digest.InstanceNamePatcher, workerAnnotations map[string]string) BuildExecutor {
 	return &completedActionLoggingBuildExecutor{
 		BuildExecutor:       base,
 		uuidGenerator:       uuidGenerator,
 		logger:              logger,
 		instanceNamePatcher: instanceNamePatcher,
+		workerAnnotations:   workerAnnotations,
 	}
 }
 
@@ -42,9 +45,10 @@ func (be *completedActionLoggingBuildExecutor) Execute(ctx context.Context, file
 			ActionDigest:    request.ActionDigest,
 			ExecuteResponse: response,
 		},
-		Uuid:           uuid.Must(be.uuidGenerator()).String(),
-		InstanceName:   be.instanceNamePatcher.PatchInstanceName(digestFunction.GetInstanceName()).String(),
-		DigestFunction: digestFunction.GetEnumValue(),
+		Uuid:              uuid.Must(be.uuidGenerator()).String(),
+		InstanceName:      be.instanceNamePatcher.PatchInstanceName(digestFunction.GetInstanceName()).String(),
+		DigestFunction:    digestFunction.GetEnumValue(),
+		WorkerAnnotations: be.workerAnnotations,
 	}
 
 	be.logger.LogCompletedAction(completedAction)
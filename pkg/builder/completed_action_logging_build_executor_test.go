@@ -54,7 +54,8 @@ func TestActionLoggingBuildExecutor(t *testing.T) {
 		baseBuildExecutor,
 		uuidGenerator.Call,
 		lq,
-		digest.NewInstanceNamePatcher(digest.EmptyInstanceName, digest.MustNewInstanceName("prefix")))
+		digest.NewInstanceNamePatcher(digest.EmptyInstanceName, digest.MustNewInstanceName("prefix")),
+		map[string]string{"kubernetes_node_name": "node-1"})
 
 	uuidGenerator.EXPECT().Call().Return(uuid.Parse("36ebab65-3c4f-4faf-818b-2eabb4cd1b02"))
 	lq.EXPECT().LogCompletedAction(&cal_proto.CompletedAction{
@@ -68,6 +69,9 @@ func TestActionLoggingBuildExecutor(t *testing.T) {
 		Uuid:           "36ebab65-3c4f-4faf-818b-2eabb4cd1b02",
 		InstanceName:   "prefix/freebsd12",
 		DigestFunction: remoteexecution.DigestFunction_SHA256,
+		WorkerAnnotations: map[string]string{
+			"kubernetes_node_name": "node-1",
+		},
 	})
 	resp := completedActionLoggingBuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, metadata)
 
@@ -0,0 +1,48 @@
+package builder
+
+import (
+	"context"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"golang.org/x/sync/semaphore"
+)
+
+type concurrencyLimitingBuildExecutor struct {
+	BuildExecutor
+	concurrencyLimit *semaphore.Weighted
+}
+
+// NewConcurrencyLimitingBuildExecutor creates a decorator for
+// BuildExecutor that acquires an execution slot from a semaphore
+// before executing an action. This can be used to let worker threads
+// that are registered in different platform queues share a fixed
+// number of execution slots.
+//
+// Slots are only acquired after an action has been assigned to the
+// worker thread. This means that idle worker threads continue to
+// synchronize against the scheduler, and are thus not removed from
+// the scheduler's platform queue. While waiting for a slot, the worker
+// thread continues to report to the scheduler that it is executing
+// the action.
+func NewConcurrencyLimitingBuildExecutor(base BuildExecutor, concurrencyLimit *semaphore.Weighted) BuildExecutor {
+	return &concurrencyLimitingBuildExecutor{
+		BuildExecutor:    base,
+		concurrencyLimit: concurrencyLimit,
+	}
+}
+
+func (be *concurrencyLimitingBuildExecutor) Execute(ctx context.Context, filePool filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+	if be.concurrencyLimit.Acquire(ctx, 1) != nil {
+		response := NewDefaultExecuteResponse(request)
+		attachErrorToExecuteResponse(response, util.StatusWrap(util.StatusFromContext(ctx), "Failed to acquire execution slot"))
+		return response
+	}
+	defer be.concurrencyLimit.Release(1)
+	return be.BuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates)
+}
@@ -0,0 +1,107 @@
+package builder_test
+
+import (
+	"context"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+
+	"golang.org/x/sync/semaphore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConcurrencyLimitingBuildExecutor(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	// Two worker threads, registered in different platform
+	// queues, that share a single execution slot.
+	concurrencyLimit := semaphore.NewWeighted(1)
+	baseBuildExecutor1 := mock.NewMockBuildExecutor(ctrl)
+	buildExecutor1 := builder.NewConcurrencyLimitingBuildExecutor(baseBuildExecutor1, concurrencyLimit)
+	baseBuildExecutor2 := mock.NewMockBuildExecutor(ctrl)
+	buildExecutor2 := builder.NewConcurrencyLimitingBuildExecutor(baseBuildExecutor2, concurrencyLimit)
+
+	filePool := mock.NewMockFilePool(ctrl)
+	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
+	digestFunction := digest.MustNewFunction("hello", remoteexecution.DigestFunction_SHA256)
+	var executionStateUpdates chan<- *remoteworker.CurrentState_Executing = make(chan *remoteworker.CurrentState_Executing)
+	request := &remoteworker.DesiredState_Executing{
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "64ec88ca00b268e5ba1a35678a1b5316d212f4f366b2477232534a8aeca37f3c",
+			SizeBytes: 11,
+		},
+	}
+
+	t.Run("Sequential", func(t *testing.T) {
+		// While the first worker thread is executing an
+		// action, the second worker thread should wait for the
+		// execution slot to be released.
+		execution1Started := make(chan struct{})
+		execution1Unblock := make(chan struct{})
+		baseBuildExecutor1.EXPECT().Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates).DoAndReturn(
+			func(ctx context.Context, filePool filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+				close(execution1Started)
+				<-execution1Unblock
+				return &remoteexecution.ExecuteResponse{
+					Result: &remoteexecution.ActionResult{ExitCode: 1},
+				}
+			})
+		execution1Done := make(chan struct{})
+		go func() {
+			testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+				Result: &remoteexecution.ActionResult{ExitCode: 1},
+			}, buildExecutor1.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates))
+			close(execution1Done)
+		}()
+		<-execution1Started
+
+		execution2Done := make(chan struct{})
+		go func() {
+			testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+				Result: &remoteexecution.ActionResult{ExitCode: 2},
+			}, buildExecutor2.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates))
+			close(execution2Done)
+		}()
+
+		// Only permit the second action to run once the first
+		// has completed.
+		baseBuildExecutor2.EXPECT().Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates).DoAndReturn(
+			func(ctx context.Context, filePool filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+				select {
+				case <-execution1Done:
+				default:
+					t.Error("Second action started executing before the first one completed")
+				}
+				return &remoteexecution.ExecuteResponse{
+					Result: &remoteexecution.ActionResult{ExitCode: 2},
+				}
+			})
+		close(execution1Unblock)
+		<-execution2Done
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		// If the action is cancelled while waiting for an
+		// execution slot, it should fail without being executed.
+		concurrencyLimit.Acquire(ctx, 1)
+		defer concurrencyLimit.Release(1)
+
+		cancelledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{
+				ExecutionMetadata: &remoteexecution.ExecutedActionMetadata{},
+			},
+			Status: status.New(codes.Canceled, "Failed to acquire execution slot: context canceled").Proto(),
+		}, buildExecutor2.Execute(cancelledCtx, filePool, monitor, digestFunction, request, executionStateUpdates))
+	})
+}
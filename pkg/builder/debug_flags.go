@@ -0,0 +1,95 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/workerdebug"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// DebugFlags holds flags that control the amount of diagnostic
+// information that is emitted by worker threads. The flags can be
+// adjusted at runtime through the WorkerDebug gRPC service, which is
+// implemented by this type.
+type DebugFlags struct {
+	lock                  sync.RWMutex
+	flags                 *workerdebug.DebugFlags
+	tracedActions         map[string]struct{}
+	fileSystemDumpActions map[string]struct{}
+}
+
+var _ workerdebug.WorkerDebugServer = (*DebugFlags)(nil)
+
+// NewDebugFlags creates a DebugFlags object that has all flags
+// disabled.
+func NewDebugFlags() *DebugFlags {
+	return &DebugFlags{
+		flags: &workerdebug.DebugFlags{},
+	}
+}
+
+// getActionDigestKey converts an action digest to a string that can be
+// used as a map key. Digests are matched regardless of the instance
+// name and digest function of the action.
+func getActionDigestKey(actionDigest *remoteexecution.Digest) string {
+	return fmt.Sprintf("%s-%d", actionDigest.GetHash(), actionDigest.GetSizeBytes())
+}
+
+func newActionDigestKeySet(field string, actionDigests []*remoteexecution.Digest) (map[string]struct{}, error) {
+	keys := make(map[string]struct{}, len(actionDigests))
+	for i, actionDigest := range actionDigests {
+		if actionDigest.GetHash() == "" || actionDigest.GetSizeBytes() < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid action digest at index %d of %s", i, field)
+		}
+		keys[getActionDigestKey(actionDigest)] = struct{}{}
+	}
+	return keys, nil
+}
+
+// GetDebugFlags returns the debug flags that are currently in effect.
+func (df *DebugFlags) GetDebugFlags(ctx context.Context, request *emptypb.Empty) (*workerdebug.DebugFlags, error) {
+	df.lock.RLock()
+	defer df.lock.RUnlock()
+
+	return proto.Clone(df.flags).(*workerdebug.DebugFlags), nil
+}
+
+// SetDebugFlags replaces the debug flags that are currently in effect.
+func (df *DebugFlags) SetDebugFlags(ctx context.Context, request *workerdebug.DebugFlags) (*emptypb.Empty, error) {
+	tracedActions, err := newActionDigestKeySet("traced_action_digests", request.TracedActionDigests)
+	if err != nil {
+		return nil, err
+	}
+	fileSystemDumpActions, err := newActionDigestKeySet("file_system_dump_action_digests", request.FileSystemDumpActionDigests)
+	if err != nil {
+		return nil, err
+	}
+
+	df.lock.Lock()
+	df.flags = proto.Clone(request).(*workerdebug.DebugFlags)
+	df.tracedActions = tracedActions
+	df.fileSystemDumpActions = fileSystemDumpActions
+	df.lock.Unlock()
+	return &emptypb.Empty{}, nil
+}
+
+// getActionFlags returns whether execution stage transitions and file
+// system operations should be logged for an action.
+func (df *DebugFlags) getActionFlags(actionDigest *remoteexecution.Digest) (logStages, dumpFileSystem bool) {
+	key := getActionDigestKey(actionDigest)
+
+	df.lock.RLock()
+	defer df.lock.RUnlock()
+
+	_, isTraced := df.tracedActions[key]
+	_, isDumped := df.fileSystemDumpActions[key]
+	logVerbosity := df.flags.LogVerbosity
+	return isTraced || isDumped || logVerbosity >= 1, isDumped || logVerbosity >= 2
+}
@@ -0,0 +1,188 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+
+	"google.golang.org/grpc/codes"
+)
+
+type debugLoggingBuildExecutor struct {
+	BuildExecutor
+	debugFlags *DebugFlags
+	clock      clock.Clock
+}
+
+// NewDebugLoggingBuildExecutor creates a decorator for BuildExecutor
+// that logs additional information about actions, based on the debug
+// flags that are in effect at the time execution starts. It may log
+// execution stage transitions, and all file system operations
+// performed by an action. As this may generate large amounts of
+// output, this information is only logged if explicitly requested.
+func NewDebugLoggingBuildExecutor(buildExecutor BuildExecutor, debugFlags *DebugFlags, clock clock.Clock) BuildExecutor {
+	return &debugLoggingBuildExecutor{
+		BuildExecutor: buildExecutor,
+		debugFlags:    debugFlags,
+		clock:         clock,
+	}
+}
+
+func (be *debugLoggingBuildExecutor) Execute(ctx context.Context, filePool re_filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+	logStages, dumpFileSystem := be.debugFlags.getActionFlags(request.ActionDigest)
+	if !logStages {
+		return be.BuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates)
+	}
+
+	logPrefix := fmt.Sprintf("Action %s: ", getActionDigestKey(request.ActionDigest))
+	if dumpFileSystem {
+		filePool = &debugLoggingFilePool{
+			base:      filePool,
+			logPrefix: logPrefix,
+		}
+		monitor = &debugLoggingUnreadDirectoryMonitor{
+			base:      monitor,
+			logPrefix: logPrefix,
+		}
+	}
+
+	baseUpdates := make(chan *remoteworker.CurrentState_Executing)
+	baseCompletion := make(chan *remoteexecution.ExecuteResponse)
+	go func() {
+		baseCompletion <- be.BuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, baseUpdates)
+	}()
+
+	var currentState *remoteworker.CurrentState_Executing
+	stageStart := be.clock.Now()
+	log.Printf("%sStarting", logPrefix)
+	for {
+		select {
+		case update := <-baseUpdates:
+			now := be.clock.Now()
+			log.Printf("%sEntering stage %#v after %s in stage %#v", logPrefix, getExecutionStageName(update), now.Sub(stageStart), getExecutionStageName(currentState))
+			currentState = update
+			stageStart = now
+			executionStateUpdates <- update
+		case response := <-baseCompletion:
+			log.Printf("%sCompleted after %s in stage %#v with status %s", logPrefix, be.clock.Now().Sub(stageStart), getExecutionStageName(currentState), codes.Code(response.GetStatus().GetCode()))
+			return response
+		}
+	}
+}
+
+// debugLoggingFilePool is a decorator for FilePool that logs all
+// operations performed against files created by the pool. As files
+// in the pool are anonymous, they are identified by a sequence number.
+type debugLoggingFilePool struct {
+	base      re_filesystem.FilePool
+	logPrefix string
+
+	nextFileID atomic.Uint64
+}
+
+func (fp *debugLoggingFilePool) NewFile() (filesystem.FileReadWriter, error) {
+	f, err := fp.base.NewFile()
+	if err != nil {
+		log.Printf("%sFailed to create file: %s", fp.logPrefix, err)
+		return nil, err
+	}
+	fileID := fp.nextFileID.Add(1)
+	log.Printf("%sCreated file #%d", fp.logPrefix, fileID)
+	return &debugLoggingFileReadWriter{
+		FileReadWriter: f,
+		logPrefix:      fmt.Sprintf("%sFile #%d: ", fp.logPrefix, fileID),
+	}, nil
+}
+
+type debugLoggingFileReadWriter struct {
+	filesystem.FileReadWriter
+	logPrefix string
+}
+
+func (f *debugLoggingFileReadWriter) ReadAt(p []byte, off int64) (int, error) {
+	n, err := f.FileReadWriter.ReadAt(p, off)
+	log.Printf("%sRead %d of %d bytes at offset %d: %v", f.logPrefix, n, len(p), off, err)
+	return n, err
+}
+
+func (f *debugLoggingFileReadWriter) WriteAt(p []byte, off int64) (int, error) {
+	n, err := f.FileReadWriter.WriteAt(p, off)
+	log.Printf("%sWrote %d of %d bytes at offset %d: %v", f.logPrefix, n, len(p), off, err)
+	return n, err
+}
+
+func (f *debugLoggingFileReadWriter) Truncate(length int64) error {
+	err := f.FileReadWriter.Truncate(length)
+	log.Printf("%sTruncated to %d bytes: %v", f.logPrefix, length, err)
+	return err
+}
+
+func (f *debugLoggingFileReadWriter) PunchHole(off, size int64) error {
+	err := re_filesystem.PunchHole(f.FileReadWriter, off, size)
+	log.Printf("%sPunched hole of %d bytes at offset %d: %v", f.logPrefix, size, off, err)
+	return err
+}
+
+func (f *debugLoggingFileReadWriter) Close() error {
+	err := f.FileReadWriter.Close()
+	log.Printf("%sClosed: %v", f.logPrefix, err)
+	return err
+}
+
+// debugLoggingUnreadDirectoryMonitor is a decorator for
+// UnreadDirectoryMonitor that logs the paths of directories and files
+// in the input root that are accessed. The base monitor may be nil, in
+// which case accesses are only logged.
+type debugLoggingUnreadDirectoryMonitor struct {
+	base      access.UnreadDirectoryMonitor
+	logPrefix string
+	trace     *path.Trace
+}
+
+func (m *debugLoggingUnreadDirectoryMonitor) ReadDirectory() access.ReadDirectoryMonitor {
+	log.Printf("%sRead directory %#v", m.logPrefix, m.trace.String())
+	var base access.ReadDirectoryMonitor
+	if m.base != nil {
+		base = m.base.ReadDirectory()
+	}
+	return &debugLoggingReadDirectoryMonitor{
+		base:      base,
+		logPrefix: m.logPrefix,
+		trace:     m.trace,
+	}
+}
+
+type debugLoggingReadDirectoryMonitor struct {
+	base      access.ReadDirectoryMonitor
+	logPrefix string
+	trace     *path.Trace
+}
+
+func (m *debugLoggingReadDirectoryMonitor) ResolvedDirectory(name path.Component) access.UnreadDirectoryMonitor {
+	var base access.UnreadDirectoryMonitor
+	if m.base != nil {
+		base = m.base.ResolvedDirectory(name)
+	}
+	return &debugLoggingUnreadDirectoryMonitor{
+		base:      base,
+		logPrefix: m.logPrefix,
+		trace:     m.trace.Append(name),
+	}
+}
+
+func (m *debugLoggingReadDirectoryMonitor) ReadFile(name path.Component) {
+	log.Printf("%sRead file %#v", m.logPrefix, m.trace.Append(name).String())
+	if m.base != nil {
+		m.base.ReadFile(name)
+	}
+}
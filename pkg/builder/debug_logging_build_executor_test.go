@@ -0,0 +1,98 @@
+package builder_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/workerdebug"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestDebugLoggingBuildExecutor(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseBuildExecutor := mock.NewMockBuildExecutor(ctrl)
+	debugFlags := builder.NewDebugFlags()
+	clock := mock.NewMockClock(ctrl)
+	buildExecutor := builder.NewDebugLoggingBuildExecutor(baseBuildExecutor, debugFlags, clock)
+
+	filePool := mock.NewMockFilePool(ctrl)
+	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
+	digestFunction := digest.MustNewFunction("", remoteexecution.DigestFunction_SHA256)
+	request := &remoteworker.DesiredState_Executing{
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "64ec88ca00b268e5ba1a35678a1b5316d212f4f366b2477232534a8aeca37f3c",
+			SizeBytes: 11,
+		},
+	}
+
+	t.Run("InvalidDigest", func(t *testing.T) {
+		_, err := debugFlags.SetDebugFlags(ctx, &workerdebug.DebugFlags{
+			TracedActionDigests: []*remoteexecution.Digest{{SizeBytes: 11}},
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Invalid action digest at index 0 of traced_action_digests"), err)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		// If no debug flags are set, calls should be forwarded
+		// without decorating any of the arguments.
+		executionStateUpdates := make(chan *remoteworker.CurrentState_Executing)
+		baseBuildExecutor.EXPECT().Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates).
+			Return(&remoteexecution.ExecuteResponse{})
+
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{}, buildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates))
+	})
+
+	t.Run("FileSystemDump", func(t *testing.T) {
+		flags := &workerdebug.DebugFlags{
+			FileSystemDumpActionDigests: []*remoteexecution.Digest{request.ActionDigest},
+		}
+		_, err := debugFlags.SetDebugFlags(ctx, flags)
+		require.NoError(t, err)
+		actualFlags, err := debugFlags.GetDebugFlags(ctx, &emptypb.Empty{})
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, flags, actualFlags)
+
+		// Execution stage transitions should be forwarded, while
+		// file system accesses are passed on to the original
+		// FilePool and UnreadDirectoryMonitor.
+		clock.EXPECT().Now().Return(time.Unix(1000, 0)).Times(3)
+		baseBuildExecutor.EXPECT().Execute(ctx, gomock.Any(), gomock.Any(), digestFunction, request, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, wrappedFilePool re_filesystem.FilePool, wrappedMonitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+				readDirectoryMonitor := mock.NewMockReadDirectoryMonitor(ctrl)
+				monitor.EXPECT().ReadDirectory().Return(readDirectoryMonitor)
+				readDirectoryMonitor.EXPECT().ReadFile(path.MustNewComponent("hello.txt"))
+				wrappedMonitor.ReadDirectory().ReadFile(path.MustNewComponent("hello.txt"))
+
+				executionStateUpdates <- &remoteworker.CurrentState_Executing{
+					ExecutionState: &remoteworker.CurrentState_Executing_FetchingInputs{
+						FetchingInputs: &emptypb.Empty{},
+					},
+				}
+				return &remoteexecution.ExecuteResponse{}
+			})
+		executionStateUpdates := make(chan *remoteworker.CurrentState_Executing, 1)
+
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{}, buildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates))
+		testutil.RequireEqualProto(t, &remoteworker.CurrentState_Executing{
+			ExecutionState: &remoteworker.CurrentState_Executing_FetchingInputs{
+				FetchingInputs: &emptypb.Empty{},
+			},
+		}, <-executionStateUpdates)
+	})
+}
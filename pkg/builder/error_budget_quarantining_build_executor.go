@@ -0,0 +1,176 @@
+package builder
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// InfrastructureErrorBudget keeps track of the outcomes of the most
+// recently executed actions of a group of worker threads. If the number
+// of actions that failed due to an infrastructure error exceeds a
+// configured value, the worker threads are quarantined.
+type InfrastructureErrorBudget struct {
+	clock              clock.Clock
+	maximumFailures    int
+	quarantineDuration time.Duration
+
+	lock             sync.Mutex
+	outcomes         []bool
+	nextOutcome      int
+	currentFailures  int
+	quarantined      bool
+	quarantinedUntil time.Time
+}
+
+// NewInfrastructureErrorBudget creates a new InfrastructureErrorBudget
+// that is in the initial state, where no actions have been executed.
+// The worker threads are quarantined if more than maximumFailures out
+// of the last windowSize actions failed due to an infrastructure
+// error.
+func NewInfrastructureErrorBudget(clock clock.Clock, windowSize, maximumFailures int, quarantineDuration time.Duration) *InfrastructureErrorBudget {
+	return &InfrastructureErrorBudget{
+		clock:              clock,
+		maximumFailures:    maximumFailures,
+		quarantineDuration: quarantineDuration,
+
+		outcomes: make([]bool, windowSize),
+	}
+}
+
+func (eb *InfrastructureErrorBudget) recordOutcome(isFailure bool) {
+	eb.lock.Lock()
+	defer eb.lock.Unlock()
+
+	// Replace the oldest outcome in the window.
+	if eb.outcomes[eb.nextOutcome] {
+		eb.currentFailures--
+	}
+	eb.outcomes[eb.nextOutcome] = isFailure
+	if isFailure {
+		eb.currentFailures++
+	}
+	eb.nextOutcome = (eb.nextOutcome + 1) % len(eb.outcomes)
+
+	if !eb.quarantined && eb.currentFailures > eb.maximumFailures {
+		eb.quarantined = true
+		eb.quarantinedUntil = eb.clock.Now().Add(eb.quarantineDuration)
+	}
+}
+
+// getQuarantineState returns whether the worker threads are
+// quarantined. If so, it also returns whether the quarantine period
+// has elapsed, meaning the worker threads may rejoin if they are
+// healthy.
+func (eb *InfrastructureErrorBudget) getQuarantineState() (quarantined bool, quarantinedUntil time.Time, mayRejoin bool) {
+	eb.lock.Lock()
+	defer eb.lock.Unlock()
+
+	if !eb.quarantined {
+		return false, time.Time{}, false
+	}
+	return true, eb.quarantinedUntil, !eb.clock.Now().Before(eb.quarantinedUntil)
+}
+
+// extendQuarantine restarts the quarantine period. This is called
+// when worker threads are found to still be unhealthy after the
+// quarantine period has elapsed.
+func (eb *InfrastructureErrorBudget) extendQuarantine() {
+	eb.lock.Lock()
+	defer eb.lock.Unlock()
+
+	eb.quarantinedUntil = eb.clock.Now().Add(eb.quarantineDuration)
+}
+
+// liftQuarantine lifts the quarantine, and discards the outcomes of
+// all previously executed actions. This gives the worker threads a
+// full error budget upon rejoining.
+func (eb *InfrastructureErrorBudget) liftQuarantine() {
+	eb.lock.Lock()
+	defer eb.lock.Unlock()
+
+	if eb.quarantined {
+		eb.quarantined = false
+		for i := range eb.outcomes {
+			eb.outcomes[i] = false
+		}
+		eb.currentFailures = 0
+	}
+}
+
+// isInfrastructureFailure returns whether an ExecuteResponse indicates
+// that the action failed due to a problem with the worker, as opposed
+// to a problem with the action itself.
+func isInfrastructureFailure(response *remoteexecution.ExecuteResponse) bool {
+	switch status.FromProto(response.Status).Code() {
+	case codes.DataLoss, codes.Internal, codes.Unavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+type errorBudgetQuarantiningBuildExecutor struct {
+	BuildExecutor
+	errorBudget *InfrastructureErrorBudget
+}
+
+// NewErrorBudgetQuarantiningBuildExecutor is a decorator for
+// BuildExecutor that records whether actions fail due to
+// infrastructure errors (e.g., I/O errors, the runner crashing, or
+// failures to create a mount) in an InfrastructureErrorBudget. When
+// the error budget is exhausted, the BuildExecutor starts to fail
+// readiness checks, thereby preventing further work from being
+// executed. This prevents a single unhealthy worker from failing a
+// meaningful fraction of the actions of a large build.
+//
+// Once the quarantine period has elapsed, the readiness checks of the
+// underlying BuildExecutor are used as a self-test. These typically
+// ensure that a build directory can be created and that the runner is
+// responsive. The quarantine is only lifted if the self-test succeeds.
+func NewErrorBudgetQuarantiningBuildExecutor(buildExecutor BuildExecutor, errorBudget *InfrastructureErrorBudget) BuildExecutor {
+	return &errorBudgetQuarantiningBuildExecutor{
+		BuildExecutor: buildExecutor,
+		errorBudget:   errorBudget,
+	}
+}
+
+func (be *errorBudgetQuarantiningBuildExecutor) CheckReadiness(ctx context.Context) error {
+	quarantined, quarantinedUntil, mayRejoin := be.errorBudget.getQuarantineState()
+	if !quarantined {
+		return be.BuildExecutor.CheckReadiness(ctx)
+	}
+	if !mayRejoin {
+		return status.Errorf(codes.Unavailable, "Worker is quarantined until %s, as too many recent actions failed due to infrastructure errors", quarantinedUntil.UTC().Format(time.RFC3339))
+	}
+
+	// The quarantine period has elapsed. Only rejoin if the
+	// self-test succeeds.
+	if err := be.BuildExecutor.CheckReadiness(ctx); err != nil {
+		be.errorBudget.extendQuarantine()
+		return util.StatusWrap(err, "Worker remains quarantined, as its self-test failed")
+	}
+	be.errorBudget.liftQuarantine()
+	return nil
+}
+
+func (be *errorBudgetQuarantiningBuildExecutor) Execute(ctx context.Context, filePool filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+	response := be.BuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates)
+	if ctx.Err() == nil {
+		// Don't penalize the worker for actions that were
+		// cancelled by the scheduler.
+		be.errorBudget.recordOutcome(isInfrastructureFailure(response))
+	}
+	return response
+}
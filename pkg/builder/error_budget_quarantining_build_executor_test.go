@@ -0,0 +1,96 @@
+package builder_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorBudgetQuarantiningBuildExecutor(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseBuildExecutor := mock.NewMockBuildExecutor(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	errorBudget := builder.NewInfrastructureErrorBudget(clock, 3, 1, 5*time.Minute)
+	buildExecutor := builder.NewErrorBudgetQuarantiningBuildExecutor(baseBuildExecutor, errorBudget)
+
+	filePool := mock.NewMockFilePool(ctrl)
+	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
+	digestFunction := digest.MustNewFunction("main", remoteexecution.DigestFunction_SHA256)
+	request := &remoteworker.DesiredState_Executing{
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "f47150151eac2fb7a0f3ee1b5c6da3cae7a09ee7e0f0b5a33d3aedc5ec4a0cd5",
+			SizeBytes: 123,
+		},
+	}
+	successResponse := &remoteexecution.ExecuteResponse{
+		Result: &remoteexecution.ActionResult{ExitCode: 1},
+	}
+	failureResponse := &remoteexecution.ExecuteResponse{
+		Result: &remoteexecution.ActionResult{},
+		Status: status.New(codes.Internal, "Failed to create mount: I/O error").Proto(),
+	}
+	execute := func(response *remoteexecution.ExecuteResponse) {
+		var executionStateUpdates chan<- *remoteworker.CurrentState_Executing = make(chan *remoteworker.CurrentState_Executing)
+		baseBuildExecutor.EXPECT().Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates).Return(response)
+		testutil.RequireEqualProto(t, response, buildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates))
+	}
+
+	// A single infrastructure failure is permitted within the
+	// window of three actions. Readiness checks should be
+	// forwarded to the underlying BuildExecutor.
+	execute(failureResponse)
+	execute(successResponse)
+	execute(successResponse)
+	execute(failureResponse)
+	baseBuildExecutor.EXPECT().CheckReadiness(ctx)
+	require.NoError(t, buildExecutor.CheckReadiness(ctx))
+
+	// A second infrastructure failure within the window should
+	// cause the worker to be quarantined.
+	clock.EXPECT().Now().Return(time.Unix(1000, 0))
+	execute(failureResponse)
+	clock.EXPECT().Now().Return(time.Unix(1100, 0))
+	testutil.RequireEqualStatus(
+		t,
+		status.Error(codes.Unavailable, "Worker is quarantined until 1970-01-01T00:21:40Z, as too many recent actions failed due to infrastructure errors"),
+		buildExecutor.CheckReadiness(ctx))
+
+	// Once the quarantine period has elapsed, the readiness check of
+	// the underlying BuildExecutor is used as a self-test. If it
+	// fails, the quarantine should be extended.
+	clock.EXPECT().Now().Return(time.Unix(1300, 0)).Times(2)
+	baseBuildExecutor.EXPECT().CheckReadiness(ctx).Return(status.Error(codes.Unavailable, "Runner not responding"))
+	testutil.RequireEqualStatus(
+		t,
+		status.Error(codes.Unavailable, "Worker remains quarantined, as its self-test failed: Runner not responding"),
+		buildExecutor.CheckReadiness(ctx))
+
+	clock.EXPECT().Now().Return(time.Unix(1500, 0))
+	testutil.RequireEqualStatus(
+		t,
+		status.Error(codes.Unavailable, "Worker is quarantined until 1970-01-01T00:26:40Z, as too many recent actions failed due to infrastructure errors"),
+		buildExecutor.CheckReadiness(ctx))
+
+	// If the self-test succeeds, the worker should rejoin with a
+	// full error budget.
+	clock.EXPECT().Now().Return(time.Unix(1600, 0))
+	baseBuildExecutor.EXPECT().CheckReadiness(ctx)
+	require.NoError(t, buildExecutor.CheckReadiness(ctx))
+
+	execute(failureResponse)
+	baseBuildExecutor.EXPECT().CheckReadiness(ctx)
+	require.NoError(t, buildExecutor.CheckReadiness(ctx))
+}
@@ -0,0 +1,31 @@
+package builder
+
+import (
+	"context"
+
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/util"
+)
+
+type filePoolReadinessCheckingBuildExecutor struct {
+	BuildExecutor
+	filePool re_filesystem.ReadinessCheckingFilePool
+}
+
+// NewFilePoolReadinessCheckingBuildExecutor creates a decorator for
+// BuildExecutor that lets readiness checks fail while the FilePool
+// used to store temporary files is unhealthy. This prevents the worker
+// from picking up actions that are likely to fail due to I/O errors.
+func NewFilePoolReadinessCheckingBuildExecutor(buildExecutor BuildExecutor, filePool re_filesystem.ReadinessCheckingFilePool) BuildExecutor {
+	return &filePoolReadinessCheckingBuildExecutor{
+		BuildExecutor: buildExecutor,
+		filePool:      filePool,
+	}
+}
+
+func (be *filePoolReadinessCheckingBuildExecutor) CheckReadiness(ctx context.Context) error {
+	if err := be.filePool.CheckReadiness(ctx); err != nil {
+		return util.StatusWrap(err, "File pool")
+	}
+	return be.BuildExecutor.CheckReadiness(ctx)
+}
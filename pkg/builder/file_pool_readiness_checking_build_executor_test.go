@@ -0,0 +1,41 @@
+package builder_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFilePoolReadinessCheckingBuildExecutor(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseBuildExecutor := mock.NewMockBuildExecutor(ctrl)
+	filePool := mock.NewMockReadinessCheckingFilePool(ctrl)
+	buildExecutor := builder.NewFilePoolReadinessCheckingBuildExecutor(baseBuildExecutor, filePool)
+
+	t.Run("Healthy", func(t *testing.T) {
+		filePool.EXPECT().CheckReadiness(ctx)
+		baseBuildExecutor.EXPECT().CheckReadiness(ctx)
+
+		require.NoError(t, buildExecutor.CheckReadiness(ctx))
+	})
+
+	t.Run("Degraded", func(t *testing.T) {
+		// The underlying BuildExecutor should not be checked
+		// while the file pool is degraded.
+		filePool.EXPECT().CheckReadiness(ctx).Return(status.Error(codes.Unavailable, "File pool is degraded, and creating a test file failed: input/output error"))
+
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.Unavailable, "File pool: File pool is degraded, and creating a test file failed: input/output error"),
+			buildExecutor.CheckReadiness(ctx))
+	})
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/util"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
@@ -57,9 +59,20 @@ type statsCollectingFilePool struct {
 	totalFiles uint64
 }
 
+// recordErrorLocked updates statistics based on an error returned by
+// the underlying FilePool.
+func (fp *statsCollectingFilePool) recordErrorLocked(err error) {
+	if status.Code(err) == codes.ResourceExhausted {
+		fp.stats.OutOfSpaceCount++
+	}
+}
+
 func (fp *statsCollectingFilePool) NewFile() (filesystem.FileReadWriter, error) {
 	f, err := fp.base.NewFile()
 	if err != nil {
+		fp.lock.Lock()
+		fp.recordErrorLocked(err)
+		fp.lock.Unlock()
 		return nil, err
 	}
 
@@ -116,6 +129,7 @@ func (f *statsCollectingFileReadWriter) WriteAt(p []byte, off int64) (int, error
 	fp.lock.Lock()
 	fp.stats.WritesCount++
 	fp.stats.WritesSizeBytes += uint64(n)
+	fp.recordErrorLocked(err)
 	if n > 0 {
 		if newSize := uint64(off) + uint64(n); newSize > f.size {
 			f.updateSizeLocked(newSize)
@@ -134,12 +148,25 @@ func (f *statsCollectingFileReadWriter) Truncate(length int64) error {
 	fp.stats.TruncatesCount++
 	if err == nil {
 		f.updateSizeLocked(uint64(length))
+	} else {
+		fp.recordErrorLocked(err)
 	}
 	fp.lock.Unlock()
 
 	return err
 }
 
+func (f *statsCollectingFileReadWriter) PunchHole(off, size int64) error {
+	err := re_filesystem.PunchHole(f.FileReadWriter, off, size)
+	if err != nil {
+		fp := f.pool
+		fp.lock.Lock()
+		fp.recordErrorLocked(err)
+		fp.lock.Unlock()
+	}
+	return err
+}
+
 func (f *statsCollectingFileReadWriter) Close() error {
 	err := f.FileReadWriter.Close()
 	f.FileReadWriter = nil
@@ -17,6 +17,8 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
@@ -59,6 +61,9 @@ func TestFilePoolStatsBuildExecutorExample(t *testing.T) {
 		require.Equal(t, io.EOF, err)
 		require.Equal(t, []byte("\x00\x00Hello\x00\x00\x00"), p[:])
 		require.NoError(t, f.Truncate(42))
+
+		// Running out of space should be tracked.
+		require.Equal(t, status.Error(codes.ResourceExhausted, "File size quota reached"), f.Truncate(1000))
 		require.NoError(t, f.Close())
 
 		return &remoteexecution.ExecuteResponse{
@@ -74,7 +79,7 @@ func TestFilePoolStatsBuildExecutorExample(t *testing.T) {
 	buildExecutor := builder.NewFilePoolStatsBuildExecutor(baseBuildExecutor)
 	executeResponse := buildExecutor.Execute(
 		ctx,
-		filesystem.InMemoryFilePool,
+		filesystem.NewQuotaEnforcingFilePool(filesystem.InMemoryFilePool, 10, 200),
 		monitor,
 		digest.MustNewFunction("hello", remoteexecution.DigestFunction_MD5),
 		request,
@@ -90,7 +95,8 @@ func TestFilePoolStatsBuildExecutorExample(t *testing.T) {
 		ReadsSizeBytes:     7,
 		WritesCount:        1,
 		WritesSizeBytes:    5,
-		TruncatesCount:     2,
+		TruncatesCount:     3,
+		OutOfSpaceCount:    1,
 	})
 	require.NoError(t, err)
 	testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
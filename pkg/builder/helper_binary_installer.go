@@ -0,0 +1,152 @@
+package builder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+)
+
+// HelperBinary describes a file that needs to be present on the local
+// file system of the worker, such as a runner shim or a tool wrapper,
+// whose contents are stored in the Content Addressable Storage.
+type HelperBinary struct {
+	Path         string
+	Digest       digest.Digest
+	IsExecutable bool
+}
+
+// installedHelperBinary contains the attributes of a helper binary at
+// the time it was installed. These are used to cheaply detect whether
+// the file has been removed or modified since.
+type installedHelperBinary struct {
+	sizeBytes        int64
+	modificationTime time.Time
+}
+
+// HelperBinaryInstaller is responsible for downloading helper binaries
+// from the Content Addressable Storage and storing them at their
+// configured location. This permits rolling out updates to these
+// binaries without rebuilding worker images.
+type HelperBinaryInstaller struct {
+	contentAddressableStorage blobstore.BlobAccess
+	helperBinaries            []HelperBinary
+
+	lock      sync.Mutex
+	installed []*installedHelperBinary
+}
+
+// NewHelperBinaryInstaller creates a HelperBinaryInstaller that
+// installs a fixed set of helper binaries.
+func NewHelperBinaryInstaller(contentAddressableStorage blobstore.BlobAccess, helperBinaries []HelperBinary) *HelperBinaryInstaller {
+	return &HelperBinaryInstaller{
+		contentAddressableStorage: contentAddressableStorage,
+		helperBinaries:            helperBinaries,
+		installed:                 make([]*installedHelperBinary, len(helperBinaries)),
+	}
+}
+
+// install a single helper binary. The contents are first written into
+// a temporary file, which is renamed to its final location afterwards.
+// This ensures that actions never observe partially written files.
+func (hi *HelperBinaryInstaller) install(ctx context.Context, helperBinary *HelperBinary) (*installedHelperBinary, error) {
+	directory, name := filepath.Split(helperBinary.Path)
+	f, err := os.CreateTemp(directory, "."+name+".*")
+	if err != nil {
+		return nil, util.StatusWrap(err, "Failed to create temporary file")
+	}
+	temporaryPath := f.Name()
+	if err := hi.contentAddressableStorage.Get(ctx, helperBinary.Digest).IntoWriter(f); err != nil {
+		f.Close()
+		os.Remove(temporaryPath)
+		return nil, util.StatusWrap(err, "Failed to download file")
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(temporaryPath)
+		return nil, util.StatusWrap(err, "Failed to close temporary file")
+	}
+	var mode os.FileMode = 0o444
+	if helperBinary.IsExecutable {
+		mode = 0o555
+	}
+	if err := os.Chmod(temporaryPath, mode); err != nil {
+		os.Remove(temporaryPath)
+		return nil, util.StatusWrap(err, "Failed to set permissions of temporary file")
+	}
+	if err := os.Rename(temporaryPath, helperBinary.Path); err != nil {
+		os.Remove(temporaryPath)
+		return nil, util.StatusWrap(err, "Failed to move temporary file into place")
+	}
+
+	fileInfo, err := os.Stat(helperBinary.Path)
+	if err != nil {
+		return nil, util.StatusWrap(err, "Failed to obtain attributes of installed file")
+	}
+	return &installedHelperBinary{
+		sizeBytes:        fileInfo.Size(),
+		modificationTime: fileInfo.ModTime(),
+	}, nil
+}
+
+// EnsureInstalled installs all helper binaries that have not been
+// installed yet, or have been removed or modified since they were
+// installed. This function should be called at startup, and in between
+// actions to repair helper binaries that were tampered with.
+func (hi *HelperBinaryInstaller) EnsureInstalled(ctx context.Context) error {
+	hi.lock.Lock()
+	defer hi.lock.Unlock()
+
+	for i := range hi.helperBinaries {
+		helperBinary := &hi.helperBinaries[i]
+		if installed := hi.installed[i]; installed != nil {
+			if fileInfo, err := os.Stat(helperBinary.Path); err == nil &&
+				fileInfo.Size() == installed.sizeBytes &&
+				fileInfo.ModTime().Equal(installed.modificationTime) {
+				continue
+			}
+		}
+
+		installed, err := hi.install(ctx, helperBinary)
+		if err != nil {
+			hi.installed[i] = nil
+			return util.StatusWrapf(err, "Failed to install helper binary %#v with digest %s", helperBinary.Path, helperBinary.Digest)
+		}
+		hi.installed[i] = installed
+	}
+	return nil
+}
+
+type helperBinaryInstallingBuildExecutor struct {
+	BuildExecutor
+	installer *HelperBinaryInstaller
+}
+
+// NewHelperBinaryInstallingBuildExecutor creates a decorator for
+// BuildExecutor that ensures that all helper binaries are installed
+// prior to executing an action.
+func NewHelperBinaryInstallingBuildExecutor(base BuildExecutor, installer *HelperBinaryInstaller) BuildExecutor {
+	return &helperBinaryInstallingBuildExecutor{
+		BuildExecutor: base,
+		installer:     installer,
+	}
+}
+
+func (be *helperBinaryInstallingBuildExecutor) Execute(ctx context.Context, filePool filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+	if err := be.installer.EnsureInstalled(ctx); err != nil {
+		response := NewDefaultExecuteResponse(request)
+		attachErrorToExecuteResponse(response, util.StatusWrapWithCode(err, codes.Unavailable, "Failed to install helper binaries"))
+		return response
+	}
+	return be.BuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates)
+}
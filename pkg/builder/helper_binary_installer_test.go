@@ -0,0 +1,132 @@
+package builder_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHelperBinaryInstaller(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	directory := t.TempDir()
+	helperDigest := digest.MustNewDigest("hello", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	helperPath := filepath.Join(directory, "wrapper")
+	installer := builder.NewHelperBinaryInstaller(contentAddressableStorage, []builder.HelperBinary{{
+		Path:         helperPath,
+		Digest:       helperDigest,
+		IsExecutable: true,
+	}})
+
+	t.Run("DownloadFailure", func(t *testing.T) {
+		contentAddressableStorage.EXPECT().Get(ctx, helperDigest).
+			Return(buffer.NewBufferFromError(status.Error(codes.Internal, "Server on fire")))
+
+		testutil.RequireEqualStatus(
+			t,
+			status.Errorf(codes.Internal, "Failed to install helper binary %#v with digest 3-8b1a9953c4611296a827abf8c47804d7-5-hello: Failed to download file: Server on fire", helperPath),
+			installer.EnsureInstalled(ctx))
+
+		// No temporary files should be left behind.
+		entries, err := os.ReadDir(directory)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		contentAddressableStorage.EXPECT().Get(ctx, helperDigest).
+			Return(buffer.NewValidatedBufferFromByteSlice([]byte("Hello")))
+
+		require.NoError(t, installer.EnsureInstalled(ctx))
+		data, err := os.ReadFile(helperPath)
+		require.NoError(t, err)
+		require.Equal(t, []byte("Hello"), data)
+		fileInfo, err := os.Stat(helperPath)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o555), fileInfo.Mode().Perm())
+
+		// Successive calls should not cause the helper binary
+		// to be downloaded once again.
+		require.NoError(t, installer.EnsureInstalled(ctx))
+	})
+
+	t.Run("Removed", func(t *testing.T) {
+		// If the helper binary is removed, for example by an
+		// action, it should be installed once again.
+		require.NoError(t, os.Remove(helperPath))
+		contentAddressableStorage.EXPECT().Get(ctx, helperDigest).
+			Return(buffer.NewValidatedBufferFromByteSlice([]byte("Hello")))
+
+		require.NoError(t, installer.EnsureInstalled(ctx))
+		data, err := os.ReadFile(helperPath)
+		require.NoError(t, err)
+		require.Equal(t, []byte("Hello"), data)
+	})
+}
+
+func TestHelperBinaryInstallingBuildExecutor(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	helperDigest := digest.MustNewDigest("hello", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	helperPath := filepath.Join(t.TempDir(), "wrapper")
+	installer := builder.NewHelperBinaryInstaller(contentAddressableStorage, []builder.HelperBinary{{
+		Path:   helperPath,
+		Digest: helperDigest,
+	}})
+	baseBuildExecutor := mock.NewMockBuildExecutor(ctrl)
+	buildExecutor := builder.NewHelperBinaryInstallingBuildExecutor(baseBuildExecutor, installer)
+
+	filePool := mock.NewMockFilePool(ctrl)
+	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
+	digestFunction := digest.MustNewFunction("hello", remoteexecution.DigestFunction_MD5)
+	var executionStateUpdates chan<- *remoteworker.CurrentState_Executing = make(chan *remoteworker.CurrentState_Executing)
+	request := &remoteworker.DesiredState_Executing{
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "d41d8cd98f00b204e9800998ecf8427e",
+			SizeBytes: 123,
+		},
+		Action: &remoteexecution.Action{},
+	}
+
+	t.Run("InstallationFailure", func(t *testing.T) {
+		// Actions should not be executed if helper binaries
+		// cannot be installed.
+		contentAddressableStorage.EXPECT().Get(ctx, helperDigest).
+			Return(buffer.NewBufferFromError(status.Error(codes.Internal, "Server on fire")))
+
+		response := buildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates)
+		testutil.RequireEqualStatus(
+			t,
+			status.Errorf(codes.Unavailable, "Failed to install helper binaries: Failed to install helper binary %#v with digest 3-8b1a9953c4611296a827abf8c47804d7-5-hello: Failed to download file: Server on fire", helperPath),
+			status.ErrorProto(response.Status))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		contentAddressableStorage.EXPECT().Get(ctx, helperDigest).
+			Return(buffer.NewValidatedBufferFromByteSlice([]byte("Hello")))
+		baseBuildExecutor.EXPECT().Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates).
+			Return(&remoteexecution.ExecuteResponse{
+				Result: &remoteexecution.ActionResult{},
+			})
+
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{},
+		}, buildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates))
+	})
+}
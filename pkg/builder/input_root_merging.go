@@ -0,0 +1,66 @@
+package builder
+
+import (
+	"context"
+	"os"
+
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// putBlob computes the digest of a blob and stores it in the Content
+// Addressable Storage.
+func putBlob(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function, data []byte) (digest.Digest, error) {
+	digestGenerator := digestFunction.NewGenerator(int64(len(data)))
+	if _, err := digestGenerator.Write(data); err != nil {
+		panic(err)
+	}
+	blobDigest := digestGenerator.Sum()
+	if err := contentAddressableStorage.Put(ctx, blobDigest, buffer.NewValidatedBufferFromByteSlice(data)); err != nil {
+		return digest.BadDigest, err
+	}
+	return blobDigest, nil
+}
+
+// mergeDirectoryContentsAtPath places the contents of a Directory
+// stored in the Content Addressable Storage inside the input root of
+// an action. The Directory must contain a single entry, whose name
+// corresponds to the last component of the provided path. Parent
+// directories of the path are created if they don't exist already.
+//
+// Because the entry is added through MergeDirectoryContents(), it is
+// read-only regardless of the type of build directory. The entry may
+// not already be part of the input root.
+func mergeDirectoryContentsAtPath(ctx context.Context, inputRootDirectory BuildDirectory, components []path.Component, errorLogger util.ErrorLogger, directoryDigest digest.Digest) error {
+	d := inputRootDirectory
+	parentComponents := components[:len(components)-1]
+	for _, component := range parentComponents {
+		if err := d.Mkdir(component, 0o777); err != nil && !os.IsExist(err) {
+			return util.StatusWrapf(err, "Failed to create parent directory %#v", component.String())
+		}
+		child, err := d.EnterBuildDirectory(component)
+		if err != nil {
+			return util.StatusWrapf(err, "Failed to enter parent directory %#v", component.String())
+		}
+		if d != inputRootDirectory {
+			d.Close()
+		}
+		d = child
+	}
+	if d != inputRootDirectory {
+		defer d.Close()
+	}
+
+	if _, err := d.Lstat(components[len(components)-1]); err == nil {
+		return status.Error(codes.InvalidArgument, "Path collides with a file or directory in the input root")
+	} else if !os.IsNotExist(err) {
+		return util.StatusWrap(err, "Failed to check for existence of path")
+	}
+	return d.MergeDirectoryContents(ctx, errorLogger, directoryDigest, nil, len(parentComponents))
+}
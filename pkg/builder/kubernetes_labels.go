@@ -0,0 +1,41 @@
+package builder
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ParseKubernetesLabels parses labels or annotations stored in the
+// format used by the Kubernetes downward API. Every line in this format
+// contains a key, followed by an equals sign and a value that is quoted
+// as a Go string literal (e.g., app="bb-worker").
+func ParseKubernetesLabels(data []byte) (map[string]string, error) {
+	labels := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		key, quotedValue, ok := strings.Cut(line, "=")
+		if !ok || key == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "Line %d does not contain a key-value pair", lineNumber)
+		}
+		value, err := strconv.Unquote(quotedValue)
+		if err != nil {
+			return nil, util.StatusWrapfWithCode(err, codes.InvalidArgument, "Invalid value for key %#v on line %d", key, lineNumber)
+		}
+		labels[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, util.StatusWrapWithCode(err, codes.InvalidArgument, "Failed to read labels")
+	}
+	return labels, nil
+}
@@ -0,0 +1,38 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseKubernetesLabels(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		labels, err := builder.ParseKubernetesLabels([]byte(
+			"kubernetes.io/arch=\"amd64\"\n" +
+				"topology.kubernetes.io/zone=\"europe-west4-a\"\n" +
+				"\n" +
+				"description=\"Contains \\\"quotes\\\" and = signs\"\n"))
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"kubernetes.io/arch":          "amd64",
+			"topology.kubernetes.io/zone": "europe-west4-a",
+			"description":                 "Contains \"quotes\" and = signs",
+		}, labels)
+	})
+
+	t.Run("MissingEqualsSign", func(t *testing.T) {
+		_, err := builder.ParseKubernetesLabels([]byte("app=\"bb-worker\"\nhello\n"))
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Line 2 does not contain a key-value pair"), err)
+	})
+
+	t.Run("UnquotedValue", func(t *testing.T) {
+		_, err := builder.ParseKubernetesLabels([]byte("app=bb-worker\n"))
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Invalid value for key \"app\" on line 1: invalid syntax"), err)
+	})
+}
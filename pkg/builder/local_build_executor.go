@@ -2,7 +2,10 @@ package builder
 
 import (
 	"context"
+	"fmt"
+	"log"
 	"os"
+	stdpath "path"
 	"sync"
 	"time"
 
@@ -63,6 +66,18 @@ func (el *capturingErrorLogger) GetError() error {
 	return el.error
 }
 
+// actionEnvironmentVariablesKey is the key of a context value
+// containing environment variables that decorators of
+// localBuildExecutor want to provide to a single action.
+type actionEnvironmentVariablesKey struct{}
+
+// withActionEnvironmentVariables returns a context that causes
+// localBuildExecutor to set additional environment variables for the
+// action that is executed.
+func withActionEnvironmentVariables(ctx context.Context, environmentVariables map[string]string) context.Context {
+	return context.WithValue(ctx, actionEnvironmentVariablesKey{}, environmentVariables)
+}
+
 type localBuildExecutor struct {
 	contentAddressableStorage      blobstore.BlobAccess
 	buildDirectoryCreator          BuildDirectoryCreator
@@ -72,11 +87,29 @@ type localBuildExecutor struct {
 	maximumMessageSizeBytes        int
 	environmentVariables           map[string]string
 	forceUploadTreesAndDirectories bool
+	sharedCaches                   []*SharedCache
+	temporaryDirectoryPolicy       *TemporaryDirectoryPolicy
+	stdinFile                      *StdinFile
+	workerMetadataFile             *WorkerMetadataFile
+	previousActionOutputGrafter    *PreviousActionOutputGrafter
+	outputLogStreamer              *OutputLogStreamer
 }
 
 // NewLocalBuildExecutor returns a BuildExecutor that executes build
 // steps on the local system.
-func NewLocalBuildExecutor(contentAddressableStorage blobstore.BlobAccess, buildDirectoryCreator BuildDirectoryCreator, runner runner_pb.RunnerClient, clock clock.Clock, inputRootCharacterDevices map[path.Component]filesystem.DeviceNumber, maximumMessageSizeBytes int, environmentVariables map[string]string, forceUploadTreesAndDirectories bool) BuildExecutor {
+//
+// If a StdinFile is provided, actions may request that the contents of
+// a blob stored in the Content Addressable Storage are provided to the
+// command over stdin.
+//
+// If a WorkerMetadataFile is provided, it is placed inside the input
+// root of every action. If a PreviousActionOutputGrafter is provided,
+// actions may request that outputs of previously executed actions are
+// placed inside their input root.
+//
+// If an OutputLogStreamer is provided, the standard output and error of
+// actions are streamed to LogStreams while the command is running.
+func NewLocalBuildExecutor(contentAddressableStorage blobstore.BlobAccess, buildDirectoryCreator BuildDirectoryCreator, runner runner_pb.RunnerClient, clock clock.Clock, inputRootCharacterDevices map[path.Component]filesystem.DeviceNumber, maximumMessageSizeBytes int, environmentVariables map[string]string, forceUploadTreesAndDirectories bool, sharedCaches []*SharedCache, temporaryDirectoryPolicy *TemporaryDirectoryPolicy, stdinFile *StdinFile, workerMetadataFile *WorkerMetadataFile, previousActionOutputGrafter *PreviousActionOutputGrafter, outputLogStreamer *OutputLogStreamer) BuildExecutor {
 	return &localBuildExecutor{
 		contentAddressableStorage:      contentAddressableStorage,
 		buildDirectoryCreator:          buildDirectoryCreator,
@@ -86,6 +119,12 @@ func NewLocalBuildExecutor(contentAddressableStorage blobstore.BlobAccess, build
 		maximumMessageSizeBytes:        maximumMessageSizeBytes,
 		environmentVariables:           environmentVariables,
 		forceUploadTreesAndDirectories: forceUploadTreesAndDirectories,
+		sharedCaches:                   sharedCaches,
+		temporaryDirectoryPolicy:       temporaryDirectoryPolicy,
+		stdinFile:                      stdinFile,
+		workerMetadataFile:             workerMetadataFile,
+		previousActionOutputGrafter:    previousActionOutputGrafter,
+		outputLogStreamer:              outputLogStreamer,
 	}
 }
 
@@ -150,7 +189,14 @@ func (be *localBuildExecutor) Execute(ctx context.Context, filePool re_filesyste
 	if !action.DoNotCache {
 		actionDigestIfNotRunInParallel = &actionDigest
 	}
-	buildDirectory, buildDirectoryPath, err := be.buildDirectoryCreator.GetBuildDirectory(ctx, actionDigestIfNotRunInParallel)
+	inputRootDigest, err := digestFunction.NewDigestFromProto(action.InputRootDigest)
+	if err != nil {
+		attachErrorToExecuteResponse(
+			response,
+			util.StatusWrap(err, "Failed to extract digest for input root"))
+		return response
+	}
+	buildDirectory, buildDirectoryPath, err := be.buildDirectoryCreator.GetBuildDirectory(NewContextWithInputRootDigest(ctx, inputRootDigest), actionDigestIfNotRunInParallel)
 	if err != nil {
 		attachErrorToExecuteResponse(
 			response,
@@ -164,7 +210,7 @@ func (be *localBuildExecutor) Execute(ctx context.Context, filePool re_filesyste
 	ctxWithIOError, cancelIOError := context.WithCancel(ctx)
 	defer cancelIOError()
 	ioErrorCapturer := capturingErrorLogger{cancel: cancelIOError}
-	buildDirectory.InstallHooks(filePool, &ioErrorCapturer)
+	buildDirectory.InstallHooks(ctx, filePool, &ioErrorCapturer, digestFunction)
 
 	executionStateUpdates <- &remoteworker.CurrentState_Executing{
 		ActionDigest: request.ActionDigest,
@@ -189,14 +235,7 @@ func (be *localBuildExecutor) Execute(ctx context.Context, filePool re_filesyste
 	}
 	defer inputRootDirectory.Close()
 
-	inputRootDigest, err := digestFunction.NewDigestFromProto(action.InputRootDigest)
-	if err != nil {
-		attachErrorToExecuteResponse(
-			response,
-			util.StatusWrap(err, "Failed to extract digest for input root"))
-		return response
-	}
-	if err := inputRootDirectory.MergeDirectoryContents(ctx, &ioErrorCapturer, inputRootDigest, monitor); err != nil {
+	if err := inputRootDirectory.MergeDirectoryContents(ctx, &ioErrorCapturer, inputRootDigest, monitor, 0); err != nil {
 		attachErrorToExecuteResponse(response, err)
 		return response
 	}
@@ -208,6 +247,20 @@ func (be *localBuildExecutor) Execute(ctx context.Context, filePool re_filesyste
 		}
 	}
 
+	for _, sharedCache := range be.sharedCaches {
+		if err := sharedCache.Populate(ctx, inputRootDirectory, &ioErrorCapturer, digestFunction); err != nil {
+			attachErrorToExecuteResponse(response, err)
+			return response
+		}
+	}
+
+	if be.workerMetadataFile != nil {
+		if err := be.workerMetadataFile.Populate(ctx, inputRootDirectory, &ioErrorCapturer, digestFunction); err != nil {
+			attachErrorToExecuteResponse(response, err)
+			return response
+		}
+	}
+
 	// Create parent directories of output files and directories.
 	// These are not declared in the input root explicitly.
 	commandDigest, err := digestFunction.NewDigestFromProto(action.CommandDigest)
@@ -243,11 +296,75 @@ func (be *localBuildExecutor) Execute(ctx context.Context, filePool re_filesyste
 		return response
 	}
 
+	// REv2.2 moved the platform properties from the Command to the
+	// Action. Prefer the latter if provided.
+	platform := action.Platform
+	if platform == nil {
+		platform = command.Platform
+	}
+
+	// Let the temporary directory be backed by memory if requested.
+	if be.temporaryDirectoryPolicy != nil {
+		temporaryDirectoryFilePool, err := be.temporaryDirectoryPolicy.GetFilePool(platform)
+		if err != nil {
+			attachErrorToExecuteResponse(response, err)
+			return response
+		}
+		if temporaryDirectoryFilePool != nil {
+			temporaryDirectory, err := buildDirectory.EnterBuildDirectory(temporaryDirectoryComponent)
+			if err != nil {
+				attachErrorToExecuteResponse(
+					response,
+					util.StatusWrap(err, "Failed to enter temporary directory inside build directory"))
+				return response
+			}
+			// Files in the temporary directory are never
+			// uploaded in the background, as background
+			// uploading is only started for the input root
+			// directory.
+			temporaryDirectory.InstallHooks(ctx, temporaryDirectoryFilePool, &ioErrorCapturer, digestFunction)
+			temporaryDirectory.Close()
+		}
+	}
+
+	if be.previousActionOutputGrafter != nil {
+		if err := be.previousActionOutputGrafter.Populate(ctx, platform, inputRootDirectory, &ioErrorCapturer, digestFunction); err != nil {
+			attachErrorToExecuteResponse(response, err)
+			return response
+		}
+	}
+
+	var stdinPath string
+	if be.stdinFile != nil {
+		if created, err := be.stdinFile.Populate(ctx, platform, buildDirectory, &ioErrorCapturer, digestFunction); err != nil {
+			attachErrorToExecuteResponse(response, err)
+			return response
+		} else if created {
+			stdinPath = buildDirectoryPath.Append(stdinComponent).String()
+		}
+	}
+
+	// Let clients observe the output of the command while it is
+	// running.
+	var stdoutStreamName, stderrStreamName string
+	stopOutputLogStreaming := func() {}
+	if be.outputLogStreamer != nil {
+		var streamNames []string
+		streamNames, stopOutputLogStreaming = be.outputLogStreamer.Start(
+			ctx,
+			stdpath.Join(digestFunction.GetInstanceName().String(), "actions", fmt.Sprintf("%s-%d", actionDigest.GetHashString(), actionDigest.GetSizeBytes())),
+			buildDirectory,
+			[]path.Component{stdoutComponent, stderrComponent})
+		stdoutStreamName, stderrStreamName = streamNames[0], streamNames[1]
+	}
+
 	executionStateUpdates <- &remoteworker.CurrentState_Executing{
 		ActionDigest: request.ActionDigest,
 		ExecutionState: &remoteworker.CurrentState_Executing_Running{
 			Running: &emptypb.Empty{},
 		},
+		StdoutStreamName: stdoutStreamName,
+		StderrStreamName: stderrStreamName,
 	}
 
 	environmentVariables := map[string]string{}
@@ -257,6 +374,19 @@ func (be *localBuildExecutor) Execute(ctx context.Context, filePool re_filesyste
 	for _, environmentVariable := range command.EnvironmentVariables {
 		environmentVariables[environmentVariable.Name] = environmentVariable.Value
 	}
+	if actionEnvironmentVariables, ok := ctx.Value(actionEnvironmentVariablesKey{}).(map[string]string); ok {
+		for name, value := range actionEnvironmentVariables {
+			environmentVariables[name] = value
+		}
+	}
+
+	// Start uploading output files as soon as they are closed by
+	// the build action, so that uploading overlaps with execution.
+	outputPaths := outputHierarchy.GetOutputPaths()
+	for i, outputPath := range outputPaths {
+		outputPaths[i] = append([]path.Component{inputRootDirectoryComponent}, outputPath...)
+	}
+	stopBackgroundUploads := buildDirectory.UploadOutputsInBackground(ctx, outputPaths)
 
 	// Invoke the command.
 	ctxWithTimeout, cancelTimeout := be.clock.NewContextWithTimeout(ctxWithIOError, executionTimeout)
@@ -268,9 +398,13 @@ func (be *localBuildExecutor) Execute(ctx context.Context, filePool re_filesyste
 		StderrPath:           buildDirectoryPath.Append(stderrComponent).String(),
 		InputRootDirectory:   buildDirectoryPath.Append(inputRootDirectoryComponent).String(),
 		TemporaryDirectory:   buildDirectoryPath.Append(temporaryDirectoryComponent).String(),
+		Platform:             platform,
+		StdinPath:            stdinPath,
 	})
 	cancelTimeout()
 	<-ctxWithTimeout.Done()
+	stopBackgroundUploads()
+	stopOutputLogStreaming()
 
 	// If an I/O error occurred during execution, attach any errors
 	// related to it to the response first. These errors should be
@@ -283,6 +417,13 @@ func (be *localBuildExecutor) Execute(ctx context.Context, filePool re_filesyste
 	if runErr == nil {
 		response.Result.ExitCode = runResponse.ExitCode
 		response.Result.ExecutionMetadata.AuxiliaryMetadata = append(response.Result.ExecutionMetadata.AuxiliaryMetadata, runResponse.ResourceUsage...)
+		if runResponse.CpuTimeLimitExceeded {
+			// Use a status code that differs from the one
+			// of the execution timeout, which is based on
+			// wall-clock time. Output of the command is
+			// still uploaded.
+			attachErrorToExecuteResponse(response, status.Error(codes.ResourceExhausted, "Command exceeded its CPU time limit"))
+		}
 	} else {
 		attachErrorToExecuteResponse(response, util.StatusWrap(runErr, "Failed to run command"))
 	}
@@ -298,6 +439,8 @@ func (be *localBuildExecutor) Execute(ctx context.Context, filePool re_filesyste
 		ExecutionState: &remoteworker.CurrentState_Executing_UploadingOutputs{
 			UploadingOutputs: &emptypb.Empty{},
 		},
+		StdoutStreamName: stdoutStreamName,
+		StderrStreamName: stderrStreamName,
 	}
 
 	// Upload command output. In the common case, the stdout and
@@ -317,5 +460,18 @@ func (be *localBuildExecutor) Execute(ctx context.Context, filePool re_filesyste
 		attachErrorToExecuteResponse(response, err)
 	}
 
+	// Merge additions to shared caches back into their snapshots,
+	// but only if the action succeeded. Failing actions may have
+	// left partially written files behind. Failing to update a
+	// shared cache does not cause the action to fail, as it only
+	// affects the performance of subsequent actions.
+	if executeResponseIsSuccessful(response) {
+		for _, sharedCache := range be.sharedCaches {
+			if err := sharedCache.Update(ctx, inputRootDirectory, digestFunction); err != nil {
+				log.Printf("Failed to update shared cache: %s", err)
+			}
+		}
+	}
+
 	return response
 }
@@ -37,7 +37,7 @@ func TestLocalBuildExecutorInvalidActionDigest(t *testing.T) {
 	buildDirectoryCreator := mock.NewMockBuildDirectoryCreator(ctrl)
 	runner := mock.NewMockRunnerClient(ctrl)
 	clock := mock.NewMockClock(ctrl)
-	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false)
+	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false /* sharedCaches = */, nil /* temporaryDirectoryPolicy = */, nil /* stdinFile = */, nil /* workerMetadataFile = */, nil /* previousActionOutputGrafter = */, nil /* outputLogStreamer = */, nil)
 
 	filePool := mock.NewMockFilePool(ctrl)
 	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
@@ -76,7 +76,7 @@ func TestLocalBuildExecutorMissingAction(t *testing.T) {
 	buildDirectoryCreator := mock.NewMockBuildDirectoryCreator(ctrl)
 	runner := mock.NewMockRunnerClient(ctrl)
 	clock := mock.NewMockClock(ctrl)
-	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false)
+	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false /* sharedCaches = */, nil /* temporaryDirectoryPolicy = */, nil /* stdinFile = */, nil /* workerMetadataFile = */, nil /* previousActionOutputGrafter = */, nil /* outputLogStreamer = */, nil)
 
 	filePool := mock.NewMockFilePool(ctrl)
 	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
@@ -107,11 +107,11 @@ func TestLocalBuildExecutorBuildDirectoryCreatorFailedFailed(t *testing.T) {
 	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
 	buildDirectoryCreator := mock.NewMockBuildDirectoryCreator(ctrl)
 	actionDigest := digest.MustNewDigest("netbsd", remoteexecution.DigestFunction_SHA256, "5555555555555555555555555555555555555555555555555555555555555555", 7)
-	buildDirectoryCreator.EXPECT().GetBuildDirectory(ctx, &actionDigest).
+	buildDirectoryCreator.EXPECT().GetBuildDirectory(gomock.Any(), &actionDigest).
 		Return(nil, nil, status.Error(codes.InvalidArgument, "Platform requirements not provided"))
 	runner := mock.NewMockRunnerClient(ctrl)
 	clock := mock.NewMockClock(ctrl)
-	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false)
+	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false /* sharedCaches = */, nil /* temporaryDirectoryPolicy = */, nil /* stdinFile = */, nil /* workerMetadataFile = */, nil /* previousActionOutputGrafter = */, nil /* outputLogStreamer = */, nil)
 
 	filePool := mock.NewMockFilePool(ctrl)
 	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
@@ -150,25 +150,27 @@ func TestLocalBuildExecutorInputRootPopulationFailed(t *testing.T) {
 	buildDirectoryCreator := mock.NewMockBuildDirectoryCreator(ctrl)
 	buildDirectory := mock.NewMockBuildDirectory(ctrl)
 	actionDigest := digest.MustNewDigest("netbsd", remoteexecution.DigestFunction_SHA256, "5555555555555555555555555555555555555555555555555555555555555555", 7)
-	buildDirectoryCreator.EXPECT().GetBuildDirectory(ctx, &actionDigest).
+	inputRootDigest := digest.MustNewDigest("netbsd", remoteexecution.DigestFunction_SHA256, "7777777777777777777777777777777777777777777777777777777777777777", 42)
+	buildDirectoryCreator.EXPECT().GetBuildDirectory(builder.NewContextWithInputRootDigest(ctx, inputRootDigest), &actionDigest).
 		Return(buildDirectory, nil, nil)
 	filePool := mock.NewMockFilePool(ctrl)
 	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
-	buildDirectory.EXPECT().InstallHooks(filePool, gomock.Any())
+	buildDirectory.EXPECT().InstallHooks(gomock.Any(), filePool, gomock.Any(), gomock.Any())
 	buildDirectory.EXPECT().Mkdir(path.MustNewComponent("root"), os.FileMode(0o777))
 	inputRootDirectory := mock.NewMockBuildDirectory(ctrl)
 	buildDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("root")).Return(inputRootDirectory, nil)
 	inputRootDirectory.EXPECT().MergeDirectoryContents(
 		ctx,
 		gomock.Any(),
-		digest.MustNewDigest("netbsd", remoteexecution.DigestFunction_SHA256, "7777777777777777777777777777777777777777777777777777777777777777", 42),
+		inputRootDigest,
 		monitor,
+		0,
 	).Return(status.Error(codes.FailedPrecondition, "Some input files could not be found"))
 	inputRootDirectory.EXPECT().Close()
 	buildDirectory.EXPECT().Close()
 	runner := mock.NewMockRunnerClient(ctrl)
 	clock := mock.NewMockClock(ctrl)
-	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false)
+	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false /* sharedCaches = */, nil /* temporaryDirectoryPolicy = */, nil /* stdinFile = */, nil /* workerMetadataFile = */, nil /* previousActionOutputGrafter = */, nil /* outputLogStreamer = */, nil)
 
 	metadata := make(chan *remoteworker.CurrentState_Executing, 10)
 	executeResponse := localBuildExecutor.Execute(
@@ -215,11 +217,11 @@ func TestLocalBuildExecutorOutputDirectoryCreationFailure(t *testing.T) {
 	buildDirectoryCreator := mock.NewMockBuildDirectoryCreator(ctrl)
 	buildDirectory := mock.NewMockBuildDirectory(ctrl)
 	actionDigest := digest.MustNewDigest("fedora", remoteexecution.DigestFunction_SHA256, "5555555555555555555555555555555555555555555555555555555555555555", 7)
-	buildDirectoryCreator.EXPECT().GetBuildDirectory(ctx, &actionDigest).
+	buildDirectoryCreator.EXPECT().GetBuildDirectory(gomock.Any(), &actionDigest).
 		Return(buildDirectory, nil, nil)
 	filePool := mock.NewMockFilePool(ctrl)
 	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
-	buildDirectory.EXPECT().InstallHooks(filePool, gomock.Any())
+	buildDirectory.EXPECT().InstallHooks(gomock.Any(), filePool, gomock.Any(), gomock.Any())
 	buildDirectory.EXPECT().Mkdir(path.MustNewComponent("root"), os.FileMode(0o777))
 	inputRootDirectory := mock.NewMockBuildDirectory(ctrl)
 	buildDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("root")).Return(inputRootDirectory, nil)
@@ -228,13 +230,14 @@ func TestLocalBuildExecutorOutputDirectoryCreationFailure(t *testing.T) {
 		gomock.Any(),
 		digest.MustNewDigest("fedora", remoteexecution.DigestFunction_SHA256, "7777777777777777777777777777777777777777777777777777777777777777", 42),
 		monitor,
+		0,
 	).Return(nil)
 	inputRootDirectory.EXPECT().Mkdir(path.MustNewComponent("foo"), os.FileMode(0o777)).Return(status.Error(codes.Internal, "Out of disk space"))
 	inputRootDirectory.EXPECT().Close()
 	buildDirectory.EXPECT().Close()
 	runner := mock.NewMockRunnerClient(ctrl)
 	clock := mock.NewMockClock(ctrl)
-	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false)
+	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false /* sharedCaches = */, nil /* temporaryDirectoryPolicy = */, nil /* stdinFile = */, nil /* workerMetadataFile = */, nil /* previousActionOutputGrafter = */, nil /* outputLogStreamer = */, nil)
 
 	metadata := make(chan *remoteworker.CurrentState_Executing, 10)
 	executeResponse := localBuildExecutor.Execute(
@@ -275,11 +278,11 @@ func TestLocalBuildExecutorMissingCommand(t *testing.T) {
 	buildDirectoryCreator := mock.NewMockBuildDirectoryCreator(ctrl)
 	buildDirectory := mock.NewMockBuildDirectory(ctrl)
 	actionDigest := digest.MustNewDigest("netbsd", remoteexecution.DigestFunction_SHA256, "5555555555555555555555555555555555555555555555555555555555555555", 7)
-	buildDirectoryCreator.EXPECT().GetBuildDirectory(ctx, &actionDigest).
+	buildDirectoryCreator.EXPECT().GetBuildDirectory(gomock.Any(), &actionDigest).
 		Return(buildDirectory, nil, nil)
 	filePool := mock.NewMockFilePool(ctrl)
 	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
-	buildDirectory.EXPECT().InstallHooks(filePool, gomock.Any())
+	buildDirectory.EXPECT().InstallHooks(gomock.Any(), filePool, gomock.Any(), gomock.Any())
 	buildDirectory.EXPECT().Mkdir(path.MustNewComponent("root"), os.FileMode(0o777))
 	inputRootDirectory := mock.NewMockBuildDirectory(ctrl)
 	buildDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("root")).Return(inputRootDirectory, nil)
@@ -288,12 +291,13 @@ func TestLocalBuildExecutorMissingCommand(t *testing.T) {
 		gomock.Any(),
 		digest.MustNewDigest("netbsd", remoteexecution.DigestFunction_SHA256, "7777777777777777777777777777777777777777777777777777777777777777", 42),
 		monitor,
+		0,
 	).Return(nil)
 	inputRootDirectory.EXPECT().Close()
 	buildDirectory.EXPECT().Close()
 	runner := mock.NewMockRunnerClient(ctrl)
 	clock := mock.NewMockClock(ctrl)
-	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false)
+	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false /* sharedCaches = */, nil /* temporaryDirectoryPolicy = */, nil /* stdinFile = */, nil /* workerMetadataFile = */, nil /* previousActionOutputGrafter = */, nil /* outputLogStreamer = */, nil)
 
 	metadata := make(chan *remoteworker.CurrentState_Executing, 10)
 	executeResponse := localBuildExecutor.Execute(
@@ -359,11 +363,11 @@ func TestLocalBuildExecutorOutputSymlinkReadingFailure(t *testing.T) {
 
 	buildDirectoryCreator := mock.NewMockBuildDirectoryCreator(ctrl)
 	actionDigest := digest.MustNewDigest("nintendo64", remoteexecution.DigestFunction_SHA256, "5555555555555555555555555555555555555555555555555555555555555555", 7)
-	buildDirectoryCreator.EXPECT().GetBuildDirectory(ctx, &actionDigest).
+	buildDirectoryCreator.EXPECT().GetBuildDirectory(gomock.Any(), &actionDigest).
 		Return(buildDirectory, nil, nil)
 	filePool := mock.NewMockFilePool(ctrl)
 	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
-	buildDirectory.EXPECT().InstallHooks(filePool, gomock.Any())
+	buildDirectory.EXPECT().InstallHooks(gomock.Any(), filePool, gomock.Any(), gomock.Any())
 	buildDirectory.EXPECT().Mkdir(path.MustNewComponent("root"), os.FileMode(0o777))
 	inputRootDirectory := mock.NewMockBuildDirectory(ctrl)
 	buildDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("root")).Return(inputRootDirectory, nil)
@@ -372,9 +376,11 @@ func TestLocalBuildExecutorOutputSymlinkReadingFailure(t *testing.T) {
 		gomock.Any(),
 		digest.MustNewDigest("nintendo64", remoteexecution.DigestFunction_SHA256, "7777777777777777777777777777777777777777777777777777777777777777", 42),
 		monitor,
+		0,
 	).Return(nil)
 	buildDirectory.EXPECT().Mkdir(path.MustNewComponent("tmp"), os.FileMode(0o777))
 	runner := mock.NewMockRunnerClient(ctrl)
+	buildDirectory.EXPECT().UploadOutputsInBackground(ctx, gomock.Any()).Return(func() {})
 	runner.EXPECT().Run(gomock.Any(), &runner_pb.RunRequest{
 		Arguments:            []string{"touch", "foo"},
 		EnvironmentVariables: map[string]string{"PATH": "/bin:/usr/bin"},
@@ -400,7 +406,7 @@ func TestLocalBuildExecutorOutputSymlinkReadingFailure(t *testing.T) {
 	clock.EXPECT().NewContextWithTimeout(gomock.Any(), time.Hour).DoAndReturn(func(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
 		return context.WithCancel(parent)
 	})
-	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false)
+	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false /* sharedCaches = */, nil /* temporaryDirectoryPolicy = */, nil /* stdinFile = */, nil /* workerMetadataFile = */, nil /* previousActionOutputGrafter = */, nil /* outputLogStreamer = */, nil)
 
 	metadata := make(chan *remoteworker.CurrentState_Executing, 10)
 	executeResponse := localBuildExecutor.Execute(
@@ -551,11 +557,11 @@ func TestLocalBuildExecutorSuccess(t *testing.T) {
 	// Command execution.
 	buildDirectoryCreator := mock.NewMockBuildDirectoryCreator(ctrl)
 	actionDigest := digest.MustNewDigest("ubuntu1804", remoteexecution.DigestFunction_SHA256, "0000000000000000000000000000000000000000000000000000000000000001", 123)
-	buildDirectoryCreator.EXPECT().GetBuildDirectory(ctx, &actionDigest).
+	buildDirectoryCreator.EXPECT().GetBuildDirectory(gomock.Any(), &actionDigest).
 		Return(buildDirectory, ((*path.Trace)(nil)).Append(path.MustNewComponent("0000000000000000")), nil)
 	filePool := mock.NewMockFilePool(ctrl)
 	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
-	buildDirectory.EXPECT().InstallHooks(filePool, gomock.Any())
+	buildDirectory.EXPECT().InstallHooks(gomock.Any(), filePool, gomock.Any(), gomock.Any())
 	buildDirectory.EXPECT().Mkdir(path.MustNewComponent("root"), os.FileMode(0o777))
 	buildDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("root")).Return(inputRootDirectory, nil)
 	inputRootDirectory.EXPECT().MergeDirectoryContents(
@@ -563,6 +569,7 @@ func TestLocalBuildExecutorSuccess(t *testing.T) {
 		gomock.Any(),
 		digest.MustNewDigest("ubuntu1804", remoteexecution.DigestFunction_SHA256, "0000000000000000000000000000000000000000000000000000000000000003", 345),
 		monitor,
+		0,
 	).Return(nil)
 	inputRootDirectory.EXPECT().Mkdir(path.MustNewComponent("dev"), os.FileMode(0o777))
 	inputRootDevDirectory := mock.NewMockBuildDirectory(ctrl)
@@ -576,7 +583,27 @@ func TestLocalBuildExecutorSuccess(t *testing.T) {
 	resourceUsage, err := anypb.New(&emptypb.Empty{})
 	require.NoError(t, err)
 	runner := mock.NewMockRunnerClient(ctrl)
-	runner.EXPECT().Run(gomock.Any(), &runner_pb.RunRequest{
+	buildDirectory.EXPECT().UploadOutputsInBackground(ctx, [][]path.Component{
+		{
+			path.MustNewComponent("root"),
+			path.MustNewComponent("bazel-out"),
+			path.MustNewComponent("k8-fastbuild"),
+			path.MustNewComponent("bin"),
+			path.MustNewComponent("_objs"),
+			path.MustNewComponent("hello"),
+			path.MustNewComponent("hello.pic.d"),
+		},
+		{
+			path.MustNewComponent("root"),
+			path.MustNewComponent("bazel-out"),
+			path.MustNewComponent("k8-fastbuild"),
+			path.MustNewComponent("bin"),
+			path.MustNewComponent("_objs"),
+			path.MustNewComponent("hello"),
+			path.MustNewComponent("hello.pic.o"),
+		},
+	}).Return(func() {})
+	runner.EXPECT().Run(gomock.Any(), testutil.EqProto(t, &runner_pb.RunRequest{
 		Arguments: []string{
 			"/usr/local/bin/clang",
 			"-MD",
@@ -598,7 +625,15 @@ func TestLocalBuildExecutorSuccess(t *testing.T) {
 		StderrPath:         "0000000000000000/stderr",
 		InputRootDirectory: "0000000000000000/root",
 		TemporaryDirectory: "0000000000000000/tmp",
-	}).Return(&runner_pb.RunResponse{
+		Platform: &remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{
+					Name:  "container-image",
+					Value: "docker://gcr.io/cloud-marketplace/google/rbe-debian8@sha256:4893599fb00089edc8351d9c26b31d3f600774cb5addefb00c70fdb6ca797abf",
+				},
+			},
+		},
+	})).Return(&runner_pb.RunResponse{
 		ExitCode:      0,
 		ResourceUsage: []*anypb.Any{resourceUsage},
 	}, nil)
@@ -615,7 +650,7 @@ func TestLocalBuildExecutorSuccess(t *testing.T) {
 		"TEST_VAR": "123",
 		"PWD":      "dont-overwrite",
 	}
-	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, inputRootCharacterDevices, 10000, environmentVars /* forceUploadTreesAndDirectories = */, false)
+	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, inputRootCharacterDevices, 10000, environmentVars /* forceUploadTreesAndDirectories = */, false /* sharedCaches = */, nil /* temporaryDirectoryPolicy = */, nil /* stdinFile = */, nil /* workerMetadataFile = */, nil /* previousActionOutputGrafter = */, nil /* outputLogStreamer = */, nil)
 
 	requestMetadata, err := anypb.New(&remoteexecution.RequestMetadata{
 		ToolInvocationId: "666b72d8-c43e-4998-866c-9312a31fe86d",
@@ -688,7 +723,7 @@ func TestLocalBuildExecutorCachingInvalidTimeout(t *testing.T) {
 	buildDirectoryCreator := mock.NewMockBuildDirectoryCreator(ctrl)
 	runner := mock.NewMockRunnerClient(ctrl)
 	clock := mock.NewMockClock(ctrl)
-	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false)
+	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false /* sharedCaches = */, nil /* temporaryDirectoryPolicy = */, nil /* stdinFile = */, nil /* workerMetadataFile = */, nil /* previousActionOutputGrafter = */, nil /* outputLogStreamer = */, nil)
 
 	// Execution should fail, as the number of nanoseconds in the
 	// timeout is not within bounds.
@@ -741,11 +776,11 @@ func TestLocalBuildExecutorInputRootIOFailureDuringExecution(t *testing.T) {
 	// Build environment.
 	buildDirectoryCreator := mock.NewMockBuildDirectoryCreator(ctrl)
 	actionDigest := digest.MustNewDigest("ubuntu1804", remoteexecution.DigestFunction_SHA256, "0000000000000000000000000000000000000000000000000000000000000001", 123)
-	buildDirectoryCreator.EXPECT().GetBuildDirectory(ctx, &actionDigest).
+	buildDirectoryCreator.EXPECT().GetBuildDirectory(gomock.Any(), &actionDigest).
 		Return(buildDirectory, nil, nil)
 	filePool := mock.NewMockFilePool(ctrl)
 	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
-	buildDirectory.EXPECT().InstallHooks(filePool, gomock.Any())
+	buildDirectory.EXPECT().InstallHooks(gomock.Any(), filePool, gomock.Any(), gomock.Any())
 
 	// Input root creation. Preserve the error logger that is
 	// provided, so that an I/O error can be triggered during the
@@ -759,7 +794,8 @@ func TestLocalBuildExecutorInputRootIOFailureDuringExecution(t *testing.T) {
 		gomock.Any(),
 		digest.MustNewDigest("ubuntu1804", remoteexecution.DigestFunction_SHA256, "0000000000000000000000000000000000000000000000000000000000000003", 345),
 		monitor,
-	).DoAndReturn(func(ctx context.Context, providedErrorLogger util.ErrorLogger, digest digest.Digest, monitor access.UnreadDirectoryMonitor) error {
+		0,
+	).DoAndReturn(func(ctx context.Context, providedErrorLogger util.ErrorLogger, digest digest.Digest, monitor access.UnreadDirectoryMonitor, depth int) error {
 		errorLogger = providedErrorLogger
 		return nil
 	})
@@ -769,6 +805,7 @@ func TestLocalBuildExecutorInputRootIOFailureDuringExecution(t *testing.T) {
 	// The build should be canceled immediately. The error should be
 	// propagated to the response.
 	runner := mock.NewMockRunnerClient(ctrl)
+	buildDirectory.EXPECT().UploadOutputsInBackground(ctx, gomock.Any()).Return(func() {})
 	runner.EXPECT().Run(gomock.Any(), &runner_pb.RunRequest{
 		Arguments:            []string{"clang"},
 		EnvironmentVariables: map[string]string{},
@@ -788,7 +825,7 @@ func TestLocalBuildExecutorInputRootIOFailureDuringExecution(t *testing.T) {
 	clock.EXPECT().NewContextWithTimeout(gomock.Any(), 15*time.Minute).DoAndReturn(func(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
 		return context.WithCancel(parent)
 	})
-	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false)
+	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false /* sharedCaches = */, nil /* temporaryDirectoryPolicy = */, nil /* stdinFile = */, nil /* workerMetadataFile = */, nil /* previousActionOutputGrafter = */, nil /* outputLogStreamer = */, nil)
 
 	metadata := make(chan *remoteworker.CurrentState_Executing, 10)
 	executeResponse := localBuildExecutor.Execute(
@@ -854,11 +891,11 @@ func TestLocalBuildExecutorTimeoutDuringExecution(t *testing.T) {
 	// Build environment.
 	buildDirectoryCreator := mock.NewMockBuildDirectoryCreator(ctrl)
 	actionDigest := digest.MustNewDigest("ubuntu1804", remoteexecution.DigestFunction_SHA256, "0000000000000000000000000000000000000000000000000000000000000001", 123)
-	buildDirectoryCreator.EXPECT().GetBuildDirectory(ctx, &actionDigest).
+	buildDirectoryCreator.EXPECT().GetBuildDirectory(gomock.Any(), &actionDigest).
 		Return(buildDirectory, nil, nil)
 	filePool := mock.NewMockFilePool(ctrl)
 	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
-	buildDirectory.EXPECT().InstallHooks(filePool, gomock.Any())
+	buildDirectory.EXPECT().InstallHooks(gomock.Any(), filePool, gomock.Any(), gomock.Any())
 
 	// Input root creation.
 	buildDirectory.EXPECT().Mkdir(path.MustNewComponent("root"), os.FileMode(0o777))
@@ -869,12 +906,14 @@ func TestLocalBuildExecutorTimeoutDuringExecution(t *testing.T) {
 		gomock.Any(),
 		digest.MustNewDigest("ubuntu1804", remoteexecution.DigestFunction_SHA256, "0000000000000000000000000000000000000000000000000000000000000003", 345),
 		monitor,
+		0,
 	).Return(nil)
 	buildDirectory.EXPECT().Mkdir(path.MustNewComponent("tmp"), os.FileMode(0o777))
 
 	// Simulate a timeout by running the command with a timeout of
 	// zero seconds. This should cause an immediate build failure.
 	runner := mock.NewMockRunnerClient(ctrl)
+	buildDirectory.EXPECT().UploadOutputsInBackground(ctx, gomock.Any()).Return(func() {})
 	runner.EXPECT().Run(gomock.Any(), &runner_pb.RunRequest{
 		Arguments:            []string{"clang"},
 		EnvironmentVariables: map[string]string{},
@@ -893,7 +932,7 @@ func TestLocalBuildExecutorTimeoutDuringExecution(t *testing.T) {
 	clock.EXPECT().NewContextWithTimeout(gomock.Any(), time.Hour).DoAndReturn(func(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
 		return context.WithTimeout(parent, 0)
 	})
-	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false)
+	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, nil, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false /* sharedCaches = */, nil /* temporaryDirectoryPolicy = */, nil /* stdinFile = */, nil /* workerMetadataFile = */, nil /* previousActionOutputGrafter = */, nil /* outputLogStreamer = */, nil)
 
 	metadata := make(chan *remoteworker.CurrentState_Executing, 10)
 	executeResponse := localBuildExecutor.Execute(
@@ -945,11 +984,11 @@ func TestLocalBuildExecutorCharacterDeviceNodeCreationFailed(t *testing.T) {
 	// Build environment.
 	buildDirectoryCreator := mock.NewMockBuildDirectoryCreator(ctrl)
 	actionDigest := digest.MustNewDigest("ubuntu1804", remoteexecution.DigestFunction_SHA256, "0000000000000000000000000000000000000000000000000000000000000001", 123)
-	buildDirectoryCreator.EXPECT().GetBuildDirectory(ctx, &actionDigest).
+	buildDirectoryCreator.EXPECT().GetBuildDirectory(gomock.Any(), &actionDigest).
 		Return(buildDirectory, nil, nil)
 	filePool := mock.NewMockFilePool(ctrl)
 	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
-	buildDirectory.EXPECT().InstallHooks(filePool, gomock.Any())
+	buildDirectory.EXPECT().InstallHooks(gomock.Any(), filePool, gomock.Any(), gomock.Any())
 
 	// Input root creation.
 	buildDirectory.EXPECT().Mkdir(path.MustNewComponent("root"), os.FileMode(0o777))
@@ -960,6 +999,7 @@ func TestLocalBuildExecutorCharacterDeviceNodeCreationFailed(t *testing.T) {
 		gomock.Any(),
 		digest.MustNewDigest("ubuntu1804", remoteexecution.DigestFunction_SHA256, "0000000000000000000000000000000000000000000000000000000000000003", 345),
 		monitor,
+		0,
 	).Return(nil)
 	inputRootDirectory.EXPECT().Mkdir(path.MustNewComponent("dev"), os.FileMode(0o777))
 	inputRootDevDirectory := mock.NewMockBuildDirectory(ctrl)
@@ -977,7 +1017,7 @@ func TestLocalBuildExecutorCharacterDeviceNodeCreationFailed(t *testing.T) {
 	inputRootCharacterDevices := map[path.Component]filesystem.DeviceNumber{
 		path.MustNewComponent("null"): filesystem.NewDeviceNumberFromMajorMinor(1, 3),
 	}
-	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, inputRootCharacterDevices, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false)
+	localBuildExecutor := builder.NewLocalBuildExecutor(contentAddressableStorage, buildDirectoryCreator, runner, clock, inputRootCharacterDevices, 10000, map[string]string{} /* forceUploadTreesAndDirectories = */, false /* sharedCaches = */, nil /* temporaryDirectoryPolicy = */, nil /* stdinFile = */, nil /* workerMetadataFile = */, nil /* previousActionOutputGrafter = */, nil /* outputLogStreamer = */, nil)
 
 	metadata := make(chan *remoteworker.CurrentState_Executing, 10)
 	executeResponse := localBuildExecutor.Execute(
@@ -66,12 +66,19 @@ func (d *naiveBuildDirectory) EnterUploadableDirectory(name path.Component) (Upl
 	return d.EnterBuildDirectory(name)
 }
 
-func (d *naiveBuildDirectory) InstallHooks(filePool re_filesystem.FilePool, errorLogger util.ErrorLogger) {
+func (d *naiveBuildDirectory) InstallHooks(ctx context.Context, filePool re_filesystem.FilePool, errorLogger util.ErrorLogger, digestFunction digest.Function) {
 	// Simply ignore the provided hooks, as POSIX offers no way to
 	// install them. This means no quota enforcement and detection
 	// of I/O errors is performed.
 }
 
+func (d *naiveBuildDirectory) UploadOutputsInBackground(ctx context.Context, outputPaths [][]path.Component) func() {
+	// POSIX offers no way to be notified of files being closed.
+	// Output files are only uploaded after the build action
+	// completes.
+	return func() {}
+}
+
 func (d *naiveBuildDirectory) mergeDirectoryContents(ctx context.Context, digest digest.Digest, inputDirectory filesystem.Directory, pathTrace *path.Trace) error {
 	// Obtain directory.
 	directory, err := d.directoryFetcher.GetDirectory(ctx, digest)
@@ -131,7 +138,7 @@ func (d *naiveBuildDirectory) mergeDirectoryContents(ctx context.Context, digest
 	return nil
 }
 
-func (d *naiveBuildDirectory) MergeDirectoryContents(ctx context.Context, errorLogger util.ErrorLogger, digest digest.Digest, monitor access.UnreadDirectoryMonitor) error {
+func (d *naiveBuildDirectory) MergeDirectoryContents(ctx context.Context, errorLogger util.ErrorLogger, digest digest.Digest, monitor access.UnreadDirectoryMonitor, depth int) error {
 	return d.mergeDirectoryContents(ctx, digest, d.DirectoryCloser, nil)
 }
 
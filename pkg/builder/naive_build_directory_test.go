@@ -102,7 +102,8 @@ func TestNaiveBuildDirectorySuccess(t *testing.T) {
 		ctx,
 		errorLogger,
 		digest.MustNewDigest("netbsd", remoteexecution.DigestFunction_SHA256, "7777777777777777777777777777777777777777777777777777777777777777", 42),
-		nil)
+		nil,
+		0)
 	require.NoError(t, err)
 }
 
@@ -124,7 +125,8 @@ func TestNaiveBuildDirectoryInputRootNotInStorage(t *testing.T) {
 		ctx,
 		errorLogger,
 		digest.MustNewDigest("netbsd", remoteexecution.DigestFunction_SHA256, "7777777777777777777777777777777777777777777777777777777777777777", 42),
-		nil)
+		nil,
+		0)
 	testutil.RequireEqualStatus(t, status.Error(codes.Internal, "Failed to obtain input directory \".\": Storage is offline"), err)
 }
 
@@ -170,7 +172,8 @@ func TestNaiveBuildDirectoryMissingInputDirectoryDigest(t *testing.T) {
 		ctx,
 		errorLogger,
 		digest.MustNewDigest("netbsd", remoteexecution.DigestFunction_SHA256, "7777777777777777777777777777777777777777777777777777777777777777", 42),
-		nil)
+		nil,
+		0)
 	testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Failed to extract digest for input directory \"Hello/World\": No digest provided"), err)
 }
 
@@ -221,7 +224,8 @@ func TestNaiveBuildDirectoryDirectoryCreationFailure(t *testing.T) {
 		ctx,
 		errorLogger,
 		digest.MustNewDigest("netbsd", remoteexecution.DigestFunction_SHA256, "7777777777777777777777777777777777777777777777777777777777777777", 42),
-		nil)
+		nil,
+		0)
 	testutil.RequireEqualStatus(t, status.Error(codes.DataLoss, "Failed to create input directory \"Hello/World\": Disk on fire"), err)
 }
 
@@ -273,7 +277,8 @@ func TestNaiveBuildDirectoryDirectoryEnterDirectoryFailure(t *testing.T) {
 		ctx,
 		errorLogger,
 		digest.MustNewDigest("netbsd", remoteexecution.DigestFunction_SHA256, "7777777777777777777777777777777777777777777777777777777777777777", 42),
-		nil)
+		nil,
+		0)
 	testutil.RequireEqualStatus(t, status.Error(codes.PermissionDenied, "Failed to enter input directory \"Hello/World\": Thou shalt not pass!"), err)
 }
 
@@ -319,7 +324,8 @@ func TestNaiveBuildDirectoryMissingInputFileDigest(t *testing.T) {
 		ctx,
 		errorLogger,
 		digest.MustNewDigest("netbsd", remoteexecution.DigestFunction_SHA256, "7777777777777777777777777777777777777777777777777777777777777777", 42),
-		nil)
+		nil,
+		0)
 	testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Failed to extract digest for input file \"Hello/World\": No digest provided"), err)
 }
 
@@ -375,7 +381,8 @@ func TestNaiveBuildDirectoryFileCreationFailure(t *testing.T) {
 		ctx,
 		errorLogger,
 		digest.MustNewDigest("netbsd", remoteexecution.DigestFunction_SHA256, "7777777777777777777777777777777777777777777777777777777777777777", 42),
-		nil)
+		nil,
+		0)
 	testutil.RequireEqualStatus(t, status.Error(codes.DataLoss, "Failed to obtain input file \"Hello/World\": Disk on fire"), err)
 }
 
@@ -423,7 +430,8 @@ func TestNaiveBuildDirectorySymlinkCreationFailure(t *testing.T) {
 		ctx,
 		errorLogger,
 		digest.MustNewDigest("netbsd", remoteexecution.DigestFunction_SHA256, "7777777777777777777777777777777777777777777777777777777777777777", 42),
-		nil)
+		nil,
+		0)
 	testutil.RequireEqualStatus(t, status.Error(codes.Unimplemented, "Failed to create input symlink \"Hello/World\": This filesystem does not support symbolic links"), err)
 }
 
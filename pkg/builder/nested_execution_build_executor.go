@@ -0,0 +1,338 @@
+package builder
+
+import (
+	"context"
+	"crypto/subtle"
+	"io"
+	"strconv"
+	"sync"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// NestedExecutionTokenMetadataKey is the name of the gRPC metadata
+// header through which workers provide the scheduler the nested
+// execution token of an action, when submitting sub-actions on its
+// behalf.
+const NestedExecutionTokenMetadataKey = "build.buildbarn.nested-execution-token"
+
+// nestedExecutionParent contains the properties of the action that is
+// currently running on a worker thread, which are inherited by the
+// sub-actions it submits.
+type nestedExecutionParent struct {
+	context         context.Context
+	token           string
+	schedulerToken  string
+	digestFunction  digest.Function
+	requestMetadata []byte
+	nestingDepth    int
+
+	// Protected by NestedExecutionProxy.lock.
+	subActionsInFlight  int
+	subActionsSubmitted int
+}
+
+// NestedExecutionProxy is an implementation of the Execution service
+// of the Remote Execution API that is exposed to actions running on a
+// single worker thread. It forwards requests to the scheduler, so that
+// actions may submit sub-actions.
+//
+// Requests are only accepted if they carry the token of the action
+// that is currently running, provided as a bearer token in the
+// "authorization" header. This prevents other processes on the system
+// from submitting sub-actions on behalf of the action.
+//
+// Sub-actions inherit the instance name, digest function and request
+// metadata of the action that submits them. They are submitted with
+// the nested execution token that the scheduler provided for the
+// action, causing the scheduler to authorize them using the
+// credentials of the client that submitted the action, as opposed to
+// those of the worker. They are cancelled when the action that
+// submitted them completes.
+//
+// Actions may only submit sub-actions if the scheduler provided a
+// nested execution token, meaning that its authorization policy
+// permits the client to do so. In addition to that, they need to have
+// a platform property containing a positive nesting depth. Sub-actions
+// must have a nesting depth that is lower than that of their parent,
+// where the absence of the platform property corresponds to a nesting
+// depth of zero. This bounds the depth of the tree of sub-actions. It also ensures that
+// sub-actions are placed in different platform queues than their
+// parents, meaning that parents waiting for the completion of their
+// sub-actions cannot occupy all workers capable of executing them.
+type NestedExecutionProxy struct {
+	executionClient             remoteexecution.ExecutionClient
+	contentAddressableStorage   blobstore.BlobAccess
+	maximumMessageSizeBytes     int
+	platformPropertyName        string
+	maximumConcurrentSubActions int
+	maximumSubActionsPerAction  int
+
+	lock   sync.Mutex
+	parent *nestedExecutionParent
+}
+
+// NewNestedExecutionProxy creates a NestedExecutionProxy that forwards
+// requests to a scheduler. Actions of sub-actions are loaded from the
+// Content Addressable Storage to validate their nesting depth. The
+// number of sub-actions that an action may have in flight, and the
+// total number of sub-actions it may submit are limited.
+func NewNestedExecutionProxy(executionClient remoteexecution.ExecutionClient, contentAddressableStorage blobstore.BlobAccess, maximumMessageSizeBytes int, platformPropertyName string, maximumConcurrentSubActions, maximumSubActionsPerAction int) *NestedExecutionProxy {
+	return &NestedExecutionProxy{
+		executionClient:             executionClient,
+		contentAddressableStorage:   contentAddressableStorage,
+		maximumMessageSizeBytes:     maximumMessageSizeBytes,
+		platformPropertyName:        platformPropertyName,
+		maximumConcurrentSubActions: maximumConcurrentSubActions,
+		maximumSubActionsPerAction:  maximumSubActionsPerAction,
+	}
+}
+
+// getNestingDepth returns the nesting depth of an action, which is
+// stored in a platform property. Actions that lack the platform
+// property have a nesting depth of zero.
+func (p *NestedExecutionProxy) getNestingDepth(platform *remoteexecution.Platform) (int, error) {
+	for _, property := range platform.GetProperties() {
+		if property.Name == p.platformPropertyName {
+			nestingDepth, err := strconv.Atoi(property.Value)
+			if err != nil || nestingDepth < 0 {
+				return 0, status.Errorf(codes.InvalidArgument, "Platform property %#v has value %#v, while a non-negative nesting depth was expected", property.Name, property.Value)
+			}
+			return nestingDepth, nil
+		}
+	}
+	return 0, nil
+}
+
+func (p *NestedExecutionProxy) setParent(parent *nestedExecutionParent) {
+	p.lock.Lock()
+	p.parent = parent
+	p.lock.Unlock()
+}
+
+// acquire a slot for forwarding a request on behalf of the action that
+// is currently running. It returns a context that is cancelled when
+// either the client disconnects or the action completes. If
+// isSubmission is set, the request counts towards the total number of
+// sub-actions the action may submit.
+func (p *NestedExecutionProxy) acquire(ctx context.Context, isSubmission bool) (context.Context, *nestedExecutionParent, func(), error) {
+	var authorization []string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		authorization = md.Get("authorization")
+	}
+
+	p.lock.Lock()
+	parent := p.parent
+	if parent == nil {
+		p.lock.Unlock()
+		return nil, nil, nil, status.Error(codes.FailedPrecondition, "No action is running that is permitted to submit sub-actions")
+	}
+	if len(authorization) != 1 || subtle.ConstantTimeCompare([]byte(authorization[0]), []byte("Bearer "+parent.token)) != 1 {
+		p.lock.Unlock()
+		return nil, nil, nil, status.Error(codes.Unauthenticated, "Request does not contain the token of the action that is currently running")
+	}
+	if parent.subActionsInFlight >= p.maximumConcurrentSubActions {
+		p.lock.Unlock()
+		return nil, nil, nil, status.Errorf(codes.ResourceExhausted, "Action already has %d sub-actions in flight, which is the maximum permitted", parent.subActionsInFlight)
+	}
+	if isSubmission {
+		if parent.subActionsSubmitted >= p.maximumSubActionsPerAction {
+			p.lock.Unlock()
+			return nil, nil, nil, status.Errorf(codes.ResourceExhausted, "Action already submitted %d sub-actions, which is the maximum permitted", parent.subActionsSubmitted)
+		}
+		parent.subActionsSubmitted++
+	}
+	parent.subActionsInFlight++
+	p.lock.Unlock()
+
+	ctxWithCancel, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(parent.context, cancel)
+	ctxWithCancel = metadata.AppendToOutgoingContext(
+		ctxWithCancel,
+		NestedExecutionTokenMetadataKey,
+		parent.schedulerToken)
+	if parent.requestMetadata != nil {
+		ctxWithCancel = metadata.AppendToOutgoingContext(
+			ctxWithCancel,
+			"build.bazel.remote.execution.v2.requestmetadata-bin",
+			string(parent.requestMetadata))
+	}
+	return ctxWithCancel, parent, func() {
+		stop()
+		cancel()
+		p.lock.Lock()
+		parent.subActionsInFlight--
+		p.lock.Unlock()
+	}, nil
+}
+
+// operationStream is the subset of the client side of the Execute()
+// and WaitExecution() streams that is used for forwarding operations.
+type operationStream interface {
+	Recv() (*longrunningpb.Operation, error)
+}
+
+// operationSink is the subset of the server side of the Execute() and
+// WaitExecution() streams that is used for forwarding operations.
+type operationSink interface {
+	Send(*longrunningpb.Operation) error
+}
+
+func forwardOperations(parent *nestedExecutionParent, client operationStream, server operationSink) error {
+	for {
+		operation, err := client.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			if parent.context.Err() != nil {
+				return status.Error(codes.Canceled, "Parent action completed")
+			}
+			return err
+		}
+		if err := server.Send(operation); err != nil {
+			return err
+		}
+	}
+}
+
+// Execute a sub-action on behalf of the action that is currently
+// running.
+func (p *NestedExecutionProxy) Execute(in *remoteexecution.ExecuteRequest, out remoteexecution.Execution_ExecuteServer) error {
+	ctx, parent, release, err := p.acquire(out.Context(), true)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	digestFunction := parent.digestFunction.GetEnumValue()
+	if in.DigestFunction != remoteexecution.DigestFunction_UNKNOWN && in.DigestFunction != digestFunction {
+		return status.Errorf(codes.InvalidArgument, "Sub-actions must use digest function %s, as used by the parent action", digestFunction)
+	}
+
+	// Bound the depth of the tree of sub-actions.
+	actionDigest, err := parent.digestFunction.NewDigestFromProto(in.ActionDigest)
+	if err != nil {
+		return util.StatusWrap(err, "Failed to extract digest for action")
+	}
+	actionMessage, err := p.contentAddressableStorage.Get(ctx, actionDigest).ToProto(&remoteexecution.Action{}, p.maximumMessageSizeBytes)
+	if err != nil {
+		return util.StatusWrap(err, "Failed to obtain action")
+	}
+	nestingDepth, err := p.getNestingDepth(actionMessage.(*remoteexecution.Action).Platform)
+	if err != nil {
+		return util.StatusWrap(err, "Invalid sub-action")
+	}
+	if nestingDepth >= parent.nestingDepth {
+		return status.Errorf(codes.PermissionDenied, "Sub-action has nesting depth %d, while the parent action has nesting depth %d", nestingDepth, parent.nestingDepth)
+	}
+
+	request := proto.Clone(in).(*remoteexecution.ExecuteRequest)
+	request.InstanceName = parent.digestFunction.GetInstanceName().String()
+	request.DigestFunction = digestFunction
+
+	client, err := p.executionClient.Execute(ctx, request)
+	if err != nil {
+		return util.StatusWrap(err, "Failed to submit sub-action")
+	}
+	return forwardOperations(parent, client, out)
+}
+
+// WaitExecution waits for the completion of a sub-action that was
+// submitted previously.
+func (p *NestedExecutionProxy) WaitExecution(in *remoteexecution.WaitExecutionRequest, out remoteexecution.Execution_WaitExecutionServer) error {
+	ctx, parent, release, err := p.acquire(out.Context(), false)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	client, err := p.executionClient.WaitExecution(ctx, in)
+	if err != nil {
+		return util.StatusWrap(err, "Failed to wait for sub-action")
+	}
+	return forwardOperations(parent, client, out)
+}
+
+type nestedExecutionBuildExecutor struct {
+	BuildExecutor
+	proxy                        *NestedExecutionProxy
+	uuidGenerator                util.UUIDGenerator
+	tokenEnvironmentVariableName string
+}
+
+// NewNestedExecutionBuildExecutor creates a decorator for BuildExecutor
+// that permits actions to submit sub-actions through a
+// NestedExecutionProxy while they are running. Only actions for which
+// the scheduler provided a nested execution token and that have a
+// positive nesting depth are permitted to do so.
+//
+// Every action is assigned a randomly generated token, which is
+// provided to the action through an environment variable. The action
+// needs to provide this token when calling into the
+// NestedExecutionProxy.
+func NewNestedExecutionBuildExecutor(base BuildExecutor, proxy *NestedExecutionProxy, uuidGenerator util.UUIDGenerator, tokenEnvironmentVariableName string) BuildExecutor {
+	return &nestedExecutionBuildExecutor{
+		BuildExecutor:                base,
+		proxy:                        proxy,
+		uuidGenerator:                uuidGenerator,
+		tokenEnvironmentVariableName: tokenEnvironmentVariableName,
+	}
+}
+
+func (be *nestedExecutionBuildExecutor) Execute(ctx context.Context, filePool re_filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+	nestingDepth, err := be.proxy.getNestingDepth(request.Action.GetPlatform())
+	if err != nil || nestingDepth == 0 || request.NestedExecutionToken == "" {
+		return be.BuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates)
+	}
+
+	token, err := be.uuidGenerator()
+	if err != nil {
+		response := NewDefaultExecuteResponse(request)
+		attachErrorToExecuteResponse(response, util.StatusWrapWithCode(err, codes.Internal, "Failed to generate nested execution token"))
+		return response
+	}
+
+	// Let sub-actions inherit the request metadata that the client
+	// provided when submitting this action.
+	var requestMetadata []byte
+	for _, auxiliaryMetadata := range request.AuxiliaryMetadata {
+		if auxiliaryMetadata.MessageIs(&remoteexecution.RequestMetadata{}) {
+			requestMetadata = auxiliaryMetadata.Value
+			break
+		}
+	}
+
+	ctxWithCancel, cancel := context.WithCancel(ctx)
+	be.proxy.setParent(&nestedExecutionParent{
+		context:         ctxWithCancel,
+		token:           token.String(),
+		schedulerToken:  request.NestedExecutionToken,
+		digestFunction:  digestFunction,
+		requestMetadata: requestMetadata,
+		nestingDepth:    nestingDepth,
+	})
+	response := be.BuildExecutor.Execute(
+		withActionEnvironmentVariables(ctx, map[string]string{
+			be.tokenEnvironmentVariableName: token.String(),
+		}),
+		filePool,
+		monitor,
+		digestFunction,
+		request,
+		executionStateUpdates)
+	be.proxy.setParent(nil)
+	cancel()
+	return response
+}
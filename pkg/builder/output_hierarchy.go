@@ -58,6 +58,20 @@ func newOutputDirectory() *outputNode {
 	}
 }
 
+// appendOutputPaths is recursively invoked by
+// OutputHierarchy.GetOutputPaths() to obtain the paths of all outputs
+// of the build action.
+func (on *outputNode) appendOutputPaths(dPath []path.Component, outputPaths *[][]path.Component) {
+	for _, m := range []map[path.Component][]string{on.directoriesToUpload, on.filesToUpload, on.pathsToUpload} {
+		for _, name := range sortToUpload(m) {
+			*outputPaths = append(*outputPaths, append(append([]path.Component(nil), dPath...), name))
+		}
+	}
+	for _, name := range on.getSubdirectoryNames() {
+		on.subdirectories[name].appendOutputPaths(append(dPath, name), outputPaths)
+	}
+}
+
 // CreateParentDirectories is recursive invoked by
 // OutputHierarchy.CreateParentDirectories() to create parent
 // directories of locations where output directories and files are
@@ -222,7 +236,7 @@ func (s *uploadOutputsState) uploadOutputDirectoryEntered(d UploadableDirectory,
 		uploadOutputsState: s,
 		directoriesSeen:    map[digest.Digest][]byte{},
 	}
-	if rootDirectoryDigest, err := dState.uploadDirectory(d, dPath); err == nil {
+	if rootDirectoryDigest, err := dState.uploadDirectoryOrSnapshot(d, dPath); err == nil {
 		// Approximate the size of the resulting Tree object, so
 		// that we may allocate all space at once.
 		directories := dState.directories
@@ -396,15 +410,35 @@ func (s *uploadOutputDirectoryState) uploadDirectory(d UploadableDirectory, dPat
 	if err != nil {
 		return digest.BadDigest, util.StatusWrapf(err, "Failed to marshal output directory %#v", dPath.String())
 	}
-
-	// There is no need to make the directory part of the Tree if we
-	// have seen an identical directory previously.
 	digest := s.computeDigest(data)
-	if _, ok := s.directoriesSeen[digest]; !ok {
+	s.addDirectory(digest, data)
+	return digest, nil
+}
+
+// UploadDirectoryOrSnapshot is called to upload an output directory.
+// If the directory is capable of creating snapshots of its contents,
+// the snapshot is uploaded. This prevents the need for traversing the
+// directory and rereading files whose digests are already known.
+func (s *uploadOutputDirectoryState) uploadDirectoryOrSnapshot(d UploadableDirectory, dPath *path.Trace) (digest.Digest, error) {
+	sd, ok := d.(SnapshotUploadableDirectory)
+	if !ok {
+		return s.uploadDirectory(d, dPath)
+	}
+	rootDirectoryDigest, err := sd.UploadSnapshot(s.context, s.digestFunction, s.addDirectory)
+	if err != nil {
+		return digest.BadDigest, util.StatusWrapf(err, "Failed to store output directory %#v", dPath.String())
+	}
+	return rootDirectoryDigest, nil
+}
+
+// AddDirectory adds a directory to the Tree that is being constructed.
+// There is no need to make the directory part of the Tree if we have
+// seen an identical directory previously.
+func (s *uploadOutputDirectoryState) addDirectory(directoryDigest digest.Digest, data []byte) {
+	if _, ok := s.directoriesSeen[directoryDigest]; !ok {
 		s.directories = append(s.directories, data)
-		s.directoriesSeen[digest] = data
+		s.directoriesSeen[directoryDigest] = data
 	}
-	return digest, nil
 }
 
 // outputNodePath is an implementation of path.ComponentWalker that is
@@ -549,6 +583,18 @@ func (oh *OutputHierarchy) CreateParentDirectories(d ParentPopulatableDirectory)
 	return oh.root.createParentDirectories(d, nil)
 }
 
+// GetOutputPaths returns the paths of all outputs of the build action,
+// relative to the input root directory. An empty path is returned if
+// the input root directory itself is an output directory.
+func (oh *OutputHierarchy) GetOutputPaths() [][]path.Component {
+	if len(oh.rootsToUpload) > 0 {
+		return [][]path.Component{{}}
+	}
+	var outputPaths [][]path.Component
+	oh.root.appendOutputPaths(nil, &outputPaths)
+	return outputPaths
+}
+
 // UploadOutputs uploads outputs of the build action into the CAS. This
 // function is called after executing the build action.
 func (oh *OutputHierarchy) UploadOutputs(ctx context.Context, d UploadableDirectory, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function, actionResult *remoteexecution.ActionResult, forceUploadTreesAndDirectories bool) error {
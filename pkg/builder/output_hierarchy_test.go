@@ -235,6 +235,34 @@ func TestOutputHierarchyCreateParentDirectories(t *testing.T) {
 	})
 }
 
+func TestOutputHierarchyGetOutputPaths(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Paths of outputs should be relative to the input
+		// root directory, as opposed to the working directory.
+		oh, err := builder.NewOutputHierarchy(&remoteexecution.Command{
+			WorkingDirectory:  "foo",
+			OutputDirectories: []string{"bar"},
+			OutputFiles:       []string{"../baz/qux"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, [][]path.Component{
+			{path.MustNewComponent("baz"), path.MustNewComponent("qux")},
+			{path.MustNewComponent("foo"), path.MustNewComponent("bar")},
+		}, oh.GetOutputPaths())
+	})
+
+	t.Run("RootDirectory", func(t *testing.T) {
+		// If the input root directory is an output directory,
+		// all files in the input root directory are outputs.
+		oh, err := builder.NewOutputHierarchy(&remoteexecution.Command{
+			WorkingDirectory:  "foo",
+			OutputDirectories: []string{".."},
+		})
+		require.NoError(t, err)
+		require.Equal(t, [][]path.Component{{}}, oh.GetOutputPaths())
+	})
+}
+
 func TestOutputHierarchyUploadOutputs(t *testing.T) {
 	ctrl, ctx := gomock.WithContext(context.Background(), t)
 
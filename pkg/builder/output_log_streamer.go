@@ -0,0 +1,151 @@
+package builder
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	remotelogstream "github.com/bazelbuild/remote-apis/build/bazel/remote/logstream/v1"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/genproto/googleapis/bytestream"
+)
+
+// outputLogStreamerChunkSizeBytes is the maximum amount of data that
+// is sent as part of a single WriteRequest.
+const outputLogStreamerChunkSizeBytes = 64 * 1024
+
+// OutputLogStreamer streams the contents of files in a build
+// directory to LogStreams while an action is running. This is used to
+// let clients observe the standard output and error of actions before
+// they complete.
+type OutputLogStreamer struct {
+	logStreamClient  remotelogstream.LogStreamServiceClient
+	byteStreamClient bytestream.ByteStreamClient
+	clock            clock.Clock
+	pollInterval     time.Duration
+}
+
+// NewOutputLogStreamer creates an OutputLogStreamer that creates
+// LogStreams through the LogStream service, and writes to them through
+// the ByteStream service. Files are checked for new data at a fixed
+// interval.
+func NewOutputLogStreamer(logStreamClient remotelogstream.LogStreamServiceClient, byteStreamClient bytestream.ByteStreamClient, clock clock.Clock, pollInterval time.Duration) *OutputLogStreamer {
+	return &OutputLogStreamer{
+		logStreamClient:  logStreamClient,
+		byteStreamClient: byteStreamClient,
+		clock:            clock,
+		pollInterval:     pollInterval,
+	}
+}
+
+// Start creating LogStreams for a list of files in a build directory,
+// and copying any data appended to these files into them. The names of
+// the LogStreams are returned in the same order as the files. The
+// returned function needs to be called after the command has finished,
+// causing the remaining data to be written and the LogStreams to be
+// finalized.
+//
+// As streaming output is merely a convenience, errors are logged
+// instead of causing the action to fail. Files for which no LogStream
+// could be created have an empty name.
+func (s *OutputLogStreamer) Start(ctx context.Context, parent string, directory BuildDirectory, names []path.Component) ([]string, func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	streamNames := make([]string, len(names))
+	for i, name := range names {
+		logStream, err := s.logStreamClient.CreateLogStream(ctx, &remotelogstream.CreateLogStreamRequest{
+			Parent: parent,
+		})
+		if err != nil {
+			log.Printf("Failed to create log stream for %#v: %s", name.String(), err)
+			continue
+		}
+		streamNames[i] = logStream.Name
+
+		wg.Add(1)
+		go func(name path.Component, writeResourceName string) {
+			defer wg.Done()
+			if err := s.stream(ctx, directory, name, writeResourceName, done); err != nil {
+				log.Printf("Failed to stream %#v to log stream %#v: %s", name.String(), writeResourceName, err)
+			}
+		}(name, logStream.WriteResourceName)
+	}
+	return streamNames, func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+func (s *OutputLogStreamer) stream(ctx context.Context, directory BuildDirectory, name path.Component, writeResourceName string, done <-chan struct{}) error {
+	client, err := s.byteStreamClient.Write(ctx)
+	if err != nil {
+		return util.StatusWrap(err, "Failed to start writing")
+	}
+
+	var offset int64
+	buf := make([]byte, outputLogStreamerChunkSizeBytes)
+	for {
+		// Wait for the next poll, or for the command to finish.
+		finished := false
+		timer, timerChannel := s.clock.NewTimer(s.pollInterval)
+		select {
+		case <-timerChannel:
+		case <-done:
+			timer.Stop()
+			finished = true
+		}
+
+		// Copy any data that has been appended to the file since
+		// the previous poll. The file may not exist until the
+		// command has been started.
+		if f, err := directory.OpenRead(name); err == nil {
+			for {
+				n, readErr := f.ReadAt(buf, offset)
+				if n > 0 {
+					if err := client.Send(&bytestream.WriteRequest{
+						ResourceName: writeResourceName,
+						WriteOffset:  offset,
+						Data:         buf[:n],
+					}); err != nil {
+						f.Close()
+						_, err := client.CloseAndRecv()
+						return util.StatusWrap(err, "Failed to write data")
+					}
+					offset += int64(n)
+				}
+				if readErr == io.EOF || (readErr == nil && n == 0) {
+					break
+				} else if readErr != nil {
+					f.Close()
+					client.CloseSend()
+					return util.StatusWrapf(readErr, "Failed to read at offset %d", offset)
+				}
+			}
+			f.Close()
+		} else if !os.IsNotExist(err) {
+			client.CloseSend()
+			return util.StatusWrap(err, "Failed to open file")
+		}
+
+		if finished {
+			if err := client.Send(&bytestream.WriteRequest{
+				ResourceName: writeResourceName,
+				WriteOffset:  offset,
+				FinishWrite:  true,
+			}); err != nil {
+				_, err := client.CloseAndRecv()
+				return util.StatusWrap(err, "Failed to finalize")
+			}
+			if _, err := client.CloseAndRecv(); err != nil {
+				return util.StatusWrap(err, "Failed to finalize")
+			}
+			return nil
+		}
+	}
+}
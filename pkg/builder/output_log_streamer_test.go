@@ -0,0 +1,96 @@
+package builder_test
+
+import (
+	"context"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	remotelogstream "github.com/bazelbuild/remote-apis/build/bazel/remote/logstream/v1"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/genproto/googleapis/bytestream"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestOutputLogStreamer(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	logStreamClient := mock.NewMockLogStreamServiceClient(ctrl)
+	byteStreamClient := mock.NewMockByteStreamClient(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	outputLogStreamer := builder.NewOutputLogStreamer(logStreamClient, byteStreamClient, clock, time.Second)
+	buildDirectory := mock.NewMockBuildDirectory(ctrl)
+
+	t.Run("CreationFailure", func(t *testing.T) {
+		// Failing to create a LogStream should not cause the
+		// action to fail. The stream name is simply left empty.
+		logStreamClient.EXPECT().CreateLogStream(ctx, testutil.EqProto(t, &remotelogstream.CreateLogStreamRequest{
+			Parent: "hello/actions/8b1a9953c4611296a827abf8c47804d7-123",
+		})).Return(nil, status.Error(codes.ResourceExhausted, "Cannot create more than 10 log streams"))
+
+		streamNames, stop := outputLogStreamer.Start(
+			ctx,
+			"hello/actions/8b1a9953c4611296a827abf8c47804d7-123",
+			buildDirectory,
+			[]path.Component{path.MustNewComponent("stdout")})
+		require.Equal(t, []string{""}, streamNames)
+		stop()
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		logStreamClient.EXPECT().CreateLogStream(ctx, testutil.EqProto(t, &remotelogstream.CreateLogStreamRequest{
+			Parent: "hello/actions/8b1a9953c4611296a827abf8c47804d7-123",
+		})).Return(&remotelogstream.LogStream{
+			Name:              "hello/actions/8b1a9953c4611296a827abf8c47804d7-123/logstreams/3c7bb9a5-3ad8-40b7-bd36-66c7e3c2b5b6",
+			WriteResourceName: "hello/actions/8b1a9953c4611296a827abf8c47804d7-123/logstreams/3c7bb9a5-3ad8-40b7-bd36-66c7e3c2b5b6/e8d7e1f9-4c44-4e1f-a8a3-8fc1d3d3a83e",
+		}, nil)
+		writeClient := mock.NewMockByteStream_WriteClient(ctrl)
+		byteStreamClient.EXPECT().Write(ctx).Return(writeClient, nil)
+
+		// During the first poll the command has not yet created
+		// its output file.
+		timer1 := mock.NewMockTimer(ctrl)
+		timerChannel1 := make(chan time.Time)
+		clock.EXPECT().NewTimer(time.Second).Return(timer1, timerChannel1)
+		buildDirectory.EXPECT().OpenRead(path.MustNewComponent("stdout")).Return(nil, syscall.ENOENT)
+
+		// Once the command has finished, all remaining data
+		// should be written, followed by finalizing the write.
+		timer2 := mock.NewMockTimer(ctrl)
+		clock.EXPECT().NewTimer(time.Second).Return(timer2, nil)
+		timer2.EXPECT().Stop().Return(true)
+		fileReader := mock.NewMockFileReader(ctrl)
+		buildDirectory.EXPECT().OpenRead(path.MustNewComponent("stdout")).Return(fileReader, nil)
+		fileReader.EXPECT().ReadAt(gomock.Any(), int64(0)).DoAndReturn(func(p []byte, off int64) (int, error) {
+			return copy(p, "Hello"), io.EOF
+		})
+		fileReader.EXPECT().Close()
+		writeClient.EXPECT().Send(testutil.EqProto(t, &bytestream.WriteRequest{
+			ResourceName: "hello/actions/8b1a9953c4611296a827abf8c47804d7-123/logstreams/3c7bb9a5-3ad8-40b7-bd36-66c7e3c2b5b6/e8d7e1f9-4c44-4e1f-a8a3-8fc1d3d3a83e",
+			Data:         []byte("Hello"),
+		}))
+		writeClient.EXPECT().Send(testutil.EqProto(t, &bytestream.WriteRequest{
+			ResourceName: "hello/actions/8b1a9953c4611296a827abf8c47804d7-123/logstreams/3c7bb9a5-3ad8-40b7-bd36-66c7e3c2b5b6/e8d7e1f9-4c44-4e1f-a8a3-8fc1d3d3a83e",
+			WriteOffset:  5,
+			FinishWrite:  true,
+		}))
+		writeClient.EXPECT().CloseAndRecv().Return(&bytestream.WriteResponse{CommittedSize: 5}, nil)
+
+		streamNames, stop := outputLogStreamer.Start(
+			ctx,
+			"hello/actions/8b1a9953c4611296a827abf8c47804d7-123",
+			buildDirectory,
+			[]path.Component{path.MustNewComponent("stdout")})
+		require.Equal(t, []string{"hello/actions/8b1a9953c4611296a827abf8c47804d7-123/logstreams/3c7bb9a5-3ad8-40b7-bd36-66c7e3c2b5b6"}, streamNames)
+		timerChannel1 <- time.Unix(1000, 0)
+		stop()
+	})
+}
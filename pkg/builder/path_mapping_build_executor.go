@@ -0,0 +1,602 @@
+package builder
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	pathMappingBuildExecutorPrometheusMetrics sync.Once
+
+	pathMappingBuildExecutorMappingsSkipped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "buildbarn",
+		Subsystem: "builder",
+		Name:      "path_mapping_build_executor_mappings_skipped_total",
+		Help:      "Number of times a path mapping could not be applied to an action, causing it to reference configuration-specific paths.",
+	},
+		[]string{"reason"},
+	)
+	pathMappingBuildExecutorMappingsSkippedWorkingDirectory   = pathMappingBuildExecutorMappingsSkipped.WithLabelValues("WorkingDirectory")
+	pathMappingBuildExecutorMappingsSkippedConflictingInputs  = pathMappingBuildExecutorMappingsSkipped.WithLabelValues("ConflictingInputs")
+	pathMappingBuildExecutorMappingsSkippedConflictingOutputs = pathMappingBuildExecutorMappingsSkipped.WithLabelValues("ConflictingOutputs")
+)
+
+// PathMapping describes a directory in the input root whose
+// subdirectories are named after the configuration for which actions
+// are executed (e.g., "bazel-out/k8-fastbuild" and
+// "bazel-out/k8-opt-exec"). PathMappingBuildExecutor merges such
+// subdirectories into a single one having a canonical name (e.g.,
+// "bazel-out/cfg").
+type PathMapping struct {
+	// The path of the directory relative to the input root, using
+	// slashes as separators (e.g., "bazel-out").
+	Directory string
+	// The name of the subdirectory into which configuration-specific
+	// subdirectories are merged (e.g., "cfg").
+	CanonicalName string
+}
+
+type pathMappingBuildExecutor struct {
+	BuildExecutor
+	contentAddressableStorage blobstore.BlobAccess
+	actionCache               blobstore.BlobAccess
+	maximumMessageSizeBytes   int
+	pathMappings              []PathMapping
+}
+
+// NewPathMappingBuildExecutor creates a decorator for BuildExecutor
+// that strips configuration names from paths in the input root, the
+// command's arguments, environment variables and output paths prior to
+// execution, similar to Bazel's --experimental_output_paths=strip. Paths
+// in the resulting ActionResult are translated back, so that clients
+// remain unaware of this rewriting.
+//
+// As the rewritten Action no longer contains any configuration names,
+// the same action built for different configurations yields the same
+// action digest. The result of the rewritten Action is stored in the
+// Action Cache, so that builds for other configurations may reuse it.
+// This is only correct if the outputs of an action do not depend on the
+// configuration in any way other than through its inputs.
+//
+// Actions commonly reference multiple configurations at once (e.g.,
+// tools built for the execution platform). The contents of all
+// configuration-specific directories are therefore merged. If this
+// causes inputs or outputs to collide, the PathMapping is not applied
+// and the action references the original paths.
+func NewPathMappingBuildExecutor(base BuildExecutor, contentAddressableStorage, actionCache blobstore.BlobAccess, maximumMessageSizeBytes int, pathMappings []PathMapping) BuildExecutor {
+	pathMappingBuildExecutorPrometheusMetrics.Do(func() {
+		prometheus.MustRegister(pathMappingBuildExecutorMappingsSkipped)
+	})
+
+	return &pathMappingBuildExecutor{
+		BuildExecutor:             base,
+		contentAddressableStorage: contentAddressableStorage,
+		actionCache:               actionCache,
+		maximumMessageSizeBytes:   maximumMessageSizeBytes,
+		pathMappings:              pathMappings,
+	}
+}
+
+// appliedPathMapping contains the prefixes of paths that were rewritten
+// by applying a PathMapping to an action.
+type appliedPathMapping struct {
+	originalPrefixes []string
+	canonicalPrefix  string
+}
+
+func (m *appliedPathMapping) mapPath(p string) string {
+	for _, originalPrefix := range m.originalPrefixes {
+		if strings.HasPrefix(p, originalPrefix) {
+			return m.canonicalPrefix + p[len(originalPrefix):]
+		}
+	}
+	return p
+}
+
+func (m *appliedPathMapping) mapPaths(paths []string) []string {
+	mappedPaths := make([]string, 0, len(paths))
+	for _, p := range paths {
+		mappedPaths = append(mappedPaths, m.mapPath(p))
+	}
+	sort.Strings(mappedPaths)
+	return mappedPaths
+}
+
+func (m *appliedPathMapping) mapCommand(command *remoteexecution.Command) {
+	replacements := make([]string, 0, 2*len(m.originalPrefixes))
+	for _, originalPrefix := range m.originalPrefixes {
+		replacements = append(replacements, originalPrefix, m.canonicalPrefix)
+	}
+	replacer := strings.NewReplacer(replacements...)
+	for i, argument := range command.Arguments {
+		command.Arguments[i] = replacer.Replace(argument)
+	}
+	for _, environmentVariable := range command.EnvironmentVariables {
+		environmentVariable.Value = replacer.Replace(environmentVariable.Value)
+	}
+	command.OutputFiles = m.mapPaths(command.OutputFiles)
+	command.OutputDirectories = m.mapPaths(command.OutputDirectories)
+	command.OutputPaths = m.mapPaths(command.OutputPaths)
+}
+
+// unmapActionResult translates the paths of outputs in an ActionResult
+// back to the ones declared in the original Command.
+func unmapActionResult(actionResult *remoteexecution.ActionResult, originalOutputPaths map[string]string) {
+	unmapPath := func(p string) string {
+		if originalPath, ok := originalOutputPaths[p]; ok {
+			return originalPath
+		}
+		return p
+	}
+	for _, outputFile := range actionResult.OutputFiles {
+		outputFile.Path = unmapPath(outputFile.Path)
+	}
+	for _, outputDirectory := range actionResult.OutputDirectories {
+		outputDirectory.Path = unmapPath(outputDirectory.Path)
+	}
+	for _, outputSymlinks := range [][]*remoteexecution.OutputSymlink{
+		actionResult.OutputSymlinks,
+		actionResult.OutputFileSymlinks,
+		actionResult.OutputDirectorySymlinks,
+	} {
+		for _, outputSymlink := range outputSymlinks {
+			outputSymlink.Path = unmapPath(outputSymlink.Path)
+		}
+	}
+}
+
+func (be *pathMappingBuildExecutor) getDirectory(ctx context.Context, directoryDigest digest.Digest) (*remoteexecution.Directory, error) {
+	directoryMessage, err := be.contentAddressableStorage.Get(ctx, directoryDigest).ToProto(&remoteexecution.Directory{}, be.maximumMessageSizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	return directoryMessage.(*remoteexecution.Directory), nil
+}
+
+// lookupDirectory resolves a directory in the input root. If no
+// directory exists at the provided path, nil is returned.
+func (be *pathMappingBuildExecutor) lookupDirectory(ctx context.Context, digestFunction digest.Function, inputRootDigest digest.Digest, directoryComponents []string) (*remoteexecution.Directory, error) {
+	directory, err := be.getDirectory(ctx, inputRootDigest)
+	if err != nil {
+		return nil, util.StatusWrap(err, "Failed to obtain input root")
+	}
+	for _, component := range directoryComponents {
+		found := false
+		for _, child := range directory.Directories {
+			if child.Name == component {
+				childDigest, err := digestFunction.NewDigestFromProto(child.Digest)
+				if err != nil {
+					return nil, util.StatusWrapf(err, "Failed to extract digest for directory %#v", child.Name)
+				}
+				if directory, err = be.getDirectory(ctx, childDigest); err != nil {
+					return nil, util.StatusWrapf(err, "Failed to obtain directory %#v", child.Name)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil
+		}
+	}
+	return directory, nil
+}
+
+// pathExists returns whether a file, directory or symbolic link exists
+// at a given path within a directory hierarchy. It also returns true if
+// one of the parent directories of the path is not a directory.
+func (be *pathMappingBuildExecutor) pathExists(ctx context.Context, digestFunction digest.Function, directoryDigest digest.Digest, components []string) (bool, error) {
+	directory, err := be.getDirectory(ctx, directoryDigest)
+	if err != nil {
+		return false, err
+	}
+	for _, child := range directory.Directories {
+		if child.Name == components[0] {
+			if len(components) == 1 {
+				return true, nil
+			}
+			childDigest, err := digestFunction.NewDigestFromProto(child.Digest)
+			if err != nil {
+				return false, util.StatusWrapf(err, "Failed to extract digest for directory %#v", child.Name)
+			}
+			exists, err := be.pathExists(ctx, digestFunction, childDigest, components[1:])
+			if err != nil {
+				return false, util.StatusWrapf(err, "Directory %#v", child.Name)
+			}
+			return exists, nil
+		}
+	}
+	for _, child := range directory.Files {
+		if child.Name == components[0] {
+			return true, nil
+		}
+	}
+	for _, child := range directory.Symlinks {
+		if child.Name == components[0] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// mergeDirectories merges the contents of a set of directories,
+// uploading all newly created Directory objects to the Content
+// Addressable Storage. Merging fails if the directories contain
+// entries with the same name, but different contents.
+func (be *pathMappingBuildExecutor) mergeDirectories(ctx context.Context, digestFunction digest.Function, directoryDigests []digest.Digest) (digest.Digest, bool, error) {
+	// Directories having identical contents don't need to be
+	// loaded to be merged.
+	uniqueDigests := directoryDigests[:0:0]
+	for _, directoryDigest := range directoryDigests {
+		if !slices.Contains(uniqueDigests, directoryDigest) {
+			uniqueDigests = append(uniqueDigests, directoryDigest)
+		}
+	}
+	if len(uniqueDigests) == 1 {
+		return uniqueDigests[0], true, nil
+	}
+
+	var nodeProperties *remoteexecution.NodeProperties
+	childDirectories := map[string][]digest.Digest{}
+	childFiles := map[string]*remoteexecution.FileNode{}
+	childSymlinks := map[string]*remoteexecution.SymlinkNode{}
+	for i, directoryDigest := range uniqueDigests {
+		directory, err := be.getDirectory(ctx, directoryDigest)
+		if err != nil {
+			return digest.BadDigest, false, err
+		}
+		if i == 0 {
+			nodeProperties = directory.NodeProperties
+		} else if !proto.Equal(nodeProperties, directory.NodeProperties) {
+			return digest.BadDigest, false, nil
+		}
+		for _, child := range directory.Directories {
+			childDigest, err := digestFunction.NewDigestFromProto(child.Digest)
+			if err != nil {
+				return digest.BadDigest, false, util.StatusWrapf(err, "Failed to extract digest for directory %#v", child.Name)
+			}
+			childDirectories[child.Name] = append(childDirectories[child.Name], childDigest)
+		}
+		for _, child := range directory.Files {
+			if existing, ok := childFiles[child.Name]; ok && !proto.Equal(existing, child) {
+				return digest.BadDigest, false, nil
+			}
+			childFiles[child.Name] = child
+		}
+		for _, child := range directory.Symlinks {
+			if existing, ok := childSymlinks[child.Name]; ok && !proto.Equal(existing, child) {
+				return digest.BadDigest, false, nil
+			}
+			childSymlinks[child.Name] = child
+		}
+	}
+
+	mergedDirectory := &remoteexecution.Directory{
+		NodeProperties: nodeProperties,
+	}
+	for name, childDigests := range childDirectories {
+		if _, ok := childFiles[name]; ok {
+			return digest.BadDigest, false, nil
+		}
+		if _, ok := childSymlinks[name]; ok {
+			return digest.BadDigest, false, nil
+		}
+		mergedChildDigest, ok, err := be.mergeDirectories(ctx, digestFunction, childDigests)
+		if err != nil {
+			return digest.BadDigest, false, util.StatusWrapf(err, "Directory %#v", name)
+		}
+		if !ok {
+			return digest.BadDigest, false, nil
+		}
+		mergedDirectory.Directories = append(mergedDirectory.Directories, &remoteexecution.DirectoryNode{
+			Name:   name,
+			Digest: mergedChildDigest.GetProto(),
+		})
+	}
+	for name, child := range childFiles {
+		if _, ok := childSymlinks[name]; ok {
+			return digest.BadDigest, false, nil
+		}
+		mergedDirectory.Files = append(mergedDirectory.Files, child)
+	}
+	for _, child := range childSymlinks {
+		mergedDirectory.Symlinks = append(mergedDirectory.Symlinks, child)
+	}
+	sortDirectory(mergedDirectory)
+	mergedDigest, err := blobstore.CASPutProto(ctx, be.contentAddressableStorage, mergedDirectory, digestFunction)
+	if err != nil {
+		return digest.BadDigest, false, err
+	}
+	return mergedDigest, true, nil
+}
+
+func sortDirectory(directory *remoteexecution.Directory) {
+	sort.Slice(directory.Directories, func(i, j int) bool { return directory.Directories[i].Name < directory.Directories[j].Name })
+	sort.Slice(directory.Files, func(i, j int) bool { return directory.Files[i].Name < directory.Files[j].Name })
+	sort.Slice(directory.Symlinks, func(i, j int) bool { return directory.Symlinks[i].Name < directory.Symlinks[j].Name })
+}
+
+// replaceInInputRoot replaces a directory in the input root, uploading
+// all modified Directory objects to the Content Addressable Storage.
+// The digest of the resulting input root is returned.
+func (be *pathMappingBuildExecutor) replaceInInputRoot(ctx context.Context, digestFunction digest.Function, directoryDigest digest.Digest, directoryComponents []string, newDigest digest.Digest) (digest.Digest, error) {
+	if len(directoryComponents) == 0 {
+		return newDigest, nil
+	}
+	directory, err := be.getDirectory(ctx, directoryDigest)
+	if err != nil {
+		return digest.BadDigest, err
+	}
+	for _, child := range directory.Directories {
+		if child.Name == directoryComponents[0] {
+			childDigest, err := digestFunction.NewDigestFromProto(child.Digest)
+			if err != nil {
+				return digest.BadDigest, util.StatusWrapf(err, "Failed to extract digest for directory %#v", child.Name)
+			}
+			newChildDigest, err := be.replaceInInputRoot(ctx, digestFunction, childDigest, directoryComponents[1:], newDigest)
+			if err != nil {
+				return digest.BadDigest, util.StatusWrapf(err, "Directory %#v", child.Name)
+			}
+			child.Digest = newChildDigest.GetProto()
+			return blobstore.CASPutProto(ctx, be.contentAddressableStorage, directory, digestFunction)
+		}
+	}
+	return digest.BadDigest, status.Errorf(codes.NotFound, "Directory %#v does not exist", directoryComponents[0])
+}
+
+// applyPathMapping attempts to apply a single PathMapping to an action,
+// merging all configuration-specific directories in the input root and
+// rewriting the Command in place. If applied, the digest of the new
+// input root and the new output paths are returned. The latter maps
+// output paths in the rewritten Command to output paths in the
+// original Command.
+func (be *pathMappingBuildExecutor) applyPathMapping(ctx context.Context, digestFunction digest.Function, inputRootDigest digest.Digest, command *remoteexecution.Command, outputPaths map[string]string, pathMapping *PathMapping) (digest.Digest, map[string]string, bool, error) {
+	// Obtain the names of all configurations, both through the
+	// input root and the output paths of the command.
+	directoryComponents := strings.Split(pathMapping.Directory, "/")
+	directory, err := be.lookupDirectory(ctx, digestFunction, inputRootDigest, directoryComponents)
+	if err != nil {
+		return digest.BadDigest, nil, false, err
+	}
+	configurationNames := map[string]struct{}{}
+	if directory != nil {
+		for _, child := range directory.Directories {
+			configurationNames[child.Name] = struct{}{}
+		}
+	}
+	directoryPrefix := pathMapping.Directory + "/"
+	for outputPath := range outputPaths {
+		if strings.HasPrefix(outputPath, directoryPrefix) {
+			if name, _, ok := strings.Cut(outputPath[len(directoryPrefix):], "/"); ok {
+				configurationNames[name] = struct{}{}
+			}
+		}
+	}
+
+	appliedMapping := appliedPathMapping{
+		canonicalPrefix: directoryPrefix + pathMapping.CanonicalName + "/",
+	}
+	for name := range configurationNames {
+		if name != pathMapping.CanonicalName {
+			appliedMapping.originalPrefixes = append(appliedMapping.originalPrefixes, directoryPrefix+name+"/")
+		}
+	}
+	if len(appliedMapping.originalPrefixes) == 0 {
+		return digest.BadDigest, nil, false, nil
+	}
+	sort.Strings(appliedMapping.originalPrefixes)
+
+	// Outputs belonging to different configurations may not end up
+	// having the same path.
+	newOutputPaths := make(map[string]string, len(outputPaths))
+	for outputPath, originalOutputPath := range outputPaths {
+		newOutputPath := appliedMapping.mapPath(outputPath)
+		if _, ok := newOutputPaths[newOutputPath]; ok {
+			pathMappingBuildExecutorMappingsSkippedConflictingOutputs.Inc()
+			return digest.BadDigest, nil, false, nil
+		}
+		newOutputPaths[newOutputPath] = originalOutputPath
+	}
+
+	if directory != nil {
+		// Files and symbolic links in the directory are not
+		// part of any configuration, and may not collide with
+		// the canonical name.
+		for _, child := range directory.Files {
+			if child.Name == pathMapping.CanonicalName {
+				pathMappingBuildExecutorMappingsSkippedConflictingInputs.Inc()
+				return digest.BadDigest, nil, false, nil
+			}
+		}
+		for _, child := range directory.Symlinks {
+			if child.Name == pathMapping.CanonicalName {
+				pathMappingBuildExecutorMappingsSkippedConflictingInputs.Inc()
+				return digest.BadDigest, nil, false, nil
+			}
+		}
+	}
+
+	if directory != nil && len(directory.Directories) > 0 {
+		// Merge the configuration-specific directories in the
+		// input root into a single directory.
+		configurationDigests := make([]digest.Digest, 0, len(directory.Directories))
+		for _, child := range directory.Directories {
+			childDigest, err := digestFunction.NewDigestFromProto(child.Digest)
+			if err != nil {
+				return digest.BadDigest, nil, false, util.StatusWrapf(err, "Failed to extract digest for directory %#v", child.Name)
+			}
+			configurationDigests = append(configurationDigests, childDigest)
+		}
+		mergedDigest, ok, err := be.mergeDirectories(ctx, digestFunction, configurationDigests)
+		if err != nil {
+			return digest.BadDigest, nil, false, util.StatusWrap(err, "Failed to merge configuration directories")
+		}
+		if !ok {
+			pathMappingBuildExecutorMappingsSkippedConflictingInputs.Inc()
+			return digest.BadDigest, nil, false, nil
+		}
+
+		// Outputs of one configuration may not end up
+		// overlapping with inputs of another configuration.
+		if len(configurationNames) > 1 {
+			for newOutputPath := range newOutputPaths {
+				if strings.HasPrefix(newOutputPath, appliedMapping.canonicalPrefix) {
+					exists, err := be.pathExists(ctx, digestFunction, mergedDigest, strings.Split(newOutputPath[len(appliedMapping.canonicalPrefix):], "/"))
+					if err != nil {
+						return digest.BadDigest, nil, false, util.StatusWrapf(err, "Failed to look up output path %#v", newOutputPath)
+					}
+					if exists {
+						pathMappingBuildExecutorMappingsSkippedConflictingOutputs.Inc()
+						return digest.BadDigest, nil, false, nil
+					}
+				}
+			}
+		}
+
+		directory.Directories = []*remoteexecution.DirectoryNode{{
+			Name:   pathMapping.CanonicalName,
+			Digest: mergedDigest.GetProto(),
+		}}
+		newDirectoryDigest, err := blobstore.CASPutProto(ctx, be.contentAddressableStorage, directory, digestFunction)
+		if err != nil {
+			return digest.BadDigest, nil, false, util.StatusWrap(err, "Failed to store merged configuration directories")
+		}
+		if inputRootDigest, err = be.replaceInInputRoot(ctx, digestFunction, inputRootDigest, directoryComponents, newDirectoryDigest); err != nil {
+			return digest.BadDigest, nil, false, util.StatusWrap(err, "Failed to rewrite input root")
+		}
+	}
+
+	appliedMapping.mapCommand(command)
+	return inputRootDigest, newOutputPaths, true, nil
+}
+
+// mapAction applies all PathMappings to an action. It returns the
+// rewritten Action and a map of output paths in the rewritten Command
+// to the ones in the original Command. If no mappings were applied,
+// the original Action is returned.
+func (be *pathMappingBuildExecutor) mapAction(ctx context.Context, digestFunction digest.Function, action *remoteexecution.Action) (*remoteexecution.Action, map[string]string, error) {
+	commandDigest, err := digestFunction.NewDigestFromProto(action.CommandDigest)
+	if err != nil {
+		return nil, nil, util.StatusWrap(err, "Failed to extract digest for command")
+	}
+	commandMessage, err := be.contentAddressableStorage.Get(ctx, commandDigest).ToProto(&remoteexecution.Command{}, be.maximumMessageSizeBytes)
+	if err != nil {
+		return nil, nil, util.StatusWrap(err, "Failed to obtain command")
+	}
+	command := commandMessage.(*remoteexecution.Command)
+	if command.WorkingDirectory != "" {
+		// Output paths are relative to the working directory,
+		// making it harder to match them. Don't bother.
+		pathMappingBuildExecutorMappingsSkippedWorkingDirectory.Add(float64(len(be.pathMappings)))
+		return action, nil, nil
+	}
+	inputRootDigest, err := digestFunction.NewDigestFromProto(action.InputRootDigest)
+	if err != nil {
+		return nil, nil, util.StatusWrap(err, "Failed to extract digest for input root")
+	}
+
+	outputPaths := map[string]string{}
+	for _, paths := range [][]string{command.OutputFiles, command.OutputDirectories, command.OutputPaths} {
+		for _, p := range paths {
+			outputPaths[p] = p
+		}
+	}
+	appliedAny := false
+	for i := range be.pathMappings {
+		newInputRootDigest, newOutputPaths, applied, err := be.applyPathMapping(ctx, digestFunction, inputRootDigest, command, outputPaths, &be.pathMappings[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		if applied {
+			inputRootDigest, outputPaths = newInputRootDigest, newOutputPaths
+			appliedAny = true
+		}
+	}
+	if !appliedAny {
+		return action, nil, nil
+	}
+
+	newCommandDigest, err := blobstore.CASPutProto(ctx, be.contentAddressableStorage, command, digestFunction)
+	if err != nil {
+		return nil, nil, util.StatusWrap(err, "Failed to store rewritten command")
+	}
+	newAction := proto.Clone(action).(*remoteexecution.Action)
+	newAction.CommandDigest = newCommandDigest.GetProto()
+	newAction.InputRootDigest = inputRootDigest.GetProto()
+	return newAction, outputPaths, nil
+}
+
+func (be *pathMappingBuildExecutor) Execute(ctx context.Context, filePool filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+	action := request.Action
+	if action == nil {
+		response := NewDefaultExecuteResponse(request)
+		attachErrorToExecuteResponse(response, status.Error(codes.InvalidArgument, "Request does not contain an action"))
+		return response
+	}
+	newAction, originalOutputPaths, err := be.mapAction(ctx, digestFunction, action)
+	if err != nil {
+		response := NewDefaultExecuteResponse(request)
+		attachErrorToExecuteResponse(response, util.StatusWrap(err, "Failed to apply path mapping"))
+		return response
+	}
+	if originalOutputPaths == nil {
+		return be.BuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates)
+	}
+	newActionDigest, err := blobstore.CASPutProto(ctx, be.contentAddressableStorage, newAction, digestFunction)
+	if err != nil {
+		response := NewDefaultExecuteResponse(request)
+		attachErrorToExecuteResponse(response, util.StatusWrap(err, "Failed to store rewritten action"))
+		return response
+	}
+
+	unmapActionResult := func(response *remoteexecution.ExecuteResponse) *remoteexecution.ExecuteResponse {
+		if response.Result != nil {
+			unmapActionResult(response.Result, originalOutputPaths)
+		}
+		return response
+	}
+
+	// The rewritten action may already have been executed as part
+	// of a build for another configuration.
+	if !action.DoNotCache && !request.SkipCacheLookup {
+		actionResult, err := be.actionCache.Get(ctx, newActionDigest).ToProto(&remoteexecution.ActionResult{}, be.maximumMessageSizeBytes)
+		if err == nil {
+			response := NewDefaultExecuteResponse(request)
+			response.Result = actionResult.(*remoteexecution.ActionResult)
+			response.CachedResult = true
+			return unmapActionResult(response)
+		} else if status.Code(err) != codes.NotFound {
+			response := NewDefaultExecuteResponse(request)
+			attachErrorToExecuteResponse(response, util.StatusWrap(err, "Failed to obtain cached result of rewritten action"))
+			return response
+		}
+	}
+
+	newRequest := proto.Clone(request).(*remoteworker.DesiredState_Executing)
+	newRequest.ActionDigest = newActionDigest.GetProto()
+	newRequest.Action = newAction
+	response := be.BuildExecutor.Execute(ctx, filePool, monitor, digestFunction, newRequest, executionStateUpdates)
+	if !action.DoNotCache && executeResponseIsSuccessful(response) {
+		if err := be.actionCache.Put(ctx, newActionDigest, buffer.NewProtoBufferFromProto(response.Result, buffer.UserProvided)); err != nil {
+			attachErrorToExecuteResponse(response, util.StatusWrap(err, "Failed to store cached result of rewritten action"))
+		}
+	}
+	return unmapActionResult(response)
+}
@@ -0,0 +1,336 @@
+package builder_test
+
+import (
+	"context"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPathMappingBuildExecutor(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	// Let the Content Addressable Storage be backed by a map, so
+	// that objects created by the BuildExecutor can be inspected.
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	contents := map[digest.Digest][]byte{}
+	contentAddressableStorage.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, blobDigest digest.Digest) buffer.Buffer {
+			data, ok := contents[blobDigest]
+			if !ok {
+				return buffer.NewBufferFromError(status.Error(codes.NotFound, "Object not found"))
+			}
+			return buffer.NewValidatedBufferFromByteSlice(data)
+		}).AnyTimes()
+	contentAddressableStorage.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, blobDigest digest.Digest, b buffer.Buffer) error {
+			data, err := b.ToByteSlice(10000)
+			require.NoError(t, err)
+			contents[blobDigest] = data
+			return nil
+		}).AnyTimes()
+	actionCache := mock.NewMockBlobAccess(ctrl)
+	baseBuildExecutor := mock.NewMockBuildExecutor(ctrl)
+	buildExecutor := builder.NewPathMappingBuildExecutor(baseBuildExecutor, contentAddressableStorage, actionCache, 10000, []builder.PathMapping{
+		{Directory: "bazel-out", CanonicalName: "cfg"},
+	})
+
+	filePool := mock.NewMockFilePool(ctrl)
+	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
+	digestFunction := digest.MustNewFunction("hello", remoteexecution.DigestFunction_SHA256)
+	putProto := func(message proto.Message) digest.Digest {
+		blobDigest, err := blobstore.CASPutProto(ctx, contentAddressableStorage, message, digestFunction)
+		require.NoError(t, err)
+		return blobDigest
+	}
+	newRequest := func(command *remoteexecution.Command, inputRoot *remoteexecution.Directory, doNotCache bool) *remoteworker.DesiredState_Executing {
+		action := &remoteexecution.Action{
+			CommandDigest:   putProto(command).GetProto(),
+			InputRootDigest: putProto(inputRoot).GetProto(),
+			DoNotCache:      doNotCache,
+		}
+		return &remoteworker.DesiredState_Executing{
+			ActionDigest: putProto(action).GetProto(),
+			Action:       action,
+		}
+	}
+
+	t.Run("NoConfigurations", func(t *testing.T) {
+		// Actions that don't reference any configurations
+		// should be executed unmodified.
+		request := newRequest(&remoteexecution.Command{
+			Arguments:   []string{"touch", "foo"},
+			OutputPaths: []string{"foo"},
+		}, &remoteexecution.Directory{}, false)
+		var executionStateUpdates chan<- *remoteworker.CurrentState_Executing = make(chan *remoteworker.CurrentState_Executing)
+		baseBuildExecutor.EXPECT().Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates).Return(&remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{
+				OutputFiles: []*remoteexecution.OutputFile{{Path: "foo"}},
+			},
+		})
+
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{
+				OutputFiles: []*remoteexecution.OutputFile{{Path: "foo"}},
+			},
+		}, buildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates))
+	})
+
+	fooCDigest := &remoteexecution.Digest{
+		Hash:      "8b1a9953c4611296a827abf8c47804d7e6c49c6b1b6f5f4b7e3e0b0d4b0e7c4a",
+		SizeBytes: 5,
+	}
+	toolDigest := &remoteexecution.Digest{
+		Hash:      "e3b98a4da31a127d4bde6e43033f66ba274cab0eb7eb1c70ec41402bf6273dd8",
+		SizeBytes: 12,
+	}
+	newBinDirectory := func(files ...*remoteexecution.FileNode) *remoteexecution.DirectoryNode {
+		return &remoteexecution.DirectoryNode{
+			Name: "bin",
+			Digest: putProto(&remoteexecution.Directory{
+				Files: files,
+			}).GetProto(),
+		}
+	}
+	newConfigurationDirectory := func(name string, files ...*remoteexecution.FileNode) *remoteexecution.DirectoryNode {
+		return &remoteexecution.DirectoryNode{
+			Name: name,
+			Digest: putProto(&remoteexecution.Directory{
+				Directories: []*remoteexecution.DirectoryNode{newBinDirectory(files...)},
+			}).GetProto(),
+		}
+	}
+	newBazelOutInputRoot := func(configurations ...*remoteexecution.DirectoryNode) *remoteexecution.Directory {
+		return &remoteexecution.Directory{
+			Directories: []*remoteexecution.DirectoryNode{{
+				Name: "bazel-out",
+				Digest: putProto(&remoteexecution.Directory{
+					Directories: configurations,
+				}).GetProto(),
+			}},
+		}
+	}
+
+	t.Run("ConflictingInputs", func(t *testing.T) {
+		// Actions referencing multiple configurations whose
+		// inputs have the same path, but different contents,
+		// cannot be rewritten.
+		request := newRequest(&remoteexecution.Command{
+			Arguments:   []string{"cat", "bazel-out/k8-opt-exec/bin/foo.c", "bazel-out/k8-fastbuild/bin/foo.c"},
+			OutputPaths: []string{"bazel-out/k8-fastbuild/bin/out"},
+		}, newBazelOutInputRoot(
+			newConfigurationDirectory("k8-fastbuild", &remoteexecution.FileNode{Name: "foo.c", Digest: fooCDigest}),
+			newConfigurationDirectory("k8-opt-exec", &remoteexecution.FileNode{Name: "foo.c", Digest: toolDigest}),
+		), false)
+		var executionStateUpdates chan<- *remoteworker.CurrentState_Executing = make(chan *remoteworker.CurrentState_Executing)
+		baseBuildExecutor.EXPECT().Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates).Return(&remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{},
+		})
+
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{},
+		}, buildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates))
+	})
+
+	t.Run("ConflictingOutputs", func(t *testing.T) {
+		// Outputs of one configuration may not overlap with
+		// inputs of another configuration.
+		request := newRequest(&remoteexecution.Command{
+			Arguments:   []string{"cp", "bazel-out/k8-opt-exec/bin/foo.c", "bazel-out/k8-fastbuild/bin/foo.c"},
+			OutputPaths: []string{"bazel-out/k8-fastbuild/bin/foo.c"},
+		}, newBazelOutInputRoot(
+			newConfigurationDirectory("k8-opt-exec", &remoteexecution.FileNode{Name: "foo.c", Digest: fooCDigest}),
+		), false)
+		var executionStateUpdates chan<- *remoteworker.CurrentState_Executing = make(chan *remoteworker.CurrentState_Executing)
+		baseBuildExecutor.EXPECT().Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates).Return(&remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{},
+		})
+
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{},
+		}, buildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates))
+	})
+
+	t.Run("MultipleConfigurations", func(t *testing.T) {
+		// Actions commonly use tools that are built for another
+		// configuration. The contents of both configurations
+		// should be merged. Files that are not part of any
+		// configuration should be left alone.
+		bazelOut := newBazelOutInputRoot(
+			newConfigurationDirectory("k8-fastbuild", &remoteexecution.FileNode{Name: "foo.c", Digest: fooCDigest}),
+			newConfigurationDirectory("k8-opt-exec", &remoteexecution.FileNode{Name: "tool", Digest: toolDigest, IsExecutable: true}),
+		)
+		bazelOutDigest, err := digestFunction.NewDigestFromProto(bazelOut.Directories[0].Digest)
+		require.NoError(t, err)
+		bazelOutDirectory, err := contentAddressableStorage.Get(ctx, bazelOutDigest).ToProto(&remoteexecution.Directory{}, 10000)
+		require.NoError(t, err)
+		bazelOutDirectory.(*remoteexecution.Directory).Files = []*remoteexecution.FileNode{{
+			Name:   "volatile-status.txt",
+			Digest: fooCDigest,
+		}}
+		bazelOut.Directories[0].Digest = putProto(bazelOutDirectory).GetProto()
+		request := newRequest(&remoteexecution.Command{
+			Arguments:   []string{"bazel-out/k8-opt-exec/bin/tool", "-o", "bazel-out/k8-fastbuild/bin/foo.o", "bazel-out/k8-fastbuild/bin/foo.c"},
+			OutputPaths: []string{"bazel-out/k8-fastbuild/bin/foo.o"},
+		}, bazelOut, false)
+		var executionStateUpdates chan<- *remoteworker.CurrentState_Executing = make(chan *remoteworker.CurrentState_Executing)
+		actionCache.EXPECT().Get(ctx, gomock.Any()).Return(buffer.NewBufferFromError(status.Error(codes.NotFound, "Object not found")))
+		baseBuildExecutor.EXPECT().Execute(ctx, filePool, monitor, digestFunction, gomock.Any(), executionStateUpdates).DoAndReturn(
+			func(ctx context.Context, filePool filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+				commandDigest, err := digestFunction.NewDigestFromProto(request.Action.CommandDigest)
+				require.NoError(t, err)
+				command, err := contentAddressableStorage.Get(ctx, commandDigest).ToProto(&remoteexecution.Command{}, 10000)
+				require.NoError(t, err)
+				testutil.RequireEqualProto(t, &remoteexecution.Command{
+					Arguments:   []string{"bazel-out/cfg/bin/tool", "-o", "bazel-out/cfg/bin/foo.o", "bazel-out/cfg/bin/foo.c"},
+					OutputPaths: []string{"bazel-out/cfg/bin/foo.o"},
+				}, command)
+
+				testutil.RequireEqualProto(t, newBazelOutInputRoot(
+					newConfigurationDirectory(
+						"cfg",
+						&remoteexecution.FileNode{Name: "foo.c", Digest: fooCDigest},
+						&remoteexecution.FileNode{Name: "tool", Digest: toolDigest, IsExecutable: true}),
+				).Directories[0].Digest, func() *remoteexecution.Digest {
+					inputRootDigest, err := digestFunction.NewDigestFromProto(request.Action.InputRootDigest)
+					require.NoError(t, err)
+					inputRoot, err := contentAddressableStorage.Get(ctx, inputRootDigest).ToProto(&remoteexecution.Directory{}, 10000)
+					require.NoError(t, err)
+					bazelOutDigest, err := digestFunction.NewDigestFromProto(inputRoot.(*remoteexecution.Directory).Directories[0].Digest)
+					require.NoError(t, err)
+					bazelOut, err := contentAddressableStorage.Get(ctx, bazelOutDigest).ToProto(&remoteexecution.Directory{}, 10000)
+					require.NoError(t, err)
+					require.Equal(t, "volatile-status.txt", bazelOut.(*remoteexecution.Directory).Files[0].Name)
+					bazelOut.(*remoteexecution.Directory).Files = nil
+					return putProto(bazelOut).GetProto()
+				}())
+
+				return &remoteexecution.ExecuteResponse{
+					Result: &remoteexecution.ActionResult{
+						OutputFiles: []*remoteexecution.OutputFile{{Path: "bazel-out/cfg/bin/foo.o"}},
+					},
+				}
+			})
+		actionCache.EXPECT().Put(ctx, gomock.Any(), gomock.Any())
+
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{
+				OutputFiles: []*remoteexecution.OutputFile{{Path: "bazel-out/k8-fastbuild/bin/foo.o"}},
+			},
+		}, buildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates))
+	})
+
+	// Two actions that only differ in the configuration for which
+	// they are built.
+	newConfigurationRequest := func(configuration string) *remoteworker.DesiredState_Executing {
+		return newRequest(&remoteexecution.Command{
+			Arguments:   []string{"cc", "-o", "bazel-out/" + configuration + "/bin/foo.o", "bazel-out/" + configuration + "/bin/foo.c"},
+			OutputPaths: []string{"bazel-out/" + configuration + "/bin/foo.o"},
+		}, &remoteexecution.Directory{
+			Directories: []*remoteexecution.DirectoryNode{{
+				Name: "bazel-out",
+				Digest: putProto(&remoteexecution.Directory{
+					Directories: []*remoteexecution.DirectoryNode{{
+						Name: configuration,
+						Digest: putProto(&remoteexecution.Directory{
+							Files: []*remoteexecution.FileNode{{
+								Name:   "foo.c",
+								Digest: fooCDigest,
+							}},
+						}).GetProto(),
+					}},
+				}).GetProto(),
+			}},
+		}, false)
+	}
+
+	var canonicalActionDigest digest.Digest
+	t.Run("CacheMiss", func(t *testing.T) {
+		// The action should be rewritten to use canonical
+		// paths. Output paths should be translated back.
+		request := newConfigurationRequest("k8-fastbuild")
+		var executionStateUpdates chan<- *remoteworker.CurrentState_Executing = make(chan *remoteworker.CurrentState_Executing)
+		actionCache.EXPECT().Get(ctx, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, blobDigest digest.Digest) buffer.Buffer {
+				canonicalActionDigest = blobDigest
+				return buffer.NewBufferFromError(status.Error(codes.NotFound, "Object not found"))
+			})
+		baseBuildExecutor.EXPECT().Execute(ctx, filePool, monitor, digestFunction, gomock.Any(), executionStateUpdates).DoAndReturn(
+			func(ctx context.Context, filePool filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+				testutil.RequireEqualProto(t, canonicalActionDigest.GetProto(), request.ActionDigest)
+
+				commandDigest, err := digestFunction.NewDigestFromProto(request.Action.CommandDigest)
+				require.NoError(t, err)
+				command, err := contentAddressableStorage.Get(ctx, commandDigest).ToProto(&remoteexecution.Command{}, 10000)
+				require.NoError(t, err)
+				testutil.RequireEqualProto(t, &remoteexecution.Command{
+					Arguments:   []string{"cc", "-o", "bazel-out/cfg/bin/foo.o", "bazel-out/cfg/bin/foo.c"},
+					OutputPaths: []string{"bazel-out/cfg/bin/foo.o"},
+				}, command)
+
+				inputRootDigest, err := digestFunction.NewDigestFromProto(request.Action.InputRootDigest)
+				require.NoError(t, err)
+				inputRoot, err := contentAddressableStorage.Get(ctx, inputRootDigest).ToProto(&remoteexecution.Directory{}, 10000)
+				require.NoError(t, err)
+				bazelOutDigest, err := digestFunction.NewDigestFromProto(inputRoot.(*remoteexecution.Directory).Directories[0].Digest)
+				require.NoError(t, err)
+				bazelOut, err := contentAddressableStorage.Get(ctx, bazelOutDigest).ToProto(&remoteexecution.Directory{}, 10000)
+				require.NoError(t, err)
+				require.Equal(t, "cfg", bazelOut.(*remoteexecution.Directory).Directories[0].Name)
+
+				return &remoteexecution.ExecuteResponse{
+					Result: &remoteexecution.ActionResult{
+						OutputFiles: []*remoteexecution.OutputFile{{Path: "bazel-out/cfg/bin/foo.o"}},
+					},
+				}
+			})
+		actionCache.EXPECT().Put(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, blobDigest digest.Digest, b buffer.Buffer) error {
+				require.Equal(t, canonicalActionDigest, blobDigest)
+				actionResult, err := b.ToProto(&remoteexecution.ActionResult{}, 10000)
+				require.NoError(t, err)
+				testutil.RequireEqualProto(t, &remoteexecution.ActionResult{
+					OutputFiles: []*remoteexecution.OutputFile{{Path: "bazel-out/cfg/bin/foo.o"}},
+				}, actionResult)
+				return nil
+			})
+
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{
+				OutputFiles: []*remoteexecution.OutputFile{{Path: "bazel-out/k8-fastbuild/bin/foo.o"}},
+			},
+		}, buildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates))
+	})
+
+	t.Run("CacheHit", func(t *testing.T) {
+		// Building the same action for another configuration
+		// should yield the same canonical action, meaning the
+		// previously stored result can be reused.
+		request := newConfigurationRequest("k8-opt")
+		actionCache.EXPECT().Get(ctx, canonicalActionDigest).Return(buffer.NewProtoBufferFromProto(&remoteexecution.ActionResult{
+			OutputFiles: []*remoteexecution.OutputFile{{Path: "bazel-out/cfg/bin/foo.o"}},
+		}, buffer.UserProvided))
+
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{
+				OutputFiles: []*remoteexecution.OutputFile{{Path: "bazel-out/k8-opt/bin/foo.o"}},
+			},
+			CachedResult: true,
+		}, buildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, make(chan *remoteworker.CurrentState_Executing)))
+	})
+}
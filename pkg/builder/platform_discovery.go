@@ -0,0 +1,188 @@
+package builder
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// HostFactSource obtains the value of a single fact about the worker
+// host, such as the version of an installed SDK or the model of a GPU.
+type HostFactSource func(ctx context.Context) (string, error)
+
+// NewFileHostFactSource creates a HostFactSource that yields the
+// contents of a file, with leading and trailing whitespace removed.
+func NewFileHostFactSource(path string) HostFactSource {
+	return func(ctx context.Context) (string, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", util.StatusWrapf(err, "Failed to read file %#v", path)
+		}
+		return string(bytes.TrimSpace(data)), nil
+	}
+}
+
+// NewCommandHostFactSource creates a HostFactSource that yields the
+// output written to stdout by a command, with leading and trailing
+// whitespace removed.
+func NewCommandHostFactSource(arguments []string) HostFactSource {
+	return func(ctx context.Context) (string, error) {
+		if len(arguments) == 0 {
+			return "", status.Error(codes.InvalidArgument, "Command has no arguments")
+		}
+		output, err := exec.CommandContext(ctx, arguments[0], arguments[1:]...).Output()
+		if err != nil {
+			return "", util.StatusWrapfWithCode(err, codes.Internal, "Failed to run command %#v", arguments[0])
+		}
+		return string(bytes.TrimSpace(output)), nil
+	}
+}
+
+// HostFact is a named fact about the worker host that should be
+// discovered at startup.
+type HostFact struct {
+	Name         string
+	Source       HostFactSource
+	IgnoreErrors bool
+}
+
+// getCPUFlags extracts the flags of the first CPU from the contents of
+// /proc/cpuinfo. On ARM these are listed under "Features".
+func getCPUFlags(cpuInfo []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(cpuInfo))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		if key = strings.TrimSpace(key); key == "flags" || key == "Features" {
+			return strings.Join(strings.Fields(value), " ")
+		}
+	}
+	return ""
+}
+
+// DiscoverHostFacts obtains facts about the worker host. In addition
+// to the facts that are provided, a number of facts are discovered
+// automatically. These are left empty if they cannot be determined on
+// the current operating system.
+func DiscoverHostFacts(ctx context.Context, facts []HostFact) (map[string]string, error) {
+	discoveredFacts := map[string]string{
+		"os":             runtime.GOOS,
+		"arch":           runtime.GOARCH,
+		"cpu_count":      strconv.FormatInt(int64(runtime.NumCPU()), 10),
+		"kernel_release": "",
+		"cpu_flags":      "",
+	}
+	if kernelRelease, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		discoveredFacts["kernel_release"] = string(bytes.TrimSpace(kernelRelease))
+	}
+	if cpuInfo, err := os.ReadFile("/proc/cpuinfo"); err == nil {
+		discoveredFacts["cpu_flags"] = getCPUFlags(cpuInfo)
+	}
+
+	for _, fact := range facts {
+		value, err := fact.Source(ctx)
+		if err != nil {
+			if !fact.IgnoreErrors {
+				return nil, util.StatusWrapf(err, "Failed to discover fact %#v", fact.Name)
+			}
+			value = ""
+		}
+		discoveredFacts[fact.Name] = value
+	}
+	return discoveredFacts, nil
+}
+
+var platformPropertyTemplateFunctions = template.FuncMap{
+	"contains":  strings.Contains,
+	"fields":    strings.Fields,
+	"hasPrefix": strings.HasPrefix,
+	"hasSuffix": strings.HasSuffix,
+	"inList": func(list []string, value string) bool {
+		for _, element := range list {
+			if element == value {
+				return true
+			}
+		}
+		return false
+	},
+	"join":      strings.Join,
+	"split":     strings.Split,
+	"trimSpace": strings.TrimSpace,
+}
+
+// PlatformPropertyTemplate is a platform property whose value is
+// computed by expanding a template against facts about the worker
+// host.
+type PlatformPropertyTemplate struct {
+	name  string
+	value *template.Template
+}
+
+// NewPlatformPropertyTemplate creates a PlatformPropertyTemplate by
+// parsing a Go text/template.
+func NewPlatformPropertyTemplate(name, value string) (PlatformPropertyTemplate, error) {
+	t, err := template.New(name).
+		Funcs(platformPropertyTemplateFunctions).
+		Option("missingkey=zero").
+		Parse(value)
+	if err != nil {
+		return PlatformPropertyTemplate{}, util.StatusWrapfWithCode(err, codes.InvalidArgument, "Invalid template for platform property %#v", name)
+	}
+	return PlatformPropertyTemplate{
+		name:  name,
+		value: t,
+	}, nil
+}
+
+// ApplyPlatformPropertyTemplates extends a platform with properties
+// obtained by expanding templates against facts about the worker
+// host. Properties whose values expand to an empty string are
+// omitted, meaning that existing properties having the same name are
+// retained. Other properties replace existing properties having the
+// same name. Properties in the resulting platform are sorted, as
+// required by the Remote Execution API.
+func ApplyPlatformPropertyTemplates(platform *remoteexecution.Platform, templates []PlatformPropertyTemplate, facts map[string]string) (*remoteexecution.Platform, error) {
+	replacedNames := map[string]struct{}{}
+	var discoveredProperties []*remoteexecution.Platform_Property
+	for _, t := range templates {
+		var value strings.Builder
+		if err := t.value.Execute(&value, facts); err != nil {
+			return nil, util.StatusWrapfWithCode(err, codes.InvalidArgument, "Failed to expand template for platform property %#v", t.name)
+		}
+		if value.Len() > 0 {
+			replacedNames[t.name] = struct{}{}
+			discoveredProperties = append(discoveredProperties, &remoteexecution.Platform_Property{
+				Name:  t.name,
+				Value: value.String(),
+			})
+		}
+	}
+
+	var properties []*remoteexecution.Platform_Property
+	for _, property := range platform.GetProperties() {
+		if _, ok := replacedNames[property.Name]; !ok {
+			properties = append(properties, property)
+		}
+	}
+	properties = append(properties, discoveredProperties...)
+	sort.SliceStable(properties, func(i, j int) bool {
+		pi, pj := properties[i], properties[j]
+		return pi.Name < pj.Name || (pi.Name == pj.Name && pi.Value < pj.Value)
+	})
+	return &remoteexecution.Platform{Properties: properties}, nil
+}
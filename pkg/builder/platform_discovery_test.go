@@ -0,0 +1,105 @@
+package builder_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDiscoverHostFacts(t *testing.T) {
+	ctx := context.Background()
+
+	directory := t.TempDir()
+	sdkVersionPath := filepath.Join(directory, "sdk_version")
+	require.NoError(t, os.WriteFile(sdkVersionPath, []byte("14.2\n"), 0o644))
+
+	t.Run("Success", func(t *testing.T) {
+		facts, err := builder.DiscoverHostFacts(ctx, []builder.HostFact{
+			{
+				Name:   "sdk_version",
+				Source: builder.NewFileHostFactSource(sdkVersionPath),
+			},
+			{
+				Name:         "gpu_model",
+				Source:       builder.NewFileHostFactSource(filepath.Join(directory, "nonexistent")),
+				IgnoreErrors: true,
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "14.2", facts["sdk_version"])
+		require.Equal(t, "", facts["gpu_model"])
+		require.Equal(t, runtime.GOOS, facts["os"])
+		require.Equal(t, runtime.GOARCH, facts["arch"])
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		_, err := builder.DiscoverHostFacts(ctx, []builder.HostFact{
+			{
+				Name: "gpu_model",
+				Source: func(ctx context.Context) (string, error) {
+					return "", status.Error(codes.Internal, "nvidia-smi not found")
+				},
+			},
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.Internal, "Failed to discover fact \"gpu_model\": nvidia-smi not found"), err)
+	})
+}
+
+func TestApplyPlatformPropertyTemplates(t *testing.T) {
+	facts := map[string]string{
+		"cpu_flags":      "fpu sse2 avx2 avx512f",
+		"kernel_release": "6.1.0-13-amd64",
+		"gpu_model":      "",
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		var templates []builder.PlatformPropertyTemplate
+		for _, property := range [][2]string{
+			{"kernel-version", "{{ index (split .kernel_release \"-\") 0 }}"},
+			{"cpu-avx512", "{{ if inList (fields .cpu_flags) \"avx512f\" }}true{{ end }}"},
+			{"gpu", "{{ .gpu_model }}"},
+			{"OSFamily", "linux"},
+		} {
+			template, err := builder.NewPlatformPropertyTemplate(property[0], property[1])
+			require.NoError(t, err)
+			templates = append(templates, template)
+		}
+
+		// Properties with empty values should be omitted, while
+		// existing properties with the same name are otherwise
+		// replaced.
+		// The resulting platform should be sorted.
+		platform, err := builder.ApplyPlatformPropertyTemplates(&remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "OSFamily", Value: "unknown"},
+				{Name: "container-image", Value: "docker://ubuntu"},
+				{Name: "gpu", Value: "none"},
+			},
+		}, templates, facts)
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, &remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "OSFamily", Value: "linux"},
+				{Name: "container-image", Value: "docker://ubuntu"},
+				{Name: "cpu-avx512", Value: "true"},
+				{Name: "gpu", Value: "none"},
+				{Name: "kernel-version", Value: "6.1.0"},
+			},
+		}, platform)
+	})
+
+	t.Run("InvalidTemplate", func(t *testing.T) {
+		_, err := builder.NewPlatformPropertyTemplate("cpu-avx512", "{{ if }}")
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
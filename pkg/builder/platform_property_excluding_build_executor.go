@@ -0,0 +1,173 @@
+package builder
+
+import (
+	"context"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	cas_proto "github.com/buildbarn/bb-remote-execution/pkg/proto/cas"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+type platformPropertyExcludingBuildExecutor struct {
+	BuildExecutor
+	contentAddressableStorage blobstore.BlobAccess
+	actionCache               blobstore.BlobAccess
+	maximumMessageSizeBytes   int
+	excludedProperties        map[string]struct{}
+}
+
+// NewPlatformPropertyExcludingBuildExecutor creates a decorator for
+// BuildExecutor that removes a configured set of platform properties
+// from actions, yielding a reduced action. Results are looked up in and
+// stored into the Action Cache under the digest of the reduced action.
+//
+// This permits sharing results between actions that only differ in
+// platform properties that don't affect the outcome of execution, such
+// as hints for selecting a worker pool or a scheduling priority. The
+// properties that were removed are recorded in the auxiliary metadata
+// of the ActionResult, so that they remain auditable.
+//
+// Unlike PathMappingBuildExecutor, the original action is executed,
+// meaning that the runner and any decorators observe the platform
+// properties as provided by the client.
+func NewPlatformPropertyExcludingBuildExecutor(base BuildExecutor, contentAddressableStorage, actionCache blobstore.BlobAccess, maximumMessageSizeBytes int, excludedProperties []string) BuildExecutor {
+	excludedPropertiesSet := make(map[string]struct{}, len(excludedProperties))
+	for _, name := range excludedProperties {
+		excludedPropertiesSet[name] = struct{}{}
+	}
+	return &platformPropertyExcludingBuildExecutor{
+		BuildExecutor:             base,
+		contentAddressableStorage: contentAddressableStorage,
+		actionCache:               actionCache,
+		maximumMessageSizeBytes:   maximumMessageSizeBytes,
+		excludedProperties:        excludedPropertiesSet,
+	}
+}
+
+// reducePlatform removes all excluded properties from a Platform
+// message. It returns the remaining and the removed properties.
+func (be *platformPropertyExcludingBuildExecutor) reducePlatform(platform *remoteexecution.Platform) (*remoteexecution.Platform, []*remoteexecution.Platform_Property) {
+	var remaining, removed []*remoteexecution.Platform_Property
+	for _, property := range platform.GetProperties() {
+		if _, ok := be.excludedProperties[property.Name]; ok {
+			removed = append(removed, property)
+		} else {
+			remaining = append(remaining, property)
+		}
+	}
+	if len(remaining) == 0 {
+		return nil, removed
+	}
+	return &remoteexecution.Platform{Properties: remaining}, removed
+}
+
+// reduceAction removes all excluded platform properties from both the
+// Action and the Command. The latter is needed, as older clients only
+// store platform properties in the Command. If no properties are
+// removed, the original Action is returned.
+func (be *platformPropertyExcludingBuildExecutor) reduceAction(ctx context.Context, digestFunction digest.Function, action *remoteexecution.Action) (*remoteexecution.Action, []*remoteexecution.Platform_Property, error) {
+	commandDigest, err := digestFunction.NewDigestFromProto(action.CommandDigest)
+	if err != nil {
+		return nil, nil, util.StatusWrap(err, "Failed to extract digest for command")
+	}
+	commandMessage, err := be.contentAddressableStorage.Get(ctx, commandDigest).ToProto(&remoteexecution.Command{}, be.maximumMessageSizeBytes)
+	if err != nil {
+		return nil, nil, util.StatusWrap(err, "Failed to obtain command")
+	}
+	command := commandMessage.(*remoteexecution.Command)
+
+	actionPlatform, removedFromAction := be.reducePlatform(action.Platform)
+	commandPlatform, removedFromCommand := be.reducePlatform(command.Platform)
+	if len(removedFromAction) == 0 && len(removedFromCommand) == 0 {
+		return action, nil, nil
+	}
+
+	newAction := proto.Clone(action).(*remoteexecution.Action)
+	newAction.Platform = actionPlatform
+	if len(removedFromCommand) > 0 {
+		command.Platform = commandPlatform
+		newCommandDigest, err := blobstore.CASPutProto(ctx, be.contentAddressableStorage, command, digestFunction)
+		if err != nil {
+			return nil, nil, util.StatusWrap(err, "Failed to store reduced command")
+		}
+		newAction.CommandDigest = newCommandDigest.GetProto()
+	}
+	if len(removedFromAction) > 0 {
+		return newAction, removedFromAction, nil
+	}
+	return newAction, removedFromCommand, nil
+}
+
+func (be *platformPropertyExcludingBuildExecutor) Execute(ctx context.Context, filePool filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+	action := request.Action
+	if action == nil {
+		response := NewDefaultExecuteResponse(request)
+		attachErrorToExecuteResponse(response, status.Error(codes.InvalidArgument, "Request does not contain an action"))
+		return response
+	}
+	if action.DoNotCache {
+		return be.BuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates)
+	}
+	reducedAction, removedProperties, err := be.reduceAction(ctx, digestFunction, action)
+	if err != nil {
+		response := NewDefaultExecuteResponse(request)
+		attachErrorToExecuteResponse(response, util.StatusWrap(err, "Failed to remove excluded platform properties"))
+		return response
+	}
+	if len(removedProperties) == 0 {
+		return be.BuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates)
+	}
+	reducedActionDigest, err := blobstore.CASPutProto(ctx, be.contentAddressableStorage, reducedAction, digestFunction)
+	if err != nil {
+		response := NewDefaultExecuteResponse(request)
+		attachErrorToExecuteResponse(response, util.StatusWrap(err, "Failed to store reduced action"))
+		return response
+	}
+
+	// The reduced action may already have been executed with
+	// different values for the excluded platform properties.
+	if !request.SkipCacheLookup {
+		actionResult, err := be.actionCache.Get(ctx, reducedActionDigest).ToProto(&remoteexecution.ActionResult{}, be.maximumMessageSizeBytes)
+		if err == nil {
+			response := NewDefaultExecuteResponse(request)
+			response.Result = actionResult.(*remoteexecution.ActionResult)
+			response.CachedResult = true
+			return response
+		} else if status.Code(err) != codes.NotFound {
+			response := NewDefaultExecuteResponse(request)
+			attachErrorToExecuteResponse(response, util.StatusWrap(err, "Failed to obtain cached result of reduced action"))
+			return response
+		}
+	}
+
+	response := be.BuildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates)
+	if executeResponseIsSuccessful(response) {
+		excludedPlatformProperties, err := anypb.New(&cas_proto.ExcludedPlatformProperties{
+			ActionDigest: request.ActionDigest,
+			Properties:   removedProperties,
+		})
+		if err != nil {
+			attachErrorToExecuteResponse(response, util.StatusWrap(err, "Failed to marshal excluded platform properties"))
+			return response
+		}
+		if response.Result.ExecutionMetadata == nil {
+			response.Result.ExecutionMetadata = &remoteexecution.ExecutedActionMetadata{}
+		}
+		response.Result.ExecutionMetadata.AuxiliaryMetadata = append(response.Result.ExecutionMetadata.AuxiliaryMetadata, excludedPlatformProperties)
+		if err := be.actionCache.Put(ctx, reducedActionDigest, buffer.NewProtoBufferFromProto(response.Result, buffer.UserProvided)); err != nil {
+			attachErrorToExecuteResponse(response, util.StatusWrap(err, "Failed to store cached result of reduced action"))
+		}
+	}
+	return response
+}
@@ -0,0 +1,190 @@
+package builder_test
+
+import (
+	"context"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	cas_proto "github.com/buildbarn/bb-remote-execution/pkg/proto/cas"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestPlatformPropertyExcludingBuildExecutor(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	// Let the Content Addressable Storage be backed by a map, so
+	// that objects created by the BuildExecutor can be inspected.
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	contents := map[digest.Digest][]byte{}
+	contentAddressableStorage.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, blobDigest digest.Digest) buffer.Buffer {
+			data, ok := contents[blobDigest]
+			if !ok {
+				return buffer.NewBufferFromError(status.Error(codes.NotFound, "Object not found"))
+			}
+			return buffer.NewValidatedBufferFromByteSlice(data)
+		}).AnyTimes()
+	contentAddressableStorage.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, blobDigest digest.Digest, b buffer.Buffer) error {
+			data, err := b.ToByteSlice(10000)
+			require.NoError(t, err)
+			contents[blobDigest] = data
+			return nil
+		}).AnyTimes()
+	actionCache := mock.NewMockBlobAccess(ctrl)
+	baseBuildExecutor := mock.NewMockBuildExecutor(ctrl)
+	buildExecutor := builder.NewPlatformPropertyExcludingBuildExecutor(baseBuildExecutor, contentAddressableStorage, actionCache, 10000, []string{"pool", "priority"})
+
+	filePool := mock.NewMockFilePool(ctrl)
+	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
+	digestFunction := digest.MustNewFunction("hello", remoteexecution.DigestFunction_SHA256)
+	putProto := func(message proto.Message) digest.Digest {
+		blobDigest, err := blobstore.CASPutProto(ctx, contentAddressableStorage, message, digestFunction)
+		require.NoError(t, err)
+		return blobDigest
+	}
+	newRequest := func(platform *remoteexecution.Platform) *remoteworker.DesiredState_Executing {
+		action := &remoteexecution.Action{
+			CommandDigest: putProto(&remoteexecution.Command{
+				Arguments:   []string{"touch", "foo"},
+				OutputPaths: []string{"foo"},
+				Platform:    platform,
+			}).GetProto(),
+			InputRootDigest: putProto(&remoteexecution.Directory{}).GetProto(),
+			Platform:        platform,
+		}
+		return &remoteworker.DesiredState_Executing{
+			ActionDigest: putProto(action).GetProto(),
+			Action:       action,
+		}
+	}
+
+	t.Run("NoExcludedProperties", func(t *testing.T) {
+		// Actions that don't have any of the excluded
+		// properties should be executed unmodified.
+		request := newRequest(&remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "OSFamily", Value: "linux"},
+			},
+		})
+		var executionStateUpdates chan<- *remoteworker.CurrentState_Executing = make(chan *remoteworker.CurrentState_Executing)
+		baseBuildExecutor.EXPECT().Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates).Return(&remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{
+				OutputFiles: []*remoteexecution.OutputFile{{Path: "foo"}},
+			},
+		})
+
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{
+				OutputFiles: []*remoteexecution.OutputFile{{Path: "foo"}},
+			},
+		}, buildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates))
+	})
+
+	// The digest of the action with all excluded properties
+	// removed.
+	reducedActionDigest := newRequest(&remoteexecution.Platform{
+		Properties: []*remoteexecution.Platform_Property{
+			{Name: "OSFamily", Value: "linux"},
+		},
+	}).ActionDigest
+	newPoolRequest := func(pool string) *remoteworker.DesiredState_Executing {
+		return newRequest(&remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "OSFamily", Value: "linux"},
+				{Name: "pool", Value: pool},
+			},
+		})
+	}
+
+	t.Run("CacheMiss", func(t *testing.T) {
+		// The original action should be executed, while its
+		// result should be stored under the digest of the
+		// reduced action.
+		request := newPoolRequest("large")
+		var executionStateUpdates chan<- *remoteworker.CurrentState_Executing = make(chan *remoteworker.CurrentState_Executing)
+		actionCache.EXPECT().Get(ctx, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, blobDigest digest.Digest) buffer.Buffer {
+				testutil.RequireEqualProto(t, reducedActionDigest, blobDigest.GetProto())
+				return buffer.NewBufferFromError(status.Error(codes.NotFound, "Object not found"))
+			})
+		baseBuildExecutor.EXPECT().Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates).Return(&remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{
+				OutputFiles: []*remoteexecution.OutputFile{{Path: "foo"}},
+			},
+		})
+		excludedPlatformProperties, err := anypb.New(&cas_proto.ExcludedPlatformProperties{
+			ActionDigest: request.ActionDigest,
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "pool", Value: "large"},
+			},
+		})
+		require.NoError(t, err)
+		expectedActionResult := &remoteexecution.ActionResult{
+			OutputFiles: []*remoteexecution.OutputFile{{Path: "foo"}},
+			ExecutionMetadata: &remoteexecution.ExecutedActionMetadata{
+				AuxiliaryMetadata: []*anypb.Any{excludedPlatformProperties},
+			},
+		}
+		actionCache.EXPECT().Put(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, blobDigest digest.Digest, b buffer.Buffer) error {
+				testutil.RequireEqualProto(t, reducedActionDigest, blobDigest.GetProto())
+				actionResult, err := b.ToProto(&remoteexecution.ActionResult{}, 10000)
+				require.NoError(t, err)
+				testutil.RequireEqualProto(t, expectedActionResult, actionResult)
+				return nil
+			})
+
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+			Result: expectedActionResult,
+		}, buildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates))
+	})
+
+	t.Run("CacheHit", func(t *testing.T) {
+		// The same action with a different value for the
+		// excluded property should reuse the cached result.
+		request := newPoolRequest("small")
+		actionCache.EXPECT().Get(ctx, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, blobDigest digest.Digest) buffer.Buffer {
+				testutil.RequireEqualProto(t, reducedActionDigest, blobDigest.GetProto())
+				return buffer.NewProtoBufferFromProto(&remoteexecution.ActionResult{
+					OutputFiles: []*remoteexecution.OutputFile{{Path: "foo"}},
+				}, buffer.UserProvided)
+			})
+
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{
+				OutputFiles: []*remoteexecution.OutputFile{{Path: "foo"}},
+			},
+			CachedResult: true,
+		}, buildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, make(chan *remoteworker.CurrentState_Executing)))
+	})
+
+	t.Run("DoNotCache", func(t *testing.T) {
+		// Actions that may not be cached should not be looked
+		// up in the Action Cache.
+		request := newPoolRequest("small")
+		request.Action.DoNotCache = true
+		var executionStateUpdates chan<- *remoteworker.CurrentState_Executing = make(chan *remoteworker.CurrentState_Executing)
+		baseBuildExecutor.EXPECT().Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates).Return(&remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{},
+		})
+
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{},
+		}, buildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates))
+	})
+}
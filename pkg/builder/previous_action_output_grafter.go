@@ -0,0 +1,273 @@
+package builder
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// PreviousActionOutputGrafter can be used by LocalBuildExecutor to
+// place the outputs of a previously executed action inside the input
+// root of an action. This permits incremental tools (e.g., compilers
+// and linkers) to reuse state produced by an earlier invocation.
+//
+// Actions request this by specifying a platform property of the form
+// "${hash}-${size_bytes}:${path}", where the digest refers to an action
+// whose result is stored in the Action Cache. The output files,
+// directories and symbolic links of the action result are placed
+// underneath the path, using the same relative paths as the ones at
+// which the previous action produced them. Because the digest of the
+// previous action is part of the platform properties, it is also part
+// of the digest of the action requesting it. Grafted outputs are
+// read-only, meaning that they cannot be altered by the action.
+type PreviousActionOutputGrafter struct {
+	platformPropertyName      string
+	contentAddressableStorage blobstore.BlobAccess
+	actionCache               blobstore.BlobAccess
+	maximumMessageSizeBytes   int
+}
+
+// NewPreviousActionOutputGrafter creates a PreviousActionOutputGrafter
+// that grafts outputs for every platform property having a given name.
+// Directory objects that are synthesized to graft outputs are written
+// into the provided Content Addressable Storage. Writes against it must
+// be visible to subsequent reads immediately, meaning that batching
+// BlobAccess implementations may not be used.
+func NewPreviousActionOutputGrafter(platformPropertyName string, contentAddressableStorage, actionCache blobstore.BlobAccess, maximumMessageSizeBytes int) *PreviousActionOutputGrafter {
+	return &PreviousActionOutputGrafter{
+		platformPropertyName:      platformPropertyName,
+		contentAddressableStorage: contentAddressableStorage,
+		actionCache:               actionCache,
+		maximumMessageSizeBytes:   maximumMessageSizeBytes,
+	}
+}
+
+// Populate grafts the outputs of all previous actions referenced by
+// the platform properties of an action into its input root.
+func (g *PreviousActionOutputGrafter) Populate(ctx context.Context, platform *remoteexecution.Platform, inputRootDirectory BuildDirectory, errorLogger util.ErrorLogger, digestFunction digest.Function) error {
+	for _, property := range platform.GetProperties() {
+		if property.Name != g.platformPropertyName {
+			continue
+		}
+
+		digestString, pathString, ok := strings.Cut(property.Value, ":")
+		separator := strings.LastIndexByte(digestString, '-')
+		if !ok || separator < 0 {
+			return status.Errorf(codes.InvalidArgument, "Platform property %#v has value %#v, while a value of the form \"${hash}-${size_bytes}:${path}\" was expected", property.Name, property.Value)
+		}
+		sizeBytes, err := strconv.ParseInt(digestString[separator+1:], 10, 64)
+		if err != nil {
+			return util.StatusWrapfWithCode(err, codes.InvalidArgument, "Invalid size in platform property %#v", property.Name)
+		}
+		actionDigest, err := digestFunction.NewDigest(digestString[:separator], sizeBytes)
+		if err != nil {
+			return util.StatusWrapf(err, "Invalid digest in platform property %#v", property.Name)
+		}
+		var graftPath outputNodePath
+		if err := path.Resolve(pathString, path.NewRelativeScopeWalker(&graftPath)); err != nil {
+			return util.StatusWrapf(err, "Invalid graft path %#v", pathString)
+		}
+		if len(graftPath.components) == 0 {
+			return status.Errorf(codes.InvalidArgument, "Graft path %#v resolves to the input root directory", pathString)
+		}
+
+		if err := g.graft(ctx, actionDigest, graftPath.components, inputRootDirectory, errorLogger, digestFunction); err != nil {
+			return util.StatusWrapf(err, "Failed to graft outputs of action %#v at path %#v", actionDigest.String(), pathString)
+		}
+	}
+	return nil
+}
+
+func (g *PreviousActionOutputGrafter) graft(ctx context.Context, actionDigest digest.Digest, components []path.Component, inputRootDirectory BuildDirectory, errorLogger util.ErrorLogger, digestFunction digest.Function) error {
+	actionResultMessage, err := g.actionCache.Get(ctx, actionDigest).ToProto(&remoteexecution.ActionResult{}, g.maximumMessageSizeBytes)
+	if err != nil {
+		return util.StatusWrap(err, "Failed to obtain action result")
+	}
+	actionResult := actionResultMessage.(*remoteexecution.ActionResult)
+	if actionResult.ExitCode != 0 {
+		// Outputs of failed actions may be incomplete, and are
+		// thus not suitable for reuse.
+		return status.Errorf(codes.FailedPrecondition, "Action completed with non-zero exit code %d", actionResult.ExitCode)
+	}
+
+	// Convert the outputs contained in the action result to a
+	// hierarchy of directories.
+	root := newGraftedDirectory()
+	for _, outputFile := range actionResult.OutputFiles {
+		parent, name, err := root.getParent(outputFile.Path)
+		if err != nil {
+			return err
+		}
+		parent.files[name] = &remoteexecution.FileNode{
+			Name:         name,
+			Digest:       outputFile.Digest,
+			IsExecutable: outputFile.IsExecutable,
+		}
+	}
+	for _, outputSymlinks := range [][]*remoteexecution.OutputSymlink{
+		actionResult.OutputSymlinks,
+		actionResult.OutputFileSymlinks,
+		actionResult.OutputDirectorySymlinks,
+	} {
+		for _, outputSymlink := range outputSymlinks {
+			parent, name, err := root.getParent(outputSymlink.Path)
+			if err != nil {
+				return err
+			}
+			parent.symlinks[name] = &remoteexecution.SymlinkNode{
+				Name:   name,
+				Target: outputSymlink.Target,
+			}
+		}
+	}
+	for _, outputDirectory := range actionResult.OutputDirectories {
+		// Output directories are only stored as Tree objects by
+		// default. Grafting them without the root Directory
+		// object would require uploading all of its children.
+		if outputDirectory.RootDirectoryDigest == nil {
+			return status.Errorf(codes.FailedPrecondition, "Output directory %#v does not have a root directory digest, meaning the action must be executed with output_directory_format DIRECTORY_ONLY or TREE_AND_DIRECTORY", outputDirectory.Path)
+		}
+		parent, name, err := root.getParent(outputDirectory.Path)
+		if err != nil {
+			return err
+		}
+		parent.outputDirectories[name] = outputDirectory.RootDirectoryDigest
+	}
+
+	// Store the directory hierarchy in the Content Addressable
+	// Storage, with the graft path as its only entry. This permits
+	// adding it to the input root through MergeDirectoryContents(),
+	// causing it to be read-only regardless of the type of build
+	// directory.
+	rootDigest, err := root.upload(ctx, g.contentAddressableStorage, digestFunction)
+	if err != nil {
+		return err
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(&remoteexecution.Directory{
+		Directories: []*remoteexecution.DirectoryNode{{
+			Name:   components[len(components)-1].String(),
+			Digest: rootDigest.GetProto(),
+		}},
+	})
+	if err != nil {
+		return util.StatusWrap(err, "Failed to marshal parent directory")
+	}
+	parentDigest, err := putBlob(ctx, g.contentAddressableStorage, digestFunction, data)
+	if err != nil {
+		return util.StatusWrap(err, "Failed to store parent directory")
+	}
+	return mergeDirectoryContentsAtPath(ctx, inputRootDirectory, components, errorLogger, parentDigest)
+}
+
+// graftedDirectory is a directory that is synthesized by
+// PreviousActionOutputGrafter, based on the paths of outputs stored in
+// an action result.
+type graftedDirectory struct {
+	directories       map[string]*graftedDirectory
+	outputDirectories map[string]*remoteexecution.Digest
+	files             map[string]*remoteexecution.FileNode
+	symlinks          map[string]*remoteexecution.SymlinkNode
+}
+
+func newGraftedDirectory() *graftedDirectory {
+	return &graftedDirectory{
+		directories:       map[string]*graftedDirectory{},
+		outputDirectories: map[string]*remoteexecution.Digest{},
+		files:             map[string]*remoteexecution.FileNode{},
+		symlinks:          map[string]*remoteexecution.SymlinkNode{},
+	}
+}
+
+// contains returns true if a directory contains an entry with a given
+// name.
+func (d *graftedDirectory) contains(name string) bool {
+	_, hasDirectory := d.directories[name]
+	_, hasOutputDirectory := d.outputDirectories[name]
+	_, hasFile := d.files[name]
+	_, hasSymlink := d.symlinks[name]
+	return hasDirectory || hasOutputDirectory || hasFile || hasSymlink
+}
+
+// getParent returns the directory in which an output should be
+// placed, creating intermediate directories as needed. It also returns
+// the filename of the output, which is guaranteed not to be in use.
+func (d *graftedDirectory) getParent(pathString string) (*graftedDirectory, string, error) {
+	var outputPath outputNodePath
+	if err := path.Resolve(pathString, path.NewRelativeScopeWalker(&outputPath)); err != nil {
+		return nil, "", util.StatusWrapf(err, "Invalid output path %#v", pathString)
+	}
+	if len(outputPath.components) == 0 {
+		return nil, "", status.Errorf(codes.InvalidArgument, "Output path %#v resolves to the working directory", pathString)
+	}
+	for _, component := range outputPath.components[:len(outputPath.components)-1] {
+		name := component.String()
+		child, ok := d.directories[name]
+		if !ok {
+			if d.contains(name) {
+				return nil, "", status.Errorf(codes.InvalidArgument, "Output path %#v overlaps with another output", pathString)
+			}
+			child = newGraftedDirectory()
+			d.directories[name] = child
+		}
+		d = child
+	}
+	name := outputPath.components[len(outputPath.components)-1].String()
+	if d.contains(name) {
+		return nil, "", status.Errorf(codes.InvalidArgument, "Output path %#v overlaps with another output", pathString)
+	}
+	return d, name, nil
+}
+
+// upload a directory and all of its children into the Content
+// Addressable Storage, returning the digest of the directory.
+func (d *graftedDirectory) upload(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function) (digest.Digest, error) {
+	var directory remoteexecution.Directory
+	for name, child := range d.directories {
+		childDigest, err := child.upload(ctx, contentAddressableStorage, digestFunction)
+		if err != nil {
+			return digest.BadDigest, err
+		}
+		directory.Directories = append(directory.Directories, &remoteexecution.DirectoryNode{
+			Name:   name,
+			Digest: childDigest.GetProto(),
+		})
+	}
+	for name, childDigest := range d.outputDirectories {
+		directory.Directories = append(directory.Directories, &remoteexecution.DirectoryNode{
+			Name:   name,
+			Digest: childDigest,
+		})
+	}
+	for _, file := range d.files {
+		directory.Files = append(directory.Files, file)
+	}
+	for _, symlink := range d.symlinks {
+		directory.Symlinks = append(directory.Symlinks, symlink)
+	}
+
+	// REv2 requires that entries are sorted by name.
+	sort.Slice(directory.Directories, func(i, j int) bool { return directory.Directories[i].Name < directory.Directories[j].Name })
+	sort.Slice(directory.Files, func(i, j int) bool { return directory.Files[i].Name < directory.Files[j].Name })
+	sort.Slice(directory.Symlinks, func(i, j int) bool { return directory.Symlinks[i].Name < directory.Symlinks[j].Name })
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(&directory)
+	if err != nil {
+		return digest.BadDigest, util.StatusWrap(err, "Failed to marshal directory")
+	}
+	directoryDigest, err := putBlob(ctx, contentAddressableStorage, digestFunction, data)
+	if err != nil {
+		return digest.BadDigest, util.StatusWrap(err, "Failed to store directory")
+	}
+	return directoryDigest, nil
+}
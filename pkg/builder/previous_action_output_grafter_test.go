@@ -0,0 +1,277 @@
+package builder_test
+
+import (
+	"context"
+	"syscall"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPreviousActionOutputGrafter(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	blobs := map[digest.Digest][]byte{}
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	contentAddressableStorage.EXPECT().Put(ctx, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, blobDigest digest.Digest, b buffer.Buffer) error {
+			data, err := b.ToByteSlice(10000)
+			require.NoError(t, err)
+			blobs[blobDigest] = data
+			return nil
+		}).
+		AnyTimes()
+	actionCache := mock.NewMockBlobAccess(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	digestFunction := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5).GetDigestFunction()
+	actionDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "d5b9d64d8ab5e0c5c8d3b1a2fd1e8f4b", 123)
+	previousActionOutputGrafter := builder.NewPreviousActionOutputGrafter("graftPreviousOutputs", contentAddressableStorage, actionCache, 10000)
+
+	getDirectory := func(directoryDigest *remoteexecution.Digest) *remoteexecution.Directory {
+		d, err := digestFunction.NewDigestFromProto(directoryDigest)
+		require.NoError(t, err)
+		var directory remoteexecution.Directory
+		require.NoError(t, proto.Unmarshal(blobs[d], &directory))
+		return &directory
+	}
+
+	t.Run("NoProperty", func(t *testing.T) {
+		// Actions that don't specify the platform property should
+		// not cause any changes to the input root.
+		inputRootDirectory := mock.NewMockBuildDirectory(ctrl)
+		require.NoError(t, previousActionOutputGrafter.Populate(
+			ctx,
+			&remoteexecution.Platform{
+				Properties: []*remoteexecution.Platform_Property{
+					{Name: "OSFamily", Value: "linux"},
+				},
+			},
+			inputRootDirectory,
+			errorLogger,
+			digestFunction))
+	})
+
+	t.Run("InvalidValue", func(t *testing.T) {
+		inputRootDirectory := mock.NewMockBuildDirectory(ctrl)
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.InvalidArgument, "Platform property \"graftPreviousOutputs\" has value \"d5b9d64d8ab5e0c5c8d3b1a2fd1e8f4b-123\", while a value of the form \"${hash}-${size_bytes}:${path}\" was expected"),
+			previousActionOutputGrafter.Populate(
+				ctx,
+				&remoteexecution.Platform{
+					Properties: []*remoteexecution.Platform_Property{
+						{Name: "graftPreviousOutputs", Value: "d5b9d64d8ab5e0c5c8d3b1a2fd1e8f4b-123"},
+					},
+				},
+				inputRootDirectory,
+				errorLogger,
+				digestFunction))
+
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.InvalidArgument, "Graft path \".\" resolves to the input root directory"),
+			previousActionOutputGrafter.Populate(
+				ctx,
+				&remoteexecution.Platform{
+					Properties: []*remoteexecution.Platform_Property{
+						{Name: "graftPreviousOutputs", Value: "d5b9d64d8ab5e0c5c8d3b1a2fd1e8f4b-123:."},
+					},
+				},
+				inputRootDirectory,
+				errorLogger,
+				digestFunction))
+	})
+
+	t.Run("ActionResultNotFound", func(t *testing.T) {
+		actionCache.EXPECT().Get(ctx, actionDigest).
+			Return(buffer.NewBufferFromError(status.Error(codes.NotFound, "Object not found")))
+		inputRootDirectory := mock.NewMockBuildDirectory(ctrl)
+
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.NotFound, "Failed to graft outputs of action \"3-d5b9d64d8ab5e0c5c8d3b1a2fd1e8f4b-123-example\" at path \"previous\": Failed to obtain action result: Object not found"),
+			previousActionOutputGrafter.Populate(
+				ctx,
+				&remoteexecution.Platform{
+					Properties: []*remoteexecution.Platform_Property{
+						{Name: "graftPreviousOutputs", Value: "d5b9d64d8ab5e0c5c8d3b1a2fd1e8f4b-123:previous"},
+					},
+				},
+				inputRootDirectory,
+				errorLogger,
+				digestFunction))
+	})
+
+	t.Run("FailedAction", func(t *testing.T) {
+		// Outputs of actions that failed may be incomplete,
+		// meaning they should not be reused.
+		actionCache.EXPECT().Get(ctx, actionDigest).
+			Return(buffer.NewProtoBufferFromProto(&remoteexecution.ActionResult{
+				ExitCode: 1,
+			}, buffer.UserProvided))
+		inputRootDirectory := mock.NewMockBuildDirectory(ctrl)
+
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.FailedPrecondition, "Failed to graft outputs of action \"3-d5b9d64d8ab5e0c5c8d3b1a2fd1e8f4b-123-example\" at path \"previous\": Action completed with non-zero exit code 1"),
+			previousActionOutputGrafter.Populate(
+				ctx,
+				&remoteexecution.Platform{
+					Properties: []*remoteexecution.Platform_Property{
+						{Name: "graftPreviousOutputs", Value: "d5b9d64d8ab5e0c5c8d3b1a2fd1e8f4b-123:previous"},
+					},
+				},
+				inputRootDirectory,
+				errorLogger,
+				digestFunction))
+	})
+
+	t.Run("MissingRootDirectoryDigest", func(t *testing.T) {
+		// Output directories can only be grafted if the root
+		// Directory object has been stored.
+		actionCache.EXPECT().Get(ctx, actionDigest).
+			Return(buffer.NewProtoBufferFromProto(&remoteexecution.ActionResult{
+				OutputDirectories: []*remoteexecution.OutputDirectory{{
+					Path: "cache",
+					TreeDigest: &remoteexecution.Digest{
+						Hash:      "0f2a2a4e51d45d8ed8d59a3ba5ce2bde",
+						SizeBytes: 200,
+					},
+				}},
+			}, buffer.UserProvided))
+		inputRootDirectory := mock.NewMockBuildDirectory(ctrl)
+
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.FailedPrecondition, "Failed to graft outputs of action \"3-d5b9d64d8ab5e0c5c8d3b1a2fd1e8f4b-123-example\" at path \"previous\": Output directory \"cache\" does not have a root directory digest, meaning the action must be executed with output_directory_format DIRECTORY_ONLY or TREE_AND_DIRECTORY"),
+			previousActionOutputGrafter.Populate(
+				ctx,
+				&remoteexecution.Platform{
+					Properties: []*remoteexecution.Platform_Property{
+						{Name: "graftPreviousOutputs", Value: "d5b9d64d8ab5e0c5c8d3b1a2fd1e8f4b-123:previous"},
+					},
+				},
+				inputRootDirectory,
+				errorLogger,
+				digestFunction))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		// Outputs of the previous action should be converted to
+		// a directory hierarchy that is merged into the input
+		// root at the requested path.
+		actionCache.EXPECT().Get(ctx, actionDigest).
+			Return(buffer.NewProtoBufferFromProto(&remoteexecution.ActionResult{
+				OutputFiles: []*remoteexecution.OutputFile{
+					{
+						Path: "bin/tool",
+						Digest: &remoteexecution.Digest{
+							Hash:      "5d3e7d1d3a3b47b4b5e0cd1f1e0b8f4a",
+							SizeBytes: 42,
+						},
+						IsExecutable: true,
+					},
+					{
+						Path: "bazel-out/libfoo.a",
+						Digest: &remoteexecution.Digest{
+							Hash:      "9e107d9d372bb6826bd81d3542a419d6",
+							SizeBytes: 1234,
+						},
+					},
+				},
+				OutputSymlinks: []*remoteexecution.OutputSymlink{{
+					Path:   "bin/link",
+					Target: "tool",
+				}},
+				OutputDirectories: []*remoteexecution.OutputDirectory{{
+					Path: "cache",
+					TreeDigest: &remoteexecution.Digest{
+						Hash:      "0f2a2a4e51d45d8ed8d59a3ba5ce2bde",
+						SizeBytes: 200,
+					},
+					RootDirectoryDigest: &remoteexecution.Digest{
+						Hash:      "e4d909c290d0fb1ca068ffaddf22cbd0",
+						SizeBytes: 100,
+					},
+				}},
+			}, buffer.UserProvided))
+
+		inputRootDirectory := mock.NewMockBuildDirectory(ctrl)
+		inputRootDirectory.EXPECT().Mkdir(path.MustNewComponent("state"), gomock.Any())
+		stateDirectory := mock.NewMockBuildDirectory(ctrl)
+		inputRootDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("state")).Return(stateDirectory, nil)
+		stateDirectory.EXPECT().Lstat(path.MustNewComponent("previous")).Return(filesystem.FileInfo{}, syscall.ENOENT)
+		stateDirectory.EXPECT().MergeDirectoryContents(ctx, errorLogger, gomock.Any(), nil, 1).
+			DoAndReturn(func(ctx context.Context, errorLogger util.ErrorLogger, directoryDigest digest.Digest, monitor access.UnreadDirectoryMonitor, depth int) error {
+				parentDirectory := getDirectory(directoryDigest.GetProto())
+				require.Len(t, parentDirectory.Directories, 1)
+				require.Equal(t, "previous", parentDirectory.Directories[0].Name)
+
+				rootDirectory := getDirectory(parentDirectory.Directories[0].Digest)
+				require.Len(t, rootDirectory.Directories, 3)
+				require.Equal(t, "bazel-out", rootDirectory.Directories[0].Name)
+				require.Equal(t, "bin", rootDirectory.Directories[1].Name)
+				testutil.RequireEqualProto(t, &remoteexecution.DirectoryNode{
+					Name: "cache",
+					Digest: &remoteexecution.Digest{
+						Hash:      "e4d909c290d0fb1ca068ffaddf22cbd0",
+						SizeBytes: 100,
+					},
+				}, rootDirectory.Directories[2])
+				require.Empty(t, rootDirectory.Files)
+				require.Empty(t, rootDirectory.Symlinks)
+
+				testutil.RequireEqualProto(t, &remoteexecution.Directory{
+					Files: []*remoteexecution.FileNode{{
+						Name: "libfoo.a",
+						Digest: &remoteexecution.Digest{
+							Hash:      "9e107d9d372bb6826bd81d3542a419d6",
+							SizeBytes: 1234,
+						},
+					}},
+				}, getDirectory(rootDirectory.Directories[0].Digest))
+				testutil.RequireEqualProto(t, &remoteexecution.Directory{
+					Files: []*remoteexecution.FileNode{{
+						Name: "tool",
+						Digest: &remoteexecution.Digest{
+							Hash:      "5d3e7d1d3a3b47b4b5e0cd1f1e0b8f4a",
+							SizeBytes: 42,
+						},
+						IsExecutable: true,
+					}},
+					Symlinks: []*remoteexecution.SymlinkNode{{
+						Name:   "link",
+						Target: "tool",
+					}},
+				}, getDirectory(rootDirectory.Directories[1].Digest))
+				return nil
+			})
+		stateDirectory.EXPECT().Close()
+
+		require.NoError(t, previousActionOutputGrafter.Populate(
+			ctx,
+			&remoteexecution.Platform{
+				Properties: []*remoteexecution.Platform_Property{
+					{Name: "graftPreviousOutputs", Value: "d5b9d64d8ab5e0c5c8d3b1a2fd1e8f4b-123:state/previous"},
+				},
+			},
+			inputRootDirectory,
+			errorLogger,
+			digestFunction))
+	})
+}
@@ -0,0 +1,293 @@
+package builder
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CPUUsageReader is used by the progress watchdog to obtain the
+// amount of CPU time consumed by the processes of an action.
+type CPUUsageReader interface {
+	GetCPUUsage() (time.Duration, error)
+}
+
+type progressWatchdogBuildExecutor struct {
+	BuildExecutor
+	clock              clock.Clock
+	stallTimeout       time.Duration
+	failStalledActions bool
+	errorLogger        util.ErrorLogger
+	cpuUsageReader     CPUUsageReader
+}
+
+// NewProgressWatchdogBuildExecutor creates a decorator for
+// BuildExecutor that monitors whether actions make observable
+// progress. Progress is measured by observing execution stage
+// transitions, operations against the FilePool (e.g., writes to output
+// files and reads performed while uploading them) and file system
+// accesses reported through the UnreadDirectoryMonitor. If a
+// CPUUsageReader is provided, any increase in CPU time consumed by the
+// action's processes is considered progress as well. This prevents CPU
+// bound actions that don't access the file system from being reported.
+//
+// If no progress is observed for the full duration of stallTimeout,
+// the action is considered to be stuck. Diagnostic information,
+// including the stack traces of all goroutines, is reported through
+// the ErrorLogger. If failStalledActions is set, execution of the
+// action is also cancelled, and the action is reported as having
+// failed due to an infrastructure error.
+func NewProgressWatchdogBuildExecutor(buildExecutor BuildExecutor, clock clock.Clock, stallTimeout time.Duration, failStalledActions bool, errorLogger util.ErrorLogger, cpuUsageReader CPUUsageReader) BuildExecutor {
+	return &progressWatchdogBuildExecutor{
+		BuildExecutor:      buildExecutor,
+		clock:              clock,
+		stallTimeout:       stallTimeout,
+		failStalledActions: failStalledActions,
+		errorLogger:        errorLogger,
+		cpuUsageReader:     cpuUsageReader,
+	}
+}
+
+// getCPUUsage returns the amount of CPU time consumed by the action's
+// processes. Failures to obtain it are logged, and cause no CPU time
+// to be reported. That way the watchdog falls back to only observing
+// other forms of progress.
+func (be *progressWatchdogBuildExecutor) getCPUUsage() time.Duration {
+	if be.cpuUsageReader == nil {
+		return 0
+	}
+	usage, err := be.cpuUsageReader.GetCPUUsage()
+	if err != nil {
+		be.errorLogger.Log(util.StatusWrap(err, "Failed to obtain CPU usage of action"))
+		return 0
+	}
+	return usage
+}
+
+// getExecutionStageName returns a human readable name of the stage of
+// execution that is reported by a worker.
+func getExecutionStageName(update *remoteworker.CurrentState_Executing) string {
+	if update == nil {
+		return "starting"
+	}
+	switch update.ExecutionState.(type) {
+	case *remoteworker.CurrentState_Executing_FetchingInputs:
+		return "fetching inputs"
+	case *remoteworker.CurrentState_Executing_Running:
+		return "running"
+	case *remoteworker.CurrentState_Executing_UploadingOutputs:
+		return "uploading outputs"
+	default:
+		return "completing"
+	}
+}
+
+// getGoroutineStacks returns the stack traces of all goroutines in the
+// current process.
+func getGoroutineStacks() string {
+	buf := make([]byte, 1<<16)
+	for {
+		if n := runtime.Stack(buf, true); n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+func (be *progressWatchdogBuildExecutor) Execute(ctx context.Context, filePool re_filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+	var tracker progressTracker
+	fp := progressTrackingFilePool{
+		base:    filePool,
+		tracker: &tracker,
+	}
+	if monitor != nil {
+		monitor = &progressTrackingUnreadDirectoryMonitor{
+			base:    monitor,
+			tracker: &tracker,
+		}
+	}
+
+	// Call into the underlying build executor.
+	baseCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	baseUpdates := make(chan *remoteworker.CurrentState_Executing)
+	baseCompletion := make(chan *remoteexecution.ExecuteResponse)
+	go func() {
+		baseCompletion <- be.BuildExecutor.Execute(baseCtx, &fp, monitor, digestFunction, request, baseUpdates)
+	}()
+
+	var currentState *remoteworker.CurrentState_Executing
+	var stallErr error
+	lastProgress := tracker.get()
+	lastCPUUsage := be.getCPUUsage()
+	for {
+		timer, timerChannel := be.clock.NewTimer(be.stallTimeout)
+		select {
+		case update := <-baseUpdates:
+			timer.Stop()
+			tracker.report()
+			currentState = update
+			executionStateUpdates <- update
+		case <-timerChannel:
+			progress, cpuUsage := tracker.get(), be.getCPUUsage()
+			if progress != lastProgress || cpuUsage > lastCPUUsage {
+				// Progress was made during the last
+				// interval, either through observable
+				// activity or by consuming CPU time.
+				// Reset the watchdog.
+				lastProgress, lastCPUUsage = progress, cpuUsage
+				if !be.failStalledActions {
+					stallErr = nil
+				}
+			} else if stallErr == nil {
+				stallErr = status.Errorf(codes.Unavailable, "Action made no observable progress for %s while %s", be.stallTimeout, getExecutionStageName(currentState))
+				be.errorLogger.Log(status.Errorf(
+					codes.Unavailable,
+					"Action with digest %#v: %s, with %d file pool files opened. Stacks of all goroutines:\n%s",
+					request.ActionDigest.GetHash(),
+					status.Convert(stallErr).Message(),
+					fp.getOpenFilesCount(),
+					getGoroutineStacks()))
+				if be.failStalledActions {
+					cancel()
+				}
+			}
+		case response := <-baseCompletion:
+			timer.Stop()
+			if be.failStalledActions && stallErr != nil {
+				// The action was cancelled by us. Report the
+				// stall instead of the cancellation error
+				// returned by the underlying build executor.
+				response.Status = status.Convert(stallErr).Proto()
+			}
+			return response
+		}
+	}
+}
+
+// progressTracker keeps track of the amount of progress that is made
+// by an action, by means of a counter that is incremented every time
+// progress is observed.
+type progressTracker struct {
+	counter atomic.Uint64
+}
+
+func (pt *progressTracker) report() {
+	pt.counter.Add(1)
+}
+
+func (pt *progressTracker) get() uint64 {
+	return pt.counter.Load()
+}
+
+// progressTrackingFilePool is a decorator for FilePool that reports
+// progress every time a file is created or accessed.
+type progressTrackingFilePool struct {
+	base    re_filesystem.FilePool
+	tracker *progressTracker
+
+	openFilesCount atomic.Int64
+}
+
+func (fp *progressTrackingFilePool) NewFile() (filesystem.FileReadWriter, error) {
+	fp.tracker.report()
+	f, err := fp.base.NewFile()
+	if err != nil {
+		return nil, err
+	}
+	fp.openFilesCount.Add(1)
+	return &progressTrackingFileReadWriter{
+		FileReadWriter: f,
+		pool:           fp,
+	}, nil
+}
+
+func (fp *progressTrackingFilePool) getOpenFilesCount() int64 {
+	return fp.openFilesCount.Load()
+}
+
+// progressTrackingFileReadWriter is a decorator for
+// filesystem.FileReadWriter that reports progress every time the file
+// is accessed.
+type progressTrackingFileReadWriter struct {
+	filesystem.FileReadWriter
+	pool *progressTrackingFilePool
+}
+
+func (f *progressTrackingFileReadWriter) ReadAt(p []byte, off int64) (int, error) {
+	f.pool.tracker.report()
+	return f.FileReadWriter.ReadAt(p, off)
+}
+
+func (f *progressTrackingFileReadWriter) WriteAt(p []byte, off int64) (int, error) {
+	f.pool.tracker.report()
+	return f.FileReadWriter.WriteAt(p, off)
+}
+
+func (f *progressTrackingFileReadWriter) Truncate(length int64) error {
+	f.pool.tracker.report()
+	return f.FileReadWriter.Truncate(length)
+}
+
+func (f *progressTrackingFileReadWriter) PunchHole(off, size int64) error {
+	f.pool.tracker.report()
+	return re_filesystem.PunchHole(f.FileReadWriter, off, size)
+}
+
+func (f *progressTrackingFileReadWriter) Close() error {
+	fp := f.pool
+	fp.tracker.report()
+	fp.openFilesCount.Add(-1)
+	err := f.FileReadWriter.Close()
+	f.FileReadWriter = nil
+	f.pool = nil
+	return err
+}
+
+// progressTrackingUnreadDirectoryMonitor is a decorator for
+// UnreadDirectoryMonitor that reports progress every time file system
+// access activity is reported.
+type progressTrackingUnreadDirectoryMonitor struct {
+	base    access.UnreadDirectoryMonitor
+	tracker *progressTracker
+}
+
+func (m *progressTrackingUnreadDirectoryMonitor) ReadDirectory() access.ReadDirectoryMonitor {
+	m.tracker.report()
+	return &progressTrackingReadDirectoryMonitor{
+		base:    m.base.ReadDirectory(),
+		tracker: m.tracker,
+	}
+}
+
+type progressTrackingReadDirectoryMonitor struct {
+	base    access.ReadDirectoryMonitor
+	tracker *progressTracker
+}
+
+func (m *progressTrackingReadDirectoryMonitor) ResolvedDirectory(name path.Component) access.UnreadDirectoryMonitor {
+	m.tracker.report()
+	return &progressTrackingUnreadDirectoryMonitor{
+		base:    m.base.ResolvedDirectory(name),
+		tracker: m.tracker,
+	}
+}
+
+func (m *progressTrackingReadDirectoryMonitor) ReadFile(name path.Component) {
+	m.tracker.report()
+	m.base.ReadFile(name)
+}
@@ -0,0 +1,169 @@
+package builder_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestProgressWatchdogBuildExecutor(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	request := &remoteworker.DesiredState_Executing{
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "d41d8cd98f00b204e9800998ecf8427e",
+			SizeBytes: 123,
+		},
+	}
+	digestFunction := digest.MustNewFunction("hello", remoteexecution.DigestFunction_MD5)
+
+	t.Run("ProgressThroughFilePool", func(t *testing.T) {
+		// Operations against the file pool should be considered
+		// progress. Once progress stops, the stall should only be
+		// reported, as failing stalled actions is disabled.
+		baseBuildExecutor := mock.NewMockBuildExecutor(ctrl)
+		clock := mock.NewMockClock(ctrl)
+		errorLogger := mock.NewMockErrorLogger(ctrl)
+		buildExecutor := builder.NewProgressWatchdogBuildExecutor(baseBuildExecutor, clock, time.Hour, false, errorLogger, nil)
+
+		filePool := mock.NewMockFilePool(ctrl)
+		file := mock.NewMockFileReadWriter(ctrl)
+		filePool.EXPECT().NewFile().Return(file, nil)
+		timer1 := mock.NewMockTimer(ctrl)
+		wakeup1 := make(chan time.Time, 1)
+		clock.EXPECT().NewTimer(time.Hour).Return(timer1, wakeup1)
+		timer2 := mock.NewMockTimer(ctrl)
+		wakeup2 := make(chan time.Time, 1)
+		wakeup2 <- time.Unix(7200, 0)
+		clock.EXPECT().NewTimer(time.Hour).Return(timer2, wakeup2)
+		stalled := make(chan struct{})
+		errorLogger.EXPECT().Log(gomock.Any()).Do(func(err error) {
+			require.Equal(t, codes.Unavailable, status.Code(err))
+			close(stalled)
+		})
+		timer3 := mock.NewMockTimer(ctrl)
+		clock.EXPECT().NewTimer(time.Hour).Return(timer3, nil)
+		timer3.EXPECT().Stop().Return(true)
+
+		baseBuildExecutor.EXPECT().Execute(
+			gomock.Any(),
+			gomock.Any(),
+			nil,
+			digestFunction,
+			request,
+			gomock.Any()).DoAndReturn(func(ctx context.Context, filePool filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+			_, err := filePool.NewFile()
+			require.NoError(t, err)
+			wakeup1 <- time.Unix(3600, 0)
+
+			<-stalled
+			return &remoteexecution.ExecuteResponse{
+				Result: &remoteexecution.ActionResult{ExitCode: 1},
+			}
+		})
+
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{ExitCode: 1},
+		}, buildExecutor.Execute(ctx, filePool, nil, digestFunction, request, make(chan *remoteworker.CurrentState_Executing, 10)))
+	})
+
+	t.Run("FailStalledAction", func(t *testing.T) {
+		// If the action makes no progress whatsoever, it should
+		// be cancelled and reported as an infrastructure error.
+		baseBuildExecutor := mock.NewMockBuildExecutor(ctrl)
+		clock := mock.NewMockClock(ctrl)
+		errorLogger := mock.NewMockErrorLogger(ctrl)
+		buildExecutor := builder.NewProgressWatchdogBuildExecutor(baseBuildExecutor, clock, time.Hour, true, errorLogger, nil)
+
+		timer1 := mock.NewMockTimer(ctrl)
+		wakeup1 := make(chan time.Time, 1)
+		wakeup1 <- time.Unix(3600, 0)
+		clock.EXPECT().NewTimer(time.Hour).Return(timer1, wakeup1)
+		errorLogger.EXPECT().Log(gomock.Any())
+		timer2 := mock.NewMockTimer(ctrl)
+		clock.EXPECT().NewTimer(time.Hour).Return(timer2, nil)
+		timer2.EXPECT().Stop().Return(true)
+
+		filePool := mock.NewMockFilePool(ctrl)
+		baseBuildExecutor.EXPECT().Execute(
+			gomock.Any(),
+			gomock.Any(),
+			nil,
+			digestFunction,
+			request,
+			gomock.Any()).DoAndReturn(func(ctx context.Context, filePool filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+			<-ctx.Done()
+			return &remoteexecution.ExecuteResponse{
+				Result: &remoteexecution.ActionResult{},
+				Status: status.New(codes.Canceled, "context canceled").Proto(),
+			}
+		})
+
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{},
+			Status: status.New(codes.Unavailable, "Action made no observable progress for 1h0m0s while starting").Proto(),
+		}, buildExecutor.Execute(ctx, filePool, nil, digestFunction, request, make(chan *remoteworker.CurrentState_Executing, 10)))
+	})
+
+	t.Run("ProgressThroughCPUUsage", func(t *testing.T) {
+		// Actions that consume CPU time without accessing the
+		// file system should not be considered to be stuck. Once
+		// CPU usage no longer increases, the action should be
+		// cancelled.
+		baseBuildExecutor := mock.NewMockBuildExecutor(ctrl)
+		clock := mock.NewMockClock(ctrl)
+		errorLogger := mock.NewMockErrorLogger(ctrl)
+		cpuUsageReader := mock.NewMockCPUUsageReader(ctrl)
+		buildExecutor := builder.NewProgressWatchdogBuildExecutor(baseBuildExecutor, clock, time.Hour, true, errorLogger, cpuUsageReader)
+
+		cpuUsageReader.EXPECT().GetCPUUsage().Return(10*time.Second, nil)
+		timer1 := mock.NewMockTimer(ctrl)
+		wakeup1 := make(chan time.Time, 1)
+		wakeup1 <- time.Unix(3600, 0)
+		clock.EXPECT().NewTimer(time.Hour).Return(timer1, wakeup1)
+		cpuUsageReader.EXPECT().GetCPUUsage().Return(20*time.Second, nil)
+		timer2 := mock.NewMockTimer(ctrl)
+		wakeup2 := make(chan time.Time, 1)
+		wakeup2 <- time.Unix(7200, 0)
+		clock.EXPECT().NewTimer(time.Hour).Return(timer2, wakeup2)
+		cpuUsageReader.EXPECT().GetCPUUsage().Return(20*time.Second, nil)
+		errorLogger.EXPECT().Log(gomock.Any())
+		timer3 := mock.NewMockTimer(ctrl)
+		clock.EXPECT().NewTimer(time.Hour).Return(timer3, nil)
+		timer3.EXPECT().Stop().Return(true)
+
+		filePool := mock.NewMockFilePool(ctrl)
+		baseBuildExecutor.EXPECT().Execute(
+			gomock.Any(),
+			gomock.Any(),
+			nil,
+			digestFunction,
+			request,
+			gomock.Any()).DoAndReturn(func(ctx context.Context, filePool filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+			<-ctx.Done()
+			return &remoteexecution.ExecuteResponse{
+				Result: &remoteexecution.ActionResult{},
+				Status: status.New(codes.Canceled, "context canceled").Proto(),
+			}
+		})
+
+		testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{},
+			Status: status.New(codes.Unavailable, "Action made no observable progress for 1h0m0s while starting").Proto(),
+		}, buildExecutor.Execute(ctx, filePool, nil, digestFunction, request, make(chan *remoteworker.CurrentState_Executing, 10)))
+	})
+}
@@ -0,0 +1,627 @@
+package builder
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/cas"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// buildDirectoryNameSlot is the state that BuildDirectoryNamePool
+// tracks for every name it hands out.
+type buildDirectoryNameSlot struct {
+	inUse bool
+	// The digest of the toolchain that was retained inside the
+	// subdirectory by the last build action that used it, or
+	// digest.BadDigest if the subdirectory was emptied entirely.
+	toolchainDigest digest.Digest
+}
+
+// BuildDirectoryNamePool hands out names of subdirectories of a build
+// directory that may be reused by subsequent build actions. It ensures
+// that a name is never handed out to more than one build action at a
+// time. The lowest available name is always returned first, so that
+// build actions run at the same path whenever possible.
+//
+// A single pool may be shared by multiple instances of
+// ReusingBuildDirectoryCreator (e.g., by all worker threads that use
+// the same toolchain), or be used by a single instance (e.g., to give
+// every worker thread its own subdirectory).
+type BuildDirectoryNamePool struct {
+	prefix string
+
+	lock  sync.Mutex
+	slots []buildDirectoryNameSlot
+}
+
+// NewBuildDirectoryNamePool creates a BuildDirectoryNamePool. The first
+// name returned by the pool is equal to the provided prefix. Successive
+// names have a numerical suffix appended to them.
+func NewBuildDirectoryNamePool(prefix path.Component) *BuildDirectoryNamePool {
+	return &BuildDirectoryNamePool{
+		prefix: prefix.String(),
+	}
+}
+
+func (p *BuildDirectoryNamePool) acquire() (int, path.Component, digest.Digest) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	slot := 0
+	for slot < len(p.slots) && p.slots[slot].inUse {
+		slot++
+	}
+	if slot == len(p.slots) {
+		p.slots = append(p.slots, buildDirectoryNameSlot{})
+	}
+	p.slots[slot].inUse = true
+	toolchainDigest := p.slots[slot].toolchainDigest
+	if slot == 0 {
+		return slot, path.MustNewComponent(p.prefix), toolchainDigest
+	}
+	return slot, path.MustNewComponent(p.prefix + "-" + strconv.FormatInt(int64(slot), 10)), toolchainDigest
+}
+
+func (p *BuildDirectoryNamePool) release(slot int, toolchainDigest digest.Digest) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.slots[slot].inUse {
+		panic("Attempted to release a build directory name that is not in use")
+	}
+	p.slots[slot] = buildDirectoryNameSlot{
+		toolchainDigest: toolchainDigest,
+	}
+}
+
+// ToolchainBuildDirectoryNamePools keeps track of a
+// BuildDirectoryNamePool for every toolchain used by build actions. A
+// single instance should be shared by all worker threads that use the
+// same build directory.
+type ToolchainBuildDirectoryNamePools struct {
+	lock  sync.Mutex
+	pools map[path.Component]*BuildDirectoryNamePool
+}
+
+// NewToolchainBuildDirectoryNamePools creates an empty set of
+// BuildDirectoryNamePools, keyed by toolchain.
+func NewToolchainBuildDirectoryNamePools() *ToolchainBuildDirectoryNamePools {
+	return &ToolchainBuildDirectoryNamePools{
+		pools: map[path.Component]*BuildDirectoryNamePool{},
+	}
+}
+
+func (p *ToolchainBuildDirectoryNamePools) get(toolchainDigest digest.Digest) *BuildDirectoryNamePool {
+	// Only use a small number of characters of the digest, to
+	// ensure the absolute path of the build directory remains
+	// short. Toolchains whose names collide share a pool. This is
+	// safe, as the digest of the toolchain that is retained inside
+	// each subdirectory is tracked separately.
+	name := path.MustNewComponent("toolchain-none")
+	if toolchainDigest != digest.BadDigest {
+		hash := sha256.Sum256([]byte(toolchainDigest.String()))
+		name = path.MustNewComponent("toolchain-" + hex.EncodeToString(hash[:8]))
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	pool, ok := p.pools[name]
+	if !ok {
+		pool = NewBuildDirectoryNamePool(name)
+		p.pools[name] = pool
+	}
+	return pool
+}
+
+type reusingBuildDirectoryCreator struct {
+	base BuildDirectoryCreator
+	pool *BuildDirectoryNamePool
+
+	// Fields that are only set if subdirectories are keyed by the
+	// toolchain of the build action.
+	toolchainPools            *ToolchainBuildDirectoryNamePools
+	toolchainComponents       []path.Component
+	directoryFetcher          cas.DirectoryFetcher
+	contentAddressableStorage blobstore.BlobAccess
+}
+
+// NewReusingBuildDirectoryCreator is an adapter for
+// BuildDirectoryCreator that causes build actions to be executed inside
+// a subdirectory within the build directory, whose name is obtained
+// from a BuildDirectoryNamePool. Contrary to SharedBuildDirectoryCreator,
+// the subdirectory is not removed after the build action completes.
+// It is merely emptied, so that it can be reused by the next build
+// action that obtains the same name.
+//
+// This allows build actions to run at a stable absolute path, which is
+// beneficial for tools that embed the working directory into their
+// outputs. To guarantee that build actions can't see data left behind
+// by ones that ran previously, the subdirectory is validated to be
+// empty prior to reuse.
+func NewReusingBuildDirectoryCreator(base BuildDirectoryCreator, pool *BuildDirectoryNamePool) BuildDirectoryCreator {
+	return &reusingBuildDirectoryCreator{
+		base: base,
+		pool: pool,
+	}
+}
+
+// NewToolchainReusingBuildDirectoryCreator is similar to
+// NewReusingBuildDirectoryCreator, except that the name of the
+// subdirectory is obtained from a pool that is selected based on the
+// toolchain of the build action. The toolchain is the directory at a
+// given path in the input root of the build action. It is identified
+// by its digest.
+//
+// When the build action completes, the toolchain is left behind inside
+// the subdirectory, while everything else is removed. The next build
+// action that obtains the same subdirectory and uses the same toolchain
+// only needs to have the remainder of its input root populated. The
+// contents of the toolchain are compared against the Content
+// Addressable Storage prior to reuse, so that modifications made by
+// earlier build actions are never observed.
+//
+// Writes against the Content Addressable Storage must be visible to the
+// DirectoryFetcher immediately, meaning that batching BlobAccess
+// implementations may not be used.
+func NewToolchainReusingBuildDirectoryCreator(base BuildDirectoryCreator, pools *ToolchainBuildDirectoryNamePools, toolchainDirectoryPath string, directoryFetcher cas.DirectoryFetcher, contentAddressableStorage blobstore.BlobAccess) (BuildDirectoryCreator, error) {
+	var toolchainPath outputNodePath
+	if err := path.Resolve(toolchainDirectoryPath, path.NewRelativeScopeWalker(&toolchainPath)); err != nil {
+		return nil, util.StatusWrapf(err, "Invalid toolchain directory path %#v", toolchainDirectoryPath)
+	}
+	if len(toolchainPath.components) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Toolchain directory path %#v resolves to the input root directory", toolchainDirectoryPath)
+	}
+	return &reusingBuildDirectoryCreator{
+		base:                      base,
+		toolchainPools:            pools,
+		toolchainComponents:       toolchainPath.components,
+		directoryFetcher:          directoryFetcher,
+		contentAddressableStorage: contentAddressableStorage,
+	}, nil
+}
+
+// getToolchainDigest returns the digest of the toolchain directory
+// contained in the input root of the build action. digest.BadDigest is
+// returned if the input root does not contain a toolchain directory.
+func (dc *reusingBuildDirectoryCreator) getToolchainDigest(ctx context.Context) (digest.Digest, digest.Digest, error) {
+	inputRootDigest, ok := getInputRootDigest(ctx)
+	if !ok {
+		return digest.BadDigest, digest.BadDigest, nil
+	}
+	directoryDigest := inputRootDigest
+	for _, component := range dc.toolchainComponents {
+		directory, err := dc.directoryFetcher.GetDirectory(ctx, directoryDigest)
+		if err != nil {
+			return digest.BadDigest, digest.BadDigest, util.StatusWrap(err, "Failed to obtain parent directory of toolchain")
+		}
+		found := false
+		for _, directoryNode := range directory.Directories {
+			if directoryNode.Name == component.String() {
+				directoryDigest, err = directoryDigest.GetDigestFunction().NewDigestFromProto(directoryNode.Digest)
+				if err != nil {
+					return digest.BadDigest, digest.BadDigest, util.StatusWrapf(err, "Failed to extract digest for directory %#v", component.String())
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return inputRootDigest, digest.BadDigest, nil
+		}
+	}
+	return inputRootDigest, directoryDigest, nil
+}
+
+func (dc *reusingBuildDirectoryCreator) GetBuildDirectory(ctx context.Context, actionDigestIfNotRunInParallel *digest.Digest) (BuildDirectory, *path.Trace, error) {
+	parentDirectory, parentDirectoryPath, err := dc.base.GetBuildDirectory(ctx, actionDigestIfNotRunInParallel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pool := dc.pool
+	inputRootDigest, toolchainDigest := digest.BadDigest, digest.BadDigest
+	if dc.toolchainPools != nil {
+		inputRootDigest, toolchainDigest, err = dc.getToolchainDigest(ctx)
+		if err != nil {
+			parentDirectory.Close()
+			return nil, nil, err
+		}
+		pool = dc.toolchainPools.get(toolchainDigest)
+	}
+
+	// Create the subdirectory if it doesn't exist already.
+	slot, childDirectoryName, retainedToolchainDigest := pool.acquire()
+	childDirectoryPath := parentDirectoryPath.Append(childDirectoryName)
+	err = parentDirectory.Mkdir(childDirectoryName, 0o777)
+	if err != nil && !os.IsExist(err) {
+		pool.release(slot, digest.BadDigest)
+		parentDirectory.Close()
+		return nil, nil, util.StatusWrapfWithCode(err, codes.Internal, "Failed to create build directory %#v", childDirectoryPath.String())
+	}
+	childDirectoryExisted := err != nil
+	childDirectory, err := parentDirectory.EnterBuildDirectory(childDirectoryName)
+	if err != nil {
+		pool.release(slot, digest.BadDigest)
+		parentDirectory.Close()
+		return nil, nil, util.StatusWrapfWithCode(err, codes.Internal, "Failed to enter build directory %#v", childDirectoryPath.String())
+	}
+
+	d := &reusingBuildDirectory{
+		BuildDirectory:     childDirectory,
+		creator:            dc,
+		parentDirectory:    parentDirectory,
+		pool:               pool,
+		slot:               slot,
+		childDirectoryPath: childDirectoryPath.String(),
+		inputRootDigest:    inputRootDigest,
+		toolchainDigest:    toolchainDigest,
+	}
+
+	// Only keep the toolchain that was left behind by the previous
+	// build action if it is the one that is needed by this build
+	// action, and it was not modified by the previous build action.
+	if childDirectoryExisted && toolchainDigest != digest.BadDigest && retainedToolchainDigest == toolchainDigest {
+		intact, err := dc.isToolchainIntact(ctx, childDirectory, toolchainDigest)
+		if err != nil {
+			childDirectory.Close()
+			pool.release(slot, digest.BadDigest)
+			parentDirectory.Close()
+			return nil, nil, util.StatusWrapfWithCode(err, codes.Internal, "Failed to validate toolchain in build directory %#v", childDirectoryPath.String())
+		}
+		if intact {
+			d.toolchainRetained = true
+		} else {
+			log.Printf("Discarding toolchain in build directory %#v, as it was modified", childDirectoryPath.String())
+		}
+	}
+
+	// Ensure that the subdirectory does not contain any files left
+	// behind by build actions that ran previously. This may happen
+	// if emptying it failed after the previous build action.
+	var removed bool
+	if d.toolchainRetained {
+		removed, err = removeAllChildrenExcept(childDirectory, dc.getRetainedComponents())
+	} else {
+		removed, err = removeAllChildren(childDirectory)
+	}
+	if err != nil {
+		childDirectory.Close()
+		pool.release(slot, digest.BadDigest)
+		parentDirectory.Close()
+		return nil, nil, util.StatusWrapfWithCode(err, codes.Internal, "Failed to empty build directory %#v prior to reuse", childDirectoryPath.String())
+	} else if removed {
+		log.Printf("Removed leftover files from build directory %#v prior to reuse", childDirectoryPath.String())
+	}
+	return d, childDirectoryPath, nil
+}
+
+// getRetainedComponents returns the path of the toolchain directory,
+// relative to the build directory.
+func (dc *reusingBuildDirectoryCreator) getRetainedComponents() []path.Component {
+	return append([]path.Component{inputRootDirectoryComponent}, dc.toolchainComponents...)
+}
+
+// isToolchainIntact returns whether the toolchain directory that was
+// left behind inside a build directory still has the contents of the
+// Directory objects stored in the Content Addressable Storage.
+func (dc *reusingBuildDirectoryCreator) isToolchainIntact(ctx context.Context, buildDirectory BuildDirectory, toolchainDigest digest.Digest) (bool, error) {
+	d := buildDirectory
+	for _, component := range dc.getRetainedComponents() {
+		child, err := d.EnterBuildDirectory(component)
+		if d != buildDirectory {
+			d.Close()
+		}
+		if err != nil {
+			if os.IsNotExist(err) || errors.Is(err, syscall.ENOTDIR) {
+				return false, nil
+			}
+			return false, util.StatusWrapf(err, "Failed to enter directory %#v", component.String())
+		}
+		d = child
+	}
+	defer d.Close()
+	return dc.directoryMatchesDigest(ctx, d, toolchainDigest)
+}
+
+func (dc *reusingBuildDirectoryCreator) directoryMatchesDigest(ctx context.Context, d BuildDirectory, directoryDigest digest.Digest) (bool, error) {
+	directory, err := dc.directoryFetcher.GetDirectory(ctx, directoryDigest)
+	if err != nil {
+		return false, util.StatusWrap(err, "Failed to obtain directory")
+	}
+	entries, err := d.ReadDir()
+	if err != nil {
+		return false, util.StatusWrap(err, "Failed to read directory")
+	}
+	if len(entries) != len(directory.Files)+len(directory.Directories)+len(directory.Symlinks) {
+		return false, nil
+	}
+	entriesByName := make(map[string]filesystem.FileInfo, len(entries))
+	for _, entry := range entries {
+		entriesByName[entry.Name().String()] = entry
+	}
+
+	digestFunction := directoryDigest.GetDigestFunction()
+	for _, file := range directory.Files {
+		entry, ok := entriesByName[file.Name]
+		if !ok || entry.Type() != filesystem.FileTypeRegularFile || entry.IsExecutable() != file.IsExecutable {
+			return false, nil
+		}
+		fileDigest, err := digestFunction.NewDigestFromProto(file.Digest)
+		if err != nil {
+			return false, util.StatusWrapf(err, "Failed to extract digest for file %#v", file.Name)
+		}
+		if matches, err := fileMatchesDigest(d, entry.Name(), fileDigest); err != nil || !matches {
+			return false, err
+		}
+	}
+	for _, directoryNode := range directory.Directories {
+		entry, ok := entriesByName[directoryNode.Name]
+		if !ok || entry.Type() != filesystem.FileTypeDirectory {
+			return false, nil
+		}
+		childDigest, err := digestFunction.NewDigestFromProto(directoryNode.Digest)
+		if err != nil {
+			return false, util.StatusWrapf(err, "Failed to extract digest for directory %#v", directoryNode.Name)
+		}
+		child, err := d.EnterBuildDirectory(entry.Name())
+		if err != nil {
+			return false, util.StatusWrapf(err, "Failed to enter directory %#v", directoryNode.Name)
+		}
+		matches, err := dc.directoryMatchesDigest(ctx, child, childDigest)
+		child.Close()
+		if err != nil {
+			return false, util.StatusWrapf(err, "Directory %#v", directoryNode.Name)
+		}
+		if !matches {
+			return false, nil
+		}
+	}
+	for _, symlink := range directory.Symlinks {
+		entry, ok := entriesByName[symlink.Name]
+		if !ok || entry.Type() != filesystem.FileTypeSymlink {
+			return false, nil
+		}
+		target, err := d.Readlink(entry.Name())
+		if err != nil {
+			return false, util.StatusWrapf(err, "Failed to read symbolic link %#v", symlink.Name)
+		}
+		if target != symlink.Target {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// fileMatchesDigest returns whether the contents of a file correspond
+// to a given digest.
+func fileMatchesDigest(d BuildDirectory, name path.Component, fileDigest digest.Digest) (bool, error) {
+	f, err := d.OpenRead(name)
+	if err != nil {
+		return false, util.StatusWrapf(err, "Failed to open file %#v", name.String())
+	}
+	defer f.Close()
+
+	// Read one more byte than expected, so that files that have
+	// grown are detected as well.
+	sizeBytes := fileDigest.GetSizeBytes()
+	generator := fileDigest.GetDigestFunction().NewGenerator(sizeBytes)
+	if _, err := io.Copy(generator, io.NewSectionReader(f, 0, sizeBytes+1)); err != nil {
+		return false, util.StatusWrapf(err, "Failed to read file %#v", name.String())
+	}
+	return generator.Sum() == fileDigest, nil
+}
+
+// mergeDirectoryContentsAroundToolchain populates a directory in the
+// input root with the contents of a Directory stored in the Content
+// Addressable Storage, except for the toolchain directory, which is
+// already present.
+//
+// This is achieved by storing a copy of every Directory along the path
+// of the toolchain directory in the Content Addressable Storage that
+// lacks the entry leading to the toolchain directory, and merging that
+// instead.
+func (dc *reusingBuildDirectoryCreator) mergeDirectoryContentsAroundToolchain(ctx context.Context, d BuildDirectory, errorLogger util.ErrorLogger, directoryDigest digest.Digest, components []path.Component, depth int) error {
+	directory, err := dc.directoryFetcher.GetDirectory(ctx, directoryDigest)
+	if err != nil {
+		return util.StatusWrap(err, "Failed to obtain input directory")
+	}
+	remainder := &remoteexecution.Directory{
+		Files:          directory.Files,
+		Symlinks:       directory.Symlinks,
+		NodeProperties: directory.NodeProperties,
+	}
+	var toolchainDirectoryNode *remoteexecution.DirectoryNode
+	for _, directoryNode := range directory.Directories {
+		if directoryNode.Name == components[0].String() {
+			toolchainDirectoryNode = directoryNode
+		} else {
+			remainder.Directories = append(remainder.Directories, directoryNode)
+		}
+	}
+	if toolchainDirectoryNode == nil {
+		return status.Errorf(codes.Internal, "Input directory does not contain directory %#v", components[0].String())
+	}
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(remainder)
+	if err != nil {
+		return util.StatusWrap(err, "Failed to marshal input directory")
+	}
+	digestFunction := directoryDigest.GetDigestFunction()
+	remainderDigest, err := putBlob(ctx, dc.contentAddressableStorage, digestFunction, data)
+	if err != nil {
+		return util.StatusWrap(err, "Failed to store input directory")
+	}
+	if err := d.MergeDirectoryContents(ctx, errorLogger, remainderDigest, nil, depth); err != nil {
+		return err
+	}
+	if len(components) == 1 {
+		return nil
+	}
+
+	childDigest, err := digestFunction.NewDigestFromProto(toolchainDirectoryNode.Digest)
+	if err != nil {
+		return util.StatusWrapf(err, "Failed to extract digest for directory %#v", components[0].String())
+	}
+	child, err := d.EnterBuildDirectory(components[0])
+	if err != nil {
+		return util.StatusWrapf(err, "Failed to enter directory %#v", components[0].String())
+	}
+	defer child.Close()
+	return dc.mergeDirectoryContentsAroundToolchain(ctx, child, errorLogger, childDigest, components[1:], depth+1)
+}
+
+// removeAllChildren removes all files and directories contained in a
+// build directory. It returns whether any files were present.
+func removeAllChildren(d BuildDirectory) (bool, error) {
+	entries, err := d.ReadDir()
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if err := d.RemoveAll(entry.Name()); err != nil {
+			return true, err
+		}
+	}
+	return len(entries) > 0, nil
+}
+
+// removeAllChildrenExcept removes all files and directories contained
+// in a build directory, except for a single directory at a given path.
+// It returns whether any files were removed.
+func removeAllChildrenExcept(d BuildDirectory, components []path.Component) (bool, error) {
+	entries, err := d.ReadDir()
+	if err != nil {
+		return false, err
+	}
+	removed := false
+	for _, entry := range entries {
+		if entry.Name() != components[0] || entry.Type() != filesystem.FileTypeDirectory {
+			if err := d.RemoveAll(entry.Name()); err != nil {
+				return true, err
+			}
+			removed = true
+		}
+	}
+	if len(components) == 1 {
+		return removed, nil
+	}
+
+	child, err := d.EnterBuildDirectory(components[0])
+	if err != nil {
+		return removed, err
+	}
+	childRemoved, err := removeAllChildrenExcept(child, components[1:])
+	child.Close()
+	return removed || childRemoved, err
+}
+
+type reusingBuildDirectory struct {
+	BuildDirectory
+	creator            *reusingBuildDirectoryCreator
+	parentDirectory    BuildDirectory
+	pool               *BuildDirectoryNamePool
+	slot               int
+	childDirectoryPath string
+	inputRootDigest    digest.Digest
+	toolchainDigest    digest.Digest
+
+	// Whether the toolchain was left behind by a previous build
+	// action, and whether it is fully present after populating the
+	// input root.
+	toolchainRetained  bool
+	toolchainPopulated bool
+}
+
+func (d *reusingBuildDirectory) Mkdir(name path.Component, perm os.FileMode) error {
+	if d.toolchainRetained && name == inputRootDirectoryComponent {
+		// The input root directory was retained, as it contains
+		// the toolchain directory.
+		return nil
+	}
+	return d.BuildDirectory.Mkdir(name, perm)
+}
+
+func (d *reusingBuildDirectory) EnterBuildDirectory(name path.Component) (BuildDirectory, error) {
+	child, err := d.BuildDirectory.EnterBuildDirectory(name)
+	if err != nil || d.toolchainDigest == digest.BadDigest || name != inputRootDirectoryComponent {
+		return child, err
+	}
+	return &toolchainInputRootDirectory{
+		BuildDirectory: child,
+		parent:         d,
+	}, nil
+}
+
+func (d *reusingBuildDirectory) Close() error {
+	// Retain the toolchain directory if it was populated
+	// successfully. Its contents are validated prior to reuse.
+	var err1 error
+	retainedToolchainDigest := digest.BadDigest
+	if d.toolchainPopulated {
+		_, err1 = removeAllChildrenExcept(d.BuildDirectory, d.creator.getRetainedComponents())
+		if err1 == nil {
+			retainedToolchainDigest = d.toolchainDigest
+		}
+	} else {
+		_, err1 = removeAllChildren(d.BuildDirectory)
+	}
+	err2 := d.BuildDirectory.Close()
+	d.pool.release(d.slot, retainedToolchainDigest)
+	err3 := d.parentDirectory.Close()
+	if err1 != nil {
+		return util.StatusWrapfWithCode(err1, codes.Internal, "Failed to empty build directory %#v", d.childDirectoryPath)
+	}
+	if err2 != nil {
+		return util.StatusWrapf(err2, "Failed to close build directory %#v", d.childDirectoryPath)
+	}
+	return err3
+}
+
+// toolchainInputRootDirectory is the input root directory of a build
+// action running inside a build directory that is keyed by toolchain.
+// It keeps track of whether the toolchain directory has been
+// populated. If the toolchain directory was retained, only the
+// remainder of the input root is populated.
+type toolchainInputRootDirectory struct {
+	BuildDirectory
+	parent *reusingBuildDirectory
+}
+
+func (d *toolchainInputRootDirectory) MergeDirectoryContents(ctx context.Context, errorLogger util.ErrorLogger, directoryDigest digest.Digest, monitor access.UnreadDirectoryMonitor, depth int) error {
+	p := d.parent
+	if directoryDigest != p.inputRootDigest || depth != 0 {
+		// Additional contents placed inside the input root
+		// (e.g., shared caches).
+		return d.BuildDirectory.MergeDirectoryContents(ctx, errorLogger, directoryDigest, monitor, depth)
+	}
+
+	if p.toolchainRetained {
+		if err := p.creator.mergeDirectoryContentsAroundToolchain(ctx, d.BuildDirectory, errorLogger, directoryDigest, p.creator.toolchainComponents, depth); err != nil {
+			return err
+		}
+	} else if err := d.BuildDirectory.MergeDirectoryContents(ctx, errorLogger, directoryDigest, monitor, depth); err != nil {
+		return err
+	}
+	p.toolchainPopulated = true
+	return nil
+}
@@ -0,0 +1,394 @@
+package builder_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReusingBuildDirectoryCreator(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseBuildDirectoryCreator := mock.NewMockBuildDirectoryCreator(ctrl)
+	baseBuildDirectoryPath := ((*path.Trace)(nil)).Append(path.MustNewComponent("base-directory"))
+	actionDigest := digest.MustNewDigest("debian8", remoteexecution.DigestFunction_SHA256, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", 0)
+	pool := builder.NewBuildDirectoryNamePool(path.MustNewComponent("toolchain"))
+	buildDirectoryCreator := builder.NewReusingBuildDirectoryCreator(baseBuildDirectoryCreator, pool)
+
+	t.Run("MkdirFailure", func(t *testing.T) {
+		// Failure to create a build subdirectory is always an
+		// internal error.
+		baseBuildDirectory := mock.NewMockBuildDirectory(ctrl)
+		baseBuildDirectoryCreator.EXPECT().GetBuildDirectory(ctx, &actionDigest).
+			Return(baseBuildDirectory, baseBuildDirectoryPath, nil)
+		baseBuildDirectory.EXPECT().Mkdir(path.MustNewComponent("toolchain"), os.FileMode(0o777)).
+			Return(status.Error(codes.ResourceExhausted, "No space left on device"))
+		baseBuildDirectory.EXPECT().Close()
+
+		_, _, err := buildDirectoryCreator.GetBuildDirectory(ctx, &actionDigest)
+		testutil.RequireEqualStatus(t, status.Error(codes.Internal, "Failed to create build directory \"base-directory/toolchain\": No space left on device"), err)
+	})
+
+	t.Run("ReuseWithLeftovers", func(t *testing.T) {
+		// If the subdirectory already exists, it should be
+		// reused. Any files left behind by previous build
+		// actions must be removed before the build directory is
+		// returned.
+		baseBuildDirectory := mock.NewMockBuildDirectory(ctrl)
+		baseBuildDirectoryCreator.EXPECT().GetBuildDirectory(ctx, &actionDigest).
+			Return(baseBuildDirectory, baseBuildDirectoryPath, nil)
+		baseBuildDirectory.EXPECT().Mkdir(path.MustNewComponent("toolchain"), os.FileMode(0o777)).
+			Return(syscall.EEXIST)
+		subDirectory := mock.NewMockBuildDirectory(ctrl)
+		baseBuildDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("toolchain")).Return(subDirectory, nil)
+		subDirectory.EXPECT().ReadDir().Return([]filesystem.FileInfo{
+			filesystem.NewFileInfo(path.MustNewComponent("stale"), filesystem.FileTypeDirectory, false),
+		}, nil)
+		subDirectory.EXPECT().RemoveAll(path.MustNewComponent("stale"))
+
+		buildDirectory, buildDirectoryPath, err := buildDirectoryCreator.GetBuildDirectory(ctx, &actionDigest)
+		require.NoError(t, err)
+		require.Equal(t, baseBuildDirectoryPath.Append(path.MustNewComponent("toolchain")), buildDirectoryPath)
+
+		// Closing the build directory should cause it to be
+		// emptied, but not removed.
+		subDirectory.EXPECT().ReadDir().Return([]filesystem.FileInfo{
+			filesystem.NewFileInfo(path.MustNewComponent("output"), filesystem.FileTypeRegularFile, false),
+		}, nil)
+		subDirectory.EXPECT().RemoveAll(path.MustNewComponent("output"))
+		subDirectory.EXPECT().Close()
+		baseBuildDirectory.EXPECT().Close()
+
+		require.NoError(t, buildDirectory.Close())
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		// Build actions that run concurrently should each get
+		// their own subdirectory. Names should be released
+		// upon closure, so that they can be reused.
+		baseBuildDirectory1 := mock.NewMockBuildDirectory(ctrl)
+		baseBuildDirectoryCreator.EXPECT().GetBuildDirectory(ctx, nil).
+			Return(baseBuildDirectory1, baseBuildDirectoryPath, nil)
+		baseBuildDirectory1.EXPECT().Mkdir(path.MustNewComponent("toolchain"), os.FileMode(0o777))
+		subDirectory1 := mock.NewMockBuildDirectory(ctrl)
+		baseBuildDirectory1.EXPECT().EnterBuildDirectory(path.MustNewComponent("toolchain")).Return(subDirectory1, nil)
+		subDirectory1.EXPECT().ReadDir()
+
+		buildDirectory1, buildDirectoryPath1, err := buildDirectoryCreator.GetBuildDirectory(ctx, nil)
+		require.NoError(t, err)
+		require.Equal(t, baseBuildDirectoryPath.Append(path.MustNewComponent("toolchain")), buildDirectoryPath1)
+
+		baseBuildDirectory2 := mock.NewMockBuildDirectory(ctrl)
+		baseBuildDirectoryCreator.EXPECT().GetBuildDirectory(ctx, nil).
+			Return(baseBuildDirectory2, baseBuildDirectoryPath, nil)
+		baseBuildDirectory2.EXPECT().Mkdir(path.MustNewComponent("toolchain-1"), os.FileMode(0o777))
+		subDirectory2 := mock.NewMockBuildDirectory(ctrl)
+		baseBuildDirectory2.EXPECT().EnterBuildDirectory(path.MustNewComponent("toolchain-1")).Return(subDirectory2, nil)
+		subDirectory2.EXPECT().ReadDir()
+
+		buildDirectory2, buildDirectoryPath2, err := buildDirectoryCreator.GetBuildDirectory(ctx, nil)
+		require.NoError(t, err)
+		require.Equal(t, baseBuildDirectoryPath.Append(path.MustNewComponent("toolchain-1")), buildDirectoryPath2)
+
+		// Errors that occur while emptying the build directory
+		// should be propagated. The name should still be
+		// released, as leftover files are removed upon reuse.
+		subDirectory1.EXPECT().ReadDir().Return(nil, status.Error(codes.Internal, "Bad file descriptor"))
+		subDirectory1.EXPECT().Close()
+		baseBuildDirectory1.EXPECT().Close()
+
+		testutil.RequireEqualStatus(t, status.Error(codes.Internal, "Failed to empty build directory \"base-directory/toolchain\": Bad file descriptor"), buildDirectory1.Close())
+
+		baseBuildDirectory3 := mock.NewMockBuildDirectory(ctrl)
+		baseBuildDirectoryCreator.EXPECT().GetBuildDirectory(ctx, nil).
+			Return(baseBuildDirectory3, baseBuildDirectoryPath, nil)
+		baseBuildDirectory3.EXPECT().Mkdir(path.MustNewComponent("toolchain"), os.FileMode(0o777)).Return(syscall.EEXIST)
+		subDirectory3 := mock.NewMockBuildDirectory(ctrl)
+		baseBuildDirectory3.EXPECT().EnterBuildDirectory(path.MustNewComponent("toolchain")).Return(subDirectory3, nil)
+		subDirectory3.EXPECT().ReadDir()
+
+		buildDirectory3, buildDirectoryPath3, err := buildDirectoryCreator.GetBuildDirectory(ctx, nil)
+		require.NoError(t, err)
+		require.Equal(t, baseBuildDirectoryPath.Append(path.MustNewComponent("toolchain")), buildDirectoryPath3)
+
+		for _, d := range []struct {
+			buildDirectory     builder.BuildDirectory
+			subDirectory       *mock.MockBuildDirectory
+			baseBuildDirectory *mock.MockBuildDirectory
+		}{
+			{buildDirectory2, subDirectory2, baseBuildDirectory2},
+			{buildDirectory3, subDirectory3, baseBuildDirectory3},
+		} {
+			d.subDirectory.EXPECT().ReadDir()
+			d.subDirectory.EXPECT().Close()
+			d.baseBuildDirectory.EXPECT().Close()
+			require.NoError(t, d.buildDirectory.Close())
+		}
+	})
+}
+
+func TestToolchainReusingBuildDirectoryCreator(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseBuildDirectoryCreator := mock.NewMockBuildDirectoryCreator(ctrl)
+	baseBuildDirectoryPath := ((*path.Trace)(nil)).Append(path.MustNewComponent("base-directory"))
+	directoryFetcher := mock.NewMockDirectoryFetcher(ctrl)
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	buildDirectoryCreator, err := builder.NewToolchainReusingBuildDirectoryCreator(
+		baseBuildDirectoryCreator,
+		builder.NewToolchainBuildDirectoryNamePools(),
+		"external/toolchain",
+		directoryFetcher,
+		contentAddressableStorage)
+	require.NoError(t, err)
+
+	// An input root that contains a toolchain at the configured
+	// path, consisting of a single executable.
+	compilerGenerator := digest.MustNewFunction("debian8", remoteexecution.DigestFunction_SHA256).NewGenerator(2)
+	compilerGenerator.Write([]byte("cc"))
+	compilerDigest := compilerGenerator.Sum()
+	toolchainDirectory := &remoteexecution.Directory{
+		Files: []*remoteexecution.FileNode{{
+			Name:         "cc",
+			Digest:       compilerDigest.GetProto(),
+			IsExecutable: true,
+		}},
+	}
+	toolchainDigest := digest.MustNewDigest("debian8", remoteexecution.DigestFunction_SHA256, "1111111111111111111111111111111111111111111111111111111111111111", 100)
+	externalDirectory := &remoteexecution.Directory{
+		Directories: []*remoteexecution.DirectoryNode{{
+			Name:   "toolchain",
+			Digest: toolchainDigest.GetProto(),
+		}},
+	}
+	externalDigest := digest.MustNewDigest("debian8", remoteexecution.DigestFunction_SHA256, "2222222222222222222222222222222222222222222222222222222222222222", 100)
+	inputRootDirectory := &remoteexecution.Directory{
+		Files: []*remoteexecution.FileNode{{
+			Name:   "main.c",
+			Digest: compilerDigest.GetProto(),
+		}},
+		Directories: []*remoteexecution.DirectoryNode{{
+			Name:   "external",
+			Digest: externalDigest.GetProto(),
+		}},
+	}
+	inputRootDigest := digest.MustNewDigest("debian8", remoteexecution.DigestFunction_SHA256, "3333333333333333333333333333333333333333333333333333333333333333", 100)
+	ctxWithInputRoot := builder.NewContextWithInputRootDigest(ctx, inputRootDigest)
+
+	// The name of the subdirectory is derived from the digest of
+	// the toolchain.
+	hash := sha256.Sum256([]byte(toolchainDigest.String()))
+	subDirectoryName := path.MustNewComponent("toolchain-" + hex.EncodeToString(hash[:8]))
+
+	expectToolchainLookup := func() {
+		directoryFetcher.EXPECT().GetDirectory(ctxWithInputRoot, inputRootDigest).Return(inputRootDirectory, nil)
+		directoryFetcher.EXPECT().GetDirectory(ctxWithInputRoot, externalDigest).Return(externalDirectory, nil)
+	}
+	expectEmptyingExceptToolchain := func(subDirectory *mock.MockBuildDirectory, leftovers ...filesystem.FileInfo) {
+		subDirectory.EXPECT().ReadDir().Return(append([]filesystem.FileInfo{
+			filesystem.NewFileInfo(path.MustNewComponent("root"), filesystem.FileTypeDirectory, false),
+		}, leftovers...), nil)
+		for _, leftover := range leftovers {
+			subDirectory.EXPECT().RemoveAll(leftover.Name())
+		}
+		rootDirectory := mock.NewMockBuildDirectory(ctrl)
+		subDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("root")).Return(rootDirectory, nil)
+		rootDirectory.EXPECT().ReadDir().Return([]filesystem.FileInfo{
+			filesystem.NewFileInfo(path.MustNewComponent("external"), filesystem.FileTypeDirectory, false),
+		}, nil)
+		externalBuildDirectory := mock.NewMockBuildDirectory(ctrl)
+		rootDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("external")).Return(externalBuildDirectory, nil)
+		externalBuildDirectory.EXPECT().ReadDir().Return([]filesystem.FileInfo{
+			filesystem.NewFileInfo(path.MustNewComponent("toolchain"), filesystem.FileTypeDirectory, false),
+		}, nil)
+		externalBuildDirectory.EXPECT().Close()
+		rootDirectory.EXPECT().Close()
+	}
+	expectToolchainValidation := func(subDirectory *mock.MockBuildDirectory, compilerContents string) {
+		rootDirectory := mock.NewMockBuildDirectory(ctrl)
+		subDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("root")).Return(rootDirectory, nil)
+		externalBuildDirectory := mock.NewMockBuildDirectory(ctrl)
+		rootDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("external")).Return(externalBuildDirectory, nil)
+		rootDirectory.EXPECT().Close()
+		toolchainBuildDirectory := mock.NewMockBuildDirectory(ctrl)
+		externalBuildDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("toolchain")).Return(toolchainBuildDirectory, nil)
+		externalBuildDirectory.EXPECT().Close()
+		directoryFetcher.EXPECT().GetDirectory(ctxWithInputRoot, toolchainDigest).Return(toolchainDirectory, nil)
+		toolchainBuildDirectory.EXPECT().ReadDir().Return([]filesystem.FileInfo{
+			filesystem.NewFileInfo(path.MustNewComponent("cc"), filesystem.FileTypeRegularFile, true),
+		}, nil)
+		compiler := mock.NewMockFileReader(ctrl)
+		toolchainBuildDirectory.EXPECT().OpenRead(path.MustNewComponent("cc")).Return(compiler, nil)
+		compiler.EXPECT().ReadAt(gomock.Any(), int64(0)).DoAndReturn(func(p []byte, off int64) (int, error) {
+			return copy(p, compilerContents), io.EOF
+		})
+		compiler.EXPECT().Close()
+		toolchainBuildDirectory.EXPECT().Close()
+	}
+
+	t.Run("InitialPopulation", func(t *testing.T) {
+		// The first build action using the toolchain should
+		// have its input root populated entirely.
+		baseBuildDirectory := mock.NewMockBuildDirectory(ctrl)
+		baseBuildDirectoryCreator.EXPECT().GetBuildDirectory(ctxWithInputRoot, nil).
+			Return(baseBuildDirectory, baseBuildDirectoryPath, nil)
+		expectToolchainLookup()
+		baseBuildDirectory.EXPECT().Mkdir(subDirectoryName, os.FileMode(0o777))
+		subDirectory := mock.NewMockBuildDirectory(ctrl)
+		baseBuildDirectory.EXPECT().EnterBuildDirectory(subDirectoryName).Return(subDirectory, nil)
+		subDirectory.EXPECT().ReadDir()
+
+		buildDirectory, buildDirectoryPath, err := buildDirectoryCreator.GetBuildDirectory(ctxWithInputRoot, nil)
+		require.NoError(t, err)
+		require.Equal(t, baseBuildDirectoryPath.Append(subDirectoryName), buildDirectoryPath)
+
+		subDirectory.EXPECT().Mkdir(path.MustNewComponent("root"), os.FileMode(0o777))
+		require.NoError(t, buildDirectory.Mkdir(path.MustNewComponent("root"), 0o777))
+		rootDirectory := mock.NewMockBuildDirectory(ctrl)
+		subDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("root")).Return(rootDirectory, nil)
+		inputRoot, err := buildDirectory.EnterBuildDirectory(path.MustNewComponent("root"))
+		require.NoError(t, err)
+		errorLogger := mock.NewMockErrorLogger(ctrl)
+		rootDirectory.EXPECT().MergeDirectoryContents(ctx, errorLogger, inputRootDigest, nil, 0)
+		require.NoError(t, inputRoot.MergeDirectoryContents(ctx, errorLogger, inputRootDigest, nil, 0))
+		rootDirectory.EXPECT().Close()
+		require.NoError(t, inputRoot.Close())
+
+		// Upon completion, everything except the toolchain
+		// should be removed.
+		expectEmptyingExceptToolchain(subDirectory, filesystem.NewFileInfo(path.MustNewComponent("tmp"), filesystem.FileTypeDirectory, false))
+		subDirectory.EXPECT().Close()
+		baseBuildDirectory.EXPECT().Close()
+		require.NoError(t, buildDirectory.Close())
+	})
+
+	t.Run("Reuse", func(t *testing.T) {
+		// The next build action using the toolchain should only
+		// have the parts of its input root populated that
+		// surround the toolchain.
+		baseBuildDirectory := mock.NewMockBuildDirectory(ctrl)
+		baseBuildDirectoryCreator.EXPECT().GetBuildDirectory(ctxWithInputRoot, nil).
+			Return(baseBuildDirectory, baseBuildDirectoryPath, nil)
+		expectToolchainLookup()
+		baseBuildDirectory.EXPECT().Mkdir(subDirectoryName, os.FileMode(0o777)).Return(syscall.EEXIST)
+		subDirectory := mock.NewMockBuildDirectory(ctrl)
+		baseBuildDirectory.EXPECT().EnterBuildDirectory(subDirectoryName).Return(subDirectory, nil)
+		expectToolchainValidation(subDirectory, "cc")
+		expectEmptyingExceptToolchain(subDirectory)
+
+		buildDirectory, _, err := buildDirectoryCreator.GetBuildDirectory(ctxWithInputRoot, nil)
+		require.NoError(t, err)
+
+		require.NoError(t, buildDirectory.Mkdir(path.MustNewComponent("root"), 0o777))
+		rootDirectory := mock.NewMockBuildDirectory(ctrl)
+		subDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("root")).Return(rootDirectory, nil)
+		inputRoot, err := buildDirectory.EnterBuildDirectory(path.MustNewComponent("root"))
+		require.NoError(t, err)
+
+		// Copies of the Directory objects leading up to the
+		// toolchain should be stored in the CAS, lacking the
+		// entry leading to the toolchain.
+		errorLogger := mock.NewMockErrorLogger(ctrl)
+		directoryFetcher.EXPECT().GetDirectory(ctx, inputRootDigest).Return(inputRootDirectory, nil)
+		var rootRemainderDigest digest.Digest
+		contentAddressableStorage.EXPECT().Put(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, blobDigest digest.Digest, b buffer.Buffer) error {
+				m, err := b.ToProto(&remoteexecution.Directory{}, 10000)
+				require.NoError(t, err)
+				testutil.RequireEqualProto(t, &remoteexecution.Directory{
+					Files: inputRootDirectory.Files,
+				}, m)
+				rootRemainderDigest = blobDigest
+				return nil
+			})
+		rootDirectory.EXPECT().MergeDirectoryContents(ctx, errorLogger, gomock.Any(), nil, 0).DoAndReturn(
+			func(ctx context.Context, errorLogger util.ErrorLogger, directoryDigest digest.Digest, monitor access.UnreadDirectoryMonitor, depth int) error {
+				require.Equal(t, rootRemainderDigest, directoryDigest)
+				return nil
+			})
+		externalBuildDirectory := mock.NewMockBuildDirectory(ctrl)
+		rootDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("external")).Return(externalBuildDirectory, nil)
+		directoryFetcher.EXPECT().GetDirectory(ctx, externalDigest).Return(externalDirectory, nil)
+		contentAddressableStorage.EXPECT().Put(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, blobDigest digest.Digest, b buffer.Buffer) error {
+				m, err := b.ToProto(&remoteexecution.Directory{}, 10000)
+				require.NoError(t, err)
+				testutil.RequireEqualProto(t, &remoteexecution.Directory{}, m)
+				return nil
+			})
+		externalBuildDirectory.EXPECT().MergeDirectoryContents(ctx, errorLogger, gomock.Any(), nil, 1)
+		externalBuildDirectory.EXPECT().Close()
+		require.NoError(t, inputRoot.MergeDirectoryContents(ctx, errorLogger, inputRootDigest, nil, 0))
+		rootDirectory.EXPECT().Close()
+		require.NoError(t, inputRoot.Close())
+
+		expectEmptyingExceptToolchain(subDirectory)
+		subDirectory.EXPECT().Close()
+		baseBuildDirectory.EXPECT().Close()
+		require.NoError(t, buildDirectory.Close())
+	})
+
+	t.Run("Modified", func(t *testing.T) {
+		// If a build action modified the toolchain, it should
+		// be discarded prior to reuse.
+		baseBuildDirectory := mock.NewMockBuildDirectory(ctrl)
+		baseBuildDirectoryCreator.EXPECT().GetBuildDirectory(ctxWithInputRoot, nil).
+			Return(baseBuildDirectory, baseBuildDirectoryPath, nil)
+		expectToolchainLookup()
+		baseBuildDirectory.EXPECT().Mkdir(subDirectoryName, os.FileMode(0o777)).Return(syscall.EEXIST)
+		subDirectory := mock.NewMockBuildDirectory(ctrl)
+		baseBuildDirectory.EXPECT().EnterBuildDirectory(subDirectoryName).Return(subDirectory, nil)
+		expectToolchainValidation(subDirectory, "xx")
+		subDirectory.EXPECT().ReadDir().Return([]filesystem.FileInfo{
+			filesystem.NewFileInfo(path.MustNewComponent("root"), filesystem.FileTypeDirectory, false),
+		}, nil)
+		subDirectory.EXPECT().RemoveAll(path.MustNewComponent("root"))
+
+		buildDirectory, _, err := buildDirectoryCreator.GetBuildDirectory(ctxWithInputRoot, nil)
+		require.NoError(t, err)
+
+		// The input root should thus be populated entirely.
+		subDirectory.EXPECT().Mkdir(path.MustNewComponent("root"), os.FileMode(0o777))
+		require.NoError(t, buildDirectory.Mkdir(path.MustNewComponent("root"), 0o777))
+
+		// As the input root was never populated, the toolchain
+		// should not be retained.
+		subDirectory.EXPECT().ReadDir().Return([]filesystem.FileInfo{
+			filesystem.NewFileInfo(path.MustNewComponent("root"), filesystem.FileTypeDirectory, false),
+		}, nil)
+		subDirectory.EXPECT().RemoveAll(path.MustNewComponent("root"))
+		subDirectory.EXPECT().Close()
+		baseBuildDirectory.EXPECT().Close()
+		require.NoError(t, buildDirectory.Close())
+	})
+
+	t.Run("InvalidPath", func(t *testing.T) {
+		_, err := builder.NewToolchainReusingBuildDirectoryCreator(
+			baseBuildDirectoryCreator,
+			builder.NewToolchainBuildDirectoryNamePools(),
+			".",
+			directoryFetcher,
+			contentAddressableStorage)
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Toolchain directory path \".\" resolves to the input root directory"), err)
+	})
+}
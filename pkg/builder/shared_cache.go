@@ -0,0 +1,462 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/cas"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// sharedCacheSnapshot is the most recent snapshot of a shared cache,
+// stored in the Content Addressable Storage.
+type sharedCacheSnapshot struct {
+	digest    digest.Digest
+	sizeBytes int64
+}
+
+// sharedCacheKey is the key under which SharedCache tracks snapshots.
+// Snapshots are never shared between instance names or digest
+// functions, as that would allow actions to observe files created by
+// unrelated tenants, or refer to objects that cannot be fetched using
+// the digest function of the action.
+type sharedCacheKey struct {
+	instanceName   string
+	digestFunction remoteexecution.DigestFunction_Value
+}
+
+func newSharedCacheKey(digestFunction digest.Function) sharedCacheKey {
+	return sharedCacheKey{
+		instanceName:   digestFunction.GetInstanceName().String(),
+		digestFunction: digestFunction.GetEnumValue(),
+	}
+}
+
+// SharedCache is a directory that is placed inside the input root of
+// every action executed by a worker, such as a Go module cache, a
+// Maven repository or a ccache directory. Its contents persist across
+// actions.
+//
+// Instead of letting actions write to a single directory concurrently,
+// every action receives its own copy of the most recent snapshot of
+// the cache. When using build directories backed by the virtual file
+// system, this copy is created lazily. Files that are added by actions
+// that succeed are merged back into the snapshot afterwards. Existing
+// files in the snapshot are never overwritten or removed, meaning that
+// a shared cache is only suitable for storing files whose contents are
+// fully determined by their path.
+//
+// Snapshots are tracked by every worker separately. Only files that
+// are not part of the snapshot an action started with are uploaded
+// after the action completes.
+type SharedCache struct {
+	pathString                string
+	components                []path.Component
+	contentAddressableStorage blobstore.BlobAccess
+	directoryFetcher          cas.DirectoryFetcher
+	maximumSizeBytes          int64
+
+	lock      sync.Mutex
+	snapshots map[sharedCacheKey]sharedCacheSnapshot
+}
+
+// NewSharedCache creates a SharedCache that is stored at a given path
+// relative to the input root. Snapshots of the cache are stored in the
+// provided Content Addressable Storage, and are loaded through the
+// provided DirectoryFetcher. Writes against the Content Addressable
+// Storage must be visible to the DirectoryFetcher immediately, meaning
+// that batching BlobAccess implementations may not be used.
+//
+// If the total size of the files in a snapshot would exceed the
+// provided maximum size, the snapshot is discarded, and the shared
+// cache starts over using only the files added by the most recent
+// action. A maximum size of zero means the size is unbounded.
+func NewSharedCache(pathString string, contentAddressableStorage blobstore.BlobAccess, directoryFetcher cas.DirectoryFetcher, maximumSizeBytes int64) (*SharedCache, error) {
+	var cachePath outputNodePath
+	if err := path.Resolve(pathString, path.NewRelativeScopeWalker(&cachePath)); err != nil {
+		return nil, util.StatusWrapf(err, "Invalid shared cache path %#v", pathString)
+	}
+	if len(cachePath.components) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Shared cache path %#v resolves to the input root directory", pathString)
+	}
+	return &SharedCache{
+		pathString:                strings.Trim(pathString, "/"),
+		components:                cachePath.components,
+		contentAddressableStorage: contentAddressableStorage,
+		directoryFetcher:          directoryFetcher,
+		maximumSizeBytes:          maximumSizeBytes,
+
+		snapshots: map[sharedCacheKey]sharedCacheSnapshot{},
+	}, nil
+}
+
+func (sc *SharedCache) getSnapshot(key sharedCacheKey) sharedCacheSnapshot {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	return sc.snapshots[key]
+}
+
+// Populate the shared cache directory inside the input root of an
+// action, using the contents of the most recent snapshot. The shared
+// cache directory may not already be part of the input root.
+func (sc *SharedCache) Populate(ctx context.Context, inputRootDirectory BuildDirectory, errorLogger util.ErrorLogger, digestFunction digest.Function) error {
+	// Create parent directories of the shared cache directory.
+	d := inputRootDirectory
+	for _, component := range sc.components[:len(sc.components)-1] {
+		if err := d.Mkdir(component, 0o777); err != nil && !os.IsExist(err) {
+			return util.StatusWrapf(err, "Failed to create parent directory of shared cache %#v", sc.pathString)
+		}
+		child, err := d.EnterBuildDirectory(component)
+		if err != nil {
+			return util.StatusWrapf(err, "Failed to enter parent directory of shared cache %#v", sc.pathString)
+		}
+		if d != inputRootDirectory {
+			d.Close()
+		}
+		d = child
+	}
+	if d != inputRootDirectory {
+		defer d.Close()
+	}
+
+	name := sc.components[len(sc.components)-1]
+	if err := d.Mkdir(name, 0o777); err != nil {
+		if os.IsExist(err) {
+			return status.Errorf(codes.InvalidArgument, "Shared cache %#v collides with a file or directory in the input root", sc.pathString)
+		}
+		return util.StatusWrapf(err, "Failed to create shared cache %#v", sc.pathString)
+	}
+
+	key := newSharedCacheKey(digestFunction)
+	snapshotDigest := sc.getSnapshot(key).digest
+	if snapshotDigest == digest.BadDigest {
+		return nil
+	}
+	cacheDirectory, err := d.EnterBuildDirectory(name)
+	if err != nil {
+		return util.StatusWrapf(err, "Failed to enter shared cache %#v", sc.pathString)
+	}
+	defer cacheDirectory.Close()
+	if _, err := sc.directoryFetcher.GetDirectory(ctx, snapshotDigest); err != nil {
+		if status.Code(err) == codes.NotFound {
+			// The snapshot has been evicted from storage.
+			// Start over with an empty cache.
+			sc.lock.Lock()
+			if sc.snapshots[key].digest == snapshotDigest {
+				delete(sc.snapshots, key)
+			}
+			sc.lock.Unlock()
+			return nil
+		}
+		return util.StatusWrapf(err, "Failed to obtain snapshot of shared cache %#v", sc.pathString)
+	}
+	if err := cacheDirectory.MergeDirectoryContents(ctx, errorLogger, snapshotDigest, nil, len(sc.components)); err != nil {
+		return util.StatusWrapf(err, "Failed to populate shared cache %#v", sc.pathString)
+	}
+	return nil
+}
+
+// Update the snapshot of the shared cache, by merging in any files and
+// directories that were added to the shared cache directory inside the
+// input root of an action. This method should only be called for
+// actions that succeeded, as failing actions may leave incomplete files
+// behind.
+//
+// As files that are part of the snapshot are never overwritten, only
+// files that are not part of the snapshot are uploaded.
+func (sc *SharedCache) Update(ctx context.Context, inputRootDirectory UploadableDirectory, digestFunction digest.Function) error {
+	// Enter the shared cache directory.
+	d := inputRootDirectory
+	var dPath *path.Trace
+	for _, component := range sc.components {
+		dPath = dPath.Append(component)
+		child, err := d.EnterUploadableDirectory(component)
+		if d != inputRootDirectory {
+			d.Close()
+		}
+		if err != nil {
+			if os.IsNotExist(err) || errors.Is(err, syscall.ENOTDIR) {
+				// The action removed the shared cache
+				// directory, or replaced it by a file.
+				return nil
+			}
+			return util.StatusWrapf(err, "Failed to enter shared cache directory %#v", dPath.String())
+		}
+		d = child
+	}
+	defer d.Close()
+
+	key := newSharedCacheKey(digestFunction)
+	additionsDigest, additionsSizeBytes, err := sc.uploadAdditions(ctx, d, dPath, digestFunction, sc.getSnapshot(key).digest)
+	if err != nil {
+		return util.StatusWrapf(err, "Failed to upload additions to shared cache %#v", sc.pathString)
+	}
+	if additionsDigest == digest.BadDigest {
+		// The action did not add any files.
+		return nil
+	}
+
+	// Merge the additions into the latest snapshot. Other actions
+	// may update the snapshot while merging is performed. Retry if
+	// that happens, so that no additions get lost.
+	for {
+		baseSnapshot := sc.getSnapshot(key)
+		mergedSnapshot := sharedCacheSnapshot{
+			sizeBytes: baseSnapshot.sizeBytes + additionsSizeBytes,
+		}
+		if sc.maximumSizeBytes > 0 && mergedSnapshot.sizeBytes > sc.maximumSizeBytes {
+			// Snapshot has grown too large. Start over.
+			mergedSnapshot = sharedCacheSnapshot{
+				digest:    additionsDigest,
+				sizeBytes: additionsSizeBytes,
+			}
+		} else {
+			mergedDigest, err := sc.mergeDirectories(ctx, digestFunction, baseSnapshot.digest, additionsDigest)
+			if err != nil {
+				return util.StatusWrapf(err, "Failed to merge shared cache %#v", sc.pathString)
+			}
+			mergedSnapshot.digest = mergedDigest
+		}
+
+		sc.lock.Lock()
+		if sc.snapshots[key] == baseSnapshot {
+			if sc.maximumSizeBytes > 0 && mergedSnapshot.sizeBytes > sc.maximumSizeBytes {
+				// The additions alone are too large.
+				delete(sc.snapshots, key)
+			} else {
+				sc.snapshots[key] = mergedSnapshot
+			}
+			sc.lock.Unlock()
+			return nil
+		}
+		sc.lock.Unlock()
+	}
+}
+
+// uploadAdditions uploads all files, directories and symbolic links
+// contained in a directory that are not part of a snapshot into the
+// Content Addressable Storage. It returns the digest of a Directory
+// message containing only the additions, and the total size of the
+// files that were added. BadDigest is returned if nothing was added.
+func (sc *SharedCache) uploadAdditions(ctx context.Context, d UploadableDirectory, dPath *path.Trace, digestFunction digest.Function, baseDigest digest.Digest) (digest.Digest, int64, error) {
+	// Obtain the names of the entries in the snapshot.
+	names := map[string]struct{}{}
+	baseDirectories := map[string]*remoteexecution.DirectoryNode{}
+	if baseDigest != digest.BadDigest {
+		base, err := sc.directoryFetcher.GetDirectory(ctx, baseDigest)
+		if err == nil {
+			for _, file := range base.Files {
+				names[file.Name] = struct{}{}
+			}
+			for _, symlink := range base.Symlinks {
+				names[symlink.Name] = struct{}{}
+			}
+			for _, directory := range base.Directories {
+				names[directory.Name] = struct{}{}
+				baseDirectories[directory.Name] = directory
+			}
+		} else if status.Code(err) != codes.NotFound {
+			return digest.BadDigest, 0, util.StatusWrapf(err, "Failed to obtain directory %#v", baseDigest.String())
+		}
+	}
+
+	entries, err := d.ReadDir()
+	if err != nil {
+		return digest.BadDigest, 0, util.StatusWrapf(err, "Failed to read contents of directory %#v", dPath.String())
+	}
+	var additions remoteexecution.Directory
+	sizeBytes := int64(0)
+	for _, entry := range entries {
+		name := entry.Name()
+		childPath := dPath.Append(name)
+		baseDirectory, isBaseDirectory := baseDirectories[name.String()]
+		if _, ok := names[name.String()]; ok && (!isBaseDirectory || entry.Type() != filesystem.FileTypeDirectory) {
+			// Entry is already part of the snapshot.
+			continue
+		}
+
+		switch entry.Type() {
+		case filesystem.FileTypeDirectory:
+			childBaseDigest := digest.BadDigest
+			if isBaseDirectory {
+				childBaseDigest, err = digestFunction.NewDigestFromProto(baseDirectory.Digest)
+				if err != nil {
+					return digest.BadDigest, 0, util.StatusWrapf(err, "Invalid digest for directory %#v", childPath.String())
+				}
+			}
+			child, err := d.EnterUploadableDirectory(name)
+			if err != nil {
+				return digest.BadDigest, 0, util.StatusWrapf(err, "Failed to enter directory %#v", childPath.String())
+			}
+			childDigest, childSizeBytes, err := sc.uploadAdditions(ctx, child, childPath, digestFunction, childBaseDigest)
+			child.Close()
+			if err != nil {
+				return digest.BadDigest, 0, err
+			}
+			if childDigest != digest.BadDigest {
+				additions.Directories = append(additions.Directories, &remoteexecution.DirectoryNode{
+					Name:   name.String(),
+					Digest: childDigest.GetProto(),
+				})
+				sizeBytes += childSizeBytes
+			}
+		case filesystem.FileTypeRegularFile:
+			fileDigest, err := d.UploadFile(ctx, name, digestFunction)
+			if err != nil {
+				return digest.BadDigest, 0, util.StatusWrapf(err, "Failed to upload file %#v", childPath.String())
+			}
+			additions.Files = append(additions.Files, &remoteexecution.FileNode{
+				Name:         name.String(),
+				Digest:       fileDigest.GetProto(),
+				IsExecutable: entry.IsExecutable(),
+			})
+			sizeBytes += fileDigest.GetSizeBytes()
+		case filesystem.FileTypeSymlink:
+			target, err := d.Readlink(name)
+			if err != nil {
+				return digest.BadDigest, 0, util.StatusWrapf(err, "Failed to read symbolic link %#v", childPath.String())
+			}
+			additions.Symlinks = append(additions.Symlinks, &remoteexecution.SymlinkNode{
+				Name:   name.String(),
+				Target: target,
+			})
+		}
+	}
+	if len(additions.Directories) == 0 && len(additions.Files) == 0 && len(additions.Symlinks) == 0 {
+		return digest.BadDigest, 0, nil
+	}
+	additionsDigest, err := sc.putDirectory(ctx, digestFunction, &additions)
+	if err != nil {
+		return digest.BadDigest, 0, err
+	}
+	return additionsDigest, sizeBytes, nil
+}
+
+// mergeDirectories computes the union of two directory hierarchies
+// stored in the Content Addressable Storage. Files and symbolic links
+// contained in the base directory take precedence over the ones
+// contained in the additions.
+func (sc *SharedCache) mergeDirectories(ctx context.Context, digestFunction digest.Function, baseDigest, additionsDigest digest.Digest) (digest.Digest, error) {
+	if baseDigest == digest.BadDigest || baseDigest == additionsDigest {
+		return additionsDigest, nil
+	}
+	base, err := sc.directoryFetcher.GetDirectory(ctx, baseDigest)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			// The snapshot has been evicted from storage.
+			return additionsDigest, nil
+		}
+		return digest.BadDigest, util.StatusWrapf(err, "Failed to obtain directory %#v", baseDigest.String())
+	}
+	additions, err := sc.directoryFetcher.GetDirectory(ctx, additionsDigest)
+	if err != nil {
+		return digest.BadDigest, util.StatusWrapf(err, "Failed to obtain directory %#v", additionsDigest.String())
+	}
+
+	merged := &remoteexecution.Directory{
+		Files:          append([]*remoteexecution.FileNode(nil), base.Files...),
+		Directories:    append([]*remoteexecution.DirectoryNode(nil), base.Directories...),
+		Symlinks:       append([]*remoteexecution.SymlinkNode(nil), base.Symlinks...),
+		NodeProperties: base.NodeProperties,
+	}
+	names := map[string]struct{}{}
+	for _, file := range base.Files {
+		names[file.Name] = struct{}{}
+	}
+	for _, symlink := range base.Symlinks {
+		names[symlink.Name] = struct{}{}
+	}
+	baseDirectories := map[string]*remoteexecution.DirectoryNode{}
+	for _, directory := range base.Directories {
+		names[directory.Name] = struct{}{}
+		baseDirectories[directory.Name] = directory
+	}
+
+	// Recursively merge directories that are present on both sides.
+	changed := false
+	for _, directory := range additions.Directories {
+		if baseDirectory, ok := baseDirectories[directory.Name]; ok {
+			childBaseDigest, err := digestFunction.NewDigestFromProto(baseDirectory.Digest)
+			if err != nil {
+				return digest.BadDigest, util.StatusWrapf(err, "Invalid digest for directory %#v", directory.Name)
+			}
+			childAdditionsDigest, err := digestFunction.NewDigestFromProto(directory.Digest)
+			if err != nil {
+				return digest.BadDigest, util.StatusWrapf(err, "Invalid digest for directory %#v", directory.Name)
+			}
+			childMergedDigest, err := sc.mergeDirectories(ctx, digestFunction, childBaseDigest, childAdditionsDigest)
+			if err != nil {
+				return digest.BadDigest, err
+			}
+			if childMergedDigest != childBaseDigest {
+				for i, mergedDirectory := range merged.Directories {
+					if mergedDirectory.Name == directory.Name {
+						merged.Directories[i] = &remoteexecution.DirectoryNode{
+							Name:   directory.Name,
+							Digest: childMergedDigest.GetProto(),
+						}
+					}
+				}
+				changed = true
+			}
+		} else if _, ok := names[directory.Name]; !ok {
+			merged.Directories = append(merged.Directories, directory)
+			changed = true
+		}
+	}
+
+	// Add files and symbolic links that are not present yet.
+	for _, file := range additions.Files {
+		if _, ok := names[file.Name]; !ok {
+			merged.Files = append(merged.Files, file)
+			changed = true
+		}
+	}
+	for _, symlink := range additions.Symlinks {
+		if _, ok := names[symlink.Name]; !ok {
+			merged.Symlinks = append(merged.Symlinks, symlink)
+			changed = true
+		}
+	}
+	if !changed {
+		return baseDigest, nil
+	}
+
+	return sc.putDirectory(ctx, digestFunction, merged)
+}
+
+// putDirectory stores a Directory message in the Content Addressable
+// Storage. REv2 requires that entries are sorted by name.
+func (sc *SharedCache) putDirectory(ctx context.Context, digestFunction digest.Function, directory *remoteexecution.Directory) (digest.Digest, error) {
+	sort.Slice(directory.Directories, func(i, j int) bool { return directory.Directories[i].Name < directory.Directories[j].Name })
+	sort.Slice(directory.Files, func(i, j int) bool { return directory.Files[i].Name < directory.Files[j].Name })
+	sort.Slice(directory.Symlinks, func(i, j int) bool { return directory.Symlinks[i].Name < directory.Symlinks[j].Name })
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(directory)
+	if err != nil {
+		return digest.BadDigest, util.StatusWrap(err, "Failed to marshal directory")
+	}
+	digestGenerator := digestFunction.NewGenerator(int64(len(data)))
+	if _, err := digestGenerator.Write(data); err != nil {
+		panic(err)
+	}
+	directoryDigest := digestGenerator.Sum()
+	if err := sc.contentAddressableStorage.Put(ctx, directoryDigest, buffer.NewValidatedBufferFromByteSlice(data)); err != nil {
+		return digest.BadDigest, util.StatusWrap(err, "Failed to store directory")
+	}
+	return directoryDigest, nil
+}
@@ -0,0 +1,211 @@
+package builder_test
+
+import (
+	"context"
+	"syscall"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSharedCache(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	// Let the Content Addressable Storage and the DirectoryFetcher
+	// be backed by a simple map.
+	blobs := map[digest.Digest][]byte{}
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	contentAddressableStorage.EXPECT().Put(ctx, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, blobDigest digest.Digest, b buffer.Buffer) error {
+			data, err := b.ToByteSlice(10000)
+			require.NoError(t, err)
+			blobs[blobDigest] = data
+			return nil
+		}).
+		AnyTimes()
+	directoryFetcher := mock.NewMockDirectoryFetcher(ctrl)
+	directoryFetcher.EXPECT().GetDirectory(ctx, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, directoryDigest digest.Digest) (*remoteexecution.Directory, error) {
+			data, ok := blobs[directoryDigest]
+			if !ok {
+				return nil, status.Error(codes.NotFound, "Object not found")
+			}
+			var directory remoteexecution.Directory
+			require.NoError(t, proto.Unmarshal(data, &directory))
+			return &directory, nil
+		}).
+		AnyTimes()
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	digestFunction := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5).GetDigestFunction()
+
+	t.Run("InvalidPath", func(t *testing.T) {
+		_, err := builder.NewSharedCache("..", contentAddressableStorage, directoryFetcher, 0)
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Invalid shared cache path \"..\": Path resolves to a location outside the input root directory"), err)
+
+		_, err = builder.NewSharedCache(".", contentAddressableStorage, directoryFetcher, 0)
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Shared cache path \".\" resolves to the input root directory"), err)
+	})
+
+	t.Run("PopulateEmpty", func(t *testing.T) {
+		// Without a snapshot, only the directory of the shared
+		// cache and its parents should be created.
+		sharedCache, err := builder.NewSharedCache("cache/go", contentAddressableStorage, directoryFetcher, 0)
+		require.NoError(t, err)
+
+		inputRootDirectory := mock.NewMockBuildDirectory(ctrl)
+		inputRootDirectory.EXPECT().Mkdir(path.MustNewComponent("cache"), gomock.Any())
+		cacheDirectory := mock.NewMockBuildDirectory(ctrl)
+		inputRootDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("cache")).Return(cacheDirectory, nil)
+		cacheDirectory.EXPECT().Mkdir(path.MustNewComponent("go"), gomock.Any())
+		cacheDirectory.EXPECT().Close()
+
+		require.NoError(t, sharedCache.Populate(ctx, inputRootDirectory, errorLogger, digestFunction))
+	})
+
+	t.Run("Collision", func(t *testing.T) {
+		// The shared cache directory may not be part of the
+		// input root.
+		sharedCache, err := builder.NewSharedCache("cache", contentAddressableStorage, directoryFetcher, 0)
+		require.NoError(t, err)
+
+		inputRootDirectory := mock.NewMockBuildDirectory(ctrl)
+		inputRootDirectory.EXPECT().Mkdir(path.MustNewComponent("cache"), gomock.Any()).Return(syscall.EEXIST)
+
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.InvalidArgument, "Shared cache \"cache\" collides with a file or directory in the input root"),
+			sharedCache.Populate(ctx, inputRootDirectory, errorLogger, digestFunction))
+	})
+
+	t.Run("UpdateAndPopulate", func(t *testing.T) {
+		sharedCache, err := builder.NewSharedCache("cache", contentAddressableStorage, directoryFetcher, 0)
+		require.NoError(t, err)
+
+		update := func(existingNames []path.Component, name path.Component, fileDigest digest.Digest) {
+			inputRootDirectory := mock.NewMockUploadableDirectory(ctrl)
+			cacheDirectory := mock.NewMockUploadableDirectory(ctrl)
+			inputRootDirectory.EXPECT().EnterUploadableDirectory(path.MustNewComponent("cache")).Return(cacheDirectory, nil)
+			var fileInfos []filesystem.FileInfo
+			for _, existingName := range existingNames {
+				fileInfos = append(fileInfos, filesystem.NewFileInfo(existingName, filesystem.FileTypeRegularFile, false))
+			}
+			cacheDirectory.EXPECT().ReadDir().Return(append(fileInfos, filesystem.NewFileInfo(name, filesystem.FileTypeRegularFile, false)), nil)
+			cacheDirectory.EXPECT().UploadFile(ctx, name, gomock.Any()).Return(fileDigest, nil)
+			cacheDirectory.EXPECT().Close()
+
+			require.NoError(t, sharedCache.Update(ctx, inputRootDirectory, digestFunction))
+		}
+
+		// Let two actions add different files to the shared
+		// cache. Both of them should end up in the snapshot.
+		// Files that are already part of the snapshot should
+		// not be uploaded again.
+		update(nil, path.MustNewComponent("a"), digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "0cc175b9c0f1b6a831c399e269772661", 1))
+		update([]path.Component{path.MustNewComponent("a")}, path.MustNewComponent("b"), digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "92eb5ffee6ae2fec3ad71c777531578f", 1))
+
+		inputRootDirectory := mock.NewMockBuildDirectory(ctrl)
+		inputRootDirectory.EXPECT().Mkdir(path.MustNewComponent("cache"), gomock.Any())
+		cacheDirectory := mock.NewMockBuildDirectory(ctrl)
+		inputRootDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("cache")).Return(cacheDirectory, nil)
+		cacheDirectory.EXPECT().MergeDirectoryContents(ctx, errorLogger, gomock.Any(), nil, 1).
+			DoAndReturn(func(ctx context.Context, errorLogger util.ErrorLogger, snapshotDigest digest.Digest, monitor access.UnreadDirectoryMonitor, depth int) error {
+				var directory remoteexecution.Directory
+				require.NoError(t, proto.Unmarshal(blobs[snapshotDigest], &directory))
+				testutil.RequireEqualProto(t, &remoteexecution.Directory{
+					Files: []*remoteexecution.FileNode{
+						{
+							Name: "a",
+							Digest: &remoteexecution.Digest{
+								Hash:      "0cc175b9c0f1b6a831c399e269772661",
+								SizeBytes: 1,
+							},
+						},
+						{
+							Name: "b",
+							Digest: &remoteexecution.Digest{
+								Hash:      "92eb5ffee6ae2fec3ad71c777531578f",
+								SizeBytes: 1,
+							},
+						},
+					},
+				}, &directory)
+				return nil
+			})
+		cacheDirectory.EXPECT().Close()
+
+		require.NoError(t, sharedCache.Populate(ctx, inputRootDirectory, errorLogger, digestFunction))
+	})
+	t.Run("UpdateRemoved", func(t *testing.T) {
+		// If the action removed the shared cache directory,
+		// there is nothing to update.
+		sharedCache, err := builder.NewSharedCache("cache", contentAddressableStorage, directoryFetcher, 0)
+		require.NoError(t, err)
+
+		inputRootDirectory := mock.NewMockUploadableDirectory(ctrl)
+		inputRootDirectory.EXPECT().EnterUploadableDirectory(path.MustNewComponent("cache")).Return(nil, syscall.ENOENT)
+
+		require.NoError(t, sharedCache.Update(ctx, inputRootDirectory, digestFunction))
+	})
+
+	t.Run("MaximumSize", func(t *testing.T) {
+		// If the snapshot would exceed the maximum size, it
+		// should be replaced by the files added by the action.
+		sharedCache, err := builder.NewSharedCache("cache", contentAddressableStorage, directoryFetcher, 6)
+		require.NoError(t, err)
+
+		update := func(name path.Component, fileDigest digest.Digest) {
+			inputRootDirectory := mock.NewMockUploadableDirectory(ctrl)
+			cacheDirectory := mock.NewMockUploadableDirectory(ctrl)
+			inputRootDirectory.EXPECT().EnterUploadableDirectory(path.MustNewComponent("cache")).Return(cacheDirectory, nil)
+			cacheDirectory.EXPECT().ReadDir().Return([]filesystem.FileInfo{
+				filesystem.NewFileInfo(name, filesystem.FileTypeRegularFile, false),
+			}, nil)
+			cacheDirectory.EXPECT().UploadFile(ctx, name, gomock.Any()).Return(fileDigest, nil)
+			cacheDirectory.EXPECT().Close()
+
+			require.NoError(t, sharedCache.Update(ctx, inputRootDirectory, digestFunction))
+		}
+		update(path.MustNewComponent("a"), digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5))
+		update(path.MustNewComponent("b"), digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "f5a7924e621e84c9280a9a27e1bcb7f6", 5))
+
+		inputRootDirectory := mock.NewMockBuildDirectory(ctrl)
+		inputRootDirectory.EXPECT().Mkdir(path.MustNewComponent("cache"), gomock.Any())
+		cacheDirectory := mock.NewMockBuildDirectory(ctrl)
+		inputRootDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent("cache")).Return(cacheDirectory, nil)
+		cacheDirectory.EXPECT().MergeDirectoryContents(ctx, errorLogger, gomock.Any(), nil, 1).
+			DoAndReturn(func(ctx context.Context, errorLogger util.ErrorLogger, snapshotDigest digest.Digest, monitor access.UnreadDirectoryMonitor, depth int) error {
+				var directory remoteexecution.Directory
+				require.NoError(t, proto.Unmarshal(blobs[snapshotDigest], &directory))
+				testutil.RequireEqualProto(t, &remoteexecution.Directory{
+					Files: []*remoteexecution.FileNode{
+						{
+							Name: "b",
+							Digest: &remoteexecution.Digest{
+								Hash:      "f5a7924e621e84c9280a9a27e1bcb7f6",
+								SizeBytes: 5,
+							},
+						},
+					},
+				}, &directory)
+				return nil
+			})
+		cacheDirectory.EXPECT().Close()
+
+		require.NoError(t, sharedCache.Populate(ctx, inputRootDirectory, errorLogger, digestFunction))
+	})
+}
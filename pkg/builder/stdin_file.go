@@ -0,0 +1,104 @@
+package builder
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var stdinComponent = path.MustNewComponent("stdin")
+
+// StdinFile is a file that is placed inside the build directory of an
+// action, whose contents are provided to the command over stdin. Its
+// contents are taken from a blob in the Content Addressable Storage
+// that is referenced by a platform property of the action, having
+// value "${hash}-${size_bytes}".
+//
+// The file is merged into the build directory, as opposed to being
+// downloaded by the worker and sent to the runner inline. This means
+// that the size of the blob is not limited by the maximum message size
+// of the runner protocol, and that virtual build directories only load
+// its contents when read.
+type StdinFile struct {
+	platformPropertyName      string
+	contentAddressableStorage blobstore.BlobAccess
+}
+
+// NewStdinFile creates a StdinFile that is populated if an action has
+// a platform property with a given name. The directory containing the
+// file is stored in the provided Content Addressable Storage prior to
+// being merged into the build directory. Writes against the Content
+// Addressable Storage must be visible to subsequent reads immediately,
+// meaning that batching BlobAccess implementations may not be used.
+func NewStdinFile(platformPropertyName string, contentAddressableStorage blobstore.BlobAccess) *StdinFile {
+	return &StdinFile{
+		platformPropertyName:      platformPropertyName,
+		contentAddressableStorage: contentAddressableStorage,
+	}
+}
+
+// Populate creates the stdin file inside the build directory of an
+// action, if the action requests it. It returns whether the file has
+// been created, in which case it is named "stdin".
+func (f *StdinFile) Populate(ctx context.Context, platform *remoteexecution.Platform, buildDirectory BuildDirectory, errorLogger util.ErrorLogger, digestFunction digest.Function) (bool, error) {
+	for _, property := range platform.GetProperties() {
+		if property.Name != f.platformPropertyName {
+			continue
+		}
+
+		separator := strings.LastIndexByte(property.Value, '-')
+		if separator < 0 {
+			return false, status.Errorf(codes.InvalidArgument, "Platform property %#v has value %#v, while a digest of the form \"${hash}-${size_bytes}\" was expected", property.Name, property.Value)
+		}
+		sizeBytes, err := strconv.ParseInt(property.Value[separator+1:], 10, 64)
+		if err != nil {
+			return false, util.StatusWrapfWithCode(err, codes.InvalidArgument, "Invalid size in platform property %#v", property.Name)
+		}
+		stdinDigest, err := digestFunction.NewDigest(property.Value[:separator], sizeBytes)
+		if err != nil {
+			return false, util.StatusWrapf(err, "Invalid digest in platform property %#v", property.Name)
+		}
+
+		// Store a directory containing only the file in the
+		// Content Addressable Storage, so that it can be added
+		// to the build directory through
+		// MergeDirectoryContents(). The directory does not
+		// contain any symbolic links, so its depth relative to
+		// the input root is irrelevant.
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(&remoteexecution.Directory{
+			Files: []*remoteexecution.FileNode{{
+				Name:   stdinComponent.String(),
+				Digest: stdinDigest.GetProto(),
+			}},
+		})
+		if err != nil {
+			return false, util.StatusWrap(err, "Failed to marshal directory of stdin file")
+		}
+		directoryDigest, err := putBlob(ctx, f.contentAddressableStorage, digestFunction, data)
+		if err != nil {
+			return false, util.StatusWrap(err, "Failed to store directory of stdin file")
+		}
+
+		if _, err := buildDirectory.Lstat(stdinComponent); err == nil {
+			return false, status.Error(codes.Internal, "Stdin file already exists in the build directory")
+		} else if !os.IsNotExist(err) {
+			return false, util.StatusWrap(err, "Failed to check for existence of stdin file")
+		}
+		if err := buildDirectory.MergeDirectoryContents(ctx, errorLogger, directoryDigest, nil, 0); err != nil {
+			return false, util.StatusWrap(err, "Failed to create stdin file")
+		}
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,110 @@
+package builder_test
+
+import (
+	"context"
+	"syscall"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestStdinFile(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	blobs := map[digest.Digest][]byte{}
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	contentAddressableStorage.EXPECT().Put(ctx, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, blobDigest digest.Digest, b buffer.Buffer) error {
+			data, err := b.ToByteSlice(10000)
+			require.NoError(t, err)
+			blobs[blobDigest] = data
+			return nil
+		}).
+		AnyTimes()
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	digestFunction := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5).GetDigestFunction()
+	stdinFile := builder.NewStdinFile("stdin", contentAddressableStorage)
+
+	t.Run("NoPlatformProperty", func(t *testing.T) {
+		// Actions that don't provide the platform property
+		// should not get a stdin file.
+		buildDirectory := mock.NewMockBuildDirectory(ctrl)
+		created, err := stdinFile.Populate(ctx, &remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "os", Value: "linux"},
+			},
+		}, buildDirectory, errorLogger, digestFunction)
+		require.NoError(t, err)
+		require.False(t, created)
+	})
+
+	t.Run("InvalidDigest", func(t *testing.T) {
+		buildDirectory := mock.NewMockBuildDirectory(ctrl)
+		_, err := stdinFile.Populate(ctx, &remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "stdin", Value: "8b1a9953c4611296a827abf8c47804d7"},
+			},
+		}, buildDirectory, errorLogger, digestFunction)
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Platform property \"stdin\" has value \"8b1a9953c4611296a827abf8c47804d7\", while a digest of the form \"${hash}-${size_bytes}\" was expected"), err)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		// The blob referenced by the platform property should
+		// be merged into the build directory as a file named
+		// "stdin".
+		buildDirectory := mock.NewMockBuildDirectory(ctrl)
+		buildDirectory.EXPECT().Lstat(path.MustNewComponent("stdin")).Return(filesystem.FileInfo{}, syscall.ENOENT)
+		buildDirectory.EXPECT().MergeDirectoryContents(ctx, errorLogger, gomock.Any(), nil, 0).
+			DoAndReturn(func(ctx context.Context, errorLogger util.ErrorLogger, directoryDigest digest.Digest, monitor access.UnreadDirectoryMonitor, depth int) error {
+				var directory remoteexecution.Directory
+				require.NoError(t, proto.Unmarshal(blobs[directoryDigest], &directory))
+				testutil.RequireEqualProto(t, &remoteexecution.Directory{
+					Files: []*remoteexecution.FileNode{{
+						Name: "stdin",
+						Digest: &remoteexecution.Digest{
+							Hash:      "f0ef7081e1539ac00ef5b761b4fb01b3",
+							SizeBytes: 12,
+						},
+					}},
+				}, &directory)
+				return nil
+			})
+
+		created, err := stdinFile.Populate(ctx, &remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "stdin", Value: "f0ef7081e1539ac00ef5b761b4fb01b3-12"},
+			},
+		}, buildDirectory, errorLogger, digestFunction)
+		require.NoError(t, err)
+		require.True(t, created)
+	})
+
+	t.Run("Collision", func(t *testing.T) {
+		// The stdin file may not be part of the build directory
+		// already.
+		buildDirectory := mock.NewMockBuildDirectory(ctrl)
+		buildDirectory.EXPECT().Lstat(path.MustNewComponent("stdin")).Return(filesystem.NewFileInfo(path.MustNewComponent("stdin"), filesystem.FileTypeRegularFile, false), nil)
+
+		_, err := stdinFile.Populate(ctx, &remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "stdin", Value: "f0ef7081e1539ac00ef5b761b4fb01b3-12"},
+			},
+		}, buildDirectory, errorLogger, digestFunction)
+		testutil.RequireEqualStatus(t, status.Error(codes.Internal, "Stdin file already exists in the build directory"), err)
+	})
+}
@@ -0,0 +1,83 @@
+package builder
+
+import (
+	"context"
+	"io"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc"
+)
+
+type streamingOperationQueueClient struct {
+	remoteworker.OperationQueueClient
+	workerCtx context.Context
+
+	stream       remoteworker.OperationQueue_SynchronizeStreamClient
+	cancelStream context.CancelFunc
+}
+
+// NewStreamingOperationQueueClient creates a decorator for
+// OperationQueueClient that performs calls to Synchronize() over a
+// single long-lived SynchronizeStream() call. This reduces the overhead
+// of synchronizing against the scheduler, and permits the scheduler to
+// let idle workers block for a longer amount of time.
+//
+// The stream is created lazily, and recreated after failures. As a
+// single stream can only be used to synchronize a single worker
+// thread, every worker thread needs to use its own instance. Instances
+// are not safe for concurrent use.
+//
+// Streams are created using a context that is derived from the one
+// provided, so that they are torn down when the worker shuts down.
+func NewStreamingOperationQueueClient(workerCtx context.Context, base remoteworker.OperationQueueClient) remoteworker.OperationQueueClient {
+	return &streamingOperationQueueClient{
+		OperationQueueClient: base,
+		workerCtx:            workerCtx,
+	}
+}
+
+func (c *streamingOperationQueueClient) closeStream() {
+	c.cancelStream()
+	c.stream = nil
+	c.cancelStream = nil
+}
+
+func (c *streamingOperationQueueClient) exchange(request *remoteworker.SynchronizeRequest) (*remoteworker.SynchronizeResponse, error) {
+	// If sending fails, the actual error is returned by Recv().
+	if err := c.stream.Send(request); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return c.stream.Recv()
+}
+
+func (c *streamingOperationQueueClient) Synchronize(ctx context.Context, request *remoteworker.SynchronizeRequest, opts ...grpc.CallOption) (*remoteworker.SynchronizeResponse, error) {
+	if c.stream == nil {
+		// The lifetime of the stream is not tied to that of
+		// individual calls to Synchronize(), but to that of the
+		// worker.
+		streamCtx, cancelStream := context.WithCancel(c.workerCtx)
+		stream, err := c.OperationQueueClient.SynchronizeStream(streamCtx, opts...)
+		if err != nil {
+			cancelStream()
+			return nil, err
+		}
+		c.stream = stream
+		c.cancelStream = cancelStream
+	}
+
+	// Forward cancelation of the current call by tearing down the
+	// stream, as there is no way to cancel an individual exchange.
+	stop := context.AfterFunc(ctx, c.cancelStream)
+	response, err := c.exchange(request)
+	if !stop() {
+		c.closeStream()
+		return nil, util.StatusFromContext(ctx)
+	}
+	if err != nil {
+		c.closeStream()
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,70 @@
+package builder_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestStreamingOperationQueueClient(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseClient := mock.NewMockOperationQueueClient(ctrl)
+	client := builder.NewStreamingOperationQueueClient(ctx, baseClient)
+
+	request := &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{"hostname": "example.com"},
+	}
+	response := &remoteworker.SynchronizeResponse{
+		NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1010},
+	}
+
+	// The first call should cause a stream to be created. Successive
+	// calls should reuse the same stream.
+	stream1 := mock.NewMockOperationQueue_SynchronizeStreamClient(ctrl)
+	baseClient.EXPECT().SynchronizeStream(gomock.Any()).Return(stream1, nil)
+	stream1.EXPECT().Send(request).Times(2)
+	stream1.EXPECT().Recv().Return(response, nil).Times(2)
+	for i := 0; i < 2; i++ {
+		actualResponse, err := client.Synchronize(ctx, request)
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, response, actualResponse)
+	}
+
+	// If the stream fails, the error should be propagated. The
+	// next call should create a new stream.
+	stream1.EXPECT().Send(request).Return(io.EOF)
+	stream1.EXPECT().Recv().Return(nil, status.Error(codes.Unavailable, "Connection reset"))
+	_, err := client.Synchronize(ctx, request)
+	testutil.RequireEqualStatus(t, status.Error(codes.Unavailable, "Connection reset"), err)
+
+	stream2 := mock.NewMockOperationQueue_SynchronizeStreamClient(ctrl)
+	baseClient.EXPECT().SynchronizeStream(gomock.Any()).Return(stream2, nil)
+	stream2.EXPECT().Send(request)
+	stream2.EXPECT().Recv().Return(response, nil)
+	actualResponse, err := client.Synchronize(ctx, request)
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, response, actualResponse)
+
+	// Canceling the context of a call should cause the stream to be
+	// torn down.
+	canceledCtx, cancel := context.WithCancel(ctx)
+	stream2.EXPECT().Send(request)
+	stream2.EXPECT().Recv().DoAndReturn(func() (*remoteworker.SynchronizeResponse, error) {
+		cancel()
+		return nil, status.Error(codes.Canceled, "context canceled")
+	})
+	_, err = client.Synchronize(canceledCtx, request)
+	testutil.RequireEqualStatus(t, status.Error(codes.Canceled, "context canceled"), err)
+}
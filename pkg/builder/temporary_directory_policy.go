@@ -0,0 +1,76 @@
+package builder
+
+import (
+	"math"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// TemporaryDirectoryBackingDisk is the value of the platform
+	// property that causes the temporary directory of an action to
+	// be backed by the FilePool of the build directory.
+	TemporaryDirectoryBackingDisk = "disk"
+	// TemporaryDirectoryBackingMemory is the value of the platform
+	// property that causes the temporary directory of an action to
+	// be backed by memory.
+	TemporaryDirectoryBackingMemory = "memory"
+)
+
+// TemporaryDirectoryPolicy determines whether files stored in the
+// temporary directory of an action are backed by the FilePool of the
+// build directory (typically disk), or by memory. Actions can choose
+// between these using a platform property. I/O heavy tests may run
+// significantly faster if their temporary directory is backed by
+// memory.
+//
+// As memory tends to be scarce, the total amount of memory used by
+// temporary directories of actions sharing the same
+// TemporaryDirectoryPolicy is capped. Individual actions are also
+// limited in how much memory they may use. Writes exceeding these
+// limits fail with ENOSPC.
+type TemporaryDirectoryPolicy struct {
+	platformPropertyName      string
+	filePool                  re_filesystem.FilePool
+	maximumSizeBytesPerAction int64
+}
+
+// NewTemporaryDirectoryPolicy creates a TemporaryDirectoryPolicy that
+// inspects a platform property with a given name.
+func NewTemporaryDirectoryPolicy(platformPropertyName string, maximumTotalSizeBytes, maximumSizeBytesPerAction int64) *TemporaryDirectoryPolicy {
+	return &TemporaryDirectoryPolicy{
+		platformPropertyName: platformPropertyName,
+		filePool: re_filesystem.NewQuotaEnforcingFilePool(
+			re_filesystem.InMemoryFilePool,
+			math.MaxInt64,
+			maximumTotalSizeBytes),
+		maximumSizeBytesPerAction: maximumSizeBytesPerAction,
+	}
+}
+
+// GetFilePool returns the FilePool that should be used to store files
+// in the temporary directory of an action. If the temporary directory
+// should be backed by the FilePool of the build directory, nil is
+// returned.
+func (tdp *TemporaryDirectoryPolicy) GetFilePool(platform *remoteexecution.Platform) (re_filesystem.FilePool, error) {
+	for _, property := range platform.GetProperties() {
+		if property.Name == tdp.platformPropertyName {
+			switch property.Value {
+			case TemporaryDirectoryBackingDisk:
+				return nil, nil
+			case TemporaryDirectoryBackingMemory:
+				return re_filesystem.NewQuotaEnforcingFilePool(
+					tdp.filePool,
+					math.MaxInt64,
+					tdp.maximumSizeBytesPerAction), nil
+			default:
+				return nil, status.Errorf(codes.InvalidArgument, "Platform property %#v has value %#v, while %#v or %#v was expected", property.Name, property.Value, TemporaryDirectoryBackingDisk, TemporaryDirectoryBackingMemory)
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,75 @@
+package builder_test
+
+import (
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTemporaryDirectoryPolicy(t *testing.T) {
+	temporaryDirectoryPolicy := builder.NewTemporaryDirectoryPolicy("tmpdir", 100, 60)
+
+	t.Run("NoPlatformProperty", func(t *testing.T) {
+		filePool, err := temporaryDirectoryPolicy.GetFilePool(&remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "OSFamily", Value: "linux"},
+			},
+		})
+		require.NoError(t, err)
+		require.Nil(t, filePool)
+	})
+
+	t.Run("Disk", func(t *testing.T) {
+		filePool, err := temporaryDirectoryPolicy.GetFilePool(&remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "tmpdir", Value: "disk"},
+			},
+		})
+		require.NoError(t, err)
+		require.Nil(t, filePool)
+	})
+
+	t.Run("InvalidValue", func(t *testing.T) {
+		_, err := temporaryDirectoryPolicy.GetFilePool(&remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "tmpdir", Value: "tmpfs"},
+			},
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Platform property \"tmpdir\" has value \"tmpfs\", while \"disk\" or \"memory\" was expected"), err)
+	})
+
+	t.Run("Memory", func(t *testing.T) {
+		platform := &remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "tmpdir", Value: "memory"},
+			},
+		}
+
+		// Every action may use up to 60 bytes of memory.
+		filePool1, err := temporaryDirectoryPolicy.GetFilePool(platform)
+		require.NoError(t, err)
+		f1, err := filePool1.NewFile()
+		require.NoError(t, err)
+		require.NoError(t, f1.Truncate(60))
+		testutil.RequireEqualStatus(t, status.Error(codes.ResourceExhausted, "File size quota reached"), f1.Truncate(61))
+
+		// All actions combined may only use 100 bytes of memory.
+		filePool2, err := temporaryDirectoryPolicy.GetFilePool(platform)
+		require.NoError(t, err)
+		f2, err := filePool2.NewFile()
+		require.NoError(t, err)
+		require.NoError(t, f2.Truncate(40))
+		testutil.RequireEqualStatus(t, status.Error(codes.ResourceExhausted, "File size quota reached"), f2.Truncate(41))
+
+		// Space is released when files are closed.
+		require.NoError(t, f1.Close())
+		require.NoError(t, f2.Truncate(60))
+		require.NoError(t, f2.Close())
+	})
+}
@@ -23,3 +23,18 @@ type UploadableDirectory interface {
 	// Upload a file into the Content Addressable Storage.
 	UploadFile(ctx context.Context, name path.Component, digestFunction digest.Function) (digest.Digest, error)
 }
+
+// SnapshotUploadableDirectory is an optional extension of
+// UploadableDirectory. It is implemented by directories that can create
+// an immutable snapshot of their contents, and upload it without
+// traversing the directory hierarchy through UploadableDirectory.
+// Files whose digests are already known don't need to be reread.
+type SnapshotUploadableDirectory interface {
+	UploadableDirectory
+
+	// Upload a snapshot of the directory hierarchy into the Content
+	// Addressable Storage. Directory messages are not uploaded, but
+	// are provided to a callback, so that they may be combined into
+	// a Tree.
+	UploadSnapshot(ctx context.Context, digestFunction digest.Function, directoryProcessor func(directoryDigest digest.Digest, data []byte)) (digest.Digest, error)
+}
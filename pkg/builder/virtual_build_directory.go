@@ -2,18 +2,22 @@ package builder
 
 import (
 	"context"
+	"io"
 	"os"
 	"syscall"
+	"time"
 
 	"github.com/buildbarn/bb-remote-execution/pkg/cas"
 	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
 	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
 	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
 	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/clock"
 	"github.com/buildbarn/bb-storage/pkg/digest"
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
 	"github.com/buildbarn/bb-storage/pkg/util"
+	"golang.org/x/sync/semaphore"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -23,13 +27,31 @@ type virtualBuildDirectoryOptions struct {
 	directoryFetcher          cas.DirectoryFetcher
 	contentAddressableStorage blobstore.BlobAccess
 	symlinkFactory            virtual.SymlinkFactory
+	symlinkTargetRewriter     virtual.SymlinkTargetRewriter
 	characterDeviceFactory    virtual.CharacterDeviceFactory
 	handleAllocator           virtual.StatefulHandleAllocator
+
+	backgroundUploadContentAddressableStorage blobstore.BlobAccess
+	backgroundUploadSemaphore                 *semaphore.Weighted
+	fileDeduplicationOptions                  *virtual.FileDeduplicationOptions
+	casFileReadaheadOptions                   *virtual.CASFileReadaheadOptions
+
+	quiescentFileConversionContentAddressableStorage blobstore.BlobAccess
+	quiescentFileConversionPeriod                    time.Duration
 }
 
 type virtualBuildDirectory struct {
 	virtual.PrepopulatedDirectory
 	options *virtualBuildDirectoryOptions
+
+	backgroundUploader         *virtual.BackgroundUploader
+	stopQuiescentFileConverter func()
+
+	// CASFileFactory for input files, returned by the
+	// deduplicating file allocator. It is shared with
+	// subdirectories, so that input files can be registered
+	// regardless of the directory to which they are merged.
+	inputCASFileFactory virtual.CASFileFactory
 }
 
 // NewVirtualBuildDirectory creates a BuildDirectory that is backed by a
@@ -37,15 +59,47 @@ type virtualBuildDirectory struct {
 // input root explicitly, it calls PrepopulatedDirectory.CreateChildren
 // to add special file and directory nodes whose contents are read on
 // demand.
-func NewVirtualBuildDirectory(directory virtual.PrepopulatedDirectory, directoryFetcher cas.DirectoryFetcher, contentAddressableStorage blobstore.BlobAccess, symlinkFactory virtual.SymlinkFactory, characterDeviceFactory virtual.CharacterDeviceFactory, handleAllocator virtual.StatefulHandleAllocator) BuildDirectory {
+//
+// If a BlobAccess for background uploads is provided, files stored at
+// the paths passed to UploadOutputsInBackground() are uploaded into the
+// Content Addressable Storage as soon as they are closed by the build
+// action. The semaphore limits the number of concurrent background
+// uploads.
+//
+// If file deduplication options are provided, output files of a build
+// action having identical contents share storage in the file pool.
+// Output files that are copies of input files are read from the
+// Content Addressable Storage, no longer using any space in the file
+// pool. If readahead options are provided, input files are read ahead
+// once they are accessed sequentially.
+//
+// Targets of symbolic links contained in the input root are processed
+// by the provided SymlinkTargetRewriter. This can be used to prevent
+// absolute symbolic links from resolving to files on the host.
+//
+// If a quiescent file conversion period is provided, output files that
+// remain unmodified for this amount of time are uploaded into the
+// provided BlobAccess while the build action is running, so that they
+// no longer occupy space in the file pool. Like the BlobAccess for
+// background uploads, this BlobAccess should not batch writes.
+func NewVirtualBuildDirectory(directory virtual.PrepopulatedDirectory, directoryFetcher cas.DirectoryFetcher, contentAddressableStorage blobstore.BlobAccess, symlinkFactory virtual.SymlinkFactory, symlinkTargetRewriter virtual.SymlinkTargetRewriter, characterDeviceFactory virtual.CharacterDeviceFactory, handleAllocator virtual.StatefulHandleAllocator, backgroundUploadContentAddressableStorage blobstore.BlobAccess, backgroundUploadSemaphore *semaphore.Weighted, fileDeduplicationOptions *virtual.FileDeduplicationOptions, casFileReadaheadOptions *virtual.CASFileReadaheadOptions, quiescentFileConversionContentAddressableStorage blobstore.BlobAccess, quiescentFileConversionPeriod time.Duration) BuildDirectory {
 	return &virtualBuildDirectory{
 		PrepopulatedDirectory: directory,
 		options: &virtualBuildDirectoryOptions{
 			directoryFetcher:          directoryFetcher,
 			contentAddressableStorage: contentAddressableStorage,
 			symlinkFactory:            symlinkFactory,
+			symlinkTargetRewriter:     symlinkTargetRewriter,
 			characterDeviceFactory:    characterDeviceFactory,
 			handleAllocator:           handleAllocator,
+
+			backgroundUploadContentAddressableStorage: backgroundUploadContentAddressableStorage,
+			backgroundUploadSemaphore:                 backgroundUploadSemaphore,
+			fileDeduplicationOptions:                  fileDeduplicationOptions,
+			casFileReadaheadOptions:                   casFileReadaheadOptions,
+
+			quiescentFileConversionContentAddressableStorage: quiescentFileConversionContentAddressableStorage,
+			quiescentFileConversionPeriod:                    quiescentFileConversionPeriod,
 		},
 	}
 }
@@ -62,11 +116,17 @@ func (d *virtualBuildDirectory) EnterBuildDirectory(name path.Component) (BuildD
 	return &virtualBuildDirectory{
 		PrepopulatedDirectory: directory,
 		options:               d.options,
+		inputCASFileFactory:   d.inputCASFileFactory,
 	}, nil
 }
 
 func (d *virtualBuildDirectory) Close() error {
 	// Virtual directories do not need to be released explicitly.
+	// Only stop converting quiescent files, if enabled.
+	if stop := d.stopQuiescentFileConverter; stop != nil {
+		stop()
+		d.stopQuiescentFileConverter = nil
+	}
 	return nil
 }
 
@@ -78,26 +138,97 @@ func (d *virtualBuildDirectory) EnterUploadableDirectory(name path.Component) (U
 	return d.EnterBuildDirectory(name)
 }
 
-func (d *virtualBuildDirectory) InstallHooks(filePool re_filesystem.FilePool, errorLogger util.ErrorLogger) {
+func (d *virtualBuildDirectory) InstallHooks(ctx context.Context, filePool re_filesystem.FilePool, errorLogger util.ErrorLogger, digestFunction digest.Function) {
+	var backgroundUploader *virtual.BackgroundUploader
+	if d.options.backgroundUploadContentAddressableStorage != nil {
+		backgroundUploader = virtual.NewBackgroundUploader(
+			d.options.backgroundUploadContentAddressableStorage,
+			digestFunction,
+			d.options.backgroundUploadSemaphore)
+	}
+	d.backgroundUploader = backgroundUploader
+	var fileAllocator virtual.FileAllocator
+	if d.options.fileDeduplicationOptions != nil {
+		fileAllocator, d.inputCASFileFactory = virtual.NewDeduplicatingPoolBackedFileAllocator(
+			filePool,
+			errorLogger,
+			clock.SystemClock,
+			backgroundUploader,
+			digestFunction,
+			d.newCASFileFactory(ctx, errorLogger),
+			d.options.fileDeduplicationOptions)
+	} else {
+		fileAllocator = virtual.NewBackgroundUploadingPoolBackedFileAllocator(filePool, errorLogger, clock.SystemClock, backgroundUploader)
+	}
+	// Let statfs() against the build directory report the
+	// capacity of the file pool of the action, as opposed to that
+	// of the file system as a whole.
+	var statFSProvider virtual.StatFSProvider
+	if _, err := re_filesystem.GetFilePoolUsage(filePool); err == nil {
+		statFSProvider = virtual.NewFilePoolStatFSProvider(filePool, errorLogger)
+	}
 	d.PrepopulatedDirectory.InstallHooks(
-		virtual.NewHandleAllocatingFileAllocator(
-			virtual.NewPoolBackedFileAllocator(filePool, errorLogger),
-			d.options.handleAllocator),
-		errorLogger)
+		virtual.NewHandleAllocatingFileAllocator(fileAllocator, d.options.handleAllocator),
+		errorLogger,
+		statFSProvider)
+
+	// Optionally convert output files that are no longer being
+	// modified to files backed by the Content Addressable Storage
+	// for the duration of the build action.
+	if d.options.quiescentFileConversionPeriod > 0 && d.stopQuiescentFileConverter == nil {
+		converter := virtual.NewQuiescentFileConverter(
+			d.PrepopulatedDirectory,
+			d.options.quiescentFileConversionContentAddressableStorage,
+			digestFunction,
+			d.newCASFileFactory(ctx, errorLogger),
+			clock.SystemClock,
+			d.options.quiescentFileConversionPeriod,
+			errorLogger)
+		ctxConverter, cancelConverter := context.WithCancel(ctx)
+		converterDone := make(chan struct{})
+		go func() {
+			converter.Run(ctxConverter)
+			close(converterDone)
+		}()
+		d.stopQuiescentFileConverter = func() {
+			cancelConverter()
+			<-converterDone
+		}
+	}
+}
+
+func (d *virtualBuildDirectory) UploadOutputsInBackground(ctx context.Context, outputPaths [][]path.Component) func() {
+	if d.backgroundUploader == nil || len(outputPaths) == 0 {
+		return func() {}
+	}
+	return d.backgroundUploader.Start(ctx, d.PrepopulatedDirectory, outputPaths)
+}
+
+// newCASFileFactory creates a CASFileFactory for files in the build
+// directory that are backed by the Content Addressable Storage.
+func (d *virtualBuildDirectory) newCASFileFactory(ctx context.Context, errorLogger util.ErrorLogger) virtual.CASFileFactory {
+	return virtual.NewStatelessHandleAllocatingCASFileFactory(
+		virtual.NewReadaheadBlobAccessCASFileFactory(
+			ctx,
+			d.options.contentAddressableStorage,
+			errorLogger,
+			d.options.casFileReadaheadOptions),
+		d.options.handleAllocator.New())
 }
 
-func (d *virtualBuildDirectory) MergeDirectoryContents(ctx context.Context, errorLogger util.ErrorLogger, digest digest.Digest, monitor access.UnreadDirectoryMonitor) error {
+func (d *virtualBuildDirectory) MergeDirectoryContents(ctx context.Context, errorLogger util.ErrorLogger, digest digest.Digest, monitor access.UnreadDirectoryMonitor, depth int) error {
+	casFileFactory := d.inputCASFileFactory
+	if casFileFactory == nil {
+		casFileFactory = d.newCASFileFactory(ctx, errorLogger)
+	}
 	initialContentsFetcher := virtual.NewCASInitialContentsFetcher(
 		ctx,
 		cas.NewDecomposedDirectoryWalker(d.options.directoryFetcher, digest),
-		virtual.NewStatelessHandleAllocatingCASFileFactory(
-			virtual.NewBlobAccessCASFileFactory(
-				ctx,
-				d.options.contentAddressableStorage,
-				errorLogger),
-			d.options.handleAllocator.New()),
+		casFileFactory,
 		d.options.symlinkFactory,
-		digest.GetDigestFunction())
+		d.options.symlinkTargetRewriter,
+		digest.GetDigestFunction(),
+		depth)
 	if monitor != nil {
 		initialContentsFetcher = virtual.NewAccessMonitoringInitialContentsFetcher(initialContentsFetcher, monitor)
 	}
@@ -119,6 +250,15 @@ func (d *virtualBuildDirectory) UploadFile(ctx context.Context, name path.Compon
 	return digest.BadDigest, syscall.EISDIR
 }
 
+func (d *virtualBuildDirectory) UploadSnapshot(ctx context.Context, digestFunction digest.Function, directoryProcessor func(directoryDigest digest.Digest, data []byte)) (digest.Digest, error) {
+	snapshot, err := d.CreateSnapshot()
+	if err != nil {
+		return digest.BadDigest, util.StatusWrap(err, "Failed to create snapshot")
+	}
+	defer snapshot.Release()
+	return snapshot.Upload(ctx, d.options.contentAddressableStorage, digestFunction, directoryProcessor)
+}
+
 func (d *virtualBuildDirectory) Lstat(name path.Component) (filesystem.FileInfo, error) {
 	child, err := d.LookupChild(name)
 	if err != nil {
@@ -150,6 +290,56 @@ func (d *virtualBuildDirectory) Mknod(name path.Component, perm os.FileMode, dev
 	return nil
 }
 
+func (d *virtualBuildDirectory) OpenRead(name path.Component) (filesystem.FileReader, error) {
+	child, err := d.LookupChild(name)
+	if err != nil {
+		return nil, err
+	}
+	_, leaf := child.GetPair()
+	if leaf == nil {
+		return nil, syscall.EISDIR
+	}
+	if s := leaf.VirtualOpenSelf(context.Background(), virtual.ShareMaskRead, &virtual.OpenExistingOptions{}, 0, &virtual.Attributes{}); s != virtual.StatusOK {
+		return nil, status.Errorf(codes.Internal, "Failed to open file: status %d", s)
+	}
+	return &virtualFileReader{leaf: leaf}, nil
+}
+
+// virtualFileReader is an implementation of filesystem.FileReader
+// that reads the contents of a file stored in a virtual build
+// directory. It keeps the file opened, so that its contents remain
+// available if it is removed.
+type virtualFileReader struct {
+	leaf virtual.NativeLeaf
+}
+
+func (r *virtualFileReader) ReadAt(p []byte, off int64) (int, error) {
+	n, eof, s := r.leaf.VirtualRead(p, uint64(off))
+	if s != virtual.StatusOK {
+		return 0, status.Errorf(codes.Internal, "Failed to read from file at offset %d: status %d", off, s)
+	}
+	if eof && n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *virtualFileReader) GetNextRegionOffset(off int64, regionType filesystem.RegionType) (int64, error) {
+	offset, s := r.leaf.VirtualSeek(uint64(off), regionType)
+	if s == virtual.StatusErrNXIO || (s == virtual.StatusOK && offset == nil) {
+		return 0, io.EOF
+	} else if s != virtual.StatusOK {
+		return 0, status.Errorf(codes.Internal, "Failed to seek file at offset %d: status %d", off, s)
+	}
+	return int64(*offset), nil
+}
+
+func (r *virtualFileReader) Close() error {
+	r.leaf.VirtualClose(virtual.ShareMaskRead)
+	r.leaf = nil
+	return nil
+}
+
 func (d *virtualBuildDirectory) Readlink(name path.Component) (string, error) {
 	child, err := d.LookupChild(name)
 	if err != nil {
@@ -0,0 +1,91 @@
+package builder
+
+import (
+	"context"
+	"sync"
+
+	"github.com/buildbarn/bb-storage/pkg/util"
+)
+
+// WorkerDrainer can be used to let worker threads stop picking up new
+// build actions, while allowing actions that are currently running to
+// complete. This is useful when the worker is about to be terminated,
+// such as when Kubernetes invokes a pod's preStop lifecycle hook.
+type WorkerDrainer struct {
+	lock            sync.Mutex
+	isDraining      bool
+	drainStarted    chan struct{}
+	activeThreads   int
+	threadsFinished chan struct{}
+	isFinished      bool
+}
+
+// NewWorkerDrainer creates a WorkerDrainer that is in the initial
+// state, where worker threads are permitted to pick up new actions.
+func NewWorkerDrainer() *WorkerDrainer {
+	return &WorkerDrainer{
+		drainStarted:    make(chan struct{}),
+		threadsFinished: make(chan struct{}),
+	}
+}
+
+// IsDraining returns whether draining of the worker has started.
+func (wd *WorkerDrainer) IsDraining() bool {
+	wd.lock.Lock()
+	defer wd.lock.Unlock()
+	return wd.isDraining
+}
+
+func (wd *WorkerDrainer) markFinishedIfIdleLocked() {
+	if wd.isDraining && wd.activeThreads == 0 && !wd.isFinished {
+		close(wd.threadsFinished)
+		wd.isFinished = true
+	}
+}
+
+// Drain the worker, causing worker threads to terminate as soon as
+// they have finished executing their current action. This function
+// blocks until all worker threads have terminated, or until the
+// provided context is canceled.
+func (wd *WorkerDrainer) Drain(ctx context.Context) error {
+	wd.lock.Lock()
+	if !wd.isDraining {
+		wd.isDraining = true
+		close(wd.drainStarted)
+		wd.markFinishedIfIdleLocked()
+	}
+	wd.lock.Unlock()
+
+	select {
+	case <-wd.threadsFinished:
+		return nil
+	case <-ctx.Done():
+		return util.StatusFromContext(ctx)
+	}
+}
+
+// enterThread registers a worker thread. It returns a context that is
+// canceled once draining starts, and a function that needs to be
+// called when the worker thread terminates.
+func (wd *WorkerDrainer) enterThread(ctx context.Context) (context.Context, func()) {
+	wd.lock.Lock()
+	wd.activeThreads++
+	wd.lock.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-wd.drainStarted:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		cancel()
+
+		wd.lock.Lock()
+		wd.activeThreads--
+		wd.markFinishedIfIdleLocked()
+		wd.lock.Unlock()
+	}
+}
@@ -0,0 +1,20 @@
+package builder_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerDrainer(t *testing.T) {
+	workerDrainer := builder.NewWorkerDrainer()
+	require.False(t, workerDrainer.IsDraining())
+
+	// In the absence of any worker threads, draining should
+	// complete immediately. Calling it repeatedly should be safe.
+	require.NoError(t, workerDrainer.Drain(context.Background()))
+	require.True(t, workerDrainer.IsDraining())
+	require.NoError(t, workerDrainer.Drain(context.Background()))
+}
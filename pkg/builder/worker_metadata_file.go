@@ -0,0 +1,153 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+	"strings"
+	"sync"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// workerMetadata is the schema of the file that is placed inside the
+// input root of actions by WorkerMetadataFile.
+type workerMetadata struct {
+	OperatingSystem string            `json:"os"`
+	Architecture    string            `json:"architecture"`
+	SizeClass       uint32            `json:"size_class"`
+	WorkerID        map[string]string `json:"worker_id"`
+}
+
+// WorkerMetadataFile is a read-only file that is placed inside the
+// input root of every action executed by a worker thread. It contains
+// information about the worker thread in JSON form, so that
+// diagnostics emitted by actions (e.g., test logs) can identify where
+// they ran.
+//
+// Only a sanitized subset of the worker ID is exposed, as it may
+// contain labels that should not be visible to actions.
+type WorkerMetadataFile struct {
+	pathString                string
+	components                []path.Component
+	contentAddressableStorage blobstore.BlobAccess
+	contents                  []byte
+
+	lock             sync.Mutex
+	directoryDigests map[workerMetadataFileKey]digest.Digest
+}
+
+// workerMetadataFileKey is the key of the map in which
+// WorkerMetadataFile caches the digests of directories that have
+// already been stored in the Content Addressable Storage.
+type workerMetadataFileKey struct {
+	instanceName   string
+	digestFunction remoteexecution.DigestFunction_Value
+}
+
+// NewWorkerMetadataFile creates a WorkerMetadataFile that is stored at
+// a given path relative to the input root. Only the worker ID labels
+// whose keys are provided are exposed. The file and the directory
+// containing it are stored in the provided Content Addressable Storage
+// prior to being merged into the input root. Writes against the
+// Content Addressable Storage must be visible to subsequent reads
+// immediately, meaning that batching BlobAccess implementations may not
+// be used.
+//
+// As the contents of the file are identical for every action, they are
+// only stored once for every instance name and digest function.
+func NewWorkerMetadataFile(pathString string, contentAddressableStorage blobstore.BlobAccess, sizeClass uint32, workerID map[string]string, workerIDKeys []string) (*WorkerMetadataFile, error) {
+	var filePath outputNodePath
+	if err := path.Resolve(pathString, path.NewRelativeScopeWalker(&filePath)); err != nil {
+		return nil, util.StatusWrapf(err, "Invalid worker metadata file path %#v", pathString)
+	}
+	if len(filePath.components) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Worker metadata file path %#v resolves to the input root directory", pathString)
+	}
+
+	sanitizedWorkerID := map[string]string{}
+	for _, key := range workerIDKeys {
+		if value, ok := workerID[key]; ok {
+			sanitizedWorkerID[key] = value
+		}
+	}
+	contents, err := json.Marshal(&workerMetadata{
+		OperatingSystem: runtime.GOOS,
+		Architecture:    runtime.GOARCH,
+		SizeClass:       sizeClass,
+		WorkerID:        sanitizedWorkerID,
+	})
+	if err != nil {
+		return nil, util.StatusWrap(err, "Failed to marshal worker metadata")
+	}
+
+	return &WorkerMetadataFile{
+		pathString:                strings.Trim(pathString, "/"),
+		components:                filePath.components,
+		contentAddressableStorage: contentAddressableStorage,
+		contents:                  append(contents, '\n'),
+		directoryDigests:          map[workerMetadataFileKey]digest.Digest{},
+	}, nil
+}
+
+// getDirectoryDigest returns the digest of a directory containing only
+// the worker metadata file. The file and the directory are stored in
+// the Content Addressable Storage the first time this function is
+// called for a given instance name and digest function.
+func (f *WorkerMetadataFile) getDirectoryDigest(ctx context.Context, digestFunction digest.Function) (digest.Digest, error) {
+	key := workerMetadataFileKey{
+		instanceName:   digestFunction.GetInstanceName().String(),
+		digestFunction: digestFunction.GetEnumValue(),
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if directoryDigest, ok := f.directoryDigests[key]; ok {
+		return directoryDigest, nil
+	}
+
+	name := f.components[len(f.components)-1]
+	fileDigest, err := putBlob(ctx, f.contentAddressableStorage, digestFunction, f.contents)
+	if err != nil {
+		return digest.BadDigest, util.StatusWrapf(err, "Failed to store contents of worker metadata file %#v", f.pathString)
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(&remoteexecution.Directory{
+		Files: []*remoteexecution.FileNode{{
+			Name:   name.String(),
+			Digest: fileDigest.GetProto(),
+		}},
+	})
+	if err != nil {
+		return digest.BadDigest, util.StatusWrapf(err, "Failed to marshal directory of worker metadata file %#v", f.pathString)
+	}
+	directoryDigest, err := putBlob(ctx, f.contentAddressableStorage, digestFunction, data)
+	if err != nil {
+		return digest.BadDigest, util.StatusWrapf(err, "Failed to store directory of worker metadata file %#v", f.pathString)
+	}
+	f.directoryDigests[key] = directoryDigest
+	return directoryDigest, nil
+}
+
+// Populate creates the worker metadata file inside the input root of
+// an action. The file may not already be part of the input root.
+func (f *WorkerMetadataFile) Populate(ctx context.Context, inputRootDirectory BuildDirectory, errorLogger util.ErrorLogger, digestFunction digest.Function) error {
+	// Store the file and a directory containing only the file in
+	// the Content Addressable Storage. This permits adding it to
+	// the input root through MergeDirectoryContents(), causing it
+	// to be read-only regardless of the type of build directory.
+	directoryDigest, err := f.getDirectoryDigest(ctx, digestFunction)
+	if err != nil {
+		return err
+	}
+	if err := mergeDirectoryContentsAtPath(ctx, inputRootDirectory, f.components, errorLogger, directoryDigest); err != nil {
+		return util.StatusWrapf(err, "Failed to create worker metadata file %#v", f.pathString)
+	}
+	return nil
+}
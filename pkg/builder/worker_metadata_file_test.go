@@ -0,0 +1,124 @@
+package builder_test
+
+import (
+	"context"
+	"encoding/json"
+	"syscall"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWorkerMetadataFile(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	blobs := map[digest.Digest][]byte{}
+	putCount := 0
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	contentAddressableStorage.EXPECT().Put(ctx, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, blobDigest digest.Digest, b buffer.Buffer) error {
+			data, err := b.ToByteSlice(10000)
+			require.NoError(t, err)
+			blobs[blobDigest] = data
+			putCount++
+			return nil
+		}).
+		AnyTimes()
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	digestFunction := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5).GetDigestFunction()
+	workerID := map[string]string{
+		"hostname": "worker-7f9c",
+		"secret":   "hunter2",
+		"thread":   "3",
+	}
+
+	t.Run("InvalidPath", func(t *testing.T) {
+		_, err := builder.NewWorkerMetadataFile("..", contentAddressableStorage, 1, workerID, nil)
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Invalid worker metadata file path \"..\": Path resolves to a location outside the input root directory"), err)
+
+		_, err = builder.NewWorkerMetadataFile(".", contentAddressableStorage, 1, workerID, nil)
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Worker metadata file path \".\" resolves to the input root directory"), err)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		// The file should be merged into its parent directory,
+		// only containing worker ID labels that are permitted.
+		workerMetadataFile, err := builder.NewWorkerMetadataFile(".buildbarn/worker.json", contentAddressableStorage, 4, workerID, []string{"hostname", "thread", "nonexistent"})
+		require.NoError(t, err)
+
+		inputRootDirectory := mock.NewMockBuildDirectory(ctrl)
+		inputRootDirectory.EXPECT().Mkdir(path.MustNewComponent(".buildbarn"), gomock.Any())
+		buildbarnDirectory := mock.NewMockBuildDirectory(ctrl)
+		inputRootDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent(".buildbarn")).Return(buildbarnDirectory, nil)
+		buildbarnDirectory.EXPECT().Lstat(path.MustNewComponent("worker.json")).Return(filesystem.FileInfo{}, syscall.ENOENT)
+		buildbarnDirectory.EXPECT().MergeDirectoryContents(ctx, errorLogger, gomock.Any(), nil, 1).
+			DoAndReturn(func(ctx context.Context, errorLogger util.ErrorLogger, directoryDigest digest.Digest, monitor access.UnreadDirectoryMonitor, depth int) error {
+				var directory remoteexecution.Directory
+				require.NoError(t, proto.Unmarshal(blobs[directoryDigest], &directory))
+				require.Len(t, directory.Files, 1)
+				require.Equal(t, "worker.json", directory.Files[0].Name)
+				require.False(t, directory.Files[0].IsExecutable)
+
+				fileDigest, err := digestFunction.NewDigestFromProto(directory.Files[0].Digest)
+				require.NoError(t, err)
+				var metadata map[string]interface{}
+				require.NoError(t, json.Unmarshal(blobs[fileDigest], &metadata))
+				require.Equal(t, float64(4), metadata["size_class"])
+				require.Equal(t, map[string]interface{}{
+					"hostname": "worker-7f9c",
+					"thread":   "3",
+				}, metadata["worker_id"])
+				return nil
+			})
+		buildbarnDirectory.EXPECT().Close()
+
+		putCount = 0
+		require.NoError(t, workerMetadataFile.Populate(ctx, inputRootDirectory, errorLogger, digestFunction))
+		require.Equal(t, 2, putCount)
+
+		// Subsequent actions should reuse the file and directory
+		// that were stored previously.
+		inputRootDirectory.EXPECT().Mkdir(path.MustNewComponent(".buildbarn"), gomock.Any()).Return(syscall.EEXIST)
+		inputRootDirectory.EXPECT().EnterBuildDirectory(path.MustNewComponent(".buildbarn")).Return(buildbarnDirectory, nil)
+		buildbarnDirectory.EXPECT().Lstat(path.MustNewComponent("worker.json")).Return(filesystem.FileInfo{}, syscall.ENOENT)
+		buildbarnDirectory.EXPECT().MergeDirectoryContents(ctx, errorLogger, gomock.Any(), nil, 1).
+			DoAndReturn(func(ctx context.Context, errorLogger util.ErrorLogger, directoryDigest digest.Digest, monitor access.UnreadDirectoryMonitor, depth int) error {
+				require.Contains(t, blobs, directoryDigest)
+				return nil
+			})
+		buildbarnDirectory.EXPECT().Close()
+
+		require.NoError(t, workerMetadataFile.Populate(ctx, inputRootDirectory, errorLogger, digestFunction))
+		require.Equal(t, 2, putCount)
+	})
+
+	t.Run("Collision", func(t *testing.T) {
+		// The worker metadata file may not be part of the input
+		// root.
+		workerMetadataFile, err := builder.NewWorkerMetadataFile("worker.json", contentAddressableStorage, 1, workerID, nil)
+		require.NoError(t, err)
+
+		inputRootDirectory := mock.NewMockBuildDirectory(ctrl)
+		inputRootDirectory.EXPECT().Lstat(path.MustNewComponent("worker.json")).Return(filesystem.NewFileInfo(path.MustNewComponent("worker.json"), filesystem.FileTypeRegularFile, false), nil)
+
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.InvalidArgument, "Failed to create worker metadata file \"worker.json\": Path collides with a file or directory in the input root"),
+			workerMetadataFile.Populate(ctx, inputRootDirectory, errorLogger, digestFunction))
+	})
+}
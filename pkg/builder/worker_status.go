@@ -0,0 +1,176 @@
+package builder
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ExecutingActionState contains information on an action that is
+// currently being executed by one of the threads of the worker, as
+// returned by WorkerStatus.GetExecutingActions().
+type ExecutingActionState struct {
+	WorkerName     string
+	DigestFunction digest.Function
+	ActionDigest   *remoteexecution.Digest
+	Stage          string
+	StartTime      time.Time
+	StageStartTime time.Time
+
+	FilePoolFilesCount     uint64
+	FilePoolFilesSizeBytes uint64
+}
+
+// RecentError contains information on an action that failed, as
+// returned by WorkerStatus.GetRecentErrors().
+type RecentError struct {
+	WorkerName     string
+	DigestFunction digest.Function
+	ActionDigest   *remoteexecution.Digest
+	Time           time.Time
+	Status         *status.Status
+}
+
+type workerStatusExecutingAction struct {
+	state    ExecutingActionState
+	filePool *statsCollectingFilePool
+}
+
+// WorkerStatus keeps track of the actions that are currently being
+// executed by the threads of a worker, and the errors that occurred
+// most recently. This information is displayed by bb_worker's web UI,
+// making it possible to debug individual workers at a glance.
+type WorkerStatus struct {
+	clock               clock.Clock
+	maximumRecentErrors int
+
+	lock             sync.Mutex
+	executingActions map[*workerStatusExecutingAction]struct{}
+	recentErrors     []RecentError
+}
+
+// NewWorkerStatus creates a WorkerStatus that initially does not track
+// any actions. At most maximumRecentErrors errors are retained.
+func NewWorkerStatus(clock clock.Clock, maximumRecentErrors int) *WorkerStatus {
+	return &WorkerStatus{
+		clock:               clock,
+		maximumRecentErrors: maximumRecentErrors,
+		executingActions:    map[*workerStatusExecutingAction]struct{}{},
+	}
+}
+
+// GetExecutingActions returns the state of all actions that are
+// currently being executed, sorted by worker name.
+func (ws *WorkerStatus) GetExecutingActions() []ExecutingActionState {
+	ws.lock.Lock()
+	states := make([]ExecutingActionState, 0, len(ws.executingActions))
+	for a := range ws.executingActions {
+		state := a.state
+		a.filePool.lock.Lock()
+		state.FilePoolFilesCount = a.filePool.totalFiles
+		state.FilePoolFilesSizeBytes = a.filePool.totalSize
+		a.filePool.lock.Unlock()
+		states = append(states, state)
+	}
+	ws.lock.Unlock()
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].WorkerName < states[j].WorkerName
+	})
+	return states
+}
+
+// GetRecentErrors returns the errors that occurred most recently,
+// starting with the newest.
+func (ws *WorkerStatus) GetRecentErrors() []RecentError {
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+
+	errs := make([]RecentError, 0, len(ws.recentErrors))
+	for i := len(ws.recentErrors) - 1; i >= 0; i-- {
+		errs = append(errs, ws.recentErrors[i])
+	}
+	return errs
+}
+
+type statusTrackingBuildExecutor struct {
+	BuildExecutor
+	workerStatus *WorkerStatus
+	workerName   string
+}
+
+// NewBuildExecutor creates a decorator for BuildExecutor that reports
+// the state of actions executed by a single thread of the worker to
+// the WorkerStatus.
+func (ws *WorkerStatus) NewBuildExecutor(buildExecutor BuildExecutor, workerName string) BuildExecutor {
+	return &statusTrackingBuildExecutor{
+		BuildExecutor: buildExecutor,
+		workerStatus:  ws,
+		workerName:    workerName,
+	}
+}
+
+func (be *statusTrackingBuildExecutor) Execute(ctx context.Context, filePool re_filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, executionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+	ws := be.workerStatus
+	now := ws.clock.Now()
+	a := &workerStatusExecutingAction{
+		state: ExecutingActionState{
+			WorkerName:     be.workerName,
+			DigestFunction: digestFunction,
+			ActionDigest:   request.ActionDigest,
+			Stage:          getExecutionStageName(nil),
+			StartTime:      now,
+			StageStartTime: now,
+		},
+		filePool: &statsCollectingFilePool{base: filePool},
+	}
+	ws.lock.Lock()
+	ws.executingActions[a] = struct{}{}
+	ws.lock.Unlock()
+
+	baseUpdates := make(chan *remoteworker.CurrentState_Executing)
+	baseCompletion := make(chan *remoteexecution.ExecuteResponse)
+	go func() {
+		baseCompletion <- be.BuildExecutor.Execute(ctx, a.filePool, monitor, digestFunction, request, baseUpdates)
+	}()
+
+	for {
+		select {
+		case update := <-baseUpdates:
+			ws.lock.Lock()
+			a.state.Stage = getExecutionStageName(update)
+			a.state.StageStartTime = ws.clock.Now()
+			ws.lock.Unlock()
+
+			executionStateUpdates <- update
+		case response := <-baseCompletion:
+			ws.lock.Lock()
+			delete(ws.executingActions, a)
+			if s := status.FromProto(response.Status); s.Code() != codes.OK && ws.maximumRecentErrors > 0 {
+				if len(ws.recentErrors) >= ws.maximumRecentErrors {
+					ws.recentErrors = ws.recentErrors[1:]
+				}
+				ws.recentErrors = append(ws.recentErrors, RecentError{
+					WorkerName:     be.workerName,
+					DigestFunction: digestFunction,
+					ActionDigest:   request.ActionDigest,
+					Time:           ws.clock.Now(),
+					Status:         s,
+				})
+			}
+			ws.lock.Unlock()
+			return response
+		}
+	}
+}
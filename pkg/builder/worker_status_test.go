@@ -0,0 +1,99 @@
+package builder_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/builder"
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/access"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestWorkerStatus(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	clock := mock.NewMockClock(ctrl)
+	workerStatus := builder.NewWorkerStatus(clock, 1)
+	baseBuildExecutor := mock.NewMockBuildExecutor(ctrl)
+	buildExecutor := workerStatus.NewBuildExecutor(baseBuildExecutor, "thread-1")
+
+	filePool := mock.NewMockFilePool(ctrl)
+	monitor := mock.NewMockUnreadDirectoryMonitor(ctrl)
+	digestFunction := digest.MustNewFunction("hello", remoteexecution.DigestFunction_SHA256)
+	request := &remoteworker.DesiredState_Executing{
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "f5a2ed8e9b5a2a1a0eb26dc7eebc1ea7a1bd4a8e5ad4e20e6fe7cd33db2a0c26",
+			SizeBytes: 123,
+		},
+	}
+
+	// Initially, no actions are being executed.
+	require.Empty(t, workerStatus.GetExecutingActions())
+	require.Empty(t, workerStatus.GetRecentErrors())
+
+	// While executing, the action and its current stage should be
+	// reported.
+	executionStateUpdates := make(chan *remoteworker.CurrentState_Executing)
+	clock.EXPECT().Now().Return(time.Unix(1000, 0))
+	clock.EXPECT().Now().Return(time.Unix(1005, 0))
+	clock.EXPECT().Now().Return(time.Unix(1030, 0))
+	baseBuildExecutor.EXPECT().Execute(ctx, gomock.Any(), monitor, digestFunction, request, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, filePool re_filesystem.FilePool, monitor access.UnreadDirectoryMonitor, digestFunction digest.Function, request *remoteworker.DesiredState_Executing, baseExecutionStateUpdates chan<- *remoteworker.CurrentState_Executing) *remoteexecution.ExecuteResponse {
+			require.Equal(t, []builder.ExecutingActionState{{
+				WorkerName:     "thread-1",
+				DigestFunction: digestFunction,
+				ActionDigest:   request.ActionDigest,
+				Stage:          "starting",
+				StartTime:      time.Unix(1000, 0),
+				StageStartTime: time.Unix(1000, 0),
+			}}, workerStatus.GetExecutingActions())
+
+			baseExecutionStateUpdates <- &remoteworker.CurrentState_Executing{
+				ActionDigest: request.ActionDigest,
+				ExecutionState: &remoteworker.CurrentState_Executing_Running{
+					Running: &emptypb.Empty{},
+				},
+			}
+			// Wait for the update to be forwarded, so that
+			// the stage is guaranteed to be updated.
+			<-executionStateUpdates
+
+			require.Equal(t, []builder.ExecutingActionState{{
+				WorkerName:     "thread-1",
+				DigestFunction: digestFunction,
+				ActionDigest:   request.ActionDigest,
+				Stage:          "running",
+				StartTime:      time.Unix(1000, 0),
+				StageStartTime: time.Unix(1005, 0),
+			}}, workerStatus.GetExecutingActions())
+
+			return &remoteexecution.ExecuteResponse{
+				Status: status.New(codes.Unavailable, "Failed to fetch inputs").Proto(),
+			}
+		})
+
+	testutil.RequireEqualProto(t, &remoteexecution.ExecuteResponse{
+		Status: status.New(codes.Unavailable, "Failed to fetch inputs").Proto(),
+	}, buildExecutor.Execute(ctx, filePool, monitor, digestFunction, request, executionStateUpdates))
+
+	// Once completed, the action should no longer be reported, but
+	// the error it yielded should be retained.
+	require.Empty(t, workerStatus.GetExecutingActions())
+	recentErrors := workerStatus.GetRecentErrors()
+	require.Len(t, recentErrors, 1)
+	require.Equal(t, "thread-1", recentErrors[0].WorkerName)
+	require.Equal(t, time.Unix(1030, 0), recentErrors[0].Time)
+	testutil.RequireEqualStatus(t, status.Error(codes.Unavailable, "Failed to fetch inputs"), recentErrors[0].Status.Err())
+}
@@ -0,0 +1,155 @@
+package cachemissexplainer
+
+import (
+	"strconv"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/cachemissexplainer"
+)
+
+// keyValuePair is a generic representation of entries in a
+// fingerprint that are identified by a key, such as environment
+// variables, platform properties and input files.
+type keyValuePair struct {
+	key   string
+	value string
+}
+
+// diffKeyValuePairs computes the differences between two lists of
+// key-value pairs, both of which are sorted by key. Consecutive entries
+// having the same key are combined, as platform properties may have
+// multiple values.
+func diffKeyValuePairs(field string, oldPairs, newPairs []keyValuePair) (differences []*cachemissexplainer.Difference) {
+	oldPairs, newPairs = combineKeyValuePairs(oldPairs), combineKeyValuePairs(newPairs)
+	for len(oldPairs) > 0 || len(newPairs) > 0 {
+		if len(newPairs) == 0 || (len(oldPairs) > 0 && oldPairs[0].key < newPairs[0].key) {
+			differences = append(differences, &cachemissexplainer.Difference{
+				Type:     cachemissexplainer.Difference_REMOVED,
+				Field:    field,
+				Key:      oldPairs[0].key,
+				OldValue: oldPairs[0].value,
+			})
+			oldPairs = oldPairs[1:]
+		} else if len(oldPairs) == 0 || oldPairs[0].key > newPairs[0].key {
+			differences = append(differences, &cachemissexplainer.Difference{
+				Type:     cachemissexplainer.Difference_ADDED,
+				Field:    field,
+				Key:      newPairs[0].key,
+				NewValue: newPairs[0].value,
+			})
+			newPairs = newPairs[1:]
+		} else {
+			if oldPairs[0].value != newPairs[0].value {
+				differences = append(differences, &cachemissexplainer.Difference{
+					Type:     cachemissexplainer.Difference_MODIFIED,
+					Field:    field,
+					Key:      oldPairs[0].key,
+					OldValue: oldPairs[0].value,
+					NewValue: newPairs[0].value,
+				})
+			}
+			oldPairs, newPairs = oldPairs[1:], newPairs[1:]
+		}
+	}
+	return
+}
+
+func combineKeyValuePairs(pairs []keyValuePair) []keyValuePair {
+	var combined []keyValuePair
+	for _, pair := range pairs {
+		if len(combined) > 0 && combined[len(combined)-1].key == pair.key {
+			combined[len(combined)-1].value += "," + pair.value
+		} else {
+			combined = append(combined, pair)
+		}
+	}
+	return combined
+}
+
+func environmentVariablesToKeyValuePairs(environmentVariables []*remoteexecution.Command_EnvironmentVariable) []keyValuePair {
+	pairs := make([]keyValuePair, 0, len(environmentVariables))
+	for _, environmentVariable := range environmentVariables {
+		pairs = append(pairs, keyValuePair{key: environmentVariable.Name, value: environmentVariable.Value})
+	}
+	return pairs
+}
+
+func platformPropertiesToKeyValuePairs(platformProperties []*remoteexecution.Platform_Property) []keyValuePair {
+	pairs := make([]keyValuePair, 0, len(platformProperties))
+	for _, platformProperty := range platformProperties {
+		pairs = append(pairs, keyValuePair{key: platformProperty.Name, value: platformProperty.Value})
+	}
+	return pairs
+}
+
+func outputPathsToKeyValuePairs(outputPaths []string) []keyValuePair {
+	pairs := make([]keyValuePair, 0, len(outputPaths))
+	for _, outputPath := range outputPaths {
+		pairs = append(pairs, keyValuePair{key: outputPath})
+	}
+	return pairs
+}
+
+// DiffActionFingerprints computes the differences between the
+// fingerprints of two actions. Command line arguments are compared
+// positionally, meaning that inserting a single argument causes all
+// subsequent arguments to be reported as being modified.
+//
+// Input roots are not compared, as doing so requires loading them
+// from the Content Addressable Storage. Use DiffInputRoots for that.
+func DiffActionFingerprints(oldFingerprint, newFingerprint *cachemissexplainer.ActionFingerprint) []*cachemissexplainer.Difference {
+	var differences []*cachemissexplainer.Difference
+
+	// Command line arguments.
+	oldArguments, newArguments := oldFingerprint.Arguments, newFingerprint.Arguments
+	for i := 0; i < len(oldArguments) || i < len(newArguments); i++ {
+		difference := &cachemissexplainer.Difference{
+			Field: "arguments",
+			Key:   strconv.FormatInt(int64(i), 10),
+		}
+		if i >= len(newArguments) {
+			difference.Type = cachemissexplainer.Difference_REMOVED
+			difference.OldValue = oldArguments[i]
+		} else if i >= len(oldArguments) {
+			difference.Type = cachemissexplainer.Difference_ADDED
+			difference.NewValue = newArguments[i]
+		} else if oldArguments[i] != newArguments[i] {
+			difference.Type = cachemissexplainer.Difference_MODIFIED
+			difference.OldValue = oldArguments[i]
+			difference.NewValue = newArguments[i]
+		} else {
+			continue
+		}
+		differences = append(differences, difference)
+	}
+
+	differences = append(
+		differences,
+		diffKeyValuePairs(
+			"environment_variables",
+			environmentVariablesToKeyValuePairs(oldFingerprint.EnvironmentVariables),
+			environmentVariablesToKeyValuePairs(newFingerprint.EnvironmentVariables))...)
+	differences = append(
+		differences,
+		diffKeyValuePairs(
+			"platform_properties",
+			platformPropertiesToKeyValuePairs(oldFingerprint.PlatformProperties),
+			platformPropertiesToKeyValuePairs(newFingerprint.PlatformProperties))...)
+
+	if oldFingerprint.WorkingDirectory != newFingerprint.WorkingDirectory {
+		differences = append(differences, &cachemissexplainer.Difference{
+			Type:     cachemissexplainer.Difference_MODIFIED,
+			Field:    "working_directory",
+			OldValue: oldFingerprint.WorkingDirectory,
+			NewValue: newFingerprint.WorkingDirectory,
+		})
+	}
+
+	differences = append(
+		differences,
+		diffKeyValuePairs(
+			"output_paths",
+			outputPathsToKeyValuePairs(oldFingerprint.OutputPaths),
+			outputPathsToKeyValuePairs(newFingerprint.OutputPaths))...)
+	return differences
+}
@@ -0,0 +1,106 @@
+package cachemissexplainer_test
+
+import (
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/cachemissexplainer"
+	cachemissexplainer_pb "github.com/buildbarn/bb-remote-execution/pkg/proto/cachemissexplainer"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+)
+
+func requireEqualDifferences(t *testing.T, expected, actual []*cachemissexplainer_pb.Difference) {
+	testutil.RequireEqualProto(
+		t,
+		&cachemissexplainer_pb.ExplainCacheMissResponse{Differences: expected},
+		&cachemissexplainer_pb.ExplainCacheMissResponse{Differences: actual})
+}
+
+func TestDiffActionFingerprints(t *testing.T) {
+	oldFingerprint := &cachemissexplainer_pb.ActionFingerprint{
+		Arguments: []string{"cc", "-c", "-O2", "hello.c"},
+		EnvironmentVariables: []*remoteexecution.Command_EnvironmentVariable{
+			{Name: "LANG", Value: "C"},
+			{Name: "PATH", Value: "/bin:/usr/bin"},
+		},
+		PlatformProperties: []*remoteexecution.Platform_Property{
+			{Name: "OSFamily", Value: "linux"},
+			{Name: "container-image", Value: "docker://ubuntu:22.04"},
+		},
+		OutputPaths:     []string{"hello.o"},
+		InputRootDigest: &remoteexecution.Digest{Hash: "4cd7a2f3ba8e8d0f3c0c6f4ab9de14fe3b0a11e46fc0bb0e5ab2baf1a7ef1e12", SizeBytes: 200},
+	}
+
+	t.Run("Identical", func(t *testing.T) {
+		requireEqualDifferences(t, nil, cachemissexplainer.DiffActionFingerprints(oldFingerprint, oldFingerprint))
+	})
+
+	t.Run("Everything", func(t *testing.T) {
+		newFingerprint := &cachemissexplainer_pb.ActionFingerprint{
+			Arguments: []string{"cc", "-c", "-O3", "hello.c", "-g"},
+			EnvironmentVariables: []*remoteexecution.Command_EnvironmentVariable{
+				{Name: "PATH", Value: "/usr/local/bin:/bin:/usr/bin"},
+				{Name: "TMPDIR", Value: "/tmp"},
+			},
+			PlatformProperties: []*remoteexecution.Platform_Property{
+				{Name: "OSFamily", Value: "linux"},
+				{Name: "container-image", Value: "docker://ubuntu:24.04"},
+			},
+			WorkingDirectory: "src",
+			OutputPaths:      []string{"hello.d", "hello.o"},
+			InputRootDigest:  &remoteexecution.Digest{Hash: "0c2bb0a1b0e8e5c3e9f9b7b7a3e6c1d1f6f2e1b0a2c6d7e8f9a0b1c2d3e4f5a6", SizeBytes: 210},
+		}
+
+		requireEqualDifferences(t, []*cachemissexplainer_pb.Difference{
+			{
+				Type:     cachemissexplainer_pb.Difference_MODIFIED,
+				Field:    "arguments",
+				Key:      "2",
+				OldValue: "-O2",
+				NewValue: "-O3",
+			},
+			{
+				Type:     cachemissexplainer_pb.Difference_ADDED,
+				Field:    "arguments",
+				Key:      "4",
+				NewValue: "-g",
+			},
+			{
+				Type:     cachemissexplainer_pb.Difference_REMOVED,
+				Field:    "environment_variables",
+				Key:      "LANG",
+				OldValue: "C",
+			},
+			{
+				Type:     cachemissexplainer_pb.Difference_MODIFIED,
+				Field:    "environment_variables",
+				Key:      "PATH",
+				OldValue: "/bin:/usr/bin",
+				NewValue: "/usr/local/bin:/bin:/usr/bin",
+			},
+			{
+				Type:     cachemissexplainer_pb.Difference_ADDED,
+				Field:    "environment_variables",
+				Key:      "TMPDIR",
+				NewValue: "/tmp",
+			},
+			{
+				Type:     cachemissexplainer_pb.Difference_MODIFIED,
+				Field:    "platform_properties",
+				Key:      "container-image",
+				OldValue: "docker://ubuntu:22.04",
+				NewValue: "docker://ubuntu:24.04",
+			},
+			{
+				Type:     cachemissexplainer_pb.Difference_MODIFIED,
+				Field:    "working_directory",
+				NewValue: "src",
+			},
+			{
+				Type:  cachemissexplainer_pb.Difference_ADDED,
+				Field: "output_paths",
+				Key:   "hello.d",
+			},
+		}, cachemissexplainer.DiffActionFingerprints(oldFingerprint, newFingerprint))
+	})
+}
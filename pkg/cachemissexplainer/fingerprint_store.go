@@ -0,0 +1,136 @@
+package cachemissexplainer
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/cachemissexplainer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/eviction"
+)
+
+// similarityKey is used by FingerprintStore to group actions that are
+// likely different versions of the same build step. Actions are
+// considered to be similar if they share the same instance name,
+// digest function and output paths.
+type similarityKey string
+
+func newSimilarityKey(actionDigest digest.Digest, fingerprint *cachemissexplainer.ActionFingerprint) similarityKey {
+	digestFunction := actionDigest.GetDigestFunction()
+	return similarityKey(strings.Join(
+		append(
+			[]string{
+				digestFunction.GetInstanceName().String(),
+				digestFunction.GetEnumValue().String(),
+			},
+			fingerprint.OutputPaths...),
+		"\x00"))
+}
+
+// FingerprintStore is an in-memory store of action fingerprints. In
+// addition to looking up fingerprints by action digest, it can be used
+// to look up the most recently stored fingerprint of an action that is
+// similar to a given action.
+type FingerprintStore struct {
+	maximumSize int
+
+	lock         sync.Mutex
+	fingerprints map[digest.Digest]*cachemissexplainer.ActionFingerprint
+	evictionSet  eviction.Set[digest.Digest]
+	// For each similarity key, the digests of the two most
+	// recently stored distinct actions, the most recent first.
+	// Two are tracked, so that an action can be compared against
+	// its predecessor after it has been stored itself.
+	mostRecent map[similarityKey][2]digest.Digest
+}
+
+// NewFingerprintStore creates a FingerprintStore that is empty. At
+// most maximumSize fingerprints are retained, using the provided
+// eviction set to determine which fingerprints to discard.
+func NewFingerprintStore(maximumSize int, evictionSet eviction.Set[digest.Digest]) *FingerprintStore {
+	return &FingerprintStore{
+		maximumSize:  maximumSize,
+		fingerprints: map[digest.Digest]*cachemissexplainer.ActionFingerprint{},
+		evictionSet:  evictionSet,
+		mostRecent:   map[similarityKey][2]digest.Digest{},
+	}
+}
+
+// Put a fingerprint of an action into the store.
+func (fs *FingerprintStore) Put(actionDigest digest.Digest, fingerprint *cachemissexplainer.ActionFingerprint) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	if _, ok := fs.fingerprints[actionDigest]; ok {
+		fs.evictionSet.Touch(actionDigest)
+	} else {
+		for len(fs.fingerprints) >= fs.maximumSize && len(fs.fingerprints) > 0 {
+			fs.removeLocked(fs.evictionSet.Peek())
+			fs.evictionSet.Remove()
+		}
+		fs.evictionSet.Insert(actionDigest)
+	}
+	fs.fingerprints[actionDigest] = fingerprint
+
+	key := newSimilarityKey(actionDigest, fingerprint)
+	if mostRecent, ok := fs.mostRecent[key]; !ok {
+		fs.mostRecent[key] = [2]digest.Digest{actionDigest, digest.BadDigest}
+	} else if mostRecent[0] != actionDigest {
+		fs.mostRecent[key] = [2]digest.Digest{actionDigest, mostRecent[0]}
+	}
+}
+
+// removeLocked removes a fingerprint from the store, and any
+// references to it that are used to find similar actions.
+func (fs *FingerprintStore) removeLocked(actionDigest digest.Digest) {
+	fingerprint := fs.fingerprints[actionDigest]
+	delete(fs.fingerprints, actionDigest)
+
+	key := newSimilarityKey(actionDigest, fingerprint)
+	if mostRecent, ok := fs.mostRecent[key]; ok {
+		if mostRecent[0] == actionDigest {
+			mostRecent[0], mostRecent[1] = mostRecent[1], digest.BadDigest
+		} else if mostRecent[1] == actionDigest {
+			mostRecent[1] = digest.BadDigest
+		}
+		if mostRecent[0] == digest.BadDigest {
+			delete(fs.mostRecent, key)
+		} else {
+			fs.mostRecent[key] = mostRecent
+		}
+	}
+}
+
+// Get the fingerprint of an action from the store.
+func (fs *FingerprintStore) Get(actionDigest digest.Digest) (*cachemissexplainer.ActionFingerprint, bool) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	fingerprint, ok := fs.fingerprints[actionDigest]
+	if ok {
+		fs.evictionSet.Touch(actionDigest)
+	}
+	return fingerprint, ok
+}
+
+// GetMostRecentSimilar returns the digest and fingerprint of the most
+// recently stored action that is similar to the provided action,
+// excluding the action itself.
+func (fs *FingerprintStore) GetMostRecentSimilar(actionDigest digest.Digest, fingerprint *cachemissexplainer.ActionFingerprint) (digest.Digest, *cachemissexplainer.ActionFingerprint, bool) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	mostRecent, ok := fs.mostRecent[newSimilarityKey(actionDigest, fingerprint)]
+	if !ok {
+		return digest.BadDigest, nil, false
+	}
+	similarDigest := mostRecent[0]
+	if similarDigest == actionDigest {
+		similarDigest = mostRecent[1]
+	}
+	if similarDigest == digest.BadDigest {
+		return digest.BadDigest, nil, false
+	}
+	fs.evictionSet.Touch(similarDigest)
+	return similarDigest, fs.fingerprints[similarDigest], true
+}
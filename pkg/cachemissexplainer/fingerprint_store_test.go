@@ -0,0 +1,70 @@
+package cachemissexplainer_test
+
+import (
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/cachemissexplainer"
+	cachemissexplainer_pb "github.com/buildbarn/bb-remote-execution/pkg/proto/cachemissexplainer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/eviction"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprintStore(t *testing.T) {
+	fingerprintStore := cachemissexplainer.NewFingerprintStore(2, eviction.NewLRUSet[digest.Digest]())
+
+	digest1 := digest.MustNewDigest("example", remoteexecution.DigestFunction_SHA256, "2c7f7fc2bc9ffd1a2e4b8ebff5ea4d5e0e5e0f0b1c4b7b0f5b2a4e8b4d4c2c1a", 123)
+	digest2 := digest.MustNewDigest("example", remoteexecution.DigestFunction_SHA256, "6b0ba1b1f25eec0aeab8f0a2e3c9d9bc4b9d2fa8e5f4a1e02e4c10b7b6f1d0a6", 123)
+	digest3 := digest.MustNewDigest("example", remoteexecution.DigestFunction_SHA256, "8d1d8b1c9e7b0e1c9a6d8a7e7c1b4e2f3a9d0b6c5e4f3a2b1c0d9e8f7a6b5c4d", 123)
+	fingerprint := func(outputPath string) *cachemissexplainer_pb.ActionFingerprint {
+		return &cachemissexplainer_pb.ActionFingerprint{OutputPaths: []string{outputPath}}
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		_, ok := fingerprintStore.Get(digest1)
+		require.False(t, ok)
+		_, _, ok = fingerprintStore.GetMostRecentSimilar(digest1, fingerprint("hello.o"))
+		require.False(t, ok)
+	})
+
+	t.Run("Similar", func(t *testing.T) {
+		fingerprintStore.Put(digest1, fingerprint("hello.o"))
+
+		// An action should not be considered to be similar
+		// to itself.
+		_, _, ok := fingerprintStore.GetMostRecentSimilar(digest1, fingerprint("hello.o"))
+		require.False(t, ok)
+
+		// Actions with different output paths should not be
+		// considered to be similar.
+		_, _, ok = fingerprintStore.GetMostRecentSimilar(digest2, fingerprint("goodbye.o"))
+		require.False(t, ok)
+
+		// After storing a second action with the same output
+		// paths, both actions should be similar to each other.
+		fingerprintStore.Put(digest2, fingerprint("hello.o"))
+		similarDigest, _, ok := fingerprintStore.GetMostRecentSimilar(digest2, fingerprint("hello.o"))
+		require.True(t, ok)
+		require.Equal(t, digest1, similarDigest)
+		similarDigest, _, ok = fingerprintStore.GetMostRecentSimilar(digest1, fingerprint("hello.o"))
+		require.True(t, ok)
+		require.Equal(t, digest2, similarDigest)
+	})
+
+	t.Run("Eviction", func(t *testing.T) {
+		// Storing a third action should cause the least
+		// recently used action to be evicted. References to
+		// it should be removed.
+		fingerprintStore.Put(digest3, fingerprint("hello.o"))
+		_, ok := fingerprintStore.Get(digest1)
+		require.False(t, ok)
+
+		similarDigest, _, ok := fingerprintStore.GetMostRecentSimilar(digest3, fingerprint("hello.o"))
+		require.True(t, ok)
+		require.Equal(t, digest2, similarDigest)
+		similarDigest, _, ok = fingerprintStore.GetMostRecentSimilar(digest2, fingerprint("hello.o"))
+		require.True(t, ok)
+		require.Equal(t, digest3, similarDigest)
+	})
+}
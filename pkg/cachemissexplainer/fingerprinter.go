@@ -0,0 +1,90 @@
+package cachemissexplainer
+
+import (
+	"context"
+	"sort"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/cachemissexplainer"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+)
+
+// Fingerprinter is responsible for computing the fingerprint of an
+// action, which summarizes the parts of the action that contribute to
+// its digest.
+type Fingerprinter interface {
+	GetActionFingerprint(ctx context.Context, actionDigest digest.Digest) (*cachemissexplainer.ActionFingerprint, error)
+}
+
+type blobAccessFingerprinter struct {
+	contentAddressableStorage blobstore.BlobAccess
+	maximumMessageSizeBytes   int
+}
+
+// NewBlobAccessFingerprinter creates a Fingerprinter that computes
+// fingerprints by loading Action and Command messages from the Content
+// Addressable Storage. The input root is not traversed, as only its
+// digest is part of the fingerprint.
+func NewBlobAccessFingerprinter(contentAddressableStorage blobstore.BlobAccess, maximumMessageSizeBytes int) Fingerprinter {
+	return &blobAccessFingerprinter{
+		contentAddressableStorage: contentAddressableStorage,
+		maximumMessageSizeBytes:   maximumMessageSizeBytes,
+	}
+}
+
+func (f *blobAccessFingerprinter) GetActionFingerprint(ctx context.Context, actionDigest digest.Digest) (*cachemissexplainer.ActionFingerprint, error) {
+	actionMessage, err := f.contentAddressableStorage.Get(ctx, actionDigest).ToProto(&remoteexecution.Action{}, f.maximumMessageSizeBytes)
+	if err != nil {
+		return nil, util.StatusWrap(err, "Failed to obtain action")
+	}
+	action := actionMessage.(*remoteexecution.Action)
+
+	digestFunction := actionDigest.GetDigestFunction()
+	commandDigest, err := digestFunction.NewDigestFromProto(action.CommandDigest)
+	if err != nil {
+		return nil, util.StatusWrap(err, "Invalid command digest")
+	}
+	commandMessage, err := f.contentAddressableStorage.Get(ctx, commandDigest).ToProto(&remoteexecution.Command{}, f.maximumMessageSizeBytes)
+	if err != nil {
+		return nil, util.StatusWrap(err, "Failed to obtain command")
+	}
+	command := commandMessage.(*remoteexecution.Command)
+
+	fingerprint := &cachemissexplainer.ActionFingerprint{
+		ActionDigest:     actionDigest.GetProto(),
+		Arguments:        command.Arguments,
+		WorkingDirectory: command.WorkingDirectory,
+		InputRootDigest:  action.InputRootDigest,
+	}
+
+	fingerprint.EnvironmentVariables = append(fingerprint.EnvironmentVariables, command.EnvironmentVariables...)
+	sort.SliceStable(fingerprint.EnvironmentVariables, func(i, j int) bool {
+		return fingerprint.EnvironmentVariables[i].Name < fingerprint.EnvironmentVariables[j].Name
+	})
+
+	// Platform properties may either be stored in the Action or
+	// the Command message. The former takes precedence.
+	platform := action.Platform
+	if platform == nil {
+		platform = command.Platform
+	}
+	fingerprint.PlatformProperties = append(fingerprint.PlatformProperties, platform.GetProperties()...)
+	sort.SliceStable(fingerprint.PlatformProperties, func(i, j int) bool {
+		return fingerprint.PlatformProperties[i].Name < fingerprint.PlatformProperties[j].Name
+	})
+
+	// Output paths may either be specified using the output_paths
+	// field, or the deprecated output_files and output_directories
+	// fields.
+	if len(command.OutputPaths) > 0 {
+		fingerprint.OutputPaths = append(fingerprint.OutputPaths, command.OutputPaths...)
+	} else {
+		fingerprint.OutputPaths = append(fingerprint.OutputPaths, command.OutputFiles...)
+		fingerprint.OutputPaths = append(fingerprint.OutputPaths, command.OutputDirectories...)
+	}
+	sort.Strings(fingerprint.OutputPaths)
+
+	return fingerprint, nil
+}
@@ -0,0 +1,177 @@
+package cachemissexplainer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/cas"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/cachemissexplainer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+)
+
+// inputRootDiffer is used by DiffInputRoots to keep track of the
+// differences between two input roots that have been found so far.
+type inputRootDiffer struct {
+	directoryFetcher   cas.DirectoryFetcher
+	digestFunction     digest.Function
+	maximumDifferences int
+
+	differences []*cachemissexplainer.Difference
+	truncated   bool
+}
+
+// DiffInputRoots computes the differences between the regular files
+// and symbolic links contained in two input roots. Subdirectories that
+// have the same digest in both input roots are skipped, meaning that
+// only the parts of the input roots that differ are loaded.
+//
+// At most maximumDifferences differences in input files are reported.
+// The boolean return value indicates whether differences were omitted.
+// If the input roots differ, but no differences in input files are
+// found (e.g., due to empty directories being added), a difference of
+// field "input_root_digest" is reported.
+func DiffInputRoots(ctx context.Context, directoryFetcher cas.DirectoryFetcher, oldInputRootDigest, newInputRootDigest digest.Digest, maximumDifferences int) ([]*cachemissexplainer.Difference, bool, error) {
+	if oldInputRootDigest == newInputRootDigest {
+		return nil, false, nil
+	}
+	d := inputRootDiffer{
+		directoryFetcher:   directoryFetcher,
+		digestFunction:     newInputRootDigest.GetDigestFunction(),
+		maximumDifferences: maximumDifferences,
+	}
+	if err := d.diffDirectories(ctx, oldInputRootDigest, newInputRootDigest, ""); err != nil {
+		return nil, false, err
+	}
+	if len(d.differences) == 0 && !d.truncated {
+		return []*cachemissexplainer.Difference{{
+			Type:     cachemissexplainer.Difference_MODIFIED,
+			Field:    "input_root_digest",
+			OldValue: formatDigest(oldInputRootDigest.GetProto()),
+			NewValue: formatDigest(newInputRootDigest.GetProto()),
+		}}, false, nil
+	}
+	return d.differences, d.truncated, nil
+}
+
+// getDirectory loads a directory from the Content Addressable Storage.
+// If the directory is only present in one of the input roots, the
+// provided digest is digest.BadDigest, in which case the directory is
+// treated as if it were empty.
+func (d *inputRootDiffer) getDirectory(ctx context.Context, directoryDigest digest.Digest, pathPrefix string) (*remoteexecution.Directory, error) {
+	if directoryDigest == digest.BadDigest {
+		return &remoteexecution.Directory{}, nil
+	}
+	directory, err := d.directoryFetcher.GetDirectory(ctx, directoryDigest)
+	if err != nil {
+		return nil, util.StatusWrapf(err, "Failed to obtain input directory %#v", pathPrefix)
+	}
+	return directory, nil
+}
+
+func (d *inputRootDiffer) getSubdirectoryDigests(directory *remoteexecution.Directory, pathPrefix string) (map[string]digest.Digest, error) {
+	subdirectoryDigests := make(map[string]digest.Digest, len(directory.Directories))
+	for _, subdirectory := range directory.Directories {
+		childDigest, err := d.digestFunction.NewDigestFromProto(subdirectory.Digest)
+		if err != nil {
+			return nil, util.StatusWrapf(err, "Invalid digest for input directory %#v", pathPrefix+subdirectory.Name)
+		}
+		subdirectoryDigests[subdirectory.Name] = childDigest
+	}
+	return subdirectoryDigests, nil
+}
+
+// diffDirectories compares the contents of two directories, and
+// recurses into subdirectories whose digests differ.
+func (d *inputRootDiffer) diffDirectories(ctx context.Context, oldDirectoryDigest, newDirectoryDigest digest.Digest, pathPrefix string) error {
+	oldDirectory, err := d.getDirectory(ctx, oldDirectoryDigest, pathPrefix)
+	if err != nil {
+		return err
+	}
+	newDirectory, err := d.getDirectory(ctx, newDirectoryDigest, pathPrefix)
+	if err != nil {
+		return err
+	}
+
+	for _, difference := range diffKeyValuePairs(
+		"input_files",
+		directoryLeavesToKeyValuePairs(oldDirectory, pathPrefix),
+		directoryLeavesToKeyValuePairs(newDirectory, pathPrefix),
+	) {
+		if len(d.differences) >= d.maximumDifferences {
+			d.truncated = true
+			return nil
+		}
+		d.differences = append(d.differences, difference)
+	}
+
+	oldSubdirectoryDigests, err := d.getSubdirectoryDigests(oldDirectory, pathPrefix)
+	if err != nil {
+		return err
+	}
+	newSubdirectoryDigests, err := d.getSubdirectoryDigests(newDirectory, pathPrefix)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(oldSubdirectoryDigests)+len(newSubdirectoryDigests))
+	for name := range oldSubdirectoryDigests {
+		names = append(names, name)
+	}
+	for name := range newSubdirectoryDigests {
+		if _, ok := oldSubdirectoryDigests[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		oldChildDigest, ok := oldSubdirectoryDigests[name]
+		if !ok {
+			oldChildDigest = digest.BadDigest
+		}
+		newChildDigest, ok := newSubdirectoryDigests[name]
+		if !ok {
+			newChildDigest = digest.BadDigest
+		}
+		if oldChildDigest == newChildDigest {
+			continue
+		}
+		if err := d.diffDirectories(ctx, oldChildDigest, newChildDigest, pathPrefix+name+"/"); err != nil {
+			return err
+		}
+		if d.truncated {
+			return nil
+		}
+	}
+	return nil
+}
+
+// directoryLeavesToKeyValuePairs converts the regular files and
+// symbolic links contained in a directory to a list of key-value
+// pairs, sorted by path.
+func directoryLeavesToKeyValuePairs(directory *remoteexecution.Directory, pathPrefix string) []keyValuePair {
+	pairs := make([]keyValuePair, 0, len(directory.Files)+len(directory.Symlinks))
+	for _, file := range directory.Files {
+		value := "file " + formatDigest(file.Digest)
+		if file.IsExecutable {
+			value = "executable " + value
+		}
+		pairs = append(pairs, keyValuePair{key: pathPrefix + file.Name, value: value})
+	}
+	for _, symlink := range directory.Symlinks {
+		pairs = append(pairs, keyValuePair{
+			key:   pathPrefix + symlink.Name,
+			value: fmt.Sprintf("symbolic link to %#v", symlink.Target),
+		})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].key < pairs[j].key
+	})
+	return pairs
+}
+
+func formatDigest(d *remoteexecution.Digest) string {
+	return fmt.Sprintf("%s-%d", d.GetHash(), d.GetSizeBytes())
+}
@@ -0,0 +1,168 @@
+package cachemissexplainer_test
+
+import (
+	"context"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/cachemissexplainer"
+	cachemissexplainer_pb "github.com/buildbarn/bb-remote-execution/pkg/proto/cachemissexplainer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffInputRoots(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	directoryFetcher := mock.NewMockDirectoryFetcher(ctrl)
+
+	oldRootDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_SHA256, "4cd7a2f3ba8e8d0f3c0c6f4ab9de14fe3b0a11e46fc0bb0e5ab2baf1a7ef1e12", 200)
+	newRootDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_SHA256, "0c2bb0a1b0e8e5c3e9f9b7b7a3e6c1d1f6f2e1b0a2c6d7e8f9a0b1c2d3e4f5a6", 210)
+	oldLibDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_SHA256, "7a3e6c1d1f6f2e1b0a2c6d7e8f9a0b1c2d3e4f5a60c2bb0a1b0e8e5c3e9f9b7b", 80)
+	newLibDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_SHA256, "b0a2c6d7e8f9a0b1c2d3e4f5a60c2bb0a1b0e8e5c3e9f9b7b7a3e6c1d1f6f2e1", 80)
+	newDirectoryDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_SHA256, "e8f9a0b1c2d3e4f5a60c2bb0a1b0e8e5c3e9f9b7b7a3e6c1d1f6f2e1b0a2c6d7", 80)
+	unchangedDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_SHA256, "3e4f5a60c2bb0a1b0e8e5c3e9f9b7b7a3e6c1d1f6f2e1b0a2c6d7e8f9a0b1c2d", 80)
+
+	helloC := &remoteexecution.FileNode{
+		Name:   "hello.c",
+		Digest: &remoteexecution.Digest{Hash: "c0fc5270fee3fb4f0f2b0d9e06ed4ba5b3b2c1763c69b7e4f7e6f2a7a1a6e0d1", SizeBytes: 100},
+	}
+	unchanged := &remoteexecution.DirectoryNode{
+		Name:   "unchanged",
+		Digest: unchangedDigest.GetProto(),
+	}
+	expectRoots := func() {
+		directoryFetcher.EXPECT().GetDirectory(ctx, oldRootDigest).Return(&remoteexecution.Directory{
+			Files: []*remoteexecution.FileNode{
+				helloC,
+				{
+					Name:   "hello.h",
+					Digest: &remoteexecution.Digest{Hash: "1e3dd2ed4dca3f4dfc1d0a1a62cf8cca0ab5ac1e0d4e06f38a1d3cdd5c8d3c5d", SizeBytes: 50},
+				},
+			},
+			Directories: []*remoteexecution.DirectoryNode{
+				{Name: "lib", Digest: oldLibDigest.GetProto()},
+				unchanged,
+			},
+		}, nil)
+		directoryFetcher.EXPECT().GetDirectory(ctx, newRootDigest).Return(&remoteexecution.Directory{
+			Files: []*remoteexecution.FileNode{helloC},
+			Directories: []*remoteexecution.DirectoryNode{
+				{Name: "lib", Digest: newLibDigest.GetProto()},
+				{Name: "new", Digest: newDirectoryDigest.GetProto()},
+				unchanged,
+			},
+			Symlinks: []*remoteexecution.SymlinkNode{
+				{Name: "hello.h", Target: "include/hello.h"},
+			},
+		}, nil)
+		directoryFetcher.EXPECT().GetDirectory(ctx, oldLibDigest).Return(&remoteexecution.Directory{
+			Files: []*remoteexecution.FileNode{
+				{
+					Name:   "a.c",
+					Digest: &remoteexecution.Digest{Hash: "8e5c3e9f9b7b7a3e6c1d1f6f2e1b0a2c6d7e8f9a0b1c2d3e4f5a60c2bb0a1b0e", SizeBytes: 10},
+				},
+			},
+		}, nil)
+		directoryFetcher.EXPECT().GetDirectory(ctx, newLibDigest).Return(&remoteexecution.Directory{
+			Files: []*remoteexecution.FileNode{
+				{
+					Name:         "a.c",
+					Digest:       &remoteexecution.Digest{Hash: "8e5c3e9f9b7b7a3e6c1d1f6f2e1b0a2c6d7e8f9a0b1c2d3e4f5a60c2bb0a1b0e", SizeBytes: 10},
+					IsExecutable: true,
+				},
+			},
+		}, nil)
+	}
+
+	t.Run("Identical", func(t *testing.T) {
+		// Identical input roots should not need to be loaded.
+		differences, truncated, err := cachemissexplainer.DiffInputRoots(ctx, directoryFetcher, oldRootDigest, oldRootDigest, 100)
+		require.NoError(t, err)
+		require.False(t, truncated)
+		requireEqualDifferences(t, nil, differences)
+	})
+
+	t.Run("Differences", func(t *testing.T) {
+		// Subdirectories having the same digest in both input
+		// roots should not be loaded.
+		expectRoots()
+		directoryFetcher.EXPECT().GetDirectory(ctx, newDirectoryDigest).Return(&remoteexecution.Directory{
+			Symlinks: []*remoteexecution.SymlinkNode{
+				{Name: "b", Target: "../hello.c"},
+			},
+		}, nil)
+
+		differences, truncated, err := cachemissexplainer.DiffInputRoots(ctx, directoryFetcher, oldRootDigest, newRootDigest, 100)
+		require.NoError(t, err)
+		require.False(t, truncated)
+		requireEqualDifferences(t, []*cachemissexplainer_pb.Difference{
+			{
+				Type:     cachemissexplainer_pb.Difference_MODIFIED,
+				Field:    "input_files",
+				Key:      "hello.h",
+				OldValue: "file 1e3dd2ed4dca3f4dfc1d0a1a62cf8cca0ab5ac1e0d4e06f38a1d3cdd5c8d3c5d-50",
+				NewValue: "symbolic link to \"include/hello.h\"",
+			},
+			{
+				Type:     cachemissexplainer_pb.Difference_MODIFIED,
+				Field:    "input_files",
+				Key:      "lib/a.c",
+				OldValue: "file 8e5c3e9f9b7b7a3e6c1d1f6f2e1b0a2c6d7e8f9a0b1c2d3e4f5a60c2bb0a1b0e-10",
+				NewValue: "executable file 8e5c3e9f9b7b7a3e6c1d1f6f2e1b0a2c6d7e8f9a0b1c2d3e4f5a60c2bb0a1b0e-10",
+			},
+			{
+				Type:     cachemissexplainer_pb.Difference_ADDED,
+				Field:    "input_files",
+				Key:      "new/b",
+				NewValue: "symbolic link to \"../hello.c\"",
+			},
+		}, differences)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		// Traversal should stop as soon as the maximum number
+		// of differences is exceeded.
+		expectRoots()
+
+		differences, truncated, err := cachemissexplainer.DiffInputRoots(ctx, directoryFetcher, oldRootDigest, newRootDigest, 1)
+		require.NoError(t, err)
+		require.True(t, truncated)
+		requireEqualDifferences(t, []*cachemissexplainer_pb.Difference{
+			{
+				Type:     cachemissexplainer_pb.Difference_MODIFIED,
+				Field:    "input_files",
+				Key:      "hello.h",
+				OldValue: "file 1e3dd2ed4dca3f4dfc1d0a1a62cf8cca0ab5ac1e0d4e06f38a1d3cdd5c8d3c5d-50",
+				NewValue: "symbolic link to \"include/hello.h\"",
+			},
+		}, differences)
+	})
+
+	t.Run("InputRootDigestOnly", func(t *testing.T) {
+		// If the input roots differ, but no differences in
+		// input files can be found, the input root digests
+		// should be reported.
+		directoryFetcher.EXPECT().GetDirectory(ctx, oldRootDigest).Return(&remoteexecution.Directory{}, nil)
+		directoryFetcher.EXPECT().GetDirectory(ctx, newRootDigest).Return(&remoteexecution.Directory{
+			Directories: []*remoteexecution.DirectoryNode{
+				{Name: "empty", Digest: newDirectoryDigest.GetProto()},
+			},
+		}, nil)
+		directoryFetcher.EXPECT().GetDirectory(ctx, newDirectoryDigest).Return(&remoteexecution.Directory{}, nil)
+
+		differences, truncated, err := cachemissexplainer.DiffInputRoots(ctx, directoryFetcher, oldRootDigest, newRootDigest, 100)
+		require.NoError(t, err)
+		require.False(t, truncated)
+		requireEqualDifferences(t, []*cachemissexplainer_pb.Difference{
+			{
+				Type:     cachemissexplainer_pb.Difference_MODIFIED,
+				Field:    "input_root_digest",
+				OldValue: "4cd7a2f3ba8e8d0f3c0c6f4ab9de14fe3b0a11e46fc0bb0e5ab2baf1a7ef1e12-200",
+				NewValue: "0c2bb0a1b0e8e5c3e9f9b7b7a3e6c1d1f6f2e1b0a2c6d7e8f9a0b1c2d3e4f5a6-210",
+			},
+		}, differences)
+	})
+}
@@ -0,0 +1,178 @@
+package cachemissexplainer
+
+import (
+	"context"
+	"io"
+	"log"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/cas"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/cachemissexplainer"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/completedactionlogger"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"golang.org/x/sync/semaphore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Server implements both the CompletedActionLogger and the
+// CacheMissExplainer gRPC services. Fingerprints are recorded for all
+// actions that are received through the CompletedActionLogger service.
+// These fingerprints are subsequently used to explain why actions were
+// executed.
+type Server struct {
+	fingerprinter               Fingerprinter
+	fingerprintStore            *FingerprintStore
+	fingerprintingSemaphore     *semaphore.Weighted
+	directoryFetcher            cas.DirectoryFetcher
+	maximumInputFileDifferences int
+}
+
+var (
+	_ completedactionlogger.CompletedActionLoggerServer = (*Server)(nil)
+	_ cachemissexplainer.CacheMissExplainerServer       = (*Server)(nil)
+)
+
+// NewServer creates a Server that stores fingerprints computed by the
+// provided Fingerprinter in a FingerprintStore. The semaphore limits
+// the number of completed actions for which fingerprints are computed
+// concurrently. The DirectoryFetcher is used to compare input roots
+// when explaining cache misses.
+func NewServer(fingerprinter Fingerprinter, fingerprintStore *FingerprintStore, fingerprintingSemaphore *semaphore.Weighted, directoryFetcher cas.DirectoryFetcher, maximumInputFileDifferences int) *Server {
+	return &Server{
+		fingerprinter:               fingerprinter,
+		fingerprintStore:            fingerprintStore,
+		fingerprintingSemaphore:     fingerprintingSemaphore,
+		directoryFetcher:            directoryFetcher,
+		maximumInputFileDifferences: maximumInputFileDifferences,
+	}
+}
+
+// getFingerprint returns the fingerprint of an action, either by
+// obtaining it from the store, or by computing it.
+func (s *Server) getFingerprint(ctx context.Context, actionDigest digest.Digest) (*cachemissexplainer.ActionFingerprint, error) {
+	if fingerprint, ok := s.fingerprintStore.Get(actionDigest); ok {
+		return fingerprint, nil
+	}
+	fingerprint, err := s.fingerprinter.GetActionFingerprint(ctx, actionDigest)
+	if err != nil {
+		return nil, err
+	}
+	s.fingerprintStore.Put(actionDigest, fingerprint)
+	return fingerprint, nil
+}
+
+// LogCompletedActions records the fingerprints of actions that are
+// streamed by workers. Fingerprints are computed in the background, so
+// that workers are not slowed down by reads against the Content
+// Addressable Storage, unless the concurrency limit is reached.
+func (s *Server) LogCompletedActions(stream completedactionlogger.CompletedActionLogger_LogCompletedActionsServer) error {
+	ctx := stream.Context()
+	fingerprintingCtx := context.WithoutCancel(ctx)
+	for {
+		completedAction, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		// Failing to compute a fingerprint should not cause
+		// workers to retransmit the completed action, as
+		// the failure is likely to be persistent.
+		if err := s.fingerprintingSemaphore.Acquire(ctx, 1); err != nil {
+			return err
+		}
+		go func() {
+			if err := s.recordCompletedAction(fingerprintingCtx, completedAction); err != nil {
+				log.Printf("Failed to record completed action %#v: %s", completedAction.Uuid, err)
+			}
+			s.fingerprintingSemaphore.Release(1)
+		}()
+		if err := stream.Send(&emptypb.Empty{}); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) recordCompletedAction(ctx context.Context, completedAction *completedactionlogger.CompletedAction) error {
+	instanceName, err := digest.NewInstanceName(completedAction.InstanceName)
+	if err != nil {
+		return util.StatusWrapf(err, "Invalid instance name %#v", completedAction.InstanceName)
+	}
+	actionDigestMessage := completedAction.HistoricalExecuteResponse.GetActionDigest()
+	digestFunction, err := instanceName.GetDigestFunction(completedAction.DigestFunction, len(actionDigestMessage.GetHash()))
+	if err != nil {
+		return err
+	}
+	actionDigest, err := digestFunction.NewDigestFromProto(actionDigestMessage)
+	if err != nil {
+		return util.StatusWrap(err, "Invalid action digest")
+	}
+	_, err = s.getFingerprint(ctx, actionDigest)
+	return err
+}
+
+// ExplainCacheMiss compares the fingerprints of two actions. If the
+// digest of the old action is not provided, the most recently recorded
+// action that is similar to the new action is used.
+func (s *Server) ExplainCacheMiss(ctx context.Context, request *cachemissexplainer.ExplainCacheMissRequest) (*cachemissexplainer.ExplainCacheMissResponse, error) {
+	instanceName, err := digest.NewInstanceName(request.InstanceName)
+	if err != nil {
+		return nil, util.StatusWrapf(err, "Invalid instance name %#v", request.InstanceName)
+	}
+	digestFunction, err := instanceName.GetDigestFunction(request.DigestFunction, len(request.NewActionDigest.GetHash()))
+	if err != nil {
+		return nil, err
+	}
+
+	newActionDigest, err := digestFunction.NewDigestFromProto(request.NewActionDigest)
+	if err != nil {
+		return nil, util.StatusWrap(err, "Invalid new action digest")
+	}
+	newFingerprint, err := s.getFingerprint(ctx, newActionDigest)
+	if err != nil {
+		return nil, util.StatusWrap(err, "Failed to obtain fingerprint of new action")
+	}
+
+	var oldActionDigest digest.Digest
+	var oldFingerprint *cachemissexplainer.ActionFingerprint
+	if request.OldActionDigest == nil {
+		var ok bool
+		oldActionDigest, oldFingerprint, ok = s.fingerprintStore.GetMostRecentSimilar(newActionDigest, newFingerprint)
+		if !ok {
+			return nil, status.Error(codes.NotFound, "No action with the same output paths has been recorded")
+		}
+	} else {
+		oldActionDigest, err = digestFunction.NewDigestFromProto(request.OldActionDigest)
+		if err != nil {
+			return nil, util.StatusWrap(err, "Invalid old action digest")
+		}
+		oldFingerprint, err = s.getFingerprint(ctx, oldActionDigest)
+		if err != nil {
+			return nil, util.StatusWrap(err, "Failed to obtain fingerprint of old action")
+		}
+	}
+
+	oldInputRootDigest, err := digestFunction.NewDigestFromProto(oldFingerprint.InputRootDigest)
+	if err != nil {
+		return nil, util.StatusWrap(err, "Invalid input root digest of old action")
+	}
+	newInputRootDigest, err := digestFunction.NewDigestFromProto(newFingerprint.InputRootDigest)
+	if err != nil {
+		return nil, util.StatusWrap(err, "Invalid input root digest of new action")
+	}
+	inputFileDifferences, inputFileDifferencesTruncated, err := DiffInputRoots(ctx, s.directoryFetcher, oldInputRootDigest, newInputRootDigest, s.maximumInputFileDifferences)
+	if err != nil {
+		return nil, util.StatusWrap(err, "Failed to compare input roots")
+	}
+
+	return &cachemissexplainer.ExplainCacheMissResponse{
+		OldActionDigest:               oldActionDigest.GetProto(),
+		Differences:                   append(DiffActionFingerprints(oldFingerprint, newFingerprint), inputFileDifferences...),
+		InputFileDifferencesTruncated: inputFileDifferencesTruncated,
+	}, nil
+}
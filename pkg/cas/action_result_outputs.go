@@ -0,0 +1,188 @@
+package cas
+
+import (
+	"context"
+	"sort"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// OutputEntryKind is the type of a file system object that was
+// created by an action.
+type OutputEntryKind int
+
+const (
+	// OutputEntryKindFile indicates that the output is a regular file.
+	OutputEntryKindFile OutputEntryKind = iota
+	// OutputEntryKindDirectory indicates that the output is a
+	// directory.
+	OutputEntryKindDirectory
+	// OutputEntryKindSymlink indicates that the output is a symbolic
+	// link.
+	OutputEntryKindSymlink
+)
+
+// OutputEntry describes a single file system object that was created
+// by an action, either as an output file, as an output symbolic link,
+// or as part of an output directory.
+type OutputEntry struct {
+	Kind OutputEntryKind
+
+	// Fields that are only set for regular files.
+	Digest       digest.Digest
+	IsExecutable bool
+
+	// Fields that are only set for symbolic links.
+	SymlinkTarget string
+}
+
+// OutputDifference describes a path at which the outputs of two
+// actions differ. Old or New is nil if the path is only present in one
+// of the actions.
+type OutputDifference struct {
+	Path string
+	Old  *OutputEntry
+	New  *OutputEntry
+}
+
+// GetActionResultOutputs converts the outputs stored in an
+// ActionResult message to a flat map, keyed by path. Output directories
+// are expanded by loading the Tree objects referenced by the
+// ActionResult, meaning that every file contained in an output
+// directory is returned individually.
+func GetActionResultOutputs(ctx context.Context, directoryFetcher DirectoryFetcher, digestFunction digest.Function, actionResult *remoteexecution.ActionResult) (map[string]OutputEntry, error) {
+	outputs := map[string]OutputEntry{}
+	addOutput := func(outputPath string, entry OutputEntry) error {
+		if _, ok := outputs[outputPath]; ok {
+			return status.Errorf(codes.InvalidArgument, "Multiple outputs exist at path %#v", outputPath)
+		}
+		outputs[outputPath] = entry
+		return nil
+	}
+
+	for _, entry := range actionResult.OutputFiles {
+		fileDigest, err := digestFunction.NewDigestFromProto(entry.Digest)
+		if err != nil {
+			return nil, util.StatusWrapf(err, "Failed to obtain digest for output file %#v", entry.Path)
+		}
+		if err := addOutput(entry.Path, OutputEntry{
+			Kind:         OutputEntryKindFile,
+			Digest:       fileDigest,
+			IsExecutable: entry.IsExecutable,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range actionResult.OutputSymlinks {
+		if err := addOutput(entry.Path, OutputEntry{
+			Kind:          OutputEntryKindSymlink,
+			SymlinkTarget: entry.Target,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range actionResult.OutputDirectories {
+		treeDigest, err := digestFunction.NewDigestFromProto(entry.TreeDigest)
+		if err != nil {
+			return nil, util.StatusWrapf(err, "Failed to obtain digest for output directory %#v", entry.Path)
+		}
+		if err := addOutputDirectory(ctx, NewTreeDirectoryWalker(directoryFetcher, treeDigest), digestFunction, entry.Path, addOutput); err != nil {
+			return nil, util.StatusWrapf(err, "Output directory %#v", entry.Path)
+		}
+	}
+	return outputs, nil
+}
+
+// addOutputDirectory adds a directory and all of its contents to the
+// flat map of outputs returned by GetActionResultOutputs().
+func addOutputDirectory(ctx context.Context, directoryWalker DirectoryWalker, digestFunction digest.Function, directoryPath string, addOutput func(string, OutputEntry) error) error {
+	if err := addOutput(directoryPath, OutputEntry{Kind: OutputEntryKindDirectory}); err != nil {
+		return err
+	}
+	directory, err := directoryWalker.GetDirectory(ctx)
+	if err != nil {
+		return util.StatusWrap(err, directoryWalker.GetDescription())
+	}
+
+	for _, entry := range directory.Files {
+		fileDigest, err := digestFunction.NewDigestFromProto(entry.Digest)
+		if err != nil {
+			return util.StatusWrapf(err, "%s: Failed to obtain digest for file %#v", directoryWalker.GetDescription(), entry.Name)
+		}
+		if err := addOutput(directoryPath+"/"+entry.Name, OutputEntry{
+			Kind:         OutputEntryKindFile,
+			Digest:       fileDigest,
+			IsExecutable: entry.IsExecutable,
+		}); err != nil {
+			return err
+		}
+	}
+	for _, entry := range directory.Symlinks {
+		if err := addOutput(directoryPath+"/"+entry.Name, OutputEntry{
+			Kind:          OutputEntryKindSymlink,
+			SymlinkTarget: entry.Target,
+		}); err != nil {
+			return err
+		}
+	}
+	for _, entry := range directory.Directories {
+		childDigest, err := digestFunction.NewDigestFromProto(entry.Digest)
+		if err != nil {
+			return util.StatusWrapf(err, "%s: Failed to obtain digest for directory %#v", directoryWalker.GetDescription(), entry.Name)
+		}
+		if err := addOutputDirectory(ctx, directoryWalker.GetChild(childDigest), digestFunction, directoryPath+"/"+entry.Name, addOutput); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DiffActionResultOutputs compares the outputs of two actions, and
+// returns a list of paths at which they differ, sorted by path. This
+// can be used to debug nondeterminism of build actions, or to explain
+// why dependent actions yield cache misses.
+func DiffActionResultOutputs(ctx context.Context, directoryFetcher DirectoryFetcher, digestFunction digest.Function, oldActionResult, newActionResult *remoteexecution.ActionResult) ([]OutputDifference, error) {
+	oldOutputs, err := GetActionResultOutputs(ctx, directoryFetcher, digestFunction, oldActionResult)
+	if err != nil {
+		return nil, util.StatusWrap(err, "Failed to obtain outputs of old action result")
+	}
+	newOutputs, err := GetActionResultOutputs(ctx, directoryFetcher, digestFunction, newActionResult)
+	if err != nil {
+		return nil, util.StatusWrap(err, "Failed to obtain outputs of new action result")
+	}
+
+	var differences []OutputDifference
+	for outputPath, oldEntry := range oldOutputs {
+		oldEntry := oldEntry
+		if newEntry, ok := newOutputs[outputPath]; !ok {
+			differences = append(differences, OutputDifference{
+				Path: outputPath,
+				Old:  &oldEntry,
+			})
+		} else if oldEntry != newEntry {
+			differences = append(differences, OutputDifference{
+				Path: outputPath,
+				Old:  &oldEntry,
+				New:  &newEntry,
+			})
+		}
+	}
+	for outputPath, newEntry := range newOutputs {
+		newEntry := newEntry
+		if _, ok := oldOutputs[outputPath]; !ok {
+			differences = append(differences, OutputDifference{
+				Path: outputPath,
+				New:  &newEntry,
+			})
+		}
+	}
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].Path < differences[j].Path
+	})
+	return differences, nil
+}
@@ -0,0 +1,178 @@
+package cas_test
+
+import (
+	"context"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/cas"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDiffActionResultOutputs(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	directoryFetcher := mock.NewMockDirectoryFetcher(ctrl)
+	digestFunction := digest.MustNewFunction("example", remoteexecution.DigestFunction_MD5)
+
+	t.Run("Identical", func(t *testing.T) {
+		actionResult := &remoteexecution.ActionResult{
+			OutputFiles: []*remoteexecution.OutputFile{
+				{
+					Path: "hello.txt",
+					Digest: &remoteexecution.Digest{
+						Hash:      "8b1a9953c4611296a827abf8c47804d7",
+						SizeBytes: 5,
+					},
+				},
+			},
+		}
+		differences, err := cas.DiffActionResultOutputs(ctx, directoryFetcher, digestFunction, actionResult, actionResult)
+		require.NoError(t, err)
+		require.Empty(t, differences)
+	})
+
+	t.Run("TreeFailure", func(t *testing.T) {
+		actionResult := &remoteexecution.ActionResult{
+			OutputDirectories: []*remoteexecution.OutputDirectory{
+				{
+					Path: "dir",
+					TreeDigest: &remoteexecution.Digest{
+						Hash:      "ed56cd683c99acdff14b77db249819fc",
+						SizeBytes: 234,
+					},
+				},
+			},
+		}
+		directoryFetcher.EXPECT().GetTreeRootDirectory(ctx, digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "ed56cd683c99acdff14b77db249819fc", 234)).
+			Return(nil, status.Error(codes.NotFound, "Object not found"))
+
+		_, err := cas.DiffActionResultOutputs(ctx, directoryFetcher, digestFunction, actionResult, &remoteexecution.ActionResult{})
+		testutil.RequireEqualStatus(t, status.Error(codes.NotFound, "Failed to obtain outputs of old action result: Output directory \"dir\": Tree \"3-ed56cd683c99acdff14b77db249819fc-234-example\" root directory: Object not found"), err)
+	})
+
+	t.Run("Differences", func(t *testing.T) {
+		oldActionResult := &remoteexecution.ActionResult{
+			OutputFiles: []*remoteexecution.OutputFile{
+				{
+					Path: "modified.txt",
+					Digest: &remoteexecution.Digest{
+						Hash:      "8b1a9953c4611296a827abf8c47804d7",
+						SizeBytes: 5,
+					},
+				},
+				{
+					Path: "removed.txt",
+					Digest: &remoteexecution.Digest{
+						Hash:      "d41d8cd98f00b204e9800998ecf8427e",
+						SizeBytes: 0,
+					},
+				},
+			},
+			OutputSymlinks: []*remoteexecution.OutputSymlink{
+				{
+					Path:   "link",
+					Target: "modified.txt",
+				},
+			},
+		}
+		newActionResult := &remoteexecution.ActionResult{
+			OutputFiles: []*remoteexecution.OutputFile{
+				{
+					Path: "modified.txt",
+					Digest: &remoteexecution.Digest{
+						Hash:      "6fc422233a40a75a1f028e11c3cd1140",
+						SizeBytes: 7,
+					},
+				},
+			},
+			OutputSymlinks: []*remoteexecution.OutputSymlink{
+				{
+					Path:   "link",
+					Target: "modified.txt",
+				},
+			},
+			OutputDirectories: []*remoteexecution.OutputDirectory{
+				{
+					Path: "dir",
+					TreeDigest: &remoteexecution.Digest{
+						Hash:      "ed56cd683c99acdff14b77db249819fc",
+						SizeBytes: 234,
+					},
+				},
+			},
+		}
+		treeDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "ed56cd683c99acdff14b77db249819fc", 234)
+		directoryFetcher.EXPECT().GetTreeRootDirectory(ctx, treeDigest).
+			Return(&remoteexecution.Directory{
+				Directories: []*remoteexecution.DirectoryNode{
+					{
+						Name: "subdir",
+						Digest: &remoteexecution.Digest{
+							Hash:      "4df5f448a5e6b3c41e6aae7a8a9832aa",
+							SizeBytes: 456,
+						},
+					},
+				},
+			}, nil)
+		directoryFetcher.EXPECT().GetTreeChildDirectory(ctx, treeDigest, digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "4df5f448a5e6b3c41e6aae7a8a9832aa", 456)).
+			Return(&remoteexecution.Directory{
+				Files: []*remoteexecution.FileNode{
+					{
+						Name: "file",
+						Digest: &remoteexecution.Digest{
+							Hash:      "8b1a9953c4611296a827abf8c47804d7",
+							SizeBytes: 5,
+						},
+						IsExecutable: true,
+					},
+				},
+			}, nil)
+
+		differences, err := cas.DiffActionResultOutputs(ctx, directoryFetcher, digestFunction, oldActionResult, newActionResult)
+		require.NoError(t, err)
+		require.Equal(t, []cas.OutputDifference{
+			{
+				Path: "dir",
+				New:  &cas.OutputEntry{Kind: cas.OutputEntryKindDirectory},
+			},
+			{
+				Path: "dir/subdir",
+				New:  &cas.OutputEntry{Kind: cas.OutputEntryKindDirectory},
+			},
+			{
+				Path: "dir/subdir/file",
+				New: &cas.OutputEntry{
+					Kind:         cas.OutputEntryKindFile,
+					Digest:       digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5),
+					IsExecutable: true,
+				},
+			},
+			{
+				Path: "modified.txt",
+				Old: &cas.OutputEntry{
+					Kind:   cas.OutputEntryKindFile,
+					Digest: digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5),
+				},
+				New: &cas.OutputEntry{
+					Kind:   cas.OutputEntryKindFile,
+					Digest: digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "6fc422233a40a75a1f028e11c3cd1140", 7),
+				},
+			},
+			{
+				Path: "removed.txt",
+				Old: &cas.OutputEntry{
+					Kind:   cas.OutputEntryKindFile,
+					Digest: digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "d41d8cd98f00b204e9800998ecf8427e", 0),
+				},
+			},
+		}, differences)
+	})
+}
@@ -0,0 +1,229 @@
+package cas
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type getTreePrefetchingDirectoryFetcher struct {
+	DirectoryFetcher
+	contentAddressableStorage remoteexecution.ContentAddressableStorageClient
+	digestKeyFormat           digest.KeyFormat
+	maximumCount              int
+	callOptions               []grpc.CallOption
+	getTreeUnimplemented      atomic.Bool
+
+	lock         sync.Mutex
+	prefetched   map[string]*remoteexecution.Directory
+	insertions   []string
+	continuation *getTreeContinuation
+}
+
+// getTreeContinuation contains the state of a call to GetTree() that
+// was stopped early, because more than maximumCount objects were
+// returned. It allows the call to be resumed where it left off.
+type getTreeContinuation struct {
+	rootDigest digest.Digest
+	pageToken  string
+}
+
+// NewGetTreePrefetchingDirectoryFetcher creates an adapter for
+// DirectoryFetcher that loads Directory objects using the GetTree()
+// method of the Content Addressable Storage (CAS). When a directory is
+// requested that has not been prefetched previously, the full
+// hierarchy underneath it is obtained using a single streaming call,
+// as opposed to fetching every Directory object separately. This
+// reduces the number of round trips when instantiating input roots
+// containing deep directory hierarchies.
+//
+// Directory objects returned by GetTree() are stored under the digest
+// of their canonical encoding. Objects that are not canonically
+// encoded, or that have been discarded because more than maximumCount
+// objects are prefetched, are fetched through the base DirectoryFetcher
+// instead. The base DirectoryFetcher is also used if GetTree() fails.
+//
+// If a hierarchy contains more than maximumCount objects, the page
+// token of the last response that was processed is retained. Directory
+// objects that are requested subsequently and that have not been
+// prefetched are first looked up by resuming this call, as opposed to
+// calling GetTree() against their own digests. This prevents parts of
+// large hierarchies from being transferred repeatedly.
+//
+// Call options may be provided to enable compression of responses
+// (e.g., using grpc.UseCompressor()) if supported by the server.
+func NewGetTreePrefetchingDirectoryFetcher(base DirectoryFetcher, contentAddressableStorage remoteexecution.ContentAddressableStorageClient, digestKeyFormat digest.KeyFormat, maximumCount int, callOptions []grpc.CallOption) DirectoryFetcher {
+	return &getTreePrefetchingDirectoryFetcher{
+		DirectoryFetcher:          base,
+		contentAddressableStorage: contentAddressableStorage,
+		digestKeyFormat:           digestKeyFormat,
+		maximumCount:              maximumCount,
+		callOptions:               callOptions,
+		prefetched:                map[string]*remoteexecution.Directory{},
+	}
+}
+
+// takePrefetched removes a prefetched Directory object. Each object is
+// only handed out once, as it is expected that callers place a
+// CachingDirectoryFetcher on top of this adapter.
+func (df *getTreePrefetchingDirectoryFetcher) takePrefetched(key string) (*remoteexecution.Directory, bool) {
+	df.lock.Lock()
+	defer df.lock.Unlock()
+
+	directory, ok := df.prefetched[key]
+	if ok {
+		delete(df.prefetched, key)
+	}
+	return directory, ok
+}
+
+func (df *getTreePrefetchingDirectoryFetcher) insertPrefetched(directories map[string]*remoteexecution.Directory) {
+	df.lock.Lock()
+	defer df.lock.Unlock()
+
+	for key, directory := range directories {
+		if _, ok := df.prefetched[key]; ok {
+			continue
+		}
+		// Discard the oldest entries if needed. Entries may
+		// already have been taken, in which case they are
+		// skipped.
+		for len(df.prefetched) >= df.maximumCount && len(df.insertions) > 0 {
+			delete(df.prefetched, df.insertions[0])
+			df.insertions = df.insertions[1:]
+		}
+		df.prefetched[key] = directory
+		df.insertions = append(df.insertions, key)
+	}
+
+	// Prevent unbounded growth of the insertion log when entries
+	// are taken before they are evicted.
+	if len(df.insertions) > 2*df.maximumCount {
+		insertions := make([]string, 0, len(df.prefetched))
+		for _, key := range df.insertions {
+			if _, ok := df.prefetched[key]; ok {
+				insertions = append(insertions, key)
+			}
+		}
+		df.insertions = insertions
+	}
+}
+
+// getTree calls GetTree() to obtain all Directory objects contained in
+// the hierarchy underneath the provided directory, starting at the
+// provided page. At most maximumCount objects are returned, rounded up
+// to the nearest page boundary. If not all objects have been returned,
+// the page token at which the call may be resumed is returned as well.
+func (df *getTreePrefetchingDirectoryFetcher) getTree(ctx context.Context, rootDigest digest.Digest, pageToken string) (map[string]*remoteexecution.Directory, string, error) {
+	ctxWithCancel, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	digestFunction := rootDigest.GetDigestFunction()
+	client, err := df.contentAddressableStorage.GetTree(ctxWithCancel, &remoteexecution.GetTreeRequest{
+		InstanceName:   rootDigest.GetInstanceName().String(),
+		RootDigest:     rootDigest.GetProto(),
+		PageSize:       int32(df.maximumCount),
+		PageToken:      pageToken,
+		DigestFunction: digestFunction.GetEnumValue(),
+	}, df.callOptions...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	directories := map[string]*remoteexecution.Directory{}
+	for {
+		response, err := client.Recv()
+		if err == io.EOF {
+			return directories, "", nil
+		} else if err != nil {
+			return nil, "", err
+		}
+		for _, directory := range response.Directories {
+			data, err := proto.MarshalOptions{Deterministic: true}.Marshal(directory)
+			if err != nil {
+				return nil, "", err
+			}
+			generator := digestFunction.NewGenerator(int64(len(data)))
+			generator.Write(data)
+			directories[generator.Sum().GetKey(df.digestKeyFormat)] = directory
+		}
+		if len(directories) >= df.maximumCount {
+			// Only stop at page boundaries, so that the
+			// call can be resumed later on.
+			return directories, response.NextPageToken, nil
+		}
+	}
+}
+
+// takeContinuation removes the state of the call to GetTree() that was
+// most recently stopped early, so that it may be resumed.
+func (df *getTreePrefetchingDirectoryFetcher) takeContinuation() *getTreeContinuation {
+	df.lock.Lock()
+	defer df.lock.Unlock()
+
+	continuation := df.continuation
+	df.continuation = nil
+	return continuation
+}
+
+// prefetch calls GetTree() and stores all Directory objects that are
+// returned, except the one that is requested, which is returned
+// separately.
+func (df *getTreePrefetchingDirectoryFetcher) prefetch(ctx context.Context, rootDigest digest.Digest, pageToken, key string) (*remoteexecution.Directory, bool, error) {
+	directories, nextPageToken, err := df.getTree(ctx, rootDigest, pageToken)
+	if err != nil {
+		return nil, false, err
+	}
+	directory, ok := directories[key]
+	delete(directories, key)
+	df.insertPrefetched(directories)
+	if nextPageToken != "" {
+		df.lock.Lock()
+		df.continuation = &getTreeContinuation{
+			rootDigest: rootDigest,
+			pageToken:  nextPageToken,
+		}
+		df.lock.Unlock()
+	}
+	return directory, ok, nil
+}
+
+func (df *getTreePrefetchingDirectoryFetcher) GetDirectory(ctx context.Context, directoryDigest digest.Digest) (*remoteexecution.Directory, error) {
+	key := directoryDigest.GetKey(df.digestKeyFormat)
+	if directory, ok := df.takePrefetched(key); ok {
+		return directory, nil
+	}
+
+	if !df.getTreeUnimplemented.Load() {
+		// The directory is likely part of a hierarchy that was
+		// only partially prefetched. Resume that call first.
+		if continuation := df.takeContinuation(); continuation != nil {
+			if directory, ok, err := df.prefetch(ctx, continuation.rootDigest, continuation.pageToken, key); err == nil && ok {
+				return directory, nil
+			}
+		}
+
+		directory, ok, err := df.prefetch(ctx, directoryDigest, "", key)
+		if err == nil {
+			if ok {
+				return directory, nil
+			}
+		} else if status.Code(err) == codes.Unimplemented {
+			// The server does not support GetTree().
+			// Don't attempt to call it again.
+			df.getTreeUnimplemented.Store(true)
+		}
+	}
+
+	// Fall back to fetching the directory separately.
+	return df.DirectoryFetcher.GetDirectory(ctx, directoryDigest)
+}
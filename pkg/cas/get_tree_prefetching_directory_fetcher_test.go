@@ -0,0 +1,145 @@
+package cas_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/cas"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func getDirectoryDigest(t *testing.T, directory *remoteexecution.Directory) digest.Digest {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(directory)
+	require.NoError(t, err)
+	generator := digest.MustNewFunction("example", remoteexecution.DigestFunction_MD5).NewGenerator(int64(len(data)))
+	generator.Write(data)
+	return generator.Sum()
+}
+
+func TestGetTreePrefetchingDirectoryFetcher(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseDirectoryFetcher := mock.NewMockDirectoryFetcher(ctrl)
+	contentAddressableStorage := mock.NewMockContentAddressableStorageClient(ctrl)
+	directoryFetcher := cas.NewGetTreePrefetchingDirectoryFetcher(baseDirectoryFetcher, contentAddressableStorage, digest.KeyWithoutInstance, 10, nil)
+
+	childDirectory := &remoteexecution.Directory{
+		Files: []*remoteexecution.FileNode{{
+			Name: "file",
+			Digest: &remoteexecution.Digest{
+				Hash:      "8b1a9953c4611296a827abf8c47804d7",
+				SizeBytes: 5,
+			},
+		}},
+	}
+	childDigest := getDirectoryDigest(t, childDirectory)
+	rootDirectory := &remoteexecution.Directory{
+		Directories: []*remoteexecution.DirectoryNode{{
+			Name:   "child",
+			Digest: childDigest.GetProto(),
+		}},
+	}
+	rootDigest := getDirectoryDigest(t, rootDirectory)
+
+	t.Run("Success", func(t *testing.T) {
+		// Requesting the root directory should cause the
+		// full hierarchy to be loaded using GetTree().
+		client := mock.NewMockContentAddressableStorage_GetTreeClient(ctrl)
+		contentAddressableStorage.EXPECT().GetTree(gomock.Any(), testutil.EqProto(t, &remoteexecution.GetTreeRequest{
+			InstanceName:   "example",
+			RootDigest:     rootDigest.GetProto(),
+			PageSize:       10,
+			DigestFunction: remoteexecution.DigestFunction_MD5,
+		})).Return(client, nil)
+		client.EXPECT().Recv().Return(&remoteexecution.GetTreeResponse{
+			Directories: []*remoteexecution.Directory{rootDirectory, childDirectory},
+		}, nil)
+		client.EXPECT().Recv().Return(nil, io.EOF)
+
+		directory, err := directoryFetcher.GetDirectory(ctx, rootDigest)
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, rootDirectory, directory)
+
+		// The child directory should be returned without
+		// performing any further I/O.
+		directory, err = directoryFetcher.GetDirectory(ctx, childDigest)
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, childDirectory, directory)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		// If the hierarchy contains more objects than may be
+		// prefetched, GetTree() should stop at a page boundary.
+		directoryFetcher := cas.NewGetTreePrefetchingDirectoryFetcher(baseDirectoryFetcher, contentAddressableStorage, digest.KeyWithoutInstance, 1, nil)
+		client1 := mock.NewMockContentAddressableStorage_GetTreeClient(ctrl)
+		contentAddressableStorage.EXPECT().GetTree(gomock.Any(), testutil.EqProto(t, &remoteexecution.GetTreeRequest{
+			InstanceName:   "example",
+			RootDigest:     rootDigest.GetProto(),
+			PageSize:       1,
+			DigestFunction: remoteexecution.DigestFunction_MD5,
+		})).Return(client1, nil)
+		client1.EXPECT().Recv().Return(&remoteexecution.GetTreeResponse{
+			Directories:   []*remoteexecution.Directory{rootDirectory},
+			NextPageToken: "page2",
+		}, nil)
+
+		directory, err := directoryFetcher.GetDirectory(ctx, rootDigest)
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, rootDirectory, directory)
+
+		// Requesting the child directory should cause the
+		// previous call to be resumed, as opposed to calling
+		// GetTree() against the child directory.
+		client2 := mock.NewMockContentAddressableStorage_GetTreeClient(ctrl)
+		contentAddressableStorage.EXPECT().GetTree(gomock.Any(), testutil.EqProto(t, &remoteexecution.GetTreeRequest{
+			InstanceName:   "example",
+			RootDigest:     rootDigest.GetProto(),
+			PageSize:       1,
+			PageToken:      "page2",
+			DigestFunction: remoteexecution.DigestFunction_MD5,
+		})).Return(client2, nil)
+		client2.EXPECT().Recv().Return(&remoteexecution.GetTreeResponse{
+			Directories: []*remoteexecution.Directory{childDirectory},
+		}, nil)
+
+		directory, err = directoryFetcher.GetDirectory(ctx, childDigest)
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, childDirectory, directory)
+	})
+
+	t.Run("Fallback", func(t *testing.T) {
+		// If GetTree() fails, the directory should be fetched
+		// through the base DirectoryFetcher.
+		contentAddressableStorage.EXPECT().GetTree(gomock.Any(), gomock.Any()).
+			Return(nil, status.Error(codes.Internal, "Server on fire"))
+		baseDirectoryFetcher.EXPECT().GetDirectory(ctx, childDigest).Return(childDirectory, nil)
+
+		directory, err := directoryFetcher.GetDirectory(ctx, childDigest)
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, childDirectory, directory)
+	})
+
+	t.Run("Unimplemented", func(t *testing.T) {
+		// If the server does not support GetTree(), it should
+		// not be called again.
+		contentAddressableStorage.EXPECT().GetTree(gomock.Any(), gomock.Any()).
+			Return(nil, status.Error(codes.Unimplemented, "GetTree() is not supported"))
+		baseDirectoryFetcher.EXPECT().GetDirectory(ctx, rootDigest).Return(rootDirectory, nil).Times(2)
+
+		for i := 0; i < 2; i++ {
+			directory, err := directoryFetcher.GetDirectory(ctx, rootDigest)
+			require.NoError(t, err)
+			testutil.RequireEqualProto(t, rootDirectory, directory)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package cas
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+)
+
+// HardlinkingCacheLayout determines the location at which
+// HardlinkingFileFetcher stores files within its cache directory.
+// Storing millions of files in a single directory degrades the
+// performance of many file systems, so it may be desirable to spread
+// files across a hierarchy of subdirectories.
+type HardlinkingCacheLayout interface {
+	// GetPath returns the names of the subdirectories in which a
+	// cached file should be stored, followed by the name of the
+	// file itself.
+	GetPath(key string) ([]path.Component, path.Component)
+}
+
+type flatHardlinkingCacheLayout struct{}
+
+func (flatHardlinkingCacheLayout) GetPath(key string) ([]path.Component, path.Component) {
+	return nil, path.MustNewComponent(key)
+}
+
+// FlatHardlinkingCacheLayout stores all files directly inside the
+// cache directory, using their key as the filename.
+var FlatHardlinkingCacheLayout HardlinkingCacheLayout = flatHardlinkingCacheLayout{}
+
+type fanOutHardlinkingCacheLayout struct {
+	levels int
+}
+
+// NewFanOutHardlinkingCacheLayout creates a HardlinkingCacheLayout
+// that stores files in a hierarchy of subdirectories, similar to how
+// Git stores loose objects. Each level of the hierarchy consists of up
+// to 256 subdirectories, named after two hexadecimal characters of a
+// hash of the key.
+func NewFanOutHardlinkingCacheLayout(levels int) HardlinkingCacheLayout {
+	if levels < 1 || levels > 4 {
+		panic("The number of levels must be between 1 and 4")
+	}
+	return &fanOutHardlinkingCacheLayout{
+		levels: levels,
+	}
+}
+
+func (cl *fanOutHardlinkingCacheLayout) GetPath(key string) ([]path.Component, path.Component) {
+	// Keys already contain a cryptographic hash, but their exact
+	// format is opaque. Hash them once more to obtain a uniform
+	// distribution across subdirectories.
+	hasher := fnv.New32a()
+	hasher.Write([]byte(key))
+	hash := hasher.Sum32()
+
+	directories := make([]path.Component, 0, cl.levels)
+	for i := 0; i < cl.levels; i++ {
+		directories = append(directories, path.MustNewComponent(fmt.Sprintf("%02x", byte(hash>>(24-8*i)))))
+	}
+	return directories, path.MustNewComponent(key)
+}
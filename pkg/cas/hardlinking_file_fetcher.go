@@ -2,28 +2,144 @@ package cas
 
 import (
 	"context"
+	"io"
+	"log"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-storage/pkg/clock"
 	"github.com/buildbarn/bb-storage/pkg/digest"
 	"github.com/buildbarn/bb-storage/pkg/eviction"
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/random"
 	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"google.golang.org/grpc/codes"
 )
 
+var (
+	hardlinkingFileFetcherPrometheusMetrics sync.Once
+
+	hardlinkingFileFetcherScrubbedFilesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "buildbarn",
+			Subsystem: "cas",
+			Name:      "hardlinking_file_fetcher_scrubbed_files_total",
+			Help:      "Number of files in the cache directory that were validated in the background, by result.",
+		},
+		[]string{"result"})
+	hardlinkingFileFetcherScrubbedFilesValid     = hardlinkingFileFetcherScrubbedFilesTotal.WithLabelValues("Valid")
+	hardlinkingFileFetcherScrubbedFilesCorrupted = hardlinkingFileFetcherScrubbedFilesTotal.WithLabelValues("Corrupted")
+	hardlinkingFileFetcherScrubbedFilesIOError   = hardlinkingFileFetcherScrubbedFilesTotal.WithLabelValues("IOError")
+
+	hardlinkingFileFetcherCachedFiles = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "buildbarn",
+			Subsystem: "cas",
+			Name:      "hardlinking_file_fetcher_cached_files",
+			Help:      "Number of files stored in the cache directory.",
+		},
+		[]string{"cache"})
+	hardlinkingFileFetcherCachedSizeBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "buildbarn",
+			Subsystem: "cas",
+			Name:      "hardlinking_file_fetcher_cached_size_bytes",
+			Help:      "Total size of the files stored in the cache directory, in bytes.",
+		},
+		[]string{"cache"})
+	hardlinkingFileFetcherEvictedFilesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "buildbarn",
+			Subsystem: "cas",
+			Name:      "hardlinking_file_fetcher_evicted_files_total",
+			Help:      "Number of files removed from the cache directory, by reason.",
+		},
+		[]string{"cache", "reason"})
+)
+
+// HardlinkingFileFetcher is a FileFetcher that stores files in a local
+// cache directory. In addition to fetching files, it provides the
+// ability to validate the contents of the cache in the background.
+type HardlinkingFileFetcher interface {
+	FileFetcher
+
+	// ScrubCachedFiles validates the contents of up to a given
+	// number of files stored in the cache against their digests.
+	// Successive calls resume where the previous call left off, so
+	// that all files in the cache are eventually validated.
+	// Corrupted files are removed from the cache directory, causing
+	// them to be downloaded once again when requested.
+	ScrubCachedFiles(ctx context.Context, maximumFileCount int) error
+
+	// EvictIdleFiles removes files from the cache that have not
+	// been accessed for a given amount of time. This prevents
+	// files that are no longer used by any build action from
+	// occupying disk space indefinitely. This method requires that
+	// the eviction set uses the LEAST_RECENTLY_USED policy, as it
+	// stops removing files once it encounters a file that has been
+	// accessed recently. It returns the number of files removed.
+	EvictIdleFiles(maximumIdleTime time.Duration) (int, error)
+
+	// FlushCachedFiles removes all files from the cache. It returns
+	// the number of files removed.
+	FlushCachedFiles() (int, error)
+
+	// MigrateCachedFiles adds files that are present in the cache
+	// directory, but are not part of the bookkeeping, to the cache.
+	// This allows files left behind by a previous invocation to be
+	// reused. Files are moved to the location dictated by the
+	// current cache layout, meaning that the layout may be changed
+	// without flushing the cache. Files with names that cannot be
+	// parsed are removed. Adopted files are not validated, as
+	// ScrubCachedFiles() can do so without delaying startup. As
+	// build actions may run while this method is executing, files
+	// are processed one at a time. It returns the number of files
+	// adopted.
+	MigrateCachedFiles(ctx context.Context) (int, error)
+}
+
+// cachedFile contains the bookkeeping of a single file stored in the
+// cache directory.
+type cachedFile struct {
+	digest digest.Digest
+
+	// The time at which the file was last inserted or accessed.
+	// Protected by evictionLock, as this field is updated while
+	// only holding filesLock for reading.
+	lastAccess time.Time
+}
+
 type hardlinkingFileFetcher struct {
 	base           FileFetcher
 	cacheDirectory filesystem.Directory
+	cacheLayout    HardlinkingCacheLayout
 	maxFiles       int
 	maxSize        int64
 
+	verificationProbability float64
+	randomNumberGenerator   random.ThreadSafeGenerator
+	clock                   clock.Clock
+
+	cachedFiles          prometheus.Gauge
+	cachedSizeBytes      prometheus.Gauge
+	evictedFilesCapacity prometheus.Counter
+	evictedFilesIdle     prometheus.Counter
+	evictedFilesFlush    prometheus.Counter
+
 	filesLock      sync.RWMutex
-	filesSize      map[string]int64
+	files          map[string]*cachedFile
 	filesTotalSize int64
 
+	scrubLock  sync.Mutex
+	scrubQueue []string
+
 	evictionLock sync.Mutex
 	evictionSet  eviction.Set[string]
 }
@@ -33,31 +149,236 @@ type hardlinkingFileFetcher struct {
 // at the target location, they are hardlinked into the cache. Future
 // calls for the same file will hardlink them from the cache to the
 // target location. This reduces the amount of network traffic needed.
-func NewHardlinkingFileFetcher(base FileFetcher, cacheDirectory filesystem.Directory, maxFiles int, maxSize int64, evictionSet eviction.Set[string]) FileFetcher {
+//
+// Because files in the cache are hardlinked into build directories,
+// actions that modify their input files in place also corrupt the
+// cache. The same holds for bit rot on the underlying storage. To
+// detect this, a fraction of cached files (as determined by
+// verificationProbability) has its contents checked against its digest
+// before being reused. Files that fail verification are removed from
+// the cache and downloaded once again.
+//
+// The location at which files are stored within the cache directory is
+// determined by a HardlinkingCacheLayout.
+//
+// As verification upon access only covers a fraction of the files,
+// storage that is failing may cause corrupted files to remain in the
+// cache for a long time. ScrubCachedFiles() may be called periodically
+// to validate all cached files, without affecting build actions.
+//
+// In addition to evicting files when the cache reaches its maximum
+// size, files that are no longer accessed may be removed by calling
+// EvictIdleFiles() periodically. The provided clock is used to track
+// when files were last accessed.
+//
+// The name of the cache is used to label Prometheus metrics, so that
+// multiple caches can be monitored separately.
+func NewHardlinkingFileFetcher(base FileFetcher, cacheDirectory filesystem.Directory, cacheLayout HardlinkingCacheLayout, maxFiles int, maxSize int64, evictionSet eviction.Set[string], verificationProbability float64, randomNumberGenerator random.ThreadSafeGenerator, clock clock.Clock, cacheName string) HardlinkingFileFetcher {
+	hardlinkingFileFetcherPrometheusMetrics.Do(func() {
+		prometheus.MustRegister(hardlinkingFileFetcherScrubbedFilesTotal)
+		prometheus.MustRegister(hardlinkingFileFetcherCachedFiles)
+		prometheus.MustRegister(hardlinkingFileFetcherCachedSizeBytes)
+		prometheus.MustRegister(hardlinkingFileFetcherEvictedFilesTotal)
+	})
+
 	return &hardlinkingFileFetcher{
 		base:           base,
 		cacheDirectory: cacheDirectory,
+		cacheLayout:    cacheLayout,
 		maxFiles:       maxFiles,
 		maxSize:        maxSize,
 
-		filesSize: map[string]int64{},
+		verificationProbability: verificationProbability,
+		randomNumberGenerator:   randomNumberGenerator,
+		clock:                   clock,
+
+		cachedFiles:          hardlinkingFileFetcherCachedFiles.WithLabelValues(cacheName),
+		cachedSizeBytes:      hardlinkingFileFetcherCachedSizeBytes.WithLabelValues(cacheName),
+		evictedFilesCapacity: hardlinkingFileFetcherEvictedFilesTotal.WithLabelValues(cacheName, "Capacity"),
+		evictedFilesIdle:     hardlinkingFileFetcherEvictedFilesTotal.WithLabelValues(cacheName, "Idle"),
+		evictedFilesFlush:    hardlinkingFileFetcherEvictedFilesTotal.WithLabelValues(cacheName, "Flush"),
+
+		files: map[string]*cachedFile{},
 
 		evictionSet: evictionSet,
 	}
 }
 
+// openCachedFileParent opens the directory in which a cached file is
+// stored, as determined by the cache layout. If create is set, any
+// missing directories are created. The returned function must be
+// called to release the directory.
+func (ff *hardlinkingFileFetcher) openCachedFileParent(key string, create bool) (filesystem.Directory, path.Component, func(), error) {
+	directoryNames, name := ff.cacheLayout.GetPath(key)
+	return ff.openDirectories(directoryNames, name, create)
+}
+
+// openDirectories opens a directory within the cache directory by
+// walking a sequence of directory names.
+func (ff *hardlinkingFileFetcher) openDirectories(directoryNames []path.Component, name path.Component, create bool) (filesystem.Directory, path.Component, func(), error) {
+	var directories []filesystem.DirectoryCloser
+	closeDirectories := func() {
+		for i := len(directories) - 1; i >= 0; i-- {
+			directories[i].Close()
+		}
+	}
+
+	d := ff.cacheDirectory
+	for _, directoryName := range directoryNames {
+		if create {
+			if err := d.Mkdir(directoryName, 0o777); err != nil && !os.IsExist(err) {
+				closeDirectories()
+				return nil, path.Component{}, nil, err
+			}
+		}
+		child, err := d.EnterDirectory(directoryName)
+		if err != nil {
+			closeDirectories()
+			return nil, path.Component{}, nil, err
+		}
+		directories = append(directories, child)
+		d = child
+	}
+	return d, name, closeDirectories, nil
+}
+
+// removeCachedFile removes a file from the cache directory. Files that
+// are already absent are ignored.
+func (ff *hardlinkingFileFetcher) removeCachedFile(key string) error {
+	d, name, closeDirectories, err := ff.openCachedFileParent(key, false)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer closeDirectories()
+	if err := d.Remove(name); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// linkFromCache creates a hardlink of a cached file at a target
+// location.
+func (ff *hardlinkingFileFetcher) linkFromCache(key string, directory filesystem.Directory, name path.Component) error {
+	d, cachedName, closeDirectories, err := ff.openCachedFileParent(key, false)
+	if err != nil {
+		return err
+	}
+	defer closeDirectories()
+	return d.Link(cachedName, directory, name)
+}
+
+// linkIntoCache creates a hardlink of a file in the cache directory.
+// Files that are already present in the cache are left untouched.
+func (ff *hardlinkingFileFetcher) linkIntoCache(key string, directory filesystem.Directory, name path.Component) error {
+	d, cachedName, closeDirectories, err := ff.openCachedFileParent(key, true)
+	if err != nil {
+		return err
+	}
+	defer closeDirectories()
+	if err := directory.Link(name, d, cachedName); err != nil && !os.IsExist(err) {
+		return err
+	}
+	return nil
+}
+
+// evictLocked removes the file that is at the head of the eviction set
+// from the cache directory. The caller must hold filesLock for writing
+// and evictionLock.
+func (ff *hardlinkingFileFetcher) evictLocked(evictedFiles prometheus.Counter) error {
+	// Remove a file from disk.
+	key := ff.evictionSet.Peek()
+	if err := ff.removeCachedFile(key); err != nil {
+		return util.StatusWrapfWithCode(err, codes.Internal, "Failed to remove cached file %#v", key)
+	}
+
+	// Remove file from bookkeeping.
+	ff.evictionSet.Remove()
+	sizeBytes := ff.files[key].digest.GetSizeBytes()
+	ff.filesTotalSize -= sizeBytes
+	delete(ff.files, key)
+	ff.cachedFiles.Dec()
+	ff.cachedSizeBytes.Sub(float64(sizeBytes))
+	evictedFiles.Inc()
+	return nil
+}
+
 func (ff *hardlinkingFileFetcher) makeSpace(size int64) error {
-	for len(ff.filesSize) > 0 && (len(ff.filesSize) >= ff.maxFiles || ff.filesTotalSize+size > ff.maxSize) {
-		// Remove a file from disk.
-		key := ff.evictionSet.Peek()
-		if err := ff.cacheDirectory.Remove(path.MustNewComponent(key)); err != nil && !os.IsNotExist(err) {
-			return util.StatusWrapfWithCode(err, codes.Internal, "Failed to remove cached file %#v", key)
+	for len(ff.files) > 0 && (len(ff.files) >= ff.maxFiles || ff.filesTotalSize+size > ff.maxSize) {
+		if err := ff.evictLocked(ff.evictedFilesCapacity); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		// Remove file from bookkeeping.
-		ff.evictionSet.Remove()
-		ff.filesTotalSize -= ff.filesSize[key]
-		delete(ff.filesSize, key)
+// verifyCachedFile checks whether the contents of a file in the cache
+// still match its digest. The caller must hold filesLock for reading.
+//
+// The file is opened while holding the lock, but the lock is released
+// while its contents are hashed. This prevents the verification of
+// large files from blocking insertions and evictions. As the file
+// remains open, its contents can still be hashed if it gets evicted in
+// the meantime. The lock is reacquired before returning.
+func (ff *hardlinkingFileFetcher) verifyCachedFile(key string, blobDigest digest.Digest) (bool, error) {
+	f, err := ff.openCachedFile(key)
+	ff.filesLock.RUnlock()
+	defer ff.filesLock.RLock()
+	if err != nil {
+		return false, err
+	}
+	if f == nil {
+		// Let the caller deal with missing files.
+		return true, nil
+	}
+	defer f.Close()
+
+	// Read one byte past the expected size, so that files that
+	// have grown are detected as well.
+	sizeBytes := blobDigest.GetSizeBytes()
+	generator := blobDigest.GetDigestFunction().NewGenerator(sizeBytes)
+	if _, err := io.Copy(generator, io.NewSectionReader(f, 0, sizeBytes+1)); err != nil {
+		return false, util.StatusWrapfWithCode(err, codes.Internal, "Failed to read cached file %#v", key)
+	}
+	return generator.Sum() == blobDigest, nil
+}
+
+// openCachedFile opens a file in the cache for reading. It returns nil
+// if the file does not exist.
+func (ff *hardlinkingFileFetcher) openCachedFile(key string) (filesystem.FileReader, error) {
+	d, name, closeDirectories, err := ff.openCachedFileParent(key, false)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, util.StatusWrapfWithCode(err, codes.Internal, "Failed to open directory of cached file %#v", key)
+	}
+	defer closeDirectories()
+	f, err := d.OpenRead(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, util.StatusWrapfWithCode(err, codes.Internal, "Failed to open cached file %#v", key)
+	}
+	return f, nil
+}
+
+// removeCorruptedCachedFile removes a file from the cache directory
+// that failed verification. As filesLock is released during
+// verification, the file may have been evicted and reinserted in the
+// meantime. The file is thus only removed if the bookkeeping still
+// refers to the file that was verified. The caller must hold filesLock
+// for reading.
+func (ff *hardlinkingFileFetcher) removeCorruptedCachedFile(key string, file *cachedFile) error {
+	if ff.files[key] != file {
+		return nil
+	}
+	log.Printf("Cached file %#v does not match its digest, removing it from the cache", key)
+	if err := ff.removeCachedFile(key); err != nil {
+		return util.StatusWrapfWithCode(err, codes.Internal, "Failed to remove corrupted cached file %#v", key)
 	}
 	return nil
 }
@@ -73,12 +394,33 @@ func (ff *hardlinkingFileFetcher) GetFile(ctx context.Context, blobDigest digest
 	// If the file is present in the cache, hardlink it to the destination.
 	wasMissing := false
 	ff.filesLock.RLock()
-	if _, ok := ff.filesSize[key]; ok {
+	file, ok := ff.files[key]
+	if ok && ff.verificationProbability > 0 && ff.randomNumberGenerator.Float64() < ff.verificationProbability {
+		if valid, err := ff.verifyCachedFile(key, blobDigest); err != nil {
+			ff.filesLock.RUnlock()
+			return err
+		} else if !valid {
+			// The file got corrupted, either due to an
+			// action modifying its inputs or bit rot.
+			// Remove it from the cache, so that it gets
+			// downloaded and reinserted below.
+			if err := ff.removeCorruptedCachedFile(key, file); err != nil {
+				ff.filesLock.RUnlock()
+				return err
+			}
+		}
+
+		// The file may have been evicted while it was being
+		// verified.
+		file, ok = ff.files[key]
+	}
+	if ok {
 		ff.evictionLock.Lock()
 		ff.evictionSet.Touch(key)
+		file.lastAccess = ff.clock.Now()
 		ff.evictionLock.Unlock()
 
-		if err := ff.cacheDirectory.Link(path.MustNewComponent(key), directory, name); err == nil {
+		if err := ff.linkFromCache(key, directory, name); err == nil {
 			// Successfully hardlinked the file to its destination.
 			ff.filesLock.RUnlock()
 			return nil
@@ -101,7 +443,7 @@ func (ff *hardlinkingFileFetcher) GetFile(ctx context.Context, blobDigest digest
 
 	ff.filesLock.Lock()
 	defer ff.filesLock.Unlock()
-	if _, ok := ff.filesSize[key]; !ok {
+	if _, ok := ff.files[key]; !ok {
 		ff.evictionLock.Lock()
 		defer ff.evictionLock.Unlock()
 
@@ -112,18 +454,286 @@ func (ff *hardlinkingFileFetcher) GetFile(ctx context.Context, blobDigest digest
 		}
 
 		// Hardlink the file into the cache.
-		if err := directory.Link(name, ff.cacheDirectory, path.MustNewComponent(key)); err != nil && !os.IsExist(err) {
+		if err := ff.linkIntoCache(key, directory, name); err != nil {
 			return util.StatusWrapfWithCode(err, codes.Internal, "Failed to add cached file %#v", key)
 		}
 		ff.evictionSet.Insert(key)
-		ff.filesSize[key] = sizeBytes
+		ff.files[key] = &cachedFile{
+			digest:     blobDigest,
+			lastAccess: ff.clock.Now(),
+		}
 		ff.filesTotalSize += sizeBytes
+		ff.cachedFiles.Inc()
+		ff.cachedSizeBytes.Add(float64(sizeBytes))
 	} else if wasMissing {
 		// Even though the file is part of our bookkeeping, we
 		// observed it didn't exist. Repair this inconsistency.
-		if err := directory.Link(name, ff.cacheDirectory, path.MustNewComponent(key)); err != nil && !os.IsExist(err) {
+		if err := ff.linkIntoCache(key, directory, name); err != nil {
 			return util.StatusWrapfWithCode(err, codes.Internal, "Failed to repair cached file %#v", key)
 		}
 	}
 	return nil
 }
+
+func (ff *hardlinkingFileFetcher) ScrubCachedFiles(ctx context.Context, maximumFileCount int) error {
+	ff.scrubLock.Lock()
+	defer ff.scrubLock.Unlock()
+
+	for i := 0; i < maximumFileCount; i++ {
+		if err := util.StatusFromContext(ctx); err != nil {
+			return err
+		}
+
+		// Once all files have been validated, start over by
+		// taking a snapshot of the files in the cache.
+		if len(ff.scrubQueue) == 0 {
+			ff.filesLock.RLock()
+			for key := range ff.files {
+				ff.scrubQueue = append(ff.scrubQueue, key)
+			}
+			ff.filesLock.RUnlock()
+			if len(ff.scrubQueue) == 0 {
+				return nil
+			}
+		}
+		key := ff.scrubQueue[len(ff.scrubQueue)-1]
+		ff.scrubQueue = ff.scrubQueue[:len(ff.scrubQueue)-1]
+
+		// Skip files that have been evicted in the meantime.
+		ff.filesLock.RLock()
+		file, ok := ff.files[key]
+		if !ok {
+			ff.filesLock.RUnlock()
+			continue
+		}
+		valid, err := ff.verifyCachedFile(key, file.digest)
+		if err != nil {
+			ff.filesLock.RUnlock()
+			// Read errors are a strong indication that the
+			// underlying storage is failing. Log them, so
+			// that they may be correlated with errors
+			// reported by the device.
+			hardlinkingFileFetcherScrubbedFilesIOError.Inc()
+			log.Printf("Failed to validate cached file %#v: %s", key, err)
+			continue
+		}
+		if valid {
+			ff.filesLock.RUnlock()
+			hardlinkingFileFetcherScrubbedFilesValid.Inc()
+			continue
+		}
+
+		hardlinkingFileFetcherScrubbedFilesCorrupted.Inc()
+		err = ff.removeCorruptedCachedFile(key, file)
+		ff.filesLock.RUnlock()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ff *hardlinkingFileFetcher) EvictIdleFiles(maximumIdleTime time.Duration) (int, error) {
+	ff.filesLock.Lock()
+	defer ff.filesLock.Unlock()
+	ff.evictionLock.Lock()
+	defer ff.evictionLock.Unlock()
+
+	minimumLastAccess := ff.clock.Now().Add(-maximumIdleTime)
+	evictedCount := 0
+	for len(ff.files) > 0 && ff.files[ff.evictionSet.Peek()].lastAccess.Before(minimumLastAccess) {
+		if err := ff.evictLocked(ff.evictedFilesIdle); err != nil {
+			return evictedCount, err
+		}
+		evictedCount++
+	}
+	return evictedCount, nil
+}
+
+func (ff *hardlinkingFileFetcher) FlushCachedFiles() (int, error) {
+	ff.filesLock.Lock()
+	defer ff.filesLock.Unlock()
+	ff.evictionLock.Lock()
+	defer ff.evictionLock.Unlock()
+
+	evictedCount := 0
+	for len(ff.files) > 0 {
+		if err := ff.evictLocked(ff.evictedFilesFlush); err != nil {
+			return evictedCount, err
+		}
+		evictedCount++
+	}
+	return evictedCount, nil
+}
+
+// parseCacheKey converts the name of a file in the cache directory
+// back to the digest and executable bit from which it was derived. It
+// returns false if the name does not match the format used by
+// GetFile().
+func parseCacheKey(key string) (digest.Digest, bool) {
+	if !strings.HasSuffix(key, "+x") && !strings.HasSuffix(key, "-x") {
+		return digest.BadDigest, false
+	}
+	fields := strings.Split(key[:len(key)-2], "-")
+	if len(fields) != 3 {
+		return digest.BadDigest, false
+	}
+	digestFunctionValue, err := strconv.ParseInt(fields[0], 10, 32)
+	if err != nil {
+		return digest.BadDigest, false
+	}
+	digestFunction, err := digest.EmptyInstanceName.GetDigestFunction(remoteexecution.DigestFunction_Value(digestFunctionValue), 0)
+	if err != nil {
+		return digest.BadDigest, false
+	}
+	sizeBytes, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return digest.BadDigest, false
+	}
+	blobDigest, err := digestFunction.NewDigest(fields[1], sizeBytes)
+	if err != nil {
+		return digest.BadDigest, false
+	}
+	return blobDigest, true
+}
+
+func (ff *hardlinkingFileFetcher) MigrateCachedFiles(ctx context.Context) (int, error) {
+	return ff.migrateDirectory(ctx, ff.cacheDirectory, nil)
+}
+
+// migrateDirectory adopts all files contained in a directory within
+// the cache directory. Directories are traversed recursively, so that
+// files stored using any HardlinkingCacheLayout are found.
+func (ff *hardlinkingFileFetcher) migrateDirectory(ctx context.Context, d filesystem.Directory, directoryNames []path.Component) (int, error) {
+	entries, err := d.ReadDir()
+	if err != nil {
+		return 0, util.StatusWrapfWithCode(err, codes.Internal, "Failed to read contents of cache subdirectory %#v", formatDirectoryNames(directoryNames))
+	}
+
+	migratedCount := 0
+	for _, entry := range entries {
+		if err := util.StatusFromContext(ctx); err != nil {
+			return migratedCount, err
+		}
+
+		name := entry.Name()
+		childDirectoryNames := append(directoryNames[:len(directoryNames):len(directoryNames)], name)
+		switch entry.Type() {
+		case filesystem.FileTypeDirectory:
+			child, err := d.EnterDirectory(name)
+			if err != nil {
+				return migratedCount, util.StatusWrapfWithCode(err, codes.Internal, "Failed to enter cache subdirectory %#v", formatDirectoryNames(childDirectoryNames))
+			}
+			childMigratedCount, err := ff.migrateDirectory(ctx, child, childDirectoryNames)
+			child.Close()
+			migratedCount += childMigratedCount
+			if err != nil {
+				return migratedCount, err
+			}
+
+			// Remove directories that have become empty,
+			// such as the ones belonging to a previously
+			// used cache layout. This is done while holding
+			// the lock, as insertions may create
+			// directories on demand.
+			ff.filesLock.Lock()
+			err = d.Remove(name)
+			ff.filesLock.Unlock()
+			if err != nil && !os.IsExist(err) {
+				return migratedCount, util.StatusWrapfWithCode(err, codes.Internal, "Failed to remove cache subdirectory %#v", formatDirectoryNames(childDirectoryNames))
+			}
+		case filesystem.FileTypeRegularFile:
+			migrated, err := ff.migrateFile(d, directoryNames, name)
+			if err != nil {
+				return migratedCount, err
+			}
+			if migrated {
+				migratedCount++
+			}
+		default:
+			if err := d.Remove(name); err != nil && !os.IsNotExist(err) {
+				return migratedCount, util.StatusWrapfWithCode(err, codes.Internal, "Failed to remove unexpected cache entry %#v", formatDirectoryNames(childDirectoryNames))
+			}
+		}
+	}
+	return migratedCount, nil
+}
+
+// migrateFile adopts a single file contained in the cache directory.
+// If the file is not stored at the location dictated by the current
+// cache layout, it is moved.
+func (ff *hardlinkingFileFetcher) migrateFile(d filesystem.Directory, directoryNames []path.Component, name path.Component) (bool, error) {
+	key := name.String()
+	blobDigest, ok := parseCacheKey(key)
+	if !ok {
+		if err := d.Remove(name); err != nil && !os.IsNotExist(err) {
+			return false, util.StatusWrapfWithCode(err, codes.Internal, "Failed to remove unrecognized cached file %#v", key)
+		}
+		return false, nil
+	}
+
+	ff.filesLock.Lock()
+	defer ff.filesLock.Unlock()
+
+	// Files that are already part of the bookkeeping have been
+	// inserted after the migration started. Those are stored at
+	// the right location, meaning any other copy is redundant.
+	currentDirectoryNames, currentName := ff.cacheLayout.GetPath(key)
+	atCurrentLocation := currentName == name && len(currentDirectoryNames) == len(directoryNames)
+	for i := 0; atCurrentLocation && i < len(directoryNames); i++ {
+		atCurrentLocation = currentDirectoryNames[i] == directoryNames[i]
+	}
+	if _, ok := ff.files[key]; ok {
+		if atCurrentLocation {
+			return false, nil
+		}
+		if err := d.Remove(name); err != nil && !os.IsNotExist(err) {
+			return false, util.StatusWrapfWithCode(err, codes.Internal, "Failed to remove redundant cached file %#v", key)
+		}
+		return false, nil
+	}
+
+	ff.evictionLock.Lock()
+	defer ff.evictionLock.Unlock()
+
+	sizeBytes := blobDigest.GetSizeBytes()
+	if sizeBytes > ff.maxSize {
+		if err := d.Remove(name); err != nil && !os.IsNotExist(err) {
+			return false, util.StatusWrapfWithCode(err, codes.Internal, "Failed to remove oversized cached file %#v", key)
+		}
+		return false, nil
+	}
+	if err := ff.makeSpace(sizeBytes); err != nil {
+		return false, err
+	}
+	if !atCurrentLocation {
+		currentDirectory, _, closeDirectories, err := ff.openDirectories(currentDirectoryNames, currentName, true)
+		if err != nil {
+			return false, util.StatusWrapfWithCode(err, codes.Internal, "Failed to create directory for cached file %#v", key)
+		}
+		err = d.Rename(name, currentDirectory, currentName)
+		closeDirectories()
+		if err != nil {
+			return false, util.StatusWrapfWithCode(err, codes.Internal, "Failed to move cached file %#v", key)
+		}
+	}
+	ff.evictionSet.Insert(key)
+	ff.files[key] = &cachedFile{
+		digest:     blobDigest,
+		lastAccess: ff.clock.Now(),
+	}
+	ff.filesTotalSize += sizeBytes
+	ff.cachedFiles.Inc()
+	ff.cachedSizeBytes.Add(float64(sizeBytes))
+	return true, nil
+}
+
+// formatDirectoryNames converts a sequence of directory names to a
+// relative pathname, for use in error messages.
+func formatDirectoryNames(directoryNames []path.Component) string {
+	names := make([]string, 0, len(directoryNames))
+	for _, directoryName := range directoryNames {
+		names = append(names, directoryName.String())
+	}
+	return strings.Join(names, "/")
+}
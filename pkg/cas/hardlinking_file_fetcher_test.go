@@ -2,15 +2,19 @@ package cas_test
 
 import (
 	"context"
+	"io"
 	"os"
 	"syscall"
 	"testing"
+	"time"
 
 	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
 	"github.com/buildbarn/bb-remote-execution/internal/mock"
 	"github.com/buildbarn/bb-remote-execution/pkg/cas"
+	"github.com/buildbarn/bb-storage/pkg/clock"
 	"github.com/buildbarn/bb-storage/pkg/digest"
 	"github.com/buildbarn/bb-storage/pkg/eviction"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
 	"github.com/buildbarn/bb-storage/pkg/testutil"
 	"github.com/golang/mock/gomock"
@@ -25,7 +29,7 @@ func TestHardlinkingFileFetcher(t *testing.T) {
 
 	baseFileFetcher := mock.NewMockFileFetcher(ctrl)
 	cacheDirectory := mock.NewMockDirectory(ctrl)
-	fileFetcher := cas.NewHardlinkingFileFetcher(baseFileFetcher, cacheDirectory, 1, 1024, eviction.NewLRUSet[string]())
+	fileFetcher := cas.NewHardlinkingFileFetcher(baseFileFetcher, cacheDirectory, cas.FlatHardlinkingCacheLayout, 1, 1024, eviction.NewLRUSet[string](), 0, nil, clock.SystemClock, "test")
 
 	blobDigest1 := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
 	buildDirectory := mock.NewMockDirectory(ctrl)
@@ -126,3 +130,299 @@ func TestHardlinkingFileFetcher(t *testing.T) {
 		t,
 		fileFetcher.GetFile(ctx, blobDigest2, buildDirectory, path.MustNewComponent("goodbye.txt"), false))
 }
+
+func TestHardlinkingFileFetcherVerification(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseFileFetcher := mock.NewMockFileFetcher(ctrl)
+	cacheDirectory := mock.NewMockDirectory(ctrl)
+	randomNumberGenerator := mock.NewMockThreadSafeGenerator(ctrl)
+	fileFetcher := cas.NewHardlinkingFileFetcher(baseFileFetcher, cacheDirectory, cas.FlatHardlinkingCacheLayout, 10, 1024, eviction.NewLRUSet[string](), 0.5, randomNumberGenerator, clock.SystemClock, "test")
+
+	blobDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	buildDirectory := mock.NewMockDirectory(ctrl)
+
+	// Insert the file into the cache.
+	baseFileFetcher.EXPECT().GetFile(ctx, blobDigest, buildDirectory, path.MustNewComponent("hello.txt"), false)
+	buildDirectory.EXPECT().Link(path.MustNewComponent("hello.txt"), cacheDirectory, path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"))
+	require.NoError(
+		t,
+		fileFetcher.GetFile(ctx, blobDigest, buildDirectory, path.MustNewComponent("hello.txt"), false))
+
+	// Files that are not sampled should be hardlinked without
+	// verifying their contents.
+	randomNumberGenerator.EXPECT().Float64().Return(0.7)
+	cacheDirectory.EXPECT().Link(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"), buildDirectory, path.MustNewComponent("hello.txt"))
+	require.NoError(
+		t,
+		fileFetcher.GetFile(ctx, blobDigest, buildDirectory, path.MustNewComponent("hello.txt"), false))
+
+	// Sampled files whose contents match their digest should be
+	// hardlinked as usual.
+	randomNumberGenerator.EXPECT().Float64().Return(0.3)
+	file1 := mock.NewMockFileReader(ctrl)
+	cacheDirectory.EXPECT().OpenRead(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x")).Return(file1, nil)
+	file1.EXPECT().ReadAt(gomock.Any(), int64(0)).DoAndReturn(func(p []byte, off int64) (int, error) {
+		return copy(p, "Hello"), io.EOF
+	})
+	file1.EXPECT().Close()
+	cacheDirectory.EXPECT().Link(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"), buildDirectory, path.MustNewComponent("hello.txt"))
+	require.NoError(
+		t,
+		fileFetcher.GetFile(ctx, blobDigest, buildDirectory, path.MustNewComponent("hello.txt"), false))
+
+	// Sampled files that have been corrupted should be removed from
+	// the cache, downloaded once again, and reinserted.
+	randomNumberGenerator.EXPECT().Float64().Return(0.3)
+	file2 := mock.NewMockFileReader(ctrl)
+	cacheDirectory.EXPECT().OpenRead(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x")).Return(file2, nil)
+	file2.EXPECT().ReadAt(gomock.Any(), int64(0)).DoAndReturn(func(p []byte, off int64) (int, error) {
+		return copy(p, "Jello"), io.EOF
+	})
+	file2.EXPECT().Close()
+	cacheDirectory.EXPECT().Remove(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"))
+	cacheDirectory.EXPECT().Link(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"), buildDirectory, path.MustNewComponent("hello.txt")).
+		Return(syscall.ENOENT)
+	baseFileFetcher.EXPECT().GetFile(ctx, blobDigest, buildDirectory, path.MustNewComponent("hello.txt"), false)
+	buildDirectory.EXPECT().Link(path.MustNewComponent("hello.txt"), cacheDirectory, path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"))
+	require.NoError(
+		t,
+		fileFetcher.GetFile(ctx, blobDigest, buildDirectory, path.MustNewComponent("hello.txt"), false))
+}
+
+func TestHardlinkingFileFetcherFanOut(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseFileFetcher := mock.NewMockFileFetcher(ctrl)
+	cacheDirectory := mock.NewMockDirectory(ctrl)
+	fileFetcher := cas.NewHardlinkingFileFetcher(baseFileFetcher, cacheDirectory, cas.NewFanOutHardlinkingCacheLayout(2), 10, 1024, eviction.NewLRUSet[string](), 0, nil, clock.SystemClock, "test")
+
+	blobDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	buildDirectory := mock.NewMockDirectory(ctrl)
+
+	// When inserting files into the cache, the subdirectories in
+	// which they are stored should be created on demand.
+	baseFileFetcher.EXPECT().GetFile(ctx, blobDigest, buildDirectory, path.MustNewComponent("hello.txt"), false)
+	cacheDirectory.EXPECT().Mkdir(path.MustNewComponent("b2"), os.FileMode(0o777)).Return(os.ErrExist)
+	subdirectory1 := mock.NewMockDirectoryCloser(ctrl)
+	cacheDirectory.EXPECT().EnterDirectory(path.MustNewComponent("b2")).Return(subdirectory1, nil)
+	subdirectory1.EXPECT().Mkdir(path.MustNewComponent("2b"), os.FileMode(0o777))
+	subdirectory2 := mock.NewMockDirectoryCloser(ctrl)
+	subdirectory1.EXPECT().EnterDirectory(path.MustNewComponent("2b")).Return(subdirectory2, nil)
+	buildDirectory.EXPECT().Link(path.MustNewComponent("hello.txt"), subdirectory2, path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"))
+	subdirectory2.EXPECT().Close()
+	subdirectory1.EXPECT().Close()
+	require.NoError(
+		t,
+		fileFetcher.GetFile(ctx, blobDigest, buildDirectory, path.MustNewComponent("hello.txt"), false))
+
+	// Subsequent requests should hardlink the file from the same
+	// location.
+	subdirectory3 := mock.NewMockDirectoryCloser(ctrl)
+	cacheDirectory.EXPECT().EnterDirectory(path.MustNewComponent("b2")).Return(subdirectory3, nil)
+	subdirectory4 := mock.NewMockDirectoryCloser(ctrl)
+	subdirectory3.EXPECT().EnterDirectory(path.MustNewComponent("2b")).Return(subdirectory4, nil)
+	subdirectory4.EXPECT().Link(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"), buildDirectory, path.MustNewComponent("hello.txt"))
+	subdirectory4.EXPECT().Close()
+	subdirectory3.EXPECT().Close()
+	require.NoError(
+		t,
+		fileFetcher.GetFile(ctx, blobDigest, buildDirectory, path.MustNewComponent("hello.txt"), false))
+}
+
+func TestHardlinkingFileFetcherScrubbing(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseFileFetcher := mock.NewMockFileFetcher(ctrl)
+	cacheDirectory := mock.NewMockDirectory(ctrl)
+	fileFetcher := cas.NewHardlinkingFileFetcher(baseFileFetcher, cacheDirectory, cas.FlatHardlinkingCacheLayout, 10, 1024, eviction.NewLRUSet[string](), 0, nil, clock.SystemClock, "test")
+
+	// Scrubbing an empty cache should be a no-op.
+	require.NoError(t, fileFetcher.ScrubCachedFiles(ctx, 10))
+
+	// Insert a file into the cache.
+	blobDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	buildDirectory := mock.NewMockDirectory(ctrl)
+	baseFileFetcher.EXPECT().GetFile(ctx, blobDigest, buildDirectory, path.MustNewComponent("hello.txt"), false)
+	buildDirectory.EXPECT().Link(path.MustNewComponent("hello.txt"), cacheDirectory, path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"))
+	require.NoError(
+		t,
+		fileFetcher.GetFile(ctx, blobDigest, buildDirectory, path.MustNewComponent("hello.txt"), false))
+
+	// Files whose contents match their digest should be left alone.
+	file1 := mock.NewMockFileReader(ctrl)
+	cacheDirectory.EXPECT().OpenRead(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x")).Return(file1, nil)
+	file1.EXPECT().ReadAt(gomock.Any(), int64(0)).DoAndReturn(func(p []byte, off int64) (int, error) {
+		return copy(p, "Hello"), io.EOF
+	})
+	file1.EXPECT().Close()
+	require.NoError(t, fileFetcher.ScrubCachedFiles(ctx, 1))
+
+	// I/O errors should be logged, but not cause scrubbing to fail.
+	file2 := mock.NewMockFileReader(ctrl)
+	cacheDirectory.EXPECT().OpenRead(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x")).Return(file2, nil)
+	file2.EXPECT().ReadAt(gomock.Any(), int64(0)).Return(0, syscall.EIO)
+	file2.EXPECT().Close()
+	require.NoError(t, fileFetcher.ScrubCachedFiles(ctx, 1))
+
+	// Corrupted files should be removed from the cache directory.
+	file3 := mock.NewMockFileReader(ctrl)
+	cacheDirectory.EXPECT().OpenRead(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x")).Return(file3, nil)
+	file3.EXPECT().ReadAt(gomock.Any(), int64(0)).DoAndReturn(func(p []byte, off int64) (int, error) {
+		return copy(p, "Jello"), io.EOF
+	})
+	file3.EXPECT().Close()
+	cacheDirectory.EXPECT().Remove(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"))
+	require.NoError(t, fileFetcher.ScrubCachedFiles(ctx, 1))
+
+	// Subsequent attempts to fetch the file should cause it to be
+	// downloaded once again.
+	cacheDirectory.EXPECT().Link(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"), buildDirectory, path.MustNewComponent("hello.txt")).
+		Return(syscall.ENOENT)
+	baseFileFetcher.EXPECT().GetFile(ctx, blobDigest, buildDirectory, path.MustNewComponent("hello.txt"), false)
+	buildDirectory.EXPECT().Link(path.MustNewComponent("hello.txt"), cacheDirectory, path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"))
+	require.NoError(
+		t,
+		fileFetcher.GetFile(ctx, blobDigest, buildDirectory, path.MustNewComponent("hello.txt"), false))
+
+	// Files should be hashed without holding any locks, so that
+	// the cache can be modified while large files are verified.
+	// If the file is evicted in the meantime, it should not be
+	// removed a second time.
+	file4 := mock.NewMockFileReader(ctrl)
+	cacheDirectory.EXPECT().OpenRead(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x")).Return(file4, nil)
+	file4.EXPECT().ReadAt(gomock.Any(), int64(0)).DoAndReturn(func(p []byte, off int64) (int, error) {
+		cacheDirectory.EXPECT().Remove(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"))
+		evictedCount, err := fileFetcher.FlushCachedFiles()
+		require.NoError(t, err)
+		require.Equal(t, 1, evictedCount)
+		return copy(p, "Jello"), io.EOF
+	})
+	file4.EXPECT().Close()
+	require.NoError(t, fileFetcher.ScrubCachedFiles(ctx, 1))
+}
+
+func TestHardlinkingFileFetcherIdleEviction(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseFileFetcher := mock.NewMockFileFetcher(ctrl)
+	cacheDirectory := mock.NewMockDirectory(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	fileFetcher := cas.NewHardlinkingFileFetcher(baseFileFetcher, cacheDirectory, cas.FlatHardlinkingCacheLayout, 10, 1024, eviction.NewLRUSet[string](), 0, nil, clock, "test")
+
+	// Evicting files from an empty cache should be a no-op.
+	clock.EXPECT().Now().Return(time.Unix(1000, 0))
+	evictedCount, err := fileFetcher.EvictIdleFiles(time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 0, evictedCount)
+
+	// Insert two files into the cache at different points in time.
+	blobDigest1 := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	blobDigest2 := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "6fc422233a40a75a1f028e11c3cd1140", 7)
+	buildDirectory := mock.NewMockDirectory(ctrl)
+	baseFileFetcher.EXPECT().GetFile(ctx, blobDigest1, buildDirectory, path.MustNewComponent("hello.txt"), false)
+	buildDirectory.EXPECT().Link(path.MustNewComponent("hello.txt"), cacheDirectory, path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"))
+	clock.EXPECT().Now().Return(time.Unix(1010, 0))
+	require.NoError(
+		t,
+		fileFetcher.GetFile(ctx, blobDigest1, buildDirectory, path.MustNewComponent("hello.txt"), false))
+
+	baseFileFetcher.EXPECT().GetFile(ctx, blobDigest2, buildDirectory, path.MustNewComponent("goodbye.txt"), false)
+	buildDirectory.EXPECT().Link(path.MustNewComponent("goodbye.txt"), cacheDirectory, path.MustNewComponent("3-6fc422233a40a75a1f028e11c3cd1140-7-x"))
+	clock.EXPECT().Now().Return(time.Unix(1020, 0))
+	require.NoError(
+		t,
+		fileFetcher.GetFile(ctx, blobDigest2, buildDirectory, path.MustNewComponent("goodbye.txt"), false))
+
+	// Accessing the first file should cause it to no longer be
+	// considered idle.
+	cacheDirectory.EXPECT().Link(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"), buildDirectory, path.MustNewComponent("hello.txt"))
+	clock.EXPECT().Now().Return(time.Unix(1030, 0))
+	require.NoError(
+		t,
+		fileFetcher.GetFile(ctx, blobDigest1, buildDirectory, path.MustNewComponent("hello.txt"), false))
+
+	// Files that have been accessed recently should be left alone.
+	clock.EXPECT().Now().Return(time.Unix(1070, 0))
+	evictedCount, err = fileFetcher.EvictIdleFiles(time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 0, evictedCount)
+
+	// Only the second file has been idle for more than a minute.
+	cacheDirectory.EXPECT().Remove(path.MustNewComponent("3-6fc422233a40a75a1f028e11c3cd1140-7-x"))
+	clock.EXPECT().Now().Return(time.Unix(1085, 0))
+	evictedCount, err = fileFetcher.EvictIdleFiles(time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 1, evictedCount)
+
+	// Errors removing files should be propagated.
+	cacheDirectory.EXPECT().Remove(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x")).
+		Return(syscall.EIO)
+	clock.EXPECT().Now().Return(time.Unix(1100, 0))
+	_, err = fileFetcher.EvictIdleFiles(time.Minute)
+	testutil.RequireEqualStatus(
+		t,
+		status.Error(codes.Internal, "Failed to remove cached file \"3-8b1a9953c4611296a827abf8c47804d7-5-x\": input/output error"),
+		err)
+
+	// Flushing the cache should remove all remaining files,
+	// regardless of when they were accessed.
+	cacheDirectory.EXPECT().Remove(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"))
+	evictedCount, err = fileFetcher.FlushCachedFiles()
+	require.NoError(t, err)
+	require.Equal(t, 1, evictedCount)
+
+	// Subsequent attempts to fetch the file should cause it to be
+	// downloaded once again.
+	baseFileFetcher.EXPECT().GetFile(ctx, blobDigest1, buildDirectory, path.MustNewComponent("hello.txt"), false)
+	buildDirectory.EXPECT().Link(path.MustNewComponent("hello.txt"), cacheDirectory, path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"))
+	clock.EXPECT().Now().Return(time.Unix(1110, 0))
+	require.NoError(
+		t,
+		fileFetcher.GetFile(ctx, blobDigest1, buildDirectory, path.MustNewComponent("hello.txt"), false))
+}
+
+func TestHardlinkingFileFetcherMigration(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseFileFetcher := mock.NewMockFileFetcher(ctrl)
+	cacheDirectory := mock.NewMockDirectory(ctrl)
+	fileFetcher := cas.NewHardlinkingFileFetcher(baseFileFetcher, cacheDirectory, cas.NewFanOutHardlinkingCacheLayout(1), 10, 1024, eviction.NewLRUSet[string](), 0, nil, clock.SystemClock, "test")
+
+	// Files left behind by a previous invocation that used a flat
+	// layout should be moved into their subdirectory. Files with
+	// unrecognized names and directories that become empty should
+	// be removed.
+	cacheDirectory.EXPECT().ReadDir().Return([]filesystem.FileInfo{
+		filesystem.NewFileInfo(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"), filesystem.FileTypeRegularFile, false),
+		filesystem.NewFileInfo(path.MustNewComponent("garbage"), filesystem.FileTypeRegularFile, false),
+		filesystem.NewFileInfo(path.MustNewComponent("old"), filesystem.FileTypeDirectory, false),
+	}, nil)
+	cacheDirectory.EXPECT().Mkdir(path.MustNewComponent("b2"), os.FileMode(0o777))
+	subdirectory1 := mock.NewMockDirectoryCloser(ctrl)
+	cacheDirectory.EXPECT().EnterDirectory(path.MustNewComponent("b2")).Return(subdirectory1, nil)
+	cacheDirectory.EXPECT().Rename(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"), subdirectory1, path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"))
+	subdirectory1.EXPECT().Close()
+	cacheDirectory.EXPECT().Remove(path.MustNewComponent("garbage"))
+	oldDirectory := mock.NewMockDirectoryCloser(ctrl)
+	cacheDirectory.EXPECT().EnterDirectory(path.MustNewComponent("old")).Return(oldDirectory, nil)
+	oldDirectory.EXPECT().ReadDir()
+	oldDirectory.EXPECT().Close()
+	cacheDirectory.EXPECT().Remove(path.MustNewComponent("old"))
+
+	migratedCount, err := fileFetcher.MigrateCachedFiles(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, migratedCount)
+
+	// The migrated file should be hardlinked from its new location
+	// without being downloaded.
+	blobDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	buildDirectory := mock.NewMockDirectory(ctrl)
+	subdirectory2 := mock.NewMockDirectoryCloser(ctrl)
+	cacheDirectory.EXPECT().EnterDirectory(path.MustNewComponent("b2")).Return(subdirectory2, nil)
+	subdirectory2.EXPECT().Link(path.MustNewComponent("3-8b1a9953c4611296a827abf8c47804d7-5-x"), buildDirectory, path.MustNewComponent("hello.txt"))
+	subdirectory2.EXPECT().Close()
+	require.NoError(
+		t,
+		fileFetcher.GetFile(ctx, blobDigest, buildDirectory, path.MustNewComponent("hello.txt"), false))
+}
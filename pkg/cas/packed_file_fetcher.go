@@ -0,0 +1,515 @@
+package cas
+
+import (
+	"context"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sync"
+
+	cas_pb "github.com/buildbarn/bb-remote-execution/pkg/proto/cas"
+	"github.com/buildbarn/bb-storage/pkg/blockdevice"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	packedFileFetcherIndexName          = path.MustNewComponent("index")
+	packedFileFetcherTemporaryIndexName = path.MustNewComponent("index.tmp")
+	// Name of the subdirectory of the cache directory into which
+	// files are exported. As it is not used by any
+	// HardlinkingCacheLayout, exported files never collide with
+	// files that HardlinkingFileFetcher inserts concurrently.
+	packedFileFetcherExportDirectoryName = path.MustNewComponent("packed")
+)
+
+// PackedFileFetcher is a FileFetcher that stores files in a single
+// pack file.
+type PackedFileFetcher interface {
+	FileFetcher
+
+	// FlushCachedFiles removes all files from the cache. It returns
+	// the number of files removed.
+	FlushCachedFiles() (int, error)
+
+	// WriteIndex writes the index of the pack file to the index
+	// directory, so that the contents of the pack file can be
+	// reused after a restart. This method does nothing if no index
+	// directory is provided.
+	WriteIndex() error
+
+	// ImportCachedFiles moves files stored in a cache directory
+	// managed by HardlinkingFileFetcher into the pack file. Files
+	// are removed from the cache directory after being imported,
+	// regardless of whether they fit in the pack file. This allows
+	// switching from HardlinkingFileFetcher to PackedFileFetcher
+	// without discarding the cache. It returns the number of files
+	// imported.
+	ImportCachedFiles(ctx context.Context, cacheDirectory filesystem.Directory) (int, error)
+
+	// ExportCachedFiles copies all files stored in the pack file
+	// into a subdirectory of a cache directory managed by
+	// HardlinkingFileFetcher, from which they can be adopted by
+	// calling HardlinkingFileFetcher.MigrateCachedFiles().
+	// Afterwards, the pack file is flushed and the index is
+	// removed. This allows switching from PackedFileFetcher back to
+	// HardlinkingFileFetcher without discarding the cache. It
+	// returns the number of files exported.
+	ExportCachedFiles(ctx context.Context, cacheDirectory filesystem.Directory) (int, error)
+}
+
+// packedFile contains the bookkeeping of a single file stored in the
+// pack file.
+type packedFile struct {
+	key         string
+	digest      digest.Digest
+	offsetBytes int64
+}
+
+type packedFileFetcher struct {
+	base           FileFetcher
+	packFile       blockdevice.BlockDevice
+	packSizeBytes  int64
+	maxFiles       int
+	indexDirectory filesystem.Directory
+
+	indexLock sync.Mutex
+
+	lock             sync.RWMutex
+	files            map[string]*packedFile
+	queue            []*packedFile
+	writeOffsetBytes int64
+}
+
+// NewPackedFileFetcher is an adapter for FileFetcher that stores the
+// contents of files in a single pack file or block device, using an
+// index that is kept in memory. Compared to HardlinkingFileFetcher,
+// this prevents the creation of millions of small files, which
+// degrades the performance of file systems such as ext4 and XFS. As
+// files cannot be hardlinked out of the pack file, they are copied
+// into the build directory instead.
+//
+// The pack file is written circularly, meaning that files are evicted
+// in the order in which they were inserted. If an index directory is
+// provided, the index is loaded from it, and may be written to it by
+// calling WriteIndex(). This allows the contents of the pack file to
+// be reused after the process restarts.
+func NewPackedFileFetcher(base FileFetcher, packFile blockdevice.BlockDevice, packSizeBytes int64, maxFiles int, indexDirectory filesystem.Directory) (PackedFileFetcher, error) {
+	ff := &packedFileFetcher{
+		base:           base,
+		packFile:       packFile,
+		packSizeBytes:  packSizeBytes,
+		maxFiles:       maxFiles,
+		indexDirectory: indexDirectory,
+
+		files: map[string]*packedFile{},
+	}
+	if indexDirectory != nil {
+		if err := ff.readIndex(); err != nil {
+			return nil, err
+		}
+	}
+	return ff, nil
+}
+
+// readIndex loads the index of the pack file that was written by a
+// previous call to WriteIndex(). Indices that cannot be used are
+// discarded, as that only causes the cache to start out empty.
+func (ff *packedFileFetcher) readIndex() error {
+	r, err := ff.indexDirectory.OpenRead(packedFileFetcherIndexName)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return util.StatusWrapWithCode(err, codes.Internal, "Failed to open index of pack file")
+	}
+	data, err := io.ReadAll(io.NewSectionReader(r, 0, math.MaxInt64))
+	r.Close()
+	if err != nil {
+		return util.StatusWrapWithCode(err, codes.Internal, "Failed to read index of pack file")
+	}
+
+	var index cas_pb.PackedFileCacheIndex
+	if err := proto.Unmarshal(data, &index); err != nil {
+		log.Print("Discarding index of pack file, as it cannot be parsed: ", err)
+		return nil
+	}
+	if index.PackSizeBytes != ff.packSizeBytes {
+		log.Printf("Discarding index of pack file, as it was created for a pack file of size %d, while the pack file has size %d", index.PackSizeBytes, ff.packSizeBytes)
+		return nil
+	}
+	if index.WriteOffsetBytes < 0 || index.WriteOffsetBytes > ff.packSizeBytes {
+		log.Printf("Discarding index of pack file, as it has invalid write offset %d", index.WriteOffsetBytes)
+		return nil
+	}
+
+	// Entries that cannot be parsed are skipped. Entries whose
+	// contents have been overwritten are detected while extracting
+	// them, as extracted files are always validated.
+	for _, entry := range index.Files {
+		digestFunction, err := digest.EmptyInstanceName.GetDigestFunction(entry.DigestFunction, 0)
+		if err != nil {
+			continue
+		}
+		blobDigest, err := digestFunction.NewDigestFromProto(entry.Digest)
+		if err != nil || entry.OffsetBytes < 0 || entry.OffsetBytes > ff.packSizeBytes-blobDigest.GetSizeBytes() {
+			continue
+		}
+		file := &packedFile{
+			key:         blobDigest.GetKey(digest.KeyWithoutInstance),
+			digest:      blobDigest,
+			offsetBytes: entry.OffsetBytes,
+		}
+		ff.files[file.key] = file
+		ff.queue = append(ff.queue, file)
+	}
+	for len(ff.queue) > ff.maxFiles {
+		ff.evictOldestLocked()
+	}
+	ff.writeOffsetBytes = index.WriteOffsetBytes
+	return nil
+}
+
+func (ff *packedFileFetcher) WriteIndex() error {
+	if ff.indexDirectory == nil {
+		return nil
+	}
+	ff.indexLock.Lock()
+	defer ff.indexLock.Unlock()
+
+	// Ensure that the contents of all files referenced by the index
+	// are persisted before the index itself.
+	if err := ff.packFile.Sync(); err != nil {
+		return util.StatusWrapWithCode(err, codes.Internal, "Failed to synchronize pack file")
+	}
+
+	ff.lock.RLock()
+	index := cas_pb.PackedFileCacheIndex{
+		PackSizeBytes:    ff.packSizeBytes,
+		WriteOffsetBytes: ff.writeOffsetBytes,
+		Files:            make([]*cas_pb.PackedFileCacheIndex_File, 0, len(ff.files)),
+	}
+	for _, file := range ff.queue {
+		if ff.files[file.key] == file {
+			index.Files = append(index.Files, &cas_pb.PackedFileCacheIndex_File{
+				DigestFunction: file.digest.GetDigestFunction().GetEnumValue(),
+				Digest:         file.digest.GetProto(),
+				OffsetBytes:    file.offsetBytes,
+			})
+		}
+	}
+	ff.lock.RUnlock()
+	data, err := proto.Marshal(&index)
+	if err != nil {
+		return util.StatusWrapWithCode(err, codes.Internal, "Failed to marshal index of pack file")
+	}
+
+	// Write the index to a temporary file, and rename it, so that a
+	// valid index is present at all times.
+	if err := ff.indexDirectory.Remove(packedFileFetcherTemporaryIndexName); err != nil && !os.IsNotExist(err) {
+		return util.StatusWrapWithCode(err, codes.Internal, "Failed to remove temporary index of pack file")
+	}
+	w, err := ff.indexDirectory.OpenWrite(packedFileFetcherTemporaryIndexName, filesystem.CreateExcl(0o600))
+	if err != nil {
+		return util.StatusWrapWithCode(err, codes.Internal, "Failed to create temporary index of pack file")
+	}
+	if _, err := w.WriteAt(data, 0); err != nil {
+		w.Close()
+		return util.StatusWrapWithCode(err, codes.Internal, "Failed to write temporary index of pack file")
+	}
+	if err := w.Sync(); err != nil {
+		w.Close()
+		return util.StatusWrapWithCode(err, codes.Internal, "Failed to synchronize temporary index of pack file")
+	}
+	if err := w.Close(); err != nil {
+		return util.StatusWrapWithCode(err, codes.Internal, "Failed to close temporary index of pack file")
+	}
+	if err := ff.indexDirectory.Rename(packedFileFetcherTemporaryIndexName, ff.indexDirectory, packedFileFetcherIndexName); err != nil {
+		return util.StatusWrapWithCode(err, codes.Internal, "Failed to rename temporary index of pack file")
+	}
+	if err := ff.indexDirectory.Sync(); err != nil {
+		return util.StatusWrapWithCode(err, codes.Internal, "Failed to synchronize index directory")
+	}
+	return nil
+}
+
+// evictOldestLocked removes the file that was inserted into the pack
+// file least recently from the index. The caller must hold the lock
+// for writing.
+func (ff *packedFileFetcher) evictOldestLocked() {
+	file := ff.queue[0]
+	ff.queue[0] = nil
+	ff.queue = ff.queue[1:]
+	if ff.files[file.key] == file {
+		delete(ff.files, file.key)
+	}
+}
+
+// extractFile copies the contents of a file stored in the pack file to
+// a target location. The contents are validated while copying, so that
+// files that were overwritten after the index was persisted and files
+// that got corrupted on disk are never handed out. It returns false if
+// the contents no longer match.
+func (ff *packedFileFetcher) extractFile(file *packedFile, directory filesystem.Directory, name path.Component, isExecutable bool) (bool, error) {
+	var mode os.FileMode = 0o444
+	if isExecutable {
+		mode = 0o555
+	}
+	w, err := directory.OpenAppend(name, filesystem.CreateExcl(mode))
+	if err != nil {
+		return false, err
+	}
+
+	sizeBytes := file.digest.GetSizeBytes()
+	generator := file.digest.GetDigestFunction().NewGenerator(sizeBytes)
+	_, err = io.Copy(io.MultiWriter(w, generator), io.NewSectionReader(ff.packFile, file.offsetBytes, sizeBytes))
+	w.Close()
+	if err != nil {
+		directory.Remove(name)
+		return false, util.StatusWrapfWithCode(err, codes.Internal, "Failed to copy packed file %#v", file.key)
+	}
+	if generator.Sum() != file.digest {
+		directory.Remove(name)
+		return false, nil
+	}
+	time := filesystem.DeterministicFileModificationTimestamp
+	if err := directory.Chtimes(name, time, time); err != nil {
+		directory.Remove(name)
+		return false, err
+	}
+	return true, nil
+}
+
+// insertFileLocked copies the contents of a file that was just
+// downloaded into the pack file. The caller must hold the lock for
+// writing.
+func (ff *packedFileFetcher) insertFileLocked(key string, blobDigest digest.Digest, directory filesystem.Directory, name path.Component) error {
+	sizeBytes := blobDigest.GetSizeBytes()
+	offsetBytes := ff.writeOffsetBytes
+	if offsetBytes+sizeBytes > ff.packSizeBytes {
+		// Wrap around to the start of the pack file. Files
+		// stored at the end of the pack file are the oldest
+		// ones, so they are discarded first.
+		for len(ff.queue) > 0 && ff.queue[0].offsetBytes >= offsetBytes {
+			ff.evictOldestLocked()
+		}
+		offsetBytes = 0
+	}
+	for len(ff.queue) > 0 && (len(ff.queue) >= ff.maxFiles || (ff.queue[0].offsetBytes >= offsetBytes && ff.queue[0].offsetBytes < offsetBytes+sizeBytes)) {
+		ff.evictOldestLocked()
+	}
+	ff.writeOffsetBytes = offsetBytes + sizeBytes
+
+	r, err := directory.OpenRead(name)
+	if err != nil {
+		return util.StatusWrapfWithCode(err, codes.Internal, "Failed to open file %#v", name.String())
+	}
+	_, err = io.Copy(io.NewOffsetWriter(ff.packFile, offsetBytes), io.NewSectionReader(r, 0, sizeBytes))
+	r.Close()
+	if err != nil {
+		return util.StatusWrapfWithCode(err, codes.Internal, "Failed to add packed file %#v", key)
+	}
+
+	file := &packedFile{
+		key:         key,
+		digest:      blobDigest,
+		offsetBytes: offsetBytes,
+	}
+	ff.files[key] = file
+	ff.queue = append(ff.queue, file)
+	return nil
+}
+
+func (ff *packedFileFetcher) GetFile(ctx context.Context, blobDigest digest.Digest, directory filesystem.Directory, name path.Component, isExecutable bool) error {
+	// The executable bit is only applied to the target location,
+	// so it does not need to be part of the key.
+	key := blobDigest.GetKey(digest.KeyWithoutInstance)
+
+	// If the file is present in the pack file, copy it to the
+	// destination.
+	ff.lock.RLock()
+	if file, ok := ff.files[key]; ok {
+		valid, err := ff.extractFile(file, directory, name, isExecutable)
+		ff.lock.RUnlock()
+		if err != nil {
+			return err
+		} else if valid {
+			return nil
+		}
+
+		log.Printf("Packed file %#v does not match its digest, removing it from the cache", key)
+		ff.lock.Lock()
+		if ff.files[key] == file {
+			delete(ff.files, key)
+		}
+		ff.lock.Unlock()
+	} else {
+		ff.lock.RUnlock()
+	}
+
+	// Download the file at the intended location.
+	if err := ff.base.GetFile(ctx, blobDigest, directory, name, isExecutable); err != nil {
+		return err
+	}
+	if blobDigest.GetSizeBytes() > ff.packSizeBytes {
+		return nil
+	}
+
+	ff.lock.Lock()
+	defer ff.lock.Unlock()
+	if _, ok := ff.files[key]; ok {
+		return nil
+	}
+	return ff.insertFileLocked(key, blobDigest, directory, name)
+}
+
+func (ff *packedFileFetcher) FlushCachedFiles() (int, error) {
+	ff.lock.Lock()
+	defer ff.lock.Unlock()
+
+	evictedCount := len(ff.files)
+	ff.files = map[string]*packedFile{}
+	ff.queue = nil
+	ff.writeOffsetBytes = 0
+	return evictedCount, nil
+}
+
+func (ff *packedFileFetcher) ImportCachedFiles(ctx context.Context, cacheDirectory filesystem.Directory) (int, error) {
+	return ff.importDirectory(ctx, cacheDirectory, nil)
+}
+
+// importDirectory imports all files contained in a directory within
+// the cache directory. Directories are traversed recursively, so that
+// files stored using any HardlinkingCacheLayout are found.
+func (ff *packedFileFetcher) importDirectory(ctx context.Context, d filesystem.Directory, directoryNames []path.Component) (int, error) {
+	entries, err := d.ReadDir()
+	if err != nil {
+		return 0, util.StatusWrapfWithCode(err, codes.Internal, "Failed to read contents of cache subdirectory %#v", formatDirectoryNames(directoryNames))
+	}
+
+	importedCount := 0
+	for _, entry := range entries {
+		if err := util.StatusFromContext(ctx); err != nil {
+			return importedCount, err
+		}
+
+		name := entry.Name()
+		childDirectoryNames := append(directoryNames[:len(directoryNames):len(directoryNames)], name)
+		switch entry.Type() {
+		case filesystem.FileTypeDirectory:
+			child, err := d.EnterDirectory(name)
+			if err != nil {
+				return importedCount, util.StatusWrapfWithCode(err, codes.Internal, "Failed to enter cache subdirectory %#v", formatDirectoryNames(childDirectoryNames))
+			}
+			childImportedCount, err := ff.importDirectory(ctx, child, childDirectoryNames)
+			child.Close()
+			importedCount += childImportedCount
+			if err != nil {
+				return importedCount, err
+			}
+			if err := d.Remove(name); err != nil && !os.IsExist(err) {
+				return importedCount, util.StatusWrapfWithCode(err, codes.Internal, "Failed to remove cache subdirectory %#v", formatDirectoryNames(childDirectoryNames))
+			}
+		case filesystem.FileTypeRegularFile:
+			imported, err := ff.importFile(d, name)
+			if err != nil {
+				return importedCount, err
+			}
+			if imported {
+				importedCount++
+			}
+			if err := d.Remove(name); err != nil && !os.IsNotExist(err) {
+				return importedCount, util.StatusWrapfWithCode(err, codes.Internal, "Failed to remove cached file %#v", formatDirectoryNames(childDirectoryNames))
+			}
+		default:
+			if err := d.Remove(name); err != nil && !os.IsNotExist(err) {
+				return importedCount, util.StatusWrapfWithCode(err, codes.Internal, "Failed to remove unexpected cache entry %#v", formatDirectoryNames(childDirectoryNames))
+			}
+		}
+	}
+	return importedCount, nil
+}
+
+// importFile copies a single file contained in the cache directory
+// into the pack file. Imported files are not validated, as that
+// already happens when they are extracted.
+func (ff *packedFileFetcher) importFile(d filesystem.Directory, name path.Component) (bool, error) {
+	blobDigest, ok := parseCacheKey(name.String())
+	if !ok || blobDigest.GetSizeBytes() > ff.packSizeBytes {
+		return false, nil
+	}
+	key := blobDigest.GetKey(digest.KeyWithoutInstance)
+
+	ff.lock.Lock()
+	defer ff.lock.Unlock()
+	if _, ok := ff.files[key]; ok {
+		// The file was already imported, possibly with a
+		// different executable bit, or downloaded after the
+		// import started.
+		return false, nil
+	}
+	if err := ff.insertFileLocked(key, blobDigest, d, name); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (ff *packedFileFetcher) ExportCachedFiles(ctx context.Context, cacheDirectory filesystem.Directory) (int, error) {
+	if err := cacheDirectory.Mkdir(packedFileFetcherExportDirectoryName, 0o777); err != nil && !os.IsExist(err) {
+		return 0, util.StatusWrapWithCode(err, codes.Internal, "Failed to create export directory")
+	}
+	directory, err := cacheDirectory.EnterDirectory(packedFileFetcherExportDirectoryName)
+	if err != nil {
+		return 0, util.StatusWrapWithCode(err, codes.Internal, "Failed to enter export directory")
+	}
+	defer directory.Close()
+
+	ff.lock.RLock()
+	queue := append([]*packedFile(nil), ff.queue...)
+	ff.lock.RUnlock()
+
+	exportedCount := 0
+	for _, file := range queue {
+		if err := util.StatusFromContext(ctx); err != nil {
+			return exportedCount, err
+		}
+
+		// Files are exported as being non-executable, as the
+		// executable bit is not stored in the pack file.
+		ff.lock.RLock()
+		if ff.files[file.key] != file {
+			ff.lock.RUnlock()
+			continue
+		}
+		name := path.MustNewComponent(file.key + "-x")
+		valid, err := ff.extractFile(file, directory, name, false)
+		ff.lock.RUnlock()
+		if err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			return exportedCount, util.StatusWrapfWithCode(err, codes.Internal, "Failed to export packed file %#v", file.key)
+		}
+		if valid {
+			exportedCount++
+		}
+	}
+
+	if _, err := ff.FlushCachedFiles(); err != nil {
+		return exportedCount, err
+	}
+	if ff.indexDirectory != nil {
+		ff.indexLock.Lock()
+		err := ff.indexDirectory.Remove(packedFileFetcherIndexName)
+		ff.indexLock.Unlock()
+		if err != nil && !os.IsNotExist(err) {
+			return exportedCount, util.StatusWrapWithCode(err, codes.Internal, "Failed to remove index of pack file")
+		}
+	}
+	return exportedCount, nil
+}
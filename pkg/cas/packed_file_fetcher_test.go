@@ -0,0 +1,194 @@
+package cas_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/cas"
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackedFileFetcher(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseFileFetcher := mock.NewMockFileFetcher(ctrl)
+	packFile, err := re_filesystem.InMemoryFilePool.NewFile()
+	require.NoError(t, err)
+	defer packFile.Close()
+	fileFetcher, err := cas.NewPackedFileFetcher(baseFileFetcher, packFile, 8, 10, nil)
+	require.NoError(t, err)
+
+	buildDirectoryPath := t.TempDir()
+	buildDirectory, err := filesystem.NewLocalDirectory(buildDirectoryPath)
+	require.NoError(t, err)
+	defer buildDirectory.Close()
+
+	blobDigest1 := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	blobDigest2 := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "f5a7924e621e84c9280a9a27e1bcb7f6", 5)
+	expectDownload := func(blobDigest digest.Digest, name string, contents string) {
+		baseFileFetcher.EXPECT().GetFile(ctx, blobDigest, buildDirectory, path.MustNewComponent(name), false).
+			DoAndReturn(func(ctx context.Context, blobDigest digest.Digest, directory filesystem.Directory, name path.Component, isExecutable bool) error {
+				return os.WriteFile(filepath.Join(buildDirectoryPath, name.String()), []byte(contents), 0o444)
+			})
+	}
+	requireContents := func(name string, contents string) {
+		actualContents, err := os.ReadFile(filepath.Join(buildDirectoryPath, name))
+		require.NoError(t, err)
+		require.Equal(t, contents, string(actualContents))
+	}
+
+	t.Run("Miss", func(t *testing.T) {
+		// The first time a file is requested, it should be
+		// downloaded and copied into the pack file.
+		expectDownload(blobDigest1, "file1", "Hello")
+		require.NoError(t, fileFetcher.GetFile(ctx, blobDigest1, buildDirectory, path.MustNewComponent("file1"), false))
+		requireContents("file1", "Hello")
+	})
+
+	t.Run("Hit", func(t *testing.T) {
+		// Subsequent requests should be served from the pack
+		// file, regardless of the executable bit.
+		require.NoError(t, fileFetcher.GetFile(ctx, blobDigest1, buildDirectory, path.MustNewComponent("file2"), true))
+		requireContents("file2", "Hello")
+		fileInfo, err := os.Stat(filepath.Join(buildDirectoryPath, "file2"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o555), fileInfo.Mode().Perm())
+	})
+
+	t.Run("Corrupted", func(t *testing.T) {
+		// Files in the pack file whose contents no longer match
+		// their digest should be downloaded once again.
+		_, err := packFile.WriteAt([]byte("J"), 0)
+		require.NoError(t, err)
+		expectDownload(blobDigest1, "file3", "Hello")
+		require.NoError(t, fileFetcher.GetFile(ctx, blobDigest1, buildDirectory, path.MustNewComponent("file3"), false))
+		requireContents("file3", "Hello")
+	})
+
+	t.Run("WrapAround", func(t *testing.T) {
+		// The pack file is only large enough to hold a single
+		// file. Storing another file should cause it to wrap
+		// around, overwriting the file stored previously.
+		expectDownload(blobDigest2, "file4", "World")
+		require.NoError(t, fileFetcher.GetFile(ctx, blobDigest2, buildDirectory, path.MustNewComponent("file4"), false))
+		require.NoError(t, fileFetcher.GetFile(ctx, blobDigest2, buildDirectory, path.MustNewComponent("file5"), false))
+		requireContents("file5", "World")
+
+		expectDownload(blobDigest1, "file6", "Hello")
+		require.NoError(t, fileFetcher.GetFile(ctx, blobDigest1, buildDirectory, path.MustNewComponent("file6"), false))
+		requireContents("file6", "Hello")
+	})
+
+	t.Run("Flush", func(t *testing.T) {
+		evictedCount, err := fileFetcher.FlushCachedFiles()
+		require.NoError(t, err)
+		require.Equal(t, 1, evictedCount)
+
+		expectDownload(blobDigest1, "file7", "Hello")
+		require.NoError(t, fileFetcher.GetFile(ctx, blobDigest1, buildDirectory, path.MustNewComponent("file7"), false))
+	})
+}
+
+func TestPackedFileFetcherMigration(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseFileFetcher := mock.NewMockFileFetcher(ctrl)
+	packFile, err := re_filesystem.InMemoryFilePool.NewFile()
+	require.NoError(t, err)
+	defer packFile.Close()
+	indexDirectoryPath := t.TempDir()
+	indexDirectory, err := filesystem.NewLocalDirectory(indexDirectoryPath)
+	require.NoError(t, err)
+	defer indexDirectory.Close()
+	cacheDirectoryPath := t.TempDir()
+	cacheDirectory, err := filesystem.NewLocalDirectory(cacheDirectoryPath)
+	require.NoError(t, err)
+	defer cacheDirectory.Close()
+	buildDirectoryPath := t.TempDir()
+	buildDirectory, err := filesystem.NewLocalDirectory(buildDirectoryPath)
+	require.NoError(t, err)
+	defer buildDirectory.Close()
+
+	blobDigest1 := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	blobDigest2 := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "f5a7924e621e84c9280a9a27e1bcb7f6", 5)
+	requireContents := func(name string, contents string) {
+		actualContents, err := os.ReadFile(filepath.Join(buildDirectoryPath, name))
+		require.NoError(t, err)
+		require.Equal(t, contents, string(actualContents))
+	}
+
+	// Store a file in the pack file, and persist the index.
+	fileFetcher1, err := cas.NewPackedFileFetcher(baseFileFetcher, packFile, 16, 10, indexDirectory)
+	require.NoError(t, err)
+	baseFileFetcher.EXPECT().GetFile(ctx, blobDigest1, buildDirectory, path.MustNewComponent("file1"), false).
+		DoAndReturn(func(ctx context.Context, blobDigest digest.Digest, directory filesystem.Directory, name path.Component, isExecutable bool) error {
+			return os.WriteFile(filepath.Join(buildDirectoryPath, name.String()), []byte("Hello"), 0o444)
+		})
+	require.NoError(t, fileFetcher1.GetFile(ctx, blobDigest1, buildDirectory, path.MustNewComponent("file1"), false))
+	require.NoError(t, fileFetcher1.WriteIndex())
+
+	// A new instance should load the index, allowing the file to
+	// be extracted without downloading it.
+	fileFetcher2, err := cas.NewPackedFileFetcher(baseFileFetcher, packFile, 16, 10, indexDirectory)
+	require.NoError(t, err)
+	require.NoError(t, fileFetcher2.GetFile(ctx, blobDigest1, buildDirectory, path.MustNewComponent("file2"), false))
+	requireContents("file2", "Hello")
+
+	t.Run("Import", func(t *testing.T) {
+		// Files in the cache directory of a
+		// HardlinkingFileFetcher should be moved into the pack
+		// file, regardless of the layout that was used.
+		// Unrecognized files should be removed.
+		require.NoError(t, os.Mkdir(filepath.Join(cacheDirectoryPath, "ab"), 0o777))
+		require.NoError(t, os.WriteFile(filepath.Join(cacheDirectoryPath, "ab", blobDigest2.GetKey(digest.KeyWithoutInstance)+"+x"), []byte("World"), 0o555))
+		require.NoError(t, os.WriteFile(filepath.Join(cacheDirectoryPath, "garbage"), []byte("Garbage"), 0o444))
+
+		importedCount, err := fileFetcher2.ImportCachedFiles(ctx, cacheDirectory)
+		require.NoError(t, err)
+		require.Equal(t, 1, importedCount)
+		entries, err := os.ReadDir(cacheDirectoryPath)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+
+		require.NoError(t, fileFetcher2.GetFile(ctx, blobDigest2, buildDirectory, path.MustNewComponent("file3"), false))
+		requireContents("file3", "World")
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		// All files should be copied into a subdirectory of the
+		// cache directory, using names that can be adopted by
+		// HardlinkingFileFetcher. The pack file and its index
+		// should be discarded afterwards.
+		exportedCount, err := fileFetcher2.ExportCachedFiles(ctx, cacheDirectory)
+		require.NoError(t, err)
+		require.Equal(t, 2, exportedCount)
+		for _, file := range []struct {
+			digest   digest.Digest
+			contents string
+		}{
+			{blobDigest1, "Hello"},
+			{blobDigest2, "World"},
+		} {
+			contents, err := os.ReadFile(filepath.Join(cacheDirectoryPath, "packed", file.digest.GetKey(digest.KeyWithoutInstance)+"-x"))
+			require.NoError(t, err)
+			require.Equal(t, file.contents, string(contents))
+		}
+		_, err = os.Stat(filepath.Join(indexDirectoryPath, "index"))
+		require.True(t, os.IsNotExist(err))
+
+		baseFileFetcher.EXPECT().GetFile(ctx, blobDigest1, buildDirectory, path.MustNewComponent("file4"), false).
+			DoAndReturn(func(ctx context.Context, blobDigest digest.Digest, directory filesystem.Directory, name path.Component, isExecutable bool) error {
+				return os.WriteFile(filepath.Join(buildDirectoryPath, name.String()), []byte("Hello"), 0o444)
+			})
+		require.NoError(t, fileFetcher2.GetFile(ctx, blobDigest1, buildDirectory, path.MustNewComponent("file4"), false))
+	})
+}
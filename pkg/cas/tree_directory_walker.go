@@ -0,0 +1,71 @@
+package cas
+
+import (
+	"context"
+	"fmt"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+)
+
+type treeDirectoryWalker struct {
+	fetcher    DirectoryFetcher
+	treeDigest digest.Digest
+}
+
+// NewTreeDirectoryWalker creates a DirectoryWalker that assumes that
+// all Directory messages are stored in a Tree object in the Content
+// Addressable Storage (CAS). This is the case for output directories of
+// build actions.
+func NewTreeDirectoryWalker(fetcher DirectoryFetcher, treeDigest digest.Digest) DirectoryWalker {
+	return &treeDirectoryWalker{
+		fetcher:    fetcher,
+		treeDigest: treeDigest,
+	}
+}
+
+func (dw *treeDirectoryWalker) GetDirectory(ctx context.Context) (*remoteexecution.Directory, error) {
+	return dw.fetcher.GetTreeRootDirectory(ctx, dw.treeDigest)
+}
+
+func (dw *treeDirectoryWalker) GetChild(digest digest.Digest) DirectoryWalker {
+	return &treeChildDirectoryWalker{
+		fetcher:     dw.fetcher,
+		treeDigest:  dw.treeDigest,
+		childDigest: digest,
+	}
+}
+
+func (dw *treeDirectoryWalker) GetDescription() string {
+	return fmt.Sprintf("Tree %#v root directory", dw.treeDigest.String())
+}
+
+func (dw *treeDirectoryWalker) GetContainingDigest() digest.Digest {
+	return dw.treeDigest
+}
+
+type treeChildDirectoryWalker struct {
+	fetcher     DirectoryFetcher
+	treeDigest  digest.Digest
+	childDigest digest.Digest
+}
+
+func (dw *treeChildDirectoryWalker) GetDirectory(ctx context.Context) (*remoteexecution.Directory, error) {
+	return dw.fetcher.GetTreeChildDirectory(ctx, dw.treeDigest, dw.childDigest)
+}
+
+func (dw *treeChildDirectoryWalker) GetChild(digest digest.Digest) DirectoryWalker {
+	return &treeChildDirectoryWalker{
+		fetcher:     dw.fetcher,
+		treeDigest:  dw.treeDigest,
+		childDigest: digest,
+	}
+}
+
+func (dw *treeChildDirectoryWalker) GetDescription() string {
+	return fmt.Sprintf("Tree %#v child directory %#v", dw.treeDigest.String(), dw.childDigest.String())
+}
+
+func (dw *treeChildDirectoryWalker) GetContainingDigest() digest.Digest {
+	return dw.treeDigest
+}
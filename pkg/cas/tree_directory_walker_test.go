@@ -0,0 +1,97 @@
+package cas_test
+
+import (
+	"context"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/cas"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTreeDirectoryWalker(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	directoryFetcher := mock.NewMockDirectoryFetcher(ctrl)
+	treeDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "ed56cd683c99acdff14b77db249819fc", 234)
+	rootDirectoryWalker := cas.NewTreeDirectoryWalker(directoryFetcher, treeDigest)
+
+	exampleDirectory := &remoteexecution.Directory{
+		Directories: []*remoteexecution.DirectoryNode{
+			{
+				Name: "foo",
+				Digest: &remoteexecution.Digest{
+					Hash:      "4df5f448a5e6b3c41e6aae7a8a9832aa",
+					SizeBytes: 456,
+				},
+			},
+		},
+	}
+
+	// Test that the DirectoryWalker loads the root directory from
+	// the Tree object, and that error messages use the right prefix.
+
+	t.Run("RootGetDirectorySuccess", func(t *testing.T) {
+		directoryFetcher.EXPECT().GetTreeRootDirectory(ctx, treeDigest).
+			Return(exampleDirectory, nil)
+		rootDirectory, err := rootDirectoryWalker.GetDirectory(ctx)
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, exampleDirectory, rootDirectory)
+	})
+
+	t.Run("RootGetDirectoryFailure", func(t *testing.T) {
+		directoryFetcher.EXPECT().GetTreeRootDirectory(ctx, treeDigest).
+			Return(nil, status.Error(codes.Internal, "Server failure"))
+		_, err := rootDirectoryWalker.GetDirectory(ctx)
+		require.Equal(t, status.Error(codes.Internal, "Server failure"), err)
+	})
+
+	t.Run("RootGetDescription", func(t *testing.T) {
+		require.Equal(
+			t,
+			"Tree \"3-ed56cd683c99acdff14b77db249819fc-234-example\" root directory",
+			rootDirectoryWalker.GetDescription())
+	})
+
+	t.Run("RootGetContainingDigest", func(t *testing.T) {
+		require.Equal(
+			t,
+			treeDigest,
+			rootDirectoryWalker.GetContainingDigest())
+	})
+
+	childDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "4df5f448a5e6b3c41e6aae7a8a9832aa", 456)
+	childDirectoryWalker := rootDirectoryWalker.GetChild(childDigest)
+
+	// Repeat the tests above against a child directory. These
+	// should be loaded from the same Tree object.
+
+	t.Run("ChildGetDirectory", func(t *testing.T) {
+		directoryFetcher.EXPECT().GetTreeChildDirectory(ctx, treeDigest, childDigest).
+			Return(exampleDirectory, nil)
+		childDirectory, err := childDirectoryWalker.GetDirectory(ctx)
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, exampleDirectory, childDirectory)
+	})
+
+	t.Run("ChildGetDescription", func(t *testing.T) {
+		require.Equal(
+			t,
+			"Tree \"3-ed56cd683c99acdff14b77db249819fc-234-example\" child directory \"3-4df5f448a5e6b3c41e6aae7a8a9832aa-456-example\"",
+			childDirectoryWalker.GetDescription())
+	})
+
+	t.Run("ChildGetContainingDigest", func(t *testing.T) {
+		require.Equal(
+			t,
+			treeDigest,
+			childDirectoryWalker.GetContainingDigest())
+	})
+}
@@ -33,7 +33,7 @@ func NewIdleInvoker(f Cleaner) *IdleInvoker {
 	return &IdleInvoker{f: f}
 }
 
-func (i *IdleInvoker) clean(ctx context.Context) error {
+func (i *IdleInvoker) clean(ctx context.Context, f Cleaner) error {
 	if i.wakeup != nil {
 		panic("Cleaning is already in progress")
 	}
@@ -41,7 +41,7 @@ func (i *IdleInvoker) clean(ctx context.Context) error {
 	i.wakeup = wakeup
 
 	i.lock.Unlock()
-	err := i.f(ctx)
+	err := f(ctx)
 	i.lock.Lock()
 
 	close(wakeup)
@@ -70,7 +70,7 @@ func (i *IdleInvoker) Acquire(ctx context.Context) error {
 	// Don't perform cleaning in case we've already been acquired,
 	// as we don't want to cause disruptions.
 	if i.useCount == 0 {
-		if err := i.clean(ctx); err != nil {
+		if err := i.clean(ctx, i.f); err != nil {
 			return err
 		}
 	}
@@ -92,5 +92,21 @@ func (i *IdleInvoker) Release(ctx context.Context) error {
 	if i.useCount > 0 {
 		return nil
 	}
-	return i.clean(ctx)
+	return i.clean(ctx, i.f)
+}
+
+// InvokeIfIdle calls a function, but only if the IdleInvoker is in the
+// idle state and no cleaning is in progress. This can be used to
+// perform background maintenance that should not interfere with build
+// actions. Calls to Acquire() block until the function returns, so it
+// should only perform a bounded amount of work. The return value
+// indicates whether the function was called.
+func (i *IdleInvoker) InvokeIfIdle(ctx context.Context, f Cleaner) (bool, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if i.useCount > 0 || i.wakeup != nil {
+		return false, nil
+	}
+	return true, i.clean(ctx, f)
 }
@@ -94,4 +94,23 @@ func TestIdleInvoker(t *testing.T) {
 		}
 		wg.Wait()
 	})
+
+	t.Run("InvokeIfIdle", func(t *testing.T) {
+		// Functions should only be invoked while idle.
+		otherCleaner := mock.NewMockCleaner(ctrl)
+		otherCleaner.EXPECT().Call(ctx)
+		invoked, err := idleInvoker.InvokeIfIdle(ctx, otherCleaner.Call)
+		require.True(t, invoked)
+		require.NoError(t, err)
+
+		mockCleaner.EXPECT().Call(ctx)
+		require.NoError(t, idleInvoker.Acquire(ctx))
+
+		invoked, err = idleInvoker.InvokeIfIdle(ctx, otherCleaner.Call)
+		require.False(t, invoked)
+		require.NoError(t, err)
+
+		mockCleaner.EXPECT().Call(ctx)
+		require.NoError(t, idleInvoker.Release(ctx))
+	})
 }
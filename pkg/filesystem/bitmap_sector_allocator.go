@@ -10,9 +10,10 @@ import (
 )
 
 type bitmapSectorAllocator struct {
-	lock       sync.Mutex
-	freeBitmap []uint64 // One bits indicate sectors that are free.
-	nextSector uint32
+	lock        sync.Mutex
+	freeBitmap  []uint64 // One bits indicate sectors that are free.
+	nextSector  uint32
+	sectorCount uint32
 }
 
 const (
@@ -35,7 +36,8 @@ func NewBitmapSectorAllocator(sectorCount uint32) SectorAllocator {
 	// permanently in use. This prevents the need for explicit
 	// bounds checking inside our algorithms.
 	sa := &bitmapSectorAllocator{
-		freeBitmap: make([]uint64, sectorCount/64+1),
+		freeBitmap:  make([]uint64, sectorCount/64+1),
+		sectorCount: sectorCount,
 	}
 
 	// Mark the exact number of sectors as being free.
@@ -168,3 +170,14 @@ func (sa *bitmapSectorAllocator) FreeList(sectors []uint32) {
 		}
 	}
 }
+
+func (sa *bitmapSectorAllocator) GetUsage() (uint32, uint32) {
+	sa.lock.Lock()
+	defer sa.lock.Unlock()
+
+	freeSectorCount := 0
+	for _, m := range sa.freeBitmap {
+		freeSectorCount += bits.OnesCount64(m)
+	}
+	return sa.sectorCount, uint32(freeSectorCount)
+}
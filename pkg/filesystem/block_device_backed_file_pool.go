@@ -38,6 +38,14 @@ func (fp *blockDeviceBackedFilePool) NewFile() (filesystem.FileReadWriter, error
 	}, nil
 }
 
+func (fp *blockDeviceBackedFilePool) GetUsage() (FilePoolUsage, error) {
+	sectorCount, freeSectorCount := fp.sectorAllocator.GetUsage()
+	return FilePoolUsage{
+		TotalSizeBytes:     uint64(sectorCount) * uint64(fp.sectorSizeBytes),
+		AvailableSizeBytes: uint64(freeSectorCount) * uint64(fp.sectorSizeBytes),
+	}, nil
+}
+
 type blockDeviceBackedFile struct {
 	fp        *blockDeviceBackedFilePool
 	sizeBytes uint64
@@ -295,6 +303,74 @@ func (f *blockDeviceBackedFile) Truncate(size int64) error {
 	return nil
 }
 
+// freeSectors returns a list of sectors that are no longer referenced
+// by the file to the sector allocator.
+func (f *blockDeviceBackedFile) freeSectors(sectors []uint32) {
+	if len(sectors) > 0 {
+		f.fp.sectorAllocator.FreeList(sectors)
+	}
+}
+
+// PunchHole deallocates all sectors that are fully contained in a
+// range of the file, returning them to the sector allocator. Parts of
+// the range that only cover sectors partially are overwritten with
+// zeroes.
+func (f *blockDeviceBackedFile) PunchHole(off, size int64) error {
+	if off < 0 || size < 0 {
+		return status.Errorf(codes.InvalidArgument, "Invalid range with offset %d and size %d", off, size)
+	}
+	end := uint64(off) + uint64(size)
+	if end > f.sizeBytes {
+		end = f.sizeBytes
+	}
+
+	// Trailing bytes in the last sector past the end of the file
+	// are always zero. A range that extends up to the end of the
+	// file may thus deallocate the last sector entirely.
+	sectorSizeBytes := uint64(f.fp.sectorSizeBytes)
+	fullSectorsEnd := end
+	if end == f.sizeBytes {
+		fullSectorsEnd = (end + sectorSizeBytes - 1) / sectorSizeBytes * sectorSizeBytes
+	}
+
+	var sectorsToFree []uint32
+	for position := uint64(off); position < end; {
+		sectorIndex := int(position / sectorSizeBytes)
+		if sectorIndex >= len(f.sectors) {
+			break
+		}
+		offsetWithinSector := position % sectorSizeBytes
+		nextPosition := (uint64(sectorIndex) + 1) * sectorSizeBytes
+		if sector := f.sectors[sectorIndex]; sector != 0 {
+			if offsetWithinSector == 0 && nextPosition <= fullSectorsEnd {
+				// Sector is fully contained in the range.
+				sectorsToFree = append(sectorsToFree, sector)
+				f.sectors[sectorIndex] = 0
+			} else {
+				// Sector is only partially contained in the
+				// range. Zero the part that is contained.
+				zeroes := f.fp.zeroSector[offsetWithinSector:]
+				if remaining := end - position; uint64(len(zeroes)) > remaining {
+					zeroes = zeroes[:remaining]
+				}
+				if _, err := f.fp.blockDevice.WriteAt(zeroes, f.toDeviceOffset(sector, int(offsetWithinSector))); err != nil {
+					f.freeSectors(sectorsToFree)
+					return err
+				}
+			}
+		}
+		position = nextPosition
+	}
+	f.freeSectors(sectorsToFree)
+
+	// Ensure that no hole remains at the end, for the same reason
+	// as in truncateSectors().
+	for len(f.sectors) > 0 && f.sectors[len(f.sectors)-1] == 0 {
+		f.sectors = f.sectors[:len(f.sectors)-1]
+	}
+	return nil
+}
+
 // writeToNewSectors is used to write data into new sectors. This
 // function is called when holes in a sparse file are filled up or when
 // data is appended to the end of a file.
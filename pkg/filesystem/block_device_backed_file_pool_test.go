@@ -170,6 +170,38 @@ func TestBlockDeviceBackedFilePool(t *testing.T) {
 		require.NoError(t, f.Close())
 	})
 
+	t.Run("PunchHole", func(t *testing.T) {
+		f, err := pool.NewFile()
+		require.NoError(t, err)
+		hpf := f.(re_filesystem.HolePunchingFileReadWriter)
+
+		require.Equal(t, status.Error(codes.InvalidArgument, "Invalid range with offset -1 and size 10"), hpf.PunchHole(-1, 10))
+
+		sectorAllocator.EXPECT().AllocateContiguous(3).Return(uint32(10), 3, nil)
+		blockDevice.EXPECT().WriteAt([]byte("Lorem ipsum dolor sit amet, consectetur adipisci"), int64(144)).Return(48, nil)
+		n, err := f.WriteAt([]byte("Lorem ipsum dolor sit amet, consectetur adipisci"), 0)
+		require.Equal(t, 48, n)
+		require.NoError(t, err)
+
+		// Punching a hole that partially covers the first
+		// sector should cause it to be zeroed. Sectors that are
+		// fully covered should be released, and the file should
+		// no longer reference them.
+		blockDevice.EXPECT().WriteAt(make([]byte, 8), int64(152))
+		sectorAllocator.EXPECT().FreeList([]uint32{11, 12})
+		require.NoError(t, hpf.PunchHole(8, 1000))
+
+		// The size of the file should remain unaltered.
+		var p [20]byte
+		n, err = f.ReadAt(p[:], 28)
+		require.Equal(t, 20, n)
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, make([]byte, 20), p[:])
+
+		sectorAllocator.EXPECT().FreeList([]uint32{10})
+		require.NoError(t, f.Close())
+	})
+
 	t.Run("WriteSectorAllocatorFailure", func(t *testing.T) {
 		f, err := pool.NewFile()
 		require.NoError(t, err)
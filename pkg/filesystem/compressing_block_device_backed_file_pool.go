@@ -0,0 +1,608 @@
+package filesystem
+
+import (
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/buildbarn/bb-storage/pkg/blockdevice"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/klauspost/compress/zstd"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	compressingBlockDeviceBackedFilePoolPrometheusMetrics sync.Once
+
+	compressingBlockDeviceBackedFilePoolFileCompressionRatio = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "buildbarn",
+			Subsystem: "filesystem",
+			Name:      "compressing_block_device_backed_file_pool_file_compression_ratio",
+			Help:      "Ratio between the size of the chunks of data of a file and the amount of space used to store them, computed when the file is closed.",
+			Buckets:   prometheus.ExponentialBuckets(1.0, 2.0, 8),
+		})
+	compressingBlockDeviceBackedFilePoolChunksStored = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "buildbarn",
+			Subsystem: "filesystem",
+			Name:      "compressing_block_device_backed_file_pool_chunks_stored_total",
+			Help:      "Number of chunks of data stored on the block device, partitioned by how they were stored.",
+		},
+		[]string{"representation"})
+	compressingBlockDeviceBackedFilePoolChunksStoredCompressed   = compressingBlockDeviceBackedFilePoolChunksStored.WithLabelValues("Compressed")
+	compressingBlockDeviceBackedFilePoolChunksStoredUncompressed = compressingBlockDeviceBackedFilePoolChunksStored.WithLabelValues("Uncompressed")
+	compressingBlockDeviceBackedFilePoolChunksStoredZero         = compressingBlockDeviceBackedFilePoolChunksStored.WithLabelValues("Zero")
+)
+
+type compressingBlockDeviceBackedFilePool struct {
+	blockDevice     blockdevice.BlockDevice
+	sectorAllocator SectorAllocator
+	sectorSizeBytes int
+	chunkSizeBytes  int
+	encoder         *zstd.Encoder
+	decoder         *zstd.Decoder
+}
+
+// NewCompressingBlockDeviceBackedFilePool creates a FilePool that
+// stores all temporary file contents on a block device, similar to
+// NewBlockDeviceBackedFilePool(). The difference is that files are
+// partitioned into fixed size chunks, each of which is compressed
+// using Zstandard before being written to the block device. As build
+// outputs tend to be highly compressible, this increases the effective
+// capacity of the block device at the cost of additional CPU usage.
+//
+// Chunks are compressed independently. To prevent small writes from
+// causing the same chunk to be decompressed and compressed repeatedly,
+// every file buffers the chunk that was most recently written to
+// partially in uncompressed form. This chunk is only compressed once it
+// is filled up, or when another chunk is written to partially. Chunks
+// that consist exclusively of zero bytes are not stored at all, while
+// chunks that cannot be compressed are stored as is.
+func NewCompressingBlockDeviceBackedFilePool(blockDevice blockdevice.BlockDevice, sectorAllocator SectorAllocator, sectorSizeBytes, chunkSizeBytes int, encoderLevel zstd.EncoderLevel) (FilePool, error) {
+	if chunkSizeBytes <= 0 || chunkSizeBytes%sectorSizeBytes != 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Chunk size of %d bytes is not a positive multiple of the sector size of %d bytes", chunkSizeBytes, sectorSizeBytes)
+	}
+	// Files may be written to from many goroutines concurrently.
+	// EncodeAll() and DecodeAll() may be called concurrently, each
+	// call claiming one of the encoder or decoder states. Provide
+	// as many states as there are CPUs, so that compression isn't
+	// serialized.
+	encoder, err := zstd.NewWriter(
+		nil,
+		zstd.WithEncoderLevel(encoderLevel),
+		zstd.WithEncoderConcurrency(runtime.GOMAXPROCS(0)),
+		zstd.WithZeroFrames(true))
+	if err != nil {
+		return nil, util.StatusWrapWithCode(err, codes.InvalidArgument, "Failed to create Zstandard encoder")
+	}
+	decoder, err := zstd.NewReader(
+		nil,
+		zstd.WithDecoderConcurrency(0),
+		zstd.WithDecoderMaxMemory(uint64(chunkSizeBytes)))
+	if err != nil {
+		return nil, util.StatusWrapWithCode(err, codes.InvalidArgument, "Failed to create Zstandard decoder")
+	}
+
+	compressingBlockDeviceBackedFilePoolPrometheusMetrics.Do(func() {
+		prometheus.MustRegister(compressingBlockDeviceBackedFilePoolFileCompressionRatio)
+		prometheus.MustRegister(compressingBlockDeviceBackedFilePoolChunksStored)
+	})
+
+	return &compressingBlockDeviceBackedFilePool{
+		blockDevice:     blockDevice,
+		sectorAllocator: sectorAllocator,
+		sectorSizeBytes: sectorSizeBytes,
+		chunkSizeBytes:  chunkSizeBytes,
+		encoder:         encoder,
+		decoder:         decoder,
+	}, nil
+}
+
+func (fp *compressingBlockDeviceBackedFilePool) NewFile() (filesystem.FileReadWriter, error) {
+	return &compressingBlockDeviceBackedFile{
+		fp: fp,
+	}, nil
+}
+
+func (fp *compressingBlockDeviceBackedFilePool) GetUsage() (FilePoolUsage, error) {
+	// Report the raw capacity of the block device. Due to
+	// compression, the amount of data that can actually be stored
+	// is likely larger.
+	sectorCount, freeSectorCount := fp.sectorAllocator.GetUsage()
+	return FilePoolUsage{
+		TotalSizeBytes:     uint64(sectorCount) * uint64(fp.sectorSizeBytes),
+		AvailableSizeBytes: uint64(freeSectorCount) * uint64(fp.sectorSizeBytes),
+	}, nil
+}
+
+// compressedChunk contains the locations of the sectors on the block
+// device in which a single chunk of a file is stored.
+type compressedChunk struct {
+	// Sectors containing the data of the chunk. This list is empty
+	// if the chunk consists exclusively of zero bytes.
+	sectors []uint32
+	// The number of bytes of data stored in the sectors. If equal
+	// to the chunk size, the chunk is stored uncompressed.
+	storedSizeBytes int
+}
+
+type compressingBlockDeviceBackedFile struct {
+	fp        *compressingBlockDeviceBackedFilePool
+	sizeBytes uint64
+	chunks    []compressedChunk
+
+	// The chunk that was most recently written to partially, in
+	// uncompressed form. Bytes past the end of dirtyChunk are zero.
+	// The buffer is grown as needed, so that small files don't
+	// need a full chunk of memory. dirtyChunk is nil if no chunk
+	// is buffered.
+	dirtyChunkIndex int
+	dirtyChunk      []byte
+
+	// Statistics for computing the compression ratio of the file.
+	dataChunksCount  int
+	usedSectorsCount int
+}
+
+func (f *compressingBlockDeviceBackedFile) Close() error {
+	if f.usedSectorsCount > 0 {
+		compressingBlockDeviceBackedFilePoolFileCompressionRatio.Observe(
+			float64(f.dataChunksCount*f.fp.chunkSizeBytes) / float64(f.usedSectorsCount*f.fp.sectorSizeBytes))
+	}
+	for i := range f.chunks {
+		f.freeChunk(i)
+	}
+	f.fp = nil
+	f.chunks = nil
+	f.dirtyChunk = nil
+	return nil
+}
+
+// flushDirtyChunk compresses the chunk that is buffered in
+// uncompressed form, and writes it to the block device.
+func (f *compressingBlockDeviceBackedFile) flushDirtyChunk() error {
+	if f.dirtyChunk == nil {
+		return nil
+	}
+	chunk := f.dirtyChunk
+	if chunkSizeBytes := f.fp.chunkSizeBytes; len(chunk) < chunkSizeBytes {
+		if cap(chunk) >= chunkSizeBytes {
+			chunk = chunk[:chunkSizeBytes]
+			clear(chunk[len(f.dirtyChunk):])
+		} else {
+			chunk = make([]byte, chunkSizeBytes)
+			copy(chunk, f.dirtyChunk)
+		}
+	}
+	if err := f.storeChunk(f.dirtyChunkIndex, chunk); err != nil {
+		return err
+	}
+	f.dirtyChunk = nil
+	f.trimTrailingHoles()
+	return nil
+}
+
+// writeDirtyChunk writes data into part of a chunk, by buffering it in
+// uncompressed form.
+func (f *compressingBlockDeviceBackedFile) writeDirtyChunk(chunkIndex, offsetWithinChunk int, p []byte) (int, error) {
+	chunkSizeBytes := f.fp.chunkSizeBytes
+	if f.dirtyChunk == nil || f.dirtyChunkIndex != chunkIndex {
+		if err := f.flushDirtyChunk(); err != nil {
+			return 0, err
+		}
+		if chunkIndex < len(f.chunks) && len(f.chunks[chunkIndex].sectors) > 0 {
+			// Chunk contains data. Decompress it, so that
+			// it can be modified.
+			chunk := make([]byte, chunkSizeBytes)
+			if err := f.loadChunk(chunkIndex, chunk); err != nil {
+				return 0, err
+			}
+			f.dirtyChunk = chunk
+		} else {
+			f.dirtyChunk = []byte{}
+		}
+		f.dirtyChunkIndex = chunkIndex
+	}
+
+	n := len(p)
+	if n > chunkSizeBytes-offsetWithinChunk {
+		n = chunkSizeBytes - offsetWithinChunk
+	}
+	if end := offsetWithinChunk + n; len(f.dirtyChunk) < end {
+		f.dirtyChunk = append(f.dirtyChunk, make([]byte, end-len(f.dirtyChunk))...)
+	}
+	copy(f.dirtyChunk[offsetWithinChunk:], p[:n])
+
+	// Compress the chunk as soon as it has been written up to its
+	// end, as is the case for files that are written sequentially.
+	// If this fails, the data remains buffered.
+	if offsetWithinChunk+n == chunkSizeBytes {
+		return n, f.flushDirtyChunk()
+	}
+	return n, nil
+}
+
+// freeChunk releases the sectors that are used to store a chunk,
+// thereby turning it into a hole.
+func (f *compressingBlockDeviceBackedFile) freeChunk(chunkIndex int) {
+	chunk := &f.chunks[chunkIndex]
+	if len(chunk.sectors) > 0 {
+		f.fp.sectorAllocator.FreeList(chunk.sectors)
+		f.dataChunksCount--
+		f.usedSectorsCount -= len(chunk.sectors)
+	}
+	*chunk = compressedChunk{}
+}
+
+// trimTrailingHoles removes chunks at the end of the file that don't
+// contain any data. This ensures that the file never ends with a hole,
+// which simplifies GetNextRegionOffset().
+func (f *compressingBlockDeviceBackedFile) trimTrailingHoles() {
+	for len(f.chunks) > 0 && len(f.chunks[len(f.chunks)-1].sectors) == 0 {
+		f.chunks = f.chunks[:len(f.chunks)-1]
+	}
+}
+
+// toDeviceOffset converts a sector number to a byte offset on the
+// block device.
+func (f *compressingBlockDeviceBackedFile) toDeviceOffset(sector uint32) int64 {
+	return int64(sector-1) * int64(f.fp.sectorSizeBytes)
+}
+
+// forEachContiguousSectors splits up a list of sectors into ranges of
+// sectors that are stored contiguously, so that they can be accessed
+// using a single operation against the block device.
+func forEachContiguousSectors(sectors []uint32, sectorSizeBytes int, buf []byte, fn func(p []byte, firstSector uint32) error) error {
+	for len(sectors) > 0 {
+		n := 1
+		for n < len(sectors) && uint64(sectors[n]) == uint64(sectors[0])+uint64(n) {
+			n++
+		}
+		if err := fn(buf[:n*sectorSizeBytes], sectors[0]); err != nil {
+			return err
+		}
+		buf = buf[n*sectorSizeBytes:]
+		sectors = sectors[n:]
+	}
+	return nil
+}
+
+// loadChunk reads the contents of a single chunk of the file from the
+// block device and decompresses it into the provided buffer, which
+// must be exactly one chunk in size.
+func (f *compressingBlockDeviceBackedFile) loadChunk(chunkIndex int, p []byte) error {
+	if f.dirtyChunk != nil && f.dirtyChunkIndex == chunkIndex {
+		// Chunk is buffered in uncompressed form.
+		clear(p[copy(p, f.dirtyChunk):])
+		return nil
+	}
+	if chunkIndex >= len(f.chunks) || len(f.chunks[chunkIndex].sectors) == 0 {
+		// Chunk consists exclusively of zero bytes.
+		for i := range p {
+			p[i] = 0
+		}
+		return nil
+	}
+
+	chunk := &f.chunks[chunkIndex]
+	sectorSizeBytes := f.fp.sectorSizeBytes
+	stored := make([]byte, len(chunk.sectors)*sectorSizeBytes)
+	if err := forEachContiguousSectors(chunk.sectors, sectorSizeBytes, stored, func(p []byte, firstSector uint32) error {
+		n, err := f.fp.blockDevice.ReadAt(p, f.toDeviceOffset(firstSector))
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if n != len(p) {
+			return status.Errorf(codes.Internal, "Read against block device returned %d bytes, while %d bytes were expected", n, len(p))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	stored = stored[:chunk.storedSizeBytes]
+
+	if chunk.storedSizeBytes == f.fp.chunkSizeBytes {
+		// Chunk is stored uncompressed.
+		copy(p, stored)
+		return nil
+	}
+	decompressed, err := f.fp.decoder.DecodeAll(stored, p[:0:len(p)])
+	if err != nil {
+		return util.StatusWrapWithCode(err, codes.Internal, "Failed to decompress chunk")
+	}
+	if len(decompressed) != len(p) {
+		return status.Errorf(codes.Internal, "Decompressed chunk is %d bytes in size, while %d bytes were expected", len(decompressed), len(p))
+	}
+	copy(p, decompressed)
+	return nil
+}
+
+func isZero(p []byte) bool {
+	for _, b := range p {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// storeChunk compresses the contents of a single chunk and writes it
+// to newly allocated sectors on the block device. The sectors that
+// were used to store the previous contents of the chunk are only
+// released upon success, so that the file remains intact in case of
+// failures.
+func (f *compressingBlockDeviceBackedFile) storeChunk(chunkIndex int, p []byte) error {
+	if chunkIndex >= len(f.chunks) {
+		f.chunks = append(f.chunks, make([]compressedChunk, chunkIndex+1-len(f.chunks))...)
+	}
+	if isZero(p) {
+		// Chunk only contains zero bytes. There is no need to
+		// store it, as it can be represented as a hole.
+		f.freeChunk(chunkIndex)
+		compressingBlockDeviceBackedFilePoolChunksStoredZero.Inc()
+		return nil
+	}
+
+	// Only store the compressed version of the chunk if it uses
+	// fewer sectors than the uncompressed version.
+	sectorSizeBytes := f.fp.sectorSizeBytes
+	data := f.fp.encoder.EncodeAll(p, make([]byte, 0, len(p)))
+	if len(data) > len(p)-sectorSizeBytes {
+		data = p
+		compressingBlockDeviceBackedFilePoolChunksStoredUncompressed.Inc()
+	} else {
+		compressingBlockDeviceBackedFilePoolChunksStoredCompressed.Inc()
+	}
+
+	// Allocate space for the chunk, which may be fragmented.
+	sectorsNeeded := (len(data) + sectorSizeBytes - 1) / sectorSizeBytes
+	sectors := make([]uint32, 0, sectorsNeeded)
+	for len(sectors) < sectorsNeeded {
+		firstSector, sectorsAllocated, err := f.fp.sectorAllocator.AllocateContiguous(sectorsNeeded - len(sectors))
+		if err != nil {
+			if len(sectors) > 0 {
+				f.fp.sectorAllocator.FreeList(sectors)
+			}
+			return err
+		}
+		for i := 0; i < sectorsAllocated; i++ {
+			sectors = append(sectors, firstSector+uint32(i))
+		}
+	}
+
+	// Write the chunk to the block device, padded with zero bytes
+	// to the sector boundary.
+	buf := make([]byte, sectorsNeeded*sectorSizeBytes)
+	copy(buf, data)
+	if err := forEachContiguousSectors(sectors, sectorSizeBytes, buf, func(p []byte, firstSector uint32) error {
+		_, err := f.fp.blockDevice.WriteAt(p, f.toDeviceOffset(firstSector))
+		return err
+	}); err != nil {
+		f.fp.sectorAllocator.FreeList(sectors)
+		return err
+	}
+
+	f.freeChunk(chunkIndex)
+	f.chunks[chunkIndex] = compressedChunk{
+		sectors:         sectors,
+		storedSizeBytes: len(data),
+	}
+	f.dataChunksCount++
+	f.usedSectorsCount += len(sectors)
+	return nil
+}
+
+func (f *compressingBlockDeviceBackedFile) GetNextRegionOffset(off int64, regionType filesystem.RegionType) (int64, error) {
+	// Short circuit calls that are out of bounds.
+	if off < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "Negative seek offset: %d", off)
+	}
+	if uint64(off) >= f.sizeBytes {
+		return 0, io.EOF
+	}
+
+	// The chunk that is buffered in uncompressed form takes
+	// precedence over what is stored on the block device.
+	dirtyChunkHasData := f.dirtyChunk != nil && !isZero(f.dirtyChunk)
+	chunkHasData := func(chunkIndex int) bool {
+		if f.dirtyChunk != nil && f.dirtyChunkIndex == chunkIndex {
+			return dirtyChunkHasData
+		}
+		return chunkIndex < len(f.chunks) && len(f.chunks[chunkIndex].sectors) > 0
+	}
+	chunksCount := len(f.chunks)
+	if dirtyChunkHasData && f.dirtyChunkIndex >= chunksCount {
+		chunksCount = f.dirtyChunkIndex + 1
+	}
+
+	chunkSizeBytes := int64(f.fp.chunkSizeBytes)
+	chunkIndex := int(off / chunkSizeBytes)
+	switch regionType {
+	case filesystem.Data:
+		// Find the next chunk containing data.
+		for ; chunkIndex < chunksCount; chunkIndex++ {
+			if chunkHasData(chunkIndex) {
+				if nextOffset := int64(chunkIndex) * chunkSizeBytes; nextOffset > off {
+					return nextOffset, nil
+				}
+				return off, nil
+			}
+		}
+		// Inside the hole at the end of the file.
+		return 0, io.EOF
+	case filesystem.Hole:
+		// Find the next chunk containing a hole.
+		for ; chunkIndex < chunksCount; chunkIndex++ {
+			if !chunkHasData(chunkIndex) {
+				if nextOffset := int64(chunkIndex) * chunkSizeBytes; nextOffset > off {
+					return nextOffset, nil
+				}
+				return off, nil
+			}
+		}
+		if allChunks := int64(chunksCount) * chunkSizeBytes; allChunks < int64(f.sizeBytes) {
+			// File ends with a hole.
+			if allChunks > off {
+				return allChunks, nil
+			}
+			return off, nil
+		}
+		// File ends in the middle of a chunk containing data.
+		return int64(f.sizeBytes), nil
+	default:
+		panic("Unknown region type")
+	}
+}
+
+func (f *compressingBlockDeviceBackedFile) ReadAt(p []byte, off int64) (int, error) {
+	// Short circuit calls that are out of bounds.
+	if off < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "Negative read offset: %d", off)
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// Limit the read operation to the size of the file. Already
+	// determine whether this operation will return nil or io.EOF.
+	if uint64(off) >= f.sizeBytes {
+		return 0, io.EOF
+	}
+	var success error
+	if end := uint64(off) + uint64(len(p)); end >= f.sizeBytes {
+		success = io.EOF
+		p = p[:f.sizeBytes-uint64(off)]
+	}
+
+	// Decompress every chunk overlapping with the read. Chunks that
+	// are read entirely can be decompressed into the output buffer
+	// directly.
+	chunkSizeBytes := f.fp.chunkSizeBytes
+	var scratch []byte
+	nTotal := 0
+	for len(p) > 0 {
+		chunkIndex := int(off / int64(chunkSizeBytes))
+		offsetWithinChunk := int(off % int64(chunkSizeBytes))
+		var n int
+		if offsetWithinChunk == 0 && len(p) >= chunkSizeBytes {
+			if err := f.loadChunk(chunkIndex, p[:chunkSizeBytes]); err != nil {
+				return nTotal, err
+			}
+			n = chunkSizeBytes
+		} else {
+			if scratch == nil {
+				scratch = make([]byte, chunkSizeBytes)
+			}
+			if err := f.loadChunk(chunkIndex, scratch); err != nil {
+				return nTotal, err
+			}
+			n = copy(p, scratch[offsetWithinChunk:])
+		}
+		nTotal += n
+		p = p[n:]
+		off += int64(n)
+	}
+	return nTotal, success
+}
+
+func (f *compressingBlockDeviceBackedFile) Sync() error {
+	// Because FilePool does not provide any persistency, there is
+	// no need to synchronize any data.
+	return nil
+}
+
+func (f *compressingBlockDeviceBackedFile) Truncate(size int64) error {
+	if size < 0 {
+		return status.Errorf(codes.InvalidArgument, "Negative truncation size: %d", size)
+	}
+	if err := f.flushDirtyChunk(); err != nil {
+		return err
+	}
+
+	chunkSizeBytes := f.fp.chunkSizeBytes
+	chunkIndex := int(size / int64(chunkSizeBytes))
+	offsetWithinChunk := int(size % int64(chunkSizeBytes))
+	if uint64(size) < f.sizeBytes && offsetWithinChunk > 0 && chunkIndex < len(f.chunks) && len(f.chunks[chunkIndex].sectors) > 0 {
+		// The file is being shrunk and the new last chunk is
+		// not a hole. Zero the trailing part of the last chunk
+		// to ensure that growing the file later on doesn't
+		// bring back old data.
+		chunk := make([]byte, chunkSizeBytes)
+		if err := f.loadChunk(chunkIndex, chunk); err != nil {
+			return err
+		}
+		for i := offsetWithinChunk; i < chunkSizeBytes; i++ {
+			chunk[i] = 0
+		}
+		if err := f.storeChunk(chunkIndex, chunk); err != nil {
+			return err
+		}
+	}
+
+	// Release all chunks past the end of the file.
+	if offsetWithinChunk > 0 {
+		chunkIndex++
+	}
+	for i := chunkIndex; i < len(f.chunks); i++ {
+		f.freeChunk(i)
+	}
+	if len(f.chunks) > chunkIndex {
+		f.chunks = f.chunks[:chunkIndex]
+	}
+	f.trimTrailingHoles()
+
+	f.sizeBytes = uint64(size)
+	return nil
+}
+
+func (f *compressingBlockDeviceBackedFile) WriteAt(p []byte, off int64) (int, error) {
+	// Short circuit calls that are out of bounds.
+	if off < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "Negative write offset: %d", off)
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// Compress every chunk overlapping with the write. Chunks that
+	// are overwritten entirely can be compressed directly. Other
+	// chunks are buffered in uncompressed form.
+	chunkSizeBytes := f.fp.chunkSizeBytes
+	nTotal := 0
+	var err error
+	for len(p) > 0 {
+		chunkIndex := int(off / int64(chunkSizeBytes))
+		offsetWithinChunk := int(off % int64(chunkSizeBytes))
+		var n int
+		if offsetWithinChunk == 0 && len(p) >= chunkSizeBytes {
+			if err = f.storeChunk(chunkIndex, p[:chunkSizeBytes]); err == nil {
+				n = chunkSizeBytes
+				if f.dirtyChunk != nil && f.dirtyChunkIndex == chunkIndex {
+					// Buffered contents have been
+					// overwritten entirely.
+					f.dirtyChunk = nil
+				}
+			}
+		} else {
+			n, err = f.writeDirtyChunk(chunkIndex, offsetWithinChunk, p)
+		}
+		nTotal += n
+		p = p[n:]
+		off += int64(n)
+		if err != nil {
+			break
+		}
+	}
+	f.trimTrailingHoles()
+
+	// Adjust file size if needed.
+	if newSize := uint64(off); nTotal > 0 && f.sizeBytes < newSize {
+		f.sizeBytes = newSize
+	}
+	return nTotal, err
+}
@@ -0,0 +1,190 @@
+package filesystem_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/golang/mock/gomock"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCompressingBlockDeviceBackedFilePool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	// Let the block device be backed by a byte slice, so that
+	// tests can validate that data is stored in compressed form.
+	const sectorSizeBytes = 512
+	const sectorCount = 64
+	const chunkSizeBytes = 4096
+	storage := make([]byte, sectorSizeBytes*sectorCount)
+	blockDevice := mock.NewMockBlockDevice(ctrl)
+	blockDevice.EXPECT().ReadAt(gomock.Any(), gomock.Any()).DoAndReturn(func(p []byte, off int64) (int, error) {
+		return copy(p, storage[off:]), nil
+	}).AnyTimes()
+	blockDevice.EXPECT().WriteAt(gomock.Any(), gomock.Any()).DoAndReturn(func(p []byte, off int64) (int, error) {
+		return copy(storage[off:], p), nil
+	}).AnyTimes()
+	sectorAllocator := re_filesystem.NewBitmapSectorAllocator(sectorCount)
+
+	t.Run("InvalidChunkSize", func(t *testing.T) {
+		_, err := re_filesystem.NewCompressingBlockDeviceBackedFilePool(blockDevice, sectorAllocator, sectorSizeBytes, 1000, zstd.SpeedDefault)
+		require.Equal(t, status.Error(codes.InvalidArgument, "Chunk size of 1000 bytes is not a positive multiple of the sector size of 512 bytes"), err)
+	})
+
+	pool, err := re_filesystem.NewCompressingBlockDeviceBackedFilePool(blockDevice, sectorAllocator, sectorSizeBytes, chunkSizeBytes, zstd.SpeedDefault)
+	require.NoError(t, err)
+
+	t.Run("CompressibleData", func(t *testing.T) {
+		f, err := pool.NewFile()
+		require.NoError(t, err)
+
+		// Write 64 KiB of highly compressible data to the file,
+		// using a mixture of aligned and unaligned writes. This
+		// would not fit on the block device uncompressed.
+		data := bytes.Repeat([]byte("Hello world! "), 6000)[:64*1024]
+		n, err := f.WriteAt(data[:100], 0)
+		require.Equal(t, 100, n)
+		require.NoError(t, err)
+		n, err = f.WriteAt(data[100:5000], 100)
+		require.Equal(t, 4900, n)
+		require.NoError(t, err)
+		n, err = f.WriteAt(data[5000:], 5000)
+		require.Equal(t, len(data)-5000, n)
+		require.NoError(t, err)
+
+		// Reading the data back should yield the original
+		// contents, regardless of alignment.
+		p := make([]byte, len(data)+10)
+		n, err = f.ReadAt(p, 0)
+		require.Equal(t, len(data), n)
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, data, p[:n])
+
+		n, err = f.ReadAt(p[:10], 4090)
+		require.Equal(t, 10, n)
+		require.NoError(t, err)
+		require.Equal(t, data[4090:4100], p[:10])
+
+		// Shrinking the file to the middle of a chunk should
+		// cause the remainder of the chunk to be zeroed.
+		require.NoError(t, f.Truncate(5000))
+		require.NoError(t, f.Truncate(10000))
+		n, err = f.ReadAt(p[:10000], 0)
+		require.Equal(t, 10000, n)
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, data[:5000], p[:5000])
+		require.Equal(t, make([]byte, 5000), p[5000:10000])
+
+		require.NoError(t, f.Close())
+	})
+
+	t.Run("BufferedWrites", func(t *testing.T) {
+		f, err := pool.NewFile()
+		require.NoError(t, err)
+
+		// Small writes should be buffered in uncompressed form,
+		// meaning that no space is allocated on the block
+		// device until the chunk has been written entirely.
+		usage1, err := re_filesystem.GetFilePoolUsage(pool)
+		require.NoError(t, err)
+		data := bytes.Repeat([]byte("Hello world! "), 400)[:chunkSizeBytes]
+		for off := 0; off < chunkSizeBytes-100; off += 100 {
+			n, err := f.WriteAt(data[off:off+100], int64(off))
+			require.Equal(t, 100, n)
+			require.NoError(t, err)
+		}
+		usage2, err := re_filesystem.GetFilePoolUsage(pool)
+		require.NoError(t, err)
+		require.Equal(t, usage1, usage2)
+
+		// Buffered data should be visible to readers.
+		p := make([]byte, chunkSizeBytes)
+		n, err := f.ReadAt(p, 0)
+		require.Equal(t, chunkSizeBytes/100*100, n)
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, data[:n], p[:n])
+		off, err := f.GetNextRegionOffset(0, filesystem.Data)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), off)
+
+		// Completing the chunk should cause it to be compressed
+		// and written to the block device.
+		n, err = f.WriteAt(data[chunkSizeBytes/100*100:], chunkSizeBytes/100*100)
+		require.Equal(t, chunkSizeBytes%100, n)
+		require.NoError(t, err)
+		usage3, err := re_filesystem.GetFilePoolUsage(pool)
+		require.NoError(t, err)
+		require.Less(t, usage3.AvailableSizeBytes, usage1.AvailableSizeBytes)
+
+		n, err = f.ReadAt(p, 0)
+		require.Equal(t, chunkSizeBytes, n)
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, data, p)
+
+		require.NoError(t, f.Close())
+	})
+
+	t.Run("IncompressibleData", func(t *testing.T) {
+		f, err := pool.NewFile()
+		require.NoError(t, err)
+
+		// Data that cannot be compressed should be stored as
+		// is. It should still be possible to read it back.
+		data := make([]byte, chunkSizeBytes)
+		x := uint32(1)
+		for i := range data {
+			x ^= x << 13
+			x ^= x >> 17
+			x ^= x << 5
+			data[i] = byte(x)
+		}
+		n, err := f.WriteAt(data, chunkSizeBytes)
+		require.Equal(t, chunkSizeBytes, n)
+		require.NoError(t, err)
+
+		p := make([]byte, 2*chunkSizeBytes)
+		n, err = f.ReadAt(p, 0)
+		require.Equal(t, 2*chunkSizeBytes, n)
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, make([]byte, chunkSizeBytes), p[:chunkSizeBytes])
+		require.Equal(t, data, p[chunkSizeBytes:])
+
+		require.NoError(t, f.Close())
+	})
+
+	t.Run("Holes", func(t *testing.T) {
+		f, err := pool.NewFile()
+		require.NoError(t, err)
+
+		// Chunks that are not written, or only contain zero
+		// bytes, should be reported as holes.
+		n, err := f.WriteAt([]byte("Hello"), 2*chunkSizeBytes+10)
+		require.Equal(t, 5, n)
+		require.NoError(t, err)
+		n, err = f.WriteAt(make([]byte, 10), 3*chunkSizeBytes)
+		require.Equal(t, 10, n)
+		require.NoError(t, err)
+
+		off, err := f.GetNextRegionOffset(0, filesystem.Data)
+		require.NoError(t, err)
+		require.Equal(t, int64(2*chunkSizeBytes), off)
+		off, err = f.GetNextRegionOffset(0, filesystem.Hole)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), off)
+		off, err = f.GetNextRegionOffset(2*chunkSizeBytes+1, filesystem.Hole)
+		require.NoError(t, err)
+		require.Equal(t, int64(3*chunkSizeBytes), off)
+		_, err = f.GetNextRegionOffset(3*chunkSizeBytes, filesystem.Data)
+		require.Equal(t, io.EOF, err)
+
+		require.NoError(t, f.Close())
+	})
+}
@@ -1,12 +1,16 @@
 package filesystem
 
 import (
+	"crypto/rand"
 	"math"
+	"os"
 
 	pb "github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/blockdevice"
+	"github.com/buildbarn/bb-storage/pkg/clock"
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/klauspost/compress/zstd"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -35,7 +39,7 @@ func NewFilePoolFromConfiguration(configuration *pb.FilePoolConfiguration) (File
 			directory.Close()
 			return nil, util.StatusWrapf(err, "Failed to empty out directory %#v", backend.DirectoryPath)
 		}
-		filePool = NewDirectoryBackedFilePool(directory)
+		filePool = NewDirectoryBackedFilePool(directory, backend.DirectoryPath)
 	case *pb.FilePoolConfiguration_BlockDevice:
 		blockDevice, sectorSizeBytes, sectorCount, err := blockdevice.NewBlockDeviceFromConfiguration(backend.BlockDevice, true)
 		if err != nil {
@@ -44,12 +48,79 @@ func NewFilePoolFromConfiguration(configuration *pb.FilePoolConfiguration) (File
 		if sectorCount > math.MaxUint32 {
 			return nil, util.StatusWrapf(err, "Block device has %d sectors, while only %d may be addressed", sectorCount, uint32(math.MaxUint32))
 		}
-		filePool = NewBlockDeviceBackedFilePool(
-			blockDevice,
-			NewBitmapSectorAllocator(uint32(sectorCount)),
-			sectorSizeBytes)
+		sectorAllocator := NewBitmapSectorAllocator(uint32(sectorCount))
+		if compressionConfiguration := configuration.BlockDeviceCompression; compressionConfiguration != nil {
+			encoderLevel := zstd.SpeedDefault
+			if level := compressionConfiguration.Level; level != 0 {
+				encoderLevel = zstd.EncoderLevelFromZstd(int(level))
+			}
+			filePool, err = NewCompressingBlockDeviceBackedFilePool(
+				blockDevice,
+				sectorAllocator,
+				sectorSizeBytes,
+				int(compressionConfiguration.ChunkSizeBytes),
+				encoderLevel)
+			if err != nil {
+				return nil, util.StatusWrap(err, "Failed to create compressing file pool")
+			}
+		} else {
+			filePool = NewBlockDeviceBackedFilePool(
+				blockDevice,
+				sectorAllocator,
+				sectorSizeBytes)
+		}
 	default:
 		return nil, status.Error(codes.InvalidArgument, "Configuration did not contain a supported file pool backend")
 	}
-	return NewMetricsFilePool(filePool), nil
+	if configuration.BlockDeviceCompression != nil && configuration.GetBlockDevice() == nil {
+		return nil, status.Error(codes.InvalidArgument, "Compression is only supported in combination with the block device backend")
+	}
+
+	if encryptionConfiguration := configuration.Encryption; encryptionConfiguration != nil {
+		var key []byte
+		switch keyConfiguration := encryptionConfiguration.Key.(type) {
+		case *pb.EncryptionConfiguration_KeyPath:
+			var err error
+			key, err = os.ReadFile(keyConfiguration.KeyPath)
+			if err != nil {
+				return nil, util.StatusWrapf(err, "Failed to read encryption key from %#v", keyConfiguration.KeyPath)
+			}
+		case *pb.EncryptionConfiguration_EphemeralKey:
+			key = make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return nil, util.StatusWrap(err, "Failed to generate ephemeral encryption key")
+			}
+		default:
+			return nil, status.Error(codes.InvalidArgument, "Encryption configuration did not contain a supported key")
+		}
+		var err error
+		filePool, err = NewEncryptingFilePool(filePool, key)
+		if err != nil {
+			return nil, util.StatusWrap(err, "Failed to create encrypting file pool")
+		}
+	}
+	if memoryTierSizeBytes := configuration.MemoryTierSizeBytes; memoryTierSizeBytes > 0 {
+		if configuration.GetInMemory() != nil {
+			return nil, status.Error(codes.InvalidArgument, "A memory tier cannot be used in combination with the in-memory backend")
+		}
+		filePool = NewTieredFilePool(InMemoryFilePool, filePool, memoryTierSizeBytes)
+	}
+	filePool = NewMetricsFilePool(filePool)
+
+	if retryConfiguration := configuration.TransientErrorRetry; retryConfiguration != nil {
+		if err := retryConfiguration.InitialBackoff.CheckValid(); err != nil {
+			return nil, util.StatusWrap(err, "Invalid initial backoff")
+		}
+		if err := retryConfiguration.MaximumBackoff.CheckValid(); err != nil {
+			return nil, util.StatusWrap(err, "Invalid maximum backoff")
+		}
+		filePool = NewRetryingFilePool(
+			filePool,
+			clock.SystemClock,
+			int(retryConfiguration.MaximumAttempts),
+			retryConfiguration.InitialBackoff.AsDuration(),
+			retryConfiguration.MaximumBackoff.AsDuration(),
+			int(retryConfiguration.MaximumConsecutiveFailures))
+	}
+	return filePool, nil
 }
@@ -3,15 +3,21 @@ package filesystem
 import (
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"sync/atomic"
 
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type directoryBackedFilePool struct {
-	directory filesystem.Directory
+	directory     filesystem.Directory
+	directoryPath string
 
 	nextID atomic.Uint64
 }
@@ -24,37 +30,55 @@ type directoryBackedFilePool struct {
 // does not keep any backing files open. This would exhaust the worker's
 // file descriptor table. Files are opened on demand.
 //
+// The path of the directory is used to deallocate storage when holes
+// are punched into files, and to report the capacity of the file
+// system containing the directory, as filesystem.Directory provides no
+// way to do so. If the path is empty or the operating system does not
+// support punching holes, ranges are overwritten with zeroes instead.
+//
 // TODO: Maybe use an eviction.Set to keep a small number of files open?
-func NewDirectoryBackedFilePool(directory filesystem.Directory) FilePool {
+func NewDirectoryBackedFilePool(directory filesystem.Directory, directoryPath string) FilePool {
 	return &directoryBackedFilePool{
-		directory: directory,
+		directory:     directory,
+		directoryPath: directoryPath,
 	}
 }
 
 func (fp *directoryBackedFilePool) NewFile() (filesystem.FileReadWriter, error) {
 	return &lazyOpeningSelfDeletingFile{
-		directory: fp.directory,
-		name:      path.MustNewComponent(strconv.FormatUint(fp.nextID.Add(1), 10)),
+		pool: fp,
+		name: path.MustNewComponent(strconv.FormatUint(fp.nextID.Add(1), 10)),
 	}, nil
 }
 
+func (fp *directoryBackedFilePool) GetUsage() (FilePoolUsage, error) {
+	if fp.directoryPath == "" {
+		return FilePoolUsage{}, status.Error(codes.Unimplemented, "File pool is not capable of reporting its usage, as the path of its directory is unknown")
+	}
+	usage, err := getDiskUsage(fp.directoryPath)
+	if err != nil {
+		return FilePoolUsage{}, util.StatusWrapf(err, "Failed to obtain capacity of file system containing directory %#v", fp.directoryPath)
+	}
+	return usage, nil
+}
+
 // lazyOpeningSelfDeletingFile is a file descriptor that forwards
 // operations to a file that is opened on demand. Upon closure, the
 // underlying file is unlinked.
 type lazyOpeningSelfDeletingFile struct {
-	directory filesystem.Directory
-	name      path.Component
+	pool *directoryBackedFilePool
+	name path.Component
 }
 
 func (f *lazyOpeningSelfDeletingFile) Close() error {
-	if err := f.directory.Remove(f.name); err != nil && !os.IsNotExist(err) {
+	if err := f.pool.directory.Remove(f.name); err != nil && !os.IsNotExist(err) {
 		return err
 	}
 	return nil
 }
 
 func (f *lazyOpeningSelfDeletingFile) GetNextRegionOffset(off int64, regionType filesystem.RegionType) (int64, error) {
-	fh, err := f.directory.OpenRead(f.name)
+	fh, err := f.pool.directory.OpenRead(f.name)
 	if os.IsNotExist(err) {
 		// Empty file that doesn't explicitly exist in the
 		// backing store yet. Treat it as if it's a zero-length
@@ -68,7 +92,7 @@ func (f *lazyOpeningSelfDeletingFile) GetNextRegionOffset(off int64, regionType
 }
 
 func (f *lazyOpeningSelfDeletingFile) ReadAt(p []byte, off int64) (int, error) {
-	fh, err := f.directory.OpenRead(f.name)
+	fh, err := f.pool.directory.OpenRead(f.name)
 	if os.IsNotExist(err) {
 		// Empty file that doesn't explicitly exist in the
 		// backing store yet. Treat it as if it's a zero-length
@@ -88,7 +112,7 @@ func (f *lazyOpeningSelfDeletingFile) Sync() error {
 }
 
 func (f *lazyOpeningSelfDeletingFile) Truncate(size int64) error {
-	fh, err := f.directory.OpenWrite(f.name, filesystem.CreateReuse(0o600))
+	fh, err := f.pool.directory.OpenWrite(f.name, filesystem.CreateReuse(0o600))
 	if err != nil {
 		return err
 	}
@@ -97,10 +121,27 @@ func (f *lazyOpeningSelfDeletingFile) Truncate(size int64) error {
 }
 
 func (f *lazyOpeningSelfDeletingFile) WriteAt(p []byte, off int64) (int, error) {
-	fh, err := f.directory.OpenWrite(f.name, filesystem.CreateReuse(0o600))
+	fh, err := f.pool.directory.OpenWrite(f.name, filesystem.CreateReuse(0o600))
 	if err != nil {
 		return 0, err
 	}
 	defer fh.Close()
 	return fh.WriteAt(p, off)
 }
+
+func (f *lazyOpeningSelfDeletingFile) PunchHole(off, size int64) error {
+	if size == 0 {
+		return nil
+	}
+	if f.pool.directoryPath != "" {
+		err := punchHole(filepath.Join(f.pool.directoryPath, f.name.String()), off, size)
+		if os.IsNotExist(err) {
+			// Empty file that doesn't explicitly exist in the
+			// backing store yet. There is nothing to deallocate.
+			return nil
+		} else if status.Code(err) != codes.Unimplemented {
+			return err
+		}
+	}
+	return zeroDataRegions(f, off, size)
+}
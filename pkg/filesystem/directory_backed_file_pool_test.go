@@ -2,6 +2,7 @@ package filesystem_test
 
 import (
 	"io"
+	"runtime"
 	"syscall"
 	"testing"
 
@@ -11,13 +12,16 @@ import (
 	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestDirectoryBackedFilePool(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
 	directory := mock.NewMockDirectory(ctrl)
-	fp := re_filesystem.NewDirectoryBackedFilePool(directory)
+	fp := re_filesystem.NewDirectoryBackedFilePool(directory, "")
 
 	t.Run("EmptyFile", func(t *testing.T) {
 		f, err := fp.NewFile()
@@ -93,3 +97,63 @@ func TestDirectoryBackedFilePool(t *testing.T) {
 		require.NoError(t, f.Close())
 	})
 }
+
+func TestDirectoryBackedFilePoolPunchHole(t *testing.T) {
+	directoryPath := t.TempDir()
+	directory, err := filesystem.NewLocalDirectory(directoryPath)
+	require.NoError(t, err)
+	defer directory.Close()
+	fp := re_filesystem.NewDirectoryBackedFilePool(directory, directoryPath)
+
+	f, err := fp.NewFile()
+	require.NoError(t, err)
+	defer f.Close()
+
+	// Punching holes in a file that does not exist in the backing
+	// store yet should be a no-op.
+	require.NoError(t, re_filesystem.PunchHole(f, 0, 0))
+
+	// Punching a hole should cause the range to read back as
+	// zeroes, without altering the size of the file.
+	n, err := f.WriteAt([]byte("Hello, world"), 0)
+	require.NoError(t, err)
+	require.Equal(t, 12, n)
+	require.NoError(t, re_filesystem.PunchHole(f, 2, 5))
+
+	var p [13]byte
+	n, err = f.ReadAt(p[:], 0)
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, 12, n)
+	require.Equal(t, []byte("He\x00\x00\x00\x00\x00world"), p[:n])
+}
+
+func TestDirectoryBackedFilePoolGetUsage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	t.Run("NoPath", func(t *testing.T) {
+		// Without knowing the path of the directory, the
+		// capacity of the file system cannot be obtained.
+		fp := re_filesystem.NewDirectoryBackedFilePool(mock.NewMockDirectory(ctrl), "")
+		_, err := re_filesystem.GetFilePoolUsage(fp)
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		if runtime.GOOS != "linux" {
+			return
+		}
+
+		// The capacity of the file system containing the
+		// directory should be reported.
+		directoryPath := t.TempDir()
+		directory, err := filesystem.NewLocalDirectory(directoryPath)
+		require.NoError(t, err)
+		defer directory.Close()
+		fp := re_filesystem.NewDirectoryBackedFilePool(directory, directoryPath)
+
+		usage, err := re_filesystem.GetFilePoolUsage(fp)
+		require.NoError(t, err)
+		require.Greater(t, usage.TotalSizeBytes, uint64(0))
+		require.LessOrEqual(t, usage.AvailableSizeBytes, usage.TotalSizeBytes)
+	})
+}
@@ -13,6 +13,11 @@ func (fp emptyFilePool) NewFile() (filesystem.FileReadWriter, error) {
 	return nil, status.Error(codes.ResourceExhausted, "Cannot create file in empty file pool")
 }
 
+func (fp emptyFilePool) GetUsage() (FilePoolUsage, error) {
+	// No space is available, and no files may be created.
+	return FilePoolUsage{}, nil
+}
+
 // EmptyFilePool is a FilePool that does not permit the creation of new
 // files. It is used as the default FilePool for the root of the
 // worker's FUSE file system to disallow the creation of files not bound
@@ -13,4 +13,9 @@ import (
 func TestEmptyFilePool(t *testing.T) {
 	_, err := filesystem.EmptyFilePool.NewFile()
 	require.Equal(t, err, status.Error(codes.ResourceExhausted, "Cannot create file in empty file pool"))
+
+	// The pool should report that it has no capacity.
+	usage, err := filesystem.GetFilePoolUsage(filesystem.EmptyFilePool)
+	require.NoError(t, err)
+	require.Equal(t, filesystem.FilePoolUsage{}, usage)
 }
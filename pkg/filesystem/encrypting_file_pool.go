@@ -0,0 +1,411 @@
+package filesystem
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// encryptedBlockSizeBytes is the size of the blocks into which
+	// files are partitioned, each of which is encrypted separately.
+	// As ChaCha20 is a stream cipher, the ciphertext of a block
+	// has the same size as its plaintext. Blocks thus remain
+	// aligned to sectors in the underlying FilePool.
+	encryptedBlockSizeBytes = 4096
+
+	// minimumEncryptionKeySizeBytes is the minimum size of the key
+	// from which keys of individual files are derived.
+	minimumEncryptionKeySizeBytes = 16
+)
+
+// encryptingFileKeyInfo is provided to HKDF to derive the keys of
+// individual files, so that the derived keys are bound to this
+// purpose.
+var encryptingFileKeyInfo = []byte("bb-remote-execution encrypting file pool")
+
+type encryptingFilePool struct {
+	base FilePool
+	key  []byte
+}
+
+// NewEncryptingFilePool creates a decorator for FilePool that encrypts
+// the contents of files before they are written to the underlying
+// FilePool. This ensures that data spilled to local disks is never
+// stored in plaintext.
+//
+// For every file a separate key is derived from the provided key using
+// HKDF-SHA256 and a random salt. Files are partitioned into blocks of
+// 4 KiB, each of which is encrypted separately using
+// ChaCha20-Poly1305. Nonces are obtained from a counter that is
+// incremented every time a block is written, which is safe because
+// keys are never shared between files. The index of the block is
+// provided as additional authenticated data, so that blocks cannot be
+// moved within files without being detected.
+//
+// Nonces and authentication tags are kept in memory, as opposed to
+// being stored in the underlying FilePool. This causes the underlying
+// file to have the same layout as the plaintext, and permits
+// distinguishing blocks that have never been written from ones that
+// have been overwritten with zero bytes by a third party. The memory
+// overhead of this is less than 1% of the size of the data stored.
+func NewEncryptingFilePool(base FilePool, key []byte) (FilePool, error) {
+	if len(key) < minimumEncryptionKeySizeBytes {
+		return nil, status.Errorf(codes.InvalidArgument, "Encryption key is %d bytes in size, while at least %d bytes are required", len(key), minimumEncryptionKeySizeBytes)
+	}
+	return &encryptingFilePool{
+		base: base,
+		key:  append([]byte(nil), key...),
+	}, nil
+}
+
+func (fp *encryptingFilePool) GetUsage() (FilePoolUsage, error) {
+	return GetFilePoolUsage(fp.base)
+}
+
+func (fp *encryptingFilePool) NewFile() (filesystem.FileReadWriter, error) {
+	var salt [32]byte
+	if _, err := rand.Read(salt[:]); err != nil {
+		return nil, util.StatusWrapWithCode(err, codes.Internal, "Failed to generate salt")
+	}
+	var fileKey [chacha20poly1305.KeySize]byte
+	if _, err := io.ReadFull(hkdf.New(sha256.New, fp.key, salt[:], encryptingFileKeyInfo), fileKey[:]); err != nil {
+		return nil, util.StatusWrapWithCode(err, codes.Internal, "Failed to derive file key")
+	}
+	aead, err := chacha20poly1305.New(fileKey[:])
+	if err != nil {
+		return nil, util.StatusWrapWithCode(err, codes.Internal, "Failed to create ChaCha20-Poly1305 cipher")
+	}
+
+	base, err := fp.base.NewFile()
+	if err != nil {
+		return nil, err
+	}
+	return &encryptingFile{
+		FileReadWriter: base,
+		aead:           aead,
+		blocks:         map[int64]encryptedBlock{},
+	}, nil
+}
+
+// encryptedBlock contains the state of a single block of a file that
+// is needed to decrypt and authenticate it.
+type encryptedBlock struct {
+	nonceCounter uint64
+	tag          [chacha20poly1305.Overhead]byte
+}
+
+type encryptingFile struct {
+	filesystem.FileReadWriter
+	aead         cipher.AEAD
+	sizeBytes    uint64
+	nonceCounter uint64
+
+	// Blocks that have been written. Blocks that are absent have
+	// never been written, and read back as zero bytes.
+	blocks map[int64]encryptedBlock
+}
+
+func (f *encryptingFile) Close() error {
+	f.blocks = nil
+	return f.FileReadWriter.Close()
+}
+
+func getEncryptedBlockNonce(nonceCounter uint64) []byte {
+	var nonce [chacha20poly1305.NonceSize]byte
+	binary.BigEndian.PutUint64(nonce[chacha20poly1305.NonceSize-8:], nonceCounter)
+	return nonce[:]
+}
+
+func getEncryptedBlockAdditionalData(blockIndex int64) []byte {
+	var additionalData [8]byte
+	binary.BigEndian.PutUint64(additionalData[:], uint64(blockIndex))
+	return additionalData[:]
+}
+
+// readBlock reads a single block from the underlying file and decrypts
+// it into the provided buffer, which must be exactly one block in size.
+func (f *encryptingFile) readBlock(blockIndex int64, p []byte) error {
+	block, ok := f.blocks[blockIndex]
+	if !ok {
+		// Block has never been written.
+		clear(p)
+		return nil
+	}
+
+	stored := make([]byte, encryptedBlockSizeBytes, encryptedBlockSizeBytes+chacha20poly1305.Overhead)
+	n, err := f.FileReadWriter.ReadAt(stored, blockIndex*encryptedBlockSizeBytes)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if n != encryptedBlockSizeBytes {
+		return status.Errorf(codes.Internal, "Block at index %d is %d bytes in size, while %d bytes were expected", blockIndex, n, encryptedBlockSizeBytes)
+	}
+	stored = append(stored, block.tag[:]...)
+	if _, err := f.aead.Open(p[:0], getEncryptedBlockNonce(block.nonceCounter), stored, getEncryptedBlockAdditionalData(blockIndex)); err != nil {
+		return util.StatusWrapfWithCode(err, codes.Internal, "Failed to decrypt block at index %d", blockIndex)
+	}
+	return nil
+}
+
+// writeBlock encrypts a single block, which must be exactly one block
+// in size, and writes it to the underlying file.
+func (f *encryptingFile) writeBlock(blockIndex int64, p []byte) error {
+	f.nonceCounter++
+	block := encryptedBlock{nonceCounter: f.nonceCounter}
+	stored := f.aead.Seal(
+		make([]byte, 0, encryptedBlockSizeBytes+chacha20poly1305.Overhead),
+		getEncryptedBlockNonce(block.nonceCounter),
+		p,
+		getEncryptedBlockAdditionalData(blockIndex))
+	copy(block.tag[:], stored[encryptedBlockSizeBytes:])
+	if _, err := f.FileReadWriter.WriteAt(stored[:encryptedBlockSizeBytes], blockIndex*encryptedBlockSizeBytes); err != nil {
+		// The block may have been overwritten partially.
+		// Prevent it from being decrypted successfully.
+		delete(f.blocks, blockIndex)
+		return err
+	}
+	f.blocks[blockIndex] = block
+	return nil
+}
+
+func (f *encryptingFile) GetNextRegionOffset(off int64, regionType filesystem.RegionType) (int64, error) {
+	// Short circuit calls that are out of bounds.
+	if off < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "Negative seek offset: %d", off)
+	}
+	if uint64(off) >= f.sizeBytes {
+		return 0, io.EOF
+	}
+
+	// Finding holes would require scanning the list of blocks.
+	// Report the file as not being sparse.
+	switch regionType {
+	case filesystem.Data:
+		return off, nil
+	case filesystem.Hole:
+		return int64(f.sizeBytes), nil
+	default:
+		panic("Unknown region type")
+	}
+}
+
+func (f *encryptingFile) ReadAt(p []byte, off int64) (int, error) {
+	// Short circuit calls that are out of bounds.
+	if off < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "Negative read offset: %d", off)
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// Limit the read operation to the size of the file. Already
+	// determine whether this operation will return nil or io.EOF.
+	if uint64(off) >= f.sizeBytes {
+		return 0, io.EOF
+	}
+	var success error
+	if end := uint64(off) + uint64(len(p)); end >= f.sizeBytes {
+		success = io.EOF
+		p = p[:f.sizeBytes-uint64(off)]
+	}
+
+	// Decrypt every block overlapping with the read. Blocks that
+	// are read entirely can be decrypted into the output buffer
+	// directly.
+	var scratch []byte
+	nTotal := 0
+	for len(p) > 0 {
+		blockIndex := off / encryptedBlockSizeBytes
+		offsetWithinBlock := int(off % encryptedBlockSizeBytes)
+		var n int
+		if offsetWithinBlock == 0 && len(p) >= encryptedBlockSizeBytes {
+			if err := f.readBlock(blockIndex, p[:encryptedBlockSizeBytes]); err != nil {
+				return nTotal, err
+			}
+			n = encryptedBlockSizeBytes
+		} else {
+			if scratch == nil {
+				scratch = make([]byte, encryptedBlockSizeBytes)
+			}
+			if err := f.readBlock(blockIndex, scratch); err != nil {
+				return nTotal, err
+			}
+			n = copy(p, scratch[offsetWithinBlock:])
+		}
+		nTotal += n
+		p = p[n:]
+		off += int64(n)
+	}
+	return nTotal, success
+}
+
+// zeroPartialBlock overwrites part of a block with zero bytes. Blocks
+// that have never been written are left untouched, as they already
+// read back as zero bytes.
+func (f *encryptingFile) zeroPartialBlock(blockIndex int64, start, end int) error {
+	if _, ok := f.blocks[blockIndex]; !ok {
+		return nil
+	}
+	block := make([]byte, encryptedBlockSizeBytes)
+	if err := f.readBlock(blockIndex, block); err != nil {
+		return err
+	}
+	clear(block[start:end])
+	return f.writeBlock(blockIndex, block)
+}
+
+func (f *encryptingFile) PunchHole(off, size int64) error {
+	if off < 0 || size < 0 {
+		return status.Errorf(codes.InvalidArgument, "Invalid range with offset %d and size %d", off, size)
+	}
+	end := off + size
+	if end <= off {
+		return nil
+	}
+
+	// Zero the parts of blocks at the edges of the range that are
+	// not covered entirely.
+	firstBlock := off / encryptedBlockSizeBytes
+	lastBlock := (end - 1) / encryptedBlockSizeBytes
+	startWithinBlock := int(off % encryptedBlockSizeBytes)
+	endWithinBlock := int((end-1)%encryptedBlockSizeBytes) + 1
+	if firstBlock == lastBlock {
+		if startWithinBlock != 0 || endWithinBlock != encryptedBlockSizeBytes {
+			return f.zeroPartialBlock(firstBlock, startWithinBlock, endWithinBlock)
+		}
+	} else {
+		if startWithinBlock != 0 {
+			if err := f.zeroPartialBlock(firstBlock, startWithinBlock, encryptedBlockSizeBytes); err != nil {
+				return err
+			}
+			firstBlock++
+		}
+		if endWithinBlock != encryptedBlockSizeBytes {
+			if err := f.zeroPartialBlock(lastBlock, 0, endWithinBlock); err != nil {
+				return err
+			}
+			lastBlock--
+		}
+	}
+
+	// Forget about the blocks that are covered entirely, and
+	// deallocate them in the underlying file.
+	if firstBlock > lastBlock {
+		return nil
+	}
+	if blockCount := lastBlock - firstBlock + 1; blockCount < int64(len(f.blocks)) {
+		for i := firstBlock; i <= lastBlock; i++ {
+			delete(f.blocks, i)
+		}
+	} else {
+		for i := range f.blocks {
+			if i >= firstBlock && i <= lastBlock {
+				delete(f.blocks, i)
+			}
+		}
+	}
+	holeStart := firstBlock * encryptedBlockSizeBytes
+	holeEnd := (lastBlock + 1) * encryptedBlockSizeBytes
+	if storedSizeBytes := int64(f.getStoredSizeBytes()); holeEnd > storedSizeBytes {
+		holeEnd = storedSizeBytes
+	}
+	if holeStart >= holeEnd {
+		return nil
+	}
+	return PunchHole(f.FileReadWriter, holeStart, holeEnd-holeStart)
+}
+
+// getStoredSizeBytes returns the size of the underlying file.
+func (f *encryptingFile) getStoredSizeBytes() uint64 {
+	return (f.sizeBytes + encryptedBlockSizeBytes - 1) / encryptedBlockSizeBytes * encryptedBlockSizeBytes
+}
+
+func (f *encryptingFile) Truncate(size int64) error {
+	if size < 0 {
+		return status.Errorf(codes.InvalidArgument, "Negative truncation size: %d", size)
+	}
+
+	blockIndex := size / encryptedBlockSizeBytes
+	offsetWithinBlock := int(size % encryptedBlockSizeBytes)
+	if uint64(size) < f.sizeBytes && offsetWithinBlock > 0 {
+		// The file is being shrunk to the middle of a block.
+		// Zero the trailing part of the last block to ensure
+		// that growing the file later on doesn't bring back
+		// old data.
+		if err := f.zeroPartialBlock(blockIndex, offsetWithinBlock, encryptedBlockSizeBytes); err != nil {
+			return err
+		}
+	}
+
+	blockCount := (size + encryptedBlockSizeBytes - 1) / encryptedBlockSizeBytes
+	if err := f.FileReadWriter.Truncate(blockCount * encryptedBlockSizeBytes); err != nil {
+		return err
+	}
+	for i := range f.blocks {
+		if i >= blockCount {
+			delete(f.blocks, i)
+		}
+	}
+	f.sizeBytes = uint64(size)
+	return nil
+}
+
+func (f *encryptingFile) WriteAt(p []byte, off int64) (int, error) {
+	// Short circuit calls that are out of bounds.
+	if off < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "Negative write offset: %d", off)
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// Encrypt every block overlapping with the write. Blocks that
+	// are overwritten entirely can be encrypted directly. Other
+	// blocks need to be read and decrypted first.
+	var scratch []byte
+	nTotal := 0
+	var err error
+	for len(p) > 0 {
+		blockIndex := off / encryptedBlockSizeBytes
+		offsetWithinBlock := int(off % encryptedBlockSizeBytes)
+		var n int
+		if offsetWithinBlock == 0 && len(p) >= encryptedBlockSizeBytes {
+			n = encryptedBlockSizeBytes
+			if err = f.writeBlock(blockIndex, p[:n]); err != nil {
+				break
+			}
+		} else {
+			if scratch == nil {
+				scratch = make([]byte, encryptedBlockSizeBytes)
+			}
+			if err = f.readBlock(blockIndex, scratch); err != nil {
+				break
+			}
+			n = copy(scratch[offsetWithinBlock:], p)
+			if err = f.writeBlock(blockIndex, scratch); err != nil {
+				break
+			}
+		}
+		nTotal += n
+		p = p[n:]
+		off += int64(n)
+	}
+
+	// Adjust file size if needed.
+	if newSize := uint64(off); nTotal > 0 && f.sizeBytes < newSize {
+		f.sizeBytes = newSize
+	}
+	return nTotal, err
+}
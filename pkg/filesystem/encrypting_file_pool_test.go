@@ -0,0 +1,153 @@
+package filesystem_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestEncryptingFilePool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	basePool := mock.NewMockFilePool(ctrl)
+
+	t.Run("InvalidKeySize", func(t *testing.T) {
+		_, err := re_filesystem.NewEncryptingFilePool(basePool, make([]byte, 10))
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Encryption key is 10 bytes in size, while at least 16 bytes are required"), err)
+	})
+
+	pool, err := re_filesystem.NewEncryptingFilePool(basePool, []byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		baseFile, err := re_filesystem.InMemoryFilePool.NewFile()
+		require.NoError(t, err)
+		basePool.EXPECT().NewFile().Return(baseFile, nil)
+
+		f, err := pool.NewFile()
+		require.NoError(t, err)
+
+		// Write data to the file using a mixture of aligned and
+		// unaligned writes.
+		data := bytes.Repeat([]byte("Hello world! "), 1000)
+		n, err := f.WriteAt(data[:100], 0)
+		require.Equal(t, 100, n)
+		require.NoError(t, err)
+		n, err = f.WriteAt(data[100:5000], 100)
+		require.Equal(t, 4900, n)
+		require.NoError(t, err)
+		n, err = f.WriteAt(data[5000:], 5000)
+		require.Equal(t, len(data)-5000, n)
+		require.NoError(t, err)
+
+		// The underlying file should not contain the data in
+		// plaintext.
+		stored := make([]byte, 64*1024)
+		n, err = baseFile.ReadAt(stored, 0)
+		require.Equal(t, io.EOF, err)
+		require.False(t, bytes.Contains(stored[:n], []byte("Hello world!")))
+
+		// Reading the data back should yield the original
+		// contents, regardless of alignment.
+		p := make([]byte, len(data)+10)
+		n, err = f.ReadAt(p, 0)
+		require.Equal(t, len(data), n)
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, data, p[:n])
+
+		n, err = f.ReadAt(p[:10], 4090)
+		require.Equal(t, 10, n)
+		require.NoError(t, err)
+		require.Equal(t, data[4090:4100], p[:10])
+
+		// Shrinking the file to the middle of a block should
+		// cause the remainder of the block to be zeroed.
+		require.NoError(t, f.Truncate(5000))
+		require.NoError(t, f.Truncate(10000))
+		n, err = f.ReadAt(p[:10000], 0)
+		require.Equal(t, 10000, n)
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, data[:5000], p[:5000])
+		require.Equal(t, make([]byte, 5000), p[5000:10000])
+
+		require.NoError(t, f.Close())
+	})
+
+	t.Run("Tampering", func(t *testing.T) {
+		baseFile, err := re_filesystem.InMemoryFilePool.NewFile()
+		require.NoError(t, err)
+		basePool.EXPECT().NewFile().Return(baseFile, nil)
+
+		f, err := pool.NewFile()
+		require.NoError(t, err)
+
+		n, err := f.WriteAt([]byte("Hello"), 0)
+		require.Equal(t, 5, n)
+		require.NoError(t, err)
+
+		// Modifying the underlying file should cause reads to
+		// fail, as the authentication tag no longer matches.
+		n, err = baseFile.WriteAt([]byte{0xff}, 20)
+		require.Equal(t, 1, n)
+		require.NoError(t, err)
+
+		_, err = f.ReadAt(make([]byte, 5), 0)
+		testutil.RequireEqualStatus(t, status.Error(codes.Internal, "Failed to decrypt block at index 0: chacha20poly1305: message authentication failed"), err)
+
+		// Blocks that have been overwritten with zero bytes
+		// should not be mistaken for holes.
+		n, err = baseFile.WriteAt(make([]byte, 4096), 0)
+		require.Equal(t, 4096, n)
+		require.NoError(t, err)
+
+		_, err = f.ReadAt(make([]byte, 5), 0)
+		testutil.RequireEqualStatus(t, status.Error(codes.Internal, "Failed to decrypt block at index 0: chacha20poly1305: message authentication failed"), err)
+
+		require.NoError(t, f.Close())
+	})
+
+	t.Run("PunchHole", func(t *testing.T) {
+		baseFile, err := re_filesystem.InMemoryFilePool.NewFile()
+		require.NoError(t, err)
+		basePool.EXPECT().NewFile().Return(baseFile, nil)
+
+		f, err := pool.NewFile()
+		require.NoError(t, err)
+
+		data := bytes.Repeat([]byte("Hello world! "), 1000)[:12288]
+		n, err := f.WriteAt(data, 0)
+		require.Equal(t, 12288, n)
+		require.NoError(t, err)
+
+		// Punching a hole should cause blocks that are covered
+		// entirely to be deallocated in the underlying file.
+		// As blocks are stored at the same offsets as their
+		// plaintext, this range remains aligned. Blocks that
+		// are only covered partially are zeroed explicitly.
+		require.NoError(t, re_filesystem.PunchHole(f, 100, 12188))
+
+		stored := make([]byte, 8192)
+		n, err = baseFile.ReadAt(stored, 4096)
+		require.Equal(t, 8192, n)
+		require.NoError(t, err)
+		require.Equal(t, make([]byte, 8192), stored)
+
+		p := make([]byte, 12288)
+		n, err = f.ReadAt(p, 0)
+		require.Equal(t, 12288, n)
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, data[:100], p[:100])
+		require.Equal(t, make([]byte, 12188), p[100:])
+
+		require.NoError(t, f.Close())
+	})
+}
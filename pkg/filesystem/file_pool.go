@@ -1,7 +1,12 @@
 package filesystem
 
 import (
+	"io"
+
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // FilePool is an allocator for temporary files. Files are created by
@@ -9,7 +14,111 @@ import (
 //
 // File handles returned by NewFile() are not thread-safe. Additional
 // locking needs to be done at higher levels to permit safe concurrent
-// access.
+// access. The only exception is that ReadAt() and
+// GetNextRegionOffset() may be called concurrently, as long as no
+// operations that modify the file are performed at the same time.
+// Implementations must therefore not mutate any state when reading.
 type FilePool interface {
 	NewFile() (filesystem.FileReadWriter, error)
 }
+
+// FilePoolUsage contains the capacity of a FilePool and the amount of
+// it that is still available.
+type FilePoolUsage struct {
+	TotalSizeBytes     uint64
+	AvailableSizeBytes uint64
+	// The number of files that may be created. These are zero if
+	// the number of files is not limited.
+	TotalFiles     uint64
+	AvailableFiles uint64
+}
+
+// UsageReportingFilePool is an optional interface that may be
+// implemented by FilePools that are capable of reporting their
+// capacity. This permits virtual file systems backed by a FilePool to
+// report meaningful values through statfs().
+type UsageReportingFilePool interface {
+	FilePool
+
+	GetUsage() (FilePoolUsage, error)
+}
+
+// GetFilePoolUsage returns the capacity of a FilePool. An error with
+// code UNIMPLEMENTED is returned if the FilePool is not capable of
+// reporting its capacity.
+func GetFilePoolUsage(fp FilePool) (FilePoolUsage, error) {
+	if urfp, ok := fp.(UsageReportingFilePool); ok {
+		return urfp.GetUsage()
+	}
+	return FilePoolUsage{}, status.Error(codes.Unimplemented, "File pool is not capable of reporting its usage")
+}
+
+// HolePunchingFileReadWriter is an optional interface that may be
+// implemented by files returned by FilePool.NewFile(). It permits
+// deallocating the storage backing a range of a file, so that sparse
+// files don't consume any space for their holes.
+type HolePunchingFileReadWriter interface {
+	filesystem.FileReadWriter
+
+	// PunchHole deallocates a range of the file, causing it to
+	// read back as zeroes. The size of the file remains unaltered.
+	PunchHole(off, size int64) error
+}
+
+// PunchHole deallocates a range of a file created by a FilePool. If the
+// file does not support deallocating storage, all data regions within
+// the range are overwritten with zeroes instead. The range must not
+// extend beyond the end of the file.
+func PunchHole(f filesystem.FileReadWriter, off, size int64) error {
+	if off < 0 || size < 0 {
+		return status.Errorf(codes.InvalidArgument, "Invalid range with offset %d and size %d", off, size)
+	}
+	if hpf, ok := f.(HolePunchingFileReadWriter); ok {
+		return hpf.PunchHole(off, size)
+	}
+	return zeroDataRegions(f, off, size)
+}
+
+// zeroDataRegions overwrites all data regions within a range of a file
+// with zeroes. This can be used to emulate hole punching for files
+// that do not support deallocating storage.
+func zeroDataRegions(f filesystem.FileReadWriter, off, size int64) error {
+	end := off + size
+	var zeroes []byte
+	for off < end {
+		// Skip over holes, as those already read back as zeroes.
+		dataOffset, err := f.GetNextRegionOffset(off, filesystem.Data)
+		if err == io.EOF || (err == nil && dataOffset >= end) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		holeOffset, err := f.GetNextRegionOffset(dataOffset, filesystem.Hole)
+		if err != nil {
+			return err
+		}
+		if holeOffset > end {
+			holeOffset = end
+		}
+
+		if zeroes == nil {
+			zeroes = make([]byte, punchHoleZeroesSizeBytes)
+		}
+		for off = dataOffset; off < holeOffset; {
+			chunk := zeroes
+			if remaining := holeOffset - off; int64(len(chunk)) > remaining {
+				chunk = chunk[:remaining]
+			}
+			n, err := f.WriteAt(chunk, off)
+			off += int64(n)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// punchHoleZeroesSizeBytes is the maximum amount of data that
+// zeroDataRegions() writes at once when emulating hole punching.
+const punchHoleZeroesSizeBytes = 1 << 16
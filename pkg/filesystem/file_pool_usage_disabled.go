@@ -0,0 +1,22 @@
+//go:build !linux
+// +build !linux
+
+package filesystem
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// getDiskUsage returns the capacity of the file system containing a
+// given path. Obtaining the capacity of file systems is only supported
+// on Linux.
+func getDiskUsage(path string) (FilePoolUsage, error) {
+	return FilePoolUsage{}, status.Error(codes.Unimplemented, "Obtaining the capacity of file systems is not supported on this operating system")
+}
+
+// getMemoryUsage returns the amount of physical memory of the system.
+// Obtaining the amount of physical memory is only supported on Linux.
+func getMemoryUsage() (FilePoolUsage, error) {
+	return FilePoolUsage{}, status.Error(codes.Unimplemented, "Obtaining the amount of physical memory is not supported on this operating system")
+}
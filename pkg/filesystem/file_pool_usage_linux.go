@@ -0,0 +1,36 @@
+//go:build linux
+// +build linux
+
+package filesystem
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// getDiskUsage returns the capacity of the file system containing a
+// given path.
+func getDiskUsage(path string) (FilePoolUsage, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return FilePoolUsage{}, err
+	}
+	return FilePoolUsage{
+		TotalSizeBytes:     stat.Blocks * uint64(stat.Bsize),
+		AvailableSizeBytes: stat.Bavail * uint64(stat.Bsize),
+		TotalFiles:         stat.Files,
+		AvailableFiles:     stat.Ffree,
+	}, nil
+}
+
+// getMemoryUsage returns the amount of physical memory of the system,
+// and the amount of it that is not in use.
+func getMemoryUsage() (FilePoolUsage, error) {
+	var info unix.Sysinfo_t
+	if err := unix.Sysinfo(&info); err != nil {
+		return FilePoolUsage{}, err
+	}
+	return FilePoolUsage{
+		TotalSizeBytes:     uint64(info.Totalram) * uint64(info.Unit),
+		AvailableSizeBytes: (uint64(info.Freeram) + uint64(info.Bufferram)) * uint64(info.Unit),
+	}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"io"
 
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/util"
 )
 
 type inMemoryFilePool struct{}
@@ -12,6 +13,16 @@ func (fp inMemoryFilePool) NewFile() (filesystem.FileReadWriter, error) {
 	return &inMemoryFile{}, nil
 }
 
+func (fp inMemoryFilePool) GetUsage() (FilePoolUsage, error) {
+	// Files are stored in memory, meaning their size is bounded by
+	// the amount of physical memory of the system.
+	usage, err := getMemoryUsage()
+	if err != nil {
+		return FilePoolUsage{}, util.StatusWrap(err, "Failed to obtain amount of physical memory")
+	}
+	return usage, nil
+}
+
 type inMemoryFile struct {
 	data []byte
 }
@@ -54,6 +54,10 @@ func (fp *metricsFilePool) NewFile() (filesystem.FileReadWriter, error) {
 	}, nil
 }
 
+func (fp *metricsFilePool) GetUsage() (FilePoolUsage, error) {
+	return GetFilePoolUsage(fp.base)
+}
+
 type metricsFile struct {
 	filesystem.FileReadWriter
 }
@@ -64,3 +68,7 @@ func (f *metricsFile) Close() error {
 	filePoolFilesClosed.Inc()
 	return err
 }
+
+func (f *metricsFile) PunchHole(off, size int64) error {
+	return PunchHole(f.FileReadWriter, off, size)
+}
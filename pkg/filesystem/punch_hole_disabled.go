@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package filesystem
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// punchHole deallocates a range of a file on disk. Punching holes is
+// only supported on Linux.
+func punchHole(path string, off, size int64) error {
+	return status.Error(codes.Unimplemented, "Punching holes in files is not supported on this operating system")
+}
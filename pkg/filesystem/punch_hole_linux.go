@@ -0,0 +1,27 @@
+//go:build linux
+// +build linux
+
+package filesystem
+
+import (
+	"golang.org/x/sys/unix"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// punchHole deallocates a range of a file on disk, causing it to read
+// back as zeroes. The size of the file remains unaltered.
+func punchHole(path string, off, size int64) error {
+	fd, err := unix.Open(path, unix.O_WRONLY|unix.O_CLOEXEC|unix.O_NOFOLLOW, 0)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+	if err := unix.Fallocate(fd, unix.FALLOC_FL_PUNCH_HOLE|unix.FALLOC_FL_KEEP_SIZE, off, size); err == unix.EOPNOTSUPP {
+		return status.Error(codes.Unimplemented, "File system does not support punching holes")
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
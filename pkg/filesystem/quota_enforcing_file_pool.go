@@ -1,14 +1,31 @@
 package filesystem
 
 import (
+	"sync"
 	"sync/atomic"
 
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+var (
+	quotaEnforcingFilePoolPrometheusMetrics sync.Once
+
+	quotaEnforcingFilePoolQuotaExceeded = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "buildbarn",
+			Subsystem: "filesystem",
+			Name:      "quota_enforcing_file_pool_quota_exceeded_total",
+			Help:      "Number of operations against a file pool that were rejected, because they would cause a quota to be exceeded.",
+		},
+		[]string{"quota"})
+	quotaEnforcingFilePoolQuotaExceededFileCount = quotaEnforcingFilePoolQuotaExceeded.WithLabelValues("FileCount")
+	quotaEnforcingFilePoolQuotaExceededSizeBytes = quotaEnforcingFilePoolQuotaExceeded.WithLabelValues("SizeBytes")
+)
+
 // quotaMetric is a simple 64-bit counter from/to which can be
 // subtracted/added atomically. It is used to store the number of files
 // and bytes of space available.
@@ -33,7 +50,9 @@ func (m *quotaMetric) release(v int64) {
 }
 
 type quotaEnforcingFilePool struct {
-	base FilePool
+	base             FilePool
+	maximumFileCount int64
+	maximumTotalSize int64
 
 	filesRemaining quotaMetric
 	bytesRemaining quotaMetric
@@ -44,9 +63,21 @@ type quotaEnforcingFilePool struct {
 // FilePool, while also limiting the total size of all files that are
 // extracted. Space is reclaimed by either truncating files or closing
 // them.
+//
+// When used by a single build action at a time, it prevents actions
+// that create large amounts of data from starving other actions that
+// share the same underlying FilePool. Operations that would cause the
+// quota to be exceeded fail with RESOURCE_EXHAUSTED, which the virtual
+// file system reports to build actions as ENOSPC.
 func NewQuotaEnforcingFilePool(base FilePool, maximumFileCount, maximumTotalSize int64) FilePool {
+	quotaEnforcingFilePoolPrometheusMetrics.Do(func() {
+		prometheus.MustRegister(quotaEnforcingFilePoolQuotaExceeded)
+	})
+
 	fp := &quotaEnforcingFilePool{
-		base: base,
+		base:             base,
+		maximumFileCount: maximumFileCount,
+		maximumTotalSize: maximumTotalSize,
 	}
 	fp.filesRemaining.remaining.Store(maximumFileCount)
 	fp.bytesRemaining.remaining.Store(maximumTotalSize)
@@ -55,7 +86,8 @@ func NewQuotaEnforcingFilePool(base FilePool, maximumFileCount, maximumTotalSize
 
 func (fp *quotaEnforcingFilePool) NewFile() (filesystem.FileReadWriter, error) {
 	if !fp.filesRemaining.allocate(1) {
-		return nil, status.Error(codes.InvalidArgument, "File count quota reached")
+		quotaEnforcingFilePoolQuotaExceededFileCount.Inc()
+		return nil, status.Error(codes.ResourceExhausted, "File count quota reached")
 	}
 	f, err := fp.base.NewFile()
 	if err != nil {
@@ -68,6 +100,32 @@ func (fp *quotaEnforcingFilePool) NewFile() (filesystem.FileReadWriter, error) {
 	}, nil
 }
 
+func (fp *quotaEnforcingFilePool) GetUsage() (FilePoolUsage, error) {
+	usage := FilePoolUsage{
+		TotalSizeBytes:     uint64(fp.maximumTotalSize),
+		AvailableSizeBytes: uint64(fp.bytesRemaining.remaining.Load()),
+		TotalFiles:         uint64(fp.maximumFileCount),
+		AvailableFiles:     uint64(fp.filesRemaining.remaining.Load()),
+	}
+
+	// The underlying pool may have less space available than
+	// permitted by the quota.
+	baseUsage, err := GetFilePoolUsage(fp.base)
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return usage, nil
+		}
+		return FilePoolUsage{}, err
+	}
+	usage.TotalSizeBytes = min(usage.TotalSizeBytes, baseUsage.TotalSizeBytes)
+	usage.AvailableSizeBytes = min(usage.AvailableSizeBytes, baseUsage.AvailableSizeBytes)
+	if baseUsage.TotalFiles > 0 {
+		usage.TotalFiles = min(usage.TotalFiles, baseUsage.TotalFiles)
+		usage.AvailableFiles = min(usage.AvailableFiles, baseUsage.AvailableFiles)
+	}
+	return usage, nil
+}
+
 type quotaEnforcingFile struct {
 	filesystem.FileReadWriter
 
@@ -87,6 +145,12 @@ func (f *quotaEnforcingFile) Close() error {
 	return err
 }
 
+func (f *quotaEnforcingFile) PunchHole(off, size int64) error {
+	// Quotas are enforced on the size of files, which is not
+	// affected by punching holes.
+	return PunchHole(f.FileReadWriter, off, size)
+}
+
 func (f *quotaEnforcingFile) Truncate(size int64) error {
 	if size < f.size {
 		// File is shrinking.
@@ -98,7 +162,8 @@ func (f *quotaEnforcingFile) Truncate(size int64) error {
 		// File is growing.
 		additionalSpace := size - f.size
 		if !f.pool.bytesRemaining.allocate(additionalSpace) {
-			return status.Error(codes.InvalidArgument, "File size quota reached")
+			quotaEnforcingFilePoolQuotaExceededSizeBytes.Inc()
+			return status.Error(codes.ResourceExhausted, "File size quota reached")
 		}
 		if err := f.FileReadWriter.Truncate(size); err != nil {
 			f.pool.bytesRemaining.release(additionalSpace)
@@ -119,7 +184,8 @@ func (f *quotaEnforcingFile) WriteAt(p []byte, off int64) (int, error) {
 	// File is growing. Allocate space prior to writing. Release it,
 	// potentially partially, upon failure.
 	if !f.pool.bytesRemaining.allocate(desiredSize - f.size) {
-		return 0, status.Error(codes.InvalidArgument, "File size quota reached")
+		quotaEnforcingFilePoolQuotaExceededSizeBytes.Inc()
+		return 0, status.Error(codes.ResourceExhausted, "File size quota reached")
 	}
 	n, err := f.FileReadWriter.WriteAt(p, off)
 	actualSize := int64(0)
@@ -30,7 +30,7 @@ func testRemainingQuota(t *testing.T, ctrl *gomock.Controller, underlyingPool *m
 		require.NoError(t, err)
 	}
 	_, err := pool.NewFile()
-	require.Equal(t, err, status.Error(codes.InvalidArgument, "File count quota reached"))
+	require.Equal(t, err, status.Error(codes.ResourceExhausted, "File count quota reached"))
 	for i := 0; i < filesRemaining; i++ {
 		underlyingFiles[i].EXPECT().Close().Return(nil)
 		require.NoError(t, files[i].Close())
@@ -46,7 +46,7 @@ func testRemainingQuota(t *testing.T, ctrl *gomock.Controller, underlyingPool *m
 		underlyingFile.EXPECT().Truncate(bytesRemaining).Return(nil)
 	}
 	require.NoError(t, f.Truncate(bytesRemaining))
-	require.Equal(t, f.Truncate(bytesRemaining+1), status.Error(codes.InvalidArgument, "File size quota reached"))
+	require.Equal(t, f.Truncate(bytesRemaining+1), status.Error(codes.ResourceExhausted, "File size quota reached"))
 	underlyingFile.EXPECT().Close().Return(nil)
 	require.NoError(t, f.Close())
 }
@@ -85,7 +85,7 @@ func TestQuotaEnforcingFilePoolExample(t *testing.T) {
 	// size should be disallowed.
 	n, err = f.WriteAt(p[:], 991)
 	require.Equal(t, 0, n)
-	require.Equal(t, err, status.Error(codes.InvalidArgument, "File size quota reached"))
+	require.Equal(t, err, status.Error(codes.ResourceExhausted, "File size quota reached"))
 	testRemainingQuota(t, ctrl, underlyingPool, pool, 9, 1000)
 
 	// A failed write should initially allocate all of the required
@@ -117,7 +117,7 @@ func TestQuotaEnforcingFilePoolExample(t *testing.T) {
 
 	// Growing the file past the permitted size should not be
 	// allowed.
-	require.Equal(t, f.Truncate(1001), status.Error(codes.InvalidArgument, "File size quota reached"))
+	require.Equal(t, f.Truncate(1001), status.Error(codes.ResourceExhausted, "File size quota reached"))
 	testRemainingQuota(t, ctrl, underlyingPool, pool, 9, 877)
 
 	// I/O error while growing file should not cause the quotas to
@@ -137,3 +137,57 @@ func TestQuotaEnforcingFilePoolExample(t *testing.T) {
 	require.NoError(t, f.Close())
 	testRemainingQuota(t, ctrl, underlyingPool, pool, 10, 1000)
 }
+
+func TestQuotaEnforcingFilePoolGetUsage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	t.Run("UnderlyingPoolWithoutUsage", func(t *testing.T) {
+		// If the underlying pool is not capable of reporting
+		// its usage, the quota should be reported as is.
+		underlyingPool := mock.NewMockFilePool(ctrl)
+		pool := re_filesystem.NewQuotaEnforcingFilePool(underlyingPool, 10, 1000)
+
+		underlyingFile := mock.NewMockFileReadWriter(ctrl)
+		underlyingPool.EXPECT().NewFile().Return(underlyingFile, nil)
+		f, err := pool.NewFile()
+		require.NoError(t, err)
+		underlyingFile.EXPECT().Truncate(int64(300)).Return(nil)
+		require.NoError(t, f.Truncate(300))
+
+		usage, err := re_filesystem.GetFilePoolUsage(pool)
+		require.NoError(t, err)
+		require.Equal(t, re_filesystem.FilePoolUsage{
+			TotalSizeBytes:     1000,
+			AvailableSizeBytes: 700,
+			TotalFiles:         10,
+			AvailableFiles:     9,
+		}, usage)
+
+		underlyingFile.EXPECT().Close().Return(nil)
+		require.NoError(t, f.Close())
+	})
+
+	t.Run("UnderlyingPoolWithUsage", func(t *testing.T) {
+		// If the underlying pool has less space available than
+		// permitted by the quota, its usage should be reported.
+		underlyingPool := mock.NewMockUsageReportingFilePool(ctrl)
+		pool := re_filesystem.NewQuotaEnforcingFilePool(underlyingPool, 10, 1000)
+
+		underlyingPool.EXPECT().GetUsage().Return(re_filesystem.FilePoolUsage{
+			TotalSizeBytes:     4000,
+			AvailableSizeBytes: 500,
+		}, nil)
+		usage, err := re_filesystem.GetFilePoolUsage(pool)
+		require.NoError(t, err)
+		require.Equal(t, re_filesystem.FilePoolUsage{
+			TotalSizeBytes:     1000,
+			AvailableSizeBytes: 500,
+			TotalFiles:         10,
+			AvailableFiles:     10,
+		}, usage)
+
+		underlyingPool.EXPECT().GetUsage().Return(re_filesystem.FilePoolUsage{}, status.Error(codes.Internal, "Disk on fire"))
+		_, err = re_filesystem.GetFilePoolUsage(pool)
+		require.Equal(t, status.Error(codes.Internal, "Disk on fire"), err)
+	})
+}
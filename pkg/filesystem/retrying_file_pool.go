@@ -0,0 +1,295 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+)
+
+// ReadinessCheckingFilePool is a FilePool that is capable of reporting
+// whether it is healthy enough to be used for executing build actions.
+type ReadinessCheckingFilePool interface {
+	FilePool
+
+	CheckReadiness(ctx context.Context) error
+}
+
+// isTransientFilePoolError returns whether an error returned by a
+// FilePool or one of its files is likely caused by a temporary problem
+// with the underlying storage. EIO may be returned by disks that are
+// briefly unresponsive. ENOSPC is not considered transient, as running
+// out of space is typically caused by build actions writing too much
+// data. Retrying would only delay the failure.
+func isTransientFilePoolError(err error) bool {
+	return errors.Is(err, syscall.EIO)
+}
+
+// TransientFilePoolError is returned by files created by
+// NewRetryingFilePool if a read or write failed with an error that is
+// likely transient. Callers of ReadAt() and WriteAt() tend to hold
+// locks on the file. Instead of backing off while these locks are
+// held, the caller is expected to release them, call
+// WaitForFilePoolRetry() and reissue the operation.
+type TransientFilePoolError struct {
+	Err   error
+	Retry <-chan time.Time
+}
+
+func (e *TransientFilePoolError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransientFilePoolError) Unwrap() error {
+	return e.Err
+}
+
+// WaitForFilePoolRetry returns whether an operation against a file that
+// failed with the provided error should be reissued. If so, it blocks
+// until the backoff requested by the FilePool has elapsed. This
+// function must be called without holding any locks.
+func WaitForFilePoolRetry(err error) bool {
+	var transientErr *TransientFilePoolError
+	if !errors.As(err, &transientErr) {
+		return false
+	}
+	<-transientErr.Retry
+	return true
+}
+
+// retryState keeps track of the number of attempts of an operation
+// that have failed with transient errors.
+type retryState struct {
+	attempts int
+	backoff  time.Duration
+}
+
+type retryingFilePool struct {
+	base                       FilePool
+	clock                      clock.Clock
+	maximumAttempts            int
+	initialBackoff             time.Duration
+	maximumBackoff             time.Duration
+	maximumConsecutiveFailures int
+
+	lock                sync.Mutex
+	consecutiveFailures int
+	lastFailure         error
+	lastProbeFailures   int
+	lastProbeTime       time.Time
+	lastProbeError      error
+}
+
+// NewRetryingFilePool creates a decorator for FilePool that retries
+// operations that fail with errors that are likely transient. This
+// prevents build actions from failing due to a single hiccup of a
+// local disk.
+//
+// Reads and writes are retried using exponential backoff. As callers
+// typically hold locks while performing these operations, the backoff
+// is not performed by the FilePool itself. Failures are reported as
+// TransientFilePoolError, allowing callers to back off after releasing
+// their locks. Other operations are retried immediately.
+//
+// If a number of consecutive operations keep on failing after being
+// retried, the FilePool is marked as degraded. While degraded,
+// readiness checks fail, thereby preventing the worker from picking
+// up more work. The degraded state is lifted as soon as an operation
+// succeeds, or a readiness check is able to write a test file.
+func NewRetryingFilePool(base FilePool, clock clock.Clock, maximumAttempts int, initialBackoff, maximumBackoff time.Duration, maximumConsecutiveFailures int) ReadinessCheckingFilePool {
+	return &retryingFilePool{
+		base:                       base,
+		clock:                      clock,
+		maximumAttempts:            maximumAttempts,
+		initialBackoff:             initialBackoff,
+		maximumBackoff:             maximumBackoff,
+		maximumConsecutiveFailures: maximumConsecutiveFailures,
+	}
+}
+
+// nextBackoff is called after an attempt of an operation completes. It
+// returns the amount of time to wait before the operation should be
+// attempted again, or false if the outcome of the operation is final.
+// It also keeps track of the number of consecutive operations that
+// failed persistently.
+func (fp *retryingFilePool) nextBackoff(state *retryState, err error) (time.Duration, bool) {
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+
+	if err == nil || !isTransientFilePoolError(err) {
+		// Errors that aren't transient (e.g., io.EOF) don't
+		// indicate that the storage is unhealthy.
+		*state = retryState{}
+		fp.consecutiveFailures = 0
+		fp.lastFailure = nil
+		return 0, false
+	}
+	state.attempts++
+	if state.attempts >= fp.maximumAttempts {
+		*state = retryState{}
+		fp.consecutiveFailures++
+		fp.lastFailure = err
+		return 0, false
+	}
+	if state.backoff == 0 {
+		state.backoff = fp.initialBackoff
+	} else {
+		state.backoff *= 2
+	}
+	if state.backoff > fp.maximumBackoff {
+		state.backoff = fp.maximumBackoff
+	}
+	return state.backoff, true
+}
+
+// retryWithoutBackoff retries an operation until it succeeds, fails
+// with an error that is not transient, or the maximum number of
+// attempts is reached. It is used for operations that callers perform
+// while holding locks on directories or files, and for which they
+// cannot back off. No delay is applied, as that would stall other
+// operations against the same directory or file.
+func (fp *retryingFilePool) retryWithoutBackoff(operation func() error) error {
+	var state retryState
+	for {
+		err := operation()
+		if _, retry := fp.nextBackoff(&state, err); !retry {
+			return err
+		}
+	}
+}
+
+func (fp *retryingFilePool) GetUsage() (FilePoolUsage, error) {
+	return GetFilePoolUsage(fp.base)
+}
+
+func (fp *retryingFilePool) NewFile() (filesystem.FileReadWriter, error) {
+	var f filesystem.FileReadWriter
+	if err := fp.retryWithoutBackoff(func() (err error) {
+		f, err = fp.base.NewFile()
+		return
+	}); err != nil {
+		return nil, err
+	}
+	return &retryingFile{
+		FileReadWriter: f,
+		pool:           fp,
+	}, nil
+}
+
+func (fp *retryingFilePool) CheckReadiness(ctx context.Context) error {
+	fp.lock.Lock()
+	consecutiveFailures, lastFailure := fp.consecutiveFailures, fp.lastFailure
+	if consecutiveFailures < fp.maximumConsecutiveFailures {
+		fp.lock.Unlock()
+		return nil
+	}
+
+	// The file pool is degraded. Only write a test file when
+	// additional failures occurred since the last attempt, or
+	// periodically to detect that the storage has recovered. This
+	// prevents readiness checks from writing to storage
+	// continuously.
+	now := fp.clock.Now()
+	if consecutiveFailures == fp.lastProbeFailures && now.Before(fp.lastProbeTime.Add(fp.maximumBackoff)) {
+		err := fp.lastProbeError
+		fp.lock.Unlock()
+		return err
+	}
+	fp.lastProbeFailures = consecutiveFailures
+	fp.lastProbeTime = now
+	fp.lock.Unlock()
+
+	err := fp.writeTestFile(consecutiveFailures, lastFailure)
+
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+	if err == nil {
+		fp.consecutiveFailures = 0
+		fp.lastFailure = nil
+	}
+	fp.lastProbeError = err
+	return err
+}
+
+// writeTestFile is called by CheckReadiness() when the file pool is
+// degraded to determine whether the storage has recovered.
+func (fp *retryingFilePool) writeTestFile(consecutiveFailures int, lastFailure error) error {
+	f, err := fp.base.NewFile()
+	if err != nil {
+		return util.StatusWrapWithCode(err, codes.Unavailable, "File pool is degraded, and creating a test file failed")
+	}
+	_, err = f.WriteAt([]byte("Hello"), 0)
+	f.Close()
+	if err != nil {
+		return util.StatusWrapfWithCode(err, codes.Unavailable, "File pool is degraded after %d consecutive failures (last failure: %s), and writing a test file failed", consecutiveFailures, lastFailure)
+	}
+	return nil
+}
+
+type retryingFile struct {
+	filesystem.FileReadWriter
+
+	pool *retryingFilePool
+
+	// State of reads and writes that failed with transient errors.
+	// This field is protected by the lock of the pool.
+	state retryState
+}
+
+// deferRetry is called after reading or writing completes. If the
+// operation failed with a transient error and may be retried, a
+// TransientFilePoolError is returned that requests the caller to back
+// off.
+func (f *retryingFile) deferRetry(err error) error {
+	backoff, retry := f.pool.nextBackoff(&f.state, err)
+	if !retry {
+		return err
+	}
+	_, timerChannel := f.pool.clock.NewTimer(backoff)
+	return &TransientFilePoolError{
+		Err:   err,
+		Retry: timerChannel,
+	}
+}
+
+func (f *retryingFile) GetNextRegionOffset(offset int64, regionType filesystem.RegionType) (int64, error) {
+	var nextOffset int64
+	err := f.pool.retryWithoutBackoff(func() (err error) {
+		nextOffset, err = f.FileReadWriter.GetNextRegionOffset(offset, regionType)
+		return
+	})
+	return nextOffset, err
+}
+
+func (f *retryingFile) PunchHole(off, size int64) error {
+	return f.pool.retryWithoutBackoff(func() error {
+		return PunchHole(f.FileReadWriter, off, size)
+	})
+}
+
+func (f *retryingFile) ReadAt(p []byte, off int64) (int, error) {
+	n, err := f.FileReadWriter.ReadAt(p, off)
+	return n, f.deferRetry(err)
+}
+
+func (f *retryingFile) Sync() error {
+	return f.pool.retryWithoutBackoff(f.FileReadWriter.Sync)
+}
+
+func (f *retryingFile) Truncate(size int64) error {
+	return f.pool.retryWithoutBackoff(func() error {
+		return f.FileReadWriter.Truncate(size)
+	})
+}
+
+func (f *retryingFile) WriteAt(p []byte, off int64) (int, error) {
+	n, err := f.FileReadWriter.WriteAt(p, off)
+	return n, f.deferRetry(err)
+}
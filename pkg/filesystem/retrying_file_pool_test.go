@@ -0,0 +1,180 @@
+package filesystem_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryingFilePool(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	underlyingPool := mock.NewMockFilePool(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	pool := re_filesystem.NewRetryingFilePool(underlyingPool, clock, 3, time.Second, 1500*time.Millisecond, 2)
+
+	expectBackoff := func(d time.Duration) {
+		timerChannel := make(chan time.Time, 1)
+		timerChannel <- time.Unix(1000, 0)
+		clock.EXPECT().NewTimer(d).Return(mock.NewMockTimer(ctrl), timerChannel)
+	}
+
+	t.Run("NewFileTransientFailure", func(t *testing.T) {
+		// Creating a file should be retried if it fails with
+		// EIO. As files are created while holding directory
+		// locks, this should be done without backing off.
+		underlyingFile := mock.NewMockFileReadWriter(ctrl)
+		gomock.InOrder(
+			underlyingPool.EXPECT().NewFile().Return(nil, &os.PathError{Op: "open", Path: "/tmp/1", Err: syscall.EIO}),
+			underlyingPool.EXPECT().NewFile().Return(underlyingFile, nil))
+
+		f, err := pool.NewFile()
+		require.NoError(t, err)
+
+		underlyingFile.EXPECT().Close()
+		require.NoError(t, f.Close())
+	})
+
+	t.Run("NewFileNonTransientFailure", func(t *testing.T) {
+		// Other errors should be returned immediately.
+		underlyingPool.EXPECT().NewFile().Return(nil, status.Error(codes.ResourceExhausted, "Out of sectors"))
+
+		_, err := pool.NewFile()
+		testutil.RequireEqualStatus(t, status.Error(codes.ResourceExhausted, "Out of sectors"), err)
+	})
+
+	t.Run("WriteAtTransientFailure", func(t *testing.T) {
+		// Writes that fail with transient errors should not
+		// back off directly, as the caller may hold locks.
+		// Instead, the caller should be requested to back off
+		// and to reissue the write.
+		underlyingFile := mock.NewMockFileReadWriter(ctrl)
+		underlyingPool.EXPECT().NewFile().Return(underlyingFile, nil)
+
+		f, err := pool.NewFile()
+		require.NoError(t, err)
+
+		underlyingFile.EXPECT().WriteAt([]byte("Hello world"), int64(100)).Return(6, syscall.EIO)
+		expectBackoff(time.Second)
+		n, err := f.WriteAt([]byte("Hello world"), 100)
+		require.Equal(t, 6, n)
+		require.True(t, re_filesystem.WaitForFilePoolRetry(err))
+
+		underlyingFile.EXPECT().WriteAt([]byte("Hello world"), int64(100)).Return(0, syscall.EIO)
+		expectBackoff(1500 * time.Millisecond)
+		n, err = f.WriteAt([]byte("Hello world"), 100)
+		require.Equal(t, 0, n)
+		require.True(t, re_filesystem.WaitForFilePoolRetry(err))
+
+		underlyingFile.EXPECT().WriteAt([]byte("Hello world"), int64(100)).Return(11, nil)
+		n, err = f.WriteAt([]byte("Hello world"), 100)
+		require.NoError(t, err)
+		require.Equal(t, 11, n)
+
+		// Running out of space is not considered transient, as
+		// it is typically caused by actions writing too much
+		// data.
+		underlyingFile.EXPECT().WriteAt([]byte("Hello world"), int64(100)).Return(6, syscall.ENOSPC)
+		n, err = f.WriteAt([]byte("Hello world"), 100)
+		require.Equal(t, syscall.ENOSPC, err)
+		require.Equal(t, 6, n)
+		require.False(t, re_filesystem.WaitForFilePoolRetry(err))
+
+		// Reaching the end of the file is not an error that
+		// should be retried.
+		underlyingFile.EXPECT().ReadAt(gomock.Len(10), int64(200)).Return(0, io.EOF)
+		var p [10]byte
+		n, err = f.ReadAt(p[:], 200)
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, 0, n)
+		require.False(t, re_filesystem.WaitForFilePoolRetry(err))
+
+		// Once the maximum number of attempts is reached, the
+		// original error should be returned.
+		gomock.InOrder(
+			underlyingFile.EXPECT().ReadAt(gomock.Len(10), int64(0)).Return(0, syscall.EIO),
+			underlyingFile.EXPECT().ReadAt(gomock.Len(10), int64(0)).Return(0, syscall.EIO),
+			underlyingFile.EXPECT().ReadAt(gomock.Len(10), int64(0)).Return(0, syscall.EIO))
+		expectBackoff(time.Second)
+		expectBackoff(1500 * time.Millisecond)
+		for i := 0; i < 2; i++ {
+			_, err = f.ReadAt(p[:], 0)
+			require.True(t, re_filesystem.WaitForFilePoolRetry(err))
+		}
+		_, err = f.ReadAt(p[:], 0)
+		require.Equal(t, syscall.EIO, err)
+
+		underlyingFile.EXPECT().Close()
+		require.NoError(t, f.Close())
+
+		// Let a successful operation reset the number of
+		// consecutive failures.
+		underlyingPool.EXPECT().NewFile().Return(underlyingFile, nil)
+		f, err = pool.NewFile()
+		require.NoError(t, err)
+		underlyingFile.EXPECT().Close()
+		require.NoError(t, f.Close())
+	})
+
+	t.Run("Degraded", func(t *testing.T) {
+		underlyingFile := mock.NewMockFileReadWriter(ctrl)
+		underlyingPool.EXPECT().NewFile().Return(underlyingFile, nil)
+
+		f, err := pool.NewFile()
+		require.NoError(t, err)
+
+		// A single operation failing persistently should not
+		// cause the pool to become degraded.
+		underlyingFile.EXPECT().Truncate(int64(0)).Return(syscall.EIO).Times(3)
+		require.Equal(t, syscall.EIO, f.Truncate(0))
+		require.NoError(t, pool.CheckReadiness(ctx))
+
+		// Once multiple consecutive operations fail, readiness
+		// checks should attempt to write a test file.
+		underlyingFile.EXPECT().Sync().Return(syscall.EIO).Times(3)
+		require.Equal(t, syscall.EIO, f.Sync())
+
+		clock.EXPECT().Now().Return(time.Unix(1000, 0))
+		testFile1 := mock.NewMockFileReadWriter(ctrl)
+		underlyingPool.EXPECT().NewFile().Return(testFile1, nil)
+		testFile1.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(0, syscall.EIO)
+		testFile1.EXPECT().Close()
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.Unavailable, "File pool is degraded after 2 consecutive failures (last failure: input/output error), and writing a test file failed: input/output error"),
+			pool.CheckReadiness(ctx))
+
+		// Subsequent readiness checks should not write another
+		// test file until the maximum backoff has passed.
+		clock.EXPECT().Now().Return(time.Unix(1001, 0))
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.Unavailable, "File pool is degraded after 2 consecutive failures (last failure: input/output error), and writing a test file failed: input/output error"),
+			pool.CheckReadiness(ctx))
+
+		// Once the test file can be written, the pool should
+		// no longer be considered degraded.
+		clock.EXPECT().Now().Return(time.Unix(1002, 0))
+		testFile2 := mock.NewMockFileReadWriter(ctrl)
+		underlyingPool.EXPECT().NewFile().Return(testFile2, nil)
+		testFile2.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+		testFile2.EXPECT().Close()
+		require.NoError(t, pool.CheckReadiness(ctx))
+		require.NoError(t, pool.CheckReadiness(ctx))
+
+		underlyingFile.EXPECT().Close()
+		require.NoError(t, f.Close())
+	})
+}
@@ -21,4 +21,7 @@ type SectorAllocator interface {
 	// Free a potentially fragmented list of sectors. Elements with
 	// value zero are ignored.
 	FreeList(sectors []uint32)
+	// GetUsage returns the total number of sectors managed by the
+	// allocator, and the number of sectors that are free.
+	GetUsage() (sectorCount, freeSectorCount uint32)
 }
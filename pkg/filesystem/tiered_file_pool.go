@@ -0,0 +1,178 @@
+package filesystem
+
+import (
+	"io"
+
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// tieredFileMigrationBufferSizeBytes is the size of the buffer that is
+// used to copy the contents of a file from memory to disk.
+const tieredFileMigrationBufferSizeBytes = 64 * 1024
+
+type tieredFilePool struct {
+	memoryPool FilePool
+	diskPool   FilePool
+
+	memoryBytesRemaining quotaMetric
+}
+
+// NewTieredFilePool creates a FilePool that initially stores files in
+// a pool that is backed by memory. Once the total size of all files
+// stored in memory would exceed a given limit, the file that is
+// growing is migrated to a pool that is backed by disk.
+//
+// Most files created by build actions are small. Storing these in
+// memory prevents them from incurring the latency of the disk, while
+// still allowing large files to be created.
+func NewTieredFilePool(memoryPool, diskPool FilePool, memoryLimitBytes int64) FilePool {
+	fp := &tieredFilePool{
+		memoryPool: memoryPool,
+		diskPool:   diskPool,
+	}
+	fp.memoryBytesRemaining.remaining.Store(memoryLimitBytes)
+	return fp
+}
+
+func (fp *tieredFilePool) NewFile() (filesystem.FileReadWriter, error) {
+	f, err := fp.memoryPool.NewFile()
+	if err != nil {
+		return nil, err
+	}
+	return &tieredFile{
+		FileReadWriter: f,
+		pool:           fp,
+		inMemory:       true,
+	}, nil
+}
+
+func (fp *tieredFilePool) GetUsage() (FilePoolUsage, error) {
+	// Files are only kept in memory if space permits. Report the
+	// capacity of the disk, as that is what ultimately limits the
+	// amount of data that can be stored.
+	return GetFilePoolUsage(fp.diskPool)
+}
+
+type tieredFile struct {
+	filesystem.FileReadWriter
+
+	pool     *tieredFilePool
+	inMemory bool
+	size     int64
+}
+
+func (f *tieredFile) Close() error {
+	err := f.FileReadWriter.Close()
+	f.FileReadWriter = nil
+
+	if f.inMemory {
+		f.pool.memoryBytesRemaining.release(f.size)
+	}
+	f.pool = nil
+	return err
+}
+
+func (f *tieredFile) PunchHole(off, size int64) error {
+	return PunchHole(f.FileReadWriter, off, size)
+}
+
+// migrateToDisk copies the contents of a file that is stored in memory
+// to a newly created file on disk. Ranges that only consist of zero
+// bytes are not copied, so that they may be stored sparsely.
+func (f *tieredFile) migrateToDisk() error {
+	diskFile, err := f.pool.diskPool.NewFile()
+	if err != nil {
+		return util.StatusWrap(err, "Failed to create file on disk")
+	}
+
+	buf := make([]byte, tieredFileMigrationBufferSizeBytes)
+	for off := int64(0); off < f.size; {
+		chunk := buf
+		if remaining := f.size - off; remaining < int64(len(chunk)) {
+			chunk = chunk[:remaining]
+		}
+		if n, err := f.FileReadWriter.ReadAt(chunk, off); n != len(chunk) {
+			diskFile.Close()
+			if err == nil || err == io.EOF {
+				return status.Errorf(codes.Internal, "Read of %d bytes at offset %d only returned %d bytes", len(chunk), off, n)
+			}
+			return util.StatusWrapf(err, "Failed to read from file in memory at offset %d", off)
+		}
+		if !isZero(chunk) {
+			if _, err := diskFile.WriteAt(chunk, off); err != nil {
+				diskFile.Close()
+				return util.StatusWrapf(err, "Failed to write to file on disk at offset %d", off)
+			}
+		}
+		off += int64(len(chunk))
+	}
+	if err := diskFile.Truncate(f.size); err != nil {
+		diskFile.Close()
+		return util.StatusWrap(err, "Failed to truncate file on disk")
+	}
+
+	// Switch over to the file on disk.
+	f.FileReadWriter.Close()
+	f.FileReadWriter = diskFile
+	f.pool.memoryBytesRemaining.release(f.size)
+	f.inMemory = false
+	return nil
+}
+
+// reserve ensures that the file can grow to a given size. If the file
+// is stored in memory and the memory limit would be exceeded, the file
+// is migrated to disk. It returns the amount of memory that was
+// allocated, which needs to be released if the subsequent operation
+// fails.
+func (f *tieredFile) reserve(desiredSize int64) (int64, error) {
+	if !f.inMemory || desiredSize <= f.size {
+		return 0, nil
+	}
+	additionalSpace := desiredSize - f.size
+	if f.pool.memoryBytesRemaining.allocate(additionalSpace) {
+		return additionalSpace, nil
+	}
+	return 0, f.migrateToDisk()
+}
+
+func (f *tieredFile) Truncate(size int64) error {
+	allocated, err := f.reserve(size)
+	if err != nil {
+		return err
+	}
+	if err := f.FileReadWriter.Truncate(size); err != nil {
+		f.pool.memoryBytesRemaining.release(allocated)
+		return err
+	}
+	if f.inMemory && size < f.size {
+		// File is shrinking.
+		f.pool.memoryBytesRemaining.release(f.size - size)
+	}
+	f.size = size
+	return nil
+}
+
+func (f *tieredFile) WriteAt(p []byte, off int64) (int, error) {
+	desiredSize := off + int64(len(p))
+	allocated, err := f.reserve(desiredSize)
+	if err != nil {
+		return 0, err
+	}
+	n, err := f.FileReadWriter.WriteAt(p, off)
+
+	// Release memory that was allocated, but not used due to the
+	// write failing partially.
+	actualSize := f.size
+	if n > 0 && off+int64(n) > actualSize {
+		actualSize = off + int64(n)
+	}
+	if actualSize < desiredSize && allocated > 0 {
+		f.pool.memoryBytesRemaining.release(desiredSize - actualSize)
+	}
+	f.size = actualSize
+	return n, err
+}
@@ -0,0 +1,96 @@
+package filesystem_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTieredFilePool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	diskPool := mock.NewMockFilePool(ctrl)
+	pool := re_filesystem.NewTieredFilePool(re_filesystem.InMemoryFilePool, diskPool, 100)
+
+	t.Run("SmallFiles", func(t *testing.T) {
+		// Files that fit within the memory limit should never
+		// be migrated to disk.
+		f1, err := pool.NewFile()
+		require.NoError(t, err)
+		n, err := f1.WriteAt(make([]byte, 60), 0)
+		require.Equal(t, 60, n)
+		require.NoError(t, err)
+
+		f2, err := pool.NewFile()
+		require.NoError(t, err)
+		require.NoError(t, f2.Truncate(40))
+
+		// Closing files should release memory, so that new
+		// files can be stored in memory as well.
+		require.NoError(t, f1.Close())
+		require.NoError(t, f2.Close())
+
+		f3, err := pool.NewFile()
+		require.NoError(t, err)
+		require.NoError(t, f3.Truncate(100))
+		require.NoError(t, f3.Truncate(0))
+		require.NoError(t, f3.Close())
+	})
+
+	t.Run("Migration", func(t *testing.T) {
+		f1, err := pool.NewFile()
+		require.NoError(t, err)
+		n, err := f1.WriteAt([]byte("Hello"), 10)
+		require.Equal(t, 5, n)
+		require.NoError(t, err)
+
+		f2, err := pool.NewFile()
+		require.NoError(t, err)
+		n, err = f2.WriteAt(make([]byte, 80), 0)
+		require.Equal(t, 80, n)
+		require.NoError(t, err)
+
+		// Growing the first file beyond the memory limit
+		// should cause it to be migrated to disk, while
+		// retaining its original contents.
+		diskFile, err := re_filesystem.InMemoryFilePool.NewFile()
+		require.NoError(t, err)
+		diskPool.EXPECT().NewFile().Return(diskFile, nil)
+
+		n, err = f1.WriteAt([]byte("world"), 20)
+		require.Equal(t, 5, n)
+		require.NoError(t, err)
+
+		var p [30]byte
+		n, err = diskFile.ReadAt(p[:], 0)
+		require.Equal(t, 25, n)
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, []byte("\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00Hello\x00\x00\x00\x00\x00world"), p[:n])
+
+		// Memory used by the first file should have been
+		// released, allowing the second file to grow.
+		require.NoError(t, f2.Truncate(100))
+
+		require.NoError(t, f1.Close())
+		require.NoError(t, f2.Close())
+	})
+
+	t.Run("MigrationFailure", func(t *testing.T) {
+		f, err := pool.NewFile()
+		require.NoError(t, err)
+
+		diskPool.EXPECT().NewFile().Return(nil, status.Error(codes.ResourceExhausted, "Out of disk space"))
+
+		testutil.RequireEqualStatus(t, status.Error(codes.ResourceExhausted, "Failed to create file on disk: Out of disk space"), f.Truncate(101))
+
+		require.NoError(t, f.Close())
+	})
+}
@@ -0,0 +1,568 @@
+package virtual
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strings"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/cas"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Identifiers that are stored in file handles of files and directories
+// contained in the directory of an action, following the digest of the
+// action itself.
+const (
+	actionResultNodeActionDirectory = iota
+	actionResultNodeActionResultFile
+	actionResultNodeStdoutFile
+	actionResultNodeStderrFile
+	actionResultNodeOutputsDirectory
+)
+
+var (
+	actionResultFileName      = path.MustNewComponent("action_result.json")
+	actionResultOutputsName   = path.MustNewComponent("outputs")
+	actionResultStderrName    = path.MustNewComponent("stderr")
+	actionResultStdoutName    = path.MustNewComponent("stdout")
+	actionResultJSONMarshaler = protojson.MarshalOptions{Multiline: true}
+)
+
+type actionResultDirectoryOptions struct {
+	context                 context.Context
+	actionCache             blobstore.BlobAccess
+	maximumMessageSizeBytes int
+	casFileFactory          CASFileFactory
+	symlinkFactory          SymlinkFactory
+	errorLogger             util.ErrorLogger
+	handleAllocator         *ResolvableDigestHandleAllocator
+	treeOptions             *casTreeDirectoryOptions
+}
+
+type actionResultDirectory struct {
+	ReadOnlyDirectory
+
+	options        *actionResultDirectoryOptions
+	digestFunction digest.Function
+}
+
+// NewActionResultDirectory creates a Directory that gives access to
+// the results of actions that have been executed previously. Results
+// can be accessed by looking up directories named "${hash}-${size}",
+// corresponding to the digest of the action. These directories are
+// populated by loading the ActionResult message from the Action Cache
+// (AC), and contain the following entries:
+//
+//   - "action_result.json": the ActionResult message in JSON form,
+//     which includes the exit code and execution metadata.
+//   - "stdout" and "stderr": the output streams of the action, if
+//     present.
+//   - "outputs": a directory hierarchy containing all output files,
+//     output directories and output symbolic links, placed at the paths
+//     at which they were created by the action.
+//
+// Files and directories are fetched from the Content Addressable
+// Storage (CAS) lazily. As the AC cannot be enumerated, the directory
+// always appears to be empty when listed.
+//
+// As entries in the AC may be overwritten, the directory of an action
+// is pinned to the ActionResult message that was loaded while looking
+// it up. Files and directories contained in it are identified by the
+// digest of the ActionResult message, making them immutable. File
+// handles that refer to an ActionResult message that has since been
+// overwritten become stale.
+//
+// This directory can be used as a debugging aid, as it allows
+// inspecting the results of remote executions without downloading
+// them explicitly.
+func NewActionResultDirectory(ctx context.Context, actionCache blobstore.BlobAccess, maximumMessageSizeBytes int, directoryFetcher cas.DirectoryFetcher, casFileFactory CASFileFactory, symlinkFactory SymlinkFactory, errorLogger util.ErrorLogger, digestFunction digest.Function, allocation StatelessHandleAllocation) Directory {
+	allocator := allocation.AsStatelessAllocator()
+	options := &actionResultDirectoryOptions{
+		context:                 ctx,
+		actionCache:             actionCache,
+		maximumMessageSizeBytes: maximumMessageSizeBytes,
+		casFileFactory:          casFileFactory,
+		symlinkFactory:          symlinkFactory,
+		errorLogger:             errorLogger,
+		treeOptions: newCASTreeDirectoryOptions(
+			directoryFetcher,
+			casFileFactory,
+			symlinkFactory,
+			errorLogger,
+			allocator.New(ByteSliceID([]byte{1})),
+			/* caseInsensitive = */ false),
+	}
+	options.handleAllocator = NewResolvableDigestHandleAllocator(allocator.New(ByteSliceID([]byte{0})), options.resolve)
+	return &actionResultDirectory{
+		options:        options,
+		digestFunction: digestFunction,
+	}
+}
+
+func (d *actionResultDirectory) VirtualGetAttributes(ctx context.Context, requested AttributesMask, attributes *Attributes) {
+	// The directory of an action changes when its entry in the AC
+	// is overwritten, meaning this directory is not immutable.
+	attributes.SetChangeID(0)
+	attributes.SetFileType(filesystem.FileTypeDirectory)
+	attributes.SetIsImmutable(false)
+	attributes.SetLinkCount(EmptyDirectoryLinkCount)
+	attributes.SetPermissions(PermissionsRead | PermissionsExecute)
+	attributes.SetSizeBytes(0)
+}
+
+func (d *actionResultDirectory) VirtualLookup(ctx context.Context, name path.Component, requested AttributesMask, out *Attributes) (DirectoryChild, Status) {
+	actionDigest, ok := parseDigestFilename(d.digestFunction, name)
+	if !ok {
+		return DirectoryChild{}, StatusErrNoEnt
+	}
+
+	// Only expose directories for actions that have a result, so
+	// that the existence of the directory can be tested.
+	snapshot, s := d.options.getActionResult(ctx, actionDigest)
+	if s != StatusOK {
+		return DirectoryChild{}, s
+	}
+	directory := d.options.lookupActionDirectory(snapshot)
+	directory.VirtualGetAttributes(ctx, requested, out)
+	return DirectoryChild{}.FromDirectory(directory), StatusOK
+}
+
+func (d *actionResultDirectory) VirtualOpenChild(ctx context.Context, name path.Component, shareAccess ShareMask, createAttributes *Attributes, existingOptions *OpenExistingOptions, requested AttributesMask, openedFileAttributes *Attributes) (Leaf, AttributesMask, ChangeInfo, Status) {
+	if _, ok := parseDigestFilename(d.digestFunction, name); !ok {
+		return ReadOnlyDirectoryOpenChildDoesntExist(createAttributes)
+	}
+	if existingOptions == nil {
+		return nil, 0, ChangeInfo{}, StatusErrExist
+	}
+	return nil, 0, ChangeInfo{}, StatusErrIsDir
+}
+
+func (d *actionResultDirectory) VirtualReadDir(ctx context.Context, firstCookie uint64, requested AttributesMask, reporter DirectoryEntryReporter) Status {
+	return StatusOK
+}
+
+// actionResultSnapshot is an ActionResult message that was loaded
+// from the Action Cache, together with the digest of the message.
+type actionResultSnapshot struct {
+	actionDigest       digest.Digest
+	actionResult       *remoteexecution.ActionResult
+	actionResultDigest digest.Digest
+}
+
+// getActionResultHash returns the hash of the ActionResult message in
+// binary form, so that it can be stored in file handles.
+func (s *actionResultSnapshot) getActionResultHash() []byte {
+	hash, err := hex.DecodeString(s.actionResultDigest.GetHashString())
+	if err != nil {
+		panic("Digest hashes are always hexadecimal")
+	}
+	return hash
+}
+
+// getActionResult loads the ActionResult message of an action from
+// the Action Cache. Errors other than the action result being absent
+// are logged, as they cannot be propagated to the user.
+func (o *actionResultDirectoryOptions) getActionResult(ctx context.Context, actionDigest digest.Digest) (*actionResultSnapshot, Status) {
+	m, err := o.actionCache.Get(ctx, actionDigest).ToProto(&remoteexecution.ActionResult{}, o.maximumMessageSizeBytes)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, StatusErrNoEnt
+		}
+		o.errorLogger.Log(util.StatusWrapf(err, "Failed to obtain action result for action %#v", actionDigest.String()))
+		return nil, StatusErrIO
+	}
+	actionResult := m.(*remoteexecution.ActionResult)
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(actionResult)
+	if err != nil {
+		o.errorLogger.Log(util.StatusWrapf(err, "Failed to marshal action result for action %#v", actionDigest.String()))
+		return nil, StatusErrIO
+	}
+	digestGenerator := actionDigest.GetDigestFunction().NewGenerator(int64(len(data)))
+	if _, err := digestGenerator.Write(data); err != nil {
+		panic(err)
+	}
+	return &actionResultSnapshot{
+		actionDigest:       actionDigest,
+		actionResult:       actionResult,
+		actionResultDigest: digestGenerator.Sum(),
+	}, StatusOK
+}
+
+func (o *actionResultDirectoryOptions) newHandleAllocation(snapshot *actionResultSnapshot, id []byte) ResolvableHandleAllocation {
+	actionDigest := snapshot.actionDigest
+	return o.handleAllocator.
+		New(actionDigest).
+		AsResolvableAllocator(func(r io.ByteReader) (DirectoryChild, Status) {
+			return o.resolve(actionDigest, r)
+		}).
+		New(bytes.NewBuffer(append(snapshot.getActionResultHash(), id...)))
+}
+
+func (o *actionResultDirectoryOptions) lookupActionDirectory(snapshot *actionResultSnapshot) Directory {
+	return o.newHandleAllocation(snapshot, []byte{actionResultNodeActionDirectory}).
+		AsStatelessDirectory(&actionResultActionDirectory{
+			options:  o,
+			snapshot: snapshot,
+		})
+}
+
+func (o *actionResultDirectoryOptions) lookupOutputsDirectory(snapshot *actionResultSnapshot, prefix string) Directory {
+	return o.newHandleAllocation(snapshot, append([]byte{actionResultNodeOutputsDirectory}, prefix...)).
+		AsStatelessDirectory(&actionResultOutputsDirectory{
+			options:  o,
+			snapshot: snapshot,
+			prefix:   prefix,
+		})
+}
+
+// lookupByteSliceFile creates a file whose contents are derived from
+// the ActionResult message, such as "action_result.json", or "stdout"
+// and "stderr" in case the output streams are stored inline.
+func (o *actionResultDirectoryOptions) lookupByteSliceFile(snapshot *actionResultSnapshot, kind byte, contents []byte) Leaf {
+	return o.newHandleAllocation(snapshot, []byte{kind}).
+		AsLeaf(NewByteSliceFile(contents))
+}
+
+func (o *actionResultDirectoryOptions) resolve(actionDigest digest.Digest, remainder io.ByteReader) (DirectoryChild, Status) {
+	// File handles contain the hash of the ActionResult message
+	// that was used to create the file or directory. If the entry
+	// in the Action Cache has been overwritten since, the file
+	// handle is stale.
+	snapshot, s := o.getActionResult(o.context, actionDigest)
+	if s == StatusErrNoEnt {
+		return DirectoryChild{}, StatusErrStale
+	} else if s != StatusOK {
+		return DirectoryChild{}, s
+	}
+	for _, c := range snapshot.getActionResultHash() {
+		if b, err := remainder.ReadByte(); err != nil {
+			return DirectoryChild{}, StatusErrBadHandle
+		} else if b != c {
+			return DirectoryChild{}, StatusErrStale
+		}
+	}
+
+	kind, err := remainder.ReadByte()
+	if err != nil {
+		return DirectoryChild{}, StatusErrBadHandle
+	}
+	switch kind {
+	case actionResultNodeActionDirectory:
+		return DirectoryChild{}.FromDirectory(o.lookupActionDirectory(snapshot)), StatusOK
+	case actionResultNodeActionResultFile, actionResultNodeStdoutFile, actionResultNodeStderrFile:
+		entries, _, s := o.getActionDirectoryEntries(snapshot)
+		if s != StatusOK {
+			return DirectoryChild{}, s
+		}
+		name := map[byte]path.Component{
+			actionResultNodeActionResultFile: actionResultFileName,
+			actionResultNodeStdoutFile:       actionResultStdoutName,
+			actionResultNodeStderrFile:       actionResultStderrName,
+		}[kind]
+		if child, ok := entries.lookup(name); ok {
+			return child, StatusOK
+		}
+		return DirectoryChild{}, StatusErrStale
+	case actionResultNodeOutputsDirectory:
+		var prefix []byte
+		for {
+			c, err := remainder.ReadByte()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return DirectoryChild{}, StatusErrBadHandle
+			}
+			prefix = append(prefix, c)
+		}
+		return DirectoryChild{}.FromDirectory(o.lookupOutputsDirectory(snapshot, string(prefix))), StatusOK
+	default:
+		return DirectoryChild{}, StatusErrBadHandle
+	}
+}
+
+// lookupOutputStream creates a file for the standard output or error
+// stream of an action. These may either be stored in the CAS, or be
+// inlined into the ActionResult message.
+func (o *actionResultDirectoryOptions) lookupOutputStream(snapshot *actionResultSnapshot, kind byte, streamDigest *remoteexecution.Digest, streamRaw []byte) (Leaf, bool, error) {
+	if streamDigest != nil {
+		blobDigest, err := snapshot.actionDigest.GetDigestFunction().NewDigestFromProto(streamDigest)
+		if err != nil {
+			return nil, false, err
+		}
+		return o.casFileFactory.LookupFile(blobDigest, false, nil), true, nil
+	}
+	if len(streamRaw) > 0 {
+		return o.lookupByteSliceFile(snapshot, kind, streamRaw), true, nil
+	}
+	return nil, false, nil
+}
+
+func (o *actionResultDirectoryOptions) getActionDirectoryEntries(snapshot *actionResultSnapshot) (staticDirectoryEntryList, uint32, Status) {
+	actionResult := snapshot.actionResult
+	actionResultJSON, err := actionResultJSONMarshaler.Marshal(actionResult)
+	if err != nil {
+		o.errorLogger.Log(util.StatusWrapf(err, "Failed to marshal action result for action %#v", snapshot.actionDigest.String()))
+		return nil, 0, StatusErrIO
+	}
+	entries := staticDirectoryEntryList{
+		{
+			name:  actionResultFileName,
+			child: DirectoryChild{}.FromLeaf(o.lookupByteSliceFile(snapshot, actionResultNodeActionResultFile, actionResultJSON)),
+		},
+		{
+			name:  actionResultOutputsName,
+			child: DirectoryChild{}.FromDirectory(o.lookupOutputsDirectory(snapshot, "")),
+		},
+	}
+
+	for _, stream := range []struct {
+		name   path.Component
+		kind   byte
+		digest *remoteexecution.Digest
+		raw    []byte
+	}{
+		{actionResultStdoutName, actionResultNodeStdoutFile, actionResult.StdoutDigest, actionResult.StdoutRaw},
+		{actionResultStderrName, actionResultNodeStderrFile, actionResult.StderrDigest, actionResult.StderrRaw},
+	} {
+		leaf, ok, err := o.lookupOutputStream(snapshot, stream.kind, stream.digest, stream.raw)
+		if err != nil {
+			o.errorLogger.Log(util.StatusWrapf(err, "Action %#v: Failed to obtain digest for %s", snapshot.actionDigest.String(), stream.name))
+			return nil, 0, StatusErrIO
+		}
+		if ok {
+			entries = append(entries, staticDirectoryEntry{
+				name:  stream.name,
+				child: DirectoryChild{}.FromLeaf(leaf),
+			})
+		}
+	}
+
+	sort.Sort(entries)
+	return entries, EmptyDirectoryLinkCount + 1, StatusOK
+}
+
+// actionResultActionDirectory is the directory corresponding to a
+// single action, containing the action result, output streams and
+// outputs of the action.
+type actionResultActionDirectory struct {
+	ReadOnlyDirectory
+
+	options  *actionResultDirectoryOptions
+	snapshot *actionResultSnapshot
+}
+
+func (d *actionResultActionDirectory) getEntries(ctx context.Context) (staticDirectoryEntryList, uint32, Status) {
+	return d.options.getActionDirectoryEntries(d.snapshot)
+}
+
+func (d *actionResultActionDirectory) VirtualGetAttributes(ctx context.Context, requested AttributesMask, attributes *Attributes) {
+	attributes.SetChangeID(0)
+	attributes.SetFileType(filesystem.FileTypeDirectory)
+	attributes.SetIsImmutable(true)
+	attributes.SetLinkCount(EmptyDirectoryLinkCount + 1)
+	attributes.SetPermissions(PermissionsRead | PermissionsExecute)
+	attributes.SetSizeBytes(0)
+}
+
+func (d *actionResultActionDirectory) VirtualLookup(ctx context.Context, name path.Component, requested AttributesMask, out *Attributes) (DirectoryChild, Status) {
+	entries, _, s := d.getEntries(ctx)
+	if s != StatusOK {
+		return DirectoryChild{}, s
+	}
+	return entries.virtualLookup(ctx, name, requested, out)
+}
+
+func (d *actionResultActionDirectory) VirtualOpenChild(ctx context.Context, name path.Component, shareAccess ShareMask, createAttributes *Attributes, existingOptions *OpenExistingOptions, requested AttributesMask, openedFileAttributes *Attributes) (Leaf, AttributesMask, ChangeInfo, Status) {
+	entries, _, s := d.getEntries(ctx)
+	if s != StatusOK {
+		return nil, 0, ChangeInfo{}, s
+	}
+	return entries.virtualOpenChild(ctx, name, shareAccess, createAttributes, existingOptions, requested, openedFileAttributes)
+}
+
+func (d *actionResultActionDirectory) VirtualReadDir(ctx context.Context, firstCookie uint64, requested AttributesMask, reporter DirectoryEntryReporter) Status {
+	entries, _, s := d.getEntries(ctx)
+	if s != StatusOK {
+		return s
+	}
+	return entries.virtualReadDir(ctx, firstCookie, requested, reporter)
+}
+
+// actionResultOutputsDirectory is a directory containing the outputs
+// of an action. As the ActionResult message stores outputs by path,
+// directories are created for every distinct path prefix.
+type actionResultOutputsDirectory struct {
+	ReadOnlyDirectory
+
+	options  *actionResultDirectoryOptions
+	snapshot *actionResultSnapshot
+	prefix   string
+}
+
+// childPath returns the path of an output relative to the directory,
+// and whether it is located in this directory.
+func (d *actionResultOutputsDirectory) childPath(outputPath string) (string, bool) {
+	if d.prefix == "" {
+		return outputPath, true
+	}
+	if !strings.HasPrefix(outputPath, d.prefix+"/") {
+		return "", false
+	}
+	return outputPath[len(d.prefix)+1:], true
+}
+
+func (d *actionResultOutputsDirectory) getEntriesUnwrapped(actionResult *remoteexecution.ActionResult) (staticDirectoryEntryList, uint32, error) {
+	digestFunction := d.snapshot.actionDigest.GetDigestFunction()
+	children := map[path.Component]DirectoryChild{}
+	intermediateDirectories := map[path.Component]struct{}{}
+
+	// addOutput registers an output in the directory. If the output
+	// is not a direct child, an intermediate directory is created.
+	addOutput := func(outputPath string, createChild func() (DirectoryChild, error)) error {
+		childPath, ok := d.childPath(outputPath)
+		if !ok {
+			return nil
+		}
+		name, remainder, isIntermediate := strings.Cut(childPath, "/")
+		component, ok := path.NewComponent(name)
+		if !ok {
+			return status.Errorf(codes.InvalidArgument, "Output path %#v contains an invalid pathname component", outputPath)
+		}
+		if _, ok := children[component]; ok {
+			return status.Errorf(codes.InvalidArgument, "Output path %#v conflicts with another output", outputPath)
+		}
+		if isIntermediate {
+			if remainder == "" {
+				return status.Errorf(codes.InvalidArgument, "Output path %#v has a trailing slash", outputPath)
+			}
+			intermediateDirectories[component] = struct{}{}
+			return nil
+		}
+		if _, ok := intermediateDirectories[component]; ok {
+			return status.Errorf(codes.InvalidArgument, "Output path %#v conflicts with another output", outputPath)
+		}
+		child, err := createChild()
+		if err != nil {
+			return util.StatusWrapf(err, "Output path %#v", outputPath)
+		}
+		children[component] = child
+		return nil
+	}
+
+	for _, entry := range actionResult.OutputFiles {
+		if err := addOutput(entry.Path, func() (DirectoryChild, error) {
+			fileDigest, err := digestFunction.NewDigestFromProto(entry.Digest)
+			if err != nil {
+				return DirectoryChild{}, util.StatusWrap(err, "Failed to obtain digest")
+			}
+			return DirectoryChild{}.FromLeaf(d.options.casFileFactory.LookupFile(fileDigest, entry.IsExecutable, nil)), nil
+		}); err != nil {
+			return nil, 0, err
+		}
+	}
+	for _, entry := range actionResult.OutputDirectories {
+		if err := addOutput(entry.Path, func() (DirectoryChild, error) {
+			treeDigest, err := digestFunction.NewDigestFromProto(entry.TreeDigest)
+			if err != nil {
+				return DirectoryChild{}, util.StatusWrap(err, "Failed to obtain tree digest")
+			}
+			return DirectoryChild{}.FromDirectory(d.options.treeOptions.lookupRootDirectory(treeDigest)), nil
+		}); err != nil {
+			return nil, 0, err
+		}
+	}
+	for _, entry := range actionResult.OutputSymlinks {
+		if err := addOutput(entry.Path, func() (DirectoryChild, error) {
+			return DirectoryChild{}.FromLeaf(d.options.symlinkFactory.LookupSymlink([]byte(entry.Target))), nil
+		}); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	for component := range intermediateDirectories {
+		childPrefix := component.String()
+		if d.prefix != "" {
+			childPrefix = d.prefix + "/" + childPrefix
+		}
+		children[component] = DirectoryChild{}.FromDirectory(d.options.lookupOutputsDirectory(d.snapshot, childPrefix))
+	}
+
+	entries := make(staticDirectoryEntryList, 0, len(children))
+	linkCount := EmptyDirectoryLinkCount
+	for name, child := range children {
+		entries = append(entries, staticDirectoryEntry{
+			name:  name,
+			child: child,
+		})
+		if directory, _ := child.GetPair(); directory != nil {
+			linkCount++
+		}
+	}
+	sort.Sort(entries)
+	return entries, linkCount, nil
+}
+
+func (d *actionResultOutputsDirectory) getEntries(ctx context.Context) (staticDirectoryEntryList, uint32, Status) {
+	entries, linkCount, err := d.getEntriesUnwrapped(d.snapshot.actionResult)
+	if err != nil {
+		d.options.errorLogger.Log(util.StatusWrapf(err, "Action %#v", d.snapshot.actionDigest.String()))
+		return nil, 0, StatusErrIO
+	}
+	return entries, linkCount, StatusOK
+}
+
+func (d *actionResultOutputsDirectory) VirtualGetAttributes(ctx context.Context, requested AttributesMask, attributes *Attributes) {
+	attributes.SetChangeID(0)
+	attributes.SetFileType(filesystem.FileTypeDirectory)
+	attributes.SetIsImmutable(true)
+	attributes.SetPermissions(PermissionsRead | PermissionsExecute)
+	attributes.SetSizeBytes(0)
+
+	// Only compute the link count when requested, as it depends on
+	// the number of subdirectories.
+	if requested&AttributesMaskLinkCount != 0 {
+		if _, linkCount, s := d.getEntries(ctx); s == StatusOK {
+			attributes.SetLinkCount(linkCount)
+		} else {
+			attributes.SetLinkCount(EmptyDirectoryLinkCount)
+		}
+	}
+}
+
+func (d *actionResultOutputsDirectory) VirtualLookup(ctx context.Context, name path.Component, requested AttributesMask, out *Attributes) (DirectoryChild, Status) {
+	entries, _, s := d.getEntries(ctx)
+	if s != StatusOK {
+		return DirectoryChild{}, s
+	}
+	return entries.virtualLookup(ctx, name, requested, out)
+}
+
+func (d *actionResultOutputsDirectory) VirtualOpenChild(ctx context.Context, name path.Component, shareAccess ShareMask, createAttributes *Attributes, existingOptions *OpenExistingOptions, requested AttributesMask, openedFileAttributes *Attributes) (Leaf, AttributesMask, ChangeInfo, Status) {
+	entries, _, s := d.getEntries(ctx)
+	if s != StatusOK {
+		return nil, 0, ChangeInfo{}, s
+	}
+	return entries.virtualOpenChild(ctx, name, shareAccess, createAttributes, existingOptions, requested, openedFileAttributes)
+}
+
+func (d *actionResultOutputsDirectory) VirtualReadDir(ctx context.Context, firstCookie uint64, requested AttributesMask, reporter DirectoryEntryReporter) Status {
+	entries, _, s := d.getEntries(ctx)
+	if s != StatusOK {
+		return s
+	}
+	return entries.virtualReadDir(ctx, firstCookie, requested, reporter)
+}
@@ -0,0 +1,217 @@
+package virtual_test
+
+import (
+	"context"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/random"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestActionResultDirectory(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	actionCache := mock.NewMockBlobAccess(ctrl)
+	directoryFetcher := mock.NewMockDirectoryFetcher(ctrl)
+	casFileFactory := mock.NewMockCASFileFactory(ctrl)
+	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	handleAllocator := virtual.NewNFSHandleAllocator(random.NewFastSingleThreadedGenerator())
+	d := virtual.NewActionResultDirectory(
+		ctx,
+		actionCache,
+		/* maximumMessageSizeBytes = */ 10000,
+		directoryFetcher,
+		casFileFactory,
+		symlinkFactory,
+		errorLogger,
+		digest.MustNewFunction("example", remoteexecution.DigestFunction_MD5),
+		handleAllocator.New())
+
+	actionDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "ad0f2fd4c3c2ea0e1d7dcbf1da2c7aed", 123)
+	actionName := path.MustNewComponent("ad0f2fd4c3c2ea0e1d7dcbf1da2c7aed-123")
+
+	t.Run("InvalidFilename", func(t *testing.T) {
+		for _, name := range []string{
+			"hello",
+			"ad0f2fd4c3c2ea0e1d7dcbf1da2c7aed",
+			"ad0f2fd4c3c2ea0e1d7dcbf1da2c7aed-",
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855-0",
+		} {
+			var out virtual.Attributes
+			_, s := d.VirtualLookup(ctx, path.MustNewComponent(name), 0, &out)
+			require.Equal(t, virtual.StatusErrNoEnt, s, name)
+		}
+	})
+
+	t.Run("Attributes", func(t *testing.T) {
+		// Entries in the Action Cache may be overwritten,
+		// meaning the directory is not immutable.
+		var attributes virtual.Attributes
+		d.VirtualGetAttributes(ctx, virtual.AttributesMaskIsImmutable, &attributes)
+		require.False(t, attributes.GetIsImmutable())
+	})
+
+	t.Run("ReadDir", func(t *testing.T) {
+		// The directory can't be listed, as the Action Cache
+		// cannot be enumerated.
+		reporter := mock.NewMockDirectoryEntryReporter(ctrl)
+		require.Equal(t, virtual.StatusOK, d.VirtualReadDir(ctx, 0, 0, reporter))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		// Actions for which no result exists should not be
+		// visible.
+		actionCache.EXPECT().Get(ctx, actionDigest).
+			Return(buffer.NewBufferFromError(status.Error(codes.NotFound, "Object not found")))
+
+		var out virtual.Attributes
+		_, s := d.VirtualLookup(ctx, actionName, 0, &out)
+		require.Equal(t, virtual.StatusErrNoEnt, s)
+	})
+
+	t.Run("StorageFailure", func(t *testing.T) {
+		actionCache.EXPECT().Get(ctx, actionDigest).
+			Return(buffer.NewBufferFromError(status.Error(codes.Internal, "Server failure")))
+		errorLogger.EXPECT().Log(testutil.EqStatus(t, status.Error(codes.Internal, "Failed to obtain action result for action \"3-ad0f2fd4c3c2ea0e1d7dcbf1da2c7aed-123-example\": Server failure")))
+
+		var out virtual.Attributes
+		_, s := d.VirtualLookup(ctx, actionName, 0, &out)
+		require.Equal(t, virtual.StatusErrIO, s)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		actionResult := &remoteexecution.ActionResult{
+			OutputFiles: []*remoteexecution.OutputFile{
+				{
+					Path: "bazel-out/k8-fastbuild/bin/hello",
+					Digest: &remoteexecution.Digest{
+						Hash:      "8b1a9953c4611296a827abf8c47804d7",
+						SizeBytes: 5,
+					},
+					IsExecutable: true,
+				},
+			},
+			OutputDirectories: []*remoteexecution.OutputDirectory{
+				{
+					Path: "bazel-out/k8-fastbuild/bin/tree",
+					TreeDigest: &remoteexecution.Digest{
+						Hash:      "ed56cd683c99acdff14b77db249819fc",
+						SizeBytes: 234,
+					},
+				},
+			},
+			OutputSymlinks: []*remoteexecution.OutputSymlink{
+				{
+					Path:   "link",
+					Target: "bazel-out/k8-fastbuild/bin/hello",
+				},
+			},
+			ExitCode:  1,
+			StderrRaw: []byte("Compilation failed\n"),
+		}
+		actionCache.EXPECT().Get(ctx, actionDigest).
+			Return(buffer.NewProtoBufferFromProto(actionResult, buffer.UserProvided))
+		var out virtual.Attributes
+		child, s := d.VirtualLookup(ctx, actionName, 0, &out)
+		require.Equal(t, virtual.StatusOK, s)
+		actionDirectory, _ := child.GetPair()
+		require.NotNil(t, actionDirectory)
+
+		// The action directory should contain the action
+		// result, the standard error output that was stored
+		// inline, and the outputs. Standard output is absent.
+		// As the action directory is pinned to the ActionResult
+		// message that was loaded during lookup, the Action
+		// Cache should not be consulted again.
+		reporter := mock.NewMockDirectoryEntryReporter(ctrl)
+		gomock.InOrder(
+			reporter.EXPECT().ReportEntry(uint64(1), path.MustNewComponent("action_result.json"), gomock.Any(), gomock.Any()).Return(true),
+			reporter.EXPECT().ReportEntry(uint64(2), path.MustNewComponent("outputs"), gomock.Any(), gomock.Any()).Return(true),
+			reporter.EXPECT().ReportEntry(uint64(3), path.MustNewComponent("stderr"), gomock.Any(), gomock.Any()).Return(true))
+		require.Equal(t, virtual.StatusOK, actionDirectory.VirtualReadDir(ctx, 0, 0, reporter))
+
+		child, s = actionDirectory.VirtualLookup(ctx, path.MustNewComponent("stderr"), 0, &out)
+		require.Equal(t, virtual.StatusOK, s)
+		_, stderr := child.GetPair()
+		var buf [100]byte
+		n, eof, s := stderr.VirtualRead(buf[:], 0)
+		require.Equal(t, virtual.StatusOK, s)
+		require.True(t, eof)
+		require.Equal(t, []byte("Compilation failed\n"), buf[:n])
+
+		// Intermediate directories should be created for
+		// outputs that are stored in subdirectories.
+		child, s = actionDirectory.VirtualLookup(ctx, path.MustNewComponent("outputs"), 0, &out)
+		require.Equal(t, virtual.StatusOK, s)
+		outputsDirectory, _ := child.GetPair()
+		require.NotNil(t, outputsDirectory)
+
+		symlink := mock.NewMockNativeLeaf(ctrl)
+		symlinkFactory.EXPECT().LookupSymlink([]byte("bazel-out/k8-fastbuild/bin/hello")).Return(symlink)
+		symlink.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMask(0), gomock.Any())
+		gomock.InOrder(
+			reporter.EXPECT().ReportEntry(uint64(1), path.MustNewComponent("bazel-out"), gomock.Any(), gomock.Any()).Return(true),
+			reporter.EXPECT().ReportEntry(uint64(2), path.MustNewComponent("link"), virtual.DirectoryChild{}.FromLeaf(symlink), gomock.Any()).Return(true))
+		require.Equal(t, virtual.StatusOK, outputsDirectory.VirtualReadDir(ctx, 0, 0, reporter))
+
+		binDirectory := outputsDirectory
+		for _, name := range []string{"bazel-out", "k8-fastbuild", "bin"} {
+			symlinkFactory.EXPECT().LookupSymlink([]byte("bazel-out/k8-fastbuild/bin/hello")).Return(symlink).MaxTimes(1)
+			child, s = binDirectory.VirtualLookup(ctx, path.MustNewComponent(name), 0, &out)
+			require.Equal(t, virtual.StatusOK, s)
+			binDirectory, _ = child.GetPair()
+			require.NotNil(t, binDirectory)
+		}
+
+		// Output files and directories should be backed by
+		// the CAS.
+		file := mock.NewMockNativeLeaf(ctrl)
+		casFileFactory.EXPECT().LookupFile(
+			digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5),
+			/* isExecutable = */ true,
+			/* readMonitor = */ nil,
+		).Return(file)
+		file.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMask(0), gomock.Any())
+		gomock.InOrder(
+			reporter.EXPECT().ReportEntry(uint64(1), path.MustNewComponent("hello"), virtual.DirectoryChild{}.FromLeaf(file), gomock.Any()).Return(true),
+			reporter.EXPECT().ReportEntry(uint64(2), path.MustNewComponent("tree"), gomock.Any(), gomock.Any()).Return(true))
+		require.Equal(t, virtual.StatusOK, binDirectory.VirtualReadDir(ctx, 0, 0, reporter))
+
+		// If the entry in the Action Cache is overwritten,
+		// subsequent lookups should yield the new results.
+		// Directories that were looked up previously should
+		// remain unaltered.
+		actionCache.EXPECT().Get(ctx, actionDigest).
+			Return(buffer.NewProtoBufferFromProto(&remoteexecution.ActionResult{
+				StdoutRaw: []byte("Hello\n"),
+			}, buffer.UserProvided))
+		child, s = d.VirtualLookup(ctx, actionName, 0, &out)
+		require.Equal(t, virtual.StatusOK, s)
+		newActionDirectory, _ := child.GetPair()
+		require.NotNil(t, newActionDirectory)
+
+		gomock.InOrder(
+			reporter.EXPECT().ReportEntry(uint64(1), path.MustNewComponent("action_result.json"), gomock.Any(), gomock.Any()).Return(true),
+			reporter.EXPECT().ReportEntry(uint64(2), path.MustNewComponent("outputs"), gomock.Any(), gomock.Any()).Return(true),
+			reporter.EXPECT().ReportEntry(uint64(3), path.MustNewComponent("stdout"), gomock.Any(), gomock.Any()).Return(true))
+		require.Equal(t, virtual.StatusOK, newActionDirectory.VirtualReadDir(ctx, 0, 0, reporter))
+
+		gomock.InOrder(
+			reporter.EXPECT().ReportEntry(uint64(1), path.MustNewComponent("action_result.json"), gomock.Any(), gomock.Any()).Return(true),
+			reporter.EXPECT().ReportEntry(uint64(2), path.MustNewComponent("outputs"), gomock.Any(), gomock.Any()).Return(true),
+			reporter.EXPECT().ReportEntry(uint64(3), path.MustNewComponent("stderr"), gomock.Any(), gomock.Any()).Return(true))
+		require.Equal(t, virtual.StatusOK, actionDirectory.VirtualReadDir(ctx, 0, 0, reporter))
+	})
+}
@@ -27,9 +27,18 @@ const (
 	AttributesMaskFileType
 	// AttributesMaskInodeNumber requests the inode number (st_ino).
 	AttributesMaskInodeNumber
+	// AttributesMaskIsImmutable requests whether the node is
+	// immutable, meaning its contents and attributes never change.
+	AttributesMaskIsImmutable
+	// AttributesMaskLastDataAccessTime requests the last data
+	// access time (st_atim).
+	AttributesMaskLastDataAccessTime
 	// AttributesMaskLastDataModificationTime requests the last data
 	// modification time (st_mtim).
 	AttributesMaskLastDataModificationTime
+	// AttributesMaskLastStatusChangeTime requests the last file
+	// status change time (st_ctim).
+	AttributesMaskLastStatusChangeTime
 	// AttributesMaskLinkCount requests the link count (st_nlink).
 	AttributesMaskLinkCount
 	// AttributesMaskPermissions requests the permissions (lowest 12
@@ -49,7 +58,10 @@ type Attributes struct {
 	fileHandle               []byte
 	fileType                 filesystem.FileType
 	inodeNumber              uint64
+	isImmutable              bool
+	lastDataAccessTime       time.Time
 	lastDataModificationTime time.Time
+	lastStatusChangeTime     time.Time
 	linkCount                uint32
 	permissions              Permissions
 	sizeBytes                uint64
@@ -135,6 +147,34 @@ func (a *Attributes) SetInodeNumber(inodeNumber uint64) *Attributes {
 	return a
 }
 
+// GetIsImmutable returns whether the node is immutable, meaning its
+// contents and attributes never change. Clients may use this to cache
+// attributes of the node for a longer amount of time. Nodes that don't
+// set this attribute are assumed to be mutable.
+func (a *Attributes) GetIsImmutable() bool {
+	return a.isImmutable && a.fieldsPresent&AttributesMaskIsImmutable != 0
+}
+
+// SetIsImmutable sets whether the node is immutable, meaning its
+// contents and attributes never change.
+func (a *Attributes) SetIsImmutable(isImmutable bool) *Attributes {
+	a.isImmutable = isImmutable
+	a.fieldsPresent |= AttributesMaskIsImmutable
+	return a
+}
+
+// GetLastDataAccessTime returns the last data access time (st_atim).
+func (a *Attributes) GetLastDataAccessTime() (time.Time, bool) {
+	return a.lastDataAccessTime, a.fieldsPresent&AttributesMaskLastDataAccessTime != 0
+}
+
+// SetLastDataAccessTime sets the last data access time (st_atim).
+func (a *Attributes) SetLastDataAccessTime(lastDataAccessTime time.Time) *Attributes {
+	a.lastDataAccessTime = lastDataAccessTime
+	a.fieldsPresent |= AttributesMaskLastDataAccessTime
+	return a
+}
+
 // GetLastDataModificationTime returns the last data modification time
 // (st_mtim).
 func (a *Attributes) GetLastDataModificationTime() (time.Time, bool) {
@@ -149,6 +189,20 @@ func (a *Attributes) SetLastDataModificationTime(lastDataModificationTime time.T
 	return a
 }
 
+// GetLastStatusChangeTime returns the last file status change time
+// (st_ctim).
+func (a *Attributes) GetLastStatusChangeTime() (time.Time, bool) {
+	return a.lastStatusChangeTime, a.fieldsPresent&AttributesMaskLastStatusChangeTime != 0
+}
+
+// SetLastStatusChangeTime sets the last file status change time
+// (st_ctim).
+func (a *Attributes) SetLastStatusChangeTime(lastStatusChangeTime time.Time) *Attributes {
+	a.lastStatusChangeTime = lastStatusChangeTime
+	a.fieldsPresent |= AttributesMaskLastStatusChangeTime
+	return a
+}
+
 // GetLinkCount returns the link count (st_nlink).
 func (a *Attributes) GetLinkCount() uint32 {
 	if a.fieldsPresent&AttributesMaskLinkCount == 0 {
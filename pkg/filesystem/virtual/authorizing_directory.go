@@ -0,0 +1,313 @@
+package virtual
+
+import (
+	"context"
+
+	"github.com/buildbarn/bb-storage/pkg/auth"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+)
+
+// authorize checks whether the caller whose authentication metadata
+// is stored in the context is permitted to access the file system.
+func authorize(ctx context.Context, authorizer auth.Authorizer) Status {
+	if auth.AuthorizeSingleInstanceName(ctx, authorizer, digest.EmptyInstanceName) != nil {
+		return StatusErrAccess
+	}
+	return StatusOK
+}
+
+// AuthorizeDirectoryModification checks whether the caller whose
+// authentication metadata is stored in the context is permitted to
+// create directories in, rename entries in, or remove entries from a
+// directory. These operations are performed through methods of
+// Directory that don't receive a context, meaning that
+// NewAuthorizingDirectory() cannot authorize them. Directories that
+// are not created by NewAuthorizingDirectory() may always be modified.
+func AuthorizeDirectoryModification(ctx context.Context, directory Directory) Status {
+	if d, ok := directory.(*authorizingDirectory); ok {
+		return authorize(ctx, d.authorizer)
+	}
+	return StatusOK
+}
+
+type authorizingDirectory struct {
+	base       Directory
+	authorizer auth.Authorizer
+}
+
+// NewAuthorizingDirectory creates a decorator for Directory that only
+// permits access to the directory and its children if the caller is
+// authorized to do so. Authorization is performed against the
+// authentication metadata stored in the context of each operation,
+// such as the metadata that is extracted from FUSE request headers by
+// fuse.NewInHeaderAuthenticator(). This makes it possible to prevent
+// other users on the same system from accessing the contents of a
+// mount.
+//
+// Child directories and leaves returned by this directory are
+// decorated as well. Operations that don't receive a context (e.g.,
+// reading from or writing to a file that has already been opened) are
+// not checked, as these can only be performed on nodes that were
+// obtained through operations that are checked.
+//
+// Creating directories, renaming and removing entries are performed
+// through methods that don't receive a context either. As the kernel
+// may issue these against directories that it has cached, without
+// looking them up again, callers must authorize these operations by
+// calling AuthorizeDirectoryModification() first.
+func NewAuthorizingDirectory(base Directory, authorizer auth.Authorizer) Directory {
+	return &authorizingDirectory{
+		base:       base,
+		authorizer: authorizer,
+	}
+}
+
+func (d *authorizingDirectory) wrapDirectory(directory Directory) Directory {
+	return &authorizingDirectory{
+		base:       directory,
+		authorizer: d.authorizer,
+	}
+}
+
+func (d *authorizingDirectory) wrapLeaf(leaf Leaf) Leaf {
+	return &authorizingLeaf{
+		Leaf:       leaf,
+		authorizer: d.authorizer,
+	}
+}
+
+func (d *authorizingDirectory) wrapChild(child DirectoryChild) DirectoryChild {
+	if directory, leaf := child.GetPair(); directory != nil {
+		return child.FromDirectory(d.wrapDirectory(directory))
+	} else {
+		return child.FromLeaf(d.wrapLeaf(leaf))
+	}
+}
+
+func (d *authorizingDirectory) VirtualGetAttributes(ctx context.Context, requested AttributesMask, attributes *Attributes) {
+	// There is no way to report failures. Attributes don't
+	// contain any of the directory's contents, so it is safe to
+	// return them unconditionally.
+	d.base.VirtualGetAttributes(ctx, requested, attributes)
+}
+
+func (d *authorizingDirectory) VirtualSetAttributes(ctx context.Context, in *Attributes, requested AttributesMask, attributes *Attributes) Status {
+	if s := authorize(ctx, d.authorizer); s != StatusOK {
+		return s
+	}
+	return d.base.VirtualSetAttributes(ctx, in, requested, attributes)
+}
+
+func (d *authorizingDirectory) VirtualGetXAttr(ctx context.Context, name string) ([]byte, Status) {
+	if s := authorize(ctx, d.authorizer); s != StatusOK {
+		return nil, s
+	}
+	return d.base.VirtualGetXAttr(ctx, name)
+}
+
+func (d *authorizingDirectory) VirtualListXAttr(ctx context.Context) ([]string, Status) {
+	if s := authorize(ctx, d.authorizer); s != StatusOK {
+		return nil, s
+	}
+	return d.base.VirtualListXAttr(ctx)
+}
+
+func (d *authorizingDirectory) VirtualRemoveXAttr(ctx context.Context, name string) Status {
+	if s := authorize(ctx, d.authorizer); s != StatusOK {
+		return s
+	}
+	return d.base.VirtualRemoveXAttr(ctx, name)
+}
+
+func (d *authorizingDirectory) VirtualSetXAttr(ctx context.Context, name string, value []byte, mode XAttrSetMode) Status {
+	if s := authorize(ctx, d.authorizer); s != StatusOK {
+		return s
+	}
+	return d.base.VirtualSetXAttr(ctx, name, value, mode)
+}
+
+func (d *authorizingDirectory) VirtualOpenChild(ctx context.Context, name path.Component, shareAccess ShareMask, createAttributes *Attributes, existingOptions *OpenExistingOptions, requested AttributesMask, openedFileAttributes *Attributes) (Leaf, AttributesMask, ChangeInfo, Status) {
+	if s := authorize(ctx, d.authorizer); s != StatusOK {
+		return nil, 0, ChangeInfo{}, s
+	}
+	leaf, respected, changeInfo, s := d.base.VirtualOpenChild(ctx, name, shareAccess, createAttributes, existingOptions, requested, openedFileAttributes)
+	if s != StatusOK {
+		return nil, 0, ChangeInfo{}, s
+	}
+	return d.wrapLeaf(leaf), respected, changeInfo, StatusOK
+}
+
+func (d *authorizingDirectory) VirtualOpenTemporaryFile(ctx context.Context, shareAccess ShareMask, createAttributes *Attributes, requested AttributesMask, openedFileAttributes *Attributes) (Leaf, AttributesMask, Status) {
+	if s := authorize(ctx, d.authorizer); s != StatusOK {
+		return nil, 0, s
+	}
+	leaf, respected, s := d.base.VirtualOpenTemporaryFile(ctx, shareAccess, createAttributes, requested, openedFileAttributes)
+	if s != StatusOK {
+		return nil, 0, s
+	}
+	return d.wrapLeaf(leaf), respected, StatusOK
+}
+
+func (d *authorizingDirectory) VirtualLink(ctx context.Context, name path.Component, leaf Leaf, requested AttributesMask, attributes *Attributes) (ChangeInfo, Status) {
+	if s := authorize(ctx, d.authorizer); s != StatusOK {
+		return ChangeInfo{}, s
+	}
+	return d.base.VirtualLink(ctx, name, unwrapAuthorizingLeaf(leaf), requested, attributes)
+}
+
+func (d *authorizingDirectory) VirtualLookup(ctx context.Context, name path.Component, requested AttributesMask, out *Attributes) (DirectoryChild, Status) {
+	if s := authorize(ctx, d.authorizer); s != StatusOK {
+		return DirectoryChild{}, s
+	}
+	child, s := d.base.VirtualLookup(ctx, name, requested, out)
+	if s != StatusOK {
+		return DirectoryChild{}, s
+	}
+	return d.wrapChild(child), StatusOK
+}
+
+func (d *authorizingDirectory) VirtualMkdir(name path.Component, requested AttributesMask, attributes *Attributes) (Directory, ChangeInfo, Status) {
+	directory, changeInfo, s := d.base.VirtualMkdir(name, requested, attributes)
+	if s != StatusOK {
+		return nil, ChangeInfo{}, s
+	}
+	return d.wrapDirectory(directory), changeInfo, StatusOK
+}
+
+func (d *authorizingDirectory) VirtualMknod(ctx context.Context, name path.Component, fileType filesystem.FileType, requested AttributesMask, attributes *Attributes) (Leaf, ChangeInfo, Status) {
+	if s := authorize(ctx, d.authorizer); s != StatusOK {
+		return nil, ChangeInfo{}, s
+	}
+	leaf, changeInfo, s := d.base.VirtualMknod(ctx, name, fileType, requested, attributes)
+	if s != StatusOK {
+		return nil, ChangeInfo{}, s
+	}
+	return d.wrapLeaf(leaf), changeInfo, StatusOK
+}
+
+func (d *authorizingDirectory) VirtualReadDir(ctx context.Context, firstCookie uint64, requested AttributesMask, reporter DirectoryEntryReporter) Status {
+	if s := authorize(ctx, d.authorizer); s != StatusOK {
+		return s
+	}
+	return d.base.VirtualReadDir(ctx, firstCookie, requested, &authorizingDirectoryEntryReporter{
+		base:      reporter,
+		directory: d,
+	})
+}
+
+func (d *authorizingDirectory) VirtualRename(oldName path.Component, newDirectory Directory, newName path.Component) (ChangeInfo, ChangeInfo, Status) {
+	// Implementations of VirtualRename() expect the new directory
+	// to be of the same type as the old one.
+	if ad, ok := newDirectory.(*authorizingDirectory); ok {
+		newDirectory = ad.base
+	}
+	return d.base.VirtualRename(oldName, newDirectory, newName)
+}
+
+func (d *authorizingDirectory) VirtualRemove(name path.Component, removeDirectory, removeLeaf bool) (ChangeInfo, Status) {
+	return d.base.VirtualRemove(name, removeDirectory, removeLeaf)
+}
+
+func (d *authorizingDirectory) VirtualSymlink(ctx context.Context, pointedTo []byte, linkName path.Component, requested AttributesMask, attributes *Attributes) (Leaf, ChangeInfo, Status) {
+	if s := authorize(ctx, d.authorizer); s != StatusOK {
+		return nil, ChangeInfo{}, s
+	}
+	leaf, changeInfo, s := d.base.VirtualSymlink(ctx, pointedTo, linkName, requested, attributes)
+	if s != StatusOK {
+		return nil, ChangeInfo{}, s
+	}
+	return d.wrapLeaf(leaf), changeInfo, StatusOK
+}
+
+// authorizingDirectoryEntryReporter is a decorator for
+// DirectoryEntryReporter that decorates all children that are
+// reported by VirtualReadDir().
+type authorizingDirectoryEntryReporter struct {
+	base      DirectoryEntryReporter
+	directory *authorizingDirectory
+}
+
+func (r *authorizingDirectoryEntryReporter) ReportEntry(nextCookie uint64, name path.Component, child DirectoryChild, attributes *Attributes) bool {
+	return r.base.ReportEntry(nextCookie, name, r.directory.wrapChild(child), attributes)
+}
+
+// authorizingLeaf is a decorator for Leaf that only permits the leaf
+// to be opened if the caller is authorized to do so. It is returned
+// by authorizingDirectory.
+type authorizingLeaf struct {
+	Leaf
+	authorizer auth.Authorizer
+}
+
+// unwrapAuthorizingLeaf strips the decorator that is placed around
+// leaves returned by authorizingDirectory. Implementations of
+// VirtualLink() and VirtualCopyFileRange() expect leaves of the same
+// type as the ones they created.
+func unwrapAuthorizingLeaf(leaf Leaf) Leaf {
+	if al, ok := leaf.(*authorizingLeaf); ok {
+		return al.Leaf
+	}
+	return leaf
+}
+
+func (l *authorizingLeaf) unwrapLeaf() Leaf {
+	return l.Leaf
+}
+
+func (l *authorizingLeaf) VirtualCopyFileRange(ctx context.Context, source Leaf, offsetIn, offsetOut, length uint64) (uint64, Status) {
+	if s := authorize(ctx, l.authorizer); s != StatusOK {
+		return 0, s
+	}
+	return l.Leaf.VirtualCopyFileRange(ctx, unwrapAuthorizingLeaf(source), offsetIn, offsetOut, length)
+}
+
+func (l *authorizingLeaf) VirtualOpenSelf(ctx context.Context, shareAccess ShareMask, options *OpenExistingOptions, requested AttributesMask, attributes *Attributes) Status {
+	if s := authorize(ctx, l.authorizer); s != StatusOK {
+		return s
+	}
+	return l.Leaf.VirtualOpenSelf(ctx, shareAccess, options, requested, attributes)
+}
+
+func (l *authorizingLeaf) VirtualReadlink(ctx context.Context) ([]byte, Status) {
+	if s := authorize(ctx, l.authorizer); s != StatusOK {
+		return nil, s
+	}
+	return l.Leaf.VirtualReadlink(ctx)
+}
+
+func (l *authorizingLeaf) VirtualSetAttributes(ctx context.Context, in *Attributes, requested AttributesMask, attributes *Attributes) Status {
+	if s := authorize(ctx, l.authorizer); s != StatusOK {
+		return s
+	}
+	return l.Leaf.VirtualSetAttributes(ctx, in, requested, attributes)
+}
+
+func (l *authorizingLeaf) VirtualGetXAttr(ctx context.Context, name string) ([]byte, Status) {
+	if s := authorize(ctx, l.authorizer); s != StatusOK {
+		return nil, s
+	}
+	return l.Leaf.VirtualGetXAttr(ctx, name)
+}
+
+func (l *authorizingLeaf) VirtualListXAttr(ctx context.Context) ([]string, Status) {
+	if s := authorize(ctx, l.authorizer); s != StatusOK {
+		return nil, s
+	}
+	return l.Leaf.VirtualListXAttr(ctx)
+}
+
+func (l *authorizingLeaf) VirtualRemoveXAttr(ctx context.Context, name string) Status {
+	if s := authorize(ctx, l.authorizer); s != StatusOK {
+		return s
+	}
+	return l.Leaf.VirtualRemoveXAttr(ctx, name)
+}
+
+func (l *authorizingLeaf) VirtualSetXAttr(ctx context.Context, name string, value []byte, mode XAttrSetMode) Status {
+	if s := authorize(ctx, l.authorizer); s != StatusOK {
+		return s
+	}
+	return l.Leaf.VirtualSetXAttr(ctx, name, value, mode)
+}
@@ -0,0 +1,165 @@
+package virtual_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthorizingDirectory(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseDirectory := mock.NewMockVirtualDirectory(ctrl)
+	authorizer := mock.NewMockAuthorizer(ctrl)
+	directory := virtual.NewAuthorizingDirectory(baseDirectory, authorizer)
+
+	allow := func() {
+		authorizer.EXPECT().Authorize(gomock.Any(), []digest.InstanceName{digest.EmptyInstanceName}).Return([]error{nil})
+	}
+	deny := func() {
+		authorizer.EXPECT().Authorize(gomock.Any(), []digest.InstanceName{digest.EmptyInstanceName}).
+			Return([]error{status.Error(codes.PermissionDenied, "You shall not pass")})
+	}
+
+	t.Run("GetAttributes", func(t *testing.T) {
+		// Attributes should be returned without performing any
+		// authorization, as the kernel needs to be able to
+		// obtain the attributes of the root directory.
+		baseDirectory.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMaskInodeNumber, gomock.Any()).
+			Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+				attributes.SetInodeNumber(123)
+			})
+
+		var attributes virtual.Attributes
+		directory.VirtualGetAttributes(ctx, virtual.AttributesMaskInodeNumber, &attributes)
+		require.Equal(t, (&virtual.Attributes{}).SetInodeNumber(123), &attributes)
+	})
+
+	t.Run("LookupDenied", func(t *testing.T) {
+		deny()
+
+		var attributes virtual.Attributes
+		_, s := directory.VirtualLookup(ctx, path.MustNewComponent("foo"), 0, &attributes)
+		require.Equal(t, virtual.StatusErrAccess, s)
+	})
+
+	t.Run("LookupDirectory", func(t *testing.T) {
+		// Child directories should be decorated as well, so
+		// that their contents are protected.
+		childDirectory := mock.NewMockVirtualDirectory(ctrl)
+		allow()
+		baseDirectory.EXPECT().VirtualLookup(ctx, path.MustNewComponent("dir"), virtual.AttributesMask(0), gomock.Any()).
+			Return(virtual.DirectoryChild{}.FromDirectory(childDirectory), virtual.StatusOK)
+
+		var attributes virtual.Attributes
+		child, s := directory.VirtualLookup(ctx, path.MustNewComponent("dir"), 0, &attributes)
+		require.Equal(t, virtual.StatusOK, s)
+		wrappedDirectory, _ := child.GetPair()
+		require.NotNil(t, wrappedDirectory)
+
+		deny()
+		reporter := mock.NewMockDirectoryEntryReporter(ctrl)
+		require.Equal(t, virtual.StatusErrAccess, wrappedDirectory.VirtualReadDir(ctx, 0, 0, reporter))
+	})
+
+	t.Run("LookupLeaf", func(t *testing.T) {
+		// Leaves should be decorated, so that they can only be
+		// opened by authorized callers.
+		childLeaf := mock.NewMockVirtualLeaf(ctrl)
+		allow()
+		baseDirectory.EXPECT().VirtualLookup(ctx, path.MustNewComponent("file"), virtual.AttributesMask(0), gomock.Any()).
+			Return(virtual.DirectoryChild{}.FromLeaf(childLeaf), virtual.StatusOK)
+
+		var attributes virtual.Attributes
+		child, s := directory.VirtualLookup(ctx, path.MustNewComponent("file"), 0, &attributes)
+		require.Equal(t, virtual.StatusOK, s)
+		_, wrappedLeaf := child.GetPair()
+		require.NotNil(t, wrappedLeaf)
+
+		deny()
+		require.Equal(t, virtual.StatusErrAccess, wrappedLeaf.VirtualOpenSelf(ctx, virtual.ShareMaskRead, &virtual.OpenExistingOptions{}, 0, &attributes))
+
+		allow()
+		childLeaf.EXPECT().VirtualOpenSelf(ctx, virtual.ShareMaskRead, &virtual.OpenExistingOptions{}, virtual.AttributesMask(0), gomock.Any()).
+			Return(virtual.StatusOK)
+		require.Equal(t, virtual.StatusOK, wrappedLeaf.VirtualOpenSelf(ctx, virtual.ShareMaskRead, &virtual.OpenExistingOptions{}, 0, &attributes))
+
+		// Reading from a file that has already been opened
+		// should not require any authorization.
+		childLeaf.EXPECT().VirtualRead(gomock.Len(5), uint64(0)).
+			DoAndReturn(func(buf []byte, offset uint64) (int, bool, virtual.Status) {
+				return copy(buf, "Hello"), true, virtual.StatusOK
+			})
+		buf := make([]byte, 5)
+		n, eof, s := wrappedLeaf.VirtualRead(buf, 0)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, 5, n)
+		require.True(t, eof)
+		require.Equal(t, []byte("Hello"), buf)
+	})
+
+	t.Run("ReadDir", func(t *testing.T) {
+		// Entries reported by VirtualReadDir() should be
+		// decorated as well.
+		childLeaf := mock.NewMockVirtualLeaf(ctrl)
+		allow()
+		baseDirectory.EXPECT().VirtualReadDir(ctx, uint64(0), virtual.AttributesMask(0), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, firstCookie uint64, requested virtual.AttributesMask, reporter virtual.DirectoryEntryReporter) virtual.Status {
+				reporter.ReportEntry(1, path.MustNewComponent("file"), virtual.DirectoryChild{}.FromLeaf(childLeaf), &virtual.Attributes{})
+				return virtual.StatusOK
+			})
+		reporter := mock.NewMockDirectoryEntryReporter(ctrl)
+		var reportedLeaf virtual.Leaf
+		reporter.EXPECT().ReportEntry(uint64(1), path.MustNewComponent("file"), gomock.Any(), &virtual.Attributes{}).
+			DoAndReturn(func(nextCookie uint64, name path.Component, child virtual.DirectoryChild, attributes *virtual.Attributes) bool {
+				_, reportedLeaf = child.GetPair()
+				return true
+			})
+
+		require.Equal(t, virtual.StatusOK, directory.VirtualReadDir(ctx, 0, 0, reporter))
+		require.NotEqual(t, virtual.Leaf(childLeaf), reportedLeaf)
+
+		deny()
+		_, s := reportedLeaf.VirtualReadlink(ctx)
+		require.Equal(t, virtual.StatusErrAccess, s)
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		// When renaming files, the underlying directory should
+		// receive the undecorated target directory.
+		targetDirectory := mock.NewMockVirtualDirectory(ctrl)
+		wrappedTargetDirectory := virtual.NewAuthorizingDirectory(targetDirectory, authorizer)
+		baseDirectory.EXPECT().VirtualRename(path.MustNewComponent("a"), targetDirectory, path.MustNewComponent("b")).
+			Return(virtual.ChangeInfo{}, virtual.ChangeInfo{}, virtual.StatusOK)
+
+		_, _, s := directory.VirtualRename(path.MustNewComponent("a"), wrappedTargetDirectory, path.MustNewComponent("b"))
+		require.Equal(t, virtual.StatusOK, s)
+	})
+	t.Run("ModificationDenied", func(t *testing.T) {
+		// Creating directories, renaming and removing entries
+		// are performed through methods that don't receive a
+		// context. Callers should authorize these separately.
+		deny()
+		require.Equal(t, virtual.StatusErrAccess, virtual.AuthorizeDirectoryModification(ctx, directory))
+	})
+
+	t.Run("ModificationAllowed", func(t *testing.T) {
+		allow()
+		require.Equal(t, virtual.StatusOK, virtual.AuthorizeDirectoryModification(ctx, directory))
+	})
+
+	t.Run("ModificationUndecorated", func(t *testing.T) {
+		// Directories that are not decorated may always be
+		// modified.
+		require.Equal(t, virtual.StatusOK, virtual.AuthorizeDirectoryModification(ctx, baseDirectory))
+	})
+}
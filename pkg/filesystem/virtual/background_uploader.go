@@ -0,0 +1,205 @@
+package virtual
+
+import (
+	"context"
+	"sync"
+
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// BackgroundUploader keeps track of files created by a pool-backed
+// file allocator whose last writable file descriptor has been closed.
+// While started, files that are stored at one of the
+// provided output paths are uploaded into the Content Addressable
+// Storage, allowing the upload of output files to overlap with the
+// execution of the build action.
+//
+// Files that are not stored at one of the output paths (e.g.,
+// temporary files or files that are later moved into place) are not
+// uploaded until they are observed at an output path.
+type BackgroundUploader struct {
+	contentAddressableStorage blobstore.BlobAccess
+	digestFunction            digest.Function
+	semaphore                 *semaphore.Weighted
+
+	lock         sync.Mutex
+	running      bool
+	pendingFiles map[*fileBackedFile]struct{}
+	wakeup       chan struct{}
+}
+
+// NewBackgroundUploader creates a BackgroundUploader that uploads
+// files into the provided Content Addressable Storage. The semaphore
+// limits the number of concurrent uploads.
+func NewBackgroundUploader(contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function, semaphore *semaphore.Weighted) *BackgroundUploader {
+	return &BackgroundUploader{
+		contentAddressableStorage: contentAddressableStorage,
+		digestFunction:            digestFunction,
+		semaphore:                 semaphore,
+
+		pendingFiles: map[*fileBackedFile]struct{}{},
+		wakeup:       make(chan struct{}, 1),
+	}
+}
+
+// enqueue registers a file whose last writable file descriptor has
+// been closed. Files are ignored if the uploader is not started.
+func (bu *BackgroundUploader) enqueue(f *fileBackedFile) {
+	bu.lock.Lock()
+	defer bu.lock.Unlock()
+
+	if bu.running {
+		bu.pendingFiles[f] = struct{}{}
+		select {
+		case bu.wakeup <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Start uploading files that are closed, until the returned function
+// is called. Only files that are stored at one of the provided paths,
+// or in a directory stored at one of the provided paths, are uploaded.
+// Paths are relative to the root directory.
+//
+// Uploads are performed using the provided context. Uploads that are
+// in progress when the returned function is called are completed
+// before it returns.
+func (bu *BackgroundUploader) Start(ctx context.Context, rootDirectory PrepopulatedDirectory, outputPaths [][]path.Component) func() {
+	bu.lock.Lock()
+	if bu.running {
+		panic("Background uploader is already running")
+	}
+	bu.running = true
+	bu.lock.Unlock()
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		bu.run(runCtx, ctx, rootDirectory, outputPaths)
+		close(done)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (bu *BackgroundUploader) run(ctx, uploadCtx context.Context, rootDirectory PrepopulatedDirectory, outputPaths [][]path.Component) {
+	var wg sync.WaitGroup
+	defer func() {
+		bu.lock.Lock()
+		bu.running = false
+		bu.pendingFiles = map[*fileBackedFile]struct{}{}
+		bu.lock.Unlock()
+		wg.Wait()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-bu.wakeup:
+		}
+
+		bu.lock.Lock()
+		pendingFiles := bu.pendingFiles
+		bu.pendingFiles = map[*fileBackedFile]struct{}{}
+		bu.lock.Unlock()
+
+		// Only upload files that are stored at one of the
+		// output paths. Traversal removes the files that are
+		// found from pendingFiles.
+		var outputFiles []*fileBackedFile
+		for _, outputPath := range outputPaths {
+			outputFiles = collectPendingOutputFiles(rootDirectory, outputPath, pendingFiles, outputFiles)
+		}
+		for _, f := range outputFiles {
+			if bu.semaphore.Acquire(ctx, 1) != nil {
+				return
+			}
+			wg.Add(1)
+			go func(f *fileBackedFile) {
+				f.uploadInBackground(uploadCtx, bu.contentAddressableStorage, bu.digestFunction)
+				bu.semaphore.Release(1)
+				wg.Done()
+			}(f)
+		}
+
+		// Files that have not been found may be moved to an
+		// output path later on. Retain them, unless they have
+		// been released in the meantime. The files' locks may
+		// not be acquired while holding the uploader's lock, as
+		// enqueue() is called with the file's lock held.
+		for f := range pendingFiles {
+			if !f.isReferenced() {
+				delete(pendingFiles, f)
+			}
+		}
+		bu.lock.Lock()
+		for f := range pendingFiles {
+			bu.pendingFiles[f] = struct{}{}
+		}
+		bu.lock.Unlock()
+	}
+}
+
+// collectPendingOutputFiles looks up a single output path. If the
+// path refers to a file that is pending upload, it is returned. If the
+// path refers to a directory, all files contained in it that are
+// pending upload are returned. An empty path refers to the root
+// directory.
+func collectPendingOutputFiles(d PrepopulatedDirectory, outputPath []path.Component, pendingFiles map[*fileBackedFile]struct{}, outputFiles []*fileBackedFile) []*fileBackedFile {
+	if len(outputPath) == 0 {
+		return collectPendingFilesInDirectory(d, pendingFiles, outputFiles)
+	}
+	for _, component := range outputPath[:len(outputPath)-1] {
+		child, err := d.LookupChild(component)
+		if err != nil {
+			return outputFiles
+		}
+		childDirectory, _ := child.GetPair()
+		if childDirectory == nil {
+			return outputFiles
+		}
+		d = childDirectory
+	}
+
+	child, err := d.LookupChild(outputPath[len(outputPath)-1])
+	if err != nil {
+		return outputFiles
+	}
+	if childDirectory, childLeaf := child.GetPair(); childDirectory != nil {
+		return collectPendingFilesInDirectory(childDirectory, pendingFiles, outputFiles)
+	} else {
+		return collectPendingFile(childLeaf, pendingFiles, outputFiles)
+	}
+}
+
+func collectPendingFilesInDirectory(d PrepopulatedDirectory, pendingFiles map[*fileBackedFile]struct{}, outputFiles []*fileBackedFile) []*fileBackedFile {
+	directories, leaves, err := d.LookupAllChildren()
+	if err != nil {
+		return outputFiles
+	}
+	for _, entry := range leaves {
+		outputFiles = collectPendingFile(entry.Child, pendingFiles, outputFiles)
+	}
+	for _, entry := range directories {
+		outputFiles = collectPendingFilesInDirectory(entry.Child, pendingFiles, outputFiles)
+	}
+	return outputFiles
+}
+
+func collectPendingFile(leaf NativeLeaf, pendingFiles map[*fileBackedFile]struct{}, outputFiles []*fileBackedFile) []*fileBackedFile {
+	if f, ok := getUndecoratedLeaf(leaf).(*fileBackedFile); ok {
+		if _, ok := pendingFiles[f]; ok {
+			delete(pendingFiles, f)
+			outputFiles = append(outputFiles, f)
+		}
+	}
+	return outputFiles
+}
@@ -3,6 +3,7 @@ package virtual
 import (
 	"context"
 	"syscall"
+	"time"
 
 	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/outputpathpersistency"
@@ -18,6 +19,7 @@ type blobAccessCASFileFactory struct {
 	context                   context.Context
 	contentAddressableStorage blobstore.BlobAccess
 	errorLogger               util.ErrorLogger
+	readahead                 *casFileReadahead
 }
 
 // NewBlobAccessCASFileFactory creates a CASFileFactory that can be used
@@ -25,11 +27,43 @@ type blobAccessCASFileFactory struct {
 // created by this factory are entirely immutable; it is only possible
 // to read their contents.
 func NewBlobAccessCASFileFactory(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, errorLogger util.ErrorLogger) CASFileFactory {
-	return &blobAccessCASFileFactory{
+	return NewReadaheadBlobAccessCASFileFactory(ctx, contentAddressableStorage, errorLogger, nil)
+}
+
+// NewReadaheadBlobAccessCASFileFactory is identical to
+// NewBlobAccessCASFileFactory, except that files created by this
+// factory detect whether they are read sequentially. If so, their
+// contents are fetched from the Content Addressable Storage in chunks
+// that are larger than the reads issued by the kernel, ahead of the
+// offset at which the file is being read. This prevents tools such as
+// linkers from being limited by round-trip latency when reading large
+// files.
+//
+// Readahead is disabled if no options are provided.
+func NewReadaheadBlobAccessCASFileFactory(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, errorLogger util.ErrorLogger, readaheadOptions *CASFileReadaheadOptions) ReadaheadCASFileFactory {
+	cff := &blobAccessCASFileFactory{
 		context:                   ctx,
 		contentAddressableStorage: contentAddressableStorage,
 		errorLogger:               errorLogger,
 	}
+	if readaheadOptions != nil {
+		cff.readahead = newCASFileReadahead(ctx, contentAddressableStorage, readaheadOptions)
+	}
+	return cff
+}
+
+func (cff *blobAccessCASFileFactory) EvictIdleFiles(maximumIdleTime time.Duration) (int, error) {
+	if cff.readahead == nil {
+		return 0, nil
+	}
+	return cff.readahead.evictIdleStreams(maximumIdleTime), nil
+}
+
+func (cff *blobAccessCASFileFactory) FlushCachedFiles() (int, error) {
+	if cff.readahead == nil {
+		return 0, nil
+	}
+	return cff.readahead.flush(), nil
 }
 
 func (cff *blobAccessCASFileFactory) LookupFile(blobDigest digest.Digest, isExecutable bool, readMonitor FileReadMonitor) NativeLeaf {
@@ -79,6 +113,12 @@ func (f *blobAccessCASFile) UploadFile(ctx context.Context, contentAddressableSt
 	return f.digest, nil
 }
 
+func (f *blobAccessCASFile) ConvertToCASFile(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function, casFileFactory CASFileFactory) error {
+	// This file is already backed by the Content Addressable
+	// Storage.
+	return nil
+}
+
 func (f *blobAccessCASFile) GetContainingDigests() digest.Set {
 	return f.digest.ToSingletonSet()
 }
@@ -102,9 +142,32 @@ func (f *blobAccessCASFile) VirtualAllocate(off, size uint64) Status {
 	return StatusErrWrongType
 }
 
+func (f *blobAccessCASFile) VirtualDeallocate(off, size uint64) Status {
+	return StatusErrWrongType
+}
+
+func (f *blobAccessCASFile) VirtualGetXAttr(ctx context.Context, name string) ([]byte, Status) {
+	return nil, StatusErrNoXAttr
+}
+
+func (f *blobAccessCASFile) VirtualListXAttr(ctx context.Context) ([]string, Status) {
+	return nil, StatusOK
+}
+
+func (f *blobAccessCASFile) VirtualRemoveXAttr(ctx context.Context, name string) Status {
+	return StatusErrNoXAttr
+}
+
+func (f *blobAccessCASFile) VirtualSetXAttr(ctx context.Context, name string, value []byte, mode XAttrSetMode) Status {
+	// Files backed by the Content Addressable Storage are
+	// immutable, meaning no extended attributes can be stored.
+	return StatusErrPerm
+}
+
 func (f *blobAccessCASFile) virtualGetAttributesCommon(attributes *Attributes) {
 	attributes.SetChangeID(0)
 	attributes.SetFileType(filesystem.FileTypeRegularFile)
+	attributes.SetIsImmutable(true)
 	attributes.SetSizeBytes(uint64(f.digest.GetSizeBytes()))
 }
 
@@ -130,7 +193,12 @@ func (f *blobAccessCASFile) VirtualRead(buf []byte, off uint64) (int, bool, Stat
 	size := uint64(f.digest.GetSizeBytes())
 	buf, eof := BoundReadToFileSize(buf, off, size)
 	if len(buf) > 0 {
-		if n, err := f.factory.contentAddressableStorage.Get(f.factory.context, f.digest).ReadAt(buf, int64(off)); n != len(buf) {
+		if readahead := f.factory.readahead; readahead != nil {
+			if err := readahead.read(f.digest, buf, off); err != nil {
+				f.factory.errorLogger.Log(util.StatusWrapf(err, "Failed to read from %s at offset %d", f.digest, off))
+				return 0, false, StatusErrIO
+			}
+		} else if n, err := f.factory.contentAddressableStorage.Get(f.factory.context, f.digest).ReadAt(buf, int64(off)); n != len(buf) {
 			f.factory.errorLogger.Log(util.StatusWrapf(err, "Failed to read from %s at offset %d", f.digest, off))
 			return 0, false, StatusErrIO
 		}
@@ -144,6 +212,10 @@ func (f *blobAccessCASFile) VirtualReadlink(ctx context.Context) ([]byte, Status
 
 func (f *blobAccessCASFile) VirtualClose(shareAccess ShareMask) {}
 
+func (f *blobAccessCASFile) VirtualCopyFileRange(ctx context.Context, source Leaf, offsetIn, offsetOut, length uint64) (uint64, Status) {
+	panic("Request to copy to read-only file should have been intercepted")
+}
+
 func (f *blobAccessCASFile) virtualSetAttributesCommon(in *Attributes) Status {
 	// TODO: chmod() calls against CAS backed files should not be
 	// permitted. Unfortunately, we allowed it in the past. When
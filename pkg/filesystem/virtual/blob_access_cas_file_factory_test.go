@@ -3,12 +3,14 @@ package virtual_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
 	"github.com/buildbarn/bb-remote-execution/internal/mock"
 	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/outputpathpersistency"
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteoutputservice"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
 	"github.com/buildbarn/bb-storage/pkg/digest"
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
@@ -41,6 +43,7 @@ func TestBlobAccessCASFileFactoryVirtualSeek(t *testing.T) {
 		(&virtual.Attributes{}).
 			SetChangeID(0).
 			SetFileType(filesystem.FileTypeRegularFile).
+			SetIsImmutable(true).
 			SetPermissions(virtual.PermissionsRead).
 			SetSizeBytes(123),
 		&out)
@@ -91,6 +94,7 @@ func TestBlobAccessCASFileFactoryGetContainingDigests(t *testing.T) {
 		(&virtual.Attributes{}).
 			SetChangeID(0).
 			SetFileType(filesystem.FileTypeRegularFile).
+			SetIsImmutable(true).
 			SetPermissions(virtual.PermissionsRead|virtual.PermissionsExecute).
 			SetSizeBytes(400),
 		&out)
@@ -117,6 +121,7 @@ func TestBlobAccessCASFileFactoryGetOutputServiceFileStatus(t *testing.T) {
 		(&virtual.Attributes{}).
 			SetChangeID(0).
 			SetFileType(filesystem.FileTypeRegularFile).
+			SetIsImmutable(true).
 			SetPermissions(virtual.PermissionsRead).
 			SetSizeBytes(123),
 		&out)
@@ -168,6 +173,7 @@ func TestBlobAccessCASFileFactoryAppendOutputPathPersistencyDirectoryNode(t *tes
 		(&virtual.Attributes{}).
 			SetChangeID(0).
 			SetFileType(filesystem.FileTypeRegularFile).
+			SetIsImmutable(true).
 			SetPermissions(virtual.PermissionsRead).
 			SetSizeBytes(123),
 		&out1)
@@ -181,6 +187,7 @@ func TestBlobAccessCASFileFactoryAppendOutputPathPersistencyDirectoryNode(t *tes
 		(&virtual.Attributes{}).
 			SetChangeID(0).
 			SetFileType(filesystem.FileTypeRegularFile).
+			SetIsImmutable(true).
 			SetPermissions(virtual.PermissionsRead|virtual.PermissionsExecute).
 			SetSizeBytes(456),
 		&out2)
@@ -209,3 +216,114 @@ func TestBlobAccessCASFileFactoryAppendOutputPathPersistencyDirectoryNode(t *tes
 		},
 	}, &directory)
 }
+
+func TestBlobAccessCASFileFactoryReadahead(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	casFileFactory := virtual.NewReadaheadBlobAccessCASFileFactory(
+		ctx,
+		contentAddressableStorage,
+		errorLogger,
+		&virtual.CASFileReadaheadOptions{
+			ChunkSizeBytes: 4,
+			MaximumFiles:   10,
+			Clock:          clock,
+		})
+
+	blobDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "781e5e245d69b566979b86e28d23f2c7", 10)
+	f := casFileFactory.LookupFile(blobDigest, false, nil)
+
+	// The first read should only fetch the data that is requested,
+	// as a single read does not indicate sequential access. Reading
+	// the file sequentially afterwards should cause it to be
+	// fetched in chunks of four bytes. Every chunk should only be
+	// fetched once, even though the file is read two bytes at a
+	// time. Seeking to an arbitrary offset afterwards should cause
+	// only the data that is requested to be fetched.
+	contentAddressableStorage.EXPECT().Get(ctx, blobDigest).
+		DoAndReturn(func(ctx context.Context, blobDigest digest.Digest) buffer.Buffer {
+			return buffer.NewValidatedBufferFromByteSlice([]byte("0123456789"))
+		}).
+		Times(4)
+	clock.EXPECT().Now().Return(time.Unix(1000, 0)).Times(6)
+
+	for _, off := range []uint64{0, 2, 4, 6, 8, 1} {
+		var buf [2]byte
+		n, eof, s := f.VirtualRead(buf[:], off)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, 2, n)
+		require.Equal(t, off == 8, eof)
+		require.Equal(t, "0123456789"[off:off+2], string(buf[:]))
+	}
+}
+
+func TestBlobAccessCASFileFactoryReadaheadEviction(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	casFileFactory := virtual.NewReadaheadBlobAccessCASFileFactory(
+		ctx,
+		contentAddressableStorage,
+		errorLogger,
+		&virtual.CASFileReadaheadOptions{
+			ChunkSizeBytes: 4,
+			MaximumFiles:   10,
+			Clock:          clock,
+		})
+
+	blobDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "781e5e245d69b566979b86e28d23f2c7", 10)
+	f := casFileFactory.LookupFile(blobDigest, false, nil)
+	contentAddressableStorage.EXPECT().Get(ctx, blobDigest).
+		DoAndReturn(func(ctx context.Context, blobDigest digest.Digest) buffer.Buffer {
+			return buffer.NewValidatedBufferFromByteSlice([]byte("0123456789"))
+		}).
+		AnyTimes()
+
+	// Read the file sequentially, so that readahead state is
+	// created for it.
+	clock.EXPECT().Now().Return(time.Unix(1000, 0)).Times(2)
+	for _, off := range []uint64{0, 2} {
+		var buf [2]byte
+		n, _, s := f.VirtualRead(buf[:], off)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, 2, n)
+	}
+
+	t.Run("NotIdle", func(t *testing.T) {
+		clock.EXPECT().Now().Return(time.Unix(1030, 0))
+		count, err := casFileFactory.EvictIdleFiles(time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, 0, count)
+	})
+
+	t.Run("Idle", func(t *testing.T) {
+		clock.EXPECT().Now().Return(time.Unix(1060, 0))
+		count, err := casFileFactory.EvictIdleFiles(time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+
+		count, err = casFileFactory.FlushCachedFiles()
+		require.NoError(t, err)
+		require.Equal(t, 0, count)
+	})
+
+	t.Run("Flush", func(t *testing.T) {
+		// Reading the file once again should recreate its
+		// readahead state, which can be flushed explicitly.
+		clock.EXPECT().Now().Return(time.Unix(1070, 0))
+		var buf [2]byte
+		n, _, s := f.VirtualRead(buf[:], 4)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, 2, n)
+		require.Equal(t, "45", string(buf[:]))
+
+		count, err := casFileFactory.FlushCachedFiles()
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+	})
+}
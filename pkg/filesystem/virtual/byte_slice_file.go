@@ -0,0 +1,112 @@
+package virtual
+
+import (
+	"context"
+
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+)
+
+type byteSliceFile struct {
+	contents []byte
+}
+
+// NewByteSliceFile creates a read-only regular file whose contents are
+// backed by a byte slice that is kept in memory. This can be used to
+// expose small pieces of metadata through the virtual file system.
+func NewByteSliceFile(contents []byte) Leaf {
+	return &byteSliceFile{
+		contents: contents,
+	}
+}
+
+func (f *byteSliceFile) VirtualAllocate(off, size uint64) Status {
+	return StatusErrWrongType
+}
+
+func (f *byteSliceFile) VirtualClose(shareAccess ShareMask) {}
+
+func (f *byteSliceFile) VirtualCopyFileRange(ctx context.Context, source Leaf, offsetIn, offsetOut, length uint64) (uint64, Status) {
+	panic("Request to copy to read-only file should have been intercepted")
+}
+
+func (f *byteSliceFile) VirtualDeallocate(off, size uint64) Status {
+	return StatusErrWrongType
+}
+
+func (f *byteSliceFile) VirtualGetAttributes(ctx context.Context, requested AttributesMask, attributes *Attributes) {
+	attributes.SetChangeID(0)
+	attributes.SetFileType(filesystem.FileTypeRegularFile)
+	attributes.SetIsImmutable(true)
+	attributes.SetLinkCount(StatelessLeafLinkCount)
+	attributes.SetPermissions(PermissionsRead)
+	attributes.SetSizeBytes(uint64(len(f.contents)))
+}
+
+func (f *byteSliceFile) VirtualGetXAttr(ctx context.Context, name string) ([]byte, Status) {
+	return nil, StatusErrNoXAttr
+}
+
+func (f *byteSliceFile) VirtualListXAttr(ctx context.Context) ([]string, Status) {
+	return nil, StatusOK
+}
+
+func (f *byteSliceFile) VirtualOpenSelf(ctx context.Context, shareAccess ShareMask, options *OpenExistingOptions, requested AttributesMask, attributes *Attributes) Status {
+	if shareAccess&^ShareMaskRead != 0 || options.Truncate {
+		return StatusErrAccess
+	}
+	f.VirtualGetAttributes(ctx, requested, attributes)
+	return StatusOK
+}
+
+func (f *byteSliceFile) VirtualRead(buf []byte, off uint64) (int, bool, Status) {
+	buf, eof := BoundReadToFileSize(buf, off, uint64(len(f.contents)))
+	if len(buf) > 0 {
+		copy(buf, f.contents[off:])
+	}
+	return len(buf), eof, StatusOK
+}
+
+func (f *byteSliceFile) VirtualReadlink(ctx context.Context) ([]byte, Status) {
+	return nil, StatusErrInval
+}
+
+func (f *byteSliceFile) VirtualRemoveXAttr(ctx context.Context, name string) Status {
+	return StatusErrNoXAttr
+}
+
+func (f *byteSliceFile) VirtualSeek(offset uint64, regionType filesystem.RegionType) (*uint64, Status) {
+	sizeBytes := uint64(len(f.contents))
+	switch regionType {
+	case filesystem.Data:
+		if offset >= sizeBytes {
+			return nil, StatusErrNXIO
+		}
+		return &offset, StatusOK
+	case filesystem.Hole:
+		if offset >= sizeBytes {
+			return nil, StatusErrNXIO
+		}
+		return &sizeBytes, StatusOK
+	default:
+		panic("Requests for other seek modes should have been intercepted")
+	}
+}
+
+func (f *byteSliceFile) VirtualSetAttributes(ctx context.Context, in *Attributes, requested AttributesMask, out *Attributes) Status {
+	if _, ok := in.GetSizeBytes(); ok {
+		return StatusErrAccess
+	}
+	if _, ok := in.GetPermissions(); ok {
+		return StatusErrPerm
+	}
+	f.VirtualGetAttributes(ctx, requested, out)
+	return StatusOK
+}
+
+func (f *byteSliceFile) VirtualSetXAttr(ctx context.Context, name string, value []byte, mode XAttrSetMode) Status {
+	return StatusErrPerm
+}
+
+func (f *byteSliceFile) VirtualWrite(buf []byte, off uint64) (int, Status) {
+	panic("Request to write to read-only file should have been intercepted")
+}
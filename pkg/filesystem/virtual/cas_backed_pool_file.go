@@ -0,0 +1,78 @@
+package virtual
+
+import (
+	"io"
+
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// casBackedPoolFile is a FileReadWriter that is used by fileBackedFile
+// after ConvertToCASFile() has been called. Instead of storing the
+// contents of the file in a FilePool, it reads them from a file that is
+// backed by the Content Addressable Storage.
+//
+// As all operations that mutate a fileBackedFile call unshareLocked(),
+// the contents of the file are copied back into the FilePool before
+// any writes take place. The write operations provided by this type
+// are thus never called.
+type casBackedPoolFile struct {
+	leaf   NativeLeaf
+	digest digest.Digest
+}
+
+func newCASBackedPoolFile(leaf NativeLeaf, blobDigest digest.Digest) *casBackedPoolFile {
+	return &casBackedPoolFile{
+		leaf:   leaf,
+		digest: blobDigest,
+	}
+}
+
+func (f *casBackedPoolFile) ReadAt(p []byte, off int64) (int, error) {
+	n, _, s := f.leaf.VirtualRead(p, uint64(off))
+	if s != StatusOK {
+		return 0, status.Errorf(codes.Internal, "Failed to read from file backed by the Content Addressable Storage at offset %d", off)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *casBackedPoolFile) GetNextRegionOffset(off int64, regionType filesystem.RegionType) (int64, error) {
+	// Files backed by the Content Addressable Storage don't contain
+	// any holes.
+	sizeBytes := f.digest.GetSizeBytes()
+	if off >= sizeBytes {
+		return 0, io.EOF
+	}
+	switch regionType {
+	case filesystem.Data:
+		return off, nil
+	case filesystem.Hole:
+		return sizeBytes, nil
+	default:
+		panic("Unknown region type")
+	}
+}
+
+func (f *casBackedPoolFile) WriteAt(p []byte, off int64) (int, error) {
+	panic("Files backed by the Content Addressable Storage should have been unshared prior to writing")
+}
+
+func (f *casBackedPoolFile) Truncate(size int64) error {
+	panic("Files backed by the Content Addressable Storage should have been unshared prior to truncation")
+}
+
+func (f *casBackedPoolFile) Sync() error {
+	return nil
+}
+
+func (f *casBackedPoolFile) Close() error {
+	f.leaf.Unlink()
+	f.leaf = nil
+	return nil
+}
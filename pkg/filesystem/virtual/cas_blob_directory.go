@@ -0,0 +1,94 @@
+package virtual
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+)
+
+type casBlobDirectory struct {
+	ReadOnlyDirectory
+
+	casFileFactory CASFileFactory
+	digestFunction digest.Function
+}
+
+// NewCASBlobDirectory creates a Directory that gives access to
+// arbitrary blobs stored in the Content Addressable Storage (CAS).
+// Blobs can be accessed by looking up files named "${hash}-${size}".
+// Files are created lazily, meaning that their contents are only
+// fetched when read. As the CAS cannot be enumerated, the directory
+// always appears to be empty when listed.
+//
+// This directory can be used by debugging tools and action wrappers
+// that need to access blobs, without requiring that they are fetched
+// explicitly.
+func NewCASBlobDirectory(casFileFactory CASFileFactory, digestFunction digest.Function) Directory {
+	return &casBlobDirectory{
+		casFileFactory: casFileFactory,
+		digestFunction: digestFunction,
+	}
+}
+
+// parseDigestFilename converts the name of a file or directory to a
+// digest, returning false if the name is not of the form
+// "${hash}-${size}".
+func parseDigestFilename(digestFunction digest.Function, name path.Component) (digest.Digest, bool) {
+	nameStr := name.String()
+	separator := strings.LastIndexByte(nameStr, '-')
+	if separator < 0 {
+		return digest.BadDigest, false
+	}
+	sizeBytes, err := strconv.ParseInt(nameStr[separator+1:], 10, 64)
+	if err != nil {
+		return digest.BadDigest, false
+	}
+	blobDigest, err := digestFunction.NewDigest(nameStr[:separator], sizeBytes)
+	if err != nil {
+		return digest.BadDigest, false
+	}
+	return blobDigest, true
+}
+
+func (d *casBlobDirectory) VirtualGetAttributes(ctx context.Context, requested AttributesMask, attributes *Attributes) {
+	attributes.SetChangeID(0)
+	attributes.SetFileType(filesystem.FileTypeDirectory)
+	attributes.SetIsImmutable(true)
+	attributes.SetLinkCount(EmptyDirectoryLinkCount)
+	attributes.SetPermissions(PermissionsRead | PermissionsExecute)
+	attributes.SetSizeBytes(0)
+}
+
+func (d *casBlobDirectory) VirtualLookup(ctx context.Context, name path.Component, requested AttributesMask, out *Attributes) (DirectoryChild, Status) {
+	blobDigest, ok := parseDigestFilename(d.digestFunction, name)
+	if !ok {
+		return DirectoryChild{}, StatusErrNoEnt
+	}
+	leaf := d.casFileFactory.LookupFile(blobDigest, false, nil)
+	leaf.VirtualGetAttributes(ctx, requested, out)
+	return DirectoryChild{}.FromLeaf(leaf), StatusOK
+}
+
+func (d *casBlobDirectory) VirtualOpenChild(ctx context.Context, name path.Component, shareAccess ShareMask, createAttributes *Attributes, existingOptions *OpenExistingOptions, requested AttributesMask, openedFileAttributes *Attributes) (Leaf, AttributesMask, ChangeInfo, Status) {
+	blobDigest, ok := parseDigestFilename(d.digestFunction, name)
+	if !ok {
+		return ReadOnlyDirectoryOpenChildDoesntExist(createAttributes)
+	}
+	if existingOptions == nil {
+		return nil, 0, ChangeInfo{}, StatusErrExist
+	}
+	leaf := d.casFileFactory.LookupFile(blobDigest, false, nil)
+	s := leaf.VirtualOpenSelf(ctx, shareAccess, existingOptions, requested, openedFileAttributes)
+	return leaf, existingOptions.ToAttributesMask(), ChangeInfo{
+		Before: 0,
+		After:  0,
+	}, s
+}
+
+func (d *casBlobDirectory) VirtualReadDir(ctx context.Context, firstCookie uint64, requested AttributesMask, reporter DirectoryEntryReporter) Status {
+	return StatusOK
+}
@@ -0,0 +1,61 @@
+package virtual_test
+
+import (
+	"context"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCASBlobDirectory(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	casFileFactory := mock.NewMockCASFileFactory(ctrl)
+	d := virtual.NewCASBlobDirectory(casFileFactory, digest.MustNewFunction("example", remoteexecution.DigestFunction_SHA256))
+
+	t.Run("InvalidFilename", func(t *testing.T) {
+		for _, name := range []string{
+			"hello",
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855-",
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855--1",
+			"8b1a9953c4611296a827abf8c47804d7-5",
+		} {
+			var out virtual.Attributes
+			_, s := d.VirtualLookup(ctx, path.MustNewComponent(name), 0, &out)
+			require.Equal(t, virtual.StatusErrNoEnt, s, name)
+		}
+	})
+
+	t.Run("Lookup", func(t *testing.T) {
+		leaf := mock.NewMockNativeLeaf(ctrl)
+		casFileFactory.EXPECT().LookupFile(
+			digest.MustNewDigest("example", remoteexecution.DigestFunction_SHA256, "185f8db32271fe25f561a6fc938b2e264306ec304eda518007d1764826381969", 5),
+			false,
+			nil,
+		).Return(leaf)
+		leaf.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMaskSizeBytes, gomock.Any()).
+			Do(func(ctx context.Context, requested virtual.AttributesMask, out *virtual.Attributes) {
+				out.SetSizeBytes(5)
+			})
+
+		var out virtual.Attributes
+		child, s := d.VirtualLookup(ctx, path.MustNewComponent("185f8db32271fe25f561a6fc938b2e264306ec304eda518007d1764826381969-5"), virtual.AttributesMaskSizeBytes, &out)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, virtual.DirectoryChild{}.FromLeaf(leaf), child)
+		require.Equal(t, *(&virtual.Attributes{}).SetSizeBytes(5), out)
+	})
+
+	t.Run("ReadDir", func(t *testing.T) {
+		// The directory can't be listed, as the CAS cannot be
+		// enumerated.
+		reporter := mock.NewMockDirectoryEntryReporter(ctrl)
+		require.Equal(t, virtual.StatusOK, d.VirtualReadDir(ctx, 0, 0, reporter))
+	})
+}
@@ -1,6 +1,8 @@
 package virtual
 
 import (
+	"time"
+
 	"github.com/buildbarn/bb-storage/pkg/digest"
 )
 
@@ -9,3 +11,19 @@ import (
 type CASFileFactory interface {
 	LookupFile(digest digest.Digest, isExecutable bool, readMonitor FileReadMonitor) NativeLeaf
 }
+
+// ReadaheadCASFileFactory is a CASFileFactory that retains the contents
+// of files that are read sequentially in memory. As the factory may be
+// long-lived, it provides methods for discarding this state.
+type ReadaheadCASFileFactory interface {
+	CASFileFactory
+
+	// EvictIdleFiles discards the state of files that have not
+	// been read for a given amount of time. It returns the number
+	// of files for which state was discarded.
+	EvictIdleFiles(maximumIdleTime time.Duration) (int, error)
+
+	// FlushCachedFiles discards the state of all files. It
+	// returns the number of files for which state was discarded.
+	FlushCachedFiles() (int, error)
+}
@@ -0,0 +1,249 @@
+package virtual
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	re_sync "github.com/buildbarn/bb-remote-execution/pkg/sync"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	casFileReadaheadPrometheusMetrics sync.Once
+
+	casFileReadaheadReads = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "buildbarn",
+			Subsystem: "virtual",
+			Name:      "cas_file_readahead_reads_total",
+			Help:      "Total number of reads against files backed by the Content Addressable Storage for which readahead is enabled.",
+		},
+		[]string{"result"})
+	casFileReadaheadReadsHit    = casFileReadaheadReads.WithLabelValues("Hit")
+	casFileReadaheadReadsMiss   = casFileReadaheadReads.WithLabelValues("Miss")
+	casFileReadaheadReadsRandom = casFileReadaheadReads.WithLabelValues("Random")
+)
+
+// CASFileReadaheadOptions contains the parameters that files created
+// by a BlobAccess backed CASFileFactory use to read ahead of the
+// current offset, once sequential access is detected.
+type CASFileReadaheadOptions struct {
+	// The size of the chunks that are read from the Content
+	// Addressable Storage. Reads issued by the kernel are typically
+	// at most 128 KiB in size. Setting this to a larger value
+	// reduces the number of round trips needed to read large files.
+	ChunkSizeBytes int
+	// The maximum number of files for which readahead state is
+	// tracked. At most two chunks are held in memory per file.
+	MaximumFiles int
+	// The clock that is used to track when files were last read,
+	// so that the readahead state of idle files can be discarded.
+	Clock clock.Clock
+}
+
+// casFileReadaheadMinimumSequentialReads is the number of consecutive
+// reads that need to be performed against a file in sequential order
+// before readahead is enabled. A single read at the start of a file
+// does not indicate sequential access, as many tools only read a file's
+// header.
+const casFileReadaheadMinimumSequentialReads = 2
+
+// casFileReadahead keeps track of the offsets at which CAS-backed files
+// are read. Once a file is read sequentially, its contents are fetched
+// from the Content Addressable Storage in large chunks, and the next
+// chunk is already fetched while the current one is being consumed.
+type casFileReadahead struct {
+	context                   context.Context
+	contentAddressableStorage blobstore.BlobAccess
+	chunkSizeBytes            uint64
+	maximumFiles              int
+	clock                     clock.Clock
+
+	lock    re_sync.Mutex
+	streams map[digest.Digest]*list.Element
+	lru     list.List
+}
+
+func newCASFileReadahead(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, options *CASFileReadaheadOptions) *casFileReadahead {
+	casFileReadaheadPrometheusMetrics.Do(func() {
+		prometheus.MustRegister(casFileReadaheadReads)
+	})
+
+	return &casFileReadahead{
+		context:                   ctx,
+		contentAddressableStorage: contentAddressableStorage,
+		chunkSizeBytes:            uint64(options.ChunkSizeBytes),
+		maximumFiles:              options.MaximumFiles,
+		clock:                     options.Clock,
+		lock:                      re_sync.Mutex{Rank: &casFileReadaheadLockRank},
+		streams:                   map[digest.Digest]*list.Element{},
+	}
+}
+
+// readaheadStream contains the readahead state of a single file.
+type readaheadStream struct {
+	digest   digest.Digest
+	lastRead time.Time
+
+	// The offset at which the next read is expected to take place
+	// if the file is read sequentially, and the number of reads
+	// that have been performed in sequential order up to that point.
+	nextOffset      uint64
+	sequentialReads int
+	current         *readaheadChunk
+	next            *readaheadChunk
+}
+
+// readaheadChunk is a contiguous region of a file that is fetched from
+// the Content Addressable Storage in the background.
+type readaheadChunk struct {
+	offset uint64
+	data   []byte
+	ready  chan struct{}
+	err    error
+}
+
+func (c *readaheadChunk) contains(off, size uint64) bool {
+	return off >= c.offset && off+size <= c.offset+uint64(len(c.data))
+}
+
+func (ra *casFileReadahead) startFetch(blobDigest digest.Digest, off uint64) *readaheadChunk {
+	size := uint64(blobDigest.GetSizeBytes()) - off
+	if size > ra.chunkSizeBytes {
+		size = ra.chunkSizeBytes
+	}
+	c := &readaheadChunk{
+		offset: off,
+		data:   make([]byte, size),
+		ready:  make(chan struct{}),
+	}
+	go func() {
+		if n, err := ra.contentAddressableStorage.Get(ra.context, blobDigest).ReadAt(c.data, int64(off)); n != len(c.data) {
+			c.err = err
+		}
+		close(c.ready)
+	}()
+	return c
+}
+
+// getStreamLocked returns the readahead state of a file, creating it
+// if it does not exist. State of the least recently read file is
+// discarded if the maximum number of files is exceeded.
+func (ra *casFileReadahead) getStreamLocked(blobDigest digest.Digest) *readaheadStream {
+	if element, ok := ra.streams[blobDigest]; ok {
+		ra.lru.MoveToFront(element)
+		return element.Value.(*readaheadStream)
+	}
+	if ra.lru.Len() >= ra.maximumFiles {
+		oldest := ra.lru.Back()
+		delete(ra.streams, ra.lru.Remove(oldest).(*readaheadStream).digest)
+	}
+	s := &readaheadStream{digest: blobDigest}
+	ra.streams[blobDigest] = ra.lru.PushFront(s)
+	return s
+}
+
+// read data from a file, either by copying it from a chunk that has
+// been read ahead, or by reading it from the Content Addressable
+// Storage directly.
+func (ra *casFileReadahead) read(blobDigest digest.Digest, buf []byte, off uint64) error {
+	size := uint64(len(buf))
+	fileSize := uint64(blobDigest.GetSizeBytes())
+
+	ra.lock.Lock()
+	s := ra.getStreamLocked(blobDigest)
+	s.lastRead = ra.clock.Now()
+	if s.sequentialReads > 0 && off == s.nextOffset {
+		if s.sequentialReads < casFileReadaheadMinimumSequentialReads {
+			s.sequentialReads++
+		}
+	} else {
+		s.sequentialReads = 1
+	}
+	sequential := s.sequentialReads >= casFileReadaheadMinimumSequentialReads
+	s.nextOffset = off + size
+
+	var c *readaheadChunk
+	if s.current != nil && s.current.contains(off, size) {
+		c = s.current
+		casFileReadaheadReadsHit.Inc()
+	} else if s.next != nil && s.next.contains(off, size) {
+		c = s.next
+		s.current, s.next = s.next, nil
+		casFileReadaheadReadsHit.Inc()
+	} else if sequential && size <= ra.chunkSizeBytes {
+		c = ra.startFetch(blobDigest, off)
+		s.current, s.next = c, nil
+		casFileReadaheadReadsMiss.Inc()
+	} else {
+		// Random access. Don't pollute the readahead state by
+		// reading more data than requested.
+		ra.lock.Unlock()
+		casFileReadaheadReadsRandom.Inc()
+		if n, err := ra.contentAddressableStorage.Get(ra.context, blobDigest).ReadAt(buf, int64(off)); n != len(buf) {
+			return err
+		}
+		return nil
+	}
+
+	// Fetch the chunk following the current one while the current
+	// one is being consumed.
+	if sequential && s.next == nil {
+		if nextOffset := c.offset + uint64(len(c.data)); nextOffset < fileSize {
+			s.next = ra.startFetch(blobDigest, nextOffset)
+		}
+	}
+	ra.lock.Unlock()
+
+	<-c.ready
+	if c.err != nil {
+		// Discard the chunk, so that subsequent reads retry
+		// fetching the data.
+		ra.lock.Lock()
+		if s.current == c {
+			s.current = nil
+		}
+		ra.lock.Unlock()
+		return util.StatusWrapf(c.err, "Failed to read ahead at offset %d", c.offset)
+	}
+	copy(buf, c.data[off-c.offset:])
+	return nil
+}
+
+// evictIdleStreams discards the readahead state of files that have not
+// been read for a given amount of time, returning the number of files
+// for which state was discarded. As the list of files is ordered by
+// the time at which they were last read, only the tail of the list
+// needs to be inspected.
+func (ra *casFileReadahead) evictIdleStreams(maximumIdleTime time.Duration) int {
+	cutoff := ra.clock.Now().Add(-maximumIdleTime)
+
+	ra.lock.Lock()
+	defer ra.lock.Unlock()
+
+	count := 0
+	for oldest := ra.lru.Back(); oldest != nil && !oldest.Value.(*readaheadStream).lastRead.After(cutoff); oldest = ra.lru.Back() {
+		delete(ra.streams, ra.lru.Remove(oldest).(*readaheadStream).digest)
+		count++
+	}
+	return count
+}
+
+// flush discards the readahead state of all files, returning the
+// number of files for which state was discarded. Chunks that are still
+// being fetched remain available to reads that are waiting for them.
+func (ra *casFileReadahead) flush() int {
+	ra.lock.Lock()
+	defer ra.lock.Unlock()
+
+	count := ra.lru.Len()
+	ra.streams = map[digest.Digest]*list.Element{}
+	ra.lru.Init()
+	return count
+}
@@ -13,15 +13,17 @@ import (
 )
 
 type casInitialContentsFetcherOptions struct {
-	context        context.Context
-	casFileFactory CASFileFactory
-	symlinkFactory SymlinkFactory
-	digestFunction digest.Function
+	context               context.Context
+	casFileFactory        CASFileFactory
+	symlinkFactory        SymlinkFactory
+	symlinkTargetRewriter SymlinkTargetRewriter
+	digestFunction        digest.Function
 }
 
 type casInitialContentsFetcher struct {
 	options         *casInitialContentsFetcherOptions
 	directoryWalker cas.DirectoryWalker
+	depth           int
 }
 
 // NewCASInitialContentsFetcher creates an InitialContentsFetcher that
@@ -31,16 +33,22 @@ type casInitialContentsFetcher struct {
 // Upon request, it loads the root directory of the tree and converts
 // all of the children to either additional InitialContentFetchers
 // (directories), FileBackedFiles (regular files) or Symlinks (symbolic
-// links).
-func NewCASInitialContentsFetcher(ctx context.Context, directoryWalker cas.DirectoryWalker, casFileFactory CASFileFactory, symlinkFactory SymlinkFactory, digestFunction digest.Function) InitialContentsFetcher {
+// links). Targets of symbolic links are processed by the provided
+// SymlinkTargetRewriter. The depth is the number of directories between
+// the root of the directory hierarchy and the directory at which the
+// tree is instantiated, which is added to the depth of every symbolic
+// link.
+func NewCASInitialContentsFetcher(ctx context.Context, directoryWalker cas.DirectoryWalker, casFileFactory CASFileFactory, symlinkFactory SymlinkFactory, symlinkTargetRewriter SymlinkTargetRewriter, digestFunction digest.Function, depth int) InitialContentsFetcher {
 	return &casInitialContentsFetcher{
 		options: &casInitialContentsFetcherOptions{
-			context:        ctx,
-			casFileFactory: casFileFactory,
-			symlinkFactory: symlinkFactory,
-			digestFunction: digestFunction,
+			context:               ctx,
+			casFileFactory:        casFileFactory,
+			symlinkFactory:        symlinkFactory,
+			symlinkTargetRewriter: symlinkTargetRewriter,
+			digestFunction:        digestFunction,
 		},
 		directoryWalker: directoryWalker,
+		depth:           depth,
 	}
 }
 
@@ -70,6 +78,7 @@ func (icf *casInitialContentsFetcher) fetchContentsUnwrapped(fileReadMonitorFact
 		children[component] = InitialNode{}.FromDirectory(&casInitialContentsFetcher{
 			options:         icf.options,
 			directoryWalker: icf.directoryWalker.GetChild(childDigest),
+			depth:           icf.depth + 1,
 		})
 	}
 
@@ -110,7 +119,11 @@ func (icf *casInitialContentsFetcher) fetchContentsUnwrapped(fileReadMonitorFact
 			return nil, status.Errorf(codes.InvalidArgument, "Directory contains multiple children named %#v", entry.Name)
 		}
 
-		leaf := icf.options.symlinkFactory.LookupSymlink([]byte(entry.Target))
+		target, err := icf.options.symlinkTargetRewriter.RewriteSymlinkTarget(entry.Target, icf.depth)
+		if err != nil {
+			return nil, util.StatusWrapf(err, "Invalid target for symlink %#v", entry.Name)
+		}
+		leaf := icf.options.symlinkFactory.LookupSymlink([]byte(target))
 		children[component] = InitialNode{}.FromLeaf(leaf)
 		leavesToUnlink = append(leavesToUnlink, leaf)
 	}
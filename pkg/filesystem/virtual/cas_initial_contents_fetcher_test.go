@@ -28,7 +28,9 @@ func TestCASInitialContentsFetcherFetchContents(t *testing.T) {
 		directoryWalker,
 		casFileFactory,
 		symlinkFactory,
-		digest.MustNewFunction("hello", remoteexecution.DigestFunction_MD5))
+		virtual.IdentitySymlinkTargetRewriter,
+		digest.MustNewFunction("hello", remoteexecution.DigestFunction_MD5),
+		/* depth = */ 0)
 
 	t.Run("DirectoryWalkerFailure", func(t *testing.T) {
 		// Errors from the backend should be propagated.
@@ -235,6 +237,98 @@ func TestCASInitialContentsFetcherFetchContents(t *testing.T) {
 	})
 }
 
+func TestCASInitialContentsFetcherSymlinkTargetRewriting(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	directoryWalker := mock.NewMockDirectoryWalker(ctrl)
+	casFileFactory := mock.NewMockCASFileFactory(ctrl)
+	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
+	symlinkTargetRewriter := mock.NewMockSymlinkTargetRewriter(ctrl)
+	initialContentsFetcher := virtual.NewCASInitialContentsFetcher(
+		ctx,
+		directoryWalker,
+		casFileFactory,
+		symlinkFactory,
+		symlinkTargetRewriter,
+		digest.MustNewFunction("hello", remoteexecution.DigestFunction_MD5),
+		/* depth = */ 2)
+
+	// Let the root directory contain a subdirectory, which in turn
+	// contains symbolic links. The depth of the subdirectory,
+	// including the depth at which the root directory is
+	// instantiated, should be provided to the SymlinkTargetRewriter.
+	fileReadMonitorFactory := mock.NewMockFileReadMonitorFactory(ctrl)
+	directoryWalker.EXPECT().GetDirectory(ctx).Return(&remoteexecution.Directory{
+		Directories: []*remoteexecution.DirectoryNode{
+			{
+				Name: "directory",
+				Digest: &remoteexecution.Digest{
+					Hash:      "4b3b03436604cb9d831b91c71a8c1952",
+					SizeBytes: 123,
+				},
+			},
+		},
+	}, nil)
+	childDirectoryWalker := mock.NewMockDirectoryWalker(ctrl)
+	directoryWalker.EXPECT().GetChild(digest.MustNewDigest("hello", remoteexecution.DigestFunction_MD5, "4b3b03436604cb9d831b91c71a8c1952", 123)).
+		Return(childDirectoryWalker)
+
+	children, err := initialContentsFetcher.FetchContents(fileReadMonitorFactory.Call)
+	require.NoError(t, err)
+	childInitialContentsFetcher, _ := children[path.MustNewComponent("directory")].GetPair()
+
+	t.Run("Rewritten", func(t *testing.T) {
+		childFileReadMonitorFactory := mock.NewMockFileReadMonitorFactory(ctrl)
+		childDirectoryWalker.EXPECT().GetDirectory(ctx).Return(&remoteexecution.Directory{
+			Symlinks: []*remoteexecution.SymlinkNode{
+				{
+					Name:   "symlink",
+					Target: "/usr/lib/libfoo.so",
+				},
+			},
+		}, nil)
+		symlinkTargetRewriter.EXPECT().RewriteSymlinkTarget("/usr/lib/libfoo.so", 3).
+			Return("../../../sysroot/usr/lib/libfoo.so", nil)
+		symlinkLeaf := mock.NewMockNativeLeaf(ctrl)
+		symlinkFactory.EXPECT().LookupSymlink([]byte("../../../sysroot/usr/lib/libfoo.so")).Return(symlinkLeaf)
+
+		grandchildren, err := childInitialContentsFetcher.FetchContents(childFileReadMonitorFactory.Call)
+		require.NoError(t, err)
+		require.Equal(t, map[path.Component]virtual.InitialNode{
+			path.MustNewComponent("symlink"): virtual.InitialNode{}.FromLeaf(symlinkLeaf),
+		}, grandchildren)
+	})
+
+	t.Run("Refused", func(t *testing.T) {
+		// Errors returned by the SymlinkTargetRewriter should
+		// cause the directory to be rejected. Leaves that were
+		// already created should be released.
+		childFileReadMonitorFactory := mock.NewMockFileReadMonitorFactory(ctrl)
+		childDirectoryWalker.EXPECT().GetDirectory(ctx).Return(&remoteexecution.Directory{
+			Symlinks: []*remoteexecution.SymlinkNode{
+				{
+					Name:   "a",
+					Target: "b",
+				},
+				{
+					Name:   "symlink",
+					Target: "/etc/passwd",
+				},
+			},
+		}, nil)
+		symlinkTargetRewriter.EXPECT().RewriteSymlinkTarget("b", 3).Return("b", nil)
+		symlinkLeaf := mock.NewMockNativeLeaf(ctrl)
+		symlinkFactory.EXPECT().LookupSymlink([]byte("b")).Return(symlinkLeaf)
+		symlinkTargetRewriter.EXPECT().RewriteSymlinkTarget("/etc/passwd", 3).
+			Return("", status.Error(codes.InvalidArgument, "Target \"/etc/passwd\" is an absolute path that does not match any of the rewrite rules"))
+		symlinkLeaf.EXPECT().Unlink()
+		childDirectoryWalker.EXPECT().GetDescription().Return("Child directory")
+
+		_, err := childInitialContentsFetcher.FetchContents(childFileReadMonitorFactory.Call)
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Child directory: Invalid target for symlink \"symlink\": Target \"/etc/passwd\" is an absolute path that does not match any of the rewrite rules"), err)
+	})
+}
+
 func TestCASInitialContentsFetcherGetContainingDigests(t *testing.T) {
 	ctrl, ctx := gomock.WithContext(context.Background(), t)
 
@@ -246,7 +340,9 @@ func TestCASInitialContentsFetcherGetContainingDigests(t *testing.T) {
 		directoryWalker,
 		casFileFactory,
 		symlinkFactory,
-		digest.MustNewFunction("hello", remoteexecution.DigestFunction_MD5))
+		virtual.IdentitySymlinkTargetRewriter,
+		digest.MustNewFunction("hello", remoteexecution.DigestFunction_MD5),
+		/* depth = */ 0)
 
 	t.Run("DirectoryWalkerFailure", func(t *testing.T) {
 		// Errors from the backend should be propagated.
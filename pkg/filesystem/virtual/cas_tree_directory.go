@@ -0,0 +1,480 @@
+package virtual
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"sync/atomic"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/cas"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Identifiers that are stored in file handles of directories contained
+// in a Tree, following the digest of the Tree itself.
+const (
+	casTreeDirectoryRoot  = 0
+	casTreeDirectoryChild = 1
+)
+
+type casTreeDirectoryOptions struct {
+	directoryFetcher cas.DirectoryFetcher
+	casFileFactory   CASFileFactory
+	symlinkFactory   SymlinkFactory
+	errorLogger      util.ErrorLogger
+	caseInsensitive  bool
+	handleAllocator  *ResolvableDigestHandleAllocator
+
+	// The Directory message that was most recently found to have
+	// sorted lists of children by VirtualReadDir(). This prevents
+	// having to check the same message for every page of results.
+	lastSortedDirectory atomic.Pointer[remoteexecution.Directory]
+}
+
+type casTreeDirectory struct {
+	ReadOnlyDirectory
+
+	options        *casTreeDirectoryOptions
+	digestFunction digest.Function
+}
+
+// NewCASTreeDirectory creates a Directory that gives access to the
+// contents of arbitrary REv2 Tree objects stored in the Content
+// Addressable Storage (CAS). Trees can be accessed by looking up
+// directories named "${hash}-${size}", which expose the root directory
+// of the Tree as a read-only directory hierarchy. As the CAS cannot be
+// enumerated, the directory always appears to be empty when listed.
+//
+// This directory can be used to inspect output directories of actions
+// that have been executed previously, without rerunning them or
+// downloading their contents explicitly.
+//
+// Files are created through the provided CASFileFactory, meaning that
+// it should hand out files with a stateless or resolvable handle.
+// Directories in the hierarchy are given resolvable handles that
+// contain the digest of the Tree, followed by the digest of the
+// directory within the Tree.
+//
+// If caseInsensitive is set, lookups of children of directories in the
+// hierarchy are case-insensitive. Directories containing children
+// whose names only differ in case cannot be accessed.
+func NewCASTreeDirectory(directoryFetcher cas.DirectoryFetcher, casFileFactory CASFileFactory, symlinkFactory SymlinkFactory, errorLogger util.ErrorLogger, digestFunction digest.Function, allocation StatelessHandleAllocation, caseInsensitive bool) Directory {
+	return &casTreeDirectory{
+		options:        newCASTreeDirectoryOptions(directoryFetcher, casFileFactory, symlinkFactory, errorLogger, allocation, caseInsensitive),
+		digestFunction: digestFunction,
+	}
+}
+
+func newCASTreeDirectoryOptions(directoryFetcher cas.DirectoryFetcher, casFileFactory CASFileFactory, symlinkFactory SymlinkFactory, errorLogger util.ErrorLogger, allocation StatelessHandleAllocation, caseInsensitive bool) *casTreeDirectoryOptions {
+	options := &casTreeDirectoryOptions{
+		directoryFetcher: directoryFetcher,
+		casFileFactory:   casFileFactory,
+		symlinkFactory:   symlinkFactory,
+		errorLogger:      errorLogger,
+		caseInsensitive:  caseInsensitive,
+	}
+	options.handleAllocator = NewResolvableDigestHandleAllocator(allocation, options.resolve)
+	return options
+}
+
+func (d *casTreeDirectory) VirtualGetAttributes(ctx context.Context, requested AttributesMask, attributes *Attributes) {
+	attributes.SetChangeID(0)
+	attributes.SetFileType(filesystem.FileTypeDirectory)
+	attributes.SetIsImmutable(true)
+	attributes.SetLinkCount(EmptyDirectoryLinkCount)
+	attributes.SetPermissions(PermissionsRead | PermissionsExecute)
+	attributes.SetSizeBytes(0)
+}
+
+func (d *casTreeDirectory) VirtualLookup(ctx context.Context, name path.Component, requested AttributesMask, out *Attributes) (DirectoryChild, Status) {
+	treeDigest, ok := parseDigestFilename(d.digestFunction, name)
+	if !ok {
+		return DirectoryChild{}, StatusErrNoEnt
+	}
+	directory := d.options.lookupRootDirectory(treeDigest)
+	directory.VirtualGetAttributes(ctx, requested, out)
+	return DirectoryChild{}.FromDirectory(directory), StatusOK
+}
+
+func (d *casTreeDirectory) VirtualOpenChild(ctx context.Context, name path.Component, shareAccess ShareMask, createAttributes *Attributes, existingOptions *OpenExistingOptions, requested AttributesMask, openedFileAttributes *Attributes) (Leaf, AttributesMask, ChangeInfo, Status) {
+	if _, ok := parseDigestFilename(d.digestFunction, name); !ok {
+		return ReadOnlyDirectoryOpenChildDoesntExist(createAttributes)
+	}
+	if existingOptions == nil {
+		return nil, 0, ChangeInfo{}, StatusErrExist
+	}
+	return nil, 0, ChangeInfo{}, StatusErrIsDir
+}
+
+func (d *casTreeDirectory) VirtualReadDir(ctx context.Context, firstCookie uint64, requested AttributesMask, reporter DirectoryEntryReporter) Status {
+	return StatusOK
+}
+
+func (o *casTreeDirectoryOptions) lookupDirectory(treeDigest digest.Digest, directoryWalker cas.DirectoryWalker, id []byte) Directory {
+	return o.handleAllocator.
+		New(treeDigest).
+		AsResolvableAllocator(func(r io.ByteReader) (DirectoryChild, Status) {
+			return o.resolve(treeDigest, r)
+		}).
+		New(bytes.NewBuffer(id)).
+		AsStatelessDirectory(&casTreeChildDirectory{
+			options:         o,
+			directoryWalker: directoryWalker,
+			digestFunction:  treeDigest.GetDigestFunction(),
+		})
+}
+
+func (o *casTreeDirectoryOptions) lookupRootDirectory(treeDigest digest.Digest) Directory {
+	return o.lookupDirectory(
+		treeDigest,
+		cas.NewTreeDirectoryWalker(o.directoryFetcher, treeDigest),
+		[]byte{casTreeDirectoryRoot})
+}
+
+func (o *casTreeDirectoryOptions) lookupChildDirectory(treeDigest, childDigest digest.Digest) Directory {
+	return o.lookupDirectory(
+		treeDigest,
+		cas.NewTreeDirectoryWalker(o.directoryFetcher, treeDigest).GetChild(childDigest),
+		append([]byte{casTreeDirectoryChild}, childDigest.GetCompactBinary()...))
+}
+
+func (o *casTreeDirectoryOptions) resolve(treeDigest digest.Digest, remainder io.ByteReader) (DirectoryChild, Status) {
+	kind, err := remainder.ReadByte()
+	if err != nil {
+		return DirectoryChild{}, StatusErrBadHandle
+	}
+	switch kind {
+	case casTreeDirectoryRoot:
+		return DirectoryChild{}.FromDirectory(o.lookupRootDirectory(treeDigest)), StatusOK
+	case casTreeDirectoryChild:
+		childDigest, err := treeDigest.GetInstanceName().NewDigestFromCompactBinary(remainder)
+		if err != nil {
+			return DirectoryChild{}, StatusErrBadHandle
+		}
+		return DirectoryChild{}.FromDirectory(o.lookupChildDirectory(treeDigest, childDigest)), StatusOK
+	default:
+		return DirectoryChild{}, StatusErrBadHandle
+	}
+}
+
+// casTreeChildDirectory is a read-only directory whose contents are
+// backed by a Directory message contained in a Tree object.
+type casTreeChildDirectory struct {
+	ReadOnlyDirectory
+
+	options         *casTreeDirectoryOptions
+	directoryWalker cas.DirectoryWalker
+	digestFunction  digest.Function
+}
+
+// getDirectory loads the Directory message backing this directory. As
+// errors cannot be propagated to the kernel in a meaningful way, they
+// are logged.
+func (d *casTreeChildDirectory) getDirectory(ctx context.Context) (*remoteexecution.Directory, Status) {
+	directory, err := d.directoryWalker.GetDirectory(ctx)
+	if err != nil {
+		d.options.errorLogger.Log(util.StatusWrap(err, d.directoryWalker.GetDescription()))
+		return nil, StatusErrIO
+	}
+	return directory, StatusOK
+}
+
+// getEntriesUnwrapped converts the Directory message backing this
+// directory to a sorted list of directory entries. If lookups are
+// case-insensitive, it also returns an index of the names of the
+// entries, keyed by their case folded names.
+func (d *casTreeChildDirectory) getEntriesUnwrapped(ctx context.Context) (staticDirectoryEntryList, map[path.Component]path.Component, error) {
+	directory, err := d.directoryWalker.GetDirectory(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries := make(staticDirectoryEntryList, 0, len(directory.Directories)+len(directory.Files)+len(directory.Symlinks))
+	treeDigest := d.directoryWalker.GetContainingDigest()
+	for _, entry := range directory.Directories {
+		component, ok := path.NewComponent(entry.Name)
+		if !ok {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "Directory %#v has an invalid name", entry.Name)
+		}
+		childDigest, err := d.digestFunction.NewDigestFromProto(entry.Digest)
+		if err != nil {
+			return nil, nil, util.StatusWrapf(err, "Failed to obtain digest for directory %#v", entry.Name)
+		}
+		entries = append(entries, staticDirectoryEntry{
+			name:  component,
+			child: DirectoryChild{}.FromDirectory(d.options.lookupChildDirectory(treeDigest, childDigest)),
+		})
+	}
+	for _, entry := range directory.Files {
+		component, ok := path.NewComponent(entry.Name)
+		if !ok {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "File %#v has an invalid name", entry.Name)
+		}
+		childDigest, err := d.digestFunction.NewDigestFromProto(entry.Digest)
+		if err != nil {
+			return nil, nil, util.StatusWrapf(err, "Failed to obtain digest for file %#v", entry.Name)
+		}
+		entries = append(entries, staticDirectoryEntry{
+			name:  component,
+			child: DirectoryChild{}.FromLeaf(d.options.casFileFactory.LookupFile(childDigest, entry.IsExecutable, nil)),
+		})
+	}
+	for _, entry := range directory.Symlinks {
+		component, ok := path.NewComponent(entry.Name)
+		if !ok {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "Symlink %#v has an invalid name", entry.Name)
+		}
+		entries = append(entries, staticDirectoryEntry{
+			name:  component,
+			child: DirectoryChild{}.FromLeaf(d.options.symlinkFactory.LookupSymlink([]byte(entry.Target))),
+		})
+	}
+
+	// Sort all entries, so that lookups can be performed using a
+	// binary search, and to ensure that readdir() is deterministic.
+	sort.Sort(entries)
+	for i := 1; i < len(entries); i++ {
+		if entries[i-1].name == entries[i].name {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "Directory contains multiple children named %#v", entries[i].name.String())
+		}
+	}
+	var caseFoldedIndex map[path.Component]path.Component
+	if d.options.caseInsensitive {
+		names := make(path.ComponentsList, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.name)
+		}
+		caseFoldedIndex, err = newCaseFoldedIndex(names)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return entries, caseFoldedIndex, nil
+}
+
+// getEntries loads the Directory message backing this directory and
+// converts it to a sorted list of directory entries. As errors cannot
+// be propagated to the kernel in a meaningful way, they are logged.
+func (d *casTreeChildDirectory) getEntries(ctx context.Context) (staticDirectoryEntryList, map[path.Component]path.Component, Status) {
+	entries, caseFoldedIndex, err := d.getEntriesUnwrapped(ctx)
+	if err != nil {
+		d.options.errorLogger.Log(util.StatusWrap(err, d.directoryWalker.GetDescription()))
+		return nil, nil, StatusErrIO
+	}
+	return entries, caseFoldedIndex, StatusOK
+}
+
+// getEntryName returns the name of the directory entry that should be
+// used to look up a child. If lookups are case-insensitive, this
+// returns the name of the entry as stored in the Directory message,
+// which is obtained from an index keyed by case folded names.
+func getEntryName(caseFoldedIndex map[path.Component]path.Component, name path.Component) path.Component {
+	if caseFoldedIndex != nil {
+		if entryName, ok := caseFoldedIndex[foldCase(name)]; ok {
+			return entryName
+		}
+	}
+	return name
+}
+
+func (d *casTreeChildDirectory) VirtualGetAttributes(ctx context.Context, requested AttributesMask, attributes *Attributes) {
+	attributes.SetChangeID(0)
+	attributes.SetFileType(filesystem.FileTypeDirectory)
+	attributes.SetIsImmutable(true)
+	attributes.SetPermissions(PermissionsRead | PermissionsExecute)
+	attributes.SetSizeBytes(0)
+
+	// Only load the Directory message when the link count is
+	// requested, as it depends on the number of subdirectories.
+	// There is no need to convert it to a list of directory entries.
+	if requested&AttributesMaskLinkCount != 0 {
+		if directory, s := d.getDirectory(ctx); s == StatusOK {
+			attributes.SetLinkCount(EmptyDirectoryLinkCount + uint32(len(directory.Directories)))
+		} else {
+			attributes.SetLinkCount(EmptyDirectoryLinkCount)
+		}
+	}
+}
+
+func (d *casTreeChildDirectory) VirtualLookup(ctx context.Context, name path.Component, requested AttributesMask, out *Attributes) (DirectoryChild, Status) {
+	entries, caseFoldedIndex, s := d.getEntries(ctx)
+	if s != StatusOK {
+		return DirectoryChild{}, s
+	}
+	return entries.virtualLookup(ctx, getEntryName(caseFoldedIndex, name), requested, out)
+}
+
+func (d *casTreeChildDirectory) VirtualOpenChild(ctx context.Context, name path.Component, shareAccess ShareMask, createAttributes *Attributes, existingOptions *OpenExistingOptions, requested AttributesMask, openedFileAttributes *Attributes) (Leaf, AttributesMask, ChangeInfo, Status) {
+	entries, caseFoldedIndex, s := d.getEntries(ctx)
+	if s != StatusOK {
+		return nil, 0, ChangeInfo{}, s
+	}
+	return entries.virtualOpenChild(ctx, getEntryName(caseFoldedIndex, name), shareAccess, createAttributes, existingOptions, requested, openedFileAttributes)
+}
+
+// VirtualReadDir reports the entries of the directory in sorted order.
+// Unlike VirtualLookup(), it does not convert the full Directory
+// message to a list of directory entries, as that causes large
+// allocations when paging through directories containing many
+// children. The lists of directories, files and symlinks contained in
+// the Directory message are already sorted, meaning they can be merged
+// on the fly. Cookies correspond to indices in the merged list.
+func (d *casTreeChildDirectory) VirtualReadDir(ctx context.Context, firstCookie uint64, requested AttributesMask, reporter DirectoryEntryReporter) Status {
+	directory, s := d.getDirectory(ctx)
+	if s != StatusOK {
+		return s
+	}
+	if !d.options.isSortedDirectory(directory) {
+		// The lists contained in the Directory message are
+		// not sorted, meaning they cannot be merged. Fall
+		// back to sorting all entries, so that the results
+		// remain consistent with VirtualLookup().
+		entries, _, s := d.getEntries(ctx)
+		if s != StatusOK {
+			return s
+		}
+		return entries.virtualReadDir(ctx, firstCookie, requested, reporter)
+	}
+	if err := d.readDirUnwrapped(ctx, directory, firstCookie, requested, reporter); err != nil {
+		d.options.errorLogger.Log(util.StatusWrap(err, d.directoryWalker.GetDescription()))
+		return StatusErrIO
+	}
+	return StatusOK
+}
+
+// isSortedDirectory returns whether the lists of directories, files
+// and symlinks contained in a Directory message are each sorted by
+// name, and don't contain any duplicates.
+func (o *casTreeDirectoryOptions) isSortedDirectory(directory *remoteexecution.Directory) bool {
+	if o.lastSortedDirectory.Load() == directory {
+		return true
+	}
+	if !isSortedByName(directory.Directories) || !isSortedByName(directory.Files) || !isSortedByName(directory.Symlinks) {
+		return false
+	}
+	o.lastSortedDirectory.Store(directory)
+	return true
+}
+
+type namedNode interface {
+	GetName() string
+}
+
+func isSortedByName[T namedNode](nodes []T) bool {
+	for i := 1; i < len(nodes); i++ {
+		if nodes[i-1].GetName() >= nodes[i].GetName() {
+			return false
+		}
+	}
+	return true
+}
+
+// countNamesBefore returns the number of entries in a sorted list
+// whose name precedes the provided name.
+func countNamesBefore[T namedNode](nodes []T, name string) int {
+	return sort.Search(len(nodes), func(i int) bool { return nodes[i].GetName() >= name })
+}
+
+// countEntriesBeforeCookie returns the number of entries in a sorted
+// list that are placed before a given cookie in the merged list of
+// directories, files and symlinks. The position of an entry in the
+// merged list is equal to its index in its own list, plus the number
+// of entries in the other two lists having a name that precedes it.
+func countEntriesBeforeCookie[T, U, V namedNode](nodes []T, others1 []U, others2 []V, cookie uint64) int {
+	return sort.Search(len(nodes), func(i int) bool {
+		name := nodes[i].GetName()
+		return uint64(i+countNamesBefore(others1, name)+countNamesBefore(others2, name)) >= cookie
+	})
+}
+
+func (d *casTreeChildDirectory) readDirUnwrapped(ctx context.Context, directory *remoteexecution.Directory, firstCookie uint64, requested AttributesMask, reporter DirectoryEntryReporter) error {
+	// Skip the entries preceding the provided cookie by performing
+	// a binary search against each of the lists, so that the cost
+	// of returning a page of results does not depend on its offset.
+	directories, files, symlinks := directory.Directories, directory.Files, directory.Symlinks
+	directoriesSkipped := countEntriesBeforeCookie(directories, files, symlinks, firstCookie)
+	filesSkipped := countEntriesBeforeCookie(files, directories, symlinks, firstCookie)
+	symlinksSkipped := countEntriesBeforeCookie(symlinks, directories, files, firstCookie)
+	cookie := uint64(directoriesSkipped + filesSkipped + symlinksSkipped)
+	if totalEntries := uint64(len(directories) + len(files) + len(symlinks)); cookie != firstCookie && firstCookie < totalEntries {
+		// Entries are only assigned distinct positions if all
+		// names are unique.
+		return status.Error(codes.InvalidArgument, "Directory contains multiple children with the same name")
+	}
+	directories = directories[directoriesSkipped:]
+	files = files[filesSkipped:]
+	symlinks = symlinks[symlinksSkipped:]
+
+	treeDigest := d.directoryWalker.GetContainingDigest()
+	previousName := ""
+	for ; len(directories) > 0 || len(files) > 0 || len(symlinks) > 0; cookie++ {
+		// Pick the entry with the lowest name among the heads
+		// of the three lists.
+		var name string
+		switch {
+		case len(directories) > 0 &&
+			(len(files) == 0 || directories[0].Name <= files[0].Name) &&
+			(len(symlinks) == 0 || directories[0].Name <= symlinks[0].Name):
+			name = directories[0].Name
+		case len(files) > 0 &&
+			(len(symlinks) == 0 || files[0].Name <= symlinks[0].Name):
+			name = files[0].Name
+		default:
+			name = symlinks[0].Name
+		}
+		if cookie > firstCookie && name == previousName {
+			return status.Errorf(codes.InvalidArgument, "Directory contains multiple children named %#v", name)
+		}
+		previousName = name
+
+		// Consume the entry.
+		var component path.Component
+		var ok bool
+		var child DirectoryChild
+		if len(directories) > 0 && directories[0].Name == name {
+			entry := directories[0]
+			directories = directories[1:]
+			if component, ok = path.NewComponent(name); !ok {
+				return status.Errorf(codes.InvalidArgument, "Directory %#v has an invalid name", name)
+			}
+			childDigest, err := d.digestFunction.NewDigestFromProto(entry.Digest)
+			if err != nil {
+				return util.StatusWrapf(err, "Failed to obtain digest for directory %#v", entry.Name)
+			}
+			child = DirectoryChild{}.FromDirectory(d.options.lookupChildDirectory(treeDigest, childDigest))
+		} else if len(files) > 0 && files[0].Name == name {
+			entry := files[0]
+			files = files[1:]
+			if component, ok = path.NewComponent(name); !ok {
+				return status.Errorf(codes.InvalidArgument, "File %#v has an invalid name", name)
+			}
+			childDigest, err := d.digestFunction.NewDigestFromProto(entry.Digest)
+			if err != nil {
+				return util.StatusWrapf(err, "Failed to obtain digest for file %#v", entry.Name)
+			}
+			child = DirectoryChild{}.FromLeaf(d.options.casFileFactory.LookupFile(childDigest, entry.IsExecutable, nil))
+		} else {
+			entry := symlinks[0]
+			symlinks = symlinks[1:]
+			if component, ok = path.NewComponent(name); !ok {
+				return status.Errorf(codes.InvalidArgument, "Symlink %#v has an invalid name", name)
+			}
+			child = DirectoryChild{}.FromLeaf(d.options.symlinkFactory.LookupSymlink([]byte(entry.Target)))
+		}
+
+		var attributes Attributes
+		child.GetNode().VirtualGetAttributes(ctx, requested, &attributes)
+		if !reporter.ReportEntry(cookie+1, component, child, &attributes) {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package virtual_test
+
+import (
+	"context"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/random"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCASTreeDirectory(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	directoryFetcher := mock.NewMockDirectoryFetcher(ctrl)
+	casFileFactory := mock.NewMockCASFileFactory(ctrl)
+	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	handleAllocator := virtual.NewNFSHandleAllocator(random.NewFastSingleThreadedGenerator())
+	d := virtual.NewCASTreeDirectory(
+		directoryFetcher,
+		casFileFactory,
+		symlinkFactory,
+		errorLogger,
+		digest.MustNewFunction("example", remoteexecution.DigestFunction_SHA256),
+		handleAllocator.New(),
+		/* caseInsensitive = */ false)
+
+	treeDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_SHA256, "2c3b0a4aa8fbb0ab8e1a7ac4b8ff0a3b0e5d0c6ed3e29c0b9b6f7d3b4a0c4e7f", 300)
+	treeName := path.MustNewComponent("2c3b0a4aa8fbb0ab8e1a7ac4b8ff0a3b0e5d0c6ed3e29c0b9b6f7d3b4a0c4e7f-300")
+
+	t.Run("InvalidFilename", func(t *testing.T) {
+		for _, name := range []string{
+			"hello",
+			"2c3b0a4aa8fbb0ab8e1a7ac4b8ff0a3b0e5d0c6ed3e29c0b9b6f7d3b4a0c4e7f",
+			"2c3b0a4aa8fbb0ab8e1a7ac4b8ff0a3b0e5d0c6ed3e29c0b9b6f7d3b4a0c4e7f-",
+			"8b1a9953c4611296a827abf8c47804d7-5",
+		} {
+			var out virtual.Attributes
+			_, s := d.VirtualLookup(ctx, path.MustNewComponent(name), 0, &out)
+			require.Equal(t, virtual.StatusErrNoEnt, s, name)
+		}
+	})
+
+	t.Run("ReadDir", func(t *testing.T) {
+		// The directory can't be listed, as the CAS cannot be
+		// enumerated.
+		reporter := mock.NewMockDirectoryEntryReporter(ctrl)
+		require.Equal(t, virtual.StatusOK, d.VirtualReadDir(ctx, 0, 0, reporter))
+	})
+
+	t.Run("OpenChild", func(t *testing.T) {
+		// Trees are exposed as directories, meaning they
+		// cannot be opened as files.
+		_, _, _, s := d.VirtualOpenChild(
+			ctx,
+			treeName,
+			virtual.ShareMaskRead,
+			nil,
+			&virtual.OpenExistingOptions{},
+			0,
+			&virtual.Attributes{})
+		require.Equal(t, virtual.StatusErrIsDir, s)
+	})
+
+	t.Run("FetchFailure", func(t *testing.T) {
+		var out virtual.Attributes
+		child, s := d.VirtualLookup(ctx, treeName, 0, &out)
+		require.Equal(t, virtual.StatusOK, s)
+		treeDirectory, _ := child.GetPair()
+		require.NotNil(t, treeDirectory)
+
+		// Errors loading the Tree should be logged, as they
+		// cannot be propagated to the user.
+		directoryFetcher.EXPECT().GetTreeRootDirectory(ctx, treeDigest).
+			Return(nil, status.Error(codes.Internal, "Server failure"))
+		errorLogger.EXPECT().Log(testutil.EqStatus(t, status.Error(codes.Internal, "Tree \"1-2c3b0a4aa8fbb0ab8e1a7ac4b8ff0a3b0e5d0c6ed3e29c0b9b6f7d3b4a0c4e7f-300-example\" root directory: Server failure")))
+
+		_, s = treeDirectory.VirtualLookup(ctx, path.MustNewComponent("foo"), 0, &out)
+		require.Equal(t, virtual.StatusErrIO, s)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		var out virtual.Attributes
+		child, s := d.VirtualLookup(ctx, treeName, 0, &out)
+		require.Equal(t, virtual.StatusOK, s)
+		treeDirectory, _ := child.GetPair()
+		require.NotNil(t, treeDirectory)
+
+		rootDirectory := &remoteexecution.Directory{
+			Directories: []*remoteexecution.DirectoryNode{
+				{
+					Name: "dir",
+					Digest: &remoteexecution.Digest{
+						Hash:      "4b1ef3ac4b1bc4fdba0b6bcbb7e8e7ae9ad4c1e0a7b3c6c2a1f2e8a6c1d0b9e4",
+						SizeBytes: 0,
+					},
+				},
+			},
+			Files: []*remoteexecution.FileNode{
+				{
+					Name: "file",
+					Digest: &remoteexecution.Digest{
+						Hash:      "185f8db32271fe25f561a6fc938b2e264306ec304eda518007d1764826381969",
+						SizeBytes: 5,
+					},
+					IsExecutable: true,
+				},
+			},
+			Symlinks: []*remoteexecution.SymlinkNode{
+				{
+					Name:   "link",
+					Target: "file",
+				},
+			},
+		}
+		file := mock.NewMockNativeLeaf(ctrl)
+		symlink := mock.NewMockNativeLeaf(ctrl)
+		expectRootDirectory := func() {
+			directoryFetcher.EXPECT().GetTreeRootDirectory(ctx, treeDigest).Return(rootDirectory, nil)
+			casFileFactory.EXPECT().LookupFile(
+				digest.MustNewDigest("example", remoteexecution.DigestFunction_SHA256, "185f8db32271fe25f561a6fc938b2e264306ec304eda518007d1764826381969", 5),
+				/* isExecutable = */ true,
+				/* readMonitor = */ nil,
+			).Return(file)
+			symlinkFactory.EXPECT().LookupSymlink([]byte("file")).Return(symlink)
+		}
+
+		// The link count of the directory should be based on
+		// the number of subdirectories. This should not
+		// require any nodes for the children to be created.
+		directoryFetcher.EXPECT().GetTreeRootDirectory(ctx, treeDigest).Return(rootDirectory, nil)
+		treeDirectory.VirtualGetAttributes(ctx, virtual.AttributesMaskLinkCount, &out)
+		require.Equal(t, virtual.EmptyDirectoryLinkCount+1, out.GetLinkCount())
+
+		// Look up a file in the Tree.
+		expectRootDirectory()
+		file.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMaskSizeBytes, gomock.Any()).
+			Do(func(ctx context.Context, requested virtual.AttributesMask, out *virtual.Attributes) {
+				out.SetSizeBytes(5)
+			})
+		child, s = treeDirectory.VirtualLookup(ctx, path.MustNewComponent("file"), virtual.AttributesMaskSizeBytes, &out)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, virtual.DirectoryChild{}.FromLeaf(file), child)
+
+		// Entries should be reported in sorted order.
+		expectRootDirectory()
+		file.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMask(0), gomock.Any())
+		symlink.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMask(0), gomock.Any())
+		reporter := mock.NewMockDirectoryEntryReporter(ctrl)
+		gomock.InOrder(
+			reporter.EXPECT().ReportEntry(uint64(1), path.MustNewComponent("dir"), gomock.Any(), gomock.Any()).Return(true),
+			reporter.EXPECT().ReportEntry(uint64(2), path.MustNewComponent("file"), virtual.DirectoryChild{}.FromLeaf(file), gomock.Any()).Return(true),
+			reporter.EXPECT().ReportEntry(uint64(3), path.MustNewComponent("link"), virtual.DirectoryChild{}.FromLeaf(symlink), gomock.Any()).Return(true))
+		require.Equal(t, virtual.StatusOK, treeDirectory.VirtualReadDir(ctx, 0, 0, reporter))
+
+		// When resuming iteration at a later cookie, nodes
+		// should only be created for the entries that are
+		// reported. This prevents large allocations when paging
+		// through directories containing many children.
+		directoryFetcher.EXPECT().GetTreeRootDirectory(ctx, treeDigest).Return(rootDirectory, nil)
+		symlinkFactory.EXPECT().LookupSymlink([]byte("file")).Return(symlink)
+		symlink.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMask(0), gomock.Any())
+		reporter.EXPECT().ReportEntry(uint64(3), path.MustNewComponent("link"), virtual.DirectoryChild{}.FromLeaf(symlink), gomock.Any()).Return(true)
+		require.Equal(t, virtual.StatusOK, treeDirectory.VirtualReadDir(ctx, 2, 0, reporter))
+
+		// Iteration should stop as soon as the reporter
+		// indicates it has no space left.
+		directoryFetcher.EXPECT().GetTreeRootDirectory(ctx, treeDigest).Return(rootDirectory, nil)
+		casFileFactory.EXPECT().LookupFile(
+			digest.MustNewDigest("example", remoteexecution.DigestFunction_SHA256, "185f8db32271fe25f561a6fc938b2e264306ec304eda518007d1764826381969", 5),
+			/* isExecutable = */ true,
+			/* readMonitor = */ nil,
+		).Return(file)
+		file.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMask(0), gomock.Any())
+		reporter.EXPECT().ReportEntry(uint64(2), path.MustNewComponent("file"), virtual.DirectoryChild{}.FromLeaf(file), gomock.Any()).Return(false)
+		require.Equal(t, virtual.StatusOK, treeDirectory.VirtualReadDir(ctx, 1, 0, reporter))
+
+		// Child directories should be loaded from the same Tree.
+		expectRootDirectory()
+		child, s = treeDirectory.VirtualLookup(ctx, path.MustNewComponent("dir"), 0, &out)
+		require.Equal(t, virtual.StatusOK, s)
+		childDirectory, _ := child.GetPair()
+		require.NotNil(t, childDirectory)
+
+		directoryFetcher.EXPECT().GetTreeChildDirectory(
+			ctx,
+			treeDigest,
+			digest.MustNewDigest("example", remoteexecution.DigestFunction_SHA256, "4b1ef3ac4b1bc4fdba0b6bcbb7e8e7ae9ad4c1e0a7b3c6c2a1f2e8a6c1d0b9e4", 0),
+		).Return(&remoteexecution.Directory{}, nil)
+		require.Equal(t, virtual.StatusOK, childDirectory.VirtualReadDir(ctx, 0, 0, reporter))
+	})
+
+	t.Run("DuplicateNames", func(t *testing.T) {
+		var out virtual.Attributes
+		child, s := d.VirtualLookup(ctx, treeName, 0, &out)
+		require.Equal(t, virtual.StatusOK, s)
+		treeDirectory, _ := child.GetPair()
+		require.NotNil(t, treeDirectory)
+
+		// When resuming iteration, duplicate names preceding
+		// the cookie should be detected, as entries can't be
+		// assigned distinct cookies.
+		directoryFetcher.EXPECT().GetTreeRootDirectory(ctx, treeDigest).Return(&remoteexecution.Directory{
+			Files: []*remoteexecution.FileNode{
+				{
+					Name: "a",
+					Digest: &remoteexecution.Digest{
+						Hash:      "185f8db32271fe25f561a6fc938b2e264306ec304eda518007d1764826381969",
+						SizeBytes: 5,
+					},
+				},
+				{
+					Name: "c",
+					Digest: &remoteexecution.Digest{
+						Hash:      "185f8db32271fe25f561a6fc938b2e264306ec304eda518007d1764826381969",
+						SizeBytes: 5,
+					},
+				},
+			},
+			Symlinks: []*remoteexecution.SymlinkNode{
+				{
+					Name:   "a",
+					Target: "c",
+				},
+			},
+		}, nil)
+		errorLogger.EXPECT().Log(testutil.EqStatus(t, status.Error(codes.InvalidArgument, "Tree \"1-2c3b0a4aa8fbb0ab8e1a7ac4b8ff0a3b0e5d0c6ed3e29c0b9b6f7d3b4a0c4e7f-300-example\" root directory: Directory contains multiple children with the same name")))
+
+		reporter := mock.NewMockDirectoryEntryReporter(ctrl)
+		require.Equal(t, virtual.StatusErrIO, treeDirectory.VirtualReadDir(ctx, 1, 0, reporter))
+	})
+
+	t.Run("UnsortedLists", func(t *testing.T) {
+		var out virtual.Attributes
+		child, s := d.VirtualLookup(ctx, treeName, 0, &out)
+		require.Equal(t, virtual.StatusOK, s)
+		treeDirectory, _ := child.GetPair()
+		require.NotNil(t, treeDirectory)
+
+		// Directory messages whose lists are not sorted cannot
+		// be merged on the fly. The entries should still be
+		// reported in sorted order, so that cookies are
+		// assigned in the same way as if the lists were sorted.
+		rootDirectory := &remoteexecution.Directory{
+			Files: []*remoteexecution.FileNode{
+				{
+					Name: "b",
+					Digest: &remoteexecution.Digest{
+						Hash:      "185f8db32271fe25f561a6fc938b2e264306ec304eda518007d1764826381969",
+						SizeBytes: 5,
+					},
+				},
+				{
+					Name: "a",
+					Digest: &remoteexecution.Digest{
+						Hash:      "185f8db32271fe25f561a6fc938b2e264306ec304eda518007d1764826381969",
+						SizeBytes: 5,
+					},
+				},
+			},
+		}
+		directoryFetcher.EXPECT().GetTreeRootDirectory(ctx, treeDigest).Return(rootDirectory, nil).Times(2)
+		file := mock.NewMockNativeLeaf(ctrl)
+		casFileFactory.EXPECT().LookupFile(
+			digest.MustNewDigest("example", remoteexecution.DigestFunction_SHA256, "185f8db32271fe25f561a6fc938b2e264306ec304eda518007d1764826381969", 5),
+			/* isExecutable = */ false,
+			/* readMonitor = */ nil,
+		).Return(file).Times(2)
+		file.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMask(0), gomock.Any())
+		reporter := mock.NewMockDirectoryEntryReporter(ctrl)
+		reporter.EXPECT().ReportEntry(uint64(2), path.MustNewComponent("b"), virtual.DirectoryChild{}.FromLeaf(file), gomock.Any()).Return(true)
+		require.Equal(t, virtual.StatusOK, treeDirectory.VirtualReadDir(ctx, 1, 0, reporter))
+	})
+}
@@ -0,0 +1,63 @@
+package virtual
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// foldRune returns the canonical representative of the set of runes
+// that are equivalent to a given rune under Unicode simple case
+// folding. The smallest rune in the orbit of unicode.SimpleFold() is
+// used, as that makes the result independent of the case of the input.
+func foldRune(r rune) rune {
+	folded := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < folded {
+			folded = f
+		}
+	}
+	return folded
+}
+
+// foldCase converts a filename to the form that is used to compare
+// filenames in directories that perform case-insensitive lookups.
+// Filenames that are not valid UTF-8 are compared as is, as converting
+// them could cause unrelated filenames to collide.
+func foldCase(name path.Component) path.Component {
+	s := name.String()
+	if !utf8.ValidString(s) {
+		return name
+	}
+	return path.MustNewComponent(strings.Map(foldRune, s))
+}
+
+// newCaseFoldedIndex creates a map from case folded filenames to the
+// filenames in a list. It returns an error if the list contains
+// multiple names that only differ in case. Such directories cannot be
+// represented when lookups are case-insensitive.
+func newCaseFoldedIndex(names path.ComponentsList) (map[path.Component]path.Component, error) {
+	sort.Sort(names)
+	index := make(map[path.Component]path.Component, len(names))
+	for _, name := range names {
+		key := foldCase(name)
+		if otherName, ok := index[key]; ok {
+			return nil, status.Errorf(codes.InvalidArgument, "Directory contains children %#v and %#v, whose names only differ in case", otherName.String(), name.String())
+		}
+		index[key] = name
+	}
+	return index, nil
+}
+
+// checkCaseCollisions returns an error if a list of filenames contains
+// multiple names that only differ in case.
+func checkCaseCollisions(names path.ComponentsList) error {
+	_, err := newCaseFoldedIndex(names)
+	return err
+}
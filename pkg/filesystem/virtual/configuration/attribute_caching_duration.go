@@ -2,6 +2,12 @@ package configuration
 
 import (
 	"time"
+
+	pb "github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // AttributeCachingDuration specifies the amount of time attributes of
@@ -18,6 +24,30 @@ type AttributeCachingDuration struct {
 	maximum time.Duration
 }
 
+// newAttributeCachingDurationFromConfiguration creates an
+// AttributeCachingDuration based on options provided in a configuration
+// message. If no configuration message is provided, a default value
+// chosen by the application is returned.
+func newAttributeCachingDurationFromConfiguration(configuration *pb.NFSv4AttributeCachingConfiguration, defaultValue AttributeCachingDuration) (AttributeCachingDuration, error) {
+	if configuration == nil {
+		return defaultValue, nil
+	}
+	if err := configuration.Minimum.CheckValid(); err != nil {
+		return AttributeCachingDuration{}, util.StatusWrap(err, "Invalid minimum")
+	}
+	if err := configuration.Maximum.CheckValid(); err != nil {
+		return AttributeCachingDuration{}, util.StatusWrap(err, "Invalid maximum")
+	}
+	a := AttributeCachingDuration{
+		minimum: configuration.Minimum.AsDuration(),
+		maximum: configuration.Maximum.AsDuration(),
+	}
+	if a.minimum < 0 || a.minimum > a.maximum {
+		return AttributeCachingDuration{}, status.Errorf(codes.InvalidArgument, "Minimum %s and maximum %s do not form a valid range", a.minimum, a.maximum)
+	}
+	return a, nil
+}
+
 // Min returns the lowest attribute cache duration. This can be used to
 // combine multiple attribute caching durations into a single value, in
 // case the NFS client makes no distinction between individual values.
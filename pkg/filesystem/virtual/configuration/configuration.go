@@ -1,6 +1,10 @@
 package configuration
 
 import (
+	"log"
+	"net"
+	"sync"
+
 	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
 	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual/nfsv4"
 	pb "github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/filesystem/virtual"
@@ -12,17 +16,36 @@ import (
 	nfsv4_xdr "github.com/buildbarn/go-xdr/pkg/protocols/nfsv4"
 	"github.com/buildbarn/go-xdr/pkg/rpcserver"
 	"github.com/jmespath/go-jmespath"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+var (
+	mountPrometheusMetrics sync.Once
+
+	mountBackendInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "buildbarn",
+			Subsystem: "virtual",
+			Name:      "mount_backend_info",
+			Help:      "Backend that is used to expose a virtual file system mount.",
+		},
+		[]string{"fs_name", "mount_path", "backend"})
+)
+
 // Mount of a virtual file system that has been created using
 // NewMountFromConfiguration(), but that hasn't been exposed to the
 // kernel or network yet. Before calling Expose(), the caller has the
 // possibility to construct a root directory.
+//
+// If a StatFSProvider is provided to Expose(), it is used to report the
+// capacity and free space of the file system (e.g., to df). If none is
+// provided, the file system is reported as being empty and having no
+// free space.
 type Mount interface {
-	Expose(terminationGroup program.Group, rootDirectory virtual.Directory) error
+	Expose(terminationGroup program.Group, rootDirectory virtual.Directory, statFSProvider virtual.StatFSProvider) error
 }
 
 type fuseMount struct {
@@ -43,7 +66,7 @@ type nfsv4Mount struct {
 	leavesAttributeCaching           AttributeCachingDuration
 }
 
-func (m *nfsv4Mount) Expose(terminationGroup program.Group, rootDirectory virtual.Directory) error {
+func (m *nfsv4Mount) Expose(terminationGroup program.Group, rootDirectory virtual.Directory, statFSProvider virtual.StatFSProvider) error {
 	// Random values that the client can use to detect that the
 	// server has been restarted and lost all state.
 	var verifier nfsv4_xdr.Verifier4
@@ -59,6 +82,17 @@ func (m *nfsv4Mount) Expose(terminationGroup program.Group, rootDirectory virtua
 	if err := announcedLeaseTime.CheckValid(); err != nil {
 		return util.StatusWrap(err, "Invalid announced lease time")
 	}
+	clientStateLimits := nfsv4.ClientStateLimits{
+		MaximumOpenOwners:     int(m.configuration.ClientStateLimits.GetMaximumOpenOwners()),
+		MaximumOpenFiles:      int(m.configuration.ClientStateLimits.GetMaximumOpenFiles()),
+		MaximumLockOwnerFiles: int(m.configuration.ClientStateLimits.GetMaximumLockOwnerFiles()),
+	}
+	if d := m.configuration.ClientStateLimits.GetExpirationGracePeriod(); d != nil {
+		if err := d.CheckValid(); err != nil {
+			return util.StatusWrap(err, "Invalid client expiration grace period")
+		}
+		clientStateLimits.ExpirationGracePeriod = d.AsDuration()
+	}
 
 	// Create an RPC server that offers the NFSv4 program.
 	rpcServer := rpcserver.NewServer(map[uint32]rpcserver.Service{
@@ -72,7 +106,9 @@ func (m *nfsv4Mount) Expose(terminationGroup program.Group, rootDirectory virtua
 					stateIDOtherPrefix,
 					clock.SystemClock,
 					enforcedLeaseTime.AsDuration(),
-					announcedLeaseTime.AsDuration()))),
+					announcedLeaseTime.AsDuration(),
+					clientStateLimits,
+					statFSProvider))),
 	}, m.authenticator)
 
 	return m.mount(terminationGroup, rpcServer)
@@ -81,28 +117,186 @@ func (m *nfsv4Mount) Expose(terminationGroup program.Group, rootDirectory virtua
 // NewMountFromConfiguration creates a new FUSE mount based on options
 // specified in a configuration message and starts processing of
 // incoming requests.
+//
+// If the configuration message contains a fallback, the availability
+// of the preferred backend is checked first. If it is not available or
+// cannot be constructed, the fallback backend is used instead. In
+// addition to that, if exposing the mount through the selected backend
+// fails, the next fallback backends are attempted, provided that they
+// can use the same handle allocator.
 func NewMountFromConfiguration(configuration *pb.MountConfiguration, fsName string, rootDirectoryAttributeCaching, childDirectoriesAttributeCaching, leavesAttributeCaching AttributeCachingDuration) (Mount, virtual.StatefulHandleAllocator, error) {
+	mountPrometheusMetrics.Do(func() {
+		prometheus.MustRegister(mountBackendInfo)
+	})
+
+	mountPath := configuration.MountPath
+	var mount Mount
+	var handleAllocator virtual.StatefulHandleAllocator
+	var backendName string
+	for configuration.Fallback != nil {
+		var err error
+		backendName, err = checkBackendAvailability(configuration)
+		if err == nil {
+			mount, handleAllocator, backendName, err = newMountFromBackend(configuration, mountPath, fsName, nil, rootDirectoryAttributeCaching, childDirectoriesAttributeCaching, leavesAttributeCaching)
+			if err == nil {
+				break
+			}
+		}
+		log.Printf("Cannot use %s to expose mount %#v, trying fallback: %s", backendName, mountPath, err)
+		configuration = configuration.Fallback
+	}
+	if mount == nil {
+		var err error
+		mount, handleAllocator, backendName, err = newMountFromBackend(configuration, mountPath, fsName, nil, rootDirectoryAttributeCaching, childDirectoriesAttributeCaching, leavesAttributeCaching)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	candidates := []fallbackMountCandidate{{
+		mount:       mount,
+		backendName: backendName,
+	}}
+
+	// The root directory passed to Expose() is constructed using
+	// the handle allocator returned by this function. Exposing it
+	// through another backend is thus only possible if that
+	// backend uses the same kind of handle allocator. Fallbacks
+	// that cannot be constructed are skipped.
+	for fallback := configuration.Fallback; fallback != nil; fallback = fallback.Fallback {
+		fallbackBackendName, err := checkBackendAvailability(fallback)
+		if err == nil {
+			if usesFUSEHandleAllocator(fallback) != usesFUSEHandleAllocator(configuration) {
+				log.Printf("Cannot use %s as a fallback for exposing mount %#v, as it requires a different kind of handle allocator than %s", fallbackBackendName, mountPath, backendName)
+				continue
+			}
+			var fallbackMount Mount
+			fallbackMount, _, fallbackBackendName, err = newMountFromBackend(fallback, mountPath, fsName, handleAllocator, rootDirectoryAttributeCaching, childDirectoriesAttributeCaching, leavesAttributeCaching)
+			if err == nil {
+				candidates = append(candidates, fallbackMountCandidate{
+					mount:       fallbackMount,
+					backendName: fallbackBackendName,
+				})
+				continue
+			}
+		}
+		log.Printf("Cannot use %s as a fallback for exposing mount %#v: %s", fallbackBackendName, mountPath, err)
+	}
+	return &fallbackMount{
+		candidates: candidates,
+		mountPath:  mountPath,
+		fsName:     fsName,
+	}, handleAllocator, nil
+}
+
+type fallbackMountCandidate struct {
+	mount       Mount
+	backendName string
+}
+
+// fallbackMount is a Mount that attempts to expose a virtual file
+// system through one or more backends, stopping at the first one that
+// succeeds.
+type fallbackMount struct {
+	candidates []fallbackMountCandidate
+	mountPath  string
+	fsName     string
+}
+
+func (m *fallbackMount) Expose(terminationGroup program.Group, rootDirectory virtual.Directory, statFSProvider virtual.StatFSProvider) error {
+	var err error
+	for i, candidate := range m.candidates {
+		if i > 0 {
+			log.Printf("Failed to expose mount %#v using %s, trying fallback: %s", m.mountPath, m.candidates[i-1].backendName, err)
+		}
+		if err = candidate.mount.Expose(terminationGroup, rootDirectory, statFSProvider); err == nil {
+			log.Printf("Using %s to expose mount %#v", candidate.backendName, m.mountPath)
+			mountBackendInfo.WithLabelValues(m.fsName, m.mountPath, candidate.backendName).Set(1)
+			return nil
+		}
+	}
+	return err
+}
+
+// closeListeners closes listening sockets that were created by a Mount
+// that failed to be exposed, so that fallback backends are capable of
+// using the same addresses.
+func closeListeners(listeners []net.Listener) {
+	for _, listener := range listeners {
+		listener.Close()
+	}
+}
+
+// usesFUSEHandleAllocator returns whether the backend specified in a
+// mount configuration message uses a FUSEStatefulHandleAllocator, as
+// opposed to an NFSStatefulHandleAllocator.
+func usesFUSEHandleAllocator(configuration *pb.MountConfiguration) bool {
+	_, ok := configuration.Backend.(*pb.MountConfiguration_Nfsv4)
+	return !ok
+}
+
+// checkBackendAvailability checks whether the backend specified in a
+// mount configuration message can be used on the current system.
+func checkBackendAvailability(configuration *pb.MountConfiguration) (string, error) {
+	switch backend := configuration.Backend.(type) {
+	case *pb.MountConfiguration_Fuse:
+		return "FUSE", checkFUSEAvailability(backend.Fuse)
+	case *pb.MountConfiguration_Nfsv4:
+		return "NFSv4", checkNFSv4Availability(backend.Nfsv4)
+	case *pb.MountConfiguration_Ninep:
+		// The 9P server runs entirely in user space.
+		return "9P", nil
+	case *pb.MountConfiguration_Virtiofs:
+		return "virtio-fs", checkVirtioFSAvailability(backend.Virtiofs)
+	case *pb.MountConfiguration_Smb:
+		// The SMB server runs entirely in user space.
+		return "SMB", nil
+	default:
+		return "", status.Error(codes.InvalidArgument, "No virtual file system backend configuration provided")
+	}
+}
+
+// newMountFromBackend creates a Mount for the backend specified in a
+// mount configuration message. An existing handle allocator may be
+// provided, which is reused if it is of the kind that is used by the
+// backend.
+func newMountFromBackend(configuration *pb.MountConfiguration, mountPath, fsName string, existingHandleAllocator virtual.StatefulHandleAllocator, rootDirectoryAttributeCaching, childDirectoriesAttributeCaching, leavesAttributeCaching AttributeCachingDuration) (Mount, virtual.StatefulHandleAllocator, string, error) {
+	fuseHandleAllocator, _ := existingHandleAllocator.(*virtual.FUSEStatefulHandleAllocator)
 	switch backend := configuration.Backend.(type) {
 	case *pb.MountConfiguration_Fuse:
-		handleAllocator := virtual.NewFUSEHandleAllocator(random.FastThreadSafeGenerator)
+		handleAllocator := fuseHandleAllocator
+		if handleAllocator == nil {
+			handleAllocator = virtual.NewFUSEHandleAllocator(random.FastThreadSafeGenerator)
+		}
 		return &fuseMount{
-			mountPath:       configuration.MountPath,
+			mountPath:       mountPath,
 			configuration:   backend.Fuse,
 			handleAllocator: handleAllocator,
 			fsName:          fsName,
-		}, handleAllocator, nil
+		}, handleAllocator, "FUSE", nil
 	case *pb.MountConfiguration_Nfsv4:
-		handleAllocator := virtual.NewNFSHandleAllocator(random.NewFastSingleThreadedGenerator())
+		handleAllocator, ok := existingHandleAllocator.(*virtual.NFSStatefulHandleAllocator)
+		if !ok {
+			handleAllocator = virtual.NewNFSHandleAllocator(random.NewFastSingleThreadedGenerator())
+			if d := backend.Nfsv4.WritebackTimeout; d != nil {
+				if err := d.CheckValid(); err != nil {
+					return nil, nil, "", util.StatusWrap(err, "Failed to parse writeback timeout")
+				}
+				if d.AsDuration() <= 0 {
+					return nil, nil, "", status.Error(codes.InvalidArgument, "Writeback timeout must be positive")
+				}
+				handleAllocator.SetWritebackTimeout(clock.SystemClock, d.AsDuration())
+			}
+		}
 
 		authenticator := rpcserver.AllowAuthenticator
 		if systemAuthentication := backend.Nfsv4.SystemAuthentication; systemAuthentication != nil {
 			compiledExpression, err := jmespath.Compile(systemAuthentication.MetadataJmespathExpression)
 			if err != nil {
-				return nil, nil, util.StatusWrap(err, "Failed to compile system authentication metadata JMESPath expression")
+				return nil, nil, "", util.StatusWrap(err, "Failed to compile system authentication metadata JMESPath expression")
 			}
 			evictionSet, err := eviction.NewSetFromConfiguration[nfsv4.SystemAuthenticatorCacheKey](systemAuthentication.CacheReplacementPolicy)
 			if err != nil {
-				return nil, nil, util.StatusWrap(err, "Failed to create system authentication eviction set")
+				return nil, nil, "", util.StatusWrap(err, "Failed to create system authentication eviction set")
 			}
 			authenticator = nfsv4.NewSystemAuthenticator(
 				compiledExpression,
@@ -110,8 +304,21 @@ func NewMountFromConfiguration(configuration *pb.MountConfiguration, fsName stri
 				eviction.NewMetricsSet(evictionSet, "SystemAuthenticator"))
 		}
 
+		rootDirectoryAttributeCaching, err := newAttributeCachingDurationFromConfiguration(backend.Nfsv4.RootDirectoryAttributeCaching, rootDirectoryAttributeCaching)
+		if err != nil {
+			return nil, nil, "", util.StatusWrap(err, "Invalid root directory attribute caching duration")
+		}
+		childDirectoriesAttributeCaching, err := newAttributeCachingDurationFromConfiguration(backend.Nfsv4.ChildDirectoriesAttributeCaching, childDirectoriesAttributeCaching)
+		if err != nil {
+			return nil, nil, "", util.StatusWrap(err, "Invalid child directories attribute caching duration")
+		}
+		leavesAttributeCaching, err := newAttributeCachingDurationFromConfiguration(backend.Nfsv4.LeavesAttributeCaching, leavesAttributeCaching)
+		if err != nil {
+			return nil, nil, "", util.StatusWrap(err, "Invalid leaves attribute caching duration")
+		}
+
 		return &nfsv4Mount{
-			mountPath:                        configuration.MountPath,
+			mountPath:                        mountPath,
 			configuration:                    backend.Nfsv4,
 			handleAllocator:                  handleAllocator,
 			authenticator:                    authenticator,
@@ -119,8 +326,47 @@ func NewMountFromConfiguration(configuration *pb.MountConfiguration, fsName stri
 			rootDirectoryAttributeCaching:    rootDirectoryAttributeCaching,
 			childDirectoriesAttributeCaching: childDirectoriesAttributeCaching,
 			leavesAttributeCaching:           leavesAttributeCaching,
-		}, handleAllocator, nil
+		}, handleAllocator, "NFSv4", nil
+	case *pb.MountConfiguration_Ninep:
+		// The 9P server keeps track of fids per connection,
+		// meaning it can use the same handle allocator as FUSE.
+		handleAllocator := fuseHandleAllocator
+		if handleAllocator == nil {
+			handleAllocator = virtual.NewFUSEHandleAllocator(random.FastThreadSafeGenerator)
+		}
+		return &ninepMount{
+			configuration:   backend.Ninep,
+			handleAllocator: handleAllocator,
+		}, handleAllocator, "9P", nil
+	case *pb.MountConfiguration_Virtiofs:
+		// Requests are processed by the same FUSE server that
+		// is used to create local mounts.
+		handleAllocator := fuseHandleAllocator
+		if handleAllocator == nil {
+			handleAllocator = virtual.NewFUSEHandleAllocator(random.FastThreadSafeGenerator)
+		}
+		return &virtiofsMount{
+			socketPath: backend.Virtiofs.VhostUserSocketPath,
+			fuseMount: fuseMount{
+				mountPath:       mountPath,
+				configuration:   backend.Virtiofs.Fuse,
+				handleAllocator: handleAllocator,
+				fsName:          fsName,
+			},
+		}, handleAllocator, "virtio-fs", nil
+	case *pb.MountConfiguration_Smb:
+		// The SMB server keeps track of open files per
+		// connection, meaning it can use the same handle
+		// allocator as FUSE.
+		handleAllocator := fuseHandleAllocator
+		if handleAllocator == nil {
+			handleAllocator = virtual.NewFUSEHandleAllocator(random.FastThreadSafeGenerator)
+		}
+		return &smbMount{
+			configuration:   backend.Smb,
+			handleAllocator: handleAllocator,
+		}, handleAllocator, "SMB", nil
 	default:
-		return nil, nil, status.Error(codes.InvalidArgument, "No virtual file system backend configuration provided")
+		return nil, nil, "", status.Error(codes.InvalidArgument, "No virtual file system backend configuration provided")
 	}
 }
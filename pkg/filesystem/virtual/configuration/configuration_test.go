@@ -0,0 +1,175 @@
+package configuration_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual/configuration"
+	pb "github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/program"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exposeMount exposes a mount, returning the error returned by
+// Expose(). Any servers that are launched by Expose() are shut down
+// before returning.
+func exposeMount(t *testing.T, mount configuration.Mount, rootDirectory virtual.Directory) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var exposeErr error
+	require.NoError(t, program.RunLocal(ctx, func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+		exposeErr = mount.Expose(siblingsGroup, rootDirectory, nil)
+		cancel()
+		return nil
+	}))
+	return exposeErr
+}
+
+// getMountBackend returns the name of the backend that was used to
+// expose a mount, as reported through Prometheus.
+func getMountBackend(t *testing.T, fsName string) string {
+	metricFamilies, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, metricFamily := range metricFamilies {
+		if metricFamily.GetName() != "buildbarn_virtual_mount_backend_info" {
+			continue
+		}
+		for _, metric := range metricFamily.Metric {
+			labels := map[string]string{}
+			for _, label := range metric.Label {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["fs_name"] == fsName {
+				return labels["backend"]
+			}
+		}
+	}
+	return ""
+}
+
+// getUnusedAddress returns a TCP address on which no server is
+// listening.
+func getUnusedAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	return address
+}
+
+func newNinePMountConfiguration(listenAddresses ...string) *pb.MountConfiguration {
+	return &pb.MountConfiguration{
+		MountPath: "/mnt",
+		Backend: &pb.MountConfiguration_Ninep{
+			Ninep: &pb.NinePMountConfiguration{
+				ListenAddresses:           listenAddresses,
+				MaximumMessageSizeBytes:   1 << 16,
+				MaximumConcurrentRequests: 10,
+			},
+		},
+	}
+}
+
+func newSMBMountConfiguration(listenAddresses ...string) *pb.MountConfiguration {
+	return &pb.MountConfiguration{
+		MountPath: "/mnt",
+		Backend: &pb.MountConfiguration_Smb{
+			Smb: &pb.SMBMountConfiguration{
+				ListenAddresses: listenAddresses,
+				ShareName:       "share",
+				AllowGuest:      true,
+			},
+		},
+	}
+}
+
+func TestNewMountFromConfigurationFallback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
+
+	// Keep an address occupied, so that servers attempting to
+	// listen on it fail.
+	occupiedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer occupiedListener.Close()
+	occupiedAddress := occupiedListener.Addr().String()
+
+	t.Run("PreferredBackendUnavailable", func(t *testing.T) {
+		// Configuration messages without a backend can never
+		// be used. The fallback should be selected immediately.
+		mountConfiguration := &pb.MountConfiguration{
+			MountPath: "/mnt",
+			Fallback:  newNinePMountConfiguration(getUnusedAddress(t)),
+		}
+		mount, handleAllocator, err := configuration.NewMountFromConfiguration(mountConfiguration, "PreferredBackendUnavailable", configuration.NoAttributeCaching, configuration.NoAttributeCaching, configuration.NoAttributeCaching)
+		require.NoError(t, err)
+		require.IsType(t, &virtual.FUSEStatefulHandleAllocator{}, handleAllocator)
+
+		require.NoError(t, exposeMount(t, mount, rootDirectory))
+		require.Equal(t, "9P", getMountBackend(t, "PreferredBackendUnavailable"))
+	})
+
+	t.Run("ExposeFallback", func(t *testing.T) {
+		// The 9P server is capable of listening on the first
+		// address, but not on the second. This should cause the
+		// SMB server to be used instead. The SMB server should
+		// be able to listen on the first address, as the 9P
+		// server should have closed it.
+		unusedAddress := getUnusedAddress(t)
+		mountConfiguration := newNinePMountConfiguration(unusedAddress, occupiedAddress)
+		mountConfiguration.Fallback = newSMBMountConfiguration(unusedAddress)
+		mount, _, err := configuration.NewMountFromConfiguration(mountConfiguration, "ExposeFallback", configuration.NoAttributeCaching, configuration.NoAttributeCaching, configuration.NoAttributeCaching)
+		require.NoError(t, err)
+
+		require.NoError(t, exposeMount(t, mount, rootDirectory))
+		require.Equal(t, "SMB", getMountBackend(t, "ExposeFallback"))
+	})
+
+	t.Run("ExposeFallbackSkipsUnusableBackends", func(t *testing.T) {
+		// Fallbacks that are unavailable, or that require a
+		// different kind of handle allocator should be skipped,
+		// as opposed to causing the mount to fail.
+		mountConfiguration := newNinePMountConfiguration(occupiedAddress)
+		mountConfiguration.Fallback = &pb.MountConfiguration{
+			MountPath: "/mnt",
+			Backend: &pb.MountConfiguration_Nfsv4{
+				Nfsv4: &pb.NFSv4MountConfiguration{},
+			},
+			Fallback: &pb.MountConfiguration{
+				MountPath: "/mnt",
+				Fallback:  newSMBMountConfiguration(getUnusedAddress(t)),
+			},
+		}
+		mount, _, err := configuration.NewMountFromConfiguration(mountConfiguration, "ExposeFallbackSkipsUnusableBackends", configuration.NoAttributeCaching, configuration.NoAttributeCaching, configuration.NoAttributeCaching)
+		require.NoError(t, err)
+
+		require.NoError(t, exposeMount(t, mount, rootDirectory))
+		require.Equal(t, "SMB", getMountBackend(t, "ExposeFallbackSkipsUnusableBackends"))
+	})
+
+	t.Run("AllBackendsFail", func(t *testing.T) {
+		// If none of the backends can be exposed, the error of
+		// the last backend should be returned.
+		mountConfiguration := newNinePMountConfiguration(occupiedAddress)
+		mountConfiguration.Fallback = newSMBMountConfiguration()
+		mount, _, err := configuration.NewMountFromConfiguration(mountConfiguration, "AllBackendsFail", configuration.NoAttributeCaching, configuration.NoAttributeCaching, configuration.NoAttributeCaching)
+		require.NoError(t, err)
+
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.InvalidArgument, "No listen addresses provided for SMB server"),
+			exposeMount(t, mount, rootDirectory))
+		require.Equal(t, "", getMountBackend(t, "AllBackendsFail"))
+	})
+}
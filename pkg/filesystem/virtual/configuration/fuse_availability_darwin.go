@@ -0,0 +1,31 @@
+//go:build darwin
+// +build darwin
+
+package configuration
+
+import (
+	"os"
+
+	pb "github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/filesystem/virtual"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Locations at which the OSXFUSE and macFUSE kernel extensions install
+// their file system bundles.
+var fuseFilesystemBundlePaths = []string{
+	"/Library/Filesystems/macfuse.fs",
+	"/Library/Filesystems/osxfuse.fs",
+}
+
+// checkFUSEAvailability checks whether a FUSE kernel extension is
+// installed on the system.
+func checkFUSEAvailability(configuration *pb.FUSEMountConfiguration) error {
+	for _, path := range fuseFilesystemBundlePaths {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		}
+	}
+	return status.Error(codes.Unavailable, "Neither macFUSE nor OSXFUSE is installed")
+}
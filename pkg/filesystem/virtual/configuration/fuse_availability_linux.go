@@ -0,0 +1,29 @@
+//go:build linux
+// +build linux
+
+package configuration
+
+import (
+	pb "github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"golang.org/x/sys/unix"
+)
+
+// checkFUSEAvailability checks whether the FUSE character device can
+// be opened. This is typically not the case when running inside
+// containers that have not been granted access to /dev/fuse.
+//
+// The check is skipped if /dev/fuse is opened by another process on
+// our behalf, as that permits running without access to it.
+func checkFUSEAvailability(configuration *pb.FUSEMountConfiguration) error {
+	if configuration.FusermountPath != "" || configuration.FuseDeviceSocketPath != "" {
+		return nil
+	}
+	fd, err := unix.Open("/dev/fuse", unix.O_RDWR|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return util.StatusWrap(err, "Failed to open /dev/fuse")
+	}
+	unix.Close(fd)
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build darwin
+// +build darwin
+
+package configuration
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func openFUSEDeviceUsingFusermount(fusermountPath, mountPath string, mountOptions []string) (int, error) {
+	return -1, status.Error(codes.Unimplemented, "Mounting through fusermount is only supported on Linux")
+}
+
+func openFUSEDeviceFromSocket(socketPath string) (int, error) {
+	return -1, status.Error(codes.Unimplemented, "Receiving FUSE devices over UNIX sockets is only supported on Linux")
+}
@@ -0,0 +1,99 @@
+//go:build linux
+// +build linux
+
+package configuration
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"golang.org/x/sys/unix"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// receiveFUSEDevice reads a single message from a UNIX socket that
+// carries a file descriptor of /dev/fuse as ancillary data.
+func receiveFUSEDevice(conn *net.UnixConn) (int, error) {
+	var data [1]byte
+	oob := make([]byte, unix.CmsgSpace(4))
+	_, oobn, _, _, err := conn.ReadMsgUnix(data[:], oob)
+	if err != nil {
+		return -1, util.StatusWrapWithCode(err, codes.Unavailable, "Failed to receive message")
+	}
+	messages, err := unix.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		return -1, util.StatusWrapWithCode(err, codes.InvalidArgument, "Failed to parse control messages")
+	}
+	var fds []int
+	for i := range messages {
+		messageFDs, err := unix.ParseUnixRights(&messages[i])
+		if err == nil {
+			fds = append(fds, messageFDs...)
+		}
+	}
+	if len(fds) != 1 {
+		for _, fd := range fds {
+			unix.Close(fd)
+		}
+		return -1, status.Errorf(codes.InvalidArgument, "Expected to receive exactly one file descriptor, while %d were received", len(fds))
+	}
+	return fds[0], nil
+}
+
+// openFUSEDeviceUsingFusermount creates a FUSE mount by invoking an
+// external fusermount3 helper, which is typically installed setuid
+// root. The helper opens /dev/fuse, mounts it, and passes the file
+// descriptor back to us through the socket referenced by _FUSE_COMMFD.
+// This permits creating FUSE mounts without having CAP_SYS_ADMIN.
+func openFUSEDeviceUsingFusermount(fusermountPath, mountPath string, mountOptions []string) (int, error) {
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return -1, util.StatusWrapWithCode(err, codes.Internal, "Failed to create socket pair")
+	}
+	localFile := os.NewFile(uintptr(fds[0]), "fusermount-local")
+	defer localFile.Close()
+	remoteFile := os.NewFile(uintptr(fds[1]), "fusermount-remote")
+	defer remoteFile.Close()
+
+	cmd := exec.Command(fusermountPath, "-o", strings.Join(mountOptions, ","), "--", mountPath)
+	// The remote end of the socket pair becomes file descriptor 3.
+	cmd.ExtraFiles = []*os.File{remoteFile}
+	cmd.Env = append(os.Environ(), "_FUSE_COMMFD=3")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return -1, util.StatusWrapfWithCode(err, codes.Internal, "Failed to run %#v: %#v", fusermountPath, strings.TrimSpace(string(output)))
+	}
+
+	conn, err := net.FileConn(localFile)
+	if err != nil {
+		return -1, util.StatusWrapWithCode(err, codes.Internal, "Failed to create connection from socket")
+	}
+	defer conn.Close()
+	fd, err := receiveFUSEDevice(conn.(*net.UnixConn))
+	if err != nil {
+		return -1, util.StatusWrapf(err, "Failed to receive FUSE device from %#v", fusermountPath)
+	}
+	return fd, nil
+}
+
+// openFUSEDeviceFromSocket obtains a file descriptor of /dev/fuse that
+// has already been mounted by a privileged process, such as a
+// Kubernetes device plugin or a sidecar container. The file descriptor
+// is received over a UNIX socket.
+func openFUSEDeviceFromSocket(socketPath string) (int, error) {
+	conn, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: socketPath, Net: "unix"})
+	if err != nil {
+		return -1, util.StatusWrapfWithCode(err, codes.Unavailable, "Failed to connect to %#v", socketPath)
+	}
+	defer conn.Close()
+	fd, err := receiveFUSEDevice(conn)
+	if err != nil {
+		return -1, util.StatusWrapf(err, "Failed to receive FUSE device from %#v", socketPath)
+	}
+	return fd, nil
+}
@@ -5,12 +5,17 @@ package configuration
 
 import (
 	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	pb "github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/filesystem/virtual"
 	"github.com/buildbarn/bb-storage/pkg/program"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-func (m *fuseMount) Expose(terminationGroup program.Group, rootDirectory virtual.Directory) error {
+func (m *fuseMount) Expose(terminationGroup program.Group, rootDirectory virtual.Directory, statFSProvider virtual.StatFSProvider) error {
+	return status.Error(codes.Unimplemented, "FUSE is not supported on this platform")
+}
+
+func checkFUSEAvailability(configuration *pb.FUSEMountConfiguration) error {
 	return status.Error(codes.Unimplemented, "FUSE is not supported on this platform")
 }
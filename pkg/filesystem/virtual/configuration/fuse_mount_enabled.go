@@ -4,84 +4,238 @@
 package configuration
 
 import (
+	"fmt"
+	pathpkg "path"
+	"strings"
 	"time"
 
 	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
 	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual/fuse"
+	"github.com/buildbarn/bb-storage/pkg/auth"
 	"github.com/buildbarn/bb-storage/pkg/clock"
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/program"
 	"github.com/buildbarn/bb-storage/pkg/util"
 	go_fuse "github.com/hanwen/go-fuse/v2/fuse"
 	"github.com/jmespath/go-jmespath"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-func (m *fuseMount) Expose(terminationGroup program.Group, rootDirectory virtual.Directory) error {
+// defaultFUSEMaxBackground is the maximum number of outstanding
+// background requests that go-fuse uses if none is configured.
+const defaultFUSEMaxBackground = 12
+
+// newRawFileSystem creates a go-fuse RawFileSystem that exposes the
+// provided root directory, using the options stored in the FUSE mount
+// configuration.
+func (m *fuseMount) newRawFileSystem(rootDirectory virtual.Directory, removalNotifierRegistrar virtual.FUSERemovalNotifierRegistrar, statFSProvider virtual.StatFSProvider) (go_fuse.RawFileSystem, error) {
 	// Parse configuration options.
 	var directoryEntryValidity time.Duration
 	if d := m.configuration.DirectoryEntryValidity; d != nil {
 		if err := d.CheckValid(); err != nil {
-			util.StatusWrap(err, "Failed to parse directory entry validity")
+			return nil, util.StatusWrap(err, "Failed to parse directory entry validity")
 		}
 		directoryEntryValidity = d.AsDuration()
 	}
 	var inodeAttributeValidity time.Duration
 	if d := m.configuration.InodeAttributeValidity; d != nil {
 		if err := d.CheckValid(); err != nil {
-			util.StatusWrap(err, "Failed to parse inode attribute validity")
+			return nil, util.StatusWrap(err, "Failed to parse inode attribute validity")
 		}
 		inodeAttributeValidity = d.AsDuration()
 	}
+	var immutableInodeAttributeValidity time.Duration
+	if d := m.configuration.ImmutableInodeAttributeValidity; d != nil {
+		if err := d.CheckValid(); err != nil {
+			return nil, util.StatusWrap(err, "Failed to parse immutable inode attribute validity")
+		}
+		immutableInodeAttributeValidity = d.AsDuration()
+	}
+	var immutableDirectoryEntryValidity time.Duration
+	if d := m.configuration.ImmutableDirectoryEntryValidity; d != nil {
+		if err := d.CheckValid(); err != nil {
+			return nil, util.StatusWrap(err, "Failed to parse immutable directory entry validity")
+		}
+		immutableDirectoryEntryValidity = d.AsDuration()
+	}
+
+	var directIOMatcher fuse.DirectIOMatcher
+	if patterns := m.configuration.DirectIoPathPatterns; len(patterns) > 0 {
+		for _, pattern := range patterns {
+			if _, err := pathpkg.Match(pattern, ""); err != nil {
+				return nil, util.StatusWrapfWithCode(err, codes.InvalidArgument, "Invalid direct I/O path pattern %#v", pattern)
+			}
+		}
+		directIOMatcher = func(relativePath string) bool {
+			for _, pattern := range patterns {
+				if matchDirectIOPathPattern(pattern, relativePath) {
+					return true
+				}
+			}
+			return false
+		}
+	}
 
 	authenticator := fuse.AllowAuthenticator
 	if expression := m.configuration.InHeaderAuthenticationMetadataJmespathExpression; expression != "" {
 		compiledExpression, err := jmespath.Compile(expression)
 		if err != nil {
-			return util.StatusWrap(err, "Failed to compile in-header authentication metadata JMESPath expression")
+			return nil, util.StatusWrap(err, "Failed to compile in-header authentication metadata JMESPath expression")
 		}
 		authenticator = fuse.NewInHeaderAuthenticator(compiledExpression)
 	}
 
+	var rawFileSystem go_fuse.RawFileSystem = fuse.NewSimpleRawFileSystem(
+		rootDirectory,
+		removalNotifierRegistrar,
+		authenticator,
+		immutableInodeAttributeValidity,
+		immutableDirectoryEntryValidity,
+		directIOMatcher,
+		statFSProvider)
+	if m.configuration.EmulateLocks {
+		rawFileSystem = fuse.NewLockEmulatingRawFileSystem(rawFileSystem)
+	}
+	if !m.configuration.EnableExtendedAttributes {
+		rawFileSystem = fuse.NewXAttrDisablingRawFileSystem(rawFileSystem)
+	}
+	if !m.configuration.DisableWritebackCache {
+		rawFileSystem = fuse.NewWriteBarrierDisablingRawFileSystem(rawFileSystem)
+	}
+
+	deterministicTimestamp := uint64(filesystem.DeterministicFileModificationTimestamp.Unix())
+	return fuse.NewMetricsRawFileSystem(
+		fuse.NewDefaultAttributesInjectingRawFileSystem(
+			rawFileSystem,
+			directoryEntryValidity,
+			inodeAttributeValidity,
+			&go_fuse.Attr{
+				Atime: deterministicTimestamp,
+				Ctime: deterministicTimestamp,
+				Mtime: deterministicTimestamp,
+			}),
+		clock.SystemClock), nil
+}
+
+// newMountOptions returns the options that need to be provided to
+// go-fuse when creating the FUSE server.
+func (m *fuseMount) newMountOptions() *go_fuse.MountOptions {
+	return &go_fuse.MountOptions{
+		// The name isn't strictly necessary, but is
+		// filled in to prevent runc from crashing with
+		// this error:
+		// https://github.com/opencontainers/runc/blob/v1.0.0-rc10/libcontainer/mount/mount_linux.go#L69
+		//
+		// Newer versions of runc use an improved parser
+		// that's more reliable:
+		// https://github.com/moby/sys/blob/master/mountinfo/mountinfo_linux.go
+		FsName:        m.fsName,
+		AllowOther:    m.configuration.AllowOther,
+		DirectMount:   m.configuration.DirectMount,
+		MaxWrite:      int(m.configuration.MaxWrite),
+		MaxBackground: int(m.configuration.MaxBackground),
+		// Speed up workloads that perform many tiny
+		// writes. This means data is only guaranteed to
+		// make it into the virtual file system after
+		// calling close()/fsync()/munmap()/msync().
+		EnableWritebackCache: !m.configuration.DisableWritebackCache,
+		EnableLocks:          m.configuration.EmulateLocks,
+	}
+}
+
+func (m *fuseMount) Expose(terminationGroup program.Group, rootDirectory virtual.Directory, statFSProvider virtual.StatFSProvider) error {
+	if authorizerConfiguration := m.configuration.Authorizer; authorizerConfiguration != nil {
+		// Access is authorized based on the authentication
+		// metadata that is extracted from FUSE request headers.
+		if m.configuration.InHeaderAuthenticationMetadataJmespathExpression == "" {
+			return status.Error(codes.InvalidArgument, "An authorizer can only be used if in-header authentication metadata is extracted")
+		}
+		authorizer, err := auth.DefaultAuthorizerFactory.NewAuthorizerFromConfiguration(authorizerConfiguration)
+		if err != nil {
+			return util.StatusWrap(err, "Failed to create authorizer")
+		}
+		rootDirectory = virtual.NewAuthorizingDirectory(rootDirectory, authorizer)
+	}
+
+	rawFileSystem, err := m.newRawFileSystem(rootDirectory, m.handleAllocator.RegisterRemovalNotifier, statFSProvider)
+	if err != nil {
+		return err
+	}
+
 	// Launch the FUSE server.
 	removeStaleMounts(m.mountPath)
-	deterministicTimestamp := uint64(filesystem.DeterministicFileModificationTimestamp.Unix())
-	server, err := go_fuse.NewServer(
-		fuse.NewMetricsRawFileSystem(
-			fuse.NewDefaultAttributesInjectingRawFileSystem(
-				fuse.NewSimpleRawFileSystem(
-					rootDirectory,
-					m.handleAllocator.RegisterRemovalNotifier,
-					authenticator),
-				directoryEntryValidity,
-				inodeAttributeValidity,
-				&go_fuse.Attr{
-					Atime: deterministicTimestamp,
-					Ctime: deterministicTimestamp,
-					Mtime: deterministicTimestamp,
-				}),
-			clock.SystemClock),
-		m.mountPath,
-		&go_fuse.MountOptions{
-			// The name isn't strictly necessary, but is
-			// filled in to prevent runc from crashing with
-			// this error:
-			// https://github.com/opencontainers/runc/blob/v1.0.0-rc10/libcontainer/mount/mount_linux.go#L69
-			//
-			// Newer versions of runc use an improved parser
-			// that's more reliable:
-			// https://github.com/moby/sys/blob/master/mountinfo/mountinfo_linux.go
-			FsName:      m.fsName,
-			AllowOther:  m.configuration.AllowOther,
-			DirectMount: m.configuration.DirectMount,
-			// Speed up workloads that perform many tiny
-			// writes. This means data is only guaranteed to
-			// make it into the virtual file system after
-			// calling close()/fsync()/munmap()/msync().
-			EnableWritebackCache: true,
-		})
+
+	// Optionally obtain a file descriptor of /dev/fuse that has
+	// already been mounted by a more privileged process. go-fuse
+	// uses such file descriptors when the mount point is provided
+	// in the form of /dev/fd/${fd}.
+	mountPoint := m.mountPath
+	fusermountPath := m.configuration.FusermountPath
+	deviceSocketPath := m.configuration.FuseDeviceSocketPath
+	mountMethods := 0
+	for _, enabled := range []bool{m.configuration.DirectMount, fusermountPath != "", deviceSocketPath != ""} {
+		if enabled {
+			mountMethods++
+		}
+	}
+	if mountMethods > 1 {
+		return status.Error(codes.InvalidArgument, "Direct mounting, mounting through fusermount and receiving the FUSE device over a socket are mutually exclusive")
+	}
+	if congestionThreshold := m.configuration.CongestionThreshold; congestionThreshold > 0 {
+		maxBackground := m.configuration.MaxBackground
+		if maxBackground == 0 {
+			maxBackground = defaultFUSEMaxBackground
+		}
+		if congestionThreshold > maxBackground {
+			return status.Errorf(codes.InvalidArgument, "Congestion threshold %d exceeds the maximum number of background requests %d", congestionThreshold, maxBackground)
+		}
+	}
+	if d := m.configuration.WritebackTimeout; d != nil {
+		if err := d.CheckValid(); err != nil {
+			return util.StatusWrap(err, "Failed to parse writeback timeout")
+		}
+		if d.AsDuration() <= 0 {
+			return status.Error(codes.InvalidArgument, "Writeback timeout must be positive")
+		}
+	}
+	if fusermountPath != "" {
+		mountOptions := []string{"fsname=" + m.fsName}
+		if m.configuration.AllowOther {
+			mountOptions = append(mountOptions, "allow_other")
+		}
+		fd, err := openFUSEDeviceUsingFusermount(fusermountPath, m.mountPath, mountOptions)
+		if err != nil {
+			return err
+		}
+		mountPoint = fmt.Sprintf("/dev/fd/%d", fd)
+	} else if deviceSocketPath != "" {
+		fd, err := openFUSEDeviceFromSocket(deviceSocketPath)
+		if err != nil {
+			return err
+		}
+		mountPoint = fmt.Sprintf("/dev/fd/%d", fd)
+	}
+
+	server, err := go_fuse.NewServer(rawFileSystem, mountPoint, m.newMountOptions())
 	if err != nil {
 		return util.StatusWrap(err, "Failed to create FUSE server")
 	}
+	if writebackTimeout := m.configuration.WritebackTimeout; writebackTimeout != nil {
+		m.handleAllocator.SetWritebackRequester(
+			func(inodeNumber uint64) bool {
+				// Invalidating the page cache of a file
+				// causes the kernel to write back any dirty
+				// pages first. ENOENT is returned if the
+				// kernel holds no state for the file.
+				s := server.InodeNotify(inodeNumber, 0, -1)
+				return s == go_fuse.OK || s == go_fuse.ENOENT
+			},
+			clock.SystemClock,
+			writebackTimeout.AsDuration())
+	}
+
 	// TODO: Run this as part of the program.Group, so that it gets
 	// cleaned up upon shutdown.
 	go server.Serve()
@@ -94,5 +248,33 @@ func (m *fuseMount) Expose(terminationGroup program.Group, rootDirectory virtual
 	); err != nil {
 		return util.StatusWrap(err, "Failed to set Linux Backing Device Info tunables")
 	}
+	if err := fuse.SetLinuxCongestionThreshold(
+		m.mountPath,
+		m.configuration.CongestionThreshold,
+	); err != nil {
+		return util.StatusWrap(err, "Failed to set congestion threshold")
+	}
 	return nil
 }
+
+// matchDirectIOPathPattern returns whether a path relative to the root
+// of the file system matches a pattern. Patterns are matched against
+// the full path, using the syntax of path.Match(). Patterns starting
+// with "**/" match at any depth.
+func matchDirectIOPathPattern(pattern, relativePath string) bool {
+	suffixPattern, anyDepth := strings.CutPrefix(pattern, "**/")
+	if !anyDepth {
+		matched, _ := pathpkg.Match(pattern, relativePath)
+		return matched
+	}
+	for {
+		if matched, _ := pathpkg.Match(suffixPattern, relativePath); matched {
+			return true
+		}
+		slash := strings.IndexByte(relativePath, '/')
+		if slash < 0 {
+			return false
+		}
+		relativePath = relativePath[slash+1:]
+	}
+}
@@ -85,6 +85,19 @@ func (bv macOSBuildVersion) greaterEqual(major int64, minor byte, daily int64) b
 	return bv.major > major || (bv.major == major && (bv.minor > minor || (bv.minor == minor && bv.daily >= daily)))
 }
 
+// checkNFSv4Availability checks whether the NFS client utilities are
+// installed, and whether Darwin specific configuration options are
+// provided.
+func checkNFSv4Availability(configuration *pb.NFSv4MountConfiguration) error {
+	if _, ok := configuration.OperatingSystem.(*pb.NFSv4MountConfiguration_Darwin); !ok {
+		return status.Error(codes.InvalidArgument, "Darwin specific NFSv4 server configuration options not provided")
+	}
+	if _, err := os.Stat("/sbin/mount_nfs"); err != nil {
+		return util.StatusWrap(err, "NFS client utilities are not installed")
+	}
+	return nil
+}
+
 func (m *nfsv4Mount) mount(terminationGroup program.Group, rpcServer *rpcserver.Server) error {
 	// Extract the version of macOS used. We need to know this, as
 	// it determines which mount options are supported.
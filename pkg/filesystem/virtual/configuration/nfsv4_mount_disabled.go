@@ -4,6 +4,7 @@
 package configuration
 
 import (
+	pb "github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/filesystem/virtual"
 	"github.com/buildbarn/bb-storage/pkg/program"
 	"github.com/buildbarn/go-xdr/pkg/rpcserver"
 
@@ -14,3 +15,7 @@ import (
 func (m *nfsv4Mount) mount(terminationGroup program.Group, rpcServer *rpcserver.Server) error {
 	return status.Error(codes.Unimplemented, "NFSv4 is not supported on this platform")
 }
+
+func checkNFSv4Availability(configuration *pb.NFSv4MountConfiguration) error {
+	return status.Error(codes.Unimplemented, "NFSv4 is not supported on this platform")
+}
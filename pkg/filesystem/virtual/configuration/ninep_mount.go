@@ -0,0 +1,88 @@
+package configuration
+
+import (
+	"context"
+	"log"
+	"net"
+	"os"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual/ninep"
+	pb "github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/program"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ninepMount struct {
+	configuration   *pb.NinePMountConfiguration
+	handleAllocator *virtual.FUSEStatefulHandleAllocator
+}
+
+func (m *ninepMount) Expose(terminationGroup program.Group, rootDirectory virtual.Directory, statFSProvider virtual.StatFSProvider) error {
+	maximumMessageSizeBytes := m.configuration.MaximumMessageSizeBytes
+	if maximumMessageSizeBytes < ninep.MinimumMessageSizeBytes {
+		return status.Errorf(codes.InvalidArgument, "Maximum message size must be at least %d bytes", ninep.MinimumMessageSizeBytes)
+	}
+	maximumConcurrentRequests := m.configuration.MaximumConcurrentRequests
+	if maximumConcurrentRequests == 0 {
+		return status.Error(codes.InvalidArgument, "Maximum number of concurrent requests must be positive")
+	}
+	server := ninep.NewServer(rootDirectory, maximumMessageSizeBytes, int(maximumConcurrentRequests), clock.SystemClock)
+
+	var listeners []net.Listener
+	for _, listenPath := range m.configuration.ListenPaths {
+		if err := os.Remove(listenPath); err != nil && !os.IsNotExist(err) {
+			closeListeners(listeners)
+			return util.StatusWrapf(err, "Could not remove stale socket for 9P server %#v", listenPath)
+		}
+		listener, err := net.Listen("unix", listenPath)
+		if err != nil {
+			closeListeners(listeners)
+			return util.StatusWrapf(err, "Failed to create listening socket for 9P server %#v", listenPath)
+		}
+		listeners = append(listeners, listener)
+	}
+	for _, listenAddress := range m.configuration.ListenAddresses {
+		listener, err := net.Listen("tcp", listenAddress)
+		if err != nil {
+			closeListeners(listeners)
+			return util.StatusWrapf(err, "Failed to create listening socket for 9P server %#v", listenAddress)
+		}
+		listeners = append(listeners, listener)
+	}
+	if len(listeners) == 0 {
+		return status.Error(codes.InvalidArgument, "No listen paths or addresses provided for 9P server")
+	}
+
+	for _, listener := range listeners {
+		listener := listener
+		terminationGroup.Go(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+			// Stop accepting connections upon shutdown.
+			go func() {
+				<-ctx.Done()
+				listener.Close()
+			}()
+
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					return util.StatusWrapf(err, "Failed to accept connection for 9P server %#v", listener.Addr().String())
+				}
+				go func() {
+					if err := server.HandleConnection(ctx, conn, conn); err != nil {
+						log.Print("Failure serving 9P connection: ", err)
+					}
+					conn.Close()
+				}()
+			}
+		})
+	}
+	return nil
+}
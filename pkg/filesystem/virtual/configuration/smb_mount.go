@@ -0,0 +1,100 @@
+package configuration
+
+import (
+	"context"
+	"encoding/hex"
+	"log"
+	"net"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual/smb"
+	pb "github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/program"
+	"github.com/buildbarn/bb-storage/pkg/random"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type smbMount struct {
+	configuration   *pb.SMBMountConfiguration
+	handleAllocator *virtual.FUSEStatefulHandleAllocator
+}
+
+func (m *smbMount) Expose(terminationGroup program.Group, rootDirectory virtual.Directory, statFSProvider virtual.StatFSProvider) error {
+	if m.configuration.ShareName == "" {
+		return status.Error(codes.InvalidArgument, "No share name provided for SMB server")
+	}
+	if len(m.configuration.UserNtHashes) == 0 && !m.configuration.AllowGuest {
+		return status.Error(codes.InvalidArgument, "No users provided for SMB server, and guest access is disabled")
+	}
+	if m.configuration.RequireEncryption && m.configuration.AllowGuest {
+		return status.Error(codes.InvalidArgument, "Guest access cannot be enabled for SMB servers that require encryption")
+	}
+	userNTHashes := make(map[string][]byte, len(m.configuration.UserNtHashes))
+	for userName, encodedNTHash := range m.configuration.UserNtHashes {
+		ntHash, err := hex.DecodeString(encodedNTHash)
+		if err != nil {
+			return util.StatusWrapfWithCode(err, codes.InvalidArgument, "Invalid NT hash for SMB user %#v", userName)
+		}
+		if len(ntHash) != 16 {
+			return status.Errorf(codes.InvalidArgument, "NT hash for SMB user %#v has length %d, while 16 bytes were expected", userName, len(ntHash))
+		}
+		userNTHashes[userName] = ntHash
+	}
+
+	// Server challenges used by NTLM authentication need to be
+	// unpredictable.
+	server := smb.NewServer(
+		rootDirectory,
+		m.configuration.ServerName,
+		m.configuration.ShareName,
+		userNTHashes,
+		m.configuration.AllowGuest,
+		m.configuration.RequireEncryption,
+		clock.SystemClock,
+		random.CryptoThreadSafeGenerator)
+
+	var listeners []net.Listener
+	for _, listenAddress := range m.configuration.ListenAddresses {
+		listener, err := net.Listen("tcp", listenAddress)
+		if err != nil {
+			closeListeners(listeners)
+			return util.StatusWrapf(err, "Failed to create listening socket for SMB server %#v", listenAddress)
+		}
+		listeners = append(listeners, listener)
+	}
+	if len(listeners) == 0 {
+		return status.Error(codes.InvalidArgument, "No listen addresses provided for SMB server")
+	}
+
+	for _, listener := range listeners {
+		listener := listener
+		terminationGroup.Go(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+			// Stop accepting connections upon shutdown.
+			go func() {
+				<-ctx.Done()
+				listener.Close()
+			}()
+
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					return util.StatusWrapf(err, "Failed to accept connection for SMB server %#v", listener.Addr().String())
+				}
+				go func() {
+					if err := server.HandleConnection(ctx, conn, conn); err != nil {
+						log.Print("Failure serving SMB connection: ", err)
+					}
+					conn.Close()
+				}()
+			}
+		})
+	}
+	return nil
+}
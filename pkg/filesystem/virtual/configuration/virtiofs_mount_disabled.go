@@ -0,0 +1,26 @@
+//go:build !linux
+// +build !linux
+
+package configuration
+
+import (
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	pb "github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/program"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type virtiofsMount struct {
+	socketPath string
+	fuseMount  fuseMount
+}
+
+func (m *virtiofsMount) Expose(terminationGroup program.Group, rootDirectory virtual.Directory, statFSProvider virtual.StatFSProvider) error {
+	return status.Error(codes.Unimplemented, "virtio-fs is not supported on this platform")
+}
+
+func checkVirtioFSAvailability(configuration *pb.VirtioFSMountConfiguration) error {
+	return status.Error(codes.Unimplemented, "virtio-fs is not supported on this platform")
+}
@@ -0,0 +1,130 @@
+//go:build linux
+// +build linux
+
+package configuration
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual/virtiofs"
+	pb "github.com/buildbarn/bb-remote-execution/pkg/proto/configuration/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/program"
+	"github.com/buildbarn/bb-storage/pkg/util"
+	go_fuse "github.com/hanwen/go-fuse/v2/fuse"
+
+	"golang.org/x/sys/unix"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type virtiofsMount struct {
+	socketPath string
+	fuseMount  fuseMount
+}
+
+func (m *virtiofsMount) Expose(terminationGroup program.Group, rootDirectory virtual.Directory, statFSProvider virtual.StatFSProvider) error {
+	// Validate the FUSE options before accepting any connections.
+	if _, err := m.fuseMount.newRawFileSystem(rootDirectory, func(virtual.FUSERemovalNotifier) {}, statFSProvider); err != nil {
+		return err
+	}
+
+	if m.socketPath == "" {
+		return status.Error(codes.InvalidArgument, "No vhost-user socket path provided")
+	}
+	if err := os.Remove(m.socketPath); err != nil && !os.IsNotExist(err) {
+		return util.StatusWrapf(err, "Could not remove stale vhost-user socket %#v", m.socketPath)
+	}
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: m.socketPath, Net: "unix"})
+	if err != nil {
+		return util.StatusWrapf(err, "Failed to create vhost-user socket %#v", m.socketPath)
+	}
+
+	terminationGroup.Go(func(ctx context.Context, siblingsGroup, dependenciesGroup program.Group) error {
+		// Stop accepting connections upon shutdown.
+		go func() {
+			<-ctx.Done()
+			listener.Close()
+		}()
+
+		for {
+			conn, err := listener.AcceptUnix()
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return util.StatusWrapf(err, "Failed to accept connection on vhost-user socket %#v", m.socketPath)
+			}
+			go func() {
+				if err := m.serveConnection(ctx, conn, rootDirectory, statFSProvider); err != nil {
+					log.Print("Failure serving virtio-fs connection: ", err)
+				}
+				conn.Close()
+			}()
+		}
+	})
+	return nil
+}
+
+// serveConnection serves the virtio-fs device to a single virtual
+// machine monitor. As every guest performs its own FUSE_INIT handshake
+// and keeps track of its own set of inodes, a separate FUSE server is
+// launched for every connection. Requests are passed to the FUSE
+// server through a socket pair that it treats as if it were /dev/fuse.
+func (m *virtiofsMount) serveConnection(ctx context.Context, conn *net.UnixConn, rootDirectory virtual.Directory, statFSProvider virtual.StatFSProvider) error {
+	// Guests cannot be notified of removed directory entries, as
+	// the notification queue of virtio-fs is not offered.
+	rawFileSystem, err := m.fuseMount.newRawFileSystem(rootDirectory, func(virtual.FUSERemovalNotifier) {}, statFSProvider)
+	if err != nil {
+		return err
+	}
+
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_SEQPACKET|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return util.StatusWrapWithCode(err, codes.Internal, "Failed to create socket pair")
+	}
+	deviceFile := os.NewFile(uintptr(fds[1]), "virtiofs-device")
+	deviceConn, err := net.FileConn(deviceFile)
+	deviceFile.Close()
+	if err != nil {
+		unix.Close(fds[0])
+		return util.StatusWrapWithCode(err, codes.Internal, "Failed to create connection from socket")
+	}
+	defer func() {
+		// go-fuse only stops processing requests after
+		// reading from the FUSE device fails. Replace its end
+		// of the socket pair by a file descriptor that cannot
+		// be read from, and close our end to wake up any
+		// blocked readers.
+		if devNull, err := unix.Open("/dev/null", unix.O_WRONLY|unix.O_CLOEXEC, 0); err == nil {
+			unix.Dup3(devNull, fds[0], unix.O_CLOEXEC)
+			unix.Close(devNull)
+		}
+		deviceConn.Close()
+	}()
+
+	// go-fuse blocks until the guest has sent FUSE_INIT. Launch it
+	// asynchronously, so that vhost-user messages can be processed
+	// in the meantime.
+	mountOptions := m.fuseMount.newMountOptions()
+	mountOptions.DirectMount = false
+	go func() {
+		server, err := go_fuse.NewServer(rawFileSystem, fmt.Sprintf("/dev/fd/%d", fds[0]), mountOptions)
+		if err != nil {
+			log.Print("Failed to create FUSE server for virtio-fs connection: ", err)
+			return
+		}
+		server.Serve()
+	}()
+
+	return virtiofs.Serve(ctx, conn, deviceConn.(*net.UnixConn))
+}
+
+func checkVirtioFSAvailability(configuration *pb.VirtioFSMountConfiguration) error {
+	return nil
+}
@@ -0,0 +1,360 @@
+package virtual
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/virtualfilesystemdebug"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// DebuggableLeaf is implemented by NativeLeaf objects that are capable
+// of exposing their internal state through the VirtualFileSystemDebug
+// service.
+type DebuggableLeaf interface {
+	// GetDebugState returns the internal state of the leaf, such as
+	// its reference count and cached digest.
+	GetDebugState() *virtualfilesystemdebug.LeafState
+	// DiscardCachedDigest discards the digest of the file's
+	// contents, if cached. This causes it to be recomputed the next
+	// time it is needed.
+	DiscardCachedDigest()
+}
+
+// FilePoolMigratableLeaf is implemented by NativeLeaf objects whose
+// contents are stored in a FilePool, and can be moved to another
+// FilePool while the file remains in use.
+type FilePoolMigratableLeaf interface {
+	// MigrateToFilePool copies the contents of the file into a
+	// file obtained from the provided FilePool, and releases the
+	// file that was used previously. The number of bytes copied is
+	// returned.
+	MigrateToFilePool(pool re_filesystem.FilePool) (uint64, error)
+}
+
+// leafDecorator is implemented by decorators of Leaf (e.g., the ones
+// created by handle allocators). It allows the debug server to access
+// the decorated leaf, as decorators hide the optional interfaces that
+// are implemented by the leaf.
+type leafDecorator interface {
+	unwrapLeaf() Leaf
+}
+
+// getUndecoratedLeaf strips all decorators from a leaf.
+func getUndecoratedLeaf(leaf Leaf) Leaf {
+	for {
+		switch decorator := leaf.(type) {
+		case leafDecorator:
+			leaf = decorator.unwrapLeaf()
+		default:
+			return leaf
+		}
+	}
+}
+
+// DebugServerFlushOptions contains the dependencies that are needed by
+// the VirtualFileSystemDebug service to convert mutable files to ones
+// backed by the Content Addressable Storage.
+type DebugServerFlushOptions struct {
+	ContentAddressableStorage blobstore.BlobAccess
+	CASFileFactory            CASFileFactory
+}
+
+type debugServer struct {
+	rootDirectory     PrepopulatedDirectory
+	migrationFilePool re_filesystem.FilePool
+	flushOptions      *DebugServerFlushOptions
+}
+
+// NewDebugServer creates a gRPC service that can be used to inspect and
+// manipulate the contents of a PrepopulatedDirectory at runtime. This
+// can be used to debug issues like stuck uploads and leaked references
+// of files, without needing to attach a debugger.
+//
+// Note that listing directories whose contents have not been
+// instantiated yet causes them to be loaded.
+//
+// If migrationFilePool is not nil, the contents of files may be moved
+// into it. This can be used to move files away from a disk that is
+// failing, without interrupting the actions that use them.
+//
+// If flushOptions is not nil, the contents of mutable files may be
+// uploaded to the Content Addressable Storage, after which the files
+// read their contents from the Content Addressable Storage. This can
+// be used to reclaim space in the file pool.
+func NewDebugServer(rootDirectory PrepopulatedDirectory, migrationFilePool re_filesystem.FilePool, flushOptions *DebugServerFlushOptions) virtualfilesystemdebug.VirtualFileSystemDebugServer {
+	return &debugServer{
+		rootDirectory:     rootDirectory,
+		migrationFilePool: migrationFilePool,
+		flushOptions:      flushOptions,
+	}
+}
+
+// convertLookupError converts errors returned by PrepopulatedDirectory
+// to gRPC errors that can be returned by the service.
+func convertLookupError(err error, p string) error {
+	if os.IsNotExist(err) {
+		return status.Errorf(codes.NotFound, "Path %#v does not exist", p)
+	}
+	return util.StatusWrapfWithCode(err, codes.Internal, "Failed to look up path %#v", p)
+}
+
+// parsePath converts a slash separated path to a list of pathname
+// components.
+func parsePath(p string) ([]path.Component, error) {
+	if p == "" {
+		return nil, nil
+	}
+	names := strings.Split(p, "/")
+	components := make([]path.Component, 0, len(names))
+	for _, name := range names {
+		component, ok := path.NewComponent(name)
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "Path %#v contains invalid pathname component %#v", p, name)
+		}
+		components = append(components, component)
+	}
+	return components, nil
+}
+
+// lookupChild resolves a path to a file or directory. The root
+// directory is returned if the path is empty.
+func (s *debugServer) lookupChild(p string) (PrepopulatedDirectoryChild, error) {
+	components, err := parsePath(p)
+	if err != nil {
+		return PrepopulatedDirectoryChild{}, err
+	}
+	return s.lookupComponents(p, components)
+}
+
+// lookupComponents resolves a list of pathname components to a file or
+// directory, relative to the root directory.
+func (s *debugServer) lookupComponents(p string, components []path.Component) (PrepopulatedDirectoryChild, error) {
+	child := PrepopulatedDirectoryChild{}.FromDirectory(s.rootDirectory)
+	for _, component := range components {
+		directory, _ := child.GetPair()
+		if directory == nil {
+			return PrepopulatedDirectoryChild{}, status.Errorf(codes.InvalidArgument, "Path %#v traverses through a file", p)
+		}
+		var err error
+		child, err = directory.LookupChild(component)
+		if err != nil {
+			return PrepopulatedDirectoryChild{}, convertLookupError(err, p)
+		}
+	}
+	return child, nil
+}
+
+// lookupDebuggableLeaf resolves a path to a file that is capable of
+// exposing its internal state.
+func (s *debugServer) lookupDebuggableLeaf(p string) (DebuggableLeaf, error) {
+	child, err := s.lookupChild(p)
+	if err != nil {
+		return nil, err
+	}
+	_, leaf := child.GetPair()
+	if leaf == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Path %#v refers to a directory", p)
+	}
+	debuggableLeaf, ok := getUndecoratedLeaf(leaf).(DebuggableLeaf)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "File at path %#v does not expose its internal state", p)
+	}
+	return debuggableLeaf, nil
+}
+
+func (s *debugServer) listDirectory(directory PrepopulatedDirectory, directoryPath string, remainingDepth uint32, response *virtualfilesystemdebug.ListDirectoryResponse) error {
+	directories, leaves, err := directory.LookupAllChildren()
+	if err != nil {
+		return util.StatusWrapfWithCode(err, codes.Internal, "Failed to list directory %#v", directoryPath)
+	}
+	for _, entry := range directories {
+		childPath := joinDebugPath(directoryPath, entry.Name)
+		response.Entries = append(response.Entries, &virtualfilesystemdebug.ListDirectoryResponse_Entry{
+			Path: childPath,
+			Type: &virtualfilesystemdebug.ListDirectoryResponse_Entry_Directory{
+				Directory: &emptypb.Empty{},
+			},
+		})
+		if remainingDepth > 0 {
+			if err := s.listDirectory(entry.Child, childPath, remainingDepth-1, response); err != nil {
+				return err
+			}
+		}
+	}
+	for _, entry := range leaves {
+		leafState := &virtualfilesystemdebug.LeafState{}
+		if debuggableLeaf, ok := getUndecoratedLeaf(entry.Child).(DebuggableLeaf); ok {
+			leafState = debuggableLeaf.GetDebugState()
+		}
+		response.Entries = append(response.Entries, &virtualfilesystemdebug.ListDirectoryResponse_Entry{
+			Path: joinDebugPath(directoryPath, entry.Name),
+			Type: &virtualfilesystemdebug.ListDirectoryResponse_Entry_Leaf{
+				Leaf: leafState,
+			},
+		})
+	}
+	return nil
+}
+
+func joinDebugPath(directoryPath string, name path.Component) string {
+	if directoryPath == "" {
+		return name.String()
+	}
+	return directoryPath + "/" + name.String()
+}
+
+func (s *debugServer) ListDirectory(ctx context.Context, request *virtualfilesystemdebug.ListDirectoryRequest) (*virtualfilesystemdebug.ListDirectoryResponse, error) {
+	child, err := s.lookupChild(request.Path)
+	if err != nil {
+		return nil, err
+	}
+	directory, _ := child.GetPair()
+	if directory == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Path %#v refers to a file", request.Path)
+	}
+	var response virtualfilesystemdebug.ListDirectoryResponse
+	if err := s.listDirectory(directory, request.Path, request.MaximumDepth, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (s *debugServer) GetLeafState(ctx context.Context, request *virtualfilesystemdebug.GetLeafStateRequest) (*virtualfilesystemdebug.LeafState, error) {
+	leaf, err := s.lookupDebuggableLeaf(request.Path)
+	if err != nil {
+		return nil, err
+	}
+	return leaf.GetDebugState(), nil
+}
+
+func (s *debugServer) RecomputeDigest(ctx context.Context, request *virtualfilesystemdebug.RecomputeDigestRequest) (*emptypb.Empty, error) {
+	leaf, err := s.lookupDebuggableLeaf(request.Path)
+	if err != nil {
+		return nil, err
+	}
+	leaf.DiscardCachedDigest()
+	return &emptypb.Empty{}, nil
+}
+
+func (s *debugServer) EvictNode(ctx context.Context, request *virtualfilesystemdebug.EvictNodeRequest) (*emptypb.Empty, error) {
+	components, err := parsePath(request.Path)
+	if err != nil {
+		return nil, err
+	}
+	if len(components) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "The root directory cannot be evicted")
+	}
+
+	// Look up the parent directory and remove the child from it.
+	parent, err := s.lookupComponents(request.Path, components[:len(components)-1])
+	if err != nil {
+		return nil, err
+	}
+	parentDirectory, _ := parent.GetPair()
+	if parentDirectory == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Path %#v traverses through a file", request.Path)
+	}
+	if err := parentDirectory.RemoveAll(components[len(components)-1]); err != nil {
+		return nil, convertLookupError(err, request.Path)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// migrateFilePool moves the contents of all files contained in a
+// directory subtree to the migration file pool. Files that are
+// reachable through multiple hard links are only migrated once.
+func (s *debugServer) migrateFilePool(child PrepopulatedDirectoryChild, childPath string, migratedLeaves map[FilePoolMigratableLeaf]struct{}, response *virtualfilesystemdebug.MigrateFilePoolResponse) error {
+	directory, leaf := child.GetPair()
+	if directory == nil {
+		migratableLeaf, ok := getUndecoratedLeaf(leaf).(FilePoolMigratableLeaf)
+		if !ok {
+			// Files that aren't backed by a FilePool (e.g.,
+			// files backed by the CAS) can be skipped.
+			return nil
+		}
+		if _, ok := migratedLeaves[migratableLeaf]; ok {
+			return nil
+		}
+		migratedLeaves[migratableLeaf] = struct{}{}
+		sizeBytes, err := migratableLeaf.MigrateToFilePool(s.migrationFilePool)
+		if err != nil {
+			return util.StatusWrapfWithCode(err, codes.Internal, "Failed to migrate file %#v", childPath)
+		}
+		response.MigratedFilesCount++
+		response.MigratedSizeBytes += sizeBytes
+		return nil
+	}
+
+	directories, leaves, err := directory.LookupAllChildren()
+	if err != nil {
+		return util.StatusWrapfWithCode(err, codes.Internal, "Failed to list directory %#v", childPath)
+	}
+	for _, entry := range directories {
+		if err := s.migrateFilePool(PrepopulatedDirectoryChild{}.FromDirectory(entry.Child), joinDebugPath(childPath, entry.Name), migratedLeaves, response); err != nil {
+			return err
+		}
+	}
+	for _, entry := range leaves {
+		if err := s.migrateFilePool(PrepopulatedDirectoryChild{}.FromLeaf(entry.Child), joinDebugPath(childPath, entry.Name), migratedLeaves, response); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *debugServer) MigrateFilePool(ctx context.Context, request *virtualfilesystemdebug.MigrateFilePoolRequest) (*virtualfilesystemdebug.MigrateFilePoolResponse, error) {
+	if s.migrationFilePool == nil {
+		return nil, status.Error(codes.FailedPrecondition, "No file pool to migrate files to has been configured")
+	}
+	child, err := s.lookupChild(request.Path)
+	if err != nil {
+		return nil, err
+	}
+	var response virtualfilesystemdebug.MigrateFilePoolResponse
+	if err := s.migrateFilePool(child, request.Path, map[FilePoolMigratableLeaf]struct{}{}, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (s *debugServer) FlushFiles(ctx context.Context, request *virtualfilesystemdebug.FlushFilesRequest) (*emptypb.Empty, error) {
+	if s.flushOptions == nil {
+		return nil, status.Error(codes.FailedPrecondition, "No Content Addressable Storage to flush files to has been configured")
+	}
+	instanceName, err := digest.NewInstanceName(request.InstanceName)
+	if err != nil {
+		return nil, util.StatusWrapf(err, "Invalid instance name %#v", request.InstanceName)
+	}
+	digestFunction, err := instanceName.GetDigestFunction(request.DigestFunction, 0)
+	if err != nil {
+		return nil, err
+	}
+	child, err := s.lookupChild(request.Path)
+	if err != nil {
+		return nil, err
+	}
+	directory, _ := child.GetPair()
+	if directory == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Path %#v refers to a file", request.Path)
+	}
+	if err := directory.FlushFiles(
+		ctx,
+		s.flushOptions.ContentAddressableStorage,
+		digestFunction,
+		s.flushOptions.CASFileFactory,
+		func(leaf NativeLeaf) bool { return true },
+	); err != nil {
+		return nil, util.StatusWrapf(err, "Failed to flush files in directory %#v", request.Path)
+	}
+	return &emptypb.Empty{}, nil
+}
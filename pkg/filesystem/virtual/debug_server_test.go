@@ -0,0 +1,223 @@
+package virtual_test
+
+import (
+	"context"
+	"syscall"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/virtualfilesystemdebug"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestDebugServer(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	rootDirectory := mock.NewMockPrepopulatedDirectory(ctrl)
+	migrationPool := mock.NewMockFilePool(ctrl)
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	casFileFactory := mock.NewMockCASFileFactory(ctrl)
+	debugServer := virtual.NewDebugServer(rootDirectory, migrationPool, &virtual.DebugServerFlushOptions{
+		ContentAddressableStorage: contentAddressableStorage,
+		CASFileFactory:            casFileFactory,
+	})
+
+	// Create a file that is opened for writing, so that it has a
+	// non-trivial reference count.
+	pool := mock.NewMockFilePool(ctrl)
+	underlyingFile := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile, nil)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	file, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
+		NewFile(true, 0, virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+	underlyingFile.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+	n, s := file.VirtualWrite([]byte("Hello"), 0)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 5, n)
+
+	fileState := &virtualfilesystemdebug.LeafState{
+		Type:                     "pool_backed_file",
+		ReferenceCount:           2,
+		WritableDescriptorsCount: 1,
+		SizeBytes:                5,
+		IsExecutable:             true,
+	}
+
+	t.Run("ListDirectory", func(t *testing.T) {
+		subdirectory := mock.NewMockPrepopulatedDirectory(ctrl)
+		rootDirectory.EXPECT().LookupAllChildren().Return(
+			[]virtual.DirectoryPrepopulatedDirEntry{
+				{Child: subdirectory, Name: path.MustNewComponent("dir")},
+			},
+			[]virtual.LeafPrepopulatedDirEntry{
+				{Child: file, Name: path.MustNewComponent("file")},
+			},
+			nil)
+		subdirectory.EXPECT().LookupAllChildren().Return(nil, nil, nil)
+
+		response, err := debugServer.ListDirectory(ctx, &virtualfilesystemdebug.ListDirectoryRequest{
+			MaximumDepth: 1,
+		})
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, &virtualfilesystemdebug.ListDirectoryResponse{
+			Entries: []*virtualfilesystemdebug.ListDirectoryResponse_Entry{
+				{
+					Path: "dir",
+					Type: &virtualfilesystemdebug.ListDirectoryResponse_Entry_Directory{
+						Directory: &emptypb.Empty{},
+					},
+				},
+				{
+					Path: "file",
+					Type: &virtualfilesystemdebug.ListDirectoryResponse_Entry_Leaf{
+						Leaf: fileState,
+					},
+				},
+			},
+		}, response)
+	})
+
+	t.Run("GetLeafStateNotFound", func(t *testing.T) {
+		rootDirectory.EXPECT().LookupChild(path.MustNewComponent("nonexistent")).
+			Return(virtual.PrepopulatedDirectoryChild{}, syscall.ENOENT)
+
+		_, err := debugServer.GetLeafState(ctx, &virtualfilesystemdebug.GetLeafStateRequest{
+			Path: "nonexistent",
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.NotFound, "Path \"nonexistent\" does not exist"), err)
+	})
+
+	t.Run("GetLeafStateInvalidPath", func(t *testing.T) {
+		_, err := debugServer.GetLeafState(ctx, &virtualfilesystemdebug.GetLeafStateRequest{
+			Path: "dir/../file",
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Path \"dir/../file\" contains invalid pathname component \"..\""), err)
+	})
+
+	t.Run("GetLeafStateSuccess", func(t *testing.T) {
+		rootDirectory.EXPECT().LookupChild(path.MustNewComponent("file")).
+			Return(virtual.PrepopulatedDirectoryChild{}.FromLeaf(file), nil)
+
+		leafState, err := debugServer.GetLeafState(ctx, &virtualfilesystemdebug.GetLeafStateRequest{
+			Path: "file",
+		})
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, fileState, leafState)
+	})
+
+	t.Run("RecomputeDigest", func(t *testing.T) {
+		rootDirectory.EXPECT().LookupChild(path.MustNewComponent("file")).
+			Return(virtual.PrepopulatedDirectoryChild{}.FromLeaf(file), nil)
+
+		_, err := debugServer.RecomputeDigest(ctx, &virtualfilesystemdebug.RecomputeDigestRequest{
+			Path: "file",
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("EvictNodeRoot", func(t *testing.T) {
+		_, err := debugServer.EvictNode(ctx, &virtualfilesystemdebug.EvictNodeRequest{})
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "The root directory cannot be evicted"), err)
+	})
+
+	t.Run("EvictNodeSuccess", func(t *testing.T) {
+		subdirectory := mock.NewMockPrepopulatedDirectory(ctrl)
+		rootDirectory.EXPECT().LookupChild(path.MustNewComponent("dir")).
+			Return(virtual.PrepopulatedDirectoryChild{}.FromDirectory(subdirectory), nil)
+		subdirectory.EXPECT().RemoveAll(path.MustNewComponent("file"))
+
+		_, err := debugServer.EvictNode(ctx, &virtualfilesystemdebug.EvictNodeRequest{
+			Path: "dir/file",
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("MigrateFilePoolNotConfigured", func(t *testing.T) {
+		_, err := virtual.NewDebugServer(rootDirectory, nil, nil).MigrateFilePool(ctx, &virtualfilesystemdebug.MigrateFilePoolRequest{})
+		testutil.RequireEqualStatus(t, status.Error(codes.FailedPrecondition, "No file pool to migrate files to has been configured"), err)
+	})
+
+	t.Run("MigrateFilePoolSuccess", func(t *testing.T) {
+		// The file is linked into the root directory twice. It
+		// should only be migrated once.
+		rootDirectory.EXPECT().LookupAllChildren().Return(
+			nil,
+			[]virtual.LeafPrepopulatedDirEntry{
+				{Child: file, Name: path.MustNewComponent("a")},
+				{Child: file, Name: path.MustNewComponent("b")},
+			},
+			nil)
+		newFile := mock.NewMockFileReadWriter(ctrl)
+		migrationPool.EXPECT().NewFile().Return(newFile, nil)
+		newFile.EXPECT().Truncate(int64(5))
+		underlyingFile.EXPECT().GetNextRegionOffset(int64(0), filesystem.Data).Return(int64(0), nil)
+		underlyingFile.EXPECT().GetNextRegionOffset(int64(0), filesystem.Hole).Return(int64(5), nil)
+		underlyingFile.EXPECT().ReadAt(gomock.Len(5), int64(0)).DoAndReturn(func(p []byte, off int64) (int, error) {
+			return copy(p, "Hello"), nil
+		})
+		newFile.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+		underlyingFile.EXPECT().Close()
+
+		response, err := debugServer.MigrateFilePool(ctx, &virtualfilesystemdebug.MigrateFilePoolRequest{})
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, &virtualfilesystemdebug.MigrateFilePoolResponse{
+			MigratedFilesCount: 1,
+			MigratedSizeBytes:  5,
+		}, response)
+
+		// Subsequent operations against the file should be
+		// forwarded to the new file.
+		newFile.EXPECT().ReadAt(gomock.Len(5), int64(0)).DoAndReturn(func(p []byte, off int64) (int, error) {
+			return copy(p, "Hello"), nil
+		})
+		var buf [5]byte
+		n, eof, s := file.VirtualRead(buf[:], 0)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, 5, n)
+		require.True(t, eof)
+		require.Equal(t, []byte("Hello"), buf[:])
+	})
+
+	t.Run("FlushFilesNotConfigured", func(t *testing.T) {
+		_, err := virtual.NewDebugServer(rootDirectory, nil, nil).FlushFiles(ctx, &virtualfilesystemdebug.FlushFilesRequest{})
+		testutil.RequireEqualStatus(t, status.Error(codes.FailedPrecondition, "No Content Addressable Storage to flush files to has been configured"), err)
+	})
+
+	t.Run("FlushFilesFile", func(t *testing.T) {
+		rootDirectory.EXPECT().LookupChild(path.MustNewComponent("file")).
+			Return(virtual.PrepopulatedDirectoryChild{}.FromLeaf(file), nil)
+
+		_, err := debugServer.FlushFiles(ctx, &virtualfilesystemdebug.FlushFilesRequest{
+			Path:           "file",
+			DigestFunction: remoteexecution.DigestFunction_SHA256,
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Path \"file\" refers to a file"), err)
+	})
+
+	t.Run("FlushFilesSuccess", func(t *testing.T) {
+		subdirectory := mock.NewMockPrepopulatedDirectory(ctrl)
+		rootDirectory.EXPECT().LookupChild(path.MustNewComponent("dir")).
+			Return(virtual.PrepopulatedDirectoryChild{}.FromDirectory(subdirectory), nil)
+		subdirectory.EXPECT().FlushFiles(ctx, contentAddressableStorage, digest.MustNewFunction("hello", remoteexecution.DigestFunction_SHA256), casFileFactory, gomock.Any())
+
+		_, err := debugServer.FlushFiles(ctx, &virtualfilesystemdebug.FlushFilesRequest{
+			Path:           "dir",
+			InstanceName:   "hello",
+			DigestFunction: remoteexecution.DigestFunction_SHA256,
+		})
+		require.NoError(t, err)
+	})
+}
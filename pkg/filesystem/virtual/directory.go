@@ -50,6 +50,19 @@ type Directory interface {
 	// Either one or both of createAttributes and existingOptions
 	// need to be provided.
 	VirtualOpenChild(ctx context.Context, name path.Component, shareAccess ShareMask, createAttributes *Attributes, existingOptions *OpenExistingOptions, requested AttributesMask, openedFileAttributes *Attributes) (Leaf, AttributesMask, ChangeInfo, Status)
+	// VirtualOpenTemporaryFile creates a regular file that is not
+	// linked into the directory, similar to open() with O_TMPFILE.
+	// The file may be linked into a directory by calling
+	// VirtualLink() while it is still opened.
+	//
+	// The returned file has a link count of one, which is owned by
+	// the caller instead of a directory. This prevents the file
+	// from becoming stale while unlinked. The caller must call
+	// Unlink() after closing the file to release it.
+	//
+	// As the file is not linked into the directory, the directory's
+	// change ID is left unmodified.
+	VirtualOpenTemporaryFile(ctx context.Context, shareAccess ShareMask, createAttributes *Attributes, requested AttributesMask, openedFileAttributes *Attributes) (Leaf, AttributesMask, Status)
 	// VirtualLink links an existing file into the directory.
 	VirtualLink(ctx context.Context, name path.Component, leaf Leaf, requested AttributesMask, attributes *Attributes) (ChangeInfo, Status)
 	// VirtualLookup obtains the inode corresponding with a child
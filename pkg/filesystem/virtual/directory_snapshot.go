@@ -0,0 +1,296 @@
+package virtual
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// snapshotVersionHolder is implemented by objects that retain older
+// versions of their state on behalf of snapshots.
+type snapshotVersionHolder interface {
+	// pruneSnapshotVersions releases all versions of the object's
+	// state that are no longer used by any snapshot.
+	pruneSnapshotVersions()
+}
+
+// snapshotEpochs keeps track of the snapshots that have been created
+// of directories in a subtree of an inMemoryPrepopulatedDirectory.
+//
+// Creating a snapshot merely increments the current epoch. Instead of
+// copying state eagerly, directories and files compare the epoch at
+// which they were last modified against the epochs of snapshots that
+// are still in use. If a snapshot was created in the meantime, the
+// existing state is retained before being modified.
+type snapshotEpochs struct {
+	current atomic.Uint64
+
+	lock    sync.Mutex
+	live    map[uint64]struct{}
+	holders map[snapshotVersionHolder]struct{}
+}
+
+func newSnapshotEpochs() *snapshotEpochs {
+	return &snapshotEpochs{
+		live:    map[uint64]struct{}{},
+		holders: map[snapshotVersionHolder]struct{}{},
+	}
+}
+
+// acquire a new epoch on behalf of a snapshot.
+func (se *snapshotEpochs) acquire() uint64 {
+	se.lock.Lock()
+	defer se.lock.Unlock()
+
+	epoch := se.current.Add(1)
+	se.live[epoch] = struct{}{}
+	return epoch
+}
+
+// release an epoch that was previously acquired by a snapshot. Any
+// state that was only retained on behalf of the snapshot is released.
+func (se *snapshotEpochs) release(epoch uint64) {
+	se.lock.Lock()
+	delete(se.live, epoch)
+	holders := se.holders
+	se.holders = map[snapshotVersionHolder]struct{}{}
+	se.lock.Unlock()
+
+	// Holders that still retain state for other snapshots register
+	// themselves once again.
+	for holder := range holders {
+		holder.pruneSnapshotVersions()
+	}
+}
+
+// isUsedLocked returns whether any snapshot is still in use whose
+// epoch lies in the range (first, last].
+func (se *snapshotEpochs) isUsedLocked(first, last uint64) bool {
+	for epoch := range se.live {
+		if first < epoch && epoch <= last {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshotVersion is a copy of the state of an object, which was
+// retained on behalf of snapshots having an epoch in the range
+// (firstEpoch, lastEpoch].
+type snapshotVersion[T any] struct {
+	firstEpoch uint64
+	lastEpoch  uint64
+	state      T
+}
+
+// snapshotVersions contains older versions of the state of an object
+// that are still in use by snapshots. The current state of the object
+// applies to all snapshots with an epoch above preservedEpoch.
+type snapshotVersions[T any] struct {
+	preservedEpoch uint64
+	versions       []snapshotVersion[T]
+}
+
+// preserve the current state of the object if a snapshot was created
+// since the object was last modified, and the snapshot is still in
+// use. This method needs to be called before modifying the object.
+func (sv *snapshotVersions[T]) preserve(epochs *snapshotEpochs, epoch uint64, holder snapshotVersionHolder, capture func() T) {
+	if epochs == nil || sv.preservedEpoch >= epoch {
+		return
+	}
+
+	epochs.lock.Lock()
+	used := epochs.isUsedLocked(sv.preservedEpoch, epoch)
+	if used {
+		epochs.holders[holder] = struct{}{}
+	}
+	epochs.lock.Unlock()
+
+	if used {
+		sv.versions = append(sv.versions, snapshotVersion[T]{
+			firstEpoch: sv.preservedEpoch,
+			lastEpoch:  epoch,
+			state:      capture(),
+		})
+	}
+	sv.preservedEpoch = epoch
+}
+
+// get the version of the state of the object that applies to a
+// snapshot. If no version is returned, the current state of the object
+// applies.
+func (sv *snapshotVersions[T]) get(epoch uint64) (T, bool) {
+	for _, version := range sv.versions {
+		if version.firstEpoch < epoch && epoch <= version.lastEpoch {
+			return version.state, true
+		}
+	}
+	var noState T
+	return noState, false
+}
+
+// prune versions of the state of the object that are no longer used by
+// any snapshot. The pruned versions are returned, so that they can be
+// released by the caller.
+func (sv *snapshotVersions[T]) prune(epochs *snapshotEpochs, holder snapshotVersionHolder) []T {
+	epochs.lock.Lock()
+	defer epochs.lock.Unlock()
+
+	var pruned []T
+	kept := sv.versions[:0]
+	for _, version := range sv.versions {
+		if epochs.isUsedLocked(version.firstEpoch, version.lastEpoch) {
+			kept = append(kept, version)
+		} else {
+			pruned = append(pruned, version.state)
+		}
+	}
+	for i := len(kept); i < len(sv.versions); i++ {
+		sv.versions[i] = snapshotVersion[T]{}
+	}
+	sv.versions = kept
+	if len(kept) > 0 {
+		epochs.holders[holder] = struct{}{}
+	}
+	return pruned
+}
+
+// snapshotVersionedLeaf is implemented by NativeLeaf types whose
+// contents may still change after they have been created. Leaves that
+// don't implement this interface are assumed to be immutable.
+type snapshotVersionedLeaf interface {
+	// setSnapshotEpochs is called when the leaf is placed in a
+	// directory, so that the leaf knows which snapshots may refer
+	// to it.
+	setSnapshotEpochs(epochs *snapshotEpochs)
+	// getSnapshotVersion returns a new reference to a leaf that
+	// contains the state of the current leaf at the time a
+	// snapshot was created. The returned leaf is not affected by
+	// changes made to the current leaf afterwards.
+	getSnapshotVersion(epochs *snapshotEpochs, epoch uint64) (NativeLeaf, error)
+}
+
+// DirectorySnapshot is an immutable copy of a directory hierarchy,
+// created by PrepopulatedDirectory.CreateSnapshot(). Changes made to
+// the original directory hierarchy after the snapshot is created are
+// not reflected by the snapshot.
+//
+// Resources that are retained on behalf of the snapshot are released
+// by calling Release().
+type DirectorySnapshot struct {
+	directory *inMemoryPrepopulatedDirectory
+	epochs    *snapshotEpochs
+	epoch     uint64
+}
+
+// Release all resources that are retained on behalf of the snapshot.
+// The snapshot may no longer be used after calling this method.
+func (s *DirectorySnapshot) Release() {
+	s.epochs.release(s.epoch)
+}
+
+// Upload the contents of the snapshot to the Content Addressable
+// Storage, returning the digest of the REv2 Directory message of the
+// root directory. As the snapshot is immutable, the resulting digest
+// may be used to refer to the snapshot's contents.
+//
+// Files are uploaded to the Content Addressable Storage directly.
+// Digests of files that were already computed while they were written
+// are reused. Directory messages are not uploaded. Instead, they are
+// provided to directoryProcessor after all of their children have been
+// processed, so that callers may combine them into a Tree.
+func (s *DirectorySnapshot) Upload(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function, directoryProcessor func(directoryDigest digest.Digest, data []byte)) (digest.Digest, error) {
+	return s.uploadDirectory(ctx, s.directory, contentAddressableStorage, digestFunction, directoryProcessor)
+}
+
+func (s *DirectorySnapshot) uploadDirectory(ctx context.Context, d *inMemoryPrepopulatedDirectory, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function, directoryProcessor func(directoryDigest digest.Digest, data []byte)) (digest.Digest, error) {
+	version, err := d.getSnapshotVersion(s.epochs, s.epoch)
+	if err != nil {
+		return digest.BadDigest, err
+	}
+	defer version.release()
+
+	var directory remoteexecution.Directory
+	sort.Slice(version.directories, func(i, j int) bool {
+		return version.directories[i].name.String() < version.directories[j].name.String()
+	})
+	for _, entry := range version.directories {
+		childDigest, err := s.uploadDirectory(ctx, entry.child, contentAddressableStorage, digestFunction, directoryProcessor)
+		if err != nil {
+			return digest.BadDigest, util.StatusWrapf(err, "Directory %#v", entry.name.String())
+		}
+		directory.Directories = append(directory.Directories, &remoteexecution.DirectoryNode{
+			Name:   entry.name.String(),
+			Digest: childDigest.GetProto(),
+		})
+	}
+
+	sort.Sort(leafPrepopulatedDirEntryList(version.leaves))
+	for _, entry := range version.leaves {
+		if err := s.appendLeaf(ctx, &directory, entry, contentAddressableStorage, digestFunction); err != nil {
+			return digest.BadDigest, err
+		}
+	}
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(&directory)
+	if err != nil {
+		return digest.BadDigest, util.StatusWrap(err, "Failed to marshal directory")
+	}
+	digestGenerator := digestFunction.NewGenerator(int64(len(data)))
+	if _, err := digestGenerator.Write(data); err != nil {
+		panic(err)
+	}
+	directoryDigest := digestGenerator.Sum()
+	directoryProcessor(directoryDigest, data)
+	return directoryDigest, nil
+}
+
+func (s *DirectorySnapshot) appendLeaf(ctx context.Context, directory *remoteexecution.Directory, entry LeafPrepopulatedDirEntry, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function) error {
+	name := entry.Name.String()
+	leaf := entry.Child
+	if versionedLeaf, ok := getUndecoratedLeaf(leaf).(snapshotVersionedLeaf); ok {
+		leafVersion, err := versionedLeaf.getSnapshotVersion(s.epochs, s.epoch)
+		if err != nil {
+			return util.StatusWrapf(err, "Failed to obtain contents of file %#v", name)
+		}
+		defer leafVersion.Unlink()
+		leaf = leafVersion
+	}
+
+	fileInfo := GetFileInfo(entry.Name, leaf)
+	switch fileInfo.Type() {
+	case filesystem.FileTypeRegularFile:
+		fileDigest, err := leaf.UploadFile(ctx, contentAddressableStorage, digestFunction)
+		if err != nil {
+			return util.StatusWrapf(err, "Failed to upload file %#v", name)
+		}
+		directory.Files = append(directory.Files, &remoteexecution.FileNode{
+			Name:         name,
+			Digest:       fileDigest.GetProto(),
+			IsExecutable: fileInfo.IsExecutable(),
+		})
+	case filesystem.FileTypeSymlink:
+		target, err := leaf.Readlink()
+		if err != nil {
+			return util.StatusWrapf(err, "Failed to read target of symbolic link %#v", name)
+		}
+		directory.Symlinks = append(directory.Symlinks, &remoteexecution.SymlinkNode{
+			Name:   name,
+			Target: target,
+		})
+	default:
+		return status.Errorf(codes.InvalidArgument, "File %#v has an unsupported file type", name)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package virtual
+
+import (
+	"sort"
+)
+
+// XAttrSetMode specifies whether VirtualSetXAttr() may create new
+// extended attributes, replace existing ones, or both. It corresponds
+// to the XATTR_CREATE and XATTR_REPLACE flags of setxattr().
+type XAttrSetMode int
+
+const (
+	// XAttrSetModeCreateOrReplace permits both creating a new
+	// extended attribute and replacing an existing one.
+	XAttrSetModeCreateOrReplace XAttrSetMode = iota
+	// XAttrSetModeCreate only permits creating a new extended
+	// attribute. StatusErrExist is returned if the extended
+	// attribute already exists.
+	XAttrSetModeCreate
+	// XAttrSetModeReplace only permits replacing an existing
+	// extended attribute. StatusErrNoXAttr is returned if the
+	// extended attribute does not exist.
+	XAttrSetModeReplace
+)
+
+// extendedAttributes holds the extended attributes of a file or
+// directory that is writable. Callers are responsible for providing
+// synchronization.
+type extendedAttributes struct {
+	values map[string][]byte
+}
+
+func (ea *extendedAttributes) get(name string) ([]byte, Status) {
+	if value, ok := ea.values[name]; ok {
+		return value, StatusOK
+	}
+	return nil, StatusErrNoXAttr
+}
+
+func (ea *extendedAttributes) list() []string {
+	names := make([]string, 0, len(ea.values))
+	for name := range ea.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (ea *extendedAttributes) remove(name string) Status {
+	if _, ok := ea.values[name]; !ok {
+		return StatusErrNoXAttr
+	}
+	delete(ea.values, name)
+	return StatusOK
+}
+
+func (ea *extendedAttributes) set(name string, value []byte, mode XAttrSetMode) Status {
+	if name == "" {
+		return StatusErrInval
+	}
+	_, exists := ea.values[name]
+	switch mode {
+	case XAttrSetModeCreate:
+		if exists {
+			return StatusErrExist
+		}
+	case XAttrSetModeReplace:
+		if !exists {
+			return StatusErrNoXAttr
+		}
+	}
+
+	// Make a copy of the value, as the caller may reuse the buffer.
+	// Values are never modified in place, meaning that get() may
+	// return them without copying.
+	if ea.values == nil {
+		ea.values = map[string][]byte{}
+	}
+	ea.values[name] = append([]byte{}, value...)
+	return StatusOK
+}
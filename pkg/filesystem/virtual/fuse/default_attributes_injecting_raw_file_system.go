@@ -86,6 +86,11 @@ func (rfs *defaultAttributesInjectingRawFileSystem) Create(cancel <-chan struct{
 	return rfs.RawFileSystem.Create(cancel, input, name, out)
 }
 
+func (rfs *defaultAttributesInjectingRawFileSystem) TmpFile(cancel <-chan struct{}, input *fuse.CreateIn, out *fuse.CreateOut) fuse.Status {
+	out.EntryOut = rfs.entryOut
+	return rfs.RawFileSystem.TmpFile(cancel, input, out)
+}
+
 func (rfs *defaultAttributesInjectingRawFileSystem) ReadDirPlus(cancel <-chan struct{}, input *fuse.ReadIn, out fuse.ReadDirPlusEntryList) fuse.Status {
 	return rfs.RawFileSystem.ReadDirPlus(cancel, input, &defaultAttributesInjectingReadDirPlusEntryList{
 		ReadDirPlusEntryList: out,
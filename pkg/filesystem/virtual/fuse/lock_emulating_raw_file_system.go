@@ -0,0 +1,225 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package fuse
+
+import (
+	"math"
+	"sync"
+	"syscall"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// releaseFlockUnlock corresponds to FUSE_RELEASE_FLOCK_UNLOCK. It is
+// set in ReleaseIn.ReleaseFlags if BSD-style locks held through the
+// file handle need to be released.
+const releaseFlockUnlock = 1 << 1
+
+// lkFlock corresponds to FUSE_LK_FLOCK. It is set in LkIn.LkFlags if
+// the lock request originates from flock() as opposed to fcntl().
+const lkFlock = 1 << 0
+
+// offsetMax corresponds to the kernel's OFFSET_MAX. It is used as the
+// end offset of locks that extend to the end of the file.
+const offsetMax = math.MaxInt64
+
+// nodeLocksKey is the key of the map in which lockEmulatingRawFileSystem
+// stores locks. POSIX and BSD-style locks are tracked separately, as
+// they do not interact with each other on Linux.
+type nodeLocksKey struct {
+	nodeID uint64
+	flock  bool
+}
+
+func newNodeLocksKey(input *fuse.LkIn) nodeLocksKey {
+	return nodeLocksKey{
+		nodeID: input.NodeId,
+		flock:  input.LkFlags&lkFlock != 0,
+	}
+}
+
+// nodeLocks contains the locks that are held against a single node.
+type nodeLocks struct {
+	locks      virtual.ByteRangeLockSet[uint64]
+	locksCount int
+	// Channel that is closed every time locks are released, so
+	// that blocking acquisitions can retry.
+	wakeup chan struct{}
+}
+
+type lockEmulatingRawFileSystem struct {
+	fuse.RawFileSystem
+
+	lock  sync.Mutex
+	nodes map[nodeLocksKey]*nodeLocks
+}
+
+// NewLockEmulatingRawFileSystem creates a decorator for RawFileSystem
+// that processes POSIX (fcntl()) and BSD-style (flock()) file locks
+// within this process, as opposed to forwarding them to the underlying
+// implementation. This gives locks consistent semantics across all
+// actions that access the same files through a single FUSE mount,
+// regardless of which worker thread executes them. This is required
+// for build tools that coordinate access to shared caches by locking
+// files, such as the Go build cache.
+//
+// The kernel only forwards locking operations if the FUSE mount is
+// created with locking enabled.
+func NewLockEmulatingRawFileSystem(base fuse.RawFileSystem) fuse.RawFileSystem {
+	return &lockEmulatingRawFileSystem{
+		RawFileSystem: base,
+		nodes:         map[nodeLocksKey]*nodeLocks{},
+	}
+}
+
+// toByteRangeLock converts a lock request provided by the kernel to
+// the representation used by ByteRangeLockSet.
+func toByteRangeLock(input *fuse.LkIn) (virtual.ByteRangeLock[uint64], fuse.Status) {
+	l := virtual.ByteRangeLock[uint64]{
+		Start: input.Lk.Start,
+		End:   input.Lk.End + 1,
+		Owner: input.Owner,
+	}
+	if input.Lk.End >= offsetMax {
+		l.End = math.MaxUint64
+	}
+	if l.End <= l.Start {
+		return l, fuse.EINVAL
+	}
+	switch input.Lk.Typ {
+	case syscall.F_UNLCK:
+		l.Type = virtual.ByteRangeLockTypeUnlocked
+	case syscall.F_WRLCK:
+		l.Type = virtual.ByteRangeLockTypeLockedExclusive
+	case syscall.F_RDLCK:
+		l.Type = virtual.ByteRangeLockTypeLockedShared
+	default:
+		return l, fuse.EINVAL
+	}
+	return l, fuse.OK
+}
+
+// setLocked applies a lock against a node, and releases the state
+// associated with the node if it no longer has any locks.
+func (rfs *lockEmulatingRawFileSystem) setLocked(key nodeLocksKey, l *virtual.ByteRangeLock[uint64]) {
+	n, ok := rfs.nodes[key]
+	if !ok {
+		if l.Type == virtual.ByteRangeLockTypeUnlocked {
+			return
+		}
+		n = &nodeLocks{
+			wakeup: make(chan struct{}),
+		}
+		n.locks.Initialize()
+		rfs.nodes[key] = n
+	}
+
+	n.locksCount += n.locks.Set(l)
+	if l.Type != virtual.ByteRangeLockTypeLockedExclusive {
+		// Unlocking or downgrading may permit other owners to
+		// acquire locks.
+		close(n.wakeup)
+		n.wakeup = make(chan struct{})
+	}
+	if n.locksCount == 0 {
+		delete(rfs.nodes, key)
+	}
+}
+
+// releaseOwner releases all locks held by an owner against a node.
+func (rfs *lockEmulatingRawFileSystem) releaseOwner(key nodeLocksKey, owner uint64) {
+	rfs.lock.Lock()
+	defer rfs.lock.Unlock()
+
+	rfs.setLocked(key, &virtual.ByteRangeLock[uint64]{
+		Start: 0,
+		End:   math.MaxUint64,
+		Owner: owner,
+		Type:  virtual.ByteRangeLockTypeUnlocked,
+	})
+}
+
+func (rfs *lockEmulatingRawFileSystem) GetLk(cancel <-chan struct{}, input *fuse.LkIn, out *fuse.LkOut) fuse.Status {
+	l, s := toByteRangeLock(input)
+	if s != fuse.OK {
+		return s
+	}
+
+	rfs.lock.Lock()
+	defer rfs.lock.Unlock()
+
+	if n, ok := rfs.nodes[newNodeLocksKey(input)]; ok {
+		if conflict := n.locks.Test(&l); conflict != nil {
+			out.Lk.Start = conflict.Start
+			out.Lk.End = offsetMax
+			if conflict.End != math.MaxUint64 {
+				out.Lk.End = conflict.End - 1
+			}
+			if conflict.Type == virtual.ByteRangeLockTypeLockedExclusive {
+				out.Lk.Typ = syscall.F_WRLCK
+			} else {
+				out.Lk.Typ = syscall.F_RDLCK
+			}
+			return fuse.OK
+		}
+	}
+	out.Lk.Typ = syscall.F_UNLCK
+	return fuse.OK
+}
+
+func (rfs *lockEmulatingRawFileSystem) setLk(cancel <-chan struct{}, input *fuse.LkIn, wait bool) fuse.Status {
+	l, s := toByteRangeLock(input)
+	if s != fuse.OK {
+		return s
+	}
+
+	key := newNodeLocksKey(input)
+	rfs.lock.Lock()
+	for {
+		n, ok := rfs.nodes[key]
+		if !ok || l.Type == virtual.ByteRangeLockTypeUnlocked || n.locks.Test(&l) == nil {
+			rfs.setLocked(key, &l)
+			rfs.lock.Unlock()
+			return fuse.OK
+		}
+		if !wait {
+			rfs.lock.Unlock()
+			return fuse.EAGAIN
+		}
+
+		// Wait for conflicting locks to be released.
+		wakeup := n.wakeup
+		rfs.lock.Unlock()
+		select {
+		case <-wakeup:
+		case <-cancel:
+			return fuse.EINTR
+		}
+		rfs.lock.Lock()
+	}
+}
+
+func (rfs *lockEmulatingRawFileSystem) SetLk(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
+	return rfs.setLk(cancel, input, false)
+}
+
+func (rfs *lockEmulatingRawFileSystem) SetLkw(cancel <-chan struct{}, input *fuse.LkIn) fuse.Status {
+	return rfs.setLk(cancel, input, true)
+}
+
+func (rfs *lockEmulatingRawFileSystem) Flush(cancel <-chan struct{}, input *fuse.FlushIn) fuse.Status {
+	// POSIX requires that all locks held by a process against a
+	// file are released when any of its descriptors of the file is
+	// closed. This does not apply to BSD-style locks.
+	rfs.releaseOwner(nodeLocksKey{nodeID: input.NodeId}, input.LockOwner)
+	return rfs.RawFileSystem.Flush(cancel, input)
+}
+
+func (rfs *lockEmulatingRawFileSystem) Release(cancel <-chan struct{}, input *fuse.ReleaseIn) {
+	if input.ReleaseFlags&releaseFlockUnlock != 0 {
+		rfs.releaseOwner(nodeLocksKey{nodeID: input.NodeId, flock: true}, input.LockOwner)
+	}
+	rfs.RawFileSystem.Release(cancel, input)
+}
@@ -0,0 +1,112 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package fuse_test
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual/fuse"
+	"github.com/golang/mock/gomock"
+	go_fuse "github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockEmulatingRawFileSystem(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	base := mock.NewMockRawFileSystem(ctrl)
+	rfs := fuse.NewLockEmulatingRawFileSystem(base)
+
+	newLkIn := func(owner, start, end uint64, typ uint32) *go_fuse.LkIn {
+		return &go_fuse.LkIn{
+			InHeader: go_fuse.InHeader{NodeId: 5},
+			Owner:    owner,
+			Lk: go_fuse.FileLock{
+				Start: start,
+				End:   end,
+				Typ:   typ,
+			},
+		}
+	}
+
+	t.Run("InvalidRange", func(t *testing.T) {
+		require.Equal(t, go_fuse.EINVAL, rfs.SetLk(nil, newLkIn(1, 10, 5, syscall.F_WRLCK)))
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		// Owner 1 acquires an exclusive lock on bytes [0, 100).
+		require.Equal(t, go_fuse.OK, rfs.SetLk(nil, newLkIn(1, 0, 99, syscall.F_WRLCK)))
+
+		// Owner 2 should not be able to acquire an overlapping
+		// lock. GetLk() should report the conflicting lock.
+		require.Equal(t, go_fuse.EAGAIN, rfs.SetLk(nil, newLkIn(2, 50, 1<<63-1, syscall.F_RDLCK)))
+		var lkOut go_fuse.LkOut
+		require.Equal(t, go_fuse.OK, rfs.GetLk(nil, newLkIn(2, 50, 1<<63-1, syscall.F_RDLCK), &lkOut))
+		require.Equal(t, go_fuse.FileLock{Start: 0, End: 99, Typ: syscall.F_WRLCK}, lkOut.Lk)
+
+		// Non-overlapping ranges may be locked.
+		require.Equal(t, go_fuse.OK, rfs.SetLk(nil, newLkIn(2, 100, 1<<63-1, syscall.F_WRLCK)))
+
+		// Closing a descriptor should release all locks held
+		// by the owner, permitting others to acquire them.
+		base.EXPECT().Flush(nil, &go_fuse.FlushIn{InHeader: go_fuse.InHeader{NodeId: 5}, LockOwner: 1})
+		require.Equal(t, go_fuse.OK, rfs.Flush(nil, &go_fuse.FlushIn{InHeader: go_fuse.InHeader{NodeId: 5}, LockOwner: 1}))
+		require.Equal(t, go_fuse.OK, rfs.GetLk(nil, newLkIn(3, 0, 99, syscall.F_WRLCK), &lkOut))
+		require.Equal(t, uint32(syscall.F_UNLCK), lkOut.Lk.Typ)
+
+		require.Equal(t, go_fuse.OK, rfs.SetLk(nil, newLkIn(2, 100, 1<<63-1, syscall.F_UNLCK)))
+	})
+
+	t.Run("Wait", func(t *testing.T) {
+		require.Equal(t, go_fuse.OK, rfs.SetLk(nil, newLkIn(1, 0, 0, syscall.F_WRLCK)))
+
+		// Blocking acquisition should complete as soon as the
+		// conflicting lock is released.
+		done := make(chan go_fuse.Status)
+		go func() {
+			done <- rfs.SetLkw(nil, newLkIn(2, 0, 0, syscall.F_WRLCK))
+		}()
+		require.Equal(t, go_fuse.OK, rfs.SetLk(nil, newLkIn(1, 0, 0, syscall.F_UNLCK)))
+		require.Equal(t, go_fuse.OK, <-done)
+
+		// Blocking acquisition should be interruptible.
+		cancel := make(chan struct{})
+		go func() {
+			done <- rfs.SetLkw(cancel, newLkIn(3, 0, 0, syscall.F_RDLCK))
+		}()
+		close(cancel)
+		require.Equal(t, go_fuse.EINTR, <-done)
+
+		require.Equal(t, go_fuse.OK, rfs.SetLk(nil, newLkIn(2, 0, 0, syscall.F_UNLCK)))
+	})
+
+	t.Run("Flock", func(t *testing.T) {
+		newFlockLkIn := func(owner uint64, typ uint32) *go_fuse.LkIn {
+			lkIn := newLkIn(owner, 0, 1<<63-1, typ)
+			lkIn.LkFlags = 1
+			return lkIn
+		}
+
+		// BSD-style locks should not conflict with POSIX locks.
+		require.Equal(t, go_fuse.OK, rfs.SetLk(nil, newLkIn(1, 0, 1<<63-1, syscall.F_WRLCK)))
+		require.Equal(t, go_fuse.OK, rfs.SetLk(nil, newFlockLkIn(2, syscall.F_WRLCK)))
+		require.Equal(t, go_fuse.EAGAIN, rfs.SetLk(nil, newFlockLkIn(3, syscall.F_RDLCK)))
+
+		// BSD-style locks should not be released when a
+		// descriptor is closed, only when the file handle
+		// through which they were acquired is released.
+		base.EXPECT().Flush(nil, &go_fuse.FlushIn{InHeader: go_fuse.InHeader{NodeId: 5}, LockOwner: 2})
+		require.Equal(t, go_fuse.OK, rfs.Flush(nil, &go_fuse.FlushIn{InHeader: go_fuse.InHeader{NodeId: 5}, LockOwner: 2}))
+		require.Equal(t, go_fuse.EAGAIN, rfs.SetLk(nil, newFlockLkIn(3, syscall.F_RDLCK)))
+
+		base.EXPECT().Release(nil, &go_fuse.ReleaseIn{InHeader: go_fuse.InHeader{NodeId: 5}, ReleaseFlags: 2, LockOwner: 2})
+		rfs.Release(nil, &go_fuse.ReleaseIn{InHeader: go_fuse.InHeader{NodeId: 5}, ReleaseFlags: 2, LockOwner: 2})
+		require.Equal(t, go_fuse.OK, rfs.SetLk(nil, newFlockLkIn(3, syscall.F_RDLCK)))
+
+		require.Equal(t, go_fuse.OK, rfs.SetLk(nil, newFlockLkIn(3, syscall.F_UNLCK)))
+		require.Equal(t, go_fuse.OK, rfs.SetLk(nil, newLkIn(1, 0, 1<<63-1, syscall.F_UNLCK)))
+	})
+}
@@ -123,6 +123,7 @@ var (
 	operationHistogramSetXAttr      = newOperationHistogramWithStatus("SetXAttr")
 	operationHistogramRemoveXAttr   = newOperationHistogramWithStatus("RemoveXAttr")
 	operationHistogramCreate        = newOperationHistogramWithStatus("Create")
+	operationHistogramTmpFile       = newOperationHistogramWithStatus("TmpFile")
 	operationHistogramOpen          = newOperationHistogramWithStatus("Open")
 	operationHistogramRead          = newOperationHistogramWithStatus("Read")
 	operationHistogramLseek         = newOperationHistogramWithStatus("Lseek")
@@ -135,6 +136,7 @@ var (
 	operationHistogramFlush         = newOperationHistogramWithStatus("Flush")
 	operationHistogramFsync         = newOperationHistogramWithStatus("Fsync")
 	operationHistogramFallocate     = newOperationHistogramWithStatus("Fallocate")
+	operationHistogramIoctl         = newOperationHistogramWithStatus("Ioctl")
 	operationHistogramOpenDir       = newOperationHistogramWithStatus("OpenDir")
 	operationHistogramReadDir       = newOperationHistogramWithStatus("ReadDir")
 	operationHistogramReadDirPlus   = newOperationHistogramWithStatus("ReadDirPlus")
@@ -301,6 +303,13 @@ func (rfs *metricsRawFileSystem) Create(cancel <-chan struct{}, input *fuse.Crea
 	return s
 }
 
+func (rfs *metricsRawFileSystem) TmpFile(cancel <-chan struct{}, input *fuse.CreateIn, out *fuse.CreateOut) fuse.Status {
+	timeStart := rfs.clock.Now()
+	s := rfs.base.TmpFile(cancel, input, out)
+	operationHistogramTmpFile.observe(s, timeStart, rfs.clock.Now())
+	return s
+}
+
 func (rfs *metricsRawFileSystem) Open(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
 	timeStart := rfs.clock.Now()
 	s := rfs.base.Open(cancel, input, out)
@@ -384,6 +393,13 @@ func (rfs *metricsRawFileSystem) Fallocate(cancel <-chan struct{}, input *fuse.F
 	return s
 }
 
+func (rfs *metricsRawFileSystem) Ioctl(cancel <-chan struct{}, input *fuse.IoctlIn, inbuf []byte, output *fuse.IoctlOut, bufOut []byte) fuse.Status {
+	timeStart := rfs.clock.Now()
+	s := rfs.base.Ioctl(cancel, input, inbuf, output, bufOut)
+	operationHistogramIoctl.observe(s, timeStart, rfs.clock.Now())
+	return s
+}
+
 func (rfs *metricsRawFileSystem) OpenDir(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOut) fuse.Status {
 	timeStart := rfs.clock.Now()
 	s := rfs.base.OpenDir(cancel, input, out)
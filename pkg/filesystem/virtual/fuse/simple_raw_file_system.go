@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
 	"sync"
 	"syscall"
 	"time"
@@ -26,7 +27,10 @@ const (
 	AttributesMaskForFUSEAttr = virtual.AttributesMaskDeviceNumber |
 		virtual.AttributesMaskFileType |
 		virtual.AttributesMaskInodeNumber |
+		virtual.AttributesMaskIsImmutable |
+		virtual.AttributesMaskLastDataAccessTime |
 		virtual.AttributesMaskLastDataModificationTime |
+		virtual.AttributesMaskLastStatusChangeTime |
 		virtual.AttributesMaskLinkCount |
 		virtual.AttributesMaskPermissions |
 		virtual.AttributesMaskSizeBytes
@@ -53,6 +57,10 @@ func toFUSEStatus(s virtual.Status) fuse.Status {
 		return fuse.EISDIR
 	case virtual.StatusErrNoEnt:
 		return fuse.ENOENT
+	case virtual.StatusErrNoSpc:
+		return fuse.Status(syscall.ENOSPC)
+	case virtual.StatusErrNoXAttr:
+		return fuse.ENOATTR
 	case virtual.StatusErrNotDir:
 		return fuse.ENOTDIR
 	case virtual.StatusErrNotEmpty:
@@ -79,16 +87,37 @@ func toFUSEStatus(s virtual.Status) fuse.Status {
 type directoryEntry struct {
 	directory virtual.Directory
 	nLookup   uint64
+	// The path relative to the root of the file system at which the
+	// directory was most recently looked up. This is only tracked
+	// if a DirectIOMatcher is provided.
+	path string
+	// Whether the directory reported itself as being immutable,
+	// meaning that its directory entries never change.
+	isImmutable bool
 }
 
 type leafEntry struct {
-	leaf    virtual.Leaf
-	nLookup uint64
+	leaf     virtual.Leaf
+	nLookup  uint64
+	directIO bool
 }
 
+// DirectIOMatcher is called by the FUSE file system to determine
+// whether I/O against a file should bypass the kernel's page cache,
+// based on the path relative to the root of the file system under
+// which the file was looked up or created. Path components are
+// separated by slashes.
+type DirectIOMatcher func(relativePath string) bool
+
 type simpleRawFileSystem struct {
 	removalNotifierRegistrar virtual.FUSERemovalNotifierRegistrar
 	authenticator            Authenticator
+	immutableAttrValid       uint64
+	immutableAttrValidNsec   uint32
+	immutableEntryValid      uint64
+	immutableEntryValidNsec  uint32
+	directIOMatcher          DirectIOMatcher
+	statFSProvider           virtual.StatFSProvider
 
 	// Maps to resolve node IDs to directories and leaves.
 	nodeLock    sync.RWMutex
@@ -100,6 +129,19 @@ type simpleRawFileSystem struct {
 // flat FUSE operations to calls against a hierarchy of Directory and
 // Leaf objects.
 //
+// Attribute validity durations are normally filled in by
+// DefaultAttributesInjectingRawFileSystem. If immutableAttrValid is
+// nonzero, it is used as the attribute validity duration of nodes that
+// report themselves as being immutable, allowing the kernel to cache
+// attributes of files backed by the Content Addressable Storage for a
+// longer amount of time than ones that may be mutated. Similarly, if
+// immutableEntryValid is nonzero, it is used as the entry validity
+// duration of directory entries contained in immutable directories.
+// This permits caching lookups within directories backed by the
+// Content Addressable Storage for longer than ones within mutable
+// directories. Whether an entry may change is determined by its parent
+// directory, as immutable files may be placed in mutable directories.
+//
 // This implementation is comparable to the RawFileSystem
 // implementations created using go-fuse's fs.NewNodeFS() and
 // nodefs.FileSystemConnector.RawFS(), except that it is simpler. It
@@ -107,16 +149,34 @@ type simpleRawFileSystem struct {
 // keep track of files stored in a directory. Tracking this information
 // is the responsibility of the Directory and Leaf implementations.
 //
+// If directIOMatcher is not nil, files for which it returns true are
+// opened with FOPEN_DIRECT_IO. This causes reads and writes against
+// these files to bypass the kernel's page cache, which prevents large
+// files that are only accessed once from evicting data that is
+// accessed frequently. The decision is made based on the path that
+// was most recently used to look up or create the file.
+//
+// If statFSProvider is not nil, it is used to report the capacity of
+// the file system through statfs().
+//
 // FUSE as a protocol makes no true distinction between Directory and
 // Leaf objects. This implementation could therefore have been
 // simplified a bit by merging these two interface types together.
 // Separation between these two interfaces was added to make it easier
 // to understand which operations actually get called against a given
 // object type.
-func NewSimpleRawFileSystem(rootDirectory virtual.Directory, removalNotifierRegistrar virtual.FUSERemovalNotifierRegistrar, authenticator Authenticator) fuse.RawFileSystem {
+func NewSimpleRawFileSystem(rootDirectory virtual.Directory, removalNotifierRegistrar virtual.FUSERemovalNotifierRegistrar, authenticator Authenticator, immutableAttrValid, immutableEntryValid time.Duration, directIOMatcher DirectIOMatcher, statFSProvider virtual.StatFSProvider) fuse.RawFileSystem {
+	immutableAttrValidNsec := immutableAttrValid.Nanoseconds()
+	immutableEntryValidNsec := immutableEntryValid.Nanoseconds()
 	return &simpleRawFileSystem{
 		removalNotifierRegistrar: removalNotifierRegistrar,
 		authenticator:            authenticator,
+		immutableAttrValid:       uint64(immutableAttrValidNsec / 1e9),
+		immutableAttrValidNsec:   uint32(immutableAttrValidNsec % 1e9),
+		immutableEntryValid:      uint64(immutableEntryValidNsec / 1e9),
+		immutableEntryValidNsec:  uint32(immutableEntryValidNsec % 1e9),
+		directIOMatcher:          directIOMatcher,
+		statFSProvider:           statFSProvider,
 
 		directories: map[uint64]directoryEntry{
 			fuse.FUSE_ROOT_ID: {
@@ -158,11 +218,21 @@ func populateAttr(attributes *virtual.Attributes, out *fuse.Attr) {
 	out.Nlink = attributes.GetLinkCount()
 	out.Mode = toFUSEFileType(attributes.GetFileType())
 
+	if lastDataAccessTime, ok := attributes.GetLastDataAccessTime(); ok {
+		nanos := lastDataAccessTime.UnixNano()
+		out.Atime = uint64(nanos / 1e9)
+		out.Atimensec = uint32(nanos % 1e9)
+	}
 	if lastDataModificationTime, ok := attributes.GetLastDataModificationTime(); ok {
 		nanos := lastDataModificationTime.UnixNano()
 		out.Mtime = uint64(nanos / 1e9)
 		out.Mtimensec = uint32(nanos % 1e9)
 	}
+	if lastStatusChangeTime, ok := attributes.GetLastStatusChangeTime(); ok {
+		nanos := lastStatusChangeTime.UnixNano()
+		out.Ctime = uint64(nanos / 1e9)
+		out.Ctimensec = uint32(nanos % 1e9)
+	}
 
 	permissions, ok := attributes.GetPermissions()
 	if !ok {
@@ -177,8 +247,30 @@ func populateAttr(attributes *virtual.Attributes, out *fuse.Attr) {
 	out.Size = sizeBytes
 }
 
-func populateEntryOut(attributes *virtual.Attributes, out *fuse.EntryOut) {
+// setAttrValidity overrides the attribute validity duration of a node
+// if the node is immutable.
+func (rfs *simpleRawFileSystem) setAttrValidity(attributes *virtual.Attributes, attrValid *uint64, attrValidNsec *uint32) {
+	if (rfs.immutableAttrValid != 0 || rfs.immutableAttrValidNsec != 0) && attributes.GetIsImmutable() {
+		*attrValid = rfs.immutableAttrValid
+		*attrValidNsec = rfs.immutableAttrValidNsec
+	}
+}
+
+func (rfs *simpleRawFileSystem) populateAttrOut(attributes *virtual.Attributes, out *fuse.AttrOut) {
 	populateAttr(attributes, &out.Attr)
+	rfs.setAttrValidity(attributes, &out.AttrValid, &out.AttrValidNsec)
+}
+
+// populateEntryOut fills in the attributes of a directory entry. The
+// entry validity duration is overridden if the directory containing the
+// entry is immutable.
+func (rfs *simpleRawFileSystem) populateEntryOut(attributes *virtual.Attributes, parentIsImmutable bool, out *fuse.EntryOut) {
+	populateAttr(attributes, &out.Attr)
+	rfs.setAttrValidity(attributes, &out.AttrValid, &out.AttrValidNsec)
+	if (rfs.immutableEntryValid != 0 || rfs.immutableEntryValidNsec != 0) && parentIsImmutable {
+		out.EntryValid = rfs.immutableEntryValid
+		out.EntryValidNsec = rfs.immutableEntryValidNsec
+	}
 	out.NodeId = out.Ino
 }
 
@@ -206,37 +298,62 @@ func (rfs *simpleRawFileSystem) getNodeLocked(nodeID uint64) virtual.Node {
 	panic(fmt.Sprintf("Node ID %d does not correspond to a known directory or leaf", nodeID))
 }
 
-func (rfs *simpleRawFileSystem) addDirectory(i virtual.Directory, attributes *virtual.Attributes, out *fuse.EntryOut) {
-	populateEntryOut(attributes, out)
+// getChildPathLocked returns the path relative to the root of the file
+// system of a child of a directory. The path is only needed to
+// determine whether files should be opened with FOPEN_DIRECT_IO. It is
+// thus not computed if no DirectIOMatcher is provided.
+func (rfs *simpleRawFileSystem) getChildPathLocked(parentNodeID uint64, name path.Component) string {
+	if rfs.directIOMatcher == nil {
+		return ""
+	}
+	if parentPath := rfs.directories[parentNodeID].path; parentPath != "" {
+		return parentPath + "/" + name.String()
+	}
+	return name.String()
+}
 
+func (rfs *simpleRawFileSystem) addDirectory(i virtual.Directory, parentNodeID uint64, name path.Component, attributes *virtual.Attributes, out *fuse.EntryOut) {
 	rfs.nodeLock.Lock()
 	defer rfs.nodeLock.Unlock()
 
+	rfs.populateEntryOut(attributes, rfs.directories[parentNodeID].isImmutable, out)
+
 	if _, ok := rfs.leaves[out.NodeId]; ok {
 		panic(fmt.Sprintf("Directory %d has the same node ID as an existing leaf", out.NodeId))
 	}
 
 	// Increment lookup count of directory.
 	rfs.directories[out.NodeId] = directoryEntry{
-		directory: i,
-		nLookup:   rfs.directories[out.NodeId].nLookup + 1,
+		directory:   i,
+		nLookup:     rfs.directories[out.NodeId].nLookup + 1,
+		path:        rfs.getChildPathLocked(parentNodeID, name),
+		isImmutable: attributes.GetIsImmutable(),
 	}
 }
 
-func (rfs *simpleRawFileSystem) addLeaf(i virtual.Leaf, attributes *virtual.Attributes, out *fuse.EntryOut) {
-	populateEntryOut(attributes, out)
-
+// addLeaf registers a leaf that has been looked up or created. It
+// returns whether the leaf should be opened with FOPEN_DIRECT_IO.
+func (rfs *simpleRawFileSystem) addLeaf(i virtual.Leaf, parentNodeID uint64, name path.Component, attributes *virtual.Attributes, out *fuse.EntryOut) bool {
 	rfs.nodeLock.Lock()
 	defer rfs.nodeLock.Unlock()
 
+	directIO := rfs.directIOMatcher != nil && rfs.directIOMatcher(rfs.getChildPathLocked(parentNodeID, name))
+	rfs.addLeafLocked(i, parentNodeID, attributes, directIO, out)
+	return directIO
+}
+
+func (rfs *simpleRawFileSystem) addLeafLocked(i virtual.Leaf, parentNodeID uint64, attributes *virtual.Attributes, directIO bool, out *fuse.EntryOut) {
+	rfs.populateEntryOut(attributes, rfs.directories[parentNodeID].isImmutable, out)
+
 	if _, ok := rfs.directories[out.NodeId]; ok {
 		panic(fmt.Sprintf("Leaf %d has the same node ID as an existing directory", out.NodeId))
 	}
 
 	// Increment lookup count of leaf.
 	rfs.leaves[out.NodeId] = leafEntry{
-		leaf:    i,
-		nLookup: rfs.leaves[out.NodeId].nLookup + 1,
+		leaf:     i,
+		nLookup:  rfs.leaves[out.NodeId].nLookup + 1,
+		directIO: directIO,
 	}
 }
 
@@ -292,7 +409,8 @@ func (rfs *simpleRawFileSystem) Lookup(cancel <-chan struct{}, header *fuse.InHe
 	rfs.nodeLock.RUnlock()
 
 	var attributes virtual.Attributes
-	child, vs := i.VirtualLookup(ctx, path.MustNewComponent(name), AttributesMaskForFUSEAttr, &attributes)
+	component := path.MustNewComponent(name)
+	child, vs := i.VirtualLookup(ctx, component, AttributesMaskForFUSEAttr, &attributes)
 	if vs != virtual.StatusOK {
 		// TODO: Should we add support for generating negative
 		// cache entries? Preliminary testing shows that this
@@ -301,9 +419,9 @@ func (rfs *simpleRawFileSystem) Lookup(cancel <-chan struct{}, header *fuse.InHe
 		return toFUSEStatus(vs)
 	}
 	if directory, leaf := child.GetPair(); directory != nil {
-		rfs.addDirectory(directory, &attributes, out)
+		rfs.addDirectory(directory, header.NodeId, component, &attributes, out)
 	} else {
-		rfs.addLeaf(leaf, &attributes, out)
+		rfs.addLeaf(leaf, header.NodeId, component, &attributes, out)
 	}
 	return fuse.OK
 }
@@ -352,7 +470,7 @@ func (rfs *simpleRawFileSystem) GetAttr(cancel <-chan struct{}, input *fuse.GetA
 
 	var attributes virtual.Attributes
 	i.VirtualGetAttributes(ctx, AttributesMaskForFUSEAttr, &attributes)
-	populateAttr(&attributes, &out.Attr)
+	rfs.populateAttrOut(&attributes, out)
 	return fuse.OK
 }
 
@@ -376,12 +494,22 @@ func (rfs *simpleRawFileSystem) SetAttr(cancel <-chan struct{}, input *fuse.SetA
 	if input.Valid&fuse.FATTR_SIZE != 0 {
 		attributesIn.SetSizeBytes(input.Size)
 	}
+	if input.Valid&fuse.FATTR_ATIME != 0 {
+		// When FATTR_ATIME_NOW is set, the kernel already
+		// fills in the current time.
+		attributesIn.SetLastDataAccessTime(time.Unix(int64(input.Atime), int64(input.Atimensec)))
+	}
+	if input.Valid&fuse.FATTR_MTIME != 0 {
+		// When FATTR_MTIME_NOW is set, the kernel already
+		// fills in the current time.
+		attributesIn.SetLastDataModificationTime(time.Unix(int64(input.Mtime), int64(input.Mtimensec)))
+	}
 
 	var attributesOut virtual.Attributes
 	if s := i.VirtualSetAttributes(ctx, &attributesIn, AttributesMaskForFUSEAttr, &attributesOut); s != virtual.StatusOK {
 		return toFUSEStatus(s)
 	}
-	populateAttr(&attributesOut, &out.Attr)
+	rfs.populateAttrOut(&attributesOut, out)
 	return fuse.OK
 }
 
@@ -410,50 +538,87 @@ func (rfs *simpleRawFileSystem) Mknod(cancel <-chan struct{}, input *fuse.MknodI
 	if vs != virtual.StatusOK {
 		return toFUSEStatus(vs)
 	}
-	rfs.addLeaf(child, &attributes, out)
+	rfs.addLeaf(child, input.NodeId, path.MustNewComponent(name), &attributes, out)
 	return fuse.OK
 }
 
 func (rfs *simpleRawFileSystem) Mkdir(cancel <-chan struct{}, input *fuse.MkdirIn, name string, out *fuse.EntryOut) fuse.Status {
+	ctx, s := rfs.createContext(cancel, &input.Caller)
+	if s != fuse.OK {
+		return s
+	}
+
 	rfs.nodeLock.RLock()
 	i := rfs.getDirectoryLocked(input.NodeId)
 	rfs.nodeLock.RUnlock()
 
+	if vs := virtual.AuthorizeDirectoryModification(ctx, i); vs != virtual.StatusOK {
+		return toFUSEStatus(vs)
+	}
+
 	var attributes virtual.Attributes
-	child, _, s := i.VirtualMkdir(path.MustNewComponent(name), AttributesMaskForFUSEAttr, &attributes)
-	if s != virtual.StatusOK {
-		return toFUSEStatus(s)
+	child, _, vs := i.VirtualMkdir(path.MustNewComponent(name), AttributesMaskForFUSEAttr, &attributes)
+	if vs != virtual.StatusOK {
+		return toFUSEStatus(vs)
 	}
-	rfs.addDirectory(child, &attributes, out)
+	rfs.addDirectory(child, input.NodeId, path.MustNewComponent(name), &attributes, out)
 	return fuse.OK
 }
 
 func (rfs *simpleRawFileSystem) Unlink(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
+	ctx, s := rfs.createContext(cancel, &header.Caller)
+	if s != fuse.OK {
+		return s
+	}
+
 	rfs.nodeLock.RLock()
 	i := rfs.getDirectoryLocked(header.NodeId)
 	rfs.nodeLock.RUnlock()
 
-	_, s := i.VirtualRemove(path.MustNewComponent(name), false, true)
-	return toFUSEStatus(s)
+	if vs := virtual.AuthorizeDirectoryModification(ctx, i); vs != virtual.StatusOK {
+		return toFUSEStatus(vs)
+	}
+	_, vs := i.VirtualRemove(path.MustNewComponent(name), false, true)
+	return toFUSEStatus(vs)
 }
 
 func (rfs *simpleRawFileSystem) Rmdir(cancel <-chan struct{}, header *fuse.InHeader, name string) fuse.Status {
+	ctx, s := rfs.createContext(cancel, &header.Caller)
+	if s != fuse.OK {
+		return s
+	}
+
 	rfs.nodeLock.RLock()
 	i := rfs.getDirectoryLocked(header.NodeId)
 	rfs.nodeLock.RUnlock()
 
-	_, s := i.VirtualRemove(path.MustNewComponent(name), true, false)
-	return toFUSEStatus(s)
+	if vs := virtual.AuthorizeDirectoryModification(ctx, i); vs != virtual.StatusOK {
+		return toFUSEStatus(vs)
+	}
+	_, vs := i.VirtualRemove(path.MustNewComponent(name), true, false)
+	return toFUSEStatus(vs)
 }
 
 func (rfs *simpleRawFileSystem) Rename(cancel <-chan struct{}, input *fuse.RenameIn, oldName, newName string) fuse.Status {
+	ctx, s := rfs.createContext(cancel, &input.Caller)
+	if s != fuse.OK {
+		return s
+	}
+
 	rfs.nodeLock.RLock()
 	iOld := rfs.getDirectoryLocked(input.NodeId)
 	iNew := rfs.getDirectoryLocked(input.Newdir)
 	rfs.nodeLock.RUnlock()
 
-	_, _, s := iOld.VirtualRename(path.MustNewComponent(oldName), iNew, path.MustNewComponent(newName))
-	return toFUSEStatus(s)
+	// Both the source and target directory are modified.
+	if vs := virtual.AuthorizeDirectoryModification(ctx, iOld); vs != virtual.StatusOK {
+		return toFUSEStatus(vs)
+	}
+	if vs := virtual.AuthorizeDirectoryModification(ctx, iNew); vs != virtual.StatusOK {
+		return toFUSEStatus(vs)
+	}
+	_, _, vs := iOld.VirtualRename(path.MustNewComponent(oldName), iNew, path.MustNewComponent(newName))
+	return toFUSEStatus(vs)
 }
 
 func (rfs *simpleRawFileSystem) Link(cancel <-chan struct{}, input *fuse.LinkIn, filename string, out *fuse.EntryOut) fuse.Status {
@@ -471,7 +636,7 @@ func (rfs *simpleRawFileSystem) Link(cancel <-chan struct{}, input *fuse.LinkIn,
 	if _, s := iParent.VirtualLink(ctx, path.MustNewComponent(filename), iChild, AttributesMaskForFUSEAttr, &attributes); s != virtual.StatusOK {
 		return toFUSEStatus(s)
 	}
-	rfs.addLeaf(iChild, &attributes, out)
+	rfs.addLeaf(iChild, input.NodeId, path.MustNewComponent(filename), &attributes, out)
 	return fuse.OK
 }
 
@@ -490,7 +655,7 @@ func (rfs *simpleRawFileSystem) Symlink(cancel <-chan struct{}, header *fuse.InH
 	if vs != virtual.StatusOK {
 		return toFUSEStatus(vs)
 	}
-	rfs.addLeaf(child, &attributes, out)
+	rfs.addLeaf(child, header.NodeId, path.MustNewComponent(linkName), &attributes, out)
 	return fuse.OK
 }
 
@@ -541,28 +706,95 @@ func (rfs *simpleRawFileSystem) Access(cancel <-chan struct{}, input *fuse.Acces
 	return fuse.OK
 }
 
+// copyXAttrOut copies the value of an extended attribute or a list of
+// extended attribute names into the buffer provided by the kernel. If
+// the buffer is empty, the kernel merely requests the size of the data.
+func copyXAttrOut(data, dest []byte) (uint32, fuse.Status) {
+	if len(dest) == 0 {
+		return uint32(len(data)), fuse.OK
+	}
+	if len(data) > len(dest) {
+		return 0, fuse.Status(syscall.ERANGE)
+	}
+	return uint32(copy(dest, data)), fuse.OK
+}
+
 func (rfs *simpleRawFileSystem) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string, dest []byte) (uint32, fuse.Status) {
-	// By returning ENOSYS here, the Linux FUSE driver will set
-	// fuse_conn::no_getxattr. This will completely eliminate
-	// getxattr() calls going forward. More details:
-	//
-	// https://github.com/torvalds/linux/blob/371e8fd02969383204b1f6023451125dbc20dfbd/fs/fuse/xattr.c#L60-L61
-	// https://github.com/torvalds/linux/blob/371e8fd02969383204b1f6023451125dbc20dfbd/fs/fuse/xattr.c#L85-L88
-	//
-	// Similar logic is used for some of the other operations.
-	return 0, fuse.ENOSYS
+	ctx, s := rfs.createContext(cancel, &header.Caller)
+	if s != fuse.OK {
+		return 0, s
+	}
+
+	rfs.nodeLock.RLock()
+	i := rfs.getNodeLocked(header.NodeId)
+	rfs.nodeLock.RUnlock()
+
+	value, vs := i.VirtualGetXAttr(ctx, attr)
+	if vs != virtual.StatusOK {
+		return 0, toFUSEStatus(vs)
+	}
+	return copyXAttrOut(value, dest)
 }
 
 func (rfs *simpleRawFileSystem) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
-	return 0, fuse.ENOSYS
+	ctx, s := rfs.createContext(cancel, &header.Caller)
+	if s != fuse.OK {
+		return 0, s
+	}
+
+	rfs.nodeLock.RLock()
+	i := rfs.getNodeLocked(header.NodeId)
+	rfs.nodeLock.RUnlock()
+
+	names, vs := i.VirtualListXAttr(ctx)
+	if vs != virtual.StatusOK {
+		return 0, toFUSEStatus(vs)
+	}
+
+	// The kernel expects a sequence of null terminated names.
+	var data []byte
+	for _, name := range names {
+		data = append(append(data, name...), 0)
+	}
+	return copyXAttrOut(data, dest)
 }
 
 func (rfs *simpleRawFileSystem) SetXAttr(cancel <-chan struct{}, input *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
-	return fuse.ENOSYS
+	ctx, s := rfs.createContext(cancel, &input.Caller)
+	if s != fuse.OK {
+		return s
+	}
+
+	var mode virtual.XAttrSetMode
+	switch input.Flags {
+	case 0:
+		mode = virtual.XAttrSetModeCreateOrReplace
+	case unix.XATTR_CREATE:
+		mode = virtual.XAttrSetModeCreate
+	case unix.XATTR_REPLACE:
+		mode = virtual.XAttrSetModeReplace
+	default:
+		return fuse.EINVAL
+	}
+
+	rfs.nodeLock.RLock()
+	i := rfs.getNodeLocked(input.NodeId)
+	rfs.nodeLock.RUnlock()
+
+	return toFUSEStatus(i.VirtualSetXAttr(ctx, attr, data, mode))
 }
 
 func (rfs *simpleRawFileSystem) RemoveXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string) fuse.Status {
-	return fuse.ENOSYS
+	ctx, s := rfs.createContext(cancel, &header.Caller)
+	if s != fuse.OK {
+		return s
+	}
+
+	rfs.nodeLock.RLock()
+	i := rfs.getNodeLocked(header.NodeId)
+	rfs.nodeLock.RUnlock()
+
+	return toFUSEStatus(i.VirtualRemoveXAttr(ctx, attr))
 }
 
 // oflagsToShareMask converts access modes stored in open() flags to a
@@ -623,7 +855,49 @@ func (rfs *simpleRawFileSystem) Create(cancel <-chan struct{}, input *fuse.Creat
 	if vs != virtual.StatusOK {
 		return toFUSEStatus(vs)
 	}
-	rfs.addLeaf(child, &openedFileAttributes, &out.EntryOut)
+	if rfs.addLeaf(child, input.NodeId, path.MustNewComponent(name), &openedFileAttributes, &out.EntryOut) {
+		out.OpenOut.OpenFlags |= fuse.FOPEN_DIRECT_IO
+	}
+	return fuse.OK
+}
+
+// temporaryFileHandle is the file handle that is returned by
+// TmpFile(). It allows Release() to distinguish file descriptors
+// created using O_TMPFILE from ones created by regular calls to open().
+const temporaryFileHandle = 1
+
+func (rfs *simpleRawFileSystem) TmpFile(cancel <-chan struct{}, input *fuse.CreateIn, out *fuse.CreateOut) fuse.Status {
+	ctx, s := rfs.createContext(cancel, &input.Caller)
+	if s != fuse.OK {
+		return s
+	}
+
+	rfs.nodeLock.RLock()
+	i := rfs.getDirectoryLocked(input.NodeId)
+	rfs.nodeLock.RUnlock()
+
+	shareAccess, s := oflagsToShareMask(input.Flags)
+	if s != fuse.OK {
+		return s
+	}
+
+	var openedFileAttributes virtual.Attributes
+	child, _, vs := i.VirtualOpenTemporaryFile(
+		ctx,
+		shareAccess,
+		(&virtual.Attributes{}).SetPermissions(virtual.NewPermissionsFromMode(input.Mode)),
+		AttributesMaskForFUSEAttr,
+		&openedFileAttributes)
+	if vs != virtual.StatusOK {
+		return toFUSEStatus(vs)
+	}
+
+	// Temporary files have no path, meaning that they cannot be
+	// matched against the DirectIOMatcher.
+	rfs.nodeLock.Lock()
+	rfs.addLeafLocked(child, input.NodeId, &openedFileAttributes, false, &out.EntryOut)
+	rfs.nodeLock.Unlock()
+	out.OpenOut.Fh = temporaryFileHandle
 	return fuse.OK
 }
 
@@ -635,6 +909,7 @@ func (rfs *simpleRawFileSystem) Open(cancel <-chan struct{}, input *fuse.OpenIn,
 
 	rfs.nodeLock.RLock()
 	i := rfs.getLeafLocked(input.NodeId)
+	directIO := rfs.leaves[input.NodeId].directIO
 	rfs.nodeLock.RUnlock()
 
 	shareAccess, s := oflagsToShareMask(input.Flags)
@@ -644,7 +919,13 @@ func (rfs *simpleRawFileSystem) Open(cancel <-chan struct{}, input *fuse.OpenIn,
 	var options virtual.OpenExistingOptions
 	oflagsToOpenExistingOptions(input.Flags, &options)
 
-	return toFUSEStatus(i.VirtualOpenSelf(ctx, shareAccess, &options, 0, &virtual.Attributes{}))
+	if vs := i.VirtualOpenSelf(ctx, shareAccess, &options, 0, &virtual.Attributes{}); vs != virtual.StatusOK {
+		return toFUSEStatus(vs)
+	}
+	if directIO {
+		out.OpenFlags |= fuse.FOPEN_DIRECT_IO
+	}
+	return fuse.OK
 }
 
 func (rfs *simpleRawFileSystem) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (fuse.ReadResult, fuse.Status) {
@@ -708,6 +989,15 @@ func (rfs *simpleRawFileSystem) Release(cancel <-chan struct{}, input *fuse.Rele
 	}
 
 	i.VirtualClose(shareAccess)
+
+	if input.Fh == temporaryFileHandle {
+		// Files created through TmpFile() hold a link that is
+		// owned by the file descriptor, so that they don't
+		// become stale before being linked into a directory.
+		if nativeLeaf, ok := i.(virtual.NativeLeaf); ok {
+			nativeLeaf.Unlink()
+		}
+	}
 }
 
 func (rfs *simpleRawFileSystem) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []byte) (uint32, fuse.Status) {
@@ -720,7 +1010,23 @@ func (rfs *simpleRawFileSystem) Write(cancel <-chan struct{}, input *fuse.WriteI
 }
 
 func (rfs *simpleRawFileSystem) CopyFileRange(cancel <-chan struct{}, input *fuse.CopyFileRangeIn) (uint32, fuse.Status) {
-	return 0, fuse.ENOTSUP
+	ctx, s := rfs.createContext(cancel, &input.Caller)
+	if s != fuse.OK {
+		return 0, s
+	}
+
+	rfs.nodeLock.RLock()
+	source := rfs.getLeafLocked(input.NodeId)
+	destination := rfs.getLeafLocked(input.NodeIdOut)
+	rfs.nodeLock.RUnlock()
+
+	// The number of bytes copied is reported as a 32-bit value.
+	length := input.Len
+	if length > math.MaxUint32 {
+		length = math.MaxUint32
+	}
+	n, vs := destination.VirtualCopyFileRange(ctx, source, input.OffIn, input.OffOut, length)
+	return uint32(n), toFUSEStatus(vs)
 }
 
 func (rfs *simpleRawFileSystem) Flush(cancel <-chan struct{}, input *fuse.FlushIn) fuse.Status {
@@ -731,11 +1037,45 @@ func (rfs *simpleRawFileSystem) Fsync(cancel <-chan struct{}, input *fuse.FsyncI
 	return fuse.OK
 }
 
+// WriteBarrierIoctl is the ioctl() command that applies a write
+// barrier to a file stored in a FUSE mount, having the same effect as
+// calling BatchApplyWriteBarrier() through the Remote Output Service.
+// It corresponds to _IO('b', 1), meaning it takes no argument.
+//
+// Writes performed through write() have been delivered to the virtual
+// file system by the time they complete, as long as the writeback
+// cache is disabled. Data written through shared memory mappings needs
+// to be flushed by calling msync() or fsync() prior to applying the
+// write barrier.
+const WriteBarrierIoctl = 0x6201
+
+func (rfs *simpleRawFileSystem) Ioctl(cancel <-chan struct{}, input *fuse.IoctlIn, inbuf []byte, output *fuse.IoctlOut, bufOut []byte) fuse.Status {
+	if input.Cmd != WriteBarrierIoctl {
+		return fuse.Status(syscall.ENOTTY)
+	}
+
+	rfs.nodeLock.RLock()
+	i := rfs.getLeafLocked(input.NodeId)
+	rfs.nodeLock.RUnlock()
+
+	return toFUSEStatus(virtual.ApplyWriteBarrier(i))
+}
+
+// fallocFlPunchHole corresponds to FALLOC_FL_PUNCH_HOLE. It is
+// declared here, as the FUSE protocol uses Linux's values for
+// fallocate() modes, even on platforms that don't define it.
+const fallocFlPunchHole = 0x02
+
 func (rfs *simpleRawFileSystem) Fallocate(cancel <-chan struct{}, input *fuse.FallocateIn) fuse.Status {
 	rfs.nodeLock.RLock()
 	i := rfs.getLeafLocked(input.NodeId)
 	rfs.nodeLock.RUnlock()
 
+	if input.Mode&fallocFlPunchHole != 0 {
+		// The kernel only permits punching holes in
+		// combination with FALLOC_FL_KEEP_SIZE.
+		return toFUSEStatus(i.VirtualDeallocate(input.Offset, input.Length))
+	}
 	return toFUSEStatus(i.VirtualAllocate(input.Offset, input.Length))
 }
 
@@ -813,16 +1153,17 @@ func (rfs *simpleRawFileSystem) ReadDir(cancel <-chan struct{}, input *fuse.Read
 }
 
 type readDirPlusReporter struct {
-	rfs *simpleRawFileSystem
-	out fuse.ReadDirPlusEntryList
+	rfs          *simpleRawFileSystem
+	parentNodeID uint64
+	out          fuse.ReadDirPlusEntryList
 }
 
 func (r *readDirPlusReporter) ReportEntry(nextCookie uint64, name path.Component, child virtual.DirectoryChild, attributes *virtual.Attributes) bool {
 	if e := r.out.AddDirLookupEntry(toFUSEDirEntry(name, attributes), dotDotEntriesCount+nextCookie); e != nil {
 		if directory, leaf := child.GetPair(); directory != nil {
-			r.rfs.addDirectory(directory, attributes, e)
+			r.rfs.addDirectory(directory, r.parentNodeID, name, attributes, e)
 		} else {
-			r.rfs.addLeaf(leaf, attributes, e)
+			r.rfs.addLeaf(leaf, r.parentNodeID, name, attributes, e)
 		}
 		return true
 	}
@@ -853,7 +1194,7 @@ func (rfs *simpleRawFileSystem) ReadDirPlus(cancel <-chan struct{}, input *fuse.
 			ctx,
 			offset-dotDotEntriesCount,
 			AttributesMaskForFUSEAttr,
-			&readDirPlusReporter{rfs: rfs, out: out}))
+			&readDirPlusReporter{rfs: rfs, parentNodeID: input.NodeId, out: out}))
 }
 
 func (rfs *simpleRawFileSystem) ReleaseDir(input *fuse.ReleaseIn) {}
@@ -868,9 +1209,33 @@ func (rfs *simpleRawFileSystem) StatFs(cancel <-chan struct{}, input *fuse.InHea
 	// this value is necessary to make pathconf(path, _PC_NAME_MAX)
 	// work.
 	out.NameLen = 255
+
+	// Directories may have a capacity that differs from the rest
+	// of the file system (e.g., build directories that are subject
+	// to quotas).
+	rfs.nodeLock.RLock()
+	node := rfs.getNodeLocked(input.NodeId)
+	rfs.nodeLock.RUnlock()
+	if statFSProvider := virtual.GetStatFSProvider(node, rfs.statFSProvider); statFSProvider != nil {
+		var statistics virtual.FileSystemStatistics
+		if s := statFSProvider.VirtualStatFS(&statistics); s != virtual.StatusOK {
+			return toFUSEStatus(s)
+		}
+		out.Bsize = statFSBlockSizeBytes
+		out.Frsize = statFSBlockSizeBytes
+		out.Blocks = statistics.TotalSizeBytes / statFSBlockSizeBytes
+		out.Bfree = statistics.AvailableSizeBytes / statFSBlockSizeBytes
+		out.Bavail = out.Bfree
+		out.Files = statistics.TotalFiles
+		out.Ffree = statistics.AvailableFiles
+	}
 	return fuse.OK
 }
 
+// statFSBlockSizeBytes is the block size that is used to report the
+// capacity of the file system through statfs().
+const statFSBlockSizeBytes = 4096
+
 func (rfs *simpleRawFileSystem) Init(server fuse.ServerCallbacks) {
 	// Obtain the inode number of the root directory.
 	rfs.nodeLock.RLock()
@@ -882,8 +1247,10 @@ func (rfs *simpleRawFileSystem) Init(server fuse.ServerCallbacks) {
 
 	rfs.removalNotifierRegistrar(func(parent uint64, name path.Component) {
 		// EntryNotify can be called to report that directory entries
-		// have been removed. This causes them to be removed from the
-		// directory entry cache used by FUSE as well.
+		// have been removed or added. This causes them to be removed
+		// from the directory entry cache used by FUSE as well, and
+		// causes the attributes of the parent directory to be
+		// invalidated.
 		if parent == rootDirectoryInodeNumber {
 			// Even though we permit the root directory to
 			// have an arbitrary inode number, FUSE requires
@@ -0,0 +1,230 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package fuse_test
+
+import (
+	"sort"
+	"syscall"
+	"testing"
+
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual/fuse"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/random"
+	"github.com/buildbarn/bb-storage/pkg/util"
+	go_fuse "github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/require"
+)
+
+// FuzzSimpleRawFileSystem sends randomized sequences of FUSE requests
+// to a SimpleRawFileSystem that is backed by an in-memory directory.
+// The FUSE protocol requires that the kernel only refers to node IDs
+// that it has looked up previously. This harness respects these
+// requirements, as SimpleRawFileSystem intentionally panics when they
+// are violated. All other properties of requests are chosen freely.
+func FuzzSimpleRawFileSystem(f *testing.F) {
+	f.Add([]byte{0, 0, 0, 2, 0, 0, 0, 2, 1, 3, 1, 0})
+	f.Add([]byte{1, 0, 0, 3, 0, 4, 0, 1, 0, 8, 0, 9, 0, 1})
+	f.Add([]byte{2, 0, 1, 0, 0, 2, 0, 3, 0, 0, 1, 6, 0, 0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		handleAllocator := virtual.NewFUSEHandleAllocator(random.FastThreadSafeGenerator)
+		rootDirectory := virtual.NewInMemoryPrepopulatedDirectory(
+			virtual.NewHandleAllocatingFileAllocator(
+				virtual.NewPoolBackedFileAllocator(re_filesystem.InMemoryFilePool, util.DefaultErrorLogger, clock.SystemClock),
+				handleAllocator),
+			virtual.NewHandleAllocatingSymlinkFactory(
+				virtual.BaseSymlinkFactory,
+				handleAllocator.New()),
+			util.DefaultErrorLogger,
+			handleAllocator,
+			sort.Sort,
+			func(string) bool { return false },
+			clock.SystemClock,
+			/* caseInsensitive = */ false)
+		rfs := fuse.NewSimpleRawFileSystem(rootDirectory, handleAllocator.RegisterRemovalNotifier, fuse.AllowAuthenticator, 0, 0, nil, nil)
+
+		// Keep track of the node IDs returned by the file
+		// system, and how many times they have been looked up.
+		type node struct {
+			id          uint64
+			isDirectory bool
+			nLookup     uint64
+		}
+		nodes := []*node{{id: go_fuse.FUSE_ROOT_ID, isDirectory: true}}
+		addNode := func(out *go_fuse.EntryOut) {
+			for _, n := range nodes {
+				if n.id == out.NodeId {
+					n.nLookup++
+					return
+				}
+			}
+			nodes = append(nodes, &node{
+				id:          out.NodeId,
+				isDirectory: out.Attr.Mode&syscall.S_IFMT == syscall.S_IFDIR,
+				nLookup:     1,
+			})
+		}
+		forgetNode := func(i int, nLookup uint64) {
+			n := nodes[i]
+			rfs.Forget(n.id, nLookup)
+			n.nLookup -= nLookup
+			if n.nLookup == 0 {
+				nodes = append(nodes[:i], nodes[i+1:]...)
+			}
+		}
+
+		type openFile struct {
+			nodeID uint64
+			flags  uint32
+		}
+		var openFiles []openFile
+
+		in := data
+		next := func() byte {
+			if len(in) == 0 {
+				return 0
+			}
+			b := in[0]
+			in = in[1:]
+			return b
+		}
+		name := func() string {
+			return string([]byte{'a' + next()%4})
+		}
+		flags := func() uint32 {
+			return []uint32{syscall.O_RDONLY, syscall.O_WRONLY, syscall.O_RDWR}[next()%3] |
+				[]uint32{0, syscall.O_TRUNC, syscall.O_EXCL}[next()%3]
+		}
+		pickNode := func(isDirectory bool) (int, bool) {
+			var candidates []int
+			for i, n := range nodes {
+				if n.isDirectory == isDirectory {
+					candidates = append(candidates, i)
+				}
+			}
+			if len(candidates) == 0 {
+				return 0, false
+			}
+			return candidates[int(next())%len(candidates)], true
+		}
+
+		for len(in) > 0 {
+			switch next() % 12 {
+			case 0:
+				if i, ok := pickNode(true); ok {
+					var out go_fuse.EntryOut
+					if rfs.Lookup(nil, &go_fuse.InHeader{NodeId: nodes[i].id}, name(), &out) == go_fuse.OK {
+						addNode(&out)
+					}
+				}
+			case 1:
+				if i, ok := pickNode(true); ok {
+					var out go_fuse.EntryOut
+					if rfs.Mkdir(nil, &go_fuse.MkdirIn{
+						InHeader: go_fuse.InHeader{NodeId: nodes[i].id},
+						Mode:     0o777,
+					}, name(), &out) == go_fuse.OK {
+						addNode(&out)
+					}
+				}
+			case 2:
+				if i, ok := pickNode(true); ok {
+					var out go_fuse.CreateOut
+					fileFlags := flags()
+					if rfs.Create(nil, &go_fuse.CreateIn{
+						InHeader: go_fuse.InHeader{NodeId: nodes[i].id},
+						Flags:    fileFlags,
+						Mode:     0o666,
+					}, name(), &out) == go_fuse.OK {
+						addNode(&out.EntryOut)
+						openFiles = append(openFiles, openFile{nodeID: out.NodeId, flags: fileFlags})
+					}
+				}
+			case 3:
+				if i, ok := pickNode(true); ok {
+					rfs.Unlink(nil, &go_fuse.InHeader{NodeId: nodes[i].id}, name())
+				}
+			case 4:
+				if i, ok := pickNode(true); ok {
+					rfs.Rmdir(nil, &go_fuse.InHeader{NodeId: nodes[i].id}, name())
+				}
+			case 5:
+				if i, ok := pickNode(true); ok {
+					if j, ok := pickNode(true); ok {
+						rfs.Rename(nil, &go_fuse.RenameIn{
+							InHeader: go_fuse.InHeader{NodeId: nodes[i].id},
+							Newdir:   nodes[j].id,
+						}, name(), name())
+					}
+				}
+			case 6:
+				if len(openFiles) > 0 {
+					file := openFiles[int(next())%len(openFiles)]
+					rfs.Write(nil, &go_fuse.WriteIn{
+						InHeader: go_fuse.InHeader{NodeId: file.nodeID},
+						Offset:   uint64(next()),
+					}, []byte("Hello"))
+				}
+			case 7:
+				if len(openFiles) > 0 {
+					i := int(next()) % len(openFiles)
+					rfs.Release(nil, &go_fuse.ReleaseIn{
+						InHeader: go_fuse.InHeader{NodeId: openFiles[i].nodeID},
+						Flags:    openFiles[i].flags,
+					})
+					openFiles = append(openFiles[:i], openFiles[i+1:]...)
+				}
+			case 8:
+				if i, ok := pickNode(false); ok {
+					fileFlags := flags() &^ syscall.O_EXCL
+					if rfs.Open(nil, &go_fuse.OpenIn{
+						InHeader: go_fuse.InHeader{NodeId: nodes[i].id},
+						Flags:    fileFlags,
+					}, &go_fuse.OpenOut{}) == go_fuse.OK {
+						openFiles = append(openFiles, openFile{nodeID: nodes[i].id, flags: fileFlags})
+					}
+				}
+			case 9:
+				if len(nodes) > 1 {
+					// Never forget the root directory.
+					i := 1 + int(next())%(len(nodes)-1)
+					forgetNode(i, 1+uint64(next())%nodes[i].nLookup)
+				}
+			case 10:
+				if i, ok := pickNode(true); ok {
+					var out go_fuse.EntryOut
+					if rfs.Symlink(nil, &go_fuse.InHeader{NodeId: nodes[i].id}, "target", name(), &out) == go_fuse.OK {
+						addNode(&out)
+					}
+				}
+			case 11:
+				if i, ok := pickNode(false); ok {
+					if j, ok := pickNode(true); ok {
+						var out go_fuse.EntryOut
+						if rfs.Link(nil, &go_fuse.LinkIn{
+							InHeader:  go_fuse.InHeader{NodeId: nodes[j].id},
+							Oldnodeid: nodes[i].id,
+						}, name(), &out) == go_fuse.OK {
+							addNode(&out)
+						}
+					}
+				}
+			}
+		}
+
+		// Release all resources held by the kernel.
+		for _, file := range openFiles {
+			rfs.Release(nil, &go_fuse.ReleaseIn{
+				InHeader: go_fuse.InHeader{NodeId: file.nodeID},
+				Flags:    file.flags,
+			})
+		}
+		for len(nodes) > 1 {
+			forgetNode(1, nodes[1].nLookup)
+		}
+		require.NoError(t, rootDirectory.RemoveAllChildren(false))
+	})
+}
@@ -5,6 +5,7 @@ package fuse_test
 
 import (
 	"context"
+	"fmt"
 	"syscall"
 	"testing"
 	"time"
@@ -17,6 +18,8 @@ import (
 	"github.com/golang/mock/gomock"
 	go_fuse "github.com/hanwen/go-fuse/v2/fuse"
 	"github.com/stretchr/testify/require"
+
+	"golang.org/x/sys/unix"
 )
 
 func TestSimpleRawFileSystemAccess(t *testing.T) {
@@ -24,7 +27,7 @@ func TestSimpleRawFileSystemAccess(t *testing.T) {
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	t.Run("Failure", func(t *testing.T) {
 		rootDirectory.EXPECT().VirtualGetAttributes(gomock.Any(), virtual.AttributesMaskPermissions, gomock.Any()).DoAndReturn(
@@ -60,7 +63,7 @@ func TestSimpleRawFileSystemLookup(t *testing.T) {
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	t.Run("NotFound", func(t *testing.T) {
 		// Lookup failure errors should be propagated.
@@ -143,6 +146,72 @@ func TestSimpleRawFileSystemLookup(t *testing.T) {
 			},
 		}, entryOut)
 	})
+
+	t.Run("Immutable", func(t *testing.T) {
+		// Directory entries referring to immutable nodes should
+		// have their attribute validity durations overridden,
+		// if configured. As the root directory is mutable, the
+		// entry validity duration should not be overridden.
+		rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 90*time.Second, 3600*time.Second+250*time.Millisecond, nil, nil)
+		childDirectory := mock.NewMockVirtualDirectory(ctrl)
+		rootDirectory.EXPECT().VirtualLookup(gomock.Any(), path.MustNewComponent("directory"), fuse.AttributesMaskForFUSEAttr, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, name path.Component, requested virtual.AttributesMask, out *virtual.Attributes) (virtual.DirectoryChild, virtual.Status) {
+				out.SetFileType(filesystem.FileTypeDirectory)
+				out.SetInodeNumber(789)
+				out.SetIsImmutable(true)
+				out.SetLinkCount(2)
+				out.SetPermissions(virtual.PermissionsRead | virtual.PermissionsExecute)
+				out.SetSizeBytes(4096)
+				return virtual.DirectoryChild{}.FromDirectory(childDirectory), virtual.StatusOK
+			})
+
+		var entryOut go_fuse.EntryOut
+		require.Equal(t, go_fuse.OK, rfs.Lookup(nil, &go_fuse.InHeader{
+			NodeId: go_fuse.FUSE_ROOT_ID,
+		}, "directory", &entryOut))
+		require.Equal(t, go_fuse.EntryOut{
+			NodeId:    789,
+			AttrValid: 90,
+			Attr: go_fuse.Attr{
+				Mode:  go_fuse.S_IFDIR | 0o555,
+				Ino:   789,
+				Nlink: 2,
+				Size:  4096,
+			},
+		}, entryOut)
+
+		// Entries contained in the immutable directory can
+		// never change, meaning that the entry validity
+		// duration should be overridden as well.
+		childFile := mock.NewMockVirtualLeaf(ctrl)
+		childDirectory.EXPECT().VirtualLookup(gomock.Any(), path.MustNewComponent("file"), fuse.AttributesMaskForFUSEAttr, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, name path.Component, requested virtual.AttributesMask, out *virtual.Attributes) (virtual.DirectoryChild, virtual.Status) {
+				out.SetFileType(filesystem.FileTypeRegularFile)
+				out.SetInodeNumber(790)
+				out.SetIsImmutable(true)
+				out.SetLinkCount(1)
+				out.SetPermissions(virtual.PermissionsRead)
+				out.SetSizeBytes(1300)
+				return virtual.DirectoryChild{}.FromLeaf(childFile), virtual.StatusOK
+			})
+
+		var fileEntryOut go_fuse.EntryOut
+		require.Equal(t, go_fuse.OK, rfs.Lookup(nil, &go_fuse.InHeader{
+			NodeId: 789,
+		}, "file", &fileEntryOut))
+		require.Equal(t, go_fuse.EntryOut{
+			NodeId:         790,
+			EntryValid:     3600,
+			EntryValidNsec: 250000000,
+			AttrValid:      90,
+			Attr: go_fuse.Attr{
+				Mode:  go_fuse.S_IFREG | 0o444,
+				Ino:   790,
+				Nlink: 1,
+				Size:  1300,
+			},
+		}, fileEntryOut)
+	})
 }
 
 func TestSimpleRawFileSystemForget(t *testing.T) {
@@ -150,7 +219,7 @@ func TestSimpleRawFileSystemForget(t *testing.T) {
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	for i := 0; i < 10; i++ {
 		// Perform ten lookups of the same directory.
@@ -282,7 +351,7 @@ func TestSimpleRawFileSystemGetAttr(t *testing.T) {
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	t.Run("Success", func(t *testing.T) {
 		rootDirectory.EXPECT().VirtualGetAttributes(gomock.Any(), fuse.AttributesMaskForFUSEAttr, gomock.Any()).DoAndReturn(
@@ -309,6 +378,38 @@ func TestSimpleRawFileSystemGetAttr(t *testing.T) {
 			},
 		}, attrOut)
 	})
+
+	t.Run("Immutable", func(t *testing.T) {
+		// Immutable nodes should have their attribute validity
+		// duration overridden, if configured.
+		rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 90*time.Second+500*time.Millisecond, 0, nil, nil)
+		rootDirectory.EXPECT().VirtualGetAttributes(gomock.Any(), fuse.AttributesMaskForFUSEAttr, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, requested virtual.AttributesMask, out *virtual.Attributes) {
+				out.SetFileType(filesystem.FileTypeDirectory)
+				out.SetInodeNumber(42)
+				out.SetIsImmutable(true)
+				out.SetLinkCount(7)
+				out.SetPermissions(virtual.PermissionsExecute)
+				out.SetSizeBytes(12)
+			})
+
+		var attrOut go_fuse.AttrOut
+		require.Equal(t, go_fuse.OK, rfs.GetAttr(nil, &go_fuse.GetAttrIn{
+			InHeader: go_fuse.InHeader{
+				NodeId: go_fuse.FUSE_ROOT_ID,
+			},
+		}, &attrOut))
+		require.Equal(t, go_fuse.AttrOut{
+			AttrValid:     90,
+			AttrValidNsec: 500000000,
+			Attr: go_fuse.Attr{
+				Mode:  go_fuse.S_IFDIR | 0o111,
+				Ino:   42,
+				Nlink: 7,
+				Size:  12,
+			},
+		}, attrOut)
+	})
 }
 
 func TestSimpleRawFileSystemSetAttr(t *testing.T) {
@@ -316,7 +417,7 @@ func TestSimpleRawFileSystemSetAttr(t *testing.T) {
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	t.Run("Chown", func(t *testing.T) {
 		// chown() operations are not supported.
@@ -398,7 +499,7 @@ func TestSimpleRawFileSystemMknod(t *testing.T) {
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	t.Run("BlockDevice", func(t *testing.T) {
 		// An mknod() call for a block device should be
@@ -468,7 +569,7 @@ func TestSimpleRawFileSystemMkdir(t *testing.T) {
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	t.Run("Failure", func(t *testing.T) {
 		// An mkdir() call that fails due to an I/O error.
@@ -524,7 +625,7 @@ func TestSimpleRawFileSystemUnlink(t *testing.T) {
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	t.Run("Failure", func(t *testing.T) {
 		// An unlink() call that fails due to an I/O error.
@@ -555,7 +656,7 @@ func TestSimpleRawFileSystemRmdir(t *testing.T) {
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	t.Run("Failure", func(t *testing.T) {
 		// An rmdir() call that fails due to an I/O error.
@@ -586,7 +687,7 @@ func TestSimpleRawFileSystemSymlink(t *testing.T) {
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	t.Run("Failure", func(t *testing.T) {
 		rootDirectory.EXPECT().VirtualSymlink(
@@ -653,7 +754,7 @@ func TestSimpleRawFileSystemCreate(t *testing.T) {
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	t.Run("ReadWriteCreateExcl", func(t *testing.T) {
 		rootDirectory.EXPECT().VirtualOpenChild(
@@ -698,12 +799,198 @@ func TestSimpleRawFileSystemCreate(t *testing.T) {
 	})
 }
 
+func TestSimpleRawFileSystemTmpFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
+	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
+
+	t.Run("Failure", func(t *testing.T) {
+		rootDirectory.EXPECT().VirtualOpenTemporaryFile(
+			gomock.Any(),
+			virtual.ShareMaskWrite,
+			(&virtual.Attributes{}).SetPermissions(virtual.PermissionsRead|virtual.PermissionsWrite),
+			fuse.AttributesMaskForFUSEAttr,
+			gomock.Any(),
+		).Return(nil, virtual.AttributesMask(0), virtual.StatusErrIO)
+
+		var createOut go_fuse.CreateOut
+		require.Equal(t, go_fuse.EIO, rfs.TmpFile(nil, &go_fuse.CreateIn{
+			InHeader: go_fuse.InHeader{
+				NodeId: go_fuse.FUSE_ROOT_ID,
+			},
+			Flags: syscall.O_WRONLY,
+			Mode:  0o644,
+		}, &createOut))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		// Successfully creating a temporary file should cause
+		// it to be registered, and be given a dedicated file
+		// handle.
+		leaf := mock.NewMockNativeLeaf(ctrl)
+		rootDirectory.EXPECT().VirtualOpenTemporaryFile(
+			gomock.Any(),
+			virtual.ShareMaskRead|virtual.ShareMaskWrite,
+			(&virtual.Attributes{}).SetPermissions(virtual.PermissionsRead|virtual.PermissionsWrite),
+			fuse.AttributesMaskForFUSEAttr,
+			gomock.Any(),
+		).DoAndReturn(func(ctx context.Context, shareAccess virtual.ShareMask, createAttributes *virtual.Attributes, requested virtual.AttributesMask, openedFileAttributes *virtual.Attributes) (virtual.Leaf, virtual.AttributesMask, virtual.Status) {
+			openedFileAttributes.SetFileType(filesystem.FileTypeRegularFile)
+			openedFileAttributes.SetInodeNumber(123)
+			openedFileAttributes.SetLinkCount(1)
+			openedFileAttributes.SetPermissions(virtual.PermissionsRead | virtual.PermissionsWrite)
+			openedFileAttributes.SetSizeBytes(0)
+			return leaf, virtual.AttributesMask(0), virtual.StatusOK
+		})
+
+		var createOut go_fuse.CreateOut
+		require.Equal(t, go_fuse.OK, rfs.TmpFile(nil, &go_fuse.CreateIn{
+			InHeader: go_fuse.InHeader{
+				NodeId: go_fuse.FUSE_ROOT_ID,
+			},
+			Flags: syscall.O_RDWR,
+			Mode:  0o644,
+		}, &createOut))
+		require.Equal(t, uint64(123), createOut.NodeId)
+		require.Equal(t, uint64(1), createOut.Fh)
+		require.Equal(t, uint32(1), createOut.Nlink)
+
+		// Closing the file should release the link that is
+		// owned by the file descriptor.
+		leaf.EXPECT().VirtualClose(virtual.ShareMaskRead | virtual.ShareMaskWrite)
+		leaf.EXPECT().Unlink()
+
+		rfs.Release(nil, &go_fuse.ReleaseIn{
+			InHeader: go_fuse.InHeader{
+				NodeId: 123,
+			},
+			Fh:    1,
+			Flags: syscall.O_RDWR,
+		})
+	})
+}
+
+func TestSimpleRawFileSystemOpenDirectIO(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
+	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, func(relativePath string) bool {
+		return relativePath == "out/archive.tar"
+	}, nil)
+
+	// Look up a subdirectory, so that the paths of files
+	// contained in it are known.
+	outDirectory := mock.NewMockVirtualDirectory(ctrl)
+	rootDirectory.EXPECT().VirtualLookup(gomock.Any(), path.MustNewComponent("out"), fuse.AttributesMaskForFUSEAttr, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, name path.Component, requested virtual.AttributesMask, out *virtual.Attributes) (virtual.DirectoryChild, virtual.Status) {
+			out.SetFileType(filesystem.FileTypeDirectory)
+			out.SetInodeNumber(99)
+			out.SetLinkCount(2)
+			out.SetPermissions(virtual.PermissionsRead | virtual.PermissionsExecute)
+			out.SetSizeBytes(4096)
+			return virtual.DirectoryChild{}.FromDirectory(outDirectory), virtual.StatusOK
+		})
+	var directoryEntryOut go_fuse.EntryOut
+	require.Equal(t, go_fuse.OK, rfs.Lookup(nil, &go_fuse.InHeader{
+		NodeId: go_fuse.FUSE_ROOT_ID,
+	}, "out", &directoryEntryOut))
+
+	for _, tc := range []struct {
+		name            string
+		parentDirectory *mock.MockVirtualDirectory
+		parentNodeID    uint64
+		inodeNumber     uint64
+		expectedFlags   uint32
+	}{
+		{"archive.tar", outDirectory, 99, 100, go_fuse.FOPEN_DIRECT_IO},
+		{"header.h", outDirectory, 99, 101, 0},
+		// Patterns are matched against the full path, not
+		// just the name of the file.
+		{"archive.tar", rootDirectory, go_fuse.FUSE_ROOT_ID, 102, 0},
+	} {
+		t.Run(fmt.Sprintf("%d", tc.inodeNumber), func(t *testing.T) {
+			leaf := mock.NewMockVirtualLeaf(ctrl)
+			tc.parentDirectory.EXPECT().VirtualLookup(gomock.Any(), path.MustNewComponent(tc.name), fuse.AttributesMaskForFUSEAttr, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, name path.Component, requested virtual.AttributesMask, out *virtual.Attributes) (virtual.DirectoryChild, virtual.Status) {
+					out.SetFileType(filesystem.FileTypeRegularFile)
+					out.SetInodeNumber(tc.inodeNumber)
+					out.SetLinkCount(1)
+					out.SetPermissions(virtual.PermissionsRead)
+					out.SetSizeBytes(1000)
+					return virtual.DirectoryChild{}.FromLeaf(leaf), virtual.StatusOK
+				})
+
+			var entryOut go_fuse.EntryOut
+			require.Equal(t, go_fuse.OK, rfs.Lookup(nil, &go_fuse.InHeader{
+				NodeId: tc.parentNodeID,
+			}, tc.name, &entryOut))
+
+			leaf.EXPECT().VirtualOpenSelf(gomock.Any(), virtual.ShareMaskRead, &virtual.OpenExistingOptions{}, virtual.AttributesMask(0), gomock.Any())
+
+			var openOut go_fuse.OpenOut
+			require.Equal(t, go_fuse.OK, rfs.Open(nil, &go_fuse.OpenIn{
+				InHeader: go_fuse.InHeader{
+					NodeId: tc.inodeNumber,
+				},
+				Flags: syscall.O_RDONLY,
+			}, &openOut))
+			require.Equal(t, tc.expectedFlags, openOut.OpenFlags)
+		})
+	}
+}
+
+func TestSimpleRawFileSystemIoctl(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
+	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
+
+	leaf := mock.NewMockVirtualLeaf(ctrl)
+	rootDirectory.EXPECT().VirtualLookup(gomock.Any(), path.MustNewComponent("file"), fuse.AttributesMaskForFUSEAttr, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, name path.Component, requested virtual.AttributesMask, out *virtual.Attributes) (virtual.DirectoryChild, virtual.Status) {
+			out.SetFileType(filesystem.FileTypeRegularFile)
+			out.SetInodeNumber(2)
+			out.SetLinkCount(1)
+			out.SetPermissions(virtual.PermissionsRead | virtual.PermissionsWrite)
+			out.SetSizeBytes(1000)
+			return virtual.DirectoryChild{}.FromLeaf(leaf), virtual.StatusOK
+		})
+	var entryOut go_fuse.EntryOut
+	require.Equal(t, go_fuse.OK, rfs.Lookup(nil, &go_fuse.InHeader{
+		NodeId: go_fuse.FUSE_ROOT_ID,
+	}, "file", &entryOut))
+
+	t.Run("UnknownCommand", func(t *testing.T) {
+		require.Equal(t, go_fuse.Status(syscall.ENOTTY), rfs.Ioctl(nil, &go_fuse.IoctlIn{
+			InHeader: go_fuse.InHeader{
+				NodeId: 2,
+			},
+			Cmd: fuse.WriteBarrierIoctl + 1,
+		}, nil, &go_fuse.IoctlOut{}, nil))
+	})
+
+	t.Run("WriteBarrier", func(t *testing.T) {
+		// Leaves whose contents cannot be modified ignore
+		// write barriers.
+		require.Equal(t, go_fuse.OK, rfs.Ioctl(nil, &go_fuse.IoctlIn{
+			InHeader: go_fuse.InHeader{
+				NodeId: 2,
+			},
+			Cmd: fuse.WriteBarrierIoctl,
+		}, nil, &go_fuse.IoctlOut{}, nil))
+	})
+}
+
 func TestSimpleRawFileSystemOpenDir(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	t.Run("PermissionDenied", func(t *testing.T) {
 		// FUSE on Linux doesn't check permissions on the
@@ -730,7 +1017,7 @@ func TestSimpleRawFileSystemReadDir(t *testing.T) {
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	// Open the root directory.
 	rootDirectory.EXPECT().VirtualGetAttributes(gomock.Any(), virtual.AttributesMaskPermissions, gomock.Any()).DoAndReturn(
@@ -961,7 +1248,7 @@ func TestSimpleRawFileSystemReadDirPlus(t *testing.T) {
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	// Open the root directory.
 	rootDirectory.EXPECT().VirtualGetAttributes(gomock.Any(), virtual.AttributesMaskPermissions, gomock.Any()).DoAndReturn(
@@ -1162,7 +1449,7 @@ func TestSimpleRawFileSystemReadlink(t *testing.T) {
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	symlink := mock.NewMockVirtualLeaf(ctrl)
 	rootDirectory.EXPECT().VirtualLookup(gomock.Any(), path.MustNewComponent("symlink"), fuse.AttributesMaskForFUSEAttr, gomock.Any()).DoAndReturn(
@@ -1212,12 +1499,88 @@ func TestSimpleRawFileSystemReadlink(t *testing.T) {
 	})
 }
 
+func TestSimpleRawFileSystemXAttr(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
+	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
+	header := go_fuse.InHeader{NodeId: go_fuse.FUSE_ROOT_ID}
+
+	t.Run("GetXAttrNotFound", func(t *testing.T) {
+		rootDirectory.EXPECT().VirtualGetXAttr(gomock.Any(), "user.foo").Return(nil, virtual.StatusErrNoXAttr)
+
+		var dest [10]byte
+		_, s := rfs.GetXAttr(nil, &header, "user.foo", dest[:])
+		require.Equal(t, go_fuse.ENOATTR, s)
+	})
+
+	t.Run("GetXAttrSize", func(t *testing.T) {
+		// An empty buffer indicates that the kernel only wants
+		// to obtain the size of the value.
+		rootDirectory.EXPECT().VirtualGetXAttr(gomock.Any(), "user.foo").Return([]byte("Hello"), virtual.StatusOK)
+
+		n, s := rfs.GetXAttr(nil, &header, "user.foo", nil)
+		require.Equal(t, go_fuse.OK, s)
+		require.Equal(t, uint32(5), n)
+	})
+
+	t.Run("GetXAttrTooSmall", func(t *testing.T) {
+		rootDirectory.EXPECT().VirtualGetXAttr(gomock.Any(), "user.foo").Return([]byte("Hello"), virtual.StatusOK)
+
+		var dest [3]byte
+		_, s := rfs.GetXAttr(nil, &header, "user.foo", dest[:])
+		require.Equal(t, go_fuse.Status(syscall.ERANGE), s)
+	})
+
+	t.Run("GetXAttrSuccess", func(t *testing.T) {
+		rootDirectory.EXPECT().VirtualGetXAttr(gomock.Any(), "user.foo").Return([]byte("Hello"), virtual.StatusOK)
+
+		var dest [10]byte
+		n, s := rfs.GetXAttr(nil, &header, "user.foo", dest[:])
+		require.Equal(t, go_fuse.OK, s)
+		require.Equal(t, []byte("Hello"), dest[:n])
+	})
+
+	t.Run("ListXAttr", func(t *testing.T) {
+		// Names should be returned in null terminated form.
+		rootDirectory.EXPECT().VirtualListXAttr(gomock.Any()).Return([]string{"user.bar", "user.foo"}, virtual.StatusOK)
+
+		var dest [100]byte
+		n, s := rfs.ListXAttr(nil, &header, dest[:])
+		require.Equal(t, go_fuse.OK, s)
+		require.Equal(t, []byte("user.bar\x00user.foo\x00"), dest[:n])
+	})
+
+	t.Run("SetXAttrInvalidFlags", func(t *testing.T) {
+		require.Equal(t, go_fuse.EINVAL, rfs.SetXAttr(nil, &go_fuse.SetXAttrIn{
+			InHeader: header,
+			Flags:    12345,
+		}, "user.foo", []byte("Hello")))
+	})
+
+	t.Run("SetXAttrCreate", func(t *testing.T) {
+		rootDirectory.EXPECT().VirtualSetXAttr(gomock.Any(), "user.foo", []byte("Hello"), virtual.XAttrSetModeCreate).Return(virtual.StatusErrExist)
+
+		require.Equal(t, go_fuse.Status(syscall.EEXIST), rfs.SetXAttr(nil, &go_fuse.SetXAttrIn{
+			InHeader: header,
+			Flags:    unix.XATTR_CREATE,
+		}, "user.foo", []byte("Hello")))
+	})
+
+	t.Run("RemoveXAttr", func(t *testing.T) {
+		rootDirectory.EXPECT().VirtualRemoveXAttr(gomock.Any(), "user.foo").Return(virtual.StatusOK)
+
+		require.Equal(t, go_fuse.OK, rfs.RemoveXAttr(nil, &header, "user.foo"))
+	})
+}
+
 func TestSimpleRawFileSystemStatFs(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	t.Run("Success", func(t *testing.T) {
 		// OSXFUSE lets the statvfs() system call succeed, even
@@ -1235,6 +1598,43 @@ func TestSimpleRawFileSystemStatFs(t *testing.T) {
 			NameLen: 255,
 		}, statfsOut)
 	})
+
+	t.Run("WithStatFSProvider", func(t *testing.T) {
+		// If a StatFSProvider is provided, the capacity of the
+		// file system should be reported.
+		statFSProvider := mock.NewMockStatFSProvider(ctrl)
+		rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, statFSProvider)
+
+		statFSProvider.EXPECT().VirtualStatFS(gomock.Any()).DoAndReturn(func(out *virtual.FileSystemStatistics) virtual.Status {
+			*out = virtual.FileSystemStatistics{
+				TotalSizeBytes:     10 * 1024 * 1024,
+				AvailableSizeBytes: 4 * 1024 * 1024,
+				TotalFiles:         1000,
+				AvailableFiles:     900,
+			}
+			return virtual.StatusOK
+		})
+		var statfsOut go_fuse.StatfsOut
+		require.Equal(t, go_fuse.OK, rfs.StatFs(nil, &go_fuse.InHeader{
+			NodeId: go_fuse.FUSE_ROOT_ID,
+		}, &statfsOut))
+		require.Equal(t, go_fuse.StatfsOut{
+			Blocks:  2560,
+			Bfree:   1024,
+			Bavail:  1024,
+			Files:   1000,
+			Ffree:   900,
+			Bsize:   4096,
+			NameLen: 255,
+			Frsize:  4096,
+		}, statfsOut)
+
+		// Failures should be propagated.
+		statFSProvider.EXPECT().VirtualStatFS(gomock.Any()).Return(virtual.StatusErrIO)
+		require.Equal(t, go_fuse.EIO, rfs.StatFs(nil, &go_fuse.InHeader{
+			NodeId: go_fuse.FUSE_ROOT_ID,
+		}, &statfsOut))
+	})
 }
 
 func TestSimpleRawFileSystemInit(t *testing.T) {
@@ -1242,7 +1642,7 @@ func TestSimpleRawFileSystemInit(t *testing.T) {
 
 	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
 	removalNotifierRegistrar := mock.NewMockFUSERemovalNotifierRegistrar(ctrl)
-	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator)
+	rfs := fuse.NewSimpleRawFileSystem(rootDirectory, removalNotifierRegistrar.Call, fuse.AllowAuthenticator, 0, 0, nil, nil)
 
 	// An Init() operation should cause SimpleRawFileSystem to
 	// register a removal notifier that forwards calls to
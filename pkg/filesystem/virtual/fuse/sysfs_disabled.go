@@ -21,3 +21,16 @@ func SetLinuxBackingDevInfoTunables(mountPath string, variables map[string]strin
 	}
 	return nil
 }
+
+// SetLinuxCongestionThreshold adjusts the number of pending background
+// requests of a FUSE mount at which the kernel considers the mount to
+// be congested.
+//
+// This is a placeholder implementation for operating systems other than
+// Linux.
+func SetLinuxCongestionThreshold(mountPath string, congestionThreshold uint32) error {
+	if congestionThreshold != 0 {
+		return status.Error(codes.Unimplemented, "Setting the congestion threshold is only supported on Linux")
+	}
+	return nil
+}
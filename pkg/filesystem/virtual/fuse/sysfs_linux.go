@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/buildbarn/bb-storage/pkg/util"
 
@@ -29,19 +30,53 @@ func SetLinuxBackingDevInfoTunables(mountPath string, variables map[string]strin
 	bdiPath := fmt.Sprintf("/sys/class/bdi/%d:%d", unix.Major(sb.Dev), unix.Minor(sb.Dev))
 
 	for key, value := range variables {
-		keyPath := filepath.Join(bdiPath, key)
-		f, err := os.OpenFile(keyPath, os.O_TRUNC|os.O_WRONLY, 0o666)
-		if err != nil {
-			return util.StatusWrapf(err, "Failed to open %#v corresponding to FUSE mount %#v", keyPath, mountPath)
-		}
-		_, err1 := f.Write([]byte(value))
-		err2 := f.Close()
-		if err1 != nil {
-			return util.StatusWrapf(err1, "Failed to write to %#v corresponding to FUSE mount %#v", keyPath, mountPath)
-		}
-		if err2 != nil {
-			return util.StatusWrapf(err2, "Failed to close %#v corresponding to FUSE mount %#v", keyPath, mountPath)
+		if err := writeSysfsFile(filepath.Join(bdiPath, key), value, mountPath); err != nil {
+			return err
 		}
 	}
 	return nil
 }
+
+// SetLinuxCongestionThreshold adjusts the number of pending background
+// requests of a FUSE mount at which the kernel considers the mount to
+// be congested. go-fuse does not permit setting this value explicitly
+// as part of FUSE_INIT, as it always uses 75% of the maximum number of
+// background requests.
+//
+// This implementation applies the threshold through the FUSE control
+// file system, which is expected to be mounted at
+// /sys/fs/fuse/connections. A value of zero leaves the threshold
+// unmodified.
+func SetLinuxCongestionThreshold(mountPath string, congestionThreshold uint32) error {
+	if congestionThreshold == 0 {
+		return nil
+	}
+
+	// FUSE mounts use anonymous device numbers, meaning that the
+	// name of the connection directory is equal to the minor
+	// number of the mount's st_dev.
+	var sb unix.Stat_t
+	if err := unix.Stat(mountPath, &sb); err != nil {
+		return util.StatusWrapf(err, "Failed to obtain device number from FUSE mount %#v", mountPath)
+	}
+	return writeSysfsFile(
+		fmt.Sprintf("/sys/fs/fuse/connections/%d/congestion_threshold", unix.Minor(sb.Dev)),
+		strconv.FormatUint(uint64(congestionThreshold), 10),
+		mountPath)
+}
+
+func writeSysfsFile(keyPath, value, mountPath string) error {
+	f, err := os.OpenFile(keyPath, os.O_TRUNC|os.O_WRONLY, 0o666)
+	if err != nil {
+		return util.StatusWrapf(err, "Failed to open %#v corresponding to FUSE mount %#v", keyPath, mountPath)
+	}
+	_, err1 := f.Write([]byte(value))
+	err2 := f.Close()
+	if err1 != nil {
+		return util.StatusWrapf(err1, "Failed to write to %#v corresponding to FUSE mount %#v", keyPath, mountPath)
+	}
+	if err2 != nil {
+		return util.StatusWrapf(err2, "Failed to close %#v corresponding to FUSE mount %#v", keyPath, mountPath)
+	}
+	return nil
+}
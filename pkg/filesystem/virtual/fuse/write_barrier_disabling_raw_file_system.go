@@ -0,0 +1,36 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package fuse
+
+import (
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+type writeBarrierDisablingRawFileSystem struct {
+	fuse.RawFileSystem
+}
+
+// NewWriteBarrierDisablingRawFileSystem creates a decorator for
+// RawFileSystem that causes ioctl() calls using WriteBarrierIoctl to
+// fail with EOPNOTSUPP.
+//
+// This decorator needs to be used if the kernel's writeback cache is
+// enabled. In that case data written to a file may remain in the page
+// cache without the file being modified. A write barrier would
+// therefore cause the digest of the file to be reported, even though
+// it does not reflect the file's current contents.
+func NewWriteBarrierDisablingRawFileSystem(base fuse.RawFileSystem) fuse.RawFileSystem {
+	return &writeBarrierDisablingRawFileSystem{
+		RawFileSystem: base,
+	}
+}
+
+func (rfs *writeBarrierDisablingRawFileSystem) Ioctl(cancel <-chan struct{}, input *fuse.IoctlIn, inbuf []byte, output *fuse.IoctlOut, bufOut []byte) fuse.Status {
+	if input.Cmd == WriteBarrierIoctl {
+		return fuse.Status(syscall.EOPNOTSUPP)
+	}
+	return rfs.RawFileSystem.Ioctl(cancel, input, inbuf, output, bufOut)
+}
@@ -0,0 +1,47 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package fuse_test
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual/fuse"
+	"github.com/golang/mock/gomock"
+	go_fuse "github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteBarrierDisablingRawFileSystem(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	base := mock.NewMockRawFileSystem(ctrl)
+	rfs := fuse.NewWriteBarrierDisablingRawFileSystem(base)
+
+	t.Run("WriteBarrier", func(t *testing.T) {
+		// Write barriers should be rejected without
+		// consulting the underlying file system.
+		require.Equal(t, go_fuse.Status(syscall.EOPNOTSUPP), rfs.Ioctl(nil, &go_fuse.IoctlIn{
+			InHeader: go_fuse.InHeader{
+				NodeId: 2,
+			},
+			Cmd: fuse.WriteBarrierIoctl,
+		}, nil, &go_fuse.IoctlOut{}, nil))
+	})
+
+	t.Run("OtherCommand", func(t *testing.T) {
+		// Other commands should be forwarded.
+		input := &go_fuse.IoctlIn{
+			InHeader: go_fuse.InHeader{
+				NodeId: 2,
+			},
+			Cmd: fuse.WriteBarrierIoctl + 1,
+		}
+		output := &go_fuse.IoctlOut{}
+		base.EXPECT().Ioctl(nil, input, nil, output, nil).Return(go_fuse.Status(syscall.ENOTTY))
+
+		require.Equal(t, go_fuse.Status(syscall.ENOTTY), rfs.Ioctl(nil, input, nil, output, nil))
+	})
+}
@@ -0,0 +1,49 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package fuse
+
+import (
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+type xattrDisablingRawFileSystem struct {
+	fuse.RawFileSystem
+}
+
+// NewXAttrDisablingRawFileSystem creates a decorator for RawFileSystem
+// that causes all operations on extended attributes to fail with
+// ENOSYS, as opposed to forwarding them to the underlying
+// implementation.
+//
+// By returning ENOSYS, the Linux FUSE driver will set
+// fuse_conn::no_getxattr. This will completely eliminate getxattr()
+// calls going forward. More details:
+//
+// https://github.com/torvalds/linux/blob/371e8fd02969383204b1f6023451125dbc20dfbd/fs/fuse/xattr.c#L60-L61
+// https://github.com/torvalds/linux/blob/371e8fd02969383204b1f6023451125dbc20dfbd/fs/fuse/xattr.c#L85-L88
+//
+// As FUSE mounts don't set SB_NOSEC, the kernel would otherwise call
+// getxattr() to obtain "security.capability" prior to every write.
+// Similar logic is used for the other operations.
+func NewXAttrDisablingRawFileSystem(base fuse.RawFileSystem) fuse.RawFileSystem {
+	return &xattrDisablingRawFileSystem{
+		RawFileSystem: base,
+	}
+}
+
+func (rfs *xattrDisablingRawFileSystem) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string, dest []byte) (uint32, fuse.Status) {
+	return 0, fuse.ENOSYS
+}
+
+func (rfs *xattrDisablingRawFileSystem) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []byte) (uint32, fuse.Status) {
+	return 0, fuse.ENOSYS
+}
+
+func (rfs *xattrDisablingRawFileSystem) SetXAttr(cancel <-chan struct{}, input *fuse.SetXAttrIn, attr string, data []byte) fuse.Status {
+	return fuse.ENOSYS
+}
+
+func (rfs *xattrDisablingRawFileSystem) RemoveXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr string) fuse.Status {
+	return fuse.ENOSYS
+}
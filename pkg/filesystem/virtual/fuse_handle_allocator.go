@@ -5,9 +5,32 @@ import (
 	"io"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteoutputservice"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/digest"
 	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
 	"github.com/buildbarn/bb-storage/pkg/random"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	fuseHandleAllocatorPrometheusMetrics sync.Once
+
+	fuseHandleAllocatorWritebackRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "buildbarn",
+			Subsystem: "virtual",
+			Name:      "fuse_handle_allocator_writeback_requests_total",
+			Help:      "Number of times the kernel was requested to write back data of a file that was about to be uploaded while opened for writing.",
+		},
+		[]string{"result"})
+	fuseHandleAllocatorWritebackRequestsSucceeded = fuseHandleAllocatorWritebackRequests.WithLabelValues("Succeeded")
+	fuseHandleAllocatorWritebackRequestsFailed    = fuseHandleAllocatorWritebackRequests.WithLabelValues("Failed")
+	fuseHandleAllocatorWritebackRequestsTimedOut  = fuseHandleAllocatorWritebackRequests.WithLabelValues("TimedOut")
+	fuseHandleAllocatorWritebackRequestsPending   = fuseHandleAllocatorWritebackRequests.WithLabelValues("AlreadyPending")
 )
 
 // fnv1aHasher is a helper type for computing FNV-1a hashes.
@@ -24,7 +47,10 @@ func (w *fnv1aHasher) Write(p []byte) (int, error) {
 }
 
 // FUSERemovalNotifier is a callback method that can be registered to
-// report the removal of files from stateful directories.
+// report the removal of files from stateful directories. It is also
+// called when files are added to stateful directories without the
+// kernel's involvement, as the kernel may have cached attributes of
+// the parent directory that are now stale.
 type FUSERemovalNotifier func(parent uint64, name path.Component)
 
 // FUSERemovalNotifierRegistrar has the same signature as
@@ -32,11 +58,98 @@ type FUSERemovalNotifier func(parent uint64, name path.Component)
 // added to aid testing.
 type FUSERemovalNotifierRegistrar func(removalNotifier FUSERemovalNotifier)
 
+// FUSEWritebackRequester is a callback method that can be registered
+// to request that the kernel writes back any data of a file that is
+// still stored in its page cache. It blocks until writeback has
+// completed, and returns whether it succeeded.
+type FUSEWritebackRequester func(inodeNumber uint64) bool
+
 type fuseHandleOptions struct {
 	randomNumberGenerator random.ThreadSafeGenerator
 
 	removalNotifiersLock sync.RWMutex
 	removalNotifiers     []FUSERemovalNotifier
+
+	writebackLock      sync.RWMutex
+	writebackRequester FUSEWritebackRequester
+	writebackClock     clock.Clock
+	writebackTimeout   time.Duration
+	// Inode numbers of files for which writeback has been
+	// requested, but for which the request has not completed.
+	writebackPending map[uint64]struct{}
+}
+
+// writeBackPendingWrites requests that the kernel writes back data of
+// a file that is opened for writing, so that it is not missed when
+// the file's contents are uploaded. As writeback may block for an
+// arbitrary amount of time (e.g., if the process writing to the file
+// has been frozen), waiting is bounded by a timeout.
+func (o *fuseHandleOptions) writeBackPendingWrites(ctx context.Context, inodeNumber uint64, leaf NativeLeaf) {
+	wbl, ok := getUndecoratedLeaf(leaf).(writeBarrierLeaf)
+	if !ok {
+		return
+	}
+
+	o.writebackLock.RLock()
+	writebackRequester := o.writebackRequester
+	writebackClock := o.writebackClock
+	writebackTimeout := o.writebackTimeout
+	o.writebackLock.RUnlock()
+	if writebackRequester == nil {
+		return
+	}
+
+	// Writes performed after a write barrier was applied may still
+	// reside in the kernel's page cache without the file having
+	// been modified. Discard any existing barrier, so that the
+	// file's digest is only reported if writeback succeeds.
+	if !wbl.revokeWriteBarrier() {
+		return
+	}
+
+	// Requests that time out remain blocked until the kernel
+	// completes writeback, which may never happen if the process
+	// writing to the file has been frozen. Don't issue additional
+	// requests against the same file in the meantime, so that
+	// blocked goroutines don't accumulate.
+	o.writebackLock.Lock()
+	if _, ok := o.writebackPending[inodeNumber]; ok {
+		o.writebackLock.Unlock()
+		fuseHandleAllocatorWritebackRequestsPending.Inc()
+		return
+	}
+	o.writebackPending[inodeNumber] = struct{}{}
+	o.writebackLock.Unlock()
+
+	// Writeback of dirty pages causes the kernel to issue write
+	// operations against the file. Perform the request
+	// asynchronously, so that it may be abandoned.
+	succeeded := make(chan bool, 1)
+	go func() {
+		ok := writebackRequester(inodeNumber)
+		o.writebackLock.Lock()
+		delete(o.writebackPending, inodeNumber)
+		o.writebackLock.Unlock()
+		succeeded <- ok
+	}()
+	timer, t := writebackClock.NewTimer(writebackTimeout)
+	select {
+	case ok := <-succeeded:
+		timer.Stop()
+		if !ok {
+			fuseHandleAllocatorWritebackRequestsFailed.Inc()
+			return
+		}
+		// All data written prior to the request has been
+		// delivered, which is equivalent to the file being
+		// synchronized using fsync().
+		fuseHandleAllocatorWritebackRequestsSucceeded.Inc()
+		ApplyWriteBarrier(leaf)
+	case <-t:
+		fuseHandleAllocatorWritebackRequestsTimedOut.Inc()
+	case <-ctx.Done():
+		timer.Stop()
+	}
 }
 
 // FUSEStatefulHandleAllocator creates a handle allocator for the
@@ -63,9 +176,14 @@ var _ StatefulHandleAllocator = (*FUSEStatefulHandleAllocator)(nil)
 
 // NewFUSEHandleAllocator creates a new FUSEStatefulHandleAllocator.
 func NewFUSEHandleAllocator(randomNumberGenerator random.ThreadSafeGenerator) *FUSEStatefulHandleAllocator {
+	fuseHandleAllocatorPrometheusMetrics.Do(func() {
+		prometheus.MustRegister(fuseHandleAllocatorWritebackRequests)
+	})
+
 	return &FUSEStatefulHandleAllocator{
 		options: &fuseHandleOptions{
 			randomNumberGenerator: randomNumberGenerator,
+			writebackPending:      map[uint64]struct{}{},
 		},
 	}
 }
@@ -85,6 +203,24 @@ func (hr *FUSEStatefulHandleAllocator) RegisterRemovalNotifier(removalNotifier F
 	hr.options.removalNotifiersLock.Unlock()
 }
 
+// SetWritebackRequester sets the callback that is invoked to request
+// that the kernel writes back data of a file, prior to it being
+// uploaded into the Content Addressable Storage while it is still
+// opened for writing. Without it, the upload may be based on stale
+// contents, as the kernel may still hold on to data that is yet to be
+// written to the file.
+//
+// This method is used by the FUSE server to register a callback that
+// sends "inode notify" events to the kernel. Waiting for writeback to
+// complete is bounded by the provided timeout.
+func (hr *FUSEStatefulHandleAllocator) SetWritebackRequester(writebackRequester FUSEWritebackRequester, clock clock.Clock, timeout time.Duration) {
+	hr.options.writebackLock.Lock()
+	hr.options.writebackRequester = writebackRequester
+	hr.options.writebackClock = clock
+	hr.options.writebackTimeout = timeout
+	hr.options.writebackLock.Unlock()
+}
+
 // New creates a new stateful handle allocation.
 func (hr *FUSEStatefulHandleAllocator) New() StatefulHandleAllocation {
 	return &fuseStatefulHandleAllocation{
@@ -133,6 +269,7 @@ func (hn *fuseStatefulHandleAllocation) AsStatelessDirectory(directory Directory
 func (hn *fuseStatefulHandleAllocation) AsNativeLeaf(leaf NativeLeaf) NativeLeaf {
 	l := &fuseStatefulNativeLeaf{
 		NativeLeaf:  leaf,
+		options:     hn.options,
 		inodeNumber: hn.options.randomNumberGenerator.Uint64(),
 	}
 	l.linkCount.Store(1)
@@ -244,6 +381,14 @@ func (dh *fuseStatefulDirectoryHandle) NotifyRemoval(name path.Component) {
 	}
 }
 
+func (dh *fuseStatefulDirectoryHandle) NotifyAddition(name path.Component) {
+	// The FUSE protocol provides no way to announce the creation
+	// of directory entries. Invalidating the name has the desired
+	// effect, as it causes the kernel to discard negative entries
+	// and the cached attributes of the parent directory.
+	dh.NotifyRemoval(name)
+}
+
 func (dh *fuseStatefulDirectoryHandle) Release() {}
 
 // fuseStatelessDirectory is a decorator for stateless Directory objects
@@ -272,13 +417,20 @@ func (d *fuseStatelessDirectory) VirtualSetAttributes(ctx context.Context, in *A
 // fuseStatefulNativeLeaf is a decorator for NativeLeaf that augments
 // the results of VirtualGetAttributes() to contain an inode number and
 // link count. Link() and Unlink() calls are intercepted, and are only
-// forwarded if the link count drops to zero.
+// forwarded if the link count drops to zero. Prior to uploading, the
+// kernel is requested to write back any data of the file it still
+// holds.
 type fuseStatefulNativeLeaf struct {
 	NativeLeaf
+	options     *fuseHandleOptions
 	inodeNumber uint64
 	linkCount   atomic.Uint32
 }
 
+func (l *fuseStatefulNativeLeaf) unwrapLeaf() Leaf {
+	return l.NativeLeaf
+}
+
 func (l *fuseStatefulNativeLeaf) Link() Status {
 	for {
 		current := l.linkCount.Load()
@@ -298,6 +450,20 @@ func (l *fuseStatefulNativeLeaf) Unlink() {
 	}
 }
 
+func (l *fuseStatefulNativeLeaf) UploadFile(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function) (digest.Digest, error) {
+	l.options.writeBackPendingWrites(ctx, l.inodeNumber, l.NativeLeaf)
+	return l.NativeLeaf.UploadFile(ctx, contentAddressableStorage, digestFunction)
+}
+
+func (l *fuseStatefulNativeLeaf) GetOutputServiceFileStatus(digestFunction *digest.Function) (*remoteoutputservice.FileStatus, error) {
+	// Only request writeback if the digest of the file is going
+	// to be reported.
+	if digestFunction != nil {
+		l.options.writeBackPendingWrites(context.Background(), l.inodeNumber, l.NativeLeaf)
+	}
+	return l.NativeLeaf.GetOutputServiceFileStatus(digestFunction)
+}
+
 func (l *fuseStatefulNativeLeaf) injectAttributes(attributes *Attributes) {
 	attributes.SetInodeNumber(l.inodeNumber)
 	attributes.SetLinkCount(l.linkCount.Load())
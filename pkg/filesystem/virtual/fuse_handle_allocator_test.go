@@ -3,11 +3,19 @@ package virtual_test
 import (
 	"bytes"
 	"context"
+	"io"
 	"testing"
+	"time"
 
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
 	"github.com/buildbarn/bb-remote-execution/internal/mock"
 	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteoutputservice"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/digest"
 	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
@@ -44,6 +52,12 @@ func TestFUSEHandleAllocator(t *testing.T) {
 		removalNotifier.EXPECT().Call(uint64(0xfccd1fc99a8c3425), path.MustNewComponent("output.o"))
 		directoryHandle.NotifyRemoval(path.MustNewComponent("output.o"))
 
+		// Addition notifications should be forwarded as well,
+		// so that stale entries in the kernel's cache are
+		// discarded.
+		removalNotifier.EXPECT().Call(uint64(0xfccd1fc99a8c3425), path.MustNewComponent("output.d"))
+		directoryHandle.NotifyAddition(path.MustNewComponent("output.d"))
+
 		directoryHandle.Release()
 	})
 
@@ -200,3 +214,153 @@ func TestFUSEHandleAllocator(t *testing.T) {
 			&attr3)
 	})
 }
+
+func TestFUSEHandleAllocatorWriteback(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	randomNumberGenerator := mock.NewMockThreadSafeGenerator(ctrl)
+	handleAllocator := virtual.NewFUSEHandleAllocator(randomNumberGenerator)
+	writebackRequester := mock.NewMockFUSEWritebackRequester(ctrl)
+	writebackClock := mock.NewMockClock(ctrl)
+	handleAllocator.SetWritebackRequester(writebackRequester.Call, writebackClock, 10*time.Second)
+
+	// Create a file that is opened for writing, and wrap it.
+	pool := mock.NewMockFilePool(ctrl)
+	underlyingFile := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile, nil)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
+		NewFile(false, 0, virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+
+	underlyingFile.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+	n, s := f.VirtualWrite([]byte("Hello"), 0)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 5, n)
+
+	randomNumberGenerator.EXPECT().Uint64().Return(uint64(0x5ad6b4e8c5c1ed8c))
+	wrappedLeaf := handleAllocator.New().AsNativeLeaf(f)
+
+	fileDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	digestFunction := fileDigest.GetDigestFunction()
+	fileStatusWithoutDigest := &remoteoutputservice.FileStatus{
+		FileType: &remoteoutputservice.FileStatus_File_{
+			File: &remoteoutputservice.FileStatus_File{},
+		},
+	}
+	fileStatusWithDigest := &remoteoutputservice.FileStatus{
+		FileType: &remoteoutputservice.FileStatus_File_{
+			File: &remoteoutputservice.FileStatus_File{
+				Digest: fileDigest.GetProto(),
+			},
+		},
+	}
+
+	t.Run("Succeeded", func(t *testing.T) {
+		// Successful writeback should be treated as if a write
+		// barrier was applied, causing the digest of the file
+		// to be reported.
+		writebackRequester.EXPECT().Call(uint64(0x5ad6b4e8c5c1ed8c)).Return(true)
+		timer := mock.NewMockTimer(ctrl)
+		writebackClock.EXPECT().NewTimer(10*time.Second).Return(timer, nil)
+		timer.EXPECT().Stop()
+
+		underlyingFile.EXPECT().ReadAt(gomock.Any(), int64(0)).DoAndReturn(func(p []byte, off int64) (int, error) {
+			return copy(p, "Hello"), io.EOF
+		})
+		contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+		contentAddressableStorage.EXPECT().Put(ctx, fileDigest, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				b.Discard()
+				return nil
+			})
+
+		blobDigest, err := wrappedLeaf.UploadFile(ctx, contentAddressableStorage, digestFunction)
+		require.NoError(t, err)
+		require.Equal(t, fileDigest, blobDigest)
+
+		writebackRequester.EXPECT().Call(uint64(0x5ad6b4e8c5c1ed8c)).Return(true)
+		timer = mock.NewMockTimer(ctrl)
+		writebackClock.EXPECT().NewTimer(10*time.Second).Return(timer, nil)
+		timer.EXPECT().Stop()
+
+		fileStatus, err := wrappedLeaf.GetOutputServiceFileStatus(&digestFunction)
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, fileStatusWithDigest, fileStatus)
+	})
+
+	t.Run("StaleWriteBarrier", func(t *testing.T) {
+		// With a writeback cache, data written after the
+		// previous writeback may reside in the kernel's page
+		// cache without the file having been modified. If
+		// writeback fails, the write barrier that was applied
+		// previously may thus no longer be relied upon.
+		writebackRequester.EXPECT().Call(uint64(0x5ad6b4e8c5c1ed8c)).Return(false)
+		timer := mock.NewMockTimer(ctrl)
+		writebackClock.EXPECT().NewTimer(10*time.Second).Return(timer, nil)
+		timer.EXPECT().Stop()
+
+		fileStatus, err := wrappedLeaf.GetOutputServiceFileStatus(&digestFunction)
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, fileStatusWithoutDigest, fileStatus)
+	})
+
+	t.Run("TimedOut", func(t *testing.T) {
+		// If the kernel does not complete writeback in time,
+		// the file should be uploaded regardless.
+		called := make(chan struct{})
+		release := make(chan struct{})
+		writebackRequester.EXPECT().Call(uint64(0x5ad6b4e8c5c1ed8c)).DoAndReturn(func(inodeNumber uint64) bool {
+			close(called)
+			<-release
+			return true
+		})
+		timer := mock.NewMockTimer(ctrl)
+		timerChannel := make(chan time.Time, 1)
+		timerChannel <- time.Unix(1000, 0)
+		writebackClock.EXPECT().NewTimer(10*time.Second).Return(timer, timerChannel)
+
+		contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+		contentAddressableStorage.EXPECT().Put(ctx, fileDigest, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				b.Discard()
+				return nil
+			})
+
+		blobDigest, err := wrappedLeaf.UploadFile(ctx, contentAddressableStorage, digestFunction)
+		require.NoError(t, err)
+		require.Equal(t, fileDigest, blobDigest)
+		<-called
+
+		// As the previous request is still blocked, no further
+		// requests should be issued against the same file.
+		// The digest of the file should not be reported, as
+		// writeback did not complete.
+		fileStatus, err := wrappedLeaf.GetOutputServiceFileStatus(&digestFunction)
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, fileStatusWithoutDigest, fileStatus)
+
+		close(release)
+	})
+
+	t.Run("NoWritableDescriptors", func(t *testing.T) {
+		// Once the file is no longer opened for writing, there
+		// is no need to request writeback.
+		f.VirtualClose(virtual.ShareMaskWrite)
+
+		contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+		contentAddressableStorage.EXPECT().Put(ctx, fileDigest, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				b.Discard()
+				return nil
+			})
+
+		blobDigest, err := wrappedLeaf.UploadFile(ctx, contentAddressableStorage, digestFunction)
+		require.NoError(t, err)
+		require.Equal(t, fileDigest, blobDigest)
+	})
+
+	underlyingFile.EXPECT().Close()
+	wrappedLeaf.Unlink()
+}
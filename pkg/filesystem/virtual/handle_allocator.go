@@ -32,8 +32,13 @@ type StatefulHandleAllocation interface {
 
 // StatefulDirectoryHandle is a handle that needs to be embedded into
 // stateful directories. It can be used to report mutations to the
-// directory through NotifyRemoval(), or report deletion through
-// Release().
+// directory through NotifyRemoval() and NotifyAddition(), or report
+// deletion through Release().
+//
+// NotifyAddition() only needs to be called for children that are
+// added by means other than the virtual file system (e.g., by
+// PrepopulatedDirectory.CreateChildren()), as the kernel is already
+// aware of changes made through the virtual file system.
 //
 // The directory type that embeds the handle must call GetAttributes()
 // as part of Directory.VirtualGetAttributes() to augment the attributes
@@ -41,6 +46,7 @@ type StatefulHandleAllocation interface {
 type StatefulDirectoryHandle interface {
 	GetAttributes(requested AttributesMask, attributes *Attributes)
 	NotifyRemoval(name path.Component)
+	NotifyAddition(name path.Component)
 	Release()
 }
 
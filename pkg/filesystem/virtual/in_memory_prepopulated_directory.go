@@ -4,15 +4,19 @@ import (
 	"context"
 	"fmt"
 	"sort"
-	"sync"
 	"syscall"
 	"time"
 
 	re_sync "github.com/buildbarn/bb-remote-execution/pkg/sync"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
 	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/digest"
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
 	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // StringMatcher is a function type that has the same signature as
@@ -29,6 +33,14 @@ type inMemoryFilesystem struct {
 	initialContentsSorter   Sorter
 	hiddenFilesMatcher      StringMatcher
 	clock                   clock.Clock
+	caseInsensitive         bool
+
+	// Lock that needs to be held when renaming files across
+	// directories. This permits VirtualRename() to inspect the
+	// ancestry of directories to prevent the creation of cycles,
+	// without holding the locks of all directories involved. It
+	// must be acquired before locking any of the directories.
+	renameLock re_sync.Mutex
 }
 
 // inMemorySubtree contains state that is shared across all
@@ -39,17 +51,26 @@ type inMemoryFilesystem struct {
 // permits us to apply per-action disk quotas. It may also have its own
 // error logger, which allows us to notify LocalBuildExecutor of disk
 // I/O errors.
+//
+// Snapshots are tracked per subtree, so that creating a snapshot of
+// the outputs of one action doesn't cause files of other actions to be
+// retained when modified.
 type inMemorySubtree struct {
-	filesystem    *inMemoryFilesystem
-	fileAllocator FileAllocator
-	errorLogger   util.ErrorLogger
+	filesystem     *inMemoryFilesystem
+	fileAllocator  FileAllocator
+	errorLogger    util.ErrorLogger
+	statFSProvider StatFSProvider
+	snapshotEpochs *snapshotEpochs
 }
 
-func (s *inMemorySubtree) createNewDirectory(initialContentsFetcher InitialContentsFetcher) *inMemoryPrepopulatedDirectory {
+func (s *inMemorySubtree) createNewDirectory(parent *inMemoryPrepopulatedDirectory, initialContentsFetcher InitialContentsFetcher) *inMemoryPrepopulatedDirectory {
 	d := &inMemoryPrepopulatedDirectory{
 		subtree:                s,
+		parent:                 parent,
+		lock:                   re_sync.Mutex{Rank: &directoryLockRank},
 		initialContentsFetcher: initialContentsFetcher,
 		contents: inMemoryDirectoryContents{
+			caseInsensitive:          s.filesystem.caseInsensitive,
 			lastDataModificationTime: s.filesystem.clock.Now(),
 		},
 	}
@@ -71,6 +92,17 @@ type inMemoryDirectoryEntry struct {
 	name     path.Component
 	previous *inMemoryDirectoryEntry
 	next     *inMemoryDirectoryEntry
+
+	// Names that only differ in case from the name of the entry,
+	// under which the kernel looked up the entry. The kernel caches
+	// directory entries for each of these names separately.
+	aliases []path.Component
+}
+
+// getNames returns all names under which the kernel may have cached
+// the entry.
+func (e *inMemoryDirectoryEntry) getNames() []path.Component {
+	return append([]path.Component{e.name}, e.aliases...)
 }
 
 // inMemoryDirectoryContents contains the listing of all children stored
@@ -78,9 +110,20 @@ type inMemoryDirectoryEntry struct {
 // and a list. The latter is needed for readdir() to behave
 // deterministically. The isDeleted flag may be set when empty and no
 // new children may be added.
+//
+// If caseInsensitive is set, the map is keyed by the case folded name
+// of the entry, while the entry itself stores the name as provided.
+//
+// readDirPosition points to the last entry reported by the most recent
+// call to VirtualReadDir() that did not reach the end of the
+// directory. It allows successive calls that page through large
+// directories to resume iteration without rescanning the list from
+// the start.
 type inMemoryDirectoryContents struct {
+	caseInsensitive          bool
 	entriesMap               map[path.Component]*inMemoryDirectoryEntry
 	entriesList              inMemoryDirectoryEntry
+	readDirPosition          *inMemoryDirectoryEntry
 	isDeleted                bool
 	changeID                 uint64
 	lastDataModificationTime time.Time
@@ -91,10 +134,50 @@ func (c *inMemoryDirectoryContents) initialize() {
 	c.entriesMap = map[path.Component]*inMemoryDirectoryEntry{}
 	c.entriesList.previous = &c.entriesList
 	c.entriesList.next = &c.entriesList
+	c.readDirPosition = nil
+}
+
+// key returns the name under which an entry is stored in entriesMap.
+func (c *inMemoryDirectoryContents) key(name path.Component) path.Component {
+	if c.caseInsensitive {
+		return foldCase(name)
+	}
+	return name
+}
+
+// lookup an entry in the directory contents by name.
+func (c *inMemoryDirectoryContents) lookup(name path.Component) (*inMemoryDirectoryEntry, bool) {
+	entry, ok := c.entriesMap[c.key(name)]
+	return entry, ok
+}
+
+// addAlias records that the kernel looked up an entry under a given
+// name. If lookups are case-insensitive and the name differs from the
+// name of the entry, the name is stored as an alias, so that the
+// kernel's directory entry for it can be invalidated when the entry is
+// removed.
+func (e *inMemoryDirectoryEntry) addAlias(name path.Component) {
+	if name == e.name {
+		return
+	}
+	for _, alias := range e.aliases {
+		if alias == name {
+			return
+		}
+	}
+	e.aliases = append(e.aliases, name)
 }
 
 // attach an existing directory or leaf to the directory contents.
-func (c *inMemoryDirectoryContents) attach(subtree *inMemorySubtree, name path.Component, child inMemoryDirectoryChild) {
+func (c *inMemoryDirectoryContents) attach(directory *inMemoryPrepopulatedDirectory, name path.Component, child inMemoryDirectoryChild) {
+	directory.preserveSnapshotVersion()
+	c.insert(directory.subtree, name, child)
+}
+
+// insert a directory or leaf into the directory contents, without
+// retaining the existing contents on behalf of snapshots. This is used
+// while the directory is being initialized.
+func (c *inMemoryDirectoryContents) insert(subtree *inMemorySubtree, name path.Component, child inMemoryDirectoryChild) {
 	if err := c.mayAttach(name); err != 0 {
 		panic(fmt.Sprintf("Directory %#v may not be attached: %s", name, err))
 	}
@@ -106,38 +189,48 @@ func (c *inMemoryDirectoryContents) attach(subtree *inMemorySubtree, name path.C
 		previous: c.entriesList.previous,
 		next:     &c.entriesList,
 	}
-	c.entriesMap[name] = entry
+	c.entriesMap[c.key(name)] = entry
 	entry.previous.next = entry
 	entry.next.previous = entry
 	c.touch(subtree)
+
+	if _, leaf := child.GetPair(); leaf != nil {
+		if versionedLeaf, ok := getUndecoratedLeaf(leaf).(snapshotVersionedLeaf); ok {
+			versionedLeaf.setSnapshotEpochs(subtree.snapshotEpochs)
+		}
+	}
 }
 
 // attachDirectory adds a new directory to the directory contents. The
 // initial contents of this new directory may be specified in the form
 // of an InitialContentsFetcher, which gets evaluated lazily.
-func (c *inMemoryDirectoryContents) attachNewDirectory(subtree *inMemorySubtree, name path.Component, initialContentsFetcher InitialContentsFetcher) *inMemoryPrepopulatedDirectory {
-	newDirectory := subtree.createNewDirectory(initialContentsFetcher)
-	c.attach(subtree, name, inMemoryDirectoryChild{}.FromDirectory(newDirectory))
+func (c *inMemoryDirectoryContents) attachNewDirectory(parent *inMemoryPrepopulatedDirectory, name path.Component, initialContentsFetcher InitialContentsFetcher) *inMemoryPrepopulatedDirectory {
+	newDirectory := parent.subtree.createNewDirectory(parent, initialContentsFetcher)
+	c.attach(parent, name, inMemoryDirectoryChild{}.FromDirectory(newDirectory))
 	return newDirectory
 }
 
 // Detach the entry from the directory. Clear the entry to prevent
 // foot-shooting. This allows VirtualReadDir() to detect that iteration
 // was interrupted.
-func (c *inMemoryDirectoryContents) detach(subtree *inMemorySubtree, entry *inMemoryDirectoryEntry) {
-	delete(c.entriesMap, entry.name)
+func (c *inMemoryDirectoryContents) detach(directory *inMemoryPrepopulatedDirectory, entry *inMemoryDirectoryEntry) {
+	directory.preserveSnapshotVersion()
+	if c.readDirPosition == entry {
+		c.readDirPosition = nil
+	}
+	delete(c.entriesMap, c.key(entry.name))
 	entry.previous.next = entry.next
 	entry.next.previous = entry.previous
 	entry.previous = nil
 	entry.next = nil
-	c.touch(subtree)
+	c.touch(directory.subtree)
 }
 
 func (c *inMemoryDirectoryContents) mayAttach(name path.Component) syscall.Errno {
 	if c.isDeleted {
 		return syscall.ENOENT
 	}
-	if _, ok := c.entriesMap[name]; ok {
+	if _, ok := c.lookup(name); ok {
 		return syscall.EEXIST
 	}
 	return 0
@@ -147,7 +240,7 @@ func (c *inMemoryDirectoryContents) virtualMayAttach(name path.Component) Status
 	if c.isDeleted {
 		return StatusErrNoEnt
 	}
-	if _, ok := c.entriesMap[name]; ok {
+	if _, ok := c.lookup(name); ok {
 		return StatusErrExist
 	}
 	return StatusOK
@@ -167,7 +260,24 @@ func (c *inMemoryDirectoryContents) isDeletable(hiddenFilesMatcher StringMatcher
 	return true
 }
 
-func (c *inMemoryDirectoryContents) createChildren(subtree *inMemorySubtree, children map[path.Component]InitialNode) {
+// checkCaseCollisions returns an error if the directory performs
+// case-insensitive lookups, and a set of children that is about to be
+// created contains names that only differ in case.
+func (c *inMemoryDirectoryContents) checkCaseCollisions(children map[path.Component]InitialNode) error {
+	if !c.caseInsensitive {
+		return nil
+	}
+	names := make(path.ComponentsList, 0, len(children))
+	for name := range children {
+		names = append(names, name)
+	}
+	return checkCaseCollisions(names)
+}
+
+// createChildren adds a set of children to the directory contents.
+// Callers that modify a directory that has already been initialized
+// need to call preserveSnapshotVersion() first.
+func (c *inMemoryDirectoryContents) createChildren(parent *inMemoryPrepopulatedDirectory, children map[path.Component]InitialNode) {
 	// Either sort or shuffle the children before inserting them
 	// into the directory. This either makes VirtualReadDir() behave
 	// deterministically, or not, based on preference.
@@ -175,19 +285,26 @@ func (c *inMemoryDirectoryContents) createChildren(subtree *inMemorySubtree, chi
 	for name := range children {
 		namesList = append(namesList, name)
 	}
-	subtree.filesystem.initialContentsSorter(namesList)
+	parent.subtree.filesystem.initialContentsSorter(namesList)
 
 	for _, name := range namesList {
 		if directory, leaf := children[name].GetPair(); directory != nil {
-			c.attachNewDirectory(subtree, name, directory)
+			newDirectory := parent.subtree.createNewDirectory(parent, directory)
+			c.insert(parent.subtree, name, inMemoryDirectoryChild{}.FromDirectory(newDirectory))
 		} else {
-			c.attach(subtree, name, inMemoryDirectoryChild{}.FromLeaf(leaf))
+			c.insert(parent.subtree, name, inMemoryDirectoryChild{}.FromLeaf(leaf))
 		}
 	}
 }
 
 func (c *inMemoryDirectoryContents) getEntryAtCookie(firstCookie uint64) *inMemoryDirectoryEntry {
+	// Entries are stored in the list in increasing cookie order.
+	// If the previous call to VirtualReadDir() stopped at an entry
+	// preceding the requested cookie, start seeking from there.
 	entry := c.entriesList.next
+	if position := c.readDirPosition; position != nil && position.cookie < firstCookie {
+		entry = position
+	}
 	for {
 		if entry == &c.entriesList || entry.cookie >= firstCookie {
 			return entry
@@ -196,6 +313,16 @@ func (c *inMemoryDirectoryContents) getEntryAtCookie(firstCookie uint64) *inMemo
 	}
 }
 
+// setReadDirPosition records the last entry that was reported by
+// VirtualReadDir(), so that the next call can resume from there. The
+// entry is ignored if it has been detached in the meantime, which may
+// happen if the lock on the directory was briefly dropped.
+func (c *inMemoryDirectoryContents) setReadDirPosition(entry *inMemoryDirectoryEntry) {
+	if entry != nil && entry.next != nil {
+		c.readDirPosition = entry
+	}
+}
+
 // getAndLockIfDirectory obtains a child from the current directory, and
 // immediately locks it if it is a directory. To prevent possible
 // deadlocks, we must respect the lock order. This may require this
@@ -203,7 +330,7 @@ func (c *inMemoryDirectoryContents) getEntryAtCookie(firstCookie uint64) *inMemo
 // the lock of the child directory.
 func (c *inMemoryDirectoryContents) getAndLockIfDirectory(name path.Component, lockPile *re_sync.LockPile) (*inMemoryDirectoryEntry, bool) {
 	for {
-		entry, ok := c.entriesMap[name]
+		entry, ok := c.lookup(name)
 		if !ok {
 			// No child node present.
 			return nil, false
@@ -219,7 +346,7 @@ func (c *inMemoryDirectoryContents) getAndLockIfDirectory(name path.Component, l
 			// dropping any of the existing locks.
 			return entry, true
 		}
-		if c.entriesMap[name] == entry {
+		if currentEntry, _ := c.lookup(name); currentEntry == entry {
 			// Even though we dropped locks, no race occurred.
 			return entry, true
 		}
@@ -238,6 +365,70 @@ func (c *inMemoryDirectoryContents) getDirectoriesAndLeavesCount(hiddenFilesMatc
 	return
 }
 
+// inMemoryDirectoryVersion contains the contents of an
+// inMemoryPrepopulatedDirectory at the time a snapshot was created. It
+// holds a reference to each of the leaves contained in it. Hidden files
+// are omitted.
+type inMemoryDirectoryVersion struct {
+	err         error
+	directories []inMemoryDirectoryVersionEntry
+	leaves      []LeafPrepopulatedDirEntry
+}
+
+type inMemoryDirectoryVersionEntry struct {
+	child *inMemoryPrepopulatedDirectory
+	name  path.Component
+}
+
+// newSnapshotVersion captures the current contents of the directory.
+func (c *inMemoryDirectoryContents) newSnapshotVersion(hiddenFilesMatcher StringMatcher) *inMemoryDirectoryVersion {
+	directoriesCount, leavesCount := c.getDirectoriesAndLeavesCount(hiddenFilesMatcher)
+	version := &inMemoryDirectoryVersion{
+		directories: make([]inMemoryDirectoryVersionEntry, 0, directoriesCount),
+		leaves:      make([]LeafPrepopulatedDirEntry, 0, leavesCount),
+	}
+	for entry := c.entriesList.next; entry != &c.entriesList; entry = entry.next {
+		if directory, leaf := entry.child.GetPair(); directory != nil {
+			version.directories = append(version.directories, inMemoryDirectoryVersionEntry{
+				child: directory,
+				name:  entry.name,
+			})
+		} else if !hiddenFilesMatcher(entry.name.String()) {
+			// Leaves stored in the directory hold a
+			// reference, meaning this cannot fail.
+			if leaf.Link() != StatusOK {
+				panic("Failed to obtain a reference to a leaf that is stored in a directory")
+			}
+			version.leaves = append(version.leaves, LeafPrepopulatedDirEntry{
+				Child: leaf,
+				Name:  entry.name,
+			})
+		}
+	}
+	return version
+}
+
+// link returns a copy of the version of the directory that holds its
+// own references to the leaves contained in it.
+func (v *inMemoryDirectoryVersion) link() *inMemoryDirectoryVersion {
+	for _, entry := range v.leaves {
+		if entry.Child.Link() != StatusOK {
+			panic("Failed to obtain a reference to a leaf that is retained on behalf of a snapshot")
+		}
+	}
+	return &inMemoryDirectoryVersion{
+		directories: append([]inMemoryDirectoryVersionEntry(nil), v.directories...),
+		leaves:      append([]LeafPrepopulatedDirEntry(nil), v.leaves...),
+	}
+}
+
+// release the references to all leaves held by the version.
+func (v *inMemoryDirectoryVersion) release() {
+	for _, entry := range v.leaves {
+		entry.Child.Unlink()
+	}
+}
+
 // inMemoryPrepopulatedDirectory is an implementation of PrepopulatedDirectory that
 // keeps all directory metadata stored in memory. Actual file data and
 // metadata is not managed by this implementation. Files are allocated
@@ -254,16 +445,34 @@ type inMemoryPrepopulatedDirectory struct {
 	subtree *inMemorySubtree
 	handle  StatefulDirectoryHandle
 
-	lock                   sync.Mutex
+	// The directory in which this directory is stored. This field
+	// may only be modified while holding the file system's rename
+	// lock. It is not cleared upon removal.
+	parent *inMemoryPrepopulatedDirectory
+
+	lock                   re_sync.Mutex
 	initialContentsFetcher InitialContentsFetcher
 	contents               inMemoryDirectoryContents
+	extendedAttributes     extendedAttributes
+
+	// The snapshot epoch at the time the current operation
+	// obtained the contents of the directory, and older versions
+	// of the contents that are still used by snapshots.
+	snapshotEpoch    uint64
+	snapshotVersions snapshotVersions[*inMemoryDirectoryVersion]
 }
 
 // NewInMemoryPrepopulatedDirectory creates a new PrepopulatedDirectory
 // that keeps all directory metadata stored in memory. As the filesystem
 // API does not allow traversing the hierarchy upwards, this directory
 // can be considered the root directory of the hierarchy.
-func NewInMemoryPrepopulatedDirectory(fileAllocator FileAllocator, symlinkFactory SymlinkFactory, errorLogger util.ErrorLogger, handleAllocator StatefulHandleAllocator, initialContentsSorter Sorter, hiddenFilesMatcher StringMatcher, clock clock.Clock) PrepopulatedDirectory {
+//
+// If caseInsensitive is set, lookups in all directories in the
+// hierarchy are case-insensitive, while the case of names of newly
+// created files is preserved. This matches the behavior of the default
+// file systems on macOS and Windows. Attempts to populate directories
+// with children whose names only differ in case fail.
+func NewInMemoryPrepopulatedDirectory(fileAllocator FileAllocator, symlinkFactory SymlinkFactory, errorLogger util.ErrorLogger, handleAllocator StatefulHandleAllocator, initialContentsSorter Sorter, hiddenFilesMatcher StringMatcher, clock clock.Clock, caseInsensitive bool) PrepopulatedDirectory {
 	subtree := &inMemorySubtree{
 		filesystem: &inMemoryFilesystem{
 			symlinkFactory:          symlinkFactory,
@@ -271,27 +480,63 @@ func NewInMemoryPrepopulatedDirectory(fileAllocator FileAllocator, symlinkFactor
 			initialContentsSorter:   initialContentsSorter,
 			hiddenFilesMatcher:      hiddenFilesMatcher,
 			clock:                   clock,
+			caseInsensitive:         caseInsensitive,
+			renameLock:              re_sync.Mutex{Rank: &renameLockRank},
 		},
-		fileAllocator: fileAllocator,
-		errorLogger:   errorLogger,
+		fileAllocator:  fileAllocator,
+		errorLogger:    errorLogger,
+		snapshotEpochs: newSnapshotEpochs(),
 	}
-	return subtree.createNewDirectory(EmptyInitialContentsFetcher)
+	return subtree.createNewDirectory(nil, EmptyInitialContentsFetcher)
 }
 
 // Initialize the directory with the intended contents if not done so
 // already. This function is used by inMemoryPrepopulatedDirectory's operations
 // to gain access to the directory's contents.
+//
+// Changes made by the operation are attributed to the snapshot epoch
+// at the time this function is called. This ensures that snapshots
+// never observe the effects of an operation partially.
 func (i *inMemoryPrepopulatedDirectory) getContents() (*inMemoryDirectoryContents, error) {
+	i.snapshotEpoch = i.subtree.snapshotEpochs.current.Load()
+	if err := i.initializeContents(); err != nil {
+		return nil, err
+	}
+	return &i.contents, nil
+}
+
+func (i *inMemoryPrepopulatedDirectory) initializeContents() error {
 	if i.initialContentsFetcher != nil {
 		children, err := i.initialContentsFetcher.FetchContents(func(name path.Component) FileReadMonitor { return nil })
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err := i.contents.checkCaseCollisions(children); err != nil {
+			for _, child := range children {
+				if _, leaf := child.GetPair(); leaf != nil {
+					leaf.Unlink()
+				}
+			}
+			return err
 		}
 		i.initialContentsFetcher = nil
 		i.contents.initialize()
-		i.contents.createChildren(i.subtree, children)
+		i.contents.createChildren(i, children)
 	}
-	return &i.contents, nil
+	return nil
+}
+
+// isAncestorOf returns true if this directory is equal to or an
+// ancestor of another directory. This function may only be called
+// while holding the file system's rename lock, as that prevents
+// directories from being moved elsewhere in the hierarchy.
+func (i *inMemoryPrepopulatedDirectory) isAncestorOf(d *inMemoryPrepopulatedDirectory) bool {
+	for ; d != nil; d = d.parent {
+		if d == i {
+			return true
+		}
+	}
+	return false
 }
 
 func (i *inMemoryPrepopulatedDirectory) markDeleted() {
@@ -309,7 +554,7 @@ func (i *inMemoryPrepopulatedDirectory) markDeleted() {
 		// removed entirely?
 		for i.contents.entriesList.next != &i.contents.entriesList {
 			entry := i.contents.entriesList.next
-			i.contents.detach(i.subtree, entry)
+			i.contents.detach(i, entry)
 			_, leaf := entry.child.GetPair()
 			leaf.Unlink()
 		}
@@ -328,7 +573,7 @@ func (i *inMemoryPrepopulatedDirectory) LookupChild(name path.Component) (Prepop
 		return PrepopulatedDirectoryChild{}, err
 	}
 
-	if entry, ok := contents.entriesMap[name]; ok {
+	if entry, ok := contents.lookup(name); ok {
 		child := &entry.child
 		directory, leaf := child.GetPair()
 		if directory != nil {
@@ -403,7 +648,8 @@ func (i *inMemoryPrepopulatedDirectory) Remove(name path.Component) error {
 	}
 
 	if entry, ok := contents.getAndLockIfDirectory(name, &lockPile); ok {
-		if directory, leaf := entry.child.GetPair(); directory != nil {
+		directory, leaf := entry.child.GetPair()
+		if directory != nil {
 			// The directory has a child directory under
 			// that name. Perform an rmdir().
 			childContents, err := directory.getContents()
@@ -414,14 +660,17 @@ func (i *inMemoryPrepopulatedDirectory) Remove(name path.Component) error {
 				return syscall.ENOTEMPTY
 			}
 			directory.markDeleted()
-		} else {
+		}
+		contents.detach(i, entry)
+		if leaf != nil {
 			// The directory has a child file/symlink under
-			// that name. Perform an unlink().
+			// that name. Perform an unlink(). This is done
+			// after detaching, so that the leaf can still
+			// be retained on behalf of snapshots.
 			leaf.Unlink()
 		}
-		contents.detach(i.subtree, entry)
 		lockPile.UnlockAll()
-		i.handle.NotifyRemoval(name)
+		i.notifyRemoval(entry)
 		return nil
 	}
 
@@ -437,10 +686,10 @@ func (i *inMemoryPrepopulatedDirectory) RemoveAll(name path.Component) error {
 		return err
 	}
 
-	if entry, ok := contents.entriesMap[name]; ok {
-		contents.detach(i.subtree, entry)
+	if entry, ok := contents.lookup(name); ok {
+		contents.detach(i, entry)
 		i.lock.Unlock()
-		i.handle.NotifyRemoval(name)
+		i.notifyRemoval(entry)
 		if directory, leaf := entry.child.GetPair(); directory != nil {
 			// The directory has a child directory under
 			// that name. Perform a recursive removal.
@@ -464,6 +713,8 @@ func (i *inMemoryPrepopulatedDirectory) RemoveAllChildren(deleteSelf bool) error
 
 func (i *inMemoryPrepopulatedDirectory) removeAllChildren(deleteSelf bool) {
 	i.lock.Lock()
+	i.snapshotEpoch = i.subtree.snapshotEpochs.current.Load()
+	i.preserveSnapshotVersion()
 	if i.initialContentsFetcher != nil {
 		// The directory has not been initialized. Instead of
 		// initializing it as intended and removing all
@@ -480,7 +731,7 @@ func (i *inMemoryPrepopulatedDirectory) removeAllChildren(deleteSelf bool) {
 		var entries *inMemoryDirectoryEntry
 		for i.contents.entriesList.next != &i.contents.entriesList {
 			entry := i.contents.entriesList.next
-			i.contents.detach(i.subtree, entry)
+			i.contents.detach(i, entry)
 			entry.previous = entries
 			entries = entry
 		}
@@ -493,13 +744,46 @@ func (i *inMemoryPrepopulatedDirectory) removeAllChildren(deleteSelf bool) {
 	}
 }
 
+// notifyRemoval notifies the kernel that an entry that has been
+// detached from the directory is no longer accessible under any of the
+// names under which it may have been cached. This method must be
+// called without holding any locks.
+func (i *inMemoryPrepopulatedDirectory) notifyRemoval(entry *inMemoryDirectoryEntry) {
+	for _, name := range entry.getNames() {
+		i.handle.NotifyRemoval(name)
+	}
+}
+
+// notifyAliasRemoval is called by operations initiated by the kernel
+// that detach an entry from the directory. The kernel only updates its
+// directory entry for the name it used, meaning that directory entries
+// for other names that only differ in case need to be invalidated
+// explicitly. As the kernel holds locks on the directory while the
+// operation is in progress, notifications are sent asynchronously.
+func (i *inMemoryPrepopulatedDirectory) notifyAliasRemoval(entry *inMemoryDirectoryEntry, usedName path.Component) {
+	if len(entry.aliases) == 0 {
+		return
+	}
+	var names []path.Component
+	for _, name := range entry.getNames() {
+		if name != usedName {
+			names = append(names, name)
+		}
+	}
+	go func() {
+		for _, name := range names {
+			i.handle.NotifyRemoval(name)
+		}
+	}()
+}
+
 // postRemoveChildren is called after bulk unlinking files and
 // directories and dropping the parent directory lock. It invalidates
 // all entries in the FUSE directory entry cache and recursively removes
 // all files.
 func (i *inMemoryPrepopulatedDirectory) postRemoveChildren(entries *inMemoryDirectoryEntry) {
 	for entry := entries; entry != nil; entry = entry.previous {
-		i.handle.NotifyRemoval(entry.name)
+		i.notifyRemoval(entry)
 		if directory, leaf := entry.child.GetPair(); directory != nil {
 			directory.removeAllChildren(true)
 		} else {
@@ -508,17 +792,26 @@ func (i *inMemoryPrepopulatedDirectory) postRemoveChildren(entries *inMemoryDire
 	}
 }
 
-func (i *inMemoryPrepopulatedDirectory) InstallHooks(fileAllocator FileAllocator, errorLogger util.ErrorLogger) {
+func (i *inMemoryPrepopulatedDirectory) InstallHooks(fileAllocator FileAllocator, errorLogger util.ErrorLogger, statFSProvider StatFSProvider) {
 	i.lock.Lock()
 	defer i.lock.Unlock()
 
 	i.subtree = &inMemorySubtree{
-		filesystem:    i.subtree.filesystem,
-		fileAllocator: fileAllocator,
-		errorLogger:   errorLogger,
+		filesystem:     i.subtree.filesystem,
+		fileAllocator:  fileAllocator,
+		errorLogger:    errorLogger,
+		statFSProvider: statFSProvider,
+		snapshotEpochs: newSnapshotEpochs(),
 	}
 }
 
+func (i *inMemoryPrepopulatedDirectory) GetSubtreeStatFSProvider() StatFSProvider {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	return i.subtree.statFSProvider
+}
+
 func (i *inMemoryPrepopulatedDirectory) CreateChildren(children map[path.Component]InitialNode, overwrite bool) error {
 	i.lock.Lock()
 	contents, err := i.getContents()
@@ -531,30 +824,46 @@ func (i *inMemoryPrepopulatedDirectory) CreateChildren(children map[path.Compone
 		i.lock.Unlock()
 		return syscall.ENOENT
 	}
+	if err := contents.checkCaseCollisions(children); err != nil {
+		i.lock.Unlock()
+		return err
+	}
 
 	// Remove entries that are about to be overwritten.
 	var overwrittenEntries *inMemoryDirectoryEntry
+	addedNames := make([]path.Component, 0, len(children))
 	if overwrite {
 		for name := range children {
-			if entry, ok := contents.entriesMap[name]; ok {
-				contents.detach(i.subtree, entry)
+			if entry, ok := contents.lookup(name); ok {
+				contents.detach(i, entry)
 				entry.previous = overwrittenEntries
 				overwrittenEntries = entry
+			} else {
+				addedNames = append(addedNames, name)
 			}
 		}
 	} else {
 		for name := range children {
-			if _, ok := contents.entriesMap[name]; ok {
+			if _, ok := contents.lookup(name); ok {
 				i.lock.Unlock()
 				return syscall.EEXIST
 			}
+			addedNames = append(addedNames, name)
 		}
 	}
 
-	contents.createChildren(i.subtree, children)
+	i.preserveSnapshotVersion()
+	contents.createChildren(i, children)
 	i.lock.Unlock()
 
 	i.postRemoveChildren(overwrittenEntries)
+
+	// Entries that were overwritten have already been invalidated.
+	// Announce the ones that did not exist before, so that the
+	// kernel discards any stale information about this directory.
+	for _, name := range addedNames {
+		i.handle.NotifyAddition(name)
+	}
 	return nil
 }
 
@@ -567,7 +876,7 @@ func (i *inMemoryPrepopulatedDirectory) CreateAndEnterPrepopulatedDirectory(name
 		return nil, err
 	}
 
-	if entry, ok := contents.entriesMap[name]; ok {
+	if entry, ok := contents.lookup(name); ok {
 		directory, leaf := entry.child.GetPair()
 		if directory != nil {
 			// Already a directory.
@@ -575,19 +884,20 @@ func (i *inMemoryPrepopulatedDirectory) CreateAndEnterPrepopulatedDirectory(name
 			return directory, nil
 		}
 		// Not a directory. Replace it.
-		contents.detach(i.subtree, entry)
+		contents.detach(i, entry)
 		leaf.Unlink()
-		newChild := contents.attachNewDirectory(i.subtree, name, EmptyInitialContentsFetcher)
+		newChild := contents.attachNewDirectory(i, name, EmptyInitialContentsFetcher)
 		i.lock.Unlock()
-		i.handle.NotifyRemoval(name)
+		i.notifyRemoval(entry)
 		return newChild, nil
 	}
 
 	if contents.isDeleted {
 		return nil, syscall.ENOENT
 	}
-	child := contents.attachNewDirectory(i.subtree, name, EmptyInitialContentsFetcher)
+	child := contents.attachNewDirectory(i, name, EmptyInitialContentsFetcher)
 	i.lock.Unlock()
+	i.handle.NotifyAddition(name)
 	return child, nil
 }
 
@@ -645,6 +955,114 @@ func (i *inMemoryPrepopulatedDirectory) FilterChildren(childFilter ChildFilter)
 	return nil
 }
 
+func (i *inMemoryPrepopulatedDirectory) FlushFiles(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function, casFileFactory CASFileFactory, leafFilter LeafFlushFilter) error {
+	i.lock.Lock()
+	if i.initialContentsFetcher != nil {
+		// Directory is not initialized. All of its contents
+		// are still provided by the InitialContentsFetcher,
+		// meaning there are no mutable files to flush.
+		i.lock.Unlock()
+		return nil
+	}
+
+	// Directory is already initialized. Gather the contents.
+	directoriesCount, leavesCount := i.contents.getDirectoriesAndLeavesCount(i.subtree.filesystem.hiddenFilesMatcher)
+	directories := make([]*inMemoryPrepopulatedDirectory, 0, directoriesCount)
+	leaves := make([]LeafPrepopulatedDirEntry, 0, leavesCount)
+	for entry := i.contents.entriesList.next; entry != &i.contents.entriesList; entry = entry.next {
+		if directory, leaf := entry.child.GetPair(); directory != nil {
+			directories = append(directories, directory)
+		} else {
+			leaves = append(leaves, LeafPrepopulatedDirEntry{
+				Child: leaf,
+				Name:  entry.name,
+			})
+		}
+	}
+	i.lock.Unlock()
+
+	// Convert the files without holding the directory lock, as
+	// uploading may take a considerable amount of time.
+	for _, entry := range leaves {
+		if !leafFilter(entry.Child) {
+			continue
+		}
+		if err := entry.Child.ConvertToCASFile(ctx, contentAddressableStorage, digestFunction, casFileFactory); err != nil {
+			if status.Code(err) == codes.NotFound {
+				// File was removed concurrently.
+				continue
+			}
+			return util.StatusWrapf(err, "Failed to convert file %#v", entry.Name.String())
+		}
+	}
+	for _, directory := range directories {
+		if err := directory.FlushFiles(ctx, contentAddressableStorage, digestFunction, casFileFactory, leafFilter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *inMemoryPrepopulatedDirectory) CreateSnapshot() (*DirectorySnapshot, error) {
+	i.lock.Lock()
+	epochs := i.subtree.snapshotEpochs
+	i.lock.Unlock()
+
+	return &DirectorySnapshot{
+		directory: i,
+		epochs:    epochs,
+		epoch:     epochs.acquire(),
+	}, nil
+}
+
+// preserveSnapshotVersion retains the current contents of the
+// directory on behalf of snapshots that have been created since the
+// directory was last modified. It needs to be called before the
+// contents of the directory are modified.
+func (i *inMemoryPrepopulatedDirectory) preserveSnapshotVersion() {
+	i.snapshotVersions.preserve(i.subtree.snapshotEpochs, i.snapshotEpoch, i, func() *inMemoryDirectoryVersion {
+		if err := i.initializeContents(); err != nil {
+			return &inMemoryDirectoryVersion{err: err}
+		}
+		return i.contents.newSnapshotVersion(i.subtree.filesystem.hiddenFilesMatcher)
+	})
+}
+
+func (i *inMemoryPrepopulatedDirectory) pruneSnapshotVersions() {
+	i.lock.Lock()
+	pruned := i.snapshotVersions.prune(i.subtree.snapshotEpochs, i)
+	i.lock.Unlock()
+
+	for _, version := range pruned {
+		version.release()
+	}
+}
+
+// getSnapshotVersion returns the contents of the directory at the time
+// a snapshot was created. The caller needs to release the returned
+// version.
+func (i *inMemoryPrepopulatedDirectory) getSnapshotVersion(epochs *snapshotEpochs, epoch uint64) (*inMemoryDirectoryVersion, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if i.subtree.snapshotEpochs != epochs {
+		return nil, status.Error(codes.InvalidArgument, "Snapshots cannot contain directories that have hooks installed separately")
+	}
+	if version, ok := i.snapshotVersions.get(epoch); ok {
+		if version.err != nil {
+			return nil, version.err
+		}
+		return version.link(), nil
+	}
+
+	// The directory has not been modified since the snapshot was
+	// created.
+	if err := i.initializeContents(); err != nil {
+		return nil, err
+	}
+	return i.contents.newSnapshotVersion(i.subtree.filesystem.hiddenFilesMatcher), nil
+}
+
 func (i *inMemoryPrepopulatedDirectory) virtualGetContents() (*inMemoryDirectoryContents, Status) {
 	contents, err := i.getContents()
 	if err != nil {
@@ -663,8 +1081,9 @@ func (i *inMemoryPrepopulatedDirectory) VirtualOpenChild(ctx context.Context, na
 		return nil, 0, ChangeInfo{}, s
 	}
 
-	if entry, ok := contents.entriesMap[name]; ok {
+	if entry, ok := contents.lookup(name); ok {
 		// File already exists.
+		entry.addAlias(name)
 		if existingOptions == nil {
 			return nil, 0, ChangeInfo{}, StatusErrExist
 		}
@@ -703,7 +1122,7 @@ func (i *inMemoryPrepopulatedDirectory) VirtualOpenChild(ctx context.Context, na
 
 	// Attach file to the directory.
 	changeIDBefore := contents.changeID
-	contents.attach(i.subtree, name, inMemoryDirectoryChild{}.FromLeaf(leaf))
+	contents.attach(i, name, inMemoryDirectoryChild{}.FromLeaf(leaf))
 	leaf.VirtualGetAttributes(ctx, requested, openedFileAttributes)
 	return leaf, respected, ChangeInfo{
 		Before: changeIDBefore,
@@ -711,6 +1130,31 @@ func (i *inMemoryPrepopulatedDirectory) VirtualOpenChild(ctx context.Context, na
 	}, StatusOK
 }
 
+func (i *inMemoryPrepopulatedDirectory) VirtualOpenTemporaryFile(ctx context.Context, shareAccess ShareMask, createAttributes *Attributes, requested AttributesMask, openedFileAttributes *Attributes) (Leaf, AttributesMask, Status) {
+	i.lock.Lock()
+	contents, s := i.virtualGetContents()
+	if s == StatusOK && contents.isDeleted {
+		s = StatusErrNoEnt
+	}
+	i.lock.Unlock()
+	if s != StatusOK {
+		return nil, 0, s
+	}
+
+	var respected AttributesMask
+	isExecutable := false
+	if permissions, ok := createAttributes.GetPermissions(); ok {
+		respected |= AttributesMaskPermissions
+		isExecutable = permissions&PermissionsExecute != 0
+	}
+	leaf, s := i.subtree.fileAllocator.NewFile(isExecutable, 0, shareAccess)
+	if s != StatusOK {
+		return nil, 0, s
+	}
+	leaf.VirtualGetAttributes(ctx, requested, openedFileAttributes)
+	return leaf, respected, StatusOK
+}
+
 const inMemoryPrepopulatedDirectoryLockedAttributesMask = AttributesMaskChangeID | AttributesMaskLastDataModificationTime
 
 func (i *inMemoryPrepopulatedDirectory) VirtualGetAttributes(ctx context.Context, requested AttributesMask, attributes *Attributes) {
@@ -761,7 +1205,7 @@ func (i *inMemoryPrepopulatedDirectory) VirtualLink(ctx context.Context, name pa
 		return ChangeInfo{}, s
 	}
 	changeIDBefore := contents.changeID
-	contents.attach(i.subtree, name, inMemoryDirectoryChild{}.FromLeaf(child))
+	contents.attach(i, name, inMemoryDirectoryChild{}.FromLeaf(child))
 
 	child.VirtualGetAttributes(ctx, requested, out)
 	return ChangeInfo{
@@ -786,6 +1230,7 @@ func (i *inMemoryPrepopulatedDirectory) VirtualLookup(ctx context.Context, name
 	// might cause a deadlock.
 	if requested&inMemoryPrepopulatedDirectoryLockedAttributesMask != 0 {
 		if entry, ok := contents.getAndLockIfDirectory(name, &lockPile); ok {
+			entry.addAlias(name)
 			directory, leaf := entry.child.GetPair()
 			if directory != nil {
 				directory.virtualGetAttributesUnlocked(requested, out)
@@ -796,7 +1241,8 @@ func (i *inMemoryPrepopulatedDirectory) VirtualLookup(ctx context.Context, name
 			return DirectoryChild{}.FromLeaf(leaf), StatusOK
 		}
 	} else {
-		if entry, ok := contents.entriesMap[name]; ok {
+		if entry, ok := contents.lookup(name); ok {
+			entry.addAlias(name)
 			directory, leaf := entry.child.GetPair()
 			if directory != nil {
 				directory.virtualGetAttributesUnlocked(requested, out)
@@ -822,7 +1268,7 @@ func (i *inMemoryPrepopulatedDirectory) VirtualMkdir(name path.Component, reques
 		return nil, ChangeInfo{}, s
 	}
 	changeIDBefore := contents.changeID
-	child := contents.attachNewDirectory(i.subtree, name, EmptyInitialContentsFetcher)
+	child := contents.attachNewDirectory(i, name, EmptyInitialContentsFetcher)
 
 	// Even though the child directory is not locked explicitly, the
 	// following is safe, as the directory has not been returned yet.
@@ -853,7 +1299,7 @@ func (i *inMemoryPrepopulatedDirectory) VirtualMknod(ctx context.Context, name p
 		New().
 		AsNativeLeaf(NewSpecialFile(fileType, nil))
 	changeIDBefore := contents.changeID
-	contents.attach(i.subtree, name, inMemoryDirectoryChild{}.FromLeaf(child))
+	contents.attach(i, name, inMemoryDirectoryChild{}.FromLeaf(child))
 
 	child.VirtualGetAttributes(ctx, requested, out)
 	return child, ChangeInfo{
@@ -872,6 +1318,7 @@ func (i *inMemoryPrepopulatedDirectory) VirtualReadDir(ctx context.Context, firs
 		return s
 	}
 
+	var lastReported *inMemoryDirectoryEntry
 	for entry := contents.getEntryAtCookie(firstCookie); entry != &contents.entriesList; {
 		if directory, leaf := entry.child.GetPair(); directory != nil {
 			var attributes Attributes
@@ -897,15 +1344,21 @@ func (i *inMemoryPrepopulatedDirectory) VirtualReadDir(ctx context.Context, firs
 			}
 
 			if !reporter.ReportEntry(entry.cookie+1, entry.name, DirectoryChild{}.FromDirectory(directory), &attributes) {
+				contents.setReadDirPosition(lastReported)
 				break
 			}
 		} else if !i.subtree.filesystem.hiddenFilesMatcher(entry.name.String()) {
 			var attributes Attributes
 			leaf.VirtualGetAttributes(ctx, requested, &attributes)
 			if !reporter.ReportEntry(entry.cookie+1, entry.name, DirectoryChild{}.FromLeaf(leaf), &attributes) {
+				contents.setReadDirPosition(lastReported)
 				break
 			}
+		} else {
+			entry = entry.next
+			continue
 		}
+		lastReported = entry
 		entry = entry.next
 	}
 	return StatusOK
@@ -918,6 +1371,21 @@ func (i *inMemoryPrepopulatedDirectory) VirtualRename(oldName path.Component, ne
 		return ChangeInfo{}, ChangeInfo{}, StatusErrXDev
 	}
 
+	// Renames across directories may move directories elsewhere in
+	// the hierarchy. Serialize these, so that the ancestry of the
+	// target directory can be inspected to prevent a directory from
+	// being moved into its own subtree. Directory locks are acquired
+	// afterwards, as LockPile may temporarily drop these.
+	//
+	// As the virtual file system is effectively single user (see
+	// Permissions), there is no need to apply sticky bit semantics.
+	crossDirectory := iOld != iNew && iOld.subtree.filesystem == iNew.subtree.filesystem
+	if crossDirectory {
+		renameLock := &iOld.subtree.filesystem.renameLock
+		renameLock.Lock()
+		defer renameLock.Unlock()
+	}
+
 	lockPile := re_sync.LockPile{}
 	defer lockPile.UnlockAll()
 	lockPile.Lock(&iOld.lock, &iNew.lock)
@@ -930,18 +1398,32 @@ func (i *inMemoryPrepopulatedDirectory) VirtualRename(oldName path.Component, ne
 	if s != StatusOK {
 		return ChangeInfo{}, ChangeInfo{}, s
 	}
+	// Attribute changes to both directories to the same snapshot
+	// epoch, so that snapshots never observe the entry being
+	// present in both or neither of the directories.
+	iNew.snapshotEpoch = iOld.snapshotEpoch
 
 	oldChangeIDBefore := oldContents.changeID
 	newChangeIDBefore := newContents.changeID
 	if newEntry, ok := newContents.getAndLockIfDirectory(newName, &lockPile); ok {
-		oldEntry, ok := oldContents.entriesMap[oldName]
+		oldEntry, ok := oldContents.lookup(oldName)
 		if !ok {
 			return ChangeInfo{}, ChangeInfo{}, StatusErrNoEnt
 		}
 		oldChild := oldEntry.child
 		oldDirectory, oldLeaf := oldChild.GetPair()
 		newChild := newEntry.child
-		if newDirectory, newLeaf := newChild.GetPair(); newDirectory != nil {
+		if newEntry == oldEntry {
+			// Renaming an entry to itself. In directories
+			// that perform case-insensitive lookups, this
+			// may still be used to change the case of the
+			// entry's name.
+			if newName != oldEntry.name {
+				oldContents.detach(iOld, oldEntry)
+				iOld.notifyAliasRemoval(oldEntry, oldName)
+				newContents.attach(iNew, newName, oldChild)
+			}
+		} else if newDirectory, newLeaf := newChild.GetPair(); newDirectory != nil {
 			// Renaming to a location at which a directory
 			// already exists.
 			if oldDirectory == nil {
@@ -960,13 +1442,18 @@ func (i *inMemoryPrepopulatedDirectory) VirtualRename(oldName path.Component, ne
 				if !newChildContents.isDeletable(i.subtree.filesystem.hiddenFilesMatcher) {
 					return ChangeInfo{}, ChangeInfo{}, StatusErrNotEmpty
 				}
-				oldContents.detach(i.subtree, oldEntry)
-				// TODO: Pick up an interlock and check for
-				// potential creation of cyclic directory
-				// structures.
-				newContents.detach(i.subtree, newEntry)
+				if crossDirectory && oldDirectory.isAncestorOf(iNew) {
+					return ChangeInfo{}, ChangeInfo{}, StatusErrInval
+				}
+				oldContents.detach(iOld, oldEntry)
+				newContents.detach(iNew, newEntry)
+				iOld.notifyAliasRemoval(oldEntry, oldName)
+				iNew.notifyAliasRemoval(newEntry, newName)
 				newDirectory.markDeleted()
-				newContents.attach(i.subtree, newName, oldChild)
+				newContents.attach(iNew, newName, oldChild)
+				if crossDirectory {
+					oldDirectory.parent = iNew
+				}
 			}
 		} else {
 			// Renaming to a location at which a leaf
@@ -979,10 +1466,12 @@ func (i *inMemoryPrepopulatedDirectory) VirtualRename(oldName path.Component, ne
 			// sequence of commands, both "a" and "b" should
 			// still exist: "touch a; ln a b; mv a b".
 			if newLeaf != oldLeaf {
-				oldContents.detach(i.subtree, oldEntry)
-				newContents.detach(i.subtree, newEntry)
+				oldContents.detach(iOld, oldEntry)
+				newContents.detach(iNew, newEntry)
+				iOld.notifyAliasRemoval(oldEntry, oldName)
+				iNew.notifyAliasRemoval(newEntry, newName)
 				newLeaf.Unlink()
-				newContents.attach(i.subtree, newName, oldChild)
+				newContents.attach(iNew, newName, oldChild)
 			}
 		}
 	} else {
@@ -990,18 +1479,26 @@ func (i *inMemoryPrepopulatedDirectory) VirtualRename(oldName path.Component, ne
 		if newContents.isDeleted {
 			return ChangeInfo{}, ChangeInfo{}, StatusErrNoEnt
 		}
-		oldEntry, ok := oldContents.entriesMap[oldName]
+		oldEntry, ok := oldContents.lookup(oldName)
 		if !ok {
 			return ChangeInfo{}, ChangeInfo{}, StatusErrNoEnt
 		}
 		oldChild := oldEntry.child
-		if oldDirectory, _ := oldChild.GetPair(); oldDirectory != nil {
+		oldDirectory, _ := oldChild.GetPair()
+		if oldDirectory != nil {
 			if iOld.subtree.filesystem != iNew.subtree.filesystem {
 				return ChangeInfo{}, ChangeInfo{}, StatusErrXDev
 			}
+			if crossDirectory && oldDirectory.isAncestorOf(iNew) {
+				return ChangeInfo{}, ChangeInfo{}, StatusErrInval
+			}
+		}
+		oldContents.detach(iOld, oldEntry)
+		iOld.notifyAliasRemoval(oldEntry, oldName)
+		newContents.attach(iNew, newName, oldChild)
+		if oldDirectory != nil && crossDirectory {
+			oldDirectory.parent = iNew
 		}
-		oldContents.detach(i.subtree, oldEntry)
-		newContents.attach(i.subtree, newName, oldChild)
 	}
 	return ChangeInfo{
 			Before: oldChangeIDBefore,
@@ -1023,7 +1520,8 @@ func (i *inMemoryPrepopulatedDirectory) VirtualRemove(name path.Component, remov
 	}
 
 	if entry, ok := contents.getAndLockIfDirectory(name, &lockPile); ok {
-		if directory, leaf := entry.child.GetPair(); directory != nil {
+		directory, leaf := entry.child.GetPair()
+		if directory != nil {
 			if !removeDirectory {
 				return ChangeInfo{}, StatusErrPerm
 			}
@@ -1035,14 +1533,15 @@ func (i *inMemoryPrepopulatedDirectory) VirtualRemove(name path.Component, remov
 				return ChangeInfo{}, StatusErrNotEmpty
 			}
 			directory.markDeleted()
-		} else {
-			if !removeLeaf {
-				return ChangeInfo{}, StatusErrNotDir
-			}
-			leaf.Unlink()
+		} else if !removeLeaf {
+			return ChangeInfo{}, StatusErrNotDir
 		}
 		changeIDBefore := contents.changeID
-		contents.detach(i.subtree, entry)
+		contents.detach(i, entry)
+		if leaf != nil {
+			leaf.Unlink()
+		}
+		i.notifyAliasRemoval(entry, name)
 		return ChangeInfo{
 			Before: changeIDBefore,
 			After:  contents.changeID,
@@ -1060,6 +1559,34 @@ func (i *inMemoryPrepopulatedDirectory) VirtualSetAttributes(ctx context.Context
 	return StatusOK
 }
 
+func (i *inMemoryPrepopulatedDirectory) VirtualGetXAttr(ctx context.Context, name string) ([]byte, Status) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	return i.extendedAttributes.get(name)
+}
+
+func (i *inMemoryPrepopulatedDirectory) VirtualListXAttr(ctx context.Context) ([]string, Status) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	return i.extendedAttributes.list(), StatusOK
+}
+
+func (i *inMemoryPrepopulatedDirectory) VirtualRemoveXAttr(ctx context.Context, name string) Status {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	return i.extendedAttributes.remove(name)
+}
+
+func (i *inMemoryPrepopulatedDirectory) VirtualSetXAttr(ctx context.Context, name string, value []byte, mode XAttrSetMode) Status {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	return i.extendedAttributes.set(name, value, mode)
+}
+
 func (i *inMemoryPrepopulatedDirectory) VirtualSymlink(ctx context.Context, pointedTo []byte, linkName path.Component, requested AttributesMask, out *Attributes) (Leaf, ChangeInfo, Status) {
 	i.lock.Lock()
 	defer i.lock.Unlock()
@@ -1074,7 +1601,7 @@ func (i *inMemoryPrepopulatedDirectory) VirtualSymlink(ctx context.Context, poin
 	}
 	child := i.subtree.filesystem.symlinkFactory.LookupSymlink(pointedTo)
 	changeIDBefore := contents.changeID
-	contents.attach(i.subtree, linkName, inMemoryDirectoryChild{}.FromLeaf(child))
+	contents.attach(i, linkName, inMemoryDirectoryChild{}.FromLeaf(child))
 
 	child.VirtualGetAttributes(ctx, requested, out)
 	return child, ChangeInfo{
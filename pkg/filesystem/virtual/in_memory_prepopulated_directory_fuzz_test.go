@@ -0,0 +1,177 @@
+package virtual_test
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+	"testing"
+
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/random"
+	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+// countingFilePool is a FilePool that keeps track of the number of
+// files that are opened. It is used to detect leaks of files.
+type countingFilePool struct {
+	openFiles atomic.Int64
+}
+
+func (fp *countingFilePool) NewFile() (filesystem.FileReadWriter, error) {
+	f, err := re_filesystem.InMemoryFilePool.NewFile()
+	if err != nil {
+		return nil, err
+	}
+	fp.openFiles.Add(1)
+	return &countingFile{FileReadWriter: f, pool: fp}, nil
+}
+
+type countingFile struct {
+	filesystem.FileReadWriter
+	pool *countingFilePool
+}
+
+func (f *countingFile) Close() error {
+	f.pool.openFiles.Add(-1)
+	return f.FileReadWriter.Close()
+}
+
+// fuzzInput consumes bytes provided by the fuzzer to make decisions.
+type fuzzInput []byte
+
+func (in *fuzzInput) byte() byte {
+	if len(*in) == 0 {
+		return 0
+	}
+	b := (*in)[0]
+	*in = (*in)[1:]
+	return b
+}
+
+func (in *fuzzInput) name() path.Component {
+	// Use a small set of names, so that operations frequently
+	// refer to existing files.
+	return path.MustNewComponent(string([]byte{'a' + in.byte()%4}))
+}
+
+func (in *fuzzInput) index(n int) int {
+	return int(in.byte()) % n
+}
+
+// FuzzInMemoryPrepopulatedDirectory applies randomized sequences of
+// operations against an InMemoryPrepopulatedDirectory. None of these
+// operations should cause panics. Once all files are closed and the
+// directory is emptied, all files in the FilePool should be released.
+func FuzzInMemoryPrepopulatedDirectory(f *testing.F) {
+	f.Add([]byte{0, 0, 1, 0, 0, 5, 0, 3, 6, 0})
+	f.Add([]byte{0, 0, 4, 0, 1, 1, 1, 3, 0, 0, 1, 1, 2, 0, 0, 6, 0})
+	f.Add([]byte{1, 0, 0, 7, 0, 1, 0, 2, 0, 0, 10, 0, 3, 6, 0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.Background()
+		filePool := &countingFilePool{}
+		handleAllocator := virtual.NewNFSHandleAllocator(random.NewFastSingleThreadedGenerator())
+		root := virtual.NewInMemoryPrepopulatedDirectory(
+			virtual.NewHandleAllocatingFileAllocator(
+				virtual.NewPoolBackedFileAllocator(filePool, util.DefaultErrorLogger, clock.SystemClock),
+				handleAllocator),
+			virtual.NewHandleAllocatingSymlinkFactory(
+				virtual.BaseSymlinkFactory,
+				handleAllocator.New()),
+			util.DefaultErrorLogger,
+			handleAllocator,
+			sort.Sort,
+			func(string) bool { return false },
+			clock.SystemClock,
+			/* caseInsensitive = */ false)
+
+		type openedLeaf struct {
+			leaf        virtual.Leaf
+			shareAccess virtual.ShareMask
+		}
+		directories := []virtual.Directory{root}
+		var openedLeaves []openedLeaf
+
+		in := fuzzInput(data)
+		for len(in) > 0 {
+			var attributes virtual.Attributes
+			directory := directories[in.index(len(directories))]
+			switch in.byte() % 11 {
+			case 0:
+				if child, _, s := directory.VirtualMkdir(in.name(), 0, &attributes); s == virtual.StatusOK {
+					directories = append(directories, child)
+				}
+			case 1:
+				shareAccess := virtual.ShareMask(in.byte()%3 + 1)
+				if leaf, _, _, s := directory.VirtualOpenChild(
+					ctx,
+					in.name(),
+					shareAccess,
+					(&virtual.Attributes{}).SetPermissions(virtual.PermissionsRead|virtual.PermissionsWrite),
+					&virtual.OpenExistingOptions{Truncate: in.byte()%2 == 0},
+					0,
+					&attributes,
+				); s == virtual.StatusOK {
+					openedLeaves = append(openedLeaves, openedLeaf{leaf: leaf, shareAccess: shareAccess})
+				}
+			case 2:
+				directory.VirtualRemove(in.name(), true, true)
+			case 3:
+				newDirectory := directories[in.index(len(directories))]
+				directory.VirtualRename(in.name(), newDirectory, in.name())
+			case 4:
+				if child, s := directory.VirtualLookup(ctx, in.name(), 0, &attributes); s == virtual.StatusOK {
+					if childDirectory, _ := child.GetPair(); childDirectory != nil {
+						directories = append(directories, childDirectory)
+					}
+				}
+			case 5:
+				if len(openedLeaves) > 0 {
+					leaf := openedLeaves[in.index(len(openedLeaves))].leaf
+					leaf.VirtualWrite([]byte("Hello"), uint64(in.byte()))
+				}
+			case 6:
+				if len(openedLeaves) > 0 {
+					i := in.index(len(openedLeaves))
+					openedLeaves[i].leaf.VirtualClose(openedLeaves[i].shareAccess)
+					openedLeaves = append(openedLeaves[:i], openedLeaves[i+1:]...)
+				}
+			case 7:
+				if len(openedLeaves) > 0 {
+					leaf := openedLeaves[in.index(len(openedLeaves))].leaf
+					directory.VirtualLink(ctx, in.name(), leaf, 0, &attributes)
+				}
+			case 8:
+				directory.VirtualReadDir(ctx, uint64(in.byte()%4), 0, discardingDirectoryEntryReporter{})
+			case 9:
+				directory.VirtualSymlink(ctx, []byte("target"), in.name(), 0, &attributes)
+			case 10:
+				if len(openedLeaves) > 0 {
+					leaf := openedLeaves[in.index(len(openedLeaves))].leaf
+					leaf.VirtualSetAttributes(ctx, (&virtual.Attributes{}).SetSizeBytes(uint64(in.byte())), 0, &attributes)
+				}
+			}
+		}
+
+		// Release all resources. This should cause all files to
+		// be returned to the pool.
+		for _, openedLeaf := range openedLeaves {
+			openedLeaf.leaf.VirtualClose(openedLeaf.shareAccess)
+		}
+		require.NoError(t, root.RemoveAllChildren(false))
+		require.Equal(t, int64(0), filePool.openFiles.Load())
+	})
+}
+
+// discardingDirectoryEntryReporter is a DirectoryEntryReporter that
+// accepts all entries without inspecting them.
+type discardingDirectoryEntryReporter struct{}
+
+func (discardingDirectoryEntryReporter) ReportEntry(nextCookie uint64, name path.Component, child virtual.DirectoryChild, attributes *virtual.Attributes) bool {
+	return true
+}
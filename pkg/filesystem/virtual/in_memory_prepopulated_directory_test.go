@@ -2,16 +2,23 @@ package virtual_test
 
 import (
 	"context"
+	"io"
+	"math/rand"
 	"os"
 	"regexp"
 	"sort"
+	"strconv"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
 
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
 	"github.com/buildbarn/bb-remote-execution/internal/mock"
 	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
 	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/digest"
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
 	"github.com/buildbarn/bb-storage/pkg/testutil"
@@ -20,6 +27,7 @@ import (
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 const inMemoryPrepopulatedDirectoryAttributesMask = virtual.AttributesMaskChangeID |
@@ -55,7 +63,7 @@ func TestInMemoryPrepopulatedDirectoryLookupChildNonExistent(t *testing.T) {
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	_, err := d.LookupChild(path.MustNewComponent("nonexistent"))
 	require.True(t, os.IsNotExist(err))
@@ -68,10 +76,11 @@ func TestInMemoryPrepopulatedDirectoryLookupChildFile(t *testing.T) {
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	leaf := mock.NewMockNativeLeaf(ctrl)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("file"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("file"): virtual.InitialNode{}.FromLeaf(leaf),
 	}, false))
@@ -88,10 +97,11 @@ func TestInMemoryPrepopulatedDirectoryLookupChildDirectory(t *testing.T) {
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("subdir"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("subdir"): virtual.InitialNode{}.FromDirectory(virtual.EmptyInitialContentsFetcher),
 	}, false))
@@ -111,11 +121,12 @@ func TestInMemoryPrepopulatedDirectoryLookupAllChildrenFailure(t *testing.T) {
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
 	initialContentsFetcher := mock.NewMockInitialContentsFetcher(ctrl)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("subdir"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("subdir"): virtual.InitialNode{}.FromDirectory(initialContentsFetcher),
 	}, false))
@@ -143,21 +154,25 @@ func TestInMemoryPrepopulatedDirectoryLookupAllChildrenSuccess(t *testing.T) {
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Populate the directory with files and directories.
 	leaf1 := mock.NewMockNativeLeaf(ctrl)
 	leaf2 := mock.NewMockNativeLeaf(ctrl)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("leaf1"))
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("._leaf2"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("leaf1"):   virtual.InitialNode{}.FromLeaf(leaf1),
 		path.MustNewComponent("._leaf2"): virtual.InitialNode{}.FromLeaf(leaf2),
 	}, false))
 
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("subdir1"))
 	subdir1, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("subdir1"))
 	require.NoError(t, err)
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("subdir2"))
 	subdir2, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("subdir2"))
 	require.NoError(t, err)
 
@@ -181,13 +196,16 @@ func TestInMemoryPrepopulatedDirectoryReadDir(t *testing.T) {
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Prepare file system.
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
 	leaf1 := mock.NewMockNativeLeaf(ctrl)
 	leaf2 := mock.NewMockNativeLeaf(ctrl)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("directory"))
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("file"))
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("._hidden_file"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("directory"):     virtual.InitialNode{}.FromDirectory(virtual.EmptyInitialContentsFetcher),
 		path.MustNewComponent("file"):          virtual.InitialNode{}.FromLeaf(leaf1),
@@ -220,7 +238,7 @@ func TestInMemoryPrepopulatedDirectoryRemoveNonExistent(t *testing.T) {
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	require.True(t, os.IsNotExist(d.Remove(path.MustNewComponent("nonexistent"))))
 }
@@ -233,9 +251,10 @@ func TestInMemoryPrepopulatedDirectoryRemoveDirectory(t *testing.T) {
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	subdirHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("directory"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("directory"): virtual.InitialNode{}.FromDirectory(virtual.EmptyInitialContentsFetcher),
 	}, false))
@@ -254,11 +273,12 @@ func TestInMemoryPrepopulatedDirectoryRemoveDirectoryNotEmpty(t *testing.T) {
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
 	initialContentsFetcher := mock.NewMockInitialContentsFetcher(ctrl)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("directory"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("directory"): virtual.InitialNode{}.FromDirectory(initialContentsFetcher),
 	}, false))
@@ -278,9 +298,10 @@ func TestInMemoryPrepopulatedDirectoryRemoveFile(t *testing.T) {
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	leaf := mock.NewMockNativeLeaf(ctrl)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("file"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("file"): virtual.InitialNode{}.FromLeaf(leaf),
 	}, false))
@@ -300,13 +321,15 @@ func TestInMemoryPrepopulatedDirectoryCreateChildrenSuccess(t *testing.T) {
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Merge another directory and file into it.
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
 	subdirectoryFetcher := mock.NewMockInitialContentsFetcher(ctrl)
 	topLevelFile := mock.NewMockNativeLeaf(ctrl)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("dir"))
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("file"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("dir"):  virtual.InitialNode{}.FromDirectory(subdirectoryFetcher),
 		path.MustNewComponent("file"): virtual.InitialNode{}.FromLeaf(topLevelFile),
@@ -361,10 +384,11 @@ func TestInMemoryPrepopulatedDirectoryCreateChildrenInRemovedDirectory(t *testin
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Create a reference to a removed child directory.
 	childHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("directory"))
 	child, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("directory"))
 	require.NoError(t, err)
 	dHandle.EXPECT().NotifyRemoval(path.MustNewComponent("directory"))
@@ -375,6 +399,132 @@ func TestInMemoryPrepopulatedDirectoryCreateChildrenInRemovedDirectory(t *testin
 	require.Equal(t, syscall.ENOENT, child.CreateChildren(map[path.Component]virtual.InitialNode{}, false))
 }
 
+func TestInMemoryPrepopulatedDirectoryCreateChildrenOverwrite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	fileAllocator := mock.NewMockFileAllocator(ctrl)
+	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
+
+	oldLeaf := mock.NewMockNativeLeaf(ctrl)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("file"))
+	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
+		path.MustNewComponent("file"): virtual.InitialNode{}.FromLeaf(oldLeaf),
+	}, false))
+
+	// When overwriting children, existing entries should be
+	// invalidated as being removed, while entries that did not
+	// exist previously should be announced as being added. This
+	// ensures that the kernel does not continue to use stale
+	// information after the directory is repopulated.
+	newLeaf1 := mock.NewMockNativeLeaf(ctrl)
+	newLeaf2 := mock.NewMockNativeLeaf(ctrl)
+	oldLeaf.EXPECT().Unlink()
+	dHandle.EXPECT().NotifyRemoval(path.MustNewComponent("file"))
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("other_file"))
+	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
+		path.MustNewComponent("file"):       virtual.InitialNode{}.FromLeaf(newLeaf1),
+		path.MustNewComponent("other_file"): virtual.InitialNode{}.FromLeaf(newLeaf2),
+	}, true))
+
+	child, err := d.LookupChild(path.MustNewComponent("file"))
+	require.NoError(t, err)
+	require.Equal(t, virtual.PrepopulatedDirectoryChild{}.FromLeaf(newLeaf1), child)
+}
+
+func TestInMemoryPrepopulatedDirectoryCaseInsensitive(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	fileAllocator := mock.NewMockFileAllocator(ctrl)
+	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, true)
+
+	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("SubDir"))
+	subDir, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("SubDir"))
+	require.NoError(t, err)
+
+	t.Run("Lookup", func(t *testing.T) {
+		// Lookups should ignore case.
+		child, err := d.LookupChild(path.MustNewComponent("SUBDIR"))
+		require.NoError(t, err)
+		require.Equal(t, virtual.PrepopulatedDirectoryChild{}.FromDirectory(subDir), child)
+
+		childDirectory, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("subdir"))
+		require.NoError(t, err)
+		require.Equal(t, subDir, childDirectory)
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		// Creating a child whose name only differs in case
+		// from an existing child should fail.
+		leaf := mock.NewMockNativeLeaf(ctrl)
+		require.Equal(t, syscall.EEXIST, d.CreateChildren(map[path.Component]virtual.InitialNode{
+			path.MustNewComponent("subdir"): virtual.InitialNode{}.FromLeaf(leaf),
+		}, false))
+	})
+
+	t.Run("Collision", func(t *testing.T) {
+		// Collisions between children that are created at the
+		// same time should be reported.
+		leaf1 := mock.NewMockNativeLeaf(ctrl)
+		leaf2 := mock.NewMockNativeLeaf(ctrl)
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.InvalidArgument, "Directory contains children \"FILE\" and \"file\", whose names only differ in case"),
+			d.CreateChildren(map[path.Component]virtual.InitialNode{
+				path.MustNewComponent("file"): virtual.InitialNode{}.FromLeaf(leaf1),
+				path.MustNewComponent("FILE"): virtual.InitialNode{}.FromLeaf(leaf2),
+			}, false))
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		// Renaming a child to a name that only differs in case
+		// should cause the new name to be preserved.
+		_, _, s := d.VirtualRename(path.MustNewComponent("subdir"), d, path.MustNewComponent("subDir"))
+		require.Equal(t, virtual.StatusOK, s)
+
+		entries, err := d.ReadDir()
+		require.NoError(t, err)
+		require.Equal(t,
+			[]filesystem.FileInfo{
+				filesystem.NewFileInfo(path.MustNewComponent("subDir"), filesystem.FileTypeDirectory, false),
+			},
+			entries)
+	})
+
+	t.Run("RemoveAliases", func(t *testing.T) {
+		// The kernel caches directory entries for every name
+		// under which a child has been looked up. Removing the
+		// child should invalidate all of them.
+		leaf := mock.NewMockNativeLeaf(ctrl)
+		dHandle.EXPECT().NotifyAddition(path.MustNewComponent("Data"))
+		require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
+			path.MustNewComponent("Data"): virtual.InitialNode{}.FromLeaf(leaf),
+		}, false))
+
+		leaf.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMask(0), gomock.Any()).Times(2)
+		var attr virtual.Attributes
+		for _, name := range []string{"DATA", "data"} {
+			child, s := d.VirtualLookup(ctx, path.MustNewComponent(name), virtual.AttributesMask(0), &attr)
+			require.Equal(t, virtual.StatusOK, s)
+			require.Equal(t, virtual.DirectoryChild{}.FromLeaf(leaf), child)
+		}
+
+		leaf.EXPECT().Unlink()
+		dHandle.EXPECT().NotifyRemoval(path.MustNewComponent("Data"))
+		dHandle.EXPECT().NotifyRemoval(path.MustNewComponent("DATA"))
+		dHandle.EXPECT().NotifyRemoval(path.MustNewComponent("data"))
+		require.NoError(t, d.Remove(path.MustNewComponent("dATA")))
+	})
+}
+
 func TestInMemoryPrepopulatedDirectoryInstallHooks(t *testing.T) {
 	ctrl, ctx := gomock.WithContext(context.Background(), t)
 
@@ -383,11 +533,12 @@ func TestInMemoryPrepopulatedDirectoryInstallHooks(t *testing.T) {
 	symlinkFactory1 := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger1 := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator1, symlinkFactory1, errorLogger1, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator1, symlinkFactory1, errorLogger1, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 	fileAllocator2 := mock.NewMockFileAllocator(ctrl)
 	errorLogger2 := mock.NewMockErrorLogger(ctrl)
-	d.InstallHooks(fileAllocator2, errorLogger2)
+	statFSProvider2 := mock.NewMockStatFSProvider(ctrl)
+	d.InstallHooks(fileAllocator2, errorLogger2, statFSProvider2)
 
 	// Validate that the top-level directory uses both the new file
 	// allocator and error logger.
@@ -407,6 +558,7 @@ func TestInMemoryPrepopulatedDirectoryInstallHooks(t *testing.T) {
 	// Validate that a subdirectory uses the new file allocator
 	// and error logger as well.
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("dir"))
 	child, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("dir"))
 	require.NoError(t, err)
 	fileAllocator2.EXPECT().NewFile(false, uint64(0), virtual.ShareMaskWrite).
@@ -420,6 +572,11 @@ func TestInMemoryPrepopulatedDirectoryInstallHooks(t *testing.T) {
 		virtual.AttributesMask(0),
 		&attr)
 	require.Equal(t, virtual.StatusErrIO, s)
+
+	// Both directories should report the capacity of the file
+	// system using the new StatFSProvider.
+	require.Equal(t, virtual.StatFSProvider(statFSProvider2), virtual.GetStatFSProvider(d, nil))
+	require.Equal(t, virtual.StatFSProvider(statFSProvider2), virtual.GetStatFSProvider(child, nil))
 }
 
 func TestInMemoryPrepopulatedDirectoryFilterChildren(t *testing.T) {
@@ -430,7 +587,7 @@ func TestInMemoryPrepopulatedDirectoryFilterChildren(t *testing.T) {
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// In the initial state, InMemoryPrepopulatedDirectory will have
 	// an EmptyInitialContentsFetcher associated with it.
@@ -456,6 +613,10 @@ func TestInMemoryPrepopulatedDirectoryFilterChildren(t *testing.T) {
 	directory2 := mock.NewMockInitialContentsFetcher(ctrl)
 	leaf1 := mock.NewMockNativeLeaf(ctrl)
 	leaf2 := mock.NewMockNativeLeaf(ctrl)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("directory1"))
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("directory2"))
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("leaf1"))
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("leaf2"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("directory1"): virtual.InitialNode{}.FromDirectory(directory1),
 		path.MustNewComponent("directory2"): virtual.InitialNode{}.FromDirectory(directory2),
@@ -488,6 +649,272 @@ func TestInMemoryPrepopulatedDirectoryFilterChildren(t *testing.T) {
 	require.NoError(t, d.FilterChildren(childFilter4.Call))
 }
 
+func TestInMemoryPrepopulatedDirectoryFlushFiles(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	fileAllocator := mock.NewMockFileAllocator(ctrl)
+	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
+
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	casFileFactory := mock.NewMockCASFileFactory(ctrl)
+	digestFunction := digest.MustNewFunction("", remoteexecution.DigestFunction_SHA256)
+
+	flushAllLeaves := func(leaf virtual.NativeLeaf) bool { return true }
+
+	// Flushing a directory that has not been initialized should
+	// not cause it to be initialized.
+	require.NoError(t, d.FlushFiles(ctx, contentAddressableStorage, digestFunction, casFileFactory, flushAllLeaves))
+
+	// Create a directory hierarchy containing some files.
+	subdirectoryHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("subdirectory"))
+	subdirectory, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("subdirectory"))
+	require.NoError(t, err)
+	mutableFile := mock.NewMockNativeLeaf(ctrl)
+	immutableFile := mock.NewMockNativeLeaf(ctrl)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("mutable"))
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("immutable"))
+	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
+		path.MustNewComponent("mutable"):   virtual.InitialNode{}.FromLeaf(mutableFile),
+		path.MustNewComponent("immutable"): virtual.InitialNode{}.FromLeaf(immutableFile),
+	}, false))
+	nestedFile := mock.NewMockNativeLeaf(ctrl)
+	removedFile := mock.NewMockNativeLeaf(ctrl)
+	subdirectoryHandle.EXPECT().NotifyAddition(path.MustNewComponent("nested"))
+	subdirectoryHandle.EXPECT().NotifyAddition(path.MustNewComponent("removed"))
+	require.NoError(t, subdirectory.CreateChildren(map[path.Component]virtual.InitialNode{
+		path.MustNewComponent("nested"):  virtual.InitialNode{}.FromLeaf(nestedFile),
+		path.MustNewComponent("removed"): virtual.InitialNode{}.FromLeaf(removedFile),
+	}, false))
+
+	t.Run("UploadFailure", func(t *testing.T) {
+		immutableFile.EXPECT().ConvertToCASFile(ctx, contentAddressableStorage, digestFunction, casFileFactory)
+		mutableFile.EXPECT().ConvertToCASFile(ctx, contentAddressableStorage, digestFunction, casFileFactory).
+			Return(status.Error(codes.Unavailable, "Server offline"))
+
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.Unavailable, "Failed to convert file \"mutable\": Server offline"),
+			d.FlushFiles(ctx, contentAddressableStorage, digestFunction, casFileFactory, flushAllLeaves))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		// Files should be converted in place, meaning that the
+		// directory hierarchy remains unaltered. Files that get
+		// removed while being converted should be skipped.
+		immutableFile.EXPECT().ConvertToCASFile(ctx, contentAddressableStorage, digestFunction, casFileFactory)
+		mutableFile.EXPECT().ConvertToCASFile(ctx, contentAddressableStorage, digestFunction, casFileFactory)
+		nestedFile.EXPECT().ConvertToCASFile(ctx, contentAddressableStorage, digestFunction, casFileFactory)
+		removedFile.EXPECT().ConvertToCASFile(ctx, contentAddressableStorage, digestFunction, casFileFactory).
+			Return(status.Error(codes.NotFound, "File was unlinked before conversion could start"))
+
+		require.NoError(t, d.FlushFiles(ctx, contentAddressableStorage, digestFunction, casFileFactory, flushAllLeaves))
+
+		child, err := d.LookupChild(path.MustNewComponent("mutable"))
+		require.NoError(t, err)
+		require.Equal(t, virtual.PrepopulatedDirectoryChild{}.FromLeaf(mutableFile), child)
+		child, err = subdirectory.LookupChild(path.MustNewComponent("nested"))
+		require.NoError(t, err)
+		require.Equal(t, virtual.PrepopulatedDirectoryChild{}.FromLeaf(nestedFile), child)
+	})
+
+	t.Run("Filtered", func(t *testing.T) {
+		// Files for which the filter returns false should not
+		// be converted.
+		require.NoError(t, d.FlushFiles(ctx, contentAddressableStorage, digestFunction, casFileFactory, func(leaf virtual.NativeLeaf) bool { return false }))
+	})
+}
+
+func TestInMemoryPrepopulatedDirectoryCreateSnapshot(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	fileAllocator := mock.NewMockFileAllocator(ctrl)
+	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
+
+	// Create a directory hierarchy containing a file, a symbolic
+	// link and a hidden file.
+	subdirectoryHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("subdirectory"))
+	subdirectory, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("subdirectory"))
+	require.NoError(t, err)
+	file := mock.NewMockNativeLeaf(ctrl)
+	hiddenFile := mock.NewMockNativeLeaf(ctrl)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("file"))
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("._hidden"))
+	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
+		path.MustNewComponent("file"):     virtual.InitialNode{}.FromLeaf(file),
+		path.MustNewComponent("._hidden"): virtual.InitialNode{}.FromLeaf(hiddenFile),
+	}, false))
+	symlink := mock.NewMockNativeLeaf(ctrl)
+	subdirectoryHandle.EXPECT().NotifyAddition(path.MustNewComponent("symlink"))
+	require.NoError(t, subdirectory.CreateChildren(map[path.Component]virtual.InitialNode{
+		path.MustNewComponent("symlink"): virtual.InitialNode{}.FromLeaf(symlink),
+	}, false))
+
+	// Creating a snapshot should not cause any state to be copied.
+	snapshot, err := d.CreateSnapshot()
+	require.NoError(t, err)
+
+	// Removing files from the original directory hierarchy should
+	// not affect the snapshot. The file needs to be retained on
+	// behalf of the snapshot.
+	file.EXPECT().Link()
+	dHandle.EXPECT().NotifyRemoval(path.MustNewComponent("file"))
+	file.EXPECT().Unlink()
+	require.NoError(t, d.Remove(path.MustNewComponent("file")))
+
+	// Uploading the snapshot should yield a digest of its root
+	// directory. Directory messages are provided to the callback,
+	// while files are uploaded to the Content Addressable Storage.
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	digestFunction := digest.MustNewFunction("", remoteexecution.DigestFunction_MD5)
+	fileDigest := digest.MustNewDigest("", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	file.EXPECT().Link()
+	file.EXPECT().VirtualGetAttributes(gomock.Any(), virtual.AttributesMaskFileType|virtual.AttributesMaskPermissions, gomock.Any()).
+		Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+			attributes.SetFileType(filesystem.FileTypeRegularFile)
+			attributes.SetPermissions(virtual.PermissionsRead | virtual.PermissionsExecute)
+		})
+	file.EXPECT().UploadFile(ctx, contentAddressableStorage, digestFunction).Return(fileDigest, nil)
+	file.EXPECT().Unlink()
+	symlink.EXPECT().Link()
+	symlink.EXPECT().VirtualGetAttributes(gomock.Any(), virtual.AttributesMaskFileType|virtual.AttributesMaskPermissions, gomock.Any()).
+		Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+			attributes.SetFileType(filesystem.FileTypeSymlink)
+			attributes.SetPermissions(virtual.PermissionsRead | virtual.PermissionsWrite | virtual.PermissionsExecute)
+		})
+	symlink.EXPECT().Readlink().Return("../file", nil)
+	symlink.EXPECT().Unlink()
+
+	directories := map[digest.Digest][]byte{}
+	rootDigest, err := snapshot.Upload(ctx, contentAddressableStorage, digestFunction, func(directoryDigest digest.Digest, data []byte) {
+		directories[directoryDigest] = data
+	})
+	require.NoError(t, err)
+	require.Len(t, directories, 2)
+	var rootDirectory remoteexecution.Directory
+	require.NoError(t, proto.Unmarshal(directories[rootDigest], &rootDirectory))
+	require.Len(t, rootDirectory.Directories, 1)
+	require.Equal(t, "subdirectory", rootDirectory.Directories[0].Name)
+	require.Len(t, rootDirectory.Files, 1)
+	testutil.RequireEqualProto(t, &remoteexecution.FileNode{
+		Name:         "file",
+		Digest:       fileDigest.GetProto(),
+		IsExecutable: true,
+	}, rootDirectory.Files[0])
+
+	subdirectoryDigest, err := digestFunction.NewDigestFromProto(rootDirectory.Directories[0].Digest)
+	require.NoError(t, err)
+	var childDirectory remoteexecution.Directory
+	require.NoError(t, proto.Unmarshal(directories[subdirectoryDigest], &childDirectory))
+	testutil.RequireEqualProto(t, &remoteexecution.Directory{
+		Symlinks: []*remoteexecution.SymlinkNode{{
+			Name:   "symlink",
+			Target: "../file",
+		}},
+	}, &childDirectory)
+
+	// Releasing the snapshot should drop the reference to the file
+	// that was retained on its behalf.
+	file.EXPECT().Unlink()
+	snapshot.Release()
+}
+
+func TestInMemoryPrepopulatedDirectoryCreateSnapshotCopyOnWrite(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	pool := mock.NewMockFilePool(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	fileAllocator := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock)
+	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
+	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
+
+	// Create a file backed by the file pool.
+	underlyingFile1 := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile1, nil)
+	f, s := fileAllocator.NewFile(false, 0, virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+	underlyingFile1.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+	n, s := f.VirtualWrite([]byte("Hello"), 0)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 5, n)
+	f.VirtualClose(virtual.ShareMaskWrite)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("file"))
+	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
+		path.MustNewComponent("file"): virtual.InitialNode{}.FromLeaf(f),
+	}, false))
+
+	// Creating a snapshot should not cause the file's contents to
+	// be copied.
+	snapshot, err := d.CreateSnapshot()
+	require.NoError(t, err)
+
+	// Modifying the original file should cause its contents to be
+	// copied into new storage, so that the snapshot remains
+	// unaffected.
+	underlyingFile2 := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile2, nil)
+	underlyingFile2.EXPECT().Truncate(int64(5))
+	underlyingFile1.EXPECT().GetNextRegionOffset(int64(0), filesystem.Data).Return(int64(0), nil)
+	underlyingFile1.EXPECT().GetNextRegionOffset(int64(0), filesystem.Hole).Return(int64(5), nil)
+	underlyingFile1.EXPECT().ReadAt(gomock.Len(5), int64(0)).DoAndReturn(
+		func(p []byte, off int64) (int, error) {
+			return copy(p, "Hello"), nil
+		})
+	underlyingFile2.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+	underlyingFile2.EXPECT().WriteAt([]byte("!"), int64(5)).Return(1, nil)
+	n, s = f.VirtualWrite([]byte("!"), 5)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 1, n)
+
+	// Uploading the snapshot should yield the original contents of
+	// the file, which are still stored in the original storage.
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	digestFunction := digest.MustNewFunction("", remoteexecution.DigestFunction_MD5)
+	fileDigest := digest.MustNewDigest("", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	underlyingFile1.EXPECT().ReadAt(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(p []byte, off int64) (int, error) {
+			if off >= 5 {
+				return 0, io.EOF
+			}
+			return copy(p, "Hello"[off:]), nil
+		}).AnyTimes()
+	contentAddressableStorage.EXPECT().Put(ctx, fileDigest, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, blobDigest digest.Digest, b buffer.Buffer) error {
+			data, err := b.ToByteSlice(10000)
+			require.NoError(t, err)
+			require.Equal(t, []byte("Hello"), data)
+			return nil
+		})
+
+	var rootDirectory remoteexecution.Directory
+	rootDigest, err := snapshot.Upload(ctx, contentAddressableStorage, digestFunction, func(directoryDigest digest.Digest, data []byte) {
+		require.NoError(t, proto.Unmarshal(data, &rootDirectory))
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, digest.BadDigest, rootDigest)
+	testutil.RequireEqualProto(t, &remoteexecution.Directory{
+		Files: []*remoteexecution.FileNode{{
+			Name:   "file",
+			Digest: fileDigest.GetProto(),
+		}},
+	}, &rootDirectory)
+
+	// Releasing the snapshot should release the original storage.
+	underlyingFile1.EXPECT().Close()
+	snapshot.Release()
+}
+
 func TestInMemoryPrepopulatedDirectoryVirtualOpenChildFileExists(t *testing.T) {
 	ctrl, ctx := gomock.WithContext(context.Background(), t)
 
@@ -495,11 +922,12 @@ func TestInMemoryPrepopulatedDirectoryVirtualOpenChildFileExists(t *testing.T) {
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Create a file at the desired target location.
 	leaf := mock.NewMockNativeLeaf(ctrl)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("target"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("target"): virtual.InitialNode{}.FromLeaf(leaf),
 	}, false))
@@ -524,11 +952,12 @@ func TestInMemoryPrepopulatedDirectoryVirtualOpenChildDirectoryExists(t *testing
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Create a directory at the desired target location.
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("target"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("target"): virtual.InitialNode{}.FromDirectory(virtual.EmptyInitialContentsFetcher),
 	}, false))
@@ -556,7 +985,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualOpenChildAllocationFailure(t *testi
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// File allocation errors should translate to EIO. The actual
 	// error should get forwarded to the error logger.
@@ -580,10 +1009,11 @@ func TestInMemoryPrepopulatedDirectoryVirtualOpenChildInRemovedDirectory(t *test
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Create a reference to a removed child directory.
 	childHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("directory"))
 	child, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("directory"))
 	require.NoError(t, err)
 	dHandle.EXPECT().NotifyRemoval(path.MustNewComponent("directory"))
@@ -629,7 +1059,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualOpenChildSuccess(t *testing.T) {
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Creation of the directory should fully succeed. The file
 	// should be present within the directory afterwards.
@@ -659,6 +1089,87 @@ func TestInMemoryPrepopulatedDirectoryVirtualOpenChildSuccess(t *testing.T) {
 		})
 }
 
+func TestInMemoryPrepopulatedDirectoryVirtualOpenTemporaryFileInRemovedDirectory(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	fileAllocator := mock.NewMockFileAllocator(ctrl)
+	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
+
+	// Create a reference to a removed child directory.
+	childHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("directory"))
+	child, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("directory"))
+	require.NoError(t, err)
+	dHandle.EXPECT().NotifyRemoval(path.MustNewComponent("directory"))
+	childHandle.EXPECT().Release()
+	require.NoError(t, d.Remove(path.MustNewComponent("directory")))
+
+	// Creating temporary files in removed directories should fail,
+	// just like on Linux.
+	var attr virtual.Attributes
+	_, _, s := child.VirtualOpenTemporaryFile(
+		ctx,
+		virtual.ShareMaskWrite,
+		(&virtual.Attributes{}).SetPermissions(virtual.PermissionsRead|virtual.PermissionsWrite),
+		virtual.AttributesMask(0),
+		&attr)
+	require.Equal(t, virtual.StatusErrNoEnt, s)
+}
+
+func TestInMemoryPrepopulatedDirectoryVirtualOpenTemporaryFileSuccess(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	fileAllocator := mock.NewMockFileAllocator(ctrl)
+	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
+	child := mock.NewMockNativeLeaf(ctrl)
+	fileAllocator.EXPECT().NewFile(true, uint64(0), virtual.ShareMaskRead|virtual.ShareMaskWrite).
+		Return(child, virtual.StatusOK)
+	child.EXPECT().VirtualGetAttributes(
+		ctx,
+		virtual.AttributesMaskInodeNumber,
+		gomock.Any(),
+	).Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+		attributes.SetInodeNumber(123)
+	})
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
+	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
+
+	// Creating a temporary file should succeed, but it should not
+	// be visible within the directory.
+	var attr virtual.Attributes
+	newChild, respected, s := d.VirtualOpenTemporaryFile(
+		ctx,
+		virtual.ShareMaskRead|virtual.ShareMaskWrite,
+		(&virtual.Attributes{}).SetPermissions(virtual.PermissionsRead|virtual.PermissionsWrite|virtual.PermissionsExecute),
+		virtual.AttributesMaskInodeNumber,
+		&attr)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, child, newChild)
+	require.Equal(t, virtual.AttributesMaskPermissions, respected)
+	require.Equal(t, *(&virtual.Attributes{}).SetInodeNumber(123), attr)
+
+	entries, err := d.ReadDir()
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	// The temporary file may be linked into the directory
+	// afterwards, similar to linkat() with AT_EMPTY_PATH.
+	child.EXPECT().Link()
+	child.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMask(0), gomock.Any())
+	changeInfo, s := d.VirtualLink(ctx, path.MustNewComponent("target"), child, 0, &attr)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, virtual.ChangeInfo{
+		Before: 0,
+		After:  1,
+	}, changeInfo)
+}
+
 func TestInMemoryPrepopulatedDirectoryVirtualGetAttributes(t *testing.T) {
 	ctrl, ctx := gomock.WithContext(context.Background(), t)
 
@@ -669,7 +1180,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualGetAttributes(t *testing.T) {
 	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
 	clock := mock.NewMockClock(ctrl)
 	clock.EXPECT().Now().Return(time.Unix(1000, 0))
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock, false)
 
 	dHandle.EXPECT().GetAttributes(inMemoryPrepopulatedDirectoryAttributesMask, gomock.Any()).
 		Do(func(attributesMask virtual.AttributesMask, attributes *virtual.Attributes) {
@@ -698,11 +1209,12 @@ func TestInMemoryPrepopulatedDirectoryVirtualLinkExists(t *testing.T) {
 	child := mock.NewMockNativeLeaf(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Attempting to link to a file that already exists should fail.
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("dir"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("dir"): virtual.InitialNode{}.FromDirectory(virtual.EmptyInitialContentsFetcher),
 	}, false))
@@ -720,10 +1232,11 @@ func TestInMemoryPrepopulatedDirectoryVirtualLinkInRemovedDirectory(t *testing.T
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Create a reference to a removed child directory.
 	childHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("directory"))
 	child, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("directory"))
 	require.NoError(t, err)
 	dHandle.EXPECT().NotifyRemoval(path.MustNewComponent("directory"))
@@ -744,7 +1257,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualLinkNotNativeLeaf(t *testing.T) {
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Trying to link a file that does not implement NativeLeaf is
 	// not possible. We can only store leaf nodes that implement
@@ -763,7 +1276,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualLinkStale(t *testing.T) {
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Attempting to link a file that has already been removed
 	// should fail.
@@ -792,7 +1305,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualLinkSuccess(t *testing.T) {
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// We should return the attributes of the existing leaf.
 	var attr virtual.Attributes
@@ -812,15 +1325,17 @@ func TestInMemoryPrepopulatedDirectoryVirtualLookup(t *testing.T) {
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
 	clock := mock.NewMockClock(ctrl)
 	clock.EXPECT().Now().Return(time.Unix(1000, 0))
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock, false)
 
 	// Create an example directory and file that we'll try to look up.
 	subdirHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
 	file := mock.NewMockNativeLeaf(ctrl)
 	clock.EXPECT().Now().Return(time.Unix(1001, 0)).Times(3)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("dir"))
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("file"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("dir"):  virtual.InitialNode{}.FromDirectory(virtual.EmptyInitialContentsFetcher),
 		path.MustNewComponent("file"): virtual.InitialNode{}.FromLeaf(file),
@@ -882,16 +1397,17 @@ func TestInMemoryPrepopulatedDirectoryVirtualMkdir(t *testing.T) {
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
 	clock := mock.NewMockClock(ctrl)
 	clock.EXPECT().Now().Return(time.Unix(1000, 0))
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock, false)
 
 	t.Run("FailureInitialContentsFetcher", func(t *testing.T) {
 		// Create a subdirectory that has an initial contents fetcher.
 		inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
 		initialContentsFetcher := mock.NewMockInitialContentsFetcher(ctrl)
 		clock.EXPECT().Now().Return(time.Unix(1001, 0)).Times(2)
+		dHandle.EXPECT().NotifyAddition(path.MustNewComponent("subdir"))
 		require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 			path.MustNewComponent("subdir"): virtual.InitialNode{}.FromDirectory(initialContentsFetcher),
 		}, false))
@@ -920,6 +1436,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualMkdir(t *testing.T) {
 		// already exists under the provided name.
 		existingFile := mock.NewMockNativeLeaf(ctrl)
 		clock.EXPECT().Now().Return(time.Unix(1002, 0))
+		dHandle.EXPECT().NotifyAddition(path.MustNewComponent("existing_file"))
 		require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 			path.MustNewComponent("existing_file"): virtual.InitialNode{}.FromLeaf(existingFile),
 		}, false))
@@ -965,11 +1482,12 @@ func TestInMemoryPrepopulatedDirectoryVirtualMknodExists(t *testing.T) {
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Files may not be overwritten by mknod().
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("dir"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("dir"): virtual.InitialNode{}.FromDirectory(virtual.EmptyInitialContentsFetcher),
 	}, false))
@@ -986,7 +1504,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualMknodSuccess(t *testing.T) {
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Create a FIFO and a UNIX domain socket.
 	fifoHandleAllocation := mock.NewMockStatefulHandleAllocation(ctrl)
@@ -1056,10 +1574,10 @@ func TestInMemoryPrepopulatedDirectoryVirtualReadDir(t *testing.T) {
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
 	clock := mock.NewMockClock(ctrl)
 	clock.EXPECT().Now().Return(time.Unix(1000, 0))
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock, false)
 
 	// Populate the directory with subdirectory that is
 	// uninitialized and a file.
@@ -1068,6 +1586,9 @@ func TestInMemoryPrepopulatedDirectoryVirtualReadDir(t *testing.T) {
 	childFile1 := mock.NewMockNativeLeaf(ctrl)
 	childFile2 := mock.NewMockNativeLeaf(ctrl)
 	clock.EXPECT().Now().Return(time.Unix(1001, 0)).Times(4)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("directory"))
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("file"))
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("._hidden_file"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("directory"):     virtual.InitialNode{}.FromDirectory(childDirectory),
 		path.MustNewComponent("file"):          virtual.InitialNode{}.FromLeaf(childFile1),
@@ -1117,6 +1638,57 @@ func TestInMemoryPrepopulatedDirectoryVirtualReadDir(t *testing.T) {
 	require.Equal(t, virtual.StatusOK, d.VirtualReadDir(ctx, 0, inMemoryPrepopulatedDirectoryAttributesMask, reporter))
 }
 
+func TestInMemoryPrepopulatedDirectoryVirtualReadDirPaging(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	fileAllocator := mock.NewMockFileAllocator(ctrl)
+	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(1000, 0))
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock, false)
+
+	childFileA := mock.NewMockNativeLeaf(ctrl)
+	childFileB := mock.NewMockNativeLeaf(ctrl)
+	childFileC := mock.NewMockNativeLeaf(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(1001, 0)).Times(3)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("a"))
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("b"))
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("c"))
+	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
+		path.MustNewComponent("a"): virtual.InitialNode{}.FromLeaf(childFileA),
+		path.MustNewComponent("b"): virtual.InitialNode{}.FromLeaf(childFileB),
+		path.MustNewComponent("c"): virtual.InitialNode{}.FromLeaf(childFileC),
+	}, false))
+
+	// Let the first call to VirtualReadDir() only return a single
+	// entry, as if the reporter ran out of space.
+	childFileA.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMask(0), gomock.Any())
+	childFileB.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMask(0), gomock.Any())
+	reporter := mock.NewMockDirectoryEntryReporter(ctrl)
+	gomock.InOrder(
+		reporter.EXPECT().ReportEntry(uint64(1), path.MustNewComponent("a"), virtual.DirectoryChild{}.FromLeaf(childFileA), gomock.Any()).Return(true),
+		reporter.EXPECT().ReportEntry(uint64(2), path.MustNewComponent("b"), virtual.DirectoryChild{}.FromLeaf(childFileB), gomock.Any()).Return(false))
+	require.Equal(t, virtual.StatusOK, d.VirtualReadDir(ctx, 0, 0, reporter))
+
+	// Resuming iteration should continue at the entry that could
+	// not be reported.
+	childFileB.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMask(0), gomock.Any())
+	childFileC.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMask(0), gomock.Any())
+	gomock.InOrder(
+		reporter.EXPECT().ReportEntry(uint64(2), path.MustNewComponent("b"), virtual.DirectoryChild{}.FromLeaf(childFileB), gomock.Any()).Return(true),
+		reporter.EXPECT().ReportEntry(uint64(3), path.MustNewComponent("c"), virtual.DirectoryChild{}.FromLeaf(childFileC), gomock.Any()).Return(true))
+	require.Equal(t, virtual.StatusOK, d.VirtualReadDir(ctx, 1, 0, reporter))
+
+	// Restarting iteration from the beginning should still be
+	// possible, even though the previous position is retained.
+	childFileA.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMask(0), gomock.Any())
+	reporter.EXPECT().ReportEntry(uint64(1), path.MustNewComponent("a"), virtual.DirectoryChild{}.FromLeaf(childFileA), gomock.Any()).Return(false)
+	require.Equal(t, virtual.StatusOK, d.VirtualReadDir(ctx, 0, 0, reporter))
+}
+
 func TestInMemoryPrepopulatedDirectoryVirtualRenameSelfDirectory(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
@@ -1124,15 +1696,17 @@ func TestInMemoryPrepopulatedDirectoryVirtualRenameSelfDirectory(t *testing.T) {
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Renaming a directory to itself should be permitted, even when
 	// it is not empty.
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	childHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("dir"))
 	child, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("dir"))
 	require.NoError(t, err)
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	childHandle.EXPECT().NotifyAddition(path.MustNewComponent("subdir"))
 	require.NoError(t, child.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("subdir"): virtual.InitialNode{}.FromDirectory(virtual.EmptyInitialContentsFetcher),
 	}, false))
@@ -1162,10 +1736,11 @@ func TestInMemoryPrepopulatedDirectoryVirtualRenameSelfFile(t *testing.T) {
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	leaf := mock.NewMockNativeLeaf(ctrl)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("a"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("a"): virtual.InitialNode{}.FromLeaf(leaf),
 	}, false))
@@ -1226,10 +1801,11 @@ func TestInMemoryPrepopulatedDirectoryVirtualRenameDirectoryInRemovedDirectory(t
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Create a reference to a removed child directory.
 	childHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("removed"))
 	child, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("removed"))
 	require.NoError(t, err)
 	dHandle.EXPECT().NotifyRemoval(path.MustNewComponent("removed"))
@@ -1238,6 +1814,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualRenameDirectoryInRemovedDirectory(t
 
 	// Moving a directory into it should fail with ENOENT.
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("dir"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("dir"): virtual.InitialNode{}.FromDirectory(virtual.EmptyInitialContentsFetcher),
 	}, false))
@@ -1260,10 +1837,11 @@ func TestInMemoryPrepopulatedDirectoryVirtualRenameFileInRemovedDirectory(t *tes
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Create a reference to a removed child directory.
 	childHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("removed"))
 	child, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("removed"))
 	require.NoError(t, err)
 	dHandle.EXPECT().NotifyRemoval(path.MustNewComponent("removed"))
@@ -1272,6 +1850,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualRenameFileInRemovedDirectory(t *tes
 
 	// Moving a file into it should fail with ENOENT.
 	leaf := mock.NewMockNativeLeaf(ctrl)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("file"))
 	require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("file"): virtual.InitialNode{}.FromLeaf(leaf),
 	}, false))
@@ -1301,14 +1880,16 @@ func TestInMemoryPrepopulatedDirectoryVirtualRenameDirectoryTwice(t *testing.T)
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// Create two empty directories.
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	childAHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("a"))
 	childA, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("a"))
 	require.NoError(t, err)
 	childBHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("b"))
 	childB, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("b"))
 	require.NoError(t, err)
 
@@ -1345,6 +1926,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualRenameDirectoryTwice(t *testing.T)
 	// Directory "a" got moved over "b", meaning that only the
 	// former should still be usable. The latter has been deleted.
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	childAHandle.EXPECT().NotifyAddition(path.MustNewComponent("subdirectory"))
 	require.NoError(t, childA.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("subdirectory"): virtual.InitialNode{}.FromDirectory(virtual.EmptyInitialContentsFetcher),
 	}, false))
@@ -1361,7 +1943,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualRenameCrossDevice1(t *testing.T) {
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d1 := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d1 := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	d2 := mock.NewMockVirtualDirectory(ctrl)
 
@@ -1379,24 +1961,26 @@ func TestInMemoryPrepopulatedDirectoryVirtualRenameCrossDevice2(t *testing.T) {
 	symlinkFactory1 := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger1 := mock.NewMockErrorLogger(ctrl)
 	handleAllocator1 := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator1)
-	d1 := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator1, symlinkFactory1, errorLogger1, handleAllocator1, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d1Handle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator1)
+	d1 := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator1, symlinkFactory1, errorLogger1, handleAllocator1, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	fileAllocator2 := mock.NewMockFileAllocator(ctrl)
 	symlinkFactory2 := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger2 := mock.NewMockErrorLogger(ctrl)
 	handleAllocator2 := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator2)
-	d2 := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator2, symlinkFactory2, errorLogger2, handleAllocator2, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	d2Handle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator2)
+	d2 := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator2, symlinkFactory2, errorLogger2, handleAllocator2, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	// It should not be possible to rename directories from one
 	// hierarchy to another, as this completely messes up
 	// InMemoryPrepopulatedDirectory's internal bookkeeping.
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator1)
+	d1Handle.EXPECT().NotifyAddition(path.MustNewComponent("src"))
 	require.NoError(t, d1.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("src"): virtual.InitialNode{}.FromDirectory(virtual.EmptyInitialContentsFetcher),
 	}, false))
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator2)
+	d2Handle.EXPECT().NotifyAddition(path.MustNewComponent("dst"))
 	require.NoError(t, d2.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("dst"): virtual.InitialNode{}.FromDirectory(virtual.EmptyInitialContentsFetcher),
 	}, false))
@@ -1410,6 +1994,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualRenameCrossDevice2(t *testing.T) {
 	// even if we disallowed this explicitly, it would still be
 	// possible to achieve this by hardlinking.
 	leaf := mock.NewMockNativeLeaf(ctrl)
+	d1Handle.EXPECT().NotifyAddition(path.MustNewComponent("leaf"))
 	require.NoError(t, d1.CreateChildren(map[path.Component]virtual.InitialNode{
 		path.MustNewComponent("leaf"): virtual.InitialNode{}.FromLeaf(leaf),
 	}, false))
@@ -1425,15 +2010,116 @@ func TestInMemoryPrepopulatedDirectoryVirtualRenameCrossDevice2(t *testing.T) {
 	}, changeInfo2)
 }
 
-func TestInMemoryPrepopulatedDirectoryVirtualRemove(t *testing.T) {
+func TestInMemoryPrepopulatedDirectoryVirtualRenameIntoOwnSubtree(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
 	fileAllocator := mock.NewMockFileAllocator(ctrl)
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
+
+	aHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	dHandle.EXPECT().NotifyAddition(path.MustNewComponent("a"))
+	a, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("a"))
+	require.NoError(t, err)
 	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	aHandle.EXPECT().NotifyAddition(path.MustNewComponent("b"))
+	b, err := a.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent("b"))
+	require.NoError(t, err)
+
+	t.Run("IntoSelf", func(t *testing.T) {
+		_, _, s := d.VirtualRename(path.MustNewComponent("a"), a, path.MustNewComponent("a"))
+		require.Equal(t, virtual.StatusErrInval, s)
+	})
+
+	t.Run("IntoChild", func(t *testing.T) {
+		_, _, s := d.VirtualRename(path.MustNewComponent("a"), b, path.MustNewComponent("a"))
+		require.Equal(t, virtual.StatusErrInval, s)
+	})
+
+	t.Run("AfterMovingChildOut", func(t *testing.T) {
+		// Once "b" is no longer a child of "a", it should be
+		// possible to move "a" into "b".
+		_, _, s := a.VirtualRename(path.MustNewComponent("b"), d, path.MustNewComponent("b"))
+		require.Equal(t, virtual.StatusOK, s)
+		_, _, s = d.VirtualRename(path.MustNewComponent("a"), b, path.MustNewComponent("a"))
+		require.Equal(t, virtual.StatusOK, s)
+
+		// Moving "b" back into "a" should now be forbidden.
+		_, _, s = d.VirtualRename(path.MustNewComponent("b"), a, path.MustNewComponent("b"))
+		require.Equal(t, virtual.StatusErrInval, s)
+	})
+}
+
+func TestInMemoryPrepopulatedDirectoryVirtualRenameStress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	fileAllocator := mock.NewMockFileAllocator(ctrl)
+	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
+
+	// Create a number of uniquely named directories, and let many
+	// goroutines move them around concurrently. This should neither
+	// cause deadlocks, nor lead to the creation of cycles.
+	const directoriesCount = 8
+	directories := []virtual.PrepopulatedDirectory{d}
+	for i := 0; i < directoriesCount; i++ {
+		inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+		dHandle.EXPECT().NotifyAddition(path.MustNewComponent(strconv.FormatInt(int64(i), 10)))
+		child, err := d.CreateAndEnterPrepopulatedDirectory(path.MustNewComponent(strconv.FormatInt(int64(i), 10)))
+		require.NoError(t, err)
+		directories = append(directories, child)
+	}
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(seed))
+			for iteration := 0; iteration < 1000; iteration++ {
+				name := path.MustNewComponent(strconv.FormatInt(int64(r.Intn(directoriesCount)), 10))
+				_, _, s := directories[r.Intn(len(directories))].VirtualRename(
+					name,
+					directories[r.Intn(len(directories))],
+					name)
+				if s != virtual.StatusOK && s != virtual.StatusErrNoEnt && s != virtual.StatusErrInval {
+					t.Errorf("Unexpected status %d", s)
+				}
+			}
+		}(int64(worker))
+	}
+	wg.Wait()
+
+	// All directories should still be reachable from the root
+	// directory.
+	var countReachable func(d virtual.PrepopulatedDirectory) int
+	countReachable = func(d virtual.PrepopulatedDirectory) int {
+		directories, _, err := d.LookupAllChildren()
+		require.NoError(t, err)
+		count := len(directories)
+		for _, directory := range directories {
+			count += countReachable(directory.Child)
+		}
+		return count
+	}
+	require.Equal(t, directoriesCount, countReachable(d))
+}
+
+func TestInMemoryPrepopulatedDirectoryVirtualRemove(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	fileAllocator := mock.NewMockFileAllocator(ctrl)
+	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	t.Run("NotFound", func(t *testing.T) {
 		// Attempting to remove a file that does not exist.
@@ -1446,6 +2132,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualRemove(t *testing.T) {
 		// directory removal should not be performed.
 		inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
 		initialContentsFetcher := mock.NewMockInitialContentsFetcher(ctrl)
+		dHandle.EXPECT().NotifyAddition(path.MustNewComponent("no_directory_removal"))
 		require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 			path.MustNewComponent("no_directory_removal"): virtual.InitialNode{}.FromDirectory(initialContentsFetcher),
 		}, false))
@@ -1458,6 +2145,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualRemove(t *testing.T) {
 		// Attempting to remove a leaf, even though leaf removal
 		// should not be performed.
 		leaf := mock.NewMockNativeLeaf(ctrl)
+		dHandle.EXPECT().NotifyAddition(path.MustNewComponent("no_file_removal"))
 		require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 			path.MustNewComponent("no_file_removal"): virtual.InitialNode{}.FromLeaf(leaf),
 		}, false))
@@ -1472,6 +2160,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualRemove(t *testing.T) {
 		// removed.
 		inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
 		initialContentsFetcher := mock.NewMockInitialContentsFetcher(ctrl)
+		dHandle.EXPECT().NotifyAddition(path.MustNewComponent("broken_directory"))
 		require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 			path.MustNewComponent("broken_directory"): virtual.InitialNode{}.FromDirectory(initialContentsFetcher),
 		}, false))
@@ -1486,6 +2175,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualRemove(t *testing.T) {
 	t.Run("ChildDirectoryNotEmpty", func(t *testing.T) {
 		inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
 		initialContentsFetcher := mock.NewMockInitialContentsFetcher(ctrl)
+		dHandle.EXPECT().NotifyAddition(path.MustNewComponent("non_empty_directory"))
 		require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 			path.MustNewComponent("non_empty_directory"): virtual.InitialNode{}.FromDirectory(initialContentsFetcher),
 		}, false))
@@ -1500,6 +2190,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualRemove(t *testing.T) {
 
 	t.Run("SuccessFile", func(t *testing.T) {
 		leaf := mock.NewMockNativeLeaf(ctrl)
+		dHandle.EXPECT().NotifyAddition(path.MustNewComponent("success"))
 		require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 			path.MustNewComponent("success"): virtual.InitialNode{}.FromLeaf(leaf),
 		}, false))
@@ -1517,8 +2208,9 @@ func TestInMemoryPrepopulatedDirectoryVirtualRemove(t *testing.T) {
 		// Directories may be removed, even if they are not
 		// empty. In that case they should exclusively consist
 		// of hidden files.
-		dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+		childHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
 		initialContentsFetcher := mock.NewMockInitialContentsFetcher(ctrl)
+		dHandle.EXPECT().NotifyAddition(path.MustNewComponent("directory_with_hidden_files"))
 		require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 			path.MustNewComponent("directory_with_hidden_files"): virtual.InitialNode{}.FromDirectory(initialContentsFetcher),
 		}, false))
@@ -1530,7 +2222,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualRemove(t *testing.T) {
 		}, nil)
 		leaf1.EXPECT().Unlink()
 		leaf2.EXPECT().Unlink()
-		dHandle.EXPECT().Release()
+		childHandle.EXPECT().Release()
 
 		changeInfo, s := d.VirtualRemove(path.MustNewComponent("directory_with_hidden_files"), true, true)
 		require.Equal(t, virtual.StatusOK, s)
@@ -1548,13 +2240,14 @@ func TestInMemoryPrepopulatedDirectoryVirtualSymlink(t *testing.T) {
 	symlinkFactory := mock.NewMockSymlinkFactory(ctrl)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	handleAllocator := mock.NewMockStatefulHandleAllocator(ctrl)
-	inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
-	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock)
+	dHandle := inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
+	d := virtual.NewInMemoryPrepopulatedDirectory(fileAllocator, symlinkFactory, errorLogger, handleAllocator, sort.Sort, hiddenFilesPatternForTesting.MatchString, clock.SystemClock, false)
 
 	t.Run("FailureInitialContentsFetcher", func(t *testing.T) {
 		// Create a subdirectory that has an initial contents fetcher.
 		inMemoryPrepopulatedDirectoryExpectMkdir(ctrl, handleAllocator)
 		initialContentsFetcher := mock.NewMockInitialContentsFetcher(ctrl)
+		dHandle.EXPECT().NotifyAddition(path.MustNewComponent("subdir"))
 		require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 			path.MustNewComponent("subdir"): virtual.InitialNode{}.FromDirectory(initialContentsFetcher),
 		}, false))
@@ -1582,6 +2275,7 @@ func TestInMemoryPrepopulatedDirectoryVirtualSymlink(t *testing.T) {
 		// The operation should fail if a file or directory
 		// already exists under the provided name.
 		existingFile := mock.NewMockNativeLeaf(ctrl)
+		dHandle.EXPECT().NotifyAddition(path.MustNewComponent("existing_file"))
 		require.NoError(t, d.CreateChildren(map[path.Component]virtual.InitialNode{
 			path.MustNewComponent("existing_file"): virtual.InitialNode{}.FromLeaf(existingFile),
 		}, false))
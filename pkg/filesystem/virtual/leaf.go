@@ -44,11 +44,21 @@ func (o *OpenExistingOptions) ToAttributesMask() (m AttributesMask) {
 // through NFSv4. Examples of leaf nodes are regular files, sockets,
 // FIFOs, symbolic links and devices.
 //
+// VirtualCopyFileRange() is called against the destination file of a
+// copy_file_range() call. It copies data from the source file without
+// requiring the data to be passed through the kernel.
+//
+// VirtualDeallocate() releases the storage backing a range of a file,
+// causing it to read back as zeroes without altering the file's size.
+// This is used to implement fallocate()'s FALLOC_FL_PUNCH_HOLE mode.
+//
 // TODO: Should all methods take an instance of Context?
 type Leaf interface {
 	Node
 
 	VirtualAllocate(off, size uint64) Status
+	VirtualCopyFileRange(ctx context.Context, source Leaf, offsetIn, offsetOut, length uint64) (uint64, Status)
+	VirtualDeallocate(off, size uint64) Status
 	VirtualSeek(offset uint64, regionType filesystem.RegionType) (*uint64, Status)
 	VirtualOpenSelf(ctx context.Context, shareAccess ShareMask, options *OpenExistingOptions, requested AttributesMask, attributes *Attributes) Status
 	VirtualRead(buf []byte, offset uint64) (n int, eof bool, s Status)
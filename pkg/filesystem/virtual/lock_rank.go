@@ -0,0 +1,39 @@
+package virtual
+
+import (
+	re_sync "github.com/buildbarn/bb-remote-execution/pkg/sync"
+)
+
+// Ranks of the locks used by the virtual file system. Locks of higher
+// rank may be acquired while holding locks of lower rank, but not the
+// other way around. Multiple directory locks may be held at once, as
+// long as they are acquired through re_sync.LockPile.
+//
+// These ranks are only validated when building with the "lockorder"
+// build tag.
+var (
+	renameLockRank = re_sync.LockRank{
+		Name: "InMemoryPrepopulatedDirectory rename",
+		Rank: 1,
+	}
+	directoryLockRank = re_sync.LockRank{
+		Name: "InMemoryPrepopulatedDirectory",
+		Rank: 2,
+	}
+	leafLockRank = re_sync.LockRank{
+		Name: "PoolBackedFileAllocator file",
+		Rank: 3,
+	}
+	deduplicatorLockRank = re_sync.LockRank{
+		Name: "PoolBackedFileAllocator deduplicator",
+		Rank: 4,
+	}
+	handlePoolLockRank = re_sync.LockRank{
+		Name: "NFS handle pool",
+		Rank: 5,
+	}
+	casFileReadaheadLockRank = re_sync.LockRank{
+		Name: "BlobAccessCASFileFactory readahead",
+		Rank: 6,
+	}
+)
@@ -31,6 +31,17 @@ type NativeLeaf interface {
 	// that's based on NativeLeaf.
 	Readlink() (string, error)
 	UploadFile(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function) (digest.Digest, error)
+	// ConvertToCASFile() uploads the contents of a mutable file to
+	// the Content Addressable Storage, and lets the file read its
+	// contents from the Content Addressable Storage from that point
+	// on. This allows resources held by the file (e.g., space in a
+	// FilePool) to be released. The identity of the file remains
+	// unchanged, and the file may still be modified afterwards.
+	//
+	// Leaf nodes that are not mutable files, or that cannot be
+	// converted at this time (e.g., because they are opened for
+	// writing), are left untouched.
+	ConvertToCASFile(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function, casFileFactory CASFileFactory) error
 	// GetContainingDigests() returns a set of digests of objects in
 	// the Content Addressable Storage that back the contents of
 	// this file.
@@ -6,9 +6,31 @@ import (
 	"encoding/binary"
 	"io"
 	"sync"
+	"time"
 
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteoutputservice"
+	re_sync "github.com/buildbarn/bb-remote-execution/pkg/sync"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/digest"
 	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
 	"github.com/buildbarn/bb-storage/pkg/random"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	nfsHandleAllocatorPrometheusMetrics sync.Once
+
+	nfsHandleAllocatorWritebackWaits = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "buildbarn",
+			Subsystem: "virtual",
+			Name:      "nfs_handle_allocator_writeback_waits_total",
+			Help:      "Number of times a file that was about to be uploaded while opened for writing was waited upon to be closed by the NFS client.",
+		},
+		[]string{"result"})
+	nfsHandleAllocatorWritebackWaitsSucceeded = nfsHandleAllocatorWritebackWaits.WithLabelValues("Succeeded")
+	nfsHandleAllocatorWritebackWaitsTimedOut  = nfsHandleAllocatorWritebackWaits.WithLabelValues("TimedOut")
 )
 
 // fileHandleToInodeNumber converts a file handle to an inode number.
@@ -41,12 +63,54 @@ func setAttributesForFileHandle(fileHandle []byte, requested AttributesMask, att
 }
 
 type nfsHandlePool struct {
-	lock                  sync.RWMutex
+	lock                  re_sync.RWMutex
 	randomNumberGenerator random.SingleThreadedGenerator
 	directories           map[uint64]Directory
 	statefulLeaves        map[uint64]*nfsStatefulNativeLeaf
 	statelessLeaves       map[uint64]*nfsStatelessNativeLeaf
 	resolvers             map[uint64]HandleResolver
+
+	writebackLock    sync.RWMutex
+	writebackClock   clock.Clock
+	writebackTimeout time.Duration
+}
+
+// waitForPendingWrites waits for a file that is opened for writing to
+// be closed, so that its contents are not uploaded while the NFS
+// client still holds on to data that needs to be written. Unlike FUSE,
+// NFS provides no means for the server to request that the client
+// writes back data. However, clients are required to write back all
+// data prior to closing a file, as part of close-to-open consistency.
+// As the file may remain opened for an arbitrary amount of time,
+// waiting is bounded by a timeout.
+func (hp *nfsHandlePool) waitForPendingWrites(ctx context.Context, leaf NativeLeaf) {
+	wbl, ok := getUndecoratedLeaf(leaf).(writeBarrierLeaf)
+	if !ok {
+		return
+	}
+
+	hp.writebackLock.RLock()
+	writebackClock := hp.writebackClock
+	writebackTimeout := hp.writebackTimeout
+	hp.writebackLock.RUnlock()
+	if writebackClock == nil {
+		return
+	}
+
+	closed := wbl.getWritableDescriptorsClosedChannel()
+	if closed == nil {
+		return
+	}
+	timer, t := writebackClock.NewTimer(writebackTimeout)
+	select {
+	case <-closed:
+		timer.Stop()
+		nfsHandleAllocatorWritebackWaitsSucceeded.Inc()
+	case <-t:
+		nfsHandleAllocatorWritebackWaitsTimedOut.Inc()
+	case <-ctx.Done():
+		timer.Stop()
+	}
 }
 
 func (hp *nfsHandlePool) createStatelessDirectoryLocked(inodeNumber uint64, underlyingDirectory Directory) Directory {
@@ -107,8 +171,13 @@ var _ StatefulHandleAllocator = (*NFSStatefulHandleAllocator)(nil)
 // NewNFSHandleAllocator creates a new NFSStatefulHandleAllocator that
 // does not have any resolvable objects.
 func NewNFSHandleAllocator(randomNumberGenerator random.SingleThreadedGenerator) *NFSStatefulHandleAllocator {
+	nfsHandleAllocatorPrometheusMetrics.Do(func() {
+		prometheus.MustRegister(nfsHandleAllocatorWritebackWaits)
+	})
+
 	return &NFSStatefulHandleAllocator{
 		pool: &nfsHandlePool{
+			lock:                  re_sync.RWMutex{Rank: &handlePoolLockRank},
 			randomNumberGenerator: randomNumberGenerator,
 			directories:           map[uint64]Directory{},
 			statefulLeaves:        map[uint64]*nfsStatefulNativeLeaf{},
@@ -159,6 +228,20 @@ func (hr *NFSStatefulHandleAllocator) ResolveHandle(r io.ByteReader) (DirectoryC
 	return DirectoryChild{}, StatusErrStale
 }
 
+// SetWritebackTimeout causes the contents of files that are opened for
+// writing to only be uploaded into the Content Addressable Storage or
+// have their digests reported through the Remote Output Service after
+// the NFS client has closed them, waiting at most the provided amount
+// of time. Without it, the contents of such files may be stale, as the
+// client may still hold on to data that is yet to be written.
+func (hr *NFSStatefulHandleAllocator) SetWritebackTimeout(clock clock.Clock, timeout time.Duration) {
+	hp := hr.pool
+	hp.writebackLock.Lock()
+	hp.writebackClock = clock
+	hp.writebackTimeout = timeout
+	hp.writebackLock.Unlock()
+}
+
 // New creates a new stateful handle allocation.
 func (hr *NFSStatefulHandleAllocator) New() StatefulHandleAllocation {
 	return &nfsStatefulHandleAllocation{
@@ -391,6 +474,11 @@ func (dh *nfsStatefulDirectoryHandle) NotifyRemoval(name path.Component) {
 	// https://github.com/torvalds/linux/blob/b05bf5c63b326ce1da84ef42498d8e0e292e694c/fs/nfs/callback_xdr.c#L779-L783
 }
 
+func (dh *nfsStatefulDirectoryHandle) NotifyAddition(name path.Component) {
+	// There is no need to notify clients, as they revalidate the
+	// contents of directories based on the change attribute.
+}
+
 func (dh *nfsStatefulDirectoryHandle) Release() {
 	hp := dh.pool
 	hp.lock.Lock()
@@ -435,6 +523,10 @@ type nfsStatefulNativeLeaf struct {
 	changeID  uint64
 }
 
+func (l *nfsStatefulNativeLeaf) unwrapLeaf() Leaf {
+	return l.NativeLeaf
+}
+
 func (l *nfsStatefulNativeLeaf) Link() Status {
 	hp := l.pool
 	hp.lock.Lock()
@@ -467,6 +559,20 @@ func (l *nfsStatefulNativeLeaf) Unlink() {
 	}
 }
 
+func (l *nfsStatefulNativeLeaf) UploadFile(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function) (digest.Digest, error) {
+	l.pool.waitForPendingWrites(ctx, l.NativeLeaf)
+	return l.NativeLeaf.UploadFile(ctx, contentAddressableStorage, digestFunction)
+}
+
+func (l *nfsStatefulNativeLeaf) GetOutputServiceFileStatus(digestFunction *digest.Function) (*remoteoutputservice.FileStatus, error) {
+	// Only wait for the file to be closed if the digest of the
+	// file is going to be reported.
+	if digestFunction != nil {
+		l.pool.waitForPendingWrites(context.Background(), l.NativeLeaf)
+	}
+	return l.NativeLeaf.GetOutputServiceFileStatus(digestFunction)
+}
+
 func (l *nfsStatefulNativeLeaf) injectAttributes(requested AttributesMask, attributes *Attributes) {
 	setAttributesForFileHandle(l.fileHandle, requested, attributes)
 	if requested&(AttributesMaskChangeID|AttributesMaskLinkCount) != 0 {
@@ -3,10 +3,16 @@ package virtual_test
 import (
 	"bytes"
 	"context"
+	"io"
 	"testing"
+	"time"
 
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
 	"github.com/buildbarn/bb-remote-execution/internal/mock"
 	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/digest"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
@@ -274,3 +280,97 @@ func TestNFSHandleAllocator(t *testing.T) {
 			&attr4)
 	})
 }
+
+func TestNFSHandleAllocatorWriteback(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	randomNumberGenerator := mock.NewMockSingleThreadedGenerator(ctrl)
+	handleAllocator := virtual.NewNFSHandleAllocator(randomNumberGenerator)
+	writebackClock := mock.NewMockClock(ctrl)
+	handleAllocator.SetWritebackTimeout(writebackClock, 10*time.Second)
+
+	// Create a file that is opened for writing, and wrap it.
+	pool := mock.NewMockFilePool(ctrl)
+	underlyingFile := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile, nil)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
+		NewFile(false, 0, virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+
+	underlyingFile.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+	n, s := f.VirtualWrite([]byte("Hello"), 0)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 5, n)
+
+	randomNumberGenerator.EXPECT().Uint64().Return(uint64(0x5ad6b4e8c5c1ed8c))
+	wrappedLeaf := handleAllocator.New().AsNativeLeaf(f)
+
+	fileDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	digestFunction := fileDigest.GetDigestFunction()
+
+	t.Run("TimedOut", func(t *testing.T) {
+		// If the client does not close the file in time, it
+		// should be uploaded regardless.
+		timer := mock.NewMockTimer(ctrl)
+		timerChannel := make(chan time.Time, 1)
+		timerChannel <- time.Unix(1000, 0)
+		writebackClock.EXPECT().NewTimer(10*time.Second).Return(timer, timerChannel)
+
+		underlyingFile.EXPECT().ReadAt(gomock.Any(), int64(0)).DoAndReturn(func(p []byte, off int64) (int, error) {
+			return copy(p, "Hello"), io.EOF
+		})
+		contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+		contentAddressableStorage.EXPECT().Put(ctx, fileDigest, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				b.Discard()
+				return nil
+			})
+
+		blobDigest, err := wrappedLeaf.UploadFile(ctx, contentAddressableStorage, digestFunction)
+		require.NoError(t, err)
+		require.Equal(t, fileDigest, blobDigest)
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		// The client closing the file while waiting should
+		// cause the upload to proceed immediately, as clients
+		// write back all data prior to closing.
+		timer := mock.NewMockTimer(ctrl)
+		writebackClock.EXPECT().NewTimer(10 * time.Second).DoAndReturn(func(d time.Duration) (clock.Timer, <-chan time.Time) {
+			f.VirtualClose(virtual.ShareMaskWrite)
+			return timer, nil
+		})
+		timer.EXPECT().Stop()
+
+		contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+		contentAddressableStorage.EXPECT().Put(ctx, fileDigest, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				b.Discard()
+				return nil
+			})
+
+		blobDigest, err := wrappedLeaf.UploadFile(ctx, contentAddressableStorage, digestFunction)
+		require.NoError(t, err)
+		require.Equal(t, fileDigest, blobDigest)
+	})
+
+	t.Run("NoWritableDescriptors", func(t *testing.T) {
+		// Once the file is no longer opened for writing, there
+		// is no need to wait.
+		contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+		contentAddressableStorage.EXPECT().Put(ctx, fileDigest, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				b.Discard()
+				return nil
+			})
+
+		blobDigest, err := wrappedLeaf.UploadFile(ctx, contentAddressableStorage, digestFunction)
+		require.NoError(t, err)
+		require.Equal(t, fileDigest, blobDigest)
+	})
+
+	underlyingFile.EXPECT().Close()
+	wrappedLeaf.Unlink()
+}
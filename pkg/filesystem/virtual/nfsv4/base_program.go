@@ -5,6 +5,7 @@ import (
 	"context"
 	"io"
 	"math"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,6 +26,12 @@ import (
 // of the server.
 const stateIDOtherPrefixLength = 4
 
+// xattrNamePrefix is prepended to the names of extended attributes
+// that are accessed through the operations added by RFC 8276. These
+// operations only provide access to the user namespace, whereas the
+// virtual file system uses names that include the namespace.
+const xattrNamePrefix = "user."
+
 var (
 	baseProgramPrometheusMetrics sync.Once
 
@@ -57,8 +64,74 @@ var (
 			Name:      "base_program_open_owner_files_removed_total",
 			Help:      "Number of open-owner files removed, either through NFSv4 CLOSE operations or due to inactivity on the open-owner.",
 		})
+
+	baseProgramDelegationsCreated = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "buildbarn",
+			Subsystem: "nfsv4",
+			Name:      "base_program_delegations_created_total",
+			Help:      "Number of read delegations handed out through NFSv4 OPEN operations.",
+		})
+	baseProgramDelegationsRemoved = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "buildbarn",
+			Subsystem: "nfsv4",
+			Name:      "base_program_delegations_removed_total",
+			Help:      "Number of read delegations removed, either through NFSv4 DELEGRETURN operations or due to the client's lease expiring.",
+		})
+
+	baseProgramClientsExpired = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "buildbarn",
+			Subsystem: "nfsv4",
+			Name:      "base_program_clients_expired_total",
+			Help:      "Number of confirmed clients removed due to their lease expiring.",
+		})
+	baseProgramClientState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "buildbarn",
+			Subsystem: "nfsv4",
+			Name:      "base_program_client_state",
+			Help:      "Amount of state currently held by a confirmed client.",
+		},
+		[]string{"client_id", "resource"})
+	baseProgramClientStateLimitsExceeded = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "buildbarn",
+			Subsystem: "nfsv4",
+			Name:      "base_program_client_state_limits_exceeded_total",
+			Help:      "Number of operations that failed with NFS4ERR_RESOURCE, due to a client reaching its limit on the amount of state it may create.",
+		},
+		[]string{"client_id", "resource"})
 )
 
+// ClientStateLimits contains limits on the amount of state that a
+// single client may create on the NFSv4 server. These limits prevent a
+// single misbehaving client from exhausting server resources that are
+// shared with other clients. Limits that are set to zero are not
+// enforced.
+type ClientStateLimits struct {
+	// The maximum number of open-owners (i.e., processes on the
+	// client that have opened files) that a client may have.
+	MaximumOpenOwners int
+	// The maximum number of files that may be opened by all
+	// open-owners of a client combined. As this limit is enforced
+	// before files are opened, clients that have reached it are
+	// also not permitted to upgrade files they already opened.
+	MaximumOpenFiles int
+	// The maximum number of files on which lock-owners of a client
+	// may hold byte-range locks.
+	MaximumLockOwnerFiles int
+
+	// The amount of time for which the server remembers clients
+	// whose lease has expired. During this period, operations
+	// referencing the client return NFS4ERR_EXPIRED instead of
+	// NFS4ERR_STALE_CLIENTID. This informs the client that its
+	// state has been lost due to inactivity, as opposed to the
+	// server having restarted.
+	ExpirationGracePeriod time.Duration
+}
+
 type baseProgram struct {
 	rootFileHandle     fileHandle
 	handleResolver     virtual.HandleResolver
@@ -67,6 +140,8 @@ type baseProgram struct {
 	clock              clock.Clock
 	enforcedLeaseTime  time.Duration
 	announcedLeaseTime nfsv4.NfsLease4
+	clientStateLimits  ClientStateLimits
+	statFSProvider     virtual.StatFSProvider
 
 	lock                         sync.Mutex
 	now                          time.Time
@@ -77,20 +152,51 @@ type baseProgram struct {
 	openOwnerFilesByOther        map[regularStateIDOther]*openOwnerFileState
 	openedFilesByHandle          map[string]*openedFileState
 	lockOwnerFilesByOther        map[regularStateIDOther]*lockOwnerFileState
+	delegationsByOther           map[regularStateIDOther]*delegationState
 	idleClientConfirmations      clientConfirmationState
 	unusedOpenOwners             openOwnerState
+	expiredClientsByShortID      map[nfsv4.Clientid4]struct{}
+	expiredClients               []expiredClient
+}
+
+// expiredClient is a short client ID of a client whose lease has
+// expired, and the time at which the server may forget about it.
+type expiredClient struct {
+	shortClientID nfsv4.Clientid4
+	forgetAfter   time.Time
 }
 
 // NewBaseProgram creates an nfsv4.Nfs4Program that forwards all
 // operations to a virtual file system. It implements most of the
 // features of NFSv4.0.
-func NewBaseProgram(rootDirectory virtual.Directory, handleResolver virtual.HandleResolver, randomNumberGenerator random.SingleThreadedGenerator, rebootVerifier nfsv4.Verifier4, stateIDOtherPrefix [stateIDOtherPrefixLength]byte, clock clock.Clock, enforcedLeaseTime, announcedLeaseTime time.Duration) nfsv4.Nfs4Program {
+//
+// Files that are immutable (e.g., ones backed by the Content
+// Addressable Storage) are handed out to clients with a read
+// delegation. This permits clients to open and close these files
+// locally, without contacting the server. As the contents of these
+// files never change, these delegations never need to be recalled.
+// This means that no use is made of the client's callback path.
+//
+// Limits on the amount of state each client may create are provided
+// through clientStateLimits. Operations that would cause a client to
+// exceed these limits fail with NFS4ERR_RESOURCE.
+//
+// If statFSProvider is not nil, it is used to report the capacity of
+// the file system through the space_* and files_* attributes.
+func NewBaseProgram(rootDirectory virtual.Directory, handleResolver virtual.HandleResolver, randomNumberGenerator random.SingleThreadedGenerator, rebootVerifier nfsv4.Verifier4, stateIDOtherPrefix [stateIDOtherPrefixLength]byte, clock clock.Clock, enforcedLeaseTime, announcedLeaseTime time.Duration, clientStateLimits ClientStateLimits, statFSProvider virtual.StatFSProvider) nfsv4.Nfs4Program {
 	baseProgramPrometheusMetrics.Do(func() {
 		prometheus.MustRegister(baseProgramOpenOwnersCreated)
 		prometheus.MustRegister(baseProgramOpenOwnersRemoved)
 
 		prometheus.MustRegister(baseProgramOpenOwnerFilesCreated)
 		prometheus.MustRegister(baseProgramOpenOwnerFilesRemoved)
+
+		prometheus.MustRegister(baseProgramDelegationsCreated)
+		prometheus.MustRegister(baseProgramDelegationsRemoved)
+
+		prometheus.MustRegister(baseProgramClientsExpired)
+		prometheus.MustRegister(baseProgramClientState)
+		prometheus.MustRegister(baseProgramClientStateLimitsExceeded)
 	})
 
 	var attributes virtual.Attributes
@@ -106,6 +212,8 @@ func NewBaseProgram(rootDirectory virtual.Directory, handleResolver virtual.Hand
 		clock:              clock,
 		enforcedLeaseTime:  enforcedLeaseTime,
 		announcedLeaseTime: nfsv4.NfsLease4(announcedLeaseTime.Seconds()),
+		clientStateLimits:  clientStateLimits,
+		statFSProvider:     statFSProvider,
 
 		randomNumberGenerator:        randomNumberGenerator,
 		clientsByLongID:              map[string]*clientState{},
@@ -114,6 +222,8 @@ func NewBaseProgram(rootDirectory virtual.Directory, handleResolver virtual.Hand
 		openOwnerFilesByOther:        map[regularStateIDOther]*openOwnerFileState{},
 		openedFilesByHandle:          map[string]*openedFileState{},
 		lockOwnerFilesByOther:        map[regularStateIDOther]*lockOwnerFileState{},
+		delegationsByOther:           map[regularStateIDOther]*delegationState{},
+		expiredClientsByShortID:      map[nfsv4.Clientid4]struct{}{},
 	}
 	p.idleClientConfirmations.previousIdle = &p.idleClientConfirmations
 	p.idleClientConfirmations.nextIdle = &p.idleClientConfirmations
@@ -182,12 +292,24 @@ func (p *baseProgram) NfsV4Nfsproc4Compound(ctx context.Context, arguments *nfsv
 				Opgetfh: res,
 			})
 			status = res.GetStatus()
+		case *nfsv4.NfsArgop4_OP_GETXATTR:
+			res := state.opGetxattr(ctx, &op.Opgetxattr)
+			resarray = append(resarray, &nfsv4.NfsResop4_OP_GETXATTR{
+				Opgetxattr: res,
+			})
+			status = res.GetGxrStatus()
 		case *nfsv4.NfsArgop4_OP_LINK:
 			res := state.opLink(ctx, &op.Oplink)
 			resarray = append(resarray, &nfsv4.NfsResop4_OP_LINK{
 				Oplink: res,
 			})
 			status = res.GetStatus()
+		case *nfsv4.NfsArgop4_OP_LISTXATTRS:
+			res := state.opListxattrs(ctx, &op.Oplistxattrs)
+			resarray = append(resarray, &nfsv4.NfsResop4_OP_LISTXATTRS{
+				Oplistxattrs: res,
+			})
+			status = res.GetLxrStatus()
 		case *nfsv4.NfsArgop4_OP_LOCK:
 			res := state.opLock(&op.Oplock)
 			resarray = append(resarray, &nfsv4.NfsResop4_OP_LOCK{
@@ -296,6 +418,12 @@ func (p *baseProgram) NfsV4Nfsproc4Compound(ctx context.Context, arguments *nfsv
 				Opremove: res,
 			})
 			status = res.GetStatus()
+		case *nfsv4.NfsArgop4_OP_REMOVEXATTR:
+			res := state.opRemovexattr(ctx, &op.Opremovexattr)
+			resarray = append(resarray, &nfsv4.NfsResop4_OP_REMOVEXATTR{
+				Opremovexattr: res,
+			})
+			status = res.GetRxrStatus()
 		case *nfsv4.NfsArgop4_OP_RENAME:
 			res := state.opRename(&op.Oprename)
 			resarray = append(resarray, &nfsv4.NfsResop4_OP_RENAME{
@@ -344,6 +472,12 @@ func (p *baseProgram) NfsV4Nfsproc4Compound(ctx context.Context, arguments *nfsv
 				OpsetclientidConfirm: res,
 			})
 			status = res.Status
+		case *nfsv4.NfsArgop4_OP_SETXATTR:
+			res := state.opSetxattr(ctx, &op.Opsetxattr)
+			resarray = append(resarray, &nfsv4.NfsResop4_OP_SETXATTR{
+				Opsetxattr: res,
+			})
+			status = res.GetSxrStatus()
 		case *nfsv4.NfsArgop4_OP_VERIFY:
 			res := state.opVerify(ctx, &op.Opverify)
 			resarray = append(resarray, &nfsv4.NfsResop4_OP_VERIFY{
@@ -393,7 +527,31 @@ func (p *baseProgram) enter() {
 		var ll leavesToClose
 		minimumLastSeen := p.now.Add(-p.enforcedLeaseTime)
 		for p.idleClientConfirmations.nextIdle != &p.idleClientConfirmations && p.idleClientConfirmations.nextIdle.lastSeen.Before(minimumLastSeen) {
-			p.idleClientConfirmations.nextIdle.remove(p, &ll)
+			ccs := p.idleClientConfirmations.nextIdle
+			if confirmedClient := ccs.client.confirmed; confirmedClient != nil && confirmedClient.confirmation == ccs {
+				// Remember that the client expired, so
+				// that it can be informed about the loss
+				// of its state.
+				baseProgramClientsExpired.Inc()
+				if gracePeriod := p.clientStateLimits.ExpirationGracePeriod; gracePeriod > 0 {
+					shortClientID := ccs.key.shortClientID
+					p.expiredClientsByShortID[shortClientID] = struct{}{}
+					p.expiredClients = append(p.expiredClients, expiredClient{
+						shortClientID: shortClientID,
+						forgetAfter:   p.now.Add(gracePeriod),
+					})
+				}
+			}
+			ccs.remove(p, &ll)
+		}
+
+		// Forget about expired clients whose grace period has
+		// ended. As the grace period is constant, these are
+		// stored in the order in which they need to be removed.
+		for len(p.expiredClients) > 0 && !p.expiredClients[0].forgetAfter.After(p.now) {
+			delete(p.expiredClientsByShortID, p.expiredClients[0].shortClientID)
+			p.expiredClients[0] = expiredClient{}
+			p.expiredClients = p.expiredClients[1:]
 		}
 
 		// Remove open-owners that no longer have any open files
@@ -425,6 +583,9 @@ func (p *baseProgram) leave() {
 func (p *baseProgram) getConfirmedClientByShortID(shortID nfsv4.Clientid4) (*confirmedClientState, nfsv4.Nfsstat4) {
 	clientConfirmation, ok := p.clientConfirmationsByShortID[shortID]
 	if !ok {
+		if _, ok := p.expiredClientsByShortID[shortID]; ok {
+			return nil, nfsv4.NFS4ERR_EXPIRED
+		}
 		return nil, nfsv4.NFS4ERR_STALE_CLIENTID
 	}
 	confirmedClient := clientConfirmation.client.confirmed
@@ -527,10 +688,37 @@ func (p *baseProgram) externalizeStateID(stateID regularStateID) nfsv4.Stateid4
 	return externalStateID
 }
 
+// Attributes that are used to report the capacity of the file system,
+// which are only supported if a StatFSProvider is provided.
+const (
+	fileSystemStatisticsAttributes0 uint32 = (1 << nfsv4.FATTR4_FILES_AVAIL) |
+		(1 << nfsv4.FATTR4_FILES_FREE) |
+		(1 << nfsv4.FATTR4_FILES_TOTAL)
+	fileSystemStatisticsAttributes1 uint32 = (1 << (nfsv4.FATTR4_SPACE_AVAIL - 32)) |
+		(1 << (nfsv4.FATTR4_SPACE_FREE - 32)) |
+		(1 << (nfsv4.FATTR4_SPACE_TOTAL - 32))
+)
+
 // writeAttributes converts file attributes returned by the virtual file
 // system into the NFSv4 wire format. It also returns a bitmask
-// indicating which attributes were actually emitted.
-func (p *baseProgram) writeAttributes(attributes *virtual.Attributes, attrRequest nfsv4.Bitmap4, w io.Writer) nfsv4.Bitmap4 {
+// indicating which attributes were actually emitted. The node from
+// which the attributes were obtained is used to determine the capacity
+// of the file system, as directories may have a capacity that differs
+// from the rest of the file system.
+func (p *baseProgram) writeAttributes(node virtual.Node, attributes *virtual.Attributes, attrRequest nfsv4.Bitmap4, w io.Writer) nfsv4.Bitmap4 {
+	// Only obtain the capacity of the file system if any of the
+	// attributes that report it are requested.
+	statFSProvider := virtual.GetStatFSProvider(node, p.statFSProvider)
+	var statistics virtual.FileSystemStatistics
+	hasStatistics := false
+	if statFSProvider != nil &&
+		((len(attrRequest) > 0 && attrRequest[0]&fileSystemStatisticsAttributes0 != 0) ||
+			(len(attrRequest) > 1 && attrRequest[1]&fileSystemStatisticsAttributes1 != 0)) {
+		hasStatistics = statFSProvider.VirtualStatFS(&statistics) == virtual.StatusOK
+	}
+	// Only report the number of files if it is limited.
+	hasFileStatistics := hasStatistics && statistics.TotalFiles > 0
+
 	attrMask := make(nfsv4.Bitmap4, len(attrRequest))
 	if len(attrRequest) > 0 {
 		// Attributes 0 to 31.
@@ -538,7 +726,7 @@ func (p *baseProgram) writeAttributes(attributes *virtual.Attributes, attrReques
 		var s uint32
 		if b := uint32(1 << nfsv4.FATTR4_SUPPORTED_ATTRS); f&b != 0 {
 			s |= b
-			nfsv4.WriteBitmap4(w, nfsv4.Bitmap4{
+			supportedAttributes := nfsv4.Bitmap4{
 				(1 << nfsv4.FATTR4_SUPPORTED_ATTRS) |
 					(1 << nfsv4.FATTR4_TYPE) |
 					(1 << nfsv4.FATTR4_FH_EXPIRE_TYPE) |
@@ -556,9 +744,17 @@ func (p *baseProgram) writeAttributes(attributes *virtual.Attributes, attrReques
 				(1 << (nfsv4.FATTR4_MODE - 32)) |
 					(1 << (nfsv4.FATTR4_NUMLINKS - 32)) |
 					(1 << (nfsv4.FATTR4_TIME_ACCESS - 32)) |
+					(1 << (nfsv4.FATTR4_TIME_ACCESS_SET - 32)) |
 					(1 << (nfsv4.FATTR4_TIME_METADATA - 32)) |
-					(1 << (nfsv4.FATTR4_TIME_MODIFY - 32)),
-			})
+					(1 << (nfsv4.FATTR4_TIME_MODIFY - 32)) |
+					(1 << (nfsv4.FATTR4_TIME_MODIFY_SET - 32)),
+				1 << (nfsv4.FATTR4_XATTR_SUPPORT - 64),
+			}
+			if statFSProvider != nil {
+				supportedAttributes[0] |= fileSystemStatisticsAttributes0
+				supportedAttributes[1] |= fileSystemStatisticsAttributes1
+			}
+			nfsv4.WriteBitmap4(w, supportedAttributes)
 		}
 		if b := uint32(1 << nfsv4.FATTR4_TYPE); f&b != 0 {
 			s |= b
@@ -636,6 +832,18 @@ func (p *baseProgram) writeAttributes(attributes *virtual.Attributes, attrReques
 			s |= b
 			nfsv4.WriteUint64T(w, attributes.GetInodeNumber())
 		}
+		if b := uint32(1 << nfsv4.FATTR4_FILES_AVAIL); f&b != 0 && hasFileStatistics {
+			s |= b
+			nfsv4.WriteUint64T(w, statistics.AvailableFiles)
+		}
+		if b := uint32(1 << nfsv4.FATTR4_FILES_FREE); f&b != 0 && hasFileStatistics {
+			s |= b
+			nfsv4.WriteUint64T(w, statistics.AvailableFiles)
+		}
+		if b := uint32(1 << nfsv4.FATTR4_FILES_TOTAL); f&b != 0 && hasFileStatistics {
+			s |= b
+			nfsv4.WriteUint64T(w, statistics.TotalFiles)
+		}
 		attrMask[0] = s
 	}
 	if len(attrRequest) > 1 {
@@ -652,13 +860,33 @@ func (p *baseProgram) writeAttributes(attributes *virtual.Attributes, attrReques
 			s |= b
 			nfsv4.WriteUint32T(w, attributes.GetLinkCount())
 		}
+		if b := uint32(1 << (nfsv4.FATTR4_SPACE_AVAIL - 32)); f&b != 0 && hasStatistics {
+			s |= b
+			nfsv4.WriteUint64T(w, statistics.AvailableSizeBytes)
+		}
+		if b := uint32(1 << (nfsv4.FATTR4_SPACE_FREE - 32)); f&b != 0 && hasStatistics {
+			s |= b
+			nfsv4.WriteUint64T(w, statistics.AvailableSizeBytes)
+		}
+		if b := uint32(1 << (nfsv4.FATTR4_SPACE_TOTAL - 32)); f&b != 0 && hasStatistics {
+			s |= b
+			nfsv4.WriteUint64T(w, statistics.TotalSizeBytes)
+		}
 		if b := uint32(1 << (nfsv4.FATTR4_TIME_ACCESS - 32)); f&b != 0 {
 			s |= b
-			deterministicNfstime4.WriteTo(w)
+			t := deterministicNfstime4
+			if lastDataAccessTime, ok := attributes.GetLastDataAccessTime(); ok {
+				t = timeToNfstime4(lastDataAccessTime)
+			}
+			t.WriteTo(w)
 		}
 		if b := uint32(1 << (nfsv4.FATTR4_TIME_METADATA - 32)); f&b != 0 {
 			s |= b
-			deterministicNfstime4.WriteTo(w)
+			t := deterministicNfstime4
+			if lastStatusChangeTime, ok := attributes.GetLastStatusChangeTime(); ok {
+				t = timeToNfstime4(lastStatusChangeTime)
+			}
+			t.WriteTo(w)
 		}
 		if b := uint32(1 << (nfsv4.FATTR4_TIME_MODIFY - 32)); f&b != 0 {
 			s |= b
@@ -670,6 +898,16 @@ func (p *baseProgram) writeAttributes(attributes *virtual.Attributes, attrReques
 		}
 		attrMask[1] = s
 	}
+	if len(attrRequest) > 2 {
+		// Attributes 64 to 95.
+		f := attrRequest[2]
+		var s uint32
+		if b := uint32(1 << (nfsv4.FATTR4_XATTR_SUPPORT - 64)); f&b != 0 {
+			s |= b
+			runtime.WriteBool(w, true)
+		}
+		attrMask[2] = s
+	}
 	return attrMask
 }
 
@@ -677,9 +915,9 @@ func (p *baseProgram) writeAttributes(attributes *virtual.Attributes, attrReques
 // system layer to an NFSv4 fattr4 structure. As required by the
 // protocol, attributes are stored in the order of the FATTR4_*
 // constants.
-func (p *baseProgram) attributesToFattr4(attributes *virtual.Attributes, attrRequest nfsv4.Bitmap4) nfsv4.Fattr4 {
+func (p *baseProgram) attributesToFattr4(node virtual.Node, attributes *virtual.Attributes, attrRequest nfsv4.Bitmap4) nfsv4.Fattr4 {
 	w := bytes.NewBuffer(nil)
-	attrMask := p.writeAttributes(attributes, attrRequest, w)
+	attrMask := p.writeAttributes(node, attributes, attrRequest, w)
 	return nfsv4.Fattr4{
 		Attrmask: attrMask,
 		AttrVals: w.Bytes(),
@@ -765,6 +1003,28 @@ func (s *compoundState) getLockOwnerFileByStateID(stateID regularStateID) (*lock
 	return lofs, nfsv4.NFS4_OK
 }
 
+// getDelegationByStateID obtains a read delegation by state ID. It also
+// checks whether the delegation state ID corresponds to the current
+// file handle, and that the client provided sequence ID matches the
+// server's value.
+func (s *compoundState) getDelegationByStateID(stateID regularStateID) (*delegationState, nfsv4.Nfsstat4) {
+	p := s.program
+	ds, ok := p.delegationsByOther[stateID.other]
+	if !ok {
+		return nil, nfsv4.NFS4ERR_BAD_STATEID
+	}
+	if !s.currentFileHandle.node.IsSet() {
+		return nil, nfsv4.NFS4ERR_NOFILEHANDLE
+	}
+	if !bytes.Equal(s.currentFileHandle.handle, ds.handle) {
+		return nil, nfsv4.NFS4ERR_BAD_STATEID
+	}
+	if st := compareStateSeqID(stateID.seqID, ds.stateID.seqID); st != nfsv4.NFS4_OK {
+		return nil, st
+	}
+	return ds, nfsv4.NFS4_OK
+}
+
 // getOpenedLeaf is used by READ and WRITE operations to obtain an
 // opened leaf corresponding to a file handle and open-owner state ID.
 //
@@ -802,6 +1062,43 @@ func (s *compoundState) getOpenedLeaf(ctx context.Context, stateID *nfsv4.Statei
 	}
 
 	p.enter()
+	if ds, st := s.getDelegationByStateID(*internalStateID); st != nfsv4.NFS4ERR_BAD_STATEID {
+		// Client provided a read delegation state ID. As
+		// delegations don't keep files opened, temporarily open
+		// the file to perform the operation.
+		if st != nfsv4.NFS4_OK {
+			p.leave()
+			return nil, nil, st
+		}
+		if shareAccess&^virtual.ShareMaskRead != 0 {
+			p.leave()
+			return nil, nil, nfsv4.NFS4ERR_OPENMODE
+		}
+		clientConfirmation := ds.confirmedClient.confirmation
+		clientConfirmation.hold(p)
+		leaf := ds.leaf
+		p.leave()
+
+		release := func() {
+			p.enter()
+			clientConfirmation.release(p)
+			p.leave()
+		}
+		if vs := leaf.VirtualOpenSelf(
+			ctx,
+			shareAccess,
+			&virtual.OpenExistingOptions{},
+			0,
+			&virtual.Attributes{},
+		); vs != virtual.StatusOK {
+			release()
+			return nil, nil, toNFSv4Status(vs)
+		}
+		return leaf, func() {
+			leaf.VirtualClose(shareAccess)
+			release()
+		}, nfsv4.NFS4_OK
+	}
 	defer p.leave()
 
 	oofs, st := s.getOpenOwnerFileByStateID(*internalStateID, false)
@@ -866,7 +1163,7 @@ func (s *compoundState) verifyAttributes(ctx context.Context, fattr *nfsv4.Fattr
 		status:    nfsv4.NFS4ERR_SAME,
 	}
 	p := s.program
-	attrMask := p.writeAttributes(&attributes, attrRequest, &w)
+	attrMask := p.writeAttributes(currentNode, &attributes, attrRequest, &w)
 
 	for i := 0; i < len(attrRequest); i++ {
 		if attrMask[i] != attrRequest[i] {
@@ -895,8 +1192,8 @@ func (s *compoundState) opAccess(ctx context.Context, args *nfsv4.Access4args) n
 
 	// Depending on whether the node is a directory or a leaf, we
 	// need to report different NFSv4 acccess permissions.
-	readMask := uint32(nfsv4.ACCESS4_READ)
-	writeMask := uint32(nfsv4.ACCESS4_EXTEND | nfsv4.ACCESS4_MODIFY)
+	readMask := uint32(nfsv4.ACCESS4_READ | nfsv4.ACCESS4_XAREAD | nfsv4.ACCESS4_XALIST)
+	writeMask := uint32(nfsv4.ACCESS4_EXTEND | nfsv4.ACCESS4_MODIFY | nfsv4.ACCESS4_XAWRITE)
 	executeMask := uint32(0)
 	if isDirectory {
 		writeMask |= nfsv4.ACCESS4_DELETE
@@ -1057,10 +1354,21 @@ func (s *compoundState) opDelegpurge(args *nfsv4.Delegpurge4args) nfsv4.Delegpur
 }
 
 func (s *compoundState) opDelegreturn(args *nfsv4.Delegreturn4args) nfsv4.Delegreturn4res {
-	// This implementation never hands out any delegations to the
-	// client, meaning that any state ID provided to this operation
-	// is invalid.
-	return nfsv4.Delegreturn4res{Status: nfsv4.NFS4ERR_BAD_STATEID}
+	p := s.program
+	delegationStateID, st := p.internalizeRegularStateID(&args.DelegStateid)
+	if st != nfsv4.NFS4_OK {
+		return nfsv4.Delegreturn4res{Status: st}
+	}
+
+	p.enter()
+	defer p.leave()
+
+	ds, st := s.getDelegationByStateID(delegationStateID)
+	if st != nfsv4.NFS4_OK {
+		return nfsv4.Delegreturn4res{Status: st}
+	}
+	ds.remove(p)
+	return nfsv4.Delegreturn4res{Status: nfsv4.NFS4_OK}
 }
 
 func (s *compoundState) opGetattr(ctx context.Context, args *nfsv4.Getattr4args) nfsv4.Getattr4res {
@@ -1073,7 +1381,7 @@ func (s *compoundState) opGetattr(ctx context.Context, args *nfsv4.Getattr4args)
 	p := s.program
 	return &nfsv4.Getattr4res_NFS4_OK{
 		Resok4: nfsv4.Getattr4resok{
-			ObjAttributes: p.attributesToFattr4(&attributes, args.AttrRequest),
+			ObjAttributes: p.attributesToFattr4(currentNode, &attributes, args.AttrRequest),
 		},
 	}
 }
@@ -1090,6 +1398,23 @@ func (s *compoundState) opGetfh() nfsv4.Getfh4res {
 	}
 }
 
+func (s *compoundState) opGetxattr(ctx context.Context, args *nfsv4.Getxattr4args) nfsv4.Getxattr4res {
+	currentNode, _, st := s.currentFileHandle.getNode()
+	if st != nfsv4.NFS4_OK {
+		return &nfsv4.Getxattr4res_default{GxrStatus: st}
+	}
+	if args.GxaName == "" {
+		return &nfsv4.Getxattr4res_default{GxrStatus: nfsv4.NFS4ERR_INVAL}
+	}
+	value, vs := currentNode.VirtualGetXAttr(ctx, xattrNamePrefix+args.GxaName)
+	if vs != virtual.StatusOK {
+		return &nfsv4.Getxattr4res_default{GxrStatus: toNFSv4Status(vs)}
+	}
+	return &nfsv4.Getxattr4res_NFS4_OK{
+		GxrValue: value,
+	}
+}
+
 func (s *compoundState) opLink(ctx context.Context, args *nfsv4.Link4args) nfsv4.Link4res {
 	sourceLeaf, st := s.savedFileHandle.getLeaf()
 	if st != nfsv4.NFS4_OK {
@@ -1114,6 +1439,58 @@ func (s *compoundState) opLink(ctx context.Context, args *nfsv4.Link4args) nfsv4
 	}
 }
 
+func (s *compoundState) opListxattrs(ctx context.Context, args *nfsv4.Listxattrs4args) nfsv4.Listxattrs4res {
+	currentNode, _, st := s.currentFileHandle.getNode()
+	if st != nfsv4.NFS4_OK {
+		return &nfsv4.Listxattrs4res_default{LxrStatus: st}
+	}
+	allNames, vs := currentNode.VirtualListXAttr(ctx)
+	if vs != virtual.StatusOK {
+		return &nfsv4.Listxattrs4res_default{LxrStatus: toNFSv4Status(vs)}
+	}
+
+	// Only report extended attributes in the user namespace, as
+	// those are the only ones that can be accessed through NFSv4.
+	names := make([]string, 0, len(allNames))
+	for _, name := range allNames {
+		if strings.HasPrefix(name, xattrNamePrefix) && len(name) > len(xattrNamePrefix) {
+			names = append(names, name[len(xattrNamePrefix):])
+		}
+	}
+
+	// The cookie is the index of the first extended attribute to
+	// return. Return as many names as fit in the maximum response
+	// size, which includes the cookie, the length of the list of
+	// names, and the EOF flag.
+	if args.LxaCookie > uint64(len(names)) {
+		return &nfsv4.Listxattrs4res_default{LxrStatus: nfsv4.NFS4ERR_BAD_COOKIE}
+	}
+	remainingSizeBytes := int64(args.LxaMaxcount) - 16
+	if remainingSizeBytes < 0 {
+		return &nfsv4.Listxattrs4res_default{LxrStatus: nfsv4.NFS4ERR_TOOSMALL}
+	}
+	cookie := args.LxaCookie
+	var returnedNames []string
+	for ; cookie < uint64(len(names)); cookie++ {
+		name := names[cookie]
+		remainingSizeBytes -= 4 + int64((len(name)+3)&^3)
+		if remainingSizeBytes < 0 {
+			if len(returnedNames) == 0 {
+				return &nfsv4.Listxattrs4res_default{LxrStatus: nfsv4.NFS4ERR_TOOSMALL}
+			}
+			break
+		}
+		returnedNames = append(returnedNames, name)
+	}
+	return &nfsv4.Listxattrs4res_NFS4_OK{
+		LxrValue: nfsv4.Listxattrs4resok{
+			LxrCookie: cookie,
+			LxrNames:  returnedNames,
+			LxrEof:    cookie == uint64(len(names)),
+		},
+	}
+}
+
 func (s *compoundState) opLock(args *nfsv4.Lock4args) nfsv4.Lock4res {
 	var ll leavesToClose
 	defer ll.closeAll()
@@ -1193,6 +1570,12 @@ func (s *compoundState) txLockInitial(args *nfsv4.Lock4args, openStateID regular
 	}
 
 	confirmedClient := oos.confirmedClient
+	p := s.program
+	if limit := p.clientStateLimits.MaximumLockOwnerFiles; limit > 0 && confirmedClient.lockOwnerFilesCount >= limit {
+		confirmedClient.metrics.lockOwnerFilesLimitExceeded.Inc()
+		return &nfsv4.Lock4res_default{Status: nfsv4.NFS4ERR_RESOURCE}
+	}
+
 	lockOwnerKey := string(owner.LockOwner.Owner)
 	los, ok := confirmedClient.lockOwners[lockOwnerKey]
 	initialTransaction := false
@@ -1217,7 +1600,6 @@ func (s *compoundState) txLockInitial(args *nfsv4.Lock4args, openStateID regular
 	}
 
 	// Start a nested transaction on the lock-owner.
-	p := s.program
 	transaction, lastResponse, st := los.startTransaction(p, owner.LockSeqid, initialTransaction)
 	if st != nfsv4.NFS4_OK {
 		if initialTransaction {
@@ -1241,6 +1623,8 @@ func (s *compoundState) txLockInitial(args *nfsv4.Lock4args, openStateID regular
 	p.lockOwnerFilesByOther[lofs.stateID.other] = lofs
 	oofs.lockOwnerFiles[los] = lofs
 	los.files = append(los.files, lofs)
+	confirmedClient.lockOwnerFilesCount++
+	confirmedClient.metrics.lockOwnerFiles.Inc()
 
 	response := s.txLockCommon(args, lofs)
 	transaction.complete(response)
@@ -1474,6 +1858,11 @@ func (s *compoundState) opOpen(ctx context.Context, args *nfsv4.Open4args) nfsv4
 		var ok bool
 		oos, ok = confirmedClient.openOwners[openOwnerKey]
 		if !ok {
+			if limit := p.clientStateLimits.MaximumOpenOwners; limit > 0 && len(confirmedClient.openOwners) >= limit {
+				confirmedClient.metrics.openOwnersLimitExceeded.Inc()
+				return &nfsv4.Open4res_default{Status: nfsv4.NFS4ERR_RESOURCE}
+			}
+
 			// Open-owner has never been seen before. Create
 			// a new one that is in the unconfirmed state.
 			oos = &openOwnerState{
@@ -1482,6 +1871,7 @@ func (s *compoundState) opOpen(ctx context.Context, args *nfsv4.Open4args) nfsv4
 				filesByHandle:   map[string]*openOwnerFileState{},
 			}
 			confirmedClient.openOwners[openOwnerKey] = oos
+			confirmedClient.metrics.openOwners.Inc()
 			baseProgramOpenOwnersCreated.Inc()
 		}
 
@@ -1545,7 +1935,7 @@ func (s *compoundState) txOpen(ctx context.Context, args *nfsv4.Open4args, oos *
 		switch how := openHow.How.(type) {
 		case *nfsv4.Createhow4_UNCHECKED4:
 			// Create a file, allowing the file to already exist.
-			if st := fattr4ToAttributes(&how.Createattrs, createAttributes); st != nfsv4.NFS4_OK {
+			if st := p.fattr4ToAttributes(&how.Createattrs, createAttributes); st != nfsv4.NFS4_OK {
 				return &nfsv4.Open4res_default{Status: st}
 			}
 			existingOptions = &virtual.OpenExistingOptions{}
@@ -1554,7 +1944,7 @@ func (s *compoundState) txOpen(ctx context.Context, args *nfsv4.Open4args, oos *
 			}
 		case *nfsv4.Createhow4_GUARDED4:
 			// Create a file, disallowing the file to already exist.
-			if st := fattr4ToAttributes(&how.Createattrs, createAttributes); st != nfsv4.NFS4_OK {
+			if st := p.fattr4ToAttributes(&how.Createattrs, createAttributes); st != nfsv4.NFS4_OK {
 				return &nfsv4.Open4res_default{Status: st}
 			}
 		case *nfsv4.Createhow4_EXCLUSIVE4:
@@ -1570,10 +1960,50 @@ func (s *compoundState) txOpen(ctx context.Context, args *nfsv4.Open4args, oos *
 		existingOptions = &virtual.OpenExistingOptions{}
 	}
 
-	// Convert claim. As we don't support delegations, we can only
-	// meaningfully support CLAIM_NULL and CLAIM_PREVIOUS.
-	switch claim := args.Claim.(type) {
+	// Convert claim. As delegations are never recalled, there is no
+	// need to support CLAIM_DELEGATE_PREV. CLAIM_DELEGATE_CUR is
+	// used by clients to convert opens performed locally into
+	// regular opens prior to returning a delegation. These can be
+	// processed like CLAIM_NULL.
+	openClaim := args.Claim
+	if claim, ok := openClaim.(*nfsv4.OpenClaim4_CLAIM_DELEGATE_CUR); ok {
+		delegationStateID, st := p.internalizeRegularStateID(&claim.DelegateCurInfo.DelegateStateid)
+		if st != nfsv4.NFS4_OK {
+			return &nfsv4.Open4res_default{Status: st}
+		}
+		ds, ok := p.delegationsByOther[delegationStateID.other]
+		if !ok || ds.confirmedClient != oos.confirmedClient {
+			return &nfsv4.Open4res_default{Status: nfsv4.NFS4ERR_BAD_STATEID}
+		}
+		openClaim = &nfsv4.OpenClaim4_CLAIM_NULL{File: claim.DelegateCurInfo.File}
+	}
+	switch claim := openClaim.(type) {
 	case *nfsv4.OpenClaim4_CLAIM_NULL:
+		// VirtualOpenChild() may create or truncate the file.
+		// Reserve capacity for the opened file up front, so
+		// that the operation is not performed if the client
+		// has too many files opened. The reservation is
+		// released if the open-owner already opened the file,
+		// or if opening fails.
+		confirmedClient := oos.confirmedClient
+		if limit := p.clientStateLimits.MaximumOpenFiles; limit > 0 && confirmedClient.openFilesCount >= limit {
+			confirmedClient.metrics.openFilesLimitExceeded.Inc()
+			return &nfsv4.Open4res_default{Status: nfsv4.NFS4ERR_RESOURCE}
+		}
+		confirmedClient.openFilesCount++
+		confirmedClient.metrics.openFiles.Inc()
+		openFileReserved := true
+		defer func() {
+			if openFileReserved {
+				if !isLocked {
+					p.enter()
+					isLocked = true
+				}
+				confirmedClient.openFilesCount--
+				confirmedClient.metrics.openFiles.Dec()
+			}
+		}()
+
 		p.leave()
 		isLocked = false
 
@@ -1595,7 +2025,7 @@ func (s *compoundState) txOpen(ctx context.Context, args *nfsv4.Open4args, oos *
 			shareAccess,
 			createAttributes,
 			existingOptions,
-			virtual.AttributesMaskFileHandle,
+			virtual.AttributesMaskFileHandle|virtual.AttributesMaskIsImmutable,
 			&attributes)
 		if vs != virtual.StatusOK {
 			return &nfsv4.Open4res_default{Status: toNFSv4Status(vs)}
@@ -1661,11 +2091,39 @@ func (s *compoundState) txOpen(ctx context.Context, args *nfsv4.Open4args, oos *
 				panic("Share access reservations can't overlap for newly created files")
 			}
 			oos.filesByHandle[handleKey] = oofs
+			openFileReserved = false
 			p.openOwnerFilesByOther[oofs.stateID.other] = oofs
 			baseProgramOpenOwnerFilesCreated.Inc()
 		}
 
 		response.Resok4.Stateid = p.externalizeStateID(oofs.stateID)
+
+		// Hand out a read delegation if the file is immutable,
+		// and the client does not already hold one.
+		if _, ok := confirmedClient.delegationsByHandle[handleKey]; !ok && shareAccess&virtual.ShareMaskWrite == 0 && attributes.GetIsImmutable() {
+			ds := &delegationState{
+				confirmedClient: confirmedClient,
+				handle:          handle,
+				handleKey:       handleKey,
+				leaf:            leaf,
+				stateID:         p.newRegularStateID(1),
+			}
+			confirmedClient.delegationsByHandle[handleKey] = ds
+			confirmedClient.metrics.delegations.Inc()
+			p.delegationsByOther[ds.stateID.other] = ds
+			baseProgramDelegationsCreated.Inc()
+
+			response.Resok4.Delegation = &nfsv4.OpenDelegation4_OPEN_DELEGATE_READ{
+				Read: nfsv4.OpenReadDelegation4{
+					Stateid: p.externalizeStateID(ds.stateID),
+					// Leave the access control entry
+					// empty, so that the client still
+					// calls ACCESS to check permissions.
+					Permissions: nfsv4.Nfsace4{},
+				},
+			}
+		}
+
 		if !oos.confirmed {
 			// The first time that this open-owner is used. Request
 			// that the caller issues an OPEN_CONFIRM operation.
@@ -1728,8 +2186,6 @@ func (s *compoundState) txOpen(ctx context.Context, args *nfsv4.Open4args, oos *
 				Delegation: &nfsv4.OpenDelegation4_OPEN_DELEGATE_NONE{},
 			},
 		}
-	case *nfsv4.OpenClaim4_CLAIM_DELEGATE_CUR:
-		return &nfsv4.Open4res_default{Status: nfsv4.NFS4ERR_RECLAIM_BAD}
 	case *nfsv4.OpenClaim4_CLAIM_DELEGATE_PREV:
 		return &nfsv4.Open4res_default{Status: nfsv4.NFS4ERR_NOTSUPP}
 	default:
@@ -2085,6 +2541,27 @@ func (s *compoundState) opRemove(args *nfsv4.Remove4args) nfsv4.Remove4res {
 	}
 }
 
+func (s *compoundState) opRemovexattr(ctx context.Context, args *nfsv4.Removexattr4args) nfsv4.Removexattr4res {
+	currentNode, _, st := s.currentFileHandle.getNode()
+	if st != nfsv4.NFS4_OK {
+		return &nfsv4.Removexattr4res_default{RxrStatus: st}
+	}
+	if args.RxaName == "" {
+		return &nfsv4.Removexattr4res_default{RxrStatus: nfsv4.NFS4ERR_INVAL}
+	}
+
+	before := getChangeID(ctx, currentNode)
+	if vs := currentNode.VirtualRemoveXAttr(ctx, xattrNamePrefix+args.RxaName); vs != virtual.StatusOK {
+		return &nfsv4.Removexattr4res_default{RxrStatus: toNFSv4Status(vs)}
+	}
+	return &nfsv4.Removexattr4res_NFS4_OK{
+		RxrInfo: nfsv4.ChangeInfo4{
+			Before: before,
+			After:  getChangeID(ctx, currentNode),
+		},
+	}
+}
+
 func (s *compoundState) opRenew(args *nfsv4.Renew4args) nfsv4.Renew4res {
 	p := s.program
 	p.enter()
@@ -2155,7 +2632,7 @@ func (s *compoundState) opSetattr(ctx context.Context, args *nfsv4.Setattr4args)
 		return nfsv4.Setattr4res{Status: st}
 	}
 	var attributes virtual.Attributes
-	if st := fattr4ToAttributes(&args.ObjAttributes, &attributes); st != nfsv4.NFS4_OK {
+	if st := s.program.fattr4ToAttributes(&args.ObjAttributes, &attributes); st != nfsv4.NFS4_OK {
 		return nfsv4.Setattr4res{Status: st}
 	}
 	if vs := currentNode.VirtualSetAttributes(ctx, &attributes, 0, &virtual.Attributes{}); vs != virtual.StatusOK {
@@ -2256,15 +2733,49 @@ func (s *compoundState) opSetclientidConfirm(args *nfsv4.SetclientidConfirm4args
 			panic("Attempted to replace confirmed client record")
 		}
 		client.confirmed = &confirmedClientState{
-			confirmation: confirmation,
-			openOwners:   map[string]*openOwnerState{},
-			lockOwners:   map[string]*lockOwnerState{},
+			confirmation:        confirmation,
+			openOwners:          map[string]*openOwnerState{},
+			lockOwners:          map[string]*lockOwnerState{},
+			delegationsByHandle: map[string]*delegationState{},
+			metrics:             newClientMetrics(client.longID),
 		}
 	}
 
 	return nfsv4.SetclientidConfirm4res{Status: nfsv4.NFS4_OK}
 }
 
+func (s *compoundState) opSetxattr(ctx context.Context, args *nfsv4.Setxattr4args) nfsv4.Setxattr4res {
+	currentNode, _, st := s.currentFileHandle.getNode()
+	if st != nfsv4.NFS4_OK {
+		return &nfsv4.Setxattr4res_default{SxrStatus: st}
+	}
+	if args.SxaKey == "" {
+		return &nfsv4.Setxattr4res_default{SxrStatus: nfsv4.NFS4ERR_INVAL}
+	}
+	var mode virtual.XAttrSetMode
+	switch args.SxaOption {
+	case nfsv4.SETXATTR4_EITHER:
+		mode = virtual.XAttrSetModeCreateOrReplace
+	case nfsv4.SETXATTR4_CREATE:
+		mode = virtual.XAttrSetModeCreate
+	case nfsv4.SETXATTR4_REPLACE:
+		mode = virtual.XAttrSetModeReplace
+	default:
+		return &nfsv4.Setxattr4res_default{SxrStatus: nfsv4.NFS4ERR_INVAL}
+	}
+
+	before := getChangeID(ctx, currentNode)
+	if vs := currentNode.VirtualSetXAttr(ctx, xattrNamePrefix+args.SxaKey, args.SxaValue, mode); vs != virtual.StatusOK {
+		return &nfsv4.Setxattr4res_default{SxrStatus: toNFSv4Status(vs)}
+	}
+	return &nfsv4.Setxattr4res_NFS4_OK{
+		SxrInfo: nfsv4.ChangeInfo4{
+			Before: before,
+			After:  getChangeID(ctx, currentNode),
+		},
+	}
+}
+
 func (s *compoundState) opWrite(ctx context.Context, args *nfsv4.Write4args) nfsv4.Write4res {
 	currentLeaf, cleanup, st := s.getOpenedLeaf(ctx, &args.Stateid, virtual.ShareMaskWrite)
 	if st != nfsv4.NFS4_OK {
@@ -2468,6 +2979,10 @@ func toNFSv4Status(s virtual.Status) nfsv4.Nfsstat4 {
 		return nfsv4.NFS4ERR_ISDIR
 	case virtual.StatusErrNoEnt:
 		return nfsv4.NFS4ERR_NOENT
+	case virtual.StatusErrNoSpc:
+		return nfsv4.NFS4ERR_NOSPC
+	case virtual.StatusErrNoXAttr:
+		return nfsv4.NFS4ERR_NOXATTR
 	case virtual.StatusErrNotDir:
 		return nfsv4.NFS4ERR_NOTDIR
 	case virtual.StatusErrNotEmpty:
@@ -2489,6 +3004,15 @@ func toNFSv4Status(s virtual.Status) nfsv4.Nfsstat4 {
 	}
 }
 
+// getChangeID returns the change ID of a node. It is used to report
+// change information for operations that modify extended attributes,
+// which aren't returned by the virtual file system.
+func getChangeID(ctx context.Context, node virtual.Node) uint64 {
+	var attributes virtual.Attributes
+	node.VirtualGetAttributes(ctx, virtual.AttributesMaskChangeID, &attributes)
+	return attributes.GetChangeID()
+}
+
 // toNFSv4ChangeInfo converts directory change information returned by
 // the virtual file system to its NFSv4 equivalent.
 func toNFSv4ChangeInfo(changeInfo *virtual.ChangeInfo) nfsv4.ChangeInfo4 {
@@ -2589,6 +3113,10 @@ func (ccs *clientConfirmationState) remove(p *baseProgram, ll *leavesToClose) {
 		if len(confirmedClient.lockOwners) != 0 {
 			panic("Removing open-owners should have removed lock-owners as well")
 		}
+		for _, ds := range confirmedClient.delegationsByHandle {
+			ds.remove(p)
+		}
+		confirmedClient.metrics.remove()
 		client.confirmed = nil
 	}
 
@@ -2607,9 +3135,65 @@ func (ccs *clientConfirmationState) remove(p *baseProgram, ll *leavesToClose) {
 // confirmedClientState stores all state for a client that has been
 // confirmed through SETCLIENTID_CONFIRM.
 type confirmedClientState struct {
-	confirmation *clientConfirmationState
-	openOwners   map[string]*openOwnerState
-	lockOwners   map[string]*lockOwnerState
+	confirmation        *clientConfirmationState
+	openOwners          map[string]*openOwnerState
+	lockOwners          map[string]*lockOwnerState
+	delegationsByHandle map[string]*delegationState
+
+	// The number of files opened by all open-owners, and the
+	// number of files associated with all lock-owners. These are
+	// used to enforce ClientStateLimits.
+	openFilesCount      int
+	lockOwnerFilesCount int
+
+	metrics *clientMetrics
+}
+
+// clientMetrics contains the Prometheus metrics that are tracked for a
+// single confirmed client. These permit identifying clients that
+// create an excessive amount of state.
+type clientMetrics struct {
+	clientID string
+
+	openOwners     prometheus.Gauge
+	openFiles      prometheus.Gauge
+	lockOwnerFiles prometheus.Gauge
+	delegations    prometheus.Gauge
+
+	openOwnersLimitExceeded     prometheus.Counter
+	openFilesLimitExceeded      prometheus.Counter
+	lockOwnerFilesLimitExceeded prometheus.Counter
+}
+
+func newClientMetrics(longID string) *clientMetrics {
+	// Client IDs are opaque, but are typically derived from the
+	// client's hostname. Prometheus requires label values to be
+	// valid UTF-8.
+	clientID := strings.ToValidUTF8(longID, "\uFFFD")
+	return &clientMetrics{
+		clientID: clientID,
+
+		openOwners:     baseProgramClientState.WithLabelValues(clientID, "OpenOwners"),
+		openFiles:      baseProgramClientState.WithLabelValues(clientID, "OpenFiles"),
+		lockOwnerFiles: baseProgramClientState.WithLabelValues(clientID, "LockOwnerFiles"),
+		delegations:    baseProgramClientState.WithLabelValues(clientID, "Delegations"),
+
+		openOwnersLimitExceeded:     baseProgramClientStateLimitsExceeded.WithLabelValues(clientID, "OpenOwners"),
+		openFilesLimitExceeded:      baseProgramClientStateLimitsExceeded.WithLabelValues(clientID, "OpenFiles"),
+		lockOwnerFilesLimitExceeded: baseProgramClientStateLimitsExceeded.WithLabelValues(clientID, "LockOwnerFiles"),
+	}
+}
+
+// remove the metrics of a client whose state has been removed, so that
+// clients that come and go don't cause the number of metrics to grow
+// indefinitely.
+func (cm *clientMetrics) remove() {
+	for _, resource := range []string{"OpenOwners", "OpenFiles", "LockOwnerFiles", "Delegations"} {
+		baseProgramClientState.DeleteLabelValues(cm.clientID, resource)
+	}
+	for _, resource := range []string{"OpenOwners", "OpenFiles", "LockOwnerFiles"} {
+		baseProgramClientStateLimitsExceeded.DeleteLabelValues(cm.clientID, resource)
+	}
 }
 
 // clientConfirmationKey contains the information that a client must
@@ -2715,6 +3299,7 @@ func (oos *openOwnerState) remove(p *baseProgram, ll *leavesToClose) {
 		oos.removeFromUnusedList()
 	}
 	delete(oos.confirmedClient.openOwners, oos.key)
+	oos.confirmedClient.metrics.openOwners.Dec()
 	baseProgramOpenOwnersRemoved.Inc()
 }
 
@@ -2909,6 +3494,8 @@ func (oofs *openOwnerFileState) removeFinalize(p *baseProgram) {
 	handleKey := oofs.openedFile.handleKey
 	delete(oofs.openOwner.filesByHandle, handleKey)
 	delete(p.openOwnerFilesByOther, oofs.stateID.other)
+	oofs.openOwner.confirmedClient.openFilesCount--
+	oofs.openOwner.confirmedClient.metrics.openFiles.Dec()
 	oofs.openOwner = nil
 	baseProgramOpenOwnerFilesRemoved.Inc()
 
@@ -2950,6 +3537,28 @@ type openedFileState struct {
 	locks           virtual.ByteRangeLockSet[*lockOwnerState]
 }
 
+// delegationState stores information on a read delegation that has
+// been handed out to a client. Delegations are only handed out for
+// immutable files. Unlike opened files, they don't keep the underlying
+// virtual.Leaf opened. The leaf is opened temporarily when the
+// delegation state ID is used to perform I/O.
+type delegationState struct {
+	confirmedClient *confirmedClientState
+	handle          nfsv4.NfsFh4
+	handleKey       string
+	leaf            virtual.Leaf
+	stateID         regularStateID
+}
+
+// remove the delegation, either due to DELEGRETURN being called, or
+// the client's lease expiring.
+func (ds *delegationState) remove(p *baseProgram) {
+	delete(ds.confirmedClient.delegationsByHandle, ds.handleKey)
+	ds.confirmedClient.metrics.delegations.Dec()
+	delete(p.delegationsByOther, ds.stateID.other)
+	baseProgramDelegationsRemoved.Inc()
+}
+
 // lockOwnerState represents byte-range locking state associated with a
 // given opened file and given lock-owner. Because lock-owners are bound
 // to a single file (i.e., they can't contain locks belonging to
@@ -3064,6 +3673,8 @@ func (lofs *lockOwnerFileState) remove(p *baseProgram, ll *leavesToClose) {
 	los.files[lastIndex] = nil
 	los.files = los.files[:lastIndex]
 	lofs.lockOwnerIndex = -1
+	los.confirmedClient.lockOwnerFilesCount--
+	los.confirmedClient.metrics.lockOwnerFiles.Dec()
 
 	// Remove the lock-owner if there are no longer any files
 	// associated with it.
@@ -3131,6 +3742,12 @@ func attrRequestToAttributesMask(attrRequest nfsv4.Bitmap4) virtual.AttributesMa
 		if f&uint32(1<<(nfsv4.FATTR4_NUMLINKS-32)) != 0 {
 			attributesMask |= virtual.AttributesMaskLinkCount
 		}
+		if f&uint32(1<<(nfsv4.FATTR4_TIME_ACCESS-32)) != 0 {
+			attributesMask |= virtual.AttributesMaskLastDataAccessTime
+		}
+		if f&uint32(1<<(nfsv4.FATTR4_TIME_METADATA-32)) != 0 {
+			attributesMask |= virtual.AttributesMaskLastStatusChangeTime
+		}
 		if f&uint32(1<<(nfsv4.FATTR4_TIME_MODIFY-32)) != 0 {
 			attributesMask |= virtual.AttributesMaskLastDataModificationTime
 		}
@@ -3230,11 +3847,17 @@ func (r *readdirReporter) ReportEntry(nextCookie uint64, name path.Component, ch
 		}
 	}
 
+	var node virtual.Node
+	if directory, leaf := child.GetPair(); directory != nil {
+		node = directory
+	} else {
+		node = leaf
+	}
 	p := r.program
 	entry := nfsv4.Entry4{
 		Cookie: lastReservedCookie + nextCookie,
 		Name:   filename,
-		Attrs:  p.attributesToFattr4(attributes, r.attrRequest),
+		Attrs:  p.attributesToFattr4(node, attributes, r.attrRequest),
 	}
 
 	// The maxcount field is the maximum number of bytes for the
@@ -3253,7 +3876,7 @@ func (r *readdirReporter) ReportEntry(nextCookie uint64, name path.Component, ch
 // fattr4ToAttributes converts a client-provided NFSv4 fattr4 to a set
 // of virtual file system attributes. Only attributes that are both
 // writable and supported by this implementation are accepted.
-func fattr4ToAttributes(in *nfsv4.Fattr4, out *virtual.Attributes) nfsv4.Nfsstat4 {
+func (p *baseProgram) fattr4ToAttributes(in *nfsv4.Fattr4, out *virtual.Attributes) nfsv4.Nfsstat4 {
 	r := bytes.NewBuffer(in.AttrVals)
 	if len(in.Attrmask) > 0 {
 		// Attributes 0 to 31.
@@ -3272,7 +3895,7 @@ func fattr4ToAttributes(in *nfsv4.Fattr4, out *virtual.Attributes) nfsv4.Nfsstat
 	if len(in.Attrmask) > 1 {
 		// Attributes 32 to 63.
 		f := in.Attrmask[1]
-		if f&^(1<<(nfsv4.FATTR4_MODE-32)) != 0 {
+		if f&^((1<<(nfsv4.FATTR4_MODE-32))|(1<<(nfsv4.FATTR4_TIME_ACCESS_SET-32))|(1<<(nfsv4.FATTR4_TIME_MODIFY_SET-32))) != 0 {
 			return nfsv4.NFS4ERR_ATTRNOTSUPP
 		}
 		if f&(1<<(nfsv4.FATTR4_MODE-32)) != 0 {
@@ -3282,6 +3905,28 @@ func fattr4ToAttributes(in *nfsv4.Fattr4, out *virtual.Attributes) nfsv4.Nfsstat
 			}
 			out.SetPermissions(virtual.NewPermissionsFromMode(mode))
 		}
+		if f&(1<<(nfsv4.FATTR4_TIME_ACCESS_SET-32)) != 0 {
+			timeAccessSet, _, err := nfsv4.ReadSettime4(r)
+			if err != nil {
+				return nfsv4.NFS4ERR_BADXDR
+			}
+			if clientTime, ok := timeAccessSet.(*nfsv4.Settime4_SET_TO_CLIENT_TIME4); ok {
+				out.SetLastDataAccessTime(time.Unix(clientTime.Time.Seconds, int64(clientTime.Time.Nseconds)))
+			} else {
+				out.SetLastDataAccessTime(p.clock.Now())
+			}
+		}
+		if f&(1<<(nfsv4.FATTR4_TIME_MODIFY_SET-32)) != 0 {
+			timeModifySet, _, err := nfsv4.ReadSettime4(r)
+			if err != nil {
+				return nfsv4.NFS4ERR_BADXDR
+			}
+			if clientTime, ok := timeModifySet.(*nfsv4.Settime4_SET_TO_CLIENT_TIME4); ok {
+				out.SetLastDataModificationTime(time.Unix(clientTime.Time.Seconds, int64(clientTime.Time.Nseconds)))
+			} else {
+				out.SetLastDataModificationTime(p.clock.Now())
+			}
+		}
 	}
 	for i := 2; i < len(in.Attrmask); i++ {
 		// Attributes 64 or higher.
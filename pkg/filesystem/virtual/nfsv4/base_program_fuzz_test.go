@@ -0,0 +1,103 @@
+package nfsv4_test
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual/nfsv4"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/random"
+	"github.com/buildbarn/bb-storage/pkg/util"
+	nfsv4_xdr "github.com/buildbarn/go-xdr/pkg/protocols/nfsv4"
+	"github.com/stretchr/testify/require"
+)
+
+// FuzzBaseProgramCompound decodes one or more COMPOUND requests from
+// the input and sends them to an NFSv4 program that is backed by an
+// in-memory directory. Malformed requests should be rejected
+// gracefully, without causing panics.
+func FuzzBaseProgramCompound(f *testing.F) {
+	for _, seed := range []*nfsv4_xdr.Compound4args{
+		{
+			Tag: "getfh",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+				&nfsv4_xdr.NfsArgop4_OP_GETFH{},
+			},
+		},
+		{
+			Tag: "lookup",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+				&nfsv4_xdr.NfsArgop4_OP_LOOKUP{
+					Oplookup: nfsv4_xdr.Lookup4args{
+						Objname: "a",
+					},
+				},
+			},
+		},
+		{
+			Tag: "access",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+				&nfsv4_xdr.NfsArgop4_OP_ACCESS{
+					Opaccess: nfsv4_xdr.Access4args{
+						Access: nfsv4_xdr.ACCESS4_READ | nfsv4_xdr.ACCESS4_LOOKUP,
+					},
+				},
+			},
+		},
+	} {
+		b := bytes.NewBuffer(nil)
+		_, err := seed.WriteTo(b)
+		require.NoError(f, err)
+		f.Add(b.Bytes())
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.Background()
+		handleAllocator := virtual.NewNFSHandleAllocator(random.NewFastSingleThreadedGenerator())
+		rootDirectory := virtual.NewInMemoryPrepopulatedDirectory(
+			virtual.NewHandleAllocatingFileAllocator(
+				virtual.NewPoolBackedFileAllocator(re_filesystem.InMemoryFilePool, util.DefaultErrorLogger, clock.SystemClock),
+				handleAllocator),
+			virtual.NewHandleAllocatingSymlinkFactory(
+				virtual.BaseSymlinkFactory,
+				handleAllocator.New()),
+			util.DefaultErrorLogger,
+			handleAllocator,
+			sort.Sort,
+			func(string) bool { return false },
+			clock.SystemClock,
+			/* caseInsensitive = */ false)
+		program := nfsv4.NewBaseProgram(
+			rootDirectory,
+			handleAllocator.ResolveHandle,
+			random.NewFastSingleThreadedGenerator(),
+			nfsv4_xdr.Verifier4{0x5a, 0x01, 0x3c, 0x5d, 0xd9, 0x83, 0x87, 0x09},
+			[...]byte{0x01, 0x02, 0x03, 0x04},
+			clock.SystemClock,
+			2*time.Minute,
+			time.Minute,
+			nfsv4.ClientStateLimits{},
+			nil)
+
+		// Process requests until the input can no longer be
+		// decoded. This permits the fuzzer to discover
+		// sequences of requests that depend on state created
+		// by earlier requests.
+		r := bytes.NewReader(data)
+		for {
+			var args nfsv4_xdr.Compound4args
+			if _, err := args.ReadFrom(r); err != nil {
+				return
+			}
+			program.NfsV4Nfsproc4Compound(ctx, &args)
+		}
+	})
+}
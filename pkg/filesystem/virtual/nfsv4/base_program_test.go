@@ -110,7 +110,7 @@ func openUnconfirmedFileForTesting(ctx context.Context, t *testing.T, randomNumb
 		virtual.ShareMaskRead,
 		nil,
 		&virtual.OpenExistingOptions{},
-		virtual.AttributesMaskFileHandle,
+		virtual.AttributesMaskFileHandle|virtual.AttributesMaskIsImmutable,
 		gomock.Any(),
 	).DoAndReturn(func(ctx context.Context, name path.Component, shareAccess virtual.ShareMask, createAttributes *virtual.Attributes, existingOptions *virtual.OpenExistingOptions, requested virtual.AttributesMask, openedFileAttributes *virtual.Attributes) (virtual.Leaf, virtual.AttributesMask, virtual.ChangeInfo, virtual.Status) {
 		openedFileAttributes.SetFileHandle(fileHandle)
@@ -239,7 +239,7 @@ func TestBaseProgramCompound_OP_ACCESS(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x96, 0x63, 0x54, 0xf1, 0xa2, 0x6b, 0x8c, 0x61}
 	stateIDOtherPrefix := [...]byte{0x68, 0x78, 0x20, 0xb7}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NoFileHandle", func(t *testing.T) {
 		// Calling ACCESS without a file handle should fail.
@@ -368,7 +368,7 @@ func TestBaseProgramCompound_OP_CLOSE(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x9f, 0xa8, 0x23, 0x40, 0x68, 0x9f, 0x3e, 0xac}
 	stateIDOtherPrefix := [...]byte{0xf5, 0x47, 0xa8, 0x88}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("AnonymousStateID", func(t *testing.T) {
 		// Calling CLOSE against the anonymous state ID is of
@@ -910,7 +910,7 @@ func TestBaseProgramCompound_OP_COMMIT(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x1a, 0xa6, 0x7e, 0x3b, 0xf7, 0x29, 0xa4, 0x7b}
 	stateIDOtherPrefix := [...]byte{0x24, 0xa7, 0x48, 0xbc}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NoFileHandle", func(t *testing.T) {
 		// Calling COMMIT without a file handle should fail.
@@ -1027,7 +1027,7 @@ func TestBaseProgramCompound_OP_CREATE(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x8d, 0x3d, 0xe8, 0x2e, 0xee, 0x3b, 0xca, 0x60}
 	stateIDOtherPrefix := [...]byte{0x60, 0xf5, 0x56, 0x97}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NoFileHandle", func(t *testing.T) {
 		// Calling CREATE without a file handle should fail.
@@ -1579,7 +1579,7 @@ func TestBaseProgramCompound_OP_DELEGPURGE(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x0b, 0xb3, 0x0d, 0xa3, 0x50, 0x11, 0x6b, 0x38}
 	stateIDOtherPrefix := [...]byte{0x17, 0x18, 0x71, 0xc6}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NotSupported", func(t *testing.T) {
 		// As we don't support CLAIM_DELEGATE_PREV, this method
@@ -1609,7 +1609,307 @@ func TestBaseProgramCompound_OP_DELEGPURGE(t *testing.T) {
 	})
 }
 
-// TODO: DELEGRETURN
+func TestBaseProgramCompound_OP_DELEGRETURN(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
+	rootDirectory.EXPECT().VirtualGetAttributes(gomock.Any(), virtual.AttributesMaskFileHandle, gomock.Any()).
+		Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+			attributes.SetFileHandle([]byte{0x9c, 0x2f, 0x0b, 0x6e, 0x41, 0xa3, 0x5d, 0x17})
+		})
+	handleResolver := mock.NewMockHandleResolver(ctrl)
+	randomNumberGenerator := mock.NewMockSingleThreadedGenerator(ctrl)
+	rebootVerifier := nfsv4_xdr.Verifier4{0x3e, 0x8a, 0x51, 0xd2, 0x07, 0xc4, 0x96, 0x2b}
+	stateIDOtherPrefix := [...]byte{0x6d, 0x12, 0xe8, 0x4f}
+	clock := mock.NewMockClock(ctrl)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
+
+	t.Run("BadStateID", func(t *testing.T) {
+		// Returning a delegation that was never handed out
+		// should fail.
+		clock.EXPECT().Now().Return(time.Unix(1000, 0))
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "delegreturn",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_DELEGRETURN{
+					Opdelegreturn: nfsv4_xdr.Delegreturn4args{
+						DelegStateid: nfsv4_xdr.Stateid4{
+							Seqid: 1,
+							Other: [...]byte{
+								0x6d, 0x12, 0xe8, 0x4f,
+								0x1e, 0x55, 0x0c, 0x8d,
+								0x27, 0xb0, 0x93, 0x6a,
+							},
+						},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "delegreturn",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_DELEGRETURN{
+					Opdelegreturn: nfsv4_xdr.Delegreturn4res{
+						Status: nfsv4_xdr.NFS4ERR_BAD_STATEID,
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4ERR_BAD_STATEID,
+		}, res)
+	})
+
+	// The remainder of the test assumes the availability of a client ID.
+	clock.EXPECT().Now().Return(time.Unix(1001, 0))
+	clock.EXPECT().Now().Return(time.Unix(1002, 0))
+	setClientIDForTesting(ctx, t, randomNumberGenerator, program, 0x4c6fd3a1e20b9857)
+
+	// Open an immutable file for reading. This should cause a read
+	// delegation to be handed out.
+	leaf := mock.NewMockVirtualLeaf(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(1003, 0))
+	clock.EXPECT().Now().Return(time.Unix(1004, 0))
+	rootDirectory.EXPECT().VirtualOpenChild(
+		ctx,
+		path.MustNewComponent("Hello"),
+		virtual.ShareMaskRead,
+		nil,
+		&virtual.OpenExistingOptions{},
+		virtual.AttributesMaskFileHandle|virtual.AttributesMaskIsImmutable,
+		gomock.Any(),
+	).DoAndReturn(func(ctx context.Context, name path.Component, shareAccess virtual.ShareMask, createAttributes *virtual.Attributes, existingOptions *virtual.OpenExistingOptions, requested virtual.AttributesMask, openedFileAttributes *virtual.Attributes) (virtual.Leaf, virtual.AttributesMask, virtual.ChangeInfo, virtual.Status) {
+		openedFileAttributes.
+			SetFileHandle([]byte{0x5a, 0x0e, 0x93, 0x71, 0xcc, 0x28, 0xb4, 0xf6}).
+			SetIsImmutable(true)
+		return leaf, 0, virtual.ChangeInfo{
+			Before: 0x8d1f5c0a3e6b7294,
+			After:  0x8d1f5c0a3e6b7294,
+		}, virtual.StatusOK
+	})
+	randomNumberGeneratorExpectRead(randomNumberGenerator, []byte{0xa4, 0x39, 0x7e, 0x02, 0xd5, 0x68, 0x1b, 0xcf})
+	randomNumberGeneratorExpectRead(randomNumberGenerator, []byte{0x31, 0xf7, 0x8e, 0x4d, 0x60, 0x9a, 0x2c, 0xb5})
+
+	res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+		Tag: "open",
+		Argarray: []nfsv4_xdr.NfsArgop4{
+			&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+			&nfsv4_xdr.NfsArgop4_OP_OPEN{
+				Opopen: nfsv4_xdr.Open4args{
+					Seqid:       8219,
+					ShareAccess: nfsv4_xdr.OPEN4_SHARE_ACCESS_READ,
+					ShareDeny:   nfsv4_xdr.OPEN4_SHARE_DENY_NONE,
+					Owner: nfsv4_xdr.OpenOwner4{
+						Clientid: 0x4c6fd3a1e20b9857,
+						Owner:    []byte{0x0f, 0x72, 0xd9, 0x36, 0x84, 0xe1, 0x5b, 0xa0},
+					},
+					Openhow: &nfsv4_xdr.Openflag4_default{},
+					Claim: &nfsv4_xdr.OpenClaim4_CLAIM_NULL{
+						File: "Hello",
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &nfsv4_xdr.Compound4res{
+		Tag: "open",
+		Resarray: []nfsv4_xdr.NfsResop4{
+			&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
+				Opputrootfh: nfsv4_xdr.Putrootfh4res{
+					Status: nfsv4_xdr.NFS4_OK,
+				},
+			},
+			&nfsv4_xdr.NfsResop4_OP_OPEN{
+				Opopen: &nfsv4_xdr.Open4res_NFS4_OK{
+					Resok4: nfsv4_xdr.Open4resok{
+						Stateid: nfsv4_xdr.Stateid4{
+							Seqid: 1,
+							Other: [...]byte{
+								0x6d, 0x12, 0xe8, 0x4f,
+								0xa4, 0x39, 0x7e, 0x02,
+								0xd5, 0x68, 0x1b, 0xcf,
+							},
+						},
+						Cinfo: nfsv4_xdr.ChangeInfo4{
+							Atomic: true,
+							Before: 0x8d1f5c0a3e6b7294,
+							After:  0x8d1f5c0a3e6b7294,
+						},
+						Rflags:  nfsv4_xdr.OPEN4_RESULT_CONFIRM | nfsv4_xdr.OPEN4_RESULT_LOCKTYPE_POSIX,
+						Attrset: nfsv4_xdr.Bitmap4{},
+						Delegation: &nfsv4_xdr.OpenDelegation4_OPEN_DELEGATE_READ{
+							Read: nfsv4_xdr.OpenReadDelegation4{
+								Stateid: nfsv4_xdr.Stateid4{
+									Seqid: 1,
+									Other: [...]byte{
+										0x6d, 0x12, 0xe8, 0x4f,
+										0x31, 0xf7, 0x8e, 0x4d,
+										0x60, 0x9a, 0x2c, 0xb5,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Status: nfsv4_xdr.NFS4_OK,
+	}, res)
+
+	t.Run("ReadWithDelegation", func(t *testing.T) {
+		// The delegation state ID may be used to read from
+		// the file. The file should be opened temporarily.
+		clock.EXPECT().Now().Return(time.Unix(1005, 0))
+		clock.EXPECT().Now().Return(time.Unix(1006, 0))
+		clock.EXPECT().Now().Return(time.Unix(1007, 0))
+		leaf.EXPECT().VirtualOpenSelf(ctx, virtual.ShareMaskRead, &virtual.OpenExistingOptions{}, virtual.AttributesMask(0), gomock.Any())
+		leaf.EXPECT().VirtualRead(gomock.Len(100), uint64(0)).
+			DoAndReturn(func(buf []byte, offset uint64) (int, bool, virtual.Status) {
+				return copy(buf, "Hello"), true, virtual.StatusOK
+			})
+		leaf.EXPECT().VirtualClose(virtual.ShareMaskRead)
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "read",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTFH{
+					Opputfh: nfsv4_xdr.Putfh4args{
+						Object: nfsv4_xdr.NfsFh4{0x5a, 0x0e, 0x93, 0x71, 0xcc, 0x28, 0xb4, 0xf6},
+					},
+				},
+				&nfsv4_xdr.NfsArgop4_OP_READ{
+					Opread: nfsv4_xdr.Read4args{
+						Stateid: nfsv4_xdr.Stateid4{
+							Seqid: 1,
+							Other: [...]byte{
+								0x6d, 0x12, 0xe8, 0x4f,
+								0x31, 0xf7, 0x8e, 0x4d,
+								0x60, 0x9a, 0x2c, 0xb5,
+							},
+						},
+						Offset: 0,
+						Count:  100,
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "read",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTFH{
+					Opputfh: nfsv4_xdr.Putfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_READ{
+					Opread: &nfsv4_xdr.Read4res_NFS4_OK{
+						Resok4: nfsv4_xdr.Read4resok{
+							Eof:  true,
+							Data: []byte("Hello"),
+						},
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4_OK,
+		}, res)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		clock.EXPECT().Now().Return(time.Unix(1008, 0))
+		clock.EXPECT().Now().Return(time.Unix(1009, 0))
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "delegreturn",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTFH{
+					Opputfh: nfsv4_xdr.Putfh4args{
+						Object: nfsv4_xdr.NfsFh4{0x5a, 0x0e, 0x93, 0x71, 0xcc, 0x28, 0xb4, 0xf6},
+					},
+				},
+				&nfsv4_xdr.NfsArgop4_OP_DELEGRETURN{
+					Opdelegreturn: nfsv4_xdr.Delegreturn4args{
+						DelegStateid: nfsv4_xdr.Stateid4{
+							Seqid: 1,
+							Other: [...]byte{
+								0x6d, 0x12, 0xe8, 0x4f,
+								0x31, 0xf7, 0x8e, 0x4d,
+								0x60, 0x9a, 0x2c, 0xb5,
+							},
+						},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "delegreturn",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTFH{
+					Opputfh: nfsv4_xdr.Putfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_DELEGRETURN{
+					Opdelegreturn: nfsv4_xdr.Delegreturn4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4_OK,
+		}, res)
+	})
+
+	t.Run("ReadAfterReturn", func(t *testing.T) {
+		// Once returned, the delegation state ID may no longer
+		// be used.
+		clock.EXPECT().Now().Return(time.Unix(1010, 0))
+		clock.EXPECT().Now().Return(time.Unix(1011, 0))
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "read",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTFH{
+					Opputfh: nfsv4_xdr.Putfh4args{
+						Object: nfsv4_xdr.NfsFh4{0x5a, 0x0e, 0x93, 0x71, 0xcc, 0x28, 0xb4, 0xf6},
+					},
+				},
+				&nfsv4_xdr.NfsArgop4_OP_READ{
+					Opread: nfsv4_xdr.Read4args{
+						Stateid: nfsv4_xdr.Stateid4{
+							Seqid: 1,
+							Other: [...]byte{
+								0x6d, 0x12, 0xe8, 0x4f,
+								0x31, 0xf7, 0x8e, 0x4d,
+								0x60, 0x9a, 0x2c, 0xb5,
+							},
+						},
+						Offset: 0,
+						Count:  100,
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "read",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTFH{
+					Opputfh: nfsv4_xdr.Putfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_READ{
+					Opread: &nfsv4_xdr.Read4res_default{
+						Status: nfsv4_xdr.NFS4ERR_BAD_STATEID,
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4ERR_BAD_STATEID,
+		}, res)
+	})
+}
 
 func TestBaseProgramCompound_OP_GETATTR(t *testing.T) {
 	ctrl, ctx := gomock.WithContext(context.Background(), t)
@@ -1624,7 +1924,7 @@ func TestBaseProgramCompound_OP_GETATTR(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x5e, 0x5f, 0xfe, 0x34, 0x05, 0x98, 0x9d, 0xf1}
 	stateIDOtherPrefix := [...]byte{0x3d, 0xc0, 0x5d, 0xd2}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NoFileHandle", func(t *testing.T) {
 		// Calling GETATTR without a file handle should fail.
@@ -1693,7 +1993,7 @@ func TestBaseProgramCompound_OP_GETATTR(t *testing.T) {
 		// Request all supported attributes.
 		rootDirectory.EXPECT().VirtualGetAttributes(
 			ctx,
-			virtual.AttributesMaskChangeID|virtual.AttributesMaskFileHandle|virtual.AttributesMaskFileType|virtual.AttributesMaskInodeNumber|virtual.AttributesMaskLastDataModificationTime|virtual.AttributesMaskLinkCount|virtual.AttributesMaskPermissions|virtual.AttributesMaskSizeBytes,
+			virtual.AttributesMaskChangeID|virtual.AttributesMaskFileHandle|virtual.AttributesMaskFileType|virtual.AttributesMaskInodeNumber|virtual.AttributesMaskLastDataAccessTime|virtual.AttributesMaskLastDataModificationTime|virtual.AttributesMaskLastStatusChangeTime|virtual.AttributesMaskLinkCount|virtual.AttributesMaskPermissions|virtual.AttributesMaskSizeBytes,
 			gomock.Any(),
 		).Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
 			attributes.SetChangeID(0xeaab7253dad16ee5)
@@ -1731,6 +2031,7 @@ func TestBaseProgramCompound_OP_GETATTR(t *testing.T) {
 								(1 << (nfsv4_xdr.FATTR4_TIME_ACCESS - 32)) |
 								(1 << (nfsv4_xdr.FATTR4_TIME_METADATA - 32)) |
 								(1 << (nfsv4_xdr.FATTR4_TIME_MODIFY - 32)),
+							1 << (nfsv4_xdr.FATTR4_XATTR_SUPPORT - 64),
 						},
 					},
 				},
@@ -1768,12 +2069,14 @@ func TestBaseProgramCompound_OP_GETATTR(t *testing.T) {
 										(1 << (nfsv4_xdr.FATTR4_TIME_ACCESS - 32)) |
 										(1 << (nfsv4_xdr.FATTR4_TIME_METADATA - 32)) |
 										(1 << (nfsv4_xdr.FATTR4_TIME_MODIFY - 32)),
+									1 << (nfsv4_xdr.FATTR4_XATTR_SUPPORT - 64),
 								},
 								AttrVals: nfsv4_xdr.Attrlist4{
 									// FATTR4_SUPPORTED_ATTRS.
-									0x00, 0x00, 0x00, 0x02,
+									0x00, 0x00, 0x00, 0x03,
 									0x00, 0x18, 0x0f, 0xff,
-									0x00, 0x30, 0x80, 0x0a,
+									0x00, 0x71, 0x80, 0x0a,
+									0x00, 0x04, 0x00, 0x00,
 									// FATTR4_TYPE == NF4DIR.
 									0x00, 0x00, 0x00, 0x02,
 									// FATTR4_FH_EXPIRE_TYPE == FH4_PERSISTENT.
@@ -1813,6 +2116,103 @@ func TestBaseProgramCompound_OP_GETATTR(t *testing.T) {
 									// FATTR4_TIME_MODIFY == 2022-06-09T16:19:26.4839067173Z.
 									0x00, 0x00, 0x00, 0x00, 0x62, 0xa2, 0x1d, 0x92,
 									0x32, 0x03, 0x26, 0x25,
+									// FATTR4_XATTR_SUPPORT == TRUE.
+									0x00, 0x00, 0x00, 0x01,
+								},
+							},
+						},
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4_OK,
+		}, res)
+	})
+}
+
+func TestBaseProgramCompound_OP_GETATTR_StatFS(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
+	rootDirectory.EXPECT().VirtualGetAttributes(gomock.Any(), virtual.AttributesMaskFileHandle, gomock.Any()).
+		Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+			attributes.SetFileHandle([]byte{0x4e, 0x2a, 0x91, 0x0c, 0x7f, 0x33, 0xd8, 0x65})
+		})
+	handleResolver := mock.NewMockHandleResolver(ctrl)
+	randomNumberGenerator := mock.NewMockSingleThreadedGenerator(ctrl)
+	rebootVerifier := nfsv4_xdr.Verifier4{0x1f, 0x8c, 0x6a, 0x05, 0xe2, 0x47, 0xb9, 0x3d}
+	stateIDOtherPrefix := [...]byte{0x62, 0x0e, 0xa4, 0x18}
+	clock := mock.NewMockClock(ctrl)
+	statFSProvider := mock.NewMockStatFSProvider(ctrl)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, statFSProvider)
+
+	getattrArgs := &nfsv4_xdr.Compound4args{
+		Tag: "statfs",
+		Argarray: []nfsv4_xdr.NfsArgop4{
+			&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+			&nfsv4_xdr.NfsArgop4_OP_GETATTR{
+				Opgetattr: nfsv4_xdr.Getattr4args{
+					AttrRequest: nfsv4_xdr.Bitmap4{
+						(1 << nfsv4_xdr.FATTR4_FILES_AVAIL) |
+							(1 << nfsv4_xdr.FATTR4_FILES_FREE) |
+							(1 << nfsv4_xdr.FATTR4_FILES_TOTAL),
+						(1 << (nfsv4_xdr.FATTR4_SPACE_AVAIL - 32)) |
+							(1 << (nfsv4_xdr.FATTR4_SPACE_FREE - 32)) |
+							(1 << (nfsv4_xdr.FATTR4_SPACE_TOTAL - 32)),
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		// The capacity of the file system should be obtained
+		// from the StatFSProvider.
+		rootDirectory.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMask(0), gomock.Any())
+		statFSProvider.EXPECT().VirtualStatFS(gomock.Any()).DoAndReturn(func(out *virtual.FileSystemStatistics) virtual.Status {
+			*out = virtual.FileSystemStatistics{
+				TotalSizeBytes:     10 * 1024 * 1024,
+				AvailableSizeBytes: 4 * 1024 * 1024,
+				TotalFiles:         1000,
+				AvailableFiles:     900,
+			}
+			return virtual.StatusOK
+		})
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, getattrArgs)
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "statfs",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
+					Opputrootfh: nfsv4_xdr.Putrootfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_GETATTR{
+					Opgetattr: &nfsv4_xdr.Getattr4res_NFS4_OK{
+						Resok4: nfsv4_xdr.Getattr4resok{
+							ObjAttributes: nfsv4_xdr.Fattr4{
+								Attrmask: nfsv4_xdr.Bitmap4{
+									(1 << nfsv4_xdr.FATTR4_FILES_AVAIL) |
+										(1 << nfsv4_xdr.FATTR4_FILES_FREE) |
+										(1 << nfsv4_xdr.FATTR4_FILES_TOTAL),
+									(1 << (nfsv4_xdr.FATTR4_SPACE_AVAIL - 32)) |
+										(1 << (nfsv4_xdr.FATTR4_SPACE_FREE - 32)) |
+										(1 << (nfsv4_xdr.FATTR4_SPACE_TOTAL - 32)),
+								},
+								AttrVals: nfsv4_xdr.Attrlist4{
+									// FATTR4_FILES_AVAIL.
+									0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x84,
+									// FATTR4_FILES_FREE.
+									0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x84,
+									// FATTR4_FILES_TOTAL.
+									0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0xe8,
+									// FATTR4_SPACE_AVAIL.
+									0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x00, 0x00,
+									// FATTR4_SPACE_FREE.
+									0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x00, 0x00,
+									// FATTR4_SPACE_TOTAL.
+									0x00, 0x00, 0x00, 0x00, 0x00, 0xa0, 0x00, 0x00,
 								},
 							},
 						},
@@ -1822,6 +2222,36 @@ func TestBaseProgramCompound_OP_GETATTR(t *testing.T) {
 			Status: nfsv4_xdr.NFS4_OK,
 		}, res)
 	})
+
+	t.Run("Failure", func(t *testing.T) {
+		// If the capacity of the file system cannot be
+		// obtained, the attributes should be omitted.
+		rootDirectory.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMask(0), gomock.Any())
+		statFSProvider.EXPECT().VirtualStatFS(gomock.Any()).Return(virtual.StatusErrIO)
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, getattrArgs)
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "statfs",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
+					Opputrootfh: nfsv4_xdr.Putrootfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_GETATTR{
+					Opgetattr: &nfsv4_xdr.Getattr4res_NFS4_OK{
+						Resok4: nfsv4_xdr.Getattr4resok{
+							ObjAttributes: nfsv4_xdr.Fattr4{
+								Attrmask: nfsv4_xdr.Bitmap4{0, 0},
+							},
+						},
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4_OK,
+		}, res)
+	})
 }
 
 func TestBaseProgramCompound_OP_GETFH(t *testing.T) {
@@ -1837,7 +2267,7 @@ func TestBaseProgramCompound_OP_GETFH(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x3c, 0x79, 0xba, 0xfe, 0xd6, 0x87, 0x1e, 0x32}
 	stateIDOtherPrefix := [...]byte{0x95, 0xce, 0xb4, 0x96}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NoFileHandle", func(t *testing.T) {
 		// Calling GETFH without a file handle should fail.
@@ -1893,6 +2323,116 @@ func TestBaseProgramCompound_OP_GETFH(t *testing.T) {
 	})
 }
 
+func TestBaseProgramCompound_OP_GETXATTR(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
+	rootDirectory.EXPECT().VirtualGetAttributes(gomock.Any(), virtual.AttributesMaskFileHandle, gomock.Any()).
+		Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+			attributes.SetFileHandle([]byte{0x5e, 0x23, 0x8b, 0x11, 0x0c, 0x7f, 0xa4, 0x39})
+		})
+	handleResolver := mock.NewMockHandleResolver(ctrl)
+	randomNumberGenerator := mock.NewMockSingleThreadedGenerator(ctrl)
+	rebootVerifier := nfsv4_xdr.Verifier4{0x9d, 0x71, 0x26, 0xe0, 0x4a, 0x13, 0xbf, 0x58}
+	stateIDOtherPrefix := [...]byte{0x61, 0x0d, 0xc2, 0x97}
+	clock := mock.NewMockClock(ctrl)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
+
+	t.Run("NoFileHandle", func(t *testing.T) {
+		// Calling GETXATTR without a file handle should fail.
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "getxattr",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_GETXATTR{
+					Opgetxattr: nfsv4_xdr.Getxattr4args{
+						GxaName: "foo",
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "getxattr",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_GETXATTR{
+					Opgetxattr: &nfsv4_xdr.Getxattr4res_default{
+						GxrStatus: nfsv4_xdr.NFS4ERR_NOFILEHANDLE,
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4ERR_NOFILEHANDLE,
+		}, res)
+	})
+
+	t.Run("NoXAttr", func(t *testing.T) {
+		// Extended attributes are accessed through NFSv4 by
+		// their name in the user namespace.
+		rootDirectory.EXPECT().VirtualGetXAttr(ctx, "user.foo").Return(nil, virtual.StatusErrNoXAttr)
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "getxattr",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+				&nfsv4_xdr.NfsArgop4_OP_GETXATTR{
+					Opgetxattr: nfsv4_xdr.Getxattr4args{
+						GxaName: "foo",
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "getxattr",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
+					Opputrootfh: nfsv4_xdr.Putrootfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_GETXATTR{
+					Opgetxattr: &nfsv4_xdr.Getxattr4res_default{
+						GxrStatus: nfsv4_xdr.NFS4ERR_NOXATTR,
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4ERR_NOXATTR,
+		}, res)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		rootDirectory.EXPECT().VirtualGetXAttr(ctx, "user.foo").Return([]byte("bar"), virtual.StatusOK)
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "getxattr",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+				&nfsv4_xdr.NfsArgop4_OP_GETXATTR{
+					Opgetxattr: nfsv4_xdr.Getxattr4args{
+						GxaName: "foo",
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "getxattr",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
+					Opputrootfh: nfsv4_xdr.Putrootfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_GETXATTR{
+					Opgetxattr: &nfsv4_xdr.Getxattr4res_NFS4_OK{
+						GxrValue: []byte("bar"),
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4_OK,
+		}, res)
+	})
+}
+
 func TestBaseProgramCompound_OP_ILLEGAL(t *testing.T) {
 	ctrl, ctx := gomock.WithContext(context.Background(), t)
 
@@ -1906,7 +2446,7 @@ func TestBaseProgramCompound_OP_ILLEGAL(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x42, 0x51, 0x65, 0x8b, 0xd2, 0x27, 0xc4, 0x13}
 	stateIDOtherPrefix := [...]byte{0x01, 0x22, 0xe2, 0xaa}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("Failure", func(t *testing.T) {
 		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
@@ -1943,7 +2483,7 @@ func TestBaseProgramCompound_OP_LINK(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x8d, 0x94, 0x96, 0x9c, 0xe9, 0x4b, 0xcf, 0xf5}
 	stateIDOtherPrefix := [...]byte{0xdf, 0xdb, 0x0d, 0x38}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NoFileHandle1", func(t *testing.T) {
 		// Calling LINK without any file handles should fail.
@@ -2260,36 +2800,244 @@ func TestBaseProgramCompound_OP_LINK(t *testing.T) {
 		})
 		require.NoError(t, err)
 		require.Equal(t, &nfsv4_xdr.Compound4res{
-			Tag: "link",
+			Tag: "link",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTFH{
+					Opputfh: nfsv4_xdr.Putfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_SAVEFH{
+					Opsavefh: nfsv4_xdr.Savefh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
+					Opputrootfh: nfsv4_xdr.Putrootfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_LINK{
+					Oplink: &nfsv4_xdr.Link4res_NFS4_OK{
+						Resok4: nfsv4_xdr.Link4resok{
+							Cinfo: nfsv4_xdr.ChangeInfo4{
+								Atomic: true,
+								Before: 0x6eee6c2bf6db7101,
+								After:  0x5d2447d9e6bec4b8,
+							},
+						},
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4_OK,
+		}, res)
+	})
+}
+
+func TestBaseProgramCompound_OP_LISTXATTRS(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
+	rootDirectory.EXPECT().VirtualGetAttributes(gomock.Any(), virtual.AttributesMaskFileHandle, gomock.Any()).
+		Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+			attributes.SetFileHandle([]byte{0x3a, 0xc8, 0x50, 0x1e, 0xd7, 0x62, 0x94, 0x0b})
+		})
+	handleResolver := mock.NewMockHandleResolver(ctrl)
+	randomNumberGenerator := mock.NewMockSingleThreadedGenerator(ctrl)
+	rebootVerifier := nfsv4_xdr.Verifier4{0x14, 0xe6, 0x8f, 0x2d, 0xb3, 0x70, 0x5a, 0xc1}
+	stateIDOtherPrefix := [...]byte{0xf2, 0x48, 0x1b, 0x6e}
+	clock := mock.NewMockClock(ctrl)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
+
+	t.Run("Success", func(t *testing.T) {
+		// Only extended attributes in the user namespace should
+		// be returned, without the namespace prefix.
+		rootDirectory.EXPECT().VirtualListXAttr(ctx).
+			Return([]string{"security.selinux", "user.aaa", "user.bbbbb"}, virtual.StatusOK)
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "listxattrs",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+				&nfsv4_xdr.NfsArgop4_OP_LISTXATTRS{
+					Oplistxattrs: nfsv4_xdr.Listxattrs4args{
+						LxaCookie:   0,
+						LxaMaxcount: 1000,
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "listxattrs",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
+					Opputrootfh: nfsv4_xdr.Putrootfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_LISTXATTRS{
+					Oplistxattrs: &nfsv4_xdr.Listxattrs4res_NFS4_OK{
+						LxrValue: nfsv4_xdr.Listxattrs4resok{
+							LxrCookie: 2,
+							LxrNames:  []string{"aaa", "bbbbb"},
+							LxrEof:    true,
+						},
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4_OK,
+		}, res)
+	})
+
+	t.Run("Partial", func(t *testing.T) {
+		// If not all names fit in the response, the cookie can
+		// be used to resume listing. The response below has
+		// space for 16 bytes of fixed fields and the first name.
+		rootDirectory.EXPECT().VirtualListXAttr(ctx).
+			Return([]string{"user.aaa", "user.bbbbb"}, virtual.StatusOK)
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "listxattrs",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+				&nfsv4_xdr.NfsArgop4_OP_LISTXATTRS{
+					Oplistxattrs: nfsv4_xdr.Listxattrs4args{
+						LxaCookie:   0,
+						LxaMaxcount: 24,
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "listxattrs",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
+					Opputrootfh: nfsv4_xdr.Putrootfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_LISTXATTRS{
+					Oplistxattrs: &nfsv4_xdr.Listxattrs4res_NFS4_OK{
+						LxrValue: nfsv4_xdr.Listxattrs4resok{
+							LxrCookie: 1,
+							LxrNames:  []string{"aaa"},
+							LxrEof:    false,
+						},
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4_OK,
+		}, res)
+
+		rootDirectory.EXPECT().VirtualListXAttr(ctx).
+			Return([]string{"user.aaa", "user.bbbbb"}, virtual.StatusOK)
+
+		res, err = program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "listxattrs",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+				&nfsv4_xdr.NfsArgop4_OP_LISTXATTRS{
+					Oplistxattrs: nfsv4_xdr.Listxattrs4args{
+						LxaCookie:   1,
+						LxaMaxcount: 28,
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "listxattrs",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
+					Opputrootfh: nfsv4_xdr.Putrootfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_LISTXATTRS{
+					Oplistxattrs: &nfsv4_xdr.Listxattrs4res_NFS4_OK{
+						LxrValue: nfsv4_xdr.Listxattrs4resok{
+							LxrCookie: 2,
+							LxrNames:  []string{"bbbbb"},
+							LxrEof:    true,
+						},
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4_OK,
+		}, res)
+	})
+
+	t.Run("TooSmall", func(t *testing.T) {
+		// If not even a single name fits in the response,
+		// NFS4ERR_TOOSMALL should be returned.
+		rootDirectory.EXPECT().VirtualListXAttr(ctx).
+			Return([]string{"user.aaa"}, virtual.StatusOK)
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "listxattrs",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+				&nfsv4_xdr.NfsArgop4_OP_LISTXATTRS{
+					Oplistxattrs: nfsv4_xdr.Listxattrs4args{
+						LxaCookie:   0,
+						LxaMaxcount: 20,
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "listxattrs",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
+					Opputrootfh: nfsv4_xdr.Putrootfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_LISTXATTRS{
+					Oplistxattrs: &nfsv4_xdr.Listxattrs4res_default{
+						LxrStatus: nfsv4_xdr.NFS4ERR_TOOSMALL,
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4ERR_TOOSMALL,
+		}, res)
+	})
+
+	t.Run("BadCookie", func(t *testing.T) {
+		rootDirectory.EXPECT().VirtualListXAttr(ctx).
+			Return([]string{"user.aaa"}, virtual.StatusOK)
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "listxattrs",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+				&nfsv4_xdr.NfsArgop4_OP_LISTXATTRS{
+					Oplistxattrs: nfsv4_xdr.Listxattrs4args{
+						LxaCookie:   2,
+						LxaMaxcount: 1000,
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "listxattrs",
 			Resarray: []nfsv4_xdr.NfsResop4{
-				&nfsv4_xdr.NfsResop4_OP_PUTFH{
-					Opputfh: nfsv4_xdr.Putfh4res{
-						Status: nfsv4_xdr.NFS4_OK,
-					},
-				},
-				&nfsv4_xdr.NfsResop4_OP_SAVEFH{
-					Opsavefh: nfsv4_xdr.Savefh4res{
-						Status: nfsv4_xdr.NFS4_OK,
-					},
-				},
 				&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
 					Opputrootfh: nfsv4_xdr.Putrootfh4res{
 						Status: nfsv4_xdr.NFS4_OK,
 					},
 				},
-				&nfsv4_xdr.NfsResop4_OP_LINK{
-					Oplink: &nfsv4_xdr.Link4res_NFS4_OK{
-						Resok4: nfsv4_xdr.Link4resok{
-							Cinfo: nfsv4_xdr.ChangeInfo4{
-								Atomic: true,
-								Before: 0x6eee6c2bf6db7101,
-								After:  0x5d2447d9e6bec4b8,
-							},
-						},
+				&nfsv4_xdr.NfsResop4_OP_LISTXATTRS{
+					Oplistxattrs: &nfsv4_xdr.Listxattrs4res_default{
+						LxrStatus: nfsv4_xdr.NFS4ERR_BAD_COOKIE,
 					},
 				},
 			},
-			Status: nfsv4_xdr.NFS4_OK,
+			Status: nfsv4_xdr.NFS4ERR_BAD_COOKIE,
 		}, res)
 	})
 }
@@ -2307,7 +3055,7 @@ func TestBaseProgramCompound_OP_LOOKUP(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0xf5, 0x66, 0xea, 0xae, 0x76, 0x70, 0xd1, 0x5b}
 	stateIDOtherPrefix := [...]byte{0x2d, 0x48, 0xd3, 0x9b}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NoFileHandle", func(t *testing.T) {
 		// Calling LOOKUP without a file handle should fail.
@@ -2590,7 +3338,7 @@ func TestBaseProgramCompound_OP_NVERIFY(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0xab, 0x23, 0xe8, 0x04, 0x79, 0x23, 0x0a, 0x27}
 	stateIDOtherPrefix := [...]byte{0x41, 0x40, 0x91, 0x69}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	// Only basic testing coverage for NVERIFY is provided, as it is
 	// assumed most of the logic is shared with VERIFY.
@@ -2701,7 +3449,7 @@ func TestBaseProgramCompound_OP_OPEN(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x18, 0xe4, 0x47, 0xf1, 0x31, 0x1c, 0xe2, 0x94}
 	stateIDOtherPrefix := [...]byte{0x5c, 0x71, 0xa6, 0x0d}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	clock.EXPECT().Now().Return(time.Unix(1000, 0))
 	clock.EXPECT().Now().Return(time.Unix(1001, 0))
@@ -2871,8 +3619,8 @@ func TestBaseProgramCompound_OP_OPEN(t *testing.T) {
 		t.Run("MismatchingDelegateType", func(t *testing.T) {
 			// When calling CLAIM_PREVIOUS, the client must
 			// provide a delegate type that is compatible
-			// with the state on the server. As we don't
-			// support delegations, the client MUST provide
+			// with the state on the server. As delegations
+			// can't be reclaimed, the client MUST provide
 			// OPEN_DELEGATE_NONE.
 			clock.EXPECT().Now().Return(time.Unix(1012, 0))
 			clock.EXPECT().Now().Return(time.Unix(1013, 0))
@@ -3129,7 +3877,7 @@ func TestBaseProgramCompound_OP_OPENATTR(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0xe6, 0x7e, 0xb7, 0xdb, 0x52, 0x9c, 0x7c, 0x86}
 	stateIDOtherPrefix := [...]byte{0x06, 0x00, 0x7c, 0x9d}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NoFileHandle", func(t *testing.T) {
 		// Calling OPENATTR without a file handle should fail.
@@ -3203,7 +3951,7 @@ func TestBaseProgramCompound_OP_OPEN_CONFIRM(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x42, 0xa8, 0x3f, 0xd1, 0xde, 0x65, 0x74, 0x2a}
 	stateIDOtherPrefix := [...]byte{0xfa, 0xc3, 0xf7, 0x18}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	clock.EXPECT().Now().Return(time.Unix(1000, 0))
 	clock.EXPECT().Now().Return(time.Unix(1001, 0))
@@ -3319,7 +4067,7 @@ func TestBaseProgramCompound_OP_OPEN_DOWNGRADE(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x4d, 0x0d, 0xc1, 0xca, 0xd9, 0xeb, 0x73, 0xc9}
 	stateIDOtherPrefix := [...]byte{0x2c, 0xa4, 0xce, 0xdc}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("AnonymousStateID", func(t *testing.T) {
 		// Calling OPEN_DOWNGRADE against the anonymous state ID
@@ -4035,7 +4783,7 @@ func TestBaseProgramCompound_OP_READ(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x58, 0x61, 0xb4, 0xff, 0x82, 0x40, 0x8f, 0x1a}
 	stateIDOtherPrefix := [...]byte{0x55, 0xc7, 0xc6, 0xa0}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("StaleStateID", func(t *testing.T) {
 		// Providing a state ID that uses an unknown prefix
@@ -4894,7 +5642,7 @@ func TestBaseProgramCompound_OP_READDIR(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x80, 0x29, 0x6e, 0xe3, 0x1a, 0xf1, 0xec, 0x41}
 	stateIDOtherPrefix := [...]byte{0xce, 0x11, 0x76, 0xe8}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NoFileHandle", func(t *testing.T) {
 		// Calling READDIR without a file handle should fail.
@@ -5218,7 +5966,7 @@ func TestBaseProgramCompound_OP_READLINK(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0xa8, 0x90, 0x8c, 0x43, 0xb7, 0xd6, 0x0f, 0x74}
 	stateIDOtherPrefix := [...]byte{0x46, 0x64, 0x44, 0x31}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NoFileHandle", func(t *testing.T) {
 		// Calling READLINK without a file handle should fail.
@@ -5360,7 +6108,7 @@ func TestBaseProgramCompound_OP_RELEASE_LOCKOWNER(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x27, 0xe1, 0xcd, 0x6a, 0x3f, 0xf8, 0xb7, 0xb2}
 	stateIDOtherPrefix := [...]byte{0xab, 0x4f, 0xf6, 0x1c}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("StaleClientID", func(t *testing.T) {
 		// Calling RELEASE_LOCKOWNER against a non-existent
@@ -5723,7 +6471,7 @@ func TestBaseProgramCompound_OP_REMOVE(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0xe7, 0x77, 0x33, 0xf4, 0x21, 0xad, 0x7a, 0x1b}
 	stateIDOtherPrefix := [...]byte{0x4b, 0x46, 0x62, 0x3c}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NoFileHandle", func(t *testing.T) {
 		// Calling REMOVE without a file handle should fail.
@@ -5935,6 +6683,104 @@ func TestBaseProgramCompound_OP_REMOVE(t *testing.T) {
 // TODO: RENAME
 // TODO: RENEW
 
+func TestBaseProgramCompound_OP_REMOVEXATTR(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
+	rootDirectory.EXPECT().VirtualGetAttributes(gomock.Any(), virtual.AttributesMaskFileHandle, gomock.Any()).
+		Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+			attributes.SetFileHandle([]byte{0xa1, 0x6f, 0x02, 0xcd, 0x3e, 0x95, 0x7b, 0x48})
+		})
+	handleResolver := mock.NewMockHandleResolver(ctrl)
+	randomNumberGenerator := mock.NewMockSingleThreadedGenerator(ctrl)
+	rebootVerifier := nfsv4_xdr.Verifier4{0x6b, 0x0e, 0xd3, 0x92, 0x21, 0x5c, 0xf7, 0x84}
+	stateIDOtherPrefix := [...]byte{0x2c, 0xb9, 0x74, 0x05}
+	clock := mock.NewMockClock(ctrl)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
+
+	t.Run("NoXAttr", func(t *testing.T) {
+		rootDirectory.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMaskChangeID, gomock.Any()).
+			Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+				attributes.SetChangeID(7)
+			})
+		rootDirectory.EXPECT().VirtualRemoveXAttr(ctx, "user.foo").Return(virtual.StatusErrNoXAttr)
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "removexattr",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+				&nfsv4_xdr.NfsArgop4_OP_REMOVEXATTR{
+					Opremovexattr: nfsv4_xdr.Removexattr4args{
+						RxaName: "foo",
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "removexattr",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
+					Opputrootfh: nfsv4_xdr.Putrootfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_REMOVEXATTR{
+					Opremovexattr: &nfsv4_xdr.Removexattr4res_default{
+						RxrStatus: nfsv4_xdr.NFS4ERR_NOXATTR,
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4ERR_NOXATTR,
+		}, res)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		gomock.InOrder(
+			rootDirectory.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMaskChangeID, gomock.Any()).
+				Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+					attributes.SetChangeID(7)
+				}),
+			rootDirectory.EXPECT().VirtualRemoveXAttr(ctx, "user.foo").Return(virtual.StatusOK),
+			rootDirectory.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMaskChangeID, gomock.Any()).
+				Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+					attributes.SetChangeID(8)
+				}))
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "removexattr",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+				&nfsv4_xdr.NfsArgop4_OP_REMOVEXATTR{
+					Opremovexattr: nfsv4_xdr.Removexattr4args{
+						RxaName: "foo",
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "removexattr",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
+					Opputrootfh: nfsv4_xdr.Putrootfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_REMOVEXATTR{
+					Opremovexattr: &nfsv4_xdr.Removexattr4res_NFS4_OK{
+						RxrInfo: nfsv4_xdr.ChangeInfo4{
+							Before: 7,
+							After:  8,
+						},
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4_OK,
+		}, res)
+	})
+}
+
 func TestBaseProgramCompound_OP_RESTOREFH(t *testing.T) {
 	ctrl, ctx := gomock.WithContext(context.Background(), t)
 
@@ -5948,7 +6794,7 @@ func TestBaseProgramCompound_OP_RESTOREFH(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x5f, 0x98, 0x5c, 0xdf, 0x8a, 0xac, 0x4d, 0x97}
 	stateIDOtherPrefix := [...]byte{0xd4, 0x7c, 0xd1, 0x8f}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NoSavedFileHandle", func(t *testing.T) {
 		// Calling RESTOREFH without a saved file handle should fail.
@@ -6052,7 +6898,7 @@ func TestBaseProgramCompound_OP_SAVEFH(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0xe9, 0xf5, 0x40, 0xa0, 0x20, 0xd9, 0x2c, 0x52}
 	stateIDOtherPrefix := [...]byte{0xf1, 0xd0, 0x0e, 0xa0}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NoFileHandle", func(t *testing.T) {
 		// Calling SAVEFH without a file handle should fail.
@@ -6092,7 +6938,7 @@ func TestBaseProgramCompound_OP_SECINFO(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x70, 0x34, 0xc6, 0x7a, 0x25, 0x6e, 0x08, 0xc0}
 	stateIDOtherPrefix := [...]byte{0xf9, 0x44, 0xa6, 0x25}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NoFileHandle", func(t *testing.T) {
 		// Calling SECINFO without a file handle should fail.
@@ -6318,7 +7164,7 @@ func TestBaseProgramCompound_OP_SETCLIENTID_CONFIRM(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x73, 0xaf, 0xeb, 0xd6, 0x5b, 0x96, 0x74, 0xde}
 	stateIDOtherPrefix := [...]byte{0xdb, 0xd3, 0xb5, 0x41}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NoKnownClientID", func(t *testing.T) {
 		// Calling SETCLIENTID_CONFIRM without calling
@@ -6620,6 +7466,143 @@ func TestBaseProgramCompound_OP_SETCLIENTID_CONFIRM(t *testing.T) {
 	})
 }
 
+func TestBaseProgramCompound_OP_SETXATTR(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
+	rootDirectory.EXPECT().VirtualGetAttributes(gomock.Any(), virtual.AttributesMaskFileHandle, gomock.Any()).
+		Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+			attributes.SetFileHandle([]byte{0x0f, 0xd4, 0x86, 0x5b, 0xe2, 0x17, 0xc9, 0x30})
+		})
+	handleResolver := mock.NewMockHandleResolver(ctrl)
+	randomNumberGenerator := mock.NewMockSingleThreadedGenerator(ctrl)
+	rebootVerifier := nfsv4_xdr.Verifier4{0xc5, 0x3b, 0x9a, 0x47, 0x08, 0xee, 0x61, 0xfd}
+	stateIDOtherPrefix := [...]byte{0x83, 0x5a, 0xde, 0x19}
+	clock := mock.NewMockClock(ctrl)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
+
+	t.Run("InvalidOption", func(t *testing.T) {
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "setxattr",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+				&nfsv4_xdr.NfsArgop4_OP_SETXATTR{
+					Opsetxattr: nfsv4_xdr.Setxattr4args{
+						SxaOption: 3,
+						SxaKey:    "foo",
+						SxaValue:  []byte("bar"),
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "setxattr",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
+					Opputrootfh: nfsv4_xdr.Putrootfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_SETXATTR{
+					Opsetxattr: &nfsv4_xdr.Setxattr4res_default{
+						SxrStatus: nfsv4_xdr.NFS4ERR_INVAL,
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4ERR_INVAL,
+		}, res)
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		// SETXATTR4_CREATE should fail if the extended
+		// attribute already exists.
+		rootDirectory.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMaskChangeID, gomock.Any()).
+			Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+				attributes.SetChangeID(12)
+			})
+		rootDirectory.EXPECT().VirtualSetXAttr(ctx, "user.foo", []byte("bar"), virtual.XAttrSetModeCreate).Return(virtual.StatusErrExist)
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "setxattr",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+				&nfsv4_xdr.NfsArgop4_OP_SETXATTR{
+					Opsetxattr: nfsv4_xdr.Setxattr4args{
+						SxaOption: nfsv4_xdr.SETXATTR4_CREATE,
+						SxaKey:    "foo",
+						SxaValue:  []byte("bar"),
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "setxattr",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
+					Opputrootfh: nfsv4_xdr.Putrootfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_SETXATTR{
+					Opsetxattr: &nfsv4_xdr.Setxattr4res_default{
+						SxrStatus: nfsv4_xdr.NFS4ERR_EXIST,
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4ERR_EXIST,
+		}, res)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		gomock.InOrder(
+			rootDirectory.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMaskChangeID, gomock.Any()).
+				Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+					attributes.SetChangeID(12)
+				}),
+			rootDirectory.EXPECT().VirtualSetXAttr(ctx, "user.foo", []byte("bar"), virtual.XAttrSetModeReplace).Return(virtual.StatusOK),
+			rootDirectory.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMaskChangeID, gomock.Any()).
+				Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+					attributes.SetChangeID(13)
+				}))
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "setxattr",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+				&nfsv4_xdr.NfsArgop4_OP_SETXATTR{
+					Opsetxattr: nfsv4_xdr.Setxattr4args{
+						SxaOption: nfsv4_xdr.SETXATTR4_REPLACE,
+						SxaKey:    "foo",
+						SxaValue:  []byte("bar"),
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "setxattr",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
+					Opputrootfh: nfsv4_xdr.Putrootfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_SETXATTR{
+					Opsetxattr: &nfsv4_xdr.Setxattr4res_NFS4_OK{
+						SxrInfo: nfsv4_xdr.ChangeInfo4{
+							Before: 12,
+							After:  13,
+						},
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4_OK,
+		}, res)
+	})
+}
+
 func TestBaseProgramCompound_OP_VERIFY(t *testing.T) {
 	ctrl, ctx := gomock.WithContext(context.Background(), t)
 
@@ -6633,7 +7616,7 @@ func TestBaseProgramCompound_OP_VERIFY(t *testing.T) {
 	rebootVerifier := nfsv4_xdr.Verifier4{0x71, 0x69, 0x6c, 0x7c, 0x90, 0x79, 0x3b, 0x13}
 	stateIDOtherPrefix := [...]byte{0x19, 0xed, 0x93, 0x5f}
 	clock := mock.NewMockClock(ctrl)
-	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{}, nil)
 
 	t.Run("NoFileHandle", func(t *testing.T) {
 		// Calling VERIFY without a file handle should fail.
@@ -6958,3 +7941,153 @@ func TestBaseProgramCompound_OP_VERIFY(t *testing.T) {
 }
 
 // TODO: WRITE
+
+func TestBaseProgramCompound_ClientStateLimits(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
+	rootDirectory.EXPECT().VirtualGetAttributes(gomock.Any(), virtual.AttributesMaskFileHandle, gomock.Any()).
+		Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+			attributes.SetFileHandle([]byte{0x7e, 0x2c, 0x41, 0x9b, 0x06, 0xd3, 0x58, 0xa0})
+		})
+	handleResolver := mock.NewMockHandleResolver(ctrl)
+	randomNumberGenerator := mock.NewMockSingleThreadedGenerator(ctrl)
+	rebootVerifier := nfsv4_xdr.Verifier4{0x4b, 0x91, 0x0e, 0xc7, 0x23, 0x5f, 0xa8, 0x6d}
+	stateIDOtherPrefix := [...]byte{0x2f, 0x83, 0xd4, 0x19}
+	clock := mock.NewMockClock(ctrl)
+	program := nfsv4.NewBaseProgram(rootDirectory, handleResolver.Call, randomNumberGenerator, rebootVerifier, stateIDOtherPrefix, clock, 2*time.Minute, time.Minute, nfsv4.ClientStateLimits{
+		MaximumOpenFiles:      1,
+		ExpirationGracePeriod: time.Hour,
+	}, nil)
+
+	clock.EXPECT().Now().Return(time.Unix(1000, 0))
+	clock.EXPECT().Now().Return(time.Unix(1001, 0))
+	setClientIDForTesting(ctx, t, randomNumberGenerator, program, 0x5e1a7d30c29b84f6)
+
+	// Open a single file, which is permitted.
+	leaf1 := mock.NewMockVirtualLeaf(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(1002, 0))
+	clock.EXPECT().Now().Return(time.Unix(1003, 0))
+	openUnconfirmedFileForTesting(
+		ctx,
+		t,
+		randomNumberGenerator,
+		program,
+		rootDirectory,
+		leaf1,
+		nfsv4_xdr.NfsFh4{0xd1, 0x6b, 0x3e, 0x08, 0x9a, 0x45, 0xc2, 0x77},
+		/* shortClientID = */ 0x5e1a7d30c29b84f6,
+		/* seqID = */ 241,
+		/* stateIDOther = */ [...]byte{
+			0x2f, 0x83, 0xd4, 0x19,
+			0x60, 0xb2, 0x1c, 0xe5,
+			0x39, 0x8d, 0xf4, 0x0a,
+		})
+	clock.EXPECT().Now().Return(time.Unix(1004, 0))
+	clock.EXPECT().Now().Return(time.Unix(1005, 0))
+	openConfirmForTesting(
+		ctx,
+		t,
+		randomNumberGenerator,
+		program,
+		nfsv4_xdr.NfsFh4{0xd1, 0x6b, 0x3e, 0x08, 0x9a, 0x45, 0xc2, 0x77},
+		/* seqID = */ 242,
+		/* stateIDOther = */ [...]byte{
+			0x2f, 0x83, 0xd4, 0x19,
+			0x60, 0xb2, 0x1c, 0xe5,
+			0x39, 0x8d, 0xf4, 0x0a,
+		})
+
+	t.Run("OpenFilesLimitExceeded", func(t *testing.T) {
+		// Opening a second file should fail, as it would cause
+		// the client to exceed the limit on the number of open
+		// files. The limit should be checked before calling
+		// into the file system, as opening may create or
+		// truncate the file.
+		clock.EXPECT().Now().Return(time.Unix(1006, 0))
+
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "open",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_PUTROOTFH{},
+				&nfsv4_xdr.NfsArgop4_OP_OPEN{
+					Opopen: nfsv4_xdr.Open4args{
+						Seqid:       243,
+						ShareAccess: nfsv4_xdr.OPEN4_SHARE_ACCESS_READ,
+						ShareDeny:   nfsv4_xdr.OPEN4_SHARE_DENY_NONE,
+						Owner: nfsv4_xdr.OpenOwner4{
+							Clientid: 0x5e1a7d30c29b84f6,
+							Owner:    []byte{0xc4, 0x85, 0x50, 0x6b, 0xa5, 0xec, 0x8e, 0x2c},
+						},
+						Openhow: &nfsv4_xdr.Openflag4_default{},
+						Claim: &nfsv4_xdr.OpenClaim4_CLAIM_NULL{
+							File: "World",
+						},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "open",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_PUTROOTFH{
+					Opputrootfh: nfsv4_xdr.Putrootfh4res{
+						Status: nfsv4_xdr.NFS4_OK,
+					},
+				},
+				&nfsv4_xdr.NfsResop4_OP_OPEN{
+					Opopen: &nfsv4_xdr.Open4res_default{
+						Status: nfsv4_xdr.NFS4ERR_RESOURCE,
+					},
+				},
+			},
+			Status: nfsv4_xdr.NFS4ERR_RESOURCE,
+		}, res)
+	})
+
+	renew := func(t *testing.T, expectedStatus nfsv4_xdr.Nfsstat4) {
+		res, err := program.NfsV4Nfsproc4Compound(ctx, &nfsv4_xdr.Compound4args{
+			Tag: "renew",
+			Argarray: []nfsv4_xdr.NfsArgop4{
+				&nfsv4_xdr.NfsArgop4_OP_RENEW{
+					Oprenew: nfsv4_xdr.Renew4args{
+						Clientid: 0x5e1a7d30c29b84f6,
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &nfsv4_xdr.Compound4res{
+			Tag: "renew",
+			Resarray: []nfsv4_xdr.NfsResop4{
+				&nfsv4_xdr.NfsResop4_OP_RENEW{
+					Oprenew: nfsv4_xdr.Renew4res{
+						Status: expectedStatus,
+					},
+				},
+			},
+			Status: expectedStatus,
+		}, res)
+	}
+
+	t.Run("Expired", func(t *testing.T) {
+		// Once the client's lease expires, its state should be
+		// removed. Subsequent operations against the client
+		// should fail with NFS4ERR_EXPIRED, so that the client
+		// knows that its state has been lost.
+		clock.EXPECT().Now().Return(time.Unix(1200, 0))
+		leaf1.EXPECT().VirtualClose(virtual.ShareMaskRead)
+		clock.EXPECT().Now().Return(time.Unix(1200, 0))
+
+		renew(t, nfsv4_xdr.NFS4ERR_EXPIRED)
+	})
+
+	t.Run("GracePeriodEnded", func(t *testing.T) {
+		// After the grace period ends, the server should no
+		// longer be aware of the client.
+		clock.EXPECT().Now().Return(time.Unix(4801, 0))
+
+		renew(t, nfsv4_xdr.NFS4ERR_STALE_CLIENTID)
+	})
+}
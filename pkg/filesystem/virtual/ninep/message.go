@@ -0,0 +1,263 @@
+package ninep
+
+import (
+	"encoding/binary"
+)
+
+// Message types of the 9P2000.L protocol. Only the T-messages that
+// are handled by the server and the R-messages that are sent back are
+// listed.
+//
+// Reference: https://github.com/chaos/diod/blob/master/protocol.md
+const (
+	messageTypeRlerror      = 7
+	messageTypeTstatfs      = 8
+	messageTypeTlopen       = 12
+	messageTypeTlcreate     = 14
+	messageTypeTsymlink     = 16
+	messageTypeTmknod       = 18
+	messageTypeTrename      = 20
+	messageTypeTreadlink    = 22
+	messageTypeTgetattr     = 24
+	messageTypeTsetattr     = 26
+	messageTypeTxattrwalk   = 30
+	messageTypeTxattrcreate = 32
+	messageTypeTreaddir     = 40
+	messageTypeTfsync       = 50
+	messageTypeTlock        = 52
+	messageTypeTgetlock     = 54
+	messageTypeTlink        = 70
+	messageTypeTmkdir       = 72
+	messageTypeTrenameat    = 74
+	messageTypeTunlinkat    = 76
+	messageTypeTversion     = 100
+	messageTypeTauth        = 102
+	messageTypeTattach      = 104
+	messageTypeTflush       = 108
+	messageTypeTwalk        = 110
+	messageTypeTread        = 116
+	messageTypeTwrite       = 118
+	messageTypeTclunk       = 120
+	messageTypeTremove      = 122
+)
+
+const (
+	// Size of the header that is prepended to every message,
+	// consisting of size[4] type[1] tag[2].
+	messageHeaderSizeBytes = 7
+	// Size of a qid, consisting of type[1] version[4] path[8].
+	qidSizeBytes = 13
+	// Maximum number of path components that may be provided to
+	// Twalk.
+	maximumWalkElements = 16
+	// Version string of the protocol that is implemented.
+	protocolVersion = "9P2000.L"
+)
+
+// Values of the type field of a qid.
+const (
+	qidTypeDirectory = 0x80
+	qidTypeSymlink   = 0x02
+	qidTypeFile      = 0x00
+)
+
+// Bits of the request_mask and valid fields of Tgetattr and Rgetattr.
+const (
+	getattrMode   = 0x00000001
+	getattrNlink  = 0x00000002
+	getattrUID    = 0x00000004
+	getattrGID    = 0x00000008
+	getattrRdev   = 0x00000010
+	getattrAtime  = 0x00000020
+	getattrMtime  = 0x00000040
+	getattrCtime  = 0x00000080
+	getattrIno    = 0x00000100
+	getattrSize   = 0x00000200
+	getattrBlocks = 0x00000400
+)
+
+// Bits of the valid field of Tsetattr.
+const (
+	setattrMode     = 0x00000001
+	setattrUID      = 0x00000002
+	setattrGID      = 0x00000004
+	setattrSize     = 0x00000008
+	setattrAtime    = 0x00000010
+	setattrMtime    = 0x00000020
+	setattrCtime    = 0x00000040
+	setattrAtimeSet = 0x00000080
+	setattrMtimeSet = 0x00000100
+)
+
+// Flags that may be provided to Tlopen and Tlcreate. These use the
+// same values as Linux's open() flags.
+const (
+	openFlagsAccessModeMask = 0x3
+	openFlagsReadOnly       = 0x0
+	openFlagsWriteOnly      = 0x1
+	openFlagsReadWrite      = 0x2
+	openFlagsExclusive      = 0x80
+	openFlagsTruncate       = 0x200
+)
+
+// Flag that may be provided to Tunlinkat to remove a directory.
+const unlinkatRemoveDirectory = 0x200
+
+// Values for the status field of Rlock and the type field of Rgetlock.
+const (
+	lockStatusSuccess = 0
+	lockTypeUnlock    = 2
+)
+
+// Error numbers returned as part of Rlerror. These use the same values
+// as Linux, regardless of the operating system on which the server
+// runs.
+const (
+	errnoEPERM      = 1
+	errnoENOENT     = 2
+	errnoEIO        = 5
+	errnoENXIO      = 6
+	errnoEBADF      = 9
+	errnoEACCES     = 13
+	errnoEEXIST     = 17
+	errnoEXDEV      = 18
+	errnoENOTDIR    = 20
+	errnoEISDIR     = 21
+	errnoEINVAL     = 22
+	errnoENOSPC     = 28
+	errnoEROFS      = 30
+	errnoENOTEMPTY  = 39
+	errnoENODATA    = 61
+	errnoEPROTO     = 71
+	errnoEOPNOTSUPP = 95
+	errnoESTALE     = 116
+)
+
+// Magic value returned by Tstatfs, corresponding to Linux's
+// V9FS_MAGIC.
+const statfsType = 0x01021997
+
+// qid is a unique identifier of a file on the server.
+type qid struct {
+	qidType uint8
+	version uint32
+	path    uint64
+}
+
+// messageDecoder can be used to extract fields from the body of an
+// incoming message. Decoding errors are sticky, meaning that callers
+// only need to check for them after all fields have been extracted.
+type messageDecoder struct {
+	body []byte
+	ok   bool
+}
+
+func newMessageDecoder(body []byte) *messageDecoder {
+	return &messageDecoder{
+		body: body,
+		ok:   true,
+	}
+}
+
+// take extracts the next n bytes from the message body. Lengths are
+// provided by the client, meaning that no memory is allocated if the
+// message body is too short. nil is returned instead.
+func (d *messageDecoder) take(n uint32) []byte {
+	if !d.ok || uint32(len(d.body)) < n {
+		d.ok = false
+		return nil
+	}
+	b := d.body[:n]
+	d.body = d.body[n:]
+	return b
+}
+
+func (d *messageDecoder) uint8() uint8 {
+	if b := d.take(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *messageDecoder) uint16() uint16 {
+	if b := d.take(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *messageDecoder) uint32() uint32 {
+	if b := d.take(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *messageDecoder) uint64() uint64 {
+	if b := d.take(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (d *messageDecoder) bytes(n uint32) []byte {
+	return d.take(n)
+}
+
+func (d *messageDecoder) string() string {
+	return string(d.take(uint32(d.uint16())))
+}
+
+// messageEncoder can be used to construct an outgoing message. Space
+// for the message header is reserved upfront, and is filled in when
+// the message is finalized.
+type messageEncoder struct {
+	buf []byte
+}
+
+func newMessageEncoder(messageType uint8, tag uint16) *messageEncoder {
+	e := &messageEncoder{
+		buf: make([]byte, messageHeaderSizeBytes, 64),
+	}
+	e.buf[4] = messageType
+	binary.LittleEndian.PutUint16(e.buf[5:], tag)
+	return e
+}
+
+func (e *messageEncoder) uint8(v uint8) {
+	e.buf = append(e.buf, v)
+}
+
+func (e *messageEncoder) uint16(v uint16) {
+	e.buf = binary.LittleEndian.AppendUint16(e.buf, v)
+}
+
+func (e *messageEncoder) uint32(v uint32) {
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, v)
+}
+
+func (e *messageEncoder) uint64(v uint64) {
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, v)
+}
+
+func (e *messageEncoder) bytes(v []byte) {
+	e.buf = append(e.buf, v...)
+}
+
+func (e *messageEncoder) string(v string) {
+	e.uint16(uint16(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *messageEncoder) qid(q qid) {
+	e.uint8(q.qidType)
+	e.uint32(q.version)
+	e.uint64(q.path)
+}
+
+// finalize fills in the size field of the message header and returns
+// the resulting message.
+func (e *messageEncoder) finalize() []byte {
+	binary.LittleEndian.PutUint32(e.buf, uint32(len(e.buf)))
+	return e.buf
+}
@@ -0,0 +1,1325 @@
+package ninep
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AttributesMaskForQID is the attributes mask to use for
+// VirtualGetAttributes() and VirtualLookup() to populate all fields of
+// a 9P qid.
+const AttributesMaskForQID = virtual.AttributesMaskFileType |
+	virtual.AttributesMaskInodeNumber
+
+// AttributesMaskForGetattr is the attributes mask to use for
+// VirtualGetAttributes() to populate all fields of Rgetattr.
+const AttributesMaskForGetattr = AttributesMaskForQID |
+	virtual.AttributesMaskDeviceNumber |
+	virtual.AttributesMaskLastDataAccessTime |
+	virtual.AttributesMaskLastDataModificationTime |
+	virtual.AttributesMaskLastStatusChangeTime |
+	virtual.AttributesMaskLinkCount |
+	virtual.AttributesMaskPermissions |
+	virtual.AttributesMaskSizeBytes
+
+// File type bits of a mode, using the same values as Linux.
+const (
+	modeTypeMask        = 0o170000
+	modeTypeSocket      = 0o140000
+	modeTypeSymlink     = 0o120000
+	modeTypeRegular     = 0o100000
+	modeTypeBlockDevice = 0o060000
+	modeTypeDirectory   = 0o040000
+	modeTypeCharDevice  = 0o020000
+	modeTypeFIFO        = 0o010000
+)
+
+// MinimumMessageSizeBytes is the smallest maximum message size that
+// clients may negotiate. It is large enough to hold the header of
+// Rread and Rwrite, and a reasonable number of path components.
+const MinimumMessageSizeBytes = 4096
+
+func toErrno(s virtual.Status) uint32 {
+	switch s {
+	case virtual.StatusErrAccess:
+		return errnoEACCES
+	case virtual.StatusErrBadHandle:
+		return errnoEBADF
+	case virtual.StatusErrExist:
+		return errnoEEXIST
+	case virtual.StatusErrInval:
+		return errnoEINVAL
+	case virtual.StatusErrIO:
+		return errnoEIO
+	case virtual.StatusErrIsDir:
+		return errnoEISDIR
+	case virtual.StatusErrNoEnt:
+		return errnoENOENT
+	case virtual.StatusErrNoSpc:
+		return errnoENOSPC
+	case virtual.StatusErrNoXAttr:
+		return errnoENODATA
+	case virtual.StatusErrNotDir:
+		return errnoENOTDIR
+	case virtual.StatusErrNotEmpty:
+		return errnoENOTEMPTY
+	case virtual.StatusErrNXIO:
+		return errnoENXIO
+	case virtual.StatusErrPerm:
+		return errnoEPERM
+	case virtual.StatusErrROFS:
+		return errnoEROFS
+	case virtual.StatusErrStale:
+		return errnoESTALE
+	case virtual.StatusErrSymlink:
+		return errnoEOPNOTSUPP
+	case virtual.StatusErrWrongType:
+		return errnoEBADF
+	case virtual.StatusErrXDev:
+		return errnoEXDEV
+	default:
+		panic("Unknown status")
+	}
+}
+
+func toMode(fileType filesystem.FileType) uint32 {
+	switch fileType {
+	case filesystem.FileTypeBlockDevice:
+		return modeTypeBlockDevice
+	case filesystem.FileTypeCharacterDevice:
+		return modeTypeCharDevice
+	case filesystem.FileTypeDirectory:
+		return modeTypeDirectory
+	case filesystem.FileTypeFIFO:
+		return modeTypeFIFO
+	case filesystem.FileTypeRegularFile:
+		return modeTypeRegular
+	case filesystem.FileTypeSocket:
+		return modeTypeSocket
+	case filesystem.FileTypeSymlink:
+		return modeTypeSymlink
+	default:
+		panic("Unknown file type")
+	}
+}
+
+func toQID(attributes *virtual.Attributes) qid {
+	q := qid{path: attributes.GetInodeNumber()}
+	switch attributes.GetFileType() {
+	case filesystem.FileTypeDirectory:
+		q.qidType = qidTypeDirectory
+	case filesystem.FileTypeSymlink:
+		q.qidType = qidTypeSymlink
+	default:
+		q.qidType = qidTypeFile
+	}
+	return q
+}
+
+// newComponent converts a filename string that's provided as part of
+// an incoming request to a pathname component that can be provided to
+// the virtual file system layer.
+func newComponent(name string) (path.Component, uint32) {
+	component, ok := path.NewComponent(name)
+	if !ok {
+		return path.Component{}, errnoEINVAL
+	}
+	return component, 0
+}
+
+// openFlagsToShareMask converts access modes stored in open() flags to
+// a ShareMask, indicating which operations are expected to be called
+// against the file descriptor.
+func openFlagsToShareMask(flags uint32) (virtual.ShareMask, uint32) {
+	switch flags & openFlagsAccessModeMask {
+	case openFlagsReadOnly:
+		return virtual.ShareMaskRead, 0
+	case openFlagsWriteOnly:
+		return virtual.ShareMaskWrite, 0
+	case openFlagsReadWrite:
+		return virtual.ShareMaskRead | virtual.ShareMaskWrite, 0
+	default:
+		return 0, errnoEINVAL
+	}
+}
+
+// Server of the 9P2000.L protocol that exposes a hierarchy of Directory
+// and Leaf objects. Connections are expected to be established by the
+// Linux kernel's v9fs client, using the unix or tcp transports. The
+// virtio transport used by virtual machine monitors (e.g., QEMU and
+// crosvm) is not supported, as it does not use a byte stream.
+//
+// This implementation keeps track of fids on a per-connection basis,
+// meaning that it does not need to resolve file handles. It should
+// therefore be used in combination with a handle allocator that is
+// suited for stateful protocols, such as the one used by FUSE.
+type Server struct {
+	rootDirectory             virtual.Directory
+	maximumMessageSizeBytes   uint32
+	maximumConcurrentRequests int
+	clock                     clock.Clock
+}
+
+// NewServer creates a 9P2000.L server that exposes the provided root
+// directory. Connections may be handed to the server by calling
+// HandleConnection().
+func NewServer(rootDirectory virtual.Directory, maximumMessageSizeBytes uint32, maximumConcurrentRequests int, clock clock.Clock) *Server {
+	return &Server{
+		rootDirectory:             rootDirectory,
+		maximumMessageSizeBytes:   maximumMessageSizeBytes,
+		maximumConcurrentRequests: maximumConcurrentRequests,
+		clock:                     clock,
+	}
+}
+
+// HandleConnection processes 9P2000.L requests received from a single
+// client, until the connection is closed. Requests are processed
+// concurrently, meaning that slow operations (e.g., reads of files
+// that need to be downloaded) don't block unrelated requests. Tversion
+// is only processed after all outstanding requests have completed.
+//
+// The number of requests processed concurrently is limited per
+// connection. Once the limit is reached, no further requests are read
+// from the connection until one of the outstanding requests completes.
+// This bounds the amount of memory a client can cause the server to
+// allocate to roughly the maximum message size multiplied by this
+// limit.
+//
+// Files that are still opened when the connection is closed are closed
+// implicitly.
+func (s *Server) HandleConnection(ctx context.Context, r io.Reader, w io.Writer) error {
+	c := connection{
+		server:           s,
+		ctx:              ctx,
+		w:                w,
+		messageSizeBytes: s.maximumMessageSizeBytes,
+		fids:             map[uint32]*fidState{},
+		pendingTags:      map[uint16]chan struct{}{},
+	}
+	var wg sync.WaitGroup
+	defer func() {
+		wg.Wait()
+		c.clunkAll()
+	}()
+	requestSlots := make(chan struct{}, s.maximumConcurrentRequests)
+
+	var header [messageHeaderSizeBytes]byte
+	for {
+		// Only read the next request once there is capacity to
+		// process it.
+		requestSlots <- struct{}{}
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if err == io.EOF {
+				wg.Wait()
+				return c.getWriteError()
+			}
+			return util.StatusWrap(err, "Failed to read message header")
+		}
+		size := binary.LittleEndian.Uint32(header[:])
+		if size < messageHeaderSizeBytes || size > c.messageSizeBytes {
+			return status.Errorf(codes.InvalidArgument, "Message has size %d, while the negotiated maximum message size is %d", size, c.messageSizeBytes)
+		}
+		body := make([]byte, size-messageHeaderSizeBytes)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return util.StatusWrap(err, "Failed to read message body")
+		}
+		if err := c.getWriteError(); err != nil {
+			return err
+		}
+
+		messageType := header[4]
+		tag := binary.LittleEndian.Uint16(header[5:])
+		done := make(chan struct{})
+		c.lock.Lock()
+		c.pendingTags[tag] = done
+		c.lock.Unlock()
+		if messageType == messageTypeTversion {
+			// Tversion aborts all outstanding I/O and may
+			// change the maximum message size. Process it
+			// synchronously, so that it never races with
+			// other requests.
+			wg.Wait()
+			c.handleRequest(messageType, tag, body, done)
+			<-requestSlots
+		} else {
+			wg.Add(1)
+			go func() {
+				c.handleRequest(messageType, tag, body, done)
+				<-requestSlots
+				wg.Done()
+			}()
+		}
+	}
+}
+
+// fidState contains the state associated with a fid that has been
+// established by the client through Tattach, Twalk or Tlcreate. As
+// requests are processed concurrently, instances are never modified
+// after being added to the fid table. Changes are made by replacing
+// them.
+type fidState struct {
+	node virtual.DirectoryChild
+	// Directories leading up to the node, which are needed to
+	// resolve "..". The root directory has no parent directories.
+	parents []virtual.Directory
+
+	opened      bool
+	shareAccess virtual.ShareMask
+}
+
+func (f *fidState) getDirectory() (virtual.Directory, uint32) {
+	directory, _ := f.node.GetPair()
+	if directory == nil {
+		return nil, errnoENOTDIR
+	}
+	return directory, 0
+}
+
+func (f *fidState) getLeaf() (virtual.Leaf, uint32) {
+	_, leaf := f.node.GetPair()
+	if leaf == nil {
+		return nil, errnoEISDIR
+	}
+	return leaf, 0
+}
+
+// close releases the share access that was acquired when the fid was
+// opened.
+func (f *fidState) close() {
+	if f.shareAccess != 0 {
+		_, leaf := f.node.GetPair()
+		leaf.VirtualClose(f.shareAccess)
+	}
+}
+
+type connection struct {
+	server *Server
+	ctx    context.Context
+
+	// Only modified by Tversion, which is processed while no
+	// other requests are in flight.
+	messageSizeBytes uint32
+
+	lock        sync.Mutex
+	fids        map[uint32]*fidState
+	pendingTags map[uint16]chan struct{}
+
+	writeLock  sync.Mutex
+	w          io.Writer
+	writeError error
+}
+
+// handleRequest processes a single request and writes its response.
+// Once written, the request is removed from the set of pending
+// requests, so that any Tflush referring to it may complete.
+func (c *connection) handleRequest(messageType uint8, tag uint16, body []byte, done chan struct{}) {
+	response, errno := c.handleMessage(messageType, tag, newMessageDecoder(body))
+	if errno != 0 {
+		response = newMessageEncoder(messageTypeRlerror, tag)
+		response.uint32(errno)
+	}
+
+	c.writeLock.Lock()
+	if c.writeError == nil {
+		if _, err := c.w.Write(response.finalize()); err != nil {
+			c.writeError = util.StatusWrap(err, "Failed to write response")
+		}
+	}
+	c.writeLock.Unlock()
+
+	c.lock.Lock()
+	if c.pendingTags[tag] == done {
+		delete(c.pendingTags, tag)
+	}
+	c.lock.Unlock()
+	close(done)
+}
+
+func (c *connection) getWriteError() error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	return c.writeError
+}
+
+func (c *connection) getFid(fid uint32) (*fidState, uint32) {
+	c.lock.Lock()
+	f, ok := c.fids[fid]
+	c.lock.Unlock()
+	if !ok {
+		return nil, errnoEBADF
+	}
+	return f, 0
+}
+
+func (c *connection) getDirectoryFid(fid uint32) (*fidState, virtual.Directory, uint32) {
+	f, errno := c.getFid(fid)
+	if errno != 0 {
+		return nil, nil, errno
+	}
+	directory, errno := f.getDirectory()
+	return f, directory, errno
+}
+
+func (c *connection) getLeafFid(fid uint32) (virtual.Leaf, uint32) {
+	f, errno := c.getFid(fid)
+	if errno != 0 {
+		return nil, errno
+	}
+	return f.getLeaf()
+}
+
+// insertFid associates state with a fid that is not in use.
+func (c *connection) insertFid(fid uint32, f *fidState) uint32 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.fids[fid]; ok {
+		return errnoEBADF
+	}
+	c.fids[fid] = f
+	return 0
+}
+
+// replaceFid replaces the state associated with a fid, but only if it
+// hasn't been altered by a concurrent request since it was obtained.
+func (c *connection) replaceFid(fid uint32, oldF, newF *fidState) uint32 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.fids[fid] != oldF {
+		return errnoEBADF
+	}
+	c.fids[fid] = newF
+	return 0
+}
+
+func (c *connection) clunk(fid uint32) uint32 {
+	c.lock.Lock()
+	f, ok := c.fids[fid]
+	delete(c.fids, fid)
+	c.lock.Unlock()
+	if !ok {
+		return errnoEBADF
+	}
+	f.close()
+	return 0
+}
+
+func (c *connection) clunkAll() {
+	c.lock.Lock()
+	fids := c.fids
+	c.fids = map[uint32]*fidState{}
+	c.lock.Unlock()
+	for _, f := range fids {
+		f.close()
+	}
+}
+
+func (c *connection) handleMessage(messageType uint8, tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	switch messageType {
+	case messageTypeTversion:
+		return c.handleVersion(tag, d)
+	case messageTypeTattach:
+		return c.handleAttach(tag, d)
+	case messageTypeTflush:
+		return c.handleFlush(tag, d)
+	case messageTypeTwalk:
+		return c.handleWalk(tag, d)
+	case messageTypeTlopen:
+		return c.handleLopen(tag, d)
+	case messageTypeTlcreate:
+		return c.handleLcreate(tag, d)
+	case messageTypeTread:
+		return c.handleRead(tag, d)
+	case messageTypeTwrite:
+		return c.handleWrite(tag, d)
+	case messageTypeTclunk:
+		return c.handleClunk(tag, d)
+	case messageTypeTremove:
+		return c.handleRemove(tag, d)
+	case messageTypeTstatfs:
+		return c.handleStatfs(tag, d)
+	case messageTypeTsymlink:
+		return c.handleSymlink(tag, d)
+	case messageTypeTmknod:
+		return c.handleMknod(tag, d)
+	case messageTypeTreadlink:
+		return c.handleReadlink(tag, d)
+	case messageTypeTgetattr:
+		return c.handleGetattr(tag, d)
+	case messageTypeTsetattr:
+		return c.handleSetattr(tag, d)
+	case messageTypeTreaddir:
+		return c.handleReaddir(tag, d)
+	case messageTypeTfsync:
+		return c.handleFsync(tag, d)
+	case messageTypeTlock:
+		return c.handleLock(tag, d)
+	case messageTypeTgetlock:
+		return c.handleGetlock(tag, d)
+	case messageTypeTlink:
+		return c.handleLink(tag, d)
+	case messageTypeTmkdir:
+		return c.handleMkdir(tag, d)
+	case messageTypeTrenameat:
+		return c.handleRenameat(tag, d)
+	case messageTypeTunlinkat:
+		return c.handleUnlinkat(tag, d)
+	default:
+		// Authentication, extended attributes and Trename
+		// are not supported. The Linux kernel falls back to
+		// Trenameat when Trename is not supported.
+		return nil, errnoEOPNOTSUPP
+	}
+}
+
+func (c *connection) handleVersion(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	messageSizeBytes := d.uint32()
+	version := d.string()
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	// Negotiating the version aborts all outstanding I/O.
+	c.clunkAll()
+
+	if messageSizeBytes > c.server.maximumMessageSizeBytes {
+		messageSizeBytes = c.server.maximumMessageSizeBytes
+	}
+	if messageSizeBytes < MinimumMessageSizeBytes {
+		return nil, errnoEINVAL
+	}
+	c.messageSizeBytes = messageSizeBytes
+
+	e := newMessageEncoder(messageTypeTversion+1, tag)
+	e.uint32(messageSizeBytes)
+	if strings.HasPrefix(version, protocolVersion) {
+		e.string(protocolVersion)
+	} else {
+		e.string("unknown")
+	}
+	return e, 0
+}
+
+func (c *connection) handleAttach(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	d.uint32() // afid.
+	d.string() // uname.
+	d.string() // aname.
+	d.uint32() // n_uname.
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	rootDirectory := c.server.rootDirectory
+	var attributes virtual.Attributes
+	rootDirectory.VirtualGetAttributes(c.ctx, AttributesMaskForQID, &attributes)
+	if errno := c.insertFid(fid, &fidState{
+		node: virtual.DirectoryChild{}.FromDirectory(rootDirectory),
+	}); errno != 0 {
+		return nil, errno
+	}
+
+	e := newMessageEncoder(messageTypeTattach+1, tag)
+	e.qid(toQID(&attributes))
+	return e, 0
+}
+
+func (c *connection) handleFlush(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	oldTag := d.uint16()
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	// Requests cannot be aborted. Wait for the response of the
+	// request to be written, as the client may reuse its tag as
+	// soon as Rflush is received.
+	c.lock.Lock()
+	done, ok := c.pendingTags[oldTag]
+	c.lock.Unlock()
+	if ok && oldTag != tag {
+		<-done
+	}
+	return newMessageEncoder(messageTypeTflush+1, tag), 0
+}
+
+func (c *connection) handleWalk(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	newFid := d.uint32()
+	names := make([]string, d.uint16())
+	if len(names) > maximumWalkElements {
+		return nil, errnoEINVAL
+	}
+	for i := range names {
+		names[i] = d.string()
+	}
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	f, errno := c.getFid(fid)
+	if errno != 0 {
+		return nil, errno
+	}
+	if f.opened {
+		return nil, errnoEBADF
+	}
+	if newFid != fid {
+		if _, errno := c.getFid(newFid); errno == 0 {
+			return nil, errnoEBADF
+		}
+	}
+
+	node := f.node
+	parents := append([]virtual.Directory(nil), f.parents...)
+	qids := make([]qid, 0, len(names))
+	for _, name := range names {
+		var q qid
+		node, parents, q, errno = c.walkComponent(node, parents, name)
+		if errno != 0 {
+			if len(qids) == 0 {
+				// Only report failures if the
+				// first path component couldn't be
+				// resolved.
+				return nil, errno
+			}
+			break
+		}
+		qids = append(qids, q)
+	}
+
+	// Only associate the new fid with the resulting node if all
+	// path components were resolved.
+	if len(qids) == len(names) {
+		newF := &fidState{
+			node:    node,
+			parents: parents,
+		}
+		if newFid == fid {
+			errno = c.replaceFid(fid, f, newF)
+		} else {
+			errno = c.insertFid(newFid, newF)
+		}
+		if errno != 0 {
+			return nil, errno
+		}
+	}
+
+	e := newMessageEncoder(messageTypeTwalk+1, tag)
+	e.uint16(uint16(len(qids)))
+	for _, q := range qids {
+		e.qid(q)
+	}
+	return e, 0
+}
+
+// walkComponent resolves a single pathname component on behalf of
+// Twalk. As directories don't keep track of their parents, ".." is
+// resolved using the list of directories that were traversed to reach
+// the current node.
+func (c *connection) walkComponent(node virtual.DirectoryChild, parents []virtual.Directory, name string) (virtual.DirectoryChild, []virtual.Directory, qid, uint32) {
+	directory, _ := node.GetPair()
+	if directory == nil {
+		return node, parents, qid{}, errnoENOTDIR
+	}
+
+	var attributes virtual.Attributes
+	switch name {
+	case ".":
+		directory.VirtualGetAttributes(c.ctx, AttributesMaskForQID, &attributes)
+	case "..":
+		if len(parents) > 0 {
+			directory = parents[len(parents)-1]
+			node = virtual.DirectoryChild{}.FromDirectory(directory)
+			parents = parents[:len(parents)-1]
+		}
+		directory.VirtualGetAttributes(c.ctx, AttributesMaskForQID, &attributes)
+	default:
+		component, errno := newComponent(name)
+		if errno != 0 {
+			return node, parents, qid{}, errno
+		}
+		child, vs := directory.VirtualLookup(c.ctx, component, AttributesMaskForQID, &attributes)
+		if vs != virtual.StatusOK {
+			return node, parents, qid{}, toErrno(vs)
+		}
+		node = child
+		parents = append(parents, directory)
+	}
+	return node, parents, toQID(&attributes), 0
+}
+
+func (c *connection) handleLopen(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	flags := d.uint32()
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	f, errno := c.getFid(fid)
+	if errno != 0 {
+		return nil, errno
+	}
+	if f.opened {
+		return nil, errnoEBADF
+	}
+
+	var attributes virtual.Attributes
+	newF := *f
+	newF.opened = true
+	directory, leaf := f.node.GetPair()
+	if directory != nil {
+		// Directories don't need to be opened explicitly.
+		directory.VirtualGetAttributes(c.ctx, AttributesMaskForQID, &attributes)
+	} else {
+		shareAccess, errno := openFlagsToShareMask(flags)
+		if errno != 0 {
+			return nil, errno
+		}
+		options := virtual.OpenExistingOptions{
+			Truncate: flags&openFlagsTruncate != 0,
+		}
+		if vs := leaf.VirtualOpenSelf(c.ctx, shareAccess, &options, AttributesMaskForQID, &attributes); vs != virtual.StatusOK {
+			return nil, toErrno(vs)
+		}
+		newF.shareAccess = shareAccess
+	}
+	if errno := c.replaceFid(fid, f, &newF); errno != 0 {
+		// The fid was opened or clunked concurrently.
+		newF.close()
+		return nil, errno
+	}
+
+	e := newMessageEncoder(messageTypeTlopen+1, tag)
+	e.qid(toQID(&attributes))
+	e.uint32(0) // iounit.
+	return e, 0
+}
+
+func (c *connection) handleLcreate(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	name := d.string()
+	flags := d.uint32()
+	mode := d.uint32()
+	d.uint32() // gid.
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	f, directory, errno := c.getDirectoryFid(fid)
+	if errno != 0 {
+		return nil, errno
+	}
+	if f.opened {
+		return nil, errnoEBADF
+	}
+	component, errno := newComponent(name)
+	if errno != 0 {
+		return nil, errno
+	}
+	shareAccess, errno := openFlagsToShareMask(flags)
+	if errno != 0 {
+		return nil, errno
+	}
+
+	// Take O_EXCL and O_TRUNC flags into consideration.
+	var existingOptions *virtual.OpenExistingOptions
+	if flags&openFlagsExclusive == 0 {
+		existingOptions = &virtual.OpenExistingOptions{
+			Truncate: flags&openFlagsTruncate != 0,
+		}
+	}
+
+	var attributes virtual.Attributes
+	leaf, _, _, vs := directory.VirtualOpenChild(
+		c.ctx,
+		component,
+		shareAccess,
+		(&virtual.Attributes{}).SetPermissions(virtual.NewPermissionsFromMode(mode)),
+		existingOptions,
+		AttributesMaskForQID,
+		&attributes)
+	if vs != virtual.StatusOK {
+		return nil, toErrno(vs)
+	}
+
+	// The fid now refers to the newly opened file.
+	newF := &fidState{
+		node:        virtual.DirectoryChild{}.FromLeaf(leaf),
+		parents:     append(append([]virtual.Directory(nil), f.parents...), directory),
+		opened:      true,
+		shareAccess: shareAccess,
+	}
+	if errno := c.replaceFid(fid, f, newF); errno != 0 {
+		// The fid was opened or clunked concurrently.
+		newF.close()
+		return nil, errno
+	}
+
+	e := newMessageEncoder(messageTypeTlcreate+1, tag)
+	e.qid(toQID(&attributes))
+	e.uint32(0) // iounit.
+	return e, 0
+}
+
+func (c *connection) handleRead(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	offset := d.uint64()
+	count := d.uint32()
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	leaf, errno := c.getLeafFid(fid)
+	if errno != 0 {
+		return nil, errno
+	}
+	if maximumCount := c.messageSizeBytes - messageHeaderSizeBytes - 4; count > maximumCount {
+		count = maximumCount
+	}
+	buf := make([]byte, count)
+	n, _, vs := leaf.VirtualRead(buf, offset)
+	if vs != virtual.StatusOK {
+		return nil, toErrno(vs)
+	}
+
+	e := newMessageEncoder(messageTypeTread+1, tag)
+	e.uint32(uint32(n))
+	e.bytes(buf[:n])
+	return e, 0
+}
+
+func (c *connection) handleWrite(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	offset := d.uint64()
+	data := d.bytes(d.uint32())
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	leaf, errno := c.getLeafFid(fid)
+	if errno != 0 {
+		return nil, errno
+	}
+	n, vs := leaf.VirtualWrite(data, offset)
+	if vs != virtual.StatusOK {
+		return nil, toErrno(vs)
+	}
+
+	e := newMessageEncoder(messageTypeTwrite+1, tag)
+	e.uint32(uint32(n))
+	return e, 0
+}
+
+func (c *connection) handleClunk(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+	if errno := c.clunk(fid); errno != 0 {
+		return nil, errno
+	}
+	return newMessageEncoder(messageTypeTclunk+1, tag), 0
+}
+
+func (c *connection) handleRemove(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	// Tremove requires the name of the file within its parent
+	// directory, which is not tracked. The Linux kernel uses
+	// Tunlinkat instead. The fid is clunked regardless of whether
+	// the removal succeeds.
+	if errno := c.clunk(fid); errno != 0 {
+		return nil, errno
+	}
+	return nil, errnoEOPNOTSUPP
+}
+
+func (c *connection) handleStatfs(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+	if _, errno := c.getFid(fid); errno != 0 {
+		return nil, errno
+	}
+
+	e := newMessageEncoder(messageTypeTstatfs+1, tag)
+	e.uint32(statfsType)
+	e.uint32(4096) // bsize.
+	e.uint64(0)    // blocks.
+	e.uint64(0)    // bfree.
+	e.uint64(0)    // bavail.
+	e.uint64(0)    // files.
+	e.uint64(0)    // ffree.
+	e.uint64(0)    // fsid.
+	e.uint32(255)  // namelen.
+	return e, 0
+}
+
+func (c *connection) handleSymlink(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	name := d.string()
+	target := d.string()
+	d.uint32() // gid.
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	_, directory, errno := c.getDirectoryFid(fid)
+	if errno != 0 {
+		return nil, errno
+	}
+	component, errno := newComponent(name)
+	if errno != 0 {
+		return nil, errno
+	}
+	var attributes virtual.Attributes
+	if _, _, vs := directory.VirtualSymlink(c.ctx, []byte(target), component, AttributesMaskForQID, &attributes); vs != virtual.StatusOK {
+		return nil, toErrno(vs)
+	}
+
+	e := newMessageEncoder(messageTypeTsymlink+1, tag)
+	e.qid(toQID(&attributes))
+	return e, 0
+}
+
+func (c *connection) handleMknod(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	name := d.string()
+	mode := d.uint32()
+	d.uint32() // major.
+	d.uint32() // minor.
+	d.uint32() // gid.
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	_, directory, errno := c.getDirectoryFid(fid)
+	if errno != 0 {
+		return nil, errno
+	}
+	component, errno := newComponent(name)
+	if errno != 0 {
+		return nil, errno
+	}
+	var fileType filesystem.FileType
+	switch mode & modeTypeMask {
+	case modeTypeFIFO:
+		fileType = filesystem.FileTypeFIFO
+	case modeTypeSocket:
+		fileType = filesystem.FileTypeSocket
+	default:
+		return nil, errnoEPERM
+	}
+	var attributes virtual.Attributes
+	if _, _, vs := directory.VirtualMknod(c.ctx, component, fileType, AttributesMaskForQID, &attributes); vs != virtual.StatusOK {
+		return nil, toErrno(vs)
+	}
+
+	e := newMessageEncoder(messageTypeTmknod+1, tag)
+	e.qid(toQID(&attributes))
+	return e, 0
+}
+
+func (c *connection) handleReadlink(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	leaf, errno := c.getLeafFid(fid)
+	if errno != 0 {
+		return nil, errno
+	}
+	target, vs := leaf.VirtualReadlink(c.ctx)
+	if vs != virtual.StatusOK {
+		return nil, toErrno(vs)
+	}
+
+	e := newMessageEncoder(messageTypeTreadlink+1, tag)
+	e.string(string(target))
+	return e, 0
+}
+
+func (c *connection) handleGetattr(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	d.uint64() // request_mask.
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	f, errno := c.getFid(fid)
+	if errno != 0 {
+		return nil, errno
+	}
+	var attributes virtual.Attributes
+	f.node.GetNode().VirtualGetAttributes(c.ctx, AttributesMaskForGetattr, &attributes)
+
+	permissions, ok := attributes.GetPermissions()
+	if !ok {
+		panic("Attributes do not contain mandatory permissions attribute")
+	}
+	sizeBytes, ok := attributes.GetSizeBytes()
+	if !ok {
+		panic("Attributes do not contain mandatory size attribute")
+	}
+	var rdev uint64
+	if deviceNumber, ok := attributes.GetDeviceNumber(); ok {
+		rdev = uint64(deviceNumber.ToRaw())
+	}
+	// Nodes that do not track access and status change times
+	// report their modification time instead.
+	lastDataModificationTime, ok := attributes.GetLastDataModificationTime()
+	if !ok {
+		lastDataModificationTime = filesystem.DeterministicFileModificationTimestamp
+	}
+	lastDataAccessTime, ok := attributes.GetLastDataAccessTime()
+	if !ok {
+		lastDataAccessTime = lastDataModificationTime
+	}
+	lastStatusChangeTime, ok := attributes.GetLastStatusChangeTime()
+	if !ok {
+		lastStatusChangeTime = lastDataModificationTime
+	}
+
+	// Ownership is not reported, as the virtual file system is
+	// effectively single user. This causes clients to fall back
+	// to the ownership provided through mount options.
+	e := newMessageEncoder(messageTypeTgetattr+1, tag)
+	e.uint64(getattrMode | getattrNlink | getattrRdev | getattrAtime | getattrMtime | getattrCtime | getattrIno | getattrSize | getattrBlocks)
+	e.qid(toQID(&attributes))
+	e.uint32(toMode(attributes.GetFileType()) | permissions.ToMode())
+	e.uint32(0) // uid.
+	e.uint32(0) // gid.
+	e.uint64(uint64(attributes.GetLinkCount()))
+	e.uint64(rdev)
+	e.uint64(sizeBytes)
+	e.uint64(4096)                    // blksize.
+	e.uint64((sizeBytes + 511) / 512) // blocks.
+	for _, t := range []time.Time{lastDataAccessTime, lastDataModificationTime, lastStatusChangeTime} {
+		nanos := t.UnixNano()
+		e.uint64(uint64(nanos / 1e9))
+		e.uint64(uint64(nanos % 1e9))
+	}
+	e.uint64(0) // btime_sec.
+	e.uint64(0) // btime_nsec.
+	e.uint64(0) // gen.
+	e.uint64(0) // data_version.
+	return e, 0
+}
+
+func (c *connection) handleSetattr(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	valid := d.uint32()
+	mode := d.uint32()
+	d.uint32() // uid.
+	d.uint32() // gid.
+	sizeBytes := d.uint64()
+	atimeSec := d.uint64()
+	atimeNsec := d.uint64()
+	mtimeSec := d.uint64()
+	mtimeNsec := d.uint64()
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	f, errno := c.getFid(fid)
+	if errno != 0 {
+		return nil, errno
+	}
+
+	var attributesIn virtual.Attributes
+	if valid&(setattrUID|setattrGID) != 0 {
+		return nil, errnoEPERM
+	}
+	if valid&setattrMode != 0 {
+		attributesIn.SetPermissions(virtual.NewPermissionsFromMode(mode))
+	}
+	if valid&setattrSize != 0 {
+		attributesIn.SetSizeBytes(sizeBytes)
+	}
+	if valid&setattrAtime != 0 {
+		if valid&setattrAtimeSet != 0 {
+			attributesIn.SetLastDataAccessTime(time.Unix(int64(atimeSec), int64(atimeNsec)))
+		} else {
+			attributesIn.SetLastDataAccessTime(c.server.clock.Now())
+		}
+	}
+	if valid&setattrMtime != 0 {
+		if valid&setattrMtimeSet != 0 {
+			attributesIn.SetLastDataModificationTime(time.Unix(int64(mtimeSec), int64(mtimeNsec)))
+		} else {
+			attributesIn.SetLastDataModificationTime(c.server.clock.Now())
+		}
+	}
+
+	var attributesOut virtual.Attributes
+	if vs := f.node.GetNode().VirtualSetAttributes(c.ctx, &attributesIn, 0, &attributesOut); vs != virtual.StatusOK {
+		return nil, toErrno(vs)
+	}
+	return newMessageEncoder(messageTypeTsetattr+1, tag), 0
+}
+
+// readdirReporter is used by Treaddir to convert directory entries
+// reported by VirtualReadDir() to the 9P2000.L wire format.
+type readdirReporter struct {
+	e              *messageEncoder
+	remainingBytes int
+}
+
+func (r *readdirReporter) addEntry(q qid, offset uint64, fileType filesystem.FileType, name string) bool {
+	entrySizeBytes := qidSizeBytes + 8 + 1 + 2 + len(name)
+	if entrySizeBytes > r.remainingBytes {
+		return false
+	}
+	r.remainingBytes -= entrySizeBytes
+	r.e.qid(q)
+	r.e.uint64(offset)
+	// Directory entry types are equal to the file type bits of
+	// the mode, shifted right by 12 bits.
+	r.e.uint8(uint8(toMode(fileType) >> 12))
+	r.e.string(name)
+	return true
+}
+
+func (r *readdirReporter) ReportEntry(nextCookie uint64, name path.Component, child virtual.DirectoryChild, attributes *virtual.Attributes) bool {
+	return r.addEntry(toQID(attributes), dotDotEntriesCount+nextCookie, attributes.GetFileType(), name.String())
+}
+
+// The names of the "." and ".." entries that are injected at the start
+// of the results returned by Treaddir.
+var dotDotEntryNames = [...]string{".", ".."}
+
+const dotDotEntriesCount uint64 = uint64(len(dotDotEntryNames))
+
+func (c *connection) handleReaddir(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	offset := d.uint64()
+	count := d.uint32()
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	f, directory, errno := c.getDirectoryFid(fid)
+	if errno != 0 {
+		return nil, errno
+	}
+	if maximumCount := c.messageSizeBytes - messageHeaderSizeBytes - 4; count > maximumCount {
+		count = maximumCount
+	}
+
+	e := newMessageEncoder(messageTypeTreaddir+1, tag)
+	e.uint32(0) // count, filled in below.
+	reporter := readdirReporter{
+		e:              e,
+		remainingBytes: int(count),
+	}
+
+	// Inject "." and ".." entries at the start of the results.
+	dotDotDirectories := [dotDotEntriesCount]virtual.Directory{directory, directory}
+	if len(f.parents) > 0 {
+		dotDotDirectories[1] = f.parents[len(f.parents)-1]
+	}
+	vs := virtual.StatusOK
+	for ; offset < dotDotEntriesCount; offset++ {
+		var attributes virtual.Attributes
+		dotDotDirectories[offset].VirtualGetAttributes(c.ctx, AttributesMaskForQID, &attributes)
+		if !reporter.addEntry(toQID(&attributes), offset+1, filesystem.FileTypeDirectory, dotDotEntryNames[offset]) {
+			break
+		}
+	}
+	if offset >= dotDotEntriesCount {
+		vs = directory.VirtualReadDir(c.ctx, offset-dotDotEntriesCount, AttributesMaskForQID, &reporter)
+	}
+	if vs != virtual.StatusOK {
+		return nil, toErrno(vs)
+	}
+
+	binary.LittleEndian.PutUint32(e.buf[messageHeaderSizeBytes:], count-uint32(reporter.remainingBytes))
+	return e, 0
+}
+
+func (c *connection) handleFsync(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	// All data is written into the virtual file system
+	// immediately, meaning there is nothing to synchronize.
+	if _, errno := c.getFid(fid); errno != 0 {
+		return nil, errno
+	}
+	return newMessageEncoder(messageTypeTfsync+1, tag), 0
+}
+
+func (c *connection) handleLock(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	d.uint8()  // type.
+	d.uint32() // flags.
+	d.uint64() // start.
+	d.uint64() // length.
+	d.uint32() // proc_id.
+	d.string() // client_id.
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	// Locks are only acquired by the client, and are not
+	// propagated to the virtual file system. Report success, so
+	// that applications relying on locks continue to work within
+	// a single client.
+	if _, errno := c.getFid(fid); errno != 0 {
+		return nil, errno
+	}
+	e := newMessageEncoder(messageTypeTlock+1, tag)
+	e.uint8(lockStatusSuccess)
+	return e, 0
+}
+
+func (c *connection) handleGetlock(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	d.uint8() // type.
+	start := d.uint64()
+	length := d.uint64()
+	procID := d.uint32()
+	clientID := d.string()
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	// As locks are not propagated, there are never any
+	// conflicting locks.
+	if _, errno := c.getFid(fid); errno != 0 {
+		return nil, errno
+	}
+	e := newMessageEncoder(messageTypeTgetlock+1, tag)
+	e.uint8(lockTypeUnlock)
+	e.uint64(start)
+	e.uint64(length)
+	e.uint32(procID)
+	e.string(clientID)
+	return e, 0
+}
+
+func (c *connection) handleLink(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	directoryFid := d.uint32()
+	fid := d.uint32()
+	name := d.string()
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	_, directory, errno := c.getDirectoryFid(directoryFid)
+	if errno != 0 {
+		return nil, errno
+	}
+	leaf, errno := c.getLeafFid(fid)
+	if errno != 0 {
+		return nil, errno
+	}
+	component, errno := newComponent(name)
+	if errno != 0 {
+		return nil, errno
+	}
+	if _, vs := directory.VirtualLink(c.ctx, component, leaf, 0, &virtual.Attributes{}); vs != virtual.StatusOK {
+		return nil, toErrno(vs)
+	}
+	return newMessageEncoder(messageTypeTlink+1, tag), 0
+}
+
+func (c *connection) handleMkdir(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	name := d.string()
+	d.uint32() // mode.
+	d.uint32() // gid.
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	_, directory, errno := c.getDirectoryFid(fid)
+	if errno != 0 {
+		return nil, errno
+	}
+	component, errno := newComponent(name)
+	if errno != 0 {
+		return nil, errno
+	}
+	var attributes virtual.Attributes
+	if _, _, vs := directory.VirtualMkdir(component, AttributesMaskForQID, &attributes); vs != virtual.StatusOK {
+		return nil, toErrno(vs)
+	}
+
+	e := newMessageEncoder(messageTypeTmkdir+1, tag)
+	e.qid(toQID(&attributes))
+	return e, 0
+}
+
+func (c *connection) handleRenameat(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	oldDirectoryFid := d.uint32()
+	oldName := d.string()
+	newDirectoryFid := d.uint32()
+	newName := d.string()
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	_, oldDirectory, errno := c.getDirectoryFid(oldDirectoryFid)
+	if errno != 0 {
+		return nil, errno
+	}
+	oldNameComponent, errno := newComponent(oldName)
+	if errno != 0 {
+		return nil, errno
+	}
+	_, newDirectory, errno := c.getDirectoryFid(newDirectoryFid)
+	if errno != 0 {
+		return nil, errno
+	}
+	newNameComponent, errno := newComponent(newName)
+	if errno != 0 {
+		return nil, errno
+	}
+	if _, _, vs := oldDirectory.VirtualRename(oldNameComponent, newDirectory, newNameComponent); vs != virtual.StatusOK {
+		return nil, toErrno(vs)
+	}
+	return newMessageEncoder(messageTypeTrenameat+1, tag), 0
+}
+
+func (c *connection) handleUnlinkat(tag uint16, d *messageDecoder) (*messageEncoder, uint32) {
+	fid := d.uint32()
+	name := d.string()
+	flags := d.uint32()
+	if !d.ok {
+		return nil, errnoEPROTO
+	}
+
+	_, directory, errno := c.getDirectoryFid(fid)
+	if errno != 0 {
+		return nil, errno
+	}
+	component, errno := newComponent(name)
+	if errno != 0 {
+		return nil, errno
+	}
+	removeDirectory := flags&unlinkatRemoveDirectory != 0
+	if _, vs := directory.VirtualRemove(component, removeDirectory, !removeDirectory); vs != virtual.StatusOK {
+		return nil, toErrno(vs)
+	}
+	return newMessageEncoder(messageTypeTunlinkat+1, tag), 0
+}
@@ -0,0 +1,387 @@
+package ninep_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual/ninep"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// newMessage encodes a 9P message consisting of the provided fields.
+func newMessage(messageType uint8, tag uint16, fields ...any) []byte {
+	buf := []byte{0, 0, 0, 0, messageType}
+	buf = binary.LittleEndian.AppendUint16(buf, tag)
+	for _, field := range fields {
+		switch v := field.(type) {
+		case uint8:
+			buf = append(buf, v)
+		case uint16:
+			buf = binary.LittleEndian.AppendUint16(buf, v)
+		case uint32:
+			buf = binary.LittleEndian.AppendUint32(buf, v)
+		case uint64:
+			buf = binary.LittleEndian.AppendUint64(buf, v)
+		case string:
+			buf = binary.LittleEndian.AppendUint16(buf, uint16(len(v)))
+			buf = append(buf, v...)
+		case []byte:
+			buf = append(buf, v...)
+		default:
+			panic("Unsupported field type")
+		}
+	}
+	binary.LittleEndian.PutUint32(buf, uint32(len(buf)))
+	return buf
+}
+
+// runServer sends requests to the server one by one. As requests are
+// processed concurrently, it waits for each response before sending
+// the next request, similar to how the client would wait for a fid to
+// be established before using it.
+func runServer(ctx context.Context, t *testing.T, rootDirectory virtual.Directory, clock *mock.MockClock, requests ...[]byte) []byte {
+	server := ninep.NewServer(rootDirectory, 1<<16, 10, clock)
+	requestReader, requestWriter := io.Pipe()
+	responseReader, responseWriter := io.Pipe()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.HandleConnection(ctx, requestReader, responseWriter)
+		responseWriter.Close()
+	}()
+
+	var responses []byte
+	for _, request := range requests {
+		_, err := requestWriter.Write(request)
+		require.NoError(t, err)
+
+		responses = append(responses, readResponse(t, responseReader)...)
+	}
+	requestWriter.Close()
+	require.NoError(t, <-errCh)
+	return responses
+}
+
+// readResponse reads a single 9P message returned by the server.
+func readResponse(t *testing.T, r io.Reader) []byte {
+	var size [4]byte
+	_, err := io.ReadFull(r, size[:])
+	require.NoError(t, err)
+	response := make([]byte, binary.LittleEndian.Uint32(size[:]))
+	copy(response, size[:])
+	_, err = io.ReadFull(r, response[4:])
+	require.NoError(t, err)
+	return response
+}
+
+func TestServer(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
+	clock := mock.NewMockClock(ctrl)
+
+	tversion := newMessage(100, 0xffff, uint32(8192), "9P2000.L")
+	rversion := newMessage(101, 0xffff, uint32(8192), "9P2000.L")
+	tattach := newMessage(104, 1, uint32(1), uint32(0xffffffff), "root", "", uint32(0))
+	rattach := newMessage(105, 1, uint8(0x80), uint32(0), uint64(1))
+	expectRootAttributes := func() {
+		rootDirectory.EXPECT().VirtualGetAttributes(ctx, ninep.AttributesMaskForQID, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+				attributes.SetFileType(filesystem.FileTypeDirectory)
+				attributes.SetInodeNumber(1)
+			})
+	}
+
+	t.Run("UnknownVersion", func(t *testing.T) {
+		require.Equal(
+			t,
+			newMessage(101, 0xffff, uint32(8192), "unknown"),
+			runServer(ctx, t, rootDirectory, clock, newMessage(100, 0xffff, uint32(8192), "9P2000")))
+	})
+
+	t.Run("UnknownFid", func(t *testing.T) {
+		require.Equal(
+			t,
+			bytes.Join([][]byte{
+				rversion,
+				newMessage(7, 1, uint32(9)),
+			}, nil),
+			runServer(ctx, t, rootDirectory, clock,
+				tversion,
+				newMessage(120, 1, uint32(42))))
+	})
+
+	t.Run("WriteTruncated", func(t *testing.T) {
+		// The length of the data provided to Twrite exceeds the
+		// size of the message. This should cause the request to
+		// fail with EPROTO, without allocating memory based on
+		// the length provided by the client.
+		require.Equal(
+			t,
+			bytes.Join([][]byte{
+				rversion,
+				newMessage(7, 1, uint32(71)),
+			}, nil),
+			runServer(ctx, t, rootDirectory, clock,
+				tversion,
+				newMessage(118, 1, uint32(1), uint64(0), uint32(0xffffffff), []byte("Hello"))))
+	})
+
+	t.Run("WalkNonexistent", func(t *testing.T) {
+		expectRootAttributes()
+		rootDirectory.EXPECT().VirtualLookup(ctx, path.MustNewComponent("nonexistent"), ninep.AttributesMaskForQID, gomock.Any()).
+			Return(virtual.DirectoryChild{}, virtual.StatusErrNoEnt)
+
+		require.Equal(
+			t,
+			bytes.Join([][]byte{
+				rversion,
+				rattach,
+				newMessage(7, 2, uint32(2)),
+			}, nil),
+			runServer(ctx, t, rootDirectory, clock,
+				tversion,
+				tattach,
+				newMessage(110, 2, uint32(1), uint32(2), uint16(2), "nonexistent", "file")))
+	})
+
+	t.Run("WalkPartial", func(t *testing.T) {
+		// If only the first path component can be resolved,
+		// Rwalk should return a single qid, and the new fid
+		// should not be created.
+		expectRootAttributes()
+		leaf := mock.NewMockVirtualLeaf(ctrl)
+		rootDirectory.EXPECT().VirtualLookup(ctx, path.MustNewComponent("file"), ninep.AttributesMaskForQID, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, name path.Component, requested virtual.AttributesMask, attributes *virtual.Attributes) (virtual.DirectoryChild, virtual.Status) {
+				attributes.SetFileType(filesystem.FileTypeRegularFile)
+				attributes.SetInodeNumber(2)
+				return virtual.DirectoryChild{}.FromLeaf(leaf), virtual.StatusOK
+			})
+
+		require.Equal(
+			t,
+			bytes.Join([][]byte{
+				rversion,
+				rattach,
+				newMessage(111, 2, uint16(1), uint8(0), uint32(0), uint64(2)),
+				newMessage(7, 3, uint32(9)),
+			}, nil),
+			runServer(ctx, t, rootDirectory, clock,
+				tversion,
+				tattach,
+				newMessage(110, 2, uint32(1), uint32(2), uint16(2), "file", "child"),
+				newMessage(120, 3, uint32(2))))
+	})
+
+	t.Run("OpenAndRead", func(t *testing.T) {
+		expectRootAttributes()
+		leaf := mock.NewMockVirtualLeaf(ctrl)
+		rootDirectory.EXPECT().VirtualLookup(ctx, path.MustNewComponent("hello.txt"), ninep.AttributesMaskForQID, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, name path.Component, requested virtual.AttributesMask, attributes *virtual.Attributes) (virtual.DirectoryChild, virtual.Status) {
+				attributes.SetFileType(filesystem.FileTypeRegularFile)
+				attributes.SetInodeNumber(2)
+				return virtual.DirectoryChild{}.FromLeaf(leaf), virtual.StatusOK
+			})
+		leaf.EXPECT().VirtualOpenSelf(ctx, virtual.ShareMaskRead, &virtual.OpenExistingOptions{}, ninep.AttributesMaskForQID, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, shareAccess virtual.ShareMask, options *virtual.OpenExistingOptions, requested virtual.AttributesMask, attributes *virtual.Attributes) virtual.Status {
+				attributes.SetFileType(filesystem.FileTypeRegularFile)
+				attributes.SetInodeNumber(2)
+				return virtual.StatusOK
+			})
+		leaf.EXPECT().VirtualRead(gomock.Len(100), uint64(0)).
+			DoAndReturn(func(buf []byte, offset uint64) (int, bool, virtual.Status) {
+				return copy(buf, "Hello"), true, virtual.StatusOK
+			})
+		leaf.EXPECT().VirtualClose(virtual.ShareMaskRead)
+
+		require.Equal(
+			t,
+			bytes.Join([][]byte{
+				rversion,
+				rattach,
+				newMessage(111, 2, uint16(1), uint8(0), uint32(0), uint64(2)),
+				newMessage(13, 3, uint8(0), uint32(0), uint64(2), uint32(0)),
+				newMessage(117, 4, uint32(5), []byte("Hello")),
+				newMessage(121, 5),
+			}, nil),
+			runServer(ctx, t, rootDirectory, clock,
+				tversion,
+				tattach,
+				newMessage(110, 2, uint32(1), uint32(2), uint16(1), "hello.txt"),
+				newMessage(12, 3, uint32(2), uint32(0)),
+				newMessage(116, 4, uint32(2), uint64(0), uint32(100)),
+				newMessage(120, 5, uint32(2))))
+	})
+
+	t.Run("CloseOnDisconnect", func(t *testing.T) {
+		// Files that are still opened when the connection is
+		// closed should be closed implicitly.
+		expectRootAttributes()
+		leaf := mock.NewMockVirtualLeaf(ctrl)
+		rootDirectory.EXPECT().VirtualOpenChild(
+			ctx,
+			path.MustNewComponent("new.txt"),
+			virtual.ShareMaskRead|virtual.ShareMaskWrite,
+			(&virtual.Attributes{}).SetPermissions(virtual.PermissionsRead|virtual.PermissionsWrite),
+			nil,
+			ninep.AttributesMaskForQID,
+			gomock.Any(),
+		).DoAndReturn(func(ctx context.Context, name path.Component, shareAccess virtual.ShareMask, createAttributes *virtual.Attributes, existingOptions *virtual.OpenExistingOptions, requested virtual.AttributesMask, attributes *virtual.Attributes) (virtual.Leaf, virtual.AttributesMask, virtual.ChangeInfo, virtual.Status) {
+			attributes.SetFileType(filesystem.FileTypeRegularFile)
+			attributes.SetInodeNumber(3)
+			return leaf, 0, virtual.ChangeInfo{}, virtual.StatusOK
+		})
+		leaf.EXPECT().VirtualWrite([]byte("Hello"), uint64(0)).Return(5, virtual.StatusOK)
+		leaf.EXPECT().VirtualClose(virtual.ShareMaskRead | virtual.ShareMaskWrite)
+
+		require.Equal(
+			t,
+			bytes.Join([][]byte{
+				rversion,
+				rattach,
+				newMessage(111, 2, uint16(0)),
+				newMessage(15, 3, uint8(0), uint32(0), uint64(3), uint32(0)),
+				newMessage(119, 4, uint32(5)),
+			}, nil),
+			runServer(ctx, t, rootDirectory, clock,
+				tversion,
+				tattach,
+				newMessage(110, 2, uint32(1), uint32(2), uint16(0)),
+				newMessage(14, 3, uint32(2), "new.txt", uint32(0x2|0x80), uint32(0o644), uint32(0)),
+				newMessage(118, 4, uint32(2), uint64(0), uint32(5), []byte("Hello"))))
+	})
+
+	t.Run("Readdir", func(t *testing.T) {
+		// The "." and ".." entries should be injected at the
+		// start of the results. For the root directory, both
+		// refer to the root directory itself.
+		expectRootAttributes()
+		expectRootAttributes()
+		expectRootAttributes()
+		leaf := mock.NewMockVirtualLeaf(ctrl)
+		rootDirectory.EXPECT().VirtualReadDir(ctx, uint64(0), ninep.AttributesMaskForQID, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, firstCookie uint64, requested virtual.AttributesMask, reporter virtual.DirectoryEntryReporter) virtual.Status {
+				require.True(t, reporter.ReportEntry(
+					1,
+					path.MustNewComponent("file"),
+					virtual.DirectoryChild{}.FromLeaf(leaf),
+					(&virtual.Attributes{}).
+						SetFileType(filesystem.FileTypeRegularFile).
+						SetInodeNumber(2)))
+				return virtual.StatusOK
+			})
+
+		require.Equal(
+			t,
+			bytes.Join([][]byte{
+				rversion,
+				rattach,
+				newMessage(
+					41, 2, uint32(3*(13+8+1+2)+1+2+4),
+					uint8(0x80), uint32(0), uint64(1), uint64(1), uint8(4), ".",
+					uint8(0x80), uint32(0), uint64(1), uint64(2), uint8(4), "..",
+					uint8(0), uint32(0), uint64(2), uint64(3), uint8(8), "file"),
+			}, nil),
+			runServer(ctx, t, rootDirectory, clock,
+				tversion,
+				tattach,
+				newMessage(40, 2, uint32(1), uint64(0), uint32(4096))))
+	})
+
+	t.Run("UnlinkatDirectory", func(t *testing.T) {
+		expectRootAttributes()
+		rootDirectory.EXPECT().VirtualRemove(path.MustNewComponent("dir"), true, false).
+			Return(virtual.ChangeInfo{}, virtual.StatusErrNotEmpty)
+
+		require.Equal(
+			t,
+			bytes.Join([][]byte{
+				rversion,
+				rattach,
+				newMessage(7, 2, uint32(39)),
+			}, nil),
+			runServer(ctx, t, rootDirectory, clock,
+				tversion,
+				tattach,
+				newMessage(76, 2, uint32(1), "dir", uint32(0x200))))
+	})
+
+	t.Run("ConcurrentRequests", func(t *testing.T) {
+		// A read that blocks should not prevent other requests
+		// from being processed. Tflush should only complete
+		// after the response of the request it refers to has
+		// been written.
+		expectRootAttributes()
+		leaf := mock.NewMockVirtualLeaf(ctrl)
+		rootDirectory.EXPECT().VirtualLookup(ctx, path.MustNewComponent("slow.txt"), ninep.AttributesMaskForQID, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, name path.Component, requested virtual.AttributesMask, attributes *virtual.Attributes) (virtual.DirectoryChild, virtual.Status) {
+				attributes.SetFileType(filesystem.FileTypeRegularFile)
+				attributes.SetInodeNumber(2)
+				return virtual.DirectoryChild{}.FromLeaf(leaf), virtual.StatusOK
+			})
+		leaf.EXPECT().VirtualOpenSelf(ctx, virtual.ShareMaskRead, &virtual.OpenExistingOptions{}, ninep.AttributesMaskForQID, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, shareAccess virtual.ShareMask, options *virtual.OpenExistingOptions, requested virtual.AttributesMask, attributes *virtual.Attributes) virtual.Status {
+				attributes.SetFileType(filesystem.FileTypeRegularFile)
+				attributes.SetInodeNumber(2)
+				return virtual.StatusOK
+			})
+		readStarted := make(chan struct{})
+		readUnblock := make(chan struct{})
+		leaf.EXPECT().VirtualRead(gomock.Len(100), uint64(0)).
+			DoAndReturn(func(buf []byte, offset uint64) (int, bool, virtual.Status) {
+				close(readStarted)
+				<-readUnblock
+				return copy(buf, "Hello"), true, virtual.StatusOK
+			})
+		leaf.EXPECT().VirtualClose(virtual.ShareMaskRead)
+
+		server := ninep.NewServer(rootDirectory, 1<<16, 10, clock)
+		requestReader, requestWriter := io.Pipe()
+		responseReader, responseWriter := io.Pipe()
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- server.HandleConnection(ctx, requestReader, responseWriter)
+			responseWriter.Close()
+		}()
+
+		for _, exchange := range [][2][]byte{
+			{tversion, rversion},
+			{tattach, rattach},
+			{
+				newMessage(110, 2, uint32(1), uint32(2), uint16(1), "slow.txt"),
+				newMessage(111, 2, uint16(1), uint8(0), uint32(0), uint64(2)),
+			},
+			{
+				newMessage(12, 3, uint32(2), uint32(0)),
+				newMessage(13, 3, uint8(0), uint32(0), uint64(2), uint32(0)),
+			},
+		} {
+			_, err := requestWriter.Write(exchange[0])
+			require.NoError(t, err)
+			require.Equal(t, exchange[1], readResponse(t, responseReader))
+		}
+
+		_, err := requestWriter.Write(newMessage(116, 4, uint32(2), uint64(0), uint32(100)))
+		require.NoError(t, err)
+		<-readStarted
+		_, err = requestWriter.Write(newMessage(108, 5, uint16(4)))
+		require.NoError(t, err)
+		_, err = requestWriter.Write(newMessage(120, 6, uint32(1)))
+		require.NoError(t, err)
+		require.Equal(t, newMessage(121, 6), readResponse(t, responseReader))
+
+		close(readUnblock)
+		require.Equal(t, newMessage(117, 4, uint32(5), []byte("Hello")), readResponse(t, responseReader))
+		require.Equal(t, newMessage(109, 5), readResponse(t, responseReader))
+
+		requestWriter.Close()
+		require.NoError(t, <-errCh)
+	})
+}
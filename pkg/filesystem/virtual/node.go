@@ -9,9 +9,18 @@ import (
 
 // Node is the intersection between Directory and Leaf. These are the
 // operations that can be applied to both kinds of objects.
+//
+// VirtualGetXAttr(), VirtualListXAttr(), VirtualRemoveXAttr() and
+// VirtualSetXAttr() provide access to extended attributes. Nodes that
+// are incapable of storing extended attributes report that none are
+// present, and fail attempts to set them.
 type Node interface {
 	VirtualGetAttributes(ctx context.Context, requested AttributesMask, attributes *Attributes)
 	VirtualSetAttributes(ctx context.Context, in *Attributes, requested AttributesMask, attributes *Attributes) Status
+	VirtualGetXAttr(ctx context.Context, name string) ([]byte, Status)
+	VirtualListXAttr(ctx context.Context) ([]string, Status)
+	VirtualRemoveXAttr(ctx context.Context, name string) Status
+	VirtualSetXAttr(ctx context.Context, name string, value []byte, mode XAttrSetMode) Status
 }
 
 // GetFileInfo extracts the attributes of a node and returns it in the
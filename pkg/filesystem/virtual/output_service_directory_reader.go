@@ -0,0 +1,133 @@
+package virtual
+
+import (
+	"context"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteoutputservice"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultOutputServiceMaximumEntriesPerResponse is the number of
+// entries that ReadOutputServiceDirectory() returns per response if
+// the client did not specify a limit.
+const defaultOutputServiceMaximumEntriesPerResponse = 1000
+
+type outputServiceDirectoryEntry struct {
+	name  path.Component
+	child DirectoryChild
+	// Only set for directories, as the modification time is
+	// obtained while VirtualReadDir() holds the directory lock.
+	directoryStatus *remoteoutputservice.FileStatus_Directory
+}
+
+// outputServiceDirectoryEntryReporter is used by
+// ReadOutputServiceDirectory() to gather a bounded number of entries
+// from a directory using VirtualReadDir().
+type outputServiceDirectoryEntryReporter struct {
+	maximumEntries int
+	entries        []outputServiceDirectoryEntry
+	nextCookie     uint64
+}
+
+func (r *outputServiceDirectoryEntryReporter) ReportEntry(nextCookie uint64, name path.Component, child DirectoryChild, attributes *Attributes) bool {
+	if len(r.entries) >= r.maximumEntries {
+		return false
+	}
+	entry := outputServiceDirectoryEntry{
+		name:  name,
+		child: child,
+	}
+	if directory, _ := child.GetPair(); directory != nil {
+		entry.directoryStatus = &remoteoutputservice.FileStatus_Directory{}
+		if lastDataModificationTime, ok := attributes.GetLastDataModificationTime(); ok {
+			entry.directoryStatus.LastModifiedTime = timestamppb.New(lastDataModificationTime)
+		}
+	}
+	r.entries = append(r.entries, entry)
+	r.nextCookie = nextCookie
+	return true
+}
+
+// ReadOutputServiceDirectory obtains the status of all children of a
+// directory, and returns them in the form of one or more
+// ReadDirectoryResponse messages that are used by the Remote Output
+// Service protocol.
+//
+// The directory is read in pages of at most maximum_entries_per_response
+// entries by calling VirtualReadDir() repeatedly. Each page is sent to
+// the provided callback before the next one is read. This means that
+// memory usage is bounded, regardless of the size of the directory,
+// and that the client can process entries while the digests of
+// successive files are still being computed. Digests of regular files
+// are only computed if the request has include_file_digest set.
+func ReadOutputServiceDirectory(ctx context.Context, directory Directory, request *remoteoutputservice.ReadDirectoryRequest, digestFunction digest.Function, sendResponse func(*remoteoutputservice.ReadDirectoryResponse) error) error {
+	var fileDigestFunction *digest.Function
+	if request.IncludeFileDigest {
+		fileDigestFunction = &digestFunction
+	}
+	maximumEntries := int(request.MaximumEntriesPerResponse)
+	if maximumEntries <= 0 {
+		maximumEntries = defaultOutputServiceMaximumEntriesPerResponse
+	}
+
+	cookie := uint64(0)
+	for {
+		reporter := outputServiceDirectoryEntryReporter{
+			maximumEntries: maximumEntries,
+		}
+		if s := directory.VirtualReadDir(ctx, cookie, AttributesMaskLastDataModificationTime, &reporter); s != StatusOK {
+			return status.Errorf(codes.Internal, "Failed to read directory at cookie %d: status %d", cookie, s)
+		}
+		if len(reporter.entries) == 0 {
+			return nil
+		}
+		cookie = reporter.nextCookie
+
+		// Obtain the status of files after VirtualReadDir() has
+		// returned, as computing digests may take a long time
+		// and must not be done while holding directory locks.
+		response := &remoteoutputservice.ReadDirectoryResponse{
+			Entries: make([]*remoteoutputservice.ReadDirectoryResponse_Entry, 0, len(reporter.entries)),
+		}
+		for _, entry := range reporter.entries {
+			var fileStatus *remoteoutputservice.FileStatus
+			if entry.directoryStatus != nil {
+				fileStatus = &remoteoutputservice.FileStatus{
+					FileType: &remoteoutputservice.FileStatus_Directory_{
+						Directory: entry.directoryStatus,
+					},
+				}
+			} else {
+				_, leaf := entry.child.GetPair()
+				nativeLeaf, ok := leaf.(NativeLeaf)
+				if !ok {
+					return status.Errorf(codes.Internal, "File %#v does not support the Remote Output Service", entry.name.String())
+				}
+				var err error
+				fileStatus, err = nativeLeaf.GetOutputServiceFileStatus(fileDigestFunction)
+				if err != nil {
+					if status.Code(err) == codes.NotFound {
+						// File was removed concurrently.
+						continue
+					}
+					return util.StatusWrapf(err, "Failed to obtain status of %#v", entry.name.String())
+				}
+			}
+			response.Entries = append(response.Entries, &remoteoutputservice.ReadDirectoryResponse_Entry{
+				Name:       entry.name.String(),
+				FileStatus: fileStatus,
+			})
+		}
+		if len(response.Entries) > 0 {
+			if err := sendResponse(response); err != nil {
+				return err
+			}
+		}
+	}
+}
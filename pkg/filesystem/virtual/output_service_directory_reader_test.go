@@ -0,0 +1,178 @@
+package virtual_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteoutputservice"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestReadOutputServiceDirectory(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	directory := mock.NewMockVirtualDirectory(ctrl)
+	digestFunction := digest.MustNewFunction("", remoteexecution.DigestFunction_SHA256)
+
+	t.Run("ReadDirFailure", func(t *testing.T) {
+		directory.EXPECT().VirtualReadDir(ctx, uint64(0), virtual.AttributesMaskLastDataModificationTime, gomock.Any()).
+			Return(virtual.StatusErrIO)
+
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.Internal, "Failed to read directory at cookie 0: status 5"),
+			virtual.ReadOutputServiceDirectory(ctx, directory, &remoteoutputservice.ReadDirectoryRequest{}, digestFunction, func(response *remoteoutputservice.ReadDirectoryResponse) error {
+				t.Fatal("No responses should be sent")
+				return nil
+			}))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		// Create a directory that contains two directories and
+		// three files, one of which gets removed concurrently.
+		childDirectory1 := mock.NewMockVirtualDirectory(ctrl)
+		childDirectory2 := mock.NewMockVirtualDirectory(ctrl)
+		file1 := mock.NewMockNativeLeaf(ctrl)
+		file2 := mock.NewMockNativeLeaf(ctrl)
+		file3 := mock.NewMockNativeLeaf(ctrl)
+		type testEntry struct {
+			name  string
+			child virtual.DirectoryChild
+			mtime time.Time
+		}
+		entries := []testEntry{
+			{name: "a", child: virtual.DirectoryChild{}.FromLeaf(file1)},
+			{name: "b", child: virtual.DirectoryChild{}.FromDirectory(childDirectory1), mtime: time.Unix(1000, 0)},
+			{name: "c", child: virtual.DirectoryChild{}.FromLeaf(file2)},
+			{name: "d", child: virtual.DirectoryChild{}.FromDirectory(childDirectory2), mtime: time.Unix(2000, 0)},
+			{name: "e", child: virtual.DirectoryChild{}.FromLeaf(file3)},
+		}
+
+		// The directory should be read in pages, each call
+		// resuming at the cookie of the last reported entry.
+		directory.EXPECT().VirtualReadDir(ctx, gomock.Any(), virtual.AttributesMaskLastDataModificationTime, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, firstCookie uint64, requested virtual.AttributesMask, reporter virtual.DirectoryEntryReporter) virtual.Status {
+				for i := firstCookie; i < uint64(len(entries)); i++ {
+					var attributes virtual.Attributes
+					if !entries[i].mtime.IsZero() {
+						attributes.SetLastDataModificationTime(entries[i].mtime)
+					}
+					if !reporter.ReportEntry(i+1, path.MustNewComponent(entries[i].name), entries[i].child, &attributes) {
+						break
+					}
+				}
+				return virtual.StatusOK
+			}).
+			Times(4)
+
+		fileStatus1 := &remoteoutputservice.FileStatus{
+			FileType: &remoteoutputservice.FileStatus_File_{
+				File: &remoteoutputservice.FileStatus_File{
+					Digest: &remoteexecution.Digest{
+						Hash:      "185f8db32271fe25f561a6fc938b2e264306ec304eda518007d1764826381969",
+						SizeBytes: 5,
+					},
+				},
+			},
+		}
+		file1.EXPECT().GetOutputServiceFileStatus(&digestFunction).Return(fileStatus1, nil)
+		file2.EXPECT().GetOutputServiceFileStatus(&digestFunction).
+			Return(nil, status.Error(codes.NotFound, "File was unlinked before digest computation could start"))
+		fileStatus3 := &remoteoutputservice.FileStatus{
+			FileType: &remoteoutputservice.FileStatus_Symlink_{
+				Symlink: &remoteoutputservice.FileStatus_Symlink{
+					Target: "a",
+				},
+			},
+		}
+		file3.EXPECT().GetOutputServiceFileStatus(&digestFunction).Return(fileStatus3, nil)
+
+		// Entries should be returned in directory order, spread
+		// out over multiple responses.
+		var responses []*remoteoutputservice.ReadDirectoryResponse
+		require.NoError(t, virtual.ReadOutputServiceDirectory(ctx, directory, &remoteoutputservice.ReadDirectoryRequest{
+			IncludeFileDigest:         true,
+			MaximumEntriesPerResponse: 2,
+		}, digestFunction, func(response *remoteoutputservice.ReadDirectoryResponse) error {
+			responses = append(responses, response)
+			return nil
+		}))
+		require.Len(t, responses, 3)
+		testutil.RequireEqualProto(t, &remoteoutputservice.ReadDirectoryResponse{
+			Entries: []*remoteoutputservice.ReadDirectoryResponse_Entry{
+				{Name: "a", FileStatus: fileStatus1},
+				{
+					Name: "b",
+					FileStatus: &remoteoutputservice.FileStatus{
+						FileType: &remoteoutputservice.FileStatus_Directory_{
+							Directory: &remoteoutputservice.FileStatus_Directory{
+								LastModifiedTime: &timestamppb.Timestamp{Seconds: 1000},
+							},
+						},
+					},
+				},
+			},
+		}, responses[0])
+		testutil.RequireEqualProto(t, &remoteoutputservice.ReadDirectoryResponse{
+			Entries: []*remoteoutputservice.ReadDirectoryResponse_Entry{
+				{
+					Name: "d",
+					FileStatus: &remoteoutputservice.FileStatus{
+						FileType: &remoteoutputservice.FileStatus_Directory_{
+							Directory: &remoteoutputservice.FileStatus_Directory{
+								LastModifiedTime: &timestamppb.Timestamp{Seconds: 2000},
+							},
+						},
+					},
+				},
+			},
+		}, responses[1])
+		testutil.RequireEqualProto(t, &remoteoutputservice.ReadDirectoryResponse{
+			Entries: []*remoteoutputservice.ReadDirectoryResponse_Entry{
+				{Name: "e", FileStatus: fileStatus3},
+			},
+		}, responses[2])
+	})
+
+	t.Run("WithoutFileDigests", func(t *testing.T) {
+		// Digests of files should only be computed if requested.
+		file := mock.NewMockNativeLeaf(ctrl)
+		directory.EXPECT().VirtualReadDir(ctx, uint64(0), virtual.AttributesMaskLastDataModificationTime, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, firstCookie uint64, requested virtual.AttributesMask, reporter virtual.DirectoryEntryReporter) virtual.Status {
+				require.True(t, reporter.ReportEntry(1, path.MustNewComponent("file"), virtual.DirectoryChild{}.FromLeaf(file), &virtual.Attributes{}))
+				return virtual.StatusOK
+			})
+		directory.EXPECT().VirtualReadDir(ctx, uint64(1), virtual.AttributesMaskLastDataModificationTime, gomock.Any()).
+			Return(virtual.StatusOK)
+		fileStatus := &remoteoutputservice.FileStatus{
+			FileType: &remoteoutputservice.FileStatus_File_{
+				File: &remoteoutputservice.FileStatus_File{},
+			},
+		}
+		file.EXPECT().GetOutputServiceFileStatus(nil).Return(fileStatus, nil)
+
+		var responses []*remoteoutputservice.ReadDirectoryResponse
+		require.NoError(t, virtual.ReadOutputServiceDirectory(ctx, directory, &remoteoutputservice.ReadDirectoryRequest{}, digestFunction, func(response *remoteoutputservice.ReadDirectoryResponse) error {
+			responses = append(responses, response)
+			return nil
+		}))
+		require.Len(t, responses, 1)
+		testutil.RequireEqualProto(t, &remoteoutputservice.ReadDirectoryResponse{
+			Entries: []*remoteoutputservice.ReadDirectoryResponse_Entry{
+				{Name: "file", FileStatus: fileStatus},
+			},
+		}, responses[0])
+	})
+}
@@ -0,0 +1,183 @@
+package virtual
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"math/bits"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+)
+
+// parallelDigestSegmentSizeBytes is the size of the segments of a file
+// whose hashes are computed concurrently by computeDigestParallel().
+// It needs to be a power of two that is at least 1024 bytes, so that
+// every segment corresponds to a subtree of the SHA256TREE hash.
+const parallelDigestSegmentSizeBytes = pipelinedCopyChunkSizeBytes
+
+// supportsParallelDigest returns whether computeDigestParallel() is
+// capable of computing digests using a given digest function. This is
+// only the case for digest functions that are based on a tree, as
+// the hashes of flat digest functions can only be computed serially.
+func supportsParallelDigest(digestFunction digest.Function) bool {
+	return digestFunction.GetEnumValue() == remoteexecution.DigestFunction_SHA256TREE
+}
+
+// computeDigestParallel computes the digest of the first sizeBytes
+// bytes of an io.ReaderAt, using a digest function for which
+// supportsParallelDigest() returns true.
+//
+// The data is partitioned into segments that are read and hashed by
+// one goroutine per CPU. As SHA256TREE hashes the leading 2^k bytes of
+// an object independently of its trailing bytes, the hashes of these
+// segments can be combined into the hash of the full object.
+func computeDigestParallel(digestFunction digest.Function, r io.ReaderAt, sizeBytes int64) (digest.Digest, error) {
+	segmentsCount := int((sizeBytes + parallelDigestSegmentSizeBytes - 1) / parallelDigestSegmentSizeBytes)
+	segmentHashes := make([][]byte, segmentsCount)
+	workersCount := runtime.GOMAXPROCS(0)
+	if workersCount > segmentsCount {
+		workersCount = segmentsCount
+	}
+
+	var nextSegment atomic.Int64
+	var failed atomic.Bool
+	var errLock sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+	wg.Add(workersCount)
+	for i := 0; i < workersCount; i++ {
+		go func() {
+			defer wg.Done()
+			buffer := pipelinedCopyBuffers.Get().(*[pipelinedCopyChunkSizeBytes]byte)
+			defer pipelinedCopyBuffers.Put(buffer)
+
+			for !failed.Load() {
+				segment := nextSegment.Add(1) - 1
+				if segment >= int64(segmentsCount) {
+					return
+				}
+				offset := segment * parallelDigestSegmentSizeBytes
+				data := buffer[:]
+				if remaining := sizeBytes - offset; remaining < int64(len(data)) {
+					data = data[:remaining]
+				}
+				if n, err := r.ReadAt(data, offset); n != len(data) {
+					if err == nil || err == io.EOF {
+						err = io.ErrUnexpectedEOF
+					}
+					errLock.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					errLock.Unlock()
+					failed.Store(true)
+					return
+				}
+
+				digestGenerator := digestFunction.NewGenerator(int64(len(data)))
+				if _, err := digestGenerator.Write(data); err != nil {
+					panic(err)
+				}
+				segmentHash, err := hex.DecodeString(digestGenerator.Sum().GetHashString())
+				if err != nil {
+					panic(err)
+				}
+				segmentHashes[segment] = segmentHash
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return digest.BadDigest, firstErr
+	}
+	return digestFunction.NewDigest(hex.EncodeToString(combineSHA256TreeSegmentHashes(segmentHashes, sizeBytes)), sizeBytes)
+}
+
+// combineSHA256TreeSegmentHashes computes the SHA256TREE hash of an
+// object, given the hashes of its segments of size
+// parallelDigestSegmentSizeBytes. Only the last segment may be
+// smaller.
+func combineSHA256TreeSegmentHashes(segmentHashes [][]byte, sizeBytes int64) []byte {
+	// The leading 2^k bytes of an object larger than a single
+	// segment form a perfect binary tree of segments. Compute the
+	// hashes of these subtrees from left to right, and combine them
+	// with the hash of the trailing segment afterwards.
+	var leftHashes [][]byte
+	for sizeBytes > parallelDigestSegmentSizeBytes {
+		leftSizeBytes := int64(1) << (bits.Len64(uint64(sizeBytes-1)) - 1)
+		level := segmentHashes[:leftSizeBytes/parallelDigestSegmentSizeBytes]
+		for len(level) > 1 {
+			nextLevel := make([][]byte, 0, len(level)/2)
+			for i := 0; i < len(level); i += 2 {
+				nextLevel = append(nextLevel, computeSHA256TreeParentHash(level[i], level[i+1]))
+			}
+			level = nextLevel
+		}
+		leftHashes = append(leftHashes, level[0])
+		segmentHashes = segmentHashes[leftSizeBytes/parallelDigestSegmentSizeBytes:]
+		sizeBytes -= leftSizeBytes
+	}
+
+	hash := segmentHashes[0]
+	for i := len(leftHashes) - 1; i >= 0; i-- {
+		hash = computeSHA256TreeParentHash(leftHashes[i], hash)
+	}
+	return hash
+}
+
+// sha256TreeParentInitialState is the initial hash value that is used
+// by SHA256TREE to compute the hash of a parent node. It consists of
+// the leading fractional parts of the square roots of the 9th to the
+// 16th prime number.
+var sha256TreeParentInitialState = [8]uint32{
+	0xcbbb9d5d, 0x629a292a, 0x9159015a, 0x152fecd8,
+	0x67332667, 0x8eb44a87, 0xdb0c2e0d, 0x47b5481d,
+}
+
+// sha256RoundConstants are the round constants of the SHA-256 block
+// cipher.
+var sha256RoundConstants = [64]uint32{
+	0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+	0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+	0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+	0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+	0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+	0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+	0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+	0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2,
+}
+
+// computeSHA256TreeParentHash computes the SHA256TREE hash of an
+// object, given the hashes of its left and right halves. This is done
+// by invoking the SHA-256 block cipher once, without adding the
+// initial hash value to its output.
+func computeSHA256TreeParentHash(left, right []byte) []byte {
+	var w [64]uint32
+	for i := 0; i < 8; i++ {
+		w[i] = binary.BigEndian.Uint32(left[4*i:])
+		w[8+i] = binary.BigEndian.Uint32(right[4*i:])
+	}
+	for i := 16; i < 64; i++ {
+		s0 := bits.RotateLeft32(w[i-15], -7) ^ bits.RotateLeft32(w[i-15], -18) ^ (w[i-15] >> 3)
+		s1 := bits.RotateLeft32(w[i-2], -17) ^ bits.RotateLeft32(w[i-2], -19) ^ (w[i-2] >> 10)
+		w[i] = w[i-16] + s0 + w[i-7] + s1
+	}
+
+	state := sha256TreeParentInitialState
+	a, b, c, d, e, f, g, h := state[0], state[1], state[2], state[3], state[4], state[5], state[6], state[7]
+	for i := 0; i < 64; i++ {
+		t1 := h + (bits.RotateLeft32(e, -6) ^ bits.RotateLeft32(e, -11) ^ bits.RotateLeft32(e, -25)) + ((e & f) ^ (^e & g)) + sha256RoundConstants[i] + w[i]
+		t2 := (bits.RotateLeft32(a, -2) ^ bits.RotateLeft32(a, -13) ^ bits.RotateLeft32(a, -22)) + ((a & b) ^ (a & c) ^ (b & c))
+		h, g, f, e, d, c, b, a = g, f, e, d+t1, c, b, a, t1+t2
+	}
+
+	hash := make([]byte, 0, 32)
+	for _, v := range [...]uint32{a, b, c, d, e, f, g, h} {
+		hash = binary.BigEndian.AppendUint32(hash, v)
+	}
+	return hash
+}
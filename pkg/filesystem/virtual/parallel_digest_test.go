@@ -0,0 +1,48 @@
+package virtual
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeDigestParallel(t *testing.T) {
+	digestFunction := digest.MustNewFunction("Hello", remoteexecution.DigestFunction_SHA256TREE)
+	require.True(t, supportsParallelDigest(digestFunction))
+
+	data := make([]byte, 9<<20+7)
+	for i := range data {
+		data[i] = byte(i*7 + i>>13)
+	}
+
+	// The digests computed by computeDigestParallel() should be
+	// identical to the ones computed serially, regardless of
+	// whether the size of the object is a power of two, and whether
+	// the final segment is complete.
+	for _, sizeBytes := range []int{
+		1,
+		1023,
+		1024,
+		1025,
+		1 << 20,
+		1<<20 + 1,
+		2 << 20,
+		3<<20 + 5,
+		4 << 20,
+		8 << 20,
+		9<<20 + 7,
+	} {
+		t.Run(fmt.Sprintf("Size%d", sizeBytes), func(t *testing.T) {
+			expectedDigestGenerator := digestFunction.NewGenerator(int64(sizeBytes))
+			expectedDigestGenerator.Write(data[:sizeBytes])
+
+			actualDigest, err := computeDigestParallel(digestFunction, bytes.NewReader(data), int64(sizeBytes))
+			require.NoError(t, err)
+			require.Equal(t, expectedDigestGenerator.Sum(), actualDigest)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package virtual
+
+import (
+	"io"
+	"sync"
+)
+
+const (
+	// pipelinedCopyChunkSizeBytes is the size of the chunks in
+	// which copyPipelined() reads data from its source.
+	pipelinedCopyChunkSizeBytes = 1 << 20
+	// pipelinedCopyDepth is the maximum number of chunks that
+	// copyPipelined() reads ahead of its consumer.
+	pipelinedCopyDepth = 4
+	// pipelinedCopyMinimumSizeBytes is the minimum size of a file
+	// for which it is worth using copyPipelined(), as opposed to a
+	// plain io.Copy().
+	pipelinedCopyMinimumSizeBytes = pipelinedCopyDepth * pipelinedCopyChunkSizeBytes
+)
+
+// pipelinedCopyBuffers contains buffers of pipelinedCopyChunkSizeBytes
+// that are used to read data from files while computing digests. These
+// are reused across calls, as allocating several megabytes of memory
+// for every digest computation puts pressure on the garbage collector.
+var pipelinedCopyBuffers = sync.Pool{
+	New: func() any {
+		return new([pipelinedCopyChunkSizeBytes]byte)
+	},
+}
+
+type pipelinedCopyChunk struct {
+	data []byte
+	err  error
+}
+
+// copyPipelined copies all data from an io.ReaderAt into an io.Writer.
+// Unlike io.Copy(), reading is performed in a separate goroutine, so
+// that reading the next chunk of data from the source overlaps with
+// writing the current chunk into the destination.
+//
+// This is used to compute digests of large files. Hash functions like
+// SHA-256 are CPU bound, while reading from the file pool may incur
+// I/O latency. Pipelining both operations reduces the amount of time
+// needed to compute digests of multi-gigabyte output files.
+func copyPipelined(w io.Writer, r io.ReaderAt) error {
+	// Obtain a fixed number of buffers that are passed back and
+	// forth between the reader and the writer. As at most
+	// pipelinedCopyDepth chunks can be in flight, sends on either
+	// channel never block.
+	var buffers [pipelinedCopyDepth]*[pipelinedCopyChunkSizeBytes]byte
+	freeBuffers := make(chan []byte, pipelinedCopyDepth)
+	for i := range buffers {
+		buffers[i] = pipelinedCopyBuffers.Get().(*[pipelinedCopyChunkSizeBytes]byte)
+		freeBuffers <- buffers[i][:]
+	}
+	filledChunks := make(chan pipelinedCopyChunk, pipelinedCopyDepth)
+	done := make(chan struct{})
+	readerDone := make(chan struct{})
+	defer func() {
+		// Only return the buffers to the pool after the reader
+		// has terminated, as it may still be writing into one.
+		close(done)
+		<-readerDone
+		for _, buffer := range buffers {
+			pipelinedCopyBuffers.Put(buffer)
+		}
+	}()
+
+	go func() {
+		defer close(readerDone)
+		offset := int64(0)
+		for {
+			var buf []byte
+			select {
+			case buf = <-freeBuffers:
+			case <-done:
+				return
+			}
+			n, err := r.ReadAt(buf, offset)
+			offset += int64(n)
+			filledChunks <- pipelinedCopyChunk{
+				data: buf[:n],
+				err:  err,
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		chunk := <-filledChunks
+		if len(chunk.data) > 0 {
+			if _, err := w.Write(chunk.data); err != nil {
+				return err
+			}
+		}
+		if chunk.err == io.EOF {
+			return nil
+		} else if chunk.err != nil {
+			return chunk.err
+		}
+		freeBuffers <- chunk.data[:cap(chunk.data)]
+	}
+}
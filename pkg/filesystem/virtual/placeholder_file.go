@@ -28,6 +28,10 @@ func (placeholderFile) UploadFile(ctx context.Context, contentAddressableStorage
 	return digest.BadDigest, status.Error(codes.InvalidArgument, "This file cannot be uploaded, as it is a placeholder")
 }
 
+func (placeholderFile) ConvertToCASFile(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function, casFileFactory CASFileFactory) error {
+	return nil
+}
+
 func (placeholderFile) GetContainingDigests() digest.Set {
 	return digest.EmptySet
 }
@@ -38,6 +42,22 @@ func (placeholderFile) VirtualAllocate(off, size uint64) Status {
 
 func (placeholderFile) VirtualClose(shareAccess ShareMask) {}
 
+func (placeholderFile) VirtualCopyFileRange(ctx context.Context, source Leaf, offsetIn, offsetOut, length uint64) (uint64, Status) {
+	panic("Request to copy to special file should have been intercepted")
+}
+
+func (placeholderFile) VirtualDeallocate(off, size uint64) Status {
+	return StatusErrWrongType
+}
+
+func (placeholderFile) VirtualGetXAttr(ctx context.Context, name string) ([]byte, Status) {
+	return nil, StatusErrNoXAttr
+}
+
+func (placeholderFile) VirtualListXAttr(ctx context.Context) ([]string, Status) {
+	return nil, StatusOK
+}
+
 func (placeholderFile) VirtualOpenSelf(ctx context.Context, shareAccess ShareMask, options *OpenExistingOptions, requested AttributesMask, attributes *Attributes) Status {
 	// Even though this file may not necessarily be a symbolic link,
 	// the NFSv4 specification requires that NFS4ERR_SYMLINK is
@@ -49,10 +69,20 @@ func (placeholderFile) VirtualRead(buf []byte, offset uint64) (int, bool, Status
 	panic("Request to read from special file should have been intercepted")
 }
 
+func (placeholderFile) VirtualRemoveXAttr(ctx context.Context, name string) Status {
+	return StatusErrNoXAttr
+}
+
 func (placeholderFile) VirtualSeek(offset uint64, regionType filesystem.RegionType) (*uint64, Status) {
 	panic("Request to seek on special file should have been intercepted")
 }
 
+func (placeholderFile) VirtualSetXAttr(ctx context.Context, name string, value []byte, mode XAttrSetMode) Status {
+	// Similar to Linux, don't permit storing extended attributes on
+	// files other than regular files and directories.
+	return StatusErrPerm
+}
+
 func (placeholderFile) VirtualWrite(buf []byte, off uint64) (int, Status) {
 	panic("Request to write to symbolic link should have been intercepted")
 }
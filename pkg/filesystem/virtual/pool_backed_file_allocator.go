@@ -5,14 +5,17 @@ import (
 	"io"
 	"math"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
 	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/outputpathpersistency"
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteoutputservice"
-	"github.com/buildbarn/bb-storage/pkg/blobstore"
-	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/virtualfilesystemdebug"
+	re_sync "github.com/buildbarn/bb-remote-execution/pkg/sync"
+	"github.com/buildbarn/bb-storage/pkg/clock"
 	"github.com/buildbarn/bb-storage/pkg/digest"
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
@@ -31,13 +34,26 @@ var (
 			Namespace: "buildbarn",
 			Subsystem: "virtual",
 			Name:      "pool_backed_file_allocator_uploads_with_writable_descriptors_total",
-			Help:      "Total number times the contents of a pool-backed file were uploaded into the Content Addressable Storage while one or more writable file descriptors were present.",
+			Help:      "Total number times the contents of a pool-backed file were uploaded into the Content Addressable Storage while one or more writable file descriptors were present, without a write barrier having been applied.",
 		})
+	poolBackedFileAllocatorBackgroundUploads = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "buildbarn",
+			Subsystem: "virtual",
+			Name:      "pool_backed_file_allocator_background_uploads_total",
+			Help:      "Total number of times the contents of a pool-backed file were uploaded into the Content Addressable Storage in the background, after its last writable file descriptor was closed.",
+		},
+		[]string{"result"})
+	poolBackedFileAllocatorBackgroundUploadsSucceeded = poolBackedFileAllocatorBackgroundUploads.WithLabelValues("Succeeded")
+	poolBackedFileAllocatorBackgroundUploadsFailed    = poolBackedFileAllocatorBackgroundUploads.WithLabelValues("Failed")
 )
 
 type poolBackedFileAllocator struct {
-	pool        re_filesystem.FilePool
-	errorLogger util.ErrorLogger
+	pool               re_filesystem.FilePool
+	errorLogger        util.ErrorLogger
+	clock              clock.Clock
+	backgroundUploader *BackgroundUploader
+	deduplicator       *poolBackedFileDeduplicator
 }
 
 // NewPoolBackedFileAllocator creates an allocator for a leaf node that
@@ -49,57 +65,211 @@ type poolBackedFileAllocator struct {
 // file descriptor count reach zero), Close() is called on the
 // underlying backing file descriptor. This may be used to request
 // deletion from underlying storage.
-func NewPoolBackedFileAllocator(pool re_filesystem.FilePool, errorLogger util.ErrorLogger) FileAllocator {
+//
+// The last data modification time of files is tracked, using the
+// provided clock. This permits build systems that perform timestamp
+// based up-to-date checks (e.g., make, ninja) to be run inside build
+// actions.
+func NewPoolBackedFileAllocator(pool re_filesystem.FilePool, errorLogger util.ErrorLogger, clock clock.Clock) FileAllocator {
+	return NewBackgroundUploadingPoolBackedFileAllocator(pool, errorLogger, clock, nil)
+}
+
+// NewBackgroundUploadingPoolBackedFileAllocator is identical to
+// NewPoolBackedFileAllocator, except that files are registered with a
+// BackgroundUploader as soon as their last writable file descriptor is
+// closed. This allows the upload of output files to overlap with the
+// execution of the build action, instead of only starting after the
+// build action completes.
+//
+// Calls to UploadFile() that are performed afterwards skip uploading if
+// the file has not been modified since. It is therefore assumed that
+// all calls to UploadFile() use the same Content Addressable Storage
+// and digest function as provided to the BackgroundUploader. If the
+// background upload fails, the error is discarded and the file is
+// uploaded once again when UploadFile() is called.
+func NewBackgroundUploadingPoolBackedFileAllocator(pool re_filesystem.FilePool, errorLogger util.ErrorLogger, clock clock.Clock, backgroundUploader *BackgroundUploader) FileAllocator {
+	return newPoolBackedFileAllocator(pool, errorLogger, clock, backgroundUploader, nil)
+}
+
+// NewDeduplicatingPoolBackedFileAllocator is identical to
+// NewBackgroundUploadingPoolBackedFileAllocator, except that files
+// whose digests are known share their storage with other files having
+// the same digest. Storage is shared with copy-on-write semantics:
+// modifying a file whose storage is shared causes its contents to be
+// copied into a new file obtained from the FilePool.
+//
+// Digests of files that are only written sequentially are computed
+// while being written, meaning that these files are deduplicated as
+// soon as their last writable file descriptor is closed. Other files
+// are deduplicated once their digest is computed, which happens in the
+// background after their last writable file descriptor is closed.
+//
+// This function also returns a decorator for the provided
+// CASFileFactory, which should be used to create the input files of
+// the build action. Files whose contents are identical to one of these
+// input files (e.g., because the build action copied them into its
+// output tree) read their contents from the Content Addressable
+// Storage, meaning they no longer occupy any space in the FilePool.
+//
+// As deduplication is only performed among files created through the
+// same allocator, it is advised to use a separate instance for every
+// build action. The options bound the number of files that are hashed
+// in the background and the number of input files that are tracked.
+func NewDeduplicatingPoolBackedFileAllocator(pool re_filesystem.FilePool, errorLogger util.ErrorLogger, clock clock.Clock, backgroundUploader *BackgroundUploader, digestFunction digest.Function, casFileFactory CASFileFactory, options *FileDeduplicationOptions) (FileAllocator, CASFileFactory) {
+	deduplicator := newPoolBackedFileDeduplicator(&digestFunction, casFileFactory, options)
+	return newPoolBackedFileAllocator(pool, errorLogger, clock, backgroundUploader, deduplicator),
+		&inputRecordingCASFileFactory{
+			base:         casFileFactory,
+			deduplicator: deduplicator,
+		}
+}
+
+func newPoolBackedFileAllocator(pool re_filesystem.FilePool, errorLogger util.ErrorLogger, clock clock.Clock, backgroundUploader *BackgroundUploader, deduplicator *poolBackedFileDeduplicator) FileAllocator {
 	poolBackedFileAllocatorPrometheusMetrics.Do(func() {
 		prometheus.MustRegister(poolBackedFileAllocatorUploadsWithWritableDescriptors)
+		prometheus.MustRegister(poolBackedFileAllocatorBackgroundUploads)
 	})
 
 	return &poolBackedFileAllocator{
-		pool:        pool,
-		errorLogger: errorLogger,
+		pool:               pool,
+		errorLogger:        errorLogger,
+		clock:              clock,
+		backgroundUploader: backgroundUploader,
+		deduplicator:       deduplicator,
 	}
 }
 
+// filePoolErrorToStatus converts an error returned by a FilePool to a
+// Status. Running out of space (e.g., due to a quota being exceeded)
+// is caused by the build action, and is reported as such. All other
+// errors are logged and reported as I/O errors.
+func filePoolErrorToStatus(errorLogger util.ErrorLogger, err error) Status {
+	if status.Code(err) == codes.ResourceExhausted {
+		return StatusErrNoSpc
+	}
+	errorLogger.Log(err)
+	return StatusErrIO
+}
+
+// getDigestFunction returns the digest function that is going to be
+// used to compute the digests of files, if known in advance.
+func (fa *poolBackedFileAllocator) getDigestFunction() *digest.Function {
+	if fa.backgroundUploader != nil {
+		return &fa.backgroundUploader.digestFunction
+	}
+	if fa.deduplicator != nil {
+		return fa.deduplicator.digestFunction
+	}
+	return nil
+}
+
 func (fa *poolBackedFileAllocator) NewFile(isExecutable bool, size uint64, shareAccess ShareMask) (NativeLeaf, Status) {
 	file, err := fa.pool.NewFile()
 	if err != nil {
-		fa.errorLogger.Log(util.StatusWrapf(err, "Failed to create new file"))
-		return nil, StatusErrIO
+		return nil, filePoolErrorToStatus(fa.errorLogger, util.StatusWrapf(err, "Failed to create new file"))
 	}
 	if size > 0 {
 		if err := file.Truncate(int64(size)); err != nil {
-			fa.errorLogger.Log(util.StatusWrapf(err, "Failed to truncate file to length %d", size))
 			file.Close()
-			return nil, StatusErrIO
+			return nil, filePoolErrorToStatus(fa.errorLogger, util.StatusWrapf(err, "Failed to truncate file to length %d", size))
 		}
 	}
+	now := fa.clock.Now()
 	f := &fileBackedFile{
-		errorLogger: fa.errorLogger,
-
-		file:           file,
-		isExecutable:   isExecutable,
-		size:           size,
-		referenceCount: 1,
-		unfreezeWakeup: make(chan struct{}),
-		cachedDigest:   digest.BadDigest,
+		pool:               fa.pool,
+		errorLogger:        fa.errorLogger,
+		clock:              fa.clock,
+		backgroundUploader: fa.backgroundUploader,
+		deduplicator:       fa.deduplicator,
+
+		lock:                     re_sync.RWMutex{Rank: &leafLockRank},
+		file:                     file,
+		isExecutable:             isExecutable,
+		size:                     size,
+		lastDataModificationTime: now,
+		lastStatusChangeTime:     now,
+		referenceCount:           1,
+		unfreezeWakeup:           make(chan struct{}),
+		cachedDigest:             digest.BadDigest,
+	}
+	f.lastDataAccessTime.Store(now.UnixNano())
+	if digestFunction := fa.getDigestFunction(); digestFunction != nil && size == 0 {
+		// The digest function that is going to be used is
+		// already known. Start hashing the file's contents
+		// while it's being written.
+		f.appendDigestGenerator = digestFunction.NewGenerator(math.MaxInt64)
 	}
 	f.acquireShareAccessLocked(shareAccess)
 	return f, StatusOK
 }
 
 type fileBackedFile struct {
-	errorLogger util.ErrorLogger
+	pool               re_filesystem.FilePool
+	errorLogger        util.ErrorLogger
+	clock              clock.Clock
+	backgroundUploader *BackgroundUploader
+	deduplicator       *poolBackedFileDeduplicator
 
-	lock                     sync.RWMutex
+	lock                     re_sync.RWMutex
 	file                     filesystem.FileReadWriter
 	isExecutable             bool
 	size                     uint64
+	lastDataModificationTime time.Time
+	lastStatusChangeTime     time.Time
 	referenceCount           uint
 	writableDescriptorsCount uint
+	writableDescriptorsDone  chan struct{}
 	frozenDescriptorsCount   uint
 	unfreezeWakeup           chan struct{}
 	cachedDigest             digest.Digest
+	cachedDigestUploaded     bool
 	changeID                 uint64
+	extendedAttributes       extendedAttributes
+
+	// The value of changeID at the time ApplyWriteBarrier() was
+	// last called. If it still matches changeID, all writes
+	// performed through file descriptors that remain open for
+	// writing are known to have been delivered.
+	hasWriteBarrier      bool
+	writeBarrierChangeID uint64
+
+	// Hash state of the file's contents, which is maintained for as
+	// long as the file is only written sequentially. This prevents
+	// updateCachedDigest() from needing to reread the file. If set,
+	// it always covers exactly the first f.size bytes of the file.
+	appendDigestGenerator *digest.Generator
+
+	// The last data access time of the file, in nanoseconds since
+	// the Unix epoch. As reads only pick up a shared lock, it is
+	// updated atomically.
+	lastDataAccessTime atomic.Int64
+
+	// Snapshots of directories that may contain the file, and older
+	// versions of the file that are still used by these snapshots.
+	snapshotEpochs   *snapshotEpochs
+	snapshotVersions snapshotVersions[*fileBackedFile]
+
+	// If the file was created to upload the contents of another
+	// file as part of a snapshot, the file from which it was
+	// created and its change ID at the time.
+	snapshotOrigin         *fileBackedFile
+	snapshotOriginChangeID uint64
+}
+
+// dataModifiedLocked updates the timestamps and the change ID of the
+// file after its contents have been modified.
+func (f *fileBackedFile) dataModifiedLocked() {
+	now := f.clock.Now()
+	f.lastDataModificationTime = now
+	f.lastStatusChangeTime = now
+	f.changeID++
+}
+
+// statusChangedLocked updates the last status change time and the
+// change ID of the file after its attributes have been modified.
+func (f *fileBackedFile) statusChangedLocked() {
+	f.lastStatusChangeTime = f.clock.Now()
+	f.changeID++
 }
 
 // lockMutatingData picks up the exclusive lock of the file and waits
@@ -189,67 +359,42 @@ func (f *fileBackedFile) getCachedDigest() digest.Digest {
 	return f.cachedDigest
 }
 
-// updateCachedDigest returns the digest of the file. It either returns
-// a cached value, or computes the digest and caches it. It is only safe
-// to call this function while the file is frozen (i.e., calling
-// f.acquireFrozenDescriptor()).
-func (f *fileBackedFile) updateCachedDigest(digestFunction digest.Function) (digest.Digest, error) {
-	// Check whether the cached digest we have is still valid.
-	if cachedDigest := f.getCachedDigest(); cachedDigest != digest.BadDigest && cachedDigest.UsesDigestFunction(digestFunction) {
-		return cachedDigest, nil
-	}
+func (f *fileBackedFile) GetDebugState() *virtualfilesystemdebug.LeafState {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
 
-	// If not, compute a new digest.
-	digestGenerator := digestFunction.NewGenerator(math.MaxInt64)
-	if _, err := io.Copy(digestGenerator, io.NewSectionReader(f, 0, math.MaxInt64)); err != nil {
-		return digest.BadDigest, util.StatusWrapWithCode(err, codes.Internal, "Failed to compute file digest")
+	state := &virtualfilesystemdebug.LeafState{
+		Type:                     "pool_backed_file",
+		ReferenceCount:           uint64(f.referenceCount),
+		WritableDescriptorsCount: uint64(f.writableDescriptorsCount),
+		FrozenDescriptorsCount:   uint64(f.frozenDescriptorsCount),
+		SizeBytes:                f.size,
+		IsExecutable:             f.isExecutable,
 	}
-	newDigest := digestGenerator.Sum()
-
-	// Store the resulting cached digest.
-	f.lock.Lock()
-	f.cachedDigest = newDigest
-	f.lock.Unlock()
-	return newDigest, nil
-}
-
-func (f *fileBackedFile) UploadFile(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function) (digest.Digest, error) {
-	// Create a file handle that temporarily freezes the contents of
-	// this file. This ensures that the file's contents don't change
-	// between the digest computation and upload phase. This allows
-	// us to safely use NewValidatedBufferFromFileReader().
-	hasWritableDescriptors, success := f.acquireFrozenDescriptor()
-	if !success {
-		return digest.BadDigest, status.Error(codes.NotFound, "File was unlinked before uploading could start")
-	}
-	if hasWritableDescriptors {
-		// Process table cleaning should have cleaned up any
-		// file descriptors belonging to files in the input
-		// root. Yet we are still seeing the file being opened
-		// for writing. This is bad, as it means that data may
-		// still be present in the kernel's page cache.
-		//
-		// TODO: Would there be any way for us to force a sync
-		// of the file's contents?
-		poolBackedFileAllocatorUploadsWithWritableDescriptors.Inc()
+	if f.cachedDigest != digest.BadDigest {
+		state.CachedDigest = f.cachedDigest.GetProto()
 	}
+	return state
+}
 
-	blobDigest, err := f.updateCachedDigest(digestFunction)
-	if err != nil {
-		f.Close()
-		return digest.BadDigest, err
-	}
+func (f *fileBackedFile) DiscardCachedDigest() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
 
-	if err := contentAddressableStorage.Put(
-		ctx,
-		blobDigest,
-		buffer.NewValidatedBufferFromReaderAt(f, blobDigest.GetSizeBytes())); err != nil {
-		return digest.BadDigest, util.StatusWrap(err, "Failed to upload file")
-	}
-	return blobDigest, nil
+	f.cachedDigest = digest.BadDigest
+	f.cachedDigestUploaded = false
+	f.appendDigestGenerator = nil
 }
 
 func (f *fileBackedFile) GetContainingDigests() digest.Set {
+	// Files that have been converted by ConvertToCASFile() depend
+	// on the presence of their contents in the Content Addressable
+	// Storage.
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	if file, ok := f.file.(*casBackedPoolFile); ok {
+		return file.digest.ToSingletonSet()
+	}
 	return digest.EmptySet
 }
 
@@ -263,14 +408,16 @@ func (f *fileBackedFile) GetOutputServiceFileStatus(digestFunction *digest.Funct
 		defer f.releaseFrozenDescriptor()
 
 		// Don't report the digest if the file is opened for
-		// writing. The kernel may still hold on to data that
-		// needs to be written, meaning that digests computed on
-		// this end are inaccurate.
+		// writing, unless a write barrier has been applied
+		// since the file was last modified. The kernel may
+		// still hold on to data that needs to be written,
+		// meaning that digests computed on this end are
+		// inaccurate.
 		//
 		// By not reporting the digest, the client will
 		// recompute it itself. This will be consistent with
 		// what's stored in the kernel's page cache.
-		if !hasWritableDescriptors {
+		if !hasWritableDescriptors || !f.hasPendingWrites() {
 			blobDigest, err := f.updateCachedDigest(*digestFunction)
 			if err != nil {
 				return nil, err
@@ -317,10 +464,14 @@ func (f *fileBackedFile) Close() error {
 }
 
 func (f *fileBackedFile) ReadAt(b []byte, off int64) (int, error) {
-	f.lock.Lock()
-	defer f.lock.Unlock()
-
-	return f.file.ReadAt(b, off)
+	for {
+		f.lock.RLock()
+		n, err := f.file.ReadAt(b, off)
+		f.lock.RUnlock()
+		if !re_filesystem.WaitForFilePoolRetry(err) {
+			return n, err
+		}
+	}
 }
 
 func (f *fileBackedFile) VirtualAllocate(off, size uint64) Status {
@@ -335,6 +486,31 @@ func (f *fileBackedFile) VirtualAllocate(off, size uint64) Status {
 	return StatusOK
 }
 
+func (f *fileBackedFile) VirtualDeallocate(off, size uint64) Status {
+	f.lockMutatingData()
+	defer f.lock.Unlock()
+
+	// Like fallocate()'s FALLOC_FL_PUNCH_HOLE, ranges extending
+	// beyond the end of the file are truncated.
+	if off >= f.size || size == 0 {
+		return StatusOK
+	}
+	if remaining := f.size - off; size > remaining {
+		size = remaining
+	}
+	if s := f.unshareLocked(f.size); s != StatusOK {
+		return s
+	}
+	if err := re_filesystem.PunchHole(f.file, int64(off), int64(size)); err != nil {
+		return filePoolErrorToStatus(f.errorLogger, util.StatusWrapf(err, "Failed to deallocate %d bytes at offset %d", size, off))
+	}
+	f.cachedDigest = digest.BadDigest
+	f.cachedDigestUploaded = false
+	f.appendDigestGenerator = nil
+	f.dataModifiedLocked()
+	return StatusOK
+}
+
 // virtualGetAttributesUnlocked gets file attributes that can be
 // obtained without picking up any locks.
 func (f *fileBackedFile) virtualGetAttributesUnlocked(attributes *Attributes) {
@@ -345,6 +521,9 @@ func (f *fileBackedFile) virtualGetAttributesUnlocked(attributes *Attributes) {
 // obtained while picking up the file's lock.
 func (f *fileBackedFile) virtualGetAttributesLocked(attributes *Attributes) {
 	attributes.SetChangeID(f.changeID)
+	attributes.SetLastDataAccessTime(time.Unix(0, f.lastDataAccessTime.Load()))
+	attributes.SetLastDataModificationTime(f.lastDataModificationTime)
+	attributes.SetLastStatusChangeTime(f.lastStatusChangeTime)
 	permissions := PermissionsRead | PermissionsWrite
 	if f.isExecutable {
 		permissions |= PermissionsExecute
@@ -357,21 +536,57 @@ func (f *fileBackedFile) VirtualGetAttributes(ctx context.Context, requested Att
 	// Only pick up the file's lock when the caller requests
 	// attributes that require locking.
 	f.virtualGetAttributesUnlocked(attributes)
-	if requested&(AttributesMaskChangeID|AttributesMaskPermissions|AttributesMaskSizeBytes) != 0 {
+	if requested&(AttributesMaskChangeID|AttributesMaskLastDataAccessTime|AttributesMaskLastDataModificationTime|AttributesMaskLastStatusChangeTime|AttributesMaskPermissions|AttributesMaskSizeBytes) != 0 {
 		f.lock.RLock()
 		f.virtualGetAttributesLocked(attributes)
 		f.lock.RUnlock()
 	}
 }
 
-func (f *fileBackedFile) VirtualSeek(offset uint64, regionType filesystem.RegionType) (*uint64, Status) {
+func (f *fileBackedFile) VirtualGetXAttr(ctx context.Context, name string) ([]byte, Status) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.extendedAttributes.get(name)
+}
+
+func (f *fileBackedFile) VirtualListXAttr(ctx context.Context) ([]string, Status) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.extendedAttributes.list(), StatusOK
+}
+
+func (f *fileBackedFile) VirtualRemoveXAttr(ctx context.Context, name string) Status {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if s := f.extendedAttributes.remove(name); s != StatusOK {
+		return s
+	}
+	f.statusChangedLocked()
+	return StatusOK
+}
+
+func (f *fileBackedFile) VirtualSetXAttr(ctx context.Context, name string, value []byte, mode XAttrSetMode) Status {
 	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if s := f.extendedAttributes.set(name, value, mode); s != StatusOK {
+		return s
+	}
+	f.statusChangedLocked()
+	return StatusOK
+}
+
+func (f *fileBackedFile) VirtualSeek(offset uint64, regionType filesystem.RegionType) (*uint64, Status) {
+	f.lock.RLock()
 	if offset >= f.size {
-		f.lock.Unlock()
+		f.lock.RUnlock()
 		return nil, StatusErrNXIO
 	}
 	off, err := f.file.GetNextRegionOffset(int64(offset), regionType)
-	f.lock.Unlock()
+	f.lock.RUnlock()
 	if err == io.EOF {
 		// NFSv4's SEEK operation with NFS4_CONTENT_DATA differs
 		// from lseek(). If there is a hole at the end of the
@@ -412,17 +627,39 @@ func (f *fileBackedFile) VirtualOpenSelf(ctx context.Context, shareAccess ShareM
 }
 
 func (f *fileBackedFile) VirtualRead(buf []byte, off uint64) (int, bool, Status) {
-	f.lock.Lock()
-	defer f.lock.Unlock()
+	for {
+		n, eof, err := f.virtualReadOnce(buf, off)
+		if err == nil {
+			return n, eof, StatusOK
+		}
+		// The file pool may request that reads that failed due
+		// to transient errors are retried. Back off without
+		// holding the lock.
+		if !re_filesystem.WaitForFilePoolRetry(err) {
+			f.errorLogger.Log(util.StatusWrapf(err, "Failed to read from file at offset %d", off))
+			return 0, false, StatusErrIO
+		}
+	}
+}
+
+func (f *fileBackedFile) virtualReadOnce(buf []byte, off uint64) (int, bool, error) {
+	// Reads only need to pick up a shared lock, as FilePool permits
+	// concurrent calls to ReadAt(). This prevents multi-threaded
+	// workloads reading the same file from contending.
+	f.lock.RLock()
+	defer f.lock.RUnlock()
 
 	buf, eof := BoundReadToFileSize(buf, off, f.size)
 	if len(buf) > 0 {
 		if n, err := f.file.ReadAt(buf, int64(off)); n != len(buf) {
-			f.errorLogger.Log(util.StatusWrapf(err, "Failed to read from file at offset %d", off))
-			return 0, false, StatusErrIO
+			if err == nil {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, false, err
 		}
 	}
-	return len(buf), eof, StatusOK
+	f.lastDataAccessTime.Store(f.clock.Now().UnixNano())
+	return len(buf), eof, nil
 }
 
 func (f *fileBackedFile) VirtualReadlink(ctx context.Context) ([]byte, Status) {
@@ -438,18 +675,45 @@ func (f *fileBackedFile) VirtualClose(shareAccess ShareMask) {
 			panic("Invalid writable descriptor count")
 		}
 		f.writableDescriptorsCount--
+		if f.writableDescriptorsCount == 0 && f.writableDescriptorsDone != nil {
+			close(f.writableDescriptorsDone)
+			f.writableDescriptorsDone = nil
+		}
 	}
 	f.releaseReferencesLocked(shareAccess.Count())
+
+	if shareAccess&ShareMaskWrite != 0 && f.writableDescriptorsCount == 0 && f.referenceCount > 0 {
+		f.deduplicateAfterCloseLocked()
+		if f.backgroundUploader != nil {
+			f.backgroundUploader.enqueue(f)
+		}
+	}
 }
 
 func (f *fileBackedFile) virtualTruncate(size uint64) Status {
+	retainedSizeBytes := f.size
+	if retainedSizeBytes > size {
+		retainedSizeBytes = size
+	}
+	if s := f.unshareLocked(retainedSizeBytes); s != StatusOK {
+		return s
+	}
 	if err := f.file.Truncate(int64(size)); err != nil {
-		f.errorLogger.Log(util.StatusWrapf(err, "Failed to truncate file to length %d", size))
-		return StatusErrIO
+		return filePoolErrorToStatus(f.errorLogger, util.StatusWrapf(err, "Failed to truncate file to length %d", size))
 	}
 	f.cachedDigest = digest.BadDigest
+	f.cachedDigestUploaded = false
+	if digestGenerator := f.appendDigestGenerator; digestGenerator != nil && size != f.size {
+		if size == 0 {
+			// The file was emptied. Sequential writes may
+			// continue to be hashed incrementally.
+			f.appendDigestGenerator = digestGenerator.Sum().GetDigestFunction().NewGenerator(math.MaxInt64)
+		} else {
+			f.appendDigestGenerator = nil
+		}
+	}
 	f.size = size
-	f.changeID++
+	f.dataModifiedLocked()
 	return StatusOK
 }
 
@@ -468,8 +732,21 @@ func (f *fileBackedFile) VirtualSetAttributes(ctx context.Context, in *Attribute
 		}
 	}
 	if permissions, ok := in.GetPermissions(); ok {
+		f.preserveSnapshotVersionLocked()
 		f.isExecutable = (permissions & PermissionsExecute) != 0
-		f.changeID++
+		f.statusChangedLocked()
+	}
+	if lastDataAccessTime, ok := in.GetLastDataAccessTime(); ok {
+		// Handling of utimensat().
+		f.lastDataAccessTime.Store(lastDataAccessTime.UnixNano())
+		f.statusChangedLocked()
+	}
+	if lastDataModificationTime, ok := in.GetLastDataModificationTime(); ok {
+		// Handling of utimensat(). Any explicitly provided
+		// timestamp should take precedence over the one set
+		// by the truncation above.
+		f.lastDataModificationTime = lastDataModificationTime
+		f.statusChangedLocked()
 	}
 
 	f.virtualGetAttributesUnlocked(out)
@@ -478,20 +755,48 @@ func (f *fileBackedFile) VirtualSetAttributes(ctx context.Context, in *Attribute
 }
 
 func (f *fileBackedFile) VirtualWrite(buf []byte, offset uint64) (int, Status) {
-	f.lockMutatingData()
-	defer f.lock.Unlock()
+	for {
+		f.lockMutatingData()
+		n, s, err := f.virtualWriteLocked(buf, offset)
+		f.lock.Unlock()
+		// The file pool may request that writes that failed due
+		// to transient errors are retried. Back off without
+		// holding the lock. Writing the same data again is
+		// harmless if the previous attempt completed partially.
+		if s == StatusOK || !re_filesystem.WaitForFilePoolRetry(err) {
+			return n, s
+		}
+	}
+}
 
+// virtualWriteLocked writes data into the file. In addition to the
+// status, it returns the error returned by the file pool, so that the
+// caller may retry the write.
+func (f *fileBackedFile) virtualWriteLocked(buf []byte, offset uint64) (int, Status, error) {
+	if s := f.unshareLocked(f.size); s != StatusOK {
+		return 0, s, nil
+	}
 	nWritten, err := f.file.WriteAt(buf, int64(offset))
 	if nWritten > 0 {
 		f.cachedDigest = digest.BadDigest
+		f.cachedDigestUploaded = false
+		if digestGenerator := f.appendDigestGenerator; digestGenerator != nil {
+			if offset == f.size {
+				digestGenerator.Write(buf[:nWritten])
+			} else {
+				// Data is overwritten or a hole is
+				// created, meaning the hash state can
+				// no longer be extended.
+				f.appendDigestGenerator = nil
+			}
+		}
 		if end := offset + uint64(nWritten); f.size < end {
 			f.size = end
 		}
-		f.changeID++
+		f.dataModifiedLocked()
 	}
 	if err != nil {
-		f.errorLogger.Log(util.StatusWrapf(err, "Failed to write to file at offset %d", offset))
-		return nWritten, StatusErrIO
+		return nWritten, filePoolErrorToStatus(f.errorLogger, util.StatusWrapf(err, "Failed to write to file at offset %d", offset)), err
 	}
-	return nWritten, StatusOK
+	return nWritten, StatusOK, nil
 }
@@ -1,23 +1,28 @@
 package virtual_test
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"syscall"
 	"testing"
+	"time"
 
 	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
 	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
 	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/outputpathpersistency"
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteoutputservice"
 	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/clock"
 	"github.com/buildbarn/bb-storage/pkg/digest"
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
 	"github.com/buildbarn/bb-storage/pkg/testutil"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/semaphore"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -32,7 +37,7 @@ func TestPoolBackedFileAllocatorGetOutputServiceFileStatus(t *testing.T) {
 	pool.EXPECT().NewFile().Return(underlyingFile, nil)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 
-	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger).
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
 		NewFile(false, 0, virtual.ShareMaskRead|virtual.ShareMaskWrite)
 	require.Equal(t, virtual.StatusOK, s)
 
@@ -103,9 +108,10 @@ func TestPoolBackedFileAllocatorGetOutputServiceFileStatus(t *testing.T) {
 		},
 	}, fileStatus)
 
-	// Change the file's contents to invalidate the cached digest. A
-	// successive call to GetOutputServiceFileStatus() should
-	// recompute the digest.
+	// Append data to the file to invalidate the cached digest. As
+	// the file is only written sequentially, a successive call to
+	// GetOutputServiceFileStatus() should be able to compute the
+	// digest without rereading the file.
 	require.Equal(t, virtual.StatusOK, f.VirtualOpenSelf(ctx, virtual.ShareMaskWrite, &virtual.OpenExistingOptions{}, 0, &virtual.Attributes{}))
 	underlyingFile.EXPECT().WriteAt([]byte(" world"), int64(5)).Return(6, nil)
 	n, s = f.VirtualWrite([]byte(" world"), 5)
@@ -113,6 +119,28 @@ func TestPoolBackedFileAllocatorGetOutputServiceFileStatus(t *testing.T) {
 	require.Equal(t, 6, n)
 	f.VirtualClose(virtual.ShareMaskWrite)
 
+	fileStatus, err = f.GetOutputServiceFileStatus(&digestFunction1)
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &remoteoutputservice.FileStatus{
+		FileType: &remoteoutputservice.FileStatus_File_{
+			File: &remoteoutputservice.FileStatus_File{
+				Digest: &remoteexecution.Digest{
+					Hash:      "3e25960a79dbc69b674cd4ec67a72c62",
+					SizeBytes: 11,
+				},
+			},
+		},
+	}, fileStatus)
+
+	// Overwriting existing parts of the file should cause the
+	// digest to be recomputed from scratch.
+	require.Equal(t, virtual.StatusOK, f.VirtualOpenSelf(ctx, virtual.ShareMaskWrite, &virtual.OpenExistingOptions{}, 0, &virtual.Attributes{}))
+	underlyingFile.EXPECT().WriteAt([]byte("H"), int64(0)).Return(1, nil)
+	n, s = f.VirtualWrite([]byte("H"), 0)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 1, n)
+	f.VirtualClose(virtual.ShareMaskWrite)
+
 	underlyingFile.EXPECT().ReadAt(gomock.Any(), int64(0)).DoAndReturn(
 		func(p []byte, off int64) (int, error) {
 			return copy(p, "Hello world"), io.EOF
@@ -171,6 +199,200 @@ func TestPoolBackedFileAllocatorGetOutputServiceFileStatus(t *testing.T) {
 	f.VirtualClose(virtual.ShareMaskRead)
 }
 
+func TestPoolBackedFileAllocatorGetOutputServiceFileStatusLargeFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	pool := mock.NewMockFilePool(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	fileAllocator := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock)
+	digestFunction := digest.MustNewFunction("Hello", remoteexecution.DigestFunction_SHA256)
+
+	// Files that are several megabytes in size are read in chunks
+	// by a separate goroutine, so that reading and hashing overlap.
+	data := make([]byte, 4<<20+1234)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	expectedDigestGenerator := digestFunction.NewGenerator(int64(len(data)))
+	expectedDigestGenerator.Write(data)
+	expectedDigest := expectedDigestGenerator.Sum()
+
+	t.Run("Success", func(t *testing.T) {
+		underlyingFile := mock.NewMockFileReadWriter(ctrl)
+		pool.EXPECT().NewFile().Return(underlyingFile, nil)
+		underlyingFile.EXPECT().Truncate(int64(len(data)))
+
+		f, s := fileAllocator.NewFile(false, uint64(len(data)), virtual.ShareMaskRead)
+		require.Equal(t, virtual.StatusOK, s)
+
+		underlyingFile.EXPECT().ReadAt(gomock.Any(), gomock.Any()).
+			DoAndReturn(bytes.NewReader(data).ReadAt).
+			MinTimes(5)
+		fileStatus, err := f.GetOutputServiceFileStatus(&digestFunction)
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, &remoteoutputservice.FileStatus{
+			FileType: &remoteoutputservice.FileStatus_File_{
+				File: &remoteoutputservice.FileStatus_File{
+					Digest: expectedDigest.GetProto(),
+				},
+			},
+		}, fileStatus)
+
+		underlyingFile.EXPECT().Close()
+		f.Unlink()
+		f.VirtualClose(virtual.ShareMaskRead)
+	})
+
+	t.Run("ReadFailure", func(t *testing.T) {
+		underlyingFile := mock.NewMockFileReadWriter(ctrl)
+		pool.EXPECT().NewFile().Return(underlyingFile, nil)
+		underlyingFile.EXPECT().Truncate(int64(len(data)))
+
+		f, s := fileAllocator.NewFile(false, uint64(len(data)), virtual.ShareMaskRead)
+		require.Equal(t, virtual.StatusOK, s)
+
+		// Read errors should be propagated, and cause the
+		// reading goroutine to terminate.
+		underlyingFile.EXPECT().ReadAt(gomock.Any(), int64(0)).Return(0, status.Error(codes.Internal, "Disk on fire"))
+		_, err := f.GetOutputServiceFileStatus(&digestFunction)
+		testutil.RequireEqualStatus(t, status.Error(codes.Internal, "Failed to compute file digest: Disk on fire"), err)
+
+		underlyingFile.EXPECT().Close()
+		f.Unlink()
+		f.VirtualClose(virtual.ShareMaskRead)
+	})
+
+	t.Run("TreeHashing", func(t *testing.T) {
+		// For digest functions that are based on a tree, the
+		// hashes of segments of the file are computed
+		// concurrently and combined afterwards. This should
+		// yield the same digest as hashing the file serially.
+		treeDigestFunction := digest.MustNewFunction("Hello", remoteexecution.DigestFunction_SHA256TREE)
+		expectedTreeDigestGenerator := treeDigestFunction.NewGenerator(int64(len(data)))
+		expectedTreeDigestGenerator.Write(data)
+		expectedTreeDigest := expectedTreeDigestGenerator.Sum()
+
+		underlyingFile := mock.NewMockFileReadWriter(ctrl)
+		pool.EXPECT().NewFile().Return(underlyingFile, nil)
+		underlyingFile.EXPECT().Truncate(int64(len(data)))
+
+		f, s := fileAllocator.NewFile(false, uint64(len(data)), virtual.ShareMaskRead)
+		require.Equal(t, virtual.StatusOK, s)
+
+		underlyingFile.EXPECT().ReadAt(gomock.Any(), gomock.Any()).
+			DoAndReturn(bytes.NewReader(data).ReadAt).
+			Times(5)
+		fileStatus, err := f.GetOutputServiceFileStatus(&treeDigestFunction)
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, &remoteoutputservice.FileStatus{
+			FileType: &remoteoutputservice.FileStatus_File_{
+				File: &remoteoutputservice.FileStatus_File{
+					Digest: expectedTreeDigest.GetProto(),
+				},
+			},
+		}, fileStatus)
+
+		underlyingFile.EXPECT().Close()
+		f.Unlink()
+		f.VirtualClose(virtual.ShareMaskRead)
+	})
+
+	t.Run("TreeHashingShortRead", func(t *testing.T) {
+		treeDigestFunction := digest.MustNewFunction("Hello", remoteexecution.DigestFunction_SHA256TREE)
+		underlyingFile := mock.NewMockFileReadWriter(ctrl)
+		pool.EXPECT().NewFile().Return(underlyingFile, nil)
+		underlyingFile.EXPECT().Truncate(int64(len(data)))
+
+		f, s := fileAllocator.NewFile(false, uint64(len(data)), virtual.ShareMaskRead)
+		require.Equal(t, virtual.StatusOK, s)
+
+		// Segments that cannot be read in their entirety should
+		// cause digest computation to fail.
+		underlyingFile.EXPECT().ReadAt(gomock.Any(), gomock.Any()).
+			DoAndReturn(bytes.NewReader(data[:len(data)-1]).ReadAt).
+			MinTimes(1).
+			MaxTimes(5)
+		_, err := f.GetOutputServiceFileStatus(&treeDigestFunction)
+		testutil.RequireEqualStatus(t, status.Error(codes.Internal, "Failed to compute file digest: unexpected EOF"), err)
+
+		underlyingFile.EXPECT().Close()
+		f.Unlink()
+		f.VirtualClose(virtual.ShareMaskRead)
+	})
+}
+
+func TestPoolBackedFileAllocatorApplyWriteBarrier(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	pool := mock.NewMockFilePool(ctrl)
+	underlyingFile := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile, nil)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
+		NewFile(false, 0, virtual.ShareMaskRead|virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+
+	underlyingFile.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+	n, s := f.VirtualWrite([]byte("Hello"), 0)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 5, n)
+
+	// Without a write barrier, the digest should not be reported
+	// while the file is opened for writing.
+	digestFunction := digest.MustNewFunction("Hello", remoteexecution.DigestFunction_MD5)
+	fileStatus, err := f.GetOutputServiceFileStatus(&digestFunction)
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &remoteoutputservice.FileStatus{
+		FileType: &remoteoutputservice.FileStatus_File_{
+			File: &remoteoutputservice.FileStatus_File{},
+		},
+	}, fileStatus)
+
+	// After applying a write barrier, all data written so far is
+	// known to be present, meaning the digest can be reported.
+	require.Equal(t, virtual.StatusOK, virtual.ApplyWriteBarrier(f))
+	underlyingFile.EXPECT().ReadAt(gomock.Any(), int64(0)).DoAndReturn(
+		func(p []byte, off int64) (int, error) {
+			return copy(p, "Hello"), io.EOF
+		})
+	fileStatus, err = f.GetOutputServiceFileStatus(&digestFunction)
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &remoteoutputservice.FileStatus{
+		FileType: &remoteoutputservice.FileStatus_File_{
+			File: &remoteoutputservice.FileStatus_File{
+				Digest: &remoteexecution.Digest{
+					Hash:      "8b1a9953c4611296a827abf8c47804d7",
+					SizeBytes: 5,
+				},
+			},
+		},
+	}, fileStatus)
+
+	// Modifying the file afterwards should invalidate the write
+	// barrier.
+	underlyingFile.EXPECT().WriteAt([]byte(" world"), int64(5)).Return(6, nil)
+	n, s = f.VirtualWrite([]byte(" world"), 5)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 6, n)
+
+	fileStatus, err = f.GetOutputServiceFileStatus(&digestFunction)
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &remoteoutputservice.FileStatus{
+		FileType: &remoteoutputservice.FileStatus_File_{
+			File: &remoteoutputservice.FileStatus_File{},
+		},
+	}, fileStatus)
+
+	// Applying write barriers to files that have been released
+	// should fail.
+	f.VirtualClose(virtual.ShareMaskWrite)
+	underlyingFile.EXPECT().Close()
+	f.Unlink()
+	f.VirtualClose(virtual.ShareMaskRead)
+	require.Equal(t, virtual.StatusErrStale, virtual.ApplyWriteBarrier(f))
+}
+
 // For plain lseek() operations such as SEEK_SET, SEEK_CUR and SEEK_END,
 // the kernel never calls into userspace, as the kernel is capable of
 // handling those requests directly. However, For SEEK_HOLE and
@@ -184,7 +406,7 @@ func TestPoolBackedFileAllocatorVirtualSeek(t *testing.T) {
 	pool.EXPECT().NewFile().Return(underlyingFile, nil)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 
-	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger).
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
 		NewFile(false, 0, virtual.ShareMaskRead|virtual.ShareMaskWrite)
 	require.Equal(t, virtual.StatusOK, s)
 
@@ -252,7 +474,7 @@ func TestPoolBackedFileAllocatorVirtualOpenSelfStaleAfterUnlink(t *testing.T) {
 	underlyingFile.EXPECT().Close()
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 
-	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger).
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
 		NewFile(false, 0, virtual.ShareMaskWrite)
 	require.Equal(t, virtual.StatusOK, s)
 
@@ -277,7 +499,7 @@ func TestPoolBackedFileAllocatorVirtualOpenSelfStaleAfterClose(t *testing.T) {
 	underlyingFile.EXPECT().Close()
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 
-	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger).
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
 		NewFile(false, 0, virtual.ShareMaskWrite)
 	require.Equal(t, virtual.StatusOK, s)
 
@@ -298,7 +520,7 @@ func TestPoolBackedFileAllocatorVirtualRead(t *testing.T) {
 	pool.EXPECT().NewFile().Return(underlyingFile, nil)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 
-	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger).
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
 		NewFile(false, 0, virtual.ShareMaskRead|virtual.ShareMaskWrite)
 	require.Equal(t, virtual.StatusOK, s)
 
@@ -356,6 +578,43 @@ func TestPoolBackedFileAllocatorVirtualRead(t *testing.T) {
 		require.Equal(t, []byte("llo"), p[:3])
 	})
 
+	t.Run("Concurrent", func(t *testing.T) {
+		// Reads should not be serialized. The first read
+		// blocks until the second read reaches the underlying
+		// file, which would deadlock if reads required
+		// exclusive access to the file.
+		secondReadStarted := make(chan struct{})
+		underlyingFile.EXPECT().ReadAt(gomock.Len(1), int64(0)).DoAndReturn(
+			func(p []byte, off int64) (int, error) {
+				<-secondReadStarted
+				return copy(p, "H"), nil
+			})
+		underlyingFile.EXPECT().ReadAt(gomock.Len(1), int64(4)).DoAndReturn(
+			func(p []byte, off int64) (int, error) {
+				close(secondReadStarted)
+				return copy(p, "o"), nil
+			})
+
+		firstReadDone := make(chan struct{})
+		go func() {
+			var p [1]byte
+			n, eof, s := f.VirtualRead(p[:], 0)
+			require.Equal(t, virtual.StatusOK, s)
+			require.Equal(t, 1, n)
+			require.False(t, eof)
+			require.Equal(t, []byte("H"), p[:])
+			close(firstReadDone)
+		}()
+
+		var p [1]byte
+		n, eof, s := f.VirtualRead(p[:], 4)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, 1, n)
+		require.True(t, eof)
+		require.Equal(t, []byte("o"), p[:])
+		<-firstReadDone
+	})
+
 	underlyingFile.EXPECT().Close()
 
 	f.VirtualClose(virtual.ShareMaskRead | virtual.ShareMaskWrite)
@@ -377,7 +636,7 @@ func TestPoolBackedFileAllocatorFUSETruncateFailure(t *testing.T) {
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	errorLogger.EXPECT().Log(testutil.EqStatus(t, status.Error(codes.Unavailable, "Failed to truncate file to length 42: Storage backends offline")))
 
-	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger).
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
 		NewFile(false, 0, virtual.ShareMaskWrite)
 	require.Equal(t, virtual.StatusOK, s)
 
@@ -392,6 +651,310 @@ func TestPoolBackedFileAllocatorFUSETruncateFailure(t *testing.T) {
 
 // Write errors should be converted to EIO errors. In order to capture
 // error details, the underlying error is forwarded to an error logger.
+func TestPoolBackedFileAllocatorVirtualCopyFileRange(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	pool := mock.NewMockFilePool(ctrl)
+	underlyingFile := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile, nil)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
+		NewFile(false, 0, virtual.ShareMaskRead|virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+
+	// Source file that is 100 bytes in size, only containing data
+	// at offsets [10, 30).
+	source := mock.NewMockVirtualLeaf(ctrl)
+	source.EXPECT().VirtualGetAttributes(ctx, virtual.AttributesMaskSizeBytes, gomock.Any()).
+		Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+			attributes.SetSizeBytes(100)
+		}).
+		AnyTimes()
+
+	t.Run("PastEndOfFile", func(t *testing.T) {
+		n, s := f.VirtualCopyFileRange(ctx, source, 100, 0, 10)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, uint64(0), n)
+	})
+
+	t.Run("SparseCopy", func(t *testing.T) {
+		// Holes in the source file should not cause any data to
+		// be written to the destination file. The length of the
+		// copy should be bounded to the size of the source file.
+		offset10 := uint64(10)
+		offset30 := uint64(30)
+		source.EXPECT().VirtualSeek(uint64(0), filesystem.Data).Return(&offset10, virtual.StatusOK)
+		underlyingFile.EXPECT().Truncate(int64(10))
+		source.EXPECT().VirtualSeek(uint64(10), filesystem.Data).Return(&offset10, virtual.StatusOK)
+		source.EXPECT().VirtualSeek(uint64(10), filesystem.Hole).Return(&offset30, virtual.StatusOK)
+		source.EXPECT().VirtualRead(gomock.Len(20), uint64(10)).DoAndReturn(
+			func(buf []byte, offset uint64) (int, bool, virtual.Status) {
+				return copy(buf, "Hello, world! Hello!"), false, virtual.StatusOK
+			})
+		underlyingFile.EXPECT().WriteAt([]byte("Hello, world! Hello!"), int64(10)).Return(20, nil)
+		source.EXPECT().VirtualSeek(uint64(30), filesystem.Data).Return(nil, virtual.StatusOK)
+		underlyingFile.EXPECT().Truncate(int64(100))
+
+		n, s := f.VirtualCopyFileRange(ctx, source, 0, 0, 1000)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, uint64(100), n)
+	})
+
+	t.Run("HoleOverData", func(t *testing.T) {
+		// Copying a hole on top of existing data should cause
+		// the data to be overwritten with zeroes.
+		source.EXPECT().VirtualSeek(uint64(50), filesystem.Data).Return(nil, virtual.StatusOK)
+		underlyingFile.EXPECT().GetNextRegionOffset(int64(15), filesystem.Data).Return(int64(15), nil)
+		underlyingFile.EXPECT().GetNextRegionOffset(int64(15), filesystem.Hole).Return(int64(30), nil)
+		underlyingFile.EXPECT().WriteAt(make([]byte, 10), int64(15)).Return(10, nil)
+
+		n, s := f.VirtualCopyFileRange(ctx, source, 50, 15, 10)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, uint64(10), n)
+	})
+
+	t.Run("ReadFailure", func(t *testing.T) {
+		offset40 := uint64(40)
+		source.EXPECT().VirtualSeek(uint64(20), filesystem.Data).Return(&offset40, virtual.StatusOK)
+		underlyingFile.EXPECT().GetNextRegionOffset(int64(0), filesystem.Data).Return(int64(10), nil)
+		underlyingFile.EXPECT().GetNextRegionOffset(int64(10), filesystem.Hole).Return(int64(30), nil)
+		underlyingFile.EXPECT().WriteAt(make([]byte, 10), int64(10)).Return(10, nil)
+		source.EXPECT().VirtualSeek(uint64(40), filesystem.Data).Return(&offset40, virtual.StatusOK)
+		source.EXPECT().VirtualSeek(uint64(40), filesystem.Hole).Return(nil, virtual.StatusOK)
+		source.EXPECT().VirtualRead(gomock.Len(10), uint64(40)).Return(0, false, virtual.StatusErrIO)
+
+		n, s := f.VirtualCopyFileRange(ctx, source, 20, 0, 30)
+		require.Equal(t, virtual.StatusErrIO, s)
+		require.Equal(t, uint64(20), n)
+	})
+
+	t.Run("Clone", func(t *testing.T) {
+		// Copying an entire pool-backed file on top of a file
+		// that is not larger should cause both files to share
+		// their storage, instead of copying any data.
+		sourceUnderlyingFile := mock.NewMockFileReadWriter(ctrl)
+		pool.EXPECT().NewFile().Return(sourceUnderlyingFile, nil)
+		sourceUnderlyingFile.EXPECT().Truncate(int64(200))
+		poolBackedSource, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
+			NewFile(false, 200, 0)
+		require.Equal(t, virtual.StatusOK, s)
+
+		underlyingFile.EXPECT().Close()
+		n, s := f.VirtualCopyFileRange(ctx, poolBackedSource, 0, 0, 200)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, uint64(200), n)
+
+		var attributes virtual.Attributes
+		f.VirtualGetAttributes(ctx, virtual.AttributesMaskSizeBytes, &attributes)
+		sizeBytes, ok := attributes.GetSizeBytes()
+		require.True(t, ok)
+		require.Equal(t, uint64(200), sizeBytes)
+
+		// Reads should go to the shared storage. The storage
+		// should only be released after both files are gone.
+		sourceUnderlyingFile.EXPECT().ReadAt(gomock.Len(5), int64(10)).DoAndReturn(
+			func(p []byte, off int64) (int, error) {
+				return copy(p, "Hello"), nil
+			})
+		var buf [5]byte
+		nRead, _, s := f.VirtualRead(buf[:], 10)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, 5, nRead)
+		require.Equal(t, []byte("Hello"), buf[:])
+
+		poolBackedSource.Unlink()
+		underlyingFile = sourceUnderlyingFile
+	})
+
+	underlyingFile.EXPECT().Close()
+	f.VirtualClose(virtual.ShareMaskRead | virtual.ShareMaskWrite)
+	f.Unlink()
+}
+
+func TestPoolBackedFileAllocatorVirtualDeallocate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	pool := mock.NewMockFilePool(ctrl)
+	underlyingFile := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile, nil)
+	underlyingFile.EXPECT().Truncate(int64(100))
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
+		NewFile(false, 100, virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+
+	t.Run("PastEndOfFile", func(t *testing.T) {
+		require.Equal(t, virtual.StatusOK, f.VirtualDeallocate(100, 10))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		// The underlying file does not support punching holes.
+		// Data regions within the range should be overwritten
+		// with zeroes instead. The range should be bounded to
+		// the size of the file.
+		underlyingFile.EXPECT().GetNextRegionOffset(int64(80), filesystem.Data).Return(int64(95), nil)
+		underlyingFile.EXPECT().GetNextRegionOffset(int64(95), filesystem.Hole).Return(int64(100), nil)
+		underlyingFile.EXPECT().WriteAt(make([]byte, 5), int64(95)).Return(5, nil)
+
+		require.Equal(t, virtual.StatusOK, f.VirtualDeallocate(80, 1000))
+	})
+
+	t.Run("IOFailure", func(t *testing.T) {
+		underlyingFile.EXPECT().GetNextRegionOffset(int64(0), filesystem.Data).Return(int64(0), status.Error(codes.Unavailable, "Storage backends offline"))
+		errorLogger.EXPECT().Log(testutil.EqStatus(t, status.Error(codes.Unavailable, "Failed to deallocate 10 bytes at offset 0: Storage backends offline")))
+
+		require.Equal(t, virtual.StatusErrIO, f.VirtualDeallocate(0, 10))
+	})
+
+	underlyingFile.EXPECT().Close()
+	f.VirtualClose(virtual.ShareMaskWrite)
+	f.Unlink()
+}
+
+func TestPoolBackedFileAllocatorXAttr(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	pool := mock.NewMockFilePool(ctrl)
+	underlyingFile := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile, nil)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
+		NewFile(false, 0, virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+
+	// Newly created files have no extended attributes.
+	names, s := f.VirtualListXAttr(ctx)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Empty(t, names)
+	_, s = f.VirtualGetXAttr(ctx, "user.foo")
+	require.Equal(t, virtual.StatusErrNoXAttr, s)
+	require.Equal(t, virtual.StatusErrNoXAttr, f.VirtualSetXAttr(ctx, "user.foo", []byte("Hello"), virtual.XAttrSetModeReplace))
+
+	// Create extended attributes, and attempt to create one of
+	// them once more.
+	require.Equal(t, virtual.StatusOK, f.VirtualSetXAttr(ctx, "user.foo", []byte("Hello"), virtual.XAttrSetModeCreate))
+	require.Equal(t, virtual.StatusOK, f.VirtualSetXAttr(ctx, "user.bar", []byte("World"), virtual.XAttrSetModeCreateOrReplace))
+	require.Equal(t, virtual.StatusErrExist, f.VirtualSetXAttr(ctx, "user.foo", []byte("Hello"), virtual.XAttrSetModeCreate))
+
+	names, s = f.VirtualListXAttr(ctx)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, []string{"user.bar", "user.foo"}, names)
+	value, s := f.VirtualGetXAttr(ctx, "user.foo")
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, []byte("Hello"), value)
+
+	// Removal.
+	require.Equal(t, virtual.StatusOK, f.VirtualRemoveXAttr(ctx, "user.foo"))
+	require.Equal(t, virtual.StatusErrNoXAttr, f.VirtualRemoveXAttr(ctx, "user.foo"))
+
+	underlyingFile.EXPECT().Close()
+	f.VirtualClose(virtual.ShareMaskWrite)
+	f.Unlink()
+}
+
+func TestPoolBackedFileAllocatorTimestamps(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	pool := mock.NewMockFilePool(ctrl)
+	underlyingFile := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile, nil)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	clock := mock.NewMockClock(ctrl)
+
+	requireTimestamps := func(t *testing.T, attributes *virtual.Attributes, lastDataAccessTime, lastDataModificationTime, lastStatusChangeTime time.Time) {
+		actualLastDataAccessTime, ok := attributes.GetLastDataAccessTime()
+		require.True(t, ok)
+		require.Equal(t, lastDataAccessTime, actualLastDataAccessTime)
+		actualLastDataModificationTime, ok := attributes.GetLastDataModificationTime()
+		require.True(t, ok)
+		require.Equal(t, lastDataModificationTime, actualLastDataModificationTime)
+		actualLastStatusChangeTime, ok := attributes.GetLastStatusChangeTime()
+		require.True(t, ok)
+		require.Equal(t, lastStatusChangeTime, actualLastStatusChangeTime)
+	}
+
+	// The creation time of the file should be reported initially.
+	clock.EXPECT().Now().Return(time.Unix(1000, 0))
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock).
+		NewFile(false, 0, virtual.ShareMaskRead|virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+
+	getTimestamps := func(t *testing.T, lastDataAccessTime, lastDataModificationTime, lastStatusChangeTime time.Time) {
+		var attributes virtual.Attributes
+		f.VirtualGetAttributes(ctx, virtual.AttributesMaskLastDataAccessTime|virtual.AttributesMaskLastDataModificationTime|virtual.AttributesMaskLastStatusChangeTime, &attributes)
+		requireTimestamps(t, &attributes, lastDataAccessTime, lastDataModificationTime, lastStatusChangeTime)
+	}
+	getTimestamps(t, time.Unix(1000, 0), time.Unix(1000, 0), time.Unix(1000, 0))
+
+	t.Run("Write", func(t *testing.T) {
+		// Writes should update the modification and status
+		// change times.
+		underlyingFile.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+		clock.EXPECT().Now().Return(time.Unix(1001, 0))
+
+		n, s := f.VirtualWrite([]byte("Hello"), 0)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, 5, n)
+		getTimestamps(t, time.Unix(1000, 0), time.Unix(1001, 0), time.Unix(1001, 0))
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		// Reads should only update the access time.
+		underlyingFile.EXPECT().ReadAt(gomock.Len(5), int64(0)).DoAndReturn(func(p []byte, off int64) (int, error) {
+			return copy(p, "Hello"), nil
+		})
+		clock.EXPECT().Now().Return(time.Unix(1002, 0))
+
+		var p [5]byte
+		n, eof, s := f.VirtualRead(p[:], 0)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, 5, n)
+		require.True(t, eof)
+		getTimestamps(t, time.Unix(1002, 0), time.Unix(1001, 0), time.Unix(1001, 0))
+	})
+
+	t.Run("SetPermissions", func(t *testing.T) {
+		// Changing attributes should only update the status
+		// change time.
+		clock.EXPECT().Now().Return(time.Unix(1003, 0))
+
+		var attributes virtual.Attributes
+		require.Equal(t, virtual.StatusOK, f.VirtualSetAttributes(
+			ctx,
+			(&virtual.Attributes{}).SetPermissions(virtual.PermissionsRead|virtual.PermissionsWrite|virtual.PermissionsExecute),
+			virtual.AttributesMaskLastDataAccessTime|virtual.AttributesMaskLastDataModificationTime|virtual.AttributesMaskLastStatusChangeTime,
+			&attributes))
+		requireTimestamps(t, &attributes, time.Unix(1002, 0), time.Unix(1001, 0), time.Unix(1003, 0))
+	})
+
+	t.Run("SetTimes", func(t *testing.T) {
+		// Explicitly setting the access and modification times,
+		// as done by utimensat(), should take precedence over
+		// the time at which the file was truncated. The status
+		// change time cannot be set explicitly.
+		underlyingFile.EXPECT().Truncate(int64(0))
+		clock.EXPECT().Now().Return(time.Unix(1004, 0)).Times(3)
+
+		var attributes virtual.Attributes
+		require.Equal(t, virtual.StatusOK, f.VirtualSetAttributes(
+			ctx,
+			(&virtual.Attributes{}).
+				SetLastDataAccessTime(time.Unix(400, 456)).
+				SetLastDataModificationTime(time.Unix(500, 123)).
+				SetSizeBytes(0),
+			virtual.AttributesMaskLastDataAccessTime|virtual.AttributesMaskLastDataModificationTime|virtual.AttributesMaskLastStatusChangeTime,
+			&attributes))
+		requireTimestamps(t, &attributes, time.Unix(400, 456), time.Unix(500, 123), time.Unix(1004, 0))
+	})
+
+	underlyingFile.EXPECT().Close()
+	f.Unlink()
+	f.VirtualClose(virtual.ShareMaskRead | virtual.ShareMaskWrite)
+}
+
 func TestPoolBackedFileAllocatorVirtualWriteFailure(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
@@ -405,7 +968,7 @@ func TestPoolBackedFileAllocatorVirtualWriteFailure(t *testing.T) {
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 	errorLogger.EXPECT().Log(testutil.EqStatus(t, status.Error(codes.Unavailable, "Failed to write to file at offset 42: Storage backends offline")))
 
-	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger).
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
 		NewFile(false, 0, virtual.ShareMaskWrite)
 	require.Equal(t, virtual.StatusOK, s)
 	_, s = f.VirtualWrite(p[:], 42)
@@ -414,6 +977,29 @@ func TestPoolBackedFileAllocatorVirtualWriteFailure(t *testing.T) {
 	f.Unlink()
 }
 
+func TestPoolBackedFileAllocatorVirtualWriteQuotaExceeded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	pool := mock.NewMockFilePool(ctrl)
+	underlyingFile := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile, nil)
+	var p [10]byte
+	underlyingFile.EXPECT().WriteAt(p[:], int64(42)).Return(0, status.Error(codes.ResourceExhausted, "File size quota reached"))
+	underlyingFile.EXPECT().Close()
+
+	// Running out of space is caused by the build action, meaning
+	// it should be reported as ENOSPC without being logged.
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
+		NewFile(false, 0, virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+	_, s = f.VirtualWrite(p[:], 42)
+	require.Equal(t, virtual.StatusErrNoSpc, s)
+	f.VirtualClose(virtual.ShareMaskWrite)
+	f.Unlink()
+}
+
 func TestPoolBackedFileAllocatorUploadFile(t *testing.T) {
 	ctrl, ctx := gomock.WithContext(context.Background(), t)
 
@@ -423,7 +1009,7 @@ func TestPoolBackedFileAllocatorUploadFile(t *testing.T) {
 	pool.EXPECT().NewFile().Return(underlyingFile, nil)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 
-	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger).
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
 		NewFile(false, 0, virtual.ShareMaskWrite)
 	require.Equal(t, virtual.StatusOK, s)
 
@@ -558,6 +1144,219 @@ func TestPoolBackedFileAllocatorUploadFile(t *testing.T) {
 	})
 }
 
+func TestPoolBackedFileAllocatorBackgroundUpload(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	// Create a file backed by a FilePool that uploads its contents
+	// in the background.
+	pool := mock.NewMockFilePool(ctrl)
+	underlyingFile := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile, nil)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	backgroundContentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	fileDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	digestFunction := fileDigest.GetDigestFunction()
+	backgroundUploader := virtual.NewBackgroundUploader(backgroundContentAddressableStorage, digestFunction, semaphore.NewWeighted(1))
+
+	f, s := virtual.NewBackgroundUploadingPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock, backgroundUploader).
+		NewFile(false, 0, virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+
+	underlyingFile.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+	n, s := f.VirtualWrite([]byte("Hello"), 0)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 5, n)
+
+	// Closing the last writable file descriptor should cause the
+	// file to be uploaded in the background, as it is stored at one
+	// of the output paths. As the digest function is known in
+	// advance, the digest is computed while writing, meaning no
+	// reads against the file are necessary.
+	rootDirectory := mock.NewMockPrepopulatedDirectory(ctrl)
+	rootDirectory.EXPECT().LookupChild(path.MustNewComponent("output")).
+		Return(virtual.PrepopulatedDirectoryChild{}.FromLeaf(f), nil)
+	stopBackgroundUploader := backgroundUploader.Start(ctx, rootDirectory, [][]path.Component{
+		{path.MustNewComponent("output")},
+	})
+	uploaded := make(chan struct{})
+	backgroundContentAddressableStorage.EXPECT().Put(ctx, fileDigest, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+			b.Discard()
+			close(uploaded)
+			return nil
+		})
+	f.VirtualClose(virtual.ShareMaskWrite)
+	<-uploaded
+
+	// Stopping the background uploader waits for the upload to
+	// complete, so that the file is known to be marked as uploaded.
+	stopBackgroundUploader()
+
+	t.Run("AlreadyUploaded", func(t *testing.T) {
+		// As the file has not been modified since, calling
+		// UploadFile() should not upload it once again.
+		contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+
+		uploadedDigest, err := f.UploadFile(ctx, contentAddressableStorage, digestFunction)
+		require.NoError(t, err)
+		require.Equal(t, fileDigest, uploadedDigest)
+	})
+
+	t.Run("ModifiedAfterUpload", func(t *testing.T) {
+		// Modifying the file should cause the next call to
+		// UploadFile() to upload the file once again.
+		require.Equal(t, virtual.StatusOK, f.VirtualOpenSelf(ctx, virtual.ShareMaskWrite, &virtual.OpenExistingOptions{}, 0, &virtual.Attributes{}))
+		underlyingFile.EXPECT().WriteAt([]byte("World"), int64(5)).Return(5, nil)
+		n, s := f.VirtualWrite([]byte("World"), 5)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, 5, n)
+
+		modifiedDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "68e109f0f40ca72a15e05cc22786f8e6", 10)
+		contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+		contentAddressableStorage.EXPECT().Put(ctx, modifiedDigest, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				b.Discard()
+				return nil
+			})
+
+		uploadedDigest, err := f.UploadFile(ctx, contentAddressableStorage, digestFunction)
+		require.NoError(t, err)
+		require.Equal(t, modifiedDigest, uploadedDigest)
+	})
+
+	// Closing a file after it has been unlinked should not cause
+	// it to be uploaded.
+	f.Unlink()
+	underlyingFile.EXPECT().Close()
+	f.VirtualClose(virtual.ShareMaskWrite)
+}
+
+func TestPoolBackedFileAllocatorConvertToCASFile(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	// Create a file backed by a FilePool.
+	pool := mock.NewMockFilePool(ctrl)
+	underlyingFile := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile, nil)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
+		NewFile(true, 0, virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+
+	// Initialize the file with the contents "Hello".
+	underlyingFile.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+	n, s := f.VirtualWrite([]byte("Hello"), 0)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 5, n)
+
+	fileDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	digestFunction := fileDigest.GetDigestFunction()
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	casFileFactory := mock.NewMockCASFileFactory(ctrl)
+
+	t.Run("OpenedForWriting", func(t *testing.T) {
+		// Files that are opened for writing should not be
+		// converted, as writes are likely to follow.
+		require.NoError(t, f.ConvertToCASFile(ctx, contentAddressableStorage, digestFunction, casFileFactory))
+	})
+
+	f.VirtualClose(virtual.ShareMaskWrite)
+
+	t.Run("UploadFailure", func(t *testing.T) {
+		underlyingFile.EXPECT().ReadAt(gomock.Any(), int64(0)).DoAndReturn(func(p []byte, off int64) (int, error) {
+			copy(p, "Hello")
+			return 5, io.EOF
+		})
+		contentAddressableStorage.EXPECT().Put(ctx, fileDigest, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				b.Discard()
+				return status.Error(codes.Internal, "Server on fire")
+			})
+
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.Internal, "Failed to upload file: Server on fire"),
+			f.ConvertToCASFile(ctx, contentAddressableStorage, digestFunction, casFileFactory))
+	})
+
+	casFile := mock.NewMockNativeLeaf(ctrl)
+
+	t.Run("Success", func(t *testing.T) {
+		// The digest was already computed previously, meaning
+		// the file only needs to be read for the upload. After
+		// uploading, the file in the pool should be released.
+		underlyingFile.EXPECT().ReadAt(gomock.Any(), int64(0)).DoAndReturn(func(p []byte, off int64) (int, error) {
+			copy(p, "Hello")
+			return 5, io.EOF
+		})
+		contentAddressableStorage.EXPECT().Put(ctx, fileDigest, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, digest digest.Digest, b buffer.Buffer) error {
+				data, err := b.ToByteSlice(10)
+				require.NoError(t, err)
+				require.Equal(t, []byte("Hello"), data)
+				return nil
+			})
+		casFileFactory.EXPECT().LookupFile(fileDigest, false, nil).Return(casFile)
+		underlyingFile.EXPECT().Close()
+
+		require.NoError(t, f.ConvertToCASFile(ctx, contentAddressableStorage, digestFunction, casFileFactory))
+		require.Equal(t, fileDigest.ToSingletonSet(), f.GetContainingDigests())
+
+		// Reads should now be served from the Content
+		// Addressable Storage.
+		casFile.EXPECT().VirtualRead(gomock.Len(5), uint64(0)).DoAndReturn(func(buf []byte, off uint64) (int, bool, virtual.Status) {
+			return copy(buf, "Hello"), true, virtual.StatusOK
+		})
+		var buf [5]byte
+		n, eof, s := f.VirtualRead(buf[:], 0)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, 5, n)
+		require.True(t, eof)
+		require.Equal(t, []byte("Hello"), buf[:])
+	})
+
+	t.Run("AlreadyConverted", func(t *testing.T) {
+		// Converting the file once again should have no effect.
+		require.NoError(t, f.ConvertToCASFile(ctx, contentAddressableStorage, digestFunction, casFileFactory))
+	})
+
+	newUnderlyingFile := mock.NewMockFileReadWriter(ctrl)
+
+	t.Run("ReopenForWriting", func(t *testing.T) {
+		// Converted files may be opened for writing once again.
+		// Upon modification, the contents of the file should be
+		// copied back into the FilePool.
+		var attributes virtual.Attributes
+		require.Equal(t, virtual.StatusOK, f.VirtualOpenSelf(ctx, virtual.ShareMaskWrite, &virtual.OpenExistingOptions{}, 0, &attributes))
+
+		pool.EXPECT().NewFile().Return(newUnderlyingFile, nil)
+		newUnderlyingFile.EXPECT().Truncate(int64(5))
+		casFile.EXPECT().VirtualRead(gomock.Len(5), uint64(0)).DoAndReturn(func(buf []byte, off uint64) (int, bool, virtual.Status) {
+			return copy(buf, "Hello"), true, virtual.StatusOK
+		})
+		newUnderlyingFile.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+		casFile.EXPECT().Unlink()
+		newUnderlyingFile.EXPECT().WriteAt([]byte("!"), int64(5)).Return(1, nil)
+
+		n, s := f.VirtualWrite([]byte("!"), 5)
+		require.Equal(t, virtual.StatusOK, s)
+		require.Equal(t, 1, n)
+		require.Equal(t, digest.EmptySet, f.GetContainingDigests())
+		f.VirtualClose(virtual.ShareMaskWrite)
+	})
+
+	newUnderlyingFile.EXPECT().Close()
+	f.Unlink()
+
+	t.Run("Stale", func(t *testing.T) {
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.NotFound, "File was unlinked before conversion could start"),
+			f.ConvertToCASFile(ctx, contentAddressableStorage, digestFunction, casFileFactory))
+	})
+}
+
 func TestPoolBackedFileAllocatorVirtualClose(t *testing.T) {
 	ctrl, ctx := gomock.WithContext(context.Background(), t)
 
@@ -567,7 +1366,7 @@ func TestPoolBackedFileAllocatorVirtualClose(t *testing.T) {
 	pool.EXPECT().NewFile().Return(underlyingFile, nil)
 	errorLogger := mock.NewMockErrorLogger(ctrl)
 
-	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger).
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
 		NewFile(false, 0, virtual.ShareMaskWrite)
 	require.Equal(t, virtual.StatusOK, s)
 
@@ -614,3 +1413,283 @@ func TestPoolBackedFileAllocatorVirtualClose(t *testing.T) {
 	underlyingFile.EXPECT().Close()
 	f.VirtualClose(virtual.ShareMaskWrite)
 }
+
+func TestPoolBackedFileAllocatorDeduplication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	pool := mock.NewMockFilePool(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	fileDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	casFileFactory := mock.NewMockCASFileFactory(ctrl)
+	allocator, _ := virtual.NewDeduplicatingPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock, nil, fileDigest.GetDigestFunction(), casFileFactory, &virtual.FileDeduplicationOptions{
+		HashingSemaphore:  semaphore.NewWeighted(1),
+		MaximumInputFiles: 10,
+	})
+
+	// Create two files having identical contents. As both files
+	// are written sequentially, their digests are known as soon as
+	// they are closed. Closing the second file should cause it to
+	// share storage with the first file, allowing the storage of
+	// the second file to be released.
+	underlyingFile1 := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile1, nil)
+	f1, s := allocator.NewFile(false, 0, virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+	underlyingFile1.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+	n, s := f1.VirtualWrite([]byte("Hello"), 0)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 5, n)
+	f1.VirtualClose(virtual.ShareMaskWrite)
+
+	underlyingFile2 := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile2, nil)
+	f2, s := allocator.NewFile(false, 0, virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+	underlyingFile2.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+	n, s = f2.VirtualWrite([]byte("Hello"), 0)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 5, n)
+	underlyingFile2.EXPECT().Close()
+	f2.VirtualClose(virtual.ShareMaskWrite)
+
+	// Reads against the second file should now go to the storage
+	// of the first file.
+	underlyingFile1.EXPECT().ReadAt(gomock.Len(5), int64(0)).DoAndReturn(
+		func(p []byte, off int64) (int, error) {
+			return copy(p, "Hello"), nil
+		})
+	var buf [5]byte
+	n, eof, s := f2.VirtualRead(buf[:], 0)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 5, n)
+	require.True(t, eof)
+	require.Equal(t, []byte("Hello"), buf[:])
+
+	// Modifying the second file should cause its contents to be
+	// copied into new storage, so that the first file remains
+	// unaffected.
+	underlyingFile3 := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile3, nil)
+	underlyingFile3.EXPECT().Truncate(int64(5))
+	underlyingFile1.EXPECT().GetNextRegionOffset(int64(0), filesystem.Data).Return(int64(0), nil)
+	underlyingFile1.EXPECT().GetNextRegionOffset(int64(0), filesystem.Hole).Return(int64(5), nil)
+	underlyingFile1.EXPECT().ReadAt(gomock.Len(5), int64(0)).DoAndReturn(
+		func(p []byte, off int64) (int, error) {
+			return copy(p, "Hello"), nil
+		})
+	underlyingFile3.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+	underlyingFile3.EXPECT().WriteAt([]byte("!"), int64(5)).Return(1, nil)
+	n, s = f2.VirtualWrite([]byte("!"), 5)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 1, n)
+
+	// As the first file no longer shares its storage, modifying it
+	// should not cause its contents to be copied.
+	underlyingFile1.EXPECT().WriteAt([]byte("?"), int64(5)).Return(1, nil)
+	n, s = f1.VirtualWrite([]byte("?"), 5)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 1, n)
+
+	underlyingFile1.EXPECT().Close()
+	f1.Unlink()
+	underlyingFile3.EXPECT().Close()
+	f2.Unlink()
+}
+
+func TestPoolBackedFileAllocatorDeduplicationInputFile(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	pool := mock.NewMockFilePool(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	fileDigest := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	digestFunction := fileDigest.GetDigestFunction()
+	casFileFactory := mock.NewMockCASFileFactory(ctrl)
+	allocator, inputCASFileFactory := virtual.NewDeduplicatingPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock, nil, digestFunction, casFileFactory, &virtual.FileDeduplicationOptions{
+		HashingSemaphore:  semaphore.NewWeighted(1),
+		MaximumInputFiles: 10,
+	})
+
+	// Create an input file through the CASFileFactory returned by
+	// the allocator.
+	inputFile := mock.NewMockNativeLeaf(ctrl)
+	casFileFactory.EXPECT().LookupFile(fileDigest, true, nil).Return(inputFile)
+	require.Equal(t, inputFile, inputCASFileFactory.LookupFile(fileDigest, true, nil))
+
+	// Create a copy of the input file. Upon closure, the storage
+	// of the copy in the FilePool should be released, as its
+	// contents can be read from the Content Addressable Storage.
+	underlyingFile := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile, nil)
+	f, s := allocator.NewFile(false, 0, virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+	underlyingFile.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+	n, s := f.VirtualWrite([]byte("Hello"), 0)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 5, n)
+
+	casFile := mock.NewMockNativeLeaf(ctrl)
+	casFileFactory.EXPECT().LookupFile(fileDigest, false, nil).Return(casFile)
+	underlyingFile.EXPECT().Close()
+	f.VirtualClose(virtual.ShareMaskWrite)
+	require.Equal(t, fileDigest.ToSingletonSet(), f.GetContainingDigests())
+
+	// Reads should be served from the Content Addressable Storage.
+	casFile.EXPECT().VirtualRead(gomock.Len(5), uint64(0)).DoAndReturn(func(buf []byte, off uint64) (int, bool, virtual.Status) {
+		return copy(buf, "Hello"), true, virtual.StatusOK
+	})
+	var buf [5]byte
+	n, eof, s := f.VirtualRead(buf[:], 0)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 5, n)
+	require.True(t, eof)
+	require.Equal(t, []byte("Hello"), buf[:])
+
+	// As the contents of the file are already present in the
+	// Content Addressable Storage, there is no need to upload it.
+	uploadedDigest, err := f.UploadFile(ctx, mock.NewMockBlobAccess(ctrl), digestFunction)
+	require.NoError(t, err)
+	require.Equal(t, fileDigest, uploadedDigest)
+
+	casFile.EXPECT().Unlink()
+	f.Unlink()
+}
+
+func TestPoolBackedFileAllocatorDeduplicationInputFileEviction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	pool := mock.NewMockFilePool(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	fileDigest1 := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 5)
+	fileDigest2 := digest.MustNewDigest("example", remoteexecution.DigestFunction_MD5, "f5a7924e621e84c9280a9a27e1bcb7f6", 5)
+	casFileFactory := mock.NewMockCASFileFactory(ctrl)
+	allocator, inputCASFileFactory := virtual.NewDeduplicatingPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock, nil, fileDigest1.GetDigestFunction(), casFileFactory, &virtual.FileDeduplicationOptions{
+		HashingSemaphore:  semaphore.NewWeighted(1),
+		MaximumInputFiles: 1,
+	})
+
+	// Create two input files. As at most one input file is
+	// tracked, the digest of the first input file is discarded.
+	inputFile1 := mock.NewMockNativeLeaf(ctrl)
+	casFileFactory.EXPECT().LookupFile(fileDigest1, false, nil).Return(inputFile1)
+	require.Equal(t, inputFile1, inputCASFileFactory.LookupFile(fileDigest1, false, nil))
+	inputFile2 := mock.NewMockNativeLeaf(ctrl)
+	casFileFactory.EXPECT().LookupFile(fileDigest2, false, nil).Return(inputFile2)
+	require.Equal(t, inputFile2, inputCASFileFactory.LookupFile(fileDigest2, false, nil))
+
+	// A copy of the first input file should remain stored in the
+	// FilePool.
+	underlyingFile1 := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile1, nil)
+	f1, s := allocator.NewFile(false, 0, virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+	underlyingFile1.EXPECT().WriteAt([]byte("Hello"), int64(0)).Return(5, nil)
+	n, s := f1.VirtualWrite([]byte("Hello"), 0)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 5, n)
+	f1.VirtualClose(virtual.ShareMaskWrite)
+
+	// A copy of the second input file should be backed by the
+	// Content Addressable Storage.
+	underlyingFile2 := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile2, nil)
+	f2, s := allocator.NewFile(false, 0, virtual.ShareMaskWrite)
+	require.Equal(t, virtual.StatusOK, s)
+	underlyingFile2.EXPECT().WriteAt([]byte("World"), int64(0)).Return(5, nil)
+	n, s = f2.VirtualWrite([]byte("World"), 0)
+	require.Equal(t, virtual.StatusOK, s)
+	require.Equal(t, 5, n)
+	casFile := mock.NewMockNativeLeaf(ctrl)
+	casFileFactory.EXPECT().LookupFile(fileDigest2, false, nil).Return(casFile)
+	underlyingFile2.EXPECT().Close()
+	f2.VirtualClose(virtual.ShareMaskWrite)
+
+	underlyingFile1.EXPECT().Close()
+	f1.Unlink()
+	casFile.EXPECT().Unlink()
+	f2.Unlink()
+}
+
+func TestPoolBackedFileAllocatorMigrateToFilePool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	pool := mock.NewMockFilePool(ctrl)
+	underlyingFile1 := mock.NewMockFileReadWriter(ctrl)
+	pool.EXPECT().NewFile().Return(underlyingFile1, nil)
+	underlyingFile1.EXPECT().Truncate(int64(10))
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+
+	f, s := virtual.NewPoolBackedFileAllocator(pool, errorLogger, clock.SystemClock).
+		NewFile(false, 10, 0)
+	require.Equal(t, virtual.StatusOK, s)
+
+	t.Run("ShortRead", func(t *testing.T) {
+		// If the source file returns fewer bytes than
+		// requested without reporting an error, the migration
+		// should fail instead of wrapping a nil error.
+		targetPool := mock.NewMockFilePool(ctrl)
+		underlyingFile2 := mock.NewMockFileReadWriter(ctrl)
+		targetPool.EXPECT().NewFile().Return(underlyingFile2, nil)
+		underlyingFile2.EXPECT().Truncate(int64(10))
+		underlyingFile1.EXPECT().GetNextRegionOffset(int64(0), filesystem.Data).Return(int64(0), nil)
+		underlyingFile1.EXPECT().GetNextRegionOffset(int64(0), filesystem.Hole).Return(int64(10), nil)
+		underlyingFile1.EXPECT().ReadAt(gomock.Len(10), int64(0)).DoAndReturn(
+			func(p []byte, off int64) (int, error) {
+				return copy(p, "Hello"), nil
+			})
+		underlyingFile2.EXPECT().Close()
+
+		_, err := f.(virtual.FilePoolMigratableLeaf).MigrateToFilePool(targetPool)
+		testutil.RequireEqualStatus(t, status.Error(codes.Internal, "Read from file at offset 0 returned 5 bytes, while 10 bytes were expected"), err)
+	})
+
+	t.Run("ReadFailure", func(t *testing.T) {
+		targetPool := mock.NewMockFilePool(ctrl)
+		underlyingFile2 := mock.NewMockFileReadWriter(ctrl)
+		targetPool.EXPECT().NewFile().Return(underlyingFile2, nil)
+		underlyingFile2.EXPECT().Truncate(int64(10))
+		underlyingFile1.EXPECT().GetNextRegionOffset(int64(0), filesystem.Data).Return(int64(0), nil)
+		underlyingFile1.EXPECT().GetNextRegionOffset(int64(0), filesystem.Hole).Return(int64(10), nil)
+		underlyingFile1.EXPECT().ReadAt(gomock.Len(10), int64(0)).Return(0, status.Error(codes.Unavailable, "Storage backends offline"))
+		underlyingFile2.EXPECT().Close()
+
+		_, err := f.(virtual.FilePoolMigratableLeaf).MigrateToFilePool(targetPool)
+		testutil.RequireEqualStatus(t, status.Error(codes.Unavailable, "Failed to read from file at offset 0: Storage backends offline"), err)
+	})
+
+	t.Run("TransientFailures", func(t *testing.T) {
+		// Reads and writes that fail with transient errors
+		// should be retried once the file pool permits it.
+		retry := make(chan time.Time)
+		close(retry)
+		targetPool := mock.NewMockFilePool(ctrl)
+		underlyingFile2 := mock.NewMockFileReadWriter(ctrl)
+		targetPool.EXPECT().NewFile().Return(underlyingFile2, nil)
+		underlyingFile2.EXPECT().Truncate(int64(10))
+		underlyingFile1.EXPECT().GetNextRegionOffset(int64(0), filesystem.Data).Return(int64(0), nil)
+		underlyingFile1.EXPECT().GetNextRegionOffset(int64(0), filesystem.Hole).Return(int64(10), nil)
+		gomock.InOrder(
+			underlyingFile1.EXPECT().ReadAt(gomock.Len(10), int64(0)).Return(0, &re_filesystem.TransientFilePoolError{
+				Err:   syscall.EIO,
+				Retry: retry,
+			}),
+			underlyingFile1.EXPECT().ReadAt(gomock.Len(10), int64(0)).DoAndReturn(
+				func(p []byte, off int64) (int, error) {
+					return copy(p, "HelloWorld"), nil
+				}))
+		gomock.InOrder(
+			underlyingFile2.EXPECT().WriteAt([]byte("HelloWorld"), int64(0)).Return(4, &re_filesystem.TransientFilePoolError{
+				Err:   syscall.EIO,
+				Retry: retry,
+			}),
+			underlyingFile2.EXPECT().WriteAt([]byte("HelloWorld"), int64(0)).Return(10, nil))
+		underlyingFile1.EXPECT().Close()
+
+		sizeBytes, err := f.(virtual.FilePoolMigratableLeaf).MigrateToFilePool(targetPool)
+		require.NoError(t, err)
+		require.Equal(t, uint64(10), sizeBytes)
+
+		underlyingFile2.EXPECT().Close()
+	})
+
+	f.Unlink()
+}
@@ -0,0 +1,211 @@
+package virtual
+
+import (
+	"context"
+	"io"
+
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// copyFileRangeChunkSizeBytes is the maximum amount of data that
+// VirtualCopyFileRange() reads from the source file at once.
+const copyFileRangeChunkSizeBytes = 1 << 20
+
+func (f *fileBackedFile) VirtualCopyFileRange(ctx context.Context, source Leaf, offsetIn, offsetOut, length uint64) (uint64, Status) {
+	var attributes Attributes
+	source.VirtualGetAttributes(ctx, AttributesMaskSizeBytes, &attributes)
+	sourceSize, ok := attributes.GetSizeBytes()
+	if !ok {
+		panic("Source file did not return size attribute, even though it was requested")
+	}
+	if offsetIn >= sourceSize {
+		return 0, StatusOK
+	}
+	if remaining := sourceSize - offsetIn; length > remaining {
+		length = remaining
+	}
+
+	// If the entire source file is copied on top of the entire
+	// destination file, let both files share their storage instead
+	// of copying any data. Storage is copied once either of the
+	// files is modified.
+	if sourceFile, ok := getUndecoratedLeaf(source).(*fileBackedFile); ok && sourceFile != f && offsetIn == 0 && offsetOut == 0 && length == sourceSize {
+		if copied, ok := f.cloneFrom(sourceFile); ok {
+			return copied, StatusOK
+		}
+	}
+
+	// Copy data regions of the source file in large chunks. Holes
+	// in the source file are reproduced in the destination file
+	// without writing any data where possible, so that sparse
+	// files remain sparse.
+	chunkSize := uint64(copyFileRangeChunkSizeBytes)
+	if chunkSize > length {
+		chunkSize = length
+	}
+	buf := make([]byte, chunkSize)
+	copied := uint64(0)
+	for copied < length {
+		dataStart, s := source.VirtualSeek(offsetIn+copied, filesystem.Data)
+		if s != StatusOK {
+			return copied, s
+		}
+		holeEnd := length
+		if dataStart != nil && *dataStart-offsetIn < holeEnd {
+			holeEnd = *dataStart - offsetIn
+		}
+		if copied < holeEnd {
+			if s := f.virtualZeroRange(offsetOut+copied, holeEnd-copied); s != StatusOK {
+				return copied, s
+			}
+			copied = holeEnd
+			continue
+		}
+
+		dataEnd, s := source.VirtualSeek(offsetIn+copied, filesystem.Hole)
+		if s != StatusOK {
+			return copied, s
+		}
+		chunk := buf
+		if dataEnd != nil && *dataEnd-offsetIn-copied < uint64(len(chunk)) {
+			chunk = chunk[:*dataEnd-offsetIn-copied]
+		}
+		if remaining := length - copied; remaining < uint64(len(chunk)) {
+			chunk = chunk[:remaining]
+		}
+		nRead, _, s := source.VirtualRead(chunk, offsetIn+copied)
+		if s != StatusOK {
+			return copied, s
+		}
+		if nRead == 0 {
+			// Source file got truncated while copying.
+			break
+		}
+		nWritten, s := f.VirtualWrite(chunk[:nRead], offsetOut+copied)
+		copied += uint64(nWritten)
+		if s != StatusOK {
+			return copied, s
+		}
+	}
+	return copied, StatusOK
+}
+
+// virtualZeroRange sets a range of the file to zero. Parts of the range
+// that lie beyond the end of the file are turned into a hole.
+func (f *fileBackedFile) virtualZeroRange(offset, length uint64) Status {
+	f.lockMutatingData()
+	defer f.lock.Unlock()
+
+	end := offset + length
+	if offset < f.size {
+		zeroesEnd := end
+		if zeroesEnd > f.size {
+			zeroesEnd = f.size
+		}
+		if s := f.unshareLocked(f.size); s != StatusOK {
+			return s
+		}
+		if err := re_filesystem.PunchHole(f.file, int64(offset), int64(zeroesEnd-offset)); err != nil {
+			return filePoolErrorToStatus(f.errorLogger, util.StatusWrapf(err, "Failed to zero %d bytes at offset %d", zeroesEnd-offset, offset))
+		}
+		f.cachedDigest = digest.BadDigest
+		f.cachedDigestUploaded = false
+		f.appendDigestGenerator = nil
+		f.dataModifiedLocked()
+	}
+	if f.size < end {
+		return f.virtualTruncate(end)
+	}
+	return StatusOK
+}
+
+// copyFileContents copies the data regions of a file into another
+// file. Holes are not copied, so that sparse files remain sparse.
+func copyFileContents(dst filesystem.FileWriter, src filesystem.FileReader, size int64) error {
+	if err := dst.Truncate(size); err != nil {
+		return util.StatusWrapf(err, "Failed to truncate file to length %d", size)
+	}
+	var buf [64 * 1024]byte
+	for offset := int64(0); offset < size; {
+		dataStart, err := src.GetNextRegionOffset(offset, filesystem.Data)
+		if err == io.EOF {
+			// Remainder of the file is a hole.
+			return nil
+		} else if err != nil {
+			return util.StatusWrapf(err, "Failed to get next data region offset at offset %d", offset)
+		}
+		dataEnd, err := src.GetNextRegionOffset(dataStart, filesystem.Hole)
+		if err == io.EOF || (err == nil && dataEnd > size) {
+			dataEnd = size
+		} else if err != nil {
+			return util.StatusWrapf(err, "Failed to get next hole region offset at offset %d", dataStart)
+		}
+
+		for dataStart < dataEnd {
+			chunk := buf[:]
+			if remaining := dataEnd - dataStart; remaining < int64(len(chunk)) {
+				chunk = chunk[:remaining]
+			}
+			// Callers hold locks on the file to ensure its
+			// contents remain stable while copying. Back off
+			// from transient errors reported by the file
+			// pool without releasing them.
+			n, err := src.ReadAt(chunk, dataStart)
+			for n != len(chunk) && re_filesystem.WaitForFilePoolRetry(err) {
+				n, err = src.ReadAt(chunk, dataStart)
+			}
+			if n != len(chunk) {
+				if err == nil || err == io.EOF {
+					// The file is shorter than its
+					// data regions claim it to be.
+					return status.Errorf(codes.Internal, "Read from file at offset %d returned %d bytes, while %d bytes were expected", dataStart, n, len(chunk))
+				}
+				return util.StatusWrapf(err, "Failed to read from file at offset %d", dataStart)
+			}
+			_, err = dst.WriteAt(chunk, dataStart)
+			for err != nil && re_filesystem.WaitForFilePoolRetry(err) {
+				_, err = dst.WriteAt(chunk, dataStart)
+			}
+			if err != nil {
+				return util.StatusWrapf(err, "Failed to write to file at offset %d", dataStart)
+			}
+			dataStart += int64(len(chunk))
+		}
+		offset = dataEnd
+	}
+	return nil
+}
+
+func (f *fileBackedFile) MigrateToFilePool(pool re_filesystem.FilePool) (uint64, error) {
+	// Hold the lock while copying, so that the file cannot be
+	// modified during the migration.
+	f.lockMutatingData()
+	defer f.lock.Unlock()
+
+	if f.referenceCount == 0 {
+		return 0, status.Error(codes.NotFound, "File has already been released")
+	}
+	newFile, err := pool.NewFile()
+	if err != nil {
+		return 0, util.StatusWrap(err, "Failed to create new file")
+	}
+	if err := copyFileContents(newFile, f.file, int64(f.size)); err != nil {
+		newFile.Close()
+		return 0, err
+	}
+
+	// The contents of the file are unaltered. There is thus no
+	// need to discard the cached digest or to bump the change ID.
+	oldFile := f.file
+	f.file = newFile
+	if err := oldFile.Close(); err != nil {
+		f.errorLogger.Log(util.StatusWrap(err, "Failed to close file after migrating its contents"))
+	}
+	return f.size, nil
+}
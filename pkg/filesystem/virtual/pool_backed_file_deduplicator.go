@@ -0,0 +1,396 @@
+package virtual
+
+import (
+	"container/list"
+	"sync"
+
+	re_sync "github.com/buildbarn/bb-remote-execution/pkg/sync"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"golang.org/x/sync/semaphore"
+)
+
+var (
+	poolBackedFileDeduplicatorPrometheusMetrics sync.Once
+
+	poolBackedFileDeduplicatorBackgroundHashingSkipped = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "buildbarn",
+			Subsystem: "virtual",
+			Name:      "pool_backed_file_deduplicator_background_hashing_skipped_total",
+			Help:      "Total number of times a pool-backed file was not deduplicated after its last writable file descriptor was closed, because the maximum number of files that are hashed concurrently was reached.",
+		})
+)
+
+// FileDeduplicationOptions contains the parameters that are used by
+// NewDeduplicatingPoolBackedFileAllocator() to bound the amount of
+// work and memory spent on deduplicating files.
+type FileDeduplicationOptions struct {
+	// Limits the number of files that are hashed in the background
+	// at once. This semaphore may be shared by the allocators of
+	// all build actions running on a worker.
+	HashingSemaphore *semaphore.Weighted
+	// The maximum number of input files of the build action whose
+	// digests are tracked. Copies of input files whose digests are
+	// no longer tracked are stored in the file pool.
+	MaximumInputFiles int
+}
+
+// poolBackedFileDeduplicator keeps track of files obtained from a
+// FilePool whose digests are known, so that pool-backed files having
+// identical contents can share the same storage.
+//
+// In addition to that, it keeps track of the digests of input files
+// that are backed by the Content Addressable Storage. Pool-backed files
+// whose contents are identical to one of these files don't need to be
+// stored in the FilePool at all. The number of input files that is
+// tracked is bounded, evicting the least recently created ones first.
+type poolBackedFileDeduplicator struct {
+	digestFunction    *digest.Function
+	casFileFactory    CASFileFactory
+	hashingSemaphore  *semaphore.Weighted
+	maximumInputFiles int
+
+	lock          re_sync.Mutex
+	files         map[digest.Digest]*sharedPoolFile
+	inputFiles    map[digest.Digest]*list.Element
+	inputFilesLRU list.List
+}
+
+func newPoolBackedFileDeduplicator(digestFunction *digest.Function, casFileFactory CASFileFactory, options *FileDeduplicationOptions) *poolBackedFileDeduplicator {
+	poolBackedFileDeduplicatorPrometheusMetrics.Do(func() {
+		prometheus.MustRegister(poolBackedFileDeduplicatorBackgroundHashingSkipped)
+	})
+
+	d := &poolBackedFileDeduplicator{
+		digestFunction: digestFunction,
+		casFileFactory: casFileFactory,
+
+		lock:       re_sync.Mutex{Rank: &deduplicatorLockRank},
+		files:      map[digest.Digest]*sharedPoolFile{},
+		inputFiles: map[digest.Digest]*list.Element{},
+	}
+	if options != nil {
+		d.hashingSemaphore = options.HashingSemaphore
+		d.maximumInputFiles = options.MaximumInputFiles
+	}
+	return d
+}
+
+// isInputFile returns whether a file backed by the Content Addressable
+// Storage having a given digest was created through the
+// CASFileFactory returned by NewDeduplicatingPoolBackedFileAllocator().
+func (d *poolBackedFileDeduplicator) isInputFile(blobDigest digest.Digest) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	_, ok := d.inputFiles[blobDigest]
+	return ok
+}
+
+// addInputFile registers the digest of an input file of the build
+// action. If the maximum number of input files is exceeded, the digest
+// of the least recently registered input file is discarded.
+func (d *poolBackedFileDeduplicator) addInputFile(blobDigest digest.Digest) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if element, ok := d.inputFiles[blobDigest]; ok {
+		d.inputFilesLRU.MoveToFront(element)
+		return
+	}
+	if d.maximumInputFiles <= 0 {
+		return
+	}
+	if d.inputFilesLRU.Len() >= d.maximumInputFiles {
+		delete(d.inputFiles, d.inputFilesLRU.Remove(d.inputFilesLRU.Back()).(digest.Digest))
+	}
+	d.inputFiles[blobDigest] = d.inputFilesLRU.PushFront(blobDigest)
+}
+
+// deduplicate a file obtained from a FilePool whose digest has just
+// been computed. If another file having the same digest is known, a
+// reference to that file is returned and the file that is provided is
+// closed. If not, the file is registered, so that files that are
+// created subsequently may share its storage.
+//
+// The file that is returned must not be modified, as it may be used by
+// other pool-backed files as well. sharedPoolFile.unwrap() needs to be
+// called to obtain a file that may be modified.
+func (d *poolBackedFileDeduplicator) deduplicate(file filesystem.FileReadWriter, blobDigest digest.Digest) filesystem.FileReadWriter {
+	switch file.(type) {
+	case *sharedPoolFile, *casBackedPoolFile:
+		// Storage is already shared with other files. There is
+		// no need to register it once again under a digest
+		// computed using a different digest function.
+		return file
+	}
+
+	if d.casFileFactory != nil && d.isInputFile(blobDigest) {
+		// The file is a copy of one of the input files of the
+		// build action. Its contents can be read from the
+		// Content Addressable Storage instead.
+		file.Close()
+		return newCASBackedPoolFile(d.casFileFactory.LookupFile(blobDigest, false, nil), blobDigest)
+	}
+
+	d.lock.Lock()
+	if sf, ok := d.files[blobDigest]; ok {
+		sf.referenceCount++
+		d.lock.Unlock()
+		file.Close()
+		return sf
+	}
+	sf := &sharedPoolFile{
+		FileReadWriter: file,
+		deduplicator:   d,
+		digest:         blobDigest,
+		referenceCount: 1,
+	}
+	d.files[blobDigest] = sf
+	d.lock.Unlock()
+	return sf
+}
+
+// sharedPoolFile is a file obtained from a FilePool whose contents may
+// be shared by multiple pool-backed files. Close() only closes the
+// underlying file once all pool-backed files have released it.
+type sharedPoolFile struct {
+	filesystem.FileReadWriter
+	deduplicator *poolBackedFileDeduplicator
+	digest       digest.Digest
+
+	// Protected by deduplicator.lock.
+	referenceCount uint
+}
+
+// unwrap the underlying file, so that it may be modified. This is only
+// possible if the caller holds the only reference to the file, in
+// which case the file is also no longer offered for deduplication.
+func (sf *sharedPoolFile) unwrap() (filesystem.FileReadWriter, bool) {
+	d := sf.deduplicator
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if sf.referenceCount != 1 {
+		return nil, false
+	}
+	sf.referenceCount = 0
+	sf.removeLocked()
+	return sf.FileReadWriter, true
+}
+
+func (sf *sharedPoolFile) removeLocked() {
+	d := sf.deduplicator
+	if d.files[sf.digest] == sf {
+		delete(d.files, sf.digest)
+	}
+}
+
+func (sf *sharedPoolFile) Close() error {
+	d := sf.deduplicator
+	d.lock.Lock()
+	if sf.referenceCount == 0 {
+		panic("Invalid reference count")
+	}
+	sf.referenceCount--
+	if sf.referenceCount > 0 {
+		d.lock.Unlock()
+		return nil
+	}
+	sf.removeLocked()
+	d.lock.Unlock()
+	return sf.FileReadWriter.Close()
+}
+
+// inputRecordingCASFileFactory is a decorator for CASFileFactory that
+// registers the digests of all files that are created through it with
+// a poolBackedFileDeduplicator.
+type inputRecordingCASFileFactory struct {
+	base         CASFileFactory
+	deduplicator *poolBackedFileDeduplicator
+}
+
+func (cff *inputRecordingCASFileFactory) LookupFile(blobDigest digest.Digest, isExecutable bool, readMonitor FileReadMonitor) NativeLeaf {
+	if blobDigest.GetSizeBytes() > 0 {
+		cff.deduplicator.addInputFile(blobDigest)
+	}
+	return cff.base.LookupFile(blobDigest, isExecutable, readMonitor)
+}
+
+// deduplicateLocked lets the file share its storage with other files
+// having the same digest. It is only safe to call this function while
+// the file is frozen, as the contents of the file must correspond with
+// the digest.
+func (f *fileBackedFile) deduplicateLocked(blobDigest digest.Digest) {
+	if f.deduplicator != nil && f.referenceCount > 0 && f.size > 0 {
+		f.file = f.deduplicator.deduplicate(f.file, blobDigest)
+		if _, ok := f.file.(*casBackedPoolFile); ok && f.cachedDigest == blobDigest {
+			// Input files are already present in the
+			// Content Addressable Storage.
+			f.cachedDigestUploaded = true
+		}
+	}
+}
+
+// deduplicateAfterCloseLocked lets the file share its storage with other
+// files after its last writable file descriptor has been closed. If
+// the file has only been written sequentially, its digest is known
+// and deduplication is performed immediately. If not, the digest is
+// computed in the background, provided that the maximum number of
+// files that are hashed concurrently has not been reached.
+func (f *fileBackedFile) deduplicateAfterCloseLocked() {
+	d := f.deduplicator
+	if d == nil || d.digestFunction == nil || f.size == 0 {
+		return
+	}
+	digestFunction := *d.digestFunction
+	if cachedDigest := f.cachedDigest; cachedDigest != digest.BadDigest && cachedDigest.UsesDigestFunction(digestFunction) {
+		// Digest was already computed, meaning the file has
+		// been deduplicated already.
+		return
+	}
+	if digestGenerator := f.appendDigestGenerator; digestGenerator != nil {
+		if newDigest := digestGenerator.Sum(); newDigest.UsesDigestFunction(digestFunction) {
+			f.cachedDigest = newDigest
+			f.cachedDigestUploaded = false
+			f.deduplicateLocked(newDigest)
+			return
+		}
+	}
+	// Bound the number of files that are hashed concurrently. If
+	// the limit is reached, the file is not deduplicated, as
+	// queueing it would hold on to the file for an unbounded
+	// amount of time.
+	if d.hashingSemaphore == nil || !d.hashingSemaphore.TryAcquire(1) {
+		poolBackedFileDeduplicatorBackgroundHashingSkipped.Inc()
+		return
+	}
+	go func() {
+		f.deduplicateInBackground(digestFunction)
+		d.hashingSemaphore.Release(1)
+	}()
+}
+
+// deduplicateInBackground computes the digest of a file that has been
+// written non-sequentially, so that it can be deduplicated while the
+// build action is still running.
+func (f *fileBackedFile) deduplicateInBackground(digestFunction digest.Function) {
+	hasWritableDescriptors, success := f.acquireFrozenDescriptor()
+	if !success {
+		return
+	}
+	defer f.releaseFrozenDescriptor()
+
+	// Don't hash files that have been reopened for writing in the
+	// meantime, as writes are likely to follow.
+	if hasWritableDescriptors {
+		return
+	}
+	if _, err := f.updateCachedDigest(digestFunction); err != nil {
+		f.errorLogger.Log(util.StatusWrap(err, "Failed to compute digest of file for deduplication"))
+	}
+}
+
+// shareLocked returns a handle to the storage of the file that may be
+// used by another file. The handle needs to be closed when no longer
+// used.
+func (f *fileBackedFile) shareLocked() *sharedPoolFile {
+	sf, ok := f.file.(*sharedPoolFile)
+	if !ok {
+		// Storage is not shared yet. Wrap the file without
+		// registering it under a digest, as its contents may not
+		// have been hashed.
+		d := f.deduplicator
+		if d == nil {
+			d = newPoolBackedFileDeduplicator(nil, nil, nil)
+		}
+		sf = &sharedPoolFile{
+			FileReadWriter: f.file,
+			deduplicator:   d,
+			digest:         digest.BadDigest,
+			referenceCount: 1,
+		}
+		f.file = sf
+	}
+	d := sf.deduplicator
+	d.lock.Lock()
+	sf.referenceCount++
+	d.lock.Unlock()
+	return sf
+}
+
+// unshareLocked ensures that the file no longer shares its storage
+// with other files, so that it may be modified. If the storage is
+// still in use by other files, the first retainedSizeBytes bytes of
+// the file are copied into a new file obtained from the FilePool.
+// This function needs to be called in operations that mutate f.file.
+func (f *fileBackedFile) unshareLocked(retainedSizeBytes uint64) Status {
+	f.preserveSnapshotVersionLocked()
+	switch file := f.file.(type) {
+	case *sharedPoolFile:
+		if unwrappedFile, ok := file.unwrap(); ok {
+			// The file may have been converted to a file
+			// backed by the Content Addressable Storage
+			// before it got shared.
+			f.file = unwrappedFile
+			return f.unshareLocked(retainedSizeBytes)
+		}
+	case *casBackedPoolFile:
+		// The file was converted to a file backed by the
+		// Content Addressable Storage. Copy its contents back
+		// into the FilePool.
+	default:
+		return StatusOK
+	}
+
+	newFile, err := f.pool.NewFile()
+	if err != nil {
+		return filePoolErrorToStatus(f.errorLogger, util.StatusWrap(err, "Failed to create new file for copying shared file contents"))
+	}
+	if err := copyFileContents(newFile, f.file, int64(retainedSizeBytes)); err != nil {
+		newFile.Close()
+		return filePoolErrorToStatus(f.errorLogger, util.StatusWrap(err, "Failed to copy shared file contents"))
+	}
+	f.file.Close()
+	f.file = newFile
+	return StatusOK
+}
+
+// cloneFrom replaces the contents of the file with the contents of
+// another file, by letting both files share their storage. This is
+// only performed if the file is not larger than the source file, as
+// copy_file_range() does not shrink the destination file.
+func (f *fileBackedFile) cloneFrom(source *fileBackedFile) (uint64, bool) {
+	source.lock.Lock()
+	if source.referenceCount == 0 {
+		source.lock.Unlock()
+		return 0, false
+	}
+	sf := source.shareLocked()
+	size := source.size
+	cachedDigest := source.cachedDigest
+	source.lock.Unlock()
+
+	f.lockMutatingData()
+	defer f.lock.Unlock()
+
+	if f.referenceCount == 0 || f.size > size {
+		sf.Close()
+		return 0, false
+	}
+	f.preserveSnapshotVersionLocked()
+	oldFile := f.file
+	f.file = sf
+	if err := oldFile.Close(); err != nil {
+		f.errorLogger.Log(util.StatusWrap(err, "Failed to close file after cloning"))
+	}
+	f.size = size
+	f.cachedDigest = cachedDigest
+	f.cachedDigestUploaded = false
+	f.appendDigestGenerator = nil
+	f.dataModifiedLocked()
+	return size, true
+}
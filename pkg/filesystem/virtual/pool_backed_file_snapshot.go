@@ -0,0 +1,108 @@
+package virtual
+
+import (
+	re_sync "github.com/buildbarn/bb-remote-execution/pkg/sync"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// snapshotLocked creates a new file that shares its storage with the
+// current file. As all operations that mutate the file call
+// unshareLocked(), the contents of the new file remain unaltered when
+// the current file is modified afterwards.
+func (f *fileBackedFile) snapshotLocked() *fileBackedFile {
+	snapshot := &fileBackedFile{
+		pool:         f.pool,
+		errorLogger:  f.errorLogger,
+		clock:        f.clock,
+		deduplicator: f.deduplicator,
+
+		lock:                     re_sync.RWMutex{Rank: &leafLockRank},
+		file:                     f.shareLocked(),
+		isExecutable:             f.isExecutable,
+		size:                     f.size,
+		lastDataModificationTime: f.lastDataModificationTime,
+		lastStatusChangeTime:     f.lastStatusChangeTime,
+		referenceCount:           1,
+		unfreezeWakeup:           make(chan struct{}),
+		cachedDigest:             f.cachedDigest,
+		cachedDigestUploaded:     f.cachedDigestUploaded,
+	}
+	snapshot.lastDataAccessTime.Store(f.lastDataAccessTime.Load())
+	return snapshot
+}
+
+func (f *fileBackedFile) setSnapshotEpochs(epochs *snapshotEpochs) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.snapshotEpochs = epochs
+}
+
+// preserveSnapshotVersionLocked retains the current contents of the
+// file on behalf of snapshots that have been created since the file
+// was last modified. It needs to be called before the file is
+// modified.
+func (f *fileBackedFile) preserveSnapshotVersionLocked() {
+	if epochs := f.snapshotEpochs; epochs != nil {
+		f.snapshotVersions.preserve(epochs, epochs.current.Load(), f, f.snapshotLocked)
+	}
+}
+
+func (f *fileBackedFile) pruneSnapshotVersions() {
+	f.lock.Lock()
+	pruned := f.snapshotVersions.prune(f.snapshotEpochs, f)
+	f.lock.Unlock()
+
+	for _, version := range pruned {
+		version.Unlink()
+	}
+}
+
+func (f *fileBackedFile) getSnapshotVersion(epochs *snapshotEpochs, epoch uint64) (NativeLeaf, error) {
+	f.lock.Lock()
+	if f.snapshotEpochs != epochs {
+		f.lock.Unlock()
+		return nil, status.Error(codes.InvalidArgument, "File is not part of the same subtree as the snapshot")
+	}
+	if version, ok := f.snapshotVersions.get(epoch); ok {
+		// The file was modified after the snapshot was
+		// created. The version is retained for as long as the
+		// snapshot exists.
+		f.lock.Unlock()
+		if version.Link() != StatusOK {
+			panic("Failed to obtain a reference to a file that is retained on behalf of a snapshot")
+		}
+		return version, nil
+	}
+	defer f.lock.Unlock()
+
+	// The file has not been modified since the snapshot was
+	// created. Return a copy that shares its storage, so that the
+	// file may continue to be modified while being uploaded.
+	if f.referenceCount == 0 {
+		return nil, status.Error(codes.NotFound, "File was unlinked before the snapshot could be read")
+	}
+	version := f.snapshotLocked()
+	version.snapshotOrigin = f
+	version.snapshotOriginChangeID = f.changeID
+	return version, nil
+}
+
+// reportDigestToSnapshotOrigin stores the digest of a file that was
+// uploaded as part of a snapshot in the file from which it was
+// created, if that file has not been modified in the meantime. This
+// prevents the file from being hashed and uploaded once again when
+// subsequent snapshots are uploaded.
+func (f *fileBackedFile) reportDigestToSnapshotOrigin(blobDigest digest.Digest) {
+	if origin := f.snapshotOrigin; origin != nil {
+		origin.lock.Lock()
+		if origin.referenceCount > 0 && origin.changeID == f.snapshotOriginChangeID {
+			origin.cachedDigest = blobDigest
+			origin.cachedDigestUploaded = true
+		}
+		origin.lock.Unlock()
+	}
+}
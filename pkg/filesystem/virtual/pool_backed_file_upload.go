@@ -0,0 +1,250 @@
+package virtual
+
+import (
+	"context"
+	"io"
+	"math"
+
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// updateCachedDigest returns the digest of the file. It either returns
+// a cached value, or computes the digest and caches it. It is only safe
+// to call this function while the file is frozen (i.e., calling
+// f.acquireFrozenDescriptor()).
+func (f *fileBackedFile) updateCachedDigest(digestFunction digest.Function) (digest.Digest, error) {
+	// Check whether the cached digest we have is still valid.
+	if cachedDigest := f.getCachedDigest(); cachedDigest != digest.BadDigest && cachedDigest.UsesDigestFunction(digestFunction) {
+		return cachedDigest, nil
+	}
+
+	// If the file has only been written sequentially, the digest
+	// can be obtained from the hash state that was maintained
+	// while writing.
+	f.lock.Lock()
+	if digestGenerator := f.appendDigestGenerator; digestGenerator != nil {
+		if newDigest := digestGenerator.Sum(); newDigest.UsesDigestFunction(digestFunction) {
+			f.cachedDigest = newDigest
+			f.cachedDigestUploaded = false
+			f.deduplicateLocked(newDigest)
+			f.lock.Unlock()
+			return newDigest, nil
+		}
+	}
+	sizeBytes := f.size
+	f.lock.Unlock()
+
+	// If not, compute a new digest. For large files, hash segments
+	// of the file concurrently if the digest function permits it.
+	// Otherwise, read the file in a separate goroutine, so that I/O
+	// against the file pool does not stall hashing.
+	var digestGenerator *digest.Generator
+	var newDigest digest.Digest
+	if sizeBytes >= pipelinedCopyMinimumSizeBytes && supportsParallelDigest(digestFunction) {
+		var err error
+		newDigest, err = computeDigestParallel(digestFunction, f, int64(sizeBytes))
+		if err != nil {
+			return digest.BadDigest, util.StatusWrapWithCode(err, codes.Internal, "Failed to compute file digest")
+		}
+	} else {
+		digestGenerator = digestFunction.NewGenerator(math.MaxInt64)
+		if sizeBytes >= pipelinedCopyMinimumSizeBytes {
+			if err := copyPipelined(digestGenerator, f); err != nil {
+				return digest.BadDigest, util.StatusWrapWithCode(err, codes.Internal, "Failed to compute file digest")
+			}
+		} else if _, err := io.Copy(digestGenerator, io.NewSectionReader(f, 0, math.MaxInt64)); err != nil {
+			return digest.BadDigest, util.StatusWrapWithCode(err, codes.Internal, "Failed to compute file digest")
+		}
+		newDigest = digestGenerator.Sum()
+	}
+
+	// Store the resulting cached digest. Retain the hash state if
+	// available, so that data appended to the file afterwards does
+	// not require the file to be rehashed from scratch.
+	f.lock.Lock()
+	f.cachedDigest = newDigest
+	f.cachedDigestUploaded = false
+	f.appendDigestGenerator = digestGenerator
+	f.deduplicateLocked(newDigest)
+	f.lock.Unlock()
+	return newDigest, nil
+}
+
+func (f *fileBackedFile) UploadFile(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function) (digest.Digest, error) {
+	// Create a file handle that temporarily freezes the contents of
+	// this file. This ensures that the file's contents don't change
+	// between the digest computation and upload phase. This allows
+	// us to safely use NewValidatedBufferFromFileReader().
+	hasWritableDescriptors, success := f.acquireFrozenDescriptor()
+	if !success {
+		return digest.BadDigest, status.Error(codes.NotFound, "File was unlinked before uploading could start")
+	}
+	if hasWritableDescriptors && f.hasPendingWrites() {
+		// Process table cleaning should have cleaned up any
+		// file descriptors belonging to files in the input
+		// root. Yet we are still seeing the file being opened
+		// for writing, without a write barrier having been
+		// applied. This is bad, as it means that data may
+		// still be present in the kernel's page cache.
+		poolBackedFileAllocatorUploadsWithWritableDescriptors.Inc()
+	}
+	return f.uploadFrozenFile(ctx, contentAddressableStorage, digestFunction)
+}
+
+// uploadFrozenFile uploads the contents of the file into the Content
+// Addressable Storage. The caller must have acquired a frozen
+// descriptor, which is released by this function.
+func (f *fileBackedFile) uploadFrozenFile(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function) (digest.Digest, error) {
+	blobDigest, err := f.updateCachedDigest(digestFunction)
+	if err != nil {
+		f.Close()
+		return digest.BadDigest, err
+	}
+
+	// Skip the upload if the file was already uploaded in the
+	// background, and has not been modified since.
+	f.lock.RLock()
+	alreadyUploaded := f.cachedDigestUploaded && f.cachedDigest == blobDigest
+	f.lock.RUnlock()
+	if alreadyUploaded {
+		f.Close()
+		f.reportDigestToSnapshotOrigin(blobDigest)
+		return blobDigest, nil
+	}
+
+	if err := contentAddressableStorage.Put(
+		ctx,
+		blobDigest,
+		buffer.NewValidatedBufferFromReaderAt(f, blobDigest.GetSizeBytes())); err != nil {
+		return digest.BadDigest, util.StatusWrap(err, "Failed to upload file")
+	}
+	f.reportDigestToSnapshotOrigin(blobDigest)
+	return blobDigest, nil
+}
+
+// isReferenced returns whether the file is still linked into a
+// directory or opened.
+func (f *fileBackedFile) isReferenced() bool {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.referenceCount > 0
+}
+
+// uploadInBackground uploads the contents of the file into the Content
+// Addressable Storage after its last writable file descriptor has been
+// closed. Errors are discarded, as the file will be uploaded once
+// again when UploadFile() is called.
+//
+// Instead of freezing the file for the duration of the upload, a
+// copy-on-write snapshot of the file is uploaded. This ensures that
+// the build action is not blocked when it modifies the file while the
+// upload is in progress.
+func (f *fileBackedFile) uploadInBackground(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function) {
+	f.lock.Lock()
+	if f.referenceCount == 0 || f.writableDescriptorsCount > 0 {
+		// File got unlinked or reopened for writing in the
+		// meantime. In the latter case it will be considered
+		// once again after it gets closed.
+		f.lock.Unlock()
+		return
+	}
+	if f.cachedDigestUploaded {
+		f.lock.Unlock()
+		return
+	}
+	changeID := f.changeID
+	blobDigest := digest.BadDigest
+	if f.cachedDigest != digest.BadDigest && f.cachedDigest.UsesDigestFunction(digestFunction) {
+		blobDigest = f.cachedDigest
+	} else if digestGenerator := f.appendDigestGenerator; digestGenerator != nil {
+		if newDigest := digestGenerator.Sum(); newDigest.UsesDigestFunction(digestFunction) {
+			blobDigest = newDigest
+		}
+	}
+	snapshot := f.snapshotLocked()
+	f.lock.Unlock()
+	defer snapshot.Unlink()
+
+	if blobDigest == digest.BadDigest {
+		digestGenerator := digestFunction.NewGenerator(math.MaxInt64)
+		if _, err := io.Copy(digestGenerator, io.NewSectionReader(snapshot, 0, int64(snapshot.size))); err != nil {
+			poolBackedFileAllocatorBackgroundUploadsFailed.Inc()
+			return
+		}
+		blobDigest = digestGenerator.Sum()
+	}
+
+	// The buffer releases a frozen descriptor when closed.
+	snapshot.acquireFrozenDescriptor()
+	if err := contentAddressableStorage.Put(
+		ctx,
+		blobDigest,
+		buffer.NewValidatedBufferFromReaderAt(snapshot, blobDigest.GetSizeBytes())); err != nil {
+		poolBackedFileAllocatorBackgroundUploadsFailed.Inc()
+		return
+	}
+	poolBackedFileAllocatorBackgroundUploadsSucceeded.Inc()
+
+	// Only record that the file has been uploaded if it has not
+	// been modified while the upload was in progress.
+	f.lock.Lock()
+	if f.referenceCount > 0 && f.changeID == changeID {
+		f.cachedDigest = blobDigest
+		f.cachedDigestUploaded = true
+	}
+	f.lock.Unlock()
+}
+
+func (f *fileBackedFile) ConvertToCASFile(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function, casFileFactory CASFileFactory) error {
+	// Keep the file frozen for the duration of the conversion. This
+	// ensures that the contents of the file don't change between
+	// the upload and the point where the file's storage is
+	// replaced.
+	hasWritableDescriptors, success := f.acquireFrozenDescriptor()
+	if !success {
+		return status.Error(codes.NotFound, "File was unlinked before conversion could start")
+	}
+	defer f.releaseFrozenDescriptor()
+
+	// Don't convert files that are opened for writing, as writes
+	// are likely to follow. Also skip files that have already
+	// been converted, or don't use any space.
+	f.lock.RLock()
+	_, isConverted := f.file.(*casBackedPoolFile)
+	isEmpty := f.size == 0
+	f.lock.RUnlock()
+	if hasWritableDescriptors || isConverted || isEmpty {
+		return nil
+	}
+
+	blobDigest, err := f.UploadFile(ctx, contentAddressableStorage, digestFunction)
+	if err != nil {
+		return err
+	}
+
+	// Replace the storage of the file while it is still frozen.
+	// The identity of the file remains unchanged, meaning that
+	// the kernel can continue to use it. Any subsequent write
+	// causes the contents to be copied back into the FilePool by
+	// unshareLocked(). Even though the file may have been reopened
+	// for writing during the upload, it has not been written to.
+	newFile := newCASBackedPoolFile(casFileFactory.LookupFile(blobDigest, false, nil), blobDigest)
+	f.lock.Lock()
+	oldFile := f.file
+	f.file = newFile
+	if f.cachedDigest == blobDigest {
+		f.cachedDigestUploaded = true
+	}
+	f.lock.Unlock()
+
+	if err := oldFile.Close(); err != nil {
+		f.errorLogger.Log(util.StatusWrap(err, "Failed to close file after converting it"))
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package virtual
+
+// hasPendingWritesLocked returns whether the kernel may still hold on
+// to data that needs to be written to the file. This is the case if
+// the file is opened for writing, and no write barrier has been applied
+// since the file was last modified.
+func (f *fileBackedFile) hasPendingWritesLocked() bool {
+	return f.writableDescriptorsCount > 0 && (!f.hasWriteBarrier || f.writeBarrierChangeID != f.changeID)
+}
+
+func (f *fileBackedFile) hasPendingWrites() bool {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.hasPendingWritesLocked()
+}
+
+func (f *fileBackedFile) applyWriteBarrier() Status {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.referenceCount == 0 {
+		return StatusErrStale
+	}
+	f.hasWriteBarrier = true
+	f.writeBarrierChangeID = f.changeID
+	return StatusOK
+}
+
+func (f *fileBackedFile) revokeWriteBarrier() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.hasWriteBarrier = false
+	return f.writableDescriptorsCount > 0
+}
+
+func (f *fileBackedFile) getWritableDescriptorsClosedChannel() <-chan struct{} {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.writableDescriptorsCount == 0 {
+		return nil
+	}
+	if f.writableDescriptorsDone == nil {
+		f.writableDescriptorsDone = make(chan struct{})
+	}
+	return f.writableDescriptorsDone
+}
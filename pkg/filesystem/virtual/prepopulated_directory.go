@@ -1,6 +1,10 @@
 package virtual
 
 import (
+	"context"
+
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/digest"
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
 	"github.com/buildbarn/bb-storage/pkg/util"
@@ -24,6 +28,13 @@ type ChildRemover func() error
 // should continue. When false, traversal will stop immediately.
 type ChildFilter func(node InitialNode, remove ChildRemover) bool
 
+// LeafFlushFilter is a callback that is invoked by
+// PrepopulatedDirectory.FlushFiles() for each of the leaves underneath
+// the current directory hierarchy. The boolean return value of this
+// function signals whether the leaf should be converted to a file
+// backed by the Content Addressable Storage.
+type LeafFlushFilter func(leaf NativeLeaf) bool
+
 // DirectoryPrepopulatedDirEntry contains information about a directory
 // node that is stored in a PrepopulatedDirectory.
 type DirectoryPrepopulatedDirEntry struct {
@@ -92,12 +103,15 @@ type PrepopulatedDirectory interface {
 	// released.
 	RemoveAllChildren(forbidNewChildren bool) error
 	// InstallHooks sets up hooks for creating files and logging
-	// errors that occur under the directory subtree.
+	// errors that occur under the directory subtree. If a
+	// StatFSProvider is provided, it is used to report the
+	// capacity of the file system when queried through directories
+	// in the subtree.
 	//
 	// This function is identical to BuildDirectory.InstallHooks(),
 	// except that it uses the FUSE specific FileAllocator instead
 	// of FilePool.
-	InstallHooks(fileAllocator FileAllocator, errorLogger util.ErrorLogger)
+	InstallHooks(fileAllocator FileAllocator, errorLogger util.ErrorLogger, statFSProvider StatFSProvider)
 	// FilterChildren() can be used to traverse over all of the
 	// InitialContentsFetcher and NativeLeaf objects stored in this
 	// directory hierarchy. For each of the objects, a callback is
@@ -108,6 +122,33 @@ type PrepopulatedDirectory interface {
 	// directories that are no longer present in the Content
 	// Addressable Storage at the start of the build.
 	FilterChildren(childFilter ChildFilter) error
+	// FlushFiles() uploads the contents of mutable files stored in
+	// this directory hierarchy (e.g., ones backed by a FilePool) to
+	// the Content Addressable Storage, and lets them read their
+	// contents from the Content Addressable Storage from that point
+	// on. Only files for which the provided filter returns true are
+	// considered. Files that are opened for writing are left
+	// untouched. Files that are written to after being flushed
+	// have their contents copied back into the FilePool.
+	//
+	// This function can be used to reclaim space in the FilePool
+	// on demand, without waiting for the build to be finalized.
+	FlushFiles(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function, casFileFactory CASFileFactory, leafFilter LeafFlushFilter) error
+	// CreateSnapshot() creates an immutable copy of the directory
+	// hierarchy. Creating a snapshot takes constant time, as no
+	// state is copied up front. Instead, directories and files
+	// retain their existing state the first time they are modified
+	// after the snapshot was created. Files retain their contents
+	// by sharing their storage with the snapshot (i.e.,
+	// copy-on-write). Hidden files are not part of the snapshot.
+	//
+	// A snapshot may not contain directories on which hooks were
+	// installed separately.
+	//
+	// This function can be used to capture large output trees, so
+	// that they can be uploaded to the Content Addressable Storage
+	// while the original directory hierarchy is reused.
+	CreateSnapshot() (*DirectorySnapshot, error)
 
 	// Functions inherited from filesystem.Directory.
 	ReadDir() ([]filesystem.FileInfo, error)
@@ -0,0 +1,122 @@
+package virtual
+
+import (
+	"context"
+	"time"
+
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/util"
+)
+
+// quiescentFileState contains the state that QuiescentFileConverter
+// tracks for every mutable file it has observed.
+type quiescentFileState struct {
+	changeID       uint64
+	unchangedSince time.Time
+}
+
+// QuiescentFileConverter is a janitor that periodically traverses a
+// PrepopulatedDirectory, searching for mutable files (e.g., ones backed
+// by a FilePool) that have not been changed for a configurable amount
+// of time. The contents of these files are uploaded to the Content
+// Addressable Storage, after which the files read their contents from
+// the Content Addressable Storage. This allows space in the FilePool to
+// be reclaimed for directory hierarchies that are long lived.
+//
+// Files are considered to be unchanged if their change ID remains
+// identical between successive traversals. Files that are opened for
+// writing are never converted. As files are converted in place, they
+// may be reopened for writing afterwards. This causes their contents to
+// be copied back into the FilePool.
+type QuiescentFileConverter struct {
+	directory                 PrepopulatedDirectory
+	contentAddressableStorage blobstore.BlobAccess
+	digestFunction            digest.Function
+	casFileFactory            CASFileFactory
+	clock                     clock.Clock
+	quiescencePeriod          time.Duration
+	errorLogger               util.ErrorLogger
+
+	files map[NativeLeaf]quiescentFileState
+}
+
+// NewQuiescentFileConverter creates a new QuiescentFileConverter that
+// converts files stored in the provided directory hierarchy. Traversal
+// only starts when Run() or Sweep() is called.
+func NewQuiescentFileConverter(directory PrepopulatedDirectory, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function, casFileFactory CASFileFactory, clock clock.Clock, quiescencePeriod time.Duration, errorLogger util.ErrorLogger) *QuiescentFileConverter {
+	return &QuiescentFileConverter{
+		directory:                 directory,
+		contentAddressableStorage: contentAddressableStorage,
+		digestFunction:            digestFunction,
+		casFileFactory:            casFileFactory,
+		clock:                     clock,
+		quiescencePeriod:          quiescencePeriod,
+		errorLogger:               errorLogger,
+
+		files: map[NativeLeaf]quiescentFileState{},
+	}
+}
+
+// Sweep performs a single traversal over the directory hierarchy,
+// converting all files that have remained unchanged for at least the
+// configured quiescence period.
+//
+// This function is not thread-safe. It should not be called while
+// Run() is running.
+func (c *QuiescentFileConverter) Sweep(ctx context.Context) error {
+	now := c.clock.Now()
+	oldFiles := c.files
+	newFiles := map[NativeLeaf]quiescentFileState{}
+	err := c.directory.FlushFiles(
+		ctx,
+		c.contentAddressableStorage,
+		c.digestFunction,
+		c.casFileFactory,
+		func(leaf NativeLeaf) bool {
+			var attributes Attributes
+			leaf.VirtualGetAttributes(ctx, AttributesMaskChangeID|AttributesMaskFileType|AttributesMaskIsImmutable, &attributes)
+			if attributes.GetFileType() != filesystem.FileTypeRegularFile || attributes.GetIsImmutable() {
+				return false
+			}
+
+			// Only convert the file if it has not been
+			// changed since it was observed previously.
+			changeID := attributes.GetChangeID()
+			if oldState, ok := oldFiles[leaf]; ok && oldState.changeID == changeID {
+				newFiles[leaf] = oldState
+				return !now.Before(oldState.unchangedSince.Add(c.quiescencePeriod))
+			}
+			newFiles[leaf] = quiescentFileState{
+				changeID:       changeID,
+				unchangedSince: now,
+			}
+			return false
+		})
+
+	// Only retain state of files that were observed during this
+	// traversal, so that state of removed files is discarded.
+	c.files = newFiles
+	return err
+}
+
+// Run the QuiescentFileConverter, calling Sweep() periodically until
+// the provided context is cancelled. Errors are reported through the
+// ErrorLogger.
+func (c *QuiescentFileConverter) Run(ctx context.Context) {
+	for {
+		timer, timerChannel := c.clock.NewTimer(c.quiescencePeriod)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timerChannel:
+		}
+
+		if err := c.Sweep(ctx); err != nil {
+			c.errorLogger.Log(util.StatusWrap(err, "Failed to convert quiescent files"))
+		}
+	}
+}
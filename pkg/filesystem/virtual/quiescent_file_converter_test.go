@@ -0,0 +1,82 @@
+package virtual_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuiescentFileConverterSweep(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	directory := mock.NewMockPrepopulatedDirectory(ctrl)
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	digestFunction := digest.MustNewFunction("", remoteexecution.DigestFunction_SHA256)
+	casFileFactory := mock.NewMockCASFileFactory(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	converter := virtual.NewQuiescentFileConverter(directory, contentAddressableStorage, digestFunction, casFileFactory, clock, time.Minute, errorLogger)
+
+	mutableFile := mock.NewMockNativeLeaf(ctrl)
+	immutableFile := mock.NewMockNativeLeaf(ctrl)
+	symlink := mock.NewMockNativeLeaf(ctrl)
+	expectSweep := func(now int64, changeID uint64, expectedConversion bool) {
+		clock.EXPECT().Now().Return(time.Unix(now, 0))
+		directory.EXPECT().FlushFiles(ctx, contentAddressableStorage, digestFunction, casFileFactory, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, contentAddressableStorage blobstore.BlobAccess, digestFunction digest.Function, casFileFactory virtual.CASFileFactory, leafFilter virtual.LeafFlushFilter) error {
+				mutableFile.EXPECT().VirtualGetAttributes(ctx, gomock.Any(), gomock.Any()).
+					Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+						attributes.SetChangeID(changeID)
+						attributes.SetFileType(filesystem.FileTypeRegularFile)
+					})
+				require.Equal(t, expectedConversion, leafFilter(mutableFile))
+
+				// Immutable files and symbolic links
+				// should never be converted.
+				immutableFile.EXPECT().VirtualGetAttributes(ctx, gomock.Any(), gomock.Any()).
+					Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+						attributes.SetChangeID(0)
+						attributes.SetFileType(filesystem.FileTypeRegularFile)
+						attributes.SetIsImmutable(true)
+					})
+				require.False(t, leafFilter(immutableFile))
+				symlink.EXPECT().VirtualGetAttributes(ctx, gomock.Any(), gomock.Any()).
+					Do(func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+						attributes.SetChangeID(0)
+						attributes.SetFileType(filesystem.FileTypeSymlink)
+					})
+				require.False(t, leafFilter(symlink))
+				return nil
+			})
+	}
+
+	// The first time a file is observed, it should not be
+	// converted, as it is unknown how long it has been unchanged.
+	expectSweep(1000, 5, false)
+	require.NoError(t, converter.Sweep(ctx))
+
+	// The file should not be converted until the quiescence period
+	// has passed.
+	expectSweep(1030, 5, false)
+	require.NoError(t, converter.Sweep(ctx))
+
+	// Changing the file should reset the quiescence period.
+	expectSweep(1060, 6, false)
+	require.NoError(t, converter.Sweep(ctx))
+	expectSweep(1090, 6, false)
+	require.NoError(t, converter.Sweep(ctx))
+
+	// Once the file has remained unchanged for the full quiescence
+	// period, it should be converted.
+	expectSweep(1120, 6, true)
+	require.NoError(t, converter.Sweep(ctx))
+}
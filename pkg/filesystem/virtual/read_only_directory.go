@@ -17,6 +17,12 @@ func (ReadOnlyDirectory) VirtualLink(ctx context.Context, name path.Component, l
 	return ChangeInfo{}, StatusErrROFS
 }
 
+// VirtualOpenTemporaryFile is an implementation of open() with
+// O_TMPFILE that treats the target directory as being read-only.
+func (ReadOnlyDirectory) VirtualOpenTemporaryFile(ctx context.Context, shareAccess ShareMask, createAttributes *Attributes, requested AttributesMask, openedFileAttributes *Attributes) (Leaf, AttributesMask, Status) {
+	return nil, 0, StatusErrROFS
+}
+
 // VirtualMkdir is an implementation of the mkdir() system call that
 // treats the target directory as being read-only.
 func (ReadOnlyDirectory) VirtualMkdir(name path.Component, requested AttributesMask, out *Attributes) (Directory, ChangeInfo, Status) {
@@ -48,6 +54,30 @@ func (ReadOnlyDirectory) VirtualSetAttributes(ctx context.Context, in *Attribute
 	return StatusErrROFS
 }
 
+// VirtualGetXAttr is an implementation of the getxattr() system call
+// for read-only directories, which don't have any extended attributes.
+func (ReadOnlyDirectory) VirtualGetXAttr(ctx context.Context, name string) ([]byte, Status) {
+	return nil, StatusErrNoXAttr
+}
+
+// VirtualListXAttr is an implementation of the listxattr() system call
+// for read-only directories, which don't have any extended attributes.
+func (ReadOnlyDirectory) VirtualListXAttr(ctx context.Context) ([]string, Status) {
+	return nil, StatusOK
+}
+
+// VirtualRemoveXAttr is an implementation of the removexattr() system
+// call that treats the target directory as being read-only.
+func (ReadOnlyDirectory) VirtualRemoveXAttr(ctx context.Context, name string) Status {
+	return StatusErrROFS
+}
+
+// VirtualSetXAttr is an implementation of the setxattr() system call
+// that treats the target directory as being read-only.
+func (ReadOnlyDirectory) VirtualSetXAttr(ctx context.Context, name string, value []byte, mode XAttrSetMode) Status {
+	return StatusErrROFS
+}
+
 // VirtualSymlink is an implementation of the symlink() system call that
 // treats the target directory as being read-only.
 func (ReadOnlyDirectory) VirtualSymlink(ctx context.Context, pointedTo []byte, linkName path.Component, requested AttributesMask, out *Attributes) (Leaf, ChangeInfo, Status) {
@@ -0,0 +1,217 @@
+package virtual
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ReferenceCountLeakDetectingFileAllocator is a FileAllocator that is
+// capable of reporting files that have been removed from the file
+// system, but still have references to them.
+type ReferenceCountLeakDetectingFileAllocator interface {
+	FileAllocator
+
+	// ReportLeaks() logs an error for every file that was unlinked
+	// a long time ago, but still has one or more open file
+	// descriptors.
+	ReportLeaks()
+}
+
+type referenceCountLeakDetectingFileAllocator struct {
+	base           FileAllocator
+	clock          clock.Clock
+	errorLogger    util.ErrorLogger
+	minimumLeakAge time.Duration
+
+	lock   sync.Mutex
+	leaves map[*referenceCountLeakDetectingLeaf]struct{}
+}
+
+// NewReferenceCountLeakDetectingFileAllocator creates a decorator for
+// FileAllocator that tracks the references to files that are created.
+// For every link and file descriptor, it records the stack trace of
+// the code that created it.
+//
+// Files whose link count dropped to zero more than minimumLeakAge ago,
+// but still have open file descriptors, are likely leaked. This is
+// problematic, as these files continue to consume space in the
+// FilePool. ReportLeaks() can be called periodically to log the stack
+// traces of the code that acquired the references that are still
+// outstanding.
+//
+// As capturing stack traces is expensive, this decorator should only
+// be enabled while debugging.
+func NewReferenceCountLeakDetectingFileAllocator(base FileAllocator, clock clock.Clock, errorLogger util.ErrorLogger, minimumLeakAge time.Duration) ReferenceCountLeakDetectingFileAllocator {
+	return &referenceCountLeakDetectingFileAllocator{
+		base:           base,
+		clock:          clock,
+		errorLogger:    errorLogger,
+		minimumLeakAge: minimumLeakAge,
+		leaves:         map[*referenceCountLeakDetectingLeaf]struct{}{},
+	}
+}
+
+func (fa *referenceCountLeakDetectingFileAllocator) NewFile(isExecutable bool, size uint64, shareAccess ShareMask) (NativeLeaf, Status) {
+	leaf, s := fa.base.NewFile(isExecutable, size, shareAccess)
+	if s != StatusOK {
+		return nil, s
+	}
+
+	stack := string(debug.Stack())
+	l := &referenceCountLeakDetectingLeaf{
+		NativeLeaf: leaf,
+		allocator:  fa,
+		links:      []string{stack},
+	}
+	if shareAccess != 0 {
+		l.descriptors = []referenceCountLeakDetectingDescriptor{{
+			shareAccess: shareAccess,
+			stack:       stack,
+		}}
+	}
+
+	fa.lock.Lock()
+	fa.leaves[l] = struct{}{}
+	fa.lock.Unlock()
+	return l, StatusOK
+}
+
+func (fa *referenceCountLeakDetectingFileAllocator) ReportLeaks() {
+	now := fa.clock.Now()
+	var leaks []error
+
+	fa.lock.Lock()
+	for l := range fa.leaves {
+		if len(l.links) == 0 && !l.reported {
+			if age := now.Sub(l.unlinkedAt); age >= fa.minimumLeakAge {
+				var sb strings.Builder
+				for i, descriptor := range l.descriptors {
+					fmt.Fprintf(&sb, "\n\nDescriptor %d with share access %#x was opened at:\n%s", i, descriptor.shareAccess, descriptor.stack)
+				}
+				leaks = append(leaks, status.Errorf(
+					codes.Internal,
+					"File was unlinked %s ago, but still has %d open descriptors:%s",
+					age,
+					len(l.descriptors),
+					sb.String()))
+				l.reported = true
+			}
+		}
+	}
+	fa.lock.Unlock()
+
+	// Log errors without holding the lock, as the error logger may
+	// block.
+	for _, err := range leaks {
+		fa.errorLogger.Log(err)
+	}
+}
+
+// referenceCountLeakDetectingDescriptor contains the stack trace of
+// the code that opened a file descriptor.
+type referenceCountLeakDetectingDescriptor struct {
+	shareAccess ShareMask
+	stack       string
+}
+
+type referenceCountLeakDetectingLeaf struct {
+	NativeLeaf
+	allocator *referenceCountLeakDetectingFileAllocator
+
+	// Fields protected by allocator.lock.
+	links       []string
+	descriptors []referenceCountLeakDetectingDescriptor
+	unlinkedAt  time.Time
+	reported    bool
+}
+
+func (l *referenceCountLeakDetectingLeaf) unwrapLeaf() Leaf {
+	return l.NativeLeaf
+}
+
+// removeIfReleasedLocked stops tracking the file if all references to
+// it have been released.
+func (l *referenceCountLeakDetectingLeaf) removeIfReleasedLocked() {
+	if len(l.links) == 0 && len(l.descriptors) == 0 {
+		delete(l.allocator.leaves, l)
+	}
+}
+
+func (l *referenceCountLeakDetectingLeaf) Link() Status {
+	if s := l.NativeLeaf.Link(); s != StatusOK {
+		return s
+	}
+
+	stack := string(debug.Stack())
+	fa := l.allocator
+	fa.lock.Lock()
+	l.links = append(l.links, stack)
+	fa.lock.Unlock()
+	return StatusOK
+}
+
+func (l *referenceCountLeakDetectingLeaf) Unlink() {
+	l.NativeLeaf.Unlink()
+
+	fa := l.allocator
+	fa.lock.Lock()
+	if len(l.links) == 0 {
+		fa.lock.Unlock()
+		panic("Invalid link count")
+	}
+	// It is not possible to determine which link is removed, so
+	// simply discard the stack trace of the oldest one.
+	l.links = l.links[1:]
+	if len(l.links) == 0 {
+		l.unlinkedAt = fa.clock.Now()
+		l.removeIfReleasedLocked()
+	}
+	fa.lock.Unlock()
+}
+
+func (l *referenceCountLeakDetectingLeaf) VirtualOpenSelf(ctx context.Context, shareAccess ShareMask, options *OpenExistingOptions, requested AttributesMask, attributes *Attributes) Status {
+	if s := l.NativeLeaf.VirtualOpenSelf(ctx, shareAccess, options, requested, attributes); s != StatusOK {
+		return s
+	}
+
+	if shareAccess != 0 {
+		stack := string(debug.Stack())
+		fa := l.allocator
+		fa.lock.Lock()
+		l.descriptors = append(l.descriptors, referenceCountLeakDetectingDescriptor{
+			shareAccess: shareAccess,
+			stack:       stack,
+		})
+		fa.lock.Unlock()
+	}
+	return StatusOK
+}
+
+func (l *referenceCountLeakDetectingLeaf) VirtualClose(shareAccess ShareMask) {
+	l.NativeLeaf.VirtualClose(shareAccess)
+
+	if shareAccess != 0 {
+		fa := l.allocator
+		fa.lock.Lock()
+		// Discard the stack trace of the oldest descriptor that
+		// was opened with the same share access mask.
+		for i, descriptor := range l.descriptors {
+			if descriptor.shareAccess == shareAccess {
+				l.descriptors = append(l.descriptors[:i], l.descriptors[i+1:]...)
+				break
+			}
+		}
+		l.removeIfReleasedLocked()
+		fa.lock.Unlock()
+	}
+}
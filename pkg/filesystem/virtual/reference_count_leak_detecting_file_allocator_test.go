@@ -0,0 +1,81 @@
+package virtual_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReferenceCountLeakDetectingFileAllocator(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseFileAllocator := mock.NewMockFileAllocator(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	errorLogger := mock.NewMockErrorLogger(ctrl)
+	fileAllocator := virtual.NewReferenceCountLeakDetectingFileAllocator(baseFileAllocator, clock, errorLogger, time.Minute)
+
+	t.Run("FullyReleased", func(t *testing.T) {
+		// Files whose references are all released should not be
+		// reported, regardless of how long ago that happened.
+		baseLeaf := mock.NewMockNativeLeaf(ctrl)
+		baseFileAllocator.EXPECT().NewFile(false, uint64(0), virtual.ShareMaskWrite).Return(baseLeaf, virtual.StatusOK)
+		leaf, s := fileAllocator.NewFile(false, 0, virtual.ShareMaskWrite)
+		require.Equal(t, virtual.StatusOK, s)
+
+		baseLeaf.EXPECT().Unlink()
+		clock.EXPECT().Now().Return(time.Unix(1000, 0))
+		leaf.Unlink()
+		baseLeaf.EXPECT().VirtualClose(virtual.ShareMaskWrite)
+		leaf.VirtualClose(virtual.ShareMaskWrite)
+
+		clock.EXPECT().Now().Return(time.Unix(2000, 0))
+		fileAllocator.ReportLeaks()
+	})
+
+	t.Run("Leaked", func(t *testing.T) {
+		baseLeaf := mock.NewMockNativeLeaf(ctrl)
+		baseFileAllocator.EXPECT().NewFile(false, uint64(0), virtual.ShareMask(0)).Return(baseLeaf, virtual.StatusOK)
+		leaf, s := fileAllocator.NewFile(false, 0, 0)
+		require.Equal(t, virtual.StatusOK, s)
+
+		// Open the file twice, but only close it once.
+		baseLeaf.EXPECT().VirtualOpenSelf(ctx, virtual.ShareMaskRead, &virtual.OpenExistingOptions{}, virtual.AttributesMask(0), gomock.Any()).
+			Return(virtual.StatusOK).
+			Times(2)
+		var attributes virtual.Attributes
+		require.Equal(t, virtual.StatusOK, leaf.VirtualOpenSelf(ctx, virtual.ShareMaskRead, &virtual.OpenExistingOptions{}, 0, &attributes))
+		require.Equal(t, virtual.StatusOK, leaf.VirtualOpenSelf(ctx, virtual.ShareMaskRead, &virtual.OpenExistingOptions{}, 0, &attributes))
+		baseLeaf.EXPECT().VirtualClose(virtual.ShareMaskRead)
+		leaf.VirtualClose(virtual.ShareMaskRead)
+
+		baseLeaf.EXPECT().Unlink()
+		clock.EXPECT().Now().Return(time.Unix(3000, 0))
+		leaf.Unlink()
+
+		// The file should not be reported if it was only
+		// unlinked recently.
+		clock.EXPECT().Now().Return(time.Unix(3030, 0))
+		fileAllocator.ReportLeaks()
+
+		// Once sufficient time has passed, it should be
+		// reported exactly once.
+		clock.EXPECT().Now().Return(time.Unix(3060, 0))
+		errorLogger.EXPECT().Log(gomock.Any()).Do(func(err error) {
+			require.Equal(t, codes.Internal, status.Code(err))
+			require.Contains(t, status.Convert(err).Message(), "File was unlinked 1m0s ago, but still has 1 open descriptors:")
+			require.Contains(t, status.Convert(err).Message(), "TestReferenceCountLeakDetectingFileAllocator")
+		})
+		fileAllocator.ReportLeaks()
+
+		clock.EXPECT().Now().Return(time.Unix(3120, 0))
+		fileAllocator.ReportLeaks()
+	})
+}
@@ -0,0 +1,225 @@
+package smb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// Identifiers of ciphers that may be used to encrypt messages.
+//
+// Reference: [MS-SMB2] section 2.2.3.1.2.
+const (
+	cipherAES128CCM = 0x0001
+	cipherAES128GCM = 0x0002
+)
+
+// deriveKey derives a 128-bit key from the session key, using the
+// SP800-108 key derivation function in counter mode with HMAC-SHA256
+// as the pseudorandom function.
+//
+// Reference: [MS-SMB2] section 3.1.4.2.
+func deriveKey(sessionKey []byte, label string, context []byte) []byte {
+	h := hmac.New(sha256.New, sessionKey)
+	h.Write([]byte{0, 0, 0, 1})
+	h.Write([]byte(label))
+	h.Write([]byte{0})
+	h.Write(context)
+	h.Write([]byte{0, 0, 0, 128})
+	return h.Sum(nil)[:16]
+}
+
+// computeAESCMAC computes the AES-CMAC of a message, as described in
+// RFC 4493. It is used by SMB 3.x to sign messages.
+func computeAESCMAC(key, message []byte) []byte {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+
+	// Generate the subkeys that are used to process the last block.
+	var k1, k2 [aes.BlockSize]byte
+	block.Encrypt(k1[:], k1[:])
+	doubleCMACSubkey(&k1)
+	k2 = k1
+	doubleCMACSubkey(&k2)
+
+	// Process all blocks except the last one. The last block is
+	// combined with a subkey, depending on whether it is complete.
+	var state [aes.BlockSize]byte
+	for len(message) > aes.BlockSize {
+		subtle.XORBytes(state[:], state[:], message[:aes.BlockSize])
+		block.Encrypt(state[:], state[:])
+		message = message[aes.BlockSize:]
+	}
+	if len(message) == aes.BlockSize {
+		subtle.XORBytes(state[:], state[:], message)
+		subtle.XORBytes(state[:], state[:], k1[:])
+	} else {
+		var last [aes.BlockSize]byte
+		copy(last[:], message)
+		last[len(message)] = 0x80
+		subtle.XORBytes(state[:], state[:], last[:])
+		subtle.XORBytes(state[:], state[:], k2[:])
+	}
+	block.Encrypt(state[:], state[:])
+	return state[:]
+}
+
+// doubleCMACSubkey multiplies a value by two in GF(2^128), which is
+// used to generate the subkeys of AES-CMAC.
+func doubleCMACSubkey(k *[aes.BlockSize]byte) {
+	carry := k[0] >> 7
+	for i := 0; i < aes.BlockSize-1; i++ {
+		k[i] = k[i]<<1 | k[i+1]>>7
+	}
+	k[aes.BlockSize-1] = k[aes.BlockSize-1]<<1 ^ carry*0x87
+}
+
+// ccm is an implementation of cipher.AEAD for AES in Counter with
+// CBC-MAC (CCM) mode, as described in RFC 3610. The Go standard
+// library only provides an implementation of GCM, while SMB 3.0 and
+// 3.0.2 only support CCM.
+type ccm struct {
+	block     cipher.Block
+	nonceSize int
+	tagSize   int
+}
+
+var errCCMOpen = errors.New("message authentication failed")
+
+// newCCM creates an AEAD that uses AES in CCM mode, using a given
+// nonce and tag size.
+func newCCM(block cipher.Block, nonceSize, tagSize int) cipher.AEAD {
+	if nonceSize < 7 || nonceSize > 13 || tagSize < 4 || tagSize > 16 || tagSize%2 != 0 {
+		panic("Invalid CCM parameters")
+	}
+	return &ccm{
+		block:     block,
+		nonceSize: nonceSize,
+		tagSize:   tagSize,
+	}
+}
+
+func (c *ccm) NonceSize() int {
+	return c.nonceSize
+}
+
+func (c *ccm) Overhead() int {
+	return c.tagSize
+}
+
+// getCounterBlock returns counter block A_i, which is used to generate
+// the key stream and to encrypt the authentication tag.
+func (c *ccm) getCounterBlock(nonce []byte, i uint64) [aes.BlockSize]byte {
+	var a [aes.BlockSize]byte
+	a[0] = byte(aes.BlockSize - 2 - c.nonceSize)
+	copy(a[1:], nonce)
+	for j := aes.BlockSize - 1; j > c.nonceSize; j-- {
+		a[j] = byte(i)
+		i >>= 8
+	}
+	return a
+}
+
+// computeTag computes the encrypted authentication tag of a message.
+func (c *ccm) computeTag(nonce, plaintext, additionalData []byte) []byte {
+	// Compute the CBC-MAC over block B_0, the additional data and
+	// the plaintext, each padded to the block size.
+	var state [aes.BlockSize]byte
+	lengthSizeBytes := aes.BlockSize - 1 - c.nonceSize
+	state[0] = byte((c.tagSize-2)/2<<3 | (lengthSizeBytes - 1))
+	if len(additionalData) > 0 {
+		state[0] |= 0x40
+	}
+	copy(state[1:], nonce)
+	length := uint64(len(plaintext))
+	for j := aes.BlockSize - 1; j > c.nonceSize; j-- {
+		state[j] = byte(length)
+		length >>= 8
+	}
+	c.block.Encrypt(state[:], state[:])
+
+	macBlocks := func(data []byte) {
+		for len(data) > 0 {
+			n := subtle.XORBytes(state[:], state[:], data)
+			c.block.Encrypt(state[:], state[:])
+			data = data[n:]
+		}
+	}
+	if len(additionalData) > 0 {
+		if len(additionalData) >= 0xff00 {
+			panic("Additional data is too large")
+		}
+		encodedAdditionalData := binary.BigEndian.AppendUint16(nil, uint16(len(additionalData)))
+		macBlocks(append(encodedAdditionalData, additionalData...))
+	}
+	macBlocks(plaintext)
+
+	// Encrypt the MAC using counter block A_0.
+	s0 := c.getCounterBlock(nonce, 0)
+	c.block.Encrypt(s0[:], s0[:])
+	subtle.XORBytes(state[:], state[:], s0[:])
+	return state[:c.tagSize]
+}
+
+// applyKeyStream encrypts or decrypts a message, using the key stream
+// generated from counter blocks A_1, A_2, etc.
+func (c *ccm) applyKeyStream(dst, src, nonce []byte) {
+	a1 := c.getCounterBlock(nonce, 1)
+	cipher.NewCTR(c.block, a1[:]).XORKeyStream(dst, src)
+}
+
+func (c *ccm) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != c.nonceSize {
+		panic("Incorrect nonce length")
+	}
+	tag := c.computeTag(nonce, plaintext, additionalData)
+	out := make([]byte, len(plaintext)+c.tagSize)
+	c.applyKeyStream(out, plaintext, nonce)
+	copy(out[len(plaintext):], tag)
+	return append(dst, out...)
+}
+
+func (c *ccm) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != c.nonceSize {
+		panic("Incorrect nonce length")
+	}
+	if len(ciphertext) < c.tagSize {
+		return nil, errCCMOpen
+	}
+	tag := ciphertext[len(ciphertext)-c.tagSize:]
+	plaintext := make([]byte, len(ciphertext)-c.tagSize)
+	c.applyKeyStream(plaintext, ciphertext[:len(plaintext)], nonce)
+	if !hmac.Equal(c.computeTag(nonce, plaintext, additionalData), tag) {
+		return nil, errCCMOpen
+	}
+	return append(dst, plaintext...), nil
+}
+
+// newMessageAEAD creates an AEAD for encrypting and decrypting messages
+// using one of the ciphers supported by SMB 3.x. Both ciphers use
+// 16-byte authentication tags. CCM uses 11-byte nonces, while GCM uses
+// 12-byte nonces.
+func newMessageAEAD(cipherID uint16, key []byte) cipher.AEAD {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+	switch cipherID {
+	case cipherAES128CCM:
+		return newCCM(block, 11, 16)
+	case cipherAES128GCM:
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			panic(err)
+		}
+		return aead
+	default:
+		panic("Unknown cipher")
+	}
+}
@@ -0,0 +1,71 @@
+package smb
+
+import (
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestComputeAESCMAC(t *testing.T) {
+	// Test vectors from RFC 4493, section 4.
+	key := mustDecodeHex("2b7e151628aed2a6abf7158809cf4f3c")
+	message := mustDecodeHex(
+		"6bc1bee22e409f96e93d7e117393172a" +
+			"ae2d8a571e03ac9c9eb76fac45af8e51" +
+			"30c81c46a35ce411e5fbc1191a0a52ef" +
+			"f69f2445df4f9b17ad2b417be66c3710")
+
+	t.Run("Empty", func(t *testing.T) {
+		require.Equal(t, mustDecodeHex("bb1d6929e95937287fa37d129b756746"), computeAESCMAC(key, nil))
+	})
+
+	t.Run("SingleBlock", func(t *testing.T) {
+		require.Equal(t, mustDecodeHex("070a16b46b4d4144f79bdd9dd04a287c"), computeAESCMAC(key, message[:16]))
+	})
+
+	t.Run("PartialBlock", func(t *testing.T) {
+		require.Equal(t, mustDecodeHex("dfa66747de9ae63030ca32611497c827"), computeAESCMAC(key, message[:40]))
+	})
+
+	t.Run("MultipleBlocks", func(t *testing.T) {
+		require.Equal(t, mustDecodeHex("51f0bebf7e3b9d92fc49741779363cfe"), computeAESCMAC(key, message))
+	})
+}
+
+func TestCCM(t *testing.T) {
+	// Packet vector #1 from RFC 3610, section 8.
+	block, err := aes.NewCipher(mustDecodeHex("c0c1c2c3c4c5c6c7c8c9cacbcccdcecf"))
+	require.NoError(t, err)
+	aead := newCCM(block, 13, 8)
+	nonce := mustDecodeHex("00000003020100a0a1a2a3a4a5")
+	additionalData := mustDecodeHex("0001020304050607")
+	plaintext := mustDecodeHex("08090a0b0c0d0e0f101112131415161718191a1b1c1d1e")
+	ciphertext := mustDecodeHex("588c979a61c663d2f066d0c2c0f989806d5f6b61dac38417e8d12cfdf926e0")
+
+	t.Run("Seal", func(t *testing.T) {
+		require.Equal(t, ciphertext, aead.Seal(nil, nonce, plaintext, additionalData))
+	})
+
+	t.Run("Open", func(t *testing.T) {
+		decrypted, err := aead.Open(nil, nonce, ciphertext, additionalData)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, decrypted)
+	})
+
+	t.Run("OpenTampered", func(t *testing.T) {
+		tampered := append([]byte(nil), ciphertext...)
+		tampered[3] ^= 1
+		_, err := aead.Open(nil, nonce, tampered, additionalData)
+		require.Error(t, err)
+	})
+}
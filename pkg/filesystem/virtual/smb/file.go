@@ -0,0 +1,1197 @@
+package smb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+)
+
+// Bits of the DesiredAccess field of the CREATE request.
+//
+// Reference: [MS-SMB2] section 2.2.13.1.
+const (
+	accessMaskFileReadData     = 0x00000001
+	accessMaskFileWriteData    = 0x00000002
+	accessMaskFileAppendData   = 0x00000004
+	accessMaskFileExecute      = 0x00000020
+	accessMaskFileAllAccess    = 0x001f01ff
+	accessMaskMaximumAllowed   = 0x02000000
+	accessMaskGenericAll       = 0x10000000
+	accessMaskGenericExecute   = 0x20000000
+	accessMaskGenericWrite     = 0x40000000
+	accessMaskGenericRead      = 0x80000000
+	accessMaskReadPermissions  = accessMaskFileReadData | accessMaskFileExecute | accessMaskMaximumAllowed | accessMaskGenericAll | accessMaskGenericExecute | accessMaskGenericRead
+	accessMaskWritePermissions = accessMaskFileWriteData | accessMaskFileAppendData | accessMaskGenericAll | accessMaskGenericWrite
+)
+
+// Values of the CreateDisposition field of the CREATE request.
+const (
+	createDispositionSupersede   = 0
+	createDispositionOpen        = 1
+	createDispositionCreate      = 2
+	createDispositionOpenIf      = 3
+	createDispositionOverwrite   = 4
+	createDispositionOverwriteIf = 5
+)
+
+// Bits of the CreateOptions field of the CREATE request.
+const (
+	createOptionDirectoryFile    = 0x00000001
+	createOptionNonDirectoryFile = 0x00000040
+	createOptionDeleteOnClose    = 0x00001000
+	createOptionOpenReparsePoint = 0x00200000
+)
+
+// Values of the CreateAction field of the CREATE response.
+const (
+	createActionSuperseded  = 0
+	createActionOpened      = 1
+	createActionCreated     = 2
+	createActionOverwritten = 3
+)
+
+const (
+	// Bit of the Flags field of the CLOSE request, indicating that
+	// the attributes of the file should be returned.
+	closeFlagPostQueryAttrib = 0x0001
+
+	// Bits of the Flags field of the QUERY_DIRECTORY request.
+	queryDirectoryFlagRestartScans      = 0x01
+	queryDirectoryFlagReturnSingleEntry = 0x02
+	queryDirectoryFlagReopen            = 0x10
+
+	// Values of the InfoType field of the QUERY_INFO and SET_INFO
+	// requests.
+	infoTypeFile       = 0x01
+	infoTypeFilesystem = 0x02
+	infoTypeSecurity   = 0x03
+
+	// Bit of the Flags field of FILE_DISPOSITION_INFORMATION_EX.
+	fileDispositionFlagDelete = 0x00000001
+
+	// Control codes of IOCTL requests that are handled.
+	fsctlDFSGetReferrals   = 0x00060194
+	fsctlDFSGetReferralsEx = 0x000601b0
+	fsctlGetReparsePoint   = 0x000900a8
+)
+
+// relatedFileID is the value of the FileId field that is used by
+// related operations in compound requests to refer to the file that
+// was opened by a preceding CREATE request.
+var relatedFileID = bytes.Repeat([]byte{0xff}, 16)
+
+// nullSecurityDescriptor is a self-relative security descriptor that
+// contains a NULL DACL, granting everyone full access. It is returned
+// for all files, as the virtual file system does not track ownership.
+var nullSecurityDescriptor = []byte{
+	1, 0, 0x04, 0x80, // Revision, Sbz1 and Control.
+	0, 0, 0, 0, // OffsetOwner.
+	0, 0, 0, 0, // OffsetGroup.
+	0, 0, 0, 0, // OffsetSacl.
+	0, 0, 0, 0, // OffsetDacl.
+}
+
+func toNTStatus(s virtual.Status) uint32 {
+	switch s {
+	case virtual.StatusErrAccess:
+		return statusAccessDenied
+	case virtual.StatusErrBadHandle:
+		return statusInvalidHandle
+	case virtual.StatusErrExist:
+		return statusObjectNameCollision
+	case virtual.StatusErrInval:
+		return statusInvalidParameter
+	case virtual.StatusErrIO:
+		return statusUnexpectedIOError
+	case virtual.StatusErrIsDir:
+		return statusFileIsADirectory
+	case virtual.StatusErrNoEnt:
+		return statusObjectNameNotFound
+	case virtual.StatusErrNoSpc:
+		return statusDiskFull
+	case virtual.StatusErrNoXAttr:
+		return statusNoEASOnFile
+	case virtual.StatusErrNotDir:
+		return statusNotADirectory
+	case virtual.StatusErrNotEmpty:
+		return statusDirectoryNotEmpty
+	case virtual.StatusErrNXIO:
+		return statusNoSuchDevice
+	case virtual.StatusErrPerm:
+		return statusAccessDenied
+	case virtual.StatusErrROFS:
+		return statusMediaWriteProtected
+	case virtual.StatusErrStale:
+		return statusFileInvalid
+	case virtual.StatusErrSymlink:
+		return statusNotSupported
+	case virtual.StatusErrWrongType:
+		return statusInvalidDeviceRequest
+	case virtual.StatusErrXDev:
+		return statusNotSameDevice
+	default:
+		panic("Unknown status")
+	}
+}
+
+// desiredAccessToShareMask converts the access mask provided to CREATE
+// to a ShareMask, indicating which operations are expected to be
+// called against the file. Opening a file with an access mask that
+// only permits accessing attributes does not require the file to be
+// opened.
+func desiredAccessToShareMask(desiredAccess uint32) virtual.ShareMask {
+	var shareAccess virtual.ShareMask
+	if desiredAccess&accessMaskReadPermissions != 0 {
+		shareAccess |= virtual.ShareMaskRead
+	}
+	if desiredAccess&accessMaskWritePermissions != 0 {
+		shareAccess |= virtual.ShareMaskWrite
+	}
+	return shareAccess
+}
+
+// openFile contains the state associated with a file that has been
+// opened by the client through CREATE.
+type openFile struct {
+	sessionID uint64
+	treeID    uint32
+
+	node virtual.DirectoryChild
+	// The directory containing the file, and the name of the file
+	// within that directory. These are needed to delete and rename
+	// files, and to resolve "..". They are not set for the root
+	// directory.
+	parent virtual.Directory
+	name   path.Component
+	// The path of the file relative to the root of the share, using
+	// backslashes as separators.
+	pathName string
+
+	desiredAccess uint32
+	shareAccess   virtual.ShareMask
+	deleteOnClose bool
+
+	// Directory enumeration state of QUERY_DIRECTORY.
+	enumerationStarted         bool
+	enumerationPattern         string
+	enumerationOffset          uint64
+	enumerationReportedEntries bool
+}
+
+func encodeFileID(fileID uint64) []byte {
+	b := binary.LittleEndian.AppendUint64(nil, fileID)
+	return binary.LittleEndian.AppendUint64(b, fileID)
+}
+
+// getOpenFile looks up an open file by the FileId field provided in a
+// request. Both the persistent and volatile parts of file IDs are
+// assigned the same value.
+func (c *connection) getOpenFile(r *request, fileID []byte) (uint64, *openFile, uint32) {
+	if bytes.Equal(fileID, relatedFileID) {
+		if r.compound.fileID == nil {
+			return 0, nil, r.compound.createStatus
+		}
+		fileID = r.compound.fileID
+	}
+	persistentFileID := binary.LittleEndian.Uint64(fileID[:8])
+	volatileFileID := binary.LittleEndian.Uint64(fileID[8:])
+	f, ok := c.openFiles[volatileFileID]
+	if !ok || persistentFileID != volatileFileID || f.sessionID != r.sessionID || f.treeID != r.treeID {
+		return 0, nil, statusFileClosed
+	}
+	return volatileFileID, f, statusSuccess
+}
+
+// closeFile closes a file that was opened through CREATE, removing it
+// from its parent directory if it was marked for deletion.
+//
+// Unlike Windows, files marked for deletion are removed when the handle
+// marking it for deletion is closed, as opposed to when the last handle
+// to the file is closed.
+func (c *connection) closeFile(fileID uint64, f *openFile) uint32 {
+	delete(c.openFiles, fileID)
+	directory, leaf := f.node.GetPair()
+	if f.shareAccess != 0 {
+		leaf.VirtualClose(f.shareAccess)
+	}
+	if f.deleteOnClose && f.parent != nil {
+		isDirectory := directory != nil
+		if _, vs := f.parent.VirtualRemove(f.name, isDirectory, !isDirectory); vs != virtual.StatusOK {
+			return toNTStatus(vs)
+		}
+	}
+	return statusSuccess
+}
+
+func (c *connection) closeAllFiles() {
+	for fileID, f := range c.openFiles {
+		c.closeFile(fileID, f)
+	}
+}
+
+// parsePath converts a pathname provided by the client to a list of
+// pathname components. The default data stream of a file may be
+// referred to by appending "::$DATA". Alternate data streams are not
+// supported.
+func parsePath(p string) ([]path.Component, uint32) {
+	var components []path.Component
+	for _, name := range strings.Split(p, "\\") {
+		if name == "" {
+			continue
+		}
+		if i := strings.IndexByte(name, ':'); i >= 0 {
+			if !strings.EqualFold(name[i:], "::$DATA") {
+				return nil, statusObjectNameInvalid
+			}
+			name = name[:i]
+		}
+		component, ok := path.NewComponent(name)
+		if !ok {
+			return nil, statusObjectNameInvalid
+		}
+		components = append(components, component)
+	}
+	return components, statusSuccess
+}
+
+func joinPath(components []path.Component) string {
+	var sb strings.Builder
+	for i, component := range components {
+		if i > 0 {
+			sb.WriteByte('\\')
+		}
+		sb.WriteString(component.String())
+	}
+	return sb.String()
+}
+
+// walk resolves the first count components of a path, which should all
+// refer to directories. If one of the components refers to a symbolic
+// link, the client is requested to resolve it by returning
+// STATUS_STOPPED_ON_SYMLINK.
+func (c *connection) walk(components []path.Component, count int) (virtual.Directory, []byte, uint32) {
+	directory := c.server.rootDirectory
+	for i, component := range components[:count] {
+		var attributes virtual.Attributes
+		child, vs := directory.VirtualLookup(c.ctx, component, virtual.AttributesMaskFileType, &attributes)
+		if vs == virtual.StatusErrNoEnt {
+			return nil, nil, statusObjectPathNotFound
+		} else if vs != virtual.StatusOK {
+			return nil, nil, toNTStatus(vs)
+		}
+		childDirectory, leaf := child.GetPair()
+		if childDirectory == nil {
+			if attributes.GetFileType() == filesystem.FileTypeSymlink {
+				return c.newSymlinkErrorResponse(leaf, components[i+1:])
+			}
+			return nil, nil, statusObjectPathNotFound
+		}
+		directory = childDirectory
+	}
+	return directory, nil, statusSuccess
+}
+
+// newSymlinkErrorResponse creates an ERROR response containing the
+// target of a symbolic link that was encountered while resolving a
+// path, and the length of the part of the path that remains to be
+// resolved.
+//
+// Reference: [MS-SMB2] section 2.2.2.2.1.
+func (c *connection) newSymlinkErrorResponse(leaf virtual.Leaf, remaining []path.Component) (virtual.Directory, []byte, uint32) {
+	target, vs := leaf.VirtualReadlink(c.ctx)
+	if vs != virtual.StatusOK {
+		return nil, nil, toNTStatus(vs)
+	}
+	unparsedPathLength := 0
+	for _, component := range remaining {
+		unparsedPathLength += 2 + len(encodeUTF16(component.String()))
+	}
+	reparseData := newSymlinkReparseData(target, unparsedPathLength)
+
+	b := []byte{9, 0, 0, 0}
+	b = binary.LittleEndian.AppendUint32(b, uint32(8+len(reparseData)))
+	b = binary.LittleEndian.AppendUint32(b, uint32(4+len(reparseData)))
+	b = binary.LittleEndian.AppendUint32(b, 0x4c4d5953) // SymLinkErrorTag.
+	return nil, append(b, reparseData...), statusStoppedOnSymlink
+}
+
+func (c *connection) handleCreate(r *request) ([]byte, uint32) {
+	body, st := c.create(r)
+	if st != statusSuccess {
+		r.compound.fileID = nil
+		r.compound.createStatus = st
+	}
+	return body, st
+}
+
+func (c *connection) create(r *request) ([]byte, uint32) {
+	if len(r.body) < 56 {
+		return nil, statusInvalidParameter
+	}
+	desiredAccess := binary.LittleEndian.Uint32(r.body[24:])
+	fileAttributes := binary.LittleEndian.Uint32(r.body[28:])
+	createDisposition := binary.LittleEndian.Uint32(r.body[36:])
+	createOptions := binary.LittleEndian.Uint32(r.body[40:])
+	encodedName, ok := getBuffer(r.message, 56, uint32(binary.LittleEndian.Uint16(r.body[44:])), uint32(binary.LittleEndian.Uint16(r.body[46:])))
+	if !ok || createDisposition > createDispositionOverwriteIf {
+		return nil, statusInvalidParameter
+	}
+	name, ok := decodeUTF16(encodedName)
+	if !ok {
+		return nil, statusObjectNameInvalid
+	}
+	if r.tree.isIPC {
+		// Named pipes are not supported.
+		return nil, statusObjectNameNotFound
+	}
+	components, st := parsePath(name)
+	if st != statusSuccess {
+		return nil, st
+	}
+
+	shareAccess := desiredAccessToShareMask(desiredAccess)
+	truncate := createDisposition == createDispositionSupersede ||
+		createDisposition == createDispositionOverwrite ||
+		createDisposition == createDispositionOverwriteIf
+	f := openFile{
+		sessionID:     r.sessionID,
+		treeID:        r.treeID,
+		pathName:      joinPath(components),
+		desiredAccess: desiredAccess,
+		deleteOnClose: createOptions&createOptionDeleteOnClose != 0,
+	}
+	createAction := uint32(createActionOpened)
+	var attributes virtual.Attributes
+	if len(components) == 0 {
+		// Opening the root directory of the share.
+		if createOptions&createOptionNonDirectoryFile != 0 {
+			return nil, statusFileIsADirectory
+		}
+		if createDisposition == createDispositionCreate {
+			return nil, statusObjectNameCollision
+		}
+		if truncate || f.deleteOnClose {
+			return nil, statusAccessDenied
+		}
+		rootDirectory := c.server.rootDirectory
+		rootDirectory.VirtualGetAttributes(c.ctx, AttributesMaskForFileInformation, &attributes)
+		f.node = virtual.DirectoryChild{}.FromDirectory(rootDirectory)
+	} else {
+		parent, errorResponse, st := c.walk(components, len(components)-1)
+		if st != statusSuccess {
+			return errorResponse, st
+		}
+		f.parent = parent
+		f.name = components[len(components)-1]
+
+		child, vs := parent.VirtualLookup(c.ctx, f.name, AttributesMaskForFileInformation, &attributes)
+		switch vs {
+		case virtual.StatusOK:
+			if createDisposition == createDispositionCreate {
+				return nil, statusObjectNameCollision
+			}
+			directory, leaf := child.GetPair()
+			if directory != nil {
+				if createOptions&createOptionNonDirectoryFile != 0 {
+					return nil, statusFileIsADirectory
+				}
+				if truncate {
+					return nil, statusInvalidParameter
+				}
+			} else {
+				if createOptions&createOptionDirectoryFile != 0 {
+					return nil, statusNotADirectory
+				}
+				if attributes.GetFileType() == filesystem.FileTypeSymlink {
+					if createOptions&createOptionOpenReparsePoint == 0 {
+						_, errorResponse, st := c.newSymlinkErrorResponse(leaf, nil)
+						return errorResponse, st
+					}
+					// Symbolic links can only be opened
+					// to access their attributes, or to
+					// remove them.
+					shareAccess = 0
+				} else if truncate {
+					if shareAccess&virtual.ShareMaskWrite == 0 {
+						return nil, statusAccessDenied
+					}
+					if vs := leaf.VirtualOpenSelf(c.ctx, shareAccess, &virtual.OpenExistingOptions{Truncate: true}, AttributesMaskForFileInformation, &attributes); vs != virtual.StatusOK {
+						return nil, toNTStatus(vs)
+					}
+					f.shareAccess = shareAccess
+					if createDisposition == createDispositionSupersede {
+						createAction = createActionSuperseded
+					} else {
+						createAction = createActionOverwritten
+					}
+				} else if shareAccess != 0 {
+					if vs := leaf.VirtualOpenSelf(c.ctx, shareAccess, &virtual.OpenExistingOptions{}, AttributesMaskForFileInformation, &attributes); vs != virtual.StatusOK {
+						return nil, toNTStatus(vs)
+					}
+					f.shareAccess = shareAccess
+				}
+			}
+			f.node = child
+		case virtual.StatusErrNoEnt:
+			if createDisposition == createDispositionOpen || createDisposition == createDispositionOverwrite {
+				return nil, statusObjectNameNotFound
+			}
+			if createOptions&createOptionDirectoryFile != 0 {
+				directory, _, vs := parent.VirtualMkdir(f.name, AttributesMaskForFileInformation, &attributes)
+				if vs != virtual.StatusOK {
+					return nil, toNTStatus(vs)
+				}
+				f.node = virtual.DirectoryChild{}.FromDirectory(directory)
+			} else {
+				permissions := virtual.PermissionsRead | virtual.PermissionsWrite
+				if fileAttributes&fileAttributeReadonly != 0 {
+					permissions = virtual.PermissionsRead
+				}
+				// Files always need to be opened to be
+				// created. If no access to the file's
+				// contents was requested, close it
+				// immediately.
+				openShareAccess := shareAccess
+				if openShareAccess == 0 {
+					openShareAccess = virtual.ShareMaskRead
+				}
+				leaf, _, _, vs := parent.VirtualOpenChild(
+					c.ctx,
+					f.name,
+					openShareAccess,
+					(&virtual.Attributes{}).SetPermissions(permissions),
+					nil,
+					AttributesMaskForFileInformation,
+					&attributes)
+				if vs != virtual.StatusOK {
+					return nil, toNTStatus(vs)
+				}
+				if shareAccess == 0 {
+					leaf.VirtualClose(openShareAccess)
+				}
+				f.node = virtual.DirectoryChild{}.FromLeaf(leaf)
+				f.shareAccess = shareAccess
+			}
+			createAction = createActionCreated
+		default:
+			return nil, toNTStatus(vs)
+		}
+	}
+
+	c.lastFileID++
+	fileID := encodeFileID(c.lastFileID)
+	c.openFiles[c.lastFileID] = &f
+	r.compound.fileID = fileID
+
+	b := make([]byte, 8, 88)
+	binary.LittleEndian.PutUint16(b[0:], 89)
+	binary.LittleEndian.PutUint32(b[4:], createAction)
+	b = appendNetworkOpenInformation(b, &attributes)
+	b = append(b, fileID...)
+	return append(b, make([]byte, 8)...), statusSuccess
+}
+
+func (c *connection) handleClose(r *request) ([]byte, uint32) {
+	if len(r.body) < 24 {
+		return nil, statusInvalidParameter
+	}
+	flags := binary.LittleEndian.Uint16(r.body[2:])
+	fileID, f, st := c.getOpenFile(r, r.body[8:24])
+	if st != statusSuccess {
+		return nil, st
+	}
+
+	b := make([]byte, 8, 64)
+	binary.LittleEndian.PutUint16(b[0:], 60)
+	if flags&closeFlagPostQueryAttrib != 0 && !f.deleteOnClose {
+		binary.LittleEndian.PutUint16(b[2:], closeFlagPostQueryAttrib)
+		var attributes virtual.Attributes
+		f.node.GetNode().VirtualGetAttributes(c.ctx, AttributesMaskForFileInformation, &attributes)
+		b = appendNetworkOpenInformation(b, &attributes)[:60]
+	} else {
+		b = append(b, make([]byte, 52)...)
+	}
+	if st := c.closeFile(fileID, f); st != statusSuccess {
+		return nil, st
+	}
+	return b, statusSuccess
+}
+
+func (c *connection) handleFlush(r *request) ([]byte, uint32) {
+	if len(r.body) < 24 {
+		return nil, statusInvalidParameter
+	}
+	// All data is written into the virtual file system
+	// immediately, meaning there is nothing to flush.
+	if _, _, st := c.getOpenFile(r, r.body[8:24]); st != statusSuccess {
+		return nil, st
+	}
+	return []byte{4, 0, 0, 0}, statusSuccess
+}
+
+func (c *connection) getLeaf(f *openFile) (virtual.Leaf, uint32) {
+	_, leaf := f.node.GetPair()
+	if leaf == nil {
+		return nil, statusInvalidDeviceRequest
+	}
+	return leaf, statusSuccess
+}
+
+func (c *connection) handleRead(r *request) ([]byte, uint32) {
+	if len(r.body) < 48 {
+		return nil, statusInvalidParameter
+	}
+	length := binary.LittleEndian.Uint32(r.body[4:])
+	offset := binary.LittleEndian.Uint64(r.body[8:])
+	_, f, st := c.getOpenFile(r, r.body[16:32])
+	if st != statusSuccess {
+		return nil, st
+	}
+	leaf, st := c.getLeaf(f)
+	if st != statusSuccess {
+		return nil, st
+	}
+	if f.shareAccess&virtual.ShareMaskRead == 0 {
+		return nil, statusAccessDenied
+	}
+	if length > maximumTransactSizeBytes {
+		return nil, statusInvalidParameter
+	}
+
+	b := make([]byte, 16, 16+length)
+	n, _, vs := leaf.VirtualRead(b[16:16+length], offset)
+	if vs != virtual.StatusOK {
+		return nil, toNTStatus(vs)
+	}
+	if n == 0 && length > 0 {
+		return nil, statusEndOfFile
+	}
+	binary.LittleEndian.PutUint16(b[0:], 17)
+	b[2] = headerSizeBytes + 16
+	binary.LittleEndian.PutUint32(b[4:], uint32(n))
+	return b[:16+n], statusSuccess
+}
+
+func (c *connection) handleWrite(r *request) ([]byte, uint32) {
+	if len(r.body) < 48 {
+		return nil, statusInvalidParameter
+	}
+	data, ok := getBuffer(r.message, 48, uint32(binary.LittleEndian.Uint16(r.body[2:])), binary.LittleEndian.Uint32(r.body[4:]))
+	if !ok {
+		return nil, statusInvalidParameter
+	}
+	offset := binary.LittleEndian.Uint64(r.body[8:])
+	_, f, st := c.getOpenFile(r, r.body[16:32])
+	if st != statusSuccess {
+		return nil, st
+	}
+	leaf, st := c.getLeaf(f)
+	if st != statusSuccess {
+		return nil, st
+	}
+	if f.shareAccess&virtual.ShareMaskWrite == 0 {
+		return nil, statusAccessDenied
+	}
+
+	n, vs := leaf.VirtualWrite(data, offset)
+	if vs != virtual.StatusOK {
+		return nil, toNTStatus(vs)
+	}
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint16(b[0:], 17)
+	binary.LittleEndian.PutUint32(b[4:], uint32(n))
+	return b, statusSuccess
+}
+
+func (c *connection) handleLock(r *request) ([]byte, uint32) {
+	if len(r.body) < 24 {
+		return nil, statusInvalidParameter
+	}
+	// Locks are only acquired by the client, and are not
+	// propagated to the virtual file system. Report success, so
+	// that applications relying on locks continue to work within
+	// a single client.
+	if _, _, st := c.getOpenFile(r, r.body[8:24]); st != statusSuccess {
+		return nil, st
+	}
+	return []byte{4, 0, 0, 0}, statusSuccess
+}
+
+func (c *connection) handleIoctl(r *request) ([]byte, uint32) {
+	if len(r.body) < 56 {
+		return nil, statusInvalidParameter
+	}
+	ctlCode := binary.LittleEndian.Uint32(r.body[4:])
+	maximumOutputResponse := binary.LittleEndian.Uint32(r.body[44:])
+	switch ctlCode {
+	case fsctlDFSGetReferrals, fsctlDFSGetReferralsEx:
+		// The server does not support DFS.
+		return nil, statusNotFound
+	case fsctlGetReparsePoint:
+		fileID, f, st := c.getOpenFile(r, r.body[8:24])
+		if st != statusSuccess {
+			return nil, st
+		}
+		var attributes virtual.Attributes
+		f.node.GetNode().VirtualGetAttributes(c.ctx, virtual.AttributesMaskFileType, &attributes)
+		_, leaf := f.node.GetPair()
+		if attributes.GetFileType() != filesystem.FileTypeSymlink {
+			return nil, statusNotAReparsePoint
+		}
+		target, vs := leaf.VirtualReadlink(c.ctx)
+		if vs != virtual.StatusOK {
+			return nil, toNTStatus(vs)
+		}
+		output := newSymlinkReparseData(target, 0)
+		if uint32(len(output)) > maximumOutputResponse {
+			return nil, statusBufferTooSmall
+		}
+
+		b := make([]byte, 48, 48+len(output))
+		binary.LittleEndian.PutUint16(b[0:], 49)
+		binary.LittleEndian.PutUint32(b[4:], ctlCode)
+		copy(b[8:24], encodeFileID(fileID))
+		binary.LittleEndian.PutUint32(b[24:], headerSizeBytes+48)
+		binary.LittleEndian.PutUint32(b[32:], headerSizeBytes+48)
+		binary.LittleEndian.PutUint32(b[36:], uint32(len(output)))
+		return append(b, output...), statusSuccess
+	default:
+		return nil, statusInvalidDeviceRequest
+	}
+}
+
+// matchPattern returns whether a filename matches a search pattern
+// provided to QUERY_DIRECTORY. Matching is case insensitive. DOS
+// wildcards are treated like their regular counterparts.
+func matchPattern(pattern, name string) bool {
+	if pattern == "*" {
+		return true
+	}
+	p := []rune(strings.ToUpper(pattern))
+	n := []rune(strings.ToUpper(name))
+	for i, c := range p {
+		switch c {
+		case '<':
+			p[i] = '*'
+		case '>':
+			p[i] = '?'
+		case '"':
+			p[i] = '.'
+		}
+	}
+
+	pi, ni, starPi, starNi := 0, 0, -1, 0
+	for ni < len(n) {
+		if pi < len(p) && (p[pi] == '?' || p[pi] == n[ni]) {
+			pi++
+			ni++
+		} else if pi < len(p) && p[pi] == '*' {
+			starPi, starNi = pi, ni
+			pi++
+		} else if starPi >= 0 {
+			starNi++
+			pi, ni = starPi+1, starNi
+		} else {
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// directoryReporter is used by QUERY_DIRECTORY to convert directory
+// entries reported by VirtualReadDir() to the SMB2 wire format.
+type directoryReporter struct {
+	infoClass        uint8
+	pattern          string
+	maximumSizeBytes int
+	singleEntry      bool
+
+	buf             []byte
+	lastEntryOffset int
+	count           int
+	offset          uint64
+	full            bool
+	done            bool
+}
+
+func (r *directoryReporter) addEntry(name string, attributes *virtual.Attributes, nextOffset uint64) bool {
+	if !matchPattern(r.pattern, name) {
+		r.offset = nextOffset
+		return true
+	}
+
+	// Entries need to be aligned to eight bytes.
+	entryOffset := (len(r.buf) + 7) &^ 7
+	entry := appendDirectoryEntry(nil, r.infoClass, name, attributes)
+	if entryOffset+len(entry) > r.maximumSizeBytes {
+		r.full = true
+		r.done = true
+		return false
+	}
+	if r.count > 0 {
+		binary.LittleEndian.PutUint32(r.buf[r.lastEntryOffset:], uint32(entryOffset-r.lastEntryOffset))
+		r.buf = append(r.buf, make([]byte, entryOffset-len(r.buf))...)
+	}
+	r.lastEntryOffset = entryOffset
+	r.buf = append(r.buf, entry...)
+	r.count++
+	r.offset = nextOffset
+	if r.singleEntry {
+		r.done = true
+		return false
+	}
+	return true
+}
+
+func (r *directoryReporter) ReportEntry(nextCookie uint64, name path.Component, child virtual.DirectoryChild, attributes *virtual.Attributes) bool {
+	return r.addEntry(name.String(), attributes, dotDotEntriesCount+nextCookie)
+}
+
+// The names of the "." and ".." entries that are injected at the start
+// of the results returned by QUERY_DIRECTORY.
+var dotDotEntryNames = [...]string{".", ".."}
+
+const dotDotEntriesCount uint64 = uint64(len(dotDotEntryNames))
+
+func (c *connection) handleQueryDirectory(r *request) ([]byte, uint32) {
+	if len(r.body) < 32 {
+		return nil, statusInvalidParameter
+	}
+	infoClass := r.body[2]
+	flags := r.body[3]
+	encodedPattern, ok := getBuffer(r.message, 32, uint32(binary.LittleEndian.Uint16(r.body[24:])), uint32(binary.LittleEndian.Uint16(r.body[26:])))
+	if !ok {
+		return nil, statusInvalidParameter
+	}
+	outputBufferLength := binary.LittleEndian.Uint32(r.body[28:])
+	_, f, st := c.getOpenFile(r, r.body[8:24])
+	if st != statusSuccess {
+		return nil, st
+	}
+	directory, _ := f.node.GetPair()
+	if directory == nil {
+		return nil, statusInvalidParameter
+	}
+	if !isSupportedDirectoryInformationClass(infoClass) {
+		return nil, statusInvalidInfoClass
+	}
+	if outputBufferLength > maximumTransactSizeBytes {
+		outputBufferLength = maximumTransactSizeBytes
+	}
+
+	if !f.enumerationStarted || flags&(queryDirectoryFlagRestartScans|queryDirectoryFlagReopen) != 0 {
+		pattern := "*"
+		if len(encodedPattern) > 0 {
+			if pattern, ok = decodeUTF16(encodedPattern); !ok {
+				return nil, statusObjectNameInvalid
+			}
+		}
+		f.enumerationStarted = true
+		f.enumerationPattern = pattern
+		f.enumerationOffset = 0
+		f.enumerationReportedEntries = false
+	}
+
+	reporter := directoryReporter{
+		infoClass:        infoClass,
+		pattern:          f.enumerationPattern,
+		maximumSizeBytes: int(outputBufferLength),
+		singleEntry:      flags&queryDirectoryFlagReturnSingleEntry != 0,
+		offset:           f.enumerationOffset,
+	}
+
+	// Inject "." and ".." entries at the start of the results.
+	dotDotDirectories := [dotDotEntriesCount]virtual.Directory{directory, directory}
+	if f.parent != nil {
+		dotDotDirectories[1] = f.parent
+	}
+	for !reporter.done && reporter.offset < dotDotEntriesCount {
+		var attributes virtual.Attributes
+		dotDotDirectories[reporter.offset].VirtualGetAttributes(c.ctx, AttributesMaskForFileInformation, &attributes)
+		reporter.addEntry(dotDotEntryNames[reporter.offset], &attributes, reporter.offset+1)
+	}
+	if !reporter.done {
+		if vs := directory.VirtualReadDir(c.ctx, reporter.offset-dotDotEntriesCount, AttributesMaskForFileInformation, &reporter); vs != virtual.StatusOK {
+			return nil, toNTStatus(vs)
+		}
+	}
+	f.enumerationOffset = reporter.offset
+
+	if reporter.count == 0 {
+		if reporter.full {
+			return nil, statusInfoLengthMismatch
+		}
+		if !f.enumerationReportedEntries {
+			return nil, statusNoSuchFile
+		}
+		return nil, statusNoMoreFiles
+	}
+	f.enumerationReportedEntries = true
+
+	b := make([]byte, 8, 8+len(reporter.buf))
+	binary.LittleEndian.PutUint16(b[0:], 9)
+	binary.LittleEndian.PutUint16(b[2:], headerSizeBytes+8)
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(reporter.buf)))
+	return append(b, reporter.buf...), statusSuccess
+}
+
+func (c *connection) handleQueryInfo(r *request) ([]byte, uint32) {
+	if len(r.body) < 40 {
+		return nil, statusInvalidParameter
+	}
+	infoType := r.body[2]
+	infoClass := r.body[3]
+	outputBufferLength := binary.LittleEndian.Uint32(r.body[4:])
+	_, f, st := c.getOpenFile(r, r.body[24:40])
+	if st != statusSuccess {
+		return nil, st
+	}
+
+	var out []byte
+	var fixedSizeBytes int
+	switch infoType {
+	case infoTypeFile:
+		out, fixedSizeBytes, st = c.queryFileInformation(f, infoClass)
+	case infoTypeFilesystem:
+		out, fixedSizeBytes, st = queryFilesystemInformation(infoClass)
+	case infoTypeSecurity:
+		out, fixedSizeBytes = nullSecurityDescriptor, len(nullSecurityDescriptor)
+	default:
+		st = statusNotSupported
+	}
+	if st != statusSuccess {
+		return nil, st
+	}
+	if uint32(len(out)) > outputBufferLength {
+		if outputBufferLength < uint32(fixedSizeBytes) {
+			return nil, statusInfoLengthMismatch
+		}
+		out = out[:outputBufferLength]
+		st = statusBufferOverflow
+	}
+
+	b := make([]byte, 8, 8+len(out))
+	binary.LittleEndian.PutUint16(b[0:], 9)
+	binary.LittleEndian.PutUint16(b[2:], headerSizeBytes+8)
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(out)))
+	return append(b, out...), st
+}
+
+// queryFileInformation returns the information of a file, encoded
+// using a given information class. It also returns the size of the
+// fixed part of the information, which is the minimum output buffer
+// size.
+func (c *connection) queryFileInformation(f *openFile, infoClass uint8) ([]byte, int, uint32) {
+	var attributes virtual.Attributes
+	f.node.GetNode().VirtualGetAttributes(c.ctx, AttributesMaskForFileInformation, &attributes)
+	appendNameInformation := func(b []byte) []byte {
+		encodedName := encodeUTF16("\\" + f.pathName)
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(encodedName)))
+		return append(b, encodedName...)
+	}
+
+	switch infoClass {
+	case fileBasicInformation:
+		return appendBasicInformation(nil, &attributes), 40, statusSuccess
+	case fileStandardInformation:
+		return appendStandardInformation(nil, &attributes, f.deleteOnClose), 24, statusSuccess
+	case fileInternalInformation:
+		return binary.LittleEndian.AppendUint64(nil, attributes.GetInodeNumber()), 8, statusSuccess
+	case fileEaInformation, fileModeInformation, fileAlignmentInformation:
+		return make([]byte, 4), 4, statusSuccess
+	case fileAccessInformation:
+		return binary.LittleEndian.AppendUint32(nil, f.desiredAccess), 4, statusSuccess
+	case fileNameInformation:
+		return appendNameInformation(nil), 4, statusSuccess
+	case filePositionInformation:
+		return make([]byte, 8), 8, statusSuccess
+	case fileAllInformation:
+		b := appendBasicInformation(nil, &attributes)
+		b = appendStandardInformation(b, &attributes, f.deleteOnClose)
+		b = binary.LittleEndian.AppendUint64(b, attributes.GetInodeNumber())
+		b = binary.LittleEndian.AppendUint32(b, 0) // EaSize.
+		b = binary.LittleEndian.AppendUint32(b, f.desiredAccess)
+		b = binary.LittleEndian.AppendUint64(b, 0) // CurrentByteOffset.
+		b = binary.LittleEndian.AppendUint32(b, 0) // Mode.
+		b = binary.LittleEndian.AppendUint32(b, 0) // AlignmentRequirement.
+		return appendNameInformation(b), 100, statusSuccess
+	case fileStreamInformation:
+		// Only files have a default data stream.
+		if attributes.GetFileType() == filesystem.FileTypeDirectory {
+			return nil, 0, statusSuccess
+		}
+		endOfFile, allocationSize := getSizes(&attributes)
+		encodedStreamName := encodeUTF16("::$DATA")
+		b := binary.LittleEndian.AppendUint32(nil, 0) // NextEntryOffset.
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(encodedStreamName)))
+		b = binary.LittleEndian.AppendUint64(b, endOfFile)
+		b = binary.LittleEndian.AppendUint64(b, allocationSize)
+		return append(b, encodedStreamName...), 24, statusSuccess
+	case fileNetworkOpenInformation:
+		return appendNetworkOpenInformation(nil, &attributes), 56, statusSuccess
+	case fileAttributeTagInformation:
+		b := binary.LittleEndian.AppendUint32(nil, getFileAttributes(&attributes))
+		return binary.LittleEndian.AppendUint32(b, getEaSize(&attributes)), 8, statusSuccess
+	default:
+		return nil, 0, statusInvalidInfoClass
+	}
+}
+
+// queryFilesystemInformation returns information on the file system,
+// encoded using a given information class.
+func queryFilesystemInformation(infoClass uint8) ([]byte, int, uint32) {
+	const bytesPerSector = 512
+	const sectorsPerAllocationUnit = allocationUnitSizeBytes / bytesPerSector
+	switch infoClass {
+	case fileFsVolumeInformation:
+		// No volume creation time, serial number and label are
+		// reported.
+		return make([]byte, 18), 18, statusSuccess
+	case fileFsSizeInformation:
+		b := binary.LittleEndian.AppendUint64(nil, totalAllocationUnits)
+		b = binary.LittleEndian.AppendUint64(b, totalAllocationUnits)
+		b = binary.LittleEndian.AppendUint32(b, sectorsPerAllocationUnit)
+		return binary.LittleEndian.AppendUint32(b, bytesPerSector), 24, statusSuccess
+	case fileFsDeviceInformation:
+		const fileDeviceDisk = 0x00000007
+		b := binary.LittleEndian.AppendUint32(nil, fileDeviceDisk)
+		return binary.LittleEndian.AppendUint32(b, 0), 8, statusSuccess
+	case fileFsAttributeInformation:
+		// Announce the file system as NTFS, as some
+		// applications refuse to work with file systems
+		// having different names.
+		const fileAttributes = 0x00000001 | // FILE_CASE_SENSITIVE_SEARCH.
+			0x00000002 | // FILE_CASE_PRESERVED_NAMES.
+			0x00000004 | // FILE_UNICODE_ON_DISK.
+			0x00000080 // FILE_SUPPORTS_REPARSE_POINTS.
+		encodedName := encodeUTF16("NTFS")
+		b := binary.LittleEndian.AppendUint32(nil, fileAttributes)
+		b = binary.LittleEndian.AppendUint32(b, 255)
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(encodedName)))
+		return append(b, encodedName...), 12, statusSuccess
+	case fileFsFullSizeInformation:
+		b := binary.LittleEndian.AppendUint64(nil, totalAllocationUnits)
+		b = binary.LittleEndian.AppendUint64(b, totalAllocationUnits)
+		b = binary.LittleEndian.AppendUint64(b, totalAllocationUnits)
+		b = binary.LittleEndian.AppendUint32(b, sectorsPerAllocationUnit)
+		return binary.LittleEndian.AppendUint32(b, bytesPerSector), 32, statusSuccess
+	case fileFsSectorSizeInformation:
+		b := binary.LittleEndian.AppendUint32(nil, bytesPerSector)
+		for i := 0; i < 3; i++ {
+			b = binary.LittleEndian.AppendUint32(b, allocationUnitSizeBytes)
+		}
+		return append(b, make([]byte, 12)...), 28, statusSuccess
+	default:
+		return nil, 0, statusInvalidInfoClass
+	}
+}
+
+func (c *connection) handleSetInfo(r *request) ([]byte, uint32) {
+	if len(r.body) < 32 {
+		return nil, statusInvalidParameter
+	}
+	infoType := r.body[2]
+	infoClass := r.body[3]
+	buffer, ok := getBuffer(r.message, 32, uint32(binary.LittleEndian.Uint16(r.body[8:])), binary.LittleEndian.Uint32(r.body[4:]))
+	if !ok {
+		return nil, statusInvalidParameter
+	}
+	_, f, st := c.getOpenFile(r, r.body[16:32])
+	if st != statusSuccess {
+		return nil, st
+	}
+
+	switch infoType {
+	case infoTypeFile:
+		st = c.setFileInformation(f, infoClass, buffer)
+	case infoTypeSecurity:
+		// Security descriptors are not stored. Ignore attempts
+		// to change them, so that copying files preserving
+		// their security descriptors succeeds.
+	default:
+		st = statusNotSupported
+	}
+	if st != statusSuccess {
+		return nil, st
+	}
+	return []byte{2, 0}, statusSuccess
+}
+
+func (c *connection) setFileInformation(f *openFile, infoClass uint8, buffer []byte) uint32 {
+	node := f.node.GetNode()
+	var attributesIn virtual.Attributes
+	switch infoClass {
+	case fileBasicInformation:
+		if len(buffer) < 36 {
+			return statusInfoLengthMismatch
+		}
+		// Values zero and -1 indicate that the timestamp
+		// should be left unchanged.
+		if lastAccessTime := binary.LittleEndian.Uint64(buffer[8:]); lastAccessTime != 0 && lastAccessTime < 0xfffffffffffffffe {
+			attributesIn.SetLastDataAccessTime(fromFileTime(lastAccessTime))
+		}
+		if lastWriteTime := binary.LittleEndian.Uint64(buffer[16:]); lastWriteTime != 0 && lastWriteTime < 0xfffffffffffffffe {
+			attributesIn.SetLastDataModificationTime(fromFileTime(lastWriteTime))
+		}
+		// The read-only attribute is mapped to the absence of
+		// write permissions on files.
+		if fileAttributes := binary.LittleEndian.Uint32(buffer[32:]); fileAttributes != 0 {
+			var attributes virtual.Attributes
+			node.VirtualGetAttributes(c.ctx, virtual.AttributesMaskFileType|virtual.AttributesMaskPermissions, &attributes)
+			if attributes.GetFileType() == filesystem.FileTypeRegularFile {
+				permissions, _ := attributes.GetPermissions()
+				newPermissions := permissions | virtual.PermissionsWrite
+				if fileAttributes&fileAttributeReadonly != 0 {
+					newPermissions &^= virtual.PermissionsWrite
+				}
+				if newPermissions != permissions {
+					attributesIn.SetPermissions(newPermissions)
+				}
+			}
+		}
+	case fileEndOfFileInformation:
+		if len(buffer) < 8 {
+			return statusInfoLengthMismatch
+		}
+		attributesIn.SetSizeBytes(binary.LittleEndian.Uint64(buffer))
+	case fileAllocationInformation:
+		// Preallocation is not supported. Storage is allocated
+		// when writing.
+		return statusSuccess
+	case fileDispositionInformation:
+		if len(buffer) < 1 {
+			return statusInfoLengthMismatch
+		}
+		return c.setDeletePending(f, buffer[0] != 0)
+	case fileDispositionInformationEx:
+		if len(buffer) < 4 {
+			return statusInfoLengthMismatch
+		}
+		return c.setDeletePending(f, binary.LittleEndian.Uint32(buffer)&fileDispositionFlagDelete != 0)
+	case fileRenameInformation:
+		return c.rename(f, buffer)
+	case fileLinkInformation:
+		return c.link(f, buffer)
+	default:
+		return statusInvalidInfoClass
+	}
+
+	if vs := node.VirtualSetAttributes(c.ctx, &attributesIn, 0, &virtual.Attributes{}); vs != virtual.StatusOK {
+		return toNTStatus(vs)
+	}
+	return statusSuccess
+}
+
+// emptyDirectoryChecker is used to determine whether a directory is
+// empty, prior to marking it for deletion.
+type emptyDirectoryChecker struct {
+	empty bool
+}
+
+func (r *emptyDirectoryChecker) ReportEntry(nextCookie uint64, name path.Component, child virtual.DirectoryChild, attributes *virtual.Attributes) bool {
+	r.empty = false
+	return false
+}
+
+func (c *connection) setDeletePending(f *openFile, deletePending bool) uint32 {
+	if !deletePending {
+		f.deleteOnClose = false
+		return statusSuccess
+	}
+	if f.parent == nil {
+		return statusAccessDenied
+	}
+	if directory, _ := f.node.GetPair(); directory != nil {
+		checker := emptyDirectoryChecker{empty: true}
+		if vs := directory.VirtualReadDir(c.ctx, 0, 0, &checker); vs != virtual.StatusOK {
+			return toNTStatus(vs)
+		}
+		if !checker.empty {
+			return statusDirectoryNotEmpty
+		}
+	}
+	f.deleteOnClose = true
+	return statusSuccess
+}
+
+// resolveTarget resolves the target of a rename or link operation,
+// which is provided in the form of FILE_RENAME_INFORMATION or
+// FILE_LINK_INFORMATION. Both structures have the same layout.
+func (c *connection) resolveTarget(buffer []byte) (bool, virtual.Directory, path.Component, []path.Component, uint32) {
+	if len(buffer) < 20 {
+		return false, nil, path.Component{}, nil, statusInfoLengthMismatch
+	}
+	replaceIfExists := buffer[0] != 0
+	nameLength := binary.LittleEndian.Uint32(buffer[16:])
+	if uint64(nameLength) > uint64(len(buffer)-20) {
+		return false, nil, path.Component{}, nil, statusInvalidParameter
+	}
+	name, ok := decodeUTF16(buffer[20 : 20+nameLength])
+	if !ok {
+		return false, nil, path.Component{}, nil, statusObjectNameInvalid
+	}
+	components, st := parsePath(name)
+	if st != statusSuccess {
+		return false, nil, path.Component{}, nil, st
+	}
+	if len(components) == 0 {
+		return false, nil, path.Component{}, nil, statusAccessDenied
+	}
+	directory, _, st := c.walk(components, len(components)-1)
+	if st == statusStoppedOnSymlink {
+		return false, nil, path.Component{}, nil, statusObjectPathNotFound
+	} else if st != statusSuccess {
+		return false, nil, path.Component{}, nil, st
+	}
+	newName := components[len(components)-1]
+	if !replaceIfExists {
+		if _, vs := directory.VirtualLookup(c.ctx, newName, 0, &virtual.Attributes{}); vs == virtual.StatusOK {
+			return false, nil, path.Component{}, nil, statusObjectNameCollision
+		}
+	}
+	return replaceIfExists, directory, newName, components, statusSuccess
+}
+
+func (c *connection) rename(f *openFile, buffer []byte) uint32 {
+	if f.parent == nil {
+		return statusAccessDenied
+	}
+	_, newDirectory, newName, components, st := c.resolveTarget(buffer)
+	if st != statusSuccess {
+		return st
+	}
+	if _, _, vs := f.parent.VirtualRename(f.name, newDirectory, newName); vs != virtual.StatusOK {
+		return toNTStatus(vs)
+	}
+	f.parent = newDirectory
+	f.name = newName
+	f.pathName = joinPath(components)
+	return statusSuccess
+}
+
+func (c *connection) link(f *openFile, buffer []byte) uint32 {
+	_, leaf := f.node.GetPair()
+	if leaf == nil {
+		return statusFileIsADirectory
+	}
+	replaceIfExists, directory, name, _, st := c.resolveTarget(buffer)
+	if st != statusSuccess {
+		return st
+	}
+	if replaceIfExists {
+		if _, vs := directory.VirtualRemove(name, false, true); vs != virtual.StatusOK && vs != virtual.StatusErrNoEnt {
+			return toNTStatus(vs)
+		}
+	}
+	if _, vs := directory.VirtualLink(c.ctx, name, leaf, 0, &virtual.Attributes{}); vs != virtual.StatusOK {
+		return toNTStatus(vs)
+	}
+	return statusSuccess
+}
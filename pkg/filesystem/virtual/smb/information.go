@@ -0,0 +1,278 @@
+package smb
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+)
+
+// AttributesMaskForFileInformation is the attributes mask to use for
+// VirtualGetAttributes() and VirtualLookup() to populate all fields of
+// the file information structures returned by the server.
+const AttributesMaskForFileInformation = virtual.AttributesMaskFileType |
+	virtual.AttributesMaskInodeNumber |
+	virtual.AttributesMaskLastDataAccessTime |
+	virtual.AttributesMaskLastDataModificationTime |
+	virtual.AttributesMaskLastStatusChangeTime |
+	virtual.AttributesMaskLinkCount |
+	virtual.AttributesMaskPermissions |
+	virtual.AttributesMaskSizeBytes
+
+// Values of the FileAttributes field of file information structures.
+//
+// Reference: [MS-FSCC] section 2.6.
+const (
+	fileAttributeReadonly     = 0x00000001
+	fileAttributeDirectory    = 0x00000010
+	fileAttributeArchive      = 0x00000020
+	fileAttributeReparsePoint = 0x00000400
+)
+
+// Information classes that can be queried through QUERY_INFO, or that
+// can be used to enumerate directories through QUERY_DIRECTORY.
+//
+// Reference: [MS-FSCC] section 2.4.
+const (
+	fileDirectoryInformation       = 1
+	fileFullDirectoryInformation   = 2
+	fileBothDirectoryInformation   = 3
+	fileBasicInformation           = 4
+	fileStandardInformation        = 5
+	fileInternalInformation        = 6
+	fileEaInformation              = 7
+	fileAccessInformation          = 8
+	fileNameInformation            = 9
+	fileRenameInformation          = 10
+	fileLinkInformation            = 11
+	fileNamesInformation           = 12
+	fileDispositionInformation     = 13
+	filePositionInformation        = 14
+	fileModeInformation            = 16
+	fileAlignmentInformation       = 17
+	fileAllInformation             = 18
+	fileAllocationInformation      = 19
+	fileEndOfFileInformation       = 20
+	fileStreamInformation          = 22
+	fileNetworkOpenInformation     = 34
+	fileAttributeTagInformation    = 35
+	fileIDBothDirectoryInformation = 37
+	fileIDFullDirectoryInformation = 38
+	fileDispositionInformationEx   = 64
+)
+
+// File system information classes that can be queried through
+// QUERY_INFO.
+//
+// Reference: [MS-FSCC] section 2.5.
+const (
+	fileFsVolumeInformation     = 1
+	fileFsSizeInformation       = 3
+	fileFsDeviceInformation     = 4
+	fileFsAttributeInformation  = 5
+	fileFsFullSizeInformation   = 7
+	fileFsSectorSizeInformation = 11
+)
+
+const (
+	// Reparse tag of symbolic links.
+	ioReparseTagSymlink = 0xa000000c
+	// Flag of symbolic link reparse data, indicating that the
+	// target is relative to the directory containing the link.
+	symlinkFlagRelative = 0x00000001
+
+	// Size of allocation units that is reported. File sizes are
+	// rounded up to this size to compute the allocation size.
+	allocationUnitSizeBytes = 4096
+	// The number of allocation units that is reported as the size of
+	// the file system. The virtual file system has no fixed size,
+	// but Windows refuses to write files if the file system appears
+	// to be full.
+	totalAllocationUnits = 1 << 30
+)
+
+// getFileAttributes returns the value of the FileAttributes field that
+// should be reported for a file.
+func getFileAttributes(attributes *virtual.Attributes) uint32 {
+	switch attributes.GetFileType() {
+	case filesystem.FileTypeDirectory:
+		return fileAttributeDirectory
+	case filesystem.FileTypeSymlink:
+		return fileAttributeReparsePoint
+	}
+	var fileAttributes uint32 = fileAttributeArchive
+	if permissions, ok := attributes.GetPermissions(); ok && permissions&virtual.PermissionsWrite == 0 {
+		fileAttributes |= fileAttributeReadonly
+	}
+	return fileAttributes
+}
+
+// getEaSize returns the value of the EaSize field of directory
+// entries. For reparse points, this field contains the reparse tag.
+func getEaSize(attributes *virtual.Attributes) uint32 {
+	if attributes.GetFileType() == filesystem.FileTypeSymlink {
+		return ioReparseTagSymlink
+	}
+	return 0
+}
+
+func getFileTime(attributes *virtual.Attributes) uint64 {
+	lastDataModificationTime, ok := attributes.GetLastDataModificationTime()
+	if !ok {
+		lastDataModificationTime = filesystem.DeterministicFileModificationTimestamp
+	}
+	return toFileTime(lastDataModificationTime)
+}
+
+// getOptionalFileTime returns the value of a timestamp that is not
+// tracked by all nodes, falling back to the last write time.
+func getOptionalFileTime(t time.Time, ok bool, lastWriteTime uint64) uint64 {
+	if !ok {
+		return lastWriteTime
+	}
+	return toFileTime(t)
+}
+
+func getSizes(attributes *virtual.Attributes) (endOfFile, allocationSize uint64) {
+	sizeBytes, ok := attributes.GetSizeBytes()
+	if !ok {
+		panic("Attributes do not contain mandatory size attribute")
+	}
+	if attributes.GetFileType() == filesystem.FileTypeDirectory {
+		return 0, 0
+	}
+	return sizeBytes, (sizeBytes + allocationUnitSizeBytes - 1) / allocationUnitSizeBytes * allocationUnitSizeBytes
+}
+
+// appendFileTimes appends the creation, last access, last write and
+// change times of a file. As creation times are not tracked, the last
+// write time is reported as the creation time.
+func appendFileTimes(b []byte, attributes *virtual.Attributes) []byte {
+	lastWriteTime := getFileTime(attributes)
+	lastAccessTime, hasLastAccessTime := attributes.GetLastDataAccessTime()
+	changeTime, hasChangeTime := attributes.GetLastStatusChangeTime()
+	b = binary.LittleEndian.AppendUint64(b, lastWriteTime)
+	b = binary.LittleEndian.AppendUint64(b, getOptionalFileTime(lastAccessTime, hasLastAccessTime, lastWriteTime))
+	b = binary.LittleEndian.AppendUint64(b, lastWriteTime)
+	return binary.LittleEndian.AppendUint64(b, getOptionalFileTime(changeTime, hasChangeTime, lastWriteTime))
+}
+
+// appendNetworkOpenInformation appends a FILE_NETWORK_OPEN_INFORMATION
+// structure. This structure is also embedded in the CREATE and CLOSE
+// responses.
+func appendNetworkOpenInformation(b []byte, attributes *virtual.Attributes) []byte {
+	endOfFile, allocationSize := getSizes(attributes)
+	b = appendFileTimes(b, attributes)
+	b = binary.LittleEndian.AppendUint64(b, allocationSize)
+	b = binary.LittleEndian.AppendUint64(b, endOfFile)
+	b = binary.LittleEndian.AppendUint32(b, getFileAttributes(attributes))
+	return binary.LittleEndian.AppendUint32(b, 0)
+}
+
+func appendBasicInformation(b []byte, attributes *virtual.Attributes) []byte {
+	b = appendFileTimes(b, attributes)
+	b = binary.LittleEndian.AppendUint32(b, getFileAttributes(attributes))
+	return binary.LittleEndian.AppendUint32(b, 0)
+}
+
+func appendStandardInformation(b []byte, attributes *virtual.Attributes, deletePending bool) []byte {
+	endOfFile, allocationSize := getSizes(attributes)
+	b = binary.LittleEndian.AppendUint64(b, allocationSize)
+	b = binary.LittleEndian.AppendUint64(b, endOfFile)
+	b = binary.LittleEndian.AppendUint32(b, attributes.GetLinkCount())
+	b = append(b, toByte(deletePending), toByte(attributes.GetFileType() == filesystem.FileTypeDirectory))
+	return binary.LittleEndian.AppendUint16(b, 0)
+}
+
+func toByte(v bool) byte {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// isSupportedDirectoryInformationClass returns whether an information
+// class may be used to enumerate directories.
+func isSupportedDirectoryInformationClass(infoClass uint8) bool {
+	switch infoClass {
+	case fileDirectoryInformation, fileFullDirectoryInformation, fileBothDirectoryInformation, fileNamesInformation, fileIDBothDirectoryInformation, fileIDFullDirectoryInformation:
+		return true
+	default:
+		return false
+	}
+}
+
+// appendDirectoryEntry appends a single directory entry returned by
+// QUERY_DIRECTORY. The NextEntryOffset field is left zero, and needs
+// to be filled in by the caller.
+func appendDirectoryEntry(b []byte, infoClass uint8, name string, attributes *virtual.Attributes) []byte {
+	encodedName := encodeUTF16(name)
+	b = binary.LittleEndian.AppendUint32(b, 0) // NextEntryOffset.
+	b = binary.LittleEndian.AppendUint32(b, 0) // FileIndex.
+	if infoClass == fileNamesInformation {
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(encodedName)))
+		return append(b, encodedName...)
+	}
+
+	endOfFile, allocationSize := getSizes(attributes)
+	b = appendFileTimes(b, attributes)
+	b = binary.LittleEndian.AppendUint64(b, endOfFile)
+	b = binary.LittleEndian.AppendUint64(b, allocationSize)
+	b = binary.LittleEndian.AppendUint32(b, getFileAttributes(attributes))
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(encodedName)))
+	switch infoClass {
+	case fileFullDirectoryInformation:
+		b = binary.LittleEndian.AppendUint32(b, getEaSize(attributes))
+	case fileIDFullDirectoryInformation:
+		b = binary.LittleEndian.AppendUint32(b, getEaSize(attributes))
+		b = binary.LittleEndian.AppendUint32(b, 0)
+		b = binary.LittleEndian.AppendUint64(b, attributes.GetInodeNumber())
+	case fileBothDirectoryInformation, fileIDBothDirectoryInformation:
+		// Short names are not supported, meaning that the
+		// ShortNameLength and ShortName fields are left zero.
+		b = binary.LittleEndian.AppendUint32(b, getEaSize(attributes))
+		b = append(b, make([]byte, 26)...)
+		if infoClass == fileIDBothDirectoryInformation {
+			b = binary.LittleEndian.AppendUint16(b, 0)
+			b = binary.LittleEndian.AppendUint64(b, attributes.GetInodeNumber())
+		}
+	}
+	return append(b, encodedName...)
+}
+
+// newSymlinkReparseData creates the reparse data of a symbolic link,
+// as returned by FSCTL_GET_REPARSE_POINT and as part of the error data
+// of STATUS_STOPPED_ON_SYMLINK. The substitute name and print name
+// are identical.
+//
+// Reference: [MS-FSCC] section 2.1.2.4.
+func newSymlinkReparseData(target []byte, unparsedPathLength int) []byte {
+	flags := uint32(symlinkFlagRelative)
+	if len(target) > 0 && target[0] == '/' {
+		flags = 0
+	}
+	encodedTarget := encodeUTF16(convertSeparators(string(target)))
+	b := binary.LittleEndian.AppendUint32(nil, ioReparseTagSymlink)
+	b = binary.LittleEndian.AppendUint16(b, uint16(12+2*len(encodedTarget)))
+	b = binary.LittleEndian.AppendUint16(b, uint16(unparsedPathLength))
+	b = binary.LittleEndian.AppendUint16(b, 0)
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(encodedTarget)))
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(encodedTarget)))
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(encodedTarget)))
+	b = binary.LittleEndian.AppendUint32(b, flags)
+	b = append(b, encodedTarget...)
+	return append(b, encodedTarget...)
+}
+
+// convertSeparators converts UNIX style pathname separators to the
+// ones used by Windows.
+func convertSeparators(p string) string {
+	b := []byte(p)
+	for i, c := range b {
+		if c == '/' {
+			b[i] = '\\'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,227 @@
+package smb
+
+import (
+	"encoding/binary"
+	"time"
+	"unicode/utf16"
+)
+
+// Commands of the SMB2 protocol.
+//
+// Reference: [MS-SMB2] section 2.2.1.2.
+const (
+	commandNegotiate      = 0x0000
+	commandSessionSetup   = 0x0001
+	commandLogoff         = 0x0002
+	commandTreeConnect    = 0x0003
+	commandTreeDisconnect = 0x0004
+	commandCreate         = 0x0005
+	commandClose          = 0x0006
+	commandFlush          = 0x0007
+	commandRead           = 0x0008
+	commandWrite          = 0x0009
+	commandLock           = 0x000a
+	commandIoctl          = 0x000b
+	commandCancel         = 0x000c
+	commandEcho           = 0x000d
+	commandQueryDirectory = 0x000e
+	commandChangeNotify   = 0x000f
+	commandQueryInfo      = 0x0010
+	commandSetInfo        = 0x0011
+	commandOplockBreak    = 0x0012
+)
+
+const (
+	// Size of the header that is prepended to every SMB2 message.
+	headerSizeBytes = 64
+	// Size of the Direct TCP transport header that is prepended to
+	// every frame, consisting of a zero byte and a 24-bit length.
+	transportHeaderSizeBytes = 4
+	// Maximum size of frames that are accepted from clients. This
+	// is large enough to hold a compound request containing a
+	// maximum size WRITE.
+	maximumFrameSizeBytes = 1 << 20
+	// Maximum size of the payload of READ, WRITE, QUERY_DIRECTORY
+	// and QUERY_INFO. As SMB2_GLOBAL_CAP_LARGE_MTU is not offered,
+	// clients may not use larger sizes.
+	maximumTransactSizeBytes = 65536
+	// Maximum number of credits that are granted to a client as part
+	// of a single response.
+	maximumCreditsGranted = 128
+)
+
+// Protocol identifiers at the start of SMB messages.
+var (
+	protocolIDSMB1 = [4]byte{0xff, 'S', 'M', 'B'}
+	protocolIDSMB2 = [4]byte{0xfe, 'S', 'M', 'B'}
+	// Protocol identifier of the transform header that is prepended
+	// to encrypted messages.
+	protocolIDTransform = [4]byte{0xfd, 'S', 'M', 'B'}
+)
+
+// Size of the transform header that is prepended to encrypted
+// messages.
+//
+// Reference: [MS-SMB2] section 2.2.41.
+const transformHeaderSizeBytes = 52
+
+// Dialects of the SMB2 protocol that are supported.
+const (
+	dialectSMB202       = 0x0202
+	dialectSMB210       = 0x0210
+	dialectSMB2Wildcard = 0x02ff
+	dialectSMB300       = 0x0300
+	dialectSMB302       = 0x0302
+	dialectSMB311       = 0x0311
+)
+
+// Bits of the Capabilities field of NEGOTIATE.
+const globalCapEncryption = 0x00000040
+
+// Types of negotiate contexts that are exchanged when SMB 3.1.1 is
+// negotiated, and the algorithms that they may refer to.
+//
+// Reference: [MS-SMB2] section 2.2.3.1.
+const (
+	negotiateContextPreauthIntegrity = 0x0001
+	negotiateContextEncryption       = 0x0002
+	negotiateContextSigning          = 0x0008
+
+	hashAlgorithmSHA512 = 0x0001
+
+	signingAlgorithmHMACSHA256 = 0x0000
+	signingAlgorithmAESCMAC    = 0x0001
+)
+
+// Bits of the Flags field of the SMB2 header.
+const (
+	flagsServerToRedir     = 0x00000001
+	flagsAsyncCommand      = 0x00000002
+	flagsRelatedOperations = 0x00000004
+	flagsSigned            = 0x00000008
+)
+
+// Bits of the SecurityMode field of NEGOTIATE and SESSION_SETUP.
+const (
+	securityModeSigningEnabled  = 0x0001
+	securityModeSigningRequired = 0x0002
+)
+
+// Bits of the SessionFlags field of the SESSION_SETUP response.
+const (
+	sessionFlagIsGuest     = 0x0001
+	sessionFlagIsNull      = 0x0002
+	sessionFlagEncryptData = 0x0004
+)
+
+// Values of the ShareType field of the TREE_CONNECT response.
+const (
+	shareTypeDisk = 0x01
+	shareTypePipe = 0x02
+)
+
+// Value of the ShareFlags field of the TREE_CONNECT response,
+// indicating that the client must not cache files offline.
+const shareFlagNoCaching = 0x00000030
+
+// NTSTATUS codes that are returned by the server.
+//
+// Reference: [MS-ERREF] section 2.3.1.
+const (
+	statusSuccess                = 0x00000000
+	statusBufferOverflow         = 0x80000005
+	statusNoMoreFiles            = 0x80000006
+	statusStoppedOnSymlink       = 0x8000002d
+	statusInvalidInfoClass       = 0xc0000003
+	statusInfoLengthMismatch     = 0xc0000004
+	statusInvalidHandle          = 0xc0000008
+	statusInvalidParameter       = 0xc000000d
+	statusNoSuchDevice           = 0xc000000e
+	statusNoSuchFile             = 0xc000000f
+	statusInvalidDeviceRequest   = 0xc0000010
+	statusEndOfFile              = 0xc0000011
+	statusMoreProcessingRequired = 0xc0000016
+	statusAccessDenied           = 0xc0000022
+	statusBufferTooSmall         = 0xc0000023
+	statusObjectNameInvalid      = 0xc0000033
+	statusObjectNameNotFound     = 0xc0000034
+	statusObjectNameCollision    = 0xc0000035
+	statusObjectPathNotFound     = 0xc000003a
+	statusNoEASOnFile            = 0xc0000052
+	statusLogonFailure           = 0xc000006d
+	statusDiskFull               = 0xc000007f
+	statusFileInvalid            = 0xc0000098
+	statusMediaWriteProtected    = 0xc00000a2
+	statusFileIsADirectory       = 0xc00000ba
+	statusNotSupported           = 0xc00000bb
+	statusNetworkNameDeleted     = 0xc00000c9
+	statusBadNetworkName         = 0xc00000cc
+	statusNotSameDevice          = 0xc00000d4
+	statusUnexpectedIOError      = 0xc00000e9
+	statusDirectoryNotEmpty      = 0xc0000101
+	statusNotADirectory          = 0xc0000103
+	statusFileClosed             = 0xc0000128
+	statusUserSessionDeleted     = 0xc0000203
+	statusNotFound               = 0xc0000225
+	statusNotAReparsePoint       = 0xc0000275
+)
+
+// windowsEpochOffset is the number of 100-nanosecond intervals between
+// January 1, 1601 and January 1, 1970.
+const windowsEpochOffset = 116444736000000000
+
+// toFileTime converts a timestamp to the FILETIME format used by SMB.
+func toFileTime(t time.Time) uint64 {
+	return uint64(t.UnixNano()/100 + windowsEpochOffset)
+}
+
+// fromFileTime converts a FILETIME to a timestamp.
+func fromFileTime(fileTime uint64) time.Time {
+	intervals := int64(fileTime) - windowsEpochOffset
+	return time.Unix(intervals/1e7, intervals%1e7*100)
+}
+
+// encodeUTF16 converts a string to UTF-16LE, which is the encoding that
+// is used by SMB for all strings.
+func encodeUTF16(s string) []byte {
+	codeUnits := utf16.Encode([]rune(s))
+	b := make([]byte, 0, 2*len(codeUnits))
+	for _, codeUnit := range codeUnits {
+		b = binary.LittleEndian.AppendUint16(b, codeUnit)
+	}
+	return b
+}
+
+// decodeUTF16 converts a UTF-16LE string to a Go string.
+func decodeUTF16(b []byte) (string, bool) {
+	if len(b)%2 != 0 {
+		return "", false
+	}
+	codeUnits := make([]uint16, 0, len(b)/2)
+	for i := 0; i < len(b); i += 2 {
+		codeUnits = append(codeUnits, binary.LittleEndian.Uint16(b[i:]))
+	}
+	return string(utf16.Decode(codeUnits)), true
+}
+
+// getBuffer returns the variable length part of a request, given an
+// offset relative to the start of the SMB2 header and a length. Offsets
+// pointing into the fixed size part of the request are rejected.
+func getBuffer(message []byte, fixedSizeBytes int, offset, length uint32) ([]byte, bool) {
+	if length == 0 {
+		return nil, true
+	}
+	if offset < uint32(headerSizeBytes+fixedSizeBytes) || uint64(offset)+uint64(length) > uint64(len(message)) {
+		return nil, false
+	}
+	return message[offset : offset+length], true
+}
+
+// padTo8 appends zero bytes to a buffer until its length is a multiple
+// of eight bytes.
+func padTo8(b []byte) []byte {
+	for len(b)%8 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
@@ -0,0 +1,274 @@
+package smb
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rc4"
+	"encoding/binary"
+	"strings"
+)
+
+// Constants pertaining to the NTLM authentication protocol.
+//
+// Reference: [MS-NLMP] section 2.2.
+const (
+	ntlmMessageTypeNegotiate    = 1
+	ntlmMessageTypeChallenge    = 2
+	ntlmMessageTypeAuthenticate = 3
+
+	ntlmFlagNegotiateUnicode                 = 0x00000001
+	ntlmFlagRequestTarget                    = 0x00000004
+	ntlmFlagNegotiateSign                    = 0x00000010
+	ntlmFlagNegotiateSeal                    = 0x00000020
+	ntlmFlagNegotiateNTLM                    = 0x00000200
+	ntlmFlagNegotiateAlwaysSign              = 0x00008000
+	ntlmFlagTargetTypeServer                 = 0x00020000
+	ntlmFlagNegotiateExtendedSessionSecurity = 0x00080000
+	ntlmFlagNegotiateTargetInfo              = 0x00800000
+	ntlmFlagNegotiateVersion                 = 0x02000000
+	ntlmFlagNegotiate128                     = 0x20000000
+	ntlmFlagNegotiateKeyExch                 = 0x40000000
+	ntlmFlagNegotiate56                      = 0x80000000
+
+	// Flags that are echoed back to the client in the CHALLENGE
+	// message if the client requested them.
+	ntlmFlagsSupported = ntlmFlagNegotiateUnicode |
+		ntlmFlagRequestTarget |
+		ntlmFlagNegotiateSign |
+		ntlmFlagNegotiateSeal |
+		ntlmFlagNegotiateAlwaysSign |
+		ntlmFlagNegotiateExtendedSessionSecurity |
+		ntlmFlagNegotiateVersion |
+		ntlmFlagNegotiate128 |
+		ntlmFlagNegotiateKeyExch |
+		ntlmFlagNegotiate56
+
+	ntlmAVIDEOL             = 0
+	ntlmAVIDNbComputerName  = 1
+	ntlmAVIDNbDomainName    = 2
+	ntlmAVIDDNSComputerName = 3
+	ntlmAVIDDNSDomainName   = 4
+	ntlmAVIDTimestamp       = 7
+
+	ntlmChallengeHeaderSizeBytes    = 56
+	ntlmAuthenticateHeaderSizeBytes = 64
+	ntlmProofSizeBytes              = 16
+)
+
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+// ntlmVersion is the VERSION structure that is sent as part of the
+// CHALLENGE message. It announces Windows 6.1 (build 7601), using
+// NTLMSSP revision 15.
+var ntlmVersion = []byte{6, 1, 0xb1, 0x1d, 0, 0, 0, 15}
+
+// getNTLMMessageType returns the type of an NTLM message.
+func getNTLMMessageType(b []byte) (uint32, bool) {
+	if len(b) < 12 || !bytes.HasPrefix(b, ntlmSignature) {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint32(b[8:]), true
+}
+
+// getNTLMNegotiateFlags returns the flags that are stored in an NTLM
+// NEGOTIATE message.
+func getNTLMNegotiateFlags(b []byte) (uint32, bool) {
+	if len(b) < 16 {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint32(b[12:]), true
+}
+
+// getNTLMChallengeFlags computes the flags that are sent back to the
+// client as part of the CHALLENGE message, based on the flags that
+// were requested by the client.
+func getNTLMChallengeFlags(negotiateFlags uint32) uint32 {
+	return negotiateFlags&ntlmFlagsSupported |
+		ntlmFlagNegotiateUnicode |
+		ntlmFlagNegotiateNTLM |
+		ntlmFlagTargetTypeServer |
+		ntlmFlagNegotiateTargetInfo
+}
+
+// appendNTLMField appends the length and offset of a variable length
+// field to the header of an NTLM message, and appends its contents to
+// the payload.
+func appendNTLMField(header, payload []byte, payloadOffset int, value []byte) ([]byte, []byte) {
+	header = binary.LittleEndian.AppendUint16(header, uint16(len(value)))
+	header = binary.LittleEndian.AppendUint16(header, uint16(len(value)))
+	header = binary.LittleEndian.AppendUint32(header, uint32(payloadOffset+len(payload)))
+	return header, append(payload, value...)
+}
+
+// appendNTLMAVPair appends an AV_PAIR to the target information that
+// is sent as part of the CHALLENGE message.
+func appendNTLMAVPair(b []byte, id uint16, value []byte) []byte {
+	b = binary.LittleEndian.AppendUint16(b, id)
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(value)))
+	return append(b, value...)
+}
+
+// newNTLMChallengeMessage creates an NTLM CHALLENGE message, containing
+// the challenge that the client needs to use to prove knowledge of the
+// user's password.
+func newNTLMChallengeMessage(flags uint32, serverChallenge *[8]byte, targetName string, timestamp uint64) []byte {
+	encodedTargetName := encodeUTF16(targetName)
+	var targetInfo []byte
+	targetInfo = appendNTLMAVPair(targetInfo, ntlmAVIDNbDomainName, encodedTargetName)
+	targetInfo = appendNTLMAVPair(targetInfo, ntlmAVIDNbComputerName, encodedTargetName)
+	targetInfo = appendNTLMAVPair(targetInfo, ntlmAVIDDNSDomainName, encodedTargetName)
+	targetInfo = appendNTLMAVPair(targetInfo, ntlmAVIDDNSComputerName, encodedTargetName)
+	targetInfo = appendNTLMAVPair(targetInfo, ntlmAVIDTimestamp, binary.LittleEndian.AppendUint64(nil, timestamp))
+	targetInfo = appendNTLMAVPair(targetInfo, ntlmAVIDEOL, nil)
+
+	header := append([]byte(nil), ntlmSignature...)
+	header = binary.LittleEndian.AppendUint32(header, ntlmMessageTypeChallenge)
+	var payload []byte
+	header, payload = appendNTLMField(header, payload, ntlmChallengeHeaderSizeBytes, encodedTargetName)
+	header = binary.LittleEndian.AppendUint32(header, flags)
+	header = append(header, serverChallenge[:]...)
+	header = append(header, make([]byte, 8)...)
+	header, payload = appendNTLMField(header, payload, ntlmChallengeHeaderSizeBytes, targetInfo)
+	header = append(header, ntlmVersion...)
+	return append(header, payload...)
+}
+
+// ntlmAuthenticateMessage contains the fields of an NTLM AUTHENTICATE
+// message that are needed to validate the client's credentials.
+type ntlmAuthenticateMessage struct {
+	ntChallengeResponse       []byte
+	domainName                string
+	userName                  string
+	encryptedRandomSessionKey []byte
+	flags                     uint32
+}
+
+// parseNTLMAuthenticateMessage parses an NTLM AUTHENTICATE message.
+// As the server always negotiates Unicode, strings are decoded as
+// UTF-16.
+func parseNTLMAuthenticateMessage(b []byte) (ntlmAuthenticateMessage, bool) {
+	if len(b) < ntlmAuthenticateHeaderSizeBytes {
+		return ntlmAuthenticateMessage{}, false
+	}
+	getField := func(offset int) ([]byte, bool) {
+		length := uint32(binary.LittleEndian.Uint16(b[offset:]))
+		fieldOffset := binary.LittleEndian.Uint32(b[offset+4:])
+		if uint64(fieldOffset)+uint64(length) > uint64(len(b)) {
+			return nil, false
+		}
+		return b[fieldOffset : fieldOffset+length], true
+	}
+	getStringField := func(offset int) (string, bool) {
+		field, ok := getField(offset)
+		if !ok {
+			return "", false
+		}
+		return decodeUTF16(field)
+	}
+
+	var m ntlmAuthenticateMessage
+	var ok bool
+	if m.ntChallengeResponse, ok = getField(20); !ok {
+		return ntlmAuthenticateMessage{}, false
+	}
+	if m.domainName, ok = getStringField(28); !ok {
+		return ntlmAuthenticateMessage{}, false
+	}
+	if m.userName, ok = getStringField(36); !ok {
+		return ntlmAuthenticateMessage{}, false
+	}
+	if m.encryptedRandomSessionKey, ok = getField(52); !ok {
+		return ntlmAuthenticateMessage{}, false
+	}
+	m.flags = binary.LittleEndian.Uint32(b[60:])
+	return m, true
+}
+
+func hmacMD5(key []byte, data ...[]byte) []byte {
+	h := hmac.New(md5.New, key)
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// verifyNTLMv2Response validates an NTLMv2 response that was computed
+// by the client, using the NT hash of the user's password. Upon
+// success, it returns the session base key.
+//
+// Clients differ in which domain name they use to compute the
+// response, as there is no domain that the server is a member of.
+// Both the domain name provided by the client and the empty domain name
+// are therefore accepted.
+func verifyNTLMv2Response(ntHash []byte, userName, domainName string, serverChallenge *[8]byte, ntChallengeResponse []byte) ([]byte, bool) {
+	if len(ntChallengeResponse) <= ntlmProofSizeBytes {
+		// NTLMv1 responses, or no response at all.
+		return nil, false
+	}
+	ntProofStr := ntChallengeResponse[:ntlmProofSizeBytes]
+	temp := ntChallengeResponse[ntlmProofSizeBytes:]
+	for _, domain := range []string{domainName, strings.ToUpper(domainName), ""} {
+		responseKeyNT := hmacMD5(ntHash, encodeUTF16(strings.ToUpper(userName)+domain))
+		if hmac.Equal(hmacMD5(responseKeyNT, serverChallenge[:], temp), ntProofStr) {
+			return hmacMD5(responseKeyNT, ntProofStr), true
+		}
+	}
+	return nil, false
+}
+
+// getNTLMExportedSessionKey computes the session key that is used to
+// sign messages, given the session base key. If key exchange has been
+// negotiated, the client picks a random session key, which it sends to
+// the server in encrypted form.
+func getNTLMExportedSessionKey(flags uint32, sessionBaseKey, encryptedRandomSessionKey []byte) ([]byte, bool) {
+	if flags&ntlmFlagNegotiateKeyExch == 0 {
+		return sessionBaseKey, true
+	}
+	if len(encryptedRandomSessionKey) != md5.Size {
+		return nil, false
+	}
+	c, err := rc4.NewCipher(sessionBaseKey)
+	if err != nil {
+		return nil, false
+	}
+	exportedSessionKey := make([]byte, md5.Size)
+	c.XORKeyStream(exportedSessionKey, encryptedRandomSessionKey)
+	return exportedSessionKey, true
+}
+
+// Magic constants that are used to derive signing and sealing keys.
+const (
+	ntlmClientSigningMagic = "session key to client-to-server signing key magic constant\x00"
+	ntlmServerSigningMagic = "session key to server-to-client signing key magic constant\x00"
+	ntlmClientSealingMagic = "session key to client-to-server sealing key magic constant\x00"
+	ntlmServerSealingMagic = "session key to server-to-client sealing key magic constant\x00"
+)
+
+// computeNTLMSignature computes the signature of a message, as used by
+// SPNEGO's mechListMIC. As only a single message is ever signed in
+// either direction, the sequence number is always zero.
+func computeNTLMSignature(flags uint32, exportedSessionKey []byte, signingMagic, sealingMagic string, message []byte) []byte {
+	signingKey := md5.Sum(append(append([]byte(nil), exportedSessionKey...), signingMagic...))
+	var sequenceNumber [4]byte
+	checksum := hmacMD5(signingKey[:], sequenceNumber[:], message)[:8]
+
+	if flags&ntlmFlagNegotiateKeyExch != 0 {
+		sealingKeySizeBytes := 5
+		if flags&ntlmFlagNegotiate128 != 0 {
+			sealingKeySizeBytes = 16
+		} else if flags&ntlmFlagNegotiate56 != 0 {
+			sealingKeySizeBytes = 7
+		}
+		sealingKey := md5.Sum(append(append([]byte(nil), exportedSessionKey[:sealingKeySizeBytes]...), sealingMagic...))
+		c, err := rc4.NewCipher(sealingKey[:])
+		if err != nil {
+			panic(err)
+		}
+		c.XORKeyStream(checksum, checksum)
+	}
+
+	signature := binary.LittleEndian.AppendUint32(nil, 1)
+	signature = append(signature, checksum...)
+	return append(signature, sequenceNumber[:]...)
+}
@@ -0,0 +1,996 @@
+package smb
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"io"
+	"strings"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/random"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorResponseBody is the body of an SMB2 ERROR response that does
+// not contain any error data.
+var errorResponseBody = []byte{9, 0, 0, 0, 0, 0, 0, 0, 0}
+
+// Server of the SMB2 protocol that exposes a hierarchy of Directory and
+// Leaf objects as a single share. This makes it possible to access the
+// virtual file system from Windows, which provides no FUSE or NFSv4
+// client that can be used for this purpose.
+//
+// The server implements dialects 2.0.2, 2.1, 3.0, 3.0.2 and 3.1.1.
+// Users are authenticated using NTLMv2. Messages are signed using
+// HMAC-SHA256 (SMB 2.x) or AES-128-CMAC (SMB 3.x) if the client
+// requests it, and may be encrypted using AES-128-CCM or AES-128-GCM
+// if SMB 3.x is negotiated. Multichannel, persistent handles, oplocks,
+// leases, change notifications and alternate data streams are not
+// supported.
+//
+// This implementation keeps track of open files on a per-connection
+// basis, meaning that it does not need to resolve file handles. It
+// should therefore be used in combination with a handle allocator that
+// is suited for stateful protocols, such as the one used by FUSE.
+type Server struct {
+	rootDirectory         virtual.Directory
+	serverName            string
+	shareName             string
+	ntHashes              map[string][]byte
+	allowGuest            bool
+	requireEncryption     bool
+	clock                 clock.Clock
+	randomNumberGenerator random.ThreadSafeGenerator
+	serverGUID            [16]byte
+}
+
+// NewServer creates an SMB2 server that exposes the provided root
+// directory as a share with a given name. Connections may be handed to
+// the server by calling HandleConnection().
+//
+// Users may authenticate using one of the provided usernames, for
+// which the NT hash (i.e., the MD4 hash of the UTF-16 encoded password)
+// is provided. This prevents the need for storing plaintext passwords.
+// If guest access is allowed, users with other usernames are granted
+// anonymous access. Usernames are case insensitive.
+//
+// If encryption is required, sessions may only be established by
+// authenticated users whose clients support SMB 3.x encryption, and
+// all requests sent as part of these sessions must be encrypted.
+func NewServer(rootDirectory virtual.Directory, serverName, shareName string, userNTHashes map[string][]byte, allowGuest, requireEncryption bool, clock clock.Clock, randomNumberGenerator random.ThreadSafeGenerator) *Server {
+	ntHashes := make(map[string][]byte, len(userNTHashes))
+	for userName, ntHash := range userNTHashes {
+		ntHashes[strings.ToUpper(userName)] = ntHash
+	}
+	s := &Server{
+		rootDirectory:         rootDirectory,
+		serverName:            serverName,
+		shareName:             shareName,
+		ntHashes:              ntHashes,
+		allowGuest:            allowGuest,
+		requireEncryption:     requireEncryption,
+		clock:                 clock,
+		randomNumberGenerator: randomNumberGenerator,
+	}
+	randomNumberGenerator.Read(s.serverGUID[:])
+	return s
+}
+
+// HandleConnection processes SMB2 requests received from a single
+// client, until the connection is closed. Requests are processed
+// sequentially, in the order in which they are received. This means
+// that no operations ever need to be processed asynchronously.
+//
+// Files that are still opened when the connection is closed are closed
+// implicitly.
+func (s *Server) HandleConnection(ctx context.Context, r io.Reader, w io.Writer) error {
+	c := connection{
+		server:    s,
+		ctx:       ctx,
+		sessions:  map[uint64]*session{},
+		openFiles: map[uint64]*openFile{},
+	}
+	defer c.closeAllFiles()
+
+	var header [transportHeaderSizeBytes]byte
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return util.StatusWrap(err, "Failed to read transport header")
+		}
+		size := binary.BigEndian.Uint32(header[:])
+		if size > maximumFrameSizeBytes {
+			return status.Errorf(codes.InvalidArgument, "Frame has size %d, while the maximum permitted size is %d", size, maximumFrameSizeBytes)
+		}
+		frame := make([]byte, size)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return util.StatusWrap(err, "Failed to read frame")
+		}
+
+		response, err := c.handleFrame(frame)
+		if err != nil {
+			return err
+		}
+		if len(response) > 0 {
+			binary.BigEndian.PutUint32(header[:], uint32(len(response)))
+			if _, err := w.Write(append(header[:], response...)); err != nil {
+				return util.StatusWrap(err, "Failed to write response")
+			}
+		}
+	}
+}
+
+// session that has been established by the client through
+// SESSION_SETUP.
+type session struct {
+	// Fields that are used while authentication is in progress.
+	rawNTLM         bool
+	mechTypes       []byte
+	challengeSent   bool
+	challengeFlags  uint32
+	serverChallenge [8]byte
+	preauthHash     []byte
+
+	// Fields that are set after authentication completes. Signing
+	// and encryption are only possible if the user was not logged
+	// in as a guest.
+	authenticated   bool
+	signingKey      []byte
+	signingRequired bool
+	encrypter       cipher.AEAD
+	decrypter       cipher.AEAD
+	nextNonce       uint64
+	encryptData     bool
+	trees           map[uint32]*tree
+}
+
+// tree that has been connected by the client through TREE_CONNECT.
+type tree struct {
+	// Whether the tree corresponds to the IPC$ share. Clients
+	// connect to this share to access named pipes, which are not
+	// supported. Connecting to it is permitted, as some clients
+	// refuse to continue otherwise.
+	isIPC bool
+}
+
+// compoundState keeps track of values that are inherited by related
+// operations that are part of a compound request.
+type compoundState struct {
+	sessionID    uint64
+	treeID       uint32
+	fileID       []byte
+	createStatus uint32
+
+	// If the request was encrypted, the session whose key was used
+	// to decrypt it. Messages in encrypted requests are not signed.
+	encryptingSession *session
+}
+
+// request that is being processed by the server.
+type request struct {
+	message   []byte
+	body      []byte
+	sessionID uint64
+	treeID    uint32
+	session   *session
+	tree      *tree
+	compound  *compoundState
+}
+
+// response that is generated by the server, prior to being combined
+// with other responses that are part of the same compound request.
+type response struct {
+	message    []byte
+	signingKey []byte
+}
+
+type connection struct {
+	server             *Server
+	ctx                context.Context
+	dialect            uint16
+	clientSecurityMode uint16
+	signingAlgorithm   uint16
+	cipher             uint16
+	preauthHash        []byte
+	sessions           map[uint64]*session
+	lastSessionID      uint64
+	lastTreeID         uint32
+	openFiles          map[uint64]*openFile
+	lastFileID         uint64
+}
+
+// handleFrame processes all requests that are stored in a single
+// frame, and returns the frame containing the responses.
+func (c *connection) handleFrame(frame []byte) ([]byte, error) {
+	if bytes.HasPrefix(frame, protocolIDSMB1[:]) {
+		return c.handleSMB1Negotiate(frame)
+	}
+	if bytes.HasPrefix(frame, protocolIDTransform[:]) {
+		return c.handleEncryptedFrame(frame)
+	}
+	return c.handleMessages(frame, &compoundState{createStatus: statusFileClosed})
+}
+
+// handleEncryptedFrame processes a frame that starts with a transform
+// header. The frame is decrypted using the key of the session in the
+// transform header, and the responses are encrypted using the same
+// session.
+//
+// Reference: [MS-SMB2] sections 3.3.5.2.1 and 3.3.4.1.4.
+func (c *connection) handleEncryptedFrame(frame []byte) ([]byte, error) {
+	if len(frame) < transformHeaderSizeBytes {
+		return nil, status.Error(codes.InvalidArgument, "Frame does not contain a valid transform header")
+	}
+	originalMessageSize := binary.LittleEndian.Uint32(frame[36:])
+	sessionID := binary.LittleEndian.Uint64(frame[44:])
+	s, ok := c.sessions[sessionID]
+	if !ok || s.decrypter == nil || binary.LittleEndian.Uint16(frame[42:]) != 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "Client sent an encrypted message for session %d, which does not support encryption", sessionID)
+	}
+
+	ciphertext := make([]byte, 0, len(frame)-transformHeaderSizeBytes+16)
+	ciphertext = append(ciphertext, frame[transformHeaderSizeBytes:]...)
+	ciphertext = append(ciphertext, frame[4:20]...)
+	plaintext, err := s.decrypter.Open(nil, frame[20:20+s.decrypter.NonceSize()], ciphertext, frame[20:transformHeaderSizeBytes])
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Failed to decrypt message")
+	}
+	if uint32(len(plaintext)) != originalMessageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "Encrypted message has size %d, while the transform header specifies size %d", len(plaintext), originalMessageSize)
+	}
+
+	out, err := c.handleMessages(plaintext, &compoundState{
+		createStatus:      statusFileClosed,
+		encryptingSession: s,
+	})
+	if err != nil || len(out) == 0 {
+		return out, err
+	}
+
+	// Encrypt the responses. Nonces are derived from a counter, so
+	// that they are never reused for the same key.
+	header := make([]byte, transformHeaderSizeBytes)
+	copy(header, protocolIDTransform[:])
+	binary.LittleEndian.PutUint64(header[20:], s.nextNonce)
+	s.nextNonce++
+	binary.LittleEndian.PutUint32(header[36:], uint32(len(out)))
+	binary.LittleEndian.PutUint16(header[42:], 1)
+	binary.LittleEndian.PutUint64(header[44:], sessionID)
+	sealed := s.encrypter.Seal(nil, header[20:20+s.encrypter.NonceSize()], out, header[20:])
+	copy(header[4:20], sealed[len(out):])
+	return append(header, sealed[:len(out)]...), nil
+}
+
+// handleMessages processes all requests that are stored in a single
+// frame, or in a single decrypted message, and returns the combined
+// responses.
+func (c *connection) handleMessages(frame []byte, compound *compoundState) ([]byte, error) {
+	var responses []response
+	for {
+		if len(frame) < headerSizeBytes || !bytes.HasPrefix(frame, protocolIDSMB2[:]) {
+			return nil, status.Error(codes.InvalidArgument, "Frame does not contain a valid SMB2 message")
+		}
+		message := frame
+		nextCommand := binary.LittleEndian.Uint32(frame[20:])
+		if nextCommand != 0 {
+			if nextCommand < headerSizeBytes || nextCommand%8 != 0 || nextCommand > uint32(len(frame)) {
+				return nil, status.Errorf(codes.InvalidArgument, "Compound request has invalid next command offset %d", nextCommand)
+			}
+			message = frame[:nextCommand]
+		}
+
+		r, err := c.handleMessage(message, compound)
+		if err != nil {
+			return nil, err
+		}
+		if r.message != nil {
+			responses = append(responses, r)
+		}
+
+		if nextCommand == 0 {
+			break
+		}
+		frame = frame[nextCommand:]
+	}
+
+	// Combine all responses into a single frame. Responses need to
+	// be padded to eight bytes, and signatures are computed over the
+	// padded responses.
+	var out []byte
+	for i, r := range responses {
+		message := r.message
+		if i < len(responses)-1 {
+			message = padTo8(message)
+			binary.LittleEndian.PutUint32(message[20:], uint32(len(message)))
+		}
+		if r.signingKey != nil {
+			c.signMessage(r.signingKey, message)
+		}
+		out = append(out, message...)
+	}
+	return out, nil
+}
+
+// computeSignature computes the signature of a message. SMB 2.x uses
+// HMAC-SHA256, while SMB 3.x uses AES-128-CMAC, unless HMAC-SHA256 is
+// negotiated explicitly.
+func (c *connection) computeSignature(signingKey, message []byte) []byte {
+	if c.signingAlgorithm == signingAlgorithmAESCMAC {
+		zeroed := append([]byte(nil), message...)
+		clear(zeroed[48:64])
+		return computeAESCMAC(signingKey, zeroed)
+	}
+	h := hmac.New(sha256.New, signingKey)
+	h.Write(message[:48])
+	h.Write(make([]byte, 16))
+	h.Write(message[64:])
+	return h.Sum(nil)[:16]
+}
+
+func (c *connection) signMessage(signingKey, message []byte) {
+	flags := binary.LittleEndian.Uint32(message[16:])
+	binary.LittleEndian.PutUint32(message[16:], flags|flagsSigned)
+	copy(message[48:64], c.computeSignature(signingKey, message))
+}
+
+// updatePreauthHash extends a preauthentication integrity hash with a
+// message, as done by SMB 3.1.1 to protect NEGOTIATE and SESSION_SETUP
+// against tampering.
+//
+// Reference: [MS-SMB2] section 3.3.5.4.
+func updatePreauthHash(preauthHash, message []byte) []byte {
+	h := sha512.New()
+	h.Write(preauthHash)
+	h.Write(message)
+	return h.Sum(nil)
+}
+
+// handleMessage processes a single SMB2 request.
+func (c *connection) handleMessage(message []byte, compound *compoundState) (response, error) {
+	command := binary.LittleEndian.Uint16(message[12:])
+	creditRequest := binary.LittleEndian.Uint16(message[14:])
+	flags := binary.LittleEndian.Uint32(message[16:])
+	r := request{
+		message:   message,
+		body:      message[headerSizeBytes:],
+		sessionID: binary.LittleEndian.Uint64(message[40:]),
+		treeID:    binary.LittleEndian.Uint32(message[36:]),
+		compound:  compound,
+	}
+	if flags&flagsRelatedOperations != 0 {
+		r.sessionID = compound.sessionID
+		r.treeID = compound.treeID
+	}
+
+	var body []byte
+	var st uint32
+	switch command {
+	case commandNegotiate:
+		if c.dialect != 0 {
+			return response{}, status.Error(codes.InvalidArgument, "Client attempted to negotiate the dialect multiple times")
+		}
+		body, st = c.handleNegotiate(&r)
+	case commandSessionSetup:
+		body, st = c.handleSessionSetup(&r)
+	case commandEcho:
+		body, st = []byte{4, 0, 0, 0}, statusSuccess
+	case commandCancel:
+		// All requests are processed synchronously, meaning
+		// there is nothing to cancel. CANCEL requests don't
+		// receive a response.
+		return response{}, nil
+	default:
+		if c.dialect == 0 {
+			return response{}, status.Error(codes.InvalidArgument, "Client did not negotiate a dialect")
+		}
+		body, st = c.handleSessionMessage(&r, command)
+	}
+
+	compound.sessionID = r.sessionID
+	compound.treeID = r.treeID
+	if body == nil {
+		body = errorResponseBody
+	}
+
+	// Grant as many credits as requested, so that the client never
+	// stalls.
+	credits := creditRequest
+	if credits < 1 {
+		credits = 1
+	} else if credits > maximumCreditsGranted {
+		credits = maximumCreditsGranted
+	}
+
+	out := make([]byte, headerSizeBytes, headerSizeBytes+len(body))
+	copy(out, protocolIDSMB2[:])
+	binary.LittleEndian.PutUint16(out[4:], headerSizeBytes)
+	copy(out[6:8], message[6:8])
+	binary.LittleEndian.PutUint32(out[8:], st)
+	binary.LittleEndian.PutUint16(out[12:], command)
+	binary.LittleEndian.PutUint16(out[14:], credits)
+	binary.LittleEndian.PutUint32(out[16:], flagsServerToRedir|flags&flagsRelatedOperations)
+	copy(out[24:36], message[24:36])
+	binary.LittleEndian.PutUint32(out[36:], r.treeID)
+	binary.LittleEndian.PutUint64(out[40:], r.sessionID)
+	out = append(out, body...)
+
+	// SMB 3.1.1 computes a hash over all NEGOTIATE and
+	// SESSION_SETUP messages up to the point where keys are
+	// derived.
+	if c.dialect == dialectSMB311 {
+		if command == commandNegotiate && st == statusSuccess {
+			c.preauthHash = updatePreauthHash(c.preauthHash, out)
+		} else if command == commandSessionSetup && st == statusMoreProcessingRequired {
+			r.session.preauthHash = updatePreauthHash(r.session.preauthHash, out)
+		}
+	}
+
+	// Sign the response if the request was signed, or if signing
+	// is required. With SMB 2.x the final SESSION_SETUP response
+	// only needs to be signed if signing is required, while SMB
+	// 3.x always requires it to be signed. Responses that are
+	// encrypted are not signed.
+	var signingKey []byte
+	if s := r.session; s != nil && s.authenticated && s.signingKey != nil && compound.encryptingSession == nil {
+		if s.signingRequired || (command == commandSessionSetup && c.dialect >= dialectSMB300) || (command != commandSessionSetup && flags&flagsSigned != 0) {
+			signingKey = s.signingKey
+		}
+	}
+	return response{message: out, signingKey: signingKey}, nil
+}
+
+// handleSessionMessage processes requests that can only be sent after
+// a session has been established.
+func (c *connection) handleSessionMessage(r *request, command uint16) ([]byte, uint32) {
+	s, ok := c.sessions[r.sessionID]
+	if !ok || !s.authenticated {
+		return nil, statusUserSessionDeleted
+	}
+	r.session = s
+	if e := r.compound.encryptingSession; e != nil {
+		// Encrypted requests may only refer to the session
+		// whose key was used to encrypt them.
+		if e != s {
+			return nil, statusAccessDenied
+		}
+	} else if s.encryptData {
+		return nil, statusAccessDenied
+	} else if s.signingKey != nil {
+		flags := binary.LittleEndian.Uint32(r.message[16:])
+		if flags&flagsSigned != 0 {
+			if !hmac.Equal(c.computeSignature(s.signingKey, r.message), r.message[48:64]) {
+				return nil, statusAccessDenied
+			}
+		} else if s.signingRequired {
+			return nil, statusAccessDenied
+		}
+	}
+
+	switch command {
+	case commandLogoff:
+		return c.handleLogoff(r)
+	case commandTreeConnect:
+		return c.handleTreeConnect(r)
+	}
+
+	t, ok := s.trees[r.treeID]
+	if !ok {
+		return nil, statusNetworkNameDeleted
+	}
+	r.tree = t
+	switch command {
+	case commandTreeDisconnect:
+		return c.handleTreeDisconnect(r)
+	case commandCreate:
+		return c.handleCreate(r)
+	case commandClose:
+		return c.handleClose(r)
+	case commandFlush:
+		return c.handleFlush(r)
+	case commandRead:
+		return c.handleRead(r)
+	case commandWrite:
+		return c.handleWrite(r)
+	case commandLock:
+		return c.handleLock(r)
+	case commandIoctl:
+		return c.handleIoctl(r)
+	case commandQueryDirectory:
+		return c.handleQueryDirectory(r)
+	case commandQueryInfo:
+		return c.handleQueryInfo(r)
+	case commandSetInfo:
+		return c.handleSetInfo(r)
+	default:
+		// Change notifications are not supported. Oplock
+		// breaks are never sent by clients, as oplocks are
+		// never granted.
+		return nil, statusNotSupported
+	}
+}
+
+// handleSMB1Negotiate processes an SMB1 NEGOTIATE request. Clients that
+// support both SMB1 and SMB2 may send such a request to initiate the
+// connection. If the client supports SMB2, the server responds with an
+// SMB2 NEGOTIATE response.
+//
+// Reference: [MS-SMB2] section 3.3.5.3.
+func (c *connection) handleSMB1Negotiate(frame []byte) ([]byte, error) {
+	const smb1HeaderSizeBytes = 32
+	const smb1CommandNegotiate = 0x72
+	if c.dialect != 0 || len(frame) < smb1HeaderSizeBytes+3 || frame[4] != smb1CommandNegotiate {
+		return nil, status.Error(codes.InvalidArgument, "Client sent an SMB1 request other than NEGOTIATE")
+	}
+	parameters := frame[smb1HeaderSizeBytes+1:]
+	wordCount := int(frame[smb1HeaderSizeBytes])
+	if len(parameters) < 2*wordCount+2 {
+		return nil, status.Error(codes.InvalidArgument, "SMB1 NEGOTIATE request is truncated")
+	}
+
+	dialect := uint16(0)
+	for _, name := range bytes.Split(parameters[2*wordCount+2:], []byte{0}) {
+		switch string(bytes.TrimPrefix(name, []byte{2})) {
+		case "SMB 2.???":
+			dialect = dialectSMB2Wildcard
+		case "SMB 2.002":
+			if dialect == 0 {
+				dialect = dialectSMB202
+			}
+		}
+	}
+	if dialect == 0 {
+		return nil, status.Error(codes.Unimplemented, "Client does not support SMB2")
+	}
+	if dialect != dialectSMB2Wildcard {
+		c.dialect = dialect
+	}
+
+	out := make([]byte, headerSizeBytes)
+	copy(out, protocolIDSMB2[:])
+	binary.LittleEndian.PutUint16(out[4:], headerSizeBytes)
+	binary.LittleEndian.PutUint16(out[12:], commandNegotiate)
+	binary.LittleEndian.PutUint16(out[14:], 1)
+	binary.LittleEndian.PutUint32(out[16:], flagsServerToRedir)
+	return append(out, c.newNegotiateResponseBody(dialect, 0, nil)...), nil
+}
+
+func (c *connection) newNegotiateResponseBody(dialect uint16, capabilities uint32, negotiateContexts [][]byte) []byte {
+	securityBuffer := newSPNEGONegTokenInit()
+	b := make([]byte, 64, 64+len(securityBuffer))
+	binary.LittleEndian.PutUint16(b[0:], 65)
+	binary.LittleEndian.PutUint16(b[2:], securityModeSigningEnabled)
+	binary.LittleEndian.PutUint16(b[4:], dialect)
+	copy(b[8:24], c.server.serverGUID[:])
+	binary.LittleEndian.PutUint32(b[24:], capabilities)
+	binary.LittleEndian.PutUint32(b[28:], maximumTransactSizeBytes)
+	binary.LittleEndian.PutUint32(b[32:], maximumTransactSizeBytes)
+	binary.LittleEndian.PutUint32(b[36:], maximumTransactSizeBytes)
+	binary.LittleEndian.PutUint64(b[40:], toFileTime(c.server.clock.Now()))
+	binary.LittleEndian.PutUint16(b[56:], headerSizeBytes+64)
+	binary.LittleEndian.PutUint16(b[58:], uint16(len(securityBuffer)))
+	b = append(b, securityBuffer...)
+
+	// Negotiate contexts are placed after the security buffer,
+	// each aligned to eight bytes.
+	if len(negotiateContexts) > 0 {
+		b = padTo8(b)
+		binary.LittleEndian.PutUint16(b[6:], uint16(len(negotiateContexts)))
+		binary.LittleEndian.PutUint32(b[60:], uint32(headerSizeBytes+len(b)))
+		for i, negotiateContext := range negotiateContexts {
+			if i > 0 {
+				b = padTo8(b)
+			}
+			b = append(b, negotiateContext...)
+		}
+	}
+	return b
+}
+
+// newNegotiateContext encodes a negotiate context that is returned as
+// part of an SMB 3.1.1 NEGOTIATE response.
+func newNegotiateContext(contextType uint16, data []byte) []byte {
+	b := make([]byte, 8, 8+len(data))
+	binary.LittleEndian.PutUint16(b[0:], contextType)
+	binary.LittleEndian.PutUint16(b[2:], uint16(len(data)))
+	return append(b, data...)
+}
+
+// getNegotiateContextAlgorithms returns the list of algorithms that
+// is stored in the data of a negotiate context. The data starts with
+// the number of algorithms, while the algorithms themselves are stored
+// at a given offset.
+func getNegotiateContextAlgorithms(data []byte, algorithmsOffset int) ([]uint16, bool) {
+	if len(data) < algorithmsOffset {
+		return nil, false
+	}
+	count := int(binary.LittleEndian.Uint16(data))
+	if count == 0 || len(data) < algorithmsOffset+2*count {
+		return nil, false
+	}
+	algorithms := make([]uint16, 0, count)
+	for i := 0; i < count; i++ {
+		algorithms = append(algorithms, binary.LittleEndian.Uint16(data[algorithmsOffset+2*i:]))
+	}
+	return algorithms, true
+}
+
+// selectAlgorithm returns the first algorithm in a list of preferred
+// algorithms that is also offered by the client.
+func selectAlgorithm(offered, preferred []uint16) (uint16, bool) {
+	for _, p := range preferred {
+		for _, o := range offered {
+			if o == p {
+				return p, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// handleNegotiateContexts processes the negotiate contexts that are
+// sent by clients that negotiate SMB 3.1.1, and returns the negotiate
+// contexts that are sent back.
+//
+// Reference: [MS-SMB2] section 3.3.5.4.
+func (c *connection) handleNegotiateContexts(r *request) ([][]byte, bool) {
+	offset := binary.LittleEndian.Uint32(r.body[28:])
+	count := int(binary.LittleEndian.Uint16(r.body[32:]))
+	if offset < headerSizeBytes+36 || uint64(offset) > uint64(len(r.message)) {
+		return nil, false
+	}
+	contexts := r.message[offset:]
+
+	var responseContexts [][]byte
+	sawPreauthIntegrity := false
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			padding := (8 - (len(r.message)-len(contexts))%8) % 8
+			if len(contexts) < padding {
+				return nil, false
+			}
+			contexts = contexts[padding:]
+		}
+		if len(contexts) < 8 {
+			return nil, false
+		}
+		contextType := binary.LittleEndian.Uint16(contexts[0:])
+		dataLength := int(binary.LittleEndian.Uint16(contexts[2:]))
+		if len(contexts) < 8+dataLength {
+			return nil, false
+		}
+		data := contexts[8 : 8+dataLength]
+		contexts = contexts[8+dataLength:]
+
+		switch contextType {
+		case negotiateContextPreauthIntegrity:
+			hashAlgorithms, ok := getNegotiateContextAlgorithms(data, 4)
+			if !ok {
+				return nil, false
+			}
+			if _, ok := selectAlgorithm(hashAlgorithms, []uint16{hashAlgorithmSHA512}); !ok {
+				return nil, false
+			}
+			sawPreauthIntegrity = true
+			responseData := make([]byte, 6, 6+32)
+			binary.LittleEndian.PutUint16(responseData[0:], 1)
+			binary.LittleEndian.PutUint16(responseData[2:], 32)
+			binary.LittleEndian.PutUint16(responseData[4:], hashAlgorithmSHA512)
+			var salt [32]byte
+			c.server.randomNumberGenerator.Read(salt[:])
+			responseContexts = append(responseContexts, newNegotiateContext(contextType, append(responseData, salt[:]...)))
+		case negotiateContextEncryption:
+			ciphers, ok := getNegotiateContextAlgorithms(data, 2)
+			if !ok {
+				return nil, false
+			}
+			// If no common cipher exists, the response
+			// contains cipher zero.
+			c.cipher, _ = selectAlgorithm(ciphers, []uint16{cipherAES128GCM, cipherAES128CCM})
+			responseData := make([]byte, 4)
+			binary.LittleEndian.PutUint16(responseData[0:], 1)
+			binary.LittleEndian.PutUint16(responseData[2:], c.cipher)
+			responseContexts = append(responseContexts, newNegotiateContext(contextType, responseData))
+		case negotiateContextSigning:
+			signingAlgorithms, ok := getNegotiateContextAlgorithms(data, 2)
+			if !ok {
+				return nil, false
+			}
+			if c.signingAlgorithm, ok = selectAlgorithm(signingAlgorithms, []uint16{signingAlgorithmAESCMAC, signingAlgorithmHMACSHA256}); !ok {
+				return nil, false
+			}
+			responseData := make([]byte, 4)
+			binary.LittleEndian.PutUint16(responseData[0:], 1)
+			binary.LittleEndian.PutUint16(responseData[2:], c.signingAlgorithm)
+			responseContexts = append(responseContexts, newNegotiateContext(contextType, responseData))
+		}
+	}
+	return responseContexts, sawPreauthIntegrity
+}
+
+func (c *connection) handleNegotiate(r *request) ([]byte, uint32) {
+	if len(r.body) < 36 {
+		return nil, statusInvalidParameter
+	}
+	dialectCount := int(binary.LittleEndian.Uint16(r.body[2:]))
+	if len(r.body) < 36+2*dialectCount {
+		return nil, statusInvalidParameter
+	}
+	dialect := uint16(0)
+	for i := 0; i < dialectCount; i++ {
+		switch d := binary.LittleEndian.Uint16(r.body[36+2*i:]); d {
+		case dialectSMB202, dialectSMB210, dialectSMB300, dialectSMB302, dialectSMB311:
+			if d > dialect {
+				dialect = d
+			}
+		}
+	}
+
+	var capabilities uint32
+	var negotiateContexts [][]byte
+	switch {
+	case dialect == 0:
+		return nil, statusNotSupported
+	case dialect < dialectSMB300:
+		c.signingAlgorithm = signingAlgorithmHMACSHA256
+	case dialect < dialectSMB311:
+		// SMB 3.0 and 3.0.2 only support AES-128-CCM, which is
+		// offered through a capability flag.
+		c.signingAlgorithm = signingAlgorithmAESCMAC
+		if binary.LittleEndian.Uint32(r.body[8:])&globalCapEncryption != 0 {
+			c.cipher = cipherAES128CCM
+			capabilities |= globalCapEncryption
+		}
+	default:
+		c.signingAlgorithm = signingAlgorithmAESCMAC
+		var ok bool
+		if negotiateContexts, ok = c.handleNegotiateContexts(r); !ok {
+			c.cipher = 0
+			return nil, statusInvalidParameter
+		}
+		c.preauthHash = updatePreauthHash(make([]byte, sha512.Size), r.message)
+	}
+	c.dialect = dialect
+	c.clientSecurityMode = binary.LittleEndian.Uint16(r.body[4:])
+	return c.newNegotiateResponseBody(dialect, capabilities, negotiateContexts), statusSuccess
+}
+
+func (c *connection) handleSessionSetup(r *request) ([]byte, uint32) {
+	if c.dialect == 0 {
+		return nil, statusAccessDenied
+	}
+	if len(r.body) < 24 {
+		return nil, statusInvalidParameter
+	}
+	securityMode := uint16(r.body[3])
+	securityBuffer, ok := getBuffer(r.message, 24, uint32(binary.LittleEndian.Uint16(r.body[12:])), uint32(binary.LittleEndian.Uint16(r.body[14:])))
+	if !ok {
+		return nil, statusInvalidParameter
+	}
+
+	var s *session
+	if r.sessionID == 0 {
+		c.lastSessionID++
+		r.sessionID = c.lastSessionID
+		s = &session{
+			preauthHash: c.preauthHash,
+			trees:       map[uint32]*tree{},
+		}
+		c.sessions[r.sessionID] = s
+	} else if s, ok = c.sessions[r.sessionID]; !ok || s.authenticated {
+		// Reauthentication of existing sessions is not
+		// supported.
+		return nil, statusUserSessionDeleted
+	}
+	if c.dialect == dialectSMB311 {
+		s.preauthHash = updatePreauthHash(s.preauthHash, r.message)
+	}
+
+	sessionFlags, responseToken, st := c.authenticate(s, securityBuffer, securityMode)
+	if st != statusSuccess && st != statusMoreProcessingRequired {
+		delete(c.sessions, r.sessionID)
+		return nil, st
+	}
+	r.session = s
+
+	b := make([]byte, 8, 8+len(responseToken))
+	binary.LittleEndian.PutUint16(b[0:], 9)
+	binary.LittleEndian.PutUint16(b[2:], sessionFlags)
+	binary.LittleEndian.PutUint16(b[4:], headerSizeBytes+8)
+	binary.LittleEndian.PutUint16(b[6:], uint16(len(responseToken)))
+	return append(b, responseToken...), st
+}
+
+// authenticate processes a single leg of NTLM authentication, wrapped
+// in SPNEGO.
+func (c *connection) authenticate(s *session, securityBuffer []byte, securityMode uint16) (uint16, []byte, uint32) {
+	token, ok := parseSPNEGOToken(securityBuffer)
+	if !ok {
+		return 0, nil, statusLogonFailure
+	}
+	s.rawNTLM = token.raw
+	if token.mechTypes != nil {
+		s.mechTypes = token.mechTypes
+	}
+
+	messageType, ok := getNTLMMessageType(token.mechToken)
+	switch {
+	case !ok && !s.challengeSent && token.offersNTLM:
+		// The client optimistically sent a token for a
+		// different mechanism (e.g., Kerberos). Request that
+		// NTLMSSP is used instead.
+		return 0, newSPNEGONegTokenResp(negStateAcceptIncomplete, true, nil, nil), statusMoreProcessingRequired
+	case ok && messageType == ntlmMessageTypeNegotiate && !s.challengeSent:
+		negotiateFlags, ok := getNTLMNegotiateFlags(token.mechToken)
+		if !ok {
+			return 0, nil, statusLogonFailure
+		}
+		s.challengeFlags = getNTLMChallengeFlags(negotiateFlags)
+		c.server.randomNumberGenerator.Read(s.serverChallenge[:])
+		s.challengeSent = true
+		challenge := newNTLMChallengeMessage(s.challengeFlags, &s.serverChallenge, c.server.serverName, toFileTime(c.server.clock.Now()))
+		if s.rawNTLM {
+			return 0, challenge, statusMoreProcessingRequired
+		}
+		return 0, newSPNEGONegTokenResp(negStateAcceptIncomplete, true, challenge, nil), statusMoreProcessingRequired
+	case ok && messageType == ntlmMessageTypeAuthenticate && s.challengeSent:
+		authenticateMessage, ok := parseNTLMAuthenticateMessage(token.mechToken)
+		if !ok {
+			return 0, nil, statusLogonFailure
+		}
+		sessionFlags, exportedSessionKey, ok := c.server.authenticateUser(&authenticateMessage, &s.serverChallenge)
+		if !ok {
+			return 0, nil, statusLogonFailure
+		}
+
+		// Validate the client's mechListMIC, and provide one
+		// in return. This protects the list of mechanisms
+		// against tampering.
+		var mechListMIC []byte
+		if !s.rawNTLM && token.mechListMIC != nil && exportedSessionKey != nil {
+			expectedMechListMIC := computeNTLMSignature(authenticateMessage.flags, exportedSessionKey, ntlmClientSigningMagic, ntlmClientSealingMagic, s.mechTypes)
+			if !hmac.Equal(expectedMechListMIC, token.mechListMIC) {
+				return 0, nil, statusLogonFailure
+			}
+			mechListMIC = computeNTLMSignature(authenticateMessage.flags, exportedSessionKey, ntlmServerSigningMagic, ntlmServerSealingMagic, s.mechTypes)
+		}
+
+		if exportedSessionKey != nil {
+			c.deriveSessionKeys(s, exportedSessionKey)
+			s.signingRequired = (c.clientSecurityMode|securityMode)&securityModeSigningRequired != 0
+		}
+		if c.server.requireEncryption {
+			// Guest sessions have no keys, meaning they
+			// cannot be encrypted.
+			if s.encrypter == nil {
+				return 0, nil, statusAccessDenied
+			}
+			s.encryptData = true
+			sessionFlags |= sessionFlagEncryptData
+		}
+		s.authenticated = true
+		if s.rawNTLM {
+			return sessionFlags, nil, statusSuccess
+		}
+		return sessionFlags, newSPNEGONegTokenResp(negStateAcceptCompleted, false, nil, mechListMIC), statusSuccess
+	default:
+		return 0, nil, statusLogonFailure
+	}
+}
+
+// deriveSessionKeys derives the keys that are used to sign and encrypt
+// messages from the session key obtained through authentication. SMB
+// 2.x uses the session key for signing directly.
+//
+// Reference: [MS-SMB2] section 3.3.5.5.3.
+func (c *connection) deriveSessionKeys(s *session, sessionKey []byte) {
+	var signingLabel, encryptionLabel, decryptionLabel string
+	var signingContext, encryptionContext, decryptionContext []byte
+	switch {
+	case c.dialect < dialectSMB300:
+		s.signingKey = sessionKey
+		return
+	case c.dialect < dialectSMB311:
+		signingLabel, signingContext = "SMB2AESCMAC", []byte("SmbSign\x00")
+		encryptionLabel, encryptionContext = "SMB2AESCCM", []byte("ServerOut\x00")
+		decryptionLabel, decryptionContext = "SMB2AESCCM", []byte("ServerIn \x00")
+	default:
+		signingLabel, signingContext = "SMBSigningKey", s.preauthHash
+		encryptionLabel, encryptionContext = "SMBS2CCipherKey", s.preauthHash
+		decryptionLabel, decryptionContext = "SMBC2SCipherKey", s.preauthHash
+	}
+	s.signingKey = deriveKey(sessionKey, signingLabel, signingContext)
+	if c.cipher != 0 {
+		s.encrypter = newMessageAEAD(c.cipher, deriveKey(sessionKey, encryptionLabel, encryptionContext))
+		s.decrypter = newMessageAEAD(c.cipher, deriveKey(sessionKey, decryptionLabel, decryptionContext))
+	}
+}
+
+// authenticateUser validates the credentials provided in an NTLM
+// AUTHENTICATE message. Upon success, it returns the session flags and
+// the key that should be used to sign messages. No key is returned for
+// guest sessions.
+func (s *Server) authenticateUser(m *ntlmAuthenticateMessage, serverChallenge *[8]byte) (uint16, []byte, bool) {
+	if m.userName == "" && len(m.ntChallengeResponse) == 0 {
+		return sessionFlagIsNull, nil, s.allowGuest
+	}
+	ntHash, ok := s.ntHashes[strings.ToUpper(m.userName)]
+	if !ok {
+		return sessionFlagIsGuest, nil, s.allowGuest
+	}
+	sessionBaseKey, ok := verifyNTLMv2Response(ntHash, m.userName, m.domainName, serverChallenge, m.ntChallengeResponse)
+	if !ok {
+		return 0, nil, false
+	}
+	exportedSessionKey, ok := getNTLMExportedSessionKey(m.flags, sessionBaseKey, m.encryptedRandomSessionKey)
+	return 0, exportedSessionKey, ok
+}
+
+func (c *connection) handleLogoff(r *request) ([]byte, uint32) {
+	for fileID, f := range c.openFiles {
+		if f.sessionID == r.sessionID {
+			c.closeFile(fileID, f)
+		}
+	}
+	delete(c.sessions, r.sessionID)
+	return []byte{4, 0, 0, 0}, statusSuccess
+}
+
+func (c *connection) handleTreeConnect(r *request) ([]byte, uint32) {
+	if len(r.body) < 8 {
+		return nil, statusInvalidParameter
+	}
+	encodedPath, ok := getBuffer(r.message, 8, uint32(binary.LittleEndian.Uint16(r.body[4:])), uint32(binary.LittleEndian.Uint16(r.body[6:])))
+	if !ok {
+		return nil, statusInvalidParameter
+	}
+	path, ok := decodeUTF16(encodedPath)
+	if !ok {
+		return nil, statusInvalidParameter
+	}
+
+	// Paths have the form \\server\share. The server name is
+	// ignored, as clients may connect using any hostname or IP
+	// address.
+	var t tree
+	shareType := uint8(shareTypeDisk)
+	switch shareName := path[strings.LastIndexByte(path, '\\')+1:]; {
+	case strings.EqualFold(shareName, "IPC$"):
+		t.isIPC = true
+		shareType = shareTypePipe
+	case !strings.EqualFold(shareName, c.server.shareName):
+		return nil, statusBadNetworkName
+	}
+	c.lastTreeID++
+	r.treeID = c.lastTreeID
+	r.session.trees[r.treeID] = &t
+
+	// Disable client side caching of files, as files may be
+	// modified by other clients of the virtual file system.
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint16(b[0:], 16)
+	b[2] = shareType
+	binary.LittleEndian.PutUint32(b[4:], shareFlagNoCaching)
+	binary.LittleEndian.PutUint32(b[12:], accessMaskFileAllAccess)
+	return b, statusSuccess
+}
+
+func (c *connection) handleTreeDisconnect(r *request) ([]byte, uint32) {
+	for fileID, f := range c.openFiles {
+		if f.sessionID == r.sessionID && f.treeID == r.treeID {
+			c.closeFile(fileID, f)
+		}
+	}
+	delete(r.session.trees, r.treeID)
+	return []byte{4, 0, 0, 0}, statusSuccess
+}
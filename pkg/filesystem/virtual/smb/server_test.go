@@ -0,0 +1,554 @@
+package smb_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual/smb"
+	"github.com/buildbarn/bb-storage/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/crypto/md4"
+)
+
+func encodeUTF16(s string) []byte {
+	var b []byte
+	for _, c := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, c)
+	}
+	return b
+}
+
+// appendFields appends a sequence of little endian encoded fields to
+// a buffer.
+func appendFields(b []byte, fields ...any) []byte {
+	for _, field := range fields {
+		switch v := field.(type) {
+		case uint8:
+			b = append(b, v)
+		case uint16:
+			b = binary.LittleEndian.AppendUint16(b, v)
+		case uint32:
+			b = binary.LittleEndian.AppendUint32(b, v)
+		case uint64:
+			b = binary.LittleEndian.AppendUint64(b, v)
+		case []byte:
+			b = append(b, v...)
+		default:
+			panic("Unsupported field type")
+		}
+	}
+	return b
+}
+
+// newRequest encodes an SMB2 request consisting of the provided body
+// fields, prefixed with the transport header.
+func newRequest(command uint16, messageID, sessionID uint64, treeID uint32, fields ...any) []byte {
+	b := []byte{0, 0, 0, 0, 0xfe, 'S', 'M', 'B'}
+	b = appendFields(b,
+		uint16(64), uint16(0), uint32(0), command, uint16(1), uint32(0), uint32(0),
+		messageID, uint32(0), treeID, sessionID, make([]byte, 16))
+	b = appendFields(b, fields...)
+	binary.BigEndian.PutUint32(b, uint32(len(b)-4))
+	return b
+}
+
+type response struct {
+	status    uint32
+	command   uint16
+	sessionID uint64
+	treeID    uint32
+	body      []byte
+}
+
+func runServer(ctx context.Context, t *testing.T, server *smb.Server, requests ...[]byte) []response {
+	var out bytes.Buffer
+	require.NoError(t, server.HandleConnection(ctx, bytes.NewReader(bytes.Join(requests, nil)), &out))
+
+	var responses []response
+	b := out.Bytes()
+	for len(b) > 0 {
+		require.GreaterOrEqual(t, len(b), 4+64)
+		size := binary.BigEndian.Uint32(b)
+		message := b[4 : 4+size]
+		require.Equal(t, []byte{0xfe, 'S', 'M', 'B'}, message[:4])
+		responses = append(responses, response{
+			status:    binary.LittleEndian.Uint32(message[8:]),
+			command:   binary.LittleEndian.Uint16(message[12:]),
+			treeID:    binary.LittleEndian.Uint32(message[36:]),
+			sessionID: binary.LittleEndian.Uint64(message[40:]),
+			body:      message[64:],
+		})
+		b = b[4+size:]
+	}
+	return responses
+}
+
+// derEncode creates a DER encoded value, used to wrap NTLM messages in
+// SPNEGO tokens.
+func derEncode(tag byte, contents ...[]byte) []byte {
+	value := bytes.Join(contents, nil)
+	if len(value) < 0x80 {
+		return append([]byte{tag, byte(len(value))}, value...)
+	}
+	return append([]byte{tag, 0x82, byte(len(value) >> 8), byte(len(value))}, value...)
+}
+
+var (
+	oidSPNEGO  = []byte{0x2b, 0x06, 0x01, 0x05, 0x05, 0x02}
+	oidNTLMSSP = []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0x82, 0x37, 0x02, 0x02, 0x0a}
+)
+
+const ntlmFlags = 0x00000001 | // NTLMSSP_NEGOTIATE_UNICODE.
+	0x00000200 | // NTLMSSP_NEGOTIATE_NTLM.
+	0x00080000 // NTLMSSP_NEGOTIATE_EXTENDED_SESSIONSECURITY.
+
+// newSessionSetupRequest creates a SESSION_SETUP request containing a
+// given security buffer.
+func newSessionSetupRequest(messageID, sessionID uint64, securityBuffer []byte) []byte {
+	return newRequest(0x0001, messageID, sessionID, 0,
+		uint16(25), uint8(0), uint8(0), uint32(0), uint32(0),
+		uint16(64+24), uint16(len(securityBuffer)), uint64(0),
+		securityBuffer)
+}
+
+// newNTLMNegotiate creates a SESSION_SETUP request containing an
+// SPNEGO NegTokenInit that contains an NTLM NEGOTIATE message.
+func newNTLMNegotiate(messageID uint64) []byte {
+	negotiateMessage := appendFields([]byte("NTLMSSP\x00"), uint32(1), uint32(ntlmFlags), uint64(0), uint64(0))
+	return newSessionSetupRequest(messageID, 0, derEncode(
+		0x60,
+		derEncode(0x06, oidSPNEGO),
+		derEncode(0xa0, derEncode(0x30,
+			derEncode(0xa0, derEncode(0x30, derEncode(0x06, oidNTLMSSP))),
+			derEncode(0xa2, derEncode(0x04, negotiateMessage))))))
+}
+
+// getNTHash computes the NT hash of a password.
+func getNTHash(password string) []byte {
+	ntHash := md4.New()
+	ntHash.Write(encodeUTF16(password))
+	return ntHash.Sum(nil)
+}
+
+// newNTLMAuthenticate creates a SESSION_SETUP request containing an
+// SPNEGO NegTokenResp that contains an NTLM AUTHENTICATE message,
+// holding an NTLMv2 response computed for a given password.
+func newNTLMAuthenticate(messageID, sessionID uint64, serverChallenge []byte, userName, domainName, password string) []byte {
+	request, _ := newNTLMAuthenticateWithSessionKey(messageID, sessionID, serverChallenge, userName, domainName, password)
+	return request
+}
+
+// newNTLMAuthenticateWithSessionKey is identical to
+// newNTLMAuthenticate, except that it also returns the session key
+// that both sides derive from the NTLMv2 response.
+func newNTLMAuthenticateWithSessionKey(messageID, sessionID uint64, serverChallenge []byte, userName, domainName, password string) ([]byte, []byte) {
+	hmacMD5 := func(key []byte, data ...[]byte) []byte {
+		h := hmac.New(md5.New, key)
+		for _, d := range data {
+			h.Write(d)
+		}
+		return h.Sum(nil)
+	}
+	responseKeyNT := hmacMD5(getNTHash(password), encodeUTF16(strings.ToUpper(userName)+domainName))
+	temp := appendFields(
+		[]byte{1, 1, 0, 0, 0, 0, 0, 0},
+		uint64(133444736000000000),
+		[]byte("clientch"),
+		uint32(0),
+		uint32(0), // MsvAvEOL.
+		uint32(0))
+	ntProofStr := hmacMD5(responseKeyNT, serverChallenge, temp)
+	ntChallengeResponse := append(ntProofStr, temp...)
+
+	var payload []byte
+	addField := func(value []byte) []byte {
+		field := appendFields(nil, uint16(len(value)), uint16(len(value)), uint32(64+len(payload)))
+		payload = append(payload, value...)
+		return field
+	}
+	authenticateMessage := appendFields(
+		[]byte("NTLMSSP\x00"),
+		uint32(3),
+		addField(nil),
+		addField(ntChallengeResponse),
+		addField(encodeUTF16(domainName)),
+		addField(encodeUTF16(userName)),
+		addField(encodeUTF16("WORKSTATION")),
+		addField(nil),
+		uint32(ntlmFlags),
+		payload)
+	return newSessionSetupRequest(messageID, sessionID, derEncode(
+		0xa1,
+		derEncode(0x30, derEncode(0xa2, derEncode(0x04, authenticateMessage))))), hmacMD5(responseKeyNT, ntProofStr)
+}
+
+func newTreeConnectRequest(messageID, sessionID uint64, path string) []byte {
+	encodedPath := encodeUTF16(path)
+	return newRequest(0x0003, messageID, sessionID, 0,
+		uint16(9), uint16(0), uint16(64+8), uint16(len(encodedPath)), encodedPath)
+}
+
+func newCreateRequest(messageID, sessionID uint64, treeID, desiredAccess, createDisposition, createOptions uint32, name string) []byte {
+	encodedName := encodeUTF16(name)
+	return newRequest(0x0005, messageID, sessionID, treeID,
+		uint16(57), uint8(0), uint8(0), uint32(2), uint64(0), uint64(0),
+		desiredAccess, uint32(0), uint32(7), createDisposition, createOptions,
+		uint16(64+56), uint16(len(encodedName)), uint32(0), uint32(0),
+		append(encodedName, 0))
+}
+
+func newCloseRequest(messageID, sessionID uint64, treeID uint32, fileID []byte) []byte {
+	return newRequest(0x0006, messageID, sessionID, treeID,
+		uint16(24), uint16(0), uint32(0), fileID)
+}
+
+func TestServer(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	rootDirectory := mock.NewMockVirtualDirectory(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(1700000000, 0)).AnyTimes()
+	randomNumberGenerator := mock.NewMockThreadSafeGenerator(ctrl)
+	randomNumberGenerator.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+		return copy(p, bytes.Repeat([]byte{0x42}, len(p))), nil
+	}).AnyTimes()
+	serverChallenge := bytes.Repeat([]byte{0x42}, 8)
+
+	newServer := func(allowGuest bool) *smb.Server {
+		return smb.NewServer(
+			rootDirectory,
+			"SERVER",
+			"bazel-out",
+			map[string][]byte{"user": getNTHash("Password")},
+			allowGuest,
+			/* requireEncryption = */ false,
+			clock,
+			randomNumberGenerator)
+	}
+	negotiate := newRequest(0x0000, 0, 0, 0,
+		uint16(36), uint16(2), uint16(0), uint16(0), uint32(0), make([]byte, 16), uint64(0),
+		uint16(0x0202), uint16(0x0210))
+	fileID := appendFields(nil, uint64(1), uint64(1))
+
+	t.Run("SMB1Negotiate", func(t *testing.T) {
+		// Clients that support SMB1 initiate the connection
+		// with an SMB1 NEGOTIATE request. The server should
+		// respond with the SMB2 wildcard dialect.
+		request := append([]byte{0, 0, 0, 0, 0xff, 'S', 'M', 'B', 0x72}, make([]byte, 27)...)
+		request = append(request, 0, 34, 0)
+		request = append(request, "\x02NT LM 0.12\x00\x02SMB 2.002\x00\x02SMB 2.???\x00"...)
+		binary.BigEndian.PutUint32(request, uint32(len(request)-4))
+
+		responses := runServer(ctx, t, newServer(false), request)
+		require.Len(t, responses, 1)
+		require.Equal(t, uint32(0), responses[0].status)
+		require.Equal(t, uint16(0x02ff), binary.LittleEndian.Uint16(responses[0].body[4:]))
+	})
+
+	t.Run("NoCommonDialect", func(t *testing.T) {
+		responses := runServer(ctx, t, newServer(false), newRequest(0x0000, 0, 0, 0,
+			uint16(36), uint16(1), uint16(0), uint16(0), uint32(0), make([]byte, 16), uint64(0),
+			uint16(0x0400)))
+		require.Len(t, responses, 1)
+		require.Equal(t, uint32(0xc00000bb), responses[0].status)
+	})
+
+	t.Run("WrongPassword", func(t *testing.T) {
+		responses := runServer(ctx, t, newServer(true),
+			negotiate,
+			newNTLMNegotiate(1),
+			newNTLMAuthenticate(2, 1, serverChallenge, "user", "DOMAIN", "Wrong"))
+		require.Len(t, responses, 3)
+		require.Equal(t, uint16(0x0210), binary.LittleEndian.Uint16(responses[0].body[4:]))
+		require.Equal(t, uint32(0xc0000016), responses[1].status)
+		require.Equal(t, uint64(1), responses[1].sessionID)
+		require.Equal(t, uint32(0xc000006d), responses[2].status)
+	})
+
+	t.Run("OpenAndRead", func(t *testing.T) {
+		leaf := mock.NewMockVirtualLeaf(ctrl)
+		rootDirectory.EXPECT().VirtualLookup(ctx, path.MustNewComponent("hello.txt"), smb.AttributesMaskForFileInformation, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, name path.Component, requested virtual.AttributesMask, attributes *virtual.Attributes) (virtual.DirectoryChild, virtual.Status) {
+				attributes.SetFileType(filesystem.FileTypeRegularFile)
+				attributes.SetInodeNumber(2)
+				attributes.SetLinkCount(1)
+				attributes.SetPermissions(virtual.PermissionsRead)
+				attributes.SetSizeBytes(5)
+				return virtual.DirectoryChild{}.FromLeaf(leaf), virtual.StatusOK
+			})
+		leaf.EXPECT().VirtualOpenSelf(ctx, virtual.ShareMaskRead, &virtual.OpenExistingOptions{}, smb.AttributesMaskForFileInformation, gomock.Any())
+		leaf.EXPECT().VirtualRead(gomock.Len(16), uint64(0)).
+			DoAndReturn(func(buf []byte, offset uint64) (int, bool, virtual.Status) {
+				return copy(buf, "Hello"), true, virtual.StatusOK
+			})
+		leaf.EXPECT().VirtualClose(virtual.ShareMaskRead)
+
+		responses := runServer(ctx, t, newServer(false),
+			negotiate,
+			newNTLMNegotiate(1),
+			newNTLMAuthenticate(2, 1, serverChallenge, "user", "DOMAIN", "Password"),
+			newTreeConnectRequest(3, 1, "\\\\SERVER\\nonexistent"),
+			newTreeConnectRequest(4, 1, "\\\\SERVER\\BAZEL-OUT"),
+			newCreateRequest(5, 1, 1, 0x00120089, 1, 0x40, "hello.txt"),
+			newRequest(0x0008, 6, 1, 1,
+				uint16(49), uint8(0), uint8(0), uint32(16), uint64(0), fileID,
+				uint32(0), uint32(0), uint32(0), uint16(0), uint16(0), uint8(0)),
+			newCloseRequest(7, 1, 1, fileID),
+			newCloseRequest(8, 1, 1, fileID))
+		require.Len(t, responses, 9)
+		require.Equal(t, uint32(0), responses[2].status)
+		require.Equal(t, uint32(0xc00000cc), responses[3].status)
+		require.Equal(t, uint32(0), responses[4].status)
+		require.Equal(t, uint32(1), responses[4].treeID)
+
+		require.Equal(t, uint32(0), responses[5].status)
+		require.Equal(t, uint16(89), binary.LittleEndian.Uint16(responses[5].body))
+		require.Equal(t, uint32(1), binary.LittleEndian.Uint32(responses[5].body[4:]))
+		require.Equal(t, uint64(5), binary.LittleEndian.Uint64(responses[5].body[48:]))
+		require.Equal(t, uint32(0x21), binary.LittleEndian.Uint32(responses[5].body[56:]))
+		require.Equal(t, fileID, responses[5].body[64:80])
+
+		require.Equal(t, uint32(0), responses[6].status)
+		require.Equal(t, appendFields(nil, uint16(17), uint8(80), uint8(0), uint32(5), uint64(0), []byte("Hello")), responses[6].body)
+
+		require.Equal(t, uint32(0), responses[7].status)
+		require.Equal(t, uint32(0xc0000128), responses[8].status)
+	})
+
+	t.Run("StoppedOnSymlink", func(t *testing.T) {
+		// If a path traverses a symbolic link, the server should
+		// return the link's target, so that the client can
+		// resolve it.
+		leaf := mock.NewMockVirtualLeaf(ctrl)
+		rootDirectory.EXPECT().VirtualLookup(ctx, path.MustNewComponent("link"), virtual.AttributesMaskFileType, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, name path.Component, requested virtual.AttributesMask, attributes *virtual.Attributes) (virtual.DirectoryChild, virtual.Status) {
+				attributes.SetFileType(filesystem.FileTypeSymlink)
+				return virtual.DirectoryChild{}.FromLeaf(leaf), virtual.StatusOK
+			})
+		leaf.EXPECT().VirtualReadlink(ctx).Return([]byte("a/b"), virtual.StatusOK)
+
+		responses := runServer(ctx, t, newServer(true),
+			negotiate,
+			newNTLMNegotiate(1),
+			newNTLMAuthenticate(2, 1, serverChallenge, "guest", "DOMAIN", ""),
+			newTreeConnectRequest(3, 1, "\\\\SERVER\\bazel-out"),
+			newCreateRequest(4, 1, 1, 0x00120089, 1, 0, "link\\file"))
+		require.Len(t, responses, 5)
+		require.Equal(t, uint32(0), responses[2].status)
+		require.Equal(t, uint16(1), binary.LittleEndian.Uint16(responses[2].body[2:]))
+
+		target := encodeUTF16("a\\b")
+		require.Equal(t, uint32(0x8000002d), responses[4].status)
+		require.Equal(t, appendFields(
+			nil,
+			uint16(9), uint8(0), uint8(0), uint32(28+2*len(target)),
+			uint32(24+2*len(target)), uint32(0x4c4d5953),
+			uint32(0xa000000c), uint16(12+2*len(target)), uint16(10),
+			uint16(0), uint16(len(target)), uint16(len(target)), uint16(len(target)),
+			uint32(1), target, target,
+		), responses[4].body)
+	})
+
+	t.Run("QueryDirectory", func(t *testing.T) {
+		setDirectoryAttributes := func(ctx context.Context, requested virtual.AttributesMask, attributes *virtual.Attributes) {
+			attributes.SetFileType(filesystem.FileTypeDirectory)
+			attributes.SetInodeNumber(1)
+			attributes.SetLinkCount(2)
+			attributes.SetPermissions(virtual.PermissionsRead | virtual.PermissionsWrite | virtual.PermissionsExecute)
+			attributes.SetSizeBytes(4096)
+		}
+		rootDirectory.EXPECT().VirtualGetAttributes(ctx, smb.AttributesMaskForFileInformation, gomock.Any()).
+			DoAndReturn(setDirectoryAttributes).
+			Times(3)
+		rootDirectory.EXPECT().VirtualReadDir(ctx, uint64(0), smb.AttributesMaskForFileInformation, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, firstCookie uint64, requested virtual.AttributesMask, reporter virtual.DirectoryEntryReporter) virtual.Status {
+				var attributes virtual.Attributes
+				attributes.SetFileType(filesystem.FileTypeRegularFile)
+				attributes.SetSizeBytes(12)
+				require.True(t, reporter.ReportEntry(1, path.MustNewComponent("file.txt"), virtual.DirectoryChild{}.FromLeaf(mock.NewMockVirtualLeaf(ctrl)), &attributes))
+				require.True(t, reporter.ReportEntry(2, path.MustNewComponent("other.log"), virtual.DirectoryChild{}.FromLeaf(mock.NewMockVirtualLeaf(ctrl)), &attributes))
+				return virtual.StatusOK
+			})
+		rootDirectory.EXPECT().VirtualReadDir(ctx, uint64(2), smb.AttributesMaskForFileInformation, gomock.Any())
+
+		pattern := encodeUTF16("*")
+		queryDirectory := func(messageID uint64) []byte {
+			return newRequest(0x000e, messageID, 1, 1,
+				uint16(33), uint8(12), uint8(0), uint32(0), fileID,
+				uint16(64+32), uint16(len(pattern)), uint32(65536), pattern)
+		}
+		responses := runServer(ctx, t, newServer(true),
+			negotiate,
+			newNTLMNegotiate(1),
+			newNTLMAuthenticate(2, 1, serverChallenge, "", "", ""),
+			newTreeConnectRequest(3, 1, "\\\\SERVER\\bazel-out"),
+			newCreateRequest(4, 1, 1, 0x00100081, 1, 0x1, ""),
+			queryDirectory(5),
+			queryDirectory(6))
+		require.Len(t, responses, 7)
+		require.Equal(t, uint32(0), responses[4].status)
+		require.Equal(t, uint32(0x10), binary.LittleEndian.Uint32(responses[4].body[56:]))
+
+		// FileNamesInformation entries should be returned for
+		// ".", ".." and "file.txt", aligned to eight bytes.
+		newEntry := func(nextEntryOffset uint32, name string) []byte {
+			encodedName := encodeUTF16(name)
+			return appendFields(nil, nextEntryOffset, uint32(0), uint32(len(encodedName)), encodedName)
+		}
+		require.Equal(t, uint32(0), responses[5].status)
+		require.Equal(t, appendFields(
+			nil,
+			uint16(9), uint16(72), uint32(94),
+			newEntry(16, "."), make([]byte, 2),
+			newEntry(16, ".."),
+			newEntry(32, "file.txt"), make([]byte, 4),
+			newEntry(0, "other.log"),
+		), responses[5].body)
+		require.Equal(t, uint32(0x80000006), responses[6].status)
+	})
+}
+
+// deriveKey implements the SP800-108 key derivation function that is
+// used by SMB 3.x.
+func deriveKey(sessionKey []byte, label string, context []byte) []byte {
+	h := hmac.New(sha256.New, sessionKey)
+	h.Write([]byte{0, 0, 0, 1})
+	h.Write([]byte(label))
+	h.Write([]byte{0})
+	h.Write(context)
+	h.Write([]byte{0, 0, 0, 128})
+	return h.Sum(nil)[:16]
+}
+
+func newGCM(t *testing.T, key []byte) cipher.AEAD {
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return aead
+}
+
+func TestServerSMB311Encryption(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(1700000000, 0)).AnyTimes()
+	randomNumberGenerator := mock.NewMockThreadSafeGenerator(ctrl)
+	randomNumberGenerator.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+		return copy(p, bytes.Repeat([]byte{0x42}, len(p))), nil
+	}).AnyTimes()
+	server := smb.NewServer(
+		mock.NewMockVirtualDirectory(ctrl),
+		"SERVER",
+		"bazel-out",
+		map[string][]byte{"user": getNTHash("Password")},
+		/* allowGuest = */ false,
+		/* requireEncryption = */ true,
+		clock,
+		randomNumberGenerator)
+
+	// Keys are derived from a hash of all messages exchanged while
+	// negotiating and authenticating, which requires that requests
+	// and responses are exchanged one at a time.
+	clientConn, serverConn := net.Pipe()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.HandleConnection(ctx, serverConn, serverConn)
+		serverConn.Close()
+	}()
+	roundTrip := func(request []byte) []byte {
+		_, err := clientConn.Write(request)
+		require.NoError(t, err)
+		var header [4]byte
+		_, err = io.ReadFull(clientConn, header[:])
+		require.NoError(t, err)
+		response := make([]byte, binary.BigEndian.Uint32(header[:]))
+		_, err = io.ReadFull(clientConn, response)
+		require.NoError(t, err)
+		return response
+	}
+	preauthHash := make([]byte, sha512.Size)
+	updatePreauthHash := func(message []byte) {
+		h := sha512.New()
+		h.Write(preauthHash)
+		h.Write(message)
+		preauthHash = h.Sum(nil)
+	}
+
+	// Negotiate SMB 3.1.1, offering SHA-512 for preauthentication
+	// integrity and AES-128-GCM for encryption.
+	negotiate := newRequest(0x0000, 0, 0, 0,
+		uint16(36), uint16(1), uint16(1), uint16(0), uint32(0), make([]byte, 16),
+		uint32(104), uint16(2), uint16(0),
+		uint16(0x0311), make([]byte, 2),
+		uint16(1), uint16(38), uint32(0), uint16(1), uint16(32), uint16(1), make([]byte, 32), make([]byte, 2),
+		uint16(2), uint16(4), uint32(0), uint16(1), uint16(2))
+	updatePreauthHash(negotiate[4:])
+	negotiateResponse := roundTrip(negotiate)
+	require.Equal(t, uint32(0), binary.LittleEndian.Uint32(negotiateResponse[8:]))
+	require.Equal(t, uint16(0x0311), binary.LittleEndian.Uint16(negotiateResponse[64+4:]))
+	require.Equal(t, uint16(2), binary.LittleEndian.Uint16(negotiateResponse[64+6:]))
+	updatePreauthHash(negotiateResponse)
+
+	// Authenticate using NTLM.
+	ntlmNegotiate := newNTLMNegotiate(1)
+	updatePreauthHash(ntlmNegotiate[4:])
+	challengeResponse := roundTrip(ntlmNegotiate)
+	require.Equal(t, uint32(0xc0000016), binary.LittleEndian.Uint32(challengeResponse[8:]))
+	updatePreauthHash(challengeResponse)
+
+	ntlmAuthenticate, sessionKey := newNTLMAuthenticateWithSessionKey(2, 1, bytes.Repeat([]byte{0x42}, 8), "user", "DOMAIN", "Password")
+	updatePreauthHash(ntlmAuthenticate[4:])
+	sessionSetupResponse := roundTrip(ntlmAuthenticate)
+	require.Equal(t, uint32(0), binary.LittleEndian.Uint32(sessionSetupResponse[8:]))
+	require.Equal(t, uint32(0x9), binary.LittleEndian.Uint32(sessionSetupResponse[16:]))
+	require.Equal(t, uint16(0x0004), binary.LittleEndian.Uint16(sessionSetupResponse[64+2:]))
+
+	// Requests that are not encrypted should be rejected.
+	require.Equal(t, uint32(0xc0000022), binary.LittleEndian.Uint32(roundTrip(newTreeConnectRequest(3, 1, "\\\\SERVER\\bazel-out"))[8:]))
+
+	// Send an encrypted TREE_CONNECT request, and decrypt the
+	// response.
+	plaintext := newTreeConnectRequest(4, 1, "\\\\SERVER\\bazel-out")[4:]
+	transformHeader := appendFields([]byte{0xfd, 'S', 'M', 'B'},
+		make([]byte, 16), []byte("clientnonce1"), make([]byte, 4),
+		uint32(len(plaintext)), uint16(0), uint16(1), uint64(1))
+	sealed := newGCM(t, deriveKey(sessionKey, "SMBC2SCipherKey", preauthHash)).Seal(nil, transformHeader[20:32], plaintext, transformHeader[20:])
+	copy(transformHeader[4:20], sealed[len(plaintext):])
+	request := appendFields([]byte{0, 0, 0, 0}, transformHeader, sealed[:len(plaintext)])
+	binary.BigEndian.PutUint32(request, uint32(len(request)-4))
+
+	encryptedResponse := roundTrip(request)
+	require.Equal(t, []byte{0xfd, 'S', 'M', 'B'}, encryptedResponse[:4])
+	require.Equal(t, uint64(1), binary.LittleEndian.Uint64(encryptedResponse[44:]))
+	treeConnectResponse, err := newGCM(t, deriveKey(sessionKey, "SMBS2CCipherKey", preauthHash)).Open(
+		nil,
+		encryptedResponse[20:32],
+		append(append([]byte(nil), encryptedResponse[52:]...), encryptedResponse[4:20]...),
+		encryptedResponse[20:52])
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xfe, 'S', 'M', 'B'}, treeConnectResponse[:4])
+	require.Equal(t, uint32(0), binary.LittleEndian.Uint32(treeConnectResponse[8:]))
+	require.Equal(t, uint32(1), binary.LittleEndian.Uint32(treeConnectResponse[36:]))
+
+	clientConn.Close()
+	require.NoError(t, <-errCh)
+}
@@ -0,0 +1,229 @@
+package smb
+
+import (
+	"bytes"
+)
+
+// Object identifiers of the SPNEGO pseudo-mechanism and the NTLMSSP
+// mechanism, in DER encoded form.
+var (
+	oidSPNEGO  = []byte{0x2b, 0x06, 0x01, 0x05, 0x05, 0x02}
+	oidNTLMSSP = []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0x82, 0x37, 0x02, 0x02, 0x0a}
+)
+
+// Tags of DER encoded values that are used by SPNEGO.
+const (
+	derTagEnumerated      = 0x0a
+	derTagOctetString     = 0x04
+	derTagObjectID        = 0x06
+	derTagSequence        = 0x30
+	derTagApplication0    = 0x60
+	derTagContextSpecific = 0xa0
+)
+
+// Values of the negState field of NegTokenResp.
+const (
+	negStateAcceptCompleted  = 0
+	negStateAcceptIncomplete = 1
+	negStateReject           = 2
+)
+
+// derEncode creates a DER encoded value, consisting of a tag and the
+// concatenation of the provided contents.
+func derEncode(tag byte, contents ...[]byte) []byte {
+	length := 0
+	for _, c := range contents {
+		length += len(c)
+	}
+	b := []byte{tag}
+	switch {
+	case length < 0x80:
+		b = append(b, byte(length))
+	case length < 0x100:
+		b = append(b, 0x81, byte(length))
+	case length < 0x10000:
+		b = append(b, 0x82, byte(length>>8), byte(length))
+	default:
+		b = append(b, 0x83, byte(length>>16), byte(length>>8), byte(length))
+	}
+	for _, c := range contents {
+		b = append(b, c...)
+	}
+	return b
+}
+
+// derDecode decodes the first DER encoded value in a buffer. In
+// addition to the tag and the contents of the value, it returns the
+// full encoding of the value and any trailing data.
+func derDecode(b []byte) (tag byte, contents, element, rest []byte, ok bool) {
+	if len(b) < 2 {
+		return 0, nil, nil, nil, false
+	}
+	tag = b[0]
+	length := int(b[1])
+	headerSizeBytes := 2
+	if length >= 0x80 {
+		lengthSizeBytes := length & 0x7f
+		if lengthSizeBytes == 0 || lengthSizeBytes > 3 || len(b) < 2+lengthSizeBytes {
+			return 0, nil, nil, nil, false
+		}
+		length = 0
+		for _, v := range b[2 : 2+lengthSizeBytes] {
+			length = length<<8 | int(v)
+		}
+		headerSizeBytes += lengthSizeBytes
+	}
+	if len(b)-headerSizeBytes < length {
+		return 0, nil, nil, nil, false
+	}
+	end := headerSizeBytes + length
+	return tag, b[headerSizeBytes:end], b[:end], b[end:], true
+}
+
+// derDecodeExpected decodes a single DER encoded value that is
+// expected to have a given tag.
+func derDecodeExpected(b []byte, expectedTag byte) ([]byte, bool) {
+	tag, contents, _, _, ok := derDecode(b)
+	if !ok || tag != expectedTag {
+		return nil, false
+	}
+	return contents, true
+}
+
+// spnegoToken contains the fields of an SPNEGO NegTokenInit or
+// NegTokenResp that are relevant to the server.
+type spnegoToken struct {
+	// The DER encoding of the MechTypeList that is sent by the
+	// client as part of NegTokenInit. This is needed to compute
+	// and validate the mechListMIC.
+	mechTypes  []byte
+	offersNTLM bool
+
+	mechToken   []byte
+	mechListMIC []byte
+
+	// Set if the client sent a bare NTLMSSP message, as opposed to
+	// one that is wrapped in SPNEGO.
+	raw bool
+}
+
+// parseSPNEGOToken parses a security buffer sent by the client as part
+// of SESSION_SETUP.
+func parseSPNEGOToken(b []byte) (spnegoToken, bool) {
+	if bytes.HasPrefix(b, ntlmSignature) {
+		return spnegoToken{mechToken: b, raw: true}, true
+	}
+
+	tag, contents, _, _, ok := derDecode(b)
+	if !ok {
+		return spnegoToken{}, false
+	}
+	var token spnegoToken
+	var fields []byte
+	switch tag {
+	case derTagApplication0:
+		// NegTokenInit, prefixed with the SPNEGO object
+		// identifier.
+		_, oid, _, rest, ok := derDecode(contents)
+		if !ok || !bytes.Equal(oid, oidSPNEGO) {
+			return spnegoToken{}, false
+		}
+		negTokenInit, ok := derDecodeExpected(rest, derTagContextSpecific|0)
+		if !ok {
+			return spnegoToken{}, false
+		}
+		if fields, ok = derDecodeExpected(negTokenInit, derTagSequence); !ok {
+			return spnegoToken{}, false
+		}
+	case derTagContextSpecific | 1:
+		// NegTokenResp.
+		if fields, ok = derDecodeExpected(contents, derTagSequence); !ok {
+			return spnegoToken{}, false
+		}
+	default:
+		return spnegoToken{}, false
+	}
+
+	for len(fields) > 0 {
+		fieldTag, field, _, rest, ok := derDecode(fields)
+		if !ok {
+			return spnegoToken{}, false
+		}
+		fields = rest
+		switch fieldTag {
+		case derTagContextSpecific | 0:
+			if tag != derTagApplication0 {
+				// negState of NegTokenResp.
+				continue
+			}
+			// mechTypes of NegTokenInit.
+			mechTypesTag, oids, mechTypes, _, ok := derDecode(field)
+			if !ok || mechTypesTag != derTagSequence {
+				return spnegoToken{}, false
+			}
+			token.mechTypes = mechTypes
+			for len(oids) > 0 {
+				oidTag, oid, _, rest, ok := derDecode(oids)
+				if !ok || oidTag != derTagObjectID {
+					return spnegoToken{}, false
+				}
+				oids = rest
+				if bytes.Equal(oid, oidNTLMSSP) {
+					token.offersNTLM = true
+				}
+			}
+		case derTagContextSpecific | 2:
+			// mechToken of NegTokenInit, or responseToken
+			// of NegTokenResp.
+			if token.mechToken, ok = derDecodeExpected(field, derTagOctetString); !ok {
+				return spnegoToken{}, false
+			}
+		case derTagContextSpecific | 3:
+			if tag == derTagApplication0 {
+				// negHints of NegTokenInit2.
+				continue
+			}
+			// mechListMIC of NegTokenResp.
+			if token.mechListMIC, ok = derDecodeExpected(field, derTagOctetString); !ok {
+				return spnegoToken{}, false
+			}
+		}
+	}
+	return token, true
+}
+
+// newSPNEGONegTokenInit creates the security buffer that is returned
+// as part of the NEGOTIATE response, announcing that the server only
+// supports NTLMSSP.
+func newSPNEGONegTokenInit() []byte {
+	return derEncode(
+		derTagApplication0,
+		derEncode(derTagObjectID, oidSPNEGO),
+		derEncode(
+			derTagContextSpecific|0,
+			derEncode(
+				derTagSequence,
+				derEncode(
+					derTagContextSpecific|0,
+					derEncode(
+						derTagSequence,
+						derEncode(derTagObjectID, oidNTLMSSP))))))
+}
+
+// newSPNEGONegTokenResp creates a NegTokenResp that is returned as part
+// of a SESSION_SETUP response.
+func newSPNEGONegTokenResp(negState byte, includeSupportedMech bool, responseToken, mechListMIC []byte) []byte {
+	fields := [][]byte{
+		derEncode(derTagContextSpecific|0, derEncode(derTagEnumerated, []byte{negState})),
+	}
+	if includeSupportedMech {
+		fields = append(fields, derEncode(derTagContextSpecific|1, derEncode(derTagObjectID, oidNTLMSSP)))
+	}
+	if responseToken != nil {
+		fields = append(fields, derEncode(derTagContextSpecific|2, derEncode(derTagOctetString, responseToken)))
+	}
+	if mechListMIC != nil {
+		fields = append(fields, derEncode(derTagContextSpecific|3, derEncode(derTagOctetString, mechListMIC)))
+	}
+	return derEncode(derTagContextSpecific|1, derEncode(derTagSequence, fields...))
+}
@@ -0,0 +1,80 @@
+package virtual
+
+import (
+	re_filesystem "github.com/buildbarn/bb-remote-execution/pkg/filesystem"
+	"github.com/buildbarn/bb-storage/pkg/util"
+)
+
+// FileSystemStatistics contains the capacity of a virtual file system,
+// as reported through statfs().
+type FileSystemStatistics struct {
+	TotalSizeBytes     uint64
+	AvailableSizeBytes uint64
+	// The number of files that may be created. These are zero if
+	// the number of files is not limited.
+	TotalFiles     uint64
+	AvailableFiles uint64
+}
+
+// StatFSProvider is called into by the FUSE and NFSv4 servers to
+// obtain the capacity of a virtual file system. Without it, these
+// servers report the file system as having no capacity at all.
+type StatFSProvider interface {
+	VirtualStatFS(out *FileSystemStatistics) Status
+}
+
+// SubtreeStatFSProvider is an optional interface that may be
+// implemented by directories whose subtree has a capacity that differs
+// from the rest of the file system. For example, build directories of
+// individual actions may be subject to quotas.
+type SubtreeStatFSProvider interface {
+	// GetSubtreeStatFSProvider returns the StatFSProvider of the
+	// subtree containing the directory, or nil if the subtree
+	// doesn't have a capacity of its own.
+	GetSubtreeStatFSProvider() StatFSProvider
+}
+
+// GetStatFSProvider returns the StatFSProvider that should be used to
+// report the capacity of the file system when queried through a given
+// node. If the node doesn't provide a StatFSProvider of its own, the
+// StatFSProvider of the file system as a whole is returned.
+func GetStatFSProvider(node Node, fileSystemStatFSProvider StatFSProvider) StatFSProvider {
+	if d, ok := node.(SubtreeStatFSProvider); ok {
+		if statFSProvider := d.GetSubtreeStatFSProvider(); statFSProvider != nil {
+			return statFSProvider
+		}
+	}
+	return fileSystemStatFSProvider
+}
+
+type filePoolStatFSProvider struct {
+	filePool    re_filesystem.FilePool
+	errorLogger util.ErrorLogger
+}
+
+// NewFilePoolStatFSProvider creates a StatFSProvider that reports the
+// capacity of the FilePool that is used to store the contents of files
+// created in the virtual file system. This ensures that tools that
+// check for the availability of free space prior to writing files
+// (e.g., pip and CMake) behave correctly.
+func NewFilePoolStatFSProvider(filePool re_filesystem.FilePool, errorLogger util.ErrorLogger) StatFSProvider {
+	return &filePoolStatFSProvider{
+		filePool:    filePool,
+		errorLogger: errorLogger,
+	}
+}
+
+func (p *filePoolStatFSProvider) VirtualStatFS(out *FileSystemStatistics) Status {
+	usage, err := re_filesystem.GetFilePoolUsage(p.filePool)
+	if err != nil {
+		p.errorLogger.Log(util.StatusWrap(err, "Failed to obtain file pool usage"))
+		return StatusErrIO
+	}
+	*out = FileSystemStatistics{
+		TotalSizeBytes:     usage.TotalSizeBytes,
+		AvailableSizeBytes: usage.AvailableSizeBytes,
+		TotalFiles:         usage.TotalFiles,
+		AvailableFiles:     usage.AvailableFiles,
+	}
+	return StatusOK
+}
@@ -27,10 +27,68 @@ func (l staticDirectoryEntryList) Swap(i, j int) {
 	l[i], l[j] = l[j], l[i]
 }
 
+// lookup performs a binary search for a directory entry with a given
+// name. The list must be sorted.
+func (l staticDirectoryEntryList) lookup(name path.Component) (DirectoryChild, bool) {
+	if i := sort.Search(len(l), func(i int) bool {
+		return l[i].name.String() >= name.String()
+	}); i < len(l) && l[i].name == name {
+		return l[i].child, true
+	}
+	return DirectoryChild{}, false
+}
+
+// virtualLookup implements Directory.VirtualLookup() for directories
+// whose contents are described by a sorted list of directory entries.
+func (l staticDirectoryEntryList) virtualLookup(ctx context.Context, name path.Component, requested AttributesMask, out *Attributes) (DirectoryChild, Status) {
+	if child, ok := l.lookup(name); ok {
+		child.GetNode().VirtualGetAttributes(ctx, requested, out)
+		return child, StatusOK
+	}
+	return DirectoryChild{}, StatusErrNoEnt
+}
+
+// virtualOpenChild implements Directory.VirtualOpenChild() for
+// read-only directories whose contents are described by a sorted list
+// of directory entries.
+func (l staticDirectoryEntryList) virtualOpenChild(ctx context.Context, name path.Component, shareAccess ShareMask, createAttributes *Attributes, existingOptions *OpenExistingOptions, requested AttributesMask, openedFileAttributes *Attributes) (Leaf, AttributesMask, ChangeInfo, Status) {
+	child, ok := l.lookup(name)
+	if !ok {
+		return ReadOnlyDirectoryOpenChildDoesntExist(createAttributes)
+	}
+	if existingOptions == nil {
+		return nil, 0, ChangeInfo{}, StatusErrExist
+	}
+	directory, leaf := child.GetPair()
+	if directory != nil {
+		return nil, 0, ChangeInfo{}, StatusErrIsDir
+	}
+	s := leaf.VirtualOpenSelf(ctx, shareAccess, existingOptions, requested, openedFileAttributes)
+	return leaf, existingOptions.ToAttributesMask(), ChangeInfo{
+		Before: 0,
+		After:  0,
+	}, s
+}
+
+// virtualReadDir implements Directory.VirtualReadDir() for directories
+// whose contents are described by a sorted list of directory entries.
+// Cookies correspond to indices in the list.
+func (l staticDirectoryEntryList) virtualReadDir(ctx context.Context, firstCookie uint64, requested AttributesMask, reporter DirectoryEntryReporter) Status {
+	for i := firstCookie; i < uint64(len(l)); i++ {
+		entry := l[i]
+		var attributes Attributes
+		entry.child.GetNode().VirtualGetAttributes(ctx, requested, &attributes)
+		if !reporter.ReportEntry(i+1, entry.name, entry.child, &attributes) {
+			break
+		}
+	}
+	return StatusOK
+}
+
 type staticDirectory struct {
 	ReadOnlyDirectory
 
-	entries   []staticDirectoryEntry
+	entries   staticDirectoryEntryList
 	linkCount uint32
 }
 
@@ -63,50 +121,20 @@ func NewStaticDirectory(directories map[path.Component]DirectoryChild) Directory
 func (d *staticDirectory) VirtualGetAttributes(ctx context.Context, requested AttributesMask, attributes *Attributes) {
 	attributes.SetChangeID(0)
 	attributes.SetFileType(filesystem.FileTypeDirectory)
+	attributes.SetIsImmutable(true)
 	attributes.SetLinkCount(d.linkCount)
 	attributes.SetPermissions(PermissionsRead | PermissionsExecute)
 	attributes.SetSizeBytes(0)
 }
 
 func (d *staticDirectory) VirtualLookup(ctx context.Context, name path.Component, requested AttributesMask, out *Attributes) (DirectoryChild, Status) {
-	if i := sort.Search(len(d.entries), func(i int) bool {
-		return d.entries[i].name.String() >= name.String()
-	}); i < len(d.entries) && d.entries[i].name == name {
-		child := d.entries[i].child
-		child.GetNode().VirtualGetAttributes(ctx, requested, out)
-		return (child), StatusOK
-	}
-	return DirectoryChild{}, StatusErrNoEnt
+	return d.entries.virtualLookup(ctx, name, requested, out)
 }
 
 func (d *staticDirectory) VirtualOpenChild(ctx context.Context, name path.Component, shareAccess ShareMask, createAttributes *Attributes, existingOptions *OpenExistingOptions, requested AttributesMask, openedFileAttributes *Attributes) (Leaf, AttributesMask, ChangeInfo, Status) {
-	if i := sort.Search(len(d.entries), func(i int) bool {
-		return d.entries[i].name.String() >= name.String()
-	}); i < len(d.entries) && d.entries[i].name == name {
-		if existingOptions == nil {
-			return nil, 0, ChangeInfo{}, StatusErrExist
-		}
-		directory, leaf := d.entries[i].child.GetPair()
-		if directory != nil {
-			return nil, 0, ChangeInfo{}, StatusErrIsDir
-		}
-		s := leaf.VirtualOpenSelf(ctx, shareAccess, existingOptions, requested, openedFileAttributes)
-		return leaf, existingOptions.ToAttributesMask(), ChangeInfo{
-			Before: 0,
-			After:  0,
-		}, s
-	}
-	return ReadOnlyDirectoryOpenChildDoesntExist(createAttributes)
+	return d.entries.virtualOpenChild(ctx, name, shareAccess, createAttributes, existingOptions, requested, openedFileAttributes)
 }
 
 func (d *staticDirectory) VirtualReadDir(ctx context.Context, firstCookie uint64, requested AttributesMask, reporter DirectoryEntryReporter) Status {
-	for i := firstCookie; i < uint64(len(d.entries)); i++ {
-		entry := d.entries[i]
-		var attributes Attributes
-		entry.child.GetNode().VirtualGetAttributes(ctx, requested, &attributes)
-		if !reporter.ReportEntry(i+1, entry.name, entry.child, &attributes) {
-			break
-		}
-	}
-	return StatusOK
+	return d.entries.virtualReadDir(ctx, firstCookie, requested, reporter)
 }
@@ -29,6 +29,13 @@ const (
 	// StatusErrNoEnt indicate sthat the operation failed due to a
 	// file not existing.
 	StatusErrNoEnt
+	// StatusErrNoSpc indicates that the operation failed due to
+	// there being insufficient space to store the data, or a quota
+	// being exceeded.
+	StatusErrNoSpc
+	// StatusErrNoXAttr indicates that the operation failed due to
+	// an extended attribute not existing.
+	StatusErrNoXAttr
 	// StatusErrNotDir indicates that a request is made against a
 	// leaf when the current operation does not allow a leaf as a
 	// target.
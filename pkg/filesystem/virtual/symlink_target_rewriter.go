@@ -0,0 +1,118 @@
+package virtual
+
+import (
+	"path"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SymlinkTargetRewriter is used by CASInitialContentsFetcher to process
+// the targets of symbolic links contained in directories stored in the
+// Content Addressable Storage, prior to instantiating them.
+type SymlinkTargetRewriter interface {
+	// RewriteSymlinkTarget returns the target that should be used
+	// for a symbolic link. The depth is the number of directories
+	// between the symbolic link and the input root (i.e., zero for
+	// symbolic links placed in the input root directly).
+	RewriteSymlinkTarget(target string, depth int) (string, error)
+}
+
+type identitySymlinkTargetRewriter struct{}
+
+func (identitySymlinkTargetRewriter) RewriteSymlinkTarget(target string, depth int) (string, error) {
+	return target, nil
+}
+
+// IdentitySymlinkTargetRewriter is an implementation of
+// SymlinkTargetRewriter that leaves all targets untouched.
+var IdentitySymlinkTargetRewriter SymlinkTargetRewriter = identitySymlinkTargetRewriter{}
+
+// SymlinkTargetRewriteRule is a rule that may be provided to
+// NewPrefixSymlinkTargetRewriter, causing absolute targets starting
+// with a given prefix to be rewritten to a location inside the input
+// root.
+type SymlinkTargetRewriteRule struct {
+	// Absolute path that is matched against targets of symbolic
+	// links (e.g., "/usr/lib").
+	AbsolutePrefix string
+	// Path relative to the input root to which the prefix is
+	// rewritten (e.g., "sysroot/usr/lib").
+	Replacement string
+}
+
+type prefixSymlinkTargetRewriter struct {
+	rules                          []SymlinkTargetRewriteRule
+	refuseUnmatchedAbsoluteTargets bool
+}
+
+// NewPrefixSymlinkTargetRewriter creates a SymlinkTargetRewriter that
+// rewrites absolute targets of symbolic links, so that they refer to
+// locations inside the input root. Without it, absolute symbolic links
+// (which are common in third-party archives) resolve to files on the
+// host file system of the worker.
+//
+// Rules are applied in order. The first rule whose prefix matches one
+// or more leading pathname components of the target is used. The
+// resulting target is relative to the location of the symbolic link,
+// so that it remains valid regardless of where the input root is
+// placed. If no rule matches, the target is either left untouched, or
+// the symbolic link is refused entirely.
+//
+// Targets are cleaned prior to matching, meaning that ".." components
+// cannot be used to escape from a rule's replacement. Replacements may
+// not refer to locations above the input root.
+func NewPrefixSymlinkTargetRewriter(rules []SymlinkTargetRewriteRule, refuseUnmatchedAbsoluteTargets bool) (SymlinkTargetRewriter, error) {
+	normalizedRules := make([]SymlinkTargetRewriteRule, 0, len(rules))
+	for _, rule := range rules {
+		if !strings.HasPrefix(rule.AbsolutePrefix, "/") {
+			return nil, status.Errorf(codes.InvalidArgument, "Symlink target prefix %#v is not an absolute path", rule.AbsolutePrefix)
+		}
+		if strings.HasPrefix(rule.Replacement, "/") {
+			return nil, status.Errorf(codes.InvalidArgument, "Replacement %#v of symlink target prefix %#v is not a relative path", rule.Replacement, rule.AbsolutePrefix)
+		}
+		replacement := path.Clean(rule.Replacement)
+		if replacement == ".." || strings.HasPrefix(replacement, "../") {
+			return nil, status.Errorf(codes.InvalidArgument, "Replacement %#v of symlink target prefix %#v refers to a location above the input root", rule.Replacement, rule.AbsolutePrefix)
+		}
+		if replacement == "." {
+			replacement = ""
+		}
+		normalizedRules = append(normalizedRules, SymlinkTargetRewriteRule{
+			AbsolutePrefix: strings.TrimRight(path.Clean(rule.AbsolutePrefix), "/"),
+			Replacement:    replacement,
+		})
+	}
+	return &prefixSymlinkTargetRewriter{
+		rules:                          normalizedRules,
+		refuseUnmatchedAbsoluteTargets: refuseUnmatchedAbsoluteTargets,
+	}, nil
+}
+
+func (r *prefixSymlinkTargetRewriter) RewriteSymlinkTarget(target string, depth int) (string, error) {
+	if !strings.HasPrefix(target, "/") {
+		return target, nil
+	}
+	cleanedTarget := path.Clean(target)
+	for _, rule := range r.rules {
+		if suffix, ok := strings.CutPrefix(cleanedTarget, rule.AbsolutePrefix); ok && (suffix == "" || suffix[0] == '/') {
+			rewritten := strings.TrimPrefix(path.Clean(rule.Replacement+suffix), "/")
+			if rewritten == ".." || strings.HasPrefix(rewritten, "../") {
+				return "", status.Errorf(codes.InvalidArgument, "Target %#v is rewritten to a location above the input root", target)
+			}
+			if rewritten == "." {
+				rewritten = ""
+			}
+			rewritten = strings.TrimRight(strings.Repeat("../", depth)+rewritten, "/")
+			if rewritten == "" {
+				return ".", nil
+			}
+			return rewritten, nil
+		}
+	}
+	if r.refuseUnmatchedAbsoluteTargets {
+		return "", status.Errorf(codes.InvalidArgument, "Target %#v is an absolute path that does not match any of the rewrite rules", target)
+	}
+	return target, nil
+}
@@ -0,0 +1,122 @@
+package virtual_test
+
+import (
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPrefixSymlinkTargetRewriter(t *testing.T) {
+	t.Run("InvalidRules", func(t *testing.T) {
+		_, err := virtual.NewPrefixSymlinkTargetRewriter([]virtual.SymlinkTargetRewriteRule{
+			{AbsolutePrefix: "usr/lib", Replacement: "sysroot/usr/lib"},
+		}, false)
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Symlink target prefix \"usr/lib\" is not an absolute path"), err)
+
+		_, err = virtual.NewPrefixSymlinkTargetRewriter([]virtual.SymlinkTargetRewriteRule{
+			{AbsolutePrefix: "/usr/lib", Replacement: "/sysroot/usr/lib"},
+		}, false)
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Replacement \"/sysroot/usr/lib\" of symlink target prefix \"/usr/lib\" is not a relative path"), err)
+
+		_, err = virtual.NewPrefixSymlinkTargetRewriter([]virtual.SymlinkTargetRewriteRule{
+			{AbsolutePrefix: "/usr/lib", Replacement: "sysroot/../../usr/lib"},
+		}, false)
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Replacement \"sysroot/../../usr/lib\" of symlink target prefix \"/usr/lib\" refers to a location above the input root"), err)
+	})
+
+	symlinkTargetRewriter, err := virtual.NewPrefixSymlinkTargetRewriter([]virtual.SymlinkTargetRewriteRule{
+		{AbsolutePrefix: "/usr/lib/", Replacement: "sysroot/usr/lib"},
+		{AbsolutePrefix: "/opt", Replacement: ""},
+	}, false)
+	require.NoError(t, err)
+
+	t.Run("RelativeTarget", func(t *testing.T) {
+		// Relative targets should be left untouched.
+		target, err := symlinkTargetRewriter.RewriteSymlinkTarget("../usr/lib/libfoo.so", 2)
+		require.NoError(t, err)
+		require.Equal(t, "../usr/lib/libfoo.so", target)
+	})
+
+	t.Run("Rewritten", func(t *testing.T) {
+		// Targets should be made relative to the location of
+		// the symbolic link.
+		target, err := symlinkTargetRewriter.RewriteSymlinkTarget("/usr/lib/libfoo.so", 0)
+		require.NoError(t, err)
+		require.Equal(t, "sysroot/usr/lib/libfoo.so", target)
+
+		target, err = symlinkTargetRewriter.RewriteSymlinkTarget("/usr/lib/libfoo.so", 2)
+		require.NoError(t, err)
+		require.Equal(t, "../../sysroot/usr/lib/libfoo.so", target)
+
+		target, err = symlinkTargetRewriter.RewriteSymlinkTarget("/usr/lib", 1)
+		require.NoError(t, err)
+		require.Equal(t, "../sysroot/usr/lib", target)
+
+		// Replacements may refer to the input root itself.
+		target, err = symlinkTargetRewriter.RewriteSymlinkTarget("/opt/bin/tool", 1)
+		require.NoError(t, err)
+		require.Equal(t, "../bin/tool", target)
+
+		target, err = symlinkTargetRewriter.RewriteSymlinkTarget("/opt", 0)
+		require.NoError(t, err)
+		require.Equal(t, ".", target)
+	})
+
+	t.Run("DotDot", func(t *testing.T) {
+		// Targets should be cleaned prior to matching them.
+		// ".." components should thus not allow escaping from
+		// the replacement, or matching prefixes that are not
+		// actually part of the target.
+		target, err := symlinkTargetRewriter.RewriteSymlinkTarget("/usr/lib/./x86_64/../libfoo.so", 1)
+		require.NoError(t, err)
+		require.Equal(t, "../sysroot/usr/lib/libfoo.so", target)
+
+		target, err = symlinkTargetRewriter.RewriteSymlinkTarget("/opt/../../opt/bin/tool", 0)
+		require.NoError(t, err)
+		require.Equal(t, "bin/tool", target)
+
+		target, err = symlinkTargetRewriter.RewriteSymlinkTarget("/../usr/lib", 0)
+		require.NoError(t, err)
+		require.Equal(t, "sysroot/usr/lib", target)
+
+		target, err = symlinkTargetRewriter.RewriteSymlinkTarget("/usr/lib/../../etc/passwd", 2)
+		require.NoError(t, err)
+		require.Equal(t, "/usr/lib/../../etc/passwd", target)
+
+		symlinkTargetRewriter, err := virtual.NewPrefixSymlinkTargetRewriter([]virtual.SymlinkTargetRewriteRule{
+			{AbsolutePrefix: "/usr/lib", Replacement: "sysroot/usr/lib"},
+		}, true)
+		require.NoError(t, err)
+
+		_, err = symlinkTargetRewriter.RewriteSymlinkTarget("/usr/lib/../../etc/passwd", 0)
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Target \"/usr/lib/../../etc/passwd\" is an absolute path that does not match any of the rewrite rules"), err)
+	})
+
+	t.Run("PartialComponent", func(t *testing.T) {
+		// Prefixes should only match entire pathname components.
+		target, err := symlinkTargetRewriter.RewriteSymlinkTarget("/optional/file", 1)
+		require.NoError(t, err)
+		require.Equal(t, "/optional/file", target)
+	})
+
+	t.Run("Refused", func(t *testing.T) {
+		// If configured, absolute targets that don't match any
+		// of the rules should be refused.
+		symlinkTargetRewriter, err := virtual.NewPrefixSymlinkTargetRewriter([]virtual.SymlinkTargetRewriteRule{
+			{AbsolutePrefix: "/usr/lib", Replacement: "sysroot/usr/lib"},
+		}, true)
+		require.NoError(t, err)
+
+		_, err = symlinkTargetRewriter.RewriteSymlinkTarget("/etc/passwd", 0)
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Target \"/etc/passwd\" is an absolute path that does not match any of the rewrite rules"), err)
+
+		target, err := symlinkTargetRewriter.RewriteSymlinkTarget("libfoo.so.1", 0)
+		require.NoError(t, err)
+		require.Equal(t, "libfoo.so.1", target)
+	})
+}
@@ -0,0 +1,536 @@
+//go:build linux
+// +build linux
+
+package virtiofs
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"log"
+	"net"
+
+	"golang.org/x/sys/unix"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// Flags of FUSE_SETUPMAPPING.
+	fuseSetupMappingFlagWrite = 1 << 0
+
+	// The FATTR_SIZE bit of the valid field of FUSE_SETATTR.
+	fuseSetattrValidSize = 1 << 3
+
+	// Bit that is set in the identifiers of requests that are
+	// issued by the device itself. Identifiers of requests issued
+	// by the guest are allocated sequentially, starting at zero.
+	internalRequestUniqueBit = 1 << 63
+
+	// The largest read or write issued by the device itself if the
+	// FUSE server did not report max_write through FUSE_INIT.
+	defaultMaximumWriteSizeBytes = 4096
+)
+
+// backendChannel over which the device sends requests to the virtual
+// machine monitor, such as requests to map memory into the DAX window.
+type backendChannel struct {
+	conn     *net.UnixConn
+	replyAck bool
+}
+
+// call sends a request to the virtual machine monitor, optionally
+// attaching a file descriptor. If VHOST_USER_PROTOCOL_F_REPLY_ACK was
+// negotiated, it waits for the request to be acknowledged.
+func (bc *backendChannel) call(request uint32, payload []byte, fd int) error {
+	flags := uint32(vhostUserFlagVersion)
+	if bc.replyAck {
+		flags |= vhostUserFlagNeedReply
+	}
+	message := make([]byte, vhostUserHeaderSizeBytes, vhostUserHeaderSizeBytes+len(payload))
+	binary.LittleEndian.PutUint32(message[0:], request)
+	binary.LittleEndian.PutUint32(message[4:], flags)
+	binary.LittleEndian.PutUint32(message[8:], uint32(len(payload)))
+	var oob []byte
+	if fd >= 0 {
+		oob = unix.UnixRights(fd)
+	}
+	if _, _, err := bc.conn.WriteMsgUnix(append(message, payload...), oob, nil); err != nil {
+		return err
+	}
+	if !bc.replyAck {
+		return nil
+	}
+
+	var reply [vhostUserHeaderSizeBytes + 8]byte
+	if _, err := io.ReadFull(bc.conn, reply[:]); err != nil {
+		return err
+	}
+	if binary.LittleEndian.Uint32(reply[0:]) != request || binary.LittleEndian.Uint32(reply[4:])&vhostUserFlagReply == 0 || binary.LittleEndian.Uint32(reply[8:]) != 8 {
+		return status.Errorf(codes.Internal, "Received malformed reply to backend request %d", request)
+	}
+	if ack := binary.LittleEndian.Uint64(reply[vhostUserHeaderSizeBytes:]); ack != 0 {
+		return status.Errorf(codes.Internal, "Virtual machine monitor failed to process backend request %d with status %d", request, ack)
+	}
+	return nil
+}
+
+// mapFile requests that the virtual machine monitor maps a file into
+// the DAX window, using VHOST_USER_BACKEND_FS_MAP.
+func (bc *backendChannel) mapFile(fd int, cacheOffset, length uint64, writable bool) error {
+	flags := uint64(vhostUserFSFlagMapRead)
+	if writable {
+		flags |= vhostUserFSFlagMapWrite
+	}
+	return bc.call(vhostUserBackendFSMap, encodeFSBackendMessage(0, cacheOffset, length, flags), fd)
+}
+
+// unmap requests that the virtual machine monitor removes a range
+// from the DAX window, using VHOST_USER_BACKEND_FS_UNMAP.
+func (bc *backendChannel) unmap(cacheOffset, length uint64) error {
+	return bc.call(vhostUserBackendFSUnmap, encodeFSBackendMessage(0, cacheOffset, length, 0), -1)
+}
+
+// encodeFSBackendMessage creates a struct VhostUserFSBackendMsg
+// containing a single entry.
+func encodeFSBackendMessage(fdOffset, cacheOffset, length, flags uint64) []byte {
+	var payload [4 * vhostUserFSBackendEntries * 8]byte
+	binary.LittleEndian.PutUint64(payload[0*vhostUserFSBackendEntries*8:], fdOffset)
+	binary.LittleEndian.PutUint64(payload[1*vhostUserFSBackendEntries*8:], cacheOffset)
+	binary.LittleEndian.PutUint64(payload[2*vhostUserFSBackendEntries*8:], length)
+	binary.LittleEndian.PutUint64(payload[3*vhostUserFSBackendEntries*8:], flags)
+	return payload[:]
+}
+
+// daxMapping of a range of a file into the DAX window of the guest.
+//
+// Files in the virtual file system are generally not backed by host
+// file descriptors. The contents of the range are therefore copied
+// into a memfd, which is mapped into the DAX window by the virtual
+// machine monitor. Changes made by the guest are written back to the
+// FUSE server when the file is flushed, synchronized or closed, or
+// when the mapping is removed.
+type daxMapping struct {
+	nodeID      uint64
+	fileHandle  uint64
+	fileOffset  uint64
+	cacheOffset uint64
+	writable    bool
+	fd          int
+	data        []byte
+}
+
+func (m *daxMapping) release() {
+	unix.Munmap(m.data)
+	unix.Close(m.fd)
+}
+
+// overlapsFile returns the part of the mapping that overlaps with a
+// range of a file, and the offset of the range at which it starts.
+func (m *daxMapping) overlapsFile(nodeID, offset, length uint64) ([]byte, uint64, bool) {
+	if m.nodeID != nodeID {
+		return nil, 0, false
+	}
+	start, end := max(offset, m.fileOffset), min(offset+length, m.fileOffset+uint64(len(m.data)))
+	if start >= end {
+		return nil, 0, false
+	}
+	return m.data[start-m.fileOffset : end-m.fileOffset], start - offset, true
+}
+
+// toErrno converts an error to an error number that can be returned to
+// the guest.
+func toErrno(err error) unix.Errno {
+	var errno unix.Errno
+	if errors.As(err, &errno) {
+		return errno
+	}
+	log.Print("Failed to process virtio-fs DAX request: ", err)
+	return unix.EIO
+}
+
+// setBackendChannel installs the channel over which the device may
+// send requests to the virtual machine monitor.
+func (d *device) setBackendChannel(bc *backendChannel) {
+	d.daxLock.Lock()
+	defer d.daxLock.Unlock()
+
+	if d.backendChannel != nil {
+		d.backendChannel.conn.Close()
+	}
+	d.backendChannel = bc
+}
+
+// callFUSEServer issues a request to the FUSE server on behalf of the
+// device itself, and waits for its response. The identifier of the
+// request is overwritten.
+func (d *device) callFUSEServer(request []byte) ([]byte, error) {
+	d.lock.Lock()
+	if d.internalRequests == nil {
+		d.lock.Unlock()
+		return nil, status.Error(codes.Unavailable, "Connection to FUSE server has been closed")
+	}
+	d.lastInternalUnique += 2
+	unique := internalRequestUniqueBit | d.lastInternalUnique
+	waiter := make(chan []byte, 1)
+	d.internalRequests[unique] = waiter
+	d.lock.Unlock()
+
+	binary.LittleEndian.PutUint64(request[8:], unique)
+	if _, err := d.fuseDevice.Write(request); err != nil {
+		d.lock.Lock()
+		delete(d.internalRequests, unique)
+		d.lock.Unlock()
+		return nil, err
+	}
+	response, ok := <-waiter
+	if !ok {
+		return nil, status.Error(codes.Unavailable, "Connection to FUSE server has been closed")
+	}
+	if errno := int32(binary.LittleEndian.Uint32(response[4:])); errno != 0 {
+		return nil, unix.Errno(-errno)
+	}
+	return response[fuseOutHeaderSizeBytes:], nil
+}
+
+// newInternalRequest creates a request that is issued by the device
+// itself. The credentials of the request are copied from the request
+// of the guest that caused it to be issued.
+func newInternalRequest(cause []byte, opcode uint32, nodeID uint64, body ...[]byte) []byte {
+	request := make([]byte, fuseInHeaderSizeBytes)
+	binary.LittleEndian.PutUint32(request[4:], opcode)
+	binary.LittleEndian.PutUint64(request[16:], nodeID)
+	copy(request[24:36], cause[24:36])
+	for _, b := range body {
+		request = append(request, b...)
+	}
+	binary.LittleEndian.PutUint32(request[0:], uint32(len(request)))
+	return request
+}
+
+func (d *device) getMaximumTransferSizeBytes() int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.maximumWriteSizeBytes == 0 {
+		return defaultMaximumWriteSizeBytes
+	}
+	return int(d.maximumWriteSizeBytes)
+}
+
+// readMappingLocked loads the contents of the range of the file that
+// is mapped into the DAX window from the FUSE server. Any part of the
+// range that lies past the end of the file is zeroed.
+func (d *device) readMappingLocked(cause []byte, m *daxMapping) error {
+	chunkSize := d.getMaximumTransferSizeBytes()
+	for done := 0; done < len(m.data); {
+		var readIn [40]byte
+		binary.LittleEndian.PutUint64(readIn[0:], m.fileHandle)
+		binary.LittleEndian.PutUint64(readIn[8:], m.fileOffset+uint64(done))
+		binary.LittleEndian.PutUint32(readIn[16:], uint32(min(len(m.data)-done, chunkSize)))
+		data, err := d.callFUSEServer(newInternalRequest(cause, fuseOpcodeRead, m.nodeID, readIn[:]))
+		if err != nil {
+			return err
+		}
+		n := copy(m.data[done:], data)
+		done += n
+		if n < chunkSize {
+			clear(m.data[done:])
+			break
+		}
+	}
+	return nil
+}
+
+// writeBackMappingLocked writes the contents of a range of a file that
+// is mapped into the DAX window back to the FUSE server. As the guest
+// sends FUSE_WRITE for writes that extend a file instead of writing
+// into the DAX window, the range is truncated to the current size of
+// the file.
+func (d *device) writeBackMappingLocked(cause []byte, m *daxMapping) error {
+	var getattrIn [16]byte
+	attrOut, err := d.callFUSEServer(newInternalRequest(cause, fuseOpcodeGetattr, m.nodeID, getattrIn[:]))
+	if err != nil {
+		return err
+	}
+	if len(attrOut) < 32 {
+		return status.Error(codes.Internal, "FUSE server returned a malformed response to FUSE_GETATTR")
+	}
+	fileSize := binary.LittleEndian.Uint64(attrOut[24:])
+	if fileSize <= m.fileOffset {
+		return nil
+	}
+	data := m.data[:min(uint64(len(m.data)), fileSize-m.fileOffset)]
+
+	chunkSize := d.getMaximumTransferSizeBytes()
+	for done := 0; done < len(data); {
+		chunk := data[done:min(len(data), done+chunkSize)]
+		var writeIn [40]byte
+		binary.LittleEndian.PutUint64(writeIn[0:], m.fileHandle)
+		binary.LittleEndian.PutUint64(writeIn[8:], m.fileOffset+uint64(done))
+		binary.LittleEndian.PutUint32(writeIn[16:], uint32(len(chunk)))
+		writeOut, err := d.callFUSEServer(newInternalRequest(cause, fuseOpcodeWrite, m.nodeID, writeIn[:], chunk))
+		if err != nil {
+			return err
+		}
+		if len(writeOut) < 4 || binary.LittleEndian.Uint32(writeOut) != uint32(len(chunk)) {
+			return status.Error(codes.Internal, "FUSE server did not write back all data of a DAX mapping")
+		}
+		done += len(chunk)
+	}
+	return nil
+}
+
+// writeBackFileLocked writes back all writable mappings of a file.
+func (d *device) writeBackFileLocked(cause []byte, nodeID uint64) error {
+	for _, m := range d.daxMappings {
+		if m.nodeID == nodeID && m.writable {
+			if err := d.writeBackMappingLocked(cause, m); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// removeMappingLocked writes back the contents of a mapping and
+// removes it from the DAX window.
+func (d *device) removeMappingLocked(cause []byte, m *daxMapping) error {
+	var err error
+	if m.writable {
+		err = d.writeBackMappingLocked(cause, m)
+	}
+	if d.backendChannel != nil {
+		if unmapErr := d.backendChannel.unmap(m.cacheOffset, uint64(len(m.data))); err == nil {
+			err = unmapErr
+		}
+	}
+	m.release()
+	delete(d.daxMappings, m.cacheOffset)
+	return err
+}
+
+// setupMapping processes FUSE_SETUPMAPPING, mapping a range of a file
+// into the DAX window.
+func (d *device) setupMapping(request []byte) error {
+	body := request[fuseInHeaderSizeBytes:]
+	if len(body) < 40 {
+		return unix.EINVAL
+	}
+	nodeID := binary.LittleEndian.Uint64(request[16:])
+	fileHandle := binary.LittleEndian.Uint64(body[0:])
+	fileOffset := binary.LittleEndian.Uint64(body[8:])
+	length := binary.LittleEndian.Uint64(body[16:])
+	writable := binary.LittleEndian.Uint64(body[24:])&fuseSetupMappingFlagWrite != 0
+	cacheOffset := binary.LittleEndian.Uint64(body[32:])
+	if length == 0 || length > 1<<30 || fileOffset+length < fileOffset || cacheOffset+length < cacheOffset {
+		return unix.EINVAL
+	}
+
+	d.daxLock.Lock()
+	defer d.daxLock.Unlock()
+
+	bc := d.backendChannel
+	if bc == nil {
+		return unix.EOPNOTSUPP
+	}
+
+	// The guest upgrades read-only mappings to writable mappings
+	// by mapping the same range again. There is no need to reload
+	// the contents of the file in that case.
+	if m, ok := d.daxMappings[cacheOffset]; ok && m.nodeID == nodeID && m.fileOffset == fileOffset && uint64(len(m.data)) == length {
+		if writable && !m.writable {
+			if err := bc.mapFile(m.fd, cacheOffset, length, true); err != nil {
+				return err
+			}
+			m.writable = true
+			m.fileHandle = fileHandle
+		}
+		return nil
+	}
+
+	// Remove existing mappings that overlap with the new mapping.
+	for _, m := range d.daxMappings {
+		if m.cacheOffset < cacheOffset+length && cacheOffset < m.cacheOffset+uint64(len(m.data)) {
+			if err := d.removeMappingLocked(request, m); err != nil {
+				return err
+			}
+		}
+	}
+
+	fd, err := unix.MemfdCreate("virtiofs-dax", unix.MFD_CLOEXEC)
+	if err != nil {
+		return err
+	}
+	if err := unix.Ftruncate(fd, int64(length)); err != nil {
+		unix.Close(fd)
+		return err
+	}
+	data, err := unix.Mmap(fd, 0, int(length), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	if err != nil {
+		unix.Close(fd)
+		return err
+	}
+	m := &daxMapping{
+		nodeID:      nodeID,
+		fileHandle:  fileHandle,
+		fileOffset:  fileOffset,
+		cacheOffset: cacheOffset,
+		writable:    writable,
+		fd:          fd,
+		data:        data,
+	}
+	if err := d.readMappingLocked(request, m); err != nil {
+		m.release()
+		return err
+	}
+	if err := bc.mapFile(fd, cacheOffset, length, writable); err != nil {
+		m.release()
+		return err
+	}
+	d.daxMappings[cacheOffset] = m
+	return nil
+}
+
+// removeMappings processes FUSE_REMOVEMAPPING, removing one or more
+// ranges from the DAX window.
+func (d *device) removeMappings(request []byte) error {
+	body := request[fuseInHeaderSizeBytes:]
+	if len(body) < 4 {
+		return unix.EINVAL
+	}
+	count := binary.LittleEndian.Uint32(body)
+	if uint64(len(body)-4) < uint64(count)*16 {
+		return unix.EINVAL
+	}
+
+	d.daxLock.Lock()
+	defer d.daxLock.Unlock()
+
+	var firstErr error
+	for i := 0; i < int(count); i++ {
+		entry := body[4+16*i:]
+		cacheOffset := binary.LittleEndian.Uint64(entry[0:])
+		end := cacheOffset + binary.LittleEndian.Uint64(entry[8:])
+		if end < cacheOffset {
+			end = ^uint64(0)
+		}
+		for _, m := range d.daxMappings {
+			if m.cacheOffset < end && cacheOffset < m.cacheOffset+uint64(len(m.data)) {
+				if err := d.removeMappingLocked(request, m); err != nil && firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+	}
+	return firstErr
+}
+
+// forwardMappedFileRequest forwards a request that accesses or
+// modifies the contents of a file to the FUSE server. If the file is
+// mapped into the DAX window, its mappings are synchronized with the
+// FUSE server.
+func (d *device) forwardMappedFileRequest(request []byte) {
+	d.daxLock.Lock()
+	defer d.daxLock.Unlock()
+
+	opcode := binary.LittleEndian.Uint32(request[4:])
+	nodeID := binary.LittleEndian.Uint64(request[16:])
+	body := request[fuseInHeaderSizeBytes:]
+	targetNodeID := nodeID
+	if opcode == fuseOpcodeCopyFileRange && len(body) >= 24 {
+		targetNodeID = binary.LittleEndian.Uint64(body[16:])
+	}
+	isMapped := false
+	for _, m := range d.daxMappings {
+		if m.nodeID == nodeID || m.nodeID == targetNodeID {
+			isMapped = true
+			break
+		}
+	}
+	if !isMapped {
+		d.writeToFUSEServer(request)
+		return
+	}
+
+	switch opcode {
+	case fuseOpcodeRelease, fuseOpcodeFsync, fuseOpcodeFlush:
+		// Make changes made through the DAX window visible
+		// to the FUSE server before the file is closed or
+		// synchronized.
+		if err := d.writeBackFileLocked(request, nodeID); err != nil {
+			log.Print("Failed to write back virtio-fs DAX mapping: ", err)
+		}
+		d.writeToFUSEServer(request)
+	case fuseOpcodeWrite:
+		// Copy data written through FUSE_WRITE into
+		// overlapping mappings.
+		response, err := d.callFUSEServerOnBehalfOfGuest(request)
+		if err == nil && len(body) >= 40 && len(response) >= fuseOutHeaderSizeBytes+4 {
+			offset := binary.LittleEndian.Uint64(body[8:])
+			written := min(uint64(binary.LittleEndian.Uint32(response[fuseOutHeaderSizeBytes:])), uint64(len(body)-40))
+			for _, m := range d.daxMappings {
+				if overlap, start, ok := m.overlapsFile(nodeID, offset, written); ok {
+					copy(overlap, body[40+start:])
+				}
+			}
+		}
+	case fuseOpcodeSetattr:
+		// Zero the parts of mappings past the end of a
+		// truncated file, so that they don't reappear when the
+		// file is extended.
+		_, err := d.callFUSEServerOnBehalfOfGuest(request)
+		if err == nil && len(body) >= 24 && binary.LittleEndian.Uint32(body[0:])&fuseSetattrValidSize != 0 {
+			size := binary.LittleEndian.Uint64(body[16:])
+			for _, m := range d.daxMappings {
+				if overlap, _, ok := m.overlapsFile(nodeID, size, ^uint64(0)-size); ok {
+					clear(overlap)
+				}
+			}
+		}
+	default:
+		// FUSE_FALLOCATE and FUSE_COPY_FILE_RANGE modify the
+		// contents of the file in ways that cannot be replicated
+		// easily. Write back any changes, and reload the
+		// mappings of the modified file afterwards.
+		err := d.writeBackFileLocked(request, nodeID)
+		if err == nil && targetNodeID != nodeID {
+			err = d.writeBackFileLocked(request, targetNodeID)
+		}
+		if err != nil {
+			d.completeRequestWithError(request, err)
+			return
+		}
+		if _, err := d.callFUSEServerOnBehalfOfGuest(request); err == nil {
+			for _, m := range d.daxMappings {
+				if m.nodeID == targetNodeID {
+					if err := d.readMappingLocked(request, m); err != nil {
+						log.Print("Failed to reload virtio-fs DAX mapping: ", err)
+					}
+				}
+			}
+		}
+	}
+}
+
+// callFUSEServerOnBehalfOfGuest forwards a request of the guest to the
+// FUSE server and waits for its response, which is subsequently
+// returned to the guest. This allows the device to act on the outcome
+// of the request before the guest does.
+func (d *device) callFUSEServerOnBehalfOfGuest(request []byte) ([]byte, error) {
+	var unique [8]byte
+	copy(unique[:], request[8:16])
+	payload, err := d.callFUSEServer(request)
+	if err != nil {
+		copy(request[8:16], unique[:])
+		d.completeRequestWithError(request, err)
+		return nil, err
+	}
+	response := make([]byte, fuseOutHeaderSizeBytes, fuseOutHeaderSizeBytes+len(payload))
+	binary.LittleEndian.PutUint32(response[0:], uint32(fuseOutHeaderSizeBytes+len(payload)))
+	copy(response[8:], unique[:])
+	response = append(response, payload...)
+	d.completeRequest(response)
+	return response, nil
+}
@@ -0,0 +1,406 @@
+//go:build linux
+// +build linux
+
+package virtiofs
+
+import (
+	"encoding/binary"
+	"log"
+	"net"
+	"os"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// Constants pertaining to the FUSE protocol that are needed to route
+// requests and responses between virtqueues and the FUSE server.
+const (
+	fuseInHeaderSizeBytes  = 40
+	fuseOutHeaderSizeBytes = 16
+
+	fuseOpcodeForget        = 2
+	fuseOpcodeGetattr       = 3
+	fuseOpcodeSetattr       = 4
+	fuseOpcodeRead          = 15
+	fuseOpcodeWrite         = 16
+	fuseOpcodeRelease       = 18
+	fuseOpcodeFsync         = 20
+	fuseOpcodeFlush         = 25
+	fuseOpcodeInit          = 26
+	fuseOpcodeInterrupt     = 36
+	fuseOpcodeBatchForget   = 42
+	fuseOpcodeFallocate     = 43
+	fuseOpcodeCopyFileRange = 47
+	fuseOpcodeSetupMapping  = 48
+	fuseOpcodeRemoveMapping = 49
+
+	// The largest message that the FUSE server may send. This
+	// corresponds to the largest maximum write size that may be
+	// negotiated through FUSE_INIT, plus space for headers.
+	maximumFUSEMessageSizeBytes = 1<<20 + 4096
+)
+
+// queue of the virtio-fs device. Queue 0 is used for high priority
+// requests (FORGET and INTERRUPT). All other queues are used for
+// regular requests.
+type queue struct {
+	// Fields provided through vhost-user messages prior to
+	// starting the queue.
+	size               uint16
+	descriptorsAddress uint64
+	availableAddress   uint64
+	usedAddress        uint64
+	base               uint16
+	call               *os.File
+	enabled            bool
+
+	// Fields that are set while the queue is running. The
+	// generation is incremented every time the queue is stopped,
+	// so that responses for requests that were taken from a
+	// previous incarnation of the queue are discarded.
+	virtqueue  *virtqueue
+	kick       *os.File
+	generation uint64
+}
+
+// pendingRequest contains the information that is needed to write a
+// response of the FUSE server back into guest memory.
+type pendingRequest struct {
+	opcode           uint32
+	queue            *queue
+	queueGeneration  uint64
+	memoryGeneration uint64
+	head             uint16
+	writable         [][]byte
+}
+
+// device of type virtio-fs, whose requests are forwarded to a FUSE
+// server that is connected through a socket.
+type device struct {
+	fuseDevice *net.UnixConn
+
+	lock             sync.Mutex
+	memory           guestMemory
+	memoryGeneration uint64
+	queues           [maximumQueues]queue
+	pendingRequests  map[uint64]pendingRequest
+	closed           bool
+
+	// Requests that are issued by the device itself, such as
+	// reads and writes of file contents that are mapped into the
+	// DAX window, and the largest transfer size negotiated by the
+	// FUSE server through FUSE_INIT.
+	internalRequests      map[uint64]chan []byte
+	lastInternalUnique    uint64
+	maximumWriteSizeBytes uint32
+
+	// State of the DAX window. The lock is held while mappings
+	// are created, removed or written back, so that their
+	// contents remain consistent with those of the FUSE server.
+	daxLock        sync.Mutex
+	backendChannel *backendChannel
+	daxMappings    map[uint64]*daxMapping
+}
+
+func newDevice(fuseDevice *net.UnixConn) *device {
+	return &device{
+		fuseDevice:       fuseDevice,
+		pendingRequests:  map[uint64]pendingRequest{},
+		internalRequests: map[uint64]chan []byte{},
+		daxMappings:      map[uint64]*daxMapping{},
+	}
+}
+
+// setMemoryLocked replaces the guest memory that is available to the
+// device. Queues that are running are relocated to the new guest
+// memory. Responses for requests that refer to the old guest memory
+// are discarded.
+func (d *device) setMemoryLocked(memory guestMemory) {
+	oldMemory := d.memory
+	d.memory = memory
+	d.memoryGeneration++
+	for i := range d.queues {
+		q := &d.queues[i]
+		if oldVirtqueue := q.virtqueue; oldVirtqueue != nil {
+			vq, err := newVirtqueue(memory, q.size, q.descriptorsAddress, q.availableAddress, q.usedAddress, oldVirtqueue.lastAvailableIndex)
+			if err != nil {
+				log.Printf("Failed to relocate virtio-fs queue %d: %s", i, err)
+				d.stopQueueLocked(q)
+				continue
+			}
+			vq.usedIndex = oldVirtqueue.usedIndex
+			q.virtqueue = vq
+		}
+	}
+	oldMemory.unmap()
+}
+
+// startQueueLocked starts processing requests on a queue, after the
+// virtual machine monitor has provided the file descriptor that is used
+// to signal that new requests are available.
+func (d *device) startQueueLocked(q *queue, kick *os.File) error {
+	if q.virtqueue != nil {
+		d.stopQueueLocked(q)
+	}
+	vq, err := newVirtqueue(d.memory, q.size, q.descriptorsAddress, q.availableAddress, q.usedAddress, q.base)
+	if err != nil {
+		kick.Close()
+		return err
+	}
+	q.virtqueue = vq
+	q.kick = kick
+
+	generation := q.generation
+	go func() {
+		var counter [8]byte
+		for {
+			if _, err := kick.Read(counter[:]); err != nil {
+				return
+			}
+			if !d.processQueue(q, generation) {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// stopQueueLocked stops processing requests on a queue. It returns
+// the index of the next descriptor chain to be taken from the available
+// ring, so that the queue can be resumed later.
+func (d *device) stopQueueLocked(q *queue) uint16 {
+	if q.virtqueue == nil {
+		return q.base
+	}
+	q.base = q.virtqueue.lastAvailableIndex
+	q.virtqueue = nil
+	q.kick.Close()
+	q.kick = nil
+	q.generation++
+	return q.base
+}
+
+// notifyLocked informs the guest that descriptor chains have been
+// placed on the used ring of a queue.
+func (d *device) notifyLocked(q *queue) {
+	if q.call != nil && q.virtqueue.needsNotification() {
+		var counter [8]byte
+		binary.LittleEndian.PutUint64(counter[:], 1)
+		q.call.Write(counter[:])
+	}
+}
+
+// processQueue takes all descriptor chains from a queue's available
+// ring, and forwards the requests contained in them to the FUSE server.
+// It returns false if the queue has been stopped.
+func (d *device) processQueue(q *queue, generation uint64) bool {
+	var requests [][]byte
+	d.lock.Lock()
+	if d.closed || q.generation != generation {
+		d.lock.Unlock()
+		return false
+	}
+	notify := false
+	for q.enabled {
+		chain, ok, err := q.virtqueue.pop(d.memory)
+		if err != nil {
+			// The guest has placed a malformed
+			// descriptor chain on the ring. There is no
+			// way to recover from this.
+			log.Print("Failed to process virtio-fs queue: ", err)
+			d.stopQueueLocked(q)
+			break
+		}
+		if !ok {
+			break
+		}
+
+		var request []byte
+		for _, buffer := range chain.readable {
+			request = append(request, buffer...)
+		}
+		if len(request) < fuseInHeaderSizeBytes {
+			q.virtqueue.push(chain.head, 0)
+			notify = true
+			continue
+		}
+		switch opcode := binary.LittleEndian.Uint32(request[4:]); opcode {
+		case fuseOpcodeForget, fuseOpcodeInterrupt, fuseOpcodeBatchForget:
+			// Requests for which the FUSE server sends
+			// no response. Complete them immediately.
+			q.virtqueue.push(chain.head, 0)
+			notify = true
+		default:
+			d.pendingRequests[binary.LittleEndian.Uint64(request[8:])] = pendingRequest{
+				opcode:           opcode,
+				queue:            q,
+				queueGeneration:  q.generation,
+				memoryGeneration: d.memoryGeneration,
+				head:             chain.head,
+				writable:         chain.writable,
+			}
+		}
+		requests = append(requests, request)
+	}
+	if notify && q.virtqueue != nil {
+		d.notifyLocked(q)
+	}
+	running := q.generation == generation
+	d.lock.Unlock()
+
+	for _, request := range requests {
+		d.forwardRequest(request)
+	}
+	return running
+}
+
+// forwardRequest forwards a single request to the FUSE server.
+// Requests pertaining to the DAX window are processed by the device
+// itself, while requests that access or modify files that are mapped
+// into the DAX window need to be synchronized with their mappings.
+func (d *device) forwardRequest(request []byte) {
+	switch binary.LittleEndian.Uint32(request[4:]) {
+	case fuseOpcodeSetupMapping:
+		d.completeRequestWithError(request, d.setupMapping(request))
+		return
+	case fuseOpcodeRemoveMapping:
+		d.completeRequestWithError(request, d.removeMappings(request))
+		return
+	case fuseOpcodeSetattr, fuseOpcodeWrite, fuseOpcodeRelease, fuseOpcodeFsync, fuseOpcodeFlush, fuseOpcodeFallocate, fuseOpcodeCopyFileRange:
+		d.forwardMappedFileRequest(request)
+		return
+	}
+	d.writeToFUSEServer(request)
+}
+
+func (d *device) writeToFUSEServer(request []byte) {
+	if _, err := d.fuseDevice.Write(request); err != nil {
+		log.Print("Failed to forward virtio-fs request to FUSE server: ", err)
+	}
+}
+
+// processResponses reads responses from the FUSE server, and writes
+// them into the writable buffers of the descriptor chains of the
+// corresponding requests. This function returns when the connection to
+// the FUSE server is closed.
+func (d *device) processResponses() {
+	response := make([]byte, maximumFUSEMessageSizeBytes)
+	for {
+		n, err := d.fuseDevice.Read(response)
+		if err != nil || n == 0 {
+			// Wake up any requests issued by the device
+			// itself that are still waiting for a response.
+			d.lock.Lock()
+			for _, waiter := range d.internalRequests {
+				close(waiter)
+			}
+			d.internalRequests = nil
+			d.lock.Unlock()
+			return
+		}
+		if n >= fuseOutHeaderSizeBytes {
+			d.completeRequest(response[:n])
+		}
+	}
+}
+
+// completeRequest writes a response into the writable buffers of the
+// descriptor chain of the corresponding request. Responses with
+// unknown identifiers include notifications sent by the FUSE server,
+// which cannot be delivered, as the notification queue is not offered
+// to the guest.
+func (d *device) completeRequest(response []byte) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	unique := binary.LittleEndian.Uint64(response[8:])
+	if waiter, ok := d.internalRequests[unique]; ok {
+		delete(d.internalRequests, unique)
+		waiter <- append([]byte(nil), response...)
+		return
+	}
+	p, ok := d.pendingRequests[unique]
+	if !ok {
+		return
+	}
+	delete(d.pendingRequests, unique)
+	if p.opcode == fuseOpcodeInit && len(response) >= fuseOutHeaderSizeBytes+24 && binary.LittleEndian.Uint32(response[4:]) == 0 {
+		// Extract max_write from struct fuse_init_out, so
+		// that reads and writes issued by the device itself
+		// don't exceed it.
+		d.maximumWriteSizeBytes = binary.LittleEndian.Uint32(response[fuseOutHeaderSizeBytes+20:])
+	}
+	q := p.queue
+	if !d.closed && q.generation == p.queueGeneration && d.memoryGeneration == p.memoryGeneration {
+		remaining := response
+		written := 0
+		for _, buffer := range p.writable {
+			c := copy(buffer, remaining)
+			remaining = remaining[c:]
+			written += c
+		}
+		q.virtqueue.push(p.head, uint32(written))
+		d.notifyLocked(q)
+	}
+}
+
+// completeRequestWithError completes a request that is processed by
+// the device itself, using a response without a payload.
+func (d *device) completeRequestWithError(request []byte, err error) {
+	var response [fuseOutHeaderSizeBytes]byte
+	binary.LittleEndian.PutUint32(response[0:], fuseOutHeaderSizeBytes)
+	if err != nil {
+		binary.LittleEndian.PutUint32(response[4:], uint32(-int32(toErrno(err))))
+	}
+	copy(response[8:], request[8:16])
+	d.completeRequest(response[:])
+}
+
+// close the device, stopping all queues and releasing guest memory.
+func (d *device) close() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.closed = true
+	for i := range d.queues {
+		q := &d.queues[i]
+		d.stopQueueLocked(q)
+		if q.call != nil {
+			q.call.Close()
+			q.call = nil
+		}
+	}
+	d.memory.unmap()
+	d.memory = nil
+	d.pendingRequests = map[uint64]pendingRequest{}
+}
+
+// closeDAXWindow releases all mappings of the DAX window, and closes
+// the channel over which the virtual machine monitor was asked to
+// create them.
+func (d *device) closeDAXWindow() {
+	d.daxLock.Lock()
+	defer d.daxLock.Unlock()
+
+	for cacheOffset, m := range d.daxMappings {
+		m.release()
+		delete(d.daxMappings, cacheOffset)
+	}
+	if d.backendChannel != nil {
+		d.backendChannel.conn.Close()
+		d.backendChannel = nil
+	}
+}
+
+// newKickFile converts a file descriptor of an eventfd that is used by
+// the guest to signal that requests are available to an os.File. The
+// file descriptor is placed in non-blocking mode, so that pending reads
+// are interrupted when the file is closed.
+func newKickFile(fd int) (*os.File, error) {
+	if err := unix.SetNonblock(fd, true); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), "vring-kick"), nil
+}
@@ -0,0 +1,121 @@
+//go:build linux
+// +build linux
+
+package virtiofs
+
+import (
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"golang.org/x/sys/unix"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maximumMemoryRegions is the maximum number of memory regions that
+// may be provided through VHOST_USER_SET_MEM_TABLE.
+const maximumMemoryRegions = 8
+
+// memoryRegion of the guest that has been mapped into the address
+// space of the current process.
+type memoryRegion struct {
+	guestPhysicalAddress uint64
+	userspaceAddress     uint64
+	mapping              []byte
+	data                 []byte
+}
+
+// guestMemory contains all memory regions of the guest that have been
+// provided by the virtual machine monitor. Descriptors in virtqueues
+// refer to memory using guest physical addresses, while the locations
+// of the virtqueues themselves are provided as addresses in the address
+// space of the virtual machine monitor.
+type guestMemory []memoryRegion
+
+// memoryRegionDescription corresponds to struct
+// vhost_user_memory_region, as sent as part of
+// VHOST_USER_SET_MEM_TABLE.
+type memoryRegionDescription struct {
+	guestPhysicalAddress uint64
+	memorySize           uint64
+	userspaceAddress     uint64
+	mmapOffset           uint64
+}
+
+// newGuestMemory maps memory regions of the guest into the address
+// space of the current process. File descriptors are closed,
+// regardless of whether mapping succeeds.
+func newGuestMemory(descriptions []memoryRegionDescription, fds []int) (guestMemory, error) {
+	defer func() {
+		for _, fd := range fds {
+			unix.Close(fd)
+		}
+	}()
+	if len(descriptions) != len(fds) {
+		return nil, status.Errorf(codes.InvalidArgument, "Received %d memory regions, but %d file descriptors", len(descriptions), len(fds))
+	}
+
+	var gm guestMemory
+	for i, description := range descriptions {
+		mappingSize := description.mmapOffset + description.memorySize
+		if description.memorySize == 0 || mappingSize < description.memorySize || mappingSize > uint64(^uint(0)>>1) {
+			gm.unmap()
+			return nil, status.Errorf(codes.InvalidArgument, "Memory region %d has an invalid size", i)
+		}
+		mapping, err := unix.Mmap(fds[i], 0, int(mappingSize), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+		if err != nil {
+			gm.unmap()
+			return nil, util.StatusWrapfWithCode(err, codes.Internal, "Failed to map memory region %d", i)
+		}
+		gm = append(gm, memoryRegion{
+			guestPhysicalAddress: description.guestPhysicalAddress,
+			userspaceAddress:     description.userspaceAddress,
+			mapping:              mapping,
+			data:                 mapping[description.mmapOffset:],
+		})
+	}
+	return gm, nil
+}
+
+// unmap all memory regions. Slices that were obtained through
+// translation may no longer be accessed afterwards.
+func (gm guestMemory) unmap() {
+	for _, region := range gm {
+		unix.Munmap(region.mapping)
+	}
+}
+
+func translate(data []byte, base, address, length uint64) ([]byte, bool) {
+	if address < base {
+		return nil, false
+	}
+	offset := address - base
+	size := uint64(len(data))
+	if offset > size || length > size-offset {
+		return nil, false
+	}
+	return data[offset : offset+length : offset+length], true
+}
+
+// translateGuestPhysical returns the memory backing a range of guest
+// physical addresses, as used by virtqueue descriptors.
+func (gm guestMemory) translateGuestPhysical(address, length uint64) ([]byte, bool) {
+	for _, region := range gm {
+		if b, ok := translate(region.data, region.guestPhysicalAddress, address, length); ok {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// translateUserspace returns the memory backing a range of addresses
+// in the address space of the virtual machine monitor, as used by
+// VHOST_USER_SET_VRING_ADDR.
+func (gm guestMemory) translateUserspace(address, length uint64) ([]byte, bool) {
+	for _, region := range gm {
+		if b, ok := translate(region.data, region.userspaceAddress, address, length); ok {
+			return b, true
+		}
+	}
+	return nil, false
+}
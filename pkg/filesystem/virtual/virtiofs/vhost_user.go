@@ -0,0 +1,473 @@
+//go:build linux
+// +build linux
+
+package virtiofs
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"golang.org/x/sys/unix"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Requests of the vhost-user protocol, as described in QEMU's
+// docs/interop/vhost-user.rst, that are supported by this
+// implementation.
+const (
+	vhostUserGetFeatures         = 1
+	vhostUserSetFeatures         = 2
+	vhostUserSetOwner            = 3
+	vhostUserResetOwner          = 4
+	vhostUserSetMemTable         = 5
+	vhostUserSetVringNum         = 8
+	vhostUserSetVringAddr        = 9
+	vhostUserSetVringBase        = 10
+	vhostUserGetVringBase        = 11
+	vhostUserSetVringKick        = 12
+	vhostUserSetVringCall        = 13
+	vhostUserSetVringErr         = 14
+	vhostUserGetProtocolFeatures = 15
+	vhostUserSetProtocolFeatures = 16
+	vhostUserGetQueueNum         = 17
+	vhostUserSetVringEnable      = 18
+	vhostUserSetBackendReqFD     = 21
+)
+
+// Requests that are sent by the backend to the virtual machine monitor
+// to manage the DAX window of a virtio-fs device. These are part of the
+// protocol extension that is implemented by virtiofsd and
+// cloud-hypervisor.
+const (
+	vhostUserBackendFSMap   = 6
+	vhostUserBackendFSUnmap = 7
+
+	vhostUserFSBackendEntries = 8
+	vhostUserFSFlagMapRead    = 0x1
+	vhostUserFSFlagMapWrite   = 0x2
+)
+
+const (
+	vhostUserHeaderSizeBytes       = 12
+	vhostUserMaximumPayloadBytes   = 4096
+	vhostUserFlagVersion           = 0x1
+	vhostUserFlagVersionMask       = 0x3
+	vhostUserFlagReply             = 0x4
+	vhostUserFlagNeedReply         = 0x8
+	vhostUserVringIndexMask        = 0xff
+	vhostUserVringInvalidFD        = 0x100
+	vhostUserMemoryRegionSizeBytes = 32
+
+	virtioFeatureVersion1            = 1 << 32
+	vhostUserFeatureProtocolFeatures = 1 << 30
+
+	vhostUserProtocolFeatureMultipleQueues = 1 << 0
+	vhostUserProtocolFeatureReplyAck       = 1 << 3
+	vhostUserProtocolFeatureBackendReq     = 1 << 5
+	vhostUserProtocolFeatureBackendSendFD  = 1 << 10
+
+	// The number of queues offered to the virtual machine monitor,
+	// being one high priority queue and 15 request queues.
+	maximumQueues = 16
+)
+
+const (
+	supportedFeatures         = virtioFeatureVersion1 | vhostUserFeatureProtocolFeatures
+	supportedProtocolFeatures = vhostUserProtocolFeatureMultipleQueues | vhostUserProtocolFeatureReplyAck | vhostUserProtocolFeatureBackendReq | vhostUserProtocolFeatureBackendSendFD
+)
+
+// connection with a virtual machine monitor over which vhost-user
+// messages are exchanged.
+type connection struct {
+	conn   *net.UnixConn
+	device *device
+
+	features         uint64
+	protocolFeatures uint64
+}
+
+// Serve a virtio-fs device to a virtual machine monitor (e.g.,
+// cloud-hypervisor or QEMU with vhost-user-fs-pci), using the vhost-user
+// protocol. FUSE requests that are placed on the virtqueues of the
+// device by the guest are forwarded to a FUSE server over the provided
+// socket, which must preserve message boundaries (i.e., a socket of
+// type SOCK_SEQPACKET). This makes it possible to reuse the FUSE server
+// that is used to create local mounts.
+//
+// Guests may mount the file system with DAX enabled, provided that the
+// virtual machine monitor offers a DAX window and supports the
+// VHOST_USER_BACKEND_FS_MAP and VHOST_USER_BACKEND_FS_UNMAP requests.
+// As files in the virtual file system are generally not backed by host
+// file descriptors, ranges of files that the guest maps into the DAX
+// window are copied into shared memory. Changes are written back to
+// the FUSE server when the file is flushed, synchronized or closed, or
+// when the range is unmapped.
+//
+// This function returns when the connection with the virtual machine
+// monitor is closed. It does not close the connection to the FUSE
+// server.
+func Serve(ctx context.Context, conn *net.UnixConn, fuseDevice *net.UnixConn) error {
+	d := newDevice(fuseDevice)
+	defer d.close()
+	defer d.closeDAXWindow()
+	go d.processResponses()
+
+	// Terminate the connection upon shutdown.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	c := &connection{
+		conn:   conn,
+		device: d,
+	}
+	for {
+		request, flags, payload, fds, err := c.readMessage()
+		if err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if flags&vhostUserFlagVersionMask != vhostUserFlagVersion {
+			closeFDs(fds)
+			return status.Errorf(codes.InvalidArgument, "Unsupported vhost-user protocol version %d", flags&vhostUserFlagVersionMask)
+		}
+
+		reply, hasReply, err := c.handleMessage(request, payload, fds)
+		if !hasReply && flags&vhostUserFlagNeedReply != 0 && c.protocolFeatures&vhostUserProtocolFeatureReplyAck != 0 {
+			// Explicitly acknowledge the request.
+			var ack uint64
+			if err != nil {
+				ack = 1
+			}
+			reply, hasReply = binary.LittleEndian.AppendUint64(nil, ack), true
+		}
+		if hasReply {
+			if writeErr := c.writeReply(request, reply); writeErr != nil {
+				return writeErr
+			}
+		}
+		if err != nil {
+			return util.StatusWrapf(err, "Failed to process vhost-user request %d", request)
+		}
+	}
+}
+
+// readMessage reads a single vhost-user message, including any file
+// descriptors that are attached to it.
+func (c *connection) readMessage() (uint32, uint32, []byte, []int, error) {
+	var header [vhostUserHeaderSizeBytes]byte
+	oob := make([]byte, unix.CmsgSpace(maximumMemoryRegions*4))
+	n, oobn, _, _, err := c.conn.ReadMsgUnix(header[:], oob)
+	if err != nil {
+		return 0, 0, nil, nil, err
+	}
+	if n == 0 {
+		return 0, 0, nil, nil, io.EOF
+	}
+
+	var fds []int
+	if oobn > 0 {
+		messages, err := unix.ParseSocketControlMessage(oob[:oobn])
+		if err != nil {
+			return 0, 0, nil, nil, util.StatusWrapWithCode(err, codes.InvalidArgument, "Failed to parse control messages")
+		}
+		for i := range messages {
+			messageFDs, err := unix.ParseUnixRights(&messages[i])
+			if err == nil {
+				fds = append(fds, messageFDs...)
+			}
+		}
+	}
+
+	if _, err := io.ReadFull(c.conn, header[n:]); err != nil {
+		closeFDs(fds)
+		return 0, 0, nil, nil, err
+	}
+	request := binary.LittleEndian.Uint32(header[0:])
+	flags := binary.LittleEndian.Uint32(header[4:])
+	size := binary.LittleEndian.Uint32(header[8:])
+	if size > vhostUserMaximumPayloadBytes {
+		closeFDs(fds)
+		return 0, 0, nil, nil, status.Errorf(codes.InvalidArgument, "Payload of vhost-user request %d is %d bytes in size, which exceeds the maximum of %d bytes", request, size, vhostUserMaximumPayloadBytes)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(c.conn, payload); err != nil {
+		closeFDs(fds)
+		return 0, 0, nil, nil, err
+	}
+	return request, flags, payload, fds, nil
+}
+
+func (c *connection) writeReply(request uint32, payload []byte) error {
+	message := make([]byte, vhostUserHeaderSizeBytes, vhostUserHeaderSizeBytes+len(payload))
+	binary.LittleEndian.PutUint32(message[0:], request)
+	binary.LittleEndian.PutUint32(message[4:], vhostUserFlagVersion|vhostUserFlagReply)
+	binary.LittleEndian.PutUint32(message[8:], uint32(len(payload)))
+	_, err := c.conn.Write(append(message, payload...))
+	return err
+}
+
+func closeFDs(fds []int) {
+	for _, fd := range fds {
+		unix.Close(fd)
+	}
+}
+
+// handleMessage processes a single vhost-user request. It returns the
+// payload of the reply, if the request has one.
+func (c *connection) handleMessage(request uint32, payload []byte, fds []int) ([]byte, bool, error) {
+	// Only requests that set up guest memory, queue notifications
+	// and the backend channel carry file descriptors.
+	switch request {
+	case vhostUserSetMemTable, vhostUserSetVringKick, vhostUserSetVringCall, vhostUserSetVringErr, vhostUserSetBackendReqFD:
+	default:
+		if len(fds) > 0 {
+			closeFDs(fds)
+			return nil, false, status.Error(codes.InvalidArgument, "Request does not permit file descriptors to be attached")
+		}
+	}
+
+	d := c.device
+	switch request {
+	case vhostUserGetFeatures:
+		return binary.LittleEndian.AppendUint64(nil, supportedFeatures), true, nil
+	case vhostUserSetFeatures:
+		features, err := decodeUint64(payload)
+		if err != nil {
+			return nil, false, err
+		}
+		if features&^supportedFeatures != 0 {
+			return nil, false, status.Errorf(codes.InvalidArgument, "Unsupported features 0x%x", features&^supportedFeatures)
+		}
+		c.features = features
+		return nil, false, nil
+	case vhostUserGetProtocolFeatures:
+		return binary.LittleEndian.AppendUint64(nil, supportedProtocolFeatures), true, nil
+	case vhostUserSetProtocolFeatures:
+		protocolFeatures, err := decodeUint64(payload)
+		if err != nil {
+			return nil, false, err
+		}
+		c.protocolFeatures = protocolFeatures & supportedProtocolFeatures
+		return nil, false, nil
+	case vhostUserGetQueueNum:
+		return binary.LittleEndian.AppendUint64(nil, maximumQueues), true, nil
+	case vhostUserSetOwner, vhostUserResetOwner:
+		return nil, false, nil
+	case vhostUserSetMemTable:
+		descriptions, err := decodeMemoryRegionDescriptions(payload)
+		if err != nil {
+			closeFDs(fds)
+			return nil, false, err
+		}
+		memory, err := newGuestMemory(descriptions, fds)
+		if err != nil {
+			return nil, false, err
+		}
+		d.lock.Lock()
+		d.setMemoryLocked(memory)
+		d.lock.Unlock()
+		return nil, false, nil
+	case vhostUserSetVringNum:
+		q, num, err := c.decodeVringState(payload)
+		if err != nil {
+			return nil, false, err
+		}
+		if num == 0 || num > maximumVirtqueueSize {
+			return nil, false, status.Errorf(codes.InvalidArgument, "Invalid virtqueue size %d", num)
+		}
+		d.lock.Lock()
+		q.size = uint16(num)
+		d.lock.Unlock()
+		return nil, false, nil
+	case vhostUserSetVringAddr:
+		if len(payload) != 40 {
+			return nil, false, status.Error(codes.InvalidArgument, "Invalid virtqueue address message size")
+		}
+		q, err := c.getQueue(binary.LittleEndian.Uint32(payload[0:]))
+		if err != nil {
+			return nil, false, err
+		}
+		d.lock.Lock()
+		q.descriptorsAddress = binary.LittleEndian.Uint64(payload[8:])
+		q.usedAddress = binary.LittleEndian.Uint64(payload[16:])
+		q.availableAddress = binary.LittleEndian.Uint64(payload[24:])
+		d.lock.Unlock()
+		return nil, false, nil
+	case vhostUserSetVringBase:
+		q, num, err := c.decodeVringState(payload)
+		if err != nil {
+			return nil, false, err
+		}
+		d.lock.Lock()
+		q.base = uint16(num)
+		d.lock.Unlock()
+		return nil, false, nil
+	case vhostUserGetVringBase:
+		q, _, err := c.decodeVringState(payload)
+		if err != nil {
+			return nil, false, err
+		}
+		d.lock.Lock()
+		base := d.stopQueueLocked(q)
+		d.lock.Unlock()
+
+		var reply [8]byte
+		binary.LittleEndian.PutUint32(reply[0:], binary.LittleEndian.Uint32(payload[0:]))
+		binary.LittleEndian.PutUint32(reply[4:], uint32(base))
+		return reply[:], true, nil
+	case vhostUserSetVringKick, vhostUserSetVringCall, vhostUserSetVringErr:
+		q, fd, err := c.decodeVringFD(payload, fds)
+		if err != nil {
+			return nil, false, err
+		}
+		d.lock.Lock()
+		defer d.lock.Unlock()
+		switch request {
+		case vhostUserSetVringKick:
+			kick, err := newKickFile(fd)
+			if err != nil {
+				return nil, false, util.StatusWrapWithCode(err, codes.Internal, "Failed to make kick file descriptor non-blocking")
+			}
+			// Without VHOST_USER_F_PROTOCOL_FEATURES, queues
+			// are enabled as soon as they are started.
+			if c.features&vhostUserFeatureProtocolFeatures == 0 {
+				q.enabled = true
+			}
+			return nil, false, d.startQueueLocked(q, kick)
+		case vhostUserSetVringCall:
+			if q.call != nil {
+				q.call.Close()
+			}
+			q.call = os.NewFile(uintptr(fd), "vring-call")
+		default:
+			// Errors are never reported.
+			unix.Close(fd)
+		}
+		return nil, false, nil
+	case vhostUserSetVringEnable:
+		q, num, err := c.decodeVringState(payload)
+		if err != nil {
+			return nil, false, err
+		}
+		d.lock.Lock()
+		q.enabled = num != 0
+		running, generation := q.virtqueue != nil, q.generation
+		d.lock.Unlock()
+
+		// Process any requests that were queued while the
+		// queue was disabled.
+		if running && num != 0 {
+			go d.processQueue(q, generation)
+		}
+		return nil, false, nil
+	case vhostUserSetBackendReqFD:
+		if len(fds) != 1 {
+			closeFDs(fds)
+			return nil, false, status.Error(codes.InvalidArgument, "Expected exactly one file descriptor to be provided")
+		}
+		f := os.NewFile(uintptr(fds[0]), "vhost-user-backend")
+		conn, err := net.FileConn(f)
+		f.Close()
+		if err != nil {
+			return nil, false, util.StatusWrapWithCode(err, codes.InvalidArgument, "Failed to create connection from backend channel file descriptor")
+		}
+		unixConn, ok := conn.(*net.UnixConn)
+		if !ok {
+			conn.Close()
+			return nil, false, status.Error(codes.InvalidArgument, "Backend channel file descriptor does not refer to a UNIX socket")
+		}
+		d.setBackendChannel(&backendChannel{
+			conn:     unixConn,
+			replyAck: c.protocolFeatures&vhostUserProtocolFeatureReplyAck != 0,
+		})
+		return nil, false, nil
+	default:
+		closeFDs(fds)
+		return nil, false, status.Errorf(codes.Unimplemented, "Unsupported vhost-user request %d", request)
+	}
+}
+
+func decodeUint64(payload []byte) (uint64, error) {
+	if len(payload) != 8 {
+		return 0, status.Error(codes.InvalidArgument, "Invalid 64-bit integer message size")
+	}
+	return binary.LittleEndian.Uint64(payload), nil
+}
+
+func (c *connection) getQueue(index uint32) (*queue, error) {
+	if index >= maximumQueues {
+		return nil, status.Errorf(codes.InvalidArgument, "Queue index %d exceeds the maximum of %d queues", index, maximumQueues)
+	}
+	return &c.device.queues[index], nil
+}
+
+// decodeVringState decodes a struct vhost_vring_state, returning the
+// queue to which it applies and its value.
+func (c *connection) decodeVringState(payload []byte) (*queue, uint32, error) {
+	if len(payload) != 8 {
+		return nil, 0, status.Error(codes.InvalidArgument, "Invalid virtqueue state message size")
+	}
+	q, err := c.getQueue(binary.LittleEndian.Uint32(payload[0:]))
+	if err != nil {
+		return nil, 0, err
+	}
+	return q, binary.LittleEndian.Uint32(payload[4:]), nil
+}
+
+// decodeVringFD decodes the payload of requests that provide a file
+// descriptor for a queue. Polling mode, where no file descriptor is
+// provided, is not supported.
+func (c *connection) decodeVringFD(payload []byte, fds []int) (*queue, int, error) {
+	value, err := decodeUint64(payload)
+	if err != nil {
+		closeFDs(fds)
+		return nil, -1, err
+	}
+	if value&vhostUserVringInvalidFD != 0 || len(fds) != 1 {
+		closeFDs(fds)
+		return nil, -1, status.Error(codes.InvalidArgument, "Expected exactly one file descriptor to be provided")
+	}
+	q, err := c.getQueue(uint32(value & vhostUserVringIndexMask))
+	if err != nil {
+		closeFDs(fds)
+		return nil, -1, err
+	}
+	return q, fds[0], nil
+}
+
+func decodeMemoryRegionDescriptions(payload []byte) ([]memoryRegionDescription, error) {
+	if len(payload) < 8 {
+		return nil, status.Error(codes.InvalidArgument, "Invalid memory table message size")
+	}
+	count := binary.LittleEndian.Uint32(payload[0:])
+	if count > maximumMemoryRegions || len(payload) < 8+int(count)*vhostUserMemoryRegionSizeBytes {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid number of memory regions %d", count)
+	}
+	descriptions := make([]memoryRegionDescription, 0, count)
+	for i := 0; i < int(count); i++ {
+		region := payload[8+i*vhostUserMemoryRegionSizeBytes:]
+		descriptions = append(descriptions, memoryRegionDescription{
+			guestPhysicalAddress: binary.LittleEndian.Uint64(region[0:]),
+			memorySize:           binary.LittleEndian.Uint64(region[8:]),
+			userspaceAddress:     binary.LittleEndian.Uint64(region[16:]),
+			mmapOffset:           binary.LittleEndian.Uint64(region[24:]),
+		})
+	}
+	return descriptions, nil
+}
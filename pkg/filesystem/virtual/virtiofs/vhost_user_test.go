@@ -0,0 +1,362 @@
+//go:build linux
+// +build linux
+
+package virtiofs_test
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/filesystem/virtual/virtiofs"
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	guestMemorySize  = 0x10000
+	userspaceAddress = 0x7f0000000000
+
+	descriptorsOffset = 0x1000
+	availableOffset   = 0x2000
+	usedOffset        = 0x3000
+	requestOffset     = 0x4000
+	responseOffset    = 0x5000
+)
+
+func newSocketPair(t *testing.T, socketType int) (*net.UnixConn, *net.UnixConn) {
+	fds, err := unix.Socketpair(unix.AF_UNIX, socketType|unix.SOCK_CLOEXEC, 0)
+	require.NoError(t, err)
+	var conns [2]*net.UnixConn
+	for i, fd := range fds {
+		f := os.NewFile(uintptr(fd), "socket")
+		conn, err := net.FileConn(f)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		conns[i] = conn.(*net.UnixConn)
+	}
+	return conns[0], conns[1]
+}
+
+func sendVhostUserMessage(t *testing.T, conn *net.UnixConn, request uint32, payload []byte, fds ...int) {
+	message := make([]byte, 12, 12+len(payload))
+	binary.LittleEndian.PutUint32(message[0:], request)
+	binary.LittleEndian.PutUint32(message[4:], 0x1)
+	binary.LittleEndian.PutUint32(message[8:], uint32(len(payload)))
+	var oob []byte
+	if len(fds) > 0 {
+		oob = unix.UnixRights(fds...)
+	}
+	_, _, err := conn.WriteMsgUnix(append(message, payload...), oob, nil)
+	require.NoError(t, err)
+}
+
+func receiveVhostUserReply(t *testing.T, conn *net.UnixConn, request uint32) []byte {
+	var header [12]byte
+	_, err := conn.Read(header[:])
+	require.NoError(t, err)
+	require.Equal(t, request, binary.LittleEndian.Uint32(header[0:]))
+	require.Equal(t, uint32(0x5), binary.LittleEndian.Uint32(header[4:]))
+	payload := make([]byte, binary.LittleEndian.Uint32(header[8:]))
+	_, err = conn.Read(payload)
+	require.NoError(t, err)
+	return payload
+}
+
+func vringState(index, num uint32) []byte {
+	var payload [8]byte
+	binary.LittleEndian.PutUint32(payload[0:], index)
+	binary.LittleEndian.PutUint32(payload[4:], num)
+	return payload[:]
+}
+
+func TestServe(t *testing.T) {
+	ctx := context.Background()
+
+	vmmConn, backendConn := newSocketPair(t, unix.SOCK_STREAM)
+	defer vmmConn.Close()
+	fuseServerConn, fuseDeviceConn := newSocketPair(t, unix.SOCK_SEQPACKET)
+	defer fuseServerConn.Close()
+	defer fuseDeviceConn.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- virtiofs.Serve(ctx, backendConn, fuseDeviceConn)
+		backendConn.Close()
+	}()
+
+	// Create guest memory that is shared with the backend.
+	memoryFD, err := unix.MemfdCreate("guest", unix.MFD_CLOEXEC)
+	require.NoError(t, err)
+	require.NoError(t, unix.Ftruncate(memoryFD, guestMemorySize))
+	memory, err := unix.Mmap(memoryFD, 0, guestMemorySize, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	require.NoError(t, err)
+	defer unix.Munmap(memory)
+
+	kickFD, err := unix.Eventfd(0, unix.EFD_CLOEXEC)
+	require.NoError(t, err)
+	defer unix.Close(kickFD)
+	callFD, err := unix.Eventfd(0, unix.EFD_CLOEXEC)
+	require.NoError(t, err)
+	defer unix.Close(callFD)
+
+	// Negotiate features and set up queue 1.
+	sendVhostUserMessage(t, vmmConn, 1, nil)
+	features := binary.LittleEndian.Uint64(receiveVhostUserReply(t, vmmConn, 1))
+	require.NotZero(t, features&(1<<32))
+	sendVhostUserMessage(t, vmmConn, 2, binary.LittleEndian.AppendUint64(nil, 1<<32))
+	sendVhostUserMessage(t, vmmConn, 3, nil)
+
+	// Provide a backend channel, over which the backend requests
+	// that files are mapped into the DAX window.
+	vmmBackendConn, backendBackendConn := newSocketPair(t, unix.SOCK_STREAM)
+	defer vmmBackendConn.Close()
+	backendBackendFile, err := backendBackendConn.File()
+	require.NoError(t, err)
+	require.NoError(t, backendBackendConn.Close())
+	sendVhostUserMessage(t, vmmConn, 21, nil, int(backendBackendFile.Fd()))
+	require.NoError(t, backendBackendFile.Close())
+
+	var memoryTable [40]byte
+	binary.LittleEndian.PutUint32(memoryTable[0:], 1)
+	binary.LittleEndian.PutUint64(memoryTable[8:], 0)
+	binary.LittleEndian.PutUint64(memoryTable[16:], guestMemorySize)
+	binary.LittleEndian.PutUint64(memoryTable[24:], userspaceAddress)
+	binary.LittleEndian.PutUint64(memoryTable[32:], 0)
+	sendVhostUserMessage(t, vmmConn, 5, memoryTable[:], memoryFD)
+
+	sendVhostUserMessage(t, vmmConn, 8, vringState(1, 8))
+	var vringAddress [40]byte
+	binary.LittleEndian.PutUint32(vringAddress[0:], 1)
+	binary.LittleEndian.PutUint64(vringAddress[8:], userspaceAddress+descriptorsOffset)
+	binary.LittleEndian.PutUint64(vringAddress[16:], userspaceAddress+usedOffset)
+	binary.LittleEndian.PutUint64(vringAddress[24:], userspaceAddress+availableOffset)
+	sendVhostUserMessage(t, vmmConn, 9, vringAddress[:])
+	sendVhostUserMessage(t, vmmConn, 10, vringState(1, 0))
+	sendVhostUserMessage(t, vmmConn, 13, binary.LittleEndian.AppendUint64(nil, 1), callFD)
+	sendVhostUserMessage(t, vmmConn, 12, binary.LittleEndian.AppendUint64(nil, 1), kickFD)
+
+	kick := func() {
+		_, err := unix.Write(kickFD, binary.LittleEndian.AppendUint64(nil, 1))
+		require.NoError(t, err)
+	}
+	waitForCall := func() {
+		var counter [8]byte
+		_, err := unix.Read(callFD, counter[:])
+		require.NoError(t, err)
+	}
+	putDescriptor := func(index uint16, offset uint64, length uint32, flags, next uint16) {
+		descriptor := memory[descriptorsOffset+16*int(index):]
+		binary.LittleEndian.PutUint64(descriptor[0:], offset)
+		binary.LittleEndian.PutUint32(descriptor[8:], length)
+		binary.LittleEndian.PutUint16(descriptor[12:], flags)
+		binary.LittleEndian.PutUint16(descriptor[14:], next)
+	}
+	makeAvailable := func(availableIndex, head uint16) {
+		binary.LittleEndian.PutUint16(memory[availableOffset+4+2*int(availableIndex%8):], head)
+		binary.LittleEndian.PutUint16(memory[availableOffset+2:], availableIndex+1)
+	}
+
+	t.Run("RequestWithResponse", func(t *testing.T) {
+		// Place a LOOKUP request on the queue, consisting of a
+		// readable and a writable descriptor.
+		request := memory[requestOffset : requestOffset+45]
+		binary.LittleEndian.PutUint32(request[0:], 45)
+		binary.LittleEndian.PutUint32(request[4:], 1)
+		binary.LittleEndian.PutUint64(request[8:], 42)
+		copy(request[40:], "hello")
+		putDescriptor(0, requestOffset, 45, 1, 1)
+		putDescriptor(1, responseOffset, 64, 2, 0)
+		makeAvailable(0, 0)
+		kick()
+
+		// The request should be forwarded to the FUSE server.
+		buffer := make([]byte, 4096)
+		n, err := fuseServerConn.Read(buffer)
+		require.NoError(t, err)
+		require.Equal(t, request, buffer[:n])
+
+		// The response of the FUSE server should be written
+		// into the writable descriptor.
+		var response [20]byte
+		binary.LittleEndian.PutUint32(response[0:], 20)
+		binary.LittleEndian.PutUint64(response[8:], 42)
+		copy(response[16:], "abcd")
+		_, err = fuseServerConn.Write(response[:])
+		require.NoError(t, err)
+
+		waitForCall()
+		require.Equal(t, uint16(1), binary.LittleEndian.Uint16(memory[usedOffset+2:]))
+		require.Equal(t, uint32(0), binary.LittleEndian.Uint32(memory[usedOffset+4:]))
+		require.Equal(t, uint32(20), binary.LittleEndian.Uint32(memory[usedOffset+8:]))
+		require.Equal(t, response[:], memory[responseOffset:responseOffset+20])
+	})
+
+	t.Run("RequestWithoutResponse", func(t *testing.T) {
+		// FORGET requests don't receive a response from the
+		// FUSE server. They should be completed immediately.
+		request := memory[requestOffset : requestOffset+48]
+		binary.LittleEndian.PutUint32(request[0:], 48)
+		binary.LittleEndian.PutUint32(request[4:], 2)
+		binary.LittleEndian.PutUint64(request[8:], 43)
+		putDescriptor(2, requestOffset, 48, 0, 0)
+		makeAvailable(1, 2)
+		kick()
+
+		buffer := make([]byte, 4096)
+		n, err := fuseServerConn.Read(buffer)
+		require.NoError(t, err)
+		require.Equal(t, request, buffer[:n])
+
+		waitForCall()
+		require.Equal(t, uint16(2), binary.LittleEndian.Uint16(memory[usedOffset+2:]))
+		require.Equal(t, uint32(2), binary.LittleEndian.Uint32(memory[usedOffset+12:]))
+		require.Equal(t, uint32(0), binary.LittleEndian.Uint32(memory[usedOffset+16:]))
+	})
+
+	receiveFUSERequest := func(opcode uint32, nodeID uint64) []byte {
+		buffer := make([]byte, 4096)
+		n, err := fuseServerConn.Read(buffer)
+		require.NoError(t, err)
+		require.Equal(t, uint32(n), binary.LittleEndian.Uint32(buffer[0:]))
+		require.Equal(t, opcode, binary.LittleEndian.Uint32(buffer[4:]))
+		require.Equal(t, nodeID, binary.LittleEndian.Uint64(buffer[16:]))
+		return buffer[:n]
+	}
+	sendFUSEResponse := func(request, payload []byte) {
+		response := make([]byte, 16, 16+len(payload))
+		binary.LittleEndian.PutUint32(response[0:], uint32(16+len(payload)))
+		copy(response[8:], request[8:16])
+		_, err := fuseServerConn.Write(append(response, payload...))
+		require.NoError(t, err)
+	}
+	receiveBackendRequest := func(request uint32) ([]byte, []int) {
+		message := make([]byte, 12+256)
+		oob := make([]byte, unix.CmsgSpace(4))
+		n, oobn, _, _, err := vmmBackendConn.ReadMsgUnix(message, oob)
+		require.NoError(t, err)
+		require.Equal(t, len(message), n)
+		require.Equal(t, request, binary.LittleEndian.Uint32(message[0:]))
+		require.Equal(t, uint32(256), binary.LittleEndian.Uint32(message[8:]))
+		var fds []int
+		if oobn > 0 {
+			controlMessages, err := unix.ParseSocketControlMessage(oob[:oobn])
+			require.NoError(t, err)
+			require.Len(t, controlMessages, 1)
+			fds, err = unix.ParseUnixRights(&controlMessages[0])
+			require.NoError(t, err)
+		}
+		return message[12:], fds
+	}
+	var daxMemory []byte
+
+	t.Run("SetupMapping", func(t *testing.T) {
+		// Place a writable FUSE_SETUPMAPPING request for the
+		// first 4 KiB of a file on the queue.
+		request := memory[requestOffset : requestOffset+80]
+		binary.LittleEndian.PutUint32(request[0:], 80)
+		binary.LittleEndian.PutUint32(request[4:], 48)
+		binary.LittleEndian.PutUint64(request[8:], 44)
+		binary.LittleEndian.PutUint64(request[16:], 5)
+		binary.LittleEndian.PutUint64(request[40:], 7)
+		binary.LittleEndian.PutUint64(request[48:], 0)
+		binary.LittleEndian.PutUint64(request[56:], 4096)
+		binary.LittleEndian.PutUint64(request[64:], 3)
+		binary.LittleEndian.PutUint64(request[72:], 0x200000)
+		putDescriptor(3, requestOffset, 80, 1, 4)
+		putDescriptor(4, responseOffset, 64, 2, 0)
+		makeAvailable(2, 3)
+		kick()
+
+		// The contents of the file should be read from the
+		// FUSE server.
+		readRequest := receiveFUSERequest(15, 5)
+		require.Equal(t, uint64(7), binary.LittleEndian.Uint64(readRequest[40:]))
+		require.Equal(t, uint64(0), binary.LittleEndian.Uint64(readRequest[48:]))
+		require.Equal(t, uint32(4096), binary.LittleEndian.Uint32(readRequest[56:]))
+		sendFUSEResponse(readRequest, []byte("Hello"))
+
+		// The virtual machine monitor should be requested to
+		// map a file containing the same data into the DAX
+		// window.
+		payload, fds := receiveBackendRequest(6)
+		require.Len(t, fds, 1)
+		defer unix.Close(fds[0])
+		require.Equal(t, uint64(0), binary.LittleEndian.Uint64(payload[0:]))
+		require.Equal(t, uint64(0x200000), binary.LittleEndian.Uint64(payload[64:]))
+		require.Equal(t, uint64(4096), binary.LittleEndian.Uint64(payload[128:]))
+		require.Equal(t, uint64(3), binary.LittleEndian.Uint64(payload[192:]))
+		daxMemory, err = unix.Mmap(fds[0], 0, 4096, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+		require.NoError(t, err)
+		require.Equal(t, append([]byte("Hello"), make([]byte, 4091)...), daxMemory)
+
+		waitForCall()
+		require.Equal(t, uint16(3), binary.LittleEndian.Uint16(memory[usedOffset+2:]))
+		require.Equal(t, uint32(3), binary.LittleEndian.Uint32(memory[usedOffset+20:]))
+		require.Equal(t, uint32(16), binary.LittleEndian.Uint32(memory[usedOffset+24:]))
+		require.Equal(t, uint32(0), binary.LittleEndian.Uint32(memory[responseOffset+4:]))
+		require.Equal(t, uint64(44), binary.LittleEndian.Uint64(memory[responseOffset+8:]))
+	})
+
+	t.Run("RemoveMapping", func(t *testing.T) {
+		defer unix.Munmap(daxMemory)
+
+		// Let the guest modify the file through the DAX
+		// window, followed by removing the mapping.
+		copy(daxMemory, "World")
+		request := memory[requestOffset : requestOffset+60]
+		binary.LittleEndian.PutUint32(request[0:], 60)
+		binary.LittleEndian.PutUint32(request[4:], 49)
+		binary.LittleEndian.PutUint64(request[8:], 46)
+		binary.LittleEndian.PutUint64(request[16:], 5)
+		binary.LittleEndian.PutUint32(request[40:], 1)
+		binary.LittleEndian.PutUint64(request[44:], 0x200000)
+		binary.LittleEndian.PutUint64(request[52:], 4096)
+		putDescriptor(5, requestOffset, 60, 1, 6)
+		putDescriptor(6, responseOffset, 64, 2, 0)
+		makeAvailable(3, 5)
+		kick()
+
+		// The changes should be written back to the FUSE
+		// server, truncated to the size of the file.
+		getattrRequest := receiveFUSERequest(3, 5)
+		var attrOut [104]byte
+		binary.LittleEndian.PutUint64(attrOut[24:], 5)
+		sendFUSEResponse(getattrRequest, attrOut[:])
+
+		writeRequest := receiveFUSERequest(16, 5)
+		require.Equal(t, uint64(7), binary.LittleEndian.Uint64(writeRequest[40:]))
+		require.Equal(t, uint64(0), binary.LittleEndian.Uint64(writeRequest[48:]))
+		require.Equal(t, uint32(5), binary.LittleEndian.Uint32(writeRequest[56:]))
+		require.Equal(t, []byte("World"), writeRequest[80:])
+		sendFUSEResponse(writeRequest, binary.LittleEndian.AppendUint64(nil, 5))
+
+		// The range should be removed from the DAX window.
+		payload, fds := receiveBackendRequest(7)
+		require.Empty(t, fds)
+		require.Equal(t, uint64(0x200000), binary.LittleEndian.Uint64(payload[64:]))
+		require.Equal(t, uint64(4096), binary.LittleEndian.Uint64(payload[128:]))
+
+		waitForCall()
+		require.Equal(t, uint16(4), binary.LittleEndian.Uint16(memory[usedOffset+2:]))
+		require.Equal(t, uint32(5), binary.LittleEndian.Uint32(memory[usedOffset+28:]))
+		require.Equal(t, uint32(16), binary.LittleEndian.Uint32(memory[usedOffset+32:]))
+		require.Equal(t, uint32(0), binary.LittleEndian.Uint32(memory[responseOffset+4:]))
+		require.Equal(t, uint64(46), binary.LittleEndian.Uint64(memory[responseOffset+8:]))
+	})
+
+	t.Run("GetVringBase", func(t *testing.T) {
+		// Stopping the queue should return the index of the
+		// next descriptor chain to process.
+		sendVhostUserMessage(t, vmmConn, 11, vringState(1, 0))
+		require.Equal(t, vringState(1, 4), receiveVhostUserReply(t, vmmConn, 11))
+	})
+
+	t.Run("Disconnect", func(t *testing.T) {
+		require.NoError(t, vmmConn.CloseWrite())
+		require.NoError(t, <-errCh)
+	})
+}
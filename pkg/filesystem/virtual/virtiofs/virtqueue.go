@@ -0,0 +1,161 @@
+//go:build linux
+// +build linux
+
+package virtiofs
+
+import (
+	"encoding/binary"
+	"sync/atomic"
+	"unsafe"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Constants pertaining to the layout of split virtqueues, as described
+// in section 2.7 of the Virtual I/O Device (VIRTIO) specification,
+// version 1.2.
+const (
+	descriptorSizeBytes  = 16
+	usedElementSizeBytes = 8
+
+	descriptorFlagNext     = 1
+	descriptorFlagWrite    = 2
+	descriptorFlagIndirect = 4
+
+	availableFlagNoInterrupt = 1
+
+	maximumVirtqueueSize = 32768
+)
+
+// loadUint16Acquire reads a 16-bit integer that is shared with the
+// guest. Subsequent reads of guest memory are not reordered before it.
+// As sync/atomic does not provide 16-bit operations, the aligned 32-bit
+// word containing the integer is loaded instead.
+func loadUint16Acquire(b []byte) uint16 {
+	p := unsafe.Pointer(&b[0])
+	misalignment := uintptr(p) & 3
+	word := atomic.LoadUint32((*uint32)(unsafe.Add(p, -int(misalignment))))
+	return uint16(word >> (misalignment * 8))
+}
+
+// descriptorChain of a request that has been taken from the available
+// ring of a virtqueue. The readable buffers contain the request that is
+// sent by the guest, while the writable buffers are used to store the
+// response.
+type descriptorChain struct {
+	head     uint16
+	readable [][]byte
+	writable [][]byte
+}
+
+// virtqueue contains the state of a single split virtqueue that is
+// shared with the guest.
+type virtqueue struct {
+	size        uint16
+	descriptors []byte
+	available   []byte
+	used        []byte
+
+	lastAvailableIndex uint16
+	usedIndex          uint16
+}
+
+// newVirtqueue creates a virtqueue, given the locations of its
+// descriptor table and rings.
+func newVirtqueue(memory guestMemory, size uint16, descriptorsAddress, availableAddress, usedAddress uint64, base uint16) (*virtqueue, error) {
+	if size == 0 || size&(size-1) != 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Virtqueue size %d is not a power of two", size)
+	}
+	if usedAddress%4 != 0 {
+		return nil, status.Error(codes.InvalidArgument, "Used ring is not aligned to four bytes")
+	}
+	descriptors, ok := memory.translateUserspace(descriptorsAddress, uint64(size)*descriptorSizeBytes)
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "Descriptor table is not contained in guest memory")
+	}
+	available, ok := memory.translateUserspace(availableAddress, 4+2*uint64(size))
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "Available ring is not contained in guest memory")
+	}
+	used, ok := memory.translateUserspace(usedAddress, 4+usedElementSizeBytes*uint64(size))
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "Used ring is not contained in guest memory")
+	}
+	return &virtqueue{
+		size:               size,
+		descriptors:        descriptors,
+		available:          available,
+		used:               used,
+		lastAvailableIndex: base,
+		usedIndex:          base,
+	}, nil
+}
+
+// pop the next descriptor chain from the available ring, if any.
+func (vq *virtqueue) pop(memory guestMemory) (descriptorChain, bool, error) {
+	if loadUint16Acquire(vq.available[2:]) == vq.lastAvailableIndex {
+		return descriptorChain{}, false, nil
+	}
+	ringOffset := 4 + 2*uint32(vq.lastAvailableIndex%vq.size)
+	head := binary.LittleEndian.Uint16(vq.available[ringOffset:])
+	vq.lastAvailableIndex++
+
+	chain := descriptorChain{head: head}
+	index := head
+	for length := uint16(0); ; length++ {
+		if index >= vq.size {
+			return descriptorChain{}, false, status.Errorf(codes.InvalidArgument, "Descriptor index %d exceeds virtqueue size %d", index, vq.size)
+		}
+		if length >= vq.size {
+			return descriptorChain{}, false, status.Error(codes.InvalidArgument, "Descriptor chain contains a cycle")
+		}
+		descriptor := vq.descriptors[uint32(index)*descriptorSizeBytes:]
+		address := binary.LittleEndian.Uint64(descriptor[0:])
+		size := binary.LittleEndian.Uint32(descriptor[8:])
+		flags := binary.LittleEndian.Uint16(descriptor[12:])
+		next := binary.LittleEndian.Uint16(descriptor[14:])
+
+		if flags&descriptorFlagIndirect != 0 {
+			return descriptorChain{}, false, status.Error(codes.InvalidArgument, "Indirect descriptors have not been negotiated")
+		}
+		buffer, ok := memory.translateGuestPhysical(address, uint64(size))
+		if !ok {
+			return descriptorChain{}, false, status.Errorf(codes.InvalidArgument, "Descriptor %d is not contained in guest memory", index)
+		}
+		if flags&descriptorFlagWrite != 0 {
+			chain.writable = append(chain.writable, buffer)
+		} else {
+			if len(chain.writable) > 0 {
+				return descriptorChain{}, false, status.Error(codes.InvalidArgument, "Descriptor chain contains readable buffers after writable buffers")
+			}
+			chain.readable = append(chain.readable, buffer)
+		}
+
+		if flags&descriptorFlagNext == 0 {
+			return chain, true, nil
+		}
+		index = next
+	}
+}
+
+// push a descriptor chain onto the used ring, indicating that the
+// request has been processed and that the provided number of bytes
+// have been written into its writable buffers.
+func (vq *virtqueue) push(head uint16, length uint32) {
+	element := vq.used[4+usedElementSizeBytes*uint32(vq.usedIndex%vq.size):]
+	binary.LittleEndian.PutUint32(element[0:], uint32(head))
+	binary.LittleEndian.PutUint32(element[4:], length)
+	vq.usedIndex++
+
+	// Publish the new index together with the flags field, which
+	// is always left zero. This ensures the element written above
+	// becomes visible to the guest first.
+	atomic.StoreUint32((*uint32)(unsafe.Pointer(&vq.used[0])), uint32(vq.usedIndex)<<16)
+}
+
+// needsNotification returns whether the guest wants to be notified
+// about descriptor chains that have been placed on the used ring.
+func (vq *virtqueue) needsNotification() bool {
+	return loadUint16Acquire(vq.available[0:])&availableFlagNoInterrupt == 0
+}
@@ -0,0 +1,40 @@
+package virtual
+
+// writeBarrierLeaf is implemented by leaves whose contents may be
+// modified through file descriptors that are opened for writing.
+type writeBarrierLeaf interface {
+	applyWriteBarrier() Status
+	// revokeWriteBarrier discards any write barrier that was
+	// applied previously. It returns whether the leaf is opened
+	// for writing, meaning that the kernel may hold on to data
+	// that still needs to be written to it.
+	revokeWriteBarrier() bool
+	// getWritableDescriptorsClosedChannel returns a channel that
+	// is closed once the leaf is no longer opened for writing. It
+	// returns nil if the leaf is not opened for writing.
+	getWritableDescriptorsClosedChannel() <-chan struct{}
+}
+
+// ApplyWriteBarrier informs a leaf that all writes against it that
+// have completed up to this point have been delivered to the virtual
+// file system, even though file descriptors opened for writing may
+// still be present. Until the leaf is modified once again, its digest
+// may be computed and reported through the Remote Output Service.
+//
+// This function should only be called if the caller can guarantee that
+// the kernel holds no further data that needs to be written to the
+// file, such as in response to the write barrier ioctl() against a
+// FUSE mount that doesn't use a writeback cache. Calling this function
+// on leaves whose contents cannot be modified has no effect.
+//
+// When a writeback cache is used, writes performed after the barrier
+// is applied may remain in the kernel's page cache without the leaf
+// being modified. Barriers are therefore revoked when the leaf's
+// contents are about to be uploaded and writeback is requested from
+// the kernel, so that a barrier never outlives a failed writeback.
+func ApplyWriteBarrier(leaf Leaf) Status {
+	if wbl, ok := getUndecoratedLeaf(leaf).(writeBarrierLeaf); ok {
+		return wbl.applyWriteBarrier()
+	}
+	return StatusOK
+}
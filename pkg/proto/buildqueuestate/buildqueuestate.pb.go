@@ -76,7 +76,7 @@ func (x ListInvocationChildrenRequest_Filter) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ListInvocationChildrenRequest_Filter.Descriptor instead.
 func (ListInvocationChildrenRequest_Filter) EnumDescriptor() ([]byte, []int) {
-	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{17, 0}
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{19, 0}
 }
 
 type PaginationInfo struct {
@@ -1159,6 +1159,116 @@ func (x *KillOperationsRequest) GetStatus() *status.Status {
 	return nil
 }
 
+type ReprioritizeOperationRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	OperationName string `protobuf:"bytes,1,opt,name=operation_name,json=operationName,proto3" json:"operation_name,omitempty"`
+	Priority      int32  `protobuf:"varint,2,opt,name=priority,proto3" json:"priority,omitempty"`
+}
+
+func (x *ReprioritizeOperationRequest) Reset() {
+	*x = ReprioritizeOperationRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReprioritizeOperationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReprioritizeOperationRequest) ProtoMessage() {}
+
+func (x *ReprioritizeOperationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReprioritizeOperationRequest.ProtoReflect.Descriptor instead.
+func (*ReprioritizeOperationRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ReprioritizeOperationRequest) GetOperationName() string {
+	if x != nil {
+		return x.OperationName
+	}
+	return ""
+}
+
+func (x *ReprioritizeOperationRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+type RetagOperationRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	OperationName string       `protobuf:"bytes,1,opt,name=operation_name,json=operationName,proto3" json:"operation_name,omitempty"`
+	InvocationIds []*anypb.Any `protobuf:"bytes,2,rep,name=invocation_ids,json=invocationIds,proto3" json:"invocation_ids,omitempty"`
+}
+
+func (x *RetagOperationRequest) Reset() {
+	*x = RetagOperationRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RetagOperationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetagOperationRequest) ProtoMessage() {}
+
+func (x *RetagOperationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetagOperationRequest.ProtoReflect.Descriptor instead.
+func (*RetagOperationRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RetagOperationRequest) GetOperationName() string {
+	if x != nil {
+		return x.OperationName
+	}
+	return ""
+}
+
+func (x *RetagOperationRequest) GetInvocationIds() []*anypb.Any {
+	if x != nil {
+		return x.InvocationIds
+	}
+	return nil
+}
+
 type ListPlatformQueuesResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1170,7 +1280,7 @@ type ListPlatformQueuesResponse struct {
 func (x *ListPlatformQueuesResponse) Reset() {
 	*x = ListPlatformQueuesResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[16]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1183,7 +1293,7 @@ func (x *ListPlatformQueuesResponse) String() string {
 func (*ListPlatformQueuesResponse) ProtoMessage() {}
 
 func (x *ListPlatformQueuesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[16]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1196,7 +1306,7 @@ func (x *ListPlatformQueuesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListPlatformQueuesResponse.ProtoReflect.Descriptor instead.
 func (*ListPlatformQueuesResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{16}
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *ListPlatformQueuesResponse) GetPlatformQueues() []*PlatformQueueState {
@@ -1218,7 +1328,7 @@ type ListInvocationChildrenRequest struct {
 func (x *ListInvocationChildrenRequest) Reset() {
 	*x = ListInvocationChildrenRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[17]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1231,7 +1341,7 @@ func (x *ListInvocationChildrenRequest) String() string {
 func (*ListInvocationChildrenRequest) ProtoMessage() {}
 
 func (x *ListInvocationChildrenRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[17]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1244,7 +1354,7 @@ func (x *ListInvocationChildrenRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListInvocationChildrenRequest.ProtoReflect.Descriptor instead.
 func (*ListInvocationChildrenRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{17}
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *ListInvocationChildrenRequest) GetInvocationName() *InvocationName {
@@ -1272,7 +1382,7 @@ type ListInvocationChildrenResponse struct {
 func (x *ListInvocationChildrenResponse) Reset() {
 	*x = ListInvocationChildrenResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[18]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[20]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1285,7 +1395,7 @@ func (x *ListInvocationChildrenResponse) String() string {
 func (*ListInvocationChildrenResponse) ProtoMessage() {}
 
 func (x *ListInvocationChildrenResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[18]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1298,7 +1408,7 @@ func (x *ListInvocationChildrenResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListInvocationChildrenResponse.ProtoReflect.Descriptor instead.
 func (*ListInvocationChildrenResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{18}
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *ListInvocationChildrenResponse) GetChildren() []*InvocationChildState {
@@ -1321,7 +1431,7 @@ type ListQueuedOperationsRequest struct {
 func (x *ListQueuedOperationsRequest) Reset() {
 	*x = ListQueuedOperationsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[19]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[21]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1334,7 +1444,7 @@ func (x *ListQueuedOperationsRequest) String() string {
 func (*ListQueuedOperationsRequest) ProtoMessage() {}
 
 func (x *ListQueuedOperationsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[19]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[21]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1347,7 +1457,7 @@ func (x *ListQueuedOperationsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListQueuedOperationsRequest.ProtoReflect.Descriptor instead.
 func (*ListQueuedOperationsRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{19}
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *ListQueuedOperationsRequest) GetInvocationName() *InvocationName {
@@ -1383,7 +1493,7 @@ type ListQueuedOperationsResponse struct {
 func (x *ListQueuedOperationsResponse) Reset() {
 	*x = ListQueuedOperationsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[20]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[22]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1396,7 +1506,7 @@ func (x *ListQueuedOperationsResponse) String() string {
 func (*ListQueuedOperationsResponse) ProtoMessage() {}
 
 func (x *ListQueuedOperationsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[20]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[22]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1409,7 +1519,7 @@ func (x *ListQueuedOperationsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListQueuedOperationsResponse.ProtoReflect.Descriptor instead.
 func (*ListQueuedOperationsResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{20}
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *ListQueuedOperationsResponse) GetQueuedOperations() []*OperationState {
@@ -1439,7 +1549,7 @@ type ListWorkersRequest struct {
 func (x *ListWorkersRequest) Reset() {
 	*x = ListWorkersRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[21]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[23]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1452,7 +1562,7 @@ func (x *ListWorkersRequest) String() string {
 func (*ListWorkersRequest) ProtoMessage() {}
 
 func (x *ListWorkersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[21]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[23]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1465,7 +1575,7 @@ func (x *ListWorkersRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListWorkersRequest.ProtoReflect.Descriptor instead.
 func (*ListWorkersRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{21}
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *ListWorkersRequest) GetFilter() *ListWorkersRequest_Filter {
@@ -1501,7 +1611,7 @@ type ListWorkersResponse struct {
 func (x *ListWorkersResponse) Reset() {
 	*x = ListWorkersResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[22]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[24]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1514,7 +1624,7 @@ func (x *ListWorkersResponse) String() string {
 func (*ListWorkersResponse) ProtoMessage() {}
 
 func (x *ListWorkersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[22]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[24]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1527,7 +1637,7 @@ func (x *ListWorkersResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListWorkersResponse.ProtoReflect.Descriptor instead.
 func (*ListWorkersResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{22}
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *ListWorkersResponse) GetWorkers() []*WorkerState {
@@ -1555,7 +1665,7 @@ type TerminateWorkersRequest struct {
 func (x *TerminateWorkersRequest) Reset() {
 	*x = TerminateWorkersRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[23]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[25]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1568,7 +1678,7 @@ func (x *TerminateWorkersRequest) String() string {
 func (*TerminateWorkersRequest) ProtoMessage() {}
 
 func (x *TerminateWorkersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[23]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1581,7 +1691,7 @@ func (x *TerminateWorkersRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TerminateWorkersRequest.ProtoReflect.Descriptor instead.
 func (*TerminateWorkersRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{23}
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *TerminateWorkersRequest) GetWorkerIdPattern() map[string]string {
@@ -1602,7 +1712,7 @@ type ListDrainsRequest struct {
 func (x *ListDrainsRequest) Reset() {
 	*x = ListDrainsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[24]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[26]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1615,7 +1725,7 @@ func (x *ListDrainsRequest) String() string {
 func (*ListDrainsRequest) ProtoMessage() {}
 
 func (x *ListDrainsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[24]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[26]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1628,7 +1738,7 @@ func (x *ListDrainsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListDrainsRequest.ProtoReflect.Descriptor instead.
 func (*ListDrainsRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{24}
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *ListDrainsRequest) GetSizeClassQueueName() *SizeClassQueueName {
@@ -1649,7 +1759,7 @@ type ListDrainsResponse struct {
 func (x *ListDrainsResponse) Reset() {
 	*x = ListDrainsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[25]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[27]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1662,7 +1772,7 @@ func (x *ListDrainsResponse) String() string {
 func (*ListDrainsResponse) ProtoMessage() {}
 
 func (x *ListDrainsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[25]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[27]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1675,7 +1785,7 @@ func (x *ListDrainsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListDrainsResponse.ProtoReflect.Descriptor instead.
 func (*ListDrainsResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{25}
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *ListDrainsResponse) GetDrains() []*DrainState {
@@ -1697,7 +1807,7 @@ type AddOrRemoveDrainRequest struct {
 func (x *AddOrRemoveDrainRequest) Reset() {
 	*x = AddOrRemoveDrainRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[26]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[28]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1710,7 +1820,7 @@ func (x *AddOrRemoveDrainRequest) String() string {
 func (*AddOrRemoveDrainRequest) ProtoMessage() {}
 
 func (x *AddOrRemoveDrainRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[26]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[28]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1723,7 +1833,7 @@ func (x *AddOrRemoveDrainRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AddOrRemoveDrainRequest.ProtoReflect.Descriptor instead.
 func (*AddOrRemoveDrainRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{26}
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *AddOrRemoveDrainRequest) GetSizeClassQueueName() *SizeClassQueueName {
@@ -1749,7 +1859,7 @@ type BackgroundLearning struct {
 func (x *BackgroundLearning) Reset() {
 	*x = BackgroundLearning{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[27]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[29]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1762,7 +1872,7 @@ func (x *BackgroundLearning) String() string {
 func (*BackgroundLearning) ProtoMessage() {}
 
 func (x *BackgroundLearning) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[27]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[29]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1775,7 +1885,125 @@ func (x *BackgroundLearning) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BackgroundLearning.ProtoReflect.Descriptor instead.
 func (*BackgroundLearning) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{27}
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{29}
+}
+
+type PredictedExecution struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ExpectedDuration *durationpb.Duration `protobuf:"bytes,1,opt,name=expected_duration,json=expectedDuration,proto3" json:"expected_duration,omitempty"`
+	Timeout          *durationpb.Duration `protobuf:"bytes,2,opt,name=timeout,proto3" json:"timeout,omitempty"`
+}
+
+func (x *PredictedExecution) Reset() {
+	*x = PredictedExecution{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PredictedExecution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PredictedExecution) ProtoMessage() {}
+
+func (x *PredictedExecution) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PredictedExecution.ProtoReflect.Descriptor instead.
+func (*PredictedExecution) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *PredictedExecution) GetExpectedDuration() *durationpb.Duration {
+	if x != nil {
+		return x.ExpectedDuration
+	}
+	return nil
+}
+
+func (x *PredictedExecution) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+type AppliedPlatformProperties struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Added                []*v2.Platform_Property `protobuf:"bytes,1,rep,name=added,proto3" json:"added,omitempty"`
+	Overridden           []*v2.Platform_Property `protobuf:"bytes,2,rep,name=overridden,proto3" json:"overridden,omitempty"`
+	OriginalActionDigest *v2.Digest              `protobuf:"bytes,3,opt,name=original_action_digest,json=originalActionDigest,proto3" json:"original_action_digest,omitempty"`
+}
+
+func (x *AppliedPlatformProperties) Reset() {
+	*x = AppliedPlatformProperties{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AppliedPlatformProperties) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppliedPlatformProperties) ProtoMessage() {}
+
+func (x *AppliedPlatformProperties) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppliedPlatformProperties.ProtoReflect.Descriptor instead.
+func (*AppliedPlatformProperties) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *AppliedPlatformProperties) GetAdded() []*v2.Platform_Property {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+func (x *AppliedPlatformProperties) GetOverridden() []*v2.Platform_Property {
+	if x != nil {
+		return x.Overridden
+	}
+	return nil
+}
+
+func (x *AppliedPlatformProperties) GetOriginalActionDigest() *v2.Digest {
+	if x != nil {
+		return x.OriginalActionDigest
+	}
+	return nil
 }
 
 type ListOperationsRequest_StartAfter struct {
@@ -1789,7 +2017,7 @@ type ListOperationsRequest_StartAfter struct {
 func (x *ListOperationsRequest_StartAfter) Reset() {
 	*x = ListOperationsRequest_StartAfter{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[30]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[34]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1802,7 +2030,7 @@ func (x *ListOperationsRequest_StartAfter) String() string {
 func (*ListOperationsRequest_StartAfter) ProtoMessage() {}
 
 func (x *ListOperationsRequest_StartAfter) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[30]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[34]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1840,7 +2068,7 @@ type KillOperationsRequest_Filter struct {
 func (x *KillOperationsRequest_Filter) Reset() {
 	*x = KillOperationsRequest_Filter{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[31]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[35]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1853,7 +2081,7 @@ func (x *KillOperationsRequest_Filter) String() string {
 func (*KillOperationsRequest_Filter) ProtoMessage() {}
 
 func (x *KillOperationsRequest_Filter) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[31]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[35]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1920,7 +2148,7 @@ type ListQueuedOperationsRequest_StartAfter struct {
 func (x *ListQueuedOperationsRequest_StartAfter) Reset() {
 	*x = ListQueuedOperationsRequest_StartAfter{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[32]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[36]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1933,7 +2161,7 @@ func (x *ListQueuedOperationsRequest_StartAfter) String() string {
 func (*ListQueuedOperationsRequest_StartAfter) ProtoMessage() {}
 
 func (x *ListQueuedOperationsRequest_StartAfter) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[32]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[36]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1946,7 +2174,7 @@ func (x *ListQueuedOperationsRequest_StartAfter) ProtoReflect() protoreflect.Mes
 
 // Deprecated: Use ListQueuedOperationsRequest_StartAfter.ProtoReflect.Descriptor instead.
 func (*ListQueuedOperationsRequest_StartAfter) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{19, 0}
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{21, 0}
 }
 
 func (x *ListQueuedOperationsRequest_StartAfter) GetPriority() int32 {
@@ -1986,7 +2214,7 @@ type ListWorkersRequest_Filter struct {
 func (x *ListWorkersRequest_Filter) Reset() {
 	*x = ListWorkersRequest_Filter{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[33]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[37]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1999,7 +2227,7 @@ func (x *ListWorkersRequest_Filter) String() string {
 func (*ListWorkersRequest_Filter) ProtoMessage() {}
 
 func (x *ListWorkersRequest_Filter) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[33]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[37]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2012,7 +2240,7 @@ func (x *ListWorkersRequest_Filter) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListWorkersRequest_Filter.ProtoReflect.Descriptor instead.
 func (*ListWorkersRequest_Filter) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{21, 0}
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{23, 0}
 }
 
 func (m *ListWorkersRequest_Filter) GetType() isListWorkersRequest_Filter_Type {
@@ -2076,7 +2304,7 @@ type ListWorkersRequest_StartAfter struct {
 func (x *ListWorkersRequest_StartAfter) Reset() {
 	*x = ListWorkersRequest_StartAfter{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[34]
+		mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[38]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2089,7 +2317,7 @@ func (x *ListWorkersRequest_StartAfter) String() string {
 func (*ListWorkersRequest_StartAfter) ProtoMessage() {}
 
 func (x *ListWorkersRequest_StartAfter) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[34]
+	mi := &file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[38]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2102,7 +2330,7 @@ func (x *ListWorkersRequest_StartAfter) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListWorkersRequest_StartAfter.ProtoReflect.Descriptor instead.
 func (*ListWorkersRequest_StartAfter) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{21, 1}
+	return file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP(), []int{23, 1}
 }
 
 func (x *ListWorkersRequest_StartAfter) GetWorkerId() map[string]string {
@@ -2379,7 +2607,21 @@ var file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDesc = []byte{
 	0x69, 0x7a, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x51, 0x75, 0x65, 0x75, 0x65, 0x4e, 0x61, 0x6d,
 	0x65, 0x48, 0x00, 0x52, 0x1c, 0x73, 0x69, 0x7a, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x51, 0x75,
 	0x65, 0x75, 0x65, 0x57, 0x69, 0x74, 0x68, 0x6f, 0x75, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72,
-	0x73, 0x42, 0x06, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x74, 0x0a, 0x1a, 0x4c, 0x69, 0x73,
+	0x73, 0x42, 0x06, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x61, 0x0a, 0x1c, 0x52, 0x65, 0x70,
+	0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x69, 0x7a, 0x65, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x6f, 0x70, 0x65,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0d, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4e, 0x61, 0x6d, 0x65,
+	0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x22, 0x7b, 0x0a, 0x15,
+	0x52, 0x65, 0x74, 0x61, 0x67, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6f,
+	0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x3b, 0x0a, 0x0e,
+	0x69, 0x6e, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x0d, 0x69, 0x6e, 0x76, 0x6f,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x73, 0x22, 0x74, 0x0a, 0x1a, 0x4c, 0x69, 0x73,
 	0x74, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x51, 0x75, 0x65, 0x75, 0x65, 0x73, 0x52,
 	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x56, 0x0a, 0x0f, 0x70, 0x6c, 0x61, 0x74, 0x66,
 	0x6f, 0x72, 0x6d, 0x5f, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
@@ -2548,81 +2790,122 @@ var file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDesc = []byte{
 	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
 	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x14, 0x0a, 0x12, 0x42,
 	0x61, 0x63, 0x6b, 0x67, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x4c, 0x65, 0x61, 0x72, 0x6e, 0x69, 0x6e,
-	0x67, 0x32, 0xc0, 0x09, 0x0a, 0x0f, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x51, 0x75, 0x65, 0x75, 0x65,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x6f, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x4f, 0x70, 0x65, 0x72,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72,
-	0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61, 0x74,
-	0x65, 0x2e, 0x47, 0x65, 0x74, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2f, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72,
-	0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61, 0x74,
-	0x65, 0x2e, 0x47, 0x65, 0x74, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x75, 0x0a, 0x0e, 0x4c, 0x69, 0x73, 0x74, 0x4f, 0x70,
-	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x30, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64,
-	0x62, 0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73,
-	0x74, 0x61, 0x74, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x31, 0x2e, 0x62, 0x75, 0x69,
-	0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75,
-	0x65, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4f, 0x70, 0x65, 0x72, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5a, 0x0a,
-	0x0e, 0x4b, 0x69, 0x6c, 0x6c, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12,
-	0x30, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c,
-	0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x4b, 0x69, 0x6c, 0x6c,
-	0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x63, 0x0a, 0x12, 0x4c, 0x69, 0x73,
-	0x74, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x51, 0x75, 0x65, 0x75, 0x65, 0x73, 0x12,
-	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x35, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62,
+	0x67, 0x22, 0x91, 0x01, 0x0a, 0x12, 0x50, 0x72, 0x65, 0x64, 0x69, 0x63, 0x74, 0x65, 0x64, 0x45,
+	0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x46, 0x0a, 0x11, 0x65, 0x78, 0x70, 0x65,
+	0x63, 0x74, 0x65, 0x64, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x10,
+	0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x33, 0x0a, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x74, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x22, 0x98, 0x02, 0x0a, 0x19, 0x41, 0x70, 0x70, 0x6c, 0x69, 0x65,
+	0x64, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x50, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74,
+	0x69, 0x65, 0x73, 0x12, 0x48, 0x0a, 0x05, 0x61, 0x64, 0x64, 0x65, 0x64, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x32, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c,
+	0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f,
+	0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x2e, 0x50, 0x72,
+	0x6f, 0x70, 0x65, 0x72, 0x74, 0x79, 0x52, 0x05, 0x61, 0x64, 0x64, 0x65, 0x64, 0x12, 0x52, 0x0a,
+	0x0a, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x32, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e,
+	0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x76, 0x32, 0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x2e, 0x50, 0x72, 0x6f,
+	0x70, 0x65, 0x72, 0x74, 0x79, 0x52, 0x0a, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64, 0x65,
+	0x6e, 0x12, 0x5d, 0x0a, 0x16, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x5f, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x27, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e,
+	0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x76, 0x32, 0x2e, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x52, 0x14, 0x6f, 0x72, 0x69, 0x67,
+	0x69, 0x6e, 0x61, 0x6c, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74,
+	0x32, 0xc0, 0x09, 0x0a, 0x0f, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x51, 0x75, 0x65, 0x75, 0x65, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x12, 0x6f, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x4f, 0x70, 0x65, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e,
+	0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x2e, 0x47, 0x65, 0x74, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x2f, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e,
+	0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x2e, 0x47, 0x65, 0x74, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x75, 0x0a, 0x0e, 0x4c, 0x69, 0x73, 0x74, 0x4f, 0x70, 0x65,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x30, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62,
 	0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74,
-	0x61, 0x74, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d,
-	0x51, 0x75, 0x65, 0x75, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x8d,
-	0x01, 0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74, 0x49, 0x6e, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x12, 0x38, 0x2e, 0x62, 0x75, 0x69, 0x6c,
+	0x61, 0x74, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x31, 0x2e, 0x62, 0x75, 0x69, 0x6c,
 	0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65,
-	0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x49, 0x6e, 0x76, 0x6f, 0x63, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x39, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e,
+	0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5a, 0x0a, 0x0e,
+	0x4b, 0x69, 0x6c, 0x6c, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x30,
+	0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x4b, 0x69, 0x6c, 0x6c, 0x4f,
+	0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x63, 0x0a, 0x12, 0x4c, 0x69, 0x73, 0x74,
+	0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x51, 0x75, 0x65, 0x75, 0x65, 0x73, 0x12, 0x16,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x35, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61,
+	0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x51,
+	0x75, 0x65, 0x75, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x8d, 0x01,
+	0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74, 0x49, 0x6e, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x12, 0x38, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x62, 0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x49, 0x6e, 0x76, 0x6f, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x39, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x62,
+	0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x49, 0x6e, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x69,
+	0x6c, 0x64, 0x72, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x87, 0x01,
+	0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x51, 0x75, 0x65, 0x75, 0x65, 0x64, 0x4f, 0x70, 0x65, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x36, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61,
+	0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x51, 0x75, 0x65, 0x75, 0x65, 0x64, 0x4f, 0x70, 0x65,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x37,
+	0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x51,
+	0x75, 0x65, 0x75, 0x65, 0x64, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x6c, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x57,
+	0x6f, 0x72, 0x6b, 0x65, 0x72, 0x73, 0x12, 0x2d, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61,
+	0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72,
+	0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61, 0x74,
+	0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5e, 0x0a, 0x10, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61,
+	0x74, 0x65, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x73, 0x12, 0x32, 0x2e, 0x62, 0x75, 0x69, 0x6c,
+	0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65,
+	0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x57,
+	0x6f, 0x72, 0x6b, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x69, 0x0a, 0x0a, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x72, 0x61,
+	0x69, 0x6e, 0x73, 0x12, 0x2c, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e,
 	0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e,
-	0x4c, 0x69, 0x73, 0x74, 0x49, 0x6e, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68,
-	0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x87,
-	0x01, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x51, 0x75, 0x65, 0x75, 0x65, 0x64, 0x4f, 0x70, 0x65,
-	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x36, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62,
+	0x4c, 0x69, 0x73, 0x74, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x2d, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75,
+	0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x4c, 0x69,
+	0x73, 0x74, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x56, 0x0a, 0x08, 0x41, 0x64, 0x64, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x12, 0x32, 0x2e, 0x62,
+	0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75,
+	0x65, 0x75, 0x65, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x41, 0x64, 0x64, 0x4f, 0x72, 0x52, 0x65,
+	0x6d, 0x6f, 0x76, 0x65, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x59, 0x0a, 0x0b, 0x52, 0x65, 0x6d, 0x6f,
+	0x76, 0x65, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x12, 0x32, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62,
 	0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74,
-	0x61, 0x74, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x51, 0x75, 0x65, 0x75, 0x65, 0x64, 0x4f, 0x70,
-	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x37, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c,
-	0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74,
-	0x51, 0x75, 0x65, 0x75, 0x65, 0x64, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x6c, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74,
-	0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x73, 0x12, 0x2d, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62,
+	0x61, 0x74, 0x65, 0x2e, 0x41, 0x64, 0x64, 0x4f, 0x72, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x44,
+	0x72, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x32, 0xde, 0x01, 0x0a, 0x16, 0x51, 0x75, 0x65, 0x75, 0x65, 0x64, 0x4f, 0x70,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x12, 0x68,
+	0x0a, 0x15, 0x52, 0x65, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x69, 0x7a, 0x65, 0x4f, 0x70,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x37, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62,
 	0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74,
-	0x61, 0x74, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x73, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61,
-	0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61,
-	0x74, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x73, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5e, 0x0a, 0x10, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e,
-	0x61, 0x74, 0x65, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x73, 0x12, 0x32, 0x2e, 0x62, 0x75, 0x69,
+	0x61, 0x74, 0x65, 0x2e, 0x52, 0x65, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x69, 0x7a, 0x65,
+	0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x5a, 0x0a, 0x0e, 0x52, 0x65, 0x74, 0x61,
+	0x67, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x30, 0x2e, 0x62, 0x75, 0x69,
 	0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75,
-	0x65, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65,
-	0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16,
-	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
-	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x69, 0x0a, 0x0a, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x72,
-	0x61, 0x69, 0x6e, 0x73, 0x12, 0x2c, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e,
-	0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61, 0x74, 0x65,
-	0x2e, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x2d, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x62,
-	0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x4c,
-	0x69, 0x73, 0x74, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x56, 0x0a, 0x08, 0x41, 0x64, 0x64, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x12, 0x32, 0x2e,
-	0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71,
-	0x75, 0x65, 0x75, 0x65, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x41, 0x64, 0x64, 0x4f, 0x72, 0x52,
-	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x59, 0x0a, 0x0b, 0x52, 0x65, 0x6d,
-	0x6f, 0x76, 0x65, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x12, 0x32, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64,
-	0x62, 0x61, 0x72, 0x6e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73,
-	0x74, 0x61, 0x74, 0x65, 0x2e, 0x41, 0x64, 0x64, 0x4f, 0x72, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65,
-	0x44, 0x72, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67,
+	0x65, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x52, 0x65, 0x74, 0x61, 0x67, 0x4f, 0x70, 0x65, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67,
 	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45,
 	0x6d, 0x70, 0x74, 0x79, 0x42, 0x44, 0x5a, 0x42, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
 	0x6f, 0x6d, 0x2f, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2f, 0x62, 0x62, 0x2d,
@@ -2645,7 +2928,7 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDescGZIP() []byte {
 }
 
 var file_pkg_proto_buildqueuestate_buildqueuestate_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes = make([]protoimpl.MessageInfo, 38)
+var file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes = make([]protoimpl.MessageInfo, 42)
 var file_pkg_proto_buildqueuestate_buildqueuestate_proto_goTypes = []interface{}{
 	(ListInvocationChildrenRequest_Filter)(0),      // 0: buildbarn.buildqueuestate.ListInvocationChildrenRequest.Filter
 	(*PaginationInfo)(nil),                         // 1: buildbarn.buildqueuestate.PaginationInfo
@@ -2664,123 +2947,138 @@ var file_pkg_proto_buildqueuestate_buildqueuestate_proto_goTypes = []interface{}
 	(*ListOperationsRequest)(nil),                  // 14: buildbarn.buildqueuestate.ListOperationsRequest
 	(*ListOperationsResponse)(nil),                 // 15: buildbarn.buildqueuestate.ListOperationsResponse
 	(*KillOperationsRequest)(nil),                  // 16: buildbarn.buildqueuestate.KillOperationsRequest
-	(*ListPlatformQueuesResponse)(nil),             // 17: buildbarn.buildqueuestate.ListPlatformQueuesResponse
-	(*ListInvocationChildrenRequest)(nil),          // 18: buildbarn.buildqueuestate.ListInvocationChildrenRequest
-	(*ListInvocationChildrenResponse)(nil),         // 19: buildbarn.buildqueuestate.ListInvocationChildrenResponse
-	(*ListQueuedOperationsRequest)(nil),            // 20: buildbarn.buildqueuestate.ListQueuedOperationsRequest
-	(*ListQueuedOperationsResponse)(nil),           // 21: buildbarn.buildqueuestate.ListQueuedOperationsResponse
-	(*ListWorkersRequest)(nil),                     // 22: buildbarn.buildqueuestate.ListWorkersRequest
-	(*ListWorkersResponse)(nil),                    // 23: buildbarn.buildqueuestate.ListWorkersResponse
-	(*TerminateWorkersRequest)(nil),                // 24: buildbarn.buildqueuestate.TerminateWorkersRequest
-	(*ListDrainsRequest)(nil),                      // 25: buildbarn.buildqueuestate.ListDrainsRequest
-	(*ListDrainsResponse)(nil),                     // 26: buildbarn.buildqueuestate.ListDrainsResponse
-	(*AddOrRemoveDrainRequest)(nil),                // 27: buildbarn.buildqueuestate.AddOrRemoveDrainRequest
-	(*BackgroundLearning)(nil),                     // 28: buildbarn.buildqueuestate.BackgroundLearning
-	nil,                                            // 29: buildbarn.buildqueuestate.WorkerState.IdEntry
-	nil,                                            // 30: buildbarn.buildqueuestate.DrainState.WorkerIdPatternEntry
-	(*ListOperationsRequest_StartAfter)(nil),       // 31: buildbarn.buildqueuestate.ListOperationsRequest.StartAfter
-	(*KillOperationsRequest_Filter)(nil),           // 32: buildbarn.buildqueuestate.KillOperationsRequest.Filter
-	(*ListQueuedOperationsRequest_StartAfter)(nil), // 33: buildbarn.buildqueuestate.ListQueuedOperationsRequest.StartAfter
-	(*ListWorkersRequest_Filter)(nil),              // 34: buildbarn.buildqueuestate.ListWorkersRequest.Filter
-	(*ListWorkersRequest_StartAfter)(nil),          // 35: buildbarn.buildqueuestate.ListWorkersRequest.StartAfter
-	nil,                                            // 36: buildbarn.buildqueuestate.ListWorkersRequest.StartAfter.WorkerIdEntry
-	nil,                                            // 37: buildbarn.buildqueuestate.TerminateWorkersRequest.WorkerIdPatternEntry
-	nil,                                            // 38: buildbarn.buildqueuestate.AddOrRemoveDrainRequest.WorkerIdPatternEntry
-	(*v2.Platform)(nil),                            // 39: build.bazel.remote.execution.v2.Platform
-	(*anypb.Any)(nil),                              // 40: google.protobuf.Any
-	(*durationpb.Duration)(nil),                    // 41: google.protobuf.Duration
-	(*timestamppb.Timestamp)(nil),                  // 42: google.protobuf.Timestamp
-	(*v2.Digest)(nil),                              // 43: build.bazel.remote.execution.v2.Digest
-	(*emptypb.Empty)(nil),                          // 44: google.protobuf.Empty
-	(*v2.ExecuteResponse)(nil),                     // 45: build.bazel.remote.execution.v2.ExecuteResponse
-	(v2.DigestFunction_Value)(0),                   // 46: build.bazel.remote.execution.v2.DigestFunction.Value
-	(v2.ExecutionStage_Value)(0),                   // 47: build.bazel.remote.execution.v2.ExecutionStage.Value
-	(*status.Status)(nil),                          // 48: google.rpc.Status
+	(*ReprioritizeOperationRequest)(nil),           // 17: buildbarn.buildqueuestate.ReprioritizeOperationRequest
+	(*RetagOperationRequest)(nil),                  // 18: buildbarn.buildqueuestate.RetagOperationRequest
+	(*ListPlatformQueuesResponse)(nil),             // 19: buildbarn.buildqueuestate.ListPlatformQueuesResponse
+	(*ListInvocationChildrenRequest)(nil),          // 20: buildbarn.buildqueuestate.ListInvocationChildrenRequest
+	(*ListInvocationChildrenResponse)(nil),         // 21: buildbarn.buildqueuestate.ListInvocationChildrenResponse
+	(*ListQueuedOperationsRequest)(nil),            // 22: buildbarn.buildqueuestate.ListQueuedOperationsRequest
+	(*ListQueuedOperationsResponse)(nil),           // 23: buildbarn.buildqueuestate.ListQueuedOperationsResponse
+	(*ListWorkersRequest)(nil),                     // 24: buildbarn.buildqueuestate.ListWorkersRequest
+	(*ListWorkersResponse)(nil),                    // 25: buildbarn.buildqueuestate.ListWorkersResponse
+	(*TerminateWorkersRequest)(nil),                // 26: buildbarn.buildqueuestate.TerminateWorkersRequest
+	(*ListDrainsRequest)(nil),                      // 27: buildbarn.buildqueuestate.ListDrainsRequest
+	(*ListDrainsResponse)(nil),                     // 28: buildbarn.buildqueuestate.ListDrainsResponse
+	(*AddOrRemoveDrainRequest)(nil),                // 29: buildbarn.buildqueuestate.AddOrRemoveDrainRequest
+	(*BackgroundLearning)(nil),                     // 30: buildbarn.buildqueuestate.BackgroundLearning
+	(*PredictedExecution)(nil),                     // 31: buildbarn.buildqueuestate.PredictedExecution
+	(*AppliedPlatformProperties)(nil),              // 32: buildbarn.buildqueuestate.AppliedPlatformProperties
+	nil,                                            // 33: buildbarn.buildqueuestate.WorkerState.IdEntry
+	nil,                                            // 34: buildbarn.buildqueuestate.DrainState.WorkerIdPatternEntry
+	(*ListOperationsRequest_StartAfter)(nil),       // 35: buildbarn.buildqueuestate.ListOperationsRequest.StartAfter
+	(*KillOperationsRequest_Filter)(nil),           // 36: buildbarn.buildqueuestate.KillOperationsRequest.Filter
+	(*ListQueuedOperationsRequest_StartAfter)(nil), // 37: buildbarn.buildqueuestate.ListQueuedOperationsRequest.StartAfter
+	(*ListWorkersRequest_Filter)(nil),              // 38: buildbarn.buildqueuestate.ListWorkersRequest.Filter
+	(*ListWorkersRequest_StartAfter)(nil),          // 39: buildbarn.buildqueuestate.ListWorkersRequest.StartAfter
+	nil,                                            // 40: buildbarn.buildqueuestate.ListWorkersRequest.StartAfter.WorkerIdEntry
+	nil,                                            // 41: buildbarn.buildqueuestate.TerminateWorkersRequest.WorkerIdPatternEntry
+	nil,                                            // 42: buildbarn.buildqueuestate.AddOrRemoveDrainRequest.WorkerIdPatternEntry
+	(*v2.Platform)(nil),                            // 43: build.bazel.remote.execution.v2.Platform
+	(*anypb.Any)(nil),                              // 44: google.protobuf.Any
+	(*durationpb.Duration)(nil),                    // 45: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),                  // 46: google.protobuf.Timestamp
+	(*v2.Digest)(nil),                              // 47: build.bazel.remote.execution.v2.Digest
+	(*emptypb.Empty)(nil),                          // 48: google.protobuf.Empty
+	(*v2.ExecuteResponse)(nil),                     // 49: build.bazel.remote.execution.v2.ExecuteResponse
+	(v2.DigestFunction_Value)(0),                   // 50: build.bazel.remote.execution.v2.DigestFunction.Value
+	(v2.ExecutionStage_Value)(0),                   // 51: build.bazel.remote.execution.v2.ExecutionStage.Value
+	(*status.Status)(nil),                          // 52: google.rpc.Status
+	(*v2.Platform_Property)(nil),                   // 53: build.bazel.remote.execution.v2.Platform.Property
 }
 var file_pkg_proto_buildqueuestate_buildqueuestate_proto_depIdxs = []int32{
-	39, // 0: buildbarn.buildqueuestate.PlatformQueueName.platform:type_name -> build.bazel.remote.execution.v2.Platform
+	43, // 0: buildbarn.buildqueuestate.PlatformQueueName.platform:type_name -> build.bazel.remote.execution.v2.Platform
 	2,  // 1: buildbarn.buildqueuestate.SizeClassQueueName.platform_queue_name:type_name -> buildbarn.buildqueuestate.PlatformQueueName
 	3,  // 2: buildbarn.buildqueuestate.InvocationName.size_class_queue_name:type_name -> buildbarn.buildqueuestate.SizeClassQueueName
-	40, // 3: buildbarn.buildqueuestate.InvocationName.ids:type_name -> google.protobuf.Any
+	44, // 3: buildbarn.buildqueuestate.InvocationName.ids:type_name -> google.protobuf.Any
 	4,  // 4: buildbarn.buildqueuestate.OperationState.invocation_name:type_name -> buildbarn.buildqueuestate.InvocationName
-	41, // 5: buildbarn.buildqueuestate.OperationState.expected_duration:type_name -> google.protobuf.Duration
-	42, // 6: buildbarn.buildqueuestate.OperationState.queued_timestamp:type_name -> google.protobuf.Timestamp
-	43, // 7: buildbarn.buildqueuestate.OperationState.action_digest:type_name -> build.bazel.remote.execution.v2.Digest
-	42, // 8: buildbarn.buildqueuestate.OperationState.timeout:type_name -> google.protobuf.Timestamp
-	44, // 9: buildbarn.buildqueuestate.OperationState.queued:type_name -> google.protobuf.Empty
-	44, // 10: buildbarn.buildqueuestate.OperationState.executing:type_name -> google.protobuf.Empty
-	45, // 11: buildbarn.buildqueuestate.OperationState.completed:type_name -> build.bazel.remote.execution.v2.ExecuteResponse
-	46, // 12: buildbarn.buildqueuestate.OperationState.digest_function:type_name -> build.bazel.remote.execution.v2.DigestFunction.Value
-	42, // 13: buildbarn.buildqueuestate.SizeClassQueueState.timeout:type_name -> google.protobuf.Timestamp
+	45, // 5: buildbarn.buildqueuestate.OperationState.expected_duration:type_name -> google.protobuf.Duration
+	46, // 6: buildbarn.buildqueuestate.OperationState.queued_timestamp:type_name -> google.protobuf.Timestamp
+	47, // 7: buildbarn.buildqueuestate.OperationState.action_digest:type_name -> build.bazel.remote.execution.v2.Digest
+	46, // 8: buildbarn.buildqueuestate.OperationState.timeout:type_name -> google.protobuf.Timestamp
+	48, // 9: buildbarn.buildqueuestate.OperationState.queued:type_name -> google.protobuf.Empty
+	48, // 10: buildbarn.buildqueuestate.OperationState.executing:type_name -> google.protobuf.Empty
+	49, // 11: buildbarn.buildqueuestate.OperationState.completed:type_name -> build.bazel.remote.execution.v2.ExecuteResponse
+	50, // 12: buildbarn.buildqueuestate.OperationState.digest_function:type_name -> build.bazel.remote.execution.v2.DigestFunction.Value
+	46, // 13: buildbarn.buildqueuestate.SizeClassQueueState.timeout:type_name -> google.protobuf.Timestamp
 	8,  // 14: buildbarn.buildqueuestate.SizeClassQueueState.root_invocation:type_name -> buildbarn.buildqueuestate.InvocationState
 	2,  // 15: buildbarn.buildqueuestate.PlatformQueueState.name:type_name -> buildbarn.buildqueuestate.PlatformQueueName
 	6,  // 16: buildbarn.buildqueuestate.PlatformQueueState.size_class_queues:type_name -> buildbarn.buildqueuestate.SizeClassQueueState
-	40, // 17: buildbarn.buildqueuestate.InvocationChildState.id:type_name -> google.protobuf.Any
+	44, // 17: buildbarn.buildqueuestate.InvocationChildState.id:type_name -> google.protobuf.Any
 	8,  // 18: buildbarn.buildqueuestate.InvocationChildState.state:type_name -> buildbarn.buildqueuestate.InvocationState
-	29, // 19: buildbarn.buildqueuestate.WorkerState.id:type_name -> buildbarn.buildqueuestate.WorkerState.IdEntry
-	42, // 20: buildbarn.buildqueuestate.WorkerState.timeout:type_name -> google.protobuf.Timestamp
+	33, // 19: buildbarn.buildqueuestate.WorkerState.id:type_name -> buildbarn.buildqueuestate.WorkerState.IdEntry
+	46, // 20: buildbarn.buildqueuestate.WorkerState.timeout:type_name -> google.protobuf.Timestamp
 	5,  // 21: buildbarn.buildqueuestate.WorkerState.current_operation:type_name -> buildbarn.buildqueuestate.OperationState
-	30, // 22: buildbarn.buildqueuestate.DrainState.worker_id_pattern:type_name -> buildbarn.buildqueuestate.DrainState.WorkerIdPatternEntry
-	42, // 23: buildbarn.buildqueuestate.DrainState.created_timestamp:type_name -> google.protobuf.Timestamp
+	34, // 22: buildbarn.buildqueuestate.DrainState.worker_id_pattern:type_name -> buildbarn.buildqueuestate.DrainState.WorkerIdPatternEntry
+	46, // 23: buildbarn.buildqueuestate.DrainState.created_timestamp:type_name -> google.protobuf.Timestamp
 	5,  // 24: buildbarn.buildqueuestate.GetOperationResponse.operation:type_name -> buildbarn.buildqueuestate.OperationState
-	31, // 25: buildbarn.buildqueuestate.ListOperationsRequest.start_after:type_name -> buildbarn.buildqueuestate.ListOperationsRequest.StartAfter
-	40, // 26: buildbarn.buildqueuestate.ListOperationsRequest.filter_invocation_id:type_name -> google.protobuf.Any
-	47, // 27: buildbarn.buildqueuestate.ListOperationsRequest.filter_stage:type_name -> build.bazel.remote.execution.v2.ExecutionStage.Value
+	35, // 25: buildbarn.buildqueuestate.ListOperationsRequest.start_after:type_name -> buildbarn.buildqueuestate.ListOperationsRequest.StartAfter
+	44, // 26: buildbarn.buildqueuestate.ListOperationsRequest.filter_invocation_id:type_name -> google.protobuf.Any
+	51, // 27: buildbarn.buildqueuestate.ListOperationsRequest.filter_stage:type_name -> build.bazel.remote.execution.v2.ExecutionStage.Value
 	5,  // 28: buildbarn.buildqueuestate.ListOperationsResponse.operations:type_name -> buildbarn.buildqueuestate.OperationState
 	1,  // 29: buildbarn.buildqueuestate.ListOperationsResponse.pagination_info:type_name -> buildbarn.buildqueuestate.PaginationInfo
-	32, // 30: buildbarn.buildqueuestate.KillOperationsRequest.filter:type_name -> buildbarn.buildqueuestate.KillOperationsRequest.Filter
-	48, // 31: buildbarn.buildqueuestate.KillOperationsRequest.status:type_name -> google.rpc.Status
-	7,  // 32: buildbarn.buildqueuestate.ListPlatformQueuesResponse.platform_queues:type_name -> buildbarn.buildqueuestate.PlatformQueueState
-	4,  // 33: buildbarn.buildqueuestate.ListInvocationChildrenRequest.invocation_name:type_name -> buildbarn.buildqueuestate.InvocationName
-	0,  // 34: buildbarn.buildqueuestate.ListInvocationChildrenRequest.filter:type_name -> buildbarn.buildqueuestate.ListInvocationChildrenRequest.Filter
-	9,  // 35: buildbarn.buildqueuestate.ListInvocationChildrenResponse.children:type_name -> buildbarn.buildqueuestate.InvocationChildState
-	4,  // 36: buildbarn.buildqueuestate.ListQueuedOperationsRequest.invocation_name:type_name -> buildbarn.buildqueuestate.InvocationName
-	33, // 37: buildbarn.buildqueuestate.ListQueuedOperationsRequest.start_after:type_name -> buildbarn.buildqueuestate.ListQueuedOperationsRequest.StartAfter
-	5,  // 38: buildbarn.buildqueuestate.ListQueuedOperationsResponse.queued_operations:type_name -> buildbarn.buildqueuestate.OperationState
-	1,  // 39: buildbarn.buildqueuestate.ListQueuedOperationsResponse.pagination_info:type_name -> buildbarn.buildqueuestate.PaginationInfo
-	34, // 40: buildbarn.buildqueuestate.ListWorkersRequest.filter:type_name -> buildbarn.buildqueuestate.ListWorkersRequest.Filter
-	35, // 41: buildbarn.buildqueuestate.ListWorkersRequest.start_after:type_name -> buildbarn.buildqueuestate.ListWorkersRequest.StartAfter
-	10, // 42: buildbarn.buildqueuestate.ListWorkersResponse.workers:type_name -> buildbarn.buildqueuestate.WorkerState
-	1,  // 43: buildbarn.buildqueuestate.ListWorkersResponse.pagination_info:type_name -> buildbarn.buildqueuestate.PaginationInfo
-	37, // 44: buildbarn.buildqueuestate.TerminateWorkersRequest.worker_id_pattern:type_name -> buildbarn.buildqueuestate.TerminateWorkersRequest.WorkerIdPatternEntry
-	3,  // 45: buildbarn.buildqueuestate.ListDrainsRequest.size_class_queue_name:type_name -> buildbarn.buildqueuestate.SizeClassQueueName
-	11, // 46: buildbarn.buildqueuestate.ListDrainsResponse.drains:type_name -> buildbarn.buildqueuestate.DrainState
-	3,  // 47: buildbarn.buildqueuestate.AddOrRemoveDrainRequest.size_class_queue_name:type_name -> buildbarn.buildqueuestate.SizeClassQueueName
-	38, // 48: buildbarn.buildqueuestate.AddOrRemoveDrainRequest.worker_id_pattern:type_name -> buildbarn.buildqueuestate.AddOrRemoveDrainRequest.WorkerIdPatternEntry
-	3,  // 49: buildbarn.buildqueuestate.KillOperationsRequest.Filter.size_class_queue_without_workers:type_name -> buildbarn.buildqueuestate.SizeClassQueueName
-	41, // 50: buildbarn.buildqueuestate.ListQueuedOperationsRequest.StartAfter.expected_duration:type_name -> google.protobuf.Duration
-	42, // 51: buildbarn.buildqueuestate.ListQueuedOperationsRequest.StartAfter.queued_timestamp:type_name -> google.protobuf.Timestamp
-	3,  // 52: buildbarn.buildqueuestate.ListWorkersRequest.Filter.all:type_name -> buildbarn.buildqueuestate.SizeClassQueueName
-	4,  // 53: buildbarn.buildqueuestate.ListWorkersRequest.Filter.executing:type_name -> buildbarn.buildqueuestate.InvocationName
-	4,  // 54: buildbarn.buildqueuestate.ListWorkersRequest.Filter.idle_synchronizing:type_name -> buildbarn.buildqueuestate.InvocationName
-	36, // 55: buildbarn.buildqueuestate.ListWorkersRequest.StartAfter.worker_id:type_name -> buildbarn.buildqueuestate.ListWorkersRequest.StartAfter.WorkerIdEntry
-	12, // 56: buildbarn.buildqueuestate.BuildQueueState.GetOperation:input_type -> buildbarn.buildqueuestate.GetOperationRequest
-	14, // 57: buildbarn.buildqueuestate.BuildQueueState.ListOperations:input_type -> buildbarn.buildqueuestate.ListOperationsRequest
-	16, // 58: buildbarn.buildqueuestate.BuildQueueState.KillOperations:input_type -> buildbarn.buildqueuestate.KillOperationsRequest
-	44, // 59: buildbarn.buildqueuestate.BuildQueueState.ListPlatformQueues:input_type -> google.protobuf.Empty
-	18, // 60: buildbarn.buildqueuestate.BuildQueueState.ListInvocationChildren:input_type -> buildbarn.buildqueuestate.ListInvocationChildrenRequest
-	20, // 61: buildbarn.buildqueuestate.BuildQueueState.ListQueuedOperations:input_type -> buildbarn.buildqueuestate.ListQueuedOperationsRequest
-	22, // 62: buildbarn.buildqueuestate.BuildQueueState.ListWorkers:input_type -> buildbarn.buildqueuestate.ListWorkersRequest
-	24, // 63: buildbarn.buildqueuestate.BuildQueueState.TerminateWorkers:input_type -> buildbarn.buildqueuestate.TerminateWorkersRequest
-	25, // 64: buildbarn.buildqueuestate.BuildQueueState.ListDrains:input_type -> buildbarn.buildqueuestate.ListDrainsRequest
-	27, // 65: buildbarn.buildqueuestate.BuildQueueState.AddDrain:input_type -> buildbarn.buildqueuestate.AddOrRemoveDrainRequest
-	27, // 66: buildbarn.buildqueuestate.BuildQueueState.RemoveDrain:input_type -> buildbarn.buildqueuestate.AddOrRemoveDrainRequest
-	13, // 67: buildbarn.buildqueuestate.BuildQueueState.GetOperation:output_type -> buildbarn.buildqueuestate.GetOperationResponse
-	15, // 68: buildbarn.buildqueuestate.BuildQueueState.ListOperations:output_type -> buildbarn.buildqueuestate.ListOperationsResponse
-	44, // 69: buildbarn.buildqueuestate.BuildQueueState.KillOperations:output_type -> google.protobuf.Empty
-	17, // 70: buildbarn.buildqueuestate.BuildQueueState.ListPlatformQueues:output_type -> buildbarn.buildqueuestate.ListPlatformQueuesResponse
-	19, // 71: buildbarn.buildqueuestate.BuildQueueState.ListInvocationChildren:output_type -> buildbarn.buildqueuestate.ListInvocationChildrenResponse
-	21, // 72: buildbarn.buildqueuestate.BuildQueueState.ListQueuedOperations:output_type -> buildbarn.buildqueuestate.ListQueuedOperationsResponse
-	23, // 73: buildbarn.buildqueuestate.BuildQueueState.ListWorkers:output_type -> buildbarn.buildqueuestate.ListWorkersResponse
-	44, // 74: buildbarn.buildqueuestate.BuildQueueState.TerminateWorkers:output_type -> google.protobuf.Empty
-	26, // 75: buildbarn.buildqueuestate.BuildQueueState.ListDrains:output_type -> buildbarn.buildqueuestate.ListDrainsResponse
-	44, // 76: buildbarn.buildqueuestate.BuildQueueState.AddDrain:output_type -> google.protobuf.Empty
-	44, // 77: buildbarn.buildqueuestate.BuildQueueState.RemoveDrain:output_type -> google.protobuf.Empty
-	67, // [67:78] is the sub-list for method output_type
-	56, // [56:67] is the sub-list for method input_type
-	56, // [56:56] is the sub-list for extension type_name
-	56, // [56:56] is the sub-list for extension extendee
-	0,  // [0:56] is the sub-list for field type_name
+	36, // 30: buildbarn.buildqueuestate.KillOperationsRequest.filter:type_name -> buildbarn.buildqueuestate.KillOperationsRequest.Filter
+	52, // 31: buildbarn.buildqueuestate.KillOperationsRequest.status:type_name -> google.rpc.Status
+	44, // 32: buildbarn.buildqueuestate.RetagOperationRequest.invocation_ids:type_name -> google.protobuf.Any
+	7,  // 33: buildbarn.buildqueuestate.ListPlatformQueuesResponse.platform_queues:type_name -> buildbarn.buildqueuestate.PlatformQueueState
+	4,  // 34: buildbarn.buildqueuestate.ListInvocationChildrenRequest.invocation_name:type_name -> buildbarn.buildqueuestate.InvocationName
+	0,  // 35: buildbarn.buildqueuestate.ListInvocationChildrenRequest.filter:type_name -> buildbarn.buildqueuestate.ListInvocationChildrenRequest.Filter
+	9,  // 36: buildbarn.buildqueuestate.ListInvocationChildrenResponse.children:type_name -> buildbarn.buildqueuestate.InvocationChildState
+	4,  // 37: buildbarn.buildqueuestate.ListQueuedOperationsRequest.invocation_name:type_name -> buildbarn.buildqueuestate.InvocationName
+	37, // 38: buildbarn.buildqueuestate.ListQueuedOperationsRequest.start_after:type_name -> buildbarn.buildqueuestate.ListQueuedOperationsRequest.StartAfter
+	5,  // 39: buildbarn.buildqueuestate.ListQueuedOperationsResponse.queued_operations:type_name -> buildbarn.buildqueuestate.OperationState
+	1,  // 40: buildbarn.buildqueuestate.ListQueuedOperationsResponse.pagination_info:type_name -> buildbarn.buildqueuestate.PaginationInfo
+	38, // 41: buildbarn.buildqueuestate.ListWorkersRequest.filter:type_name -> buildbarn.buildqueuestate.ListWorkersRequest.Filter
+	39, // 42: buildbarn.buildqueuestate.ListWorkersRequest.start_after:type_name -> buildbarn.buildqueuestate.ListWorkersRequest.StartAfter
+	10, // 43: buildbarn.buildqueuestate.ListWorkersResponse.workers:type_name -> buildbarn.buildqueuestate.WorkerState
+	1,  // 44: buildbarn.buildqueuestate.ListWorkersResponse.pagination_info:type_name -> buildbarn.buildqueuestate.PaginationInfo
+	41, // 45: buildbarn.buildqueuestate.TerminateWorkersRequest.worker_id_pattern:type_name -> buildbarn.buildqueuestate.TerminateWorkersRequest.WorkerIdPatternEntry
+	3,  // 46: buildbarn.buildqueuestate.ListDrainsRequest.size_class_queue_name:type_name -> buildbarn.buildqueuestate.SizeClassQueueName
+	11, // 47: buildbarn.buildqueuestate.ListDrainsResponse.drains:type_name -> buildbarn.buildqueuestate.DrainState
+	3,  // 48: buildbarn.buildqueuestate.AddOrRemoveDrainRequest.size_class_queue_name:type_name -> buildbarn.buildqueuestate.SizeClassQueueName
+	42, // 49: buildbarn.buildqueuestate.AddOrRemoveDrainRequest.worker_id_pattern:type_name -> buildbarn.buildqueuestate.AddOrRemoveDrainRequest.WorkerIdPatternEntry
+	45, // 50: buildbarn.buildqueuestate.PredictedExecution.expected_duration:type_name -> google.protobuf.Duration
+	45, // 51: buildbarn.buildqueuestate.PredictedExecution.timeout:type_name -> google.protobuf.Duration
+	53, // 52: buildbarn.buildqueuestate.AppliedPlatformProperties.added:type_name -> build.bazel.remote.execution.v2.Platform.Property
+	53, // 53: buildbarn.buildqueuestate.AppliedPlatformProperties.overridden:type_name -> build.bazel.remote.execution.v2.Platform.Property
+	47, // 54: buildbarn.buildqueuestate.AppliedPlatformProperties.original_action_digest:type_name -> build.bazel.remote.execution.v2.Digest
+	3,  // 55: buildbarn.buildqueuestate.KillOperationsRequest.Filter.size_class_queue_without_workers:type_name -> buildbarn.buildqueuestate.SizeClassQueueName
+	45, // 56: buildbarn.buildqueuestate.ListQueuedOperationsRequest.StartAfter.expected_duration:type_name -> google.protobuf.Duration
+	46, // 57: buildbarn.buildqueuestate.ListQueuedOperationsRequest.StartAfter.queued_timestamp:type_name -> google.protobuf.Timestamp
+	3,  // 58: buildbarn.buildqueuestate.ListWorkersRequest.Filter.all:type_name -> buildbarn.buildqueuestate.SizeClassQueueName
+	4,  // 59: buildbarn.buildqueuestate.ListWorkersRequest.Filter.executing:type_name -> buildbarn.buildqueuestate.InvocationName
+	4,  // 60: buildbarn.buildqueuestate.ListWorkersRequest.Filter.idle_synchronizing:type_name -> buildbarn.buildqueuestate.InvocationName
+	40, // 61: buildbarn.buildqueuestate.ListWorkersRequest.StartAfter.worker_id:type_name -> buildbarn.buildqueuestate.ListWorkersRequest.StartAfter.WorkerIdEntry
+	12, // 62: buildbarn.buildqueuestate.BuildQueueState.GetOperation:input_type -> buildbarn.buildqueuestate.GetOperationRequest
+	14, // 63: buildbarn.buildqueuestate.BuildQueueState.ListOperations:input_type -> buildbarn.buildqueuestate.ListOperationsRequest
+	16, // 64: buildbarn.buildqueuestate.BuildQueueState.KillOperations:input_type -> buildbarn.buildqueuestate.KillOperationsRequest
+	48, // 65: buildbarn.buildqueuestate.BuildQueueState.ListPlatformQueues:input_type -> google.protobuf.Empty
+	20, // 66: buildbarn.buildqueuestate.BuildQueueState.ListInvocationChildren:input_type -> buildbarn.buildqueuestate.ListInvocationChildrenRequest
+	22, // 67: buildbarn.buildqueuestate.BuildQueueState.ListQueuedOperations:input_type -> buildbarn.buildqueuestate.ListQueuedOperationsRequest
+	24, // 68: buildbarn.buildqueuestate.BuildQueueState.ListWorkers:input_type -> buildbarn.buildqueuestate.ListWorkersRequest
+	26, // 69: buildbarn.buildqueuestate.BuildQueueState.TerminateWorkers:input_type -> buildbarn.buildqueuestate.TerminateWorkersRequest
+	27, // 70: buildbarn.buildqueuestate.BuildQueueState.ListDrains:input_type -> buildbarn.buildqueuestate.ListDrainsRequest
+	29, // 71: buildbarn.buildqueuestate.BuildQueueState.AddDrain:input_type -> buildbarn.buildqueuestate.AddOrRemoveDrainRequest
+	29, // 72: buildbarn.buildqueuestate.BuildQueueState.RemoveDrain:input_type -> buildbarn.buildqueuestate.AddOrRemoveDrainRequest
+	17, // 73: buildbarn.buildqueuestate.QueuedOperationControl.ReprioritizeOperation:input_type -> buildbarn.buildqueuestate.ReprioritizeOperationRequest
+	18, // 74: buildbarn.buildqueuestate.QueuedOperationControl.RetagOperation:input_type -> buildbarn.buildqueuestate.RetagOperationRequest
+	13, // 75: buildbarn.buildqueuestate.BuildQueueState.GetOperation:output_type -> buildbarn.buildqueuestate.GetOperationResponse
+	15, // 76: buildbarn.buildqueuestate.BuildQueueState.ListOperations:output_type -> buildbarn.buildqueuestate.ListOperationsResponse
+	48, // 77: buildbarn.buildqueuestate.BuildQueueState.KillOperations:output_type -> google.protobuf.Empty
+	19, // 78: buildbarn.buildqueuestate.BuildQueueState.ListPlatformQueues:output_type -> buildbarn.buildqueuestate.ListPlatformQueuesResponse
+	21, // 79: buildbarn.buildqueuestate.BuildQueueState.ListInvocationChildren:output_type -> buildbarn.buildqueuestate.ListInvocationChildrenResponse
+	23, // 80: buildbarn.buildqueuestate.BuildQueueState.ListQueuedOperations:output_type -> buildbarn.buildqueuestate.ListQueuedOperationsResponse
+	25, // 81: buildbarn.buildqueuestate.BuildQueueState.ListWorkers:output_type -> buildbarn.buildqueuestate.ListWorkersResponse
+	48, // 82: buildbarn.buildqueuestate.BuildQueueState.TerminateWorkers:output_type -> google.protobuf.Empty
+	28, // 83: buildbarn.buildqueuestate.BuildQueueState.ListDrains:output_type -> buildbarn.buildqueuestate.ListDrainsResponse
+	48, // 84: buildbarn.buildqueuestate.BuildQueueState.AddDrain:output_type -> google.protobuf.Empty
+	48, // 85: buildbarn.buildqueuestate.BuildQueueState.RemoveDrain:output_type -> google.protobuf.Empty
+	48, // 86: buildbarn.buildqueuestate.QueuedOperationControl.ReprioritizeOperation:output_type -> google.protobuf.Empty
+	48, // 87: buildbarn.buildqueuestate.QueuedOperationControl.RetagOperation:output_type -> google.protobuf.Empty
+	75, // [75:88] is the sub-list for method output_type
+	62, // [62:75] is the sub-list for method input_type
+	62, // [62:62] is the sub-list for extension type_name
+	62, // [62:62] is the sub-list for extension extendee
+	0,  // [0:62] is the sub-list for field type_name
 }
 
 func init() { file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() }
@@ -2982,7 +3280,7 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 			}
 		}
 		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListPlatformQueuesResponse); i {
+			switch v := v.(*ReprioritizeOperationRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2994,7 +3292,7 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 			}
 		}
 		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListInvocationChildrenRequest); i {
+			switch v := v.(*RetagOperationRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -3006,7 +3304,7 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 			}
 		}
 		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListInvocationChildrenResponse); i {
+			switch v := v.(*ListPlatformQueuesResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -3018,7 +3316,7 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 			}
 		}
 		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListQueuedOperationsRequest); i {
+			switch v := v.(*ListInvocationChildrenRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -3030,7 +3328,7 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 			}
 		}
 		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListQueuedOperationsResponse); i {
+			switch v := v.(*ListInvocationChildrenResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -3042,7 +3340,7 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 			}
 		}
 		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListWorkersRequest); i {
+			switch v := v.(*ListQueuedOperationsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -3054,7 +3352,7 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 			}
 		}
 		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListWorkersResponse); i {
+			switch v := v.(*ListQueuedOperationsResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -3066,7 +3364,7 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 			}
 		}
 		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TerminateWorkersRequest); i {
+			switch v := v.(*ListWorkersRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -3078,7 +3376,7 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 			}
 		}
 		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListDrainsRequest); i {
+			switch v := v.(*ListWorkersResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -3090,7 +3388,7 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 			}
 		}
 		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListDrainsResponse); i {
+			switch v := v.(*TerminateWorkersRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -3102,7 +3400,7 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 			}
 		}
 		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AddOrRemoveDrainRequest); i {
+			switch v := v.(*ListDrainsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -3114,6 +3412,30 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 			}
 		}
 		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListDrainsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddOrRemoveDrainRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*BackgroundLearning); i {
 			case 0:
 				return &v.state
@@ -3126,7 +3448,7 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 			}
 		}
 		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListOperationsRequest_StartAfter); i {
+			switch v := v.(*PredictedExecution); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -3138,6 +3460,30 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 			}
 		}
 		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AppliedPlatformProperties); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListOperationsRequest_StartAfter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*KillOperationsRequest_Filter); i {
 			case 0:
 				return &v.state
@@ -3149,7 +3495,7 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ListQueuedOperationsRequest_StartAfter); i {
 			case 0:
 				return &v.state
@@ -3161,7 +3507,7 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ListWorkersRequest_Filter); i {
 			case 0:
 				return &v.state
@@ -3173,7 +3519,7 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ListWorkersRequest_StartAfter); i {
 			case 0:
 				return &v.state
@@ -3191,11 +3537,11 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 		(*OperationState_Executing)(nil),
 		(*OperationState_Completed)(nil),
 	}
-	file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[31].OneofWrappers = []interface{}{
+	file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[35].OneofWrappers = []interface{}{
 		(*KillOperationsRequest_Filter_OperationName)(nil),
 		(*KillOperationsRequest_Filter_SizeClassQueueWithoutWorkers)(nil),
 	}
-	file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[33].OneofWrappers = []interface{}{
+	file_pkg_proto_buildqueuestate_buildqueuestate_proto_msgTypes[37].OneofWrappers = []interface{}{
 		(*ListWorkersRequest_Filter_All)(nil),
 		(*ListWorkersRequest_Filter_Executing)(nil),
 		(*ListWorkersRequest_Filter_IdleSynchronizing)(nil),
@@ -3206,9 +3552,9 @@ func file_pkg_proto_buildqueuestate_buildqueuestate_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_proto_buildqueuestate_buildqueuestate_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   38,
+			NumMessages:   42,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_pkg_proto_buildqueuestate_buildqueuestate_proto_goTypes,
 		DependencyIndexes: file_pkg_proto_buildqueuestate_buildqueuestate_proto_depIdxs,
@@ -3660,3 +4006,111 @@ var _BuildQueueState_serviceDesc = grpc.ServiceDesc{
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/proto/buildqueuestate/buildqueuestate.proto",
 }
+
+// QueuedOperationControlClient is the client API for QueuedOperationControl service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type QueuedOperationControlClient interface {
+	ReprioritizeOperation(ctx context.Context, in *ReprioritizeOperationRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RetagOperation(ctx context.Context, in *RetagOperationRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type queuedOperationControlClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewQueuedOperationControlClient(cc grpc.ClientConnInterface) QueuedOperationControlClient {
+	return &queuedOperationControlClient{cc}
+}
+
+func (c *queuedOperationControlClient) ReprioritizeOperation(ctx context.Context, in *ReprioritizeOperationRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/buildbarn.buildqueuestate.QueuedOperationControl/ReprioritizeOperation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queuedOperationControlClient) RetagOperation(ctx context.Context, in *RetagOperationRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/buildbarn.buildqueuestate.QueuedOperationControl/RetagOperation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// QueuedOperationControlServer is the server API for QueuedOperationControl service.
+type QueuedOperationControlServer interface {
+	ReprioritizeOperation(context.Context, *ReprioritizeOperationRequest) (*emptypb.Empty, error)
+	RetagOperation(context.Context, *RetagOperationRequest) (*emptypb.Empty, error)
+}
+
+// UnimplementedQueuedOperationControlServer can be embedded to have forward compatible implementations.
+type UnimplementedQueuedOperationControlServer struct {
+}
+
+func (*UnimplementedQueuedOperationControlServer) ReprioritizeOperation(context.Context, *ReprioritizeOperationRequest) (*emptypb.Empty, error) {
+	return nil, status1.Errorf(codes.Unimplemented, "method ReprioritizeOperation not implemented")
+}
+func (*UnimplementedQueuedOperationControlServer) RetagOperation(context.Context, *RetagOperationRequest) (*emptypb.Empty, error) {
+	return nil, status1.Errorf(codes.Unimplemented, "method RetagOperation not implemented")
+}
+
+func RegisterQueuedOperationControlServer(s grpc.ServiceRegistrar, srv QueuedOperationControlServer) {
+	s.RegisterService(&_QueuedOperationControl_serviceDesc, srv)
+}
+
+func _QueuedOperationControl_ReprioritizeOperation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReprioritizeOperationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueuedOperationControlServer).ReprioritizeOperation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/buildbarn.buildqueuestate.QueuedOperationControl/ReprioritizeOperation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueuedOperationControlServer).ReprioritizeOperation(ctx, req.(*ReprioritizeOperationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueuedOperationControl_RetagOperation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetagOperationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueuedOperationControlServer).RetagOperation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/buildbarn.buildqueuestate.QueuedOperationControl/RetagOperation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueuedOperationControlServer).RetagOperation(ctx, req.(*RetagOperationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _QueuedOperationControl_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "buildbarn.buildqueuestate.QueuedOperationControl",
+	HandlerType: (*QueuedOperationControlServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ReprioritizeOperation",
+			Handler:    _QueuedOperationControl_ReprioritizeOperation_Handler,
+		},
+		{
+			MethodName: "RetagOperation",
+			Handler:    _QueuedOperationControl_RetagOperation_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/proto/buildqueuestate/buildqueuestate.proto",
+}
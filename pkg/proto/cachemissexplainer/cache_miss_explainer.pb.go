@@ -0,0 +1,696 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.32.0
+// 	protoc        v4.23.4
+// source: pkg/proto/cachemissexplainer/cache_miss_explainer.proto
+
+package cachemissexplainer
+
+import (
+	context "context"
+	v2 "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Difference_Type int32
+
+const (
+	Difference_MODIFIED Difference_Type = 0
+	Difference_ADDED    Difference_Type = 1
+	Difference_REMOVED  Difference_Type = 2
+)
+
+// Enum value maps for Difference_Type.
+var (
+	Difference_Type_name = map[int32]string{
+		0: "MODIFIED",
+		1: "ADDED",
+		2: "REMOVED",
+	}
+	Difference_Type_value = map[string]int32{
+		"MODIFIED": 0,
+		"ADDED":    1,
+		"REMOVED":  2,
+	}
+)
+
+func (x Difference_Type) Enum() *Difference_Type {
+	p := new(Difference_Type)
+	*p = x
+	return p
+}
+
+func (x Difference_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Difference_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_enumTypes[0].Descriptor()
+}
+
+func (Difference_Type) Type() protoreflect.EnumType {
+	return &file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_enumTypes[0]
+}
+
+func (x Difference_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Difference_Type.Descriptor instead.
+func (Difference_Type) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_rawDescGZIP(), []int{3, 0}
+}
+
+type ActionFingerprint struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ActionDigest         *v2.Digest                        `protobuf:"bytes,1,opt,name=action_digest,json=actionDigest,proto3" json:"action_digest,omitempty"`
+	Arguments            []string                          `protobuf:"bytes,2,rep,name=arguments,proto3" json:"arguments,omitempty"`
+	EnvironmentVariables []*v2.Command_EnvironmentVariable `protobuf:"bytes,3,rep,name=environment_variables,json=environmentVariables,proto3" json:"environment_variables,omitempty"`
+	PlatformProperties   []*v2.Platform_Property           `protobuf:"bytes,4,rep,name=platform_properties,json=platformProperties,proto3" json:"platform_properties,omitempty"`
+	WorkingDirectory     string                            `protobuf:"bytes,5,opt,name=working_directory,json=workingDirectory,proto3" json:"working_directory,omitempty"`
+	OutputPaths          []string                          `protobuf:"bytes,6,rep,name=output_paths,json=outputPaths,proto3" json:"output_paths,omitempty"`
+	InputRootDigest      *v2.Digest                        `protobuf:"bytes,7,opt,name=input_root_digest,json=inputRootDigest,proto3" json:"input_root_digest,omitempty"`
+}
+
+func (x *ActionFingerprint) Reset() {
+	*x = ActionFingerprint{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ActionFingerprint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActionFingerprint) ProtoMessage() {}
+
+func (x *ActionFingerprint) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActionFingerprint.ProtoReflect.Descriptor instead.
+func (*ActionFingerprint) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ActionFingerprint) GetActionDigest() *v2.Digest {
+	if x != nil {
+		return x.ActionDigest
+	}
+	return nil
+}
+
+func (x *ActionFingerprint) GetArguments() []string {
+	if x != nil {
+		return x.Arguments
+	}
+	return nil
+}
+
+func (x *ActionFingerprint) GetEnvironmentVariables() []*v2.Command_EnvironmentVariable {
+	if x != nil {
+		return x.EnvironmentVariables
+	}
+	return nil
+}
+
+func (x *ActionFingerprint) GetPlatformProperties() []*v2.Platform_Property {
+	if x != nil {
+		return x.PlatformProperties
+	}
+	return nil
+}
+
+func (x *ActionFingerprint) GetWorkingDirectory() string {
+	if x != nil {
+		return x.WorkingDirectory
+	}
+	return ""
+}
+
+func (x *ActionFingerprint) GetOutputPaths() []string {
+	if x != nil {
+		return x.OutputPaths
+	}
+	return nil
+}
+
+func (x *ActionFingerprint) GetInputRootDigest() *v2.Digest {
+	if x != nil {
+		return x.InputRootDigest
+	}
+	return nil
+}
+
+type ExplainCacheMissRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	InstanceName    string                  `protobuf:"bytes,1,opt,name=instance_name,json=instanceName,proto3" json:"instance_name,omitempty"`
+	DigestFunction  v2.DigestFunction_Value `protobuf:"varint,2,opt,name=digest_function,json=digestFunction,proto3,enum=build.bazel.remote.execution.v2.DigestFunction_Value" json:"digest_function,omitempty"`
+	NewActionDigest *v2.Digest              `protobuf:"bytes,3,opt,name=new_action_digest,json=newActionDigest,proto3" json:"new_action_digest,omitempty"`
+	OldActionDigest *v2.Digest              `protobuf:"bytes,4,opt,name=old_action_digest,json=oldActionDigest,proto3" json:"old_action_digest,omitempty"`
+}
+
+func (x *ExplainCacheMissRequest) Reset() {
+	*x = ExplainCacheMissRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExplainCacheMissRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainCacheMissRequest) ProtoMessage() {}
+
+func (x *ExplainCacheMissRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainCacheMissRequest.ProtoReflect.Descriptor instead.
+func (*ExplainCacheMissRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ExplainCacheMissRequest) GetInstanceName() string {
+	if x != nil {
+		return x.InstanceName
+	}
+	return ""
+}
+
+func (x *ExplainCacheMissRequest) GetDigestFunction() v2.DigestFunction_Value {
+	if x != nil {
+		return x.DigestFunction
+	}
+	return v2.DigestFunction_Value(0)
+}
+
+func (x *ExplainCacheMissRequest) GetNewActionDigest() *v2.Digest {
+	if x != nil {
+		return x.NewActionDigest
+	}
+	return nil
+}
+
+func (x *ExplainCacheMissRequest) GetOldActionDigest() *v2.Digest {
+	if x != nil {
+		return x.OldActionDigest
+	}
+	return nil
+}
+
+type ExplainCacheMissResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	OldActionDigest               *v2.Digest    `protobuf:"bytes,1,opt,name=old_action_digest,json=oldActionDigest,proto3" json:"old_action_digest,omitempty"`
+	Differences                   []*Difference `protobuf:"bytes,2,rep,name=differences,proto3" json:"differences,omitempty"`
+	InputFileDifferencesTruncated bool          `protobuf:"varint,3,opt,name=input_file_differences_truncated,json=inputFileDifferencesTruncated,proto3" json:"input_file_differences_truncated,omitempty"`
+}
+
+func (x *ExplainCacheMissResponse) Reset() {
+	*x = ExplainCacheMissResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExplainCacheMissResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainCacheMissResponse) ProtoMessage() {}
+
+func (x *ExplainCacheMissResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainCacheMissResponse.ProtoReflect.Descriptor instead.
+func (*ExplainCacheMissResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ExplainCacheMissResponse) GetOldActionDigest() *v2.Digest {
+	if x != nil {
+		return x.OldActionDigest
+	}
+	return nil
+}
+
+func (x *ExplainCacheMissResponse) GetDifferences() []*Difference {
+	if x != nil {
+		return x.Differences
+	}
+	return nil
+}
+
+func (x *ExplainCacheMissResponse) GetInputFileDifferencesTruncated() bool {
+	if x != nil {
+		return x.InputFileDifferencesTruncated
+	}
+	return false
+}
+
+type Difference struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type     Difference_Type `protobuf:"varint,1,opt,name=type,proto3,enum=buildbarn.cachemissexplainer.Difference_Type" json:"type,omitempty"`
+	Field    string          `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`
+	Key      string          `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	OldValue string          `protobuf:"bytes,4,opt,name=old_value,json=oldValue,proto3" json:"old_value,omitempty"`
+	NewValue string          `protobuf:"bytes,5,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"`
+}
+
+func (x *Difference) Reset() {
+	*x = Difference{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Difference) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Difference) ProtoMessage() {}
+
+func (x *Difference) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Difference.ProtoReflect.Descriptor instead.
+func (*Difference) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Difference) GetType() Difference_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Difference_MODIFIED
+}
+
+func (x *Difference) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Difference) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Difference) GetOldValue() string {
+	if x != nil {
+		return x.OldValue
+	}
+	return ""
+}
+
+func (x *Difference) GetNewValue() string {
+	if x != nil {
+		return x.NewValue
+	}
+	return ""
+}
+
+var File_pkg_proto_cachemissexplainer_cache_miss_explainer_proto protoreflect.FileDescriptor
+
+var file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_rawDesc = []byte{
+	0x0a, 0x37, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x61, 0x63, 0x68,
+	0x65, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x2f, 0x63,
+	0x61, 0x63, 0x68, 0x65, 0x5f, 0x6d, 0x69, 0x73, 0x73, 0x5f, 0x65, 0x78, 0x70, 0x6c, 0x61, 0x69,
+	0x6e, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x1c, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x61, 0x63, 0x68, 0x65, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x78,
+	0x70, 0x6c, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x1a, 0x36, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2f, 0x62,
+	0x61, 0x7a, 0x65, 0x6c, 0x2f, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2f, 0x65, 0x78, 0x65, 0x63,
+	0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x76, 0x32, 0x2f, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f,
+	0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
+	0xfc, 0x03, 0x0a, 0x11, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72,
+	0x70, 0x72, 0x69, 0x6e, 0x74, 0x12, 0x4c, 0x0a, 0x0d, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
+	0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x62,
+	0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x44,
+	0x69, 0x67, 0x65, 0x73, 0x74, 0x52, 0x0c, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x69, 0x67,
+	0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x72, 0x67, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x61, 0x72, 0x67, 0x75, 0x6d, 0x65, 0x6e, 0x74,
+	0x73, 0x12, 0x71, 0x0a, 0x15, 0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74,
+	0x5f, 0x76, 0x61, 0x72, 0x69, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x3c, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x76, 0x32, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x45, 0x6e, 0x76, 0x69, 0x72,
+	0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x56, 0x61, 0x72, 0x69, 0x61, 0x62, 0x6c, 0x65, 0x52, 0x14,
+	0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x56, 0x61, 0x72, 0x69, 0x61,
+	0x62, 0x6c, 0x65, 0x73, 0x12, 0x63, 0x0a, 0x13, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d,
+	0x5f, 0x70, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x69, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x32, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e,
+	0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x76, 0x32, 0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x2e, 0x50, 0x72, 0x6f,
+	0x70, 0x65, 0x72, 0x74, 0x79, 0x52, 0x12, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x50,
+	0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x69, 0x65, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x77, 0x6f, 0x72,
+	0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x77, 0x6f, 0x72, 0x6b, 0x69, 0x6e, 0x67, 0x44, 0x69, 0x72,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74,
+	0x5f, 0x70, 0x61, 0x74, 0x68, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x6f, 0x75,
+	0x74, 0x70, 0x75, 0x74, 0x50, 0x61, 0x74, 0x68, 0x73, 0x12, 0x53, 0x0a, 0x11, 0x69, 0x6e, 0x70,
+	0x75, 0x74, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a,
+	0x65, 0x6c, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x52, 0x0f, 0x69,
+	0x6e, 0x70, 0x75, 0x74, 0x52, 0x6f, 0x6f, 0x74, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x22, 0xc8,
+	0x02, 0x0a, 0x17, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x4d,
+	0x69, 0x73, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x6e,
+	0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12,
+	0x5e, 0x0a, 0x0f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x5f, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x35, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78,
+	0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x44, 0x69, 0x67, 0x65, 0x73,
+	0x74, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52,
+	0x0e, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x53, 0x0a, 0x11, 0x6e, 0x65, 0x77, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x69,
+	0x67, 0x65, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x62, 0x75, 0x69,
+	0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e,
+	0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x44, 0x69, 0x67,
+	0x65, 0x73, 0x74, 0x52, 0x0f, 0x6e, 0x65, 0x77, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x69,
+	0x67, 0x65, 0x73, 0x74, 0x12, 0x53, 0x0a, 0x11, 0x6f, 0x6c, 0x64, 0x5f, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x27, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e, 0x72, 0x65,
+	0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76,
+	0x32, 0x2e, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x52, 0x0f, 0x6f, 0x6c, 0x64, 0x41, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x22, 0x84, 0x02, 0x0a, 0x18, 0x45, 0x78,
+	0x70, 0x6c, 0x61, 0x69, 0x6e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x4d, 0x69, 0x73, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x53, 0x0a, 0x11, 0x6f, 0x6c, 0x64, 0x5f, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x27, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e,
+	0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x76, 0x32, 0x2e, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x52, 0x0f, 0x6f, 0x6c, 0x64, 0x41,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x4a, 0x0a, 0x0b, 0x64,
+	0x69, 0x66, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x28, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x61, 0x63,
+	0x68, 0x65, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x2e,
+	0x44, 0x69, 0x66, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x0b, 0x64, 0x69, 0x66, 0x66,
+	0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x12, 0x47, 0x0a, 0x20, 0x69, 0x6e, 0x70, 0x75, 0x74,
+	0x5f, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x64, 0x69, 0x66, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65,
+	0x73, 0x5f, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x1d, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x44, 0x69, 0x66, 0x66,
+	0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x54, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64,
+	0x22, 0xdf, 0x01, 0x0a, 0x0a, 0x44, 0x69, 0x66, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12,
+	0x41, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2d, 0x2e,
+	0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x61, 0x63, 0x68, 0x65, 0x6d,
+	0x69, 0x73, 0x73, 0x65, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x2e, 0x44, 0x69, 0x66,
+	0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x6f, 0x6c,
+	0x64, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6f,
+	0x6c, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x65, 0x77, 0x5f, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6e, 0x65, 0x77, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x22, 0x2c, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0c, 0x0a, 0x08,
+	0x4d, 0x4f, 0x44, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x41, 0x44,
+	0x44, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x52, 0x45, 0x4d, 0x4f, 0x56, 0x45, 0x44,
+	0x10, 0x02, 0x32, 0x98, 0x01, 0x0a, 0x12, 0x43, 0x61, 0x63, 0x68, 0x65, 0x4d, 0x69, 0x73, 0x73,
+	0x45, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x12, 0x81, 0x01, 0x0a, 0x10, 0x45, 0x78,
+	0x70, 0x6c, 0x61, 0x69, 0x6e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x4d, 0x69, 0x73, 0x73, 0x12, 0x35,
+	0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x61, 0x63, 0x68, 0x65,
+	0x6d, 0x69, 0x73, 0x73, 0x65, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x2e, 0x45, 0x78,
+	0x70, 0x6c, 0x61, 0x69, 0x6e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x4d, 0x69, 0x73, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x36, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72,
+	0x6e, 0x2e, 0x63, 0x61, 0x63, 0x68, 0x65, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x78, 0x70, 0x6c, 0x61,
+	0x69, 0x6e, 0x65, 0x72, 0x2e, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x43, 0x61, 0x63, 0x68,
+	0x65, 0x4d, 0x69, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x47, 0x5a,
+	0x45, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x62, 0x75, 0x69, 0x6c,
+	0x64, 0x62, 0x61, 0x72, 0x6e, 0x2f, 0x62, 0x62, 0x2d, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2d,
+	0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x61, 0x63, 0x68, 0x65, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x78, 0x70,
+	0x6c, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_rawDescOnce sync.Once
+	file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_rawDescData = file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_rawDesc
+)
+
+func file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_rawDescGZIP() []byte {
+	file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_rawDescOnce.Do(func() {
+		file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_rawDescData)
+	})
+	return file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_rawDescData
+}
+
+var file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_goTypes = []interface{}{
+	(Difference_Type)(0),                   // 0: buildbarn.cachemissexplainer.Difference.Type
+	(*ActionFingerprint)(nil),              // 1: buildbarn.cachemissexplainer.ActionFingerprint
+	(*ExplainCacheMissRequest)(nil),        // 2: buildbarn.cachemissexplainer.ExplainCacheMissRequest
+	(*ExplainCacheMissResponse)(nil),       // 3: buildbarn.cachemissexplainer.ExplainCacheMissResponse
+	(*Difference)(nil),                     // 4: buildbarn.cachemissexplainer.Difference
+	(*v2.Digest)(nil),                      // 5: build.bazel.remote.execution.v2.Digest
+	(*v2.Command_EnvironmentVariable)(nil), // 6: build.bazel.remote.execution.v2.Command.EnvironmentVariable
+	(*v2.Platform_Property)(nil),           // 7: build.bazel.remote.execution.v2.Platform.Property
+	(v2.DigestFunction_Value)(0),           // 8: build.bazel.remote.execution.v2.DigestFunction.Value
+}
+var file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_depIdxs = []int32{
+	5,  // 0: buildbarn.cachemissexplainer.ActionFingerprint.action_digest:type_name -> build.bazel.remote.execution.v2.Digest
+	6,  // 1: buildbarn.cachemissexplainer.ActionFingerprint.environment_variables:type_name -> build.bazel.remote.execution.v2.Command.EnvironmentVariable
+	7,  // 2: buildbarn.cachemissexplainer.ActionFingerprint.platform_properties:type_name -> build.bazel.remote.execution.v2.Platform.Property
+	5,  // 3: buildbarn.cachemissexplainer.ActionFingerprint.input_root_digest:type_name -> build.bazel.remote.execution.v2.Digest
+	8,  // 4: buildbarn.cachemissexplainer.ExplainCacheMissRequest.digest_function:type_name -> build.bazel.remote.execution.v2.DigestFunction.Value
+	5,  // 5: buildbarn.cachemissexplainer.ExplainCacheMissRequest.new_action_digest:type_name -> build.bazel.remote.execution.v2.Digest
+	5,  // 6: buildbarn.cachemissexplainer.ExplainCacheMissRequest.old_action_digest:type_name -> build.bazel.remote.execution.v2.Digest
+	5,  // 7: buildbarn.cachemissexplainer.ExplainCacheMissResponse.old_action_digest:type_name -> build.bazel.remote.execution.v2.Digest
+	4,  // 8: buildbarn.cachemissexplainer.ExplainCacheMissResponse.differences:type_name -> buildbarn.cachemissexplainer.Difference
+	0,  // 9: buildbarn.cachemissexplainer.Difference.type:type_name -> buildbarn.cachemissexplainer.Difference.Type
+	2,  // 10: buildbarn.cachemissexplainer.CacheMissExplainer.ExplainCacheMiss:input_type -> buildbarn.cachemissexplainer.ExplainCacheMissRequest
+	3,  // 11: buildbarn.cachemissexplainer.CacheMissExplainer.ExplainCacheMiss:output_type -> buildbarn.cachemissexplainer.ExplainCacheMissResponse
+	11, // [11:12] is the sub-list for method output_type
+	10, // [10:11] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_init() }
+func file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_init() {
+	if File_pkg_proto_cachemissexplainer_cache_miss_explainer_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ActionFingerprint); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExplainCacheMissRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExplainCacheMissResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Difference); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_goTypes,
+		DependencyIndexes: file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_depIdxs,
+		EnumInfos:         file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_enumTypes,
+		MessageInfos:      file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_msgTypes,
+	}.Build()
+	File_pkg_proto_cachemissexplainer_cache_miss_explainer_proto = out.File
+	file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_rawDesc = nil
+	file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_goTypes = nil
+	file_pkg_proto_cachemissexplainer_cache_miss_explainer_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// CacheMissExplainerClient is the client API for CacheMissExplainer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type CacheMissExplainerClient interface {
+	ExplainCacheMiss(ctx context.Context, in *ExplainCacheMissRequest, opts ...grpc.CallOption) (*ExplainCacheMissResponse, error)
+}
+
+type cacheMissExplainerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCacheMissExplainerClient(cc grpc.ClientConnInterface) CacheMissExplainerClient {
+	return &cacheMissExplainerClient{cc}
+}
+
+func (c *cacheMissExplainerClient) ExplainCacheMiss(ctx context.Context, in *ExplainCacheMissRequest, opts ...grpc.CallOption) (*ExplainCacheMissResponse, error) {
+	out := new(ExplainCacheMissResponse)
+	err := c.cc.Invoke(ctx, "/buildbarn.cachemissexplainer.CacheMissExplainer/ExplainCacheMiss", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CacheMissExplainerServer is the server API for CacheMissExplainer service.
+type CacheMissExplainerServer interface {
+	ExplainCacheMiss(context.Context, *ExplainCacheMissRequest) (*ExplainCacheMissResponse, error)
+}
+
+// UnimplementedCacheMissExplainerServer can be embedded to have forward compatible implementations.
+type UnimplementedCacheMissExplainerServer struct {
+}
+
+func (*UnimplementedCacheMissExplainerServer) ExplainCacheMiss(context.Context, *ExplainCacheMissRequest) (*ExplainCacheMissResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExplainCacheMiss not implemented")
+}
+
+func RegisterCacheMissExplainerServer(s grpc.ServiceRegistrar, srv CacheMissExplainerServer) {
+	s.RegisterService(&_CacheMissExplainer_serviceDesc, srv)
+}
+
+func _CacheMissExplainer_ExplainCacheMiss_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExplainCacheMissRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheMissExplainerServer).ExplainCacheMiss(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/buildbarn.cachemissexplainer.CacheMissExplainer/ExplainCacheMiss",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheMissExplainerServer).ExplainCacheMiss(ctx, req.(*ExplainCacheMissRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _CacheMissExplainer_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "buildbarn.cachemissexplainer.CacheMissExplainer",
+	HandlerType: (*CacheMissExplainerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ExplainCacheMiss",
+			Handler:    _CacheMissExplainer_ExplainCacheMiss_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/proto/cachemissexplainer/cache_miss_explainer.proto",
+}
@@ -76,6 +76,187 @@ func (x *HistoricalExecuteResponse) GetExecuteResponse() *v2.ExecuteResponse {
 	return nil
 }
 
+type ExcludedPlatformProperties struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ActionDigest *v2.Digest              `protobuf:"bytes,1,opt,name=action_digest,json=actionDigest,proto3" json:"action_digest,omitempty"`
+	Properties   []*v2.Platform_Property `protobuf:"bytes,2,rep,name=properties,proto3" json:"properties,omitempty"`
+}
+
+func (x *ExcludedPlatformProperties) Reset() {
+	*x = ExcludedPlatformProperties{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_cas_cas_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExcludedPlatformProperties) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExcludedPlatformProperties) ProtoMessage() {}
+
+func (x *ExcludedPlatformProperties) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_cas_cas_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExcludedPlatformProperties.ProtoReflect.Descriptor instead.
+func (*ExcludedPlatformProperties) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_cas_cas_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ExcludedPlatformProperties) GetActionDigest() *v2.Digest {
+	if x != nil {
+		return x.ActionDigest
+	}
+	return nil
+}
+
+func (x *ExcludedPlatformProperties) GetProperties() []*v2.Platform_Property {
+	if x != nil {
+		return x.Properties
+	}
+	return nil
+}
+
+type PackedFileCacheIndex struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PackSizeBytes    int64                        `protobuf:"varint,1,opt,name=pack_size_bytes,json=packSizeBytes,proto3" json:"pack_size_bytes,omitempty"`
+	WriteOffsetBytes int64                        `protobuf:"varint,2,opt,name=write_offset_bytes,json=writeOffsetBytes,proto3" json:"write_offset_bytes,omitempty"`
+	Files            []*PackedFileCacheIndex_File `protobuf:"bytes,3,rep,name=files,proto3" json:"files,omitempty"`
+}
+
+func (x *PackedFileCacheIndex) Reset() {
+	*x = PackedFileCacheIndex{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_cas_cas_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PackedFileCacheIndex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PackedFileCacheIndex) ProtoMessage() {}
+
+func (x *PackedFileCacheIndex) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_cas_cas_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PackedFileCacheIndex.ProtoReflect.Descriptor instead.
+func (*PackedFileCacheIndex) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_cas_cas_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PackedFileCacheIndex) GetPackSizeBytes() int64 {
+	if x != nil {
+		return x.PackSizeBytes
+	}
+	return 0
+}
+
+func (x *PackedFileCacheIndex) GetWriteOffsetBytes() int64 {
+	if x != nil {
+		return x.WriteOffsetBytes
+	}
+	return 0
+}
+
+func (x *PackedFileCacheIndex) GetFiles() []*PackedFileCacheIndex_File {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type PackedFileCacheIndex_File struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DigestFunction v2.DigestFunction_Value `protobuf:"varint,1,opt,name=digest_function,json=digestFunction,proto3,enum=build.bazel.remote.execution.v2.DigestFunction_Value" json:"digest_function,omitempty"`
+	Digest         *v2.Digest              `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	OffsetBytes    int64                   `protobuf:"varint,3,opt,name=offset_bytes,json=offsetBytes,proto3" json:"offset_bytes,omitempty"`
+}
+
+func (x *PackedFileCacheIndex_File) Reset() {
+	*x = PackedFileCacheIndex_File{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_cas_cas_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PackedFileCacheIndex_File) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PackedFileCacheIndex_File) ProtoMessage() {}
+
+func (x *PackedFileCacheIndex_File) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_cas_cas_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PackedFileCacheIndex_File.ProtoReflect.Descriptor instead.
+func (*PackedFileCacheIndex_File) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_cas_cas_proto_rawDescGZIP(), []int{2, 0}
+}
+
+func (x *PackedFileCacheIndex_File) GetDigestFunction() v2.DigestFunction_Value {
+	if x != nil {
+		return x.DigestFunction
+	}
+	return v2.DigestFunction_Value(0)
+}
+
+func (x *PackedFileCacheIndex_File) GetDigest() *v2.Digest {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *PackedFileCacheIndex_File) GetOffsetBytes() int64 {
+	if x != nil {
+		return x.OffsetBytes
+	}
+	return 0
+}
+
 var File_pkg_proto_cas_cas_proto protoreflect.FileDescriptor
 
 var file_pkg_proto_cas_cas_proto_rawDesc = []byte{
@@ -97,12 +278,47 @@ var file_pkg_proto_cas_cas_proto_rawDesc = []byte{
 	0x61, 0x7a, 0x65, 0x6c, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78, 0x65, 0x63,
 	0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65,
 	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x0f, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74,
-	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x42,
-	0x38, 0x5a, 0x36, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x62, 0x75,
-	0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2f, 0x62, 0x62, 0x2d, 0x72, 0x65, 0x6d, 0x6f, 0x74,
-	0x65, 0x2d, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x70, 0x6b, 0x67, 0x2f,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x61, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x33,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x22,
+	0xbe, 0x01, 0x0a, 0x1a, 0x45, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x50, 0x6c, 0x61, 0x74,
+	0x66, 0x6f, 0x72, 0x6d, 0x50, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x69, 0x65, 0x73, 0x12, 0x4c,
+	0x0a, 0x0d, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61,
+	0x7a, 0x65, 0x6c, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x52, 0x0c,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x52, 0x0a, 0x0a,
+	0x70, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x32, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x76, 0x32, 0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x2e, 0x50, 0x72, 0x6f, 0x70,
+	0x65, 0x72, 0x74, 0x79, 0x52, 0x0a, 0x70, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x69, 0x65, 0x73,
+	0x22, 0xf9, 0x02, 0x0a, 0x14, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x43,
+	0x61, 0x63, 0x68, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x26, 0x0a, 0x0f, 0x70, 0x61, 0x63,
+	0x6b, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0d, 0x70, 0x61, 0x63, 0x6b, 0x53, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x12, 0x2c, 0x0a, 0x12, 0x77, 0x72, 0x69, 0x74, 0x65, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65,
+	0x74, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x10, 0x77,
+	0x72, 0x69, 0x74, 0x65, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12,
+	0x3e, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28,
+	0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x61, 0x73, 0x2e, 0x50,
+	0x61, 0x63, 0x6b, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x43, 0x61, 0x63, 0x68, 0x65, 0x49, 0x6e,
+	0x64, 0x65, 0x78, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x1a,
+	0xca, 0x01, 0x0a, 0x04, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x5e, 0x0a, 0x0f, 0x64, 0x69, 0x67, 0x65,
+	0x73, 0x74, 0x5f, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x35, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e,
+	0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x76, 0x32, 0x2e, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x0e, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74,
+	0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3f, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65,
+	0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78,
+	0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x44, 0x69, 0x67, 0x65, 0x73,
+	0x74, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x66, 0x66,
+	0x73, 0x65, 0x74, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0b, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x42, 0x79, 0x74, 0x65, 0x73, 0x42, 0x38, 0x5a, 0x36,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x62, 0x61, 0x72, 0x6e, 0x2f, 0x62, 0x62, 0x2d, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2d, 0x65,
+	0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2f, 0x63, 0x61, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -117,20 +333,30 @@ func file_pkg_proto_cas_cas_proto_rawDescGZIP() []byte {
 	return file_pkg_proto_cas_cas_proto_rawDescData
 }
 
-var file_pkg_proto_cas_cas_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_pkg_proto_cas_cas_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_pkg_proto_cas_cas_proto_goTypes = []interface{}{
-	(*HistoricalExecuteResponse)(nil), // 0: buildbarn.cas.HistoricalExecuteResponse
-	(*v2.Digest)(nil),                 // 1: build.bazel.remote.execution.v2.Digest
-	(*v2.ExecuteResponse)(nil),        // 2: build.bazel.remote.execution.v2.ExecuteResponse
+	(*HistoricalExecuteResponse)(nil),  // 0: buildbarn.cas.HistoricalExecuteResponse
+	(*ExcludedPlatformProperties)(nil), // 1: buildbarn.cas.ExcludedPlatformProperties
+	(*PackedFileCacheIndex)(nil),       // 2: buildbarn.cas.PackedFileCacheIndex
+	(*PackedFileCacheIndex_File)(nil),  // 3: buildbarn.cas.PackedFileCacheIndex.File
+	(*v2.Digest)(nil),                  // 4: build.bazel.remote.execution.v2.Digest
+	(*v2.ExecuteResponse)(nil),         // 5: build.bazel.remote.execution.v2.ExecuteResponse
+	(*v2.Platform_Property)(nil),       // 6: build.bazel.remote.execution.v2.Platform.Property
+	(v2.DigestFunction_Value)(0),       // 7: build.bazel.remote.execution.v2.DigestFunction.Value
 }
 var file_pkg_proto_cas_cas_proto_depIdxs = []int32{
-	1, // 0: buildbarn.cas.HistoricalExecuteResponse.action_digest:type_name -> build.bazel.remote.execution.v2.Digest
-	2, // 1: buildbarn.cas.HistoricalExecuteResponse.execute_response:type_name -> build.bazel.remote.execution.v2.ExecuteResponse
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	4, // 0: buildbarn.cas.HistoricalExecuteResponse.action_digest:type_name -> build.bazel.remote.execution.v2.Digest
+	5, // 1: buildbarn.cas.HistoricalExecuteResponse.execute_response:type_name -> build.bazel.remote.execution.v2.ExecuteResponse
+	4, // 2: buildbarn.cas.ExcludedPlatformProperties.action_digest:type_name -> build.bazel.remote.execution.v2.Digest
+	6, // 3: buildbarn.cas.ExcludedPlatformProperties.properties:type_name -> build.bazel.remote.execution.v2.Platform.Property
+	3, // 4: buildbarn.cas.PackedFileCacheIndex.files:type_name -> buildbarn.cas.PackedFileCacheIndex.File
+	7, // 5: buildbarn.cas.PackedFileCacheIndex.File.digest_function:type_name -> build.bazel.remote.execution.v2.DigestFunction.Value
+	4, // 6: buildbarn.cas.PackedFileCacheIndex.File.digest:type_name -> build.bazel.remote.execution.v2.Digest
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_pkg_proto_cas_cas_proto_init() }
@@ -151,6 +377,42 @@ func file_pkg_proto_cas_cas_proto_init() {
 				return nil
 			}
 		}
+		file_pkg_proto_cas_cas_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExcludedPlatformProperties); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_cas_cas_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PackedFileCacheIndex); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_cas_cas_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PackedFileCacheIndex_File); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -158,7 +420,7 @@ func file_pkg_proto_cas_cas_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_proto_cas_cas_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
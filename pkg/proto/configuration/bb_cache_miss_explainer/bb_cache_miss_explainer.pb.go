@@ -0,0 +1,275 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.32.0
+// 	protoc        v4.23.4
+// source: pkg/proto/configuration/bb_cache_miss_explainer/bb_cache_miss_explainer.proto
+
+package bb_cache_miss_explainer
+
+import (
+	blobstore "github.com/buildbarn/bb-storage/pkg/proto/configuration/blobstore"
+	eviction "github.com/buildbarn/bb-storage/pkg/proto/configuration/eviction"
+	global "github.com/buildbarn/bb-storage/pkg/proto/configuration/global"
+	grpc "github.com/buildbarn/bb-storage/pkg/proto/configuration/grpc"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ApplicationConfiguration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Global                       *global.Configuration              `protobuf:"bytes,1,opt,name=global,proto3" json:"global,omitempty"`
+	GrpcServers                  []*grpc.ServerConfiguration        `protobuf:"bytes,2,rep,name=grpc_servers,json=grpcServers,proto3" json:"grpc_servers,omitempty"`
+	ContentAddressableStorage    *blobstore.BlobAccessConfiguration `protobuf:"bytes,3,opt,name=content_addressable_storage,json=contentAddressableStorage,proto3" json:"content_addressable_storage,omitempty"`
+	MaximumMessageSizeBytes      int64                              `protobuf:"varint,4,opt,name=maximum_message_size_bytes,json=maximumMessageSizeBytes,proto3" json:"maximum_message_size_bytes,omitempty"`
+	MaximumFingerprints          int32                              `protobuf:"varint,5,opt,name=maximum_fingerprints,json=maximumFingerprints,proto3" json:"maximum_fingerprints,omitempty"`
+	FingerprintReplacementPolicy eviction.CacheReplacementPolicy    `protobuf:"varint,6,opt,name=fingerprint_replacement_policy,json=fingerprintReplacementPolicy,proto3,enum=buildbarn.configuration.eviction.CacheReplacementPolicy" json:"fingerprint_replacement_policy,omitempty"`
+	MaximumInputFileDifferences  int32                              `protobuf:"varint,7,opt,name=maximum_input_file_differences,json=maximumInputFileDifferences,proto3" json:"maximum_input_file_differences,omitempty"`
+	FingerprintingConcurrency    int64                              `protobuf:"varint,8,opt,name=fingerprinting_concurrency,json=fingerprintingConcurrency,proto3" json:"fingerprinting_concurrency,omitempty"`
+}
+
+func (x *ApplicationConfiguration) Reset() {
+	*x = ApplicationConfiguration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApplicationConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplicationConfiguration) ProtoMessage() {}
+
+func (x *ApplicationConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplicationConfiguration.ProtoReflect.Descriptor instead.
+func (*ApplicationConfiguration) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ApplicationConfiguration) GetGlobal() *global.Configuration {
+	if x != nil {
+		return x.Global
+	}
+	return nil
+}
+
+func (x *ApplicationConfiguration) GetGrpcServers() []*grpc.ServerConfiguration {
+	if x != nil {
+		return x.GrpcServers
+	}
+	return nil
+}
+
+func (x *ApplicationConfiguration) GetContentAddressableStorage() *blobstore.BlobAccessConfiguration {
+	if x != nil {
+		return x.ContentAddressableStorage
+	}
+	return nil
+}
+
+func (x *ApplicationConfiguration) GetMaximumMessageSizeBytes() int64 {
+	if x != nil {
+		return x.MaximumMessageSizeBytes
+	}
+	return 0
+}
+
+func (x *ApplicationConfiguration) GetMaximumFingerprints() int32 {
+	if x != nil {
+		return x.MaximumFingerprints
+	}
+	return 0
+}
+
+func (x *ApplicationConfiguration) GetFingerprintReplacementPolicy() eviction.CacheReplacementPolicy {
+	if x != nil {
+		return x.FingerprintReplacementPolicy
+	}
+	return eviction.CacheReplacementPolicy(0)
+}
+
+func (x *ApplicationConfiguration) GetMaximumInputFileDifferences() int32 {
+	if x != nil {
+		return x.MaximumInputFileDifferences
+	}
+	return 0
+}
+
+func (x *ApplicationConfiguration) GetFingerprintingConcurrency() int64 {
+	if x != nil {
+		return x.FingerprintingConcurrency
+	}
+	return 0
+}
+
+var File_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto protoreflect.FileDescriptor
+
+var file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_rawDesc = []byte{
+	0x0a, 0x4d, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x62, 0x62, 0x5f, 0x63, 0x61, 0x63,
+	0x68, 0x65, 0x5f, 0x6d, 0x69, 0x73, 0x73, 0x5f, 0x65, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x65,
+	0x72, 0x2f, 0x62, 0x62, 0x5f, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x6d, 0x69, 0x73, 0x73, 0x5f,
+	0x65, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x2f, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x62, 0x62, 0x5f, 0x63, 0x61, 0x63, 0x68,
+	0x65, 0x5f, 0x6d, 0x69, 0x73, 0x73, 0x5f, 0x65, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x65, 0x72,
+	0x1a, 0x31, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x62, 0x6c, 0x6f, 0x62, 0x73, 0x74,
+	0x6f, 0x72, 0x65, 0x2f, 0x62, 0x6c, 0x6f, 0x62, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x1a, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x65, 0x76, 0x69,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x65, 0x76, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x2b, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x67, 0x6c,
+	0x6f, 0x62, 0x61, 0x6c, 0x2f, 0x67, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x1a, 0x27, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f,
+	0x67, 0x72, 0x70, 0x63, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xa7, 0x05, 0x0a, 0x18, 0x41,
+	0x70, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x45, 0x0a, 0x06, 0x67, 0x6c, 0x6f, 0x62, 0x61,
+	0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62,
+	0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x2e, 0x67, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x67, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x12, 0x54,
+	0x0a, 0x0c, 0x67, 0x72, 0x70, 0x63, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x31, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e,
+	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x67,
+	0x72, 0x70, 0x63, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x67, 0x72, 0x70, 0x63, 0x53, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x73, 0x12, 0x7a, 0x0a, 0x1b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f,
+	0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x73, 0x74, 0x6f, 0x72,
+	0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x3a, 0x2e, 0x62, 0x75, 0x69, 0x6c,
+	0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x62, 0x6c, 0x6f, 0x62, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x42, 0x6c,
+	0x6f, 0x62, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x19, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x41, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65,
+	0x12, 0x3b, 0x0a, 0x1a, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x17, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x31, 0x0a,
+	0x14, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70,
+	0x72, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x13, 0x6d, 0x61, 0x78,
+	0x69, 0x6d, 0x75, 0x6d, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x73,
+	0x12, 0x7e, 0x0a, 0x1e, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x5f,
+	0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x70, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x38, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x65, 0x76, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x43, 0x61, 0x63, 0x68,
+	0x65, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x52, 0x1c, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x52,
+	0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x12, 0x43, 0x0a, 0x1e, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x69, 0x6e, 0x70, 0x75,
+	0x74, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x64, 0x69, 0x66, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63,
+	0x65, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x1b, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75,
+	0x6d, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x44, 0x69, 0x66, 0x66, 0x65, 0x72,
+	0x65, 0x6e, 0x63, 0x65, 0x73, 0x12, 0x3d, 0x0a, 0x1a, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70,
+	0x72, 0x69, 0x6e, 0x74, 0x69, 0x6e, 0x67, 0x5f, 0x63, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65,
+	0x6e, 0x63, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x19, 0x66, 0x69, 0x6e, 0x67, 0x65,
+	0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72,
+	0x65, 0x6e, 0x63, 0x79, 0x42, 0x5a, 0x5a, 0x58, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2f, 0x62, 0x62, 0x2d,
+	0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2d, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x62, 0x62, 0x5f, 0x63, 0x61, 0x63, 0x68,
+	0x65, 0x5f, 0x6d, 0x69, 0x73, 0x73, 0x5f, 0x65, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x65, 0x72,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_rawDescOnce sync.Once
+	file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_rawDescData = file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_rawDesc
+)
+
+func file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_rawDescGZIP() []byte {
+	file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_rawDescOnce.Do(func() {
+		file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_rawDescData)
+	})
+	return file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_rawDescData
+}
+
+var file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_goTypes = []interface{}{
+	(*ApplicationConfiguration)(nil),          // 0: buildbarn.configuration.bb_cache_miss_explainer.ApplicationConfiguration
+	(*global.Configuration)(nil),              // 1: buildbarn.configuration.global.Configuration
+	(*grpc.ServerConfiguration)(nil),          // 2: buildbarn.configuration.grpc.ServerConfiguration
+	(*blobstore.BlobAccessConfiguration)(nil), // 3: buildbarn.configuration.blobstore.BlobAccessConfiguration
+	(eviction.CacheReplacementPolicy)(0),      // 4: buildbarn.configuration.eviction.CacheReplacementPolicy
+}
+var file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_depIdxs = []int32{
+	1, // 0: buildbarn.configuration.bb_cache_miss_explainer.ApplicationConfiguration.global:type_name -> buildbarn.configuration.global.Configuration
+	2, // 1: buildbarn.configuration.bb_cache_miss_explainer.ApplicationConfiguration.grpc_servers:type_name -> buildbarn.configuration.grpc.ServerConfiguration
+	3, // 2: buildbarn.configuration.bb_cache_miss_explainer.ApplicationConfiguration.content_addressable_storage:type_name -> buildbarn.configuration.blobstore.BlobAccessConfiguration
+	4, // 3: buildbarn.configuration.bb_cache_miss_explainer.ApplicationConfiguration.fingerprint_replacement_policy:type_name -> buildbarn.configuration.eviction.CacheReplacementPolicy
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() {
+	file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_init()
+}
+func file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_init() {
+	if File_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ApplicationConfiguration); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_goTypes,
+		DependencyIndexes: file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_depIdxs,
+		MessageInfos:      file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_msgTypes,
+	}.Build()
+	File_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto = out.File
+	file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_rawDesc = nil
+	file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_goTypes = nil
+	file_pkg_proto_configuration_bb_cache_miss_explainer_bb_cache_miss_explainer_proto_depIdxs = nil
+}
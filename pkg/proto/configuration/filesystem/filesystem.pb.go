@@ -10,6 +10,7 @@ import (
 	blockdevice "github.com/buildbarn/bb-storage/pkg/proto/configuration/blockdevice"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
 	reflect "reflect"
 	sync "sync"
@@ -32,7 +33,11 @@ type FilePoolConfiguration struct {
 	//	*FilePoolConfiguration_InMemory
 	//	*FilePoolConfiguration_DirectoryPath
 	//	*FilePoolConfiguration_BlockDevice
-	Backend isFilePoolConfiguration_Backend `protobuf_oneof:"backend"`
+	Backend                isFilePoolConfiguration_Backend      `protobuf_oneof:"backend"`
+	TransientErrorRetry    *TransientErrorRetryConfiguration    `protobuf:"bytes,4,opt,name=transient_error_retry,json=transientErrorRetry,proto3" json:"transient_error_retry,omitempty"`
+	BlockDeviceCompression *BlockDeviceCompressionConfiguration `protobuf:"bytes,5,opt,name=block_device_compression,json=blockDeviceCompression,proto3" json:"block_device_compression,omitempty"`
+	Encryption             *EncryptionConfiguration             `protobuf:"bytes,6,opt,name=encryption,proto3" json:"encryption,omitempty"`
+	MemoryTierSizeBytes    int64                                `protobuf:"varint,7,opt,name=memory_tier_size_bytes,json=memoryTierSizeBytes,proto3" json:"memory_tier_size_bytes,omitempty"`
 }
 
 func (x *FilePoolConfiguration) Reset() {
@@ -95,6 +100,34 @@ func (x *FilePoolConfiguration) GetBlockDevice() *blockdevice.Configuration {
 	return nil
 }
 
+func (x *FilePoolConfiguration) GetTransientErrorRetry() *TransientErrorRetryConfiguration {
+	if x != nil {
+		return x.TransientErrorRetry
+	}
+	return nil
+}
+
+func (x *FilePoolConfiguration) GetBlockDeviceCompression() *BlockDeviceCompressionConfiguration {
+	if x != nil {
+		return x.BlockDeviceCompression
+	}
+	return nil
+}
+
+func (x *FilePoolConfiguration) GetEncryption() *EncryptionConfiguration {
+	if x != nil {
+		return x.Encryption
+	}
+	return nil
+}
+
+func (x *FilePoolConfiguration) GetMemoryTierSizeBytes() int64 {
+	if x != nil {
+		return x.MemoryTierSizeBytes
+	}
+	return 0
+}
+
 type isFilePoolConfiguration_Backend interface {
 	isFilePoolConfiguration_Backend()
 }
@@ -117,6 +150,213 @@ func (*FilePoolConfiguration_DirectoryPath) isFilePoolConfiguration_Backend() {}
 
 func (*FilePoolConfiguration_BlockDevice) isFilePoolConfiguration_Backend() {}
 
+type EncryptionConfiguration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Key:
+	//
+	//	*EncryptionConfiguration_KeyPath
+	//	*EncryptionConfiguration_EphemeralKey
+	Key isEncryptionConfiguration_Key `protobuf_oneof:"key"`
+}
+
+func (x *EncryptionConfiguration) Reset() {
+	*x = EncryptionConfiguration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_configuration_filesystem_filesystem_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EncryptionConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EncryptionConfiguration) ProtoMessage() {}
+
+func (x *EncryptionConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_configuration_filesystem_filesystem_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EncryptionConfiguration.ProtoReflect.Descriptor instead.
+func (*EncryptionConfiguration) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_configuration_filesystem_filesystem_proto_rawDescGZIP(), []int{1}
+}
+
+func (m *EncryptionConfiguration) GetKey() isEncryptionConfiguration_Key {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (x *EncryptionConfiguration) GetKeyPath() string {
+	if x, ok := x.GetKey().(*EncryptionConfiguration_KeyPath); ok {
+		return x.KeyPath
+	}
+	return ""
+}
+
+func (x *EncryptionConfiguration) GetEphemeralKey() *emptypb.Empty {
+	if x, ok := x.GetKey().(*EncryptionConfiguration_EphemeralKey); ok {
+		return x.EphemeralKey
+	}
+	return nil
+}
+
+type isEncryptionConfiguration_Key interface {
+	isEncryptionConfiguration_Key()
+}
+
+type EncryptionConfiguration_KeyPath struct {
+	KeyPath string `protobuf:"bytes,1,opt,name=key_path,json=keyPath,proto3,oneof"`
+}
+
+type EncryptionConfiguration_EphemeralKey struct {
+	EphemeralKey *emptypb.Empty `protobuf:"bytes,2,opt,name=ephemeral_key,json=ephemeralKey,proto3,oneof"`
+}
+
+func (*EncryptionConfiguration_KeyPath) isEncryptionConfiguration_Key() {}
+
+func (*EncryptionConfiguration_EphemeralKey) isEncryptionConfiguration_Key() {}
+
+type BlockDeviceCompressionConfiguration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ChunkSizeBytes uint32 `protobuf:"varint,1,opt,name=chunk_size_bytes,json=chunkSizeBytes,proto3" json:"chunk_size_bytes,omitempty"`
+	Level          int32  `protobuf:"varint,2,opt,name=level,proto3" json:"level,omitempty"`
+}
+
+func (x *BlockDeviceCompressionConfiguration) Reset() {
+	*x = BlockDeviceCompressionConfiguration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_configuration_filesystem_filesystem_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlockDeviceCompressionConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockDeviceCompressionConfiguration) ProtoMessage() {}
+
+func (x *BlockDeviceCompressionConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_configuration_filesystem_filesystem_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockDeviceCompressionConfiguration.ProtoReflect.Descriptor instead.
+func (*BlockDeviceCompressionConfiguration) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_configuration_filesystem_filesystem_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BlockDeviceCompressionConfiguration) GetChunkSizeBytes() uint32 {
+	if x != nil {
+		return x.ChunkSizeBytes
+	}
+	return 0
+}
+
+func (x *BlockDeviceCompressionConfiguration) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+type TransientErrorRetryConfiguration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MaximumAttempts            uint32               `protobuf:"varint,1,opt,name=maximum_attempts,json=maximumAttempts,proto3" json:"maximum_attempts,omitempty"`
+	InitialBackoff             *durationpb.Duration `protobuf:"bytes,2,opt,name=initial_backoff,json=initialBackoff,proto3" json:"initial_backoff,omitempty"`
+	MaximumBackoff             *durationpb.Duration `protobuf:"bytes,3,opt,name=maximum_backoff,json=maximumBackoff,proto3" json:"maximum_backoff,omitempty"`
+	MaximumConsecutiveFailures uint32               `protobuf:"varint,4,opt,name=maximum_consecutive_failures,json=maximumConsecutiveFailures,proto3" json:"maximum_consecutive_failures,omitempty"`
+}
+
+func (x *TransientErrorRetryConfiguration) Reset() {
+	*x = TransientErrorRetryConfiguration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_configuration_filesystem_filesystem_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransientErrorRetryConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransientErrorRetryConfiguration) ProtoMessage() {}
+
+func (x *TransientErrorRetryConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_configuration_filesystem_filesystem_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransientErrorRetryConfiguration.ProtoReflect.Descriptor instead.
+func (*TransientErrorRetryConfiguration) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_configuration_filesystem_filesystem_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TransientErrorRetryConfiguration) GetMaximumAttempts() uint32 {
+	if x != nil {
+		return x.MaximumAttempts
+	}
+	return 0
+}
+
+func (x *TransientErrorRetryConfiguration) GetInitialBackoff() *durationpb.Duration {
+	if x != nil {
+		return x.InitialBackoff
+	}
+	return nil
+}
+
+func (x *TransientErrorRetryConfiguration) GetMaximumBackoff() *durationpb.Duration {
+	if x != nil {
+		return x.MaximumBackoff
+	}
+	return nil
+}
+
+func (x *TransientErrorRetryConfiguration) GetMaximumConsecutiveFailures() uint32 {
+	if x != nil {
+		return x.MaximumConsecutiveFailures
+	}
+	return 0
+}
+
 var File_pkg_proto_configuration_filesystem_filesystem_proto protoreflect.FileDescriptor
 
 var file_pkg_proto_configuration_filesystem_filesystem_proto_rawDesc = []byte{
@@ -125,12 +365,14 @@ var file_pkg_proto_configuration_filesystem_filesystem_proto_rawDesc = []byte{
 	0x73, 0x74, 0x65, 0x6d, 0x2f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2e,
 	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x22, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e,
 	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66,
-	0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
 	0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79,
 	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x35, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74,
 	0x6f, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f,
 	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x62, 0x6c, 0x6f, 0x63,
-	0x6b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xdb, 0x01,
+	0x6b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xeb, 0x04,
 	0x0a, 0x15, 0x46, 0x69, 0x6c, 0x65, 0x50, 0x6f, 0x6f, 0x6c, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
 	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x35, 0x0a, 0x09, 0x69, 0x6e, 0x5f, 0x6d, 0x65,
 	0x6d, 0x6f, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6f, 0x6f,
@@ -144,13 +386,70 @@ var file_pkg_proto_configuration_filesystem_filesystem_proto_rawDesc = []byte{
 	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x64, 0x65, 0x76,
 	0x69, 0x63, 0x65, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
 	0x6e, 0x48, 0x00, 0x52, 0x0b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65,
-	0x42, 0x09, 0x0a, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x42, 0x4d, 0x5a, 0x4b, 0x67,
-	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62,
-	0x61, 0x72, 0x6e, 0x2f, 0x62, 0x62, 0x2d, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2d, 0x65, 0x78,
-	0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f,
-	0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x33,
+	0x12, 0x78, 0x0a, 0x15, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x5f, 0x72, 0x65, 0x74, 0x72, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x44, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79,
+	0x73, 0x74, 0x65, 0x6d, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x65, 0x6e, 0x74, 0x45, 0x72,
+	0x72, 0x6f, 0x72, 0x52, 0x65, 0x74, 0x72, 0x79, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x13, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x65, 0x6e, 0x74,
+	0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x65, 0x74, 0x72, 0x79, 0x12, 0x81, 0x01, 0x0a, 0x18, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6f, 0x6d, 0x70,
+	0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x47, 0x2e,
+	0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74,
+	0x65, 0x6d, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6f,
+	0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x16, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x44, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x5b,
+	0x0a, 0x0a, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x3b, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69, 0x6c,
+	0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2e, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x0a, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x33, 0x0a, 0x16, 0x6d,
+	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x74, 0x69, 0x65, 0x72, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x5f,
+	0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x13, 0x6d, 0x65, 0x6d,
+	0x6f, 0x72, 0x79, 0x54, 0x69, 0x65, 0x72, 0x53, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73,
+	0x42, 0x09, 0x0a, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x22, 0x7c, 0x0a, 0x17, 0x45,
+	0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x70, 0x61,
+	0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x50,
+	0x61, 0x74, 0x68, 0x12, 0x3d, 0x0a, 0x0d, 0x65, 0x70, 0x68, 0x65, 0x6d, 0x65, 0x72, 0x61, 0x6c,
+	0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x48, 0x00, 0x52, 0x0c, 0x65, 0x70, 0x68, 0x65, 0x6d, 0x65, 0x72, 0x61, 0x6c, 0x4b,
+	0x65, 0x79, 0x42, 0x05, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x22, 0x65, 0x0a, 0x23, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x28, 0x0a, 0x10, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62,
+	0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x63, 0x68, 0x75, 0x6e,
+	0x6b, 0x53, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x65,
+	0x76, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c,
+	0x22, 0x97, 0x02, 0x0a, 0x20, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x65, 0x6e, 0x74, 0x45, 0x72,
+	0x72, 0x6f, 0x72, 0x52, 0x65, 0x74, 0x72, 0x79, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x29, 0x0a, 0x10, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d,
+	0x5f, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0f, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73,
+	0x12, 0x42, 0x0a, 0x0f, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x62, 0x61, 0x63, 0x6b,
+	0x6f, 0x66, 0x66, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0e, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x42, 0x61, 0x63,
+	0x6b, 0x6f, 0x66, 0x66, 0x12, 0x42, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x5f,
+	0x62, 0x61, 0x63, 0x6b, 0x6f, 0x66, 0x66, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0e, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75,
+	0x6d, 0x42, 0x61, 0x63, 0x6b, 0x6f, 0x66, 0x66, 0x12, 0x40, 0x0a, 0x1c, 0x6d, 0x61, 0x78, 0x69,
+	0x6d, 0x75, 0x6d, 0x5f, 0x63, 0x6f, 0x6e, 0x73, 0x65, 0x63, 0x75, 0x74, 0x69, 0x76, 0x65, 0x5f,
+	0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x1a,
+	0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x43, 0x6f, 0x6e, 0x73, 0x65, 0x63, 0x75, 0x74, 0x69,
+	0x76, 0x65, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x42, 0x4d, 0x5a, 0x4b, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61,
+	0x72, 0x6e, 0x2f, 0x62, 0x62, 0x2d, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2d, 0x65, 0x78, 0x65,
+	0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x66,
+	0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
@@ -165,20 +464,30 @@ func file_pkg_proto_configuration_filesystem_filesystem_proto_rawDescGZIP() []by
 	return file_pkg_proto_configuration_filesystem_filesystem_proto_rawDescData
 }
 
-var file_pkg_proto_configuration_filesystem_filesystem_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_pkg_proto_configuration_filesystem_filesystem_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_pkg_proto_configuration_filesystem_filesystem_proto_goTypes = []interface{}{
-	(*FilePoolConfiguration)(nil),     // 0: buildbarn.configuration.filesystem.FilePoolConfiguration
-	(*emptypb.Empty)(nil),             // 1: google.protobuf.Empty
-	(*blockdevice.Configuration)(nil), // 2: buildbarn.configuration.blockdevice.Configuration
+	(*FilePoolConfiguration)(nil),               // 0: buildbarn.configuration.filesystem.FilePoolConfiguration
+	(*EncryptionConfiguration)(nil),             // 1: buildbarn.configuration.filesystem.EncryptionConfiguration
+	(*BlockDeviceCompressionConfiguration)(nil), // 2: buildbarn.configuration.filesystem.BlockDeviceCompressionConfiguration
+	(*TransientErrorRetryConfiguration)(nil),    // 3: buildbarn.configuration.filesystem.TransientErrorRetryConfiguration
+	(*emptypb.Empty)(nil),                       // 4: google.protobuf.Empty
+	(*blockdevice.Configuration)(nil),           // 5: buildbarn.configuration.blockdevice.Configuration
+	(*durationpb.Duration)(nil),                 // 6: google.protobuf.Duration
 }
 var file_pkg_proto_configuration_filesystem_filesystem_proto_depIdxs = []int32{
-	1, // 0: buildbarn.configuration.filesystem.FilePoolConfiguration.in_memory:type_name -> google.protobuf.Empty
-	2, // 1: buildbarn.configuration.filesystem.FilePoolConfiguration.block_device:type_name -> buildbarn.configuration.blockdevice.Configuration
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	4, // 0: buildbarn.configuration.filesystem.FilePoolConfiguration.in_memory:type_name -> google.protobuf.Empty
+	5, // 1: buildbarn.configuration.filesystem.FilePoolConfiguration.block_device:type_name -> buildbarn.configuration.blockdevice.Configuration
+	3, // 2: buildbarn.configuration.filesystem.FilePoolConfiguration.transient_error_retry:type_name -> buildbarn.configuration.filesystem.TransientErrorRetryConfiguration
+	2, // 3: buildbarn.configuration.filesystem.FilePoolConfiguration.block_device_compression:type_name -> buildbarn.configuration.filesystem.BlockDeviceCompressionConfiguration
+	1, // 4: buildbarn.configuration.filesystem.FilePoolConfiguration.encryption:type_name -> buildbarn.configuration.filesystem.EncryptionConfiguration
+	4, // 5: buildbarn.configuration.filesystem.EncryptionConfiguration.ephemeral_key:type_name -> google.protobuf.Empty
+	6, // 6: buildbarn.configuration.filesystem.TransientErrorRetryConfiguration.initial_backoff:type_name -> google.protobuf.Duration
+	6, // 7: buildbarn.configuration.filesystem.TransientErrorRetryConfiguration.maximum_backoff:type_name -> google.protobuf.Duration
+	8, // [8:8] is the sub-list for method output_type
+	8, // [8:8] is the sub-list for method input_type
+	8, // [8:8] is the sub-list for extension type_name
+	8, // [8:8] is the sub-list for extension extendee
+	0, // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_pkg_proto_configuration_filesystem_filesystem_proto_init() }
@@ -199,19 +508,59 @@ func file_pkg_proto_configuration_filesystem_filesystem_proto_init() {
 				return nil
 			}
 		}
+		file_pkg_proto_configuration_filesystem_filesystem_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EncryptionConfiguration); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_configuration_filesystem_filesystem_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BlockDeviceCompressionConfiguration); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_configuration_filesystem_filesystem_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransientErrorRetryConfiguration); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_pkg_proto_configuration_filesystem_filesystem_proto_msgTypes[0].OneofWrappers = []interface{}{
 		(*FilePoolConfiguration_InMemory)(nil),
 		(*FilePoolConfiguration_DirectoryPath)(nil),
 		(*FilePoolConfiguration_BlockDevice)(nil),
 	}
+	file_pkg_proto_configuration_filesystem_filesystem_proto_msgTypes[1].OneofWrappers = []interface{}{
+		(*EncryptionConfiguration_KeyPath)(nil),
+		(*EncryptionConfiguration_EphemeralKey)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_proto_configuration_filesystem_filesystem_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
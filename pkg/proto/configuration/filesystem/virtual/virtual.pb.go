@@ -7,6 +7,7 @@
 package virtual
 
 import (
+	auth "github.com/buildbarn/bb-storage/pkg/proto/configuration/auth"
 	eviction "github.com/buildbarn/bb-storage/pkg/proto/configuration/eviction"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
@@ -32,7 +33,11 @@ type MountConfiguration struct {
 	//
 	//	*MountConfiguration_Fuse
 	//	*MountConfiguration_Nfsv4
-	Backend isMountConfiguration_Backend `protobuf_oneof:"backend"`
+	//	*MountConfiguration_Ninep
+	//	*MountConfiguration_Virtiofs
+	//	*MountConfiguration_Smb
+	Backend  isMountConfiguration_Backend `protobuf_oneof:"backend"`
+	Fallback *MountConfiguration          `protobuf:"bytes,4,opt,name=fallback,proto3" json:"fallback,omitempty"`
 }
 
 func (x *MountConfiguration) Reset() {
@@ -95,6 +100,34 @@ func (x *MountConfiguration) GetNfsv4() *NFSv4MountConfiguration {
 	return nil
 }
 
+func (x *MountConfiguration) GetNinep() *NinePMountConfiguration {
+	if x, ok := x.GetBackend().(*MountConfiguration_Ninep); ok {
+		return x.Ninep
+	}
+	return nil
+}
+
+func (x *MountConfiguration) GetVirtiofs() *VirtioFSMountConfiguration {
+	if x, ok := x.GetBackend().(*MountConfiguration_Virtiofs); ok {
+		return x.Virtiofs
+	}
+	return nil
+}
+
+func (x *MountConfiguration) GetSmb() *SMBMountConfiguration {
+	if x, ok := x.GetBackend().(*MountConfiguration_Smb); ok {
+		return x.Smb
+	}
+	return nil
+}
+
+func (x *MountConfiguration) GetFallback() *MountConfiguration {
+	if x != nil {
+		return x.Fallback
+	}
+	return nil
+}
+
 type isMountConfiguration_Backend interface {
 	isMountConfiguration_Backend()
 }
@@ -107,21 +140,52 @@ type MountConfiguration_Nfsv4 struct {
 	Nfsv4 *NFSv4MountConfiguration `protobuf:"bytes,3,opt,name=nfsv4,proto3,oneof"`
 }
 
+type MountConfiguration_Ninep struct {
+	Ninep *NinePMountConfiguration `protobuf:"bytes,5,opt,name=ninep,proto3,oneof"`
+}
+
+type MountConfiguration_Virtiofs struct {
+	Virtiofs *VirtioFSMountConfiguration `protobuf:"bytes,6,opt,name=virtiofs,proto3,oneof"`
+}
+
+type MountConfiguration_Smb struct {
+	Smb *SMBMountConfiguration `protobuf:"bytes,7,opt,name=smb,proto3,oneof"`
+}
+
 func (*MountConfiguration_Fuse) isMountConfiguration_Backend() {}
 
 func (*MountConfiguration_Nfsv4) isMountConfiguration_Backend() {}
 
+func (*MountConfiguration_Ninep) isMountConfiguration_Backend() {}
+
+func (*MountConfiguration_Virtiofs) isMountConfiguration_Backend() {}
+
+func (*MountConfiguration_Smb) isMountConfiguration_Backend() {}
+
 type FUSEMountConfiguration struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DirectoryEntryValidity                           *durationpb.Duration `protobuf:"bytes,2,opt,name=directory_entry_validity,json=directoryEntryValidity,proto3" json:"directory_entry_validity,omitempty"`
-	InodeAttributeValidity                           *durationpb.Duration `protobuf:"bytes,3,opt,name=inode_attribute_validity,json=inodeAttributeValidity,proto3" json:"inode_attribute_validity,omitempty"`
-	AllowOther                                       bool                 `protobuf:"varint,6,opt,name=allow_other,json=allowOther,proto3" json:"allow_other,omitempty"`
-	DirectMount                                      bool                 `protobuf:"varint,7,opt,name=direct_mount,json=directMount,proto3" json:"direct_mount,omitempty"`
-	InHeaderAuthenticationMetadataJmespathExpression string               `protobuf:"bytes,8,opt,name=in_header_authentication_metadata_jmespath_expression,json=inHeaderAuthenticationMetadataJmespathExpression,proto3" json:"in_header_authentication_metadata_jmespath_expression,omitempty"`
-	LinuxBackingDevInfoTunables                      map[string]string    `protobuf:"bytes,9,rep,name=linux_backing_dev_info_tunables,json=linuxBackingDevInfoTunables,proto3" json:"linux_backing_dev_info_tunables,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	DirectoryEntryValidity                           *durationpb.Duration          `protobuf:"bytes,2,opt,name=directory_entry_validity,json=directoryEntryValidity,proto3" json:"directory_entry_validity,omitempty"`
+	InodeAttributeValidity                           *durationpb.Duration          `protobuf:"bytes,3,opt,name=inode_attribute_validity,json=inodeAttributeValidity,proto3" json:"inode_attribute_validity,omitempty"`
+	AllowOther                                       bool                          `protobuf:"varint,6,opt,name=allow_other,json=allowOther,proto3" json:"allow_other,omitempty"`
+	DirectMount                                      bool                          `protobuf:"varint,7,opt,name=direct_mount,json=directMount,proto3" json:"direct_mount,omitempty"`
+	InHeaderAuthenticationMetadataJmespathExpression string                        `protobuf:"bytes,8,opt,name=in_header_authentication_metadata_jmespath_expression,json=inHeaderAuthenticationMetadataJmespathExpression,proto3" json:"in_header_authentication_metadata_jmespath_expression,omitempty"`
+	LinuxBackingDevInfoTunables                      map[string]string             `protobuf:"bytes,9,rep,name=linux_backing_dev_info_tunables,json=linuxBackingDevInfoTunables,proto3" json:"linux_backing_dev_info_tunables,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	ImmutableInodeAttributeValidity                  *durationpb.Duration          `protobuf:"bytes,10,opt,name=immutable_inode_attribute_validity,json=immutableInodeAttributeValidity,proto3" json:"immutable_inode_attribute_validity,omitempty"`
+	DirectIoPathPatterns                             []string                      `protobuf:"bytes,11,rep,name=direct_io_path_patterns,json=directIoPathPatterns,proto3" json:"direct_io_path_patterns,omitempty"`
+	FusermountPath                                   string                        `protobuf:"bytes,12,opt,name=fusermount_path,json=fusermountPath,proto3" json:"fusermount_path,omitempty"`
+	FuseDeviceSocketPath                             string                        `protobuf:"bytes,13,opt,name=fuse_device_socket_path,json=fuseDeviceSocketPath,proto3" json:"fuse_device_socket_path,omitempty"`
+	EmulateLocks                                     bool                          `protobuf:"varint,14,opt,name=emulate_locks,json=emulateLocks,proto3" json:"emulate_locks,omitempty"`
+	EnableExtendedAttributes                         bool                          `protobuf:"varint,15,opt,name=enable_extended_attributes,json=enableExtendedAttributes,proto3" json:"enable_extended_attributes,omitempty"`
+	MaxWrite                                         uint32                        `protobuf:"varint,16,opt,name=max_write,json=maxWrite,proto3" json:"max_write,omitempty"`
+	MaxBackground                                    uint32                        `protobuf:"varint,17,opt,name=max_background,json=maxBackground,proto3" json:"max_background,omitempty"`
+	CongestionThreshold                              uint32                        `protobuf:"varint,18,opt,name=congestion_threshold,json=congestionThreshold,proto3" json:"congestion_threshold,omitempty"`
+	DisableWritebackCache                            bool                          `protobuf:"varint,19,opt,name=disable_writeback_cache,json=disableWritebackCache,proto3" json:"disable_writeback_cache,omitempty"`
+	ImmutableDirectoryEntryValidity                  *durationpb.Duration          `protobuf:"bytes,20,opt,name=immutable_directory_entry_validity,json=immutableDirectoryEntryValidity,proto3" json:"immutable_directory_entry_validity,omitempty"`
+	WritebackTimeout                                 *durationpb.Duration          `protobuf:"bytes,21,opt,name=writeback_timeout,json=writebackTimeout,proto3" json:"writeback_timeout,omitempty"`
+	Authorizer                                       *auth.AuthorizerConfiguration `protobuf:"bytes,22,opt,name=authorizer,proto3" json:"authorizer,omitempty"`
 }
 
 func (x *FUSEMountConfiguration) Reset() {
@@ -198,6 +262,97 @@ func (x *FUSEMountConfiguration) GetLinuxBackingDevInfoTunables() map[string]str
 	return nil
 }
 
+func (x *FUSEMountConfiguration) GetImmutableInodeAttributeValidity() *durationpb.Duration {
+	if x != nil {
+		return x.ImmutableInodeAttributeValidity
+	}
+	return nil
+}
+
+func (x *FUSEMountConfiguration) GetDirectIoPathPatterns() []string {
+	if x != nil {
+		return x.DirectIoPathPatterns
+	}
+	return nil
+}
+
+func (x *FUSEMountConfiguration) GetFusermountPath() string {
+	if x != nil {
+		return x.FusermountPath
+	}
+	return ""
+}
+
+func (x *FUSEMountConfiguration) GetFuseDeviceSocketPath() string {
+	if x != nil {
+		return x.FuseDeviceSocketPath
+	}
+	return ""
+}
+
+func (x *FUSEMountConfiguration) GetEmulateLocks() bool {
+	if x != nil {
+		return x.EmulateLocks
+	}
+	return false
+}
+
+func (x *FUSEMountConfiguration) GetEnableExtendedAttributes() bool {
+	if x != nil {
+		return x.EnableExtendedAttributes
+	}
+	return false
+}
+
+func (x *FUSEMountConfiguration) GetMaxWrite() uint32 {
+	if x != nil {
+		return x.MaxWrite
+	}
+	return 0
+}
+
+func (x *FUSEMountConfiguration) GetMaxBackground() uint32 {
+	if x != nil {
+		return x.MaxBackground
+	}
+	return 0
+}
+
+func (x *FUSEMountConfiguration) GetCongestionThreshold() uint32 {
+	if x != nil {
+		return x.CongestionThreshold
+	}
+	return 0
+}
+
+func (x *FUSEMountConfiguration) GetDisableWritebackCache() bool {
+	if x != nil {
+		return x.DisableWritebackCache
+	}
+	return false
+}
+
+func (x *FUSEMountConfiguration) GetImmutableDirectoryEntryValidity() *durationpb.Duration {
+	if x != nil {
+		return x.ImmutableDirectoryEntryValidity
+	}
+	return nil
+}
+
+func (x *FUSEMountConfiguration) GetWritebackTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.WritebackTimeout
+	}
+	return nil
+}
+
+func (x *FUSEMountConfiguration) GetAuthorizer() *auth.AuthorizerConfiguration {
+	if x != nil {
+		return x.Authorizer
+	}
+	return nil
+}
+
 type NFSv4MountConfiguration struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -206,10 +361,15 @@ type NFSv4MountConfiguration struct {
 	// Types that are assignable to OperatingSystem:
 	//
 	//	*NFSv4MountConfiguration_Darwin
-	OperatingSystem      isNFSv4MountConfiguration_OperatingSystem `protobuf_oneof:"operating_system"`
-	EnforcedLeaseTime    *durationpb.Duration                      `protobuf:"bytes,2,opt,name=enforced_lease_time,json=enforcedLeaseTime,proto3" json:"enforced_lease_time,omitempty"`
-	AnnouncedLeaseTime   *durationpb.Duration                      `protobuf:"bytes,3,opt,name=announced_lease_time,json=announcedLeaseTime,proto3" json:"announced_lease_time,omitempty"`
-	SystemAuthentication *RPCv2SystemAuthenticationConfiguration   `protobuf:"bytes,4,opt,name=system_authentication,json=systemAuthentication,proto3" json:"system_authentication,omitempty"`
+	OperatingSystem                  isNFSv4MountConfiguration_OperatingSystem `protobuf_oneof:"operating_system"`
+	EnforcedLeaseTime                *durationpb.Duration                      `protobuf:"bytes,2,opt,name=enforced_lease_time,json=enforcedLeaseTime,proto3" json:"enforced_lease_time,omitempty"`
+	AnnouncedLeaseTime               *durationpb.Duration                      `protobuf:"bytes,3,opt,name=announced_lease_time,json=announcedLeaseTime,proto3" json:"announced_lease_time,omitempty"`
+	SystemAuthentication             *RPCv2SystemAuthenticationConfiguration   `protobuf:"bytes,4,opt,name=system_authentication,json=systemAuthentication,proto3" json:"system_authentication,omitempty"`
+	RootDirectoryAttributeCaching    *NFSv4AttributeCachingConfiguration       `protobuf:"bytes,5,opt,name=root_directory_attribute_caching,json=rootDirectoryAttributeCaching,proto3" json:"root_directory_attribute_caching,omitempty"`
+	ChildDirectoriesAttributeCaching *NFSv4AttributeCachingConfiguration       `protobuf:"bytes,6,opt,name=child_directories_attribute_caching,json=childDirectoriesAttributeCaching,proto3" json:"child_directories_attribute_caching,omitempty"`
+	LeavesAttributeCaching           *NFSv4AttributeCachingConfiguration       `protobuf:"bytes,7,opt,name=leaves_attribute_caching,json=leavesAttributeCaching,proto3" json:"leaves_attribute_caching,omitempty"`
+	ClientStateLimits                *NFSv4ClientStateLimitsConfiguration      `protobuf:"bytes,8,opt,name=client_state_limits,json=clientStateLimits,proto3" json:"client_state_limits,omitempty"`
+	WritebackTimeout                 *durationpb.Duration                      `protobuf:"bytes,9,opt,name=writeback_timeout,json=writebackTimeout,proto3" json:"writeback_timeout,omitempty"`
 }
 
 func (x *NFSv4MountConfiguration) Reset() {
@@ -279,6 +439,41 @@ func (x *NFSv4MountConfiguration) GetSystemAuthentication() *RPCv2SystemAuthenti
 	return nil
 }
 
+func (x *NFSv4MountConfiguration) GetRootDirectoryAttributeCaching() *NFSv4AttributeCachingConfiguration {
+	if x != nil {
+		return x.RootDirectoryAttributeCaching
+	}
+	return nil
+}
+
+func (x *NFSv4MountConfiguration) GetChildDirectoriesAttributeCaching() *NFSv4AttributeCachingConfiguration {
+	if x != nil {
+		return x.ChildDirectoriesAttributeCaching
+	}
+	return nil
+}
+
+func (x *NFSv4MountConfiguration) GetLeavesAttributeCaching() *NFSv4AttributeCachingConfiguration {
+	if x != nil {
+		return x.LeavesAttributeCaching
+	}
+	return nil
+}
+
+func (x *NFSv4MountConfiguration) GetClientStateLimits() *NFSv4ClientStateLimitsConfiguration {
+	if x != nil {
+		return x.ClientStateLimits
+	}
+	return nil
+}
+
+func (x *NFSv4MountConfiguration) GetWritebackTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.WritebackTimeout
+	}
+	return nil
+}
+
 type isNFSv4MountConfiguration_OperatingSystem interface {
 	isNFSv4MountConfiguration_OperatingSystem()
 }
@@ -289,6 +484,132 @@ type NFSv4MountConfiguration_Darwin struct {
 
 func (*NFSv4MountConfiguration_Darwin) isNFSv4MountConfiguration_OperatingSystem() {}
 
+type NFSv4ClientStateLimitsConfiguration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MaximumOpenOwners     uint32               `protobuf:"varint,1,opt,name=maximum_open_owners,json=maximumOpenOwners,proto3" json:"maximum_open_owners,omitempty"`
+	MaximumOpenFiles      uint32               `protobuf:"varint,2,opt,name=maximum_open_files,json=maximumOpenFiles,proto3" json:"maximum_open_files,omitempty"`
+	MaximumLockOwnerFiles uint32               `protobuf:"varint,3,opt,name=maximum_lock_owner_files,json=maximumLockOwnerFiles,proto3" json:"maximum_lock_owner_files,omitempty"`
+	ExpirationGracePeriod *durationpb.Duration `protobuf:"bytes,4,opt,name=expiration_grace_period,json=expirationGracePeriod,proto3" json:"expiration_grace_period,omitempty"`
+}
+
+func (x *NFSv4ClientStateLimitsConfiguration) Reset() {
+	*x = NFSv4ClientStateLimitsConfiguration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NFSv4ClientStateLimitsConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NFSv4ClientStateLimitsConfiguration) ProtoMessage() {}
+
+func (x *NFSv4ClientStateLimitsConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NFSv4ClientStateLimitsConfiguration.ProtoReflect.Descriptor instead.
+func (*NFSv4ClientStateLimitsConfiguration) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_configuration_filesystem_virtual_virtual_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *NFSv4ClientStateLimitsConfiguration) GetMaximumOpenOwners() uint32 {
+	if x != nil {
+		return x.MaximumOpenOwners
+	}
+	return 0
+}
+
+func (x *NFSv4ClientStateLimitsConfiguration) GetMaximumOpenFiles() uint32 {
+	if x != nil {
+		return x.MaximumOpenFiles
+	}
+	return 0
+}
+
+func (x *NFSv4ClientStateLimitsConfiguration) GetMaximumLockOwnerFiles() uint32 {
+	if x != nil {
+		return x.MaximumLockOwnerFiles
+	}
+	return 0
+}
+
+func (x *NFSv4ClientStateLimitsConfiguration) GetExpirationGracePeriod() *durationpb.Duration {
+	if x != nil {
+		return x.ExpirationGracePeriod
+	}
+	return nil
+}
+
+type NFSv4AttributeCachingConfiguration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Minimum *durationpb.Duration `protobuf:"bytes,1,opt,name=minimum,proto3" json:"minimum,omitempty"`
+	Maximum *durationpb.Duration `protobuf:"bytes,2,opt,name=maximum,proto3" json:"maximum,omitempty"`
+}
+
+func (x *NFSv4AttributeCachingConfiguration) Reset() {
+	*x = NFSv4AttributeCachingConfiguration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NFSv4AttributeCachingConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NFSv4AttributeCachingConfiguration) ProtoMessage() {}
+
+func (x *NFSv4AttributeCachingConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NFSv4AttributeCachingConfiguration.ProtoReflect.Descriptor instead.
+func (*NFSv4AttributeCachingConfiguration) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_configuration_filesystem_virtual_virtual_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *NFSv4AttributeCachingConfiguration) GetMinimum() *durationpb.Duration {
+	if x != nil {
+		return x.Minimum
+	}
+	return nil
+}
+
+func (x *NFSv4AttributeCachingConfiguration) GetMaximum() *durationpb.Duration {
+	if x != nil {
+		return x.Maximum
+	}
+	return nil
+}
+
 type NFSv4DarwinMountConfiguration struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -301,7 +622,7 @@ type NFSv4DarwinMountConfiguration struct {
 func (x *NFSv4DarwinMountConfiguration) Reset() {
 	*x = NFSv4DarwinMountConfiguration{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[3]
+		mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -314,7 +635,7 @@ func (x *NFSv4DarwinMountConfiguration) String() string {
 func (*NFSv4DarwinMountConfiguration) ProtoMessage() {}
 
 func (x *NFSv4DarwinMountConfiguration) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[3]
+	mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -327,7 +648,7 @@ func (x *NFSv4DarwinMountConfiguration) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NFSv4DarwinMountConfiguration.ProtoReflect.Descriptor instead.
 func (*NFSv4DarwinMountConfiguration) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_configuration_filesystem_virtual_virtual_proto_rawDescGZIP(), []int{3}
+	return file_pkg_proto_configuration_filesystem_virtual_virtual_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *NFSv4DarwinMountConfiguration) GetSocketPath() string {
@@ -344,6 +665,219 @@ func (x *NFSv4DarwinMountConfiguration) GetAccessCacheSize() uint32 {
 	return 0
 }
 
+type NinePMountConfiguration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ListenPaths               []string `protobuf:"bytes,1,rep,name=listen_paths,json=listenPaths,proto3" json:"listen_paths,omitempty"`
+	ListenAddresses           []string `protobuf:"bytes,2,rep,name=listen_addresses,json=listenAddresses,proto3" json:"listen_addresses,omitempty"`
+	MaximumMessageSizeBytes   uint32   `protobuf:"varint,3,opt,name=maximum_message_size_bytes,json=maximumMessageSizeBytes,proto3" json:"maximum_message_size_bytes,omitempty"`
+	MaximumConcurrentRequests uint32   `protobuf:"varint,4,opt,name=maximum_concurrent_requests,json=maximumConcurrentRequests,proto3" json:"maximum_concurrent_requests,omitempty"`
+}
+
+func (x *NinePMountConfiguration) Reset() {
+	*x = NinePMountConfiguration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NinePMountConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NinePMountConfiguration) ProtoMessage() {}
+
+func (x *NinePMountConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NinePMountConfiguration.ProtoReflect.Descriptor instead.
+func (*NinePMountConfiguration) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_configuration_filesystem_virtual_virtual_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *NinePMountConfiguration) GetListenPaths() []string {
+	if x != nil {
+		return x.ListenPaths
+	}
+	return nil
+}
+
+func (x *NinePMountConfiguration) GetListenAddresses() []string {
+	if x != nil {
+		return x.ListenAddresses
+	}
+	return nil
+}
+
+func (x *NinePMountConfiguration) GetMaximumMessageSizeBytes() uint32 {
+	if x != nil {
+		return x.MaximumMessageSizeBytes
+	}
+	return 0
+}
+
+func (x *NinePMountConfiguration) GetMaximumConcurrentRequests() uint32 {
+	if x != nil {
+		return x.MaximumConcurrentRequests
+	}
+	return 0
+}
+
+type VirtioFSMountConfiguration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VhostUserSocketPath string                  `protobuf:"bytes,1,opt,name=vhost_user_socket_path,json=vhostUserSocketPath,proto3" json:"vhost_user_socket_path,omitempty"`
+	Fuse                *FUSEMountConfiguration `protobuf:"bytes,2,opt,name=fuse,proto3" json:"fuse,omitempty"`
+}
+
+func (x *VirtioFSMountConfiguration) Reset() {
+	*x = VirtioFSMountConfiguration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VirtioFSMountConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VirtioFSMountConfiguration) ProtoMessage() {}
+
+func (x *VirtioFSMountConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VirtioFSMountConfiguration.ProtoReflect.Descriptor instead.
+func (*VirtioFSMountConfiguration) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_configuration_filesystem_virtual_virtual_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *VirtioFSMountConfiguration) GetVhostUserSocketPath() string {
+	if x != nil {
+		return x.VhostUserSocketPath
+	}
+	return ""
+}
+
+func (x *VirtioFSMountConfiguration) GetFuse() *FUSEMountConfiguration {
+	if x != nil {
+		return x.Fuse
+	}
+	return nil
+}
+
+type SMBMountConfiguration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ListenAddresses   []string          `protobuf:"bytes,1,rep,name=listen_addresses,json=listenAddresses,proto3" json:"listen_addresses,omitempty"`
+	ServerName        string            `protobuf:"bytes,2,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`
+	ShareName         string            `protobuf:"bytes,3,opt,name=share_name,json=shareName,proto3" json:"share_name,omitempty"`
+	AllowGuest        bool              `protobuf:"varint,5,opt,name=allow_guest,json=allowGuest,proto3" json:"allow_guest,omitempty"`
+	UserNtHashes      map[string]string `protobuf:"bytes,6,rep,name=user_nt_hashes,json=userNtHashes,proto3" json:"user_nt_hashes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	RequireEncryption bool              `protobuf:"varint,7,opt,name=require_encryption,json=requireEncryption,proto3" json:"require_encryption,omitempty"`
+}
+
+func (x *SMBMountConfiguration) Reset() {
+	*x = SMBMountConfiguration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SMBMountConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SMBMountConfiguration) ProtoMessage() {}
+
+func (x *SMBMountConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SMBMountConfiguration.ProtoReflect.Descriptor instead.
+func (*SMBMountConfiguration) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_configuration_filesystem_virtual_virtual_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SMBMountConfiguration) GetListenAddresses() []string {
+	if x != nil {
+		return x.ListenAddresses
+	}
+	return nil
+}
+
+func (x *SMBMountConfiguration) GetServerName() string {
+	if x != nil {
+		return x.ServerName
+	}
+	return ""
+}
+
+func (x *SMBMountConfiguration) GetShareName() string {
+	if x != nil {
+		return x.ShareName
+	}
+	return ""
+}
+
+func (x *SMBMountConfiguration) GetAllowGuest() bool {
+	if x != nil {
+		return x.AllowGuest
+	}
+	return false
+}
+
+func (x *SMBMountConfiguration) GetUserNtHashes() map[string]string {
+	if x != nil {
+		return x.UserNtHashes
+	}
+	return nil
+}
+
+func (x *SMBMountConfiguration) GetRequireEncryption() bool {
+	if x != nil {
+		return x.RequireEncryption
+	}
+	return false
+}
+
 type RPCv2SystemAuthenticationConfiguration struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -357,7 +891,7 @@ type RPCv2SystemAuthenticationConfiguration struct {
 func (x *RPCv2SystemAuthenticationConfiguration) Reset() {
 	*x = RPCv2SystemAuthenticationConfiguration{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[4]
+		mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -370,7 +904,7 @@ func (x *RPCv2SystemAuthenticationConfiguration) String() string {
 func (*RPCv2SystemAuthenticationConfiguration) ProtoMessage() {}
 
 func (x *RPCv2SystemAuthenticationConfiguration) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[4]
+	mi := &file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -383,7 +917,7 @@ func (x *RPCv2SystemAuthenticationConfiguration) ProtoReflect() protoreflect.Mes
 
 // Deprecated: Use RPCv2SystemAuthenticationConfiguration.ProtoReflect.Descriptor instead.
 func (*RPCv2SystemAuthenticationConfiguration) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_configuration_filesystem_virtual_virtual_proto_rawDescGZIP(), []int{4}
+	return file_pkg_proto_configuration_filesystem_virtual_virtual_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *RPCv2SystemAuthenticationConfiguration) GetMetadataJmespathExpression() string {
@@ -418,123 +952,317 @@ var file_pkg_proto_configuration_filesystem_virtual_virtual_proto_rawDesc = []by
 	0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2e, 0x76,
 	0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
 	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x27, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74,
 	0x6f, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f,
-	0x65, 0x76, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x65, 0x76, 0x69, 0x63, 0x74, 0x69, 0x6f,
-	0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xf5, 0x01, 0x0a, 0x12, 0x4d, 0x6f, 0x75, 0x6e,
-	0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d,
-	0x0a, 0x0a, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x09, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x50, 0x61, 0x74, 0x68, 0x12, 0x58, 0x0a,
-	0x04, 0x66, 0x75, 0x73, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x42, 0x2e, 0x62, 0x75,
-	0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d,
-	0x2e, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x2e, 0x46, 0x55, 0x53, 0x45, 0x4d, 0x6f, 0x75,
+	0x61, 0x75, 0x74, 0x68, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
+	0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x65, 0x76, 0x69, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x2f, 0x65, 0x76, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x22, 0xeb, 0x04, 0x0a, 0x12, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x6f, 0x75, 0x6e, 0x74,
+	0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x6f, 0x75,
+	0x6e, 0x74, 0x50, 0x61, 0x74, 0x68, 0x12, 0x58, 0x0a, 0x04, 0x66, 0x75, 0x73, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x42, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e,
+	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66,
+	0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2e, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61,
+	0x6c, 0x2e, 0x46, 0x55, 0x53, 0x45, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x04, 0x66, 0x75, 0x73, 0x65,
+	0x12, 0x5b, 0x0a, 0x05, 0x6e, 0x66, 0x73, 0x76, 0x34, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x43, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79,
+	0x73, 0x74, 0x65, 0x6d, 0x2e, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x2e, 0x4e, 0x46, 0x53,
+	0x76, 0x34, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x05, 0x6e, 0x66, 0x73, 0x76, 0x34, 0x12, 0x5b, 0x0a,
+	0x05, 0x6e, 0x69, 0x6e, 0x65, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x43, 0x2e, 0x62,
+	0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65,
+	0x6d, 0x2e, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x2e, 0x4e, 0x69, 0x6e, 0x65, 0x50, 0x4d,
+	0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x48, 0x00, 0x52, 0x05, 0x6e, 0x69, 0x6e, 0x65, 0x70, 0x12, 0x64, 0x0a, 0x08, 0x76, 0x69,
+	0x72, 0x74, 0x69, 0x6f, 0x66, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x46, 0x2e, 0x62,
+	0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65,
+	0x6d, 0x2e, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x2e, 0x56, 0x69, 0x72, 0x74, 0x69, 0x6f,
+	0x46, 0x53, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x08, 0x76, 0x69, 0x72, 0x74, 0x69, 0x6f, 0x66, 0x73,
+	0x12, 0x55, 0x0a, 0x03, 0x73, 0x6d, 0x62, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x41, 0x2e,
+	0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74,
+	0x65, 0x6d, 0x2e, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x2e, 0x53, 0x4d, 0x42, 0x4d, 0x6f,
+	0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x48, 0x00, 0x52, 0x03, 0x73, 0x6d, 0x62, 0x12, 0x5a, 0x0a, 0x08, 0x66, 0x61, 0x6c, 0x6c, 0x62,
+	0x61, 0x63, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x3e, 0x2e, 0x62, 0x75, 0x69, 0x6c,
+	0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2e, 0x76,
+	0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x2e, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x66, 0x61, 0x6c, 0x6c, 0x62,
+	0x61, 0x63, 0x6b, 0x42, 0x09, 0x0a, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x22, 0x97,
+	0x0b, 0x0a, 0x16, 0x46, 0x55, 0x53, 0x45, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x53, 0x0a, 0x18, 0x64, 0x69, 0x72,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x5f, 0x76, 0x61, 0x6c,
+	0x69, 0x64, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x16, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x79, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x12, 0x53,
+	0x0a, 0x18, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74,
+	0x65, 0x5f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x16, 0x69, 0x6e, 0x6f,
+	0x64, 0x65, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x56, 0x61, 0x6c, 0x69, 0x64,
+	0x69, 0x74, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x6f, 0x74, 0x68,
+	0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x4f,
+	0x74, 0x68, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x5f, 0x6d,
+	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x64, 0x69, 0x72, 0x65,
+	0x63, 0x74, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x6f, 0x0a, 0x35, 0x69, 0x6e, 0x5f, 0x68, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x5f, 0x61, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x6a, 0x6d, 0x65,
+	0x73, 0x70, 0x61, 0x74, 0x68, 0x5f, 0x65, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x30, 0x69, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x41, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x65,
+	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x4a, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x74, 0x68, 0x45, 0x78,
+	0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0xa9, 0x01, 0x0a, 0x1f, 0x6c, 0x69, 0x6e,
+	0x75, 0x78, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x64, 0x65, 0x76, 0x5f, 0x69,
+	0x6e, 0x66, 0x6f, 0x5f, 0x74, 0x75, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x18, 0x09, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x63, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69, 0x6c,
+	0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2e, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x2e,
+	0x46, 0x55, 0x53, 0x45, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x4c, 0x69, 0x6e, 0x75, 0x78, 0x42, 0x61, 0x63, 0x6b,
+	0x69, 0x6e, 0x67, 0x44, 0x65, 0x76, 0x49, 0x6e, 0x66, 0x6f, 0x54, 0x75, 0x6e, 0x61, 0x62, 0x6c,
+	0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x1b, 0x6c, 0x69, 0x6e, 0x75, 0x78, 0x42, 0x61,
+	0x63, 0x6b, 0x69, 0x6e, 0x67, 0x44, 0x65, 0x76, 0x49, 0x6e, 0x66, 0x6f, 0x54, 0x75, 0x6e, 0x61,
+	0x62, 0x6c, 0x65, 0x73, 0x12, 0x66, 0x0a, 0x22, 0x69, 0x6d, 0x6d, 0x75, 0x74, 0x61, 0x62, 0x6c,
+	0x65, 0x5f, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74,
+	0x65, 0x5f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x1f, 0x69, 0x6d, 0x6d,
+	0x75, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x49, 0x6e, 0x6f, 0x64, 0x65, 0x41, 0x74, 0x74, 0x72, 0x69,
+	0x62, 0x75, 0x74, 0x65, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x12, 0x35, 0x0a, 0x17,
+	0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x5f, 0x69, 0x6f, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x5f, 0x70,
+	0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x09, 0x52, 0x14, 0x64,
+	0x69, 0x72, 0x65, 0x63, 0x74, 0x49, 0x6f, 0x50, 0x61, 0x74, 0x68, 0x50, 0x61, 0x74, 0x74, 0x65,
+	0x72, 0x6e, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x66, 0x75, 0x73, 0x65, 0x72, 0x6d, 0x6f, 0x75, 0x6e,
+	0x74, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x66, 0x75,
+	0x73, 0x65, 0x72, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x50, 0x61, 0x74, 0x68, 0x12, 0x35, 0x0a, 0x17,
+	0x66, 0x75, 0x73, 0x65, 0x5f, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x73, 0x6f, 0x63, 0x6b,
+	0x65, 0x74, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x66,
+	0x75, 0x73, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x53, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x50,
+	0x61, 0x74, 0x68, 0x12, 0x23, 0x0a, 0x0d, 0x65, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x5f, 0x6c,
+	0x6f, 0x63, 0x6b, 0x73, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x65, 0x6d, 0x75, 0x6c,
+	0x61, 0x74, 0x65, 0x4c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x3c, 0x0a, 0x1a, 0x65, 0x6e, 0x61, 0x62,
+	0x6c, 0x65, 0x5f, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x74, 0x72,
+	0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x08, 0x52, 0x18, 0x65, 0x6e,
+	0x61, 0x62, 0x6c, 0x65, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x65, 0x64, 0x41, 0x74, 0x74, 0x72,
+	0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x77, 0x72,
+	0x69, 0x74, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x57, 0x72,
+	0x69, 0x74, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x6d, 0x61, 0x78, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x67,
+	0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x6d, 0x61, 0x78,
+	0x42, 0x61, 0x63, 0x6b, 0x67, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x31, 0x0a, 0x14, 0x63, 0x6f,
+	0x6e, 0x67, 0x65, 0x73, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f,
+	0x6c, 0x64, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x13, 0x63, 0x6f, 0x6e, 0x67, 0x65, 0x73,
+	0x74, 0x69, 0x6f, 0x6e, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x36, 0x0a,
+	0x17, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x77, 0x72, 0x69, 0x74, 0x65, 0x62, 0x61,
+	0x63, 0x6b, 0x5f, 0x63, 0x61, 0x63, 0x68, 0x65, 0x18, 0x13, 0x20, 0x01, 0x28, 0x08, 0x52, 0x15,
+	0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x57, 0x72, 0x69, 0x74, 0x65, 0x62, 0x61, 0x63, 0x6b,
+	0x43, 0x61, 0x63, 0x68, 0x65, 0x12, 0x66, 0x0a, 0x22, 0x69, 0x6d, 0x6d, 0x75, 0x74, 0x61, 0x62,
+	0x6c, 0x65, 0x5f, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x5f, 0x65, 0x6e, 0x74,
+	0x72, 0x79, 0x5f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x18, 0x14, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x1f, 0x69, 0x6d,
+	0x6d, 0x75, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x12, 0x46, 0x0a,
+	0x11, 0x77, 0x72, 0x69, 0x74, 0x65, 0x62, 0x61, 0x63, 0x6b, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x10, 0x77, 0x72, 0x69, 0x74, 0x65, 0x62, 0x61, 0x63, 0x6b, 0x54, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x55, 0x0a, 0x0a, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69,
+	0x7a, 0x65, 0x72, 0x18, 0x16, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x35, 0x2e, 0x62, 0x75, 0x69, 0x6c,
+	0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69,
+	0x7a, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x0a, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x1a, 0x4e, 0x0a, 0x20,
+	0x4c, 0x69, 0x6e, 0x75, 0x78, 0x42, 0x61, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x44, 0x65, 0x76, 0x49,
+	0x6e, 0x66, 0x6f, 0x54, 0x75, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x4a, 0x04, 0x08, 0x04,
+	0x10, 0x05, 0x4a, 0x04, 0x08, 0x05, 0x10, 0x06, 0x22, 0xc2, 0x08, 0x0a, 0x17, 0x4e, 0x46, 0x53,
+	0x76, 0x34, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x63, 0x0a, 0x06, 0x64, 0x61, 0x72, 0x77, 0x69, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x49, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e,
+	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66,
+	0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2e, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61,
+	0x6c, 0x2e, 0x4e, 0x46, 0x53, 0x76, 0x34, 0x44, 0x61, 0x72, 0x77, 0x69, 0x6e, 0x4d, 0x6f, 0x75,
 	0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48,
-	0x00, 0x52, 0x04, 0x66, 0x75, 0x73, 0x65, 0x12, 0x5b, 0x0a, 0x05, 0x6e, 0x66, 0x73, 0x76, 0x34,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x43, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61,
+	0x00, 0x52, 0x06, 0x64, 0x61, 0x72, 0x77, 0x69, 0x6e, 0x12, 0x49, 0x0a, 0x13, 0x65, 0x6e, 0x66,
+	0x6f, 0x72, 0x63, 0x65, 0x64, 0x5f, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x11, 0x65, 0x6e, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x64, 0x4c, 0x65, 0x61, 0x73, 0x65,
+	0x54, 0x69, 0x6d, 0x65, 0x12, 0x4b, 0x0a, 0x14, 0x61, 0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65,
+	0x64, 0x5f, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x12, 0x61,
+	0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65, 0x64, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x54, 0x69, 0x6d,
+	0x65, 0x12, 0x87, 0x01, 0x0a, 0x15, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f, 0x61, 0x75, 0x74,
+	0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x52, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69, 0x6c, 0x65,
+	0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2e, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x2e, 0x52,
+	0x50, 0x43, 0x76, 0x32, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x41, 0x75, 0x74, 0x68, 0x65, 0x6e,
+	0x74, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x14, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x41, 0x75, 0x74,
+	0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x97, 0x01, 0x0a, 0x20,
+	0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x5f, 0x61,
+	0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x5f, 0x63, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x67,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x4e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61,
 	0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
 	0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2e, 0x76, 0x69, 0x72, 0x74,
-	0x75, 0x61, 0x6c, 0x2e, 0x4e, 0x46, 0x53, 0x76, 0x34, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f,
-	0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x05, 0x6e,
-	0x66, 0x73, 0x76, 0x34, 0x42, 0x09, 0x0a, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x22,
-	0xff, 0x04, 0x0a, 0x16, 0x46, 0x55, 0x53, 0x45, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e,
-	0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x53, 0x0a, 0x18, 0x64, 0x69,
-	0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x5f, 0x76, 0x61,
-	0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67,
-	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44,
-	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x16, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f,
-	0x72, 0x79, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x12,
-	0x53, 0x0a, 0x18, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75,
-	0x74, 0x65, 0x5f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x16, 0x69, 0x6e,
-	0x6f, 0x64, 0x65, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x56, 0x61, 0x6c, 0x69,
-	0x64, 0x69, 0x74, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x6f, 0x74,
-	0x68, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x61, 0x6c, 0x6c, 0x6f, 0x77,
-	0x4f, 0x74, 0x68, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x5f,
-	0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x64, 0x69, 0x72,
-	0x65, 0x63, 0x74, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x6f, 0x0a, 0x35, 0x69, 0x6e, 0x5f, 0x68,
-	0x65, 0x61, 0x64, 0x65, 0x72, 0x5f, 0x61, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x6a, 0x6d,
-	0x65, 0x73, 0x70, 0x61, 0x74, 0x68, 0x5f, 0x65, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f,
-	0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x30, 0x69, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65,
-	0x72, 0x41, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d,
-	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x4a, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x74, 0x68, 0x45,
-	0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0xa9, 0x01, 0x0a, 0x1f, 0x6c, 0x69,
-	0x6e, 0x75, 0x78, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x64, 0x65, 0x76, 0x5f,
-	0x69, 0x6e, 0x66, 0x6f, 0x5f, 0x74, 0x75, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x18, 0x09, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x63, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e,
+	0x75, 0x61, 0x6c, 0x2e, 0x4e, 0x46, 0x53, 0x76, 0x34, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75,
+	0x74, 0x65, 0x43, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x1d, 0x72, 0x6f, 0x6f, 0x74, 0x44, 0x69, 0x72, 0x65,
+	0x63, 0x74, 0x6f, 0x72, 0x79, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x43, 0x61,
+	0x63, 0x68, 0x69, 0x6e, 0x67, 0x12, 0x9d, 0x01, 0x0a, 0x23, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x5f,
+	0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x69, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x74, 0x72,
+	0x69, 0x62, 0x75, 0x74, 0x65, 0x5f, 0x63, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x67, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x4e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e,
 	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69,
 	0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2e, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c,
-	0x2e, 0x46, 0x55, 0x53, 0x45, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x4c, 0x69, 0x6e, 0x75, 0x78, 0x42, 0x61, 0x63,
-	0x6b, 0x69, 0x6e, 0x67, 0x44, 0x65, 0x76, 0x49, 0x6e, 0x66, 0x6f, 0x54, 0x75, 0x6e, 0x61, 0x62,
-	0x6c, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x1b, 0x6c, 0x69, 0x6e, 0x75, 0x78, 0x42,
-	0x61, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x44, 0x65, 0x76, 0x49, 0x6e, 0x66, 0x6f, 0x54, 0x75, 0x6e,
-	0x61, 0x62, 0x6c, 0x65, 0x73, 0x1a, 0x4e, 0x0a, 0x20, 0x4c, 0x69, 0x6e, 0x75, 0x78, 0x42, 0x61,
-	0x63, 0x6b, 0x69, 0x6e, 0x67, 0x44, 0x65, 0x76, 0x49, 0x6e, 0x66, 0x6f, 0x54, 0x75, 0x6e, 0x61,
-	0x62, 0x6c, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x3a, 0x02, 0x38, 0x01, 0x4a, 0x04, 0x08, 0x04, 0x10, 0x05, 0x4a, 0x04, 0x08, 0x05, 0x10,
-	0x06, 0x22, 0xb4, 0x03, 0x0a, 0x17, 0x4e, 0x46, 0x53, 0x76, 0x34, 0x4d, 0x6f, 0x75, 0x6e, 0x74,
-	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x63, 0x0a,
-	0x06, 0x64, 0x61, 0x72, 0x77, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x49, 0x2e,
+	0x2e, 0x4e, 0x46, 0x53, 0x76, 0x34, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x43,
+	0x61, 0x63, 0x68, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x20, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x69, 0x65, 0x73, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x43, 0x61,
+	0x63, 0x68, 0x69, 0x6e, 0x67, 0x12, 0x88, 0x01, 0x0a, 0x18, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73,
+	0x5f, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x5f, 0x63, 0x61, 0x63, 0x68, 0x69,
+	0x6e, 0x67, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x4e, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2e, 0x76, 0x69,
+	0x72, 0x74, 0x75, 0x61, 0x6c, 0x2e, 0x4e, 0x46, 0x53, 0x76, 0x34, 0x41, 0x74, 0x74, 0x72, 0x69,
+	0x62, 0x75, 0x74, 0x65, 0x43, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x16, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73,
+	0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x43, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x67,
+	0x12, 0x7f, 0x0a, 0x13, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x4f, 0x2e,
 	0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
 	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74,
 	0x65, 0x6d, 0x2e, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x2e, 0x4e, 0x46, 0x53, 0x76, 0x34,
-	0x44, 0x61, 0x72, 0x77, 0x69, 0x6e, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69,
-	0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x06, 0x64, 0x61, 0x72, 0x77,
-	0x69, 0x6e, 0x12, 0x49, 0x0a, 0x13, 0x65, 0x6e, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x64, 0x5f, 0x6c,
-	0x65, 0x61, 0x73, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x11, 0x65, 0x6e, 0x66, 0x6f,
-	0x72, 0x63, 0x65, 0x64, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x4b, 0x0a,
-	0x14, 0x61, 0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x6c, 0x65, 0x61, 0x73, 0x65,
-	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f,
-	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75,
-	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x12, 0x61, 0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65,
-	0x64, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x87, 0x01, 0x0a, 0x15, 0x73,
-	0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f, 0x61, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x52, 0x2e, 0x62, 0x75, 0x69,
-	0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2e,
-	0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x2e, 0x52, 0x50, 0x43, 0x76, 0x32, 0x53, 0x79, 0x73,
-	0x74, 0x65, 0x6d, 0x41, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x14,
-	0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x41, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x42, 0x12, 0x0a, 0x10, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6e,
-	0x67, 0x5f, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x22, 0x78, 0x0a, 0x1d, 0x4e, 0x46, 0x53, 0x76,
-	0x34, 0x44, 0x61, 0x72, 0x77, 0x69, 0x6e, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66,
-	0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x6f, 0x63,
-	0x6b, 0x65, 0x74, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
-	0x73, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x50, 0x61, 0x74, 0x68, 0x12, 0x2a, 0x0a, 0x11, 0x61, 0x63,
-	0x63, 0x65, 0x73, 0x73, 0x5f, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x43, 0x61, 0x63,
-	0x68, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x4a, 0x04, 0x08, 0x03,
-	0x10, 0x04, 0x22, 0x8c, 0x02, 0x0a, 0x26, 0x52, 0x50, 0x43, 0x76, 0x32, 0x53, 0x79, 0x73, 0x74,
-	0x65, 0x6d, 0x41, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x40, 0x0a,
-	0x1c, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x6a, 0x6d, 0x65, 0x73, 0x70, 0x61,
-	0x74, 0x68, 0x5f, 0x65, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x1a, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x4a, 0x6d, 0x65,
-	0x73, 0x70, 0x61, 0x74, 0x68, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12,
-	0x2c, 0x0a, 0x12, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x63, 0x61, 0x63, 0x68, 0x65,
-	0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x6d, 0x61, 0x78,
-	0x69, 0x6d, 0x75, 0x6d, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x72, 0x0a,
-	0x18, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65,
-	0x6e, 0x74, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32,
-	0x38, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
-	0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x65, 0x76, 0x69, 0x63, 0x74, 0x69,
-	0x6f, 0x6e, 0x2e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x6d,
-	0x65, 0x6e, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x16, 0x63, 0x61, 0x63, 0x68, 0x65,
-	0x52, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63,
-	0x79, 0x42, 0x55, 0x5a, 0x53, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
-	0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2f, 0x62, 0x62, 0x2d, 0x72, 0x65, 0x6d,
-	0x6f, 0x74, 0x65, 0x2d, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x70, 0x6b,
-	0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d,
-	0x2f, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74,
+	0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x11,
+	0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74,
+	0x73, 0x12, 0x46, 0x0a, 0x11, 0x77, 0x72, 0x69, 0x74, 0x65, 0x62, 0x61, 0x63, 0x6b, 0x5f, 0x74,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x10, 0x77, 0x72, 0x69, 0x74, 0x65, 0x62, 0x61,
+	0x63, 0x6b, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x42, 0x12, 0x0a, 0x10, 0x6f, 0x70, 0x65,
+	0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x5f, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x22, 0x8f, 0x02,
+	0x0a, 0x23, 0x4e, 0x46, 0x53, 0x76, 0x34, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2e, 0x0a, 0x13, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d,
+	0x5f, 0x6f, 0x70, 0x65, 0x6e, 0x5f, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x11, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x4f, 0x70, 0x65, 0x6e, 0x4f,
+	0x77, 0x6e, 0x65, 0x72, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d,
+	0x5f, 0x6f, 0x70, 0x65, 0x6e, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x10, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x4f, 0x70, 0x65, 0x6e, 0x46, 0x69,
+	0x6c, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x18, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x6c,
+	0x6f, 0x63, 0x6b, 0x5f, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x15, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x4c, 0x6f,
+	0x63, 0x6b, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x51, 0x0a, 0x17,
+	0x65, 0x78, 0x70, 0x69, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x67, 0x72, 0x61, 0x63, 0x65,
+	0x5f, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x15, 0x65, 0x78, 0x70, 0x69, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x47, 0x72, 0x61, 0x63, 0x65, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x22,
+	0x8e, 0x01, 0x0a, 0x22, 0x4e, 0x46, 0x53, 0x76, 0x34, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75,
+	0x74, 0x65, 0x43, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x33, 0x0a, 0x07, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75,
+	0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x07, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x12, 0x33, 0x0a, 0x07, 0x6d,
+	0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d,
+	0x22, 0x78, 0x0a, 0x1d, 0x4e, 0x46, 0x53, 0x76, 0x34, 0x44, 0x61, 0x72, 0x77, 0x69, 0x6e, 0x4d,
+	0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x5f, 0x70, 0x61, 0x74, 0x68,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x50, 0x61,
+	0x74, 0x68, 0x12, 0x2a, 0x0a, 0x11, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x63, 0x61, 0x63,
+	0x68, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x61,
+	0x63, 0x63, 0x65, 0x73, 0x73, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x4a, 0x04,
+	0x08, 0x02, 0x10, 0x03, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x22, 0xe4, 0x01, 0x0a, 0x17, 0x4e,
+	0x69, 0x6e, 0x65, 0x50, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e,
+	0x5f, 0x70, 0x61, 0x74, 0x68, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x6c, 0x69,
+	0x73, 0x74, 0x65, 0x6e, 0x50, 0x61, 0x74, 0x68, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x6c, 0x69, 0x73,
+	0x74, 0x65, 0x6e, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0f, 0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x65, 0x73, 0x12, 0x3b, 0x0a, 0x1a, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x5f,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62, 0x79, 0x74,
+	0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x17, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75,
+	0x6d, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x12, 0x3e, 0x0a, 0x1b, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x63, 0x6f, 0x6e,
+	0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x19, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x43,
+	0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x73, 0x22, 0xa9, 0x01, 0x0a, 0x1a, 0x56, 0x69, 0x72, 0x74, 0x69, 0x6f, 0x46, 0x53, 0x4d, 0x6f,
+	0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x33, 0x0a, 0x16, 0x76, 0x68, 0x6f, 0x73, 0x74, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x73,
+	0x6f, 0x63, 0x6b, 0x65, 0x74, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x13, 0x76, 0x68, 0x6f, 0x73, 0x74, 0x55, 0x73, 0x65, 0x72, 0x53, 0x6f, 0x63, 0x6b, 0x65,
+	0x74, 0x50, 0x61, 0x74, 0x68, 0x12, 0x56, 0x0a, 0x04, 0x66, 0x75, 0x73, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x42, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69,
+	0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2e, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c,
+	0x2e, 0x46, 0x55, 0x53, 0x45, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x04, 0x66, 0x75, 0x73, 0x65, 0x22, 0x94, 0x03,
+	0x0a, 0x15, 0x53, 0x4d, 0x42, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x29, 0x0a, 0x10, 0x6c, 0x69, 0x73, 0x74, 0x65,
+	0x6e, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x0f, 0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4e,
+	0x61, 0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x68, 0x61, 0x72, 0x65, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x68, 0x61, 0x72, 0x65, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x67, 0x75, 0x65, 0x73,
+	0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x47, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x79, 0x0a, 0x0e, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x6e, 0x74, 0x5f, 0x68,
+	0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x53, 0x2e, 0x62, 0x75,
+	0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d,
+	0x2e, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x2e, 0x53, 0x4d, 0x42, 0x4d, 0x6f, 0x75, 0x6e,
+	0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x55,
+	0x73, 0x65, 0x72, 0x4e, 0x74, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x0c, 0x75, 0x73, 0x65, 0x72, 0x4e, 0x74, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x12, 0x2d,
+	0x0a, 0x12, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x5f, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x72, 0x65, 0x71, 0x75,
+	0x69, 0x72, 0x65, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x3f, 0x0a,
+	0x11, 0x55, 0x73, 0x65, 0x72, 0x4e, 0x74, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x4a, 0x04,
+	0x08, 0x04, 0x10, 0x05, 0x22, 0x8c, 0x02, 0x0a, 0x26, 0x52, 0x50, 0x43, 0x76, 0x32, 0x53, 0x79,
+	0x73, 0x74, 0x65, 0x6d, 0x41, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x40, 0x0a, 0x1c, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x6a, 0x6d, 0x65, 0x73,
+	0x70, 0x61, 0x74, 0x68, 0x5f, 0x65, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x1a, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x4a,
+	0x6d, 0x65, 0x73, 0x70, 0x61, 0x74, 0x68, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x2c, 0x0a, 0x12, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x63, 0x61, 0x63,
+	0x68, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x6d,
+	0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12,
+	0x72, 0x0a, 0x18, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x38, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x65, 0x76, 0x69, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x63,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x16, 0x63, 0x61, 0x63,
+	0x68, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x50, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x42, 0x55, 0x5a, 0x53, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2f, 0x62, 0x62, 0x2d, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2d, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2f,
+	0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74,
+	0x65, 0x6d, 0x2f, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
 }
 
 var (
@@ -549,33 +1277,58 @@ func file_pkg_proto_configuration_filesystem_virtual_virtual_proto_rawDescGZIP()
 	return file_pkg_proto_configuration_filesystem_virtual_virtual_proto_rawDescData
 }
 
-var file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_pkg_proto_configuration_filesystem_virtual_virtual_proto_goTypes = []interface{}{
 	(*MountConfiguration)(nil),                     // 0: buildbarn.configuration.filesystem.virtual.MountConfiguration
 	(*FUSEMountConfiguration)(nil),                 // 1: buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration
 	(*NFSv4MountConfiguration)(nil),                // 2: buildbarn.configuration.filesystem.virtual.NFSv4MountConfiguration
-	(*NFSv4DarwinMountConfiguration)(nil),          // 3: buildbarn.configuration.filesystem.virtual.NFSv4DarwinMountConfiguration
-	(*RPCv2SystemAuthenticationConfiguration)(nil), // 4: buildbarn.configuration.filesystem.virtual.RPCv2SystemAuthenticationConfiguration
-	nil,                                  // 5: buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration.LinuxBackingDevInfoTunablesEntry
-	(*durationpb.Duration)(nil),          // 6: google.protobuf.Duration
-	(eviction.CacheReplacementPolicy)(0), // 7: buildbarn.configuration.eviction.CacheReplacementPolicy
+	(*NFSv4ClientStateLimitsConfiguration)(nil),    // 3: buildbarn.configuration.filesystem.virtual.NFSv4ClientStateLimitsConfiguration
+	(*NFSv4AttributeCachingConfiguration)(nil),     // 4: buildbarn.configuration.filesystem.virtual.NFSv4AttributeCachingConfiguration
+	(*NFSv4DarwinMountConfiguration)(nil),          // 5: buildbarn.configuration.filesystem.virtual.NFSv4DarwinMountConfiguration
+	(*NinePMountConfiguration)(nil),                // 6: buildbarn.configuration.filesystem.virtual.NinePMountConfiguration
+	(*VirtioFSMountConfiguration)(nil),             // 7: buildbarn.configuration.filesystem.virtual.VirtioFSMountConfiguration
+	(*SMBMountConfiguration)(nil),                  // 8: buildbarn.configuration.filesystem.virtual.SMBMountConfiguration
+	(*RPCv2SystemAuthenticationConfiguration)(nil), // 9: buildbarn.configuration.filesystem.virtual.RPCv2SystemAuthenticationConfiguration
+	nil,                                  // 10: buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration.LinuxBackingDevInfoTunablesEntry
+	nil,                                  // 11: buildbarn.configuration.filesystem.virtual.SMBMountConfiguration.UserNtHashesEntry
+	(*durationpb.Duration)(nil),          // 12: google.protobuf.Duration
+	(*auth.AuthorizerConfiguration)(nil), // 13: buildbarn.configuration.auth.AuthorizerConfiguration
+	(eviction.CacheReplacementPolicy)(0), // 14: buildbarn.configuration.eviction.CacheReplacementPolicy
 }
 var file_pkg_proto_configuration_filesystem_virtual_virtual_proto_depIdxs = []int32{
 	1,  // 0: buildbarn.configuration.filesystem.virtual.MountConfiguration.fuse:type_name -> buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration
 	2,  // 1: buildbarn.configuration.filesystem.virtual.MountConfiguration.nfsv4:type_name -> buildbarn.configuration.filesystem.virtual.NFSv4MountConfiguration
-	6,  // 2: buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration.directory_entry_validity:type_name -> google.protobuf.Duration
-	6,  // 3: buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration.inode_attribute_validity:type_name -> google.protobuf.Duration
-	5,  // 4: buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration.linux_backing_dev_info_tunables:type_name -> buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration.LinuxBackingDevInfoTunablesEntry
-	3,  // 5: buildbarn.configuration.filesystem.virtual.NFSv4MountConfiguration.darwin:type_name -> buildbarn.configuration.filesystem.virtual.NFSv4DarwinMountConfiguration
-	6,  // 6: buildbarn.configuration.filesystem.virtual.NFSv4MountConfiguration.enforced_lease_time:type_name -> google.protobuf.Duration
-	6,  // 7: buildbarn.configuration.filesystem.virtual.NFSv4MountConfiguration.announced_lease_time:type_name -> google.protobuf.Duration
-	4,  // 8: buildbarn.configuration.filesystem.virtual.NFSv4MountConfiguration.system_authentication:type_name -> buildbarn.configuration.filesystem.virtual.RPCv2SystemAuthenticationConfiguration
-	7,  // 9: buildbarn.configuration.filesystem.virtual.RPCv2SystemAuthenticationConfiguration.cache_replacement_policy:type_name -> buildbarn.configuration.eviction.CacheReplacementPolicy
-	10, // [10:10] is the sub-list for method output_type
-	10, // [10:10] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	6,  // 2: buildbarn.configuration.filesystem.virtual.MountConfiguration.ninep:type_name -> buildbarn.configuration.filesystem.virtual.NinePMountConfiguration
+	7,  // 3: buildbarn.configuration.filesystem.virtual.MountConfiguration.virtiofs:type_name -> buildbarn.configuration.filesystem.virtual.VirtioFSMountConfiguration
+	8,  // 4: buildbarn.configuration.filesystem.virtual.MountConfiguration.smb:type_name -> buildbarn.configuration.filesystem.virtual.SMBMountConfiguration
+	0,  // 5: buildbarn.configuration.filesystem.virtual.MountConfiguration.fallback:type_name -> buildbarn.configuration.filesystem.virtual.MountConfiguration
+	12, // 6: buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration.directory_entry_validity:type_name -> google.protobuf.Duration
+	12, // 7: buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration.inode_attribute_validity:type_name -> google.protobuf.Duration
+	10, // 8: buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration.linux_backing_dev_info_tunables:type_name -> buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration.LinuxBackingDevInfoTunablesEntry
+	12, // 9: buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration.immutable_inode_attribute_validity:type_name -> google.protobuf.Duration
+	12, // 10: buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration.immutable_directory_entry_validity:type_name -> google.protobuf.Duration
+	12, // 11: buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration.writeback_timeout:type_name -> google.protobuf.Duration
+	13, // 12: buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration.authorizer:type_name -> buildbarn.configuration.auth.AuthorizerConfiguration
+	5,  // 13: buildbarn.configuration.filesystem.virtual.NFSv4MountConfiguration.darwin:type_name -> buildbarn.configuration.filesystem.virtual.NFSv4DarwinMountConfiguration
+	12, // 14: buildbarn.configuration.filesystem.virtual.NFSv4MountConfiguration.enforced_lease_time:type_name -> google.protobuf.Duration
+	12, // 15: buildbarn.configuration.filesystem.virtual.NFSv4MountConfiguration.announced_lease_time:type_name -> google.protobuf.Duration
+	9,  // 16: buildbarn.configuration.filesystem.virtual.NFSv4MountConfiguration.system_authentication:type_name -> buildbarn.configuration.filesystem.virtual.RPCv2SystemAuthenticationConfiguration
+	4,  // 17: buildbarn.configuration.filesystem.virtual.NFSv4MountConfiguration.root_directory_attribute_caching:type_name -> buildbarn.configuration.filesystem.virtual.NFSv4AttributeCachingConfiguration
+	4,  // 18: buildbarn.configuration.filesystem.virtual.NFSv4MountConfiguration.child_directories_attribute_caching:type_name -> buildbarn.configuration.filesystem.virtual.NFSv4AttributeCachingConfiguration
+	4,  // 19: buildbarn.configuration.filesystem.virtual.NFSv4MountConfiguration.leaves_attribute_caching:type_name -> buildbarn.configuration.filesystem.virtual.NFSv4AttributeCachingConfiguration
+	3,  // 20: buildbarn.configuration.filesystem.virtual.NFSv4MountConfiguration.client_state_limits:type_name -> buildbarn.configuration.filesystem.virtual.NFSv4ClientStateLimitsConfiguration
+	12, // 21: buildbarn.configuration.filesystem.virtual.NFSv4MountConfiguration.writeback_timeout:type_name -> google.protobuf.Duration
+	12, // 22: buildbarn.configuration.filesystem.virtual.NFSv4ClientStateLimitsConfiguration.expiration_grace_period:type_name -> google.protobuf.Duration
+	12, // 23: buildbarn.configuration.filesystem.virtual.NFSv4AttributeCachingConfiguration.minimum:type_name -> google.protobuf.Duration
+	12, // 24: buildbarn.configuration.filesystem.virtual.NFSv4AttributeCachingConfiguration.maximum:type_name -> google.protobuf.Duration
+	1,  // 25: buildbarn.configuration.filesystem.virtual.VirtioFSMountConfiguration.fuse:type_name -> buildbarn.configuration.filesystem.virtual.FUSEMountConfiguration
+	11, // 26: buildbarn.configuration.filesystem.virtual.SMBMountConfiguration.user_nt_hashes:type_name -> buildbarn.configuration.filesystem.virtual.SMBMountConfiguration.UserNtHashesEntry
+	14, // 27: buildbarn.configuration.filesystem.virtual.RPCv2SystemAuthenticationConfiguration.cache_replacement_policy:type_name -> buildbarn.configuration.eviction.CacheReplacementPolicy
+	28, // [28:28] is the sub-list for method output_type
+	28, // [28:28] is the sub-list for method input_type
+	28, // [28:28] is the sub-list for extension type_name
+	28, // [28:28] is the sub-list for extension extendee
+	0,  // [0:28] is the sub-list for field type_name
 }
 
 func init() { file_pkg_proto_configuration_filesystem_virtual_virtual_proto_init() }
@@ -621,7 +1374,7 @@ func file_pkg_proto_configuration_filesystem_virtual_virtual_proto_init() {
 			}
 		}
 		file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*NFSv4DarwinMountConfiguration); i {
+			switch v := v.(*NFSv4ClientStateLimitsConfiguration); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -633,6 +1386,66 @@ func file_pkg_proto_configuration_filesystem_virtual_virtual_proto_init() {
 			}
 		}
 		file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NFSv4AttributeCachingConfiguration); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NFSv4DarwinMountConfiguration); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NinePMountConfiguration); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VirtioFSMountConfiguration); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SMBMountConfiguration); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*RPCv2SystemAuthenticationConfiguration); i {
 			case 0:
 				return &v.state
@@ -648,6 +1461,9 @@ func file_pkg_proto_configuration_filesystem_virtual_virtual_proto_init() {
 	file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[0].OneofWrappers = []interface{}{
 		(*MountConfiguration_Fuse)(nil),
 		(*MountConfiguration_Nfsv4)(nil),
+		(*MountConfiguration_Ninep)(nil),
+		(*MountConfiguration_Virtiofs)(nil),
+		(*MountConfiguration_Smb)(nil),
 	}
 	file_pkg_proto_configuration_filesystem_virtual_virtual_proto_msgTypes[2].OneofWrappers = []interface{}{
 		(*NFSv4MountConfiguration_Darwin)(nil),
@@ -658,7 +1474,7 @@ func file_pkg_proto_configuration_filesystem_virtual_virtual_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_proto_configuration_filesystem_virtual_virtual_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,492 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.32.0
+// 	protoc        v4.23.4
+// source: pkg/proto/configuration/objectstorage/objectstorage.proto
+
+package objectstorage
+
+import (
+	blobstore "github.com/buildbarn/bb-storage/pkg/proto/configuration/blobstore"
+	aws "github.com/buildbarn/bb-storage/pkg/proto/configuration/cloud/aws"
+	gcp "github.com/buildbarn/bb-storage/pkg/proto/configuration/cloud/gcp"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ObjectStoreConfiguration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Backend:
+	//
+	//	*ObjectStoreConfiguration_S3
+	//	*ObjectStoreConfiguration_Gcs
+	Backend isObjectStoreConfiguration_Backend `protobuf_oneof:"backend"`
+}
+
+func (x *ObjectStoreConfiguration) Reset() {
+	*x = ObjectStoreConfiguration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_configuration_objectstorage_objectstorage_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ObjectStoreConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObjectStoreConfiguration) ProtoMessage() {}
+
+func (x *ObjectStoreConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_configuration_objectstorage_objectstorage_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObjectStoreConfiguration.ProtoReflect.Descriptor instead.
+func (*ObjectStoreConfiguration) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_configuration_objectstorage_objectstorage_proto_rawDescGZIP(), []int{0}
+}
+
+func (m *ObjectStoreConfiguration) GetBackend() isObjectStoreConfiguration_Backend {
+	if m != nil {
+		return m.Backend
+	}
+	return nil
+}
+
+func (x *ObjectStoreConfiguration) GetS3() *S3ObjectStoreConfiguration {
+	if x, ok := x.GetBackend().(*ObjectStoreConfiguration_S3); ok {
+		return x.S3
+	}
+	return nil
+}
+
+func (x *ObjectStoreConfiguration) GetGcs() *GCSObjectStoreConfiguration {
+	if x, ok := x.GetBackend().(*ObjectStoreConfiguration_Gcs); ok {
+		return x.Gcs
+	}
+	return nil
+}
+
+type isObjectStoreConfiguration_Backend interface {
+	isObjectStoreConfiguration_Backend()
+}
+
+type ObjectStoreConfiguration_S3 struct {
+	S3 *S3ObjectStoreConfiguration `protobuf:"bytes,1,opt,name=s3,proto3,oneof"`
+}
+
+type ObjectStoreConfiguration_Gcs struct {
+	Gcs *GCSObjectStoreConfiguration `protobuf:"bytes,2,opt,name=gcs,proto3,oneof"`
+}
+
+func (*ObjectStoreConfiguration_S3) isObjectStoreConfiguration_Backend() {}
+
+func (*ObjectStoreConfiguration_Gcs) isObjectStoreConfiguration_Backend() {}
+
+type S3ObjectStoreConfiguration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AwsSession *aws.SessionConfiguration `protobuf:"bytes,1,opt,name=aws_session,json=awsSession,proto3" json:"aws_session,omitempty"`
+	Bucket     string                    `protobuf:"bytes,2,opt,name=bucket,proto3" json:"bucket,omitempty"`
+}
+
+func (x *S3ObjectStoreConfiguration) Reset() {
+	*x = S3ObjectStoreConfiguration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_configuration_objectstorage_objectstorage_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *S3ObjectStoreConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*S3ObjectStoreConfiguration) ProtoMessage() {}
+
+func (x *S3ObjectStoreConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_configuration_objectstorage_objectstorage_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use S3ObjectStoreConfiguration.ProtoReflect.Descriptor instead.
+func (*S3ObjectStoreConfiguration) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_configuration_objectstorage_objectstorage_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *S3ObjectStoreConfiguration) GetAwsSession() *aws.SessionConfiguration {
+	if x != nil {
+		return x.AwsSession
+	}
+	return nil
+}
+
+func (x *S3ObjectStoreConfiguration) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+type GCSObjectStoreConfiguration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ClientOptions *gcp.ClientOptionsConfiguration `protobuf:"bytes,1,opt,name=client_options,json=clientOptions,proto3" json:"client_options,omitempty"`
+	Bucket        string                          `protobuf:"bytes,2,opt,name=bucket,proto3" json:"bucket,omitempty"`
+}
+
+func (x *GCSObjectStoreConfiguration) Reset() {
+	*x = GCSObjectStoreConfiguration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_configuration_objectstorage_objectstorage_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GCSObjectStoreConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GCSObjectStoreConfiguration) ProtoMessage() {}
+
+func (x *GCSObjectStoreConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_configuration_objectstorage_objectstorage_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GCSObjectStoreConfiguration.ProtoReflect.Descriptor instead.
+func (*GCSObjectStoreConfiguration) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_configuration_objectstorage_objectstorage_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GCSObjectStoreConfiguration) GetClientOptions() *gcp.ClientOptionsConfiguration {
+	if x != nil {
+		return x.ClientOptions
+	}
+	return nil
+}
+
+func (x *GCSObjectStoreConfiguration) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+type OffloadingConfiguration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ObjectStore                       *ObjectStoreConfiguration          `protobuf:"bytes,1,opt,name=object_store,json=objectStore,proto3" json:"object_store,omitempty"`
+	KeyPrefix                         string                             `protobuf:"bytes,2,opt,name=key_prefix,json=keyPrefix,proto3" json:"key_prefix,omitempty"`
+	MinimumSizeBytes                  int64                              `protobuf:"varint,3,opt,name=minimum_size_bytes,json=minimumSizeBytes,proto3" json:"minimum_size_bytes,omitempty"`
+	InstanceNamePrefixes              []string                           `protobuf:"bytes,4,rep,name=instance_name_prefixes,json=instanceNamePrefixes,proto3" json:"instance_name_prefixes,omitempty"`
+	IndirectContentAddressableStorage *blobstore.BlobAccessConfiguration `protobuf:"bytes,5,opt,name=indirect_content_addressable_storage,json=indirectContentAddressableStorage,proto3" json:"indirect_content_addressable_storage,omitempty"`
+}
+
+func (x *OffloadingConfiguration) Reset() {
+	*x = OffloadingConfiguration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_configuration_objectstorage_objectstorage_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OffloadingConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OffloadingConfiguration) ProtoMessage() {}
+
+func (x *OffloadingConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_configuration_objectstorage_objectstorage_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OffloadingConfiguration.ProtoReflect.Descriptor instead.
+func (*OffloadingConfiguration) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_configuration_objectstorage_objectstorage_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *OffloadingConfiguration) GetObjectStore() *ObjectStoreConfiguration {
+	if x != nil {
+		return x.ObjectStore
+	}
+	return nil
+}
+
+func (x *OffloadingConfiguration) GetKeyPrefix() string {
+	if x != nil {
+		return x.KeyPrefix
+	}
+	return ""
+}
+
+func (x *OffloadingConfiguration) GetMinimumSizeBytes() int64 {
+	if x != nil {
+		return x.MinimumSizeBytes
+	}
+	return 0
+}
+
+func (x *OffloadingConfiguration) GetInstanceNamePrefixes() []string {
+	if x != nil {
+		return x.InstanceNamePrefixes
+	}
+	return nil
+}
+
+func (x *OffloadingConfiguration) GetIndirectContentAddressableStorage() *blobstore.BlobAccessConfiguration {
+	if x != nil {
+		return x.IndirectContentAddressableStorage
+	}
+	return nil
+}
+
+var File_pkg_proto_configuration_objectstorage_objectstorage_proto protoreflect.FileDescriptor
+
+var file_pkg_proto_configuration_objectstorage_objectstorage_proto_rawDesc = []byte{
+	0x0a, 0x39, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74,
+	0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2f, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x74,
+	0x6f, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x25, 0x62, 0x75, 0x69,
+	0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x74, 0x6f, 0x72, 0x61,
+	0x67, 0x65, 0x1a, 0x31, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x62, 0x6c, 0x6f, 0x62,
+	0x73, 0x74, 0x6f, 0x72, 0x65, 0x2f, 0x62, 0x6c, 0x6f, 0x62, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x2b, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x63,
+	0x6c, 0x6f, 0x75, 0x64, 0x2f, 0x61, 0x77, 0x73, 0x2f, 0x61, 0x77, 0x73, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x1a, 0x2b, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x63, 0x6c, 0x6f, 0x75,
+	0x64, 0x2f, 0x67, 0x63, 0x70, 0x2f, 0x67, 0x63, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
+	0xd2, 0x01, 0x0a, 0x18, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x53, 0x0a, 0x02,
+	0x73, 0x33, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x41, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65,
+	0x2e, 0x53, 0x33, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x02, 0x73,
+	0x33, 0x12, 0x56, 0x0a, 0x03, 0x67, 0x63, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x42,
+	0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73,
+	0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x47, 0x43, 0x53, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74,
+	0x53, 0x74, 0x6f, 0x72, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x48, 0x00, 0x52, 0x03, 0x67, 0x63, 0x73, 0x42, 0x09, 0x0a, 0x07, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x22, 0x8e, 0x01, 0x0a, 0x1a, 0x53, 0x33, 0x4f, 0x62, 0x6a, 0x65, 0x63,
+	0x74, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x58, 0x0a, 0x0b, 0x61, 0x77, 0x73, 0x5f, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x37, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x2e, 0x61, 0x77, 0x73, 0x2e, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x0a, 0x61, 0x77, 0x73, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a,
+	0x06, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x62,
+	0x75, 0x63, 0x6b, 0x65, 0x74, 0x22, 0x9b, 0x01, 0x0a, 0x1b, 0x47, 0x43, 0x53, 0x4f, 0x62, 0x6a,
+	0x65, 0x63, 0x74, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x64, 0x0a, 0x0e, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f,
+	0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x3d, 0x2e,
+	0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x2e, 0x67, 0x63,
+	0x70, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x63, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x62,
+	0x75, 0x63, 0x6b, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x62, 0x75, 0x63,
+	0x6b, 0x65, 0x74, 0x22, 0x8e, 0x03, 0x0a, 0x17, 0x4f, 0x66, 0x66, 0x6c, 0x6f, 0x61, 0x64, 0x69,
+	0x6e, 0x67, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x62, 0x0a, 0x0c, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x5f, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x3f, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72,
+	0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x4f, 0x62,
+	0x6a, 0x65, 0x63, 0x74, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x53, 0x74,
+	0x6f, 0x72, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6b, 0x65, 0x79, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69,
+	0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6b, 0x65, 0x79, 0x50, 0x72, 0x65, 0x66,
+	0x69, 0x78, 0x12, 0x2c, 0x0a, 0x12, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x73, 0x69,
+	0x7a, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x10,
+	0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x53, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73,
+	0x12, 0x34, 0x0a, 0x16, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x14, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x50, 0x72,
+	0x65, 0x66, 0x69, 0x78, 0x65, 0x73, 0x12, 0x8b, 0x01, 0x0a, 0x24, 0x69, 0x6e, 0x64, 0x69, 0x72,
+	0x65, 0x63, 0x74, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x3a, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72,
+	0x6e, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x62, 0x6c, 0x6f, 0x62, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x41, 0x63,
+	0x63, 0x65, 0x73, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x21, 0x69, 0x6e, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65,
+	0x6e, 0x74, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x53, 0x74, 0x6f,
+	0x72, 0x61, 0x67, 0x65, 0x42, 0x50, 0x5a, 0x4e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2f, 0x62, 0x62, 0x2d,
+	0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2d, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73,
+	0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pkg_proto_configuration_objectstorage_objectstorage_proto_rawDescOnce sync.Once
+	file_pkg_proto_configuration_objectstorage_objectstorage_proto_rawDescData = file_pkg_proto_configuration_objectstorage_objectstorage_proto_rawDesc
+)
+
+func file_pkg_proto_configuration_objectstorage_objectstorage_proto_rawDescGZIP() []byte {
+	file_pkg_proto_configuration_objectstorage_objectstorage_proto_rawDescOnce.Do(func() {
+		file_pkg_proto_configuration_objectstorage_objectstorage_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_proto_configuration_objectstorage_objectstorage_proto_rawDescData)
+	})
+	return file_pkg_proto_configuration_objectstorage_objectstorage_proto_rawDescData
+}
+
+var file_pkg_proto_configuration_objectstorage_objectstorage_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_pkg_proto_configuration_objectstorage_objectstorage_proto_goTypes = []interface{}{
+	(*ObjectStoreConfiguration)(nil),          // 0: buildbarn.configuration.objectstorage.ObjectStoreConfiguration
+	(*S3ObjectStoreConfiguration)(nil),        // 1: buildbarn.configuration.objectstorage.S3ObjectStoreConfiguration
+	(*GCSObjectStoreConfiguration)(nil),       // 2: buildbarn.configuration.objectstorage.GCSObjectStoreConfiguration
+	(*OffloadingConfiguration)(nil),           // 3: buildbarn.configuration.objectstorage.OffloadingConfiguration
+	(*aws.SessionConfiguration)(nil),          // 4: buildbarn.configuration.cloud.aws.SessionConfiguration
+	(*gcp.ClientOptionsConfiguration)(nil),    // 5: buildbarn.configuration.cloud.gcp.ClientOptionsConfiguration
+	(*blobstore.BlobAccessConfiguration)(nil), // 6: buildbarn.configuration.blobstore.BlobAccessConfiguration
+}
+var file_pkg_proto_configuration_objectstorage_objectstorage_proto_depIdxs = []int32{
+	1, // 0: buildbarn.configuration.objectstorage.ObjectStoreConfiguration.s3:type_name -> buildbarn.configuration.objectstorage.S3ObjectStoreConfiguration
+	2, // 1: buildbarn.configuration.objectstorage.ObjectStoreConfiguration.gcs:type_name -> buildbarn.configuration.objectstorage.GCSObjectStoreConfiguration
+	4, // 2: buildbarn.configuration.objectstorage.S3ObjectStoreConfiguration.aws_session:type_name -> buildbarn.configuration.cloud.aws.SessionConfiguration
+	5, // 3: buildbarn.configuration.objectstorage.GCSObjectStoreConfiguration.client_options:type_name -> buildbarn.configuration.cloud.gcp.ClientOptionsConfiguration
+	0, // 4: buildbarn.configuration.objectstorage.OffloadingConfiguration.object_store:type_name -> buildbarn.configuration.objectstorage.ObjectStoreConfiguration
+	6, // 5: buildbarn.configuration.objectstorage.OffloadingConfiguration.indirect_content_addressable_storage:type_name -> buildbarn.configuration.blobstore.BlobAccessConfiguration
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_pkg_proto_configuration_objectstorage_objectstorage_proto_init() }
+func file_pkg_proto_configuration_objectstorage_objectstorage_proto_init() {
+	if File_pkg_proto_configuration_objectstorage_objectstorage_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pkg_proto_configuration_objectstorage_objectstorage_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ObjectStoreConfiguration); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_configuration_objectstorage_objectstorage_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*S3ObjectStoreConfiguration); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_configuration_objectstorage_objectstorage_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GCSObjectStoreConfiguration); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_configuration_objectstorage_objectstorage_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OffloadingConfiguration); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_pkg_proto_configuration_objectstorage_objectstorage_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*ObjectStoreConfiguration_S3)(nil),
+		(*ObjectStoreConfiguration_Gcs)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pkg_proto_configuration_objectstorage_objectstorage_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_pkg_proto_configuration_objectstorage_objectstorage_proto_goTypes,
+		DependencyIndexes: file_pkg_proto_configuration_objectstorage_objectstorage_proto_depIdxs,
+		MessageInfos:      file_pkg_proto_configuration_objectstorage_objectstorage_proto_msgTypes,
+	}.Build()
+	File_pkg_proto_configuration_objectstorage_objectstorage_proto = out.File
+	file_pkg_proto_configuration_objectstorage_objectstorage_proto_rawDesc = nil
+	file_pkg_proto_configuration_objectstorage_objectstorage_proto_goTypes = nil
+	file_pkg_proto_configuration_objectstorage_objectstorage_proto_depIdxs = nil
+}
@@ -437,6 +437,61 @@ func (x *BatchStatRequest) GetPaths() []string {
 	return nil
 }
 
+type BatchApplyWriteBarrierRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BuildId string   `protobuf:"bytes,1,opt,name=build_id,json=buildId,proto3" json:"build_id,omitempty"`
+	Paths   []string `protobuf:"bytes,2,rep,name=paths,proto3" json:"paths,omitempty"`
+}
+
+func (x *BatchApplyWriteBarrierRequest) Reset() {
+	*x = BatchApplyWriteBarrierRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchApplyWriteBarrierRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchApplyWriteBarrierRequest) ProtoMessage() {}
+
+func (x *BatchApplyWriteBarrierRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchApplyWriteBarrierRequest.ProtoReflect.Descriptor instead.
+func (*BatchApplyWriteBarrierRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchApplyWriteBarrierRequest) GetBuildId() string {
+	if x != nil {
+		return x.BuildId
+	}
+	return ""
+}
+
+func (x *BatchApplyWriteBarrierRequest) GetPaths() []string {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
 type BatchStatResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -448,7 +503,7 @@ type BatchStatResponse struct {
 func (x *BatchStatResponse) Reset() {
 	*x = BatchStatResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[6]
+		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -461,7 +516,7 @@ func (x *BatchStatResponse) String() string {
 func (*BatchStatResponse) ProtoMessage() {}
 
 func (x *BatchStatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[6]
+	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -474,7 +529,7 @@ func (x *BatchStatResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchStatResponse.ProtoReflect.Descriptor instead.
 func (*BatchStatResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{6}
+	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *BatchStatResponse) GetResponses() []*StatResponse {
@@ -484,6 +539,124 @@ func (x *BatchStatResponse) GetResponses() []*StatResponse {
 	return nil
 }
 
+type ReadDirectoryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BuildId                   string `protobuf:"bytes,1,opt,name=build_id,json=buildId,proto3" json:"build_id,omitempty"`
+	IncludeFileDigest         bool   `protobuf:"varint,2,opt,name=include_file_digest,json=includeFileDigest,proto3" json:"include_file_digest,omitempty"`
+	Path                      string `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	MaximumEntriesPerResponse uint32 `protobuf:"varint,4,opt,name=maximum_entries_per_response,json=maximumEntriesPerResponse,proto3" json:"maximum_entries_per_response,omitempty"`
+}
+
+func (x *ReadDirectoryRequest) Reset() {
+	*x = ReadDirectoryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReadDirectoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadDirectoryRequest) ProtoMessage() {}
+
+func (x *ReadDirectoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadDirectoryRequest.ProtoReflect.Descriptor instead.
+func (*ReadDirectoryRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ReadDirectoryRequest) GetBuildId() string {
+	if x != nil {
+		return x.BuildId
+	}
+	return ""
+}
+
+func (x *ReadDirectoryRequest) GetIncludeFileDigest() bool {
+	if x != nil {
+		return x.IncludeFileDigest
+	}
+	return false
+}
+
+func (x *ReadDirectoryRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ReadDirectoryRequest) GetMaximumEntriesPerResponse() uint32 {
+	if x != nil {
+		return x.MaximumEntriesPerResponse
+	}
+	return 0
+}
+
+type ReadDirectoryResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Entries []*ReadDirectoryResponse_Entry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *ReadDirectoryResponse) Reset() {
+	*x = ReadDirectoryResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReadDirectoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadDirectoryResponse) ProtoMessage() {}
+
+func (x *ReadDirectoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadDirectoryResponse.ProtoReflect.Descriptor instead.
+func (*ReadDirectoryResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ReadDirectoryResponse) GetEntries() []*ReadDirectoryResponse_Entry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
 type StatResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -495,7 +668,7 @@ type StatResponse struct {
 func (x *StatResponse) Reset() {
 	*x = StatResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[7]
+		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -508,7 +681,7 @@ func (x *StatResponse) String() string {
 func (*StatResponse) ProtoMessage() {}
 
 func (x *StatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[7]
+	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -521,7 +694,7 @@ func (x *StatResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatResponse.ProtoReflect.Descriptor instead.
 func (*StatResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{7}
+	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *StatResponse) GetFileStatus() *FileStatus {
@@ -548,7 +721,7 @@ type FileStatus struct {
 func (x *FileStatus) Reset() {
 	*x = FileStatus{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[8]
+		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -561,7 +734,7 @@ func (x *FileStatus) String() string {
 func (*FileStatus) ProtoMessage() {}
 
 func (x *FileStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[8]
+	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -574,7 +747,7 @@ func (x *FileStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileStatus.ProtoReflect.Descriptor instead.
 func (*FileStatus) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{8}
+	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{11}
 }
 
 func (m *FileStatus) GetFileType() isFileStatus_FileType {
@@ -652,7 +825,7 @@ type FinalizeBuildRequest struct {
 func (x *FinalizeBuildRequest) Reset() {
 	*x = FinalizeBuildRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[9]
+		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -665,7 +838,7 @@ func (x *FinalizeBuildRequest) String() string {
 func (*FinalizeBuildRequest) ProtoMessage() {}
 
 func (x *FinalizeBuildRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[9]
+	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -678,7 +851,7 @@ func (x *FinalizeBuildRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FinalizeBuildRequest.ProtoReflect.Descriptor instead.
 func (*FinalizeBuildRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{9}
+	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *FinalizeBuildRequest) GetBuildId() string {
@@ -695,6 +868,61 @@ func (x *FinalizeBuildRequest) GetBuildSuccessful() bool {
 	return false
 }
 
+type ReadDirectoryResponse_Entry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name       string      `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	FileStatus *FileStatus `protobuf:"bytes,2,opt,name=file_status,json=fileStatus,proto3" json:"file_status,omitempty"`
+}
+
+func (x *ReadDirectoryResponse_Entry) Reset() {
+	*x = ReadDirectoryResponse_Entry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReadDirectoryResponse_Entry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadDirectoryResponse_Entry) ProtoMessage() {}
+
+func (x *ReadDirectoryResponse_Entry) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadDirectoryResponse_Entry.ProtoReflect.Descriptor instead.
+func (*ReadDirectoryResponse_Entry) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{9, 0}
+}
+
+func (x *ReadDirectoryResponse_Entry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ReadDirectoryResponse_Entry) GetFileStatus() *FileStatus {
+	if x != nil {
+		return x.FileStatus
+	}
+	return nil
+}
+
 type FileStatus_File struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -706,7 +934,7 @@ type FileStatus_File struct {
 func (x *FileStatus_File) Reset() {
 	*x = FileStatus_File{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[11]
+		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -719,7 +947,7 @@ func (x *FileStatus_File) String() string {
 func (*FileStatus_File) ProtoMessage() {}
 
 func (x *FileStatus_File) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[11]
+	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -732,7 +960,7 @@ func (x *FileStatus_File) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileStatus_File.ProtoReflect.Descriptor instead.
 func (*FileStatus_File) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{8, 0}
+	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{11, 0}
 }
 
 func (x *FileStatus_File) GetDigest() *v2.Digest {
@@ -753,7 +981,7 @@ type FileStatus_Symlink struct {
 func (x *FileStatus_Symlink) Reset() {
 	*x = FileStatus_Symlink{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[12]
+		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -766,7 +994,7 @@ func (x *FileStatus_Symlink) String() string {
 func (*FileStatus_Symlink) ProtoMessage() {}
 
 func (x *FileStatus_Symlink) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[12]
+	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -779,7 +1007,7 @@ func (x *FileStatus_Symlink) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileStatus_Symlink.ProtoReflect.Descriptor instead.
 func (*FileStatus_Symlink) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{8, 1}
+	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{11, 1}
 }
 
 func (x *FileStatus_Symlink) GetTarget() string {
@@ -800,7 +1028,7 @@ type FileStatus_Directory struct {
 func (x *FileStatus_Directory) Reset() {
 	*x = FileStatus_Directory{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[13]
+		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -813,7 +1041,7 @@ func (x *FileStatus_Directory) String() string {
 func (*FileStatus_Directory) ProtoMessage() {}
 
 func (x *FileStatus_Directory) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[13]
+	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -826,7 +1054,7 @@ func (x *FileStatus_Directory) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileStatus_Directory.ProtoReflect.Descriptor instead.
 func (*FileStatus_Directory) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{8, 2}
+	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{11, 2}
 }
 
 func (x *FileStatus_Directory) GetLastModifiedTime() *timestamppb.Timestamp {
@@ -847,7 +1075,7 @@ type FileStatus_External struct {
 func (x *FileStatus_External) Reset() {
 	*x = FileStatus_External{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[14]
+		mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -860,7 +1088,7 @@ func (x *FileStatus_External) String() string {
 func (*FileStatus_External) ProtoMessage() {}
 
 func (x *FileStatus_External) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[14]
+	mi := &file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -873,7 +1101,7 @@ func (x *FileStatus_External) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileStatus_External.ProtoReflect.Descriptor instead.
 func (*FileStatus_External) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{8, 3}
+	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(), []int{11, 3}
 }
 
 func (x *FileStatus_External) GetNextPath() string {
@@ -982,93 +1210,135 @@ var file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDesc = []b
 	0x0a, 0x0f, 0x66, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x73, 0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b,
 	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x66, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x53,
 	0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x61, 0x74, 0x68, 0x73,
-	0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x70, 0x61, 0x74, 0x68, 0x73, 0x22, 0x56, 0x0a,
-	0x11, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x74, 0x61, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x41, 0x0a, 0x09, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x73, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f,
-	0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x74,
-	0x61, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x09, 0x72, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x73, 0x22, 0x52, 0x0a, 0x0c, 0x53, 0x74, 0x61, 0x74, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x0b, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x72, 0x65, 0x6d,
+	0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x70, 0x61, 0x74, 0x68, 0x73, 0x22, 0x50, 0x0a,
+	0x1d, 0x42, 0x61, 0x74, 0x63, 0x68, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x57, 0x72, 0x69, 0x74, 0x65,
+	0x42, 0x61, 0x72, 0x72, 0x69, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19,
+	0x0a, 0x08, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x61, 0x74,
+	0x68, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x70, 0x61, 0x74, 0x68, 0x73, 0x22,
+	0x56, 0x0a, 0x11, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x74, 0x61, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a, 0x09, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65,
+	0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
+	0x53, 0x74, 0x61, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x09, 0x72, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x73, 0x22, 0xb6, 0x01, 0x0a, 0x14, 0x52, 0x65, 0x61, 0x64,
+	0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x19, 0x0a, 0x08, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x49, 0x64, 0x12, 0x2e, 0x0a, 0x13, 0x69,
+	0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x64, 0x69, 0x67, 0x65,
+	0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64,
+	0x65, 0x46, 0x69, 0x6c, 0x65, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70,
+	0x61, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12,
+	0x3f, 0x0a, 0x1c, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x69,
+	0x65, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x19, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x45, 0x6e,
+	0x74, 0x72, 0x69, 0x65, 0x73, 0x50, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0xc6, 0x01, 0x0a, 0x15, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f,
+	0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4c, 0x0a, 0x07, 0x65, 0x6e,
+	0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x72, 0x65,
+	0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x1a, 0x5f, 0x0a, 0x05, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x42, 0x0a, 0x0b, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x72, 0x65, 0x6d,
 	0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69,
 	0x63, 0x65, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x0a, 0x66,
-	0x69, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0xa1, 0x04, 0x0a, 0x0a, 0x46, 0x69,
-	0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x3c, 0x0a, 0x04, 0x66, 0x69, 0x6c, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f,
-	0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x46,
-	0x69, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x48, 0x00,
-	0x52, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x45, 0x0a, 0x07, 0x73, 0x79, 0x6d, 0x6c, 0x69, 0x6e,
-	0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65,
-	0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
-	0x46, 0x69, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x53, 0x79, 0x6d, 0x6c, 0x69,
-	0x6e, 0x6b, 0x48, 0x00, 0x52, 0x07, 0x73, 0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x12, 0x4b, 0x0a,
-	0x09, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x2b, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74,
+	0x69, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x52, 0x0a, 0x0c, 0x53, 0x74, 0x61,
+	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x0b, 0x66, 0x69, 0x6c,
+	0x65, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21,
+	0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x52, 0x0a, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0xa1, 0x04,
+	0x0a, 0x0a, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x3c, 0x0a, 0x04,
+	0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x72, 0x65, 0x6d,
+	0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x46, 0x69,
+	0x6c, 0x65, 0x48, 0x00, 0x52, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x45, 0x0a, 0x07, 0x73, 0x79,
+	0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x72, 0x65,
+	0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x53,
+	0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x48, 0x00, 0x52, 0x07, 0x73, 0x79, 0x6d, 0x6c, 0x69, 0x6e,
+	0x6b, 0x12, 0x4b, 0x0a, 0x09, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75,
+	0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x46, 0x69, 0x6c,
+	0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x79, 0x48, 0x00, 0x52, 0x09, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x48,
+	0x0a, 0x08, 0x65, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x2a, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74,
 	0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x2e, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x48, 0x00, 0x52,
-	0x09, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x48, 0x0a, 0x08, 0x65, 0x78,
-	0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x72,
-	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72,
-	0x76, 0x69, 0x63, 0x65, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e,
-	0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x48, 0x00, 0x52, 0x08, 0x65, 0x78, 0x74, 0x65,
-	0x72, 0x6e, 0x61, 0x6c, 0x1a, 0x47, 0x0a, 0x04, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x3f, 0x0a, 0x06,
-	0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x62,
-	0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74,
-	0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x44,
-	0x69, 0x67, 0x65, 0x73, 0x74, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x0a,
-	0x07, 0x53, 0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x72, 0x67,
-	0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74,
-	0x1a, 0x55, 0x0a, 0x09, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x48, 0x0a,
-	0x12, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x5f, 0x74,
-	0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
-	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x10, 0x6c, 0x61, 0x73, 0x74, 0x4d, 0x6f, 0x64, 0x69, 0x66,
-	0x69, 0x65, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x1a, 0x27, 0x0a, 0x08, 0x45, 0x78, 0x74, 0x65, 0x72,
-	0x6e, 0x61, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x74, 0x68,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x74, 0x68,
-	0x42, 0x0b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x22, 0x5c, 0x0a,
-	0x14, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x5f, 0x69,
-	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x49, 0x64,
-	0x12, 0x29, 0x0a, 0x10, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x5f, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73,
-	0x73, 0x66, 0x75, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x62, 0x75, 0x69, 0x6c,
-	0x64, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x66, 0x75, 0x6c, 0x32, 0xc6, 0x03, 0x0a, 0x13,
-	0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x53, 0x65, 0x72, 0x76,
-	0x69, 0x63, 0x65, 0x12, 0x44, 0x0a, 0x05, 0x43, 0x6c, 0x65, 0x61, 0x6e, 0x12, 0x23, 0x2e, 0x72,
+	0x74, 0x75, 0x73, 0x2e, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x48, 0x00, 0x52, 0x08,
+	0x65, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x1a, 0x47, 0x0a, 0x04, 0x46, 0x69, 0x6c, 0x65,
+	0x12, 0x3f, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x27, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x76, 0x32, 0x2e, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73,
+	0x74, 0x1a, 0x21, 0x0a, 0x07, 0x53, 0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x12, 0x16, 0x0a, 0x06,
+	0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61,
+	0x72, 0x67, 0x65, 0x74, 0x1a, 0x55, 0x0a, 0x09, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x79, 0x12, 0x48, 0x0a, 0x12, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69,
+	0x65, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x10, 0x6c, 0x61, 0x73, 0x74, 0x4d,
+	0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x1a, 0x27, 0x0a, 0x08, 0x45,
+	0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x65, 0x78, 0x74, 0x5f,
+	0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6e, 0x65, 0x78, 0x74,
+	0x50, 0x61, 0x74, 0x68, 0x42, 0x0b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x74, 0x79, 0x70,
+	0x65, 0x22, 0x5c, 0x0a, 0x14, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x42, 0x75, 0x69,
+	0x6c, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x75, 0x69,
+	0x6c, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x75, 0x69,
+	0x6c, 0x64, 0x49, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x5f, 0x73, 0x75,
+	0x63, 0x63, 0x65, 0x73, 0x73, 0x66, 0x75, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f,
+	0x62, 0x75, 0x69, 0x6c, 0x64, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x66, 0x75, 0x6c, 0x32,
+	0x9c, 0x05, 0x0a, 0x13, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x44, 0x0a, 0x05, 0x43, 0x6c, 0x65, 0x61, 0x6e,
+	0x12, 0x23, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74,
+	0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x43, 0x6c, 0x65, 0x61, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x61, 0x0a,
+	0x0a, 0x53, 0x74, 0x61, 0x72, 0x74, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x12, 0x28, 0x2e, 0x72, 0x65,
+	0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x29, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f,
+	0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x74,
+	0x61, 0x72, 0x74, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x50, 0x0a, 0x0b, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12,
+	0x29, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x12, 0x5e, 0x0a, 0x09, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x74, 0x61, 0x74, 0x12,
+	0x27, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x74, 0x61,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x74, 0x61, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x66, 0x0a, 0x16, 0x42, 0x61, 0x74, 0x63, 0x68, 0x41, 0x70, 0x70, 0x6c, 0x79,
+	0x57, 0x72, 0x69, 0x74, 0x65, 0x42, 0x61, 0x72, 0x72, 0x69, 0x65, 0x72, 0x12, 0x34, 0x2e, 0x72,
 	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72,
-	0x76, 0x69, 0x63, 0x65, 0x2e, 0x43, 0x6c, 0x65, 0x61, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x61, 0x0a, 0x0a, 0x53, 0x74, 0x61,
-	0x72, 0x74, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x12, 0x28, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65,
-	0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
-	0x53, 0x74, 0x61, 0x72, 0x74, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x29, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75,
-	0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x42,
-	0x75, 0x69, 0x6c, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x50, 0x0a, 0x0b,
-	0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x29, 0x2e, 0x72, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x57,
+	0x72, 0x69, 0x74, 0x65, 0x42, 0x61, 0x72, 0x72, 0x69, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x6c, 0x0a, 0x0d, 0x52, 0x65,
+	0x61, 0x64, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x2b, 0x2e, 0x72, 0x65,
 	0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76,
-	0x69, 0x63, 0x65, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52,
+	0x69, 0x63, 0x65, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2c, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x2e, 0x52, 0x65, 0x61, 0x64, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x54, 0x0a, 0x0d, 0x46, 0x69, 0x6e, 0x61,
+	0x6c, 0x69, 0x7a, 0x65, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x12, 0x2b, 0x2e, 0x72, 0x65, 0x6d, 0x6f,
+	0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x2e, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x52,
 	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x5e,
-	0x0a, 0x09, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x74, 0x61, 0x74, 0x12, 0x27, 0x2e, 0x72, 0x65,
-	0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76,
-	0x69, 0x63, 0x65, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x74, 0x61, 0x74, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75,
-	0x74, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x42, 0x61, 0x74,
-	0x63, 0x68, 0x53, 0x74, 0x61, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x54,
-	0x0a, 0x0d, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x12,
-	0x2b, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f,
-	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65,
-	0x42, 0x75, 0x69, 0x6c, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67,
-	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45,
-	0x6d, 0x70, 0x74, 0x79, 0x42, 0x55, 0x0a, 0x24, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2e, 0x64, 0x65, 0x76, 0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2e, 0x62, 0x75, 0x69, 0x6c,
-	0x64, 0x2e, 0x6c, 0x69, 0x62, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x42, 0x18, 0x52, 0x65,
-	0x6d, 0x6f, 0x74, 0x65, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
-	0x65, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x5a, 0x13, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x6f, 0x75,
-	0x74, 0x70, 0x75, 0x74, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x55,
+	0x0a, 0x24, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x64, 0x65, 0x76,
+	0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2e, 0x6c, 0x69, 0x62, 0x2e,
+	0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x42, 0x18, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x4f, 0x75,
+	0x74, 0x70, 0x75, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x50, 0x72, 0x6f, 0x74, 0x6f,
+	0x5a, 0x13, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1083,61 +1353,71 @@ func file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescGZIP(
 	return file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDescData
 }
 
-var file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
 var file_pkg_proto_remoteoutputservice_remote_output_service_proto_goTypes = []interface{}{
-	(*CleanRequest)(nil),              // 0: remote_output_service.CleanRequest
-	(*StartBuildRequest)(nil),         // 1: remote_output_service.StartBuildRequest
-	(*InitialOutputPathContents)(nil), // 2: remote_output_service.InitialOutputPathContents
-	(*StartBuildResponse)(nil),        // 3: remote_output_service.StartBuildResponse
-	(*BatchCreateRequest)(nil),        // 4: remote_output_service.BatchCreateRequest
-	(*BatchStatRequest)(nil),          // 5: remote_output_service.BatchStatRequest
-	(*BatchStatResponse)(nil),         // 6: remote_output_service.BatchStatResponse
-	(*StatResponse)(nil),              // 7: remote_output_service.StatResponse
-	(*FileStatus)(nil),                // 8: remote_output_service.FileStatus
-	(*FinalizeBuildRequest)(nil),      // 9: remote_output_service.FinalizeBuildRequest
-	nil,                               // 10: remote_output_service.StartBuildRequest.OutputPathAliasesEntry
-	(*FileStatus_File)(nil),           // 11: remote_output_service.FileStatus.File
-	(*FileStatus_Symlink)(nil),        // 12: remote_output_service.FileStatus.Symlink
-	(*FileStatus_Directory)(nil),      // 13: remote_output_service.FileStatus.Directory
-	(*FileStatus_External)(nil),       // 14: remote_output_service.FileStatus.External
-	(v2.DigestFunction_Value)(0),      // 15: build.bazel.remote.execution.v2.DigestFunction.Value
-	(*v2.OutputFile)(nil),             // 16: build.bazel.remote.execution.v2.OutputFile
-	(*v2.OutputSymlink)(nil),          // 17: build.bazel.remote.execution.v2.OutputSymlink
-	(*v2.OutputDirectory)(nil),        // 18: build.bazel.remote.execution.v2.OutputDirectory
-	(*v2.Digest)(nil),                 // 19: build.bazel.remote.execution.v2.Digest
-	(*timestamppb.Timestamp)(nil),     // 20: google.protobuf.Timestamp
-	(*emptypb.Empty)(nil),             // 21: google.protobuf.Empty
+	(*CleanRequest)(nil),                  // 0: remote_output_service.CleanRequest
+	(*StartBuildRequest)(nil),             // 1: remote_output_service.StartBuildRequest
+	(*InitialOutputPathContents)(nil),     // 2: remote_output_service.InitialOutputPathContents
+	(*StartBuildResponse)(nil),            // 3: remote_output_service.StartBuildResponse
+	(*BatchCreateRequest)(nil),            // 4: remote_output_service.BatchCreateRequest
+	(*BatchStatRequest)(nil),              // 5: remote_output_service.BatchStatRequest
+	(*BatchApplyWriteBarrierRequest)(nil), // 6: remote_output_service.BatchApplyWriteBarrierRequest
+	(*BatchStatResponse)(nil),             // 7: remote_output_service.BatchStatResponse
+	(*ReadDirectoryRequest)(nil),          // 8: remote_output_service.ReadDirectoryRequest
+	(*ReadDirectoryResponse)(nil),         // 9: remote_output_service.ReadDirectoryResponse
+	(*StatResponse)(nil),                  // 10: remote_output_service.StatResponse
+	(*FileStatus)(nil),                    // 11: remote_output_service.FileStatus
+	(*FinalizeBuildRequest)(nil),          // 12: remote_output_service.FinalizeBuildRequest
+	nil,                                   // 13: remote_output_service.StartBuildRequest.OutputPathAliasesEntry
+	(*ReadDirectoryResponse_Entry)(nil),   // 14: remote_output_service.ReadDirectoryResponse.Entry
+	(*FileStatus_File)(nil),               // 15: remote_output_service.FileStatus.File
+	(*FileStatus_Symlink)(nil),            // 16: remote_output_service.FileStatus.Symlink
+	(*FileStatus_Directory)(nil),          // 17: remote_output_service.FileStatus.Directory
+	(*FileStatus_External)(nil),           // 18: remote_output_service.FileStatus.External
+	(v2.DigestFunction_Value)(0),          // 19: build.bazel.remote.execution.v2.DigestFunction.Value
+	(*v2.OutputFile)(nil),                 // 20: build.bazel.remote.execution.v2.OutputFile
+	(*v2.OutputSymlink)(nil),              // 21: build.bazel.remote.execution.v2.OutputSymlink
+	(*v2.OutputDirectory)(nil),            // 22: build.bazel.remote.execution.v2.OutputDirectory
+	(*v2.Digest)(nil),                     // 23: build.bazel.remote.execution.v2.Digest
+	(*timestamppb.Timestamp)(nil),         // 24: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),                 // 25: google.protobuf.Empty
 }
 var file_pkg_proto_remoteoutputservice_remote_output_service_proto_depIdxs = []int32{
-	15, // 0: remote_output_service.StartBuildRequest.digest_function:type_name -> build.bazel.remote.execution.v2.DigestFunction.Value
-	10, // 1: remote_output_service.StartBuildRequest.output_path_aliases:type_name -> remote_output_service.StartBuildRequest.OutputPathAliasesEntry
+	19, // 0: remote_output_service.StartBuildRequest.digest_function:type_name -> build.bazel.remote.execution.v2.DigestFunction.Value
+	13, // 1: remote_output_service.StartBuildRequest.output_path_aliases:type_name -> remote_output_service.StartBuildRequest.OutputPathAliasesEntry
 	2,  // 2: remote_output_service.StartBuildResponse.initial_output_path_contents:type_name -> remote_output_service.InitialOutputPathContents
-	16, // 3: remote_output_service.BatchCreateRequest.files:type_name -> build.bazel.remote.execution.v2.OutputFile
-	17, // 4: remote_output_service.BatchCreateRequest.symlinks:type_name -> build.bazel.remote.execution.v2.OutputSymlink
-	18, // 5: remote_output_service.BatchCreateRequest.directories:type_name -> build.bazel.remote.execution.v2.OutputDirectory
-	7,  // 6: remote_output_service.BatchStatResponse.responses:type_name -> remote_output_service.StatResponse
-	8,  // 7: remote_output_service.StatResponse.file_status:type_name -> remote_output_service.FileStatus
-	11, // 8: remote_output_service.FileStatus.file:type_name -> remote_output_service.FileStatus.File
-	12, // 9: remote_output_service.FileStatus.symlink:type_name -> remote_output_service.FileStatus.Symlink
-	13, // 10: remote_output_service.FileStatus.directory:type_name -> remote_output_service.FileStatus.Directory
-	14, // 11: remote_output_service.FileStatus.external:type_name -> remote_output_service.FileStatus.External
-	19, // 12: remote_output_service.FileStatus.File.digest:type_name -> build.bazel.remote.execution.v2.Digest
-	20, // 13: remote_output_service.FileStatus.Directory.last_modified_time:type_name -> google.protobuf.Timestamp
-	0,  // 14: remote_output_service.RemoteOutputService.Clean:input_type -> remote_output_service.CleanRequest
-	1,  // 15: remote_output_service.RemoteOutputService.StartBuild:input_type -> remote_output_service.StartBuildRequest
-	4,  // 16: remote_output_service.RemoteOutputService.BatchCreate:input_type -> remote_output_service.BatchCreateRequest
-	5,  // 17: remote_output_service.RemoteOutputService.BatchStat:input_type -> remote_output_service.BatchStatRequest
-	9,  // 18: remote_output_service.RemoteOutputService.FinalizeBuild:input_type -> remote_output_service.FinalizeBuildRequest
-	21, // 19: remote_output_service.RemoteOutputService.Clean:output_type -> google.protobuf.Empty
-	3,  // 20: remote_output_service.RemoteOutputService.StartBuild:output_type -> remote_output_service.StartBuildResponse
-	21, // 21: remote_output_service.RemoteOutputService.BatchCreate:output_type -> google.protobuf.Empty
-	6,  // 22: remote_output_service.RemoteOutputService.BatchStat:output_type -> remote_output_service.BatchStatResponse
-	21, // 23: remote_output_service.RemoteOutputService.FinalizeBuild:output_type -> google.protobuf.Empty
-	19, // [19:24] is the sub-list for method output_type
-	14, // [14:19] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	20, // 3: remote_output_service.BatchCreateRequest.files:type_name -> build.bazel.remote.execution.v2.OutputFile
+	21, // 4: remote_output_service.BatchCreateRequest.symlinks:type_name -> build.bazel.remote.execution.v2.OutputSymlink
+	22, // 5: remote_output_service.BatchCreateRequest.directories:type_name -> build.bazel.remote.execution.v2.OutputDirectory
+	10, // 6: remote_output_service.BatchStatResponse.responses:type_name -> remote_output_service.StatResponse
+	14, // 7: remote_output_service.ReadDirectoryResponse.entries:type_name -> remote_output_service.ReadDirectoryResponse.Entry
+	11, // 8: remote_output_service.StatResponse.file_status:type_name -> remote_output_service.FileStatus
+	15, // 9: remote_output_service.FileStatus.file:type_name -> remote_output_service.FileStatus.File
+	16, // 10: remote_output_service.FileStatus.symlink:type_name -> remote_output_service.FileStatus.Symlink
+	17, // 11: remote_output_service.FileStatus.directory:type_name -> remote_output_service.FileStatus.Directory
+	18, // 12: remote_output_service.FileStatus.external:type_name -> remote_output_service.FileStatus.External
+	11, // 13: remote_output_service.ReadDirectoryResponse.Entry.file_status:type_name -> remote_output_service.FileStatus
+	23, // 14: remote_output_service.FileStatus.File.digest:type_name -> build.bazel.remote.execution.v2.Digest
+	24, // 15: remote_output_service.FileStatus.Directory.last_modified_time:type_name -> google.protobuf.Timestamp
+	0,  // 16: remote_output_service.RemoteOutputService.Clean:input_type -> remote_output_service.CleanRequest
+	1,  // 17: remote_output_service.RemoteOutputService.StartBuild:input_type -> remote_output_service.StartBuildRequest
+	4,  // 18: remote_output_service.RemoteOutputService.BatchCreate:input_type -> remote_output_service.BatchCreateRequest
+	5,  // 19: remote_output_service.RemoteOutputService.BatchStat:input_type -> remote_output_service.BatchStatRequest
+	6,  // 20: remote_output_service.RemoteOutputService.BatchApplyWriteBarrier:input_type -> remote_output_service.BatchApplyWriteBarrierRequest
+	8,  // 21: remote_output_service.RemoteOutputService.ReadDirectory:input_type -> remote_output_service.ReadDirectoryRequest
+	12, // 22: remote_output_service.RemoteOutputService.FinalizeBuild:input_type -> remote_output_service.FinalizeBuildRequest
+	25, // 23: remote_output_service.RemoteOutputService.Clean:output_type -> google.protobuf.Empty
+	3,  // 24: remote_output_service.RemoteOutputService.StartBuild:output_type -> remote_output_service.StartBuildResponse
+	25, // 25: remote_output_service.RemoteOutputService.BatchCreate:output_type -> google.protobuf.Empty
+	7,  // 26: remote_output_service.RemoteOutputService.BatchStat:output_type -> remote_output_service.BatchStatResponse
+	25, // 27: remote_output_service.RemoteOutputService.BatchApplyWriteBarrier:output_type -> google.protobuf.Empty
+	9,  // 28: remote_output_service.RemoteOutputService.ReadDirectory:output_type -> remote_output_service.ReadDirectoryResponse
+	25, // 29: remote_output_service.RemoteOutputService.FinalizeBuild:output_type -> google.protobuf.Empty
+	23, // [23:30] is the sub-list for method output_type
+	16, // [16:23] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_pkg_proto_remoteoutputservice_remote_output_service_proto_init() }
@@ -1219,7 +1499,7 @@ func file_pkg_proto_remoteoutputservice_remote_output_service_proto_init() {
 			}
 		}
 		file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BatchStatResponse); i {
+			switch v := v.(*BatchApplyWriteBarrierRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1231,7 +1511,7 @@ func file_pkg_proto_remoteoutputservice_remote_output_service_proto_init() {
 			}
 		}
 		file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StatResponse); i {
+			switch v := v.(*BatchStatResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1243,7 +1523,7 @@ func file_pkg_proto_remoteoutputservice_remote_output_service_proto_init() {
 			}
 		}
 		file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*FileStatus); i {
+			switch v := v.(*ReadDirectoryRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1255,7 +1535,19 @@ func file_pkg_proto_remoteoutputservice_remote_output_service_proto_init() {
 			}
 		}
 		file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*FinalizeBuildRequest); i {
+			switch v := v.(*ReadDirectoryResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1267,7 +1559,7 @@ func file_pkg_proto_remoteoutputservice_remote_output_service_proto_init() {
 			}
 		}
 		file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*FileStatus_File); i {
+			switch v := v.(*FileStatus); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1279,6 +1571,42 @@ func file_pkg_proto_remoteoutputservice_remote_output_service_proto_init() {
 			}
 		}
 		file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FinalizeBuildRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReadDirectoryResponse_Entry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FileStatus_File); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*FileStatus_Symlink); i {
 			case 0:
 				return &v.state
@@ -1290,7 +1618,7 @@ func file_pkg_proto_remoteoutputservice_remote_output_service_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*FileStatus_Directory); i {
 			case 0:
 				return &v.state
@@ -1302,7 +1630,7 @@ func file_pkg_proto_remoteoutputservice_remote_output_service_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*FileStatus_External); i {
 			case 0:
 				return &v.state
@@ -1315,7 +1643,7 @@ func file_pkg_proto_remoteoutputservice_remote_output_service_proto_init() {
 			}
 		}
 	}
-	file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[8].OneofWrappers = []interface{}{
+	file_pkg_proto_remoteoutputservice_remote_output_service_proto_msgTypes[11].OneofWrappers = []interface{}{
 		(*FileStatus_File_)(nil),
 		(*FileStatus_Symlink_)(nil),
 		(*FileStatus_Directory_)(nil),
@@ -1327,7 +1655,7 @@ func file_pkg_proto_remoteoutputservice_remote_output_service_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_proto_remoteoutputservice_remote_output_service_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   15,
+			NumMessages:   19,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -1357,6 +1685,8 @@ type RemoteOutputServiceClient interface {
 	StartBuild(ctx context.Context, in *StartBuildRequest, opts ...grpc.CallOption) (*StartBuildResponse, error)
 	BatchCreate(ctx context.Context, in *BatchCreateRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	BatchStat(ctx context.Context, in *BatchStatRequest, opts ...grpc.CallOption) (*BatchStatResponse, error)
+	BatchApplyWriteBarrier(ctx context.Context, in *BatchApplyWriteBarrierRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ReadDirectory(ctx context.Context, in *ReadDirectoryRequest, opts ...grpc.CallOption) (RemoteOutputService_ReadDirectoryClient, error)
 	FinalizeBuild(ctx context.Context, in *FinalizeBuildRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
@@ -1404,6 +1734,47 @@ func (c *remoteOutputServiceClient) BatchStat(ctx context.Context, in *BatchStat
 	return out, nil
 }
 
+func (c *remoteOutputServiceClient) BatchApplyWriteBarrier(ctx context.Context, in *BatchApplyWriteBarrierRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/remote_output_service.RemoteOutputService/BatchApplyWriteBarrier", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remoteOutputServiceClient) ReadDirectory(ctx context.Context, in *ReadDirectoryRequest, opts ...grpc.CallOption) (RemoteOutputService_ReadDirectoryClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_RemoteOutputService_serviceDesc.Streams[0], "/remote_output_service.RemoteOutputService/ReadDirectory", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &remoteOutputServiceReadDirectoryClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RemoteOutputService_ReadDirectoryClient interface {
+	Recv() (*ReadDirectoryResponse, error)
+	grpc.ClientStream
+}
+
+type remoteOutputServiceReadDirectoryClient struct {
+	grpc.ClientStream
+}
+
+func (x *remoteOutputServiceReadDirectoryClient) Recv() (*ReadDirectoryResponse, error) {
+	m := new(ReadDirectoryResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *remoteOutputServiceClient) FinalizeBuild(ctx context.Context, in *FinalizeBuildRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	out := new(emptypb.Empty)
 	err := c.cc.Invoke(ctx, "/remote_output_service.RemoteOutputService/FinalizeBuild", in, out, opts...)
@@ -1419,6 +1790,8 @@ type RemoteOutputServiceServer interface {
 	StartBuild(context.Context, *StartBuildRequest) (*StartBuildResponse, error)
 	BatchCreate(context.Context, *BatchCreateRequest) (*emptypb.Empty, error)
 	BatchStat(context.Context, *BatchStatRequest) (*BatchStatResponse, error)
+	BatchApplyWriteBarrier(context.Context, *BatchApplyWriteBarrierRequest) (*emptypb.Empty, error)
+	ReadDirectory(*ReadDirectoryRequest, RemoteOutputService_ReadDirectoryServer) error
 	FinalizeBuild(context.Context, *FinalizeBuildRequest) (*emptypb.Empty, error)
 }
 
@@ -1438,6 +1811,12 @@ func (*UnimplementedRemoteOutputServiceServer) BatchCreate(context.Context, *Bat
 func (*UnimplementedRemoteOutputServiceServer) BatchStat(context.Context, *BatchStatRequest) (*BatchStatResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BatchStat not implemented")
 }
+func (*UnimplementedRemoteOutputServiceServer) BatchApplyWriteBarrier(context.Context, *BatchApplyWriteBarrierRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchApplyWriteBarrier not implemented")
+}
+func (*UnimplementedRemoteOutputServiceServer) ReadDirectory(*ReadDirectoryRequest, RemoteOutputService_ReadDirectoryServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReadDirectory not implemented")
+}
 func (*UnimplementedRemoteOutputServiceServer) FinalizeBuild(context.Context, *FinalizeBuildRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FinalizeBuild not implemented")
 }
@@ -1518,6 +1897,45 @@ func _RemoteOutputService_BatchStat_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RemoteOutputService_BatchApplyWriteBarrier_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchApplyWriteBarrierRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemoteOutputServiceServer).BatchApplyWriteBarrier(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote_output_service.RemoteOutputService/BatchApplyWriteBarrier",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemoteOutputServiceServer).BatchApplyWriteBarrier(ctx, req.(*BatchApplyWriteBarrierRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RemoteOutputService_ReadDirectory_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadDirectoryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RemoteOutputServiceServer).ReadDirectory(m, &remoteOutputServiceReadDirectoryServer{stream})
+}
+
+type RemoteOutputService_ReadDirectoryServer interface {
+	Send(*ReadDirectoryResponse) error
+	grpc.ServerStream
+}
+
+type remoteOutputServiceReadDirectoryServer struct {
+	grpc.ServerStream
+}
+
+func (x *remoteOutputServiceReadDirectoryServer) Send(m *ReadDirectoryResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _RemoteOutputService_FinalizeBuild_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(FinalizeBuildRequest)
 	if err := dec(in); err != nil {
@@ -1556,11 +1974,21 @@ var _RemoteOutputService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "BatchStat",
 			Handler:    _RemoteOutputService_BatchStat_Handler,
 		},
+		{
+			MethodName: "BatchApplyWriteBarrier",
+			Handler:    _RemoteOutputService_BatchApplyWriteBarrier_Handler,
+		},
 		{
 			MethodName: "FinalizeBuild",
 			Handler:    _RemoteOutputService_FinalizeBuild_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ReadDirectory",
+			Handler:       _RemoteOutputService_ReadDirectory_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "pkg/proto/remoteoutputservice/remote_output_service.proto",
 }
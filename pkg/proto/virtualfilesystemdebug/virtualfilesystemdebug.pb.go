@@ -0,0 +1,1222 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.32.0
+// 	protoc        v4.23.4
+// source: pkg/proto/virtualfilesystemdebug/virtualfilesystemdebug.proto
+
+package virtualfilesystemdebug
+
+import (
+	context "context"
+	v2 "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListDirectoryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path         string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	MaximumDepth uint32 `protobuf:"varint,2,opt,name=maximum_depth,json=maximumDepth,proto3" json:"maximum_depth,omitempty"`
+}
+
+func (x *ListDirectoryRequest) Reset() {
+	*x = ListDirectoryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListDirectoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDirectoryRequest) ProtoMessage() {}
+
+func (x *ListDirectoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDirectoryRequest.ProtoReflect.Descriptor instead.
+func (*ListDirectoryRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListDirectoryRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ListDirectoryRequest) GetMaximumDepth() uint32 {
+	if x != nil {
+		return x.MaximumDepth
+	}
+	return 0
+}
+
+type ListDirectoryResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Entries []*ListDirectoryResponse_Entry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *ListDirectoryResponse) Reset() {
+	*x = ListDirectoryResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListDirectoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDirectoryResponse) ProtoMessage() {}
+
+func (x *ListDirectoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDirectoryResponse.ProtoReflect.Descriptor instead.
+func (*ListDirectoryResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListDirectoryResponse) GetEntries() []*ListDirectoryResponse_Entry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type GetLeafStateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+}
+
+func (x *GetLeafStateRequest) Reset() {
+	*x = GetLeafStateRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLeafStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLeafStateRequest) ProtoMessage() {}
+
+func (x *GetLeafStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLeafStateRequest.ProtoReflect.Descriptor instead.
+func (*GetLeafStateRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetLeafStateRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type LeafState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type                     string     `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	ReferenceCount           uint64     `protobuf:"varint,2,opt,name=reference_count,json=referenceCount,proto3" json:"reference_count,omitempty"`
+	WritableDescriptorsCount uint64     `protobuf:"varint,3,opt,name=writable_descriptors_count,json=writableDescriptorsCount,proto3" json:"writable_descriptors_count,omitempty"`
+	FrozenDescriptorsCount   uint64     `protobuf:"varint,4,opt,name=frozen_descriptors_count,json=frozenDescriptorsCount,proto3" json:"frozen_descriptors_count,omitempty"`
+	CachedDigest             *v2.Digest `protobuf:"bytes,5,opt,name=cached_digest,json=cachedDigest,proto3" json:"cached_digest,omitempty"`
+	SizeBytes                uint64     `protobuf:"varint,6,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	IsExecutable             bool       `protobuf:"varint,7,opt,name=is_executable,json=isExecutable,proto3" json:"is_executable,omitempty"`
+}
+
+func (x *LeafState) Reset() {
+	*x = LeafState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LeafState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeafState) ProtoMessage() {}
+
+func (x *LeafState) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeafState.ProtoReflect.Descriptor instead.
+func (*LeafState) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *LeafState) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *LeafState) GetReferenceCount() uint64 {
+	if x != nil {
+		return x.ReferenceCount
+	}
+	return 0
+}
+
+func (x *LeafState) GetWritableDescriptorsCount() uint64 {
+	if x != nil {
+		return x.WritableDescriptorsCount
+	}
+	return 0
+}
+
+func (x *LeafState) GetFrozenDescriptorsCount() uint64 {
+	if x != nil {
+		return x.FrozenDescriptorsCount
+	}
+	return 0
+}
+
+func (x *LeafState) GetCachedDigest() *v2.Digest {
+	if x != nil {
+		return x.CachedDigest
+	}
+	return nil
+}
+
+func (x *LeafState) GetSizeBytes() uint64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *LeafState) GetIsExecutable() bool {
+	if x != nil {
+		return x.IsExecutable
+	}
+	return false
+}
+
+type RecomputeDigestRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+}
+
+func (x *RecomputeDigestRequest) Reset() {
+	*x = RecomputeDigestRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecomputeDigestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecomputeDigestRequest) ProtoMessage() {}
+
+func (x *RecomputeDigestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecomputeDigestRequest.ProtoReflect.Descriptor instead.
+func (*RecomputeDigestRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RecomputeDigestRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type EvictNodeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+}
+
+func (x *EvictNodeRequest) Reset() {
+	*x = EvictNodeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EvictNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvictNodeRequest) ProtoMessage() {}
+
+func (x *EvictNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvictNodeRequest.ProtoReflect.Descriptor instead.
+func (*EvictNodeRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *EvictNodeRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type MigrateFilePoolRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+}
+
+func (x *MigrateFilePoolRequest) Reset() {
+	*x = MigrateFilePoolRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MigrateFilePoolRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MigrateFilePoolRequest) ProtoMessage() {}
+
+func (x *MigrateFilePoolRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MigrateFilePoolRequest.ProtoReflect.Descriptor instead.
+func (*MigrateFilePoolRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MigrateFilePoolRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type MigrateFilePoolResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MigratedFilesCount uint64 `protobuf:"varint,1,opt,name=migrated_files_count,json=migratedFilesCount,proto3" json:"migrated_files_count,omitempty"`
+	MigratedSizeBytes  uint64 `protobuf:"varint,2,opt,name=migrated_size_bytes,json=migratedSizeBytes,proto3" json:"migrated_size_bytes,omitempty"`
+}
+
+func (x *MigrateFilePoolResponse) Reset() {
+	*x = MigrateFilePoolResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MigrateFilePoolResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MigrateFilePoolResponse) ProtoMessage() {}
+
+func (x *MigrateFilePoolResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MigrateFilePoolResponse.ProtoReflect.Descriptor instead.
+func (*MigrateFilePoolResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MigrateFilePoolResponse) GetMigratedFilesCount() uint64 {
+	if x != nil {
+		return x.MigratedFilesCount
+	}
+	return 0
+}
+
+func (x *MigrateFilePoolResponse) GetMigratedSizeBytes() uint64 {
+	if x != nil {
+		return x.MigratedSizeBytes
+	}
+	return 0
+}
+
+type FlushFilesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path           string                  `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	InstanceName   string                  `protobuf:"bytes,2,opt,name=instance_name,json=instanceName,proto3" json:"instance_name,omitempty"`
+	DigestFunction v2.DigestFunction_Value `protobuf:"varint,3,opt,name=digest_function,json=digestFunction,proto3,enum=build.bazel.remote.execution.v2.DigestFunction_Value" json:"digest_function,omitempty"`
+}
+
+func (x *FlushFilesRequest) Reset() {
+	*x = FlushFilesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FlushFilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlushFilesRequest) ProtoMessage() {}
+
+func (x *FlushFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlushFilesRequest.ProtoReflect.Descriptor instead.
+func (*FlushFilesRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FlushFilesRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FlushFilesRequest) GetInstanceName() string {
+	if x != nil {
+		return x.InstanceName
+	}
+	return ""
+}
+
+func (x *FlushFilesRequest) GetDigestFunction() v2.DigestFunction_Value {
+	if x != nil {
+		return x.DigestFunction
+	}
+	return v2.DigestFunction_Value(0)
+}
+
+type ListDirectoryResponse_Entry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// Types that are assignable to Type:
+	//
+	//	*ListDirectoryResponse_Entry_Directory
+	//	*ListDirectoryResponse_Entry_Leaf
+	Type isListDirectoryResponse_Entry_Type `protobuf_oneof:"type"`
+}
+
+func (x *ListDirectoryResponse_Entry) Reset() {
+	*x = ListDirectoryResponse_Entry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListDirectoryResponse_Entry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDirectoryResponse_Entry) ProtoMessage() {}
+
+func (x *ListDirectoryResponse_Entry) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDirectoryResponse_Entry.ProtoReflect.Descriptor instead.
+func (*ListDirectoryResponse_Entry) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescGZIP(), []int{1, 0}
+}
+
+func (x *ListDirectoryResponse_Entry) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (m *ListDirectoryResponse_Entry) GetType() isListDirectoryResponse_Entry_Type {
+	if m != nil {
+		return m.Type
+	}
+	return nil
+}
+
+func (x *ListDirectoryResponse_Entry) GetDirectory() *emptypb.Empty {
+	if x, ok := x.GetType().(*ListDirectoryResponse_Entry_Directory); ok {
+		return x.Directory
+	}
+	return nil
+}
+
+func (x *ListDirectoryResponse_Entry) GetLeaf() *LeafState {
+	if x, ok := x.GetType().(*ListDirectoryResponse_Entry_Leaf); ok {
+		return x.Leaf
+	}
+	return nil
+}
+
+type isListDirectoryResponse_Entry_Type interface {
+	isListDirectoryResponse_Entry_Type()
+}
+
+type ListDirectoryResponse_Entry_Directory struct {
+	Directory *emptypb.Empty `protobuf:"bytes,2,opt,name=directory,proto3,oneof"`
+}
+
+type ListDirectoryResponse_Entry_Leaf struct {
+	Leaf *LeafState `protobuf:"bytes,3,opt,name=leaf,proto3,oneof"`
+}
+
+func (*ListDirectoryResponse_Entry_Directory) isListDirectoryResponse_Entry_Type() {}
+
+func (*ListDirectoryResponse_Entry_Leaf) isListDirectoryResponse_Entry_Type() {}
+
+var File_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto protoreflect.FileDescriptor
+
+var file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDesc = []byte{
+	0x0a, 0x3d, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x69, 0x72, 0x74,
+	0x75, 0x61, 0x6c, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x65, 0x62,
+	0x75, 0x67, 0x2f, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79,
+	0x73, 0x74, 0x65, 0x6d, 0x64, 0x65, 0x62, 0x75, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x20, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x76, 0x69, 0x72, 0x74, 0x75,
+	0x61, 0x6c, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x65, 0x62, 0x75,
+	0x67, 0x1a, 0x36, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2f, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2f, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2f, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2f,
+	0x76, 0x32, 0x2f, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x4f, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x69,
+	0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61,
+	0x74, 0x68, 0x12, 0x23, 0x0a, 0x0d, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x64, 0x65,
+	0x70, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x6d, 0x61, 0x78, 0x69, 0x6d,
+	0x75, 0x6d, 0x44, 0x65, 0x70, 0x74, 0x68, 0x22, 0x91, 0x02, 0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74,
+	0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x57, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x3d, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x76,
+	0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d,
+	0x64, 0x65, 0x62, 0x75, 0x67, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x1a, 0x9e, 0x01, 0x0a, 0x05, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x36, 0x0a, 0x09, 0x64, 0x69, 0x72, 0x65,
+	0x63, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x48, 0x00, 0x52, 0x09, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79,
+	0x12, 0x41, 0x0a, 0x04, 0x6c, 0x65, 0x61, 0x66, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2b,
+	0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x76, 0x69, 0x72, 0x74, 0x75,
+	0x61, 0x6c, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x65, 0x62, 0x75,
+	0x67, 0x2e, 0x4c, 0x65, 0x61, 0x66, 0x53, 0x74, 0x61, 0x74, 0x65, 0x48, 0x00, 0x52, 0x04, 0x6c,
+	0x65, 0x61, 0x66, 0x42, 0x06, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x29, 0x0a, 0x13, 0x47,
+	0x65, 0x74, 0x4c, 0x65, 0x61, 0x66, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x22, 0xd2, 0x02, 0x0a, 0x09, 0x4c, 0x65, 0x61, 0x66, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x72, 0x65, 0x66, 0x65,
+	0x72, 0x65, 0x6e, 0x63, 0x65, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0e, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x43, 0x6f, 0x75, 0x6e,
+	0x74, 0x12, 0x3c, 0x0a, 0x1a, 0x77, 0x72, 0x69, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x64, 0x65,
+	0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x73, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x18, 0x77, 0x72, 0x69, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x44,
+	0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12,
+	0x38, 0x0a, 0x18, 0x66, 0x72, 0x6f, 0x7a, 0x65, 0x6e, 0x5f, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69,
+	0x70, 0x74, 0x6f, 0x72, 0x73, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x16, 0x66, 0x72, 0x6f, 0x7a, 0x65, 0x6e, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70,
+	0x74, 0x6f, 0x72, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x4c, 0x0a, 0x0d, 0x63, 0x61, 0x63,
+	0x68, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x27, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x76, 0x32, 0x2e, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x52, 0x0c, 0x63, 0x61, 0x63, 0x68, 0x65,
+	0x64, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x69, 0x7a, 0x65, 0x5f,
+	0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x73, 0x69, 0x7a,
+	0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x73, 0x5f, 0x65, 0x78, 0x65,
+	0x63, 0x75, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x69,
+	0x73, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x22, 0x2c, 0x0a, 0x16, 0x52,
+	0x65, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x22, 0x26, 0x0a, 0x10, 0x45, 0x76, 0x69,
+	0x63, 0x74, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74,
+	0x68, 0x22, 0x2c, 0x0a, 0x16, 0x4d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x65, 0x46, 0x69, 0x6c, 0x65,
+	0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70,
+	0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x22,
+	0x7b, 0x0a, 0x17, 0x4d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x50, 0x6f,
+	0x6f, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x30, 0x0a, 0x14, 0x6d, 0x69,
+	0x67, 0x72, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x5f, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x12, 0x6d, 0x69, 0x67, 0x72, 0x61, 0x74,
+	0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x2e, 0x0a, 0x13,
+	0x6d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62, 0x79,
+	0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x11, 0x6d, 0x69, 0x67, 0x72, 0x61,
+	0x74, 0x65, 0x64, 0x53, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22, 0xac, 0x01, 0x0a,
+	0x11, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e,
+	0x63, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x69,
+	0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x5e, 0x0a, 0x0f, 0x64,
+	0x69, 0x67, 0x65, 0x73, 0x74, 0x5f, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x35, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a,
+	0x65, 0x6c, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x46, 0x75, 0x6e,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x0e, 0x64, 0x69, 0x67,
+	0x65, 0x73, 0x74, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x32, 0xb1, 0x05, 0x0a, 0x16,
+	0x56, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65,
+	0x6d, 0x44, 0x65, 0x62, 0x75, 0x67, 0x12, 0x80, 0x01, 0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74, 0x44,
+	0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x36, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x62, 0x61, 0x72, 0x6e, 0x2e, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x66, 0x69, 0x6c, 0x65,
+	0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x65, 0x62, 0x75, 0x67, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x37, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x76, 0x69, 0x72,
+	0x74, 0x75, 0x61, 0x6c, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x65,
+	0x62, 0x75, 0x67, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x72, 0x0a, 0x0c, 0x47, 0x65, 0x74,
+	0x4c, 0x65, 0x61, 0x66, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x35, 0x2e, 0x62, 0x75, 0x69, 0x6c,
+	0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x66, 0x69, 0x6c,
+	0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x65, 0x62, 0x75, 0x67, 0x2e, 0x47, 0x65, 0x74,
+	0x4c, 0x65, 0x61, 0x66, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x2b, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x76, 0x69, 0x72,
+	0x74, 0x75, 0x61, 0x6c, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x65,
+	0x62, 0x75, 0x67, 0x2e, 0x4c, 0x65, 0x61, 0x66, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x63, 0x0a,
+	0x0f, 0x52, 0x65, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74,
+	0x12, 0x38, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x76, 0x69, 0x72,
+	0x74, 0x75, 0x61, 0x6c, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x65,
+	0x62, 0x75, 0x67, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x44, 0x69, 0x67,
+	0x65, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x12, 0x57, 0x0a, 0x09, 0x45, 0x76, 0x69, 0x63, 0x74, 0x4e, 0x6f, 0x64, 0x65, 0x12,
+	0x32, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x76, 0x69, 0x72, 0x74,
+	0x75, 0x61, 0x6c, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x65, 0x62,
+	0x75, 0x67, 0x2e, 0x45, 0x76, 0x69, 0x63, 0x74, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x86, 0x01, 0x0a, 0x0f,
+	0x4d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x50, 0x6f, 0x6f, 0x6c, 0x12,
+	0x38, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x76, 0x69, 0x72, 0x74,
+	0x75, 0x61, 0x6c, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x65, 0x62,
+	0x75, 0x67, 0x2e, 0x4d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x50, 0x6f,
+	0x6f, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x39, 0x2e, 0x62, 0x75, 0x69, 0x6c,
+	0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x66, 0x69, 0x6c,
+	0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x65, 0x62, 0x75, 0x67, 0x2e, 0x4d, 0x69, 0x67,
+	0x72, 0x61, 0x74, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x59, 0x0a, 0x0a, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x46, 0x69, 0x6c,
+	0x65, 0x73, 0x12, 0x33, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x76,
+	0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d,
+	0x64, 0x65, 0x62, 0x75, 0x67, 0x2e, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x46, 0x69, 0x6c, 0x65, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x42,
+	0x4b, 0x5a, 0x49, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x62, 0x75,
+	0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2f, 0x62, 0x62, 0x2d, 0x72, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x2d, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x70, 0x6b, 0x67, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x66, 0x69, 0x6c,
+	0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x65, 0x62, 0x75, 0x67, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescOnce sync.Once
+	file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescData = file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDesc
+)
+
+func file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescGZIP() []byte {
+	file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescOnce.Do(func() {
+		file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescData)
+	})
+	return file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDescData
+}
+
+var file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_goTypes = []interface{}{
+	(*ListDirectoryRequest)(nil),        // 0: buildbarn.virtualfilesystemdebug.ListDirectoryRequest
+	(*ListDirectoryResponse)(nil),       // 1: buildbarn.virtualfilesystemdebug.ListDirectoryResponse
+	(*GetLeafStateRequest)(nil),         // 2: buildbarn.virtualfilesystemdebug.GetLeafStateRequest
+	(*LeafState)(nil),                   // 3: buildbarn.virtualfilesystemdebug.LeafState
+	(*RecomputeDigestRequest)(nil),      // 4: buildbarn.virtualfilesystemdebug.RecomputeDigestRequest
+	(*EvictNodeRequest)(nil),            // 5: buildbarn.virtualfilesystemdebug.EvictNodeRequest
+	(*MigrateFilePoolRequest)(nil),      // 6: buildbarn.virtualfilesystemdebug.MigrateFilePoolRequest
+	(*MigrateFilePoolResponse)(nil),     // 7: buildbarn.virtualfilesystemdebug.MigrateFilePoolResponse
+	(*FlushFilesRequest)(nil),           // 8: buildbarn.virtualfilesystemdebug.FlushFilesRequest
+	(*ListDirectoryResponse_Entry)(nil), // 9: buildbarn.virtualfilesystemdebug.ListDirectoryResponse.Entry
+	(*v2.Digest)(nil),                   // 10: build.bazel.remote.execution.v2.Digest
+	(v2.DigestFunction_Value)(0),        // 11: build.bazel.remote.execution.v2.DigestFunction.Value
+	(*emptypb.Empty)(nil),               // 12: google.protobuf.Empty
+}
+var file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_depIdxs = []int32{
+	9,  // 0: buildbarn.virtualfilesystemdebug.ListDirectoryResponse.entries:type_name -> buildbarn.virtualfilesystemdebug.ListDirectoryResponse.Entry
+	10, // 1: buildbarn.virtualfilesystemdebug.LeafState.cached_digest:type_name -> build.bazel.remote.execution.v2.Digest
+	11, // 2: buildbarn.virtualfilesystemdebug.FlushFilesRequest.digest_function:type_name -> build.bazel.remote.execution.v2.DigestFunction.Value
+	12, // 3: buildbarn.virtualfilesystemdebug.ListDirectoryResponse.Entry.directory:type_name -> google.protobuf.Empty
+	3,  // 4: buildbarn.virtualfilesystemdebug.ListDirectoryResponse.Entry.leaf:type_name -> buildbarn.virtualfilesystemdebug.LeafState
+	0,  // 5: buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug.ListDirectory:input_type -> buildbarn.virtualfilesystemdebug.ListDirectoryRequest
+	2,  // 6: buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug.GetLeafState:input_type -> buildbarn.virtualfilesystemdebug.GetLeafStateRequest
+	4,  // 7: buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug.RecomputeDigest:input_type -> buildbarn.virtualfilesystemdebug.RecomputeDigestRequest
+	5,  // 8: buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug.EvictNode:input_type -> buildbarn.virtualfilesystemdebug.EvictNodeRequest
+	6,  // 9: buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug.MigrateFilePool:input_type -> buildbarn.virtualfilesystemdebug.MigrateFilePoolRequest
+	8,  // 10: buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug.FlushFiles:input_type -> buildbarn.virtualfilesystemdebug.FlushFilesRequest
+	1,  // 11: buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug.ListDirectory:output_type -> buildbarn.virtualfilesystemdebug.ListDirectoryResponse
+	3,  // 12: buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug.GetLeafState:output_type -> buildbarn.virtualfilesystemdebug.LeafState
+	12, // 13: buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug.RecomputeDigest:output_type -> google.protobuf.Empty
+	12, // 14: buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug.EvictNode:output_type -> google.protobuf.Empty
+	7,  // 15: buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug.MigrateFilePool:output_type -> buildbarn.virtualfilesystemdebug.MigrateFilePoolResponse
+	12, // 16: buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug.FlushFiles:output_type -> google.protobuf.Empty
+	11, // [11:17] is the sub-list for method output_type
+	5,  // [5:11] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_init() }
+func file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_init() {
+	if File_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListDirectoryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListDirectoryResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLeafStateRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LeafState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecomputeDigestRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EvictNodeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MigrateFilePoolRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MigrateFilePoolResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FlushFilesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListDirectoryResponse_Entry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes[9].OneofWrappers = []interface{}{
+		(*ListDirectoryResponse_Entry_Directory)(nil),
+		(*ListDirectoryResponse_Entry_Leaf)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_goTypes,
+		DependencyIndexes: file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_depIdxs,
+		MessageInfos:      file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_msgTypes,
+	}.Build()
+	File_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto = out.File
+	file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_rawDesc = nil
+	file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_goTypes = nil
+	file_pkg_proto_virtualfilesystemdebug_virtualfilesystemdebug_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// VirtualFileSystemDebugClient is the client API for VirtualFileSystemDebug service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type VirtualFileSystemDebugClient interface {
+	ListDirectory(ctx context.Context, in *ListDirectoryRequest, opts ...grpc.CallOption) (*ListDirectoryResponse, error)
+	GetLeafState(ctx context.Context, in *GetLeafStateRequest, opts ...grpc.CallOption) (*LeafState, error)
+	RecomputeDigest(ctx context.Context, in *RecomputeDigestRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	EvictNode(ctx context.Context, in *EvictNodeRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	MigrateFilePool(ctx context.Context, in *MigrateFilePoolRequest, opts ...grpc.CallOption) (*MigrateFilePoolResponse, error)
+	FlushFiles(ctx context.Context, in *FlushFilesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type virtualFileSystemDebugClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVirtualFileSystemDebugClient(cc grpc.ClientConnInterface) VirtualFileSystemDebugClient {
+	return &virtualFileSystemDebugClient{cc}
+}
+
+func (c *virtualFileSystemDebugClient) ListDirectory(ctx context.Context, in *ListDirectoryRequest, opts ...grpc.CallOption) (*ListDirectoryResponse, error) {
+	out := new(ListDirectoryResponse)
+	err := c.cc.Invoke(ctx, "/buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug/ListDirectory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *virtualFileSystemDebugClient) GetLeafState(ctx context.Context, in *GetLeafStateRequest, opts ...grpc.CallOption) (*LeafState, error) {
+	out := new(LeafState)
+	err := c.cc.Invoke(ctx, "/buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug/GetLeafState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *virtualFileSystemDebugClient) RecomputeDigest(ctx context.Context, in *RecomputeDigestRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug/RecomputeDigest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *virtualFileSystemDebugClient) EvictNode(ctx context.Context, in *EvictNodeRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug/EvictNode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *virtualFileSystemDebugClient) MigrateFilePool(ctx context.Context, in *MigrateFilePoolRequest, opts ...grpc.CallOption) (*MigrateFilePoolResponse, error) {
+	out := new(MigrateFilePoolResponse)
+	err := c.cc.Invoke(ctx, "/buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug/MigrateFilePool", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *virtualFileSystemDebugClient) FlushFiles(ctx context.Context, in *FlushFilesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug/FlushFiles", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// VirtualFileSystemDebugServer is the server API for VirtualFileSystemDebug service.
+type VirtualFileSystemDebugServer interface {
+	ListDirectory(context.Context, *ListDirectoryRequest) (*ListDirectoryResponse, error)
+	GetLeafState(context.Context, *GetLeafStateRequest) (*LeafState, error)
+	RecomputeDigest(context.Context, *RecomputeDigestRequest) (*emptypb.Empty, error)
+	EvictNode(context.Context, *EvictNodeRequest) (*emptypb.Empty, error)
+	MigrateFilePool(context.Context, *MigrateFilePoolRequest) (*MigrateFilePoolResponse, error)
+	FlushFiles(context.Context, *FlushFilesRequest) (*emptypb.Empty, error)
+}
+
+// UnimplementedVirtualFileSystemDebugServer can be embedded to have forward compatible implementations.
+type UnimplementedVirtualFileSystemDebugServer struct {
+}
+
+func (*UnimplementedVirtualFileSystemDebugServer) ListDirectory(context.Context, *ListDirectoryRequest) (*ListDirectoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDirectory not implemented")
+}
+func (*UnimplementedVirtualFileSystemDebugServer) GetLeafState(context.Context, *GetLeafStateRequest) (*LeafState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLeafState not implemented")
+}
+func (*UnimplementedVirtualFileSystemDebugServer) RecomputeDigest(context.Context, *RecomputeDigestRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecomputeDigest not implemented")
+}
+func (*UnimplementedVirtualFileSystemDebugServer) EvictNode(context.Context, *EvictNodeRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EvictNode not implemented")
+}
+func (*UnimplementedVirtualFileSystemDebugServer) MigrateFilePool(context.Context, *MigrateFilePoolRequest) (*MigrateFilePoolResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MigrateFilePool not implemented")
+}
+func (*UnimplementedVirtualFileSystemDebugServer) FlushFiles(context.Context, *FlushFilesRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FlushFiles not implemented")
+}
+
+func RegisterVirtualFileSystemDebugServer(s grpc.ServiceRegistrar, srv VirtualFileSystemDebugServer) {
+	s.RegisterService(&_VirtualFileSystemDebug_serviceDesc, srv)
+}
+
+func _VirtualFileSystemDebug_ListDirectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDirectoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VirtualFileSystemDebugServer).ListDirectory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug/ListDirectory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VirtualFileSystemDebugServer).ListDirectory(ctx, req.(*ListDirectoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VirtualFileSystemDebug_GetLeafState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLeafStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VirtualFileSystemDebugServer).GetLeafState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug/GetLeafState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VirtualFileSystemDebugServer).GetLeafState(ctx, req.(*GetLeafStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VirtualFileSystemDebug_RecomputeDigest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecomputeDigestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VirtualFileSystemDebugServer).RecomputeDigest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug/RecomputeDigest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VirtualFileSystemDebugServer).RecomputeDigest(ctx, req.(*RecomputeDigestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VirtualFileSystemDebug_EvictNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvictNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VirtualFileSystemDebugServer).EvictNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug/EvictNode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VirtualFileSystemDebugServer).EvictNode(ctx, req.(*EvictNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VirtualFileSystemDebug_MigrateFilePool_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MigrateFilePoolRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VirtualFileSystemDebugServer).MigrateFilePool(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug/MigrateFilePool",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VirtualFileSystemDebugServer).MigrateFilePool(ctx, req.(*MigrateFilePoolRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VirtualFileSystemDebug_FlushFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FlushFilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VirtualFileSystemDebugServer).FlushFiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug/FlushFiles",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VirtualFileSystemDebugServer).FlushFiles(ctx, req.(*FlushFilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _VirtualFileSystemDebug_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "buildbarn.virtualfilesystemdebug.VirtualFileSystemDebug",
+	HandlerType: (*VirtualFileSystemDebugServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListDirectory",
+			Handler:    _VirtualFileSystemDebug_ListDirectory_Handler,
+		},
+		{
+			MethodName: "GetLeafState",
+			Handler:    _VirtualFileSystemDebug_GetLeafState_Handler,
+		},
+		{
+			MethodName: "RecomputeDigest",
+			Handler:    _VirtualFileSystemDebug_RecomputeDigest_Handler,
+		},
+		{
+			MethodName: "EvictNode",
+			Handler:    _VirtualFileSystemDebug_EvictNode_Handler,
+		},
+		{
+			MethodName: "MigrateFilePool",
+			Handler:    _VirtualFileSystemDebug_MigrateFilePool_Handler,
+		},
+		{
+			MethodName: "FlushFiles",
+			Handler:    _VirtualFileSystemDebug_FlushFiles_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/proto/virtualfilesystemdebug/virtualfilesystemdebug.proto",
+}
@@ -0,0 +1,323 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.32.0
+// 	protoc        v4.23.4
+// source: pkg/proto/workerdebug/workerdebug.proto
+
+package workerdebug
+
+import (
+	context "context"
+	v2 "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DebugFlags struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LogVerbosity                uint32       `protobuf:"varint,1,opt,name=log_verbosity,json=logVerbosity,proto3" json:"log_verbosity,omitempty"`
+	TracedActionDigests         []*v2.Digest `protobuf:"bytes,2,rep,name=traced_action_digests,json=tracedActionDigests,proto3" json:"traced_action_digests,omitempty"`
+	FileSystemDumpActionDigests []*v2.Digest `protobuf:"bytes,3,rep,name=file_system_dump_action_digests,json=fileSystemDumpActionDigests,proto3" json:"file_system_dump_action_digests,omitempty"`
+}
+
+func (x *DebugFlags) Reset() {
+	*x = DebugFlags{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_workerdebug_workerdebug_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DebugFlags) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DebugFlags) ProtoMessage() {}
+
+func (x *DebugFlags) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_workerdebug_workerdebug_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DebugFlags.ProtoReflect.Descriptor instead.
+func (*DebugFlags) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_workerdebug_workerdebug_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DebugFlags) GetLogVerbosity() uint32 {
+	if x != nil {
+		return x.LogVerbosity
+	}
+	return 0
+}
+
+func (x *DebugFlags) GetTracedActionDigests() []*v2.Digest {
+	if x != nil {
+		return x.TracedActionDigests
+	}
+	return nil
+}
+
+func (x *DebugFlags) GetFileSystemDumpActionDigests() []*v2.Digest {
+	if x != nil {
+		return x.FileSystemDumpActionDigests
+	}
+	return nil
+}
+
+var File_pkg_proto_workerdebug_workerdebug_proto protoreflect.FileDescriptor
+
+var file_pkg_proto_workerdebug_workerdebug_proto_rawDesc = []byte{
+	0x0a, 0x27, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x77, 0x6f, 0x72, 0x6b,
+	0x65, 0x72, 0x64, 0x65, 0x62, 0x75, 0x67, 0x2f, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x64, 0x65,
+	0x62, 0x75, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x15, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x62, 0x61, 0x72, 0x6e, 0x2e, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x64, 0x65, 0x62, 0x75, 0x67,
+	0x1a, 0x36, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2f, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2f, 0x72, 0x65,
+	0x6d, 0x6f, 0x74, 0x65, 0x2f, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x76,
+	0x32, 0x2f, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xfd, 0x01, 0x0a, 0x0a, 0x44, 0x65, 0x62, 0x75, 0x67, 0x46,
+	0x6c, 0x61, 0x67, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x6c, 0x6f, 0x67, 0x5f, 0x76, 0x65, 0x72, 0x62,
+	0x6f, 0x73, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x6c, 0x6f, 0x67,
+	0x56, 0x65, 0x72, 0x62, 0x6f, 0x73, 0x69, 0x74, 0x79, 0x12, 0x5b, 0x0a, 0x15, 0x74, 0x72, 0x61,
+	0x63, 0x65, 0x64, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73,
+	0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78,
+	0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x44, 0x69, 0x67, 0x65, 0x73,
+	0x74, 0x52, 0x13, 0x74, 0x72, 0x61, 0x63, 0x65, 0x64, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x44,
+	0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x12, 0x6d, 0x0a, 0x1f, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x73,
+	0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f, 0x64, 0x75, 0x6d, 0x70, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x27, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x2e, 0x62, 0x61, 0x7a, 0x65, 0x6c, 0x2e, 0x72, 0x65,
+	0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76,
+	0x32, 0x2e, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x52, 0x1b, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x79,
+	0x73, 0x74, 0x65, 0x6d, 0x44, 0x75, 0x6d, 0x70, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x69,
+	0x67, 0x65, 0x73, 0x74, 0x73, 0x32, 0xa5, 0x01, 0x0a, 0x0b, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72,
+	0x44, 0x65, 0x62, 0x75, 0x67, 0x12, 0x4a, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x44, 0x65, 0x62, 0x75,
+	0x67, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x21,
+	0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x77, 0x6f, 0x72, 0x6b, 0x65,
+	0x72, 0x64, 0x65, 0x62, 0x75, 0x67, 0x2e, 0x44, 0x65, 0x62, 0x75, 0x67, 0x46, 0x6c, 0x61, 0x67,
+	0x73, 0x12, 0x4a, 0x0a, 0x0d, 0x53, 0x65, 0x74, 0x44, 0x65, 0x62, 0x75, 0x67, 0x46, 0x6c, 0x61,
+	0x67, 0x73, 0x12, 0x21, 0x2e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x62, 0x61, 0x72, 0x6e, 0x2e, 0x77,
+	0x6f, 0x72, 0x6b, 0x65, 0x72, 0x64, 0x65, 0x62, 0x75, 0x67, 0x2e, 0x44, 0x65, 0x62, 0x75, 0x67,
+	0x46, 0x6c, 0x61, 0x67, 0x73, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x40, 0x5a,
+	0x3e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x62, 0x75, 0x69, 0x6c,
+	0x64, 0x62, 0x61, 0x72, 0x6e, 0x2f, 0x62, 0x62, 0x2d, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2d,
+	0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2f, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x64, 0x65, 0x62, 0x75, 0x67, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pkg_proto_workerdebug_workerdebug_proto_rawDescOnce sync.Once
+	file_pkg_proto_workerdebug_workerdebug_proto_rawDescData = file_pkg_proto_workerdebug_workerdebug_proto_rawDesc
+)
+
+func file_pkg_proto_workerdebug_workerdebug_proto_rawDescGZIP() []byte {
+	file_pkg_proto_workerdebug_workerdebug_proto_rawDescOnce.Do(func() {
+		file_pkg_proto_workerdebug_workerdebug_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_proto_workerdebug_workerdebug_proto_rawDescData)
+	})
+	return file_pkg_proto_workerdebug_workerdebug_proto_rawDescData
+}
+
+var file_pkg_proto_workerdebug_workerdebug_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_pkg_proto_workerdebug_workerdebug_proto_goTypes = []interface{}{
+	(*DebugFlags)(nil),    // 0: buildbarn.workerdebug.DebugFlags
+	(*v2.Digest)(nil),     // 1: build.bazel.remote.execution.v2.Digest
+	(*emptypb.Empty)(nil), // 2: google.protobuf.Empty
+}
+var file_pkg_proto_workerdebug_workerdebug_proto_depIdxs = []int32{
+	1, // 0: buildbarn.workerdebug.DebugFlags.traced_action_digests:type_name -> build.bazel.remote.execution.v2.Digest
+	1, // 1: buildbarn.workerdebug.DebugFlags.file_system_dump_action_digests:type_name -> build.bazel.remote.execution.v2.Digest
+	2, // 2: buildbarn.workerdebug.WorkerDebug.GetDebugFlags:input_type -> google.protobuf.Empty
+	0, // 3: buildbarn.workerdebug.WorkerDebug.SetDebugFlags:input_type -> buildbarn.workerdebug.DebugFlags
+	0, // 4: buildbarn.workerdebug.WorkerDebug.GetDebugFlags:output_type -> buildbarn.workerdebug.DebugFlags
+	2, // 5: buildbarn.workerdebug.WorkerDebug.SetDebugFlags:output_type -> google.protobuf.Empty
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_pkg_proto_workerdebug_workerdebug_proto_init() }
+func file_pkg_proto_workerdebug_workerdebug_proto_init() {
+	if File_pkg_proto_workerdebug_workerdebug_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pkg_proto_workerdebug_workerdebug_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DebugFlags); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pkg_proto_workerdebug_workerdebug_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pkg_proto_workerdebug_workerdebug_proto_goTypes,
+		DependencyIndexes: file_pkg_proto_workerdebug_workerdebug_proto_depIdxs,
+		MessageInfos:      file_pkg_proto_workerdebug_workerdebug_proto_msgTypes,
+	}.Build()
+	File_pkg_proto_workerdebug_workerdebug_proto = out.File
+	file_pkg_proto_workerdebug_workerdebug_proto_rawDesc = nil
+	file_pkg_proto_workerdebug_workerdebug_proto_goTypes = nil
+	file_pkg_proto_workerdebug_workerdebug_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// WorkerDebugClient is the client API for WorkerDebug service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type WorkerDebugClient interface {
+	GetDebugFlags(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*DebugFlags, error)
+	SetDebugFlags(ctx context.Context, in *DebugFlags, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type workerDebugClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWorkerDebugClient(cc grpc.ClientConnInterface) WorkerDebugClient {
+	return &workerDebugClient{cc}
+}
+
+func (c *workerDebugClient) GetDebugFlags(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*DebugFlags, error) {
+	out := new(DebugFlags)
+	err := c.cc.Invoke(ctx, "/buildbarn.workerdebug.WorkerDebug/GetDebugFlags", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workerDebugClient) SetDebugFlags(ctx context.Context, in *DebugFlags, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/buildbarn.workerdebug.WorkerDebug/SetDebugFlags", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WorkerDebugServer is the server API for WorkerDebug service.
+type WorkerDebugServer interface {
+	GetDebugFlags(context.Context, *emptypb.Empty) (*DebugFlags, error)
+	SetDebugFlags(context.Context, *DebugFlags) (*emptypb.Empty, error)
+}
+
+// UnimplementedWorkerDebugServer can be embedded to have forward compatible implementations.
+type UnimplementedWorkerDebugServer struct {
+}
+
+func (*UnimplementedWorkerDebugServer) GetDebugFlags(context.Context, *emptypb.Empty) (*DebugFlags, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDebugFlags not implemented")
+}
+func (*UnimplementedWorkerDebugServer) SetDebugFlags(context.Context, *DebugFlags) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDebugFlags not implemented")
+}
+
+func RegisterWorkerDebugServer(s grpc.ServiceRegistrar, srv WorkerDebugServer) {
+	s.RegisterService(&_WorkerDebug_serviceDesc, srv)
+}
+
+func _WorkerDebug_GetDebugFlags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkerDebugServer).GetDebugFlags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/buildbarn.workerdebug.WorkerDebug/GetDebugFlags",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkerDebugServer).GetDebugFlags(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WorkerDebug_SetDebugFlags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DebugFlags)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkerDebugServer).SetDebugFlags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/buildbarn.workerdebug.WorkerDebug/SetDebugFlags",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkerDebugServer).SetDebugFlags(ctx, req.(*DebugFlags))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _WorkerDebug_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "buildbarn.workerdebug.WorkerDebug",
+	HandlerType: (*WorkerDebugServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetDebugFlags",
+			Handler:    _WorkerDebug_GetDebugFlags_Handler,
+		},
+		{
+			MethodName: "SetDebugFlags",
+			Handler:    _WorkerDebug_SetDebugFlags_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/proto/workerdebug/workerdebug.proto",
+}
@@ -0,0 +1,123 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/runner"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CPUTimeLimitConfiguration contains the parameters that the local
+// runner uses to terminate actions that consume more CPU time than
+// permitted. Unlike the execution timeout, which is based on
+// wall-clock time, actions that are sleeping or waiting for I/O do not
+// consume any of their CPU time budget.
+//
+// CPU time is accounted for using Linux control groups (cgroups v2).
+// Every action is placed in a cgroup of its own, so that CPU time
+// consumed by processes spawned by the action is accounted for as
+// well.
+type CPUTimeLimitConfiguration struct {
+	// Path of a cgroup under which cgroups for actions are
+	// created. The runner must be permitted to create cgroups
+	// underneath it.
+	CgroupParentPath string
+	// Name of the platform property that actions may use to
+	// specify their CPU time budget (e.g., "300s").
+	PlatformProperty string
+	// The CPU time budget of actions that don't specify one. If
+	// zero, such actions have no CPU time budget.
+	DefaultLimit time.Duration
+	// The largest CPU time budget that actions may specify through
+	// the platform property.
+	MaximumLimit time.Duration
+	// The number of CPUs that an action may keep busy concurrently,
+	// which is enforced through cpu.max. This bounds the rate at
+	// which CPU time is consumed, meaning that it only needs to be
+	// checked at the point in time at which the budget may have
+	// been exhausted.
+	MaximumCPUs int
+	// Clock that is used to schedule checks of the CPU time used.
+	Clock clock.Clock
+}
+
+// cpuTimeLimitingCgroupNameKey is the key of a context value that
+// decorators of the local runner may set to choose the name of the
+// cgroup in which the action is run. This permits them to refer to the
+// cgroup in the arguments of the command, so that processes that are
+// not descendants of the command (e.g., containers launched through a
+// container runtime's daemon) can be placed in it as well.
+type cpuTimeLimitingCgroupNameKey struct{}
+
+var cpuTimeLimitingCgroupsCreated atomic.Uint64
+
+// newCPUTimeLimitingCgroupName returns a name for a cgroup in which an
+// action is run that is unique for the lifetime of the runner.
+func newCPUTimeLimitingCgroupName() string {
+	return fmt.Sprintf("action-%d-%d", os.Getpid(), cpuTimeLimitingCgroupsCreated.Add(1))
+}
+
+// getLimit returns the CPU time budget of an action. Zero is returned
+// if the action has no CPU time budget.
+func (c *CPUTimeLimitConfiguration) getLimit(request *runner.RunRequest) (time.Duration, error) {
+	if c.PlatformProperty != "" {
+		for _, property := range request.Platform.GetProperties() {
+			if property.Name == c.PlatformProperty {
+				limit, err := time.ParseDuration(property.Value)
+				if err != nil {
+					return 0, util.StatusWrapfWithCode(err, codes.InvalidArgument, "Invalid value for platform property %#v", c.PlatformProperty)
+				}
+				if limit <= 0 {
+					return 0, status.Errorf(codes.InvalidArgument, "Value for platform property %#v must be positive", c.PlatformProperty)
+				}
+				if limit > c.MaximumLimit {
+					return 0, status.Errorf(codes.InvalidArgument, "Value for platform property %#v exceeds the maximum of %s", c.PlatformProperty, c.MaximumLimit)
+				}
+				return limit, nil
+			}
+		}
+	}
+	return c.DefaultLimit, nil
+}
+
+// minimumCPUTimeCheckInterval is the smallest amount of time between
+// two successive checks of the CPU time used by an action. It prevents
+// busy looping when an action is close to exhausting its budget, or
+// when its CPU time usage cannot be obtained.
+const minimumCPUTimeCheckInterval = 10 * time.Millisecond
+
+// enforceCPUTimeLimit blocks until an action has used up its CPU time
+// budget, or until stop is closed. As the action cannot use more than
+// maximumCPUs seconds of CPU time per second of wall-clock time, there
+// is no need to check its usage periodically. The next check is
+// scheduled at the earliest point in time at which the budget may have
+// been exhausted. The return value indicates whether the budget was
+// exhausted.
+func enforceCPUTimeLimit(clock clock.Clock, limit time.Duration, maximumCPUs int, getUsage func() (time.Duration, error), stop <-chan struct{}) bool {
+	for {
+		delay := minimumCPUTimeCheckInterval
+		if usage, err := getUsage(); err == nil {
+			if usage >= limit {
+				return true
+			}
+			if d := (limit - usage) / time.Duration(maximumCPUs); d > delay {
+				delay = d
+			}
+		}
+
+		timer, t := clock.NewTimer(delay)
+		select {
+		case <-stop:
+			timer.Stop()
+			return false
+		case <-t:
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTimerClock is an implementation of clock.Clock that records the
+// duration of the timers that are created. Timers either expire
+// immediately, or never expire.
+type fakeTimerClock struct {
+	expire  bool
+	delays  []time.Duration
+	stopped int
+}
+
+func (c *fakeTimerClock) Now() time.Time {
+	panic("Not implemented")
+}
+
+func (c *fakeTimerClock) NewContextWithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	panic("Not implemented")
+}
+
+func (c *fakeTimerClock) NewTimer(d time.Duration) (clock.Timer, <-chan time.Time) {
+	c.delays = append(c.delays, d)
+	t := make(chan time.Time, 1)
+	if c.expire {
+		t <- time.Unix(0, 0)
+	}
+	return fakeTimer{clock: c}, t
+}
+
+type fakeTimer struct {
+	clock *fakeTimerClock
+}
+
+func (t fakeTimer) Stop() bool {
+	t.clock.stopped++
+	return true
+}
+
+func TestEnforceCPUTimeLimit(t *testing.T) {
+	t.Run("Exhausted", func(t *testing.T) {
+		// Checks should be scheduled at the earliest point in
+		// time at which the budget may have been exhausted,
+		// given that two CPUs may be used concurrently.
+		usages := []time.Duration{
+			0,
+			6 * time.Second,
+			9995 * time.Millisecond,
+			-1,
+			10 * time.Second,
+		}
+		getUsage := func() (time.Duration, error) {
+			usage := usages[0]
+			usages = usages[1:]
+			if usage < 0 {
+				return 0, errors.New("cpu.stat is unavailable")
+			}
+			return usage, nil
+		}
+		clock := &fakeTimerClock{expire: true}
+		require.True(t, enforceCPUTimeLimit(clock, 10*time.Second, 2, getUsage, make(chan struct{})))
+		require.Equal(t, []time.Duration{
+			5 * time.Second,
+			2 * time.Second,
+			10 * time.Millisecond,
+			10 * time.Millisecond,
+		}, clock.delays)
+		require.Empty(t, usages)
+	})
+
+	t.Run("Stopped", func(t *testing.T) {
+		// Once the action completes, no further checks should
+		// be performed.
+		getUsage := func() (time.Duration, error) {
+			return time.Second, nil
+		}
+		stop := make(chan struct{})
+		close(stop)
+		clock := &fakeTimerClock{}
+		require.False(t, enforceCPUTimeLimit(clock, 10*time.Second, 1, getUsage, stop))
+		require.Equal(t, []time.Duration{9 * time.Second}, clock.delays)
+		require.Equal(t, 1, clock.stopped)
+	})
+}
@@ -0,0 +1,141 @@
+//go:build linux
+// +build linux
+
+package runner
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// cpuMaxPeriodMicroseconds is the period that is written to cpu.max.
+// It is equal to the kernel's default.
+const cpuMaxPeriodMicroseconds = 100000
+
+// cpuTimeLimitingCgroup is a cgroup in which a single action is run.
+// The rate at which processes in the cgroup consume CPU time is bounded
+// through cpu.max. Once the CPU time budget of the action is exhausted,
+// all processes in the cgroup are killed.
+//
+// This requires Linux 5.14 or later, as it depends on the availability
+// of cgroup.kill.
+type cpuTimeLimitingCgroup struct {
+	path          string
+	fd            int
+	limitExceeded atomic.Bool
+	stop          chan struct{}
+	stopped       chan struct{}
+}
+
+func newCPUTimeLimitingCgroup(parentPath, name string, maximumCPUs int, cmd *exec.Cmd) (*cpuTimeLimitingCgroup, error) {
+	cgroupPath := filepath.Join(parentPath, name)
+	if err := os.Mkdir(cgroupPath, 0o755); err != nil {
+		return nil, util.StatusWrapfWithCode(err, codes.Internal, "Failed to create cgroup %#v", cgroupPath)
+	}
+	cpuMax := fmt.Sprintf("%d %d", maximumCPUs*cpuMaxPeriodMicroseconds, cpuMaxPeriodMicroseconds)
+	if err := os.WriteFile(filepath.Join(cgroupPath, "cpu.max"), []byte(cpuMax), 0); err != nil {
+		unix.Rmdir(cgroupPath)
+		return nil, util.StatusWrapfWithCode(err, codes.Internal, "Failed to set maximum CPU bandwidth of cgroup %#v", cgroupPath)
+	}
+	fd, err := unix.Open(cgroupPath, unix.O_DIRECTORY|unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		unix.Rmdir(cgroupPath)
+		return nil, util.StatusWrapfWithCode(err, codes.Internal, "Failed to open cgroup %#v", cgroupPath)
+	}
+
+	// Let the process be spawned inside the cgroup directly, as
+	// opposed to moving it into the cgroup after it has started.
+	// This ensures that child processes cannot escape accounting.
+	var sysProcAttr syscall.SysProcAttr
+	if cmd.SysProcAttr != nil {
+		sysProcAttr = *cmd.SysProcAttr
+	}
+	sysProcAttr.UseCgroupFD = true
+	sysProcAttr.CgroupFD = fd
+	cmd.SysProcAttr = &sysProcAttr
+
+	return &cpuTimeLimitingCgroup{
+		path: cgroupPath,
+		fd:   fd,
+	}, nil
+}
+
+// getUsage returns the amount of CPU time used by all processes that
+// have run inside the cgroup.
+func (cg *cpuTimeLimitingCgroup) getUsage() (time.Duration, error) {
+	data, err := os.ReadFile(filepath.Join(cg.path, "cpu.stat"))
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, "usage_usec "); ok {
+			usageMicroseconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return 0, util.StatusWrapf(err, "Invalid CPU usage %#v", value)
+			}
+			return time.Duration(usageMicroseconds) * time.Microsecond, nil
+		}
+	}
+	return 0, status.Error(codes.Internal, "CPU usage is not reported")
+}
+
+func (cg *cpuTimeLimitingCgroup) kill() error {
+	return os.WriteFile(filepath.Join(cg.path, "cgroup.kill"), []byte("1"), 0)
+}
+
+// startMonitoring starts a goroutine that kills all processes in the
+// cgroup once they have used up their CPU time budget.
+func (cg *cpuTimeLimitingCgroup) startMonitoring(clock clock.Clock, limit time.Duration, maximumCPUs int) {
+	cg.stop = make(chan struct{})
+	cg.stopped = make(chan struct{})
+	go func() {
+		defer close(cg.stopped)
+		if enforceCPUTimeLimit(clock, limit, maximumCPUs, cg.getUsage, cg.stop) {
+			cg.limitExceeded.Store(true)
+			cg.kill()
+		}
+	}()
+}
+
+// close stops monitoring the cgroup and removes it. Processes that the
+// action left behind (e.g., by daemonizing) are killed, as the cgroup
+// cannot be removed otherwise. The return value indicates whether the
+// action was killed due to exceeding its CPU time budget.
+func (cg *cpuTimeLimitingCgroup) close() (bool, error) {
+	if cg.stop != nil {
+		close(cg.stop)
+		<-cg.stopped
+	}
+	unix.Close(cg.fd)
+
+	if err := cg.kill(); err != nil {
+		return false, util.StatusWrapfWithCode(err, codes.Internal, "Failed to kill processes in cgroup %#v", cg.path)
+	}
+	// Processes are removed from the cgroup asynchronously after
+	// being killed.
+	for attempt := 0; ; attempt++ {
+		err := unix.Rmdir(cg.path)
+		if err == nil {
+			break
+		}
+		if err != unix.EBUSY || attempt >= 100 {
+			return false, util.StatusWrapfWithCode(err, codes.Internal, "Failed to remove cgroup %#v", cg.path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return cg.limitExceeded.Load(), nil
+}
@@ -0,0 +1,29 @@
+//go:build !linux
+// +build !linux
+
+package runner
+
+import (
+	"os/exec"
+	"time"
+
+	"github.com/buildbarn/bb-storage/pkg/clock"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// cpuTimeLimitingCgroup is a placeholder for platforms that do not
+// support cgroups.
+type cpuTimeLimitingCgroup struct{}
+
+func newCPUTimeLimitingCgroup(parentPath, name string, maximumCPUs int, cmd *exec.Cmd) (*cpuTimeLimitingCgroup, error) {
+	return nil, status.Error(codes.Unimplemented, "CPU time limits can only be enforced on Linux")
+}
+
+func (cg *cpuTimeLimitingCgroup) startMonitoring(clock clock.Clock, limit time.Duration, maximumCPUs int) {
+}
+
+func (cg *cpuTimeLimitingCgroup) close() (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,259 @@
+package runner
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	runner_pb "github.com/buildbarn/bb-remote-execution/pkg/proto/runner"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GuestEnvironmentConfiguration contains the settings that are used by
+// the RunnerServer returned by NewGuestEnvironmentRunner to run
+// commands inside a WSL distribution or a container.
+type GuestEnvironmentConfiguration struct {
+	// Absolute path of the build directory on the host, using the
+	// host's path syntax (e.g., "C:\build").
+	BuildDirectoryPath string
+	// Environment variables that should be passed to the program
+	// that launches the guest environment (e.g., SystemRoot, PATH).
+	HostEnvironmentVariables map[string]string
+
+	// Name of the platform property whose value contains the name
+	// of the WSL distribution to run the command in.
+	WSLDistributionPlatformProperty string
+	// Path of the wsl.exe utility.
+	WSLPath string
+	// Pattern that WSL distributions requested by actions must
+	// match in their entirety. This is required if
+	// WSLDistributionPlatformProperty is set, as platform
+	// properties are under the control of clients.
+	AllowedWSLDistributions *regexp.Regexp
+
+	// Name of the platform property whose value contains the
+	// container image to run the command in.
+	ContainerImagePlatformProperty string
+	// Path of the container runtime's command line utility (e.g.,
+	// docker.exe).
+	ContainerRuntimePath string
+	// Path at which the build directory is bind mounted inside the
+	// container.
+	ContainerBuildDirectoryPath string
+	// Whether the container runtime runs Windows containers, as
+	// opposed to Linux containers. Windows containers don't support
+	// running an init process inside the container.
+	WindowsContainers bool
+	// Pattern that container images requested by actions must
+	// match in their entirety. This is required if
+	// ContainerImagePlatformProperty is set, as platform properties
+	// are under the control of clients.
+	AllowedContainerImages *regexp.Regexp
+	// The CPU time limit configuration of the underlying runner, if
+	// any. Containers are created by the container runtime's
+	// daemon, meaning they are not descendants of the command that
+	// is run by the underlying runner. They are placed in the
+	// action's cgroup explicitly, so that CPU time consumed by
+	// them is accounted for.
+	CPUTimeLimit *CPUTimeLimitConfiguration
+	// Path of CPUTimeLimit.CgroupParentPath, relative to the root
+	// of the cgroup hierarchy (e.g., "/bb_runner"). This is the
+	// syntax accepted by the container runtime's --cgroup-parent
+	// flag.
+	ContainerCgroupParent string
+}
+
+type guestEnvironmentRunner struct {
+	runner_pb.RunnerServer
+	configuration         GuestEnvironmentConfiguration
+	wslBuildDirectoryPath string
+}
+
+var (
+	windowsDrivePathPattern = regexp.MustCompile(`^([A-Za-z]):[\\/]?(.*)$`)
+
+	// wslDistributionNamePattern matches the names of WSL
+	// distributions (e.g., "Ubuntu-22.04"). Names must start with
+	// an alphanumeric character, so that they can never be
+	// interpreted as command line flags.
+	wslDistributionNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+	// containerImageReferencePattern matches the syntax of
+	// container image references (e.g.,
+	// "mcr.microsoft.com/windows/servercore:ltsc2022" or
+	// "ubuntu@sha256:..."). References must start with an
+	// alphanumeric character, so that they can never be
+	// interpreted as command line flags.
+	containerImageReferencePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/:@-]*$`)
+)
+
+// getWSLPath converts an absolute Windows path to the path at which it
+// is accessible inside a WSL distribution. WSL exposes Windows drives
+// through a Plan 9 file server, mounted under /mnt.
+func getWSLPath(windowsPath string) (string, error) {
+	match := windowsDrivePathPattern.FindStringSubmatch(windowsPath)
+	if match == nil {
+		return "", status.Errorf(codes.InvalidArgument, "Path %#v is not an absolute path starting with a drive letter", windowsPath)
+	}
+	return path.Join("/mnt", strings.ToLower(match[1]), strings.ReplaceAll(match[2], "\\", "/")), nil
+}
+
+// NewGuestEnvironmentRunner creates a decorator for RunnerServer that
+// runs commands inside a WSL distribution or a container when requested
+// through the action's platform properties. Commands of actions that
+// don't request either are run on the host.
+//
+// Commands are run by rewriting their arguments to invoke wsl.exe or
+// the container runtime. As platform properties are provided by
+// clients, WSL distributions and container images are only used if
+// they match a pattern of allowed distributions and images,
+// respectively. The build directory is made accessible to the
+// guest at the same location for every action: WSL exposes it through
+// its Plan 9 file server under /mnt, while containers receive it as a
+// bind mount. This allows a single worker to serve mixed Windows/Linux
+// CI fleets.
+//
+// Environment variables of the command are passed to the guest
+// explicitly, as neither wsl.exe nor the container runtime forward the
+// environment of the host. The command's stdout and stderr are captured
+// by the underlying RunnerServer as usual.
+func NewGuestEnvironmentRunner(base runner_pb.RunnerServer, configuration GuestEnvironmentConfiguration) (runner_pb.RunnerServer, error) {
+	r := &guestEnvironmentRunner{
+		RunnerServer:  base,
+		configuration: configuration,
+	}
+	if configuration.ContainerImagePlatformProperty != "" && configuration.AllowedContainerImages == nil {
+		return nil, status.Error(codes.InvalidArgument, "A pattern of allowed container images must be provided when running actions inside containers")
+	}
+	if configuration.WSLDistributionPlatformProperty != "" {
+		if configuration.AllowedWSLDistributions == nil {
+			return nil, status.Error(codes.InvalidArgument, "A pattern of allowed WSL distributions must be provided when running actions inside WSL distributions")
+		}
+		wslBuildDirectoryPath, err := getWSLPath(configuration.BuildDirectoryPath)
+		if err != nil {
+			return nil, err
+		}
+		r.wslBuildDirectoryPath = wslBuildDirectoryPath
+	}
+	return r, nil
+}
+
+// getSortedEnvironmentVariables converts a map of environment variables
+// to a sorted list of "name=value" pairs, so that the resulting command
+// line is deterministic.
+func getSortedEnvironmentVariables(environmentVariables map[string]string) []string {
+	pairs := make([]string, 0, len(environmentVariables))
+	for name, value := range environmentVariables {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+func (r *guestEnvironmentRunner) Run(ctx context.Context, request *runner_pb.RunRequest) (*runner_pb.RunResponse, error) {
+	var wslDistribution, containerImage string
+	if platform := request.Platform; platform != nil {
+		for _, property := range platform.Properties {
+			if name := property.Name; name == r.configuration.WSLDistributionPlatformProperty && name != "" {
+				wslDistribution = property.Value
+			} else if name == r.configuration.ContainerImagePlatformProperty && name != "" {
+				containerImage = property.Value
+			}
+		}
+	}
+	if len(request.Arguments) < 1 {
+		return nil, status.Error(codes.InvalidArgument, "Insufficient number of command arguments")
+	}
+
+	var arguments []string
+	switch {
+	case wslDistribution != "" && containerImage != "":
+		return nil, status.Error(codes.InvalidArgument, "Actions cannot request both a WSL distribution and a container image")
+	case wslDistribution != "":
+		if !wslDistributionNamePattern.MatchString(wslDistribution) || !r.configuration.AllowedWSLDistributions.MatchString(wslDistribution) {
+			return nil, status.Errorf(codes.InvalidArgument, "WSL distribution %#v is not permitted", wslDistribution)
+		}
+		// Run the command through env(1), so that environment
+		// variables are set without relying on WSLENV.
+		environmentVariables := getSortedEnvironmentVariables(request.EnvironmentVariables)
+		if request.TemporaryDirectory != "" {
+			environmentVariables = append(environmentVariables, "TMPDIR="+path.Join(r.wslBuildDirectoryPath, request.TemporaryDirectory))
+		}
+		arguments = append(
+			[]string{
+				r.configuration.WSLPath,
+				"--distribution", wslDistribution,
+				"--cd", path.Join(r.wslBuildDirectoryPath, request.InputRootDirectory, request.WorkingDirectory),
+				"--exec", "/usr/bin/env", "-i",
+			},
+			environmentVariables...)
+	case containerImage != "":
+		if !containerImageReferencePattern.MatchString(containerImage) || !r.configuration.AllowedContainerImages.MatchString(containerImage) {
+			return nil, status.Errorf(codes.InvalidArgument, "Container image %#v is not permitted", containerImage)
+		}
+		containerBuildDirectoryPath := r.configuration.ContainerBuildDirectoryPath
+		arguments = []string{r.configuration.ContainerRuntimePath, "run", "--rm"}
+		if !r.configuration.WindowsContainers {
+			// Let an init process reap zombie processes
+			// and forward signals to the command.
+			arguments = append(arguments, "--init")
+		}
+		arguments = append(
+			arguments,
+			"--volume", r.configuration.BuildDirectoryPath+":"+containerBuildDirectoryPath,
+			"--workdir", path.Join(containerBuildDirectoryPath, request.InputRootDirectory, request.WorkingDirectory))
+		for _, environmentVariable := range getSortedEnvironmentVariables(request.EnvironmentVariables) {
+			arguments = append(arguments, "--env", environmentVariable)
+		}
+		if request.TemporaryDirectory != "" {
+			arguments = append(arguments, "--env", "TMPDIR="+path.Join(containerBuildDirectoryPath, request.TemporaryDirectory))
+		}
+		if r.configuration.CPUTimeLimit != nil {
+			cpuTimeLimit, err := r.configuration.CPUTimeLimit.getLimit(request)
+			if err != nil {
+				return nil, err
+			}
+			if cpuTimeLimit > 0 {
+				// Let the underlying runner create the
+				// action's cgroup under a name that is
+				// known up front, so that the container
+				// can be placed inside it.
+				cgroupName := newCPUTimeLimitingCgroupName()
+				ctx = context.WithValue(ctx, cpuTimeLimitingCgroupNameKey{}, cgroupName)
+				arguments = append(arguments, "--cgroup-parent", path.Join(r.configuration.ContainerCgroupParent, cgroupName))
+			}
+		}
+		if request.StdinPath != "" {
+			// Keep stdin open, so that it can be forwarded
+			// to the command in the container.
+			arguments = append(arguments, "--interactive")
+		}
+		// Terminate the list of options, so that the image
+		// and the command's arguments are never interpreted as
+		// options of the container runtime.
+		arguments = append(arguments, "--", containerImage)
+	default:
+		return r.RunnerServer.Run(ctx, request)
+	}
+	arguments = append(arguments, request.Arguments...)
+
+	// The program launching the guest environment is run on the
+	// host, meaning it should receive the host's environment. The
+	// platform is forwarded, so that the underlying runner applies
+	// the same CPU time limit.
+	return r.RunnerServer.Run(ctx, &runner_pb.RunRequest{
+		Arguments:            arguments,
+		EnvironmentVariables: r.configuration.HostEnvironmentVariables,
+		WorkingDirectory:     request.WorkingDirectory,
+		StdoutPath:           request.StdoutPath,
+		StderrPath:           request.StderrPath,
+		InputRootDirectory:   request.InputRootDirectory,
+		TemporaryDirectory:   request.TemporaryDirectory,
+		StdinPath:            request.StdinPath,
+		Platform:             request.Platform,
+	})
+}
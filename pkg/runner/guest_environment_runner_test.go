@@ -0,0 +1,304 @@
+package runner_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	runner_pb "github.com/buildbarn/bb-remote-execution/pkg/proto/runner"
+	"github.com/buildbarn/bb-remote-execution/pkg/runner"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGuestEnvironmentRunner(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseRunner := mock.NewMockRunnerServer(ctrl)
+	runner, err := runner.NewGuestEnvironmentRunner(baseRunner, runner.GuestEnvironmentConfiguration{
+		BuildDirectoryPath:              "C:\\build",
+		HostEnvironmentVariables:        map[string]string{"SystemRoot": "C:\\Windows"},
+		WSLDistributionPlatformProperty: "wsl-distribution",
+		WSLPath:                         "wsl.exe",
+		AllowedWSLDistributions:         regexp.MustCompile(`^(Ubuntu-22\.04|-.*)$`),
+		ContainerImagePlatformProperty:  "container-image",
+		ContainerRuntimePath:            "docker.exe",
+		ContainerBuildDirectoryPath:     "/build",
+		AllowedContainerImages:          regexp.MustCompile(`^(ubuntu:22\.04|-.*)$`),
+	})
+	require.NoError(t, err)
+
+	response := &runner_pb.RunResponse{
+		ExitCode: 123,
+	}
+	newRequest := func(properties ...*remoteexecution.Platform_Property) *runner_pb.RunRequest {
+		return &runner_pb.RunRequest{
+			Arguments: []string{"cc", "-o", "hello.o", "hello.c"},
+			EnvironmentVariables: map[string]string{
+				"PATH": "/bin:/usr/bin",
+				"LANG": "C",
+			},
+			WorkingDirectory:   "src",
+			StdoutPath:         "1/stdout",
+			StderrPath:         "1/stderr",
+			InputRootDirectory: "1/root",
+			TemporaryDirectory: "1/tmp",
+			Platform: &remoteexecution.Platform{
+				Properties: properties,
+			},
+		}
+	}
+
+	t.Run("Host", func(t *testing.T) {
+		// Actions not requesting a guest environment should be
+		// run on the host.
+		request := newRequest(&remoteexecution.Platform_Property{Name: "OSFamily", Value: "Windows"})
+		baseRunner.EXPECT().Run(ctx, testutil.EqProto(t, request)).Return(response, nil)
+
+		observedResponse, err := runner.Run(ctx, request)
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, response, observedResponse)
+	})
+
+	t.Run("WSL", func(t *testing.T) {
+		baseRunner.EXPECT().Run(ctx, testutil.EqProto(t, &runner_pb.RunRequest{
+			Arguments: []string{
+				"wsl.exe",
+				"--distribution", "Ubuntu-22.04",
+				"--cd", "/mnt/c/build/1/root/src",
+				"--exec", "/usr/bin/env", "-i",
+				"LANG=C",
+				"PATH=/bin:/usr/bin",
+				"TMPDIR=/mnt/c/build/1/tmp",
+				"cc", "-o", "hello.o", "hello.c",
+			},
+			EnvironmentVariables: map[string]string{"SystemRoot": "C:\\Windows"},
+			WorkingDirectory:     "src",
+			StdoutPath:           "1/stdout",
+			StderrPath:           "1/stderr",
+			InputRootDirectory:   "1/root",
+			TemporaryDirectory:   "1/tmp",
+			Platform: &remoteexecution.Platform{
+				Properties: []*remoteexecution.Platform_Property{
+					{Name: "wsl-distribution", Value: "Ubuntu-22.04"},
+				},
+			},
+		})).Return(response, nil)
+
+		observedResponse, err := runner.Run(ctx, newRequest(&remoteexecution.Platform_Property{Name: "wsl-distribution", Value: "Ubuntu-22.04"}))
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, response, observedResponse)
+	})
+
+	t.Run("WSLDistributionNotAllowed", func(t *testing.T) {
+		// WSL distributions that don't match the pattern of
+		// allowed distributions should be rejected.
+		_, err := runner.Run(ctx, newRequest(&remoteexecution.Platform_Property{Name: "wsl-distribution", Value: "docker-desktop"}))
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "WSL distribution \"docker-desktop\" is not permitted"), err)
+	})
+
+	t.Run("WSLDistributionOption", func(t *testing.T) {
+		// WSL distributions that could be interpreted as
+		// options of wsl.exe should be rejected, even if the
+		// pattern of allowed distributions is too permissive.
+		_, err := runner.Run(ctx, newRequest(&remoteexecution.Platform_Property{Name: "wsl-distribution", Value: "--system"}))
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "WSL distribution \"--system\" is not permitted"), err)
+	})
+
+	t.Run("Container", func(t *testing.T) {
+		baseRunner.EXPECT().Run(ctx, testutil.EqProto(t, &runner_pb.RunRequest{
+			Arguments: []string{
+				"docker.exe",
+				"run", "--rm", "--init",
+				"--volume", "C:\\build:/build",
+				"--workdir", "/build/1/root/src",
+				"--env", "LANG=C",
+				"--env", "PATH=/bin:/usr/bin",
+				"--env", "TMPDIR=/build/1/tmp",
+				"--",
+				"ubuntu:22.04",
+				"cc", "-o", "hello.o", "hello.c",
+			},
+			EnvironmentVariables: map[string]string{"SystemRoot": "C:\\Windows"},
+			WorkingDirectory:     "src",
+			StdoutPath:           "1/stdout",
+			StderrPath:           "1/stderr",
+			InputRootDirectory:   "1/root",
+			TemporaryDirectory:   "1/tmp",
+			Platform: &remoteexecution.Platform{
+				Properties: []*remoteexecution.Platform_Property{
+					{Name: "container-image", Value: "ubuntu:22.04"},
+				},
+			},
+		})).Return(response, nil)
+
+		observedResponse, err := runner.Run(ctx, newRequest(&remoteexecution.Platform_Property{Name: "container-image", Value: "ubuntu:22.04"}))
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, response, observedResponse)
+	})
+
+	t.Run("ContainerImageNotAllowed", func(t *testing.T) {
+		// Container images that don't match the pattern of
+		// allowed images should be rejected.
+		_, err := runner.Run(ctx, newRequest(&remoteexecution.Platform_Property{Name: "container-image", Value: "evil/image:latest"}))
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Container image \"evil/image:latest\" is not permitted"), err)
+	})
+
+	t.Run("ContainerImageOption", func(t *testing.T) {
+		// Container images that could be interpreted as options
+		// of the container runtime should be rejected, even if
+		// the pattern of allowed images is too permissive.
+		_, err := runner.Run(ctx, newRequest(&remoteexecution.Platform_Property{Name: "container-image", Value: "--privileged"}))
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Container image \"--privileged\" is not permitted"), err)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		_, err := runner.Run(ctx, newRequest(
+			&remoteexecution.Platform_Property{Name: "container-image", Value: "ubuntu:22.04"},
+			&remoteexecution.Platform_Property{Name: "wsl-distribution", Value: "Ubuntu-22.04"}))
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Actions cannot request both a WSL distribution and a container image"), err)
+	})
+}
+
+func TestGuestEnvironmentRunnerWindowsContainers(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseRunner := mock.NewMockRunnerServer(ctrl)
+	runner, err := runner.NewGuestEnvironmentRunner(baseRunner, runner.GuestEnvironmentConfiguration{
+		BuildDirectoryPath:             "C:\\build",
+		ContainerImagePlatformProperty: "container-image",
+		ContainerRuntimePath:           "docker.exe",
+		ContainerBuildDirectoryPath:    "C:/build",
+		WindowsContainers:              true,
+		AllowedContainerImages:         regexp.MustCompile(`^mcr\.microsoft\.com/windows/servercore:ltsc2022$`),
+	})
+	require.NoError(t, err)
+
+	// Windows containers don't support running an init process,
+	// meaning --init should not be provided.
+	baseRunner.EXPECT().Run(ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request *runner_pb.RunRequest) (*runner_pb.RunResponse, error) {
+			require.Equal(t, []string{
+				"docker.exe",
+				"run", "--rm",
+				"--volume", "C:\\build:C:/build",
+				"--workdir", "C:/build",
+				"--",
+				"mcr.microsoft.com/windows/servercore:ltsc2022",
+				"cmd.exe", "/c", "exit",
+			}, request.Arguments)
+			return &runner_pb.RunResponse{}, nil
+		})
+
+	_, err = runner.Run(ctx, &runner_pb.RunRequest{
+		Arguments: []string{"cmd.exe", "/c", "exit"},
+		Platform: &remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "container-image", Value: "mcr.microsoft.com/windows/servercore:ltsc2022"},
+			},
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestGuestEnvironmentRunnerContainerCPUTimeLimit(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseRunner := mock.NewMockRunnerServer(ctrl)
+	runner, err := runner.NewGuestEnvironmentRunner(baseRunner, runner.GuestEnvironmentConfiguration{
+		BuildDirectoryPath:             "/build",
+		ContainerImagePlatformProperty: "container-image",
+		ContainerRuntimePath:           "docker",
+		ContainerBuildDirectoryPath:    "/build",
+		AllowedContainerImages:         regexp.MustCompile(`^ubuntu:22\.04$`),
+		CPUTimeLimit: &runner.CPUTimeLimitConfiguration{
+			CgroupParentPath: "/sys/fs/cgroup/bb_runner",
+			PlatformProperty: "cpu-time-limit",
+			MaximumLimit:     time.Hour,
+			MaximumCPUs:      1,
+		},
+		ContainerCgroupParent: "/bb_runner",
+	})
+	require.NoError(t, err)
+
+	t.Run("NoLimit", func(t *testing.T) {
+		// Actions without a CPU time limit should not be
+		// placed in a cgroup.
+		baseRunner.EXPECT().Run(ctx, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, request *runner_pb.RunRequest) (*runner_pb.RunResponse, error) {
+				require.NotContains(t, request.Arguments, "--cgroup-parent")
+				return &runner_pb.RunResponse{}, nil
+			})
+
+		_, err := runner.Run(ctx, &runner_pb.RunRequest{
+			Arguments: []string{"true"},
+			Platform: &remoteexecution.Platform{
+				Properties: []*remoteexecution.Platform_Property{
+					{Name: "container-image", Value: "ubuntu:22.04"},
+				},
+			},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		// Containers are created by the container runtime's
+		// daemon. They should be placed in the cgroup of the
+		// action explicitly, as they would otherwise escape
+		// accounting of CPU time.
+		baseRunner.EXPECT().Run(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, request *runner_pb.RunRequest) (*runner_pb.RunResponse, error) {
+				require.Len(t, request.Arguments, 13)
+				require.Equal(t, []string{"docker", "run", "--rm", "--init", "--volume", "/build:/build", "--workdir", "/build"}, request.Arguments[:8])
+				require.Equal(t, "--cgroup-parent", request.Arguments[8])
+				require.Regexp(t, `^/bb_runner/action-[0-9]+-[0-9]+$`, request.Arguments[9])
+				require.Equal(t, []string{"--", "ubuntu:22.04", "true"}, request.Arguments[10:])
+				return &runner_pb.RunResponse{}, nil
+			})
+
+		_, err := runner.Run(ctx, &runner_pb.RunRequest{
+			Arguments: []string{"true"},
+			Platform: &remoteexecution.Platform{
+				Properties: []*remoteexecution.Platform_Property{
+					{Name: "container-image", Value: "ubuntu:22.04"},
+					{Name: "cpu-time-limit", Value: "60s"},
+				},
+			},
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestGuestEnvironmentRunnerNoAllowedContainerImages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	// Running actions inside containers without restricting which
+	// images may be used should not be permitted.
+	_, err := runner.NewGuestEnvironmentRunner(mock.NewMockRunnerServer(ctrl), runner.GuestEnvironmentConfiguration{
+		BuildDirectoryPath:             "C:\\build",
+		ContainerImagePlatformProperty: "container-image",
+		ContainerRuntimePath:           "docker.exe",
+		ContainerBuildDirectoryPath:    "/build",
+	})
+	testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "A pattern of allowed container images must be provided when running actions inside containers"), err)
+}
+
+func TestGuestEnvironmentRunnerNoAllowedWSLDistributions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	// Running actions inside WSL distributions without restricting
+	// which distributions may be used should not be permitted.
+	_, err := runner.NewGuestEnvironmentRunner(mock.NewMockRunnerServer(ctrl), runner.GuestEnvironmentConfiguration{
+		BuildDirectoryPath:              "C:\\build",
+		WSLDistributionPlatformProperty: "wsl-distribution",
+		WSLPath:                         "wsl.exe",
+	})
+	testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "A pattern of allowed WSL distributions must be provided when running actions inside WSL distributions"), err)
+}
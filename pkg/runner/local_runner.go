@@ -3,13 +3,18 @@ package runner
 import (
 	"context"
 	"errors"
+	"io"
+	"math"
 	"os/exec"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/runner"
 	"github.com/buildbarn/bb-storage/pkg/filesystem"
 	"github.com/buildbarn/bb-storage/pkg/filesystem/path"
 	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -17,6 +22,19 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+var (
+	localRunnerPrometheusMetrics sync.Once
+
+	localRunnerCancellationToExitDurationSeconds = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "buildbarn",
+			Subsystem: "runner",
+			Name:      "local_runner_cancellation_to_exit_duration_seconds",
+			Help:      "Amount of time it took for processes to terminate after the execution of the action was canceled, in seconds.",
+			Buckets:   util.DecimalExponentialBuckets(-3, 6, 2),
+		})
+)
+
 // buildDirectoryPathResolver is an implementation of
 // path.ComponentWalker that is used by localRunner.Run() to resolve
 // paths inside the build directory, such as stdout and stderr log files
@@ -67,6 +85,7 @@ type localRunner struct {
 	buildDirectoryPath           *path.Builder
 	commandCreator               CommandCreator
 	setTmpdirEnvironmentVariable bool
+	cpuTimeLimit                 *CPUTimeLimitConfiguration
 }
 
 func (r *localRunner) openLog(logPath string) (filesystem.FileAppender, error) {
@@ -83,6 +102,20 @@ func (r *localRunner) openLog(logPath string) (filesystem.FileAppender, error) {
 	return logFileResolver.stack.Peek().OpenAppend(*logFileResolver.TerminalName, filesystem.CreateExcl(0o666))
 }
 
+func (r *localRunner) openStdin(stdinPath string) (filesystem.FileReader, error) {
+	stdinFileResolver := buildDirectoryPathResolver{
+		stack: util.NewNonEmptyStack(filesystem.NopDirectoryCloser(r.buildDirectory)),
+	}
+	defer stdinFileResolver.closeAll()
+	if err := path.Resolve(stdinPath, path.NewRelativeScopeWalker(&stdinFileResolver)); err != nil {
+		return nil, err
+	}
+	if stdinFileResolver.TerminalName == nil {
+		return nil, status.Error(codes.InvalidArgument, "Path resolves to a directory")
+	}
+	return stdinFileResolver.stack.Peek().OpenRead(*stdinFileResolver.TerminalName)
+}
+
 // CommandCreator is a type alias for a function that creates the
 // exec.Cmd in localRunner.Run(). It may use different strategies for
 // resolving the paths of argv[0] and the working directory, depending
@@ -90,13 +123,20 @@ func (r *localRunner) openLog(logPath string) (filesystem.FileAppender, error) {
 type CommandCreator func(ctx context.Context, arguments []string, inputRootDirectory *path.Builder, workingDirectory, pathVariable string) (*exec.Cmd, error)
 
 // NewLocalRunner returns a Runner capable of running commands on the
-// local system directly.
-func NewLocalRunner(buildDirectory filesystem.Directory, buildDirectoryPath *path.Builder, commandCreator CommandCreator, setTmpdirEnvironmentVariable bool) runner.RunnerServer {
+// local system directly. If a CPU time limit configuration is
+// provided, actions are terminated once they exhaust their CPU time
+// budget.
+func NewLocalRunner(buildDirectory filesystem.Directory, buildDirectoryPath *path.Builder, commandCreator CommandCreator, setTmpdirEnvironmentVariable bool, cpuTimeLimit *CPUTimeLimitConfiguration) runner.RunnerServer {
+	localRunnerPrometheusMetrics.Do(func() {
+		prometheus.MustRegister(localRunnerCancellationToExitDurationSeconds)
+	})
+
 	return &localRunner{
 		buildDirectory:               buildDirectory,
 		buildDirectoryPath:           buildDirectoryPath,
 		commandCreator:               commandCreator,
 		setTmpdirEnvironmentVariable: setTmpdirEnvironmentVariable,
+		cpuTimeLimit:                 cpuTimeLimit,
 	}
 }
 
@@ -110,6 +150,15 @@ func (r *localRunner) Run(ctx context.Context, request *runner.RunRequest) (*run
 		return nil, util.StatusWrap(err, "Failed to resolve input root directory")
 	}
 
+	var cpuTimeLimit time.Duration
+	if r.cpuTimeLimit != nil {
+		var err error
+		cpuTimeLimit, err = r.cpuTimeLimit.getLimit(request)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	cmd, err := r.commandCreator(ctx, request.Arguments, inputRootDirectory, request.WorkingDirectory, request.EnvironmentVariables["PATH"])
 	if err != nil {
 		return nil, err
@@ -130,6 +179,17 @@ func (r *localRunner) Run(ctx context.Context, request *runner.RunRequest) (*run
 		cmd.Env = append(cmd.Env, name+"="+value)
 	}
 
+	// Provide the contents of a file in the build directory over
+	// stdin, if requested.
+	if request.StdinPath != "" {
+		stdin, err := r.openStdin(request.StdinPath)
+		if err != nil {
+			return nil, util.StatusWrapf(err, "Failed to open stdin path %q", request.StdinPath)
+		}
+		defer stdin.Close()
+		cmd.Stdin = io.NewSectionReader(stdin, 0, math.MaxInt64)
+	}
+
 	// Open output files for logging.
 	stdout, err := r.openLog(request.StdoutPath)
 	if err != nil {
@@ -144,12 +204,31 @@ func (r *localRunner) Run(ctx context.Context, request *runner.RunRequest) (*run
 	}
 	cmd.Stderr = stderr
 
+	// Place the process in a cgroup of its own if it needs to be
+	// subject to a CPU time limit.
+	var cgroup *cpuTimeLimitingCgroup
+	if cpuTimeLimit > 0 {
+		cgroupName, ok := ctx.Value(cpuTimeLimitingCgroupNameKey{}).(string)
+		if !ok {
+			cgroupName = newCPUTimeLimitingCgroupName()
+		}
+		cgroup, err = newCPUTimeLimitingCgroup(r.cpuTimeLimit.CgroupParentPath, cgroupName, r.cpuTimeLimit.MaximumCPUs, cmd)
+		if err != nil {
+			stdout.Close()
+			stderr.Close()
+			return nil, err
+		}
+	}
+
 	// Start the subprocess. We can already close the output files
 	// while the process is running.
 	err = cmd.Start()
 	stdout.Close()
 	stderr.Close()
 	if err != nil {
+		if cgroup != nil {
+			cgroup.close()
+		}
 		code := codes.Internal
 		for _, invalidArgumentErr := range invalidArgumentErrs {
 			if errors.Is(err, invalidArgumentErr) {
@@ -159,22 +238,52 @@ func (r *localRunner) Run(ctx context.Context, request *runner.RunRequest) (*run
 		}
 		return nil, util.StatusWrapWithCode(err, code, "Failed to start process")
 	}
+	if cgroup != nil {
+		cgroup.startMonitoring(r.cpuTimeLimit.Clock, cpuTimeLimit, r.cpuTimeLimit.MaximumCPUs)
+	}
 
 	// Wait for execution to complete. Permit non-zero exit codes.
-	if err := cmd.Wait(); err != nil {
-		if _, ok := err.(*exec.ExitError); !ok {
+	// If the action gets canceled or times out, measure how long it
+	// takes for the process to terminate.
+	canceledAt := make(chan time.Time, 1)
+	stopCancellationMeasurement := context.AfterFunc(ctx, func() {
+		canceledAt <- time.Now()
+	})
+	waitErr := cmd.Wait()
+	canceled := !stopCancellationMeasurement()
+	if canceled {
+		localRunnerCancellationToExitDurationSeconds.Observe(time.Since(<-canceledAt).Seconds())
+	}
+	cpuTimeLimitExceeded := false
+	if cgroup != nil {
+		cpuTimeLimitExceeded, err = cgroup.close()
+		if err != nil {
 			return nil, err
 		}
 	}
+	if canceled {
+		return nil, util.StatusFromContext(ctx)
+	}
+	if waitErr != nil {
+		if _, ok := waitErr.(*exec.ExitError); !ok {
+			return nil, waitErr
+		}
+	}
 
 	// Attach rusage information to the response.
 	posixResourceUsage, err := anypb.New(getPOSIXResourceUsage(cmd))
 	if err != nil {
 		return nil, util.StatusWrap(err, "Failed to marshal POSIX resource usage")
 	}
+	// Processes that are killed for exceeding their CPU time
+	// budget are reported separately from the execution timeout,
+	// which is based on wall-clock time. The exit code is still
+	// returned, so that the worker can upload any output that was
+	// written prior to termination.
 	return &runner.RunResponse{
-		ExitCode:      int32(cmd.ProcessState.ExitCode()),
-		ResourceUsage: []*anypb.Any{posixResourceUsage},
+		ExitCode:             int32(cmd.ProcessState.ExitCode()),
+		ResourceUsage:        []*anypb.Any{posixResourceUsage},
+		CpuTimeLimitExceeded: cpuTimeLimitExceeded,
 	}, nil
 }
 
@@ -8,7 +8,9 @@ import (
 	"strings"
 	"syscall"
 	"testing"
+	"time"
 
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
 	"github.com/buildbarn/bb-remote-execution/internal/mock"
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/resourceusage"
 	runner_pb "github.com/buildbarn/bb-remote-execution/pkg/proto/runner"
@@ -27,7 +29,7 @@ func TestLocalRunnerCheckReadiness(t *testing.T) {
 	ctrl, ctx := gomock.WithContext(context.Background(), t)
 
 	buildDirectory := mock.NewMockDirectory(ctrl)
-	runner := runner.NewLocalRunner(buildDirectory, &path.EmptyBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false)
+	runner := runner.NewLocalRunner(buildDirectory, &path.EmptyBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, nil)
 
 	t.Run("NoPathSpecified", func(t *testing.T) {
 		_, err := runner.CheckReadiness(ctx, &runner_pb.CheckReadinessRequest{})
@@ -114,7 +116,7 @@ func TestLocalRunnerRun(t *testing.T) {
 		// variables should cause the process to be executed in
 		// an empty environment. It should not inherit the
 		// environment of the runner.
-		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false)
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, nil)
 		response, err := runner.Run(context.Background(), &runner_pb.RunRequest{
 			Arguments:          getEnvCommand,
 			StdoutPath:         "EmptyEnvironment/stdout",
@@ -144,7 +146,7 @@ func TestLocalRunnerRun(t *testing.T) {
 		// The environment variables provided in the RunRequest
 		// should be respected. If automatic injection of TMPDIR
 		// is enabled, that variable should also be added.
-		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), true)
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), true, nil)
 		response, err := runner.Run(context.Background(), &runner_pb.RunRequest{
 			Arguments: getEnvCommand,
 			EnvironmentVariables: map[string]string{
@@ -202,7 +204,7 @@ func TestLocalRunnerRun(t *testing.T) {
 
 		// Automatic injection of TMPDIR should have no effect
 		// if the command to be run provides its own TMPDIR.
-		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), true)
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), true, nil)
 		response, err := runner.Run(context.Background(), &runner_pb.RunRequest{
 			Arguments:            getEnvCommand,
 			EnvironmentVariables: envMap,
@@ -246,7 +248,7 @@ func TestLocalRunnerRun(t *testing.T) {
 		} else {
 			exit255Command = []string{"/bin/sh", "-c", "exit 255"}
 		}
-		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false)
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, nil)
 		response, err := runner.Run(context.Background(), &runner_pb.RunRequest{
 			Arguments:          exit255Command,
 			StdoutPath:         "NonZeroExitCode/stdout",
@@ -266,6 +268,84 @@ func TestLocalRunnerRun(t *testing.T) {
 		require.Empty(t, stderr)
 	})
 
+	t.Run("Stdin", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			return
+		}
+
+		testPath := filepath.Join(buildDirectoryPath, "Stdin")
+		require.NoError(t, os.Mkdir(testPath, 0o777))
+		require.NoError(t, os.Mkdir(filepath.Join(testPath, "root"), 0o777))
+		require.NoError(t, os.Mkdir(filepath.Join(testPath, "tmp"), 0o777))
+		require.NoError(t, os.WriteFile(filepath.Join(testPath, "stdin"), []byte("Hello world\n"), 0o444))
+
+		// The contents of the file referenced by the request
+		// should be readable by the process over stdin.
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, nil)
+		response, err := runner.Run(context.Background(), &runner_pb.RunRequest{
+			Arguments:          []string{"/bin/cat"},
+			StdoutPath:         "Stdin/stdout",
+			StderrPath:         "Stdin/stderr",
+			InputRootDirectory: "Stdin/root",
+			TemporaryDirectory: "Stdin/tmp",
+			StdinPath:          "Stdin/stdin",
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), response.ExitCode)
+
+		stdout, err := os.ReadFile(filepath.Join(testPath, "stdout"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("Hello world\n"), stdout)
+	})
+
+	t.Run("InvalidCPUTimeLimit", func(t *testing.T) {
+		// CPU time limits provided through platform properties
+		// should be validated before the process is launched.
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, &runner.CPUTimeLimitConfiguration{
+			CgroupParentPath: "/sys/fs/cgroup/nonexistent",
+			PlatformProperty: "cpu-time-limit",
+			MaximumLimit:     time.Hour,
+			MaximumCPUs:      1,
+		})
+		_, err := runner.Run(context.Background(), &runner_pb.RunRequest{
+			Arguments:          []string{"/bin/true"},
+			StdoutPath:         "InvalidCPUTimeLimit/stdout",
+			StderrPath:         "InvalidCPUTimeLimit/stderr",
+			InputRootDirectory: "InvalidCPUTimeLimit/root",
+			TemporaryDirectory: "InvalidCPUTimeLimit/tmp",
+			Platform: &remoteexecution.Platform{
+				Properties: []*remoteexecution.Platform_Property{
+					{Name: "cpu-time-limit", Value: "banana"},
+				},
+			},
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Invalid value for platform property \"cpu-time-limit\": time: invalid duration \"banana\""), err)
+	})
+
+	t.Run("ExcessiveCPUTimeLimit", func(t *testing.T) {
+		// Actions should not be able to raise their CPU time
+		// budget beyond the configured maximum.
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, &runner.CPUTimeLimitConfiguration{
+			CgroupParentPath: "/sys/fs/cgroup/nonexistent",
+			PlatformProperty: "cpu-time-limit",
+			MaximumLimit:     time.Hour,
+			MaximumCPUs:      1,
+		})
+		_, err := runner.Run(context.Background(), &runner_pb.RunRequest{
+			Arguments:          []string{"/bin/true"},
+			StdoutPath:         "ExcessiveCPUTimeLimit/stdout",
+			StderrPath:         "ExcessiveCPUTimeLimit/stderr",
+			InputRootDirectory: "ExcessiveCPUTimeLimit/root",
+			TemporaryDirectory: "ExcessiveCPUTimeLimit/tmp",
+			Platform: &remoteexecution.Platform{
+				Properties: []*remoteexecution.Platform_Property{
+					{Name: "cpu-time-limit", Value: "2h"},
+				},
+			},
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Value for platform property \"cpu-time-limit\" exceeds the maximum of 1h0m0s"), err)
+	})
+
 	t.Run("SigKill", func(t *testing.T) {
 		if runtime.GOOS == "windows" {
 			return
@@ -279,7 +359,7 @@ func TestLocalRunnerRun(t *testing.T) {
 		// If the process terminates due to a signal, the name
 		// of the signal should be set as part of the POSIX
 		// resource usage message.
-		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false)
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, nil)
 		response, err := runner.Run(context.Background(), &runner_pb.RunRequest{
 			Arguments:          []string{"/bin/sh", "-c", "kill -s KILL $$"},
 			StdoutPath:         "SigKill/stdout",
@@ -304,6 +384,38 @@ func TestLocalRunnerRun(t *testing.T) {
 		require.Empty(t, stderr)
 	})
 
+	t.Run("CancelKillsProcessGroup", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			return
+		}
+
+		testPath := filepath.Join(buildDirectoryPath, "CancelKillsProcessGroup")
+		require.NoError(t, os.Mkdir(testPath, 0o777))
+		require.NoError(t, os.Mkdir(filepath.Join(testPath, "root"), 0o777))
+		require.NoError(t, os.Mkdir(filepath.Join(testPath, "tmp"), 0o777))
+
+		// Canceling the action should not only terminate the
+		// process itself, but also any children it spawned.
+		// The error should reflect that the action was
+		// canceled.
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, nil)
+		_, err := runner.Run(ctx, &runner_pb.RunRequest{
+			Arguments:            []string{"/bin/sh", "-c", "(sleep 1; touch ../marker) & wait"},
+			EnvironmentVariables: map[string]string{"PATH": "/bin:/usr/bin"},
+			StdoutPath:           "CancelKillsProcessGroup/stdout",
+			StderrPath:           "CancelKillsProcessGroup/stderr",
+			InputRootDirectory:   "CancelKillsProcessGroup/root",
+			TemporaryDirectory:   "CancelKillsProcessGroup/tmp",
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.Canceled, "context canceled"), err)
+
+		time.Sleep(2 * time.Second)
+		_, err = os.Stat(filepath.Join(testPath, "marker"))
+		require.True(t, os.IsNotExist(err))
+	})
+
 	t.Run("UnknownCommandWithEmptyPath", func(t *testing.T) {
 		testPath := filepath.Join(buildDirectoryPath, "UnknownCommandWithEmptyPath")
 		require.NoError(t, os.Mkdir(testPath, 0o777))
@@ -314,7 +426,7 @@ func TestLocalRunnerRun(t *testing.T) {
 		// against $PATH need to be performed. If PATH is not
 		// set, the action should fail with a non-retriable
 		// error.
-		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false)
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, nil)
 		_, err := runner.Run(context.Background(), &runner_pb.RunRequest{
 			Arguments:          []string{"nonexistent_command"},
 			StdoutPath:         "UnknownCommandWithEmptyPath/stdout",
@@ -333,7 +445,7 @@ func TestLocalRunnerRun(t *testing.T) {
 
 		// Even invoking known shell utilities shouldn't be
 		// permitted if PATH points to a nonexistent location.
-		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false)
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, nil)
 		_, err := runner.Run(context.Background(), &runner_pb.RunRequest{
 			Arguments:            []string{"sh", "-c", "exit 123"},
 			EnvironmentVariables: map[string]string{"PATH": "/nonexistent"},
@@ -361,7 +473,7 @@ func TestLocalRunnerRun(t *testing.T) {
 		// working directory. Because the search path is
 		// relative, execve() should be called with a relative
 		// path as well.
-		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false)
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, nil)
 		response, err := runner.Run(context.Background(), &runner_pb.RunRequest{
 			Arguments:            []string{"hello.sh"},
 			EnvironmentVariables: map[string]string{"PATH": "subdirectory"},
@@ -392,7 +504,7 @@ func TestLocalRunnerRun(t *testing.T) {
 		// of multiple components, no $PATH lookup is performed.
 		// If the path does not exist, the action should fail
 		// with a non-retriable error.
-		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false)
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, nil)
 		_, err := runner.Run(context.Background(), &runner_pb.RunRequest{
 			Arguments:          []string{"./nonexistent_command"},
 			StdoutPath:         "UnknownCommandRelative/stdout",
@@ -411,7 +523,7 @@ func TestLocalRunnerRun(t *testing.T) {
 
 		// If argv[0] is an absolute path that does not exist,
 		// we should also return a non-retriable error.
-		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false)
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, nil)
 		_, err := runner.Run(context.Background(), &runner_pb.RunRequest{
 			Arguments:          []string{"/nonexistent_command"},
 			StdoutPath:         "UnknownCommandAbsolute/stdout",
@@ -434,7 +546,7 @@ func TestLocalRunnerRun(t *testing.T) {
 		// If argv[0] is a binary that cannot be executed we
 		// should also return a non-retriable error. In this
 		// case it's a JPEG file.
-		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false)
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, nil)
 		_, err := runner.Run(context.Background(), &runner_pb.RunRequest{
 			Arguments:          []string{"./not_a.binary"},
 			StdoutPath:         "ExecFormatErrorJPEG/stdout",
@@ -491,7 +603,7 @@ func TestLocalRunnerRun(t *testing.T) {
 		//
 		// Test this by attempting to run a tiny Mach-O
 		// executable that uses CPU_TYPE_VAX.
-		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false)
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, nil)
 		_, err := runner.Run(context.Background(), &runner_pb.RunRequest{
 			Arguments:          []string{"./not_a.binary"},
 			StdoutPath:         "ExecFormatErrorMachOBadArch/stdout",
@@ -510,7 +622,7 @@ func TestLocalRunnerRun(t *testing.T) {
 
 		// If argv[0] refers to a directory, we should also
 		// return a non-retriable error.
-		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false)
+		runner := runner.NewLocalRunner(buildDirectory, buildDirectoryPathBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, nil)
 		_, err := runner.Run(context.Background(), &runner_pb.RunRequest{
 			Arguments:          []string{"/"},
 			StdoutPath:         "UnknownCommandDirectory/stdout",
@@ -531,7 +643,7 @@ func TestLocalRunnerRun(t *testing.T) {
 		// privileges. It shouldn't be possible to trick the
 		// runner into opening files outside the build
 		// directory.
-		runner := runner.NewLocalRunner(buildDirectory, &path.EmptyBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false)
+		runner := runner.NewLocalRunner(buildDirectory, &path.EmptyBuilder, runner.NewPlainCommandCreator(&syscall.SysProcAttr{}), false, nil)
 		_, err := runner.Run(context.Background(), &runner_pb.RunRequest{
 			Arguments:          getEnvCommand,
 			StdoutPath:         "hello/../../../../../../etc/passwd",
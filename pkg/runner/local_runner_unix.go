@@ -78,6 +78,29 @@ func lookupExecutable(workingDirectory *path.Builder, pathVariable, argv0 string
 	return "", status.Errorf(codes.InvalidArgument, "Cannot find executable %#v in search paths %#v", argv0, pathVariable)
 }
 
+// killProcessGroupOnCancel adjusts a command to be launched in its own
+// process group. Upon cancellation, the entire process group is killed,
+// as opposed to just the process itself. This ensures that any child
+// processes spawned by the action (e.g., compilers launched by a build
+// script) don't continue to run, wasting capacity on the worker.
+//
+// Processes that place themselves in another process group or session
+// are not terminated. These need to be cleaned up by other means, such
+// as a process table cleaner.
+func killProcessGroupOnCancel(cmd *exec.Cmd, sysProcAttr *syscall.SysProcAttr) {
+	sysProcAttrCopy := *sysProcAttr
+	if !sysProcAttrCopy.Setsid {
+		// Processes that create a new session are already the
+		// leader of a new process group.
+		sysProcAttrCopy.Setpgid = true
+		sysProcAttrCopy.Pgid = 0
+	}
+	cmd.SysProcAttr = &sysProcAttrCopy
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
 // NewPlainCommandCreator returns a CommandCreator for cases where we don't
 // need to chroot into the input root directory.
 func NewPlainCommandCreator(sysProcAttr *syscall.SysProcAttr) CommandCreator {
@@ -100,7 +123,7 @@ func NewPlainCommandCreator(sysProcAttr *syscall.SysProcAttr) CommandCreator {
 		cmd.Args = arguments
 		cmd.Dir = workingDirectory.String()
 		cmd.Path = executablePath
-		cmd.SysProcAttr = sysProcAttr
+		killProcessGroupOnCancel(cmd, sysProcAttr)
 		return cmd, nil
 	}
 }
@@ -117,7 +140,7 @@ func NewChrootedCommandCreator(sysProcAttr *syscall.SysProcAttr) (CommandCreator
 		cmd := exec.CommandContext(ctx, "/usr/bin/env", append([]string{"--"}, arguments...)...)
 		sysProcAttrCopy := *sysProcAttr
 		sysProcAttrCopy.Chroot = inputRootDirectory.String()
-		cmd.SysProcAttr = &sysProcAttrCopy
+		killProcessGroupOnCancel(cmd, &sysProcAttrCopy)
 
 		// Set the working relative to be relative to the root
 		// directory of the chrooted environment.
@@ -5,7 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"math"
+	"slices"
 	"sort"
 	"strconv"
 	"sync"
@@ -34,6 +37,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
@@ -54,6 +58,13 @@ var (
 			Help:      "Number of times an Execute() request of a cacheable action was performed, and whether it was in-flight deduplicated against an existing task.",
 		},
 		[]string{"instance_name_prefix", "platform", "size_class", "outcome"})
+	inMemoryBuildQueueSkipCacheLookupIgnoredTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "buildbarn",
+			Subsystem: "builder",
+			Name:      "in_memory_build_queue_skip_cache_lookup_ignored_total",
+			Help:      "Number of times an Execute() request had skip_cache_lookup set, but was ignored due to a per-instance name policy.",
+		})
 
 	inMemoryBuildQueueInvocationsCreatedTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -123,6 +134,14 @@ var (
 			Buckets:   prometheus.LinearBuckets(0, 1, 11),
 		},
 		[]string{"instance_name_prefix", "platform", "size_class", "result", "grpc_code"})
+	inMemoryBuildQueueTasksMigratedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "buildbarn",
+			Subsystem: "builder",
+			Name:      "in_memory_build_queue_tasks_migrated_total",
+			Help:      "Number of queued tasks that were migrated to another platform queue, as their size class queue got drained or removed.",
+		},
+		[]string{"instance_name_prefix", "platform", "size_class"})
 	inMemoryBuildQueueTasksCompletedDurationSeconds = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Namespace: "buildbarn",
@@ -202,6 +221,13 @@ type InMemoryBuildQueueConfiguration struct {
 	// synchronization requests get smeared out over time.
 	GetIdleWorkerSynchronizationInterval func() time.Duration
 
+	// StreamingIdleWorkerSynchronizationInterval is identical to
+	// GetIdleWorkerSynchronizationInterval, except that it applies
+	// to workers that synchronize using SynchronizeStream(). As
+	// the liveness of these workers is tracked through the stream,
+	// this value may be considerably larger.
+	StreamingIdleWorkerSynchronizationInterval time.Duration
+
 	// WorkerTaskRetryCount specifies how many times a worker may
 	// redundantly request that a single task is started. By
 	// limiting this, we can prevent a single task from
@@ -212,6 +238,51 @@ type InMemoryBuildQueueConfiguration struct {
 	// worker may remain registered by InMemoryBuildQueue when no
 	// Synchronize() calls are received.
 	WorkerWithNoSynchronizationsTimeout time.Duration
+
+	// ReportExpectedDurationToClients specifies whether the
+	// expected duration and timeout of an operation, as computed
+	// by the initial size class analyzer, should be reported to
+	// clients as part of ExecuteOperationMetadata. This allows
+	// clients to make informed decisions about whether to run
+	// actions locally or remotely.
+	ReportExpectedDurationToClients bool
+
+	// MigrateQueuedOperations specifies whether queued operations
+	// should be migrated to another platform queue when the size
+	// class queue in which they are placed is removed, or when all
+	// of its workers are drained. Without this option, operations
+	// are failed back to clients when their size class queue is
+	// removed, and remain queued when all workers are drained.
+	//
+	// Operations are only migrated to platform queues that have the
+	// same instance name prefix and the same platform properties,
+	// disregarding the ones listed in
+	// MigrationIgnoredPlatformPropertyNames. The platform queue
+	// must have at least one worker that is not drained.
+	MigrateQueuedOperations bool
+
+	// MigrationIgnoredPlatformPropertyNames contains the names of
+	// platform properties that should be ignored when determining
+	// whether platform queues are equivalent for the purpose of
+	// migrating queued operations (e.g., properties identifying a
+	// pool or a datacenter).
+	MigrationIgnoredPlatformPropertyNames []string
+
+	// SkipCacheLookupIgnoredInstanceNamePrefixes contains instance
+	// name prefixes for which ExecuteRequest.skip_cache_lookup
+	// provided by clients is ignored. For other instance names,
+	// requests having skip_cache_lookup set are not deduplicated
+	// against identical tasks that are already queued or executing,
+	// causing the action to be executed once more. The effective
+	// value is forwarded to workers.
+	SkipCacheLookupIgnoredInstanceNamePrefixes []digest.InstanceName
+
+	// CacheKeyExcludedPlatformPropertyNames contains the names of
+	// platform properties that are disregarded when deduplicating
+	// in-flight actions. This allows actions that only differ in
+	// properties that don't affect the outcome of execution (e.g.,
+	// hints for selecting a worker pool) to share a single task.
+	CacheKeyExcludedPlatformPropertyNames []string
 }
 
 // InMemoryBuildQueue implements a BuildQueue that can distribute
@@ -229,6 +300,15 @@ type InMemoryBuildQueue struct {
 	maximumMessageSizeBytes             int
 	actionRouter                        routing.ActionRouter
 
+	// Default and enforced platform properties of predeclared
+	// platform queues. These are protected by a separate lock, so
+	// that they can be merged into actions without holding the
+	// main lock, as this requires writing into the Content
+	// Addressable Storage.
+	platformPropertyMergersLock sync.RWMutex
+	platformPropertyMergersTrie *platform.Trie
+	platformPropertyMergers     []*platform.PropertyMerger
+
 	lock               sync.Mutex
 	platformQueuesTrie *platform.Trie
 	platformQueues     []*platformQueue
@@ -244,6 +324,10 @@ type InMemoryBuildQueue struct {
 	// same action.
 	inFlightDeduplicationMap map[digest.Digest]*task
 
+	// Instance name prefixes for which skip_cache_lookup provided
+	// by clients is ignored.
+	skipCacheLookupIgnoredTrie *digest.InstanceNameTrie
+
 	// Time value that is updated during every mutation of build
 	// queue state. This reduces the number of clock accesses, while
 	// also making it easier to test this code.
@@ -264,6 +348,17 @@ type InMemoryBuildQueue struct {
 	// Authorizer used to allow/deny access for certain users to
 	// perform KillOperations calls.
 	killOperationsAuthorizer auth.Authorizer
+
+	// Authorizer used to determine whether workers are permitted
+	// to write the results of actions into the Action Cache.
+	actionCacheWriteAuthorizer auth.Authorizer
+
+	// Authorizer used to determine whether clients are permitted
+	// to let their actions submit sub-actions, and the tasks of
+	// actions that are permitted to do so, keyed by the token that
+	// is provided to the worker.
+	nestedExecutionAuthorizer auth.Authorizer
+	nestedExecutionTokens     map[string]*task
 }
 
 var inMemoryBuildQueueCapabilitiesProvider = capabilities.NewStaticProvider(&remoteexecution.ServerCapabilities{
@@ -282,9 +377,10 @@ var inMemoryBuildQueueCapabilitiesProvider = capabilities.NewStaticProvider(&rem
 // NewInMemoryBuildQueue creates a new InMemoryBuildQueue that is in the
 // initial state. It does not have any queues, workers or queued
 // execution requests. All of these are created by sending it RPCs.
-func NewInMemoryBuildQueue(contentAddressableStorage blobstore.BlobAccess, clock clock.Clock, uuidGenerator util.UUIDGenerator, configuration *InMemoryBuildQueueConfiguration, maximumMessageSizeBytes int, actionRouter routing.ActionRouter, executeAuthorizer, modifyDrainsAuthorizer, killOperationsAuthorizer auth.Authorizer) *InMemoryBuildQueue {
+func NewInMemoryBuildQueue(contentAddressableStorage blobstore.BlobAccess, clock clock.Clock, uuidGenerator util.UUIDGenerator, configuration *InMemoryBuildQueueConfiguration, maximumMessageSizeBytes int, actionRouter routing.ActionRouter, executeAuthorizer, modifyDrainsAuthorizer, killOperationsAuthorizer, actionCacheWriteAuthorizer, nestedExecutionAuthorizer auth.Authorizer) *InMemoryBuildQueue {
 	inMemoryBuildQueuePrometheusMetrics.Do(func() {
 		prometheus.MustRegister(inMemoryBuildQueueInFlightDeduplicationsTotal)
+		prometheus.MustRegister(inMemoryBuildQueueSkipCacheLookupIgnoredTotal)
 
 		prometheus.MustRegister(inMemoryBuildQueueInvocationsCreatedTotal)
 		prometheus.MustRegister(inMemoryBuildQueueInvocationsActivatedTotal)
@@ -295,6 +391,7 @@ func NewInMemoryBuildQueue(contentAddressableStorage blobstore.BlobAccess, clock
 		prometheus.MustRegister(inMemoryBuildQueueTasksQueuedDurationSeconds)
 		prometheus.MustRegister(inMemoryBuildQueueTasksExecutingDurationSeconds)
 		prometheus.MustRegister(inMemoryBuildQueueTasksExecutingRetries)
+		prometheus.MustRegister(inMemoryBuildQueueTasksMigratedTotal)
 		prometheus.MustRegister(inMemoryBuildQueueTasksCompletedDurationSeconds)
 
 		prometheus.MustRegister(inMemoryBuildQueueWorkersCreatedTotal)
@@ -304,6 +401,10 @@ func NewInMemoryBuildQueue(contentAddressableStorage blobstore.BlobAccess, clock
 		prometheus.MustRegister(inMemoryBuildQueueWorkerInvocationStickinessRetained)
 	})
 
+	skipCacheLookupIgnoredTrie := digest.NewInstanceNameTrie()
+	for _, instanceNamePrefix := range configuration.SkipCacheLookupIgnoredInstanceNamePrefixes {
+		skipCacheLookupIgnoredTrie.Set(instanceNamePrefix, 0)
+	}
 	return &InMemoryBuildQueue{
 		Provider: capabilities.NewAuthorizingProvider(inMemoryBuildQueueCapabilitiesProvider, executeAuthorizer),
 
@@ -314,13 +415,18 @@ func NewInMemoryBuildQueue(contentAddressableStorage blobstore.BlobAccess, clock
 		platformQueueAbsenceHardFailureTime: clock.Now().Add(configuration.PlatformQueueWithNoWorkersTimeout),
 		maximumMessageSizeBytes:             maximumMessageSizeBytes,
 		actionRouter:                        actionRouter,
+		platformPropertyMergersTrie:         platform.NewTrie(),
 		platformQueuesTrie:                  platform.NewTrie(),
 		sizeClassQueues:                     map[sizeClassKey]*sizeClassQueue{},
 		operationsNameMap:                   map[string]*operation{},
 		inFlightDeduplicationMap:            map[digest.Digest]*task{},
+		skipCacheLookupIgnoredTrie:          skipCacheLookupIgnoredTrie,
 		executeAuthorizer:                   executeAuthorizer,
 		modifyDrainsAuthorizer:              modifyDrainsAuthorizer,
 		killOperationsAuthorizer:            killOperationsAuthorizer,
+		actionCacheWriteAuthorizer:          actionCacheWriteAuthorizer,
+		nestedExecutionAuthorizer:           nestedExecutionAuthorizer,
+		nestedExecutionTokens:               map[string]*task{},
 	}
 }
 
@@ -338,7 +444,20 @@ var (
 // capable of using multiple size classes, as a maximum size class and
 // initialsizeclass.Analyzer can be provided for specifying how
 // operations are assigned to size classes.
-func (bq *InMemoryBuildQueue) RegisterPredeclaredPlatformQueue(instanceNamePrefix digest.InstanceName, platformMessage *remoteexecution.Platform, workerInvocationStickinessLimits []time.Duration, maximumQueuedBackgroundLearningOperations int, backgroundLearningOperationPriority int32, maximumSizeClass uint32) error {
+//
+// If backgroundLearningOnIdleWorkersOnly is set, operations that are
+// created to perform background learning are only assigned to workers
+// if no other operations are queued, meaning they only run on capacity
+// that would otherwise remain idle.
+//
+// If platformPropertyMerger is provided, it is used to merge default
+// platform properties into the platform of actions that are placed in
+// this queue, prior to sending them to workers. As this yields a
+// different action, it is written into the Content Addressable Storage
+// and executed under its own digest. This ensures that results are
+// stored in the Action Cache under the digest of the action that was
+// actually executed.
+func (bq *InMemoryBuildQueue) RegisterPredeclaredPlatformQueue(instanceNamePrefix digest.InstanceName, platformMessage *remoteexecution.Platform, workerInvocationStickinessLimits []time.Duration, maximumQueuedBackgroundLearningOperations int, backgroundLearningOperationPriority int32, backgroundLearningOnIdleWorkersOnly bool, platformPropertyMerger *platform.PropertyMerger, maximumSizeClass uint32) error {
 	platformKey, err := platform.NewKey(instanceNamePrefix, platformMessage)
 	if err != nil {
 		return err
@@ -351,11 +470,31 @@ func (bq *InMemoryBuildQueue) RegisterPredeclaredPlatformQueue(instanceNamePrefi
 		return status.Error(codes.AlreadyExists, "A queue with the same instance name prefix or platform already exists")
 	}
 
-	pq := bq.addPlatformQueue(platformKey, workerInvocationStickinessLimits, maximumQueuedBackgroundLearningOperations, backgroundLearningOperationPriority)
+	if platformPropertyMerger != nil {
+		bq.platformPropertyMergersLock.Lock()
+		bq.platformPropertyMergersTrie.Set(platformKey, len(bq.platformPropertyMergers))
+		bq.platformPropertyMergers = append(bq.platformPropertyMergers, platformPropertyMerger)
+		bq.platformPropertyMergersLock.Unlock()
+	}
+
+	pq := bq.addPlatformQueue(platformKey, workerInvocationStickinessLimits, maximumQueuedBackgroundLearningOperations, backgroundLearningOperationPriority, backgroundLearningOnIdleWorkersOnly)
 	pq.addSizeClassQueue(bq, maximumSizeClass, false)
 	return nil
 }
 
+// getPlatformPropertyMerger returns the PropertyMerger of the
+// predeclared platform queue in which actions having a given platform
+// key are placed, if any.
+func (bq *InMemoryBuildQueue) getPlatformPropertyMerger(platformKey platform.Key) *platform.PropertyMerger {
+	bq.platformPropertyMergersLock.RLock()
+	defer bq.platformPropertyMergersLock.RUnlock()
+
+	if index := bq.platformPropertyMergersTrie.GetLongestPrefix(platformKey); index >= 0 {
+		return bq.platformPropertyMergers[index]
+	}
+	return nil
+}
+
 // getRequestMetadata extracts the RequestMetadata message stored in the
 // gRPC request headers. This message contains the invocation ID that is
 // used to group incoming requests by client, so that tasks can be
@@ -383,16 +522,30 @@ func (bq *InMemoryBuildQueue) Execute(in *remoteexecution.ExecuteRequest, out re
 	// To prevent loading this messages from the Content Addressable
 	// Storage (CAS) multiple times, the scheduler holds on to it
 	// and passes it on to the workers.
-	ctx := out.Context()
 	instanceName, err := digest.NewInstanceName(in.InstanceName)
 	if err != nil {
 		return util.StatusWrapf(err, "Invalid instance name %#v", in.InstanceName)
 	}
 
+	ctx, err := bq.getNestedExecutionContext(out.Context())
+	if err != nil {
+		return err
+	}
 	if err := auth.AuthorizeSingleInstanceName(ctx, bq.executeAuthorizer, instanceName); err != nil {
 		return util.StatusWrap(err, "Authorization")
 	}
 
+	// Determine whether the client is permitted to let the action
+	// submit sub-actions. This must be done without holding any
+	// locks, as the authorizer may block.
+	permitNestedExecution := true
+	if err := auth.AuthorizeSingleInstanceName(ctx, bq.nestedExecutionAuthorizer, instanceName); err != nil {
+		if status.Code(err) != codes.PermissionDenied {
+			return util.StatusWrap(err, "Failed to authorize nested execution")
+		}
+		permitNestedExecution = false
+	}
+
 	digestFunction, err := instanceName.GetDigestFunction(in.DigestFunction, len(in.ActionDigest.GetHash()))
 	if err != nil {
 		return err
@@ -431,15 +584,59 @@ func (bq *InMemoryBuildQueue) Execute(in *remoteexecution.ExecuteRequest, out re
 	}
 	w3cTraceContext := otel.W3CTraceContextFromContext(ctx)
 
+	// Clients may request that an action is executed again, even if
+	// a cached result is available. Unless ignored, this also
+	// prevents deduplicating the action against an identical task
+	// that is already queued or executing.
+	skipCacheLookup := in.SkipCacheLookup
+	if skipCacheLookup && bq.skipCacheLookupIgnoredTrie.ContainsPrefix(instanceName) {
+		skipCacheLookup = false
+		inMemoryBuildQueueSkipCacheLookupIgnoredTotal.Inc()
+	}
+
 	platformKey, invocationKeys, initialSizeClassSelector, err := bq.actionRouter.RouteAction(ctx, actionDigest.GetDigestFunction(), action, requestMetadata)
 	if err != nil {
 		return util.StatusWrap(err, "Failed to route action")
 	}
 
+	// Merge default platform properties of the platform queue into
+	// the action, and record which changes were made, so that they
+	// can be inspected afterwards. The resulting action is stored
+	// in the Content Addressable Storage, so that workers store
+	// its results in the Action Cache under its own digest, as
+	// opposed to that of the action provided by the client.
+	if platformPropertyMerger := bq.getPlatformPropertyMerger(platformKey); platformPropertyMerger != nil {
+		mergedPlatform, appliedPlatformProperties := platformPropertyMerger.MergePlatform(action.Platform)
+		if appliedPlatformProperties != nil {
+			mergedAction := proto.Clone(action).(*remoteexecution.Action)
+			mergedAction.Platform = mergedPlatform
+			mergedActionDigest, err := blobstore.CASPutProto(ctx, bq.contentAddressableStorage, mergedAction, digestFunction)
+			if err != nil {
+				initialSizeClassSelector.Abandoned()
+				return util.StatusWrap(err, "Failed to store action with merged platform properties")
+			}
+			appliedPlatformProperties.OriginalActionDigest = actionDigest.GetProto()
+			appliedPlatformPropertiesAny, err := anypb.New(appliedPlatformProperties)
+			if err != nil {
+				initialSizeClassSelector.Abandoned()
+				return util.StatusWrap(err, "Failed to marshal applied platform properties")
+			}
+			action = mergedAction
+			actionDigest = mergedActionDigest
+			auxiliaryMetadata = append(auxiliaryMetadata, appliedPlatformPropertiesAny)
+		}
+	}
+
+	inFlightDeduplicationKey, err := bq.getInFlightDeduplicationKey(actionDigest, action)
+	if err != nil {
+		initialSizeClassSelector.Abandoned()
+		return util.StatusWrap(err, "Failed to compute in-flight deduplication key")
+	}
+
 	bq.enter(bq.clock.Now())
 	defer bq.leave()
 
-	if t, ok := bq.inFlightDeduplicationMap[actionDigest]; ok {
+	if t, ok := bq.inFlightDeduplicationMap[inFlightDeduplicationKey]; ok && !skipCacheLookup {
 		// A task for the same action digest already exists
 		// against which we may deduplicate. No need to create a
 		// task.
@@ -500,25 +697,43 @@ func (bq *InMemoryBuildQueue) Execute(in *remoteexecution.ExecuteRequest, out re
 	actionWithCustomTimeout := *action
 	actionWithCustomTimeout.Timeout = durationpb.New(timeout)
 	t := &task{
-		operations:   map[*invocation]*operation{},
-		actionDigest: actionDigest,
+		operations:               map[*invocation]*operation{},
+		actionDigest:             actionDigest,
+		inFlightDeduplicationKey: inFlightDeduplicationKey,
 		desiredState: remoteworker.DesiredState_Executing{
-			ActionDigest:       in.ActionDigest,
+			ActionDigest:       actionDigest.GetProto(),
 			Action:             &actionWithCustomTimeout,
 			QueuedTimestamp:    bq.getCurrentTime(),
 			AuxiliaryMetadata:  auxiliaryMetadata,
 			InstanceNameSuffix: pq.instanceNamePatcher.PatchInstanceName(instanceName).String(),
 			DigestFunction:     digestFunction.GetEnumValue(),
 			W3CTraceContext:    w3cTraceContext,
+			SkipCacheLookup:    skipCacheLookup,
 		},
 		targetID:                requestMetadata.GetTargetId(),
 		expectedDuration:        expectedDuration,
 		initialSizeClassLearner: initialSizeClassLearner,
 		stageChangeWakeup:       make(chan struct{}),
 	}
+	if permitNestedExecution {
+		// Provide the worker a token that it can use to
+		// submit sub-actions on behalf of the client.
+		nestedExecutionToken := uuid.Must(bq.uuidGenerator()).String()
+		t.desiredState.NestedExecutionToken = nestedExecutionToken
+		t.nestedExecutionAuthenticationMetadata = auth.AuthenticationMetadataFromContext(ctx)
+		bq.nestedExecutionTokens[nestedExecutionToken] = t
+	}
 	if !action.DoNotCache {
-		bq.inFlightDeduplicationMap[actionDigest] = t
-		scq.inFlightDeduplicationsNew.Inc()
+		if _, ok := bq.inFlightDeduplicationMap[inFlightDeduplicationKey]; ok {
+			// An identical task already exists, but the
+			// client requested that the action is executed
+			// again. Let other requests continue to be
+			// deduplicated against the existing task.
+			scq.inFlightDeduplicationsSkipCacheLookup.Inc()
+		} else {
+			bq.inFlightDeduplicationMap[inFlightDeduplicationKey] = t
+			scq.inFlightDeduplicationsNew.Inc()
+		}
 	}
 	i := scq.getOrCreateInvocation(bq, invocationKeys)
 	o := t.newOperation(bq, in.ExecutionPolicy.GetPriority(), i, false)
@@ -530,6 +745,11 @@ func (bq *InMemoryBuildQueue) Execute(in *remoteexecution.ExecuteRequest, out re
 // Execute(). This call can be used by the client to reattach to an
 // operation in case of network failure.
 func (bq *InMemoryBuildQueue) WaitExecution(in *remoteexecution.WaitExecutionRequest, out remoteexecution.Execution_WaitExecutionServer) error {
+	ctx, err := bq.getNestedExecutionContext(out.Context())
+	if err != nil {
+		return err
+	}
+
 	bq.enter(bq.clock.Now())
 	for {
 		o, ok := bq.operationsNameMap[in.Name]
@@ -543,7 +763,7 @@ func (bq *InMemoryBuildQueue) WaitExecution(in *remoteexecution.WaitExecutionReq
 		// This must be done without holding any locks, as the authorizer
 		// may block.
 		bq.leave()
-		if err := auth.AuthorizeSingleInstanceName(out.Context(), bq.executeAuthorizer, instanceName); err != nil {
+		if err := auth.AuthorizeSingleInstanceName(ctx, bq.executeAuthorizer, instanceName); err != nil {
 			return util.StatusWrap(err, "Authorization")
 		}
 
@@ -555,14 +775,111 @@ func (bq *InMemoryBuildQueue) WaitExecution(in *remoteexecution.WaitExecutionReq
 	}
 }
 
+// getNestedExecutionContext returns the context against which
+// Execute() and WaitExecution() requests are authorized. Requests that
+// workers send on behalf of actions that submit sub-actions carry the
+// nested execution token of the parent action. These requests are
+// authorized using the authentication metadata of the client that
+// submitted the parent action, as opposed to that of the worker.
+func (bq *InMemoryBuildQueue) getNestedExecutionContext(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+	tokens := md.Get(re_builder.NestedExecutionTokenMetadataKey)
+	switch len(tokens) {
+	case 0:
+		return ctx, nil
+	case 1:
+		bq.enter(bq.clock.Now())
+		t, ok := bq.nestedExecutionTokens[tokens[0]]
+		bq.leave()
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "Nested execution token is invalid, or belongs to an action that has already completed")
+		}
+		return auth.NewContextWithAuthenticationMetadata(ctx, t.nestedExecutionAuthenticationMetadata), nil
+	default:
+		return nil, status.Error(codes.InvalidArgument, "Request contains multiple nested execution tokens")
+	}
+}
+
 // Synchronize the state of a worker with the scheduler. This call is
 // used by a worker to report the completion of an operation and to
 // request more work.
 func (bq *InMemoryBuildQueue) Synchronize(ctx context.Context, request *remoteworker.SynchronizeRequest) (*remoteworker.SynchronizeResponse, error) {
+	return bq.synchronize(ctx, request, bq.configuration.GetIdleWorkerSynchronizationInterval)
+}
+
+// SynchronizeStream is equivalent to Synchronize(), except that
+// synchronizations of a single worker are performed over a
+// long-lived stream. This removes the overhead of creating an RPC for
+// every synchronization. As the lifetime of the stream is tied to that
+// of the worker, idle workers are permitted to block for a longer
+// amount of time, thereby reducing the number of synchronizations
+// performed by idle workers.
+func (bq *InMemoryBuildQueue) SynchronizeStream(stream remoteworker.OperationQueue_SynchronizeStreamServer) error {
+	ctx := stream.Context()
+	for {
+		request, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		response, err := bq.synchronize(ctx, request, bq.getStreamingIdleWorkerSynchronizationInterval)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(response); err != nil {
+			return err
+		}
+	}
+}
+
+func (bq *InMemoryBuildQueue) getStreamingIdleWorkerSynchronizationInterval() time.Duration {
+	return bq.configuration.StreamingIdleWorkerSynchronizationInterval
+}
+
+func (bq *InMemoryBuildQueue) synchronize(ctx context.Context, request *remoteworker.SynchronizeRequest, getIdleSynchronizationInterval func() time.Duration) (*remoteworker.SynchronizeResponse, error) {
 	instanceNamePrefix, err := digest.NewInstanceName(request.InstanceNamePrefix)
 	if err != nil {
 		return nil, util.StatusWrapf(err, "Invalid instance name %#v", request.InstanceNamePrefix)
 	}
+
+	// Determine whether the worker is permitted to write results
+	// into the Action Cache. This is based on the credentials of
+	// the worker, as opposed to any properties it reports about
+	// itself. This must be done without holding any locks, as the
+	// authorizer may block.
+	prohibitActionCacheWrites := false
+	if err := auth.AuthorizeSingleInstanceName(ctx, bq.actionCacheWriteAuthorizer, instanceNamePrefix); err != nil {
+		if status.Code(err) != codes.PermissionDenied {
+			return nil, util.StatusWrap(err, "Failed to authorize Action Cache writes")
+		}
+		prohibitActionCacheWrites = true
+	}
+
+	response, err := bq.synchronizeWithInstanceName(ctx, request, instanceNamePrefix, getIdleSynchronizationInterval)
+	if err != nil || !prohibitActionCacheWrites {
+		return response, err
+	}
+	executing := response.DesiredState.GetExecuting()
+	if executing == nil {
+		return response, nil
+	}
+	executing = proto.Clone(executing).(*remoteworker.DesiredState_Executing)
+	executing.ProhibitActionCacheWrites = true
+	return &remoteworker.SynchronizeResponse{
+		NextSynchronizationAt: response.NextSynchronizationAt,
+		DesiredState: &remoteworker.DesiredState{
+			WorkerState: &remoteworker.DesiredState_Executing_{
+				Executing: executing,
+			},
+		},
+	}, nil
+}
+
+func (bq *InMemoryBuildQueue) synchronizeWithInstanceName(ctx context.Context, request *remoteworker.SynchronizeRequest, instanceNamePrefix digest.InstanceName, getIdleSynchronizationInterval func() time.Duration) (*remoteworker.SynchronizeResponse, error) {
 	platformKey, err := platform.NewKey(instanceNamePrefix, request.Platform)
 	if err != nil {
 		return nil, err
@@ -608,7 +925,7 @@ func (bq *InMemoryBuildQueue) Synchronize(ctx context.Context, request *remotewo
 			// pair has not been observed before. Create a
 			// new platform queue containing a single size
 			// class queue.
-			pq = bq.addPlatformQueue(platformKey, nil, 0, 0)
+			pq = bq.addPlatformQueue(platformKey, nil, 0, 0, false)
 		}
 		scq = pq.addSizeClassQueue(bq, request.SizeClass, true)
 	}
@@ -654,7 +971,7 @@ func (bq *InMemoryBuildQueue) Synchronize(ctx context.Context, request *remotewo
 	}
 	switch workerState := currentState.WorkerState.(type) {
 	case *remoteworker.CurrentState_Idle:
-		return w.getCurrentOrNextTask(ctx, bq, scq, request.WorkerId, request.PreferBeingIdle)
+		return w.getCurrentOrNextTask(ctx, bq, scq, request.WorkerId, request.PreferBeingIdle, getIdleSynchronizationInterval)
 	case *remoteworker.CurrentState_Executing_:
 		executing := workerState.Executing
 		if executing.ActionDigest == nil {
@@ -662,9 +979,9 @@ func (bq *InMemoryBuildQueue) Synchronize(ctx context.Context, request *remotewo
 		}
 		switch executionState := executing.ExecutionState.(type) {
 		case *remoteworker.CurrentState_Executing_Completed:
-			return w.completeTask(ctx, bq, scq, request.WorkerId, executing.ActionDigest, executionState.Completed, request.PreferBeingIdle)
+			return w.completeTask(ctx, bq, scq, request.WorkerId, executing.ActionDigest, executionState.Completed, request.PreferBeingIdle, getIdleSynchronizationInterval)
 		default:
-			return w.updateTask(bq, scq, request.WorkerId, executing.ActionDigest, request.PreferBeingIdle)
+			return w.updateTask(bq, scq, request.WorkerId, executing, request.PreferBeingIdle)
 		}
 	default:
 		return nil, status.Error(codes.InvalidArgument, "Worker provided an unknown current state")
@@ -798,6 +1115,104 @@ func (bq *InMemoryBuildQueue) KillOperations(ctx context.Context, request *build
 	}
 }
 
+// ReprioritizeOperation changes the priority of an operation that is
+// currently in the QUEUED stage. As this method is intended to be
+// called by clients (e.g., to promote the target a user is waiting
+// on), it is authorized using the same policy as Execute(). Operation
+// names are unguessable, meaning that clients can only reprioritize
+// operations that they created or are waiting on.
+//
+// To ensure clients cannot use this method to overtake other
+// invocations, the priority of an operation may not be raised above
+// that of the first queued operation in the same invocation.
+func (bq *InMemoryBuildQueue) ReprioritizeOperation(ctx context.Context, request *buildqueuestate.ReprioritizeOperationRequest) (*emptypb.Empty, error) {
+	for {
+		// Extract the instance name prefix of the size class
+		// queue to which the operation belongs.
+		bq.enter(bq.clock.Now())
+		o, ok := bq.operationsNameMap[request.OperationName]
+		if !ok {
+			bq.leave()
+			return nil, status.Errorf(codes.NotFound, "Operation %#v not found", request.OperationName)
+		}
+		instanceNamePrefix := o.task.getCurrentSizeClassQueue().getKey().platformKey.GetInstanceNamePrefix()
+		bq.leave()
+
+		// Perform authorization checks without holding any
+		// locks.
+		if err := auth.AuthorizeSingleInstanceName(ctx, bq.executeAuthorizer, instanceNamePrefix); err != nil {
+			return nil, util.StatusWrap(err, "Authorization")
+		}
+
+		// Reprioritize the operation if it still exists after
+		// reacquiring the lock. Otherwise we retry.
+		bq.enter(bq.clock.Now())
+		if o == bq.operationsNameMap[request.OperationName] {
+			err := o.reprioritize(request.Priority)
+			bq.leave()
+			if err != nil {
+				return nil, err
+			}
+			publicAuthenticationMetadata, _ := auth.AuthenticationMetadataFromContext(ctx).GetPublicProto()
+			log.Printf("Operation %#v reprioritized to %d by %s", request.OperationName, request.Priority, protojson.Format(publicAuthenticationMetadata))
+			return &emptypb.Empty{}, nil
+		}
+		bq.leave()
+	}
+}
+
+// RetagOperation moves an operation that is currently in the QUEUED
+// stage to a different invocation. Just like ReprioritizeOperation(),
+// it is authorized using the same policy as Execute().
+//
+// To ensure clients cannot use this method to obtain a larger share of
+// workers, operations may only be moved to sibling invocations that
+// already have one or more queued or executing operations.
+func (bq *InMemoryBuildQueue) RetagOperation(ctx context.Context, request *buildqueuestate.RetagOperationRequest) (*emptypb.Empty, error) {
+	invocationKeys := make([]scheduler_invocation.Key, 0, len(request.InvocationIds))
+	for idx, id := range request.InvocationIds {
+		key, err := scheduler_invocation.NewKey(id)
+		if err != nil {
+			return nil, util.StatusWrapf(err, "Invalid invocation key at index %d", idx)
+		}
+		invocationKeys = append(invocationKeys, key)
+	}
+
+	for {
+		// Extract the instance name prefix of the size class
+		// queue to which the operation belongs.
+		bq.enter(bq.clock.Now())
+		o, ok := bq.operationsNameMap[request.OperationName]
+		if !ok {
+			bq.leave()
+			return nil, status.Errorf(codes.NotFound, "Operation %#v not found", request.OperationName)
+		}
+		instanceNamePrefix := o.task.getCurrentSizeClassQueue().getKey().platformKey.GetInstanceNamePrefix()
+		bq.leave()
+
+		// Perform authorization checks without holding any
+		// locks.
+		if err := auth.AuthorizeSingleInstanceName(ctx, bq.executeAuthorizer, instanceNamePrefix); err != nil {
+			return nil, util.StatusWrap(err, "Authorization")
+		}
+
+		// Retag the operation if it still exists after
+		// reacquiring the lock. Otherwise we retry.
+		bq.enter(bq.clock.Now())
+		if o == bq.operationsNameMap[request.OperationName] {
+			err := o.retag(invocationKeys)
+			bq.leave()
+			if err != nil {
+				return nil, err
+			}
+			publicAuthenticationMetadata, _ := auth.AuthenticationMetadataFromContext(ctx).GetPublicProto()
+			log.Printf("Operation %#v retagged to invocation %s by %s", request.OperationName, invocationKeys[len(invocationKeys)-1], protojson.Format(publicAuthenticationMetadata))
+			return &emptypb.Empty{}, nil
+		}
+		bq.leave()
+	}
+}
+
 // ListOperations returns detailed information about all of the
 // operations tracked by the InMemoryBuildQueue.
 func (bq *InMemoryBuildQueue) ListOperations(ctx context.Context, request *buildqueuestate.ListOperationsRequest) (*buildqueuestate.ListOperationsResponse, error) {
@@ -1161,6 +1576,12 @@ func (bq *InMemoryBuildQueue) AddDrain(ctx context.Context, request *buildqueues
 				w.wakeUp(scq)
 			}
 		}
+
+		// If none of the workers are able to pick up queued
+		// operations, move them elsewhere.
+		if !scq.hasUndrainedWorkers() {
+			scq.migrateQueuedOperations(bq)
+		}
 	})
 }
 
@@ -1261,13 +1682,14 @@ func (bq *InMemoryBuildQueue) getIdleSynchronizeResponse() *remoteworker.Synchro
 }
 
 // addPlatformQueue creates a new platform queue for a given platform.
-func (bq *InMemoryBuildQueue) addPlatformQueue(platformKey platform.Key, workerInvocationStickinessLimits []time.Duration, maximumQueuedBackgroundLearningOperations int, backgroundLearningOperationPriority int32) *platformQueue {
+func (bq *InMemoryBuildQueue) addPlatformQueue(platformKey platform.Key, workerInvocationStickinessLimits []time.Duration, maximumQueuedBackgroundLearningOperations int, backgroundLearningOperationPriority int32, backgroundLearningOnIdleWorkersOnly bool) *platformQueue {
 	pq := &platformQueue{
 		platformKey:                               platformKey,
 		instanceNamePatcher:                       digest.NewInstanceNamePatcher(platformKey.GetInstanceNamePrefix(), digest.EmptyInstanceName),
 		workerInvocationStickinessLimits:          workerInvocationStickinessLimits,
 		maximumQueuedBackgroundLearningOperations: maximumQueuedBackgroundLearningOperations,
 		backgroundLearningOperationPriority:       backgroundLearningOperationPriority,
+		backgroundLearningOnIdleWorkersOnly:       backgroundLearningOnIdleWorkersOnly,
 	}
 	bq.platformQueuesTrie.Set(platformKey, len(bq.platformQueues))
 	bq.platformQueues = append(bq.platformQueues, pq)
@@ -1343,6 +1765,7 @@ type platformQueue struct {
 	workerInvocationStickinessLimits          []time.Duration
 	maximumQueuedBackgroundLearningOperations int
 	backgroundLearningOperationPriority       int32
+	backgroundLearningOnIdleWorkersOnly       bool
 
 	sizeClasses     []uint32
 	sizeClassQueues []*sizeClassQueue
@@ -1381,12 +1804,14 @@ func (pq *platformQueue) addSizeClassQueue(bq *InMemoryBuildQueue, sizeClass uin
 		inFlightDeduplicationsSameInvocation:  inMemoryBuildQueueInFlightDeduplicationsTotal.WithLabelValues(instanceNamePrefix, platformStr, sizeClassStr, "SameInvocation"),
 		inFlightDeduplicationsOtherInvocation: inMemoryBuildQueueInFlightDeduplicationsTotal.WithLabelValues(instanceNamePrefix, platformStr, sizeClassStr, "OtherInvocation"),
 		inFlightDeduplicationsNew:             inMemoryBuildQueueInFlightDeduplicationsTotal.WithLabelValues(instanceNamePrefix, platformStr, sizeClassStr, "New"),
+		inFlightDeduplicationsSkipCacheLookup: inMemoryBuildQueueInFlightDeduplicationsTotal.WithLabelValues(instanceNamePrefix, platformStr, sizeClassStr, "SkipCacheLookup"),
 
 		tasksScheduledWorker:          newTasksScheduledCounterVec(tasksScheduledTotal, "Worker"),
 		tasksScheduledQueue:           newTasksScheduledCounterVec(tasksScheduledTotal, "Queue"),
 		tasksQueuedDurationSeconds:    inMemoryBuildQueueTasksQueuedDurationSeconds.WithLabelValues(instanceNamePrefix, platformStr, sizeClassStr),
 		tasksExecutingDurationSeconds: inMemoryBuildQueueTasksExecutingDurationSeconds.MustCurryWith(platformLabels),
 		tasksExecutingRetries:         inMemoryBuildQueueTasksExecutingRetries.MustCurryWith(platformLabels),
+		tasksMigratedTotal:            inMemoryBuildQueueTasksMigratedTotal.WithLabelValues(instanceNamePrefix, platformStr, sizeClassStr),
 		tasksCompletedDurationSeconds: inMemoryBuildQueueTasksCompletedDurationSeconds.WithLabelValues(instanceNamePrefix, platformStr, sizeClassStr),
 
 		workersCreatedTotal:          inMemoryBuildQueueWorkersCreatedTotal.WithLabelValues(instanceNamePrefix, platformStr, sizeClassStr),
@@ -1468,6 +1893,7 @@ type sizeClassQueue struct {
 	inFlightDeduplicationsSameInvocation  prometheus.Counter
 	inFlightDeduplicationsOtherInvocation prometheus.Counter
 	inFlightDeduplicationsNew             prometheus.Counter
+	inFlightDeduplicationsSkipCacheLookup prometheus.Counter
 
 	invocationsMetrics []invocationsMetrics
 
@@ -1476,6 +1902,7 @@ type sizeClassQueue struct {
 	tasksQueuedDurationSeconds    prometheus.Observer
 	tasksExecutingDurationSeconds prometheus.ObserverVec
 	tasksExecutingRetries         prometheus.ObserverVec
+	tasksMigratedTotal            prometheus.Counter
 	tasksCompletedDurationSeconds prometheus.Observer
 
 	workersCreatedTotal          prometheus.Counter
@@ -1498,6 +1925,7 @@ func (scq *sizeClassQueue) getKey() sizeClassKey {
 // queue and all associated queued operations to be removed from the
 // InMemoryBuildQueue.
 func (scq *sizeClassQueue) remove(bq *InMemoryBuildQueue) {
+	scq.migrateQueuedOperations(bq)
 	scq.rootInvocation.cancelAllQueuedOperations(
 		bq,
 		status.New(
@@ -1529,6 +1957,138 @@ func (scq *sizeClassQueue) remove(bq *InMemoryBuildQueue) {
 	}
 }
 
+// hasUndrainedWorkers returns whether the size class queue has one or
+// more workers that are not drained, meaning they may pick up queued
+// operations.
+func (scq *sizeClassQueue) hasUndrainedWorkers() bool {
+	for workerKey, w := range scq.workers {
+		if !w.isDrained(scq, workerKey.getWorkerID()) {
+			return true
+		}
+	}
+	return false
+}
+
+// getInFlightDeduplicationKey returns the key under which a task for
+// an action is stored in the in-flight deduplication map. This is the
+// digest of the action with all platform properties removed that are
+// excluded from Action Cache keys.
+func (bq *InMemoryBuildQueue) getInFlightDeduplicationKey(actionDigest digest.Digest, action *remoteexecution.Action) (digest.Digest, error) {
+	var properties []*remoteexecution.Platform_Property
+	for _, property := range action.Platform.GetProperties() {
+		if !slices.Contains(bq.configuration.CacheKeyExcludedPlatformPropertyNames, property.Name) {
+			properties = append(properties, property)
+		}
+	}
+	if len(properties) == len(action.Platform.GetProperties()) {
+		return actionDigest, nil
+	}
+
+	reducedAction := proto.Clone(action).(*remoteexecution.Action)
+	reducedAction.Platform = nil
+	if len(properties) > 0 {
+		reducedAction.Platform = &remoteexecution.Platform{Properties: properties}
+	}
+	data, err := proto.Marshal(reducedAction)
+	if err != nil {
+		return digest.BadDigest, err
+	}
+	digestGenerator := actionDigest.GetDigestFunction().NewGenerator(int64(len(data)))
+	if _, err := digestGenerator.Write(data); err != nil {
+		return digest.BadDigest, err
+	}
+	return digestGenerator.Sum(), nil
+}
+
+// getMigrationKey returns a key of a platform queue that can be used
+// to determine whether queued operations may be migrated between
+// platform queues. The key contains all platform properties, except
+// the ones that are configured to be ignored.
+func (bq *InMemoryBuildQueue) getMigrationKey(platformKey platform.Key) platform.Key {
+	var properties []*remoteexecution.Platform_Property
+	for _, property := range platformKey.GetPlatformQueueName().Platform.Properties {
+		ignored := false
+		for _, name := range bq.configuration.MigrationIgnoredPlatformPropertyNames {
+			if property.Name == name {
+				ignored = true
+				break
+			}
+		}
+		if !ignored {
+			properties = append(properties, property)
+		}
+	}
+	migrationKey, err := platform.NewKey(platformKey.GetInstanceNamePrefix(), &remoteexecution.Platform{Properties: properties})
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create key of previously validated platform: %s", err))
+	}
+	return migrationKey
+}
+
+// getMigrationTarget returns a size class queue to which queued
+// operations of a given size class queue may be migrated. The largest
+// size class queue of an equivalent platform queue is returned, as
+// size classes of both platform queues may not be comparable. If no
+// platform queue is capable of running the operations, nil is
+// returned.
+func (bq *InMemoryBuildQueue) getMigrationTarget(scq *sizeClassQueue) *sizeClassQueue {
+	migrationKey := bq.getMigrationKey(scq.platformQueue.platformKey)
+	for _, pq := range bq.platformQueues {
+		if pq != scq.platformQueue && bq.getMigrationKey(pq.platformKey) == migrationKey {
+			if targetSCQ := pq.sizeClassQueues[len(pq.sizeClassQueues)-1]; targetSCQ.hasUndrainedWorkers() {
+				return targetSCQ
+			}
+		}
+	}
+	return nil
+}
+
+// migrateQueuedOperations moves all queued operations in the size class
+// queue to an equivalent platform queue, if migration of queued
+// operations is enabled. This is done when the size class queue is
+// about to be removed, or when all of its workers are drained.
+//
+// Operations created to perform background learning are not migrated,
+// as the results of these operations are only meaningful for the size
+// class queues for which they were created.
+func (scq *sizeClassQueue) migrateQueuedOperations(bq *InMemoryBuildQueue) {
+	if !bq.configuration.MigrateQueuedOperations || !scq.rootInvocation.isQueued() {
+		return
+	}
+	targetSCQ := bq.getMigrationTarget(scq)
+	if targetSCQ == nil {
+		return
+	}
+
+	// Collect all queued tasks prior to migrating them, as
+	// migration mutates the heaps of queued invocations. Tasks
+	// may be part of multiple invocations due to in-flight
+	// deduplication.
+	var tasks []*task
+	seenTasks := map[*task]struct{}{}
+	var collectQueuedTasks func(i *invocation)
+	collectQueuedTasks = func(i *invocation) {
+		if i.isBackgroundLearning() {
+			return
+		}
+		for _, o := range i.queuedOperations {
+			if _, ok := seenTasks[o.task]; !ok {
+				seenTasks[o.task] = struct{}{}
+				tasks = append(tasks, o.task)
+			}
+		}
+		for _, iChild := range i.queuedChildren {
+			collectQueuedTasks(iChild)
+		}
+	}
+	collectQueuedTasks(&scq.rootInvocation)
+
+	for _, t := range tasks {
+		t.migrate(bq, targetSCQ)
+		scq.tasksMigratedTotal.Inc()
+	}
+}
+
 // removeStaleWorker is invoked when Synchronize() isn't being invoked
 // by a worker quickly enough. It causes the worker to be removed from
 // the InMemoryBuildQueue.
@@ -1815,6 +2375,13 @@ func (i *invocation) isQueued() bool {
 	return i.queuedOperations.Len() > 0 || i.queuedChildren.Len() > 0
 }
 
+// isBackgroundLearning returns whether an invocation is the one in
+// which operations are placed that are created to perform background
+// learning.
+func (i *invocation) isBackgroundLearning() bool {
+	return len(i.invocationKeys) == 1 && i.invocationKeys[0] == scheduler_invocation.BackgroundLearningKeys[0]
+}
+
 // isActive returns whether an invocation has one or more queued or
 // executing operations. These are generally the ones that users of the
 // BuildQueueState service want to view.
@@ -2121,10 +2688,25 @@ func (o *operation) waitExecution(bq *InMemoryBuildQueue, out remoteexecution.Ex
 	for {
 		// Construct the longrunningpb.Operation that needs to be
 		// sent back to the client.
-		metadata, err := anypb.New(&remoteexecution.ExecuteOperationMetadata{
-			Stage:        t.getStage(),
-			ActionDigest: t.desiredState.ActionDigest,
-		})
+		executeOperationMetadata := &remoteexecution.ExecuteOperationMetadata{
+			Stage:            t.getStage(),
+			ActionDigest:     t.desiredState.ActionDigest,
+			StdoutStreamName: t.stdoutStreamName,
+			StderrStreamName: t.stderrStreamName,
+		}
+		if bq.configuration.ReportExpectedDurationToClients && t.executeResponse == nil {
+			predictedExecution, err := anypb.New(&buildqueuestate.PredictedExecution{
+				ExpectedDuration: durationpb.New(t.expectedDuration),
+				Timeout:          t.desiredState.Action.Timeout,
+			})
+			if err != nil {
+				return util.StatusWrap(err, "Failed to marshal predicted execution")
+			}
+			executeOperationMetadata.PartialExecutionMetadata = &remoteexecution.ExecutedActionMetadata{
+				AuxiliaryMetadata: []*anypb.Any{predictedExecution},
+			}
+		}
+		metadata, err := anypb.New(executeOperationMetadata)
 		if err != nil {
 			return util.StatusWrap(err, "Failed to marshal execute operation metadata")
 		}
@@ -2199,6 +2781,63 @@ func (o *operation) enqueue() {
 	}
 }
 
+// reprioritize changes the priority of a queued operation, moving it
+// to its new position in the queued operations heap of its invocation.
+func (o *operation) reprioritize(priority int32) error {
+	if o.queueIndex < 0 {
+		return status.Error(codes.FailedPrecondition, "Only operations in the QUEUED stage can be reprioritized")
+	}
+	if firstPriority := o.invocation.queuedOperations[0].priority; priority < firstPriority {
+		return status.Errorf(codes.PermissionDenied, "Operation cannot be given a priority higher than %d, as that would cause it to overtake other invocations", firstPriority)
+	}
+	o.removeQueuedFromInvocation()
+	o.priority = priority
+	o.enqueue()
+	return nil
+}
+
+// retag moves a queued operation to a sibling invocation that already
+// has one or more queued or executing operations.
+func (o *operation) retag(invocationKeys []scheduler_invocation.Key) error {
+	if o.queueIndex < 0 {
+		return status.Error(codes.FailedPrecondition, "Only operations in the QUEUED stage can be retagged")
+	}
+	oldI := o.invocation
+	depth := len(oldI.invocationKeys)
+	if len(invocationKeys) != depth || depth == 0 {
+		return status.Errorf(codes.InvalidArgument, "Operation belongs to an invocation with %d IDs, while %d IDs were provided", depth, len(invocationKeys))
+	}
+	for idx, invocationKey := range invocationKeys[:depth-1] {
+		if invocationKey != oldI.invocationKeys[idx] {
+			return status.Errorf(codes.PermissionDenied, "Invocation ID at index %d differs from the operation's current invocation, while only the last invocation ID may be changed", idx)
+		}
+	}
+	newI, ok := oldI.parent.children[invocationKeys[depth-1]]
+	if newI == oldI {
+		return nil
+	}
+	if !ok || !newI.isActive() {
+		return status.Error(codes.PermissionDenied, "Operations can only be moved to invocations that already have one or more queued or executing operations")
+	}
+	if oldI.isBackgroundLearning() || newI.isBackgroundLearning() {
+		return status.Error(codes.PermissionDenied, "Operations cannot be moved into or out of the background learning invocation")
+	}
+	t := o.task
+	if _, ok := t.operations[newI]; ok {
+		return status.Error(codes.AlreadyExists, "The task associated with the operation is already part of the target invocation")
+	}
+
+	o.removeQueuedFromInvocation()
+	delete(t.operations, oldI)
+	o.invocation = newI
+	t.operations[newI] = o
+	o.enqueue()
+	for oldI.removeIfEmpty() {
+		oldI = oldI.parent
+	}
+	return nil
+}
+
 func (o *operation) remove(bq *InMemoryBuildQueue) {
 	delete(bq.operationsNameMap, o.name)
 
@@ -2285,6 +2924,12 @@ type task struct {
 	actionDigest digest.Digest
 	desiredState remoteworker.DesiredState_Executing
 
+	// The key under which the task is stored in the in-flight
+	// deduplication map. This is equal to the action digest,
+	// unless the action has platform properties that are
+	// excluded from Action Cache keys.
+	inFlightDeduplicationKey digest.Digest
+
 	// The name of the target that triggered this operation. This
 	// field is not strictly necessary to implement the BuildQueue
 	// and OperationQueueServer interfaces. It needs to be present
@@ -2306,8 +2951,18 @@ type task struct {
 	initialSizeClassLearner initialsizeclass.Learner
 	mayExistWithoutWaiters  bool
 
+	// Names of LogStreams containing the output of the action while
+	// it is running, as reported by the worker.
+	stdoutStreamName string
+	stderrStreamName string
+
 	executeResponse   *remoteexecution.ExecuteResponse
 	stageChangeWakeup chan struct{}
+
+	// If the action is permitted to submit sub-actions, the
+	// authentication metadata of the client that submitted it.
+	// Sub-actions are authorized using this metadata.
+	nestedExecutionAuthenticationMetadata *auth.AuthenticationMetadata
 }
 
 // newOperation attaches a new operation to a task. This function must
@@ -2340,6 +2995,17 @@ func (t *task) reportNonFinalStageChange() {
 	t.stageChangeWakeup = make(chan struct{})
 }
 
+// setStreamNames stores the names of the LogStreams to which the
+// worker writes the output of the action. Clients are notified if
+// these names change, so that they can start reading from them.
+func (t *task) setStreamNames(stdoutStreamName, stderrStreamName string) {
+	if (stdoutStreamName != "" || stderrStreamName != "") && (stdoutStreamName != t.stdoutStreamName || stderrStreamName != t.stderrStreamName) {
+		t.stdoutStreamName = stdoutStreamName
+		t.stderrStreamName = stderrStreamName
+		t.reportNonFinalStageChange()
+	}
+}
+
 // schedule a task. This function will first attempt to directly assign
 // a task to an idle worker that is synchronizing against the scheduler.
 // When no such worker exists, it will queue the operation, so that a
@@ -2396,6 +3062,30 @@ func (t *task) schedule(bq *InMemoryBuildQueue) {
 	}
 }
 
+// migrate a queued task to a size class queue belonging to another
+// platform queue. As the task's initial size class learner was created
+// for the original platform queue, learning is abandoned. The task is
+// run without any further retries on other size classes.
+func (t *task) migrate(bq *InMemoryBuildQueue, targetSCQ *sizeClassQueue) {
+	t.registerQueuedStageFinished(bq)
+	t.initialSizeClassLearner.Abandoned()
+	t.initialSizeClassLearner = initialsizeclass.NewLargestSizeClassLearner()
+
+	operations := t.operations
+	t.operations = make(map[*invocation]*operation, len(operations))
+	for oldI, o := range operations {
+		o.removeQueuedFromInvocation()
+		i := targetSCQ.getOrCreateInvocation(bq, oldI.invocationKeys)
+		for oldI.removeIfEmpty() {
+			oldI = oldI.parent
+		}
+		t.operations[i] = o
+		o.invocation = i
+	}
+	t.schedule(bq)
+	t.reportNonFinalStageChange()
+}
+
 // getStage returns whether the task is in the queued, executing or
 // completed stage.
 func (t *task) getStage() remoteexecution.ExecutionStage_Value {
@@ -2551,7 +3241,13 @@ func (t *task) complete(bq *InMemoryBuildQueue, executeResponse *remoteexecution
 		// after completion. This reduces memory usage
 		// significantly. Keep the Action digest, so that
 		// there's still a way to figure out what the task was.
-		delete(bq.inFlightDeduplicationMap, t.actionDigest)
+		if bq.inFlightDeduplicationMap[t.inFlightDeduplicationKey] == t {
+			delete(bq.inFlightDeduplicationMap, t.inFlightDeduplicationKey)
+		}
+		if nestedExecutionToken := t.desiredState.NestedExecutionToken; nestedExecutionToken != "" {
+			delete(bq.nestedExecutionTokens, nestedExecutionToken)
+			t.nestedExecutionAuthenticationMetadata = nil
+		}
 		t.executeResponse = executeResponse
 		t.desiredState.Action = nil
 		close(t.stageChangeWakeup)
@@ -2786,10 +3482,25 @@ func (w *worker) assignNextQueuedTask(bq *InMemoryBuildQueue, scq *sizeClassQueu
 			// last executed task. This reduces the startup
 			// overhead of actions that leave state behind
 			// on the worker.
+			//
+			// If background learning operations may only
+			// run on idle workers, the invocation
+			// containing them is only picked if no other
+			// invocations have queued operations.
 			iBest := i.queuedChildren[0]
+			deferBackgroundLearning := i == &scq.rootInvocation && pq.backgroundLearningOnIdleWorkersOnly && len(i.queuedChildren) > 1
+			if deferBackgroundLearning && iBest.isBackgroundLearning() {
+				// The next most preferable invocation
+				// is one of the children of the root of
+				// the heap.
+				iBest = i.queuedChildren[1]
+				if len(i.queuedChildren) > 2 && i.queuedChildren.Less(2, 1) {
+					iBest = i.queuedChildren[2]
+				}
+			}
 			if len(lastInvocationKeys) > 0 && len(workerInvocationStickinessLimits) > 0 {
 				iSticky := i.children[lastInvocationKeys[0]]
-				if iSticky.isQueued() && iSticky.isPreferred(iBest, w.stickinessStartingTimes[0].Add(workerInvocationStickinessLimits[0]).After(bq.now)) {
+				if iSticky.isQueued() && !(deferBackgroundLearning && iSticky.isBackgroundLearning()) && iSticky.isPreferred(iBest, w.stickinessStartingTimes[0].Add(workerInvocationStickinessLimits[0]).After(bq.now)) {
 					iBest = iSticky
 				}
 				if iBest == iSticky {
@@ -2877,7 +3588,7 @@ func (w *worker) getExecutingSynchronizeResponse(bq *InMemoryBuildQueue) *remote
 // provided, this function either blocks until work is available or
 // returns immediately. When returning immediately, it instructs the
 // worker to go idle.
-func (w *worker) getNextTask(ctx context.Context, bq *InMemoryBuildQueue, scq *sizeClassQueue, workerID map[string]string, preferBeingIdle bool) (*remoteworker.SynchronizeResponse, error) {
+func (w *worker) getNextTask(ctx context.Context, bq *InMemoryBuildQueue, scq *sizeClassQueue, workerID map[string]string, preferBeingIdle bool, getIdleSynchronizationInterval func() time.Duration) (*remoteworker.SynchronizeResponse, error) {
 	if preferBeingIdle {
 		// The worker wants to terminate or is experiencing some
 		// issues. Explicitly instruct the worker to go idle, so
@@ -2899,7 +3610,7 @@ func (w *worker) getNextTask(ctx context.Context, bq *InMemoryBuildQueue, scq *s
 		return bq.getIdleSynchronizeResponse(), nil
 	}
 
-	timeoutTimer, timeoutChannel := bq.clock.NewTimer(bq.configuration.GetIdleWorkerSynchronizationInterval())
+	timeoutTimer, timeoutChannel := bq.clock.NewTimer(getIdleSynchronizationInterval())
 	defer timeoutTimer.Stop()
 
 	for {
@@ -2983,7 +3694,7 @@ func (w *worker) getNextTask(ctx context.Context, bq *InMemoryBuildQueue, scq *s
 // instructs the worker to run the task it should be running. When the
 // worker has no task assigned to it, it attempts to request a task from
 // the queue.
-func (w *worker) getCurrentOrNextTask(ctx context.Context, bq *InMemoryBuildQueue, scq *sizeClassQueue, workerID map[string]string, preferBeingIdle bool) (*remoteworker.SynchronizeResponse, error) {
+func (w *worker) getCurrentOrNextTask(ctx context.Context, bq *InMemoryBuildQueue, scq *sizeClassQueue, workerID map[string]string, preferBeingIdle bool, getIdleSynchronizationInterval func() time.Duration) (*remoteworker.SynchronizeResponse, error) {
 	if t := w.currentTask; t != nil {
 		if t.retryCount < bq.configuration.WorkerTaskRetryCount {
 			t.retryCount++
@@ -3004,7 +3715,7 @@ func (w *worker) getCurrentOrNextTask(ctx context.Context, bq *InMemoryBuildQueu
 				newWorkerKey(workerID)).Proto(),
 		}, false)
 	}
-	return w.getNextTask(ctx, bq, scq, workerID, preferBeingIdle)
+	return w.getNextTask(ctx, bq, scq, workerID, preferBeingIdle, getIdleSynchronizationInterval)
 }
 
 // isRunningCorrectTask determines whether the worker is actually
@@ -3020,10 +3731,11 @@ func (w *worker) isRunningCorrectTask(actionDigest *remoteexecution.Digest) bool
 
 // updateTask processes execution status updates from the worker that do
 // not equal the 'completed' state.
-func (w *worker) updateTask(bq *InMemoryBuildQueue, scq *sizeClassQueue, workerID map[string]string, actionDigest *remoteexecution.Digest, preferBeingIdle bool) (*remoteworker.SynchronizeResponse, error) {
-	if !w.isRunningCorrectTask(actionDigest) {
-		return w.getCurrentOrNextTask(nil, bq, scq, workerID, preferBeingIdle)
+func (w *worker) updateTask(bq *InMemoryBuildQueue, scq *sizeClassQueue, workerID map[string]string, executing *remoteworker.CurrentState_Executing, preferBeingIdle bool) (*remoteworker.SynchronizeResponse, error) {
+	if !w.isRunningCorrectTask(executing.ActionDigest) {
+		return w.getCurrentOrNextTask(nil, bq, scq, workerID, preferBeingIdle, nil)
 	}
+	w.currentTask.setStreamNames(executing.StdoutStreamName, executing.StderrStreamName)
 	// The worker is doing fine. Allow it to continue with what it's
 	// doing right now.
 	return &remoteworker.SynchronizeResponse{
@@ -3035,12 +3747,12 @@ func (w *worker) updateTask(bq *InMemoryBuildQueue, scq *sizeClassQueue, workerI
 // equal the 'completed' state. It causes the execute response to be
 // preserved and communicated to clients that are waiting on the
 // completion of the task.
-func (w *worker) completeTask(ctx context.Context, bq *InMemoryBuildQueue, scq *sizeClassQueue, workerID map[string]string, actionDigest *remoteexecution.Digest, executeResponse *remoteexecution.ExecuteResponse, preferBeingIdle bool) (*remoteworker.SynchronizeResponse, error) {
+func (w *worker) completeTask(ctx context.Context, bq *InMemoryBuildQueue, scq *sizeClassQueue, workerID map[string]string, actionDigest *remoteexecution.Digest, executeResponse *remoteexecution.ExecuteResponse, preferBeingIdle bool, getIdleSynchronizationInterval func() time.Duration) (*remoteworker.SynchronizeResponse, error) {
 	if !w.isRunningCorrectTask(actionDigest) {
-		return w.getCurrentOrNextTask(ctx, bq, scq, workerID, preferBeingIdle)
+		return w.getCurrentOrNextTask(ctx, bq, scq, workerID, preferBeingIdle, getIdleSynchronizationInterval)
 	}
 	w.currentTask.complete(bq, executeResponse, true)
-	return w.getNextTask(ctx, bq, scq, workerID, preferBeingIdle)
+	return w.getNextTask(ctx, bq, scq, workerID, preferBeingIdle, getIdleSynchronizationInterval)
 }
 
 type idleSynchronizingWorker struct {
@@ -0,0 +1,617 @@
+package scheduler_test
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler"
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/initialsizeclass"
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/invocation"
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/platform"
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/routing"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+)
+
+const (
+	simulationInvocationsCount = 5
+	simulationWorkersCount     = 8
+
+	// The maximum number of operations that clients may have in
+	// flight at any given time. This prevents the queue from
+	// growing without bounds in case clients outpace the workers.
+	simulationMaximumOperationsInFlight = 100
+
+	// Workers that are alive synchronize at least this often, which
+	// is well within WorkerWithNoSynchronizationsTimeout.
+	simulationWorkerHeartbeatInterval = 30 * time.Second
+
+	// The maximum amount of time by which the clock is advanced in
+	// a single step. It must be small enough that heartbeats are
+	// never late.
+	simulationMaximumClockStep = 5 * time.Second
+)
+
+// simulatedClock is an implementation of clock.Clock whose time only
+// progresses when it is advanced by the simulation explicitly. Timers
+// fire as soon as the time reaches their expiration time.
+type simulatedClock struct {
+	lock   sync.Mutex
+	now    time.Time
+	timers map[*simulatedTimer]struct{}
+}
+
+func newSimulatedClock(now time.Time) *simulatedClock {
+	return &simulatedClock{
+		now:    now,
+		timers: map[*simulatedTimer]struct{}{},
+	}
+}
+
+func (c *simulatedClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *simulatedClock) NewContextWithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	// InMemoryBuildQueue does not create contexts with timeouts.
+	// Never let them expire, as opposed to expiring in real time.
+	return context.WithCancel(parent)
+}
+
+func (c *simulatedClock) NewTimer(d time.Duration) (clock.Timer, <-chan time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	t := &simulatedTimer{
+		clock:      c,
+		expiration: c.now.Add(d),
+		channel:    make(chan time.Time, 1),
+	}
+	c.timers[t] = struct{}{}
+	return t, t.channel
+}
+
+func (c *simulatedClock) advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+	for t := range c.timers {
+		if !t.expiration.After(c.now) {
+			t.channel <- t.expiration
+			delete(c.timers, t)
+		}
+	}
+}
+
+type simulatedTimer struct {
+	clock      *simulatedClock
+	expiration time.Time
+	channel    chan time.Time
+}
+
+func (t *simulatedTimer) Stop() bool {
+	c := t.clock
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.timers[t]; !ok {
+		return false
+	}
+	delete(c.timers, t)
+	return true
+}
+
+// simulatedOperation is an operation that is created by calling
+// Execute() against the build queue. It implements
+// Execution_ExecuteServer, so that Execute() can be called without
+// going through gRPC. It also contains the state of the operation as
+// tracked by the simulation's model.
+type simulatedOperation struct {
+	grpc.ServerStream
+
+	ctx          context.Context
+	invocation   int
+	actionDigest *remoteexecution.Digest
+	retryCount   int
+
+	// State that is only accessed by the goroutine calling
+	// Execute() until the operation is done.
+	queued     chan struct{}
+	sentFirst  bool
+	lastUpdate *longrunningpb.Operation
+	done       chan struct{}
+	err        error
+
+	// The timestamp at which the operation was queued, and the
+	// outcome that the model expects once the operation completes.
+	queuedTimestamp time.Time
+	expectedCode    codes.Code
+	completed       bool
+}
+
+func (o *simulatedOperation) Context() context.Context {
+	return o.ctx
+}
+
+func (o *simulatedOperation) Send(operation *longrunningpb.Operation) error {
+	if !o.sentFirst {
+		o.sentFirst = true
+		close(o.queued)
+	}
+	o.lastUpdate = operation
+	return nil
+}
+
+// simulatedWorker is a worker that synchronizes against the build
+// queue. When operation is nil, the worker is idle.
+type simulatedWorker struct {
+	id                  map[string]string
+	lastSynchronization time.Time
+	operation           *simulatedOperation
+}
+
+// crashedWorker is a worker that stopped synchronizing while executing
+// an operation. The build queue should fail the operation as soon as
+// the worker is considered to be stale.
+type crashedWorker struct {
+	removalTime time.Time
+	operation   *simulatedOperation
+}
+
+// buildQueueSimulation drives an InMemoryBuildQueue with scripted
+// clients and workers, using a clock that is fully under control of the
+// simulation. Every decision is made by a seeded random number
+// generator, making failures reproducible.
+//
+// The simulation maintains a model of which operations are queued and
+// executing, which it uses to validate that the build queue:
+//
+//   - Always assigns queued work to idle workers.
+//   - Schedules invocations fairly, by picking an operation from the
+//     invocation that has the fewest executing operations.
+//   - Executes operations within an invocation in the order in which
+//     they were queued.
+//   - Hands out the same task to a worker that restarts, up to the
+//     configured number of retries.
+//   - Fails operations on workers that stop synchronizing, once
+//     WorkerWithNoSynchronizationsTimeout has passed.
+//   - Reports the expected outcome of every operation to clients.
+type buildQueueSimulation struct {
+	t             *testing.T
+	ctx           context.Context
+	random        *rand.Rand
+	clock         *simulatedClock
+	configuration *scheduler.InMemoryBuildQueueConfiguration
+	buildQueue    *scheduler.InMemoryBuildQueue
+
+	workers        []*simulatedWorker
+	nextWorkerID   int
+	crashedWorkers []crashedWorker
+
+	operations             []*simulatedOperation
+	operationsByHash       map[string]*simulatedOperation
+	nextActionID           uint64
+	operationsInFlight     int
+	queuedOperations       [simulationInvocationsCount][]*simulatedOperation
+	executingWorkersCounts [simulationInvocationsCount]int
+}
+
+func newBuildQueueSimulation(t *testing.T, seed int64) *buildQueueSimulation {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	contentAddressableStorage.EXPECT().Get(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, blobDigest digest.Digest) buffer.Buffer {
+			return buffer.NewProtoBufferFromProto(&remoteexecution.Action{
+				CommandDigest: &remoteexecution.Digest{
+					Hash:      "ec8a7a2a7e0aa2e64e4ebaa0e3ff8c3a5d9dbbd6e1a3da4c6ea1c1f5d25c8b66",
+					SizeBytes: 456,
+				},
+			}, buffer.UserProvided)
+		}).
+		AnyTimes()
+
+	// Reduce the number of updates sent to clients while
+	// operations are queued, as these are not of interest.
+	configuration := buildQueueConfigurationForTesting
+	configuration.ExecutionUpdateInterval = time.Hour
+
+	s := &buildQueueSimulation{
+		t:                t,
+		ctx:              ctx,
+		random:           rand.New(rand.NewSource(seed)),
+		clock:            newSimulatedClock(time.Unix(1000, 0)),
+		configuration:    &configuration,
+		operationsByHash: map[string]*simulatedOperation{},
+	}
+	s.buildQueue = scheduler.NewInMemoryBuildQueue(
+		contentAddressableStorage,
+		s.clock,
+		uuid.NewRandom,
+		s.configuration,
+		10000,
+		routing.NewSimpleActionRouter(
+			platform.NewStaticKeyExtractor(platformForTesting),
+			[]invocation.KeyExtractor{invocation.ToolInvocationIDKeyExtractor},
+			initialsizeclass.NewFallbackAnalyzer(initialsizeclass.NewActionTimeoutExtractor(time.Hour, time.Hour))),
+		allowAllAuthorizer,
+		allowAllAuthorizer,
+		allowAllAuthorizer,
+		allowAllAuthorizer,
+		denyAllAuthorizer)
+
+	// Let all workers announce themselves, so that the platform
+	// queue exists before any operations are submitted.
+	for i := 0; i < simulationWorkersCount; i++ {
+		w := s.newWorker()
+		s.synchronizeIdle(w, true)
+	}
+	return s
+}
+
+func (s *buildQueueSimulation) newWorker() *simulatedWorker {
+	w := &simulatedWorker{
+		id: map[string]string{
+			"hostname": "worker",
+			"thread":   strconv.FormatInt(int64(s.nextWorkerID), 10),
+		},
+	}
+	s.nextWorkerID++
+	s.workers = append(s.workers, w)
+	return w
+}
+
+// synchronize a worker against the build queue. The context that is
+// provided is canceled, meaning that the build queue returns an error
+// if it attempts to block. None of the synchronizations performed by
+// the simulation should block.
+func (s *buildQueueSimulation) synchronize(w *simulatedWorker, currentState *remoteworker.CurrentState, preferBeingIdle bool) *remoteworker.SynchronizeResponse {
+	s.expireCrashedWorkers()
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	cancel()
+	response, err := s.buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+		WorkerId:           w.id,
+		InstanceNamePrefix: "main",
+		Platform:           platformForTesting,
+		CurrentState:       currentState,
+		PreferBeingIdle:    preferBeingIdle,
+	})
+	require.NoError(s.t, err)
+	w.lastSynchronization = s.clock.Now()
+	return response
+}
+
+func (s *buildQueueSimulation) synchronizeIdle(w *simulatedWorker, preferBeingIdle bool) *remoteworker.SynchronizeResponse {
+	return s.synchronize(w, &remoteworker.CurrentState{
+		WorkerState: &remoteworker.CurrentState_Idle{
+			Idle: &emptypb.Empty{},
+		},
+	}, preferBeingIdle)
+}
+
+func (s *buildQueueSimulation) synchronizeExecuting(w *simulatedWorker, executing *remoteworker.CurrentState_Executing, preferBeingIdle bool) *remoteworker.SynchronizeResponse {
+	executing.ActionDigest = w.operation.actionDigest
+	return s.synchronize(w, &remoteworker.CurrentState{
+		WorkerState: &remoteworker.CurrentState_Executing_{
+			Executing: executing,
+		},
+	}, preferBeingIdle)
+}
+
+// expireCrashedWorkers updates the model to account for workers that
+// the build queue considers to be stale. Their operations should have
+// failed.
+func (s *buildQueueSimulation) expireCrashedWorkers() {
+	now := s.clock.Now()
+	remaining := s.crashedWorkers[:0]
+	for _, cw := range s.crashedWorkers {
+		if cw.removalTime.After(now) {
+			remaining = append(remaining, cw)
+		} else {
+			s.completeOperation(cw.operation, codes.Unavailable)
+		}
+	}
+	s.crashedWorkers = remaining
+}
+
+func (s *buildQueueSimulation) completeOperation(o *simulatedOperation, code codes.Code) {
+	require.False(s.t, o.completed, "Operation completed twice")
+	o.completed = true
+	o.expectedCode = code
+	s.executingWorkersCounts[o.invocation]--
+	s.operationsInFlight--
+}
+
+func (s *buildQueueSimulation) hasQueuedOperations() bool {
+	for _, queued := range s.queuedOperations {
+		if len(queued) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// submitOperation lets a client call Execute() for a new action. It
+// waits for the first update to be returned, so that the operation is
+// guaranteed to be queued before the simulation continues.
+func (s *buildQueueSimulation) submitOperation() {
+	invocationIndex := s.random.Intn(simulationInvocationsCount)
+	requestMetadataBin, err := proto.Marshal(&remoteexecution.RequestMetadata{
+		ToolInvocationId: fmt.Sprintf("invocation%d", invocationIndex),
+	})
+	require.NoError(s.t, err)
+
+	o := &simulatedOperation{
+		ctx: metadata.NewIncomingContext(
+			s.ctx,
+			metadata.Pairs("build.bazel.remote.execution.v2.requestmetadata-bin", string(requestMetadataBin))),
+		invocation: invocationIndex,
+		actionDigest: &remoteexecution.Digest{
+			Hash:      fmt.Sprintf("%064x", s.nextActionID),
+			SizeBytes: 123,
+		},
+		queued:          make(chan struct{}),
+		done:            make(chan struct{}),
+		queuedTimestamp: s.clock.Now(),
+	}
+	s.nextActionID++
+	go func() {
+		o.err = s.buildQueue.Execute(&remoteexecution.ExecuteRequest{
+			InstanceName: "main",
+			ActionDigest: o.actionDigest,
+		}, o)
+		close(o.done)
+	}()
+	select {
+	case <-o.queued:
+	case <-o.done:
+		require.NoError(s.t, o.err)
+		require.FailNow(s.t, "Execute() completed without sending any updates")
+	}
+
+	s.operations = append(s.operations, o)
+	s.operationsByHash[o.actionDigest.Hash] = o
+	s.operationsInFlight++
+	s.queuedOperations[invocationIndex] = append(s.queuedOperations[invocationIndex], o)
+}
+
+// startOperation lets an idle worker request work while operations are
+// queued. The operation that is returned must be picked fairly.
+func (s *buildQueueSimulation) startOperation(w *simulatedWorker) {
+	response := s.synchronizeIdle(w, false)
+	executing := response.DesiredState.GetExecuting()
+	require.NotNil(s.t, executing, "Idle worker did not receive work, even though operations are queued")
+	o, ok := s.operationsByHash[executing.ActionDigest.GetHash()]
+	require.True(s.t, ok, "Worker received unknown action %s", executing.ActionDigest.GetHash())
+
+	// The invocation of the operation must have the fewest
+	// executing operations of all invocations with queued
+	// operations.
+	queued := s.queuedOperations[o.invocation]
+	for i, otherQueued := range s.queuedOperations {
+		if len(otherQueued) > 0 {
+			require.LessOrEqual(
+				s.t,
+				s.executingWorkersCounts[o.invocation],
+				s.executingWorkersCounts[i],
+				"Started operation of invocation %d, even though invocation %d has fewer executing operations",
+				o.invocation,
+				i)
+		}
+	}
+
+	// Within an invocation, operations must be started in the
+	// order in which they were queued. Operations that were queued
+	// at the same time may be started in any order.
+	index := -1
+	for i, queuedOperation := range queued {
+		if queuedOperation == o {
+			index = i
+			break
+		}
+	}
+	require.NotEqual(s.t, -1, index, "Worker received an operation that is not queued")
+	require.Equal(s.t, queued[0].queuedTimestamp, o.queuedTimestamp, "Operations of invocation %d were not started in FIFO order", o.invocation)
+	s.queuedOperations[o.invocation] = append(queued[:index], queued[index+1:]...)
+
+	s.executingWorkersCounts[o.invocation]++
+	w.operation = o
+}
+
+// actOnWorker lets a randomly chosen worker make progress. Workers that
+// are executing may complete their operation, report that they are
+// still running, restart, or crash.
+func (s *buildQueueSimulation) actOnWorker() {
+	w := s.workers[s.random.Intn(len(s.workers))]
+	o := w.operation
+	if o == nil {
+		if s.hasQueuedOperations() {
+			s.startOperation(w)
+		} else {
+			response := s.synchronizeIdle(w, true)
+			require.NotNil(s.t, response.DesiredState.GetIdle())
+		}
+		return
+	}
+
+	switch r := s.random.Intn(100); {
+	case r < 60:
+		// Complete the operation successfully.
+		response := s.synchronizeExecuting(w, &remoteworker.CurrentState_Executing{
+			ExecutionState: &remoteworker.CurrentState_Executing_Completed{
+				Completed: &remoteexecution.ExecuteResponse{
+					Result: &remoteexecution.ActionResult{},
+				},
+			},
+		}, true)
+		require.NotNil(s.t, response.DesiredState.GetIdle())
+		s.completeOperation(o, codes.OK)
+		w.operation = nil
+	case r < 90:
+		// Report that the operation is still running.
+		response := s.synchronizeExecuting(w, &remoteworker.CurrentState_Executing{
+			ExecutionState: &remoteworker.CurrentState_Executing_Running{
+				Running: &emptypb.Empty{},
+			},
+		}, false)
+		require.Nil(s.t, response.DesiredState)
+	case r < 98:
+		// The worker restarted, meaning it reports itself as
+		// being idle. It should receive the same task, unless
+		// it has been retried too often.
+		response := s.synchronizeIdle(w, true)
+		if o.retryCount < s.configuration.WorkerTaskRetryCount {
+			executing := response.DesiredState.GetExecuting()
+			require.NotNil(s.t, executing, "Restarted worker did not receive its task again")
+			require.Equal(s.t, o.actionDigest.Hash, executing.ActionDigest.GetHash())
+			o.retryCount++
+		} else {
+			require.NotNil(s.t, response.DesiredState.GetIdle())
+			s.completeOperation(o, codes.Internal)
+			w.operation = nil
+		}
+	default:
+		// The worker crashed and never synchronizes again.
+		// Replace it with a new worker.
+		s.crashedWorkers = append(s.crashedWorkers, crashedWorker{
+			removalTime: w.lastSynchronization.Add(s.configuration.WorkerWithNoSynchronizationsTimeout),
+			operation:   o,
+		})
+		for i, otherWorker := range s.workers {
+			if otherWorker == w {
+				s.workers = append(s.workers[:i], s.workers[i+1:]...)
+				break
+			}
+		}
+		s.synchronizeIdle(s.newWorker(), true)
+	}
+}
+
+// advanceClock lets time progress. Workers that haven't synchronized
+// for some time are given the opportunity to do so first, so that they
+// don't become stale.
+func (s *buildQueueSimulation) advanceClock(d time.Duration) {
+	deadline := s.clock.Now().Add(d)
+	for _, w := range s.workers {
+		if !w.lastSynchronization.Add(simulationWorkerHeartbeatInterval).After(deadline) {
+			if w.operation == nil {
+				s.synchronizeIdle(w, true)
+			} else {
+				response := s.synchronizeExecuting(w, &remoteworker.CurrentState_Executing{
+					ExecutionState: &remoteworker.CurrentState_Executing_Running{
+						Running: &emptypb.Empty{},
+					},
+				}, false)
+				require.Nil(s.t, response.DesiredState)
+			}
+		}
+	}
+	s.clock.advance(d)
+}
+
+func (s *buildQueueSimulation) run(eventsCount int) {
+	for i := 0; i < eventsCount; i++ {
+		switch r := s.random.Intn(100); {
+		case r < 20:
+			if s.operationsInFlight < simulationMaximumOperationsInFlight {
+				s.submitOperation()
+			}
+		case r < 40:
+			s.advanceClock(time.Duration(s.random.Int63n(int64(simulationMaximumClockStep))))
+		default:
+			s.actOnWorker()
+		}
+	}
+
+	// Let the workers that are still alive drain the queue.
+	for {
+		busy := false
+		for _, w := range s.workers {
+			if w.operation != nil {
+				s.synchronizeExecuting(w, &remoteworker.CurrentState_Executing{
+					ExecutionState: &remoteworker.CurrentState_Executing_Completed{
+						Completed: &remoteexecution.ExecuteResponse{
+							Result: &remoteexecution.ActionResult{},
+						},
+					},
+				}, true)
+				s.completeOperation(w.operation, codes.OK)
+				w.operation = nil
+				busy = true
+			} else if s.hasQueuedOperations() {
+				s.startOperation(w)
+				busy = true
+			}
+		}
+		if !busy {
+			break
+		}
+	}
+
+	// Wait for crashed workers to become stale, so that their
+	// operations fail.
+	s.advanceClock(s.configuration.WorkerWithNoSynchronizationsTimeout)
+	for _, w := range s.workers {
+		s.synchronizeIdle(w, true)
+	}
+	require.Empty(s.t, s.crashedWorkers)
+	require.Equal(s.t, 0, s.operationsInFlight)
+
+	// All clients should have received the outcome predicted by
+	// the model.
+	for _, o := range s.operations {
+		select {
+		case <-o.done:
+		case <-time.After(time.Minute):
+			require.FailNow(s.t, "Execute() did not return", "Operation for action %s", o.actionDigest.Hash)
+		}
+		require.NoError(s.t, o.err)
+		require.True(s.t, o.lastUpdate.Done)
+		var executeResponse remoteexecution.ExecuteResponse
+		require.NoError(s.t, o.lastUpdate.GetResponse().UnmarshalTo(&executeResponse))
+		require.Equal(s.t, o.expectedCode, status.FromProto(executeResponse.Status).Code(), "Operation for action %s", o.actionDigest.Hash)
+	}
+}
+
+// simulationEventsCount controls the number of events processed by
+// each run of TestInMemoryBuildQueueSimulation. The default is small
+// enough to keep regular test runs fast. A larger number of events can
+// be provided to exercise rare interleavings, e.g. by running:
+//
+//	go test -run TestInMemoryBuildQueueSimulation ./pkg/scheduler -args -simulation_events_count=1000000
+var simulationEventsCount = flag.Int("simulation_events_count", 10000, "Number of events to process per seed in TestInMemoryBuildQueueSimulation")
+
+func TestInMemoryBuildQueueSimulation(t *testing.T) {
+	eventsCount := *simulationEventsCount
+	if testing.Short() && eventsCount > 1000 {
+		eventsCount = 1000
+	}
+	for _, seed := range []int64{1, 2, 3} {
+		t.Run(fmt.Sprintf("Seed%d", seed), func(t *testing.T) {
+			newBuildQueueSimulation(t, seed).run(eventsCount)
+		})
+	}
+}
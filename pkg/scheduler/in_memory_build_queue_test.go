@@ -10,6 +10,7 @@ import (
 
 	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
 	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	re_builder "github.com/buildbarn/bb-remote-execution/pkg/builder"
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/buildqueuestate"
 	"github.com/buildbarn/bb-remote-execution/pkg/proto/remoteworker"
 	"github.com/buildbarn/bb-remote-execution/pkg/scheduler"
@@ -40,13 +41,14 @@ import (
 )
 
 var buildQueueConfigurationForTesting = scheduler.InMemoryBuildQueueConfiguration{
-	ExecutionUpdateInterval:              time.Minute,
-	OperationWithNoWaitersTimeout:        time.Minute,
-	PlatformQueueWithNoWorkersTimeout:    15 * time.Minute,
-	BusyWorkerSynchronizationInterval:    10 * time.Second,
-	GetIdleWorkerSynchronizationInterval: func() time.Duration { return time.Minute },
-	WorkerTaskRetryCount:                 9,
-	WorkerWithNoSynchronizationsTimeout:  time.Minute,
+	ExecutionUpdateInterval:                    time.Minute,
+	OperationWithNoWaitersTimeout:              time.Minute,
+	PlatformQueueWithNoWorkersTimeout:          15 * time.Minute,
+	BusyWorkerSynchronizationInterval:          10 * time.Second,
+	GetIdleWorkerSynchronizationInterval:       func() time.Duration { return time.Minute },
+	StreamingIdleWorkerSynchronizationInterval: 30 * time.Minute,
+	WorkerTaskRetryCount:                       9,
+	WorkerWithNoSynchronizationsTimeout:        time.Minute,
 }
 
 var platformForTesting = &remoteexecution.Platform{
@@ -58,6 +60,8 @@ var platformForTesting = &remoteexecution.Platform{
 
 var allowAllAuthorizer = auth.NewStaticAuthorizer(func(digest.InstanceName) bool { return true })
 
+var denyAllAuthorizer = auth.NewStaticAuthorizer(func(digest.InstanceName) bool { return false })
+
 // getExecutionClient creates a GRPC client for calling Execute() and
 // WaitExecution() operations against a build queue. These operations
 // use streaming RPCs, which prevents us from invoking these operations
@@ -90,7 +94,7 @@ func TestInMemoryBuildQueueExecuteBadRequest(t *testing.T) {
 	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
 	executionClient := getExecutionClient(t, buildQueue)
 
 	// ExecuteRequest contains an invalid action digest.
@@ -217,7 +221,7 @@ func TestInMemoryBuildQueuePurgeStaleWorkersAndQueues(t *testing.T) {
 	clock := mock.NewMockClock(ctrl)
 	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
 	executionClient := getExecutionClient(t, buildQueue)
 
 	// Announce a new worker, which creates a queue for operations.
@@ -520,7 +524,7 @@ func TestInMemoryBuildQueuePurgeStaleOperations(t *testing.T) {
 	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
 	executionClient := getExecutionClient(t, buildQueue)
 
 	// Announce a new worker, which creates a queue for operations.
@@ -769,7 +773,7 @@ func TestInMemoryBuildQueueCrashLoopingWorker(t *testing.T) {
 	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
 	executionClient := getExecutionClient(t, buildQueue)
 
 	// Announce a new worker, which creates a queue for operations.
@@ -990,7 +994,7 @@ func TestInMemoryBuildQueueKillOperationsOperationName(t *testing.T) {
 	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
 	executionClient := getExecutionClient(t, buildQueue)
 
 	// Announce a new worker, which creates a queue for operations.
@@ -1209,7 +1213,7 @@ func TestInMemoryBuildQueueKillOperationsSizeClassQueueWithoutWorkers(t *testing
 	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
 	executionClient := getExecutionClient(t, buildQueue)
 
 	// If the scheduler is in the initial state, the size class
@@ -1376,7 +1380,7 @@ func TestInMemoryBuildQueueIdleWorkerSynchronizationTimeout(t *testing.T) {
 	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
 
 	// When no work appears, workers should still be woken up
 	// periodically to resynchronize. This ensures that workers that
@@ -1434,7 +1438,7 @@ func TestInMemoryBuildQueueDrainedWorker(t *testing.T) {
 	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
 	executionClient := getExecutionClient(t, buildQueue)
 
 	// Announce a new worker, which creates a queue for operations.
@@ -1736,7 +1740,7 @@ func TestInMemoryBuildQueueInvocationFairness(t *testing.T) {
 	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
 	executionClient := getExecutionClient(t, buildQueue)
 
 	// Announce a new worker, which creates a queue for operations.
@@ -2131,7 +2135,7 @@ func TestInMemoryBuildQueueInFlightDeduplicationAbandonQueued(t *testing.T) {
 	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
 	executionClient := getExecutionClient(t, buildQueue)
 
 	// Announce a new worker, which creates a queue for operations.
@@ -2322,7 +2326,7 @@ func TestInMemoryBuildQueueInFlightDeduplicationAbandonExecuting(t *testing.T) {
 	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
 	executionClient := getExecutionClient(t, buildQueue)
 
 	// Announce a new worker, which creates a queue for operations.
@@ -2549,6 +2553,192 @@ func TestInMemoryBuildQueueInFlightDeduplicationAbandonExecuting(t *testing.T) {
 	}
 }
 
+func TestInMemoryBuildQueueInFlightDeduplicationSkipCacheLookup(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(1000, 0)).AnyTimes()
+	timer := mock.NewMockTimer(ctrl)
+	clock.EXPECT().NewTimer(time.Minute).Return(timer, nil).AnyTimes()
+	timer.EXPECT().Stop().Return(true).AnyTimes()
+	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
+	actionRouter := mock.NewMockActionRouter(ctrl)
+	configuration := buildQueueConfigurationForTesting
+	configuration.SkipCacheLookupIgnoredInstanceNamePrefixes = []digest.InstanceName{
+		digest.MustNewInstanceName("ci"),
+	}
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &configuration, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
+	executionClient := getExecutionClient(t, buildQueue)
+
+	// Announce workers, which creates queues for operations.
+	for _, instanceNamePrefix := range []string{"main", "ci/main"} {
+		_, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+			WorkerId: map[string]string{
+				"hostname": "worker123",
+				"thread":   "42",
+			},
+			InstanceNamePrefix: instanceNamePrefix,
+			Platform:           platformForTesting,
+			CurrentState: &remoteworker.CurrentState{
+				WorkerState: &remoteworker.CurrentState_Idle{
+					Idle: &emptypb.Empty{},
+				},
+			},
+			PreferBeingIdle: true,
+		})
+		require.NoError(t, err)
+	}
+
+	// Helper for enqueueing an operation. If the operation is
+	// expected to be deduplicated against an existing task, the
+	// initial size class selector is abandoned and the name of the
+	// existing operation is returned to the client.
+	initialSizeClassLearner := mock.NewMockLearner(ctrl)
+	execute := func(t *testing.T, instanceName, operationName string, skipCacheLookup, deduplicated bool) {
+		contentAddressableStorage.EXPECT().Get(
+			gomock.Any(),
+			digest.MustNewDigest(instanceName, remoteexecution.DigestFunction_SHA256, "fc96ea0eee854b45950d3a7448332445730886691b992cb7917da0853664f7c2", 123),
+		).Return(buffer.NewProtoBufferFromProto(&remoteexecution.Action{
+			CommandDigest: &remoteexecution.Digest{
+				Hash:      "f7a3ac7c17e535bc9b54ab13dbbb95a52ca1f1edaf9503ce23ccb3eca331a4f5",
+				SizeBytes: 456,
+			},
+		}, buffer.UserProvided))
+		initialSizeClassSelector := mock.NewMockSelector(ctrl)
+		actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(platform.MustNewKey(instanceName, platformForTesting), nil, initialSizeClassSelector, nil)
+		if deduplicated {
+			initialSizeClassSelector.EXPECT().Abandoned()
+		} else {
+			initialSizeClassSelector.EXPECT().Select([]uint32{0}).
+				Return(0, 15*time.Minute, 30*time.Minute, initialSizeClassLearner)
+			uuidGenerator.EXPECT().Call().Return(uuid.Parse(operationName))
+		}
+
+		stream, err := executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
+			InstanceName: instanceName,
+			ActionDigest: &remoteexecution.Digest{
+				Hash:      "fc96ea0eee854b45950d3a7448332445730886691b992cb7917da0853664f7c2",
+				SizeBytes: 123,
+			},
+			SkipCacheLookup: skipCacheLookup,
+		})
+		require.NoError(t, err)
+		update, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, operationName, update.Name)
+	}
+
+	t.Run("Honored", func(t *testing.T) {
+		// Requests that have skip_cache_lookup set should not
+		// be deduplicated against the existing task, as that
+		// would cause the action not to be executed again.
+		execute(t, "main", "b4667823-9f8e-451d-a3e4-4481ec67329f", false, false)
+		execute(t, "main", "1b9e4aaf-b984-4ebc-9b51-0e31bf1b0edb", true, false)
+
+		// Requests that don't have skip_cache_lookup set should
+		// continue to be deduplicated.
+		execute(t, "main", "b4667823-9f8e-451d-a3e4-4481ec67329f", false, true)
+	})
+
+	t.Run("Ignored", func(t *testing.T) {
+		// For instance names for which skip_cache_lookup is
+		// ignored, requests should be deduplicated regardless.
+		execute(t, "ci/main", "def137ac-7724-43ff-98f9-b16a3ba01dcd", false, false)
+		execute(t, "ci/main", "def137ac-7724-43ff-98f9-b16a3ba01dcd", true, true)
+	})
+}
+
+func TestInMemoryBuildQueueInFlightDeduplicationExcludedPlatformProperties(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(1000, 0)).AnyTimes()
+	timer := mock.NewMockTimer(ctrl)
+	clock.EXPECT().NewTimer(time.Minute).Return(timer, nil).AnyTimes()
+	timer.EXPECT().Stop().Return(true).AnyTimes()
+	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
+	actionRouter := mock.NewMockActionRouter(ctrl)
+	configuration := buildQueueConfigurationForTesting
+	configuration.CacheKeyExcludedPlatformPropertyNames = []string{"pool"}
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &configuration, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
+	executionClient := getExecutionClient(t, buildQueue)
+
+	// Announce a new worker, which creates a queue for operations.
+	_, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{
+			"hostname": "worker123",
+			"thread":   "42",
+		},
+		InstanceNamePrefix: "main",
+		Platform:           platformForTesting,
+		CurrentState: &remoteworker.CurrentState{
+			WorkerState: &remoteworker.CurrentState_Idle{
+				Idle: &emptypb.Empty{},
+			},
+		},
+		PreferBeingIdle: true,
+	})
+	require.NoError(t, err)
+
+	// Helper for enqueueing an operation for an action having a
+	// given value for the "cpu" and "pool" platform properties.
+	initialSizeClassLearner := mock.NewMockLearner(ctrl)
+	execute := func(cpu, pool, operationName string, deduplicated bool) {
+		actionMessage := &remoteexecution.Action{
+			CommandDigest: &remoteexecution.Digest{
+				Hash:      "f7a3ac7c17e535bc9b54ab13dbbb95a52ca1f1edaf9503ce23ccb3eca331a4f5",
+				SizeBytes: 456,
+			},
+			Platform: &remoteexecution.Platform{
+				Properties: []*remoteexecution.Platform_Property{
+					{Name: "cpu", Value: cpu},
+					{Name: "pool", Value: pool},
+				},
+			},
+		}
+		actionData, err := proto.Marshal(actionMessage)
+		require.NoError(t, err)
+		digestGenerator := digest.MustNewFunction("main", remoteexecution.DigestFunction_SHA256).NewGenerator(int64(len(actionData)))
+		_, err = digestGenerator.Write(actionData)
+		require.NoError(t, err)
+		actionDigest := digestGenerator.Sum()
+		contentAddressableStorage.EXPECT().Get(gomock.Any(), actionDigest).
+			Return(buffer.NewProtoBufferFromProto(actionMessage, buffer.UserProvided))
+		initialSizeClassSelector := mock.NewMockSelector(ctrl)
+		actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(platform.MustNewKey("main", platformForTesting), nil, initialSizeClassSelector, nil)
+		if deduplicated {
+			initialSizeClassSelector.EXPECT().Abandoned()
+		} else {
+			initialSizeClassSelector.EXPECT().Select([]uint32{0}).
+				Return(0, 15*time.Minute, 30*time.Minute, initialSizeClassLearner)
+			uuidGenerator.EXPECT().Call().Return(uuid.Parse(operationName))
+		}
+
+		stream, err := executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
+			InstanceName: "main",
+			ActionDigest: actionDigest.GetProto(),
+		})
+		require.NoError(t, err)
+		update, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, operationName, update.Name)
+	}
+
+	// Actions that only differ in the value of the "pool" platform
+	// property should be deduplicated, as this property is
+	// excluded from Action Cache keys.
+	execute("armv6", "large", "b4667823-9f8e-451d-a3e4-4481ec67329f", false)
+	execute("armv6", "small", "b4667823-9f8e-451d-a3e4-4481ec67329f", true)
+
+	// Actions that differ in other platform properties should not
+	// be deduplicated.
+	execute("x86_64", "small", "1b9e4aaf-b984-4ebc-9b51-0e31bf1b0edb", false)
+}
+
 func TestInMemoryBuildQueuePreferBeingIdle(t *testing.T) {
 	ctrl, ctx := gomock.WithContext(context.Background(), t)
 
@@ -2557,7 +2747,7 @@ func TestInMemoryBuildQueuePreferBeingIdle(t *testing.T) {
 	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
 	executionClient := getExecutionClient(t, buildQueue)
 
 	// Announce a new worker, which creates a queue for operations.
@@ -2779,7 +2969,7 @@ func TestInMemoryBuildQueueMultipleSizeClasses(t *testing.T) {
 	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
 	executionClient := getExecutionClient(t, buildQueue)
 
 	// Register a platform queue that allows workers up to size
@@ -2792,6 +2982,8 @@ func TestInMemoryBuildQueueMultipleSizeClasses(t *testing.T) {
 		/* workerInvocationStickinessLimits = */ nil,
 		/* maximumQueuedBackgroundLearningOperations = */ 0,
 		/* backgroundLearningOperationPriority = */ 0,
+		/* backgroundLearningOnIdleWorkersOnly = */ false,
+		/* platformPropertyMerger = */ nil,
 		/* maximumSizeClass = */ 8))
 
 	// Workers with a higher size class should be rejected, as no
@@ -3154,7 +3346,7 @@ func TestInMemoryBuildQueueBackgroundRun(t *testing.T) {
 	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
 	executionClient := getExecutionClient(t, buildQueue)
 
 	// Register a platform queue that allows workers up to size
@@ -3167,6 +3359,8 @@ func TestInMemoryBuildQueueBackgroundRun(t *testing.T) {
 		/* workerInvocationStickinessLimits = */ nil,
 		/* maximumQueuedBackgroundLearningOperations = */ 10,
 		/* backgroundLearningOperationPriority = */ 100,
+		/* backgroundLearningOnIdleWorkersOnly = */ false,
+		/* platformPropertyMerger = */ nil,
 		/* maximumSizeClass = */ 8))
 
 	clock.EXPECT().Now().Return(time.Unix(1002, 0))
@@ -3470,248 +3664,459 @@ func TestInMemoryBuildQueueBackgroundRun(t *testing.T) {
 	}, response)
 }
 
-func TestInMemoryBuildQueueIdleSynchronizingWorkers(t *testing.T) {
+func TestInMemoryBuildQueueBackgroundLearningOnIdleWorkersOnly(t *testing.T) {
 	ctrl, ctx := gomock.WithContext(context.Background(), t)
 
 	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
-	mockClock := mock.NewMockClock(ctrl)
-	mockClock.EXPECT().Now().Return(time.Unix(0, 0))
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, mockClock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
 	executionClient := getExecutionClient(t, buildQueue)
 
-	// Common values used by steps below.
-	action := &remoteexecution.Action{
-		CommandDigest: &remoteexecution.Digest{
-			Hash:      "61c585c297d00409bd477b6b80759c94ec545ab4",
-			SizeBytes: 456,
-		},
-		DoNotCache: true,
-		Timeout:    &durationpb.Duration{Seconds: 420},
+	requestMetadata := &remoteexecution.RequestMetadata{
+		ToolInvocationId: "a7b3c0a8-0a8e-4c57-9d1b-6b1f4f1d2c3e",
 	}
-	actionDigest := &remoteexecution.Digest{
-		Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
-		SizeBytes: 123,
-	}
-	invocationID1, err := anypb.New(&remoteexecution.RequestMetadata{
-		ToolInvocationId: "33b38903-d456-4417-951b-bd8a2681c136",
-	})
+	requestMetadataAny, err := anypb.New(requestMetadata)
 	require.NoError(t, err)
-	invocationID2, err := anypb.New(&remoteexecution.RequestMetadata{
-		ToolInvocationId: "75b27319-2704-4fa0-84c9-6881ea5b93ad",
-	})
+	requestMetadataBin, err := proto.Marshal(requestMetadata)
 	require.NoError(t, err)
-	invocationID3, err := anypb.New(&remoteexecution.RequestMetadata{
-		ToolInvocationId: "d6be714f-cef6-408f-b3c7-dfeeae48f63f",
+	ctxWithRequestMetadata := metadata.AppendToOutgoingContext(
+		ctx,
+		"build.bazel.remote.execution.v2.requestmetadata-bin",
+		string(requestMetadataBin))
+
+	// Register a platform queue that allows workers up to size
+	// class 8. The maximum needs to be provided to ensure that the
+	// execution strategy remains deterministic.
+	clock.EXPECT().Now().Return(time.Unix(1000, 0))
+	require.NoError(t, buildQueue.RegisterPredeclaredPlatformQueue(
+		digest.MustNewInstanceName("main"),
+		platformForTesting,
+		/* workerInvocationStickinessLimits = */ nil,
+		/* maximumQueuedBackgroundLearningOperations = */ 10,
+		/* backgroundLearningOperationPriority = */ 0,
+		/* backgroundLearningOnIdleWorkersOnly = */ true,
+		/* platformPropertyMerger = */ nil,
+		/* maximumSizeClass = */ 8))
+
+	clock.EXPECT().Now().Return(time.Unix(1002, 0))
+	response, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{
+			"hostname": "worker123",
+			"thread":   "42",
+		},
+		InstanceNamePrefix: "main",
+		Platform:           platformForTesting,
+		SizeClass:          3,
+		CurrentState: &remoteworker.CurrentState{
+			WorkerState: &remoteworker.CurrentState_Executing_{
+				Executing: &remoteworker.CurrentState_Executing{
+					ActionDigest: &remoteexecution.Digest{
+						Hash:      "099a3f6dc1e8e91dbcca4ea964cd2237d4b11733",
+						SizeBytes: 123,
+					},
+					ExecutionState: &remoteworker.CurrentState_Executing_FetchingInputs{
+						FetchingInputs: &emptypb.Empty{},
+					},
+				},
+			},
+		},
 	})
 	require.NoError(t, err)
-	workerID1 := map[string]string{
-		"hostname": "worker123",
-		"thread":   "42",
-	}
-	workerID2 := map[string]string{
-		"hostname": "worker123",
-		"thread":   "43",
-	}
-	sizeClassQueueName := &buildqueuestate.SizeClassQueueName{
-		PlatformQueueName: &buildqueuestate.PlatformQueueName{
-			Platform: platformForTesting,
+	testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
+		NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1002},
+		DesiredState: &remoteworker.DesiredState{
+			WorkerState: &remoteworker.DesiredState_Idle{
+				Idle: &emptypb.Empty{},
+			},
+		},
+	}, response)
+
+	// Let a client enqueue a new operation, which we'll initially
+	// schedule on the largest size class.
+	contentAddressableStorage.EXPECT().Get(
+		gomock.Any(),
+		digest.MustNewDigest("main", remoteexecution.DigestFunction_SHA1, "da39a3ee5e6b4b0d3255bfef95601890afd80709", 123),
+	).Return(buffer.NewProtoBufferFromProto(&remoteexecution.Action{
+		CommandDigest: &remoteexecution.Digest{
+			Hash:      "61c585c297d00409bd477b6b80759c94ec545ab4",
+			SizeBytes: 456,
+		},
+	}, buffer.UserProvided))
+	initialSizeClassSelector := mock.NewMockSelector(ctrl)
+	actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), testutil.EqProto(t, &remoteexecution.Action{
+		CommandDigest: &remoteexecution.Digest{
+			Hash:      "61c585c297d00409bd477b6b80759c94ec545ab4",
+			SizeBytes: 456,
+		},
+	}), nil).Return(platform.MustNewKey("main", platformForTesting), nil, initialSizeClassSelector, nil)
+	initialSizeClassLearner1 := mock.NewMockLearner(ctrl)
+	initialSizeClassSelector.EXPECT().Select([]uint32{3, 8}).
+		Return(1, 3*time.Minute, 7*time.Minute, initialSizeClassLearner1)
+	clock.EXPECT().Now().Return(time.Unix(1003, 0))
+	timer1 := mock.NewMockTimer(ctrl)
+	clock.EXPECT().NewTimer(time.Minute).Return(timer1, nil)
+	uuidGenerator.EXPECT().Call().Return(uuid.Parse("36ebab65-3c4f-4faf-818b-2eabb4cd1b02"))
+
+	stream1, err := executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
+		InstanceName: "main",
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+			SizeBytes: 123,
 		},
-	}
-	metadataExecuting, err := anypb.New(&remoteexecution.ExecuteOperationMetadata{
-		Stage:        remoteexecution.ExecutionStage_EXECUTING,
-		ActionDigest: actionDigest,
 	})
 	require.NoError(t, err)
-	metadataCompleted, err := anypb.New(&remoteexecution.ExecuteOperationMetadata{
-		Stage:        remoteexecution.ExecutionStage_COMPLETED,
-		ActionDigest: actionDigest,
-	})
+	update, err := stream1.Recv()
 	require.NoError(t, err)
-	executeResponse, err := anypb.New(&remoteexecution.ExecuteResponse{
-		Result: &remoteexecution.ActionResult{},
+	metadata, err := anypb.New(&remoteexecution.ExecuteOperationMetadata{
+		Stage: remoteexecution.ExecutionStage_QUEUED,
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+			SizeBytes: 123,
+		},
 	})
 	require.NoError(t, err)
+	testutil.RequireEqualProto(t, update, &longrunningpb.Operation{
+		Name:     "36ebab65-3c4f-4faf-818b-2eabb4cd1b02",
+		Metadata: metadata,
+	})
 
-	contentAddressableStorage.EXPECT().Get(
-		gomock.Any(),
-		digest.MustNewDigest("", remoteexecution.DigestFunction_SHA1, "da39a3ee5e6b4b0d3255bfef95601890afd80709", 123),
-	).Return(buffer.NewProtoBufferFromProto(action, buffer.UserProvided)).AnyTimes()
-
-	// Create a worker that does a blocking Synchronize() call
-	// against the scheduler.
-	mockClock.EXPECT().Now().Return(time.Unix(1000, 0))
-	timer1 := mock.NewMockTimer(ctrl)
-	wait1 := make(chan struct{}, 1)
-	mockClock.EXPECT().NewTimer(time.Minute).DoAndReturn(func(d time.Duration) (clock.Timer, <-chan time.Time) {
-		wait1 <- struct{}{}
-		return timer1, nil
-	})
-	var response1 *remoteworker.SynchronizeResponse
-	var err1 error
-	wait2 := make(chan struct{}, 1)
-	go func() {
-		response1, err1 = buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
-			WorkerId: workerID1,
-			Platform: platformForTesting,
-			CurrentState: &remoteworker.CurrentState{
-				WorkerState: &remoteworker.CurrentState_Idle{
-					Idle: &emptypb.Empty{},
-				},
-			},
-		})
-		wait2 <- struct{}{}
-	}()
-	<-wait1
-
-	// Assign a task to it. The worker should be woken up directly.
-	// The client should immediately receive an EXECUTING update.
-	// There is no need to return QUEUED.
-	initialSizeClassSelector1 := mock.NewMockSelector(ctrl)
-	actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), testutil.EqProto(t, action), nil).Return(
-		platform.MustNewKey("", platformForTesting),
-		[]invocation.Key{invocation.MustNewKey(invocationID1)},
-		initialSizeClassSelector1,
-		nil,
-	)
-	initialSizeClassLearner1 := mock.NewMockLearner(ctrl)
-	initialSizeClassSelector1.EXPECT().Select([]uint32{0}).
-		Return(0, 3*time.Minute, 7*time.Minute, initialSizeClassLearner1)
-	mockClock.EXPECT().Now().Return(time.Unix(1001, 0)).Times(2)
+	// Let a worker for the largest size class pick it up.
+	timer1.EXPECT().Stop().Return(true)
+	clock.EXPECT().Now().Return(time.Unix(1004, 0)).Times(2)
 	timer2 := mock.NewMockTimer(ctrl)
-	mockClock.EXPECT().NewTimer(time.Minute).Return(timer2, nil)
-	uuidGenerator.EXPECT().Call().Return(uuid.Parse("36ebab65-3c4f-4faf-818b-2eabb4cd1b02"))
-	timer1.EXPECT().Stop()
-
-	stream1, err := executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
-		ActionDigest: actionDigest,
+	clock.EXPECT().NewTimer(time.Minute).Return(timer2, nil)
+	response, err = buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{
+			"hostname": "worker456",
+			"thread":   "42",
+		},
+		InstanceNamePrefix: "main",
+		Platform:           platformForTesting,
+		SizeClass:          8,
+		CurrentState: &remoteworker.CurrentState{
+			WorkerState: &remoteworker.CurrentState_Idle{
+				Idle: &emptypb.Empty{},
+			},
+		},
 	})
 	require.NoError(t, err)
-	update, err := stream1.Recv()
-	require.NoError(t, err)
-	testutil.RequireEqualProto(t, update, &longrunningpb.Operation{
-		Name:     "36ebab65-3c4f-4faf-818b-2eabb4cd1b02",
-		Metadata: metadataExecuting,
-	})
-
-	// The worker should get unblocked.
-	<-wait2
-	require.NoError(t, err1)
 	testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
-		NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1011},
+		NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1014},
 		DesiredState: &remoteworker.DesiredState{
 			WorkerState: &remoteworker.DesiredState_Executing_{
 				Executing: &remoteworker.DesiredState_Executing{
-					DigestFunction:  remoteexecution.DigestFunction_SHA1,
-					ActionDigest:    actionDigest,
-					Action:          action,
-					QueuedTimestamp: &timestamppb.Timestamp{Seconds: 1001},
+					DigestFunction: remoteexecution.DigestFunction_SHA1,
+					ActionDigest: &remoteexecution.Digest{
+						Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+						SizeBytes: 123,
+					},
+					Action: &remoteexecution.Action{
+						CommandDigest: &remoteexecution.Digest{
+							Hash:      "61c585c297d00409bd477b6b80759c94ec545ab4",
+							SizeBytes: 456,
+						},
+						Timeout: &durationpb.Duration{Seconds: 420},
+					},
+					QueuedTimestamp: &timestamppb.Timestamp{Seconds: 1003},
 				},
 			},
 		},
-	}, response1)
-
-	// Let the worker complete the operation. This should wake up
-	// the client.
-	mockClock.EXPECT().Now().Return(time.Unix(1002, 0)).Times(3)
-	initialSizeClassLearner1.EXPECT().Succeeded(time.Duration(0), []uint32{0})
-	timer2.EXPECT().Stop()
-	timer3 := mock.NewMockTimer(ctrl)
-	wait3 := make(chan struct{}, 1)
-	mockClock.EXPECT().NewTimer(time.Minute).DoAndReturn(func(d time.Duration) (clock.Timer, <-chan time.Time) {
-		wait3 <- struct{}{}
-		return timer3, nil
+	}, response)
+	update, err = stream1.Recv()
+	require.NoError(t, err)
+	metadata, err = anypb.New(&remoteexecution.ExecuteOperationMetadata{
+		Stage: remoteexecution.ExecutionStage_EXECUTING,
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+			SizeBytes: 123,
+		},
 	})
-	var response2 *remoteworker.SynchronizeResponse
-	var err2 error
-	wait4 := make(chan struct{}, 1)
-	go func() {
-		response2, err2 = buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
-			WorkerId: workerID1,
-			Platform: platformForTesting,
-			CurrentState: &remoteworker.CurrentState{
-				WorkerState: &remoteworker.CurrentState_Executing_{
-					Executing: &remoteworker.CurrentState_Executing{
-						ActionDigest: actionDigest,
-						ExecutionState: &remoteworker.CurrentState_Executing_Completed{
-							Completed: &remoteexecution.ExecuteResponse{
-								Result: &remoteexecution.ActionResult{},
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &longrunningpb.Operation{
+		Name:     "36ebab65-3c4f-4faf-818b-2eabb4cd1b02",
+		Metadata: metadata,
+	}, update)
+
+	// The action succeeds on the worker of the largest size class.
+	// In response, request that the same action is rerun on the
+	// smaller size class. Because we don't want to leave the client
+	// blocked on that, this should be done as part of a separate
+	// task.
+	initialSizeClassLearner2 := mock.NewMockLearner(ctrl)
+	initialSizeClassLearner1.EXPECT().Succeeded(3*time.Second, []uint32{3, 8}).
+		Return(0, 30*time.Second, time.Minute, initialSizeClassLearner2)
+	uuidGenerator.EXPECT().Call().Return(uuid.Parse("30326ed7-101a-4bf2-93eb-fcb6e7672415"))
+	timer2.EXPECT().Stop().Return(true)
+	clock.EXPECT().Now().Return(time.Unix(1005, 0)).Times(3)
+	response, err = buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{
+			"hostname": "worker456",
+			"thread":   "42",
+		},
+		InstanceNamePrefix: "main",
+		Platform:           platformForTesting,
+		SizeClass:          8,
+		CurrentState: &remoteworker.CurrentState{
+			WorkerState: &remoteworker.CurrentState_Executing_{
+				Executing: &remoteworker.CurrentState_Executing{
+					ActionDigest: &remoteexecution.Digest{
+						Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+						SizeBytes: 123,
+					},
+					ExecutionState: &remoteworker.CurrentState_Executing_Completed{
+						Completed: &remoteexecution.ExecuteResponse{
+							Result: &remoteexecution.ActionResult{
+								ExecutionMetadata: &remoteexecution.ExecutedActionMetadata{
+									VirtualExecutionDuration: &durationpb.Duration{Seconds: 3},
+								},
 							},
 						},
 					},
 				},
 			},
-		})
-		wait4 <- struct{}{}
-	}()
-	<-wait3
-
+		},
+		PreferBeingIdle: true,
+	})
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
+		NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1005},
+		DesiredState: &remoteworker.DesiredState{
+			WorkerState: &remoteworker.DesiredState_Idle{
+				Idle: &emptypb.Empty{},
+			},
+		},
+	}, response)
 	update, err = stream1.Recv()
 	require.NoError(t, err)
+	metadata, err = anypb.New(&remoteexecution.ExecuteOperationMetadata{
+		Stage: remoteexecution.ExecutionStage_COMPLETED,
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+			SizeBytes: 123,
+		},
+	})
+	require.NoError(t, err)
+	executeResponse, err := anypb.New(&remoteexecution.ExecuteResponse{
+		Result: &remoteexecution.ActionResult{
+			ExecutionMetadata: &remoteexecution.ExecutedActionMetadata{
+				VirtualExecutionDuration: &durationpb.Duration{Seconds: 3},
+			},
+		},
+	})
+	require.NoError(t, err)
 	testutil.RequireEqualProto(t, update, &longrunningpb.Operation{
 		Name:     "36ebab65-3c4f-4faf-818b-2eabb4cd1b02",
-		Metadata: metadataCompleted,
+		Metadata: metadata,
 		Done:     true,
 		Result:   &longrunningpb.Operation_Response{Response: executeResponse},
 	})
-	_, err = stream1.Recv()
-	require.Equal(t, io.EOF, err)
 
-	// Even though there are no longer any active or queued
-	// invocations, the scheduler should keep track of the
-	// invocation belonging to the previously completed action,
-	// keeping track of how which workers are associated with it.
-	mockClock.EXPECT().Now().Return(time.Unix(1003, 0)).Times(3)
-	invocationName := &buildqueuestate.InvocationName{
-		SizeClassQueueName: sizeClassQueueName,
-	}
-	invocationStates, err := buildQueue.ListInvocationChildren(ctx, &buildqueuestate.ListInvocationChildrenRequest{
-		InvocationName: invocationName,
-		Filter:         buildqueuestate.ListInvocationChildrenRequest_ALL,
-	})
-	require.NoError(t, err)
-	testutil.RequireEqualProto(t, &buildqueuestate.ListInvocationChildrenResponse{
-		Children: []*buildqueuestate.InvocationChildState{
-			{
-				Id: invocationID1,
-				State: &buildqueuestate.InvocationState{
-					IdleWorkersCount:              1,
-					IdleSynchronizingWorkersCount: 1,
-				},
-			},
+	// Let another client enqueue an operation that is scheduled on
+	// the smaller size class directly. The background learning
+	// operation has the same priority and was queued earlier, which
+	// would normally cause it to be picked first. As background
+	// learning operations may only run on idle workers, the
+	// client's operation should be picked instead.
+	contentAddressableStorage.EXPECT().Get(
+		gomock.Any(),
+		digest.MustNewDigest("main", remoteexecution.DigestFunction_SHA1, "4e1243bd22c66e76c2ba9eddc1f91394e57f9f83", 123),
+	).Return(buffer.NewProtoBufferFromProto(&remoteexecution.Action{
+		CommandDigest: &remoteexecution.Digest{
+			Hash:      "d5f0a1ff8b6e1e5c3b4b8d4e0b3f8f9c1a2b3c4d",
+			SizeBytes: 456,
 		},
-	}, invocationStates)
+	}, buffer.UserProvided))
+	initialSizeClassSelector2 := mock.NewMockSelector(ctrl)
+	actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), testutil.EqProto(t, &remoteexecution.Action{
+		CommandDigest: &remoteexecution.Digest{
+			Hash:      "d5f0a1ff8b6e1e5c3b4b8d4e0b3f8f9c1a2b3c4d",
+			SizeBytes: 456,
+		},
+	}), testutil.EqProto(t, requestMetadata)).Return(
+		platform.MustNewKey("main", platformForTesting),
+		[]invocation.Key{invocation.MustNewKey(requestMetadataAny)},
+		initialSizeClassSelector2,
+		nil,
+	)
+	initialSizeClassLearner3 := mock.NewMockLearner(ctrl)
+	initialSizeClassSelector2.EXPECT().Select([]uint32{3, 8}).
+		Return(0, 30*time.Second, time.Minute, initialSizeClassLearner3)
+	clock.EXPECT().Now().Return(time.Unix(1006, 0))
+	timer3 := mock.NewMockTimer(ctrl)
+	clock.EXPECT().NewTimer(time.Minute).Return(timer3, nil)
+	uuidGenerator.EXPECT().Call().Return(uuid.Parse("1f6e1b8e-7d0a-4a6b-9f3e-2c5d8a9b0c1d"))
 
-	invocationStates, err = buildQueue.ListInvocationChildren(ctx, &buildqueuestate.ListInvocationChildrenRequest{
-		InvocationName: invocationName,
-		Filter:         buildqueuestate.ListInvocationChildrenRequest_ACTIVE,
+	stream2, err := executionClient.Execute(ctxWithRequestMetadata, &remoteexecution.ExecuteRequest{
+		InstanceName: "main",
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "4e1243bd22c66e76c2ba9eddc1f91394e57f9f83",
+			SizeBytes: 123,
+		},
 	})
 	require.NoError(t, err)
-	testutil.RequireEqualProto(t, &buildqueuestate.ListInvocationChildrenResponse{}, invocationStates)
-
-	invocationStates, err = buildQueue.ListInvocationChildren(ctx, &buildqueuestate.ListInvocationChildrenRequest{
-		InvocationName: invocationName,
-		Filter:         buildqueuestate.ListInvocationChildrenRequest_QUEUED,
-	})
+	update, err = stream2.Recv()
 	require.NoError(t, err)
-	testutil.RequireEqualProto(t, &buildqueuestate.ListInvocationChildrenResponse{}, invocationStates)
-
-	// Create a second worker that issues a blocking Synchronize()
-	// call against the scheduler.
-	mockClock.EXPECT().Now().Return(time.Unix(1004, 0))
+	metadata, err = anypb.New(&remoteexecution.ExecuteOperationMetadata{
+		Stage: remoteexecution.ExecutionStage_QUEUED,
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "4e1243bd22c66e76c2ba9eddc1f91394e57f9f83",
+			SizeBytes: 123,
+		},
+	})
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &longrunningpb.Operation{
+		Name:     "1f6e1b8e-7d0a-4a6b-9f3e-2c5d8a9b0c1d",
+		Metadata: metadata,
+	}, update)
+
+	// The worker for the smaller size class should pick up the
+	// client's operation, leaving the background learning operation
+	// queued until the worker would otherwise be idle.
+	timer3.EXPECT().Stop().Return(true)
+	clock.EXPECT().Now().Return(time.Unix(1007, 0)).Times(2)
 	timer4 := mock.NewMockTimer(ctrl)
-	wait5 := make(chan struct{}, 1)
+	clock.EXPECT().NewTimer(time.Minute).Return(timer4, nil)
+	response, err = buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{
+			"hostname": "worker123",
+			"thread":   "42",
+		},
+		InstanceNamePrefix: "main",
+		Platform:           platformForTesting,
+		SizeClass:          3,
+		CurrentState: &remoteworker.CurrentState{
+			WorkerState: &remoteworker.CurrentState_Idle{
+				Idle: &emptypb.Empty{},
+			},
+		},
+	})
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
+		NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1017},
+		DesiredState: &remoteworker.DesiredState{
+			WorkerState: &remoteworker.DesiredState_Executing_{
+				Executing: &remoteworker.DesiredState_Executing{
+					DigestFunction: remoteexecution.DigestFunction_SHA1,
+					ActionDigest: &remoteexecution.Digest{
+						Hash:      "4e1243bd22c66e76c2ba9eddc1f91394e57f9f83",
+						SizeBytes: 123,
+					},
+					Action: &remoteexecution.Action{
+						CommandDigest: &remoteexecution.Digest{
+							Hash:      "d5f0a1ff8b6e1e5c3b4b8d4e0b3f8f9c1a2b3c4d",
+							SizeBytes: 456,
+						},
+						Timeout: &durationpb.Duration{Seconds: 60},
+					},
+					QueuedTimestamp:   &timestamppb.Timestamp{Seconds: 1006},
+					AuxiliaryMetadata: []*anypb.Any{requestMetadataAny},
+				},
+			},
+		},
+	}, response)
+	update, err = stream2.Recv()
+	require.NoError(t, err)
+	metadata, err = anypb.New(&remoteexecution.ExecuteOperationMetadata{
+		Stage: remoteexecution.ExecutionStage_EXECUTING,
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "4e1243bd22c66e76c2ba9eddc1f91394e57f9f83",
+			SizeBytes: 123,
+		},
+	})
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &longrunningpb.Operation{
+		Name:     "1f6e1b8e-7d0a-4a6b-9f3e-2c5d8a9b0c1d",
+		Metadata: metadata,
+	}, update)
+}
+
+func TestInMemoryBuildQueueIdleSynchronizingWorkers(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	mockClock := mock.NewMockClock(ctrl)
+	mockClock.EXPECT().Now().Return(time.Unix(0, 0))
+	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
+	actionRouter := mock.NewMockActionRouter(ctrl)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, mockClock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
+	executionClient := getExecutionClient(t, buildQueue)
+
+	// Common values used by steps below.
+	action := &remoteexecution.Action{
+		CommandDigest: &remoteexecution.Digest{
+			Hash:      "61c585c297d00409bd477b6b80759c94ec545ab4",
+			SizeBytes: 456,
+		},
+		DoNotCache: true,
+		Timeout:    &durationpb.Duration{Seconds: 420},
+	}
+	actionDigest := &remoteexecution.Digest{
+		Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+		SizeBytes: 123,
+	}
+	invocationID1, err := anypb.New(&remoteexecution.RequestMetadata{
+		ToolInvocationId: "33b38903-d456-4417-951b-bd8a2681c136",
+	})
+	require.NoError(t, err)
+	invocationID2, err := anypb.New(&remoteexecution.RequestMetadata{
+		ToolInvocationId: "75b27319-2704-4fa0-84c9-6881ea5b93ad",
+	})
+	require.NoError(t, err)
+	invocationID3, err := anypb.New(&remoteexecution.RequestMetadata{
+		ToolInvocationId: "d6be714f-cef6-408f-b3c7-dfeeae48f63f",
+	})
+	require.NoError(t, err)
+	workerID1 := map[string]string{
+		"hostname": "worker123",
+		"thread":   "42",
+	}
+	workerID2 := map[string]string{
+		"hostname": "worker123",
+		"thread":   "43",
+	}
+	sizeClassQueueName := &buildqueuestate.SizeClassQueueName{
+		PlatformQueueName: &buildqueuestate.PlatformQueueName{
+			Platform: platformForTesting,
+		},
+	}
+	metadataExecuting, err := anypb.New(&remoteexecution.ExecuteOperationMetadata{
+		Stage:        remoteexecution.ExecutionStage_EXECUTING,
+		ActionDigest: actionDigest,
+	})
+	require.NoError(t, err)
+	metadataCompleted, err := anypb.New(&remoteexecution.ExecuteOperationMetadata{
+		Stage:        remoteexecution.ExecutionStage_COMPLETED,
+		ActionDigest: actionDigest,
+	})
+	require.NoError(t, err)
+	executeResponse, err := anypb.New(&remoteexecution.ExecuteResponse{
+		Result: &remoteexecution.ActionResult{},
+	})
+	require.NoError(t, err)
+
+	contentAddressableStorage.EXPECT().Get(
+		gomock.Any(),
+		digest.MustNewDigest("", remoteexecution.DigestFunction_SHA1, "da39a3ee5e6b4b0d3255bfef95601890afd80709", 123),
+	).Return(buffer.NewProtoBufferFromProto(action, buffer.UserProvided)).AnyTimes()
+
+	// Create a worker that does a blocking Synchronize() call
+	// against the scheduler.
+	mockClock.EXPECT().Now().Return(time.Unix(1000, 0))
+	timer1 := mock.NewMockTimer(ctrl)
+	wait1 := make(chan struct{}, 1)
 	mockClock.EXPECT().NewTimer(time.Minute).DoAndReturn(func(d time.Duration) (clock.Timer, <-chan time.Time) {
-		wait5 <- struct{}{}
-		return timer4, nil
+		wait1 <- struct{}{}
+		return timer1, nil
 	})
-	var response3 *remoteworker.SynchronizeResponse
-	var err3 error
-	wait6 := make(chan struct{}, 1)
+	var response1 *remoteworker.SynchronizeResponse
+	var err1 error
+	wait2 := make(chan struct{}, 1)
 	go func() {
-		response3, err3 = buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
-			WorkerId: workerID2,
+		response1, err1 = buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+			WorkerId: workerID1,
 			Platform: platformForTesting,
 			CurrentState: &remoteworker.CurrentState{
 				WorkerState: &remoteworker.CurrentState_Idle{
@@ -3719,70 +4124,74 @@ func TestInMemoryBuildQueueIdleSynchronizingWorkers(t *testing.T) {
 				},
 			},
 		})
-		wait6 <- struct{}{}
+		wait2 <- struct{}{}
 	}()
-	<-wait5
+	<-wait1
 
-	// Schedule another operation. Because this operation uses a
-	// different invocation ID, it must be scheduled on the second
-	// worker. We want to keep the first worker available for
-	// actions for the same invocation ID.
-	initialSizeClassSelector2 := mock.NewMockSelector(ctrl)
+	// Assign a task to it. The worker should be woken up directly.
+	// The client should immediately receive an EXECUTING update.
+	// There is no need to return QUEUED.
+	initialSizeClassSelector1 := mock.NewMockSelector(ctrl)
 	actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), testutil.EqProto(t, action), nil).Return(
 		platform.MustNewKey("", platformForTesting),
-		[]invocation.Key{invocation.MustNewKey(invocationID2)},
-		initialSizeClassSelector2,
+		[]invocation.Key{invocation.MustNewKey(invocationID1)},
+		initialSizeClassSelector1,
 		nil,
 	)
-	initialSizeClassLearner2 := mock.NewMockLearner(ctrl)
-	initialSizeClassSelector2.EXPECT().Select([]uint32{0}).
-		Return(0, 3*time.Minute, 7*time.Minute, initialSizeClassLearner2)
-	mockClock.EXPECT().Now().Return(time.Unix(1005, 0)).Times(2)
-	timer5 := mock.NewMockTimer(ctrl)
-	mockClock.EXPECT().NewTimer(time.Minute).Return(timer5, nil)
-	uuidGenerator.EXPECT().Call().Return(uuid.Parse("e98bb734-0ec7-4cc5-bb98-bb3d5c0788c2"))
-	timer4.EXPECT().Stop()
+	initialSizeClassLearner1 := mock.NewMockLearner(ctrl)
+	initialSizeClassSelector1.EXPECT().Select([]uint32{0}).
+		Return(0, 3*time.Minute, 7*time.Minute, initialSizeClassLearner1)
+	mockClock.EXPECT().Now().Return(time.Unix(1001, 0)).Times(2)
+	timer2 := mock.NewMockTimer(ctrl)
+	mockClock.EXPECT().NewTimer(time.Minute).Return(timer2, nil)
+	uuidGenerator.EXPECT().Call().Return(uuid.Parse("36ebab65-3c4f-4faf-818b-2eabb4cd1b02"))
+	timer1.EXPECT().Stop()
 
-	stream2, err := executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
+	stream1, err := executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
 		ActionDigest: actionDigest,
 	})
 	require.NoError(t, err)
-	update, err = stream2.Recv()
+	update, err := stream1.Recv()
 	require.NoError(t, err)
 	testutil.RequireEqualProto(t, update, &longrunningpb.Operation{
-		Name:     "e98bb734-0ec7-4cc5-bb98-bb3d5c0788c2",
+		Name:     "36ebab65-3c4f-4faf-818b-2eabb4cd1b02",
 		Metadata: metadataExecuting,
 	})
 
-	<-wait6
-	require.NoError(t, err3)
+	// The worker should get unblocked.
+	<-wait2
+	require.NoError(t, err1)
 	testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
-		NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1015},
+		NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1011},
 		DesiredState: &remoteworker.DesiredState{
 			WorkerState: &remoteworker.DesiredState_Executing_{
 				Executing: &remoteworker.DesiredState_Executing{
 					DigestFunction:  remoteexecution.DigestFunction_SHA1,
 					ActionDigest:    actionDigest,
 					Action:          action,
-					QueuedTimestamp: &timestamppb.Timestamp{Seconds: 1005},
+					QueuedTimestamp: &timestamppb.Timestamp{Seconds: 1001},
 				},
 			},
 		},
-	}, response3)
+	}, response1)
 
-	// Let the second worker complete the operation.
-	mockClock.EXPECT().Now().Return(time.Unix(1006, 0)).Times(3)
-	initialSizeClassLearner2.EXPECT().Succeeded(time.Duration(0), []uint32{0})
-	timer5.EXPECT().Stop()
-	timer6 := mock.NewMockTimer(ctrl)
-	wait7 := make(chan struct{}, 1)
+	// Let the worker complete the operation. This should wake up
+	// the client.
+	mockClock.EXPECT().Now().Return(time.Unix(1002, 0)).Times(3)
+	initialSizeClassLearner1.EXPECT().Succeeded(time.Duration(0), []uint32{0})
+	timer2.EXPECT().Stop()
+	timer3 := mock.NewMockTimer(ctrl)
+	wait3 := make(chan struct{}, 1)
 	mockClock.EXPECT().NewTimer(time.Minute).DoAndReturn(func(d time.Duration) (clock.Timer, <-chan time.Time) {
-		wait7 <- struct{}{}
-		return timer6, nil
+		wait3 <- struct{}{}
+		return timer3, nil
 	})
+	var response2 *remoteworker.SynchronizeResponse
+	var err2 error
+	wait4 := make(chan struct{}, 1)
 	go func() {
-		buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
-			WorkerId: workerID2,
+		response2, err2 = buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+			WorkerId: workerID1,
 			Platform: platformForTesting,
 			CurrentState: &remoteworker.CurrentState{
 				WorkerState: &remoteworker.CurrentState_Executing_{
@@ -3797,13 +4206,14 @@ func TestInMemoryBuildQueueIdleSynchronizingWorkers(t *testing.T) {
 				},
 			},
 		})
+		wait4 <- struct{}{}
 	}()
-	<-wait7
+	<-wait3
 
-	update, err = stream2.Recv()
+	update, err = stream1.Recv()
 	require.NoError(t, err)
 	testutil.RequireEqualProto(t, update, &longrunningpb.Operation{
-		Name:     "e98bb734-0ec7-4cc5-bb98-bb3d5c0788c2",
+		Name:     "36ebab65-3c4f-4faf-818b-2eabb4cd1b02",
 		Metadata: metadataCompleted,
 		Done:     true,
 		Result:   &longrunningpb.Operation_Response{Response: executeResponse},
@@ -3811,9 +4221,15 @@ func TestInMemoryBuildQueueIdleSynchronizingWorkers(t *testing.T) {
 	_, err = stream1.Recv()
 	require.Equal(t, io.EOF, err)
 
-	// Both invocations should now have one worker.
-	mockClock.EXPECT().Now().Return(time.Unix(1007, 0))
-	invocationStates, err = buildQueue.ListInvocationChildren(ctx, &buildqueuestate.ListInvocationChildrenRequest{
+	// Even though there are no longer any active or queued
+	// invocations, the scheduler should keep track of the
+	// invocation belonging to the previously completed action,
+	// keeping track of how which workers are associated with it.
+	mockClock.EXPECT().Now().Return(time.Unix(1003, 0)).Times(3)
+	invocationName := &buildqueuestate.InvocationName{
+		SizeClassQueueName: sizeClassQueueName,
+	}
+	invocationStates, err := buildQueue.ListInvocationChildren(ctx, &buildqueuestate.ListInvocationChildrenRequest{
 		InvocationName: invocationName,
 		Filter:         buildqueuestate.ListInvocationChildrenRequest_ALL,
 	})
@@ -3827,22 +4243,169 @@ func TestInMemoryBuildQueueIdleSynchronizingWorkers(t *testing.T) {
 					IdleSynchronizingWorkersCount: 1,
 				},
 			},
-			{
-				Id: invocationID2,
-				State: &buildqueuestate.InvocationState{
-					IdleWorkersCount:              1,
-					IdleSynchronizingWorkersCount: 1,
-				},
-			},
 		},
 	}, invocationStates)
 
-	// When submitting a third operation that uses an unknown
-	// invocation, we should execute it on the first worker, as that
-	// worker is associated with an invocation that is least
-	// recently seen.
-	initialSizeClassSelector3 := mock.NewMockSelector(ctrl)
-	actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), testutil.EqProto(t, action), nil).Return(
+	invocationStates, err = buildQueue.ListInvocationChildren(ctx, &buildqueuestate.ListInvocationChildrenRequest{
+		InvocationName: invocationName,
+		Filter:         buildqueuestate.ListInvocationChildrenRequest_ACTIVE,
+	})
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &buildqueuestate.ListInvocationChildrenResponse{}, invocationStates)
+
+	invocationStates, err = buildQueue.ListInvocationChildren(ctx, &buildqueuestate.ListInvocationChildrenRequest{
+		InvocationName: invocationName,
+		Filter:         buildqueuestate.ListInvocationChildrenRequest_QUEUED,
+	})
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &buildqueuestate.ListInvocationChildrenResponse{}, invocationStates)
+
+	// Create a second worker that issues a blocking Synchronize()
+	// call against the scheduler.
+	mockClock.EXPECT().Now().Return(time.Unix(1004, 0))
+	timer4 := mock.NewMockTimer(ctrl)
+	wait5 := make(chan struct{}, 1)
+	mockClock.EXPECT().NewTimer(time.Minute).DoAndReturn(func(d time.Duration) (clock.Timer, <-chan time.Time) {
+		wait5 <- struct{}{}
+		return timer4, nil
+	})
+	var response3 *remoteworker.SynchronizeResponse
+	var err3 error
+	wait6 := make(chan struct{}, 1)
+	go func() {
+		response3, err3 = buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+			WorkerId: workerID2,
+			Platform: platformForTesting,
+			CurrentState: &remoteworker.CurrentState{
+				WorkerState: &remoteworker.CurrentState_Idle{
+					Idle: &emptypb.Empty{},
+				},
+			},
+		})
+		wait6 <- struct{}{}
+	}()
+	<-wait5
+
+	// Schedule another operation. Because this operation uses a
+	// different invocation ID, it must be scheduled on the second
+	// worker. We want to keep the first worker available for
+	// actions for the same invocation ID.
+	initialSizeClassSelector2 := mock.NewMockSelector(ctrl)
+	actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), testutil.EqProto(t, action), nil).Return(
+		platform.MustNewKey("", platformForTesting),
+		[]invocation.Key{invocation.MustNewKey(invocationID2)},
+		initialSizeClassSelector2,
+		nil,
+	)
+	initialSizeClassLearner2 := mock.NewMockLearner(ctrl)
+	initialSizeClassSelector2.EXPECT().Select([]uint32{0}).
+		Return(0, 3*time.Minute, 7*time.Minute, initialSizeClassLearner2)
+	mockClock.EXPECT().Now().Return(time.Unix(1005, 0)).Times(2)
+	timer5 := mock.NewMockTimer(ctrl)
+	mockClock.EXPECT().NewTimer(time.Minute).Return(timer5, nil)
+	uuidGenerator.EXPECT().Call().Return(uuid.Parse("e98bb734-0ec7-4cc5-bb98-bb3d5c0788c2"))
+	timer4.EXPECT().Stop()
+
+	stream2, err := executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
+		ActionDigest: actionDigest,
+	})
+	require.NoError(t, err)
+	update, err = stream2.Recv()
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, update, &longrunningpb.Operation{
+		Name:     "e98bb734-0ec7-4cc5-bb98-bb3d5c0788c2",
+		Metadata: metadataExecuting,
+	})
+
+	<-wait6
+	require.NoError(t, err3)
+	testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
+		NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1015},
+		DesiredState: &remoteworker.DesiredState{
+			WorkerState: &remoteworker.DesiredState_Executing_{
+				Executing: &remoteworker.DesiredState_Executing{
+					DigestFunction:  remoteexecution.DigestFunction_SHA1,
+					ActionDigest:    actionDigest,
+					Action:          action,
+					QueuedTimestamp: &timestamppb.Timestamp{Seconds: 1005},
+				},
+			},
+		},
+	}, response3)
+
+	// Let the second worker complete the operation.
+	mockClock.EXPECT().Now().Return(time.Unix(1006, 0)).Times(3)
+	initialSizeClassLearner2.EXPECT().Succeeded(time.Duration(0), []uint32{0})
+	timer5.EXPECT().Stop()
+	timer6 := mock.NewMockTimer(ctrl)
+	wait7 := make(chan struct{}, 1)
+	mockClock.EXPECT().NewTimer(time.Minute).DoAndReturn(func(d time.Duration) (clock.Timer, <-chan time.Time) {
+		wait7 <- struct{}{}
+		return timer6, nil
+	})
+	go func() {
+		buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+			WorkerId: workerID2,
+			Platform: platformForTesting,
+			CurrentState: &remoteworker.CurrentState{
+				WorkerState: &remoteworker.CurrentState_Executing_{
+					Executing: &remoteworker.CurrentState_Executing{
+						ActionDigest: actionDigest,
+						ExecutionState: &remoteworker.CurrentState_Executing_Completed{
+							Completed: &remoteexecution.ExecuteResponse{
+								Result: &remoteexecution.ActionResult{},
+							},
+						},
+					},
+				},
+			},
+		})
+	}()
+	<-wait7
+
+	update, err = stream2.Recv()
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, update, &longrunningpb.Operation{
+		Name:     "e98bb734-0ec7-4cc5-bb98-bb3d5c0788c2",
+		Metadata: metadataCompleted,
+		Done:     true,
+		Result:   &longrunningpb.Operation_Response{Response: executeResponse},
+	})
+	_, err = stream1.Recv()
+	require.Equal(t, io.EOF, err)
+
+	// Both invocations should now have one worker.
+	mockClock.EXPECT().Now().Return(time.Unix(1007, 0))
+	invocationStates, err = buildQueue.ListInvocationChildren(ctx, &buildqueuestate.ListInvocationChildrenRequest{
+		InvocationName: invocationName,
+		Filter:         buildqueuestate.ListInvocationChildrenRequest_ALL,
+	})
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &buildqueuestate.ListInvocationChildrenResponse{
+		Children: []*buildqueuestate.InvocationChildState{
+			{
+				Id: invocationID1,
+				State: &buildqueuestate.InvocationState{
+					IdleWorkersCount:              1,
+					IdleSynchronizingWorkersCount: 1,
+				},
+			},
+			{
+				Id: invocationID2,
+				State: &buildqueuestate.InvocationState{
+					IdleWorkersCount:              1,
+					IdleSynchronizingWorkersCount: 1,
+				},
+			},
+		},
+	}, invocationStates)
+
+	// When submitting a third operation that uses an unknown
+	// invocation, we should execute it on the first worker, as that
+	// worker is associated with an invocation that is least
+	// recently seen.
+	initialSizeClassSelector3 := mock.NewMockSelector(ctrl)
+	actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), testutil.EqProto(t, action), nil).Return(
 		platform.MustNewKey("", platformForTesting),
 		[]invocation.Key{invocation.MustNewKey(invocationID3)},
 		initialSizeClassSelector3,
@@ -3893,7 +4456,7 @@ func TestInMemoryBuildQueueWorkerInvocationStickinessLimit(t *testing.T) {
 	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
 	executionClient := getExecutionClient(t, buildQueue)
 
 	// Register a platform queue that has a small amount of worker
@@ -3907,6 +4470,8 @@ func TestInMemoryBuildQueueWorkerInvocationStickinessLimit(t *testing.T) {
 		/* workerInvocationStickinessLimits = */ []time.Duration{3 * time.Second},
 		/* maximumQueuedBackgroundLearningOperations = */ 10,
 		/* backgroundLearningOperationPriority = */ 100,
+		/* backgroundLearningOnIdleWorkersOnly = */ false,
+		/* platformPropertyMerger = */ nil,
 		/* maximumSizeClass = */ 0))
 
 	operationParameters := []struct {
@@ -4119,7 +4684,7 @@ func TestInMemoryBuildQueueAuthorization(t *testing.T) {
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
 	authorizer := mock.NewMockAuthorizer(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, authorizer, authorizer, authorizer)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, authorizer, authorizer, authorizer, allowAllAuthorizer, denyAllAuthorizer)
 	beepboop := digest.MustNewInstanceName("beepboop")
 
 	t.Run("GetCapabilities-NotAuthorized", func(t *testing.T) {
@@ -4156,6 +4721,8 @@ func TestInMemoryBuildQueueAuthorization(t *testing.T) {
 			/* workerInvocationStickinessLimits = */ nil,
 			/* maximumQueuedBackgroundLearningOperations = */ 0,
 			/* backgroundLearningOperationPriority = */ 0,
+			/* backgroundLearningOnIdleWorkersOnly = */ false,
+			/* platformPropertyMerger = */ nil,
 			/* maximumSizeClass = */ 0)
 
 		// Allow the Execute
@@ -4222,280 +4789,1437 @@ func TestInMemoryBuildQueueAuthorization(t *testing.T) {
 	})
 }
 
-func TestInMemoryBuildQueueNestedInvocationsSynchronization(t *testing.T) {
+func TestInMemoryBuildQueueActionCacheWriteAuthorization(t *testing.T) {
 	ctrl, ctx := gomock.WithContext(context.Background(), t)
 
 	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
-	mockClock := mock.NewMockClock(ctrl)
-	mockClock.EXPECT().Now().Return(time.Unix(0, 0))
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(0, 0))
 	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
 	actionRouter := mock.NewMockActionRouter(ctrl)
-	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, mockClock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer)
+	actionCacheWriteAuthorizer := mock.NewMockAuthorizer(ctrl)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, actionCacheWriteAuthorizer, denyAllAuthorizer)
 	executionClient := getExecutionClient(t, buildQueue)
+	instanceName := digest.MustNewInstanceName("main")
 
-	mockClock.EXPECT().Now().Return(time.Unix(1000, 0))
-	require.NoError(t, buildQueue.RegisterPredeclaredPlatformQueue(
-		digest.EmptyInstanceName,
-		platformForTesting,
-		/* workerInvocationStickinessLimits = */ nil,
-		/* maximumQueuedBackgroundLearningOperations = */ 0,
-		/* backgroundLearningOperationPriority = */ 0,
-		/* maximumSizeClass = */ 0))
+	t.Run("AuthorizerFailure", func(t *testing.T) {
+		// Failures of the authorizer should not cause the
+		// worker to be permitted to write into the Action
+		// Cache.
+		actionCacheWriteAuthorizer.EXPECT().Authorize(gomock.Any(), []digest.InstanceName{instanceName}).
+			Return([]error{status.Error(codes.Internal, "I fell over")})
 
-	// Create ten workers. Let all of them complete a task that
-	// belonged to the same correlated invocations ID, but a
-	// different tool invocation ID.
-	correlatedInvocationsID := &remoteexecution.RequestMetadata{
-		CorrelatedInvocationsId: "7e5d4921-a6d2-480e-8e2d-a4afecd7ab33",
-	}
-	correlatedInvocationsIDAny, err := anypb.New(correlatedInvocationsID)
-	require.NoError(t, err)
-	for i, p := range []struct {
-		operationName    string
-		toolInvocationID string
-	}{
-		{"716f2e32-d273-49e7-a842-82282e89d1a3", "93b8f304-4a3d-4832-91b2-e596e8a08f42"},
-		{"0a656823-9f91-4220-9dcd-58503e62e6e8", "e3658358-6325-4405-a43c-b3f81ca6e52a"},
-		{"175ead9a-a095-43a5-9f2c-e3a293938ff3", "a76ecbfc-4a3a-4822-99c5-30c0ff7dbbb8"},
-		{"d354a1ea-0945-4c84-be17-ac01b8058d06", "167fda12-0c84-4936-978f-2b54ebe3b600"},
-		{"5fe458ce-a28d-4478-9ced-7ee23a539cb6", "02c0d7d2-2e0a-4664-8030-60ad5e9026bd"},
-		{"30e6d53c-a737-47bc-8b06-8e8f9cf7a3b3", "892d2891-8344-4cb0-88b6-852106b2b329"},
-		{"77d52264-940c-461b-b67f-e3e68cb5b7f0", "1ccd7a67-0b55-4242-a4a1-942064a79341"},
-		{"201d6ff3-ffe3-4126-928b-cba38938ebb5", "cbb6455f-ede1-4314-ba63-a44f18188672"},
-		{"303fa653-8516-4a4f-9b6b-60c3f1f45f29", "3c5c6475-5ce6-42ef-b8ba-aa0a67348174"},
-		{"ca172285-ba23-42fd-a714-b582cad3bf32", "fd345278-02e8-48d8-8ff6-4f467c0cd942"},
-	} {
-		action := &remoteexecution.Action{
-			DoNotCache: true,
-			CommandDigest: &remoteexecution.Digest{
-				Hash:      "9b818e201c59f31954cb1e126cc67562ec545ab4",
-				SizeBytes: 456,
+		_, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+			WorkerId: map[string]string{
+				"hostname": "worker123",
+				"thread":   "42",
 			},
-		}
-		contentAddressableStorage.EXPECT().Get(
-			gomock.Any(),
-			digest.MustNewDigest("", remoteexecution.DigestFunction_SHA1, "0474d2f48968a56da4de20718d8ac23aafd80709", 123),
-		).Return(buffer.NewProtoBufferFromProto(action, buffer.UserProvided))
-		toolInvocationID := &remoteexecution.RequestMetadata{
-			ToolInvocationId: p.toolInvocationID,
-		}
-		toolInvocationIDAny, err := anypb.New(toolInvocationID)
-		require.NoError(t, err)
-		initialSizeClassSelector := mock.NewMockSelector(ctrl)
-		actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), testutil.EqProto(t, action), nil).Return(
-			platform.MustNewKey("", platformForTesting),
-			[]invocation.Key{
-				invocation.MustNewKey(correlatedInvocationsIDAny),
-				invocation.MustNewKey(toolInvocationIDAny),
+			InstanceNamePrefix: "main",
+			CurrentState: &remoteworker.CurrentState{
+				WorkerState: &remoteworker.CurrentState_Idle{
+					Idle: &emptypb.Empty{},
+				},
 			},
-			initialSizeClassSelector,
-			nil,
-		)
-		initialSizeClassLearner := mock.NewMockLearner(ctrl)
-		initialSizeClassSelector.EXPECT().Select([]uint32{0}).
-			Return(0, 30*time.Second, time.Minute, initialSizeClassLearner)
-		mockClock.EXPECT().Now().Return(time.Unix(1010+int64(i), 0))
-		timer1 := mock.NewMockTimer(ctrl)
-		mockClock.EXPECT().NewTimer(time.Minute).Return(timer1, nil)
-		uuidGenerator.EXPECT().Call().Return(uuid.Parse(p.operationName))
-
-		actionDigest := &remoteexecution.Digest{
-			Hash:      "0474d2f48968a56da4de20718d8ac23aafd80709",
-			SizeBytes: 123,
-		}
-		stream, err := executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
-			ActionDigest: actionDigest,
-		})
-		require.NoError(t, err)
-		update, err := stream.Recv()
-		require.NoError(t, err)
-		metadata, err := anypb.New(&remoteexecution.ExecuteOperationMetadata{
-			Stage:        remoteexecution.ExecutionStage_QUEUED,
-			ActionDigest: actionDigest,
 		})
-		require.NoError(t, err)
-		testutil.RequireEqualProto(t, &longrunningpb.Operation{
-			Name:     p.operationName,
-			Metadata: metadata,
-		}, update)
+		testutil.RequireEqualStatus(t, status.Error(codes.Internal, "Failed to authorize Action Cache writes: I fell over"), err)
+	})
 
-		mockClock.EXPECT().Now().Return(time.Unix(1010+int64(i), 1)).Times(2)
-		timer1.EXPECT().Stop()
-		timer2 := mock.NewMockTimer(ctrl)
-		mockClock.EXPECT().NewTimer(time.Minute).Return(timer2, nil)
-		workerID := map[string]string{
-			"hostname": "worker123",
-			"thread":   strconv.FormatInt(int64(i), 10),
-		}
+	t.Run("PermissionDenied", func(t *testing.T) {
+		// Announce a new worker, which creates a queue for
+		// operations.
+		actionCacheWriteAuthorizer.EXPECT().Authorize(gomock.Any(), []digest.InstanceName{instanceName}).
+			Return([]error{status.Error(codes.PermissionDenied, "Worker is running a canary release")})
+		clock.EXPECT().Now().Return(time.Unix(1000, 0))
 		response, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
-			WorkerId: workerID,
-			Platform: platformForTesting,
+			WorkerId: map[string]string{
+				"hostname": "worker123",
+				"thread":   "42",
+			},
+			InstanceNamePrefix: "main",
 			CurrentState: &remoteworker.CurrentState{
-				WorkerState: &remoteworker.CurrentState_Idle{
-					Idle: &emptypb.Empty{},
+				WorkerState: &remoteworker.CurrentState_Executing_{
+					Executing: &remoteworker.CurrentState_Executing{
+						ActionDigest: &remoteexecution.Digest{
+							Hash:      "099a3f6dc1e8e91dbcca4ea964cd2237d4b11733",
+							SizeBytes: 123,
+						},
+						ExecutionState: &remoteworker.CurrentState_Executing_FetchingInputs{
+							FetchingInputs: &emptypb.Empty{},
+						},
+					},
 				},
 			},
 		})
 		require.NoError(t, err)
 		testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
-			NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1020 + int64(i), Nanos: 1},
+			NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1000},
 			DesiredState: &remoteworker.DesiredState{
-				WorkerState: &remoteworker.DesiredState_Executing_{
-					Executing: &remoteworker.DesiredState_Executing{
-						DigestFunction: remoteexecution.DigestFunction_SHA1,
-						ActionDigest:   actionDigest,
-						Action: &remoteexecution.Action{
-							DoNotCache: true,
-							CommandDigest: &remoteexecution.Digest{
-								Hash:      "9b818e201c59f31954cb1e126cc67562ec545ab4",
-								SizeBytes: 456,
-							},
-							Timeout: &durationpb.Duration{Seconds: 60},
-						},
-						QueuedTimestamp: &timestamppb.Timestamp{Seconds: 1010 + int64(i)},
-					},
+				WorkerState: &remoteworker.DesiredState_Idle{
+					Idle: &emptypb.Empty{},
 				},
 			},
 		}, response)
 
-		update, err = stream.Recv()
-		require.NoError(t, err)
-		metadata, err = anypb.New(&remoteexecution.ExecuteOperationMetadata{
-			Stage:        remoteexecution.ExecutionStage_EXECUTING,
-			ActionDigest: actionDigest,
+		// Let a client enqueue a new operation.
+		contentAddressableStorage.EXPECT().Get(
+			gomock.Any(),
+			digest.MustNewDigest("main", remoteexecution.DigestFunction_SHA1, "da39a3ee5e6b4b0d3255bfef95601890afd80709", 123),
+		).Return(buffer.NewProtoBufferFromProto(&remoteexecution.Action{
+			CommandDigest: &remoteexecution.Digest{
+				Hash:      "61c585c297d00409bd477b6b80759c94ec545ab4",
+				SizeBytes: 456,
+			},
+		}, buffer.UserProvided))
+		initialSizeClassSelector := mock.NewMockSelector(ctrl)
+		actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), gomock.Any(), nil).
+			Return(platform.MustNewKey("main", &remoteexecution.Platform{}), nil, initialSizeClassSelector, nil)
+		initialSizeClassLearner := mock.NewMockLearner(ctrl)
+		initialSizeClassSelector.EXPECT().Select([]uint32{0}).
+			Return(0, 15*time.Minute, 30*time.Minute, initialSizeClassLearner)
+		clock.EXPECT().Now().Return(time.Unix(1001, 0))
+		timer1 := mock.NewMockTimer(ctrl)
+		clock.EXPECT().NewTimer(time.Minute).Return(timer1, nil)
+		uuidGenerator.EXPECT().Call().Return(uuid.Parse("36ebab65-3c4f-4faf-818b-2eabb4cd1b02"))
+		stream1, err := executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
+			InstanceName: "main",
+			ActionDigest: &remoteexecution.Digest{
+				Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+				SizeBytes: 123,
+			},
 		})
 		require.NoError(t, err)
-		testutil.RequireEqualProto(t, update, &longrunningpb.Operation{
-			Name:     p.operationName,
-			Metadata: metadata,
-		})
+		_, err = stream1.Recv()
+		require.NoError(t, err)
 
-		initialSizeClassLearner.EXPECT().Succeeded(time.Duration(0), []uint32{0})
-		mockClock.EXPECT().Now().Return(time.Unix(1010+int64(i), 2)).Times(3)
-		timer2.EXPECT().Stop()
+		// When assigning the operation to the worker, it
+		// should be instructed not to write into the Action
+		// Cache.
+		actionCacheWriteAuthorizer.EXPECT().Authorize(gomock.Any(), []digest.InstanceName{instanceName}).
+			Return([]error{status.Error(codes.PermissionDenied, "Worker is running a canary release")})
+		clock.EXPECT().Now().Return(time.Unix(1002, 0))
+		timer1.EXPECT().Stop().Return(true)
+		clock.EXPECT().Now().Return(time.Unix(1002, 0))
+		timer2 := mock.NewMockTimer(ctrl)
+		clock.EXPECT().NewTimer(time.Minute).Return(timer2, nil)
 		response, err = buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
-			WorkerId: workerID,
-			Platform: platformForTesting,
+			WorkerId: map[string]string{
+				"hostname": "worker123",
+				"thread":   "42",
+			},
+			InstanceNamePrefix: "main",
 			CurrentState: &remoteworker.CurrentState{
-				WorkerState: &remoteworker.CurrentState_Executing_{
-					Executing: &remoteworker.CurrentState_Executing{
-						ActionDigest: actionDigest,
-						ExecutionState: &remoteworker.CurrentState_Executing_Completed{
-							Completed: &remoteexecution.ExecuteResponse{
-								Result: &remoteexecution.ActionResult{},
-							},
-						},
-					},
+				WorkerState: &remoteworker.CurrentState_Idle{
+					Idle: &emptypb.Empty{},
 				},
 			},
-			PreferBeingIdle: true,
 		})
 		require.NoError(t, err)
 		testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
-			NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1010 + int64(i), Nanos: 2},
+			NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1012},
 			DesiredState: &remoteworker.DesiredState{
-				WorkerState: &remoteworker.DesiredState_Idle{
-					Idle: &emptypb.Empty{},
+				WorkerState: &remoteworker.DesiredState_Executing_{
+					Executing: &remoteworker.DesiredState_Executing{
+						DigestFunction: remoteexecution.DigestFunction_SHA1,
+						ActionDigest: &remoteexecution.Digest{
+							Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+							SizeBytes: 123,
+						},
+						Action: &remoteexecution.Action{
+							CommandDigest: &remoteexecution.Digest{
+								Hash:      "61c585c297d00409bd477b6b80759c94ec545ab4",
+								SizeBytes: 456,
+							},
+							Timeout: &durationpb.Duration{Seconds: 1800},
+						},
+						QueuedTimestamp:           &timestamppb.Timestamp{Seconds: 1001},
+						ProhibitActionCacheWrites: true,
+					},
 				},
 			},
 		}, response)
-
-		update, err = stream.Recv()
-		require.NoError(t, err)
-		metadata, err = anypb.New(&remoteexecution.ExecuteOperationMetadata{
-			Stage:        remoteexecution.ExecutionStage_COMPLETED,
-			ActionDigest: actionDigest,
-		})
+		_, err = stream1.Recv()
 		require.NoError(t, err)
-		executeResponse, err := anypb.New(&remoteexecution.ExecuteResponse{
-			Result: &remoteexecution.ActionResult{},
+	})
+}
+
+func TestInMemoryBuildQueueNestedExecution(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(1000, 0)).AnyTimes()
+	timer := mock.NewMockTimer(ctrl)
+	clock.EXPECT().NewTimer(time.Minute).Return(timer, nil).AnyTimes()
+	timer.EXPECT().Stop().Return(true).AnyTimes()
+	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
+	actionRouter := mock.NewMockActionRouter(ctrl)
+	executeAuthorizer := mock.NewMockAuthorizer(ctrl)
+	nestedExecutionAuthorizer := mock.NewMockAuthorizer(ctrl)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, executeAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, nestedExecutionAuthorizer)
+	instanceName := digest.MustNewInstanceName("main")
+
+	// Expose the build queue through a gRPC server that attaches
+	// authentication metadata, based on the name of the user
+	// provided by the client.
+	conn := bufconn.Listen(1)
+	server := grpc.NewServer(grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		md, _ := metadata.FromIncomingContext(ss.Context())
+		var raw any
+		if users := md.Get("user"); len(users) == 1 {
+			raw = map[string]any{"public": map[string]any{"user": users[0]}}
+		}
+		authenticationMetadata, err := auth.NewAuthenticationMetadataFromRaw(raw)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedServerStream{
+			ServerStream: ss,
+			ctx:          auth.NewContextWithAuthenticationMetadata(ss.Context(), authenticationMetadata),
 		})
-		require.NoError(t, err)
-		testutil.RequireEqualProto(t, &longrunningpb.Operation{
-			Name:     p.operationName,
-			Metadata: metadata,
-			Done:     true,
-			Result:   &longrunningpb.Operation_Response{Response: executeResponse},
-		}, update)
-	}
+	}))
+	remoteexecution.RegisterExecutionServer(server, buildQueue)
+	go func() {
+		require.NoError(t, server.Serve(conn))
+	}()
+	client, err := grpc.Dial(
+		"myself",
+		grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
+			return conn.Dial()
+		}),
+		grpc.WithInsecure())
+	require.NoError(t, err)
+	executionClient := remoteexecution.NewExecutionClient(client)
 
-	// At the top level, we should see an invocation with ten children.
-	invocationName := &buildqueuestate.InvocationName{
-		SizeClassQueueName: &buildqueuestate.SizeClassQueueName{
-			PlatformQueueName: &buildqueuestate.PlatformQueueName{
-				Platform: platformForTesting,
+	// Announce a new worker, which creates a queue for operations.
+	response, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{
+			"hostname": "worker123",
+			"thread":   "42",
+		},
+		InstanceNamePrefix: "main",
+		CurrentState: &remoteworker.CurrentState{
+			WorkerState: &remoteworker.CurrentState_Idle{
+				Idle: &emptypb.Empty{},
 			},
 		},
-	}
-	mockClock.EXPECT().Now().Return(time.Unix(1030, 0))
-	invocationStates, err := buildQueue.ListInvocationChildren(ctx, &buildqueuestate.ListInvocationChildrenRequest{
-		InvocationName: invocationName,
-		Filter:         buildqueuestate.ListInvocationChildrenRequest_ALL,
+		PreferBeingIdle: true,
 	})
 	require.NoError(t, err)
-	testutil.RequireEqualProto(t, &buildqueuestate.ListInvocationChildrenResponse{
-		Children: []*buildqueuestate.InvocationChildState{
-			{
-				Id: correlatedInvocationsIDAny,
-				State: &buildqueuestate.InvocationState{
-					IdleWorkersCount: 10,
-					ChildrenCount:    10,
-				},
-			},
-		},
-	}, invocationStates)
 
-	// Let all ten workers perform a blocking Synchronize() call.
-	allWorkersWait := make(chan struct{})
-	timers := make([]*mock.MockTimer, 0, 10)
-	wakeups := make([]chan<- time.Time, 0, 10)
-	for iIter := 0; iIter < 10; iIter++ {
-		i := iIter
-		mockClock.EXPECT().Now().Return(time.Unix(1040+int64(i), 0))
-		timer := mock.NewMockTimer(ctrl)
-		wakeup := make(chan time.Time, 1)
-		timerCreationWait := make(chan struct{})
-		mockClock.EXPECT().NewTimer(time.Minute).DoAndReturn(func(d time.Duration) (clock.Timer, <-chan time.Time) {
-			timerCreationWait <- struct{}{}
-			return timer, wakeup
+	// Let a client enqueue an operation that is permitted to
+	// submit sub-actions.
+	executeAuthorizer.EXPECT().Authorize(gomock.Any(), []digest.InstanceName{instanceName}).Return([]error{nil})
+	nestedExecutionAuthorizer.EXPECT().Authorize(gomock.Any(), []digest.InstanceName{instanceName}).Return([]error{nil})
+	contentAddressableStorage.EXPECT().Get(
+		gomock.Any(),
+		digest.MustNewDigest("main", remoteexecution.DigestFunction_SHA1, "da39a3ee5e6b4b0d3255bfef95601890afd80709", 123),
+	).Return(buffer.NewProtoBufferFromProto(&remoteexecution.Action{
+		CommandDigest: &remoteexecution.Digest{
+			Hash:      "61c585c297d00409bd477b6b80759c94ec545ab4",
+			SizeBytes: 456,
+		},
+	}, buffer.UserProvided))
+	initialSizeClassSelector := mock.NewMockSelector(ctrl)
+	actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), gomock.Any(), nil).
+		Return(platform.MustNewKey("main", &remoteexecution.Platform{}), nil, initialSizeClassSelector, nil)
+	initialSizeClassLearner := mock.NewMockLearner(ctrl)
+	initialSizeClassSelector.EXPECT().Select([]uint32{0}).
+		Return(0, 15*time.Minute, 30*time.Minute, initialSizeClassLearner)
+	uuidGenerator.EXPECT().Call().Return(uuid.Parse("8f1c9e58-4bd1-4a4a-9c55-3f27d0f4f4b1"))
+	uuidGenerator.EXPECT().Call().Return(uuid.Parse("36ebab65-3c4f-4faf-818b-2eabb4cd1b02"))
+	stream, err := executionClient.Execute(
+		metadata.AppendToOutgoingContext(ctx, "user", "alice"),
+		&remoteexecution.ExecuteRequest{
+			InstanceName: "main",
+			ActionDigest: &remoteexecution.Digest{
+				Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+				SizeBytes: 123,
+			},
 		})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
 
-		go func() {
-			response, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
-				WorkerId: map[string]string{
-					"hostname": "worker123",
-					"thread":   strconv.FormatInt(int64(i), 10),
-				},
-				Platform: platformForTesting,
-				CurrentState: &remoteworker.CurrentState{
-					WorkerState: &remoteworker.CurrentState_Idle{
-						Idle: &emptypb.Empty{},
-					},
+	// The worker should be provided a nested execution token.
+	response, err = buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{
+			"hostname": "worker123",
+			"thread":   "42",
+		},
+		InstanceNamePrefix: "main",
+		CurrentState: &remoteworker.CurrentState{
+			WorkerState: &remoteworker.CurrentState_Idle{
+				Idle: &emptypb.Empty{},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "8f1c9e58-4bd1-4a4a-9c55-3f27d0f4f4b1", response.DesiredState.GetExecuting().GetNestedExecutionToken())
+
+	t.Run("ParentCredentials", func(t *testing.T) {
+		// Sub-actions submitted by the worker should be
+		// authorized using the credentials of the client that
+		// submitted the parent action.
+		executeAuthorizer.EXPECT().Authorize(gomock.Any(), []digest.InstanceName{instanceName}).DoAndReturn(
+			func(ctx context.Context, instanceNames []digest.InstanceName) []error {
+				require.Equal(t, map[string]any{"user": "alice"}, auth.AuthenticationMetadataFromContext(ctx).GetRaw()["public"])
+				return []error{status.Error(codes.PermissionDenied, "Alice has exceeded her quota")}
+			})
+		stream, err := executionClient.Execute(
+			metadata.AppendToOutgoingContext(ctx, "user", "worker", re_builder.NestedExecutionTokenMetadataKey, "8f1c9e58-4bd1-4a4a-9c55-3f27d0f4f4b1"),
+			&remoteexecution.ExecuteRequest{
+				InstanceName: "main",
+				ActionDigest: &remoteexecution.Digest{
+					Hash:      "7d4c3b6b2c1a0e6d5f4b3a29181716151413121a",
+					SizeBytes: 123,
 				},
 			})
-			require.NoError(t, err)
-			testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
-				NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1100 + int64(i)},
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		testutil.RequireEqualStatus(t, status.Error(codes.PermissionDenied, "Authorization: Alice has exceeded her quota"), err)
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		stream, err := executionClient.Execute(
+			metadata.AppendToOutgoingContext(ctx, "user", "worker", re_builder.NestedExecutionTokenMetadataKey, "d6a6b1a8-9a5e-4f0e-8c53-1e0e5f8f3c2d"),
+			&remoteexecution.ExecuteRequest{
+				InstanceName: "main",
+				ActionDigest: &remoteexecution.Digest{
+					Hash:      "7d4c3b6b2c1a0e6d5f4b3a29181716151413121a",
+					SizeBytes: 123,
+				},
+			})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		testutil.RequireEqualStatus(t, status.Error(codes.Unauthenticated, "Nested execution token is invalid, or belongs to an action that has already completed"), err)
+	})
+
+	// Let the worker complete the parent action.
+	initialSizeClassLearner.EXPECT().Succeeded(10*time.Second, []uint32{0})
+	_, err = buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{
+			"hostname": "worker123",
+			"thread":   "42",
+		},
+		InstanceNamePrefix: "main",
+		CurrentState: &remoteworker.CurrentState{
+			WorkerState: &remoteworker.CurrentState_Executing_{
+				Executing: &remoteworker.CurrentState_Executing{
+					ActionDigest: &remoteexecution.Digest{
+						Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+						SizeBytes: 123,
+					},
+					ExecutionState: &remoteworker.CurrentState_Executing_Completed{
+						Completed: &remoteexecution.ExecuteResponse{
+							Result: &remoteexecution.ActionResult{
+								ExecutionMetadata: &remoteexecution.ExecutedActionMetadata{
+									VirtualExecutionDuration: &durationpb.Duration{Seconds: 10},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		PreferBeingIdle: true,
+	})
+	require.NoError(t, err)
+
+	t.Run("RevokedToken", func(t *testing.T) {
+		// Once the parent action has completed, its token
+		// should no longer be accepted.
+		stream, err := executionClient.Execute(
+			metadata.AppendToOutgoingContext(ctx, "user", "worker", re_builder.NestedExecutionTokenMetadataKey, "8f1c9e58-4bd1-4a4a-9c55-3f27d0f4f4b1"),
+			&remoteexecution.ExecuteRequest{
+				InstanceName: "main",
+				ActionDigest: &remoteexecution.Digest{
+					Hash:      "7d4c3b6b2c1a0e6d5f4b3a29181716151413121a",
+					SizeBytes: 123,
+				},
+			})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		testutil.RequireEqualStatus(t, status.Error(codes.Unauthenticated, "Nested execution token is invalid, or belongs to an action that has already completed"), err)
+	})
+}
+
+// authenticatedServerStream is a grpc.ServerStream whose context
+// is overridden.
+type authenticatedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *authenticatedServerStream) Context() context.Context {
+	return ss.ctx
+}
+
+func TestInMemoryBuildQueueNestedInvocationsSynchronization(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	mockClock := mock.NewMockClock(ctrl)
+	mockClock.EXPECT().Now().Return(time.Unix(0, 0))
+	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
+	actionRouter := mock.NewMockActionRouter(ctrl)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, mockClock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
+	executionClient := getExecutionClient(t, buildQueue)
+
+	mockClock.EXPECT().Now().Return(time.Unix(1000, 0))
+	require.NoError(t, buildQueue.RegisterPredeclaredPlatformQueue(
+		digest.EmptyInstanceName,
+		platformForTesting,
+		/* workerInvocationStickinessLimits = */ nil,
+		/* maximumQueuedBackgroundLearningOperations = */ 0,
+		/* backgroundLearningOperationPriority = */ 0,
+		/* backgroundLearningOnIdleWorkersOnly = */ false,
+		/* platformPropertyMerger = */ nil,
+		/* maximumSizeClass = */ 0))
+
+	// Create ten workers. Let all of them complete a task that
+	// belonged to the same correlated invocations ID, but a
+	// different tool invocation ID.
+	correlatedInvocationsID := &remoteexecution.RequestMetadata{
+		CorrelatedInvocationsId: "7e5d4921-a6d2-480e-8e2d-a4afecd7ab33",
+	}
+	correlatedInvocationsIDAny, err := anypb.New(correlatedInvocationsID)
+	require.NoError(t, err)
+	for i, p := range []struct {
+		operationName    string
+		toolInvocationID string
+	}{
+		{"716f2e32-d273-49e7-a842-82282e89d1a3", "93b8f304-4a3d-4832-91b2-e596e8a08f42"},
+		{"0a656823-9f91-4220-9dcd-58503e62e6e8", "e3658358-6325-4405-a43c-b3f81ca6e52a"},
+		{"175ead9a-a095-43a5-9f2c-e3a293938ff3", "a76ecbfc-4a3a-4822-99c5-30c0ff7dbbb8"},
+		{"d354a1ea-0945-4c84-be17-ac01b8058d06", "167fda12-0c84-4936-978f-2b54ebe3b600"},
+		{"5fe458ce-a28d-4478-9ced-7ee23a539cb6", "02c0d7d2-2e0a-4664-8030-60ad5e9026bd"},
+		{"30e6d53c-a737-47bc-8b06-8e8f9cf7a3b3", "892d2891-8344-4cb0-88b6-852106b2b329"},
+		{"77d52264-940c-461b-b67f-e3e68cb5b7f0", "1ccd7a67-0b55-4242-a4a1-942064a79341"},
+		{"201d6ff3-ffe3-4126-928b-cba38938ebb5", "cbb6455f-ede1-4314-ba63-a44f18188672"},
+		{"303fa653-8516-4a4f-9b6b-60c3f1f45f29", "3c5c6475-5ce6-42ef-b8ba-aa0a67348174"},
+		{"ca172285-ba23-42fd-a714-b582cad3bf32", "fd345278-02e8-48d8-8ff6-4f467c0cd942"},
+	} {
+		action := &remoteexecution.Action{
+			DoNotCache: true,
+			CommandDigest: &remoteexecution.Digest{
+				Hash:      "9b818e201c59f31954cb1e126cc67562ec545ab4",
+				SizeBytes: 456,
+			},
+		}
+		contentAddressableStorage.EXPECT().Get(
+			gomock.Any(),
+			digest.MustNewDigest("", remoteexecution.DigestFunction_SHA1, "0474d2f48968a56da4de20718d8ac23aafd80709", 123),
+		).Return(buffer.NewProtoBufferFromProto(action, buffer.UserProvided))
+		toolInvocationID := &remoteexecution.RequestMetadata{
+			ToolInvocationId: p.toolInvocationID,
+		}
+		toolInvocationIDAny, err := anypb.New(toolInvocationID)
+		require.NoError(t, err)
+		initialSizeClassSelector := mock.NewMockSelector(ctrl)
+		actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), testutil.EqProto(t, action), nil).Return(
+			platform.MustNewKey("", platformForTesting),
+			[]invocation.Key{
+				invocation.MustNewKey(correlatedInvocationsIDAny),
+				invocation.MustNewKey(toolInvocationIDAny),
+			},
+			initialSizeClassSelector,
+			nil,
+		)
+		initialSizeClassLearner := mock.NewMockLearner(ctrl)
+		initialSizeClassSelector.EXPECT().Select([]uint32{0}).
+			Return(0, 30*time.Second, time.Minute, initialSizeClassLearner)
+		mockClock.EXPECT().Now().Return(time.Unix(1010+int64(i), 0))
+		timer1 := mock.NewMockTimer(ctrl)
+		mockClock.EXPECT().NewTimer(time.Minute).Return(timer1, nil)
+		uuidGenerator.EXPECT().Call().Return(uuid.Parse(p.operationName))
+
+		actionDigest := &remoteexecution.Digest{
+			Hash:      "0474d2f48968a56da4de20718d8ac23aafd80709",
+			SizeBytes: 123,
+		}
+		stream, err := executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
+			ActionDigest: actionDigest,
+		})
+		require.NoError(t, err)
+		update, err := stream.Recv()
+		require.NoError(t, err)
+		metadata, err := anypb.New(&remoteexecution.ExecuteOperationMetadata{
+			Stage:        remoteexecution.ExecutionStage_QUEUED,
+			ActionDigest: actionDigest,
+		})
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, &longrunningpb.Operation{
+			Name:     p.operationName,
+			Metadata: metadata,
+		}, update)
+
+		mockClock.EXPECT().Now().Return(time.Unix(1010+int64(i), 1)).Times(2)
+		timer1.EXPECT().Stop()
+		timer2 := mock.NewMockTimer(ctrl)
+		mockClock.EXPECT().NewTimer(time.Minute).Return(timer2, nil)
+		workerID := map[string]string{
+			"hostname": "worker123",
+			"thread":   strconv.FormatInt(int64(i), 10),
+		}
+		response, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+			WorkerId: workerID,
+			Platform: platformForTesting,
+			CurrentState: &remoteworker.CurrentState{
+				WorkerState: &remoteworker.CurrentState_Idle{
+					Idle: &emptypb.Empty{},
+				},
+			},
+		})
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
+			NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1020 + int64(i), Nanos: 1},
+			DesiredState: &remoteworker.DesiredState{
+				WorkerState: &remoteworker.DesiredState_Executing_{
+					Executing: &remoteworker.DesiredState_Executing{
+						DigestFunction: remoteexecution.DigestFunction_SHA1,
+						ActionDigest:   actionDigest,
+						Action: &remoteexecution.Action{
+							DoNotCache: true,
+							CommandDigest: &remoteexecution.Digest{
+								Hash:      "9b818e201c59f31954cb1e126cc67562ec545ab4",
+								SizeBytes: 456,
+							},
+							Timeout: &durationpb.Duration{Seconds: 60},
+						},
+						QueuedTimestamp: &timestamppb.Timestamp{Seconds: 1010 + int64(i)},
+					},
+				},
+			},
+		}, response)
+
+		update, err = stream.Recv()
+		require.NoError(t, err)
+		metadata, err = anypb.New(&remoteexecution.ExecuteOperationMetadata{
+			Stage:        remoteexecution.ExecutionStage_EXECUTING,
+			ActionDigest: actionDigest,
+		})
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, update, &longrunningpb.Operation{
+			Name:     p.operationName,
+			Metadata: metadata,
+		})
+
+		initialSizeClassLearner.EXPECT().Succeeded(time.Duration(0), []uint32{0})
+		mockClock.EXPECT().Now().Return(time.Unix(1010+int64(i), 2)).Times(3)
+		timer2.EXPECT().Stop()
+		response, err = buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+			WorkerId: workerID,
+			Platform: platformForTesting,
+			CurrentState: &remoteworker.CurrentState{
+				WorkerState: &remoteworker.CurrentState_Executing_{
+					Executing: &remoteworker.CurrentState_Executing{
+						ActionDigest: actionDigest,
+						ExecutionState: &remoteworker.CurrentState_Executing_Completed{
+							Completed: &remoteexecution.ExecuteResponse{
+								Result: &remoteexecution.ActionResult{},
+							},
+						},
+					},
+				},
+			},
+			PreferBeingIdle: true,
+		})
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
+			NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1010 + int64(i), Nanos: 2},
+			DesiredState: &remoteworker.DesiredState{
+				WorkerState: &remoteworker.DesiredState_Idle{
+					Idle: &emptypb.Empty{},
+				},
+			},
+		}, response)
+
+		update, err = stream.Recv()
+		require.NoError(t, err)
+		metadata, err = anypb.New(&remoteexecution.ExecuteOperationMetadata{
+			Stage:        remoteexecution.ExecutionStage_COMPLETED,
+			ActionDigest: actionDigest,
+		})
+		require.NoError(t, err)
+		executeResponse, err := anypb.New(&remoteexecution.ExecuteResponse{
+			Result: &remoteexecution.ActionResult{},
+		})
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, &longrunningpb.Operation{
+			Name:     p.operationName,
+			Metadata: metadata,
+			Done:     true,
+			Result:   &longrunningpb.Operation_Response{Response: executeResponse},
+		}, update)
+	}
+
+	// At the top level, we should see an invocation with ten children.
+	invocationName := &buildqueuestate.InvocationName{
+		SizeClassQueueName: &buildqueuestate.SizeClassQueueName{
+			PlatformQueueName: &buildqueuestate.PlatformQueueName{
+				Platform: platformForTesting,
+			},
+		},
+	}
+	mockClock.EXPECT().Now().Return(time.Unix(1030, 0))
+	invocationStates, err := buildQueue.ListInvocationChildren(ctx, &buildqueuestate.ListInvocationChildrenRequest{
+		InvocationName: invocationName,
+		Filter:         buildqueuestate.ListInvocationChildrenRequest_ALL,
+	})
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &buildqueuestate.ListInvocationChildrenResponse{
+		Children: []*buildqueuestate.InvocationChildState{
+			{
+				Id: correlatedInvocationsIDAny,
+				State: &buildqueuestate.InvocationState{
+					IdleWorkersCount: 10,
+					ChildrenCount:    10,
+				},
+			},
+		},
+	}, invocationStates)
+
+	// Let all ten workers perform a blocking Synchronize() call.
+	allWorkersWait := make(chan struct{})
+	timers := make([]*mock.MockTimer, 0, 10)
+	wakeups := make([]chan<- time.Time, 0, 10)
+	for iIter := 0; iIter < 10; iIter++ {
+		i := iIter
+		mockClock.EXPECT().Now().Return(time.Unix(1040+int64(i), 0))
+		timer := mock.NewMockTimer(ctrl)
+		wakeup := make(chan time.Time, 1)
+		timerCreationWait := make(chan struct{})
+		mockClock.EXPECT().NewTimer(time.Minute).DoAndReturn(func(d time.Duration) (clock.Timer, <-chan time.Time) {
+			timerCreationWait <- struct{}{}
+			return timer, wakeup
+		})
+
+		go func() {
+			response, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+				WorkerId: map[string]string{
+					"hostname": "worker123",
+					"thread":   strconv.FormatInt(int64(i), 10),
+				},
+				Platform: platformForTesting,
+				CurrentState: &remoteworker.CurrentState{
+					WorkerState: &remoteworker.CurrentState_Idle{
+						Idle: &emptypb.Empty{},
+					},
+				},
+			})
+			require.NoError(t, err)
+			testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
+				NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1100 + int64(i)},
 				DesiredState: &remoteworker.DesiredState{
 					WorkerState: &remoteworker.DesiredState_Idle{
 						Idle: &emptypb.Empty{},
 					},
 				},
-			}, response)
+			}, response)
+
+			allWorkersWait <- struct{}{}
+		}()
+
+		<-timerCreationWait
+		timers = append(timers, timer)
+		wakeups = append(wakeups, wakeup)
+	}
+
+	// Wake up all ten workers without receiving any new work. This
+	// should not cause any crashes.
+	for i := 0; i < 10; i++ {
+		timers[i].EXPECT().Stop()
+		wakeups[i] <- time.Unix(1100+int64(i), 0)
+		<-allWorkersWait
+	}
+}
+
+func TestInMemoryBuildQueueReportExpectedDurationToClients(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	contentAddressableStorage.EXPECT().Get(
+		gomock.Any(),
+		digest.MustNewDigest("main", remoteexecution.DigestFunction_SHA1, "da39a3ee5e6b4b0d3255bfef95601890afd80709", 123),
+	).Return(buffer.NewProtoBufferFromProto(&remoteexecution.Action{
+		CommandDigest: &remoteexecution.Digest{
+			Hash:      "61c585c297d00409bd477b6b80759c94ec545ab4",
+			SizeBytes: 456,
+		},
+		Timeout: &durationpb.Duration{Seconds: 1800},
+	}, buffer.UserProvided))
+	actionRouter := mock.NewMockActionRouter(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(0, 0))
+	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
+	configuration := buildQueueConfigurationForTesting
+	configuration.ReportExpectedDurationToClients = true
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &configuration, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
+	executionClient := getExecutionClient(t, buildQueue)
+
+	// Announce a new worker, which creates a queue for operations.
+	clock.EXPECT().Now().Return(time.Unix(1000, 0))
+	response, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{
+			"hostname": "worker123",
+			"thread":   "42",
+		},
+		InstanceNamePrefix: "main",
+		CurrentState: &remoteworker.CurrentState{
+			WorkerState: &remoteworker.CurrentState_Executing_{
+				Executing: &remoteworker.CurrentState_Executing{
+					ActionDigest: &remoteexecution.Digest{
+						Hash:      "099a3f6dc1e8e91dbcca4ea964cd2237d4b11733",
+						SizeBytes: 123,
+					},
+					ExecutionState: &remoteworker.CurrentState_Executing_FetchingInputs{
+						FetchingInputs: &emptypb.Empty{},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, response, &remoteworker.SynchronizeResponse{
+		NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1000},
+		DesiredState: &remoteworker.DesiredState{
+			WorkerState: &remoteworker.DesiredState_Idle{
+				Idle: &emptypb.Empty{},
+			},
+		},
+	})
+
+	// Let a client enqueue a new operation. The expected duration
+	// that is returned by the initial size class selector should
+	// be reported as part of the operation's metadata, together
+	// with the timeout of the action.
+	initialSizeClassSelector := mock.NewMockSelector(ctrl)
+	actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), gomock.Any(), nil).
+		Return(platform.MustNewKey("main", &remoteexecution.Platform{}), nil, initialSizeClassSelector, nil)
+	initialSizeClassLearner := mock.NewMockLearner(ctrl)
+	initialSizeClassSelector.EXPECT().Select([]uint32{0}).
+		Return(0, 15*time.Minute, 30*time.Minute, initialSizeClassLearner)
+	clock.EXPECT().Now().Return(time.Unix(1001, 0))
+	timer1 := mock.NewMockTimer(ctrl)
+	clock.EXPECT().NewTimer(time.Minute).Return(timer1, nil)
+	uuidGenerator.EXPECT().Call().Return(uuid.Parse("36ebab65-3c4f-4faf-818b-2eabb4cd1b02"))
+	stream1, err := executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
+		InstanceName: "main",
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+			SizeBytes: 123,
+		},
+	})
+	require.NoError(t, err)
+	update, err := stream1.Recv()
+	require.NoError(t, err)
+	predictedExecution, err := anypb.New(&buildqueuestate.PredictedExecution{
+		ExpectedDuration: &durationpb.Duration{Seconds: 900},
+		Timeout:          &durationpb.Duration{Seconds: 1800},
+	})
+	require.NoError(t, err)
+	metadata, err := anypb.New(&remoteexecution.ExecuteOperationMetadata{
+		Stage: remoteexecution.ExecutionStage_QUEUED,
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+			SizeBytes: 123,
+		},
+		PartialExecutionMetadata: &remoteexecution.ExecutedActionMetadata{
+			AuxiliaryMetadata: []*anypb.Any{predictedExecution},
+		},
+	})
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, update, &longrunningpb.Operation{
+		Name:     "36ebab65-3c4f-4faf-818b-2eabb4cd1b02",
+		Metadata: metadata,
+	})
+}
+
+func TestInMemoryBuildQueueMigrateQueuedOperations(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	contentAddressableStorage.EXPECT().Get(
+		gomock.Any(),
+		digest.MustNewDigest("main", remoteexecution.DigestFunction_SHA1, "da39a3ee5e6b4b0d3255bfef95601890afd80709", 123),
+	).Return(buffer.NewProtoBufferFromProto(&remoteexecution.Action{
+		CommandDigest: &remoteexecution.Digest{
+			Hash:      "61c585c297d00409bd477b6b80759c94ec545ab4",
+			SizeBytes: 456,
+		},
+	}, buffer.UserProvided))
+	actionRouter := mock.NewMockActionRouter(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(1000, 0)).AnyTimes()
+	timer := mock.NewMockTimer(ctrl)
+	clock.EXPECT().NewTimer(time.Minute).Return(timer, nil).AnyTimes()
+	timer.EXPECT().Stop().Return(true).AnyTimes()
+	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
+	configuration := buildQueueConfigurationForTesting
+	configuration.MigrateQueuedOperations = true
+	configuration.MigrationIgnoredPlatformPropertyNames = []string{"pool"}
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &configuration, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
+	executionClient := getExecutionClient(t, buildQueue)
+
+	// Announce workers for two pools that are equivalent, except
+	// for the "pool" platform property.
+	platformA := &remoteexecution.Platform{
+		Properties: []*remoteexecution.Platform_Property{
+			{Name: "os", Value: "linux"},
+			{Name: "pool", Value: "a"},
+		},
+	}
+	platformB := &remoteexecution.Platform{
+		Properties: []*remoteexecution.Platform_Property{
+			{Name: "os", Value: "linux"},
+			{Name: "pool", Value: "b"},
+		},
+	}
+	for _, worker := range []struct {
+		hostname string
+		platform *remoteexecution.Platform
+	}{
+		{"worker-a", platformA},
+		{"worker-b", platformB},
+	} {
+		response, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+			WorkerId: map[string]string{
+				"hostname": worker.hostname,
+			},
+			InstanceNamePrefix: "main",
+			Platform:           worker.platform,
+			CurrentState: &remoteworker.CurrentState{
+				WorkerState: &remoteworker.CurrentState_Executing_{
+					Executing: &remoteworker.CurrentState_Executing{
+						ActionDigest: &remoteexecution.Digest{
+							Hash:      "099a3f6dc1e8e91dbcca4ea964cd2237d4b11733",
+							SizeBytes: 123,
+						},
+						ExecutionState: &remoteworker.CurrentState_Executing_FetchingInputs{
+							FetchingInputs: &emptypb.Empty{},
+						},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
+			NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1000},
+			DesiredState: &remoteworker.DesiredState{
+				WorkerState: &remoteworker.DesiredState_Idle{
+					Idle: &emptypb.Empty{},
+				},
+			},
+		}, response)
+	}
+
+	// Let a client enqueue an operation in pool "a". As the worker
+	// in that pool isn't synchronizing, it should remain queued.
+	initialSizeClassSelector := mock.NewMockSelector(ctrl)
+	actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), gomock.Any(), nil).
+		Return(platform.MustNewKey("main", platformA), nil, initialSizeClassSelector, nil)
+	initialSizeClassLearner := mock.NewMockLearner(ctrl)
+	initialSizeClassSelector.EXPECT().Select([]uint32{0}).
+		Return(0, 15*time.Minute, 30*time.Minute, initialSizeClassLearner)
+	uuidGenerator.EXPECT().Call().Return(uuid.Parse("36ebab65-3c4f-4faf-818b-2eabb4cd1b02"))
+	stream, err := executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
+		InstanceName: "main",
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+			SizeBytes: 123,
+		},
+	})
+	require.NoError(t, err)
+	queuedMetadata, err := anypb.New(&remoteexecution.ExecuteOperationMetadata{
+		Stage: remoteexecution.ExecutionStage_QUEUED,
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+			SizeBytes: 123,
+		},
+	})
+	require.NoError(t, err)
+	update, err := stream.Recv()
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &longrunningpb.Operation{
+		Name:     "36ebab65-3c4f-4faf-818b-2eabb4cd1b02",
+		Metadata: queuedMetadata,
+	}, update)
+
+	// Draining the only worker in pool "a" should cause the
+	// operation to be migrated to pool "b". As the initial size
+	// class learner was created for pool "a", it is abandoned. The
+	// client should be informed that the operation is still queued.
+	initialSizeClassLearner.EXPECT().Abandoned()
+	_, err = buildQueue.AddDrain(ctx, &buildqueuestate.AddOrRemoveDrainRequest{
+		SizeClassQueueName: &buildqueuestate.SizeClassQueueName{
+			PlatformQueueName: &buildqueuestate.PlatformQueueName{
+				InstanceNamePrefix: "main",
+				Platform:           platformA,
+			},
+		},
+		WorkerIdPattern: map[string]string{
+			"hostname": "worker-a",
+		},
+	})
+	require.NoError(t, err)
+	update, err = stream.Recv()
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &longrunningpb.Operation{
+		Name:     "36ebab65-3c4f-4faf-818b-2eabb4cd1b02",
+		Metadata: queuedMetadata,
+	}, update)
+
+	// The worker in pool "b" should now pick up the operation.
+	response, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{
+			"hostname": "worker-b",
+		},
+		InstanceNamePrefix: "main",
+		Platform:           platformB,
+		CurrentState: &remoteworker.CurrentState{
+			WorkerState: &remoteworker.CurrentState_Idle{
+				Idle: &emptypb.Empty{},
+			},
+		},
+	})
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
+		NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1010},
+		DesiredState: &remoteworker.DesiredState{
+			WorkerState: &remoteworker.DesiredState_Executing_{
+				Executing: &remoteworker.DesiredState_Executing{
+					DigestFunction: remoteexecution.DigestFunction_SHA1,
+					ActionDigest: &remoteexecution.Digest{
+						Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+						SizeBytes: 123,
+					},
+					Action: &remoteexecution.Action{
+						CommandDigest: &remoteexecution.Digest{
+							Hash:      "61c585c297d00409bd477b6b80759c94ec545ab4",
+							SizeBytes: 456,
+						},
+						Timeout: &durationpb.Duration{Seconds: 1800},
+					},
+					QueuedTimestamp: &timestamppb.Timestamp{Seconds: 1000},
+				},
+			},
+		},
+	}, response)
+}
+
+func TestInMemoryBuildQueueReprioritizeOperation(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	for _, hash := range []string{
+		"da39a3ee5e6b4b0d3255bfef95601890afd80709",
+		"c2e0a7cbd8d1d4b4b5f7a1fe8f9e3b2e1ad0c4f7",
+	} {
+		contentAddressableStorage.EXPECT().Get(
+			gomock.Any(),
+			digest.MustNewDigest("main", remoteexecution.DigestFunction_SHA1, hash, 123),
+		).Return(buffer.NewProtoBufferFromProto(&remoteexecution.Action{
+			CommandDigest: &remoteexecution.Digest{
+				Hash:      "61c585c297d00409bd477b6b80759c94ec545ab4",
+				SizeBytes: 456,
+			},
+		}, buffer.UserProvided))
+	}
+	actionRouter := mock.NewMockActionRouter(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(1000, 0)).AnyTimes()
+	timer := mock.NewMockTimer(ctrl)
+	clock.EXPECT().NewTimer(time.Minute).Return(timer, nil).AnyTimes()
+	timer.EXPECT().Stop().Return(true).AnyTimes()
+	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
+	executionClient := getExecutionClient(t, buildQueue)
+
+	// Announce a new worker, which creates a queue for operations.
+	response, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{
+			"hostname": "worker123",
+		},
+		InstanceNamePrefix: "main",
+		Platform:           platformForTesting,
+		CurrentState: &remoteworker.CurrentState{
+			WorkerState: &remoteworker.CurrentState_Executing_{
+				Executing: &remoteworker.CurrentState_Executing{
+					ActionDigest: &remoteexecution.Digest{
+						Hash:      "099a3f6dc1e8e91dbcca4ea964cd2237d4b11733",
+						SizeBytes: 123,
+					},
+					ExecutionState: &remoteworker.CurrentState_Executing_FetchingInputs{
+						FetchingInputs: &emptypb.Empty{},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
+		NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1000},
+		DesiredState: &remoteworker.DesiredState{
+			WorkerState: &remoteworker.DesiredState_Idle{
+				Idle: &emptypb.Empty{},
+			},
+		},
+	}, response)
+
+	// Enqueue two operations within the same invocation, having
+	// priorities 0 and 10.
+	for _, operation := range []struct {
+		hash     string
+		name     string
+		priority int32
+	}{
+		{"da39a3ee5e6b4b0d3255bfef95601890afd80709", "36ebab65-3c4f-4faf-818b-2eabb4cd1b02", 0},
+		{"c2e0a7cbd8d1d4b4b5f7a1fe8f9e3b2e1ad0c4f7", "b4667823-9f8e-451d-a3e4-4481ec67329f", 10},
+	} {
+		initialSizeClassSelector := mock.NewMockSelector(ctrl)
+		actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), gomock.Any(), nil).
+			Return(platform.MustNewKey("main", platformForTesting), nil, initialSizeClassSelector, nil)
+		initialSizeClassLearner := mock.NewMockLearner(ctrl)
+		initialSizeClassSelector.EXPECT().Select([]uint32{0}).
+			Return(0, 15*time.Minute, 30*time.Minute, initialSizeClassLearner)
+		uuidGenerator.EXPECT().Call().Return(uuid.Parse(operation.name))
+		stream, err := executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
+			InstanceName: "main",
+			ActionDigest: &remoteexecution.Digest{
+				Hash:      operation.hash,
+				SizeBytes: 123,
+			},
+			ExecutionPolicy: &remoteexecution.ExecutionPolicy{
+				Priority: operation.priority,
+			},
+		})
+		require.NoError(t, err)
+		update, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, operation.name, update.Name)
+	}
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := buildQueue.ReprioritizeOperation(ctx, &buildqueuestate.ReprioritizeOperationRequest{
+			OperationName: "7a1c3ea6-1f8a-4b71-b2b4-6e8a2e5c4d3f",
+			Priority:      0,
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.NotFound, "Operation \"7a1c3ea6-1f8a-4b71-b2b4-6e8a2e5c4d3f\" not found"), err)
+	})
+
+	t.Run("OvertakingOtherInvocations", func(t *testing.T) {
+		// The second operation may not be given a priority that
+		// is higher than that of the first operation, as that
+		// would allow it to overtake other invocations.
+		_, err := buildQueue.ReprioritizeOperation(ctx, &buildqueuestate.ReprioritizeOperationRequest{
+			OperationName: "b4667823-9f8e-451d-a3e4-4481ec67329f",
+			Priority:      -1,
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.PermissionDenied, "Operation cannot be given a priority higher than 0, as that would cause it to overtake other invocations"), err)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		// Promote the second operation, while demoting the
+		// first operation. This should cause the second
+		// operation to be executed first.
+		_, err := buildQueue.ReprioritizeOperation(ctx, &buildqueuestate.ReprioritizeOperationRequest{
+			OperationName: "b4667823-9f8e-451d-a3e4-4481ec67329f",
+			Priority:      0,
+		})
+		require.NoError(t, err)
+		_, err = buildQueue.ReprioritizeOperation(ctx, &buildqueuestate.ReprioritizeOperationRequest{
+			OperationName: "36ebab65-3c4f-4faf-818b-2eabb4cd1b02",
+			Priority:      20,
+		})
+		require.NoError(t, err)
+
+		operationState, err := buildQueue.GetOperation(ctx, &buildqueuestate.GetOperationRequest{
+			OperationName: "36ebab65-3c4f-4faf-818b-2eabb4cd1b02",
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(20), operationState.Operation.Priority)
+
+		response, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+			WorkerId: map[string]string{
+				"hostname": "worker123",
+			},
+			InstanceNamePrefix: "main",
+			Platform:           platformForTesting,
+			CurrentState: &remoteworker.CurrentState{
+				WorkerState: &remoteworker.CurrentState_Idle{
+					Idle: &emptypb.Empty{},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "c2e0a7cbd8d1d4b4b5f7a1fe8f9e3b2e1ad0c4f7", response.DesiredState.GetExecuting().ActionDigest.Hash)
+	})
+
+	t.Run("NotQueued", func(t *testing.T) {
+		// Operations that are already executing can no longer
+		// be reprioritized.
+		_, err := buildQueue.ReprioritizeOperation(ctx, &buildqueuestate.ReprioritizeOperationRequest{
+			OperationName: "b4667823-9f8e-451d-a3e4-4481ec67329f",
+			Priority:      0,
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.FailedPrecondition, "Only operations in the QUEUED stage can be reprioritized"), err)
+	})
+}
+
+func TestInMemoryBuildQueueRetagOperation(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	for _, hash := range []string{
+		"da39a3ee5e6b4b0d3255bfef95601890afd80709",
+		"c2e0a7cbd8d1d4b4b5f7a1fe8f9e3b2e1ad0c4f7",
+		"4a0b2b6c9ec3a4f1d0e5f8b7a6c9d2e1f0a3b4c5",
+	} {
+		contentAddressableStorage.EXPECT().Get(
+			gomock.Any(),
+			digest.MustNewDigest("main", remoteexecution.DigestFunction_SHA1, hash, 123),
+		).Return(buffer.NewProtoBufferFromProto(&remoteexecution.Action{
+			CommandDigest: &remoteexecution.Digest{
+				Hash:      "61c585c297d00409bd477b6b80759c94ec545ab4",
+				SizeBytes: 456,
+			},
+		}, buffer.UserProvided))
+	}
+	actionRouter := mock.NewMockActionRouter(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(1000, 0)).AnyTimes()
+	timer := mock.NewMockTimer(ctrl)
+	clock.EXPECT().NewTimer(time.Minute).Return(timer, nil).AnyTimes()
+	timer.EXPECT().Stop().Return(true).AnyTimes()
+	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
+	executionClient := getExecutionClient(t, buildQueue)
+
+	// Announce a new worker, which creates a queue for operations.
+	_, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{
+			"hostname": "worker123",
+		},
+		InstanceNamePrefix: "main",
+		Platform:           platformForTesting,
+		CurrentState: &remoteworker.CurrentState{
+			WorkerState: &remoteworker.CurrentState_Executing_{
+				Executing: &remoteworker.CurrentState_Executing{
+					ActionDigest: &remoteexecution.Digest{
+						Hash:      "099a3f6dc1e8e91dbcca4ea964cd2237d4b11733",
+						SizeBytes: 123,
+					},
+					ExecutionState: &remoteworker.CurrentState_Executing_FetchingInputs{
+						FetchingInputs: &emptypb.Empty{},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
 
-			allWorkersWait <- struct{}{}
-		}()
+	invocationIDs := map[string]*anypb.Any{}
+	for _, toolInvocationID := range []string{"invocation1", "invocation2", "invocation3"} {
+		invocationID, err := anypb.New(&remoteexecution.RequestMetadata{
+			ToolInvocationId: toolInvocationID,
+		})
+		require.NoError(t, err)
+		invocationIDs[toolInvocationID] = invocationID
+	}
 
-		<-timerCreationWait
-		timers = append(timers, timer)
-		wakeups = append(wakeups, wakeup)
+	// Enqueue one operation in the first invocation, and two
+	// operations in the second invocation.
+	for _, operation := range []struct {
+		hash         string
+		name         string
+		invocationID string
+	}{
+		{"da39a3ee5e6b4b0d3255bfef95601890afd80709", "36ebab65-3c4f-4faf-818b-2eabb4cd1b02", "invocation1"},
+		{"c2e0a7cbd8d1d4b4b5f7a1fe8f9e3b2e1ad0c4f7", "b4667823-9f8e-451d-a3e4-4481ec67329f", "invocation2"},
+		{"4a0b2b6c9ec3a4f1d0e5f8b7a6c9d2e1f0a3b4c5", "0d1f0ab4-8d57-4c1e-9b57-3f0c2b8a9d61", "invocation2"},
+	} {
+		initialSizeClassSelector := mock.NewMockSelector(ctrl)
+		actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), gomock.Any(), nil).Return(
+			platform.MustNewKey("main", platformForTesting),
+			[]invocation.Key{invocation.MustNewKey(invocationIDs[operation.invocationID])},
+			initialSizeClassSelector,
+			nil,
+		)
+		initialSizeClassLearner := mock.NewMockLearner(ctrl)
+		initialSizeClassSelector.EXPECT().Select([]uint32{0}).
+			Return(0, 15*time.Minute, 30*time.Minute, initialSizeClassLearner)
+		uuidGenerator.EXPECT().Call().Return(uuid.Parse(operation.name))
+		stream, err := executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
+			InstanceName: "main",
+			ActionDigest: &remoteexecution.Digest{
+				Hash:      operation.hash,
+				SizeBytes: 123,
+			},
+		})
+		require.NoError(t, err)
+		update, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, operation.name, update.Name)
 	}
 
-	// Wake up all ten workers without receiving any new work. This
-	// should not cause any crashes.
-	for i := 0; i < 10; i++ {
-		timers[i].EXPECT().Stop()
-		wakeups[i] <- time.Unix(1100+int64(i), 0)
-		<-allWorkersWait
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := buildQueue.RetagOperation(ctx, &buildqueuestate.RetagOperationRequest{
+			OperationName: "7a1c3ea6-1f8a-4b71-b2b4-6e8a2e5c4d3f",
+			InvocationIds: []*anypb.Any{invocationIDs["invocation1"]},
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.NotFound, "Operation \"7a1c3ea6-1f8a-4b71-b2b4-6e8a2e5c4d3f\" not found"), err)
+	})
+
+	t.Run("DifferentDepth", func(t *testing.T) {
+		_, err := buildQueue.RetagOperation(ctx, &buildqueuestate.RetagOperationRequest{
+			OperationName: "0d1f0ab4-8d57-4c1e-9b57-3f0c2b8a9d61",
+			InvocationIds: []*anypb.Any{invocationIDs["invocation1"], invocationIDs["invocation2"]},
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "Operation belongs to an invocation with 1 IDs, while 2 IDs were provided"), err)
+	})
+
+	t.Run("InactiveInvocation", func(t *testing.T) {
+		// Moving operations into new invocations is not
+		// permitted, as that would allow clients to obtain a
+		// larger share of workers.
+		_, err := buildQueue.RetagOperation(ctx, &buildqueuestate.RetagOperationRequest{
+			OperationName: "0d1f0ab4-8d57-4c1e-9b57-3f0c2b8a9d61",
+			InvocationIds: []*anypb.Any{invocationIDs["invocation3"]},
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.PermissionDenied, "Operations can only be moved to invocations that already have one or more queued or executing operations"), err)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		// Move both operations of the second invocation into
+		// the first invocation. This should cause the second
+		// invocation to be removed.
+		for _, operationName := range []string{
+			"0d1f0ab4-8d57-4c1e-9b57-3f0c2b8a9d61",
+			"b4667823-9f8e-451d-a3e4-4481ec67329f",
+		} {
+			_, err := buildQueue.RetagOperation(ctx, &buildqueuestate.RetagOperationRequest{
+				OperationName: operationName,
+				InvocationIds: []*anypb.Any{invocationIDs["invocation1"]},
+			})
+			require.NoError(t, err)
+
+			operationState, err := buildQueue.GetOperation(ctx, &buildqueuestate.GetOperationRequest{
+				OperationName: operationName,
+			})
+			require.NoError(t, err)
+			testutil.RequireEqualProto(t, invocationIDs["invocation1"], operationState.Operation.InvocationName.Ids[0])
+		}
+
+		// As the second invocation no longer exists, operations
+		// can no longer be moved into it.
+		_, err := buildQueue.RetagOperation(ctx, &buildqueuestate.RetagOperationRequest{
+			OperationName: "36ebab65-3c4f-4faf-818b-2eabb4cd1b02",
+			InvocationIds: []*anypb.Any{invocationIDs["invocation2"]},
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.PermissionDenied, "Operations can only be moved to invocations that already have one or more queued or executing operations"), err)
+	})
+
+	t.Run("NotQueued", func(t *testing.T) {
+		// Operations that are already executing can no longer
+		// be retagged.
+		response, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+			WorkerId: map[string]string{
+				"hostname": "worker123",
+			},
+			InstanceNamePrefix: "main",
+			Platform:           platformForTesting,
+			CurrentState: &remoteworker.CurrentState{
+				WorkerState: &remoteworker.CurrentState_Idle{
+					Idle: &emptypb.Empty{},
+				},
+			},
+		})
+		require.NoError(t, err)
+		operationName, ok := map[string]string{
+			"da39a3ee5e6b4b0d3255bfef95601890afd80709": "36ebab65-3c4f-4faf-818b-2eabb4cd1b02",
+			"c2e0a7cbd8d1d4b4b5f7a1fe8f9e3b2e1ad0c4f7": "b4667823-9f8e-451d-a3e4-4481ec67329f",
+			"4a0b2b6c9ec3a4f1d0e5f8b7a6c9d2e1f0a3b4c5": "0d1f0ab4-8d57-4c1e-9b57-3f0c2b8a9d61",
+		}[response.DesiredState.GetExecuting().ActionDigest.Hash]
+		require.True(t, ok)
+
+		_, err = buildQueue.RetagOperation(ctx, &buildqueuestate.RetagOperationRequest{
+			OperationName: operationName,
+			InvocationIds: []*anypb.Any{invocationIDs["invocation1"]},
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.FailedPrecondition, "Only operations in the QUEUED stage can be retagged"), err)
+	})
+}
+
+func TestInMemoryBuildQueuePlatformPropertyMerging(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Unix(0, 0))
+	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
+	actionRouter := mock.NewMockActionRouter(ctrl)
+	buildQueue := scheduler.NewInMemoryBuildQueue(contentAddressableStorage, clock, uuidGenerator.Call, &buildQueueConfigurationForTesting, 10000, actionRouter, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, allowAllAuthorizer, denyAllAuthorizer)
+	executionClient := getExecutionClient(t, buildQueue)
+
+	// Register a platform queue that provides a default container
+	// image.
+	clock.EXPECT().Now().Return(time.Unix(1000, 0))
+	require.NoError(t, buildQueue.RegisterPredeclaredPlatformQueue(
+		digest.MustNewInstanceName("main"),
+		platformForTesting,
+		/* workerInvocationStickinessLimits = */ nil,
+		/* maximumQueuedBackgroundLearningOperations = */ 0,
+		/* backgroundLearningOperationPriority = */ 0,
+		/* backgroundLearningOnIdleWorkersOnly = */ false,
+		platform.NewPropertyMerger(
+			[]*remoteexecution.Platform_Property{
+				{Name: "container-image", Value: "docker://ubuntu:22.04"},
+			},
+			nil),
+		/* maximumSizeClass = */ 0))
+
+	clock.EXPECT().Now().Return(time.Unix(1000, 0))
+	response, err := buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{
+			"hostname": "worker123",
+			"thread":   "42",
+		},
+		InstanceNamePrefix: "main",
+		Platform:           platformForTesting,
+		CurrentState: &remoteworker.CurrentState{
+			WorkerState: &remoteworker.CurrentState_Executing_{
+				Executing: &remoteworker.CurrentState_Executing{
+					ActionDigest: &remoteexecution.Digest{
+						Hash:      "099a3f6dc1e8e91dbcca4ea964cd2237d4b11733",
+						SizeBytes: 123,
+					},
+					ExecutionState: &remoteworker.CurrentState_Executing_FetchingInputs{
+						FetchingInputs: &emptypb.Empty{},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
+		NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1000},
+		DesiredState: &remoteworker.DesiredState{
+			WorkerState: &remoteworker.DesiredState_Idle{
+				Idle: &emptypb.Empty{},
+			},
+		},
+	}, response)
+
+	// Let a client enqueue an operation. As the default container
+	// image is merged into the action, the resulting action should
+	// be stored in the Content Addressable Storage.
+	contentAddressableStorage.EXPECT().Get(
+		gomock.Any(),
+		digest.MustNewDigest("main", remoteexecution.DigestFunction_SHA1, "da39a3ee5e6b4b0d3255bfef95601890afd80709", 123),
+	).Return(buffer.NewProtoBufferFromProto(&remoteexecution.Action{
+		CommandDigest: &remoteexecution.Digest{
+			Hash:      "61c585c297d00409bd477b6b80759c94ec545ab4",
+			SizeBytes: 456,
+		},
+		Platform: platformForTesting,
+	}, buffer.UserProvided))
+	initialSizeClassSelector := mock.NewMockSelector(ctrl)
+	actionRouter.EXPECT().RouteAction(gomock.Any(), gomock.Any(), gomock.Any(), nil).
+		Return(platform.MustNewKey("main", platformForTesting), nil, initialSizeClassSelector, nil)
+	mergedAction := &remoteexecution.Action{
+		CommandDigest: &remoteexecution.Digest{
+			Hash:      "61c585c297d00409bd477b6b80759c94ec545ab4",
+			SizeBytes: 456,
+		},
+		Platform: &remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "container-image", Value: "docker://ubuntu:22.04"},
+				{Name: "cpu", Value: "armv6"},
+				{Name: "os", Value: "linux"},
+			},
+		},
 	}
+	var mergedActionDigest digest.Digest
+	contentAddressableStorage.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, blobDigest digest.Digest, b buffer.Buffer) error {
+			m, err := b.ToProto(&remoteexecution.Action{}, 10000)
+			require.NoError(t, err)
+			testutil.RequireEqualProto(t, mergedAction, m)
+			mergedActionDigest = blobDigest
+			return nil
+		})
+	initialSizeClassLearner := mock.NewMockLearner(ctrl)
+	initialSizeClassSelector.EXPECT().Select([]uint32{0}).
+		Return(0, 15*time.Minute, 30*time.Minute, initialSizeClassLearner)
+	clock.EXPECT().Now().Return(time.Unix(1001, 0))
+	timer1 := mock.NewMockTimer(ctrl)
+	clock.EXPECT().NewTimer(time.Minute).Return(timer1, nil)
+	uuidGenerator.EXPECT().Call().Return(uuid.Parse("36ebab65-3c4f-4faf-818b-2eabb4cd1b02"))
+	stream1, err := executionClient.Execute(ctx, &remoteexecution.ExecuteRequest{
+		InstanceName: "main",
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+			SizeBytes: 123,
+		},
+	})
+	require.NoError(t, err)
+	_, err = stream1.Recv()
+	require.NoError(t, err)
+	require.Equal(t, digest.MustNewDigest("main", remoteexecution.DigestFunction_SHA1, "62cc11d1f59578474fd9bcdd9a39774b8f40b5df", 118), mergedActionDigest)
+
+	// The worker should be instructed to execute the merged action
+	// under its own digest. The original action digest should be
+	// provided as part of the audit trail.
+	clock.EXPECT().Now().Return(time.Unix(1002, 0))
+	timer1.EXPECT().Stop().Return(true)
+	clock.EXPECT().Now().Return(time.Unix(1002, 0))
+	timer2 := mock.NewMockTimer(ctrl)
+	clock.EXPECT().NewTimer(time.Minute).Return(timer2, nil)
+	response, err = buildQueue.Synchronize(ctx, &remoteworker.SynchronizeRequest{
+		WorkerId: map[string]string{
+			"hostname": "worker123",
+			"thread":   "42",
+		},
+		InstanceNamePrefix: "main",
+		Platform:           platformForTesting,
+		CurrentState: &remoteworker.CurrentState{
+			WorkerState: &remoteworker.CurrentState_Idle{
+				Idle: &emptypb.Empty{},
+			},
+		},
+	})
+	require.NoError(t, err)
+	appliedPlatformProperties, err := anypb.New(&buildqueuestate.AppliedPlatformProperties{
+		Added: []*remoteexecution.Platform_Property{
+			{Name: "container-image", Value: "docker://ubuntu:22.04"},
+		},
+		OriginalActionDigest: &remoteexecution.Digest{
+			Hash:      "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+			SizeBytes: 123,
+		},
+	})
+	require.NoError(t, err)
+	testutil.RequireEqualProto(t, &remoteworker.SynchronizeResponse{
+		NextSynchronizationAt: &timestamppb.Timestamp{Seconds: 1012},
+		DesiredState: &remoteworker.DesiredState{
+			WorkerState: &remoteworker.DesiredState_Executing_{
+				Executing: &remoteworker.DesiredState_Executing{
+					DigestFunction: remoteexecution.DigestFunction_SHA1,
+					ActionDigest:   mergedActionDigest.GetProto(),
+					Action: &remoteexecution.Action{
+						CommandDigest: &remoteexecution.Digest{
+							Hash:      "61c585c297d00409bd477b6b80759c94ec545ab4",
+							SizeBytes: 456,
+						},
+						Platform: mergedAction.Platform,
+						Timeout:  &durationpb.Duration{Seconds: 1800},
+					},
+					QueuedTimestamp:   &timestamppb.Timestamp{Seconds: 1001},
+					AuxiliaryMetadata: []*anypb.Any{appliedPlatformProperties},
+				},
+			},
+		},
+	}, response)
+	_, err = stream1.Recv()
+	require.NoError(t, err)
 }
@@ -82,3 +82,12 @@ func (largestFallbackLearner) Failed(timedOut bool) (time.Duration, time.Duratio
 	// Action failed on the largest size class.
 	return 0, 0, nil
 }
+
+// NewLargestSizeClassLearner creates a Learner for actions that are run
+// on the largest size class, and from which nothing needs to be
+// learned. Failures are considered to be definitive. This can be used
+// by the scheduler in case actions are moved to platform queues for
+// which no initial size class analysis was performed.
+func NewLargestSizeClassLearner() Learner {
+	return largestFallbackLearner{}
+}
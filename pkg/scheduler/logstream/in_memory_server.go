@@ -0,0 +1,289 @@
+package logstream
+
+import (
+	"container/list"
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	remotelogstream "github.com/bazelbuild/remote-apis/build/bazel/remote/logstream/v1"
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/google/uuid"
+
+	"google.golang.org/genproto/googleapis/bytestream"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// readChunkSizeBytes is the maximum amount of data that is returned
+// as part of a single ReadResponse.
+const readChunkSizeBytes = 64 * 1024
+
+// Server implements both the LogStream service and the ByteStream
+// service of the Remote Execution API. LogStreams can be created by
+// calling CreateLogStream(), after which they can be written and read
+// through the ByteStream service.
+type Server interface {
+	remotelogstream.LogStreamServiceServer
+	bytestream.ByteStreamServer
+}
+
+type inMemoryLogStream struct {
+	name              string
+	writeResourceName string
+	data              []byte
+	finalized         bool
+	lastWriteTime     time.Time
+
+	// Channel that is closed when data is appended to the
+	// LogStream, or when it is finalized. This allows readers to
+	// wait for more data to become available.
+	wakeup chan struct{}
+
+	// Position in the list of LogStreams, ordered by the time at
+	// which they were last written.
+	element *list.Element
+}
+
+type inMemoryServer struct {
+	clock                     clock.Clock
+	uuidGenerator             util.UUIDGenerator
+	maximumSizeBytesPerStream int
+	maximumTotalSizeBytes     int
+	retention                 time.Duration
+	maximumStreams            int
+
+	lock               sync.Mutex
+	streamsByName      map[string]*inMemoryLogStream
+	streamsByWriteName map[string]*inMemoryLogStream
+	streamsByLastWrite list.List
+	totalSizeBytes     int
+}
+
+// NewInMemoryServer creates a Server that keeps the contents of all
+// LogStreams in memory. LogStreams are discarded once they have not
+// been written to for a given amount of time. This permits clients to
+// read the output of an action while it is still running, without
+// requiring the deployment of a separate log server.
+//
+// The number of LogStreams that may exist at any given time is
+// limited, so that clients cannot exhaust memory by creating
+// LogStreams without writing to them.
+//
+// Names of LogStreams have the form "${parent}/logstreams/${uuid}".
+// The resource name that needs to be used to write to a LogStream is
+// only returned to the creator of the LogStream, and contains an
+// additional randomly generated component.
+func NewInMemoryServer(clock clock.Clock, uuidGenerator util.UUIDGenerator, maximumSizeBytesPerStream, maximumTotalSizeBytes int, retention time.Duration, maximumStreams int) Server {
+	return &inMemoryServer{
+		clock:                     clock,
+		uuidGenerator:             uuidGenerator,
+		maximumSizeBytesPerStream: maximumSizeBytesPerStream,
+		maximumTotalSizeBytes:     maximumTotalSizeBytes,
+		retention:                 retention,
+		maximumStreams:            maximumStreams,
+		streamsByName:             map[string]*inMemoryLogStream{},
+		streamsByWriteName:        map[string]*inMemoryLogStream{},
+	}
+}
+
+// removeExpiredLocked removes LogStreams that have not been written to
+// for longer than the retention period.
+func (s *inMemoryServer) removeExpiredLocked() {
+	now := s.clock.Now()
+	for element := s.streamsByLastWrite.Front(); element != nil; element = s.streamsByLastWrite.Front() {
+		ls := element.Value.(*inMemoryLogStream)
+		if now.Sub(ls.lastWriteTime) < s.retention {
+			break
+		}
+		s.streamsByLastWrite.Remove(element)
+		delete(s.streamsByName, ls.name)
+		delete(s.streamsByWriteName, ls.writeResourceName)
+		s.totalSizeBytes -= len(ls.data)
+
+		// Wake up readers that are still waiting, so that they
+		// terminate.
+		if !ls.finalized {
+			ls.finalized = true
+			close(ls.wakeup)
+		}
+	}
+}
+
+func (s *inMemoryServer) CreateLogStream(ctx context.Context, request *remotelogstream.CreateLogStreamRequest) (*remotelogstream.LogStream, error) {
+	if request.Parent == "" {
+		return nil, status.Error(codes.InvalidArgument, "No parent provided")
+	}
+	name := request.Parent + "/logstreams/" + uuid.Must(s.uuidGenerator()).String()
+	writeResourceName := name + "/" + uuid.Must(s.uuidGenerator()).String()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.removeExpiredLocked()
+	if len(s.streamsByName) >= s.maximumStreams {
+		return nil, status.Errorf(codes.ResourceExhausted, "Cannot create more than %d log streams", s.maximumStreams)
+	}
+	ls := &inMemoryLogStream{
+		name:              name,
+		writeResourceName: writeResourceName,
+		lastWriteTime:     s.clock.Now(),
+		wakeup:            make(chan struct{}),
+	}
+	ls.element = s.streamsByLastWrite.PushBack(ls)
+	s.streamsByName[name] = ls
+	s.streamsByWriteName[writeResourceName] = ls
+	return &remotelogstream.LogStream{
+		Name:              name,
+		WriteResourceName: writeResourceName,
+	}, nil
+}
+
+func (s *inMemoryServer) Read(in *bytestream.ReadRequest, out bytestream.ByteStream_ReadServer) error {
+	if in.ReadOffset < 0 {
+		return status.Errorf(codes.OutOfRange, "Negative read offset: %d", in.ReadOffset)
+	}
+	if in.ReadLimit < 0 {
+		return status.Errorf(codes.InvalidArgument, "Negative read limit: %d", in.ReadLimit)
+	}
+	offset := int(in.ReadOffset)
+	remaining := int(in.ReadLimit)
+	ctx := out.Context()
+	for {
+		s.lock.Lock()
+		s.removeExpiredLocked()
+		ls, ok := s.streamsByName[in.ResourceName]
+		if !ok {
+			s.lock.Unlock()
+			return status.Errorf(codes.NotFound, "Log stream %#v does not exist", in.ResourceName)
+		}
+		if offset > len(ls.data) {
+			s.lock.Unlock()
+			return status.Errorf(codes.OutOfRange, "Read offset %d exceeds log stream size %d", offset, len(ls.data))
+		}
+
+		// Data is only ever appended to LogStreams, meaning
+		// that it is safe to access the slice after dropping
+		// the lock.
+		chunk := ls.data[offset:]
+		if len(chunk) > readChunkSizeBytes {
+			chunk = chunk[:readChunkSizeBytes]
+		}
+		if in.ReadLimit > 0 && len(chunk) > remaining {
+			chunk = chunk[:remaining]
+		}
+		finalized := ls.finalized
+		wakeup := ls.wakeup
+		s.lock.Unlock()
+
+		if len(chunk) > 0 {
+			if err := out.Send(&bytestream.ReadResponse{Data: chunk}); err != nil {
+				return err
+			}
+			offset += len(chunk)
+			if in.ReadLimit > 0 {
+				remaining -= len(chunk)
+				if remaining == 0 {
+					return nil
+				}
+			}
+			continue
+		}
+		if finalized {
+			return nil
+		}
+
+		// Wait for more data to be written.
+		select {
+		case <-wakeup:
+		case <-ctx.Done():
+			return util.StatusFromContext(ctx)
+		}
+	}
+}
+
+func (s *inMemoryServer) Write(out bytestream.ByteStream_WriteServer) error {
+	var ls *inMemoryLogStream
+	for {
+		request, err := out.Recv()
+		if err == io.EOF {
+			if ls == nil {
+				return status.Error(codes.InvalidArgument, "Client closed stream without sending any requests")
+			}
+			s.lock.Lock()
+			committedSize := len(ls.data)
+			s.lock.Unlock()
+			return out.SendAndClose(&bytestream.WriteResponse{
+				CommittedSize: int64(committedSize),
+			})
+		} else if err != nil {
+			return err
+		}
+
+		s.lock.Lock()
+		if ls == nil {
+			s.removeExpiredLocked()
+			var ok bool
+			ls, ok = s.streamsByWriteName[request.ResourceName]
+			if !ok {
+				s.lock.Unlock()
+				return status.Errorf(codes.NotFound, "Log stream with write resource name %#v does not exist", request.ResourceName)
+			}
+		} else if request.ResourceName != "" && request.ResourceName != ls.writeResourceName {
+			s.lock.Unlock()
+			return status.Error(codes.InvalidArgument, "Resource name changed while writing")
+		}
+		if ls.finalized {
+			s.lock.Unlock()
+			return status.Errorf(codes.FailedPrecondition, "Log stream %#v has already been finalized", ls.name)
+		}
+		if request.WriteOffset != int64(len(ls.data)) {
+			s.lock.Unlock()
+			return status.Errorf(codes.InvalidArgument, "Write offset %d does not match committed size %d", request.WriteOffset, len(ls.data))
+		}
+		if len(ls.data)+len(request.Data) > s.maximumSizeBytesPerStream {
+			s.lock.Unlock()
+			return status.Errorf(codes.ResourceExhausted, "Log stream would exceed the maximum size of %d bytes", s.maximumSizeBytesPerStream)
+		}
+		if s.totalSizeBytes+len(request.Data) > s.maximumTotalSizeBytes {
+			s.lock.Unlock()
+			return status.Errorf(codes.ResourceExhausted, "Log streams would exceed the maximum total size of %d bytes", s.maximumTotalSizeBytes)
+		}
+
+		ls.data = append(ls.data, request.Data...)
+		s.totalSizeBytes += len(request.Data)
+		ls.lastWriteTime = s.clock.Now()
+		s.streamsByLastWrite.MoveToBack(ls.element)
+		close(ls.wakeup)
+		if request.FinishWrite {
+			ls.finalized = true
+		} else {
+			ls.wakeup = make(chan struct{})
+		}
+		committedSize := len(ls.data)
+		s.lock.Unlock()
+
+		if request.FinishWrite {
+			return out.SendAndClose(&bytestream.WriteResponse{
+				CommittedSize: int64(committedSize),
+			})
+		}
+	}
+}
+
+func (s *inMemoryServer) QueryWriteStatus(ctx context.Context, request *bytestream.QueryWriteStatusRequest) (*bytestream.QueryWriteStatusResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.removeExpiredLocked()
+	ls, ok := s.streamsByWriteName[request.ResourceName]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "Log stream with write resource name %#v does not exist", request.ResourceName)
+	}
+	return &bytestream.QueryWriteStatusResponse{
+		CommittedSize: int64(len(ls.data)),
+		Complete:      ls.finalized,
+	}, nil
+}
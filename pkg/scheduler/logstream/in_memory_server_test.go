@@ -0,0 +1,217 @@
+package logstream_test
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	remotelogstream "github.com/bazelbuild/remote-apis/build/bazel/remote/logstream/v1"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/logstream"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/genproto/googleapis/bytestream"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestInMemoryServer(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	clock := mock.NewMockClock(ctrl)
+	now := time.Unix(1000, 0)
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	uuidGenerator := mock.NewMockUUIDGenerator(ctrl)
+	server := logstream.NewInMemoryServer(clock, uuidGenerator.Call, 10, 15, time.Minute, 4)
+
+	createLogStream := func(readUUID, writeUUID string) *remotelogstream.LogStream {
+		uuidGenerator.EXPECT().Call().Return(uuid.Parse(readUUID))
+		uuidGenerator.EXPECT().Call().Return(uuid.Parse(writeUUID))
+		logStream, err := server.CreateLogStream(ctx, &remotelogstream.CreateLogStreamRequest{
+			Parent: "hello/operations/b9e1b1d4-6b4f-4bd8-a7c3-4d7e3c36b1b5",
+		})
+		require.NoError(t, err)
+		return logStream
+	}
+
+	t.Run("NoParent", func(t *testing.T) {
+		_, err := server.CreateLogStream(ctx, &remotelogstream.CreateLogStreamRequest{})
+		testutil.RequireEqualStatus(t, status.Error(codes.InvalidArgument, "No parent provided"), err)
+	})
+
+	t.Run("WriteAndRead", func(t *testing.T) {
+		logStream := createLogStream("30bd1d6f-e1f4-4f2f-8b7c-dbb5a4a0e2b5", "e7d4c6d3-3b43-4c4a-9e6c-4b6c2b0c8f58")
+		testutil.RequireEqualProto(t, &remotelogstream.LogStream{
+			Name:              "hello/operations/b9e1b1d4-6b4f-4bd8-a7c3-4d7e3c36b1b5/logstreams/30bd1d6f-e1f4-4f2f-8b7c-dbb5a4a0e2b5",
+			WriteResourceName: "hello/operations/b9e1b1d4-6b4f-4bd8-a7c3-4d7e3c36b1b5/logstreams/30bd1d6f-e1f4-4f2f-8b7c-dbb5a4a0e2b5/e7d4c6d3-3b43-4c4a-9e6c-4b6c2b0c8f58",
+		}, logStream)
+
+		// Write data to the LogStream in two separate calls.
+		writeServer := mock.NewMockByteStream_WriteServer(ctrl)
+		writeServer.EXPECT().Recv().Return(&bytestream.WriteRequest{
+			ResourceName: logStream.WriteResourceName,
+			Data:         []byte("Hello"),
+		}, nil)
+		writeServer.EXPECT().Recv().Return(nil, io.EOF)
+		writeServer.EXPECT().SendAndClose(testutil.EqProto(t, &bytestream.WriteResponse{CommittedSize: 5}))
+		require.NoError(t, server.Write(writeServer))
+
+		queryWriteStatus, err := server.QueryWriteStatus(ctx, &bytestream.QueryWriteStatusRequest{
+			ResourceName: logStream.WriteResourceName,
+		})
+		require.NoError(t, err)
+		testutil.RequireEqualProto(t, &bytestream.QueryWriteStatusResponse{CommittedSize: 5}, queryWriteStatus)
+
+		writeServer = mock.NewMockByteStream_WriteServer(ctrl)
+		writeServer.EXPECT().Recv().Return(&bytestream.WriteRequest{
+			ResourceName: logStream.WriteResourceName,
+			WriteOffset:  5,
+			Data:         []byte("World"),
+			FinishWrite:  true,
+		}, nil)
+		writeServer.EXPECT().SendAndClose(testutil.EqProto(t, &bytestream.WriteResponse{CommittedSize: 10}))
+		require.NoError(t, server.Write(writeServer))
+
+		// Reads should be able to resume at an arbitrary offset.
+		readServer := mock.NewMockByteStream_ReadServer(ctrl)
+		readServer.EXPECT().Context().Return(ctx)
+		readServer.EXPECT().Send(testutil.EqProto(t, &bytestream.ReadResponse{Data: []byte("loWor")}))
+		require.NoError(t, server.Read(&bytestream.ReadRequest{
+			ResourceName: logStream.Name,
+			ReadOffset:   3,
+			ReadLimit:    5,
+		}, readServer))
+
+		readServer = mock.NewMockByteStream_ReadServer(ctrl)
+		readServer.EXPECT().Context().Return(ctx)
+		readServer.EXPECT().Send(testutil.EqProto(t, &bytestream.ReadResponse{Data: []byte("World")}))
+		require.NoError(t, server.Read(&bytestream.ReadRequest{
+			ResourceName: logStream.Name,
+			ReadOffset:   5,
+		}, readServer))
+
+		// Reading past the end of the LogStream is not permitted.
+		readServer = mock.NewMockByteStream_ReadServer(ctrl)
+		readServer.EXPECT().Context().Return(ctx)
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.OutOfRange, "Read offset 11 exceeds log stream size 10"),
+			server.Read(&bytestream.ReadRequest{
+				ResourceName: logStream.Name,
+				ReadOffset:   11,
+			}, readServer))
+
+		// No further writes may occur after finalization.
+		writeServer = mock.NewMockByteStream_WriteServer(ctrl)
+		writeServer.EXPECT().Recv().Return(&bytestream.WriteRequest{
+			ResourceName: logStream.WriteResourceName,
+			WriteOffset:  10,
+			Data:         []byte("!"),
+		}, nil)
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.FailedPrecondition, "Log stream \"hello/operations/b9e1b1d4-6b4f-4bd8-a7c3-4d7e3c36b1b5/logstreams/30bd1d6f-e1f4-4f2f-8b7c-dbb5a4a0e2b5\" has already been finalized"),
+			server.Write(writeServer))
+	})
+
+	t.Run("ReadWaitsForData", func(t *testing.T) {
+		// Readers that have consumed all data should block until
+		// more data is written.
+		logStream := createLogStream("4a6b1f5c-9a43-4e5e-9b4c-8d1f9b7f3e11", "6f0c1d8e-2b6a-4c3f-8a1e-0f7e5d4c3b2a")
+
+		readServer := mock.NewMockByteStream_ReadServer(ctrl)
+		readServer.EXPECT().Context().Return(ctx)
+		readServer.EXPECT().Send(testutil.EqProto(t, &bytestream.ReadResponse{Data: []byte("Hello")}))
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			require.NoError(t, server.Read(&bytestream.ReadRequest{
+				ResourceName: logStream.Name,
+			}, readServer))
+			wg.Done()
+		}()
+
+		writeServer := mock.NewMockByteStream_WriteServer(ctrl)
+		writeServer.EXPECT().Recv().Return(&bytestream.WriteRequest{
+			ResourceName: logStream.WriteResourceName,
+			Data:         []byte("Hello"),
+			FinishWrite:  true,
+		}, nil)
+		writeServer.EXPECT().SendAndClose(testutil.EqProto(t, &bytestream.WriteResponse{CommittedSize: 5}))
+		require.NoError(t, server.Write(writeServer))
+		wg.Wait()
+	})
+
+	t.Run("InvalidWriteOffset", func(t *testing.T) {
+		logStream := createLogStream("8e1f2d3c-4b5a-4e6f-9d8c-7b6a5f4e3d2c", "1a2b3c4d-5e6f-4a8b-9c0d-1e2f3a4b5c6d")
+
+		writeServer := mock.NewMockByteStream_WriteServer(ctrl)
+		writeServer.EXPECT().Recv().Return(&bytestream.WriteRequest{
+			ResourceName: logStream.WriteResourceName,
+			WriteOffset:  3,
+			Data:         []byte("Hello"),
+		}, nil)
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.InvalidArgument, "Write offset 3 does not match committed size 0"),
+			server.Write(writeServer))
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		logStream := createLogStream("9f8e7d6c-5b4a-4392-8170-6f5e4d3c2b1a", "0a9b8c7d-6e5f-4a3b-8c1d-0e9f8a7b6c5d")
+
+		writeServer := mock.NewMockByteStream_WriteServer(ctrl)
+		writeServer.EXPECT().Recv().Return(&bytestream.WriteRequest{
+			ResourceName: logStream.WriteResourceName,
+			Data:         []byte("Hello World"),
+		}, nil)
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.ResourceExhausted, "Log stream would exceed the maximum size of 10 bytes"),
+			server.Write(writeServer))
+
+		// The total size of all LogStreams is limited as well.
+		// 15 bytes are already in use by the LogStreams created
+		// previously.
+		writeServer = mock.NewMockByteStream_WriteServer(ctrl)
+		writeServer.EXPECT().Recv().Return(&bytestream.WriteRequest{
+			ResourceName: logStream.WriteResourceName,
+			Data:         []byte("Hello!"),
+		}, nil)
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.ResourceExhausted, "Log streams would exceed the maximum total size of 15 bytes"),
+			server.Write(writeServer))
+	})
+
+	t.Run("TooManyStreams", func(t *testing.T) {
+		// Four LogStreams have been created previously, which
+		// is the maximum permitted.
+		uuidGenerator.EXPECT().Call().Return(uuid.Parse("2b3c4d5e-6f7a-4b8c-9d0e-1f2a3b4c5d6e"))
+		uuidGenerator.EXPECT().Call().Return(uuid.Parse("3c4d5e6f-7a8b-4c9d-8e1f-2a3b4c5d6e7f"))
+		_, err := server.CreateLogStream(ctx, &remotelogstream.CreateLogStreamRequest{
+			Parent: "hello/operations/b9e1b1d4-6b4f-4bd8-a7c3-4d7e3c36b1b5",
+		})
+		testutil.RequireEqualStatus(t, status.Error(codes.ResourceExhausted, "Cannot create more than 4 log streams"), err)
+	})
+
+	t.Run("Expiration", func(t *testing.T) {
+		// LogStreams that have not been written to for the
+		// retention period should be removed.
+		now = now.Add(time.Minute)
+
+		readServer := mock.NewMockByteStream_ReadServer(ctrl)
+		readServer.EXPECT().Context().Return(ctx)
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.NotFound, "Log stream \"hello/operations/b9e1b1d4-6b4f-4bd8-a7c3-4d7e3c36b1b5/logstreams/30bd1d6f-e1f4-4f2f-8b7c-dbb5a4a0e2b5\" does not exist"),
+			server.Read(&bytestream.ReadRequest{
+				ResourceName: "hello/operations/b9e1b1d4-6b4f-4bd8-a7c3-4d7e3c36b1b5/logstreams/30bd1d6f-e1f4-4f2f-8b7c-dbb5a4a0e2b5",
+			}, readServer))
+	})
+}
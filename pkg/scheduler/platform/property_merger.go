@@ -0,0 +1,92 @@
+package platform
+
+import (
+	"sort"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/buildqueuestate"
+)
+
+// PropertyMerger is capable of merging platform properties that are
+// configured centrally into the platform properties provided by
+// clients. This permits operators to set properties such as container
+// images and sandboxing flags, without requiring that every client
+// provides them.
+//
+// The following precedence rules apply:
+//
+//   - Enforced properties always take precedence. Any properties
+//     provided by the client having the same name are discarded.
+//   - Properties provided by the client take precedence over default
+//     properties having the same name.
+//   - Default properties are only added if the client did not provide
+//     any properties having the same name.
+type PropertyMerger struct {
+	defaultProperties  []*remoteexecution.Platform_Property
+	enforcedProperties []*remoteexecution.Platform_Property
+}
+
+// NewPropertyMerger creates a new PropertyMerger that applies the
+// provided default and enforced platform properties.
+func NewPropertyMerger(defaultProperties, enforcedProperties []*remoteexecution.Platform_Property) *PropertyMerger {
+	return &PropertyMerger{
+		defaultProperties:  defaultProperties,
+		enforcedProperties: enforcedProperties,
+	}
+}
+
+func getPropertyNames(properties []*remoteexecution.Platform_Property) map[string]struct{} {
+	names := make(map[string]struct{}, len(properties))
+	for _, property := range properties {
+		names[property.Name] = struct{}{}
+	}
+	return names
+}
+
+// MergePlatform merges the configured platform properties into a
+// platform provided by the client. The resulting platform is sorted, as
+// required by REv2. In addition to the resulting platform, a message is
+// returned that describes which properties were added and overridden.
+// This message may be used as an audit trail. If no changes were made,
+// the original platform and a nil message are returned.
+func (pm *PropertyMerger) MergePlatform(platform *remoteexecution.Platform) (*remoteexecution.Platform, *buildqueuestate.AppliedPlatformProperties) {
+	var clientProperties []*remoteexecution.Platform_Property
+	if platform != nil {
+		clientProperties = platform.Properties
+	}
+	clientNames := getPropertyNames(clientProperties)
+	enforcedNames := getPropertyNames(pm.enforcedProperties)
+
+	var mergedProperties []*remoteexecution.Platform_Property
+	var applied buildqueuestate.AppliedPlatformProperties
+	for _, property := range clientProperties {
+		if _, ok := enforcedNames[property.Name]; ok {
+			applied.Overridden = append(applied.Overridden, property)
+		} else {
+			mergedProperties = append(mergedProperties, property)
+		}
+	}
+	for _, property := range pm.enforcedProperties {
+		if _, ok := clientNames[property.Name]; !ok {
+			applied.Added = append(applied.Added, property)
+		}
+		mergedProperties = append(mergedProperties, property)
+	}
+	for _, property := range pm.defaultProperties {
+		_, hasClientProperty := clientNames[property.Name]
+		_, hasEnforcedProperty := enforcedNames[property.Name]
+		if !hasClientProperty && !hasEnforcedProperty {
+			applied.Added = append(applied.Added, property)
+			mergedProperties = append(mergedProperties, property)
+		}
+	}
+
+	if len(applied.Added) == 0 && len(applied.Overridden) == 0 {
+		return platform, nil
+	}
+	sort.Slice(mergedProperties, func(i, j int) bool {
+		pi, pj := mergedProperties[i], mergedProperties[j]
+		return pi.Name < pj.Name || (pi.Name == pj.Name && pi.Value < pj.Value)
+	})
+	return &remoteexecution.Platform{Properties: mergedProperties}, &applied
+}
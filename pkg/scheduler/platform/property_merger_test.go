@@ -0,0 +1,84 @@
+package platform_test
+
+import (
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/proto/buildqueuestate"
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/platform"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPropertyMerger(t *testing.T) {
+	propertyMerger := platform.NewPropertyMerger(
+		[]*remoteexecution.Platform_Property{
+			{Name: "container-image", Value: "docker://ubuntu:22.04"},
+			{Name: "dockerNetwork", Value: "off"},
+		},
+		[]*remoteexecution.Platform_Property{
+			{Name: "sandbox", Value: "strict"},
+		})
+
+	t.Run("NilPlatform", func(t *testing.T) {
+		// Actions without any platform properties should
+		// receive all default and enforced properties.
+		mergedPlatform, applied := propertyMerger.MergePlatform(nil)
+		testutil.RequireEqualProto(t, &remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "container-image", Value: "docker://ubuntu:22.04"},
+				{Name: "dockerNetwork", Value: "off"},
+				{Name: "sandbox", Value: "strict"},
+			},
+		}, mergedPlatform)
+		testutil.RequireEqualProto(t, &buildqueuestate.AppliedPlatformProperties{
+			Added: []*remoteexecution.Platform_Property{
+				{Name: "sandbox", Value: "strict"},
+				{Name: "container-image", Value: "docker://ubuntu:22.04"},
+				{Name: "dockerNetwork", Value: "off"},
+			},
+		}, applied)
+	})
+
+	t.Run("Precedence", func(t *testing.T) {
+		// Client provided properties should take precedence
+		// over default properties, while enforced properties
+		// should take precedence over client provided ones.
+		mergedPlatform, applied := propertyMerger.MergePlatform(&remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "OSFamily", Value: "linux"},
+				{Name: "container-image", Value: "docker://debian:12"},
+				{Name: "sandbox", Value: "none"},
+			},
+		})
+		testutil.RequireEqualProto(t, &remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "OSFamily", Value: "linux"},
+				{Name: "container-image", Value: "docker://debian:12"},
+				{Name: "dockerNetwork", Value: "off"},
+				{Name: "sandbox", Value: "strict"},
+			},
+		}, mergedPlatform)
+		testutil.RequireEqualProto(t, &buildqueuestate.AppliedPlatformProperties{
+			Added: []*remoteexecution.Platform_Property{
+				{Name: "dockerNetwork", Value: "off"},
+			},
+			Overridden: []*remoteexecution.Platform_Property{
+				{Name: "sandbox", Value: "none"},
+			},
+		}, applied)
+	})
+
+	t.Run("NoChanges", func(t *testing.T) {
+		// If the merger doesn't need to make any changes, the
+		// original platform should be returned.
+		originalPlatform := &remoteexecution.Platform{
+			Properties: []*remoteexecution.Platform_Property{
+				{Name: "OSFamily", Value: "linux"},
+			},
+		}
+		mergedPlatform, applied := platform.NewPropertyMerger(nil, nil).MergePlatform(originalPlatform)
+		require.Equal(t, originalPlatform, mergedPlatform)
+		require.Nil(t, applied)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/platform"
 	"github.com/buildbarn/bb-storage/pkg/blobstore"
 	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/eviction"
 	"github.com/buildbarn/bb-storage/pkg/util"
 
 	"google.golang.org/grpc/codes"
@@ -62,6 +63,21 @@ func NewActionRouterFromConfiguration(configuration *pb.ActionRouterConfiguratio
 			}
 		}
 		return actionRouter, nil
+	case *pb.ActionRouterConfiguration_InputRootLimiting:
+		baseActionRouter, err := NewActionRouterFromConfiguration(kind.InputRootLimiting.Base, contentAddressableStorage, maximumMessageSizeBytes, previousExecutionStatsStore)
+		if err != nil {
+			return nil, err
+		}
+		return NewInputRootLimitingActionRouter(
+			baseActionRouter,
+			contentAddressableStorage,
+			maximumMessageSizeBytes,
+			kind.InputRootLimiting.MaximumSizeBytes,
+			kind.InputRootLimiting.MaximumFileCount,
+			kind.InputRootLimiting.Suggestion,
+			int(kind.InputRootLimiting.MaximumDirectoriesWalked),
+			int(kind.InputRootLimiting.MaximumCachedDirectories),
+			eviction.NewMetricsSet(eviction.NewLRUSet[digest.Digest](), "InputRootLimitingActionRouter")), nil
 	default:
 		return nil, status.Error(codes.InvalidArgument, "Configuration did not contain a supported action router type")
 	}
@@ -0,0 +1,247 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/initialsizeclass"
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/invocation"
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/platform"
+	"github.com/buildbarn/bb-storage/pkg/blobstore"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/eviction"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type inputRootLimitingActionRouter struct {
+	base                      ActionRouter
+	contentAddressableStorage blobstore.BlobAccess
+	maximumMessageSizeBytes   int
+	maximumSizeBytes          int64
+	maximumFileCount          int64
+	suggestion                string
+	maximumDirectoriesWalked  int
+	maximumCachedDirectories  int
+
+	lock         sync.Mutex
+	cachedTotals map[digest.Digest]inputRootTotals
+	evictionSet  eviction.Set[digest.Digest]
+}
+
+// NewInputRootLimitingActionRouter creates a decorator for
+// ActionRouter that rejects actions whose input roots exceed a given
+// total size or number of files. This can be used to protect workers
+// with little memory or disk space from running out of resources while
+// instantiating input roots. In combination with
+// DemultiplexingActionRouter, different limits may be applied to each
+// platform queue.
+//
+// As REv2 Directory messages don't contain any totals of their
+// subtrees, these need to be computed by loading directories from the
+// Content Addressable Storage. To keep the cost of doing this bounded,
+// the totals of subtrees are cached across calls, and at most
+// maximumDirectoriesWalked directories are loaded per action. Actions
+// for which limits are not exceeded after loading this number of
+// directories are admitted.
+//
+// Actions that exceed the limits are rejected with FAILED_PRECONDITION
+// and a PreconditionFailure error detail. The provided suggestion,
+// which may, for example, mention a platform having larger workers, is
+// appended to the error message.
+//
+// A limit of zero indicates that no limit should be applied.
+func NewInputRootLimitingActionRouter(base ActionRouter, contentAddressableStorage blobstore.BlobAccess, maximumMessageSizeBytes int, maximumSizeBytes, maximumFileCount int64, suggestion string, maximumDirectoriesWalked, maximumCachedDirectories int, evictionSet eviction.Set[digest.Digest]) ActionRouter {
+	return &inputRootLimitingActionRouter{
+		base:                      base,
+		contentAddressableStorage: contentAddressableStorage,
+		maximumMessageSizeBytes:   maximumMessageSizeBytes,
+		maximumSizeBytes:          maximumSizeBytes,
+		maximumFileCount:          maximumFileCount,
+		suggestion:                suggestion,
+		maximumDirectoriesWalked:  maximumDirectoriesWalked,
+		maximumCachedDirectories:  maximumCachedDirectories,
+		cachedTotals:              map[digest.Digest]inputRootTotals{},
+		evictionSet:               evictionSet,
+	}
+}
+
+// inputRootTotals contains the total size and number of files
+// contained in a directory hierarchy.
+type inputRootTotals struct {
+	sizeBytes int64
+	fileCount int64
+}
+
+func (ar *inputRootLimitingActionRouter) exceedsLimits(totals inputRootTotals) bool {
+	return (ar.maximumSizeBytes > 0 && totals.sizeBytes > ar.maximumSizeBytes) ||
+		(ar.maximumFileCount > 0 && totals.fileCount > ar.maximumFileCount)
+}
+
+// getCachedTotals returns the totals of a directory hierarchy that
+// were computed by a previous call to RouteAction().
+func (ar *inputRootLimitingActionRouter) getCachedTotals(directoryDigest digest.Digest) (inputRootTotals, bool) {
+	ar.lock.Lock()
+	defer ar.lock.Unlock()
+
+	totals, ok := ar.cachedTotals[directoryDigest]
+	if ok {
+		ar.evictionSet.Touch(directoryDigest)
+	}
+	return totals, ok
+}
+
+// putCachedTotals stores the totals of a directory hierarchy, so that
+// subsequent calls to RouteAction() don't need to load it again.
+func (ar *inputRootLimitingActionRouter) putCachedTotals(directoryDigest digest.Digest, totals inputRootTotals) {
+	if ar.maximumCachedDirectories <= 0 {
+		return
+	}
+
+	ar.lock.Lock()
+	defer ar.lock.Unlock()
+
+	if _, ok := ar.cachedTotals[directoryDigest]; ok {
+		ar.evictionSet.Touch(directoryDigest)
+		return
+	}
+	for len(ar.cachedTotals) >= ar.maximumCachedDirectories {
+		delete(ar.cachedTotals, ar.evictionSet.Peek())
+		ar.evictionSet.Remove()
+	}
+	ar.cachedTotals[directoryDigest] = totals
+	ar.evictionSet.Insert(directoryDigest)
+}
+
+// inputRootTotalsComputer computes the total size and number of files
+// of an input root. Totals of directories are memoized, so that
+// directories that occur in the input root many times are only loaded
+// once.
+type inputRootTotalsComputer struct {
+	ar                   *inputRootLimitingActionRouter
+	digestFunction       digest.Function
+	remainingDirectories int
+	totals               map[digest.Digest]inputRootTotals
+}
+
+// getTotals returns the totals of a directory hierarchy. Traversal
+// stops as soon as the limits are exceeded, or the maximum number of
+// directories to load is reached. The totals that are returned may be
+// lower than the actual values in that case, which is indicated by
+// returning false.
+func (tc *inputRootTotalsComputer) getTotals(ctx context.Context, directoryDigest digest.Digest) (inputRootTotals, bool, error) {
+	if totals, ok := tc.totals[directoryDigest]; ok {
+		return totals, true, nil
+	}
+	if totals, ok := tc.ar.getCachedTotals(directoryDigest); ok {
+		tc.totals[directoryDigest] = totals
+		return totals, true, nil
+	}
+	if tc.remainingDirectories <= 0 {
+		return inputRootTotals{}, false, nil
+	}
+	tc.remainingDirectories--
+	directoryMessage, err := tc.ar.contentAddressableStorage.Get(ctx, directoryDigest).ToProto(&remoteexecution.Directory{}, tc.ar.maximumMessageSizeBytes)
+	if err != nil {
+		return inputRootTotals{}, false, util.StatusWrapf(err, "Failed to obtain directory %#v", directoryDigest.String())
+	}
+	directory := directoryMessage.(*remoteexecution.Directory)
+
+	var totals inputRootTotals
+	for _, file := range directory.Files {
+		totals.sizeBytes += file.Digest.GetSizeBytes()
+		totals.fileCount++
+	}
+	for _, child := range directory.Directories {
+		if tc.ar.exceedsLimits(totals) {
+			return totals, false, nil
+		}
+		childDigest, err := tc.digestFunction.NewDigestFromProto(child.Digest)
+		if err != nil {
+			return inputRootTotals{}, false, util.StatusWrapf(err, "Failed to extract digest for directory %#v", child.Name)
+		}
+		childTotals, complete, err := tc.getTotals(ctx, childDigest)
+		if err != nil {
+			return inputRootTotals{}, false, err
+		}
+		totals.sizeBytes += childTotals.sizeBytes
+		totals.fileCount += childTotals.fileCount
+		if !complete {
+			return totals, false, nil
+		}
+	}
+
+	// Only memoize totals that have been computed completely.
+	tc.totals[directoryDigest] = totals
+	tc.ar.putCachedTotals(directoryDigest, totals)
+	return totals, true, nil
+}
+
+func (ar *inputRootLimitingActionRouter) newLimitExceededError(totals inputRootTotals) error {
+	var violations []*errdetails.PreconditionFailure_Violation
+	var message string
+	if ar.maximumSizeBytes > 0 && totals.sizeBytes > ar.maximumSizeBytes {
+		description := fmt.Sprintf("Input root has a size of at least %d bytes, while at most %d bytes is permitted", totals.sizeBytes, ar.maximumSizeBytes)
+		violations = append(violations, &errdetails.PreconditionFailure_Violation{
+			Type:        inputRootTooLargeViolationType,
+			Subject:     "input_root_size_bytes",
+			Description: description,
+		})
+		message = description
+	}
+	if ar.maximumFileCount > 0 && totals.fileCount > ar.maximumFileCount {
+		description := fmt.Sprintf("Input root contains at least %d files, while at most %d files are permitted", totals.fileCount, ar.maximumFileCount)
+		violations = append(violations, &errdetails.PreconditionFailure_Violation{
+			Type:        inputRootTooLargeViolationType,
+			Subject:     "input_root_file_count",
+			Description: description,
+		})
+		if message == "" {
+			message = description
+		}
+	}
+	if ar.suggestion != "" {
+		message += ". " + ar.suggestion
+	}
+	s, err := status.New(codes.FailedPrecondition, message).WithDetails(
+		&errdetails.PreconditionFailure{Violations: violations})
+	if err != nil {
+		return err
+	}
+	return s.Err()
+}
+
+// inputRootTooLargeViolationType is the type of the violations that
+// are reported when actions are rejected. Similar to the "MISSING"
+// type used by REv2, this permits clients to identify the cause of the
+// failure programmatically.
+const inputRootTooLargeViolationType = "INPUT_ROOT_TOO_LARGE"
+
+func (ar *inputRootLimitingActionRouter) RouteAction(ctx context.Context, digestFunction digest.Function, action *remoteexecution.Action, requestMetadata *remoteexecution.RequestMetadata) (platform.Key, []invocation.Key, initialsizeclass.Selector, error) {
+	inputRootDigest, err := digestFunction.NewDigestFromProto(action.InputRootDigest)
+	if err != nil {
+		return platform.Key{}, nil, nil, util.StatusWrap(err, "Failed to extract digest for input root")
+	}
+	tc := inputRootTotalsComputer{
+		ar:                   ar,
+		digestFunction:       digestFunction,
+		remainingDirectories: ar.maximumDirectoriesWalked,
+		totals:               map[digest.Digest]inputRootTotals{},
+	}
+	totals, _, err := tc.getTotals(ctx, inputRootDigest)
+	if err != nil {
+		return platform.Key{}, nil, nil, err
+	}
+	// Totals are lower bounds if traversal was stopped early. The
+	// limits are thus only exceeded if they are exceeded by these
+	// lower bounds.
+	if ar.exceedsLimits(totals) {
+		return platform.Key{}, nil, nil, ar.newLimitExceededError(totals)
+	}
+	return ar.base.RouteAction(ctx, digestFunction, action, requestMetadata)
+}
@@ -0,0 +1,160 @@
+package routing_test
+
+import (
+	"context"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/platform"
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/routing"
+	"github.com/buildbarn/bb-storage/pkg/blobstore/buffer"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/eviction"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestInputRootLimitingActionRouter(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	baseActionRouter := mock.NewMockActionRouter(ctrl)
+	contentAddressableStorage := mock.NewMockBlobAccess(ctrl)
+	newActionRouter := func(maximumDirectoriesWalked, maximumCachedDirectories int) routing.ActionRouter {
+		return routing.NewInputRootLimitingActionRouter(
+			baseActionRouter,
+			contentAddressableStorage,
+			10000,
+			/* maximumSizeBytes = */ 1000,
+			/* maximumFileCount = */ 4,
+			"Please use execution property 'pool=large'",
+			maximumDirectoriesWalked,
+			maximumCachedDirectories,
+			eviction.NewLRUSet[digest.Digest]())
+	}
+
+	// An input root containing the same subdirectory twice. The
+	// subdirectory should only be loaded once.
+	digestFunction := digest.MustNewFunction("hello", remoteexecution.DigestFunction_MD5)
+	subdirectoryDigest := digest.MustNewDigest("hello", remoteexecution.DigestFunction_MD5, "4df5f448a5e6b3c41e6aae7a8a9832aa", 200)
+	inputRootDigest := digest.MustNewDigest("hello", remoteexecution.DigestFunction_MD5, "8b1a9953c4611296a827abf8c47804d7", 300)
+	action := &remoteexecution.Action{
+		InputRootDigest: &remoteexecution.Digest{
+			Hash:      "8b1a9953c4611296a827abf8c47804d7",
+			SizeBytes: 300,
+		},
+	}
+	expectInputRootOnly := func() {
+		contentAddressableStorage.EXPECT().Get(ctx, inputRootDigest).Return(buffer.NewProtoBufferFromProto(&remoteexecution.Directory{
+			Directories: []*remoteexecution.DirectoryNode{
+				{
+					Name: "a",
+					Digest: &remoteexecution.Digest{
+						Hash:      "4df5f448a5e6b3c41e6aae7a8a9832aa",
+						SizeBytes: 200,
+					},
+				},
+				{
+					Name: "b",
+					Digest: &remoteexecution.Digest{
+						Hash:      "4df5f448a5e6b3c41e6aae7a8a9832aa",
+						SizeBytes: 200,
+					},
+				},
+			},
+		}, buffer.UserProvided))
+	}
+	expectSubdirectory := func(fileSizeBytes int64) {
+		contentAddressableStorage.EXPECT().Get(ctx, subdirectoryDigest).Return(buffer.NewProtoBufferFromProto(&remoteexecution.Directory{
+			Files: []*remoteexecution.FileNode{
+				{
+					Name: "file1",
+					Digest: &remoteexecution.Digest{
+						Hash:      "8f4ed2f3a9fea1b3c2a5cbd3b2a6e9b1",
+						SizeBytes: fileSizeBytes,
+					},
+				},
+				{
+					Name: "file2",
+					Digest: &remoteexecution.Digest{
+						Hash:      "8f4ed2f3a9fea1b3c2a5cbd3b2a6e9b1",
+						SizeBytes: fileSizeBytes,
+					},
+				},
+			},
+		}, buffer.UserProvided))
+	}
+	expectInputRoot := func(fileSizeBytes int64) {
+		expectInputRootOnly()
+		expectSubdirectory(fileSizeBytes)
+	}
+
+	t.Run("WithinLimits", func(t *testing.T) {
+		// Four files of 250 bytes exactly match the limits.
+		actionRouter := newActionRouter(100, 100)
+		expectInputRoot(250)
+		initialSizeClassSelector := mock.NewMockSelector(ctrl)
+		baseActionRouter.EXPECT().RouteAction(ctx, digestFunction, action, nil).
+			Return(platform.MustNewKey("hello", nil), nil, initialSizeClassSelector, nil).
+			Times(2)
+
+		platformKey, invocationKeys, selector, err := actionRouter.RouteAction(ctx, digestFunction, action, nil)
+		require.NoError(t, err)
+		require.Equal(t, platform.MustNewKey("hello", nil), platformKey)
+		require.Empty(t, invocationKeys)
+		require.Equal(t, initialSizeClassSelector, selector)
+
+		// Totals of the input root should have been cached,
+		// meaning that routing the same action once more should
+		// not cause any directories to be loaded.
+		_, _, _, err = actionRouter.RouteAction(ctx, digestFunction, action, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("SizeExceeded", func(t *testing.T) {
+		actionRouter := newActionRouter(100, 100)
+		expectInputRoot(251)
+
+		_, _, _, err := actionRouter.RouteAction(ctx, digestFunction, action, nil)
+		expectedStatus, err2 := status.New(codes.FailedPrecondition, "Input root has a size of at least 1004 bytes, while at most 1000 bytes is permitted. Please use execution property 'pool=large'").WithDetails(
+			&errdetails.PreconditionFailure{
+				Violations: []*errdetails.PreconditionFailure_Violation{
+					{
+						Type:        "INPUT_ROOT_TOO_LARGE",
+						Subject:     "input_root_size_bytes",
+						Description: "Input root has a size of at least 1004 bytes, while at most 1000 bytes is permitted",
+					},
+				},
+			})
+		require.NoError(t, err2)
+		testutil.RequireEqualStatus(t, expectedStatus.Err(), err)
+	})
+
+	t.Run("WalkLimitReached", func(t *testing.T) {
+		// If the maximum number of directories to load is
+		// reached without exceeding any of the limits, the
+		// action should be admitted.
+		actionRouter := newActionRouter(1, 100)
+		expectInputRootOnly()
+		initialSizeClassSelector := mock.NewMockSelector(ctrl)
+		baseActionRouter.EXPECT().RouteAction(ctx, digestFunction, action, nil).
+			Return(platform.MustNewKey("hello", nil), nil, initialSizeClassSelector, nil)
+
+		_, _, _, err := actionRouter.RouteAction(ctx, digestFunction, action, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("StorageFailure", func(t *testing.T) {
+		actionRouter := newActionRouter(100, 100)
+		contentAddressableStorage.EXPECT().Get(ctx, inputRootDigest).
+			Return(buffer.NewBufferFromError(status.Error(codes.Internal, "Server on fire")))
+
+		_, _, _, err := actionRouter.RouteAction(ctx, digestFunction, action, nil)
+		testutil.RequireEqualStatus(t, status.Error(codes.Internal, "Failed to obtain directory \"3-8b1a9953c4611296a827abf8c47804d7-300-hello\": Server on fire"), err)
+	})
+}
@@ -0,0 +1,106 @@
+package sharding
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+type ringEntry struct {
+	hash   uint64
+	member string
+}
+
+// ConsistentHashRing assigns keys to members, such that changes to the
+// set of members only cause a small fraction of the keys to be
+// reassigned. Every member is placed on the ring multiple times
+// (virtual nodes), so that keys are distributed evenly.
+//
+// ConsistentHashRing is safe for concurrent use. The set of members may
+// be changed while lookups are performed.
+type ConsistentHashRing struct {
+	virtualNodesPerMember int
+
+	lock    sync.RWMutex
+	members []string
+	entries []ringEntry
+}
+
+// NewConsistentHashRing creates a ConsistentHashRing that initially
+// has no members.
+func NewConsistentHashRing(virtualNodesPerMember int) *ConsistentHashRing {
+	return &ConsistentHashRing{
+		virtualNodesPerMember: virtualNodesPerMember,
+	}
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+
+	// FNV-1a provides poor diffusion for short inputs that only
+	// differ in their last characters, such as the names of virtual
+	// nodes. Apply the finalizer of MurmurHash3 to ensure that
+	// entries are spread evenly across the ring.
+	v := h.Sum64()
+	v ^= v >> 33
+	v *= 0xff51afd7ed558ccd
+	v ^= v >> 33
+	v *= 0xc4ceb9fe1a85ec53
+	v ^= v >> 33
+	return v
+}
+
+// SetMembers replaces the set of members of the ring. Keys that were
+// assigned to members that remain part of the ring are only reassigned
+// if they are now claimed by a newly added member.
+func (r *ConsistentHashRing) SetMembers(members []string) {
+	entries := make([]ringEntry, 0, len(members)*r.virtualNodesPerMember)
+	for _, member := range members {
+		for i := 0; i < r.virtualNodesPerMember; i++ {
+			entries = append(entries, ringEntry{
+				hash:   hashString(member + "#" + strconv.FormatInt(int64(i), 10)),
+				member: member,
+			})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].hash < entries[j].hash ||
+			(entries[i].hash == entries[j].hash && entries[i].member < entries[j].member)
+	})
+
+	r.lock.Lock()
+	r.members = append([]string(nil), members...)
+	r.entries = entries
+	r.lock.Unlock()
+}
+
+// GetMembers returns the current set of members of the ring.
+func (r *ConsistentHashRing) GetMembers() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return append([]string(nil), r.members...)
+}
+
+// Lookup the member to which a key is assigned. False is returned if
+// the ring has no members.
+func (r *ConsistentHashRing) Lookup(key string) (string, bool) {
+	hash := hashString(key)
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if len(r.entries) == 0 {
+		return "", false
+	}
+	i := sort.Search(len(r.entries), func(i int) bool {
+		return r.entries[i].hash >= hash
+	})
+	if i == len(r.entries) {
+		// Wrap around the ring.
+		i = 0
+	}
+	return r.entries[i].member, true
+}
@@ -0,0 +1,61 @@
+package sharding_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/sharding"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsistentHashRing(t *testing.T) {
+	ring := sharding.NewConsistentHashRing(100)
+
+	t.Run("Empty", func(t *testing.T) {
+		_, ok := ring.Lookup("hello")
+		require.False(t, ok)
+	})
+
+	// Distribute a large number of keys across three members.
+	ring.SetMembers([]string{"a", "b", "c"})
+	require.Equal(t, []string{"a", "b", "c"}, ring.GetMembers())
+	const keysCount = 10000
+	assignments := make([]string, 0, keysCount)
+	counts := map[string]int{}
+	for i := 0; i < keysCount; i++ {
+		member, ok := ring.Lookup(strconv.FormatInt(int64(i), 10))
+		require.True(t, ok)
+		assignments = append(assignments, member)
+		counts[member]++
+	}
+
+	t.Run("Distribution", func(t *testing.T) {
+		// Keys should be distributed reasonably evenly.
+		for _, member := range []string{"a", "b", "c"} {
+			require.Greater(t, counts[member], keysCount/5, member)
+		}
+	})
+
+	t.Run("Deterministic", func(t *testing.T) {
+		for i, expectedMember := range assignments {
+			member, ok := ring.Lookup(strconv.FormatInt(int64(i), 10))
+			require.True(t, ok)
+			require.Equal(t, expectedMember, member)
+		}
+	})
+
+	t.Run("RemoveMember", func(t *testing.T) {
+		// Removing a member should only cause keys assigned
+		// to that member to be reassigned.
+		ring.SetMembers([]string{"a", "c"})
+		for i, previousMember := range assignments {
+			member, ok := ring.Lookup(strconv.FormatInt(int64(i), 10))
+			require.True(t, ok)
+			if previousMember == "b" {
+				require.NotEqual(t, "b", member)
+			} else {
+				require.Equal(t, previousMember, member)
+			}
+		}
+	})
+}
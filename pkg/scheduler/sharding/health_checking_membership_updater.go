@@ -0,0 +1,72 @@
+package sharding
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/buildbarn/bb-storage/pkg/clock"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// UpdateMembershipFromHealthChecks periodically calls into the health
+// checking service of every shard, and updates the members of a hash
+// ring to consist of the shards that are healthy. This causes actions
+// to be resharded automatically when schedulers are added, removed or
+// become unavailable.
+//
+// This function blocks until the provided context is canceled.
+func UpdateMembershipFromHealthChecks(ctx context.Context, ring *ConsistentHashRing, healthClients map[string]grpc_health_v1.HealthClient, clock clock.Clock, interval, timeout time.Duration) error {
+	var previousMembers []string
+	for {
+		members := checkHealth(ctx, healthClients, timeout)
+		if !slices.Equal(previousMembers, members) {
+			log.Printf("Healthy schedulers: %v", members)
+			ring.SetMembers(members)
+			previousMembers = members
+		}
+
+		timer, t := clock.NewTimer(interval)
+		select {
+		case <-t:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		}
+	}
+}
+
+// checkHealth concurrently checks the health of all shards, returning
+// the names of the ones that are serving in sorted order.
+func checkHealth(ctx context.Context, healthClients map[string]grpc_health_v1.HealthClient, timeout time.Duration) []string {
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	members := make([]string, 0, len(healthClients))
+	for shardName, healthClient := range healthClients {
+		wg.Add(1)
+		go func(shardName string, healthClient grpc_health_v1.HealthClient) {
+			defer wg.Done()
+			ctxWithTimeout, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			response, err := healthClient.Check(ctxWithTimeout, &grpc_health_v1.HealthCheckRequest{})
+			if err != nil {
+				log.Printf("Health check of scheduler %#v failed: %s", shardName, err)
+				return
+			}
+			if response.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+				log.Printf("Scheduler %#v has health status %s", shardName, response.Status)
+				return
+			}
+			lock.Lock()
+			members = append(members, shardName)
+			lock.Unlock()
+		}(shardName, healthClient)
+	}
+	wg.Wait()
+	sort.Strings(members)
+	return members
+}
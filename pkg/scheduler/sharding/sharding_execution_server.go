@@ -0,0 +1,136 @@
+package sharding
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-storage/pkg/digest"
+	"github.com/buildbarn/bb-storage/pkg/util"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+)
+
+// Shard of the scheduler, to which ShardingExecutionServer may forward
+// requests.
+type Shard struct {
+	ExecutionClient    remoteexecution.ExecutionClient
+	CapabilitiesClient remoteexecution.CapabilitiesClient
+}
+
+// ShardingExecutionServer is an implementation of the Execution
+// service that forwards requests to one of multiple schedulers. The
+// scheduler is picked by hashing the action digest, meaning that
+// identical actions end up on the same scheduler. This ensures that
+// in-flight deduplication of actions continues to work.
+//
+// Names of operations returned to clients are prefixed with the name
+// of the shard, so that calls to WaitExecution() can be forwarded to
+// the scheduler that owns the operation, even if the set of members of
+// the hash ring has changed in the meantime.
+type ShardingExecutionServer struct {
+	shards map[string]Shard
+	ring   *ConsistentHashRing
+}
+
+// NewShardingExecutionServer creates a ShardingExecutionServer that
+// forwards requests to a set of shards. The hash ring is used to pick
+// a shard for newly submitted actions. Its members must correspond to
+// keys in the map of shards.
+func NewShardingExecutionServer(shards map[string]Shard, ring *ConsistentHashRing) *ShardingExecutionServer {
+	return &ShardingExecutionServer{
+		shards: shards,
+		ring:   ring,
+	}
+}
+
+func (s *ShardingExecutionServer) getShardForKey(key string) (string, Shard, error) {
+	shardName, ok := s.ring.Lookup(key)
+	if !ok {
+		return "", Shard{}, status.Error(codes.Unavailable, "No healthy schedulers are available")
+	}
+	return shardName, s.shards[shardName], nil
+}
+
+// GetCapabilities forwards the request to one of the schedulers. This
+// method can be used to implement capabilities.Provider.
+func (s *ShardingExecutionServer) GetCapabilities(ctx context.Context, instanceName digest.InstanceName) (*remoteexecution.ServerCapabilities, error) {
+	shardName, shard, err := s.getShardForKey(instanceName.String())
+	if err != nil {
+		return nil, err
+	}
+	serverCapabilities, err := shard.CapabilitiesClient.GetCapabilities(ctx, &remoteexecution.GetCapabilitiesRequest{
+		InstanceName: instanceName.String(),
+	})
+	if err != nil {
+		return nil, util.StatusWrapf(err, "Shard %#v", shardName)
+	}
+	return serverCapabilities, nil
+}
+
+type operationReceiver interface {
+	Recv() (*longrunningpb.Operation, error)
+}
+
+type operationSender interface {
+	Send(*longrunningpb.Operation) error
+}
+
+// forwardOperations relays operations returned by a scheduler to the
+// client, prefixing operation names with the name of the shard.
+func forwardOperations(shardName string, in operationReceiver, out operationSender) error {
+	for {
+		operation, err := in.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		operation.Name = shardName + "/" + operation.Name
+		if err := out.Send(operation); err != nil {
+			return err
+		}
+	}
+}
+
+// Execute an action by forwarding it to the scheduler that is
+// responsible for the action digest.
+func (s *ShardingExecutionServer) Execute(request *remoteexecution.ExecuteRequest, out remoteexecution.Execution_ExecuteServer) error {
+	actionDigest := request.ActionDigest
+	if actionDigest == nil {
+		return status.Error(codes.InvalidArgument, "No action digest provided")
+	}
+	shardName, shard, err := s.getShardForKey(request.InstanceName + "/" + actionDigest.Hash)
+	if err != nil {
+		return err
+	}
+	client, err := shard.ExecutionClient.Execute(out.Context(), request)
+	if err != nil {
+		return util.StatusWrapf(err, "Shard %#v", shardName)
+	}
+	return forwardOperations(shardName, client, out)
+}
+
+// WaitExecution forwards a request to wait for an operation to the
+// scheduler that owns the operation.
+func (s *ShardingExecutionServer) WaitExecution(request *remoteexecution.WaitExecutionRequest, out remoteexecution.Execution_WaitExecutionServer) error {
+	shardName, operationName, ok := strings.Cut(request.Name, "/")
+	if !ok {
+		return status.Errorf(codes.NotFound, "Operation %#v not found", request.Name)
+	}
+	shard, ok := s.shards[shardName]
+	if !ok {
+		return status.Errorf(codes.NotFound, "Operation %#v not found", request.Name)
+	}
+	client, err := shard.ExecutionClient.WaitExecution(out.Context(), &remoteexecution.WaitExecutionRequest{
+		Name: operationName,
+	})
+	if err != nil {
+		return util.StatusWrapf(err, "Shard %#v", shardName)
+	}
+	return forwardOperations(shardName, client, out)
+}
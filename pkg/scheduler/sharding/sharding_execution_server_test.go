@@ -0,0 +1,92 @@
+package sharding_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	remoteexecution "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/scheduler/sharding"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+)
+
+func TestShardingExecutionServer(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+
+	executionClientA := mock.NewMockExecutionClient(ctrl)
+	executionClientB := mock.NewMockExecutionClient(ctrl)
+	ring := sharding.NewConsistentHashRing(10)
+	server := sharding.NewShardingExecutionServer(
+		map[string]sharding.Shard{
+			"a": {ExecutionClient: executionClientA},
+			"b": {ExecutionClient: executionClientB},
+		},
+		ring)
+
+	request := &remoteexecution.ExecuteRequest{
+		InstanceName: "hello",
+		ActionDigest: &remoteexecution.Digest{
+			Hash:      "8b1a9953c4611296a827abf8c47804d7",
+			SizeBytes: 123,
+		},
+	}
+
+	t.Run("NoHealthySchedulers", func(t *testing.T) {
+		out := mock.NewMockExecution_ExecuteServer(ctrl)
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.Unavailable, "No healthy schedulers are available"),
+			server.Execute(request, out))
+	})
+
+	t.Run("ExecuteSuccess", func(t *testing.T) {
+		// With only a single member, all actions should be
+		// forwarded to it. Operation names should be prefixed
+		// with the name of the shard.
+		ring.SetMembers([]string{"b"})
+
+		out := mock.NewMockExecution_ExecuteServer(ctrl)
+		out.EXPECT().Context().Return(ctx).AnyTimes()
+		client := mock.NewMockExecution_ExecuteClient(ctrl)
+		executionClientB.EXPECT().Execute(ctx, request).Return(client, nil)
+		client.EXPECT().Recv().Return(&longrunningpb.Operation{Name: "operation1"}, nil)
+		out.EXPECT().Send(&longrunningpb.Operation{Name: "b/operation1"})
+		client.EXPECT().Recv().Return(&longrunningpb.Operation{Name: "operation1", Done: true}, nil)
+		out.EXPECT().Send(&longrunningpb.Operation{Name: "b/operation1", Done: true})
+		client.EXPECT().Recv().Return(nil, io.EOF)
+
+		require.NoError(t, server.Execute(request, out))
+	})
+
+	t.Run("WaitExecutionUnknownShard", func(t *testing.T) {
+		out := mock.NewMockExecution_WaitExecutionServer(ctrl)
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.NotFound, "Operation \"c/operation1\" not found"),
+			server.WaitExecution(&remoteexecution.WaitExecutionRequest{Name: "c/operation1"}, out))
+	})
+
+	t.Run("WaitExecutionSuccess", func(t *testing.T) {
+		// Even though shard "a" is no longer part of the hash
+		// ring, it should still be possible to wait for
+		// operations owned by it.
+		out := mock.NewMockExecution_WaitExecutionServer(ctrl)
+		out.EXPECT().Context().Return(ctx).AnyTimes()
+		client := mock.NewMockExecution_WaitExecutionClient(ctrl)
+		executionClientA.EXPECT().WaitExecution(ctx, &remoteexecution.WaitExecutionRequest{Name: "operation2"}).Return(client, nil)
+		client.EXPECT().Recv().Return(nil, status.Error(codes.NotFound, "Operation not found"))
+
+		testutil.RequireEqualStatus(
+			t,
+			status.Error(codes.NotFound, "Operation not found"),
+			server.WaitExecution(&remoteexecution.WaitExecutionRequest{Name: "a/operation2"}, out))
+	})
+}
@@ -0,0 +1,24 @@
+package sync
+
+// LockRank describes the position of a class of locks in a lock
+// hierarchy. To prevent deadlocks, a thread may only block on acquiring
+// a lock if all other locks held by the thread have a lower rank.
+// Acquiring locks of equal or lower rank is only permitted by calling
+// TryLock(), which is what LockPile does.
+//
+// Lock ranks are only validated when building with the "lockorder"
+// build tag (e.g., "bazel test --config=lockorder //..."). In regular
+// builds, Mutex and RWMutex behave identically to their counterparts
+// in the standard library.
+type LockRank struct {
+	// Human readable name of the class of locks, which is used in
+	// diagnostic messages.
+	Name string
+	// Rank of the class of locks in the lock hierarchy.
+	Rank int
+}
+
+var (
+	_ TryLocker = &Mutex{}
+	_ TryLocker = &RWMutex{}
+)
@@ -0,0 +1,22 @@
+//go:build !lockorder
+// +build !lockorder
+
+package sync
+
+import (
+	"sync"
+)
+
+// Mutex is a mutual exclusion lock that is part of a lock hierarchy.
+// In regular builds, it is equivalent to sync.Mutex.
+type Mutex struct {
+	sync.Mutex
+	Rank *LockRank
+}
+
+// RWMutex is a reader/writer mutual exclusion lock that is part of a
+// lock hierarchy. In regular builds, it is equivalent to sync.RWMutex.
+type RWMutex struct {
+	sync.RWMutex
+	Rank *LockRank
+}
@@ -0,0 +1,282 @@
+//go:build lockorder
+// +build lockorder
+
+package sync
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// lockWatchdogThreshold is the amount of time a lock may be held or
+// waited upon, before the watchdog dumps the stacks of all goroutines.
+const lockWatchdogThreshold = 30 * time.Second
+
+// lockHolding contains the state of a lock that is held by a goroutine.
+type lockHolding struct {
+	rank     *LockRank
+	count    int
+	since    time.Time
+	stack    []byte
+	reported bool
+}
+
+// lockWait contains the state of a goroutine that is blocked on
+// acquiring a lock.
+type lockWait struct {
+	rank     *LockRank
+	since    time.Time
+	reported bool
+}
+
+// lockTracker keeps track of the locks held by every goroutine, so
+// that lock order violations and stalls can be detected.
+type lockTracker struct {
+	lock     sync.Mutex
+	holdings map[uint64]map[interface{}]*lockHolding
+	waits    map[uint64]*lockWait
+}
+
+var tracker = lockTracker{
+	holdings: map[uint64]map[interface{}]*lockHolding{},
+	waits:    map[uint64]*lockWait{},
+}
+
+func init() {
+	go func() {
+		for {
+			time.Sleep(lockWatchdogThreshold / 2)
+			tracker.reportStalls()
+		}
+	}()
+}
+
+// getGoroutineID returns the numerical identifier of the calling
+// goroutine. The Go runtime does not expose it directly, so it is
+// extracted from the first line of the goroutine's stack trace, which
+// has the form "goroutine 123 [running]:".
+func getGoroutineID() uint64 {
+	var buf [64]byte
+	fields := strings.Fields(string(buf[:runtime.Stack(buf[:], false)]))
+	if len(fields) < 2 {
+		panic("Failed to obtain goroutine ID from stack trace")
+	}
+	id, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to parse goroutine ID %#v: %s", fields[1], err))
+	}
+	return id
+}
+
+func getLockName(rank *LockRank) string {
+	if rank == nil {
+		return "unranked lock"
+	}
+	return fmt.Sprintf("%s lock (rank %d)", rank.Name, rank.Rank)
+}
+
+// beforeBlockingLock is called before a goroutine blocks on acquiring
+// a lock. It validates that doing so does not violate the lock
+// hierarchy, and registers the goroutine as waiting.
+func (t *lockTracker) beforeBlockingLock(goroutine uint64, lock interface{}, rank *LockRank) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for heldLock, h := range t.holdings[goroutine] {
+		if heldLock == lock {
+			panic(fmt.Sprintf("Attempted to recursively acquire %s, which was acquired at:\n%s", getLockName(rank), h.stack))
+		}
+		if rank != nil && h.rank != nil && h.rank.Rank >= rank.Rank {
+			panic(fmt.Sprintf("Lock order violation: blocking on %s while holding %s, which was acquired at:\n%s", getLockName(rank), getLockName(h.rank), h.stack))
+		}
+	}
+	t.waits[goroutine] = &lockWait{
+		rank:  rank,
+		since: time.Now(),
+	}
+}
+
+// afterLock is called after a goroutine has acquired a lock.
+func (t *lockTracker) afterLock(goroutine uint64, lock interface{}, rank *LockRank) {
+	stack := debug.Stack()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.waits, goroutine)
+	holdings, ok := t.holdings[goroutine]
+	if !ok {
+		holdings = map[interface{}]*lockHolding{}
+		t.holdings[goroutine] = holdings
+	}
+	if h, ok := holdings[lock]; ok {
+		// Read lock acquired recursively through TryRLock().
+		h.count++
+		return
+	}
+	holdings[lock] = &lockHolding{
+		rank:  rank,
+		count: 1,
+		since: time.Now(),
+		stack: stack,
+	}
+}
+
+// beforeUnlock is called before a goroutine releases a lock. Go
+// permits locks to be released by goroutines other than the one that
+// acquired them. If the calling goroutine does not hold the lock, the
+// holdings of other goroutines are inspected.
+func (t *lockTracker) beforeUnlock(goroutine uint64, lock interface{}) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.releaseLocked(goroutine, lock) {
+		return
+	}
+	for otherGoroutine := range t.holdings {
+		if t.releaseLocked(otherGoroutine, lock) {
+			return
+		}
+	}
+	panic("Attempted to release a lock that is not held")
+}
+
+func (t *lockTracker) releaseLocked(goroutine uint64, lock interface{}) bool {
+	holdings := t.holdings[goroutine]
+	h, ok := holdings[lock]
+	if !ok {
+		return false
+	}
+	h.count--
+	if h.count == 0 {
+		delete(holdings, lock)
+		if len(holdings) == 0 {
+			delete(t.holdings, goroutine)
+		}
+	}
+	return true
+}
+
+// reportStalls logs locks that have been held or waited upon for an
+// excessive amount of time. If any are found, the stacks of all
+// goroutines are written to stderr. This makes it possible to
+// determine which goroutine is responsible for the stall.
+func (t *lockTracker) reportStalls() {
+	now := time.Now()
+	var messages []string
+
+	t.lock.Lock()
+	for goroutine, holdings := range t.holdings {
+		for _, h := range holdings {
+			if d := now.Sub(h.since); !h.reported && d >= lockWatchdogThreshold {
+				messages = append(messages, fmt.Sprintf("Goroutine %d has held %s for %s, which was acquired at:\n%s", goroutine, getLockName(h.rank), d, h.stack))
+				h.reported = true
+			}
+		}
+	}
+	for goroutine, w := range t.waits {
+		if d := now.Sub(w.since); !w.reported && d >= lockWatchdogThreshold {
+			messages = append(messages, fmt.Sprintf("Goroutine %d has been blocked on acquiring %s for %s", goroutine, getLockName(w.rank), d))
+			w.reported = true
+		}
+	}
+	t.lock.Unlock()
+
+	if len(messages) > 0 {
+		log.Print("Lock watchdog detected stalls:\n", strings.Join(messages, "\n"))
+		pprof.Lookup("goroutine").WriteTo(os.Stderr, 2)
+	}
+}
+
+// Mutex is a mutual exclusion lock that is part of a lock hierarchy.
+// In builds with the "lockorder" build tag, it validates that locks
+// are acquired in the order of their rank.
+type Mutex struct {
+	mutex sync.Mutex
+	Rank  *LockRank
+}
+
+// Lock the mutex, blocking if needed.
+func (m *Mutex) Lock() {
+	goroutine := getGoroutineID()
+	tracker.beforeBlockingLock(goroutine, m, m.Rank)
+	m.mutex.Lock()
+	tracker.afterLock(goroutine, m, m.Rank)
+}
+
+// TryLock attempts to lock the mutex without blocking.
+func (m *Mutex) TryLock() bool {
+	if !m.mutex.TryLock() {
+		return false
+	}
+	tracker.afterLock(getGoroutineID(), m, m.Rank)
+	return true
+}
+
+// Unlock the mutex.
+func (m *Mutex) Unlock() {
+	tracker.beforeUnlock(getGoroutineID(), m)
+	m.mutex.Unlock()
+}
+
+// RWMutex is a reader/writer mutual exclusion lock that is part of a
+// lock hierarchy. In builds with the "lockorder" build tag, it
+// validates that locks are acquired in the order of their rank.
+type RWMutex struct {
+	mutex sync.RWMutex
+	Rank  *LockRank
+}
+
+// Lock the mutex exclusively, blocking if needed.
+func (m *RWMutex) Lock() {
+	goroutine := getGoroutineID()
+	tracker.beforeBlockingLock(goroutine, m, m.Rank)
+	m.mutex.Lock()
+	tracker.afterLock(goroutine, m, m.Rank)
+}
+
+// TryLock attempts to lock the mutex exclusively without blocking.
+func (m *RWMutex) TryLock() bool {
+	if !m.mutex.TryLock() {
+		return false
+	}
+	tracker.afterLock(getGoroutineID(), m, m.Rank)
+	return true
+}
+
+// Unlock the mutex after it was locked exclusively.
+func (m *RWMutex) Unlock() {
+	tracker.beforeUnlock(getGoroutineID(), m)
+	m.mutex.Unlock()
+}
+
+// RLock locks the mutex for reading, blocking if needed.
+func (m *RWMutex) RLock() {
+	goroutine := getGoroutineID()
+	tracker.beforeBlockingLock(goroutine, m, m.Rank)
+	m.mutex.RLock()
+	tracker.afterLock(goroutine, m, m.Rank)
+}
+
+// TryRLock attempts to lock the mutex for reading without blocking.
+func (m *RWMutex) TryRLock() bool {
+	if !m.mutex.TryRLock() {
+		return false
+	}
+	tracker.afterLock(getGoroutineID(), m, m.Rank)
+	return true
+}
+
+// RUnlock the mutex after it was locked for reading.
+func (m *RWMutex) RUnlock() {
+	tracker.beforeUnlock(getGoroutineID(), m)
+	m.mutex.RUnlock()
+}
@@ -0,0 +1,63 @@
+//go:build lockorder
+// +build lockorder
+
+package sync_test
+
+import (
+	"testing"
+
+	"github.com/buildbarn/bb-remote-execution/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutexLockOrder(t *testing.T) {
+	lowRank := &sync.LockRank{Name: "Low", Rank: 1}
+	highRank := &sync.LockRank{Name: "High", Rank: 2}
+
+	t.Run("IncreasingRank", func(t *testing.T) {
+		// Acquiring locks in order of increasing rank is
+		// permitted.
+		l1 := sync.Mutex{Rank: lowRank}
+		l2 := sync.RWMutex{Rank: highRank}
+		l1.Lock()
+		l2.RLock()
+		l2.RUnlock()
+		l1.Unlock()
+	})
+
+	t.Run("DecreasingRank", func(t *testing.T) {
+		// Blocking on a lock of lower rank may cause deadlocks.
+		l1 := sync.Mutex{Rank: highRank}
+		l2 := sync.Mutex{Rank: lowRank}
+		l1.Lock()
+		require.PanicsWithValue(t, "Lock order violation: blocking on Low lock (rank 1) while holding High lock (rank 2), which was acquired at:\n", func() {
+			defer func() {
+				// Strip the stack trace from the panic
+				// message.
+				if r := recover(); r != nil {
+					message := r.(string)
+					for i := 0; i < len(message); i++ {
+						if message[i] == '\n' {
+							panic(message[:i+1])
+						}
+					}
+					panic(message)
+				}
+			}()
+			l2.Lock()
+		})
+		l1.Unlock()
+	})
+
+	t.Run("EqualRankTryLock", func(t *testing.T) {
+		// Acquiring locks of equal rank is permitted through
+		// TryLock(), as that cannot block. This is what
+		// LockPile relies on.
+		l1 := sync.Mutex{Rank: lowRank}
+		l2 := sync.Mutex{Rank: lowRank}
+		l1.Lock()
+		require.True(t, l2.TryLock())
+		l2.Unlock()
+		l1.Unlock()
+	})
+}
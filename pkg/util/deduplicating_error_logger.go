@@ -0,0 +1,131 @@
+package util
+
+import (
+	"sync"
+	"time"
+
+	"github.com/buildbarn/bb-storage/pkg/clock"
+	"github.com/buildbarn/bb-storage/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	deduplicatingErrorLoggerPrometheusMetrics sync.Once
+
+	deduplicatingErrorLoggerErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "buildbarn",
+			Subsystem: "util",
+			Name:      "deduplicating_error_logger_errors_total",
+			Help:      "Number of errors reported to the deduplicating error logger, grouped by gRPC status code and whether they were logged, deduplicated or rate limited.",
+		},
+		[]string{"name", "grpc_code", "outcome"})
+)
+
+type deduplicatingErrorLoggerCategory struct {
+	loggedTotal       prometheus.Counter
+	deduplicatedTotal prometheus.Counter
+	rateLimitedTotal  prometheus.Counter
+
+	// State that is reset at the start of every interval.
+	intervalStart   time.Time
+	seenMessages    map[string]struct{}
+	loggedCount     int
+	suppressedCount int
+}
+
+type deduplicatingErrorLogger struct {
+	base                     util.ErrorLogger
+	clock                    clock.Clock
+	name                     string
+	interval                 time.Duration
+	maximumErrorsPerInterval int
+
+	lock       sync.Mutex
+	categories map[codes.Code]*deduplicatingErrorLoggerCategory
+}
+
+// NewDeduplicatingErrorLogger creates a decorator for ErrorLogger that
+// prevents logs from being flooded in case a large number of similar
+// errors occur in a short amount of time (e.g., when a storage backend
+// is misbehaving).
+//
+// Errors are categorized by gRPC status code. Within every interval,
+// errors having a message identical to one that was logged previously
+// are discarded. In addition to that, at most maximumErrorsPerInterval
+// distinct errors are logged per category. Errors that are logged are
+// forwarded in full. The number of errors that were discarded is
+// reported when the next error of the same category occurs after the
+// interval has elapsed. Counts are also exposed through Prometheus.
+func NewDeduplicatingErrorLogger(base util.ErrorLogger, clock clock.Clock, name string, interval time.Duration, maximumErrorsPerInterval int) util.ErrorLogger {
+	deduplicatingErrorLoggerPrometheusMetrics.Do(func() {
+		prometheus.MustRegister(deduplicatingErrorLoggerErrorsTotal)
+	})
+
+	return &deduplicatingErrorLogger{
+		base:                     base,
+		clock:                    clock,
+		name:                     name,
+		interval:                 interval,
+		maximumErrorsPerInterval: maximumErrorsPerInterval,
+		categories:               map[codes.Code]*deduplicatingErrorLoggerCategory{},
+	}
+}
+
+func (el *deduplicatingErrorLogger) getCategory(code codes.Code) *deduplicatingErrorLoggerCategory {
+	if c, ok := el.categories[code]; ok {
+		return c
+	}
+	codeName := code.String()
+	c := &deduplicatingErrorLoggerCategory{
+		loggedTotal:       deduplicatingErrorLoggerErrorsTotal.WithLabelValues(el.name, codeName, "Logged"),
+		deduplicatedTotal: deduplicatingErrorLoggerErrorsTotal.WithLabelValues(el.name, codeName, "Deduplicated"),
+		rateLimitedTotal:  deduplicatingErrorLoggerErrorsTotal.WithLabelValues(el.name, codeName, "RateLimited"),
+		seenMessages:      map[string]struct{}{},
+	}
+	el.categories[code] = c
+	return c
+}
+
+func (el *deduplicatingErrorLogger) Log(err error) {
+	code := status.Code(err)
+	message := err.Error()
+	now := el.clock.Now()
+
+	// Determine which errors need to be forwarded while holding
+	// the lock, but call into the underlying ErrorLogger afterwards.
+	var errs []error
+	el.lock.Lock()
+	c := el.getCategory(code)
+	if now.Sub(c.intervalStart) >= el.interval {
+		// Start a new interval, reporting how many errors were
+		// discarded during the previous one.
+		if c.suppressedCount > 0 {
+			errs = append(errs, status.Errorf(code, "Suppressed %d errors with this status code in an interval of %s", c.suppressedCount, el.interval))
+		}
+		c.intervalStart = now
+		c.seenMessages = map[string]struct{}{}
+		c.loggedCount = 0
+		c.suppressedCount = 0
+	}
+	if _, ok := c.seenMessages[message]; ok {
+		c.deduplicatedTotal.Inc()
+		c.suppressedCount++
+	} else if c.loggedCount >= el.maximumErrorsPerInterval {
+		c.rateLimitedTotal.Inc()
+		c.suppressedCount++
+	} else {
+		c.loggedTotal.Inc()
+		c.seenMessages[message] = struct{}{}
+		c.loggedCount++
+		errs = append(errs, err)
+	}
+	el.lock.Unlock()
+
+	for _, err := range errs {
+		el.base.Log(err)
+	}
+}
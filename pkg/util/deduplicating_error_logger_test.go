@@ -0,0 +1,54 @@
+package util_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/buildbarn/bb-remote-execution/internal/mock"
+	"github.com/buildbarn/bb-remote-execution/pkg/util"
+	"github.com/buildbarn/bb-storage/pkg/testutil"
+	"github.com/golang/mock/gomock"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDeduplicatingErrorLogger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	baseErrorLogger := mock.NewMockErrorLogger(ctrl)
+	clock := mock.NewMockClock(ctrl)
+	errorLogger := util.NewDeduplicatingErrorLogger(baseErrorLogger, clock, "test", time.Minute, 2)
+
+	// The first occurrence of an error should be forwarded.
+	clock.EXPECT().Now().Return(time.Unix(1000, 0))
+	baseErrorLogger.EXPECT().Log(testutil.EqStatus(t, status.Error(codes.Unavailable, "Shard 1 is offline")))
+	errorLogger.Log(status.Error(codes.Unavailable, "Shard 1 is offline"))
+
+	// Identical errors should be discarded.
+	clock.EXPECT().Now().Return(time.Unix(1010, 0))
+	errorLogger.Log(status.Error(codes.Unavailable, "Shard 1 is offline"))
+
+	// A second distinct error with the same status code should
+	// still be forwarded, while a third one should be rate limited.
+	clock.EXPECT().Now().Return(time.Unix(1020, 0))
+	baseErrorLogger.EXPECT().Log(testutil.EqStatus(t, status.Error(codes.Unavailable, "Shard 2 is offline")))
+	errorLogger.Log(status.Error(codes.Unavailable, "Shard 2 is offline"))
+
+	clock.EXPECT().Now().Return(time.Unix(1030, 0))
+	errorLogger.Log(status.Error(codes.Unavailable, "Shard 3 is offline"))
+
+	// Rate limiting is applied per status code, meaning that errors
+	// with other codes are unaffected.
+	clock.EXPECT().Now().Return(time.Unix(1040, 0))
+	baseErrorLogger.EXPECT().Log(testutil.EqStatus(t, status.Error(codes.Internal, "Disk is full")))
+	errorLogger.Log(status.Error(codes.Internal, "Disk is full"))
+
+	// Once the interval has elapsed, the number of errors that were
+	// discarded should be reported, and errors should be forwarded
+	// once again.
+	clock.EXPECT().Now().Return(time.Unix(1060, 0))
+	baseErrorLogger.EXPECT().Log(testutil.EqStatus(t, status.Error(codes.Unavailable, "Suppressed 2 errors with this status code in an interval of 1m0s")))
+	baseErrorLogger.EXPECT().Log(testutil.EqStatus(t, status.Error(codes.Unavailable, "Shard 1 is offline")))
+	errorLogger.Log(status.Error(codes.Unavailable, "Shard 1 is offline"))
+}